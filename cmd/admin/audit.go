@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// auditLogWriter records audit_logs rows
+type auditLogWriter interface {
+	CreateAuditLog(ctx context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error)
+}
+
+// auditLogLister lists audit_logs rows
+type auditLogLister interface {
+	ListAuditLogs(ctx context.Context, arg sqlc.ListAuditLogsParams) ([]sqlc.AuditLog, error)
+}
+
+// maxAuditRows caps a list-audit page
+const maxAuditRows = 1000
+
+// auditFilter holds the list-audit flags as given. since and until are dates (2006-01-02, until inclusive)
+// or RFC 3339 times (until exclusive); success is "true", "false" or empty for any result.
+type auditFilter struct {
+	actor   string
+	action  string
+	since   string
+	until   string
+	success string
+	limit   int
+}
+
+// params validates the filter and converts it to the ListAuditLogs parameters
+func (f auditFilter) params() (sqlc.ListAuditLogsParams, error) {
+	if f.limit < 1 || f.limit > maxAuditRows {
+		return sqlc.ListAuditLogsParams{}, fmt.Errorf("-limit must be between 1 and %d", maxAuditRows)
+	}
+
+	params := sqlc.ListAuditLogsParams{
+		Actor:    pgtype.Text{String: f.actor, Valid: f.actor != ""},
+		Action:   pgtype.Text{String: f.action, Valid: f.action != ""},
+		RowLimit: int32(f.limit),
+	}
+
+	var err error
+	if params.Since, err = parseTimeFlag("since", f.since, false); err != nil {
+		return sqlc.ListAuditLogsParams{}, err
+	}
+	if params.Until, err = parseTimeFlag("until", f.until, true); err != nil {
+		return sqlc.ListAuditLogsParams{}, err
+	}
+	if params.Since.Valid && params.Until.Valid && !params.Until.Time.After(params.Since.Time) {
+		return sqlc.ListAuditLogsParams{}, fmt.Errorf("-until must be after -since")
+	}
+
+	if f.success != "" {
+		success, err := strconv.ParseBool(f.success)
+		if err != nil {
+			return sqlc.ListAuditLogsParams{}, fmt.Errorf("-success must be true or false")
+		}
+		params.Success = pgtype.Bool{Bool: success, Valid: true}
+	}
+	return params, nil
+}
+
+// auditResult is the entry's recorded result ("success", "error", "declined"), or "-" when it has none
+func auditResult(metadata []byte) string {
+	var parsed struct {
+		Result  string `json:"result"`
+		Outcome struct {
+			Result string `json:"result"`
+		} `json:"outcome"`
+	}
+	if err := json.Unmarshal(metadata, &parsed); err == nil {
+		if parsed.Result != "" {
+			return parsed.Result
+		}
+		if parsed.Outcome.Result != "" {
+			return parsed.Outcome.Result
+		}
+	}
+	return "-"
+}
+
+// operator identifies the person running the command in audit entries
+func operator() string {
+	return "admin:" + getEnv("USER", "unknown")
+}
+
+// createAuditLog records an operator change. The change has already been made, so a failure is reported
+// without undoing it.
+func createAuditLog(ctx context.Context, queries auditLogWriter, entry *domain.AuditEntry) {
+	metadata := entry.Metadata
+	if metadata == nil {
+		metadata = json.RawMessage(`{"source":"admin-cli","result":"success"}`)
+	}
+
+	_, err := queries.CreateAuditLog(ctx, sqlc.CreateAuditLogParams{
+		EventType:  entry.EventType,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		AgentID:    entry.AgentID,
+		UserID:     pgtype.Text{String: entry.Actor, Valid: entry.Actor != ""},
+		Action:     entry.Action,
+		AfterState: entry.Changes,
+		Metadata:   metadata,
+	})
+	if err != nil {
+		fmt.Printf("warning: %s was applied but its audit log entry failed: %v\n", entry.EventType, err)
+	}
+}
+
+// serviceAuditEntry is the audit record of a change to a calling service (agentID empty when no merchant is involved).
+// details are recorded with the IDs in the entry's changes.
+func serviceAuditEntry(action, serviceID, agentID string, details map[string]string) *domain.AuditEntry {
+	changes := map[string]string{"service_id": serviceID, "agent_id": agentID}
+	for key, value := range details {
+		changes[key] = value
+	}
+	changesJSON, _ := json.Marshal(changes)
+	return &domain.AuditEntry{
+		EventType:  "service." + action,
+		EntityType: "service",
+		EntityID:   serviceID,
+		AgentID:    agentID,
+		Actor:      operator(),
+		Action:     action,
+		Changes:    changesJSON,
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+func TestAuditFilter_Params(t *testing.T) {
+	params, err := auditFilter{
+		actor:   "admin:jane",
+		action:  domain.AuditActionRevokeAccess,
+		since:   "2025-06-01",
+		until:   "2025-06-30",
+		success: "false",
+		limit:   50,
+	}.params()
+	require.NoError(t, err)
+
+	assert.Equal(t, "admin:jane", params.Actor.String)
+	assert.True(t, params.Actor.Valid)
+	assert.Equal(t, "revoke_access", params.Action.String)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), params.Since.Time)
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), params.Until.Time, "a date as the end of the range covers the whole day")
+	assert.True(t, params.Success.Valid)
+	assert.False(t, params.Success.Bool)
+	assert.Equal(t, int32(50), params.RowLimit)
+}
+
+func TestAuditFilter_ParamsUnset(t *testing.T) {
+	params, err := auditFilter{limit: 100}.params()
+	require.NoError(t, err)
+
+	assert.False(t, params.Actor.Valid)
+	assert.False(t, params.Action.Valid)
+	assert.False(t, params.Since.Valid)
+	assert.False(t, params.Until.Valid)
+	assert.False(t, params.Success.Valid, "no success filter matches every result")
+}
+
+func TestAuditFilter_ParamsRFC3339(t *testing.T) {
+	params, err := auditFilter{since: "2025-06-01T12:00:00Z", until: "2025-06-01T13:00:00Z", limit: 1}.params()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 1, 13, 0, 0, 0, time.UTC), params.Until.Time, "times are used as given")
+}
+
+func TestAuditFilter_ParamsInvalid(t *testing.T) {
+	tests := map[string]auditFilter{
+		"zero limit":     {limit: 0},
+		"limit too high": {limit: maxAuditRows + 1},
+		"bad since":      {since: "June 1", limit: 10},
+		"bad success":    {success: "maybe", limit: 10},
+		"inverted range": {since: "2025-06-02", until: "2025-06-01", limit: 10},
+	}
+	for name, filter := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := filter.params()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAuditResult(t *testing.T) {
+	assert.Equal(t, "success", auditResult([]byte(`{"result":"success"}`)))
+	assert.Equal(t, "declined", auditResult([]byte(`{"outcome":{"result":"declined"}}`)), "payment entries nest the result")
+	assert.Equal(t, "-", auditResult([]byte(`{}`)))
+	assert.Equal(t, "-", auditResult(nil))
+}
+
+// recordingAuditWriter collects audit entries, created a minute apart, and lists them the way ListAuditLogs does
+type recordingAuditWriter struct {
+	entries []sqlc.CreateAuditLogParams
+}
+
+func (r *recordingAuditWriter) CreateAuditLog(ctx context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error) {
+	r.entries = append(r.entries, arg)
+	return sqlc.AuditLog{ID: int64(len(r.entries))}, nil
+}
+
+func (r *recordingAuditWriter) ListAuditLogs(ctx context.Context, arg sqlc.ListAuditLogsParams) ([]sqlc.AuditLog, error) {
+	var logs []sqlc.AuditLog
+	for i := len(r.entries) - 1; i >= 0 && len(logs) < int(arg.RowLimit); i-- {
+		entry := r.entries[i]
+		createdAt := time.Date(2025, 6, 1, 0, i, 0, 0, time.UTC)
+		switch {
+		case arg.Actor.Valid && entry.UserID.String != arg.Actor.String,
+			arg.Action.Valid && entry.Action != arg.Action.String,
+			arg.Since.Valid && createdAt.Before(arg.Since.Time),
+			arg.Until.Valid && !createdAt.Before(arg.Until.Time),
+			arg.Success.Valid && (auditResult(entry.Metadata) == "success") != arg.Success.Bool:
+			continue
+		}
+		logs = append(logs, sqlc.AuditLog{
+			ID:         int64(i + 1),
+			EventType:  entry.EventType,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			AgentID:    entry.AgentID,
+			UserID:     entry.UserID,
+			Action:     entry.Action,
+			Metadata:   entry.Metadata,
+			CreatedAt:  createdAt,
+		})
+	}
+	return logs, nil
+}
+
+func TestCreateAuditLog_ServiceEntry(t *testing.T) {
+	t.Setenv("USER", "jane")
+	writer := &recordingAuditWriter{}
+
+	createAuditLog(context.Background(), writer, serviceAuditEntry(domain.AuditActionGrantAccess, "pos-service", "merchant-1", map[string]string{"expires_at": "never"}))
+
+	require.Len(t, writer.entries, 1)
+	entry := writer.entries[0]
+	assert.Equal(t, "service.grant_access", entry.EventType)
+	assert.Equal(t, "pos-service", entry.EntityID)
+	assert.Equal(t, "admin:jane", entry.UserID.String)
+	assert.Equal(t, "success", auditResult(entry.Metadata), "list-audit -success=true finds CLI changes")
+
+	var changes map[string]string
+	require.NoError(t, json.Unmarshal(entry.AfterState, &changes))
+	assert.Equal(t, "merchant-1", changes["agent_id"])
+	assert.Equal(t, "never", changes["expires_at"])
+}
+
+func TestListAudit_FindsCLIChangesByResult(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("USER", "jane")
+	store := &recordingAuditWriter{}
+
+	createAuditLog(ctx, store, serviceAuditEntry(domain.AuditActionGrantAccess, "pos-service", "merchant-1", nil))
+	createAuditLog(ctx, store, &domain.AuditEntry{
+		EventType:  "payment.refund",
+		EntityType: "transaction",
+		EntityID:   "tx-1",
+		AgentID:    "merchant-1",
+		Actor:      "pos-service",
+		Action:     "refund",
+		Metadata:   json.RawMessage(`{"outcome":{"result":"declined"}}`),
+	})
+	createAuditLog(ctx, store, serviceAuditEntry(domain.AuditActionRevokeAccess, "pos-service", "merchant-1", nil))
+
+	list := func(filter auditFilter) []string {
+		var out bytes.Buffer
+		require.NoError(t, listAudit(ctx, &out, store, filter))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.NotEmpty(t, lines)
+		assert.Equal(t, []string{"TIME", "ACTOR", "EVENT", "ENTITY", "AGENT", "ID", "RESULT"}, strings.Fields(lines[0]))
+		return lines[1:]
+	}
+
+	succeeded := list(auditFilter{success: "true", limit: 100})
+	require.Len(t, succeeded, 2, "the CLI's own changes are recorded as successes")
+	assert.Equal(t, []string{"2025-06-01T00:02:00Z", "admin:jane", "service.revoke_access", "service/pos-service", "merchant-1", "success"},
+		strings.Fields(succeeded[0]), "newest first")
+	assert.Contains(t, succeeded[1], "service.grant_access")
+
+	failed := list(auditFilter{success: "false", limit: 100})
+	require.Len(t, failed, 1)
+	assert.Equal(t, []string{"2025-06-01T00:01:00Z", "pos-service", "payment.refund", "transaction/tx-1", "merchant-1", "declined"},
+		strings.Fields(failed[0]))
+
+	assert.Len(t, list(auditFilter{actor: "admin:jane", action: domain.AuditActionGrantAccess, limit: 100}), 1)
+	assert.Len(t, list(auditFilter{limit: 1}), 1, "-limit caps the rows")
+
+	var out bytes.Buffer
+	assert.Error(t, listAudit(ctx, &out, store, auditFilter{success: "maybe", limit: 100}))
+	assert.Empty(t, out.String(), "nothing is listed for an invalid filter")
+}
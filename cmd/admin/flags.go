@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// featureFlagStore sets and lists feature flags, recording changes in audit_logs
+type featureFlagStore interface {
+	auditLogWriter
+	SetFeatureFlag(ctx context.Context, arg sqlc.SetFeatureFlagParams) (sqlc.FeatureFlag, error)
+	ListFeatureFlags(ctx context.Context) ([]sqlc.FeatureFlag, error)
+}
+
+// setCharges turns new charges on or off for every merchant, or for agentID alone. Running servers read the
+// flag on every Sale and Authorize, so the change applies to the next request.
+func setCharges(ctx context.Context, queries featureFlagStore, agentID, enabledFlag, reason string) error {
+	enabled, err := strconv.ParseBool(enabledFlag)
+	if err != nil {
+		return fmt.Errorf("-enabled must be true or false")
+	}
+
+	flag, err := queries.SetFeatureFlag(ctx, sqlc.SetFeatureFlagParams{
+		Name:      domain.FeatureFlagChargesEnabled,
+		AgentID:   pgtype.Text{String: agentID, Valid: agentID != ""},
+		Enabled:   enabled,
+		Reason:    pgtype.Text{String: reason, Valid: reason != ""},
+		UpdatedBy: pgtype.Text{String: operator(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("set %s: %w", domain.FeatureFlagChargesEnabled, err)
+	}
+
+	createAuditLog(ctx, queries, featureFlagAuditEntry(flag))
+
+	scope := "all merchants"
+	if agentID != "" {
+		scope = "merchant " + agentID
+	}
+	state := "enabled"
+	if !enabled {
+		state = "disabled; refunds and voids still go through"
+	}
+	fmt.Printf("New charges for %s are %s\n", scope, state)
+	return nil
+}
+
+func listFlags(ctx context.Context, queries featureFlagStore) error {
+	flags, err := queries.ListFeatureFlags(ctx)
+	if err != nil {
+		return fmt.Errorf("list feature flags: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FLAG\tSCOPE\tENABLED\tREASON\tUPDATED BY\tUPDATED")
+	for _, flag := range flags {
+		scope := "global"
+		if flag.AgentID.Valid {
+			scope = flag.AgentID.String
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\t%s\n", flag.Name, scope, flag.Enabled, orDash(flag.Reason.String),
+			orDash(flag.UpdatedBy.String), flag.UpdatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+// featureFlagAuditEntry is the audit record of a flag change (agent ID empty for a global flag)
+func featureFlagAuditEntry(flag sqlc.FeatureFlag) *domain.AuditEntry {
+	changes, _ := json.Marshal(map[string]any{
+		"name":     flag.Name,
+		"agent_id": flag.AgentID.String,
+		"enabled":  flag.Enabled,
+		"reason":   flag.Reason.String,
+	})
+	return &domain.AuditEntry{
+		EventType:  "feature_flag." + domain.AuditActionSetFeatureFlag,
+		EntityType: "feature_flag",
+		EntityID:   flag.ID.String(),
+		AgentID:    flag.AgentID.String,
+		Actor:      operator(),
+		Action:     domain.AuditActionSetFeatureFlag,
+		Changes:    changes,
+	}
+}
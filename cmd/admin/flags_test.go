@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// fakeFlagStore keeps feature flags in memory, upserting by name and merchant
+type fakeFlagStore struct {
+	recordingAuditWriter
+	flags []sqlc.FeatureFlag
+}
+
+func (f *fakeFlagStore) SetFeatureFlag(ctx context.Context, arg sqlc.SetFeatureFlagParams) (sqlc.FeatureFlag, error) {
+	for i, flag := range f.flags {
+		if flag.Name == arg.Name && flag.AgentID == arg.AgentID {
+			f.flags[i].Enabled, f.flags[i].Reason, f.flags[i].UpdatedBy = arg.Enabled, arg.Reason, arg.UpdatedBy
+			return f.flags[i], nil
+		}
+	}
+	flag := sqlc.FeatureFlag{ID: uuid.New(), Name: arg.Name, AgentID: arg.AgentID, Enabled: arg.Enabled, Reason: arg.Reason, UpdatedBy: arg.UpdatedBy}
+	f.flags = append(f.flags, flag)
+	return flag, nil
+}
+
+func (f *fakeFlagStore) ListFeatureFlags(ctx context.Context) ([]sqlc.FeatureFlag, error) {
+	return f.flags, nil
+}
+
+func TestSetCharges(t *testing.T) {
+	t.Setenv("USER", "jane")
+	store := &fakeFlagStore{}
+
+	require.NoError(t, setCharges(context.Background(), store, "", "false", "incident 42"))
+	require.NoError(t, setCharges(context.Background(), store, "merchant-1", "false", ""))
+	require.NoError(t, setCharges(context.Background(), store, "", "true", "resolved"))
+
+	require.Len(t, store.flags, 2, "the global flag is updated in place")
+	assert.False(t, store.flags[0].AgentID.Valid)
+	assert.True(t, store.flags[0].Enabled)
+	assert.Equal(t, "resolved", store.flags[0].Reason.String)
+	assert.Equal(t, "admin:jane", store.flags[0].UpdatedBy.String)
+	assert.Equal(t, "merchant-1", store.flags[1].AgentID.String)
+	assert.False(t, store.flags[1].Enabled)
+
+	require.Len(t, store.entries, 3, "every change is audited")
+	entry := store.entries[1]
+	assert.Equal(t, "feature_flag.set_feature_flag", entry.EventType)
+	assert.Equal(t, "merchant-1", entry.AgentID)
+	var changes map[string]any
+	require.NoError(t, json.Unmarshal(entry.AfterState, &changes))
+	assert.Equal(t, domain.FeatureFlagChargesEnabled, changes["name"])
+	assert.Equal(t, false, changes["enabled"])
+}
+
+func TestSetCharges_InvalidEnabled(t *testing.T) {
+	store := &fakeFlagStore{}
+	assert.Error(t, setCharges(context.Background(), store, "", "off", ""))
+	assert.Empty(t, store.flags)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/services/serviceauth"
+)
+
+// maxListRows caps a list-services or list-merchants page
+const maxListRows = 1000
+
+// likeEscaper escapes LIKE wildcards (and the escape character itself) so they match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// listFilter holds the list-services and list-merchants flags as given. search matches part of the
+// service or agent ID (its slug) or name, case-insensitively; env is a merchant environment.
+type listFilter struct {
+	search string
+	env    string
+	limit  int
+	offset int
+}
+
+// searchPattern is the ILIKE pattern for the search term (NULL when there is none)
+func (f listFilter) searchPattern() pgtype.Text {
+	search := strings.TrimSpace(f.search)
+	if search == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: "%" + likeEscaper.Replace(search) + "%", Valid: true}
+}
+
+func (f listFilter) validatePage() error {
+	if f.limit < 1 || f.limit > maxListRows {
+		return fmt.Errorf("-limit must be between 1 and %d", maxListRows)
+	}
+	if f.offset < 0 {
+		return fmt.Errorf("-offset can't be negative")
+	}
+	return nil
+}
+
+// servicesParams validates the filter and converts it to the ListServices parameters
+func (f listFilter) servicesParams() (sqlc.ListServicesParams, error) {
+	if err := f.validatePage(); err != nil {
+		return sqlc.ListServicesParams{}, err
+	}
+	if f.env != "" {
+		return sqlc.ListServicesParams{}, fmt.Errorf("-env applies to list-merchants only; services aren't tied to an environment")
+	}
+	return sqlc.ListServicesParams{
+		Search:    f.searchPattern(),
+		LimitVal:  int32(f.limit),
+		OffsetVal: int32(f.offset),
+	}, nil
+}
+
+// merchantsParams validates the filter and converts it to the ListAgents parameters
+func (f listFilter) merchantsParams() (sqlc.ListAgentsParams, error) {
+	if err := f.validatePage(); err != nil {
+		return sqlc.ListAgentsParams{}, err
+	}
+	env := strings.ToLower(strings.TrimSpace(f.env))
+	return sqlc.ListAgentsParams{
+		Environment: pgtype.Text{String: env, Valid: env != ""},
+		Search:      f.searchPattern(),
+		LimitVal:    int32(f.limit),
+		OffsetVal:   int32(f.offset),
+	}, nil
+}
+
+// merchantLister lists merchants' credentials rows
+type merchantLister interface {
+	ListAgents(ctx context.Context, arg sqlc.ListAgentsParams) ([]sqlc.AgentCredential, error)
+}
+
+func listServices(ctx context.Context, registry *serviceauth.Registry, filter listFilter) error {
+	params, err := filter.servicesParams()
+	if err != nil {
+		return err
+	}
+
+	services, err := registry.ListServices(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE ID\tNAME\tACTIVE\tCREATED")
+	for _, service := range services {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", service.ServiceID, service.ServiceName, service.IsActive, service.CreatedAt.Format(time.RFC3339))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	printNextPage(len(services), filter)
+	return nil
+}
+
+func listMerchants(ctx context.Context, queries merchantLister, filter listFilter) error {
+	params, err := filter.merchantsParams()
+	if err != nil {
+		return err
+	}
+
+	merchants, err := queries.ListAgents(ctx, params)
+	if err != nil {
+		return fmt.Errorf("list merchants: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT ID\tNAME\tENVIRONMENT\tSTATUS\tCREATED")
+	for _, merchant := range merchants {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", merchant.AgentID, merchant.AgentName, merchant.Environment,
+			merchant.Status, merchant.CreatedAt.Format(time.RFC3339))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	printNextPage(len(merchants), filter)
+	return nil
+}
+
+// printNextPage tells the operator how to fetch the next page when this one was full
+func printNextPage(rows int, filter listFilter) {
+	if rows == filter.limit {
+		fmt.Printf("More rows may follow: repeat with -offset=%d\n", filter.offset+filter.limit)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/services/serviceauth"
+)
+
+// fakeMerchants serves ListAgents from memory, matching search patterns the way ILIKE does
+type fakeMerchants struct {
+	agents []sqlc.AgentCredential
+}
+
+func (f *fakeMerchants) ListAgents(ctx context.Context, arg sqlc.ListAgentsParams) ([]sqlc.AgentCredential, error) {
+	var matched []sqlc.AgentCredential
+	for _, agent := range f.agents {
+		if arg.Environment.Valid && agent.Environment != arg.Environment.String {
+			continue
+		}
+		if arg.Search.Valid && !ilike(agent.AgentID, arg.Search.String) && !ilike(agent.AgentName, arg.Search.String) {
+			continue
+		}
+		matched = append(matched, agent)
+	}
+	if int(arg.OffsetVal) >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[arg.OffsetVal:]
+	if len(matched) > int(arg.LimitVal) {
+		matched = matched[:arg.LimitVal]
+	}
+	return matched, nil
+}
+
+// ilike reports whether value matches a LIKE pattern case-insensitively (% and _ wildcards, \ escapes)
+func ilike(value, pattern string) bool {
+	var re strings.Builder
+	re.WriteString("(?is)^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			re.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case c == '%':
+			re.WriteString(".*")
+		case c == '_':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String()).MatchString(value)
+}
+
+func agentIDs(agents []sqlc.AgentCredential) []string {
+	ids := make([]string, len(agents))
+	for i, agent := range agents {
+		ids[i] = agent.AgentID
+	}
+	return ids
+}
+
+func TestListFilter_MerchantsMatchPartialSlug(t *testing.T) {
+	merchants := &fakeMerchants{agents: []sqlc.AgentCredential{
+		{AgentID: "acme-coffee-downtown", AgentName: "Acme Coffee", Environment: "production"},
+		{AgentID: "acme-coffee-sandbox", AgentName: "Acme Coffee (test)", Environment: "test"},
+		{AgentID: "bobs-bikes", AgentName: "Bob's Bikes", Environment: "production"},
+		{AgentID: "50_percent_off", AgentName: "Half Price", Environment: "production"},
+	}}
+	list := func(filter listFilter) []string {
+		params, err := filter.merchantsParams()
+		require.NoError(t, err)
+		agents, err := merchants.ListAgents(context.Background(), params)
+		require.NoError(t, err)
+		return agentIDs(agents)
+	}
+
+	assert.Equal(t, []string{"acme-coffee-downtown", "acme-coffee-sandbox"}, list(listFilter{search: "COFFEE", limit: 100}))
+	assert.Equal(t, []string{"bobs-bikes"}, list(listFilter{search: "bob's", limit: 100}), "names match too")
+	assert.Equal(t, []string{"acme-coffee-downtown"}, list(listFilter{search: "acme", env: "production", limit: 100}))
+	assert.Equal(t, []string{"50_percent_off"}, list(listFilter{search: "0_p", limit: 100}), "wildcards in the search are literal")
+	assert.Empty(t, list(listFilter{search: "50%", limit: 100}))
+
+	assert.Equal(t, []string{"acme-coffee-sandbox"}, list(listFilter{search: "acme", limit: 1, offset: 1}))
+	assert.Len(t, list(listFilter{limit: 100}), 4, "no search lists every merchant")
+}
+
+func TestListFilter_Params(t *testing.T) {
+	params, err := listFilter{search: "  pos ", limit: 25, offset: 50}.servicesParams()
+	require.NoError(t, err)
+	assert.Equal(t, "%pos%", params.Search.String)
+	assert.True(t, params.Search.Valid)
+	assert.Equal(t, int32(25), params.LimitVal)
+	assert.Equal(t, int32(50), params.OffsetVal)
+
+	params, err = listFilter{limit: 100}.servicesParams()
+	require.NoError(t, err)
+	assert.False(t, params.Search.Valid)
+
+	merchants, err := listFilter{env: "Production", limit: 100}.merchantsParams()
+	require.NoError(t, err)
+	assert.Equal(t, "production", merchants.Environment.String)
+	assert.False(t, merchants.Search.Valid)
+}
+
+func TestListFilter_ParamsInvalid(t *testing.T) {
+	for name, filter := range map[string]listFilter{
+		"zero limit":      {limit: 0},
+		"limit too high":  {limit: maxListRows + 1},
+		"negative offset": {limit: 10, offset: -1},
+		"env on services": {limit: 10, env: "test"},
+	} {
+		_, err := filter.servicesParams()
+		assert.Error(t, err, name)
+	}
+
+	_, err := listFilter{limit: 10, offset: -1}.merchantsParams()
+	assert.Error(t, err)
+}
+
+// fakeGrants serves ListServiceGrantDetails from memory, filtered the way the query is
+type fakeGrants struct {
+	serviceauth.QueryExecutor
+	grants []sqlc.ListServiceGrantDetailsRow
+}
+
+func (f *fakeGrants) ListServiceGrantDetails(ctx context.Context, arg sqlc.ListServiceGrantDetailsParams) ([]sqlc.ListServiceGrantDetailsRow, error) {
+	var matched []sqlc.ListServiceGrantDetailsRow
+	for _, grant := range f.grants {
+		if (arg.ServiceID.Valid && grant.ServiceID != arg.ServiceID.String) || (arg.AgentID.Valid && grant.AgentID != arg.AgentID.String) {
+			continue
+		}
+		matched = append(matched, grant)
+	}
+	return matched, nil
+}
+
+func TestListGrants_ShowsExpiry(t *testing.T) {
+	grantedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	expired := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	expiring := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	registry := serviceauth.NewRegistry(&fakeGrants{grants: []sqlc.ListServiceGrantDetailsRow{
+		{ServiceID: "pos-service", ServiceName: "POS", ServiceActive: true, AgentID: "merchant-1", AgentName: "Acme", GrantedAt: grantedAt},
+		{ServiceID: "pos-service", ServiceName: "POS", ServiceActive: true, AgentID: "merchant-2", AgentName: "Bobs", GrantedAt: grantedAt,
+			ExpiresAt: pgtype.Timestamptz{Time: expired, Valid: true}},
+		{ServiceID: "reporting", ServiceName: "Reports", ServiceActive: false, AgentID: "merchant-1", AgentName: "Acme", GrantedAt: grantedAt,
+			ExpiresAt: pgtype.Timestamptz{Time: expiring, Valid: true}},
+	}}, zap.NewNop())
+
+	list := func(serviceID, agentID string) [][]string {
+		var out bytes.Buffer
+		require.NoError(t, listGrants(context.Background(), &out, registry, serviceID, agentID))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.NotEmpty(t, lines)
+		rows := make([][]string, len(lines)-1)
+		for i, line := range lines[1:] {
+			rows[i] = strings.Fields(line)
+		}
+		return rows
+	}
+
+	rows := list("", "")
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"pos-service", "POS", "true", "merchant-1", "Acme", "2025-01-02T03:04:05Z", "never"}, rows[0])
+	assert.Equal(t, []string{expired.Format(time.RFC3339), "(expired)"}, rows[1][6:], "lapsed grants are flagged")
+	assert.Equal(t, []string{"false", "merchant-1"}, rows[2][2:4], "a deactivated service's grants are still listed")
+	assert.Equal(t, []string{expiring.Format(time.RFC3339)}, rows[2][6:])
+
+	assert.Len(t, list("pos-service", ""), 2)
+	assert.Len(t, list("", "merchant-1"), 2)
+	assert.Len(t, list("reporting", "merchant-2"), 0)
+}
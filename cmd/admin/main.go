@@ -0,0 +1,414 @@
+// Command admin runs operator tasks that have no gRPC API: registering calling services, rotating their keys,
+// granting them access to merchants, rotating merchants' EPX MAC secrets and switching new charges off during an incident.
+//
+//	admin -action=create-service -service-id=pos-service -name="POS"
+//	admin -action=add-key -service-id=pos-service -key-id=2025-06 -public-key-file=pos-2025-06.pem
+//	admin -action=retire-key -service-id=pos-service -key-id=2025-01
+//	admin -action=list-keys -service-id=pos-service
+//	admin -action=list-services [-search=pos] [-limit=100] [-offset=0]
+//	admin -action=list-merchants [-search=acme] [-env=production] [-limit=100] [-offset=0]
+//	admin -action=grant-access -service-id=pos-service -agent-id=merchant-1 [-expires-at=2025-12-31]
+//	admin -action=revoke-access -service-id=pos-service -agent-id=merchant-1
+//	admin -action=deactivate-service -service-id=pos-service
+//	admin -action=list-grants [-service-id=pos-service] [-agent-id=merchant-1]
+//	admin -action=verify-token -token=eyJhbGciOi...
+//	admin -action=list-audit [-actor=admin:jane] [-audit-action=revoke_access] [-since=2025-06-01] [-until=2025-06-30] [-success=true]
+//	admin -action=rotate-mac -agent-id=merchant-1 [-mac-file=new-mac.txt] [-keep-previous=false]
+//	admin -action=set-charges -enabled=false [-agent-id=merchant-1] [-reason="incident 42"]
+//	admin -action=list-flags
+//
+// It connects with the server's DB_* environment variables and reads secrets from the same store as the server.
+// Access, MAC and flag changes are recorded in audit_logs with the operator's $USER.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/adapters/database"
+	"github.com/kevin07696/payment-service/internal/adapters/secrets"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/agent"
+	"github.com/kevin07696/payment-service/internal/services/ports"
+	"github.com/kevin07696/payment-service/internal/services/serviceauth"
+)
+
+func main() {
+	action := flag.String("action", "", "create-service, list-services, list-merchants, add-key, retire-key, list-keys, grant-access, revoke-access, list-grants, deactivate-service, verify-token, rotate-mac, list-audit, set-charges or list-flags")
+	serviceID := flag.String("service-id", "", "Service ID (the iss claim of its tokens)")
+	name := flag.String("name", "", "Service name (create-service)")
+	keyID := flag.String("key-id", "", "Key ID (the kid header of tokens signed with the key)")
+	publicKeyFile := flag.String("public-key-file", "", "PEM-encoded RSA public key (add-key)")
+	agentID := flag.String("agent-id", "", "Merchant agent ID (grant-access, revoke-access, list-grants, rotate-mac; set-charges for one merchant)")
+	macFile := flag.String("mac-file", "", "File holding the new MAC issued by EPX; a random MAC is generated when omitted (rotate-mac)")
+	expiresAt := flag.String("expires-at", "", "When the grant lapses: a date (2006-01-02, through the end of that day UTC) or RFC 3339 time; never when omitted (grant-access)")
+	token := flag.String("token", "", "Service JWT to decode and verify against the registered keys (verify-token)")
+	enabled := flag.String("enabled", "", "true to accept new charges, false to refuse them (set-charges)")
+	reason := flag.String("reason", "", "Why the flag is changed, e.g. an incident reference (set-charges)")
+	keepPrevious := flag.Bool("keep-previous", true, "Keep the replaced MAC readable for in-flight callbacks (rotate-mac)")
+	var audit auditFilter
+	flag.StringVar(&audit.actor, "actor", "", "Only entries by this user, e.g. admin:jane or a service ID (list-audit)")
+	flag.StringVar(&audit.action, "audit-action", "", "Only entries with this action, e.g. rotate_mac or refund (list-audit)")
+	flag.StringVar(&audit.since, "since", "", "Only entries from this date (2006-01-02) or RFC 3339 time (list-audit)")
+	flag.StringVar(&audit.until, "until", "", "Only entries up to this date, inclusive, or before this RFC 3339 time (list-audit)")
+	flag.StringVar(&audit.success, "success", "", "true for successful entries only, false for failed ones (list-audit)")
+	var list listFilter
+	flag.StringVar(&list.search, "search", "", "Only rows whose ID (slug) or name contains this, case-insensitively (list-services, list-merchants)")
+	flag.StringVar(&list.env, "env", "", "Only merchants in this environment: test or production (list-merchants)")
+	flag.IntVar(&list.offset, "offset", 0, "Rows to skip, for the next page (list-services, list-merchants)")
+	limit := flag.Int("limit", 100, "Maximum rows (list-services, list-merchants, list-audit newest first)")
+	flag.Parse()
+	audit.limit, list.limit = *limit, *limit
+
+	logger, _ := zap.NewDevelopment()
+	defer logger.Sync()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db, err := database.NewPostgreSQLAdapter(ctx, database.DefaultPostgreSQLConfig(databaseURL()), logger)
+	if err != nil {
+		fatal(err)
+	}
+	defer db.Close()
+
+	registry := serviceauth.NewRegistry(db.Queries(), logger)
+
+	switch *action {
+	case "create-service":
+		requireFlags(map[string]string{"service-id": *serviceID, "name": *name})
+		err = createService(ctx, registry, *serviceID, *name)
+	case "list-services":
+		err = listServices(ctx, registry, list)
+	case "list-merchants":
+		err = listMerchants(ctx, db.Queries(), list)
+	case "add-key":
+		requireFlags(map[string]string{"service-id": *serviceID, "key-id": *keyID, "public-key-file": *publicKeyFile})
+		err = addKey(ctx, registry, *serviceID, *keyID, *publicKeyFile)
+	case "retire-key":
+		requireFlags(map[string]string{"service-id": *serviceID, "key-id": *keyID})
+		err = retireKey(ctx, registry, *serviceID, *keyID)
+	case "list-keys":
+		requireFlags(map[string]string{"service-id": *serviceID})
+		err = listKeys(ctx, registry, *serviceID)
+	case "grant-access":
+		requireFlags(map[string]string{"service-id": *serviceID, "agent-id": *agentID})
+		err = grantAccess(ctx, registry, db.Queries(), *serviceID, *agentID, *expiresAt)
+	case "revoke-access":
+		requireFlags(map[string]string{"service-id": *serviceID, "agent-id": *agentID})
+		err = revokeAccess(ctx, registry, db.Queries(), *serviceID, *agentID)
+	case "list-grants":
+		err = listGrants(ctx, os.Stdout, registry, *serviceID, *agentID)
+	case "deactivate-service":
+		requireFlags(map[string]string{"service-id": *serviceID})
+		err = deactivateService(ctx, registry, db.Queries(), *serviceID)
+	case "verify-token":
+		requireFlags(map[string]string{"token": *token})
+		err = verifyToken(ctx, registry, strings.TrimPrefix(strings.TrimSpace(*token), "Bearer "))
+	case "rotate-mac":
+		requireFlags(map[string]string{"agent-id": *agentID})
+		agentSvc := agent.NewAgentService(db, secrets.NewLocalSecretManager("./secrets", logger), logger)
+		err = rotateMAC(ctx, agentSvc, *agentID, *macFile, *keepPrevious)
+	case "list-audit":
+		err = listAudit(ctx, os.Stdout, db.Queries(), audit)
+	case "set-charges":
+		requireFlags(map[string]string{"enabled": *enabled})
+		err = setCharges(ctx, db.Queries(), *agentID, *enabled, *reason)
+	case "list-flags":
+		err = listFlags(ctx, db.Queries())
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func createService(ctx context.Context, registry *serviceauth.Registry, serviceID, name string) error {
+	service, err := registry.CreateService(ctx, serviceID, name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created service %s (%s)\nAdd its signing key with -action=add-key\n", service.ServiceID, service.ServiceName)
+	return nil
+}
+
+func addKey(ctx context.Context, registry *serviceauth.Registry, serviceID, keyID, publicKeyFile string) error {
+	pem, err := os.ReadFile(publicKeyFile)
+	if err != nil {
+		return fmt.Errorf("read public key: %w", err)
+	}
+
+	if _, err := registry.AddKey(ctx, serviceID, keyID, string(pem)); err != nil {
+		return err
+	}
+	fmt.Printf("Added key %s to %s; tokens signed with it verify now\n", keyID, serviceID)
+	return nil
+}
+
+func retireKey(ctx context.Context, registry *serviceauth.Registry, serviceID, keyID string) error {
+	if _, err := registry.RetireKey(ctx, serviceID, keyID); err != nil {
+		return err
+	}
+	fmt.Printf("Retired key %s of %s; running servers stop accepting it within 30 seconds\n", keyID, serviceID)
+	return nil
+}
+
+func listKeys(ctx context.Context, registry *serviceauth.Registry, serviceID string) error {
+	keys, err := registry.ListKeys(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY ID\tACTIVE\tCREATED\tRETIRED")
+	for _, key := range keys {
+		retired := "-"
+		if key.RetiredAt.Valid {
+			retired = key.RetiredAt.Time.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%t\t%s\t%s\n", key.KeyID, key.IsActive, key.CreatedAt.Format(time.RFC3339), retired)
+	}
+	return w.Flush()
+}
+
+func grantAccess(ctx context.Context, registry *serviceauth.Registry, audit auditLogWriter, serviceID, agentID, expiresAtFlag string) error {
+	expiresAt, err := parseTimeFlag("expires-at", expiresAtFlag, true)
+	if err != nil {
+		return err
+	}
+	if expiresAt.Valid && !expiresAt.Time.After(time.Now()) {
+		return fmt.Errorf("-expires-at must be in the future")
+	}
+
+	if _, err := registry.GrantAccess(ctx, serviceID, agentID, expiresAt.Time); err != nil {
+		return err
+	}
+
+	expiry := "never"
+	if expiresAt.Valid {
+		expiry = expiresAt.Time.UTC().Format(time.RFC3339)
+	}
+	createAuditLog(ctx, audit, serviceAuditEntry(domain.AuditActionGrantAccess, serviceID, agentID, map[string]string{"expires_at": expiry}))
+	fmt.Printf("Granted %s access to merchant %s (expires: %s)\n", serviceID, agentID, expiry)
+	return nil
+}
+
+func revokeAccess(ctx context.Context, registry *serviceauth.Registry, audit auditLogWriter, serviceID, agentID string) error {
+	if err := registry.RevokeAccess(ctx, serviceID, agentID); err != nil {
+		return err
+	}
+	createAuditLog(ctx, audit, serviceAuditEntry(domain.AuditActionRevokeAccess, serviceID, agentID, nil))
+	fmt.Printf("Revoked %s access to merchant %s; running servers deny it within 30 seconds\n", serviceID, agentID)
+	return nil
+}
+
+func listGrants(ctx context.Context, out io.Writer, registry *serviceauth.Registry, serviceID, agentID string) error {
+	grants, err := registry.ListGrants(ctx, serviceID, agentID)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE ID\tSERVICE\tSERVICE ACTIVE\tAGENT ID\tMERCHANT\tGRANTED\tEXPIRES")
+	for _, grant := range grants {
+		expires := "never"
+		if grant.ExpiresAt.Valid {
+			expires = grant.ExpiresAt.Time.Format(time.RFC3339)
+			if !grant.ExpiresAt.Time.After(time.Now()) {
+				expires += " (expired)"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\t%s\t%s\n", grant.ServiceID, grant.ServiceName, grant.ServiceActive,
+			grant.AgentID, grant.AgentName, grant.GrantedAt.Format(time.RFC3339), expires)
+	}
+	return w.Flush()
+}
+
+func deactivateService(ctx context.Context, registry *serviceauth.Registry, audit auditLogWriter, serviceID string) error {
+	if _, err := registry.DeactivateService(ctx, serviceID); err != nil {
+		return err
+	}
+	createAuditLog(ctx, audit, serviceAuditEntry(domain.AuditActionDeactivateService, serviceID, "", nil))
+	fmt.Printf("Deactivated %s; running servers reject its tokens within 30 seconds\n", serviceID)
+	return nil
+}
+
+func verifyToken(ctx context.Context, registry *serviceauth.Registry, token string) error {
+	inspection, err := registry.InspectToken(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if claims := inspection.Claims; claims != nil {
+		fmt.Fprintf(w, "Issuer (service ID):\t%s\n", orDash(claims.Issuer))
+		fmt.Fprintf(w, "Key ID (kid):\t%s\n", orDash(inspection.KeyID))
+		fmt.Fprintf(w, "Subject:\t%s\n", orDash(claims.Subject))
+		fmt.Fprintf(w, "Audience:\t%s\n", orDash(strings.Join(claims.Audience, " ")))
+		fmt.Fprintf(w, "Issued at:\t%s\n", claimTime(claims.IssuedAt))
+		fmt.Fprintf(w, "Not before:\t%s\n", claimTime(claims.NotBefore))
+		fmt.Fprintf(w, "Expires:\t%s\n", claimTime(claims.ExpiresAt))
+		fmt.Fprintf(w, "Scopes:\t%s\n", orDash(strings.Join(strings.Fields(claims.Scope), " ")))
+	}
+	if service := inspection.Service; service != nil {
+		fmt.Fprintf(w, "Service:\t%s (%s), active: %t\n", service.ServiceID, service.ServiceName, service.IsActive)
+		grants := make([]string, len(inspection.Grants))
+		for i, grant := range inspection.Grants {
+			grants[i] = grant.AgentID
+			if grant.ExpiresAt.Valid {
+				grants[i] += " (until " + grant.ExpiresAt.Time.Format(time.RFC3339)
+				if !grant.ExpiresAt.Time.After(time.Now()) {
+					grants[i] += ", expired"
+				}
+				grants[i] += ")"
+			}
+		}
+		fmt.Fprintf(w, "Merchant grants:\t%s\n", orDash(strings.Join(grants, ", ")))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !inspection.Valid() {
+		return fmt.Errorf("token rejected (%s): %w", inspection.Reason, inspection.Err)
+	}
+	fmt.Println("Token is valid: the signature verifies against an active key of the service")
+	return nil
+}
+
+// claimTime formats a time claim with how far it is from now, or "-" when the token doesn't carry it
+func claimTime(claim *jwt.NumericDate) string {
+	if claim == nil {
+		return "-"
+	}
+	from := time.Until(claim.Time).Round(time.Second)
+	if from < 0 {
+		return fmt.Sprintf("%s (%s ago)", claim.Time.UTC().Format(time.RFC3339), -from)
+	}
+	return fmt.Sprintf("%s (in %s)", claim.Time.UTC().Format(time.RFC3339), from)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func rotateMAC(ctx context.Context, agentSvc ports.AgentService, agentID, macFile string, keepPrevious bool) error {
+	var newMAC string
+	if macFile != "" {
+		mac, err := os.ReadFile(macFile)
+		if err != nil {
+			return fmt.Errorf("read MAC: %w", err)
+		}
+		newMAC = strings.TrimSpace(string(mac))
+	}
+
+	result, err := agentSvc.RotateMAC(ctx, &ports.RotateMACRequest{
+		AgentID:      agentID,
+		NewMACSecret: newMAC,
+		KeepPrevious: keepPrevious,
+		Actor:        operator(),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rotated MAC of %s at %s: version %s is live (read back and confirmed), previous version %s\n",
+		result.AgentID, result.MACSecretPath, result.CurrentVersion, result.PreviousVersion)
+	if result.PreviousPath != "" {
+		fmt.Printf("Previous MAC kept at %s; callbacks signed with it are accepted for %s\n", result.PreviousPath, domain.PreviousMACGracePeriod)
+	}
+	if newMAC == "" {
+		fmt.Println("A new MAC was generated; register it with EPX for this merchant")
+	}
+	return nil
+}
+
+func listAudit(ctx context.Context, out io.Writer, queries auditLogLister, filter auditFilter) error {
+	params, err := filter.params()
+	if err != nil {
+		return err
+	}
+
+	entries, err := queries.ListAuditLogs(ctx, params)
+	if err != nil {
+		return fmt.Errorf("list audit logs: %w", err)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tACTOR\tEVENT\tENTITY\tAGENT ID\tRESULT")
+	for _, entry := range entries {
+		actor := "-"
+		if entry.UserID.Valid {
+			actor = entry.UserID.String
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%s\t%s\n", entry.CreatedAt.Format(time.RFC3339), actor, entry.EventType,
+			entry.EntityType, entry.EntityID, entry.AgentID, auditResult(entry.Metadata))
+	}
+	return w.Flush()
+}
+
+// databaseURL builds the connection string from the same environment variables as the server
+func databaseURL() string {
+	return fmt.Sprintf(
+		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		getEnv("DB_USER", "postgres"),
+		getEnv("DB_PASSWORD", "postgres"),
+		getEnv("DB_HOST", "localhost"),
+		getEnv("DB_PORT", "5432"),
+		getEnv("DB_NAME", "payment_service"),
+		getEnv("DB_SSL_MODE", "disable"),
+	)
+}
+
+// parseTimeFlag parses a date or RFC 3339 time. A date given as the end of a range covers that whole day.
+func parseTimeFlag(flagName, value string, end bool) (pgtype.Timestamptz, error) {
+	if value == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		if end {
+			day = day.AddDate(0, 0, 1)
+		}
+		return pgtype.Timestamptz{Time: day, Valid: true}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return pgtype.Timestamptz{}, fmt.Errorf("-%s must be a date (2006-01-02) or RFC 3339 time", flagName)
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
+
+func requireFlags(flags map[string]string) {
+	for name, value := range flags {
+		if value == "" {
+			fatal(fmt.Errorf("-%s is required", name))
+		}
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
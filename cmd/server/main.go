@@ -7,37 +7,49 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/kevin07696/payment-service/internal/adapters/database"
 	"github.com/kevin07696/payment-service/internal/adapters/epx"
 	"github.com/kevin07696/payment-service/internal/adapters/north"
+	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
 	"github.com/kevin07696/payment-service/internal/adapters/secrets"
+	"github.com/kevin07696/payment-service/internal/domain"
 	agentHandler "github.com/kevin07696/payment-service/internal/handlers/agent"
 	chargebackHandler "github.com/kevin07696/payment-service/internal/handlers/chargeback"
 	cronHandler "github.com/kevin07696/payment-service/internal/handlers/cron"
 	paymentHandler "github.com/kevin07696/payment-service/internal/handlers/payment"
 	paymentmethodHandler "github.com/kevin07696/payment-service/internal/handlers/payment_method"
 	subscriptionHandler "github.com/kevin07696/payment-service/internal/handlers/subscription"
+	webhookHandler "github.com/kevin07696/payment-service/internal/handlers/webhook"
 	agentService "github.com/kevin07696/payment-service/internal/services/agent"
+	chargebackService "github.com/kevin07696/payment-service/internal/services/chargeback"
 	paymentService "github.com/kevin07696/payment-service/internal/services/payment"
 	paymentmethodService "github.com/kevin07696/payment-service/internal/services/payment_method"
+	"github.com/kevin07696/payment-service/internal/services/serviceauth"
 	subscriptionService "github.com/kevin07696/payment-service/internal/services/subscription"
 	webhookService "github.com/kevin07696/payment-service/internal/services/webhook"
 	"github.com/kevin07696/payment-service/pkg/middleware"
+	"github.com/kevin07696/payment-service/pkg/observability"
 	"github.com/kevin07696/payment-service/pkg/security"
 	agentv1 "github.com/kevin07696/payment-service/proto/agent/v1"
 	chargebackv1 "github.com/kevin07696/payment-service/proto/chargeback/v1"
 	paymentv1 "github.com/kevin07696/payment-service/proto/payment/v1"
 	paymentmethodv1 "github.com/kevin07696/payment-service/proto/payment_method/v1"
 	subscriptionv1 "github.com/kevin07696/payment-service/proto/subscription/v1"
+	webhookv1 "github.com/kevin07696/payment-service/proto/webhook/v1"
 )
 
 func main() {
@@ -52,6 +64,16 @@ func main() {
 	// Load configuration from environment
 	cfg := loadConfig(logger)
 
+	// Initialize tracing (spans are only exported when an OTLP endpoint is configured)
+	shutdownTracing, err := observability.InitTracing(context.Background(), observability.TracingConfig{
+		ServiceName: cfg.TracingServiceName,
+		Endpoint:    cfg.TracingEndpoint,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", zap.Error(err))
+	}
+
 	// Initialize database connection pool
 	dbPool, err := initDatabase(cfg, logger)
 	if err != nil {
@@ -67,12 +89,51 @@ func main() {
 	deps := initDependencies(dbPool, cfg, logger)
 
 	// Initialize gRPC server with interceptors
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			loggingInterceptor(logger),
-			recoveryInterceptor(logger),
-		),
-	)
+	interceptors := []grpc.UnaryServerInterceptor{
+		observability.TracingInterceptor(),
+		loggingInterceptor(logger),
+		recoveryInterceptor(logger),
+		observability.UnaryServerInterceptor(),
+	}
+	// Payment RPCs, cron jobs and Browser Post callbacks can be mid-way through an EPX call at shutdown;
+	// they're drained before the servers stop
+	drainer := middleware.NewDrainer(paymentv1.PaymentService_ServiceDesc.ServiceName)
+	interceptors = append(interceptors, drainer.UnaryServerInterceptor())
+	var serviceKeys middleware.ServiceKeyFunc
+	switch {
+	case cfg.AuthServiceKeysDir != "":
+		keys, err := middleware.LoadServiceKeys(cfg.AuthServiceKeysDir)
+		if err != nil {
+			logger.Fatal("Failed to load service keys", zap.Error(err))
+		}
+		serviceKeys = middleware.StaticServiceKeys(keys)
+		logger.Info("Service JWT authentication enabled", zap.String("key_source", "directory"), zap.Int("services", len(keys)))
+	case cfg.AuthKeySource == "database":
+		serviceKeys = deps.serviceRegistry.ActiveKeys
+		logger.Info("Service JWT authentication enabled", zap.String("key_source", "database"))
+	default:
+		logger.Warn("Service authentication not configured (AUTH_SERVICE_KEYS_DIR or AUTH_KEY_SOURCE=database), gRPC requests are not authenticated and the transaction status and export endpoints refuse every request")
+	}
+	// HTTP endpoints for calling services take the same service tokens; without them the handlers see no service and deny
+	serviceHTTPAuth := func(scope string, next http.HandlerFunc) http.HandlerFunc { return next }
+	if serviceKeys != nil {
+		serviceHTTPAuth = middleware.NewHTTPAuth(middleware.AuthConfig{
+			Keys:      serviceKeys,
+			ClockSkew: time.Duration(cfg.AuthClockSkewSeconds) * time.Second,
+		}, logger)
+		// Merchant grants come from the database whichever source the keys come from
+		interceptors = append(interceptors, middleware.NewAuthInterceptor(middleware.AuthConfig{
+			Keys:                serviceKeys,
+			ClockSkew:           time.Duration(cfg.AuthClockSkewSeconds) * time.Second,
+			MethodScopes:        methodScopes,
+			Access:              deps.serviceRegistry.HasAccess,
+			ResolveMerchant:     deps.requestMerchant,
+			MerchantlessMethods: merchantlessMethods,
+		}, logger))
+	}
+	interceptors = append(interceptors, deps.merchantRateLimiter.UnaryServerInterceptor())
+
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
 
 	// Register all gRPC services
 	paymentv1.RegisterPaymentServiceServer(grpcServer, deps.paymentHandler)
@@ -80,6 +141,10 @@ func main() {
 	paymentmethodv1.RegisterPaymentMethodServiceServer(grpcServer, deps.paymentMethodHandler)
 	agentv1.RegisterAgentServiceServer(grpcServer, deps.agentHandler)
 	chargebackv1.RegisterChargebackServiceServer(grpcServer, deps.chargebackHandler)
+	webhookv1.RegisterWebhookServiceServer(grpcServer, deps.webhookHandler)
+
+	// Register the standard gRPC health service (static liveness: SERVING while the process is up)
+	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
 
 	// Register reflection service (for tools like grpcurl)
 	reflection.Register(grpcServer)
@@ -91,15 +156,36 @@ func main() {
 	// Adjust these values based on expected staging traffic
 	rateLimiter := middleware.NewRateLimiter(10, 20)
 
-	// Cron endpoints
-	httpMux.HandleFunc("/cron/process-billing", deps.billingCronHandler.ProcessBilling)
-	httpMux.HandleFunc("/cron/sync-disputes", deps.disputeSyncCronHandler.SyncDisputes)
+	// Cron endpoints (each job runs on one instance at a time)
+	cronJob := func(job string, next http.HandlerFunc) http.HandlerFunc {
+		return drainer.HTTPHandlerFunc(observability.CronHandler(job, cronHandler.Exclusive(deps.cronJobLocker, job, cfg.CronSecret, logger, next)))
+	}
+	httpMux.HandleFunc("/cron/process-billing", cronJob("process-billing", deps.billingCronHandler.ProcessBilling))
+	httpMux.HandleFunc("/cron/sync-disputes", cronJob("sync-disputes", deps.disputeSyncCronHandler.SyncDisputes))
+	httpMux.HandleFunc("/cron/reconcile", cronJob("reconcile", deps.reconciliationCronHandler.Reconcile))
+	httpMux.HandleFunc("/cron/expiring-cards", cronJob("expiring-cards", deps.expiringCardsCronHandler.NotifyExpiringCards))
+	httpMux.HandleFunc("/cron/chargeback-deadlines", cronJob("chargeback-deadlines", deps.chargebackDeadlineCronHandler.ProcessDeadlines))
+	httpMux.HandleFunc("/cron/auth-auto-void", cronJob("auth-auto-void", deps.authAutoVoidCronHandler.VoidExpiredAuthorizations))
+	httpMux.HandleFunc("/cron/retry-webhooks", cronJob("retry-webhooks", deps.webhookRetryCronHandler.RetryWebhooks))
+	httpMux.HandleFunc("/cron/webhooks/dead-letter", deps.webhookRetryCronHandler.ListDeadLetters)
 	httpMux.HandleFunc("/cron/health", deps.billingCronHandler.HealthCheck)
 	httpMux.HandleFunc("/cron/stats", deps.billingCronHandler.Stats)
 
+	// Prometheus metrics
+	httpMux.Handle("/metrics", promhttp.Handler())
+
+	// Readiness probe (downstream dependencies); liveness is the static gRPC health service
+	httpMux.HandleFunc("/ready", deps.healthChecker.HealthHandler())
+
 	// Browser Post endpoints (with rate limiting)
-	httpMux.HandleFunc("/api/v1/payments/browser-post/form", rateLimiter.HTTPHandlerFunc(deps.browserPostCallbackHandler.GetPaymentForm))
-	httpMux.HandleFunc("/api/v1/payments/browser-post/callback", rateLimiter.HTTPHandlerFunc(deps.browserPostCallbackHandler.HandleCallback))
+	httpMux.HandleFunc("/api/v1/payments/browser-post/form", rateLimiter.HTTPHandlerFunc(drainer.HTTPHandlerFunc(deps.browserPostCallbackHandler.GetPaymentForm)))
+	httpMux.HandleFunc("/api/v1/payments/browser-post/callback", rateLimiter.HTTPHandlerFunc(drainer.HTTPHandlerFunc(deps.browserPostCallbackHandler.HandleCallback)))
+
+	// Transaction status for Browser Post clients re-fetching the result after the redirect
+	httpMux.HandleFunc("GET /api/v1/payments/{id}/status", drainer.HTTPHandlerFunc(serviceHTTPAuth(scopePaymentRead, deps.transactionStatusHandler.GetStatus)))
+
+	// CSV export of a merchant's transactions for bookkeeping
+	httpMux.HandleFunc("GET /api/v1/transactions/export", drainer.HTTPHandlerFunc(serviceHTTPAuth(scopePaymentRead, deps.transactionExportHandler.Export)))
 
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.HTTPPort),
@@ -133,6 +219,14 @@ func main() {
 		}
 	}()
 
+	// Flush batched webhooks whose interval has elapsed
+	batchCtx, stopBatches := context.WithCancel(context.Background())
+	batchesDone := make(chan struct{})
+	go func() {
+		deps.webhookService.RunBatchFlusher(batchCtx, time.Second)
+		close(batchesDone)
+	}()
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -140,6 +234,14 @@ func main() {
 
 	logger.Info("Shutting down servers...")
 
+	// Turn away new payment RPCs and HTTP requests and let in-flight ones finish, so a capture or a billing run
+	// isn't cut off after EPX approved it
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownDrainSeconds)*time.Second)
+	if err := drainer.Drain(drainCtx); err != nil {
+		logger.Error("In-flight payment operations did not finish before the drain timeout", zap.Error(err))
+	}
+	cancelDrain()
+
 	// Graceful shutdown
 	grpcServer.GracefulStop()
 
@@ -150,6 +252,18 @@ func main() {
 		logger.Error("HTTP server shutdown error", zap.Error(err))
 	}
 
+	// Stop flushing webhook batches; events still waiting to fill stay queued in the database
+	stopBatches()
+	<-batchesDone
+
+	// Nothing sends to EPX any more; close the pooled socket connections
+	deps.serverPost.Close()
+
+	// Flush buffered spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Tracing shutdown error", zap.Error(err))
+	}
+
 	logger.Info("Servers stopped")
 }
 
@@ -168,35 +282,92 @@ type Config struct {
 	MaxConns   int32
 	MinConns   int32
 
+	// Startup connection retries, for when Postgres comes up after the service
+	DBConnectAttempts       int // Pings before giving up (1 = no retries)
+	DBConnectMaxWaitSeconds int // Longest backoff between pings
+
 	// EPX Payment Gateway (Server Post API for transactions)
-	EPXServerPostURL string // EPX Server Post API URL (e.g., https://secure.epxuap.com)
-	EPXTimeout       int
-	EPXCustNbr       string // EPX Customer Number
-	EPXMerchNbr      string // EPX Merchant Number
-	EPXDBAnbr        string // EPX DBA Number
-	EPXTerminalNbr   string // EPX Terminal Number
+	EPXServerPostURL  string // EPX Server Post API URL (e.g., https://secure.epxuap.com)
+	EPXTimeout        int
+	EPXCustNbr        string // EPX Customer Number
+	EPXMerchNbr       string // EPX Merchant Number
+	EPXDBAnbr         string // EPX DBA Number
+	EPXTerminalNbr    string // EPX Terminal Number
+	EPXKeyExchangeURL string // EPX Key Exchange URL (empty = environment default)
+
+	EPXSoftDescriptorField string // Server Post field for statement descriptors, as confirmed by EPX (empty = not sent)
 
 	// North Merchant Reporting API (for disputes/chargebacks, NOT payments)
 	NorthMerchantReportingURL string // North Reporting API URL (e.g., https://api.north.com)
 	NorthTimeout              int
 
 	// Browser Post Configuration
-	CallbackBaseURL string // Base URL for Browser Post callbacks (e.g., "http://localhost:8081")
+	CallbackBaseURL string   // Base URL for Browser Post callbacks (e.g., "http://localhost:8081")
+	DevReturnHosts  []string // Browser Post return_url hosts allowed for every merchant (development only)
+
+	BrowserPostTACValidityMinutes   int // How long an EPX TAC (and so a sale form) stays valid
+	BrowserPostCallbackGraceMinutes int // How late after the TAC expires a callback is still accepted
 
 	// Cron authentication
 	CronSecret string
+
+	// Subscription billing runs
+	BillingConcurrency      int     // Subscriptions billed at once
+	BillingMaxPerRun        int     // Most subscriptions billed per run
+	BillingChargesPerSecond float64 // EPX charges started per second (0 = unlimited)
+
+	// Readiness probe: secret read to verify secret manager access (empty = check skipped)
+	ReadinessSecretPath string
+
+	// Fee estimation (JSON array of {card_brand, funding_type, percent, fixed}; empty = built-in defaults)
+	FeeScheduleJSON string
+
+	// Fraud policy for charges the scorer can't decide ("approve", "review" or "decline"; empty = decline / review)
+	FraudOnUnknownDecision string
+	FraudOnScorerError     string
+
+	// OpenTelemetry tracing
+	TracingEndpoint    string  // OTLP/gRPC collector URL (e.g., http://otel-collector:4317); empty disables export
+	TracingServiceName string  // service.name resource attribute
+	TracingSampleRatio float64 // Fraction of new traces sampled (0-1)
+
+	// Service JWT authentication
+	AuthServiceKeysDir   string // Directory of <service_id>.pem public keys (one key per service)
+	AuthKeySource        string // "database" verifies tokens with the services' active keys managed by the admin CLI
+	AuthClockSkewSeconds int    // Tolerance applied to token exp/nbf/iat
+
+	// Outbound TLS policy for EPX, North and webhook connections
+	OutboundTLSMinVersion string // "1.2" (default) or "1.3"
+
+	// Shutdown: how long in-flight payment RPCs get to finish once new ones are rejected
+	ShutdownDrainSeconds int
 }
 
 // Dependencies holds all initialized services and handlers
 type Dependencies struct {
-	paymentHandler             paymentv1.PaymentServiceServer
-	subscriptionHandler        subscriptionv1.SubscriptionServiceServer
-	paymentMethodHandler       paymentmethodv1.PaymentMethodServiceServer
-	agentHandler               agentv1.AgentServiceServer
-	chargebackHandler          chargebackv1.ChargebackServiceServer
-	billingCronHandler         *cronHandler.BillingHandler
-	disputeSyncCronHandler     *cronHandler.DisputeSyncHandler
-	browserPostCallbackHandler *paymentHandler.BrowserPostCallbackHandler
+	paymentHandler                paymentv1.PaymentServiceServer
+	subscriptionHandler           subscriptionv1.SubscriptionServiceServer
+	paymentMethodHandler          paymentmethodv1.PaymentMethodServiceServer
+	agentHandler                  agentv1.AgentServiceServer
+	chargebackHandler             chargebackv1.ChargebackServiceServer
+	webhookHandler                webhookv1.WebhookServiceServer
+	billingCronHandler            *cronHandler.BillingHandler
+	disputeSyncCronHandler        *cronHandler.DisputeSyncHandler
+	reconciliationCronHandler     *cronHandler.ReconciliationHandler
+	expiringCardsCronHandler      *cronHandler.ExpiringCardsHandler
+	chargebackDeadlineCronHandler *cronHandler.ChargebackDeadlineHandler
+	authAutoVoidCronHandler       *cronHandler.AuthAutoVoidHandler
+	webhookRetryCronHandler       *cronHandler.WebhookRetryHandler
+	browserPostCallbackHandler    *paymentHandler.BrowserPostCallbackHandler
+	transactionStatusHandler      *paymentHandler.TransactionStatusHandler
+	transactionExportHandler      *paymentHandler.TransactionExportHandler
+	healthChecker                 *observability.HealthChecker
+	merchantRateLimiter           *middleware.MerchantRateLimiter
+	webhookService                *webhookService.WebhookDeliveryService
+	serverPost                    adapterports.ServerPostAdapter
+	serviceRegistry               *serviceauth.Registry
+	requestMerchant               middleware.MerchantResolverFunc
+	cronJobLocker                 cronHandler.JobLocker
 }
 
 // loadConfig loads configuration from environment variables
@@ -212,17 +383,40 @@ func loadConfig(logger *zap.Logger) *Config {
 		DBSSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		MaxConns:   int32(getEnvInt("DB_MAX_CONNS", 25)),
 		MinConns:   int32(getEnvInt("DB_MIN_CONNS", 5)),
+
+		DBConnectAttempts:       getEnvInt("DB_CONNECT_ATTEMPTS", 10),
+		DBConnectMaxWaitSeconds: getEnvInt("DB_CONNECT_MAX_WAIT_SECONDS", 30),
 		// Try new variable name first, fallback to old name for backwards compatibility
-		EPXServerPostURL:          getEnvWithFallback("EPX_SERVER_POST_URL", "EPX_BASE_URL", "https://sandbox.north.com"),
-		EPXTimeout:                getEnvInt("EPX_TIMEOUT", 30),
-		EPXCustNbr:                getEnv("EPX_CUST_NBR", "9001"),    // EPX sandbox customer number
-		EPXMerchNbr:               getEnv("EPX_MERCH_NBR", "900300"), // EPX sandbox merchant number
-		EPXDBAnbr:                 getEnv("EPX_DBA_NBR", "2"),        // EPX sandbox DBA number
-		EPXTerminalNbr:            getEnv("EPX_TERMINAL_NBR", "77"),  // EPX sandbox terminal number
-		NorthMerchantReportingURL: getEnvWithFallback("NORTH_MERCHANT_REPORTING_URL", "NORTH_API_URL", "https://api.north.com"),
-		NorthTimeout:              getEnvInt("NORTH_TIMEOUT", 30),
-		CallbackBaseURL:           getEnv("CALLBACK_BASE_URL", "http://localhost:8081"),
-		CronSecret:                getEnv("CRON_SECRET", "change-me-in-production"),
+		EPXServerPostURL:                getEnvWithFallback("EPX_SERVER_POST_URL", "EPX_BASE_URL", "https://sandbox.north.com"),
+		EPXTimeout:                      getEnvInt("EPX_TIMEOUT", 30),
+		EPXCustNbr:                      getEnv("EPX_CUST_NBR", "9001"),    // EPX sandbox customer number
+		EPXMerchNbr:                     getEnv("EPX_MERCH_NBR", "900300"), // EPX sandbox merchant number
+		EPXDBAnbr:                       getEnv("EPX_DBA_NBR", "2"),        // EPX sandbox DBA number
+		EPXTerminalNbr:                  getEnv("EPX_TERMINAL_NBR", "77"),  // EPX sandbox terminal number
+		EPXKeyExchangeURL:               getEnv("EPX_KEY_EXCHANGE_URL", ""),
+		EPXSoftDescriptorField:          getEnv("EPX_SOFT_DESCRIPTOR_FIELD", ""),
+		NorthMerchantReportingURL:       getEnvWithFallback("NORTH_MERCHANT_REPORTING_URL", "NORTH_API_URL", "https://api.north.com"),
+		NorthTimeout:                    getEnvInt("NORTH_TIMEOUT", 30),
+		CallbackBaseURL:                 getEnv("CALLBACK_BASE_URL", "http://localhost:8081"),
+		DevReturnHosts:                  getEnvList("BROWSER_POST_DEV_RETURN_HOSTS"),
+		BrowserPostTACValidityMinutes:   getEnvInt("BROWSER_POST_TAC_VALIDITY_MINUTES", 240),
+		BrowserPostCallbackGraceMinutes: getEnvInt("BROWSER_POST_CALLBACK_GRACE_MINUTES", 30),
+		CronSecret:                      getEnv("CRON_SECRET", "change-me-in-production"),
+		BillingConcurrency:              getEnvInt("BILLING_CONCURRENCY", 4),
+		BillingMaxPerRun:                getEnvInt("BILLING_MAX_PER_RUN", 1000),
+		BillingChargesPerSecond:         getEnvFloat("BILLING_CHARGES_PER_SECOND", 10),
+		ReadinessSecretPath:             getEnv("READINESS_SECRET_PATH", ""),
+		FeeScheduleJSON:                 getEnv("FEE_SCHEDULE_JSON", ""),
+		FraudOnUnknownDecision:          getEnv("FRAUD_ON_UNKNOWN_DECISION", ""),
+		FraudOnScorerError:              getEnv("FRAUD_ON_SCORER_ERROR", ""),
+		TracingEndpoint:                 getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		TracingServiceName:              getEnv("OTEL_SERVICE_NAME", "payment-service"),
+		TracingSampleRatio:              getEnvFloat("OTEL_TRACES_SAMPLE_RATIO", 1.0),
+		AuthServiceKeysDir:              getEnv("AUTH_SERVICE_KEYS_DIR", ""),
+		AuthKeySource:                   getEnv("AUTH_KEY_SOURCE", ""),
+		AuthClockSkewSeconds:            getEnvInt("AUTH_CLOCK_SKEW_SECONDS", 60),
+		OutboundTLSMinVersion:           getEnv("OUTBOUND_TLS_MIN_VERSION", "1.2"),
+		ShutdownDrainSeconds:            getEnvInt("SHUTDOWN_DRAIN_SECONDS", 20),
 	}
 
 	logger.Info("Configuration loaded",
@@ -231,30 +425,129 @@ func loadConfig(logger *zap.Logger) *Config {
 		zap.Int("db_port", cfg.DBPort),
 		zap.String("epx_server_post_url", cfg.EPXServerPostURL),
 		zap.String("north_merchant_reporting_url", cfg.NorthMerchantReportingURL),
+		zap.String("otel_exporter_otlp_endpoint", cfg.TracingEndpoint),
 	)
 
 	return cfg
 }
 
-// initLogger initializes the logger
+// initLogger initializes the logger. Card data, BRICs and secrets are sanitized in every log field.
 func initLogger() *zap.Logger {
 	env := getEnv("ENVIRONMENT", "development")
+	redact := zap.WrapCore(security.RedactingCore)
 
 	if env == "production" {
 		zapCfg := zap.NewProductionConfig()
 		zapCfg.Level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
-		logger, _ := zapCfg.Build()
+		logger, _ := zapCfg.Build(redact)
 		return logger
 	}
 
-	logger, _ := zap.NewDevelopment()
+	logger, _ := zap.NewDevelopment(redact)
 	return logger
 }
 
-// initDatabase initializes the PostgreSQL connection pool
+// dbPingTimeout bounds each startup ping, so an unreachable host doesn't use up the whole retry budget
+const dbPingTimeout = 10 * time.Second
+
+// pinger is the part of the connection pool waitForDatabase needs
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// dbRetryPolicy is how long startup waits for the database: the backoff doubles from InitialWait up to MaxWait
+type dbRetryPolicy struct {
+	Attempts    int
+	InitialWait time.Duration
+	MaxWait     time.Duration
+}
+
+// waitForDatabase pings until the database answers or the attempts run out, returning the last ping error
+func waitForDatabase(ctx context.Context, db pinger, policy dbRetryPolicy, logger *zap.Logger) error {
+	wait := policy.InitialWait
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, dbPingTimeout)
+		err := db.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt >= policy.Attempts {
+			return fmt.Errorf("ping database after %d attempts: %w", attempt, err)
+		}
+
+		logger.Warn("Database not ready, retrying",
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", policy.Attempts),
+			zap.Duration("retry_in", wait),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("ping database: %w", ctx.Err())
+		case <-time.After(wait):
+		}
+		if wait *= 2; wait > policy.MaxWait {
+			wait = policy.MaxWait
+		}
+	}
+}
+
+// checkEPXEndpoints returns an error when any EPX endpoint belongs to the other environment than epxEnv,
+// so a deployment can't send production credentials to the sandbox or sandbox ones to production
+func checkEPXEndpoints(epxEnv domain.Environment, endpoints map[string]string) error {
+	names := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := epx.ValidateEnvironmentURL(string(epxEnv), endpoints[name]); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// merchantLister is the part of the agent service checkMerchantEnvironments needs
+type merchantLister interface {
+	ListAgents(ctx context.Context, environment *domain.Environment, isActive *bool, limit, offset int) ([]*domain.Agent, int, error)
+}
+
+// merchantEnvironmentPageSize is how many merchants checkMerchantEnvironments reads at a time
+const merchantEnvironmentPageSize = 100
+
+// checkMerchantEnvironments logs an error for every active merchant whose environment doesn't match epxEnv and
+// returns their IDs. Their payments are refused (domain.ErrEnvironmentMismatch); the other merchants are unaffected,
+// so startup carries on.
+func checkMerchantEnvironments(ctx context.Context, merchants merchantLister, epxEnv domain.Environment, logger *zap.Logger) ([]string, error) {
+	active := true
+	var mismatched []string
+	for offset := 0; ; offset += merchantEnvironmentPageSize {
+		page, total, err := merchants.ListAgents(ctx, nil, &active, merchantEnvironmentPageSize, offset)
+		if err != nil {
+			return mismatched, fmt.Errorf("list merchants: %w", err)
+		}
+		for _, merchant := range page {
+			if err := merchant.Environment.CheckEPX(epxEnv); err != nil {
+				logger.Error("Merchant environment doesn't match the EPX environment; its payments will be refused",
+					zap.String("agent_id", merchant.AgentID),
+					zap.String("merchant_environment", string(merchant.Environment)),
+					zap.String("epx_environment", string(epxEnv)),
+				)
+				mismatched = append(mismatched, merchant.AgentID)
+			}
+		}
+		if len(page) == 0 || offset+len(page) >= total {
+			return mismatched, nil
+		}
+	}
+}
+
+// initDatabase initializes the PostgreSQL connection pool, waiting for the database if it isn't up yet
 func initDatabase(cfg *Config, logger *zap.Logger) (*pgxpool.Pool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	ctx := context.Background()
 
 	connString := fmt.Sprintf(
 		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
@@ -279,10 +572,14 @@ func initDatabase(cfg *Config, logger *zap.Logger) (*pgxpool.Pool, error) {
 		return nil, fmt.Errorf("create connection pool: %w", err)
 	}
 
-	// Verify connection
-	if err := pool.Ping(ctx); err != nil {
+	// Verify connection (the pool connects lazily, so this is where a database that isn't up yet shows)
+	if err := waitForDatabase(ctx, pool, dbRetryPolicy{
+		Attempts:    cfg.DBConnectAttempts,
+		InitialWait: time.Second,
+		MaxWait:     time.Duration(cfg.DBConnectMaxWaitSeconds) * time.Second,
+	}, logger); err != nil {
 		pool.Close()
-		return nil, fmt.Errorf("ping database: %w", err)
+		return nil, err
 	}
 
 	return pool, nil
@@ -309,50 +606,123 @@ func initDependencies(dbPool *pgxpool.Pool, cfg *Config, logger *zap.Logger) *De
 	}
 
 	// Initialize EPX adapters with environment-specific configuration
-	epxEnv := "sandbox"
-	if getEnv("ENVIRONMENT", "development") == "production" {
-		epxEnv = "production"
+	epxEnv := domain.EPXEnvironmentFor(getEnv("ENVIRONMENT", "development"))
+
+	// Minimum TLS version for every outbound payment connection
+	minTLSVersion, err := security.ParseTLSVersion(cfg.OutboundTLSMinVersion)
+	if err != nil {
+		logger.Fatal("Invalid OUTBOUND_TLS_MIN_VERSION", zap.Error(err))
 	}
 
 	// Server Post adapter configuration
-	serverPostCfg := epx.DefaultServerPostConfig(epxEnv)
+	serverPostCfg := epx.DefaultServerPostConfig(string(epxEnv))
 	serverPostCfg.BaseURL = cfg.EPXServerPostURL // Override with env var
+	serverPostCfg.Timeout = time.Duration(cfg.EPXTimeout) * time.Second
+	serverPostCfg.MinTLSVersion = minTLSVersion
+	serverPostCfg.SoftDescriptorField = cfg.EPXSoftDescriptorField
 	serverPost := epx.NewServerPostAdapter(serverPostCfg, logger)
 
 	// Browser Post adapter configuration
-	browserPostCfg := epx.DefaultBrowserPostConfig(epxEnv)
+	browserPostCfg := epx.DefaultBrowserPostConfig(string(epxEnv))
 	browserPostCfg.PostURL = cfg.EPXServerPostURL + "/browserpost" // Derived from Server Post URL
 	browserPost := epx.NewBrowserPostAdapter(browserPostCfg, logger)
 
 	// BRIC Storage adapter configuration
-	bricStorageCfg := epx.DefaultBRICStorageConfig(epxEnv)
+	bricStorageCfg := epx.DefaultBRICStorageConfig(string(epxEnv))
 	bricStorageCfg.BaseURL = cfg.EPXServerPostURL // Same as Server Post
+	bricStorageCfg.MinTLSVersion = minTLSVersion
 	bricStorage := epx.NewBRICStorageAdapter(bricStorageCfg, logger)
 
+	// Key Exchange adapter (only used for readiness checks on this server)
+	keyExchangeCfg := epx.DefaultKeyExchangeConfig(string(epxEnv))
+	keyExchangeCfg.MinTLSVersion = minTLSVersion
+	if cfg.EPXKeyExchangeURL != "" {
+		keyExchangeCfg.BaseURL = cfg.EPXKeyExchangeURL
+	}
+	keyExchange := epx.NewKeyExchangeAdapter(keyExchangeCfg, logger)
+
+	// Production credentials must never reach the sandbox, nor sandbox ones production
+	if err := checkEPXEndpoints(epxEnv, map[string]string{
+		"EPX_SERVER_POST_URL":  serverPostCfg.BaseURL, // Browser Post and BRIC Storage share its host
+		"EPX_KEY_EXCHANGE_URL": keyExchangeCfg.BaseURL,
+	}); err != nil {
+		logger.Fatal("EPX endpoint doesn't match ENVIRONMENT", zap.String("epx_environment", string(epxEnv)), zap.Error(err))
+	}
+
 	// Initialize secret manager (using local file system for development)
 	secretManager := secrets.NewLocalSecretManager("./secrets", logger)
 
+	// Readiness checks: database ping, EPX reachability and secret manager access
+	healthChecker := observability.NewHealthChecker(dbPool).
+		AddCheck("epx_key_exchange", keyExchange.Ping)
+	if cfg.ReadinessSecretPath != "" {
+		healthChecker.AddCheck("secret_manager", func(ctx context.Context) error {
+			_, err := secretManager.GetSecret(ctx, cfg.ReadinessSecretPath)
+			return err
+		})
+	}
+
 	// Initialize North merchant reporting adapter
 	merchantReportingCfg := &north.MerchantReportingConfig{
 		BaseURL: cfg.NorthMerchantReportingURL,
 		Timeout: time.Duration(cfg.NorthTimeout) * time.Second,
 	}
-	httpClient := &http.Client{Timeout: time.Duration(cfg.NorthTimeout) * time.Second}
+	httpClient := &http.Client{
+		Timeout:   time.Duration(cfg.NorthTimeout) * time.Second,
+		Transport: security.NewHTTPTransport(minTLSVersion),
+	}
 	loggerAdapter := security.NewZapLogger(logger)
 	merchantReporting := north.NewMerchantReportingAdapter(merchantReportingCfg, httpClient, loggerAdapter)
 
+	// Initialize webhook delivery service
+	webhookClient := webhookService.NewDeliveryClient(security.NewHTTPTransport(minTLSVersion), 10*time.Second)
+	webhookSvc := webhookService.NewWebhookDeliveryService(dbAdapter, webhookClient, logger)
+
+	// Fee schedule for estimated processing fees
+	feeSchedule := domain.DefaultFeeSchedule()
+	if cfg.FeeScheduleJSON != "" {
+		feeSchedule, err = domain.ParseFeeSchedule([]byte(cfg.FeeScheduleJSON))
+		if err != nil {
+			logger.Fatal("Failed to parse fee schedule", zap.Error(err))
+		}
+	}
+
+	// Fraud policy for charges the fraud scorer can't decide
+	fraudPolicy := paymentService.FraudPolicy{
+		OnUnknownDecision: adapterports.FraudDecision(cfg.FraudOnUnknownDecision),
+		OnScorerError:     adapterports.FraudDecision(cfg.FraudOnScorerError),
+	}
+	for _, decision := range []adapterports.FraudDecision{fraudPolicy.OnUnknownDecision, fraudPolicy.OnScorerError} {
+		if decision != "" && !decision.Valid() {
+			logger.Fatal("Invalid fraud policy decision", zap.String("decision", string(decision)))
+		}
+	}
+
 	// Initialize services
 	paymentSvc := paymentService.NewPaymentService(
 		dbAdapter,
 		serverPost,
+		epxEnv,
 		secretManager,
+		webhookSvc,
+		feeSchedule,
+		nil, // No fraud scoring service configured; every charge is approved
+		fraudPolicy,
 		logger,
 	)
 
 	subscriptionSvc := subscriptionService.NewSubscriptionService(
 		dbAdapter,
 		serverPost,
+		merchantReporting,
+		epxEnv,
 		secretManager,
+		webhookSvc,
+		subscriptionService.BillingConfig{
+			Concurrency:      cfg.BillingConcurrency,
+			MaxPerRun:        cfg.BillingMaxPerRun,
+			ChargesPerSecond: cfg.BillingChargesPerSecond,
+		},
 		logger,
 	)
 
@@ -361,6 +731,7 @@ func initDependencies(dbPool *pgxpool.Pool, cfg *Config, logger *zap.Logger) *De
 		browserPost,
 		serverPost,
 		bricStorage,
+		epxEnv,
 		secretManager,
 		logger,
 	)
@@ -371,25 +742,45 @@ func initDependencies(dbPool *pgxpool.Pool, cfg *Config, logger *zap.Logger) *De
 		logger,
 	)
 
-	// Initialize webhook delivery service
-	webhookSvc := webhookService.NewWebhookDeliveryService(dbAdapter, nil, logger)
+	// Merchants of the other environment can't transact through these adapters; flag them now rather than at their first payment
+	if _, err := checkMerchantEnvironments(context.Background(), agentSvc, epxEnv, logger); err != nil {
+		logger.Warn("Failed to check merchant environments", zap.Error(err))
+	}
+
+	// Per-merchant request limits come from each merchant's effective config (tier default or override)
+	merchantRateLimiter := middleware.NewMerchantRateLimiter(func(ctx context.Context, merchantID string) (float64, int, error) {
+		config, err := agentSvc.GetEffectiveConfig(ctx, merchantID)
+		if err != nil {
+			return 0, 0, err
+		}
+		return config.RequestsPerSecond, config.BurstLimit, nil
+	})
 
 	// Initialize handlers
 	paymentHdlr := paymentHandler.NewHandler(paymentSvc, logger)
-	subscriptionHdlr := subscriptionHandler.NewHandler(subscriptionSvc, logger)
+	subscriptionHdlr := subscriptionHandler.NewHandler(subscriptionSvc, dbAdapter, logger)
 	paymentMethodHdlr := paymentmethodHandler.NewHandler(paymentMethodSvc, logger)
 	agentHdlr := agentHandler.NewHandler(agentSvc, logger)
-	chargebackHdlr := chargebackHandler.NewHandler(dbAdapter, logger)
+	chargebackEvidenceSvc := chargebackService.NewEvidenceService(dbAdapter, logger)
+	chargebackHdlr := chargebackHandler.NewHandler(dbAdapter, chargebackEvidenceSvc, logger)
+	webhookHdlr := webhookHandler.NewHandler(webhookSvc, logger)
 
 	// Initialize cron handlers (for HTTP endpoints)
-	billingCronHdlr := cronHandler.NewBillingHandler(subscriptionSvc, logger, cfg.CronSecret)
+	billingCronHdlr := cronHandler.NewBillingHandler(subscriptionSvc, dbAdapter, logger, cfg.CronSecret)
 	disputeSyncCronHdlr := cronHandler.NewDisputeSyncHandler(merchantReporting, dbAdapter, webhookSvc, logger, cfg.CronSecret)
+	reconciliationCronHdlr := cronHandler.NewReconciliationHandler(merchantReporting, dbAdapter, logger, cfg.CronSecret)
+	expiringCardsCronHdlr := cronHandler.NewExpiringCardsHandler(dbAdapter, webhookSvc, logger, cfg.CronSecret)
+	chargebackDeadlineCronHdlr := cronHandler.NewChargebackDeadlineHandler(dbAdapter, webhookSvc, logger, cfg.CronSecret)
+	authAutoVoidCronHdlr := cronHandler.NewAuthAutoVoidHandler(paymentSvc, dbAdapter, logger, cfg.CronSecret)
+	webhookRetryCronHdlr := cronHandler.NewWebhookRetryHandler(webhookSvc, logger, cfg.CronSecret)
 
 	// Initialize Browser Post callback handler
 	browserPostCallbackHdlr := paymentHandler.NewBrowserPostCallbackHandler(
 		dbAdapter,
 		browserPost,
 		paymentMethodSvc,
+		paymentSvc,
+		secretManager, // Merchant MAC secrets for callback MAC verification
 		logger,
 		browserPostCfg.PostURL, // EPX Browser Post endpoint URL
 		cfg.EPXCustNbr,         // EPX Customer Number
@@ -397,17 +788,42 @@ func initDependencies(dbPool *pgxpool.Pool, cfg *Config, logger *zap.Logger) *De
 		cfg.EPXDBAnbr,          // EPX DBA Number
 		cfg.EPXTerminalNbr,     // EPX Terminal Number
 		cfg.CallbackBaseURL,    // Base URL for callbacks
+		epxEnv,                 // EPX environment merchants must match; production requires https return URLs
+		cfg.DevReturnHosts,     // return_url hosts allowed for every merchant
+		paymentHandler.PendingExpiryConfig{
+			TACValidity:   time.Duration(cfg.BrowserPostTACValidityMinutes) * time.Minute,
+			CallbackGrace: time.Duration(cfg.BrowserPostCallbackGraceMinutes) * time.Minute,
+		},
 	)
 
+	serviceRegistry := serviceauth.NewRegistry(dbAdapter.Queries(), logger)
+	transactionStatusHdlr := paymentHandler.NewTransactionStatusHandler(paymentSvc, paymentMethodSvc, serviceRegistry.HasAccess, logger)
+	transactionExportHdlr := paymentHandler.NewTransactionExportHandler(paymentSvc, serviceRegistry.HasAccess, logger)
+
 	return &Dependencies{
-		paymentHandler:             paymentHdlr,
-		subscriptionHandler:        subscriptionHdlr,
-		paymentMethodHandler:       paymentMethodHdlr,
-		agentHandler:               agentHdlr,
-		chargebackHandler:          chargebackHdlr,
-		billingCronHandler:         billingCronHdlr,
-		disputeSyncCronHandler:     disputeSyncCronHdlr,
-		browserPostCallbackHandler: browserPostCallbackHdlr,
+		paymentHandler:                paymentHdlr,
+		subscriptionHandler:           subscriptionHdlr,
+		paymentMethodHandler:          paymentMethodHdlr,
+		agentHandler:                  agentHdlr,
+		chargebackHandler:             chargebackHdlr,
+		webhookHandler:                webhookHdlr,
+		billingCronHandler:            billingCronHdlr,
+		disputeSyncCronHandler:        disputeSyncCronHdlr,
+		reconciliationCronHandler:     reconciliationCronHdlr,
+		expiringCardsCronHandler:      expiringCardsCronHdlr,
+		chargebackDeadlineCronHandler: chargebackDeadlineCronHdlr,
+		authAutoVoidCronHandler:       authAutoVoidCronHdlr,
+		webhookRetryCronHandler:       webhookRetryCronHdlr,
+		browserPostCallbackHandler:    browserPostCallbackHdlr,
+		transactionStatusHandler:      transactionStatusHdlr,
+		transactionExportHandler:      transactionExportHdlr,
+		healthChecker:                 healthChecker,
+		merchantRateLimiter:           merchantRateLimiter,
+		webhookService:                webhookSvc,
+		serverPost:                    serverPost,
+		serviceRegistry:               serviceRegistry,
+		requestMerchant:               serviceauth.RequestMerchant(dbAdapter.Queries()),
+		cronJobLocker:                 dbAdapter,
 	}
 }
 
@@ -482,6 +898,26 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		var floatValue float64
+		fmt.Sscanf(value, "%g", &floatValue)
+		return floatValue
+	}
+	return defaultValue
+}
+
+// getEnvList splits a comma-separated variable, dropping empty entries (nil when unset)
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // getEnvWithFallback tries the primary key first, then fallback key, then default value
 // This provides backwards compatibility when renaming environment variables
 func getEnvWithFallback(primaryKey, fallbackKey, defaultValue string) string {
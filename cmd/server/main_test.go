@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// fakePinger fails its first failures pings, then succeeds
+type fakePinger struct {
+	failures int
+	calls    int
+}
+
+func (f *fakePinger) Ping(ctx context.Context) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestWaitForDatabase(t *testing.T) {
+	policy := dbRetryPolicy{Attempts: 5, InitialWait: time.Millisecond, MaxWait: 2 * time.Millisecond}
+
+	t.Run("retries until the database is up", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		db := &fakePinger{failures: 2}
+
+		require.NoError(t, waitForDatabase(context.Background(), db, policy, zap.New(core)))
+		assert.Equal(t, 3, db.calls)
+
+		retries := logs.FilterMessage("Database not ready, retrying").All()
+		require.Len(t, retries, 2, "each retry is logged")
+		assert.Equal(t, int64(1), retries[0].ContextMap()["attempt"])
+		assert.Equal(t, int64(2), retries[1].ContextMap()["attempt"])
+	})
+
+	t.Run("gives up after the configured attempts", func(t *testing.T) {
+		db := &fakePinger{failures: 10}
+
+		err := waitForDatabase(context.Background(), db, policy, zap.NewNop())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "after 5 attempts")
+		assert.Equal(t, 5, db.calls)
+	})
+
+	t.Run("one attempt means no retries", func(t *testing.T) {
+		db := &fakePinger{failures: 1}
+
+		require.Error(t, waitForDatabase(context.Background(), db, dbRetryPolicy{Attempts: 1}, zap.NewNop()))
+		assert.Equal(t, 1, db.calls)
+	})
+
+	t.Run("stops waiting when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		db := &fakePinger{failures: 10}
+
+		err := waitForDatabase(ctx, db, dbRetryPolicy{Attempts: 5, InitialWait: time.Hour, MaxWait: time.Hour}, zap.NewNop())
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, db.calls)
+	})
+}
+
+// fakeMerchantLister pages through merchants the way ListAgents does
+type fakeMerchantLister struct {
+	merchants []*domain.Agent
+}
+
+func (f *fakeMerchantLister) ListAgents(ctx context.Context, environment *domain.Environment, isActive *bool, limit, offset int) ([]*domain.Agent, int, error) {
+	if offset >= len(f.merchants) {
+		return nil, len(f.merchants), nil
+	}
+	end := min(offset+limit, len(f.merchants))
+	return f.merchants[offset:end], len(f.merchants), nil
+}
+
+func TestCheckMerchantEnvironments(t *testing.T) {
+	var merchants []*domain.Agent
+	for i := 0; i < merchantEnvironmentPageSize+5; i++ {
+		merchants = append(merchants, &domain.Agent{AgentID: fmt.Sprintf("merchant-%d", i), Environment: domain.EnvironmentSandbox})
+	}
+	merchants[3].Environment = domain.EnvironmentProduction
+	merchants[merchantEnvironmentPageSize+2].Environment = domain.EnvironmentProduction
+	lister := &fakeMerchantLister{merchants: merchants}
+
+	core, logs := observer.New(zapcore.ErrorLevel)
+	mismatched, err := checkMerchantEnvironments(context.Background(), lister, domain.EnvironmentSandbox, zap.New(core))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"merchant-3", fmt.Sprintf("merchant-%d", merchantEnvironmentPageSize+2)}, mismatched,
+		"production merchants on every page are flagged")
+	assert.Equal(t, 2, logs.Len())
+
+	mismatched, err = checkMerchantEnvironments(context.Background(), lister, domain.EnvironmentProduction, zap.NewNop())
+	require.NoError(t, err)
+	assert.Len(t, mismatched, merchantEnvironmentPageSize+3, "sandbox merchants can't use production EPX either")
+
+	mismatched, err = checkMerchantEnvironments(context.Background(), &fakeMerchantLister{merchants: merchants[4:6]}, domain.EnvironmentSandbox, zap.NewNop())
+	require.NoError(t, err)
+	assert.Empty(t, mismatched)
+}
+
+func TestCheckEPXEndpoints(t *testing.T) {
+	assert.NoError(t, checkEPXEndpoints(domain.EnvironmentSandbox, map[string]string{
+		"EPX_SERVER_POST_URL":  "https://secure.epxuap.com",
+		"EPX_KEY_EXCHANGE_URL": "https://epxnow.com/epx/key_exchange_sandbox",
+	}))
+	assert.NoError(t, checkEPXEndpoints(domain.EnvironmentProduction, map[string]string{
+		"EPX_SERVER_POST_URL":  "https://epxnow.com/epx/server_post",
+		"EPX_KEY_EXCHANGE_URL": "https://epxnow.com/epx/key_exchange",
+	}))
+
+	err := checkEPXEndpoints(domain.EnvironmentProduction, map[string]string{
+		"EPX_SERVER_POST_URL":  "https://secure.epxuap.com",
+		"EPX_KEY_EXCHANGE_URL": "https://epxnow.com/epx/key_exchange",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "EPX_SERVER_POST_URL")
+
+	err = checkEPXEndpoints(domain.EnvironmentSandbox, map[string]string{
+		"EPX_SERVER_POST_URL": "https://epxnow.com/epx/server_post",
+	})
+	assert.Error(t, err, "sandbox deployments can't point at production EPX")
+}
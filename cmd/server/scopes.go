@@ -0,0 +1,112 @@
+package main
+
+import (
+	agentv1 "github.com/kevin07696/payment-service/proto/agent/v1"
+	chargebackv1 "github.com/kevin07696/payment-service/proto/chargeback/v1"
+	paymentv1 "github.com/kevin07696/payment-service/proto/payment/v1"
+	paymentmethodv1 "github.com/kevin07696/payment-service/proto/payment_method/v1"
+	subscriptionv1 "github.com/kevin07696/payment-service/proto/subscription/v1"
+	webhookv1 "github.com/kevin07696/payment-service/proto/webhook/v1"
+)
+
+// Scopes granted to service tokens
+const (
+	scopePaymentRead         = "payment:read"
+	scopePaymentWrite        = "payment:write"
+	scopePaymentRefund       = "payment:refund"
+	scopePaymentAdmin        = "payment:admin" // Operator diagnostics, never granted to merchant integrations
+	scopePaymentMethodRead   = "payment_method:read"
+	scopePaymentMethodManage = "payment_method:manage"
+	scopeSubscriptionRead    = "subscription:read"
+	scopeSubscriptionManage  = "subscription:manage"
+	scopeSubscriptionBilling = "subscription:billing"
+	scopeChargebackRead      = "chargeback:read"
+	scopeChargebackManage    = "chargeback:manage"
+	scopeWebhookRead         = "webhook:read"
+	scopeWebhookManage       = "webhook:manage"
+	scopeAgentRead           = "agent:read"
+	scopeAgentManage         = "agent:manage"
+	scopeMerchantRead        = "merchant:read"
+	scopeMerchantSettings    = "merchant:settings"
+)
+
+// methodScopes is the scope each RPC requires; methods missing here are denied to every service token
+var methodScopes = map[string]string{
+	paymentv1.PaymentService_Authorize_FullMethodName:                   scopePaymentWrite,
+	paymentv1.PaymentService_Capture_FullMethodName:                     scopePaymentWrite,
+	paymentv1.PaymentService_PartialReverseAuthorization_FullMethodName: scopePaymentWrite,
+	paymentv1.PaymentService_IncrementAuthorization_FullMethodName:      scopePaymentWrite,
+	paymentv1.PaymentService_Sale_FullMethodName:                        scopePaymentWrite,
+	paymentv1.PaymentService_BatchSale_FullMethodName:                   scopePaymentWrite,
+	paymentv1.PaymentService_Void_FullMethodName:                        scopePaymentWrite,
+	paymentv1.PaymentService_Refund_FullMethodName:                      scopePaymentRefund,
+	paymentv1.PaymentService_RefundByReference_FullMethodName:           scopePaymentRefund,
+	paymentv1.PaymentService_GetTransaction_FullMethodName:              scopePaymentRead,
+	paymentv1.PaymentService_GetTransactionStatuses_FullMethodName:      scopePaymentRead,
+	paymentv1.PaymentService_BatchGetTransactions_FullMethodName:        scopePaymentRead,
+	paymentv1.PaymentService_ListTransactions_FullMethodName:            scopePaymentRead,
+	paymentv1.PaymentService_GetEstimatedFees_FullMethodName:            scopePaymentRead,
+	paymentv1.PaymentService_ClassifyDeclineCode_FullMethodName:         scopePaymentAdmin,
+	paymentv1.PaymentService_GetSettlementSummary_FullMethodName:        scopePaymentRead,
+
+	paymentmethodv1.PaymentMethodService_SavePaymentMethod_FullMethodName:                 scopePaymentMethodManage,
+	paymentmethodv1.PaymentMethodService_GetPaymentMethod_FullMethodName:                  scopePaymentMethodRead,
+	paymentmethodv1.PaymentMethodService_ListPaymentMethods_FullMethodName:                scopePaymentMethodRead,
+	paymentmethodv1.PaymentMethodService_ListExpiringPaymentMethods_FullMethodName:        scopePaymentMethodRead,
+	paymentmethodv1.PaymentMethodService_UpdatePaymentMethodStatus_FullMethodName:         scopePaymentMethodManage,
+	paymentmethodv1.PaymentMethodService_UpdatePaymentMethodExpiry_FullMethodName:         scopePaymentMethodManage,
+	paymentmethodv1.PaymentMethodService_DeletePaymentMethod_FullMethodName:               scopePaymentMethodManage,
+	paymentmethodv1.PaymentMethodService_SetDefaultPaymentMethod_FullMethodName:           scopePaymentMethodManage,
+	paymentmethodv1.PaymentMethodService_VerifyACHAccount_FullMethodName:                  scopePaymentMethodManage,
+	paymentmethodv1.PaymentMethodService_ConvertFinancialBRICToStorageBRIC_FullMethodName: scopePaymentMethodManage,
+	paymentmethodv1.PaymentMethodService_TokenizePaymentMethod_FullMethodName:             scopePaymentMethodManage,
+	paymentmethodv1.PaymentMethodService_ProcessACHReturn_FullMethodName:                  scopePaymentMethodManage,
+	paymentmethodv1.PaymentMethodService_InitiateMicroDeposits_FullMethodName:             scopePaymentMethodManage,
+	paymentmethodv1.PaymentMethodService_VerifyMicroDeposits_FullMethodName:               scopePaymentMethodManage,
+
+	subscriptionv1.SubscriptionService_CreateSubscription_FullMethodName:           scopeSubscriptionManage,
+	subscriptionv1.SubscriptionService_UpdateSubscription_FullMethodName:           scopeSubscriptionManage,
+	subscriptionv1.SubscriptionService_CancelSubscription_FullMethodName:           scopeSubscriptionManage,
+	subscriptionv1.SubscriptionService_PauseSubscription_FullMethodName:            scopeSubscriptionManage,
+	subscriptionv1.SubscriptionService_ResumeSubscription_FullMethodName:           scopeSubscriptionManage,
+	subscriptionv1.SubscriptionService_GetSubscription_FullMethodName:              scopeSubscriptionRead,
+	subscriptionv1.SubscriptionService_ListCustomerSubscriptions_FullMethodName:    scopeSubscriptionRead,
+	subscriptionv1.SubscriptionService_ListSubscriptionTransactions_FullMethodName: scopeSubscriptionRead,
+	subscriptionv1.SubscriptionService_GetSubscriptionChargeHistory_FullMethodName: scopeSubscriptionRead,
+	subscriptionv1.SubscriptionService_ProcessDueBilling_FullMethodName:            scopeSubscriptionBilling,
+
+	chargebackv1.ChargebackService_GetChargeback_FullMethodName:            scopeChargebackRead,
+	chargebackv1.ChargebackService_ListChargebacks_FullMethodName:          scopeChargebackRead,
+	chargebackv1.ChargebackService_SubmitChargebackEvidence_FullMethodName: scopeChargebackManage,
+
+	webhookv1.WebhookService_RedeliverWebhook_FullMethodName:          scopeWebhookManage,
+	webhookv1.WebhookService_RedeliverFailedWebhooks_FullMethodName:   scopeWebhookManage,
+	webhookv1.WebhookService_GetWebhookStats_FullMethodName:           scopeWebhookRead,
+	webhookv1.WebhookService_CreateWebhookSubscription_FullMethodName: scopeWebhookManage,
+	webhookv1.WebhookService_ListWebhookSubscriptions_FullMethodName:  scopeWebhookRead,
+	webhookv1.WebhookService_UpdateWebhookSubscription_FullMethodName: scopeWebhookManage,
+	webhookv1.WebhookService_DeleteWebhookSubscription_FullMethodName: scopeWebhookManage,
+	webhookv1.WebhookService_ListEventTypes_FullMethodName:            scopeWebhookRead,
+
+	agentv1.AgentService_RegisterAgent_FullMethodName:              scopeAgentManage,
+	agentv1.AgentService_GetAgent_FullMethodName:                   scopeAgentRead,
+	agentv1.AgentService_ListAgents_FullMethodName:                 scopeAgentRead,
+	agentv1.AgentService_UpdateAgent_FullMethodName:                scopeAgentManage,
+	agentv1.AgentService_DeactivateAgent_FullMethodName:            scopeAgentManage,
+	agentv1.AgentService_SuspendMerchant_FullMethodName:            scopeAgentManage,
+	agentv1.AgentService_ReactivateMerchant_FullMethodName:         scopeAgentManage,
+	agentv1.AgentService_RotateMAC_FullMethodName:                  scopeAgentManage,
+	agentv1.AgentService_GetEffectiveMerchantConfig_FullMethodName: scopeAgentRead,
+	agentv1.AgentService_GetMerchant_FullMethodName:                scopeMerchantRead,
+	agentv1.AgentService_UpdateMerchantSettings_FullMethodName:     scopeMerchantSettings,
+}
+
+// merchantlessMethods act for no single merchant, so they need no merchant grant (their scope still applies).
+// Every other method must name a merchant the service is granted, by agent_id or through the resource it acts on.
+var merchantlessMethods = map[string]bool{
+	paymentv1.PaymentService_ClassifyDeclineCode_FullMethodName:         true,
+	subscriptionv1.SubscriptionService_ProcessDueBilling_FullMethodName: true,
+	webhookv1.WebhookService_ListEventTypes_FullMethodName:              true,
+	agentv1.AgentService_RegisterAgent_FullMethodName:                   true,
+	agentv1.AgentService_ListAgents_FullMethodName:                      true,
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/kevin07696/payment-service/pkg/middleware"
+	paymentv1 "github.com/kevin07696/payment-service/proto/payment/v1"
+)
+
+func TestClassifyDeclineCode_AdminScopeOnly(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	interceptor := middleware.NewAuthInterceptor(middleware.AuthConfig{
+		Keys:                middleware.StaticServiceKeys(map[string]*rsa.PublicKey{"pos-service": &key.PublicKey}),
+		MethodScopes:        methodScopes,
+		MerchantlessMethods: merchantlessMethods,
+	}, zap.NewNop())
+
+	call := func(scope string) error {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, middleware.ServiceClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    "pos-service",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+			Scope: scope,
+		}).SignedString(key)
+		require.NoError(t, err)
+
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		_, err = interceptor(ctx, &paymentv1.ClassifyDeclineCodeRequest{AuthResp: "51"},
+			&grpc.UnaryServerInfo{FullMethod: paymentv1.PaymentService_ClassifyDeclineCode_FullMethodName},
+			func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil })
+		return err
+	}
+
+	err = call("payment:read payment:write payment:refund")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "a merchant integration's scopes don't reach the diagnostic")
+	assert.Contains(t, status.Convert(err).Message(), `missing scope "payment:admin"`)
+
+	assert.NoError(t, call("payment:admin"))
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// CustomerStore is the customer lookup and creation ResolveCustomer needs
+type CustomerStore interface {
+	GetCustomer(ctx context.Context, arg sqlc.GetCustomerParams) (sqlc.Customer, error)
+	CreateCustomer(ctx context.Context, arg sqlc.CreateCustomerParams) (sqlc.Customer, error)
+}
+
+// ResolveCustomer applies the merchant's customer policy to a payment's customer_id before the gateway is called:
+// an unknown customer is created (auto_create) or rejects the payment (require_existing).
+// Guest payments and merchants without a policy skip the lookup. Sale, Authorize and the Browser Post form and
+// callback all resolve the customer they charge or save a card for.
+func ResolveCustomer(ctx context.Context, store CustomerStore, config *domain.MerchantConfig, agentID string, customerID, email *string) error {
+	if customerID == nil || *customerID == "" || config.CustomerPolicy == domain.CustomerPolicyUnchecked {
+		return nil
+	}
+
+	_, err := store.GetCustomer(ctx, sqlc.GetCustomerParams{AgentID: agentID, CustomerID: *customerID})
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	if config.CustomerPolicy != domain.CustomerPolicyAutoCreate {
+		return fmt.Errorf("%w: %s", domain.ErrCustomerNotFound, *customerID)
+	}
+	var emailText pgtype.Text
+	if email != nil {
+		emailText = pgtype.Text{String: *email, Valid: true}
+	}
+	if _, err := store.CreateCustomer(ctx, sqlc.CreateCustomerParams{
+		AgentID:     agentID,
+		CustomerID:  *customerID,
+		Email:       emailText,
+		AutoCreated: true,
+	}); err != nil {
+		return fmt.Errorf("failed to create customer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCustomerStore keeps customers in memory, keyed by agent and customer_id
+type fakeCustomerStore struct {
+	customers map[string]sqlc.Customer
+	lookups   int
+}
+
+func newFakeCustomerStore() *fakeCustomerStore {
+	return &fakeCustomerStore{customers: make(map[string]sqlc.Customer)}
+}
+
+func (f *fakeCustomerStore) GetCustomer(ctx context.Context, arg sqlc.GetCustomerParams) (sqlc.Customer, error) {
+	f.lookups++
+	customer, ok := f.customers[arg.AgentID+"/"+arg.CustomerID]
+	if !ok {
+		return sqlc.Customer{}, pgx.ErrNoRows
+	}
+	return customer, nil
+}
+
+func (f *fakeCustomerStore) CreateCustomer(ctx context.Context, arg sqlc.CreateCustomerParams) (sqlc.Customer, error) {
+	customer := sqlc.Customer{ID: uuid.New(), AgentID: arg.AgentID, CustomerID: arg.CustomerID, Email: arg.Email, AutoCreated: arg.AutoCreated}
+	f.customers[arg.AgentID+"/"+arg.CustomerID] = customer
+	return customer, nil
+}
+
+func customerPolicyConfig(policy domain.CustomerPolicy) *domain.MerchantConfig {
+	return domain.ResolveMerchantConfig(domain.MerchantTierStandard, &domain.MerchantConfigOverrides{CustomerPolicy: &policy})
+}
+
+func TestResolveCustomer_AutoCreateOnReference(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeCustomerStore()
+	customerID, email := "cust-42", "jane@example.com"
+	config := customerPolicyConfig(domain.CustomerPolicyAutoCreate)
+
+	require.NoError(t, ResolveCustomer(ctx, store, config, "merchant-1", &customerID, &email))
+
+	created, ok := store.customers["merchant-1/cust-42"]
+	require.True(t, ok, "unknown customer is created")
+	assert.True(t, created.AutoCreated)
+	assert.Equal(t, "jane@example.com", created.Email.String)
+
+	// The next payment finds it
+	require.NoError(t, ResolveCustomer(ctx, store, config, "merchant-1", &customerID, nil))
+	assert.Len(t, store.customers, 1)
+}
+
+func TestResolveCustomer_RequireExistingRejectsUnknown(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeCustomerStore()
+	config := customerPolicyConfig(domain.CustomerPolicyRequireExisting)
+
+	unknown := "cust-42"
+	err := ResolveCustomer(ctx, store, config, "merchant-1", &unknown, nil)
+	assert.ErrorIs(t, err, domain.ErrCustomerNotFound)
+	assert.Empty(t, store.customers, "nothing is created for strict merchants")
+
+	// Another merchant's customer with the same ID doesn't count
+	_, _ = store.CreateCustomer(ctx, sqlc.CreateCustomerParams{AgentID: "merchant-2", CustomerID: "cust-42"})
+	assert.ErrorIs(t, ResolveCustomer(ctx, store, config, "merchant-1", &unknown, nil), domain.ErrCustomerNotFound)
+
+	_, _ = store.CreateCustomer(ctx, sqlc.CreateCustomerParams{AgentID: "merchant-1", CustomerID: "cust-42"})
+	assert.NoError(t, ResolveCustomer(ctx, store, config, "merchant-1", &unknown, nil))
+}
+
+func TestResolveCustomer_UncheckedAndGuestSkipLookup(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeCustomerStore()
+	customerID := "cust-42"
+
+	assert.NoError(t, ResolveCustomer(ctx, store, customerPolicyConfig(domain.CustomerPolicyUnchecked), "merchant-1", &customerID, nil))
+	assert.NoError(t, ResolveCustomer(ctx, store, customerPolicyConfig(domain.CustomerPolicyRequireExisting), "merchant-1", nil, nil))
+	assert.Zero(t, store.lookups)
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// DailyVolumeStore is the counter queries that enforce a merchant's daily volume limit
+type DailyVolumeStore interface {
+	ReserveDailyVolume(ctx context.Context, arg sqlc.ReserveDailyVolumeParams) (pgtype.Numeric, error)
+	ReleaseDailyVolume(ctx context.Context, arg sqlc.ReleaseDailyVolumeParams) error
+}
+
+// DailyVolumeReservation is volume held against a merchant's daily limit for one payment
+type DailyVolumeReservation struct {
+	agentID string
+	day     pgtype.Date
+	amount  pgtype.Numeric
+}
+
+// ReserveDailyVolume atomically adds amount to the merchant's volume for the UTC day, failing with
+// ErrDailyVolumeLimitExceeded instead if that would pass limit. A non-positive limit means no limit (nil reservation).
+// Every path that charges a merchant reserves before the gateway call, so concurrent payments can't jointly pass
+// the limit; approved and ambiguous (gateway error) payments keep their reservation, while declines and requests
+// that never reached the gateway release it.
+func ReserveDailyVolume(ctx context.Context, store DailyVolumeStore, agentID string, amount, limit decimal.Decimal, now time.Time) (*DailyVolumeReservation, error) {
+	if !limit.IsPositive() {
+		return nil, nil
+	}
+
+	utc := now.UTC()
+	reservation := &DailyVolumeReservation{
+		agentID: agentID,
+		day:     pgtype.Date{Time: time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC), Valid: true},
+		amount:  numeric(amount),
+	}
+
+	_, err := store.ReserveDailyVolume(ctx, sqlc.ReserveDailyVolumeParams{
+		AgentID:    reservation.agentID,
+		VolumeDate: reservation.day,
+		Amount:     reservation.amount,
+		DailyLimit: numeric(limit),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s would exceed the daily limit of %s", domain.ErrDailyVolumeLimitExceeded, amount.StringFixed(2), limit.StringFixed(2))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve daily volume: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// StoredDailyVolumeReservation is the reservation a payment recorded with itself: amount held against day
+// (nil when day is NULL, nothing was reserved)
+func StoredDailyVolumeReservation(agentID string, day pgtype.Date, amount pgtype.Numeric) *DailyVolumeReservation {
+	if !day.Valid {
+		return nil
+	}
+	return &DailyVolumeReservation{agentID: agentID, day: day, amount: amount}
+}
+
+// Day is the UTC day the reservation is held against, for recording with the payment (NULL without a reservation)
+func (r *DailyVolumeReservation) Day() pgtype.Date {
+	if r == nil {
+		return pgtype.Date{}
+	}
+	return r.day
+}
+
+// Release returns the reservation's amount to the day's remaining volume (no-op without a reservation)
+func (r *DailyVolumeReservation) Release(ctx context.Context, store DailyVolumeStore) error {
+	if r == nil {
+		return nil
+	}
+
+	return store.ReleaseDailyVolume(ctx, sqlc.ReleaseDailyVolumeParams{
+		AgentID:    r.agentID,
+		VolumeDate: r.day,
+		Amount:     r.amount,
+	})
+}
+
+func numeric(d decimal.Decimal) pgtype.Numeric {
+	return pgtype.Numeric{Int: d.Coefficient(), Exp: d.Exponent(), Valid: true}
+}
@@ -0,0 +1,72 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// Runs the counter queries against a migrated database:
+//
+//	TEST_DATABASE_URL=postgres://... go test -tags=integration ./internal/adapters/database
+func TestReserveDailyVolume_ConcurrentReservationsAgainstPostgres(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	db, err := NewPostgreSQLAdapter(ctx, DefaultPostgreSQLConfig(url), zap.NewNop())
+	require.NoError(t, err)
+	defer db.Close()
+
+	agentID := "daily-volume-it-" + uuid.NewString()
+	t.Cleanup(func() {
+		_, _ = db.Pool().Exec(context.Background(), "DELETE FROM merchant_daily_volume WHERE agent_id = $1", agentID)
+	})
+
+	limit := decimal.NewFromInt(1000)
+	amount := decimal.RequireFromString("30.00")
+	now := time.Now()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved int
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := ReserveDailyVolume(ctx, db.Queries(), agentID, amount, limit, now)
+			if err != nil {
+				assert.ErrorIs(t, err, domain.ErrDailyVolumeLimitExceeded)
+				return
+			}
+			mu.Lock()
+			reserved++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// 33 x 30.00 fits under 1000.00; the other 17 are refused however the upserts interleave
+	assert.Equal(t, 33, reserved)
+
+	var total string
+	require.NoError(t, db.Pool().QueryRow(ctx,
+		"SELECT amount::text FROM merchant_daily_volume WHERE agent_id = $1", agentID).Scan(&total))
+	assert.True(t, decimal.RequireFromString(total).Equal(decimal.NewFromInt(990)), "counter is %s", total)
+}
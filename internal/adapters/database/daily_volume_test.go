@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDailyVolume mimics the counter queries: each upsert holds the row lock while it checks and increments
+type fakeDailyVolume struct {
+	mu     sync.Mutex
+	totals map[string]decimal.Decimal
+}
+
+func (f *fakeDailyVolume) key(agentID string, day pgtype.Date) string {
+	return agentID + "/" + day.Time.Format("2006-01-02")
+}
+
+func (f *fakeDailyVolume) ReserveDailyVolume(ctx context.Context, arg sqlc.ReserveDailyVolumeParams) (pgtype.Numeric, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := f.key(arg.AgentID, arg.VolumeDate)
+	next := f.totals[key].Add(decimal.NewFromBigInt(arg.Amount.Int, arg.Amount.Exp))
+	if next.GreaterThan(decimal.NewFromBigInt(arg.DailyLimit.Int, arg.DailyLimit.Exp)) {
+		return pgtype.Numeric{}, pgx.ErrNoRows
+	}
+	f.totals[key] = next
+	return numeric(next), nil
+}
+
+func (f *fakeDailyVolume) ReleaseDailyVolume(ctx context.Context, arg sqlc.ReleaseDailyVolumeParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := f.key(arg.AgentID, arg.VolumeDate)
+	f.totals[key] = decimal.Max(f.totals[key].Sub(decimal.NewFromBigInt(arg.Amount.Int, arg.Amount.Exp)), decimal.Zero)
+	return nil
+}
+
+func TestReserveDailyVolume_ConcurrentSalesNeverExceedCap(t *testing.T) {
+	store := &fakeDailyVolume{totals: map[string]decimal.Decimal{}}
+	now := time.Date(2025, 6, 15, 23, 59, 0, 0, time.UTC)
+	limit := decimal.NewFromInt(1000)
+	amount := decimal.RequireFromString("30.00")
+
+	// Already close to the cap: 880 of 1000 used, room for 4 more 30.00 sales
+	_, err := ReserveDailyVolume(context.Background(), store, "agent-1", decimal.NewFromInt(880), limit, now)
+	require.NoError(t, err)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		approved = decimal.Zero
+		rejected int
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(declined bool) {
+			defer wg.Done()
+
+			reservation, err := ReserveDailyVolume(context.Background(), store, "agent-1", amount, limit, now)
+			if err != nil {
+				assert.ErrorIs(t, err, domain.ErrDailyVolumeLimitExceeded)
+				mu.Lock()
+				rejected++
+				mu.Unlock()
+				return
+			}
+
+			// A declined sale gives its room back to later sales
+			if declined {
+				assert.NoError(t, reservation.Release(context.Background(), store))
+				return
+			}
+			mu.Lock()
+			approved = approved.Add(amount)
+			mu.Unlock()
+		}(i%5 == 0)
+	}
+	wg.Wait()
+
+	total := store.totals[store.key("agent-1", pgtype.Date{Time: now})]
+	assert.True(t, total.LessThanOrEqual(limit), "cap exceeded: %s", total)
+	assert.True(t, decimal.NewFromInt(880).Add(approved).Equal(total), "counter matches approved volume")
+	assert.Positive(t, rejected, "sales past the cap are rejected")
+
+	// A new UTC day starts from zero
+	_, err = ReserveDailyVolume(context.Background(), store, "agent-1", amount, limit, now.Add(time.Minute))
+	assert.NoError(t, err)
+}
+
+func TestReserveDailyVolume_NoLimitOrOverLimit(t *testing.T) {
+	store := &fakeDailyVolume{totals: map[string]decimal.Decimal{}}
+	now := time.Now()
+
+	reservation, err := ReserveDailyVolume(context.Background(), store, "agent-1", decimal.NewFromInt(500), decimal.Zero, now)
+	require.NoError(t, err)
+	assert.Nil(t, reservation, "no limit configured, nothing reserved")
+	assert.NoError(t, reservation.Release(context.Background(), store))
+	assert.Empty(t, store.totals)
+
+	_, err = ReserveDailyVolume(context.Background(), store, "agent-1", decimal.NewFromInt(101), decimal.NewFromInt(100), now)
+	assert.ErrorIs(t, err, domain.ErrDailyVolumeLimitExceeded, "a single sale larger than the cap")
+}
@@ -0,0 +1,13 @@
+package database
+
+import (
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// CheckEPXEnvironment fails with ErrEnvironmentMismatch when the stored merchant belongs to the other EPX
+// environment than epx, the one this deployment's adapters talk to. Every path that sends a merchant's
+// credentials to EPX (charges, tokenization, pre-notes, micro-deposits, Browser Post forms) checks it first.
+func CheckEPXEnvironment(agent *sqlc.AgentCredential, epx domain.Environment) error {
+	return domain.Environment(agent.Environment).CheckEPX(epx)
+}
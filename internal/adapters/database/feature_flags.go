@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// FeatureFlagStore reads the flags that apply to a merchant
+type FeatureFlagStore interface {
+	GetFeatureFlagsForAgent(ctx context.Context, arg sqlc.GetFeatureFlagsForAgentParams) ([]sqlc.FeatureFlag, error)
+}
+
+// CheckChargesEnabled fails with ErrChargesDisabled when the global or the merchant's charges_enabled flag is off.
+// Every path that starts a charge checks it; flags are read each time, so a change made with the admin CLI
+// applies to the next request.
+func CheckChargesEnabled(ctx context.Context, store FeatureFlagStore, agentID string) error {
+	rows, err := store.GetFeatureFlagsForAgent(ctx, sqlc.GetFeatureFlagsForAgentParams{
+		Name:    domain.FeatureFlagChargesEnabled,
+		AgentID: pgtype.Text{String: agentID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read feature flags: %w", err)
+	}
+
+	flags := make([]domain.FeatureFlag, len(rows))
+	for i, row := range rows {
+		flags[i] = domain.FeatureFlag{
+			Name:    row.Name,
+			AgentID: row.AgentID.String,
+			Enabled: row.Enabled,
+			Reason:  row.Reason.String,
+		}
+	}
+	return domain.CheckChargesEnabled(flags)
+}
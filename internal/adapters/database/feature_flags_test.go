@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeFeatureFlags answers flag lookups from an in-memory table
+type fakeFeatureFlags struct {
+	flags []sqlc.FeatureFlag
+}
+
+func (f *fakeFeatureFlags) GetFeatureFlagsForAgent(ctx context.Context, arg sqlc.GetFeatureFlagsForAgentParams) ([]sqlc.FeatureFlag, error) {
+	var matched []sqlc.FeatureFlag
+	for _, flag := range f.flags {
+		if flag.Name == arg.Name && (!flag.AgentID.Valid || flag.AgentID == arg.AgentID) {
+			matched = append(matched, flag)
+		}
+	}
+	return matched, nil
+}
+
+func chargesFlag(agentID string, enabled bool) sqlc.FeatureFlag {
+	return sqlc.FeatureFlag{
+		Name:    domain.FeatureFlagChargesEnabled,
+		AgentID: pgtype.Text{String: agentID, Valid: agentID != ""},
+		Enabled: enabled,
+	}
+}
+
+func TestCheckChargesEnabled(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, CheckChargesEnabled(ctx, &fakeFeatureFlags{}, "merchant-1"), "charges are on without flags")
+	assert.NoError(t, CheckChargesEnabled(ctx, &fakeFeatureFlags{flags: []sqlc.FeatureFlag{chargesFlag("", true)}}, "merchant-1"))
+
+	globalOff := &fakeFeatureFlags{flags: []sqlc.FeatureFlag{chargesFlag("", false)}}
+	assert.ErrorIs(t, CheckChargesEnabled(ctx, globalOff, "merchant-1"), domain.ErrChargesDisabled)
+	assert.ErrorIs(t, CheckChargesEnabled(ctx, globalOff, "merchant-2"), domain.ErrChargesDisabled)
+
+	merchantOff := &fakeFeatureFlags{flags: []sqlc.FeatureFlag{chargesFlag("", true), chargesFlag("merchant-1", false)}}
+	err := CheckChargesEnabled(ctx, merchantOff, "merchant-1")
+	assert.ErrorIs(t, err, domain.ErrChargesDisabled)
+	assert.Contains(t, err.Error(), "for this merchant")
+	assert.NoError(t, CheckChargesEnabled(ctx, merchantOff, "merchant-2"), "other merchants keep charging")
+}
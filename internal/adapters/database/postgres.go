@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kevin07696/payment-service/internal/db/sqlc"
@@ -33,6 +34,15 @@ func DefaultPostgreSQLConfig(databaseURL string) *PostgreSQLConfig {
 	}
 }
 
+// Store is the database access the services depend on. PostgreSQLAdapter is the production implementation;
+// tests substitute in-memory Querier fakes.
+type Store interface {
+	Queries() sqlc.Querier
+	WithTx(ctx context.Context, fn func(sqlc.Querier) error) error
+}
+
+var _ Store = (*PostgreSQLAdapter)(nil)
+
 // PostgreSQLAdapter provides database access using pgx pool and sqlc-generated queries
 type PostgreSQLAdapter struct {
 	pool    *pgxpool.Pool
@@ -83,7 +93,7 @@ func NewPostgreSQLAdapter(ctx context.Context, cfg *PostgreSQLConfig, logger *za
 }
 
 // Queries returns the sqlc queries instance for database operations
-func (a *PostgreSQLAdapter) Queries() *sqlc.Queries {
+func (a *PostgreSQLAdapter) Queries() sqlc.Querier {
 	return a.queries
 }
 
@@ -101,7 +111,7 @@ func (a *PostgreSQLAdapter) Close() {
 // WithTx executes a function within a database transaction
 // If the function returns an error, the transaction is rolled back
 // Otherwise, the transaction is committed
-func (a *PostgreSQLAdapter) WithTx(ctx context.Context, fn func(*sqlc.Queries) error) error {
+func (a *PostgreSQLAdapter) WithTx(ctx context.Context, fn func(sqlc.Querier) error) error {
 	// Begin transaction
 	tx, err := a.pool.Begin(ctx)
 	if err != nil {
@@ -147,3 +157,39 @@ func (a *PostgreSQLAdapter) HealthCheck(ctx context.Context) error {
 func (a *PostgreSQLAdapter) Stats() *pgxpool.Stat {
 	return a.pool.Stat()
 }
+
+// TryLockCronJob takes the cluster-wide lock for a cron job on a dedicated connection.
+// It returns acquired=false without waiting when another instance holds the lock. Call unlock when the run
+// ends; if the process dies first, the connection closes and PostgreSQL releases the lock.
+func (a *PostgreSQLAdapter) TryLockCronJob(ctx context.Context, job string) (unlock func(), acquired bool, err error) {
+	conn, err := a.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	queries := sqlc.New(conn)
+	acquired, err = queries.TryLockCronJob(ctx, job)
+	if err != nil || !acquired {
+		conn.Release()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to lock cron job: %w", err)
+		}
+		return nil, false, nil
+	}
+
+	unlock = func() {
+		// The request context may already be done; the lock must still be released
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := queries.UnlockCronJob(unlockCtx, job); err != nil {
+			a.logger.Error("Failed to unlock cron job, closing its connection",
+				zap.String("job", job),
+				zap.Error(err),
+			)
+			// Closing the session releases the lock; the pool discards the closed connection
+			conn.Conn().Close(unlockCtx)
+		}
+		conn.Release()
+	}
+	return unlock, true, nil
+}
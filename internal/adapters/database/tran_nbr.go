@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+)
+
+// tranNbrRequestNamespace scopes the request IDs TranNbrRequestID derives
+var tranNbrRequestNamespace = uuid.MustParse("5b0f3c1e-8d2a-4e47-9a61-0c7d2f4e9b13")
+
+// TranNbrStore is the TRAN_NBR allocation AllocateTranNbr needs
+type TranNbrStore interface {
+	GetTranNbr(ctx context.Context, transactionID uuid.UUID) (sqlc.EpxTranNbr, error)
+	NextTranNbr(ctx context.Context, agentID string) (int64, error)
+	AssignTranNbr(ctx context.Context, arg sqlc.AssignTranNbrParams) (sqlc.EpxTranNbr, error)
+}
+
+// AllocateTranNbr returns the TRAN_NBR sent to EPX for the transaction: the merchant's next number the first
+// time the transaction ID is seen, and the same number on every retry. Numbers come from a per-merchant counter,
+// so they fit EPX's 10 digits without the collisions of deriving them from the transaction UUID.
+func AllocateTranNbr(ctx context.Context, store TranNbrStore, agentID string, transactionID uuid.UUID) (int64, error) {
+	existing, err := store.GetTranNbr(ctx, transactionID)
+	if err == nil {
+		return existing.TranNbr, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("failed to get TRAN_NBR: %w", err)
+	}
+
+	next, err := store.NextTranNbr(ctx, agentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate TRAN_NBR: %w", err)
+	}
+
+	assigned, err := store.AssignTranNbr(ctx, sqlc.AssignTranNbrParams{
+		TransactionID: transactionID,
+		AgentID:       agentID,
+		TranNbr:       next,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A concurrent retry assigned a number first; use it and leave this one unused
+		existing, err = store.GetTranNbr(ctx, transactionID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get TRAN_NBR: %w", err)
+		}
+		return existing.TranNbr, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign TRAN_NBR: %w", err)
+	}
+	return assigned.TranNbr, nil
+}
+
+// TranNbrRequestID is the ID an EPX request without a transaction row of its own allocates its TRAN_NBR under.
+// It is derived from what identifies the request (e.g. the payment method a pre-note verifies), so a retry of
+// the same request gets the same TRAN_NBR and EPX recognizes it as a duplicate.
+func TranNbrRequestID(parts ...string) uuid.UUID {
+	return uuid.NewSHA1(tranNbrRequestNamespace, []byte(strings.Join(parts, "/")))
+}
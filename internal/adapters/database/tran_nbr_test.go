@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTranNbrStore keeps per-merchant counters and allocated numbers in memory.
+// racedBy, when set, is assigned to the next transaction just before AssignTranNbr, as a concurrent retry would.
+type fakeTranNbrStore struct {
+	counters map[string]int64
+	assigned map[uuid.UUID]sqlc.EpxTranNbr
+	racedBy  *sqlc.EpxTranNbr
+}
+
+func newFakeTranNbrStore() *fakeTranNbrStore {
+	return &fakeTranNbrStore{counters: make(map[string]int64), assigned: make(map[uuid.UUID]sqlc.EpxTranNbr)}
+}
+
+func (f *fakeTranNbrStore) GetTranNbr(ctx context.Context, transactionID uuid.UUID) (sqlc.EpxTranNbr, error) {
+	row, ok := f.assigned[transactionID]
+	if !ok {
+		return sqlc.EpxTranNbr{}, pgx.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeTranNbrStore) NextTranNbr(ctx context.Context, agentID string) (int64, error) {
+	f.counters[agentID]++
+	return f.counters[agentID], nil
+}
+
+func (f *fakeTranNbrStore) AssignTranNbr(ctx context.Context, arg sqlc.AssignTranNbrParams) (sqlc.EpxTranNbr, error) {
+	if f.racedBy != nil {
+		f.assigned[arg.TransactionID] = *f.racedBy
+		f.racedBy = nil
+	}
+	if _, ok := f.assigned[arg.TransactionID]; ok {
+		return sqlc.EpxTranNbr{}, pgx.ErrNoRows
+	}
+	row := sqlc.EpxTranNbr{TransactionID: arg.TransactionID, AgentID: arg.AgentID, TranNbr: arg.TranNbr}
+	f.assigned[arg.TransactionID] = row
+	return row, nil
+}
+
+func TestAllocateTranNbr_UniquePerMerchant(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeTranNbrStore()
+
+	seen := map[string]map[int64]bool{"merchant-1": {}, "merchant-2": {}}
+	for i := 0; i < 1000; i++ {
+		for agentID, numbers := range seen {
+			tranNbr, err := AllocateTranNbr(ctx, store, agentID, uuid.New())
+			require.NoError(t, err)
+			assert.False(t, numbers[tranNbr], "TRAN_NBR %d allocated twice for %s", tranNbr, agentID)
+			assert.LessOrEqual(t, len(strconv.FormatInt(tranNbr, 10)), 10)
+			numbers[tranNbr] = true
+		}
+	}
+	assert.Len(t, seen["merchant-1"], 1000)
+	assert.Len(t, seen["merchant-2"], 1000)
+}
+
+func TestAllocateTranNbr_RetryReusesNumber(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeTranNbrStore()
+	transactionID := uuid.New()
+
+	first, err := AllocateTranNbr(ctx, store, "merchant-1", transactionID)
+	require.NoError(t, err)
+	retry, err := AllocateTranNbr(ctx, store, "merchant-1", transactionID)
+	require.NoError(t, err)
+	assert.Equal(t, first, retry)
+	assert.Equal(t, int64(1), store.counters["merchant-1"], "a retry doesn't take a new number")
+
+	other, err := AllocateTranNbr(ctx, store, "merchant-1", uuid.New())
+	require.NoError(t, err)
+	assert.NotEqual(t, first, other)
+}
+
+func TestAllocateTranNbr_ConcurrentRetryWins(t *testing.T) {
+	store := newFakeTranNbrStore()
+	transactionID := uuid.New()
+	store.racedBy = &sqlc.EpxTranNbr{TransactionID: transactionID, AgentID: "merchant-1", TranNbr: 7}
+
+	tranNbr, err := AllocateTranNbr(context.Background(), store, "merchant-1", transactionID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), tranNbr, "the number assigned first is used")
+}
+
+func TestTranNbrRequestID_StablePerRequest(t *testing.T) {
+	pmID := uuid.New().String()
+	assert.Equal(t, TranNbrRequestID("prenote", pmID), TranNbrRequestID("prenote", pmID), "a retry gets the same ID")
+	assert.NotEqual(t, TranNbrRequestID("prenote", pmID), TranNbrRequestID("prenote", uuid.New().String()))
+	assert.NotEqual(t, TranNbrRequestID("prenote", pmID), TranNbrRequestID("bric-conversion", pmID), "operations don't share numbers")
+}
@@ -2,7 +2,6 @@ package epx
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/xml"
 	"fmt"
 	"io"
@@ -11,6 +10,8 @@ import (
 	"time"
 
 	"github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/pkg/observability"
+	"github.com/kevin07696/payment-service/pkg/security"
 	"go.uber.org/zap"
 )
 
@@ -27,6 +28,7 @@ type BRICStorageConfig struct {
 
 	// TLS configuration
 	InsecureSkipVerify bool
+	MinTLSVersion      uint16 // Lowest TLS version negotiated (tls.VersionTLS12 or tls.VersionTLS13)
 
 	// Retry configuration
 	MaxRetries      int
@@ -45,6 +47,7 @@ func DefaultBRICStorageConfig(environment string) *BRICStorageConfig {
 		BaseURL:            baseURL,
 		Timeout:            30 * time.Second,
 		InsecureSkipVerify: environment == "sandbox",
+		MinTLSVersion:      security.DefaultMinTLSVersion,
 		MaxRetries:         3,
 		RetryDelay:         1 * time.Second,
 		RetryableErrors:    []string{"timeout", "connection", "temporary"},
@@ -62,9 +65,7 @@ type bricStorageAdapter struct {
 func NewBRICStorageAdapter(config *BRICStorageConfig, logger *zap.Logger) ports.BRICStorageAdapter {
 	// Configure HTTP client
 	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: config.InsecureSkipVerify,
-		},
+		TLSClientConfig:     security.ClientTLSConfig(config.MinTLSVersion, config.InsecureSkipVerify),
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 100,
 		IdleConnTimeout:     90 * time.Second,
@@ -376,6 +377,7 @@ func (a *bricStorageAdapter) sendRequest(ctx context.Context, xmlRequest string,
 		}
 
 		httpReq.Header.Set("Content-Type", "application/xml")
+		observability.InjectTraceContext(ctx, httpReq.Header)
 
 		// Send request
 		startTime := time.Now()
@@ -406,7 +408,7 @@ func (a *bricStorageAdapter) sendRequest(ctx context.Context, xmlRequest string,
 		if err := xml.Unmarshal(body, &xmlResp); err != nil {
 			a.logger.Error("Failed to parse XML response",
 				zap.Error(err),
-				zap.String("body", string(body)),
+				zap.String("body", security.SanitizeBody(string(body))),
 			)
 			return nil, fmt.Errorf("failed to parse XML response: %w", err)
 		}
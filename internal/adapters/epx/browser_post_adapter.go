@@ -166,6 +166,7 @@ func (a *browserPostAdapter) ParseRedirectResponse(params map[string][]string) (
 		ProcessedAt:  processedAt,
 		RawParams:    rawParams,
 	}
+	response.CardFundingType = getValue("AUTH_CARD_FUNDING_TYPE")
 
 	a.logger.Info("Parsed Browser Post response",
 		zap.String("auth_guid", response.AuthGUID),
@@ -0,0 +1,52 @@
+package epx
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Known EPX hosts. Production and the sandbox share epxnow.com for Key Exchange and Browser Post,
+// where the sandbox is told apart by its path (key_exchange_sandbox, browser_post_sandbox).
+const (
+	productionHost = "epxnow.com"
+	sandboxHost    = "epxuap.com"
+)
+
+// ValidateEnvironmentURL returns an error when rawURL is a known EPX endpoint of the other environment,
+// e.g. the sandbox host configured for a production deployment. Unknown hosts (local mocks, proxies) pass.
+func ValidateEnvironmentURL(environment, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid EPX URL %q: %w", rawURL, err)
+	}
+
+	target := urlEnvironment(u)
+	if target == "" {
+		return nil
+	}
+	if (environment == "production") != (target == "production") {
+		return fmt.Errorf("EPX URL %s is a %s endpoint but the EPX environment is %s", u.Redacted(), target, environment)
+	}
+	return nil
+}
+
+// urlEnvironment returns "production" or "sandbox" for a known EPX endpoint, or "" for any other host
+func urlEnvironment(u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case hostIs(host, sandboxHost), strings.Contains(host, "sandbox"):
+		return "sandbox"
+	case hostIs(host, productionHost):
+		if strings.Contains(strings.ToLower(u.Path), "sandbox") {
+			return "sandbox"
+		}
+		return "production"
+	}
+	return ""
+}
+
+// hostIs reports whether host is domain or one of its subdomains
+func hostIs(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,44 @@
+package epx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEnvironmentURL(t *testing.T) {
+	tests := []struct {
+		environment string
+		url         string
+		wantErr     bool
+	}{
+		{"sandbox", "https://secure.epxuap.com", false},
+		{"sandbox", "https://epxnow.com/epx/key_exchange_sandbox", false},
+		{"sandbox", "https://sandbox.north.com", false},
+		{"sandbox", "https://epxnow.com/epx/server_post", true},
+		{"sandbox", "https://epxnow.com/epx/key_exchange", true},
+		{"production", "https://epxnow.com/epx/server_post", false},
+		{"production", "https://epxnow.com/epx/key_exchange", false},
+		{"production", "https://secure.epxuap.com", true},
+		{"production", "https://epxnow.com/epx/browser_post_sandbox", true},
+		{"production", "http://localhost:8081", false}, // Mocks aren't EPX hosts
+		{"sandbox", "http://epx-mock:8081", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateEnvironmentURL(tt.environment, tt.url)
+		if tt.wantErr {
+			assert.Error(t, err, "%s through %s", tt.environment, tt.url)
+		} else {
+			assert.NoError(t, err, "%s through %s", tt.environment, tt.url)
+		}
+	}
+
+	// Every default endpoint matches its own environment
+	for _, env := range []string{"sandbox", "production"} {
+		assert.NoError(t, ValidateEnvironmentURL(env, DefaultServerPostConfig(env).BaseURL))
+		assert.NoError(t, ValidateEnvironmentURL(env, DefaultBrowserPostConfig(env).PostURL))
+		assert.NoError(t, ValidateEnvironmentURL(env, DefaultKeyExchangeConfig(env).BaseURL))
+		assert.NoError(t, ValidateEnvironmentURL(env, DefaultBRICStorageConfig(env).BaseURL))
+	}
+}
@@ -2,7 +2,6 @@ package epx
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +11,8 @@ import (
 	"time"
 
 	"github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/pkg/observability"
+	"github.com/kevin07696/payment-service/pkg/security"
 	"go.uber.org/zap"
 )
 
@@ -27,6 +28,7 @@ type KeyExchangeConfig struct {
 
 	// TLS configuration (production should verify certificates)
 	InsecureSkipVerify bool
+	MinTLSVersion      uint16 // Lowest TLS version negotiated (tls.VersionTLS12 or tls.VersionTLS13)
 
 	// TAC expiration duration (default: 4 hours per EPX documentation)
 	TACExpiration time.Duration
@@ -43,7 +45,8 @@ func DefaultKeyExchangeConfig(environment string) *KeyExchangeConfig {
 		BaseURL:            baseURL,
 		Timeout:            30 * time.Second,
 		InsecureSkipVerify: environment == "sandbox", // Only skip verification in sandbox
-		TACExpiration:      4 * time.Hour,            // EPX TAC expires in 4 hours
+		MinTLSVersion:      security.DefaultMinTLSVersion,
+		TACExpiration:      4 * time.Hour, // EPX TAC expires in 4 hours
 	}
 }
 
@@ -58,9 +61,7 @@ type keyExchangeAdapter struct {
 func NewKeyExchangeAdapter(config *KeyExchangeConfig, logger *zap.Logger) ports.KeyExchangeAdapter {
 	// Configure HTTP client with timeout and TLS settings
 	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: config.InsecureSkipVerify,
-		},
+		TLSClientConfig:     security.ClientTLSConfig(config.MinTLSVersion, config.InsecureSkipVerify),
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 100,
 		IdleConnTimeout:     90 * time.Second,
@@ -78,6 +79,21 @@ func NewKeyExchangeAdapter(config *KeyExchangeConfig, logger *zap.Logger) ports.
 	}
 }
 
+// Ping sends a HEAD request to the Key Exchange endpoint; any HTTP status means EPX is reachable
+func (a *keyExchangeAdapter) Ping(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodHead, a.config.BaseURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpResp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("key exchange unreachable: %w", err)
+	}
+	httpResp.Body.Close()
+	return nil
+}
+
 // GetTAC requests a Terminal Authorization Code from EPX Key Exchange service
 // Based on EPX Browser Post API documentation - Key Exchange Request (page 6)
 func (a *keyExchangeAdapter) GetTAC(ctx context.Context, req *ports.KeyExchangeRequest) (*ports.KeyExchangeResponse, error) {
@@ -104,6 +120,7 @@ func (a *keyExchangeAdapter) GetTAC(ctx context.Context, req *ports.KeyExchangeR
 	}
 
 	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	observability.InjectTraceContext(ctx, httpReq.Header)
 
 	// Send request to EPX
 	startTime := time.Now()
@@ -134,7 +151,7 @@ func (a *keyExchangeAdapter) GetTAC(ctx context.Context, req *ports.KeyExchangeR
 	if httpResp.StatusCode != http.StatusOK {
 		a.logger.Error("EPX Key Exchange returned error",
 			zap.Int("status_code", httpResp.StatusCode),
-			zap.String("body", string(body)),
+			zap.String("body", security.SanitizeBody(string(body))),
 		)
 		return nil, fmt.Errorf("EPX returned status %d: %s", httpResp.StatusCode, string(body))
 	}
@@ -144,7 +161,7 @@ func (a *keyExchangeAdapter) GetTAC(ctx context.Context, req *ports.KeyExchangeR
 	if err != nil {
 		a.logger.Error("Failed to parse Key Exchange response",
 			zap.Error(err),
-			zap.String("body", string(body)),
+			zap.String("body", security.SanitizeBody(string(body))),
 		)
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
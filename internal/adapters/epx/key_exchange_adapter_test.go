@@ -0,0 +1,28 @@
+package epx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// TestKeyExchangePing tests that any HTTP answer counts as reachable and a dead endpoint does not
+func TestKeyExchangePing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+
+	config := DefaultKeyExchangeConfig("sandbox")
+	config.BaseURL = srv.URL
+	adapter := NewKeyExchangeAdapter(config, zap.NewNop())
+
+	assert.NoError(t, adapter.Ping(context.Background()))
+
+	srv.Close()
+	assert.Error(t, adapter.Ping(context.Background()))
+}
@@ -0,0 +1,105 @@
+package epx
+
+import (
+	pkgerrors "github.com/kevin07696/payment-service/pkg/errors"
+)
+
+// responseCode describes how an EPX AUTH_RESP code should be handled
+type responseCode struct {
+	message   string
+	category  pkgerrors.ErrorCategory
+	retriable bool
+}
+
+// responseCodes maps EPX AUTH_RESP codes (ISO 8583 based) to error categories.
+// Retriable means the same request may succeed later without cardholder action.
+var responseCodes = map[string]responseCode{
+	"00": {"Approved", pkgerrors.CategoryApproved, false},
+	"85": {"Approved (no reason to decline)", pkgerrors.CategoryApproved, false},
+
+	// Hard declines
+	"01": {"Refer to card issuer", pkgerrors.CategoryDeclined, false},
+	"02": {"Refer to card issuer, special condition", pkgerrors.CategoryDeclined, false},
+	"05": {"Do not honor", pkgerrors.CategoryDeclined, false},
+	"55": {"Incorrect PIN", pkgerrors.CategoryDeclined, false},
+	"57": {"Transaction not permitted to cardholder", pkgerrors.CategoryDeclined, false},
+	"62": {"Restricted card", pkgerrors.CategoryDeclined, false},
+	"65": {"Exceeds withdrawal frequency limit", pkgerrors.CategoryDeclined, false},
+	"75": {"PIN tries exceeded", pkgerrors.CategoryDeclined, false},
+	"R0": {"Stop payment order", pkgerrors.CategoryDeclined, false},
+	"R1": {"Revocation of authorization order", pkgerrors.CategoryDeclined, false},
+
+	// Funds
+	"51": {"Insufficient funds", pkgerrors.CategoryInsufficientFunds, false},
+	"61": {"Exceeds withdrawal amount limit", pkgerrors.CategoryInsufficientFunds, false},
+
+	// Card data
+	"14": {"Invalid card number", pkgerrors.CategoryInvalidCard, false},
+	"15": {"No such issuer", pkgerrors.CategoryInvalidCard, false},
+	"78": {"No account", pkgerrors.CategoryInvalidCard, false},
+	"82": {"CVV validation failed", pkgerrors.CategoryInvalidCard, false},
+	"N7": {"CVV2 mismatch", pkgerrors.CategoryInvalidCard, false},
+	"54": {"Expired card", pkgerrors.CategoryExpiredCard, false},
+
+	// Fraud / lost / stolen
+	"04": {"Pick up card", pkgerrors.CategoryFraud, false},
+	"07": {"Pick up card, special condition", pkgerrors.CategoryFraud, false},
+	"41": {"Lost card", pkgerrors.CategoryFraud, false},
+	"43": {"Stolen card", pkgerrors.CategoryFraud, false},
+	"59": {"Suspected fraud", pkgerrors.CategoryFraud, false},
+
+	// Request problems (fix the request, don't retry as-is)
+	"03": {"Invalid merchant", pkgerrors.CategoryInvalidRequest, false},
+	"12": {"Invalid transaction", pkgerrors.CategoryInvalidRequest, false},
+	"13": {"Invalid amount", pkgerrors.CategoryInvalidRequest, false},
+	"58": {"Transaction not permitted to terminal", pkgerrors.CategoryInvalidRequest, false},
+	"94": {"Duplicate transaction", pkgerrors.CategoryInvalidRequest, false},
+
+	// Transient issuer/network failures
+	"19": {"Re-enter transaction", pkgerrors.CategorySystemError, true},
+	"91": {"Issuer or switch unavailable", pkgerrors.CategorySystemError, true},
+	"92": {"Unable to route transaction", pkgerrors.CategorySystemError, true},
+	"96": {"System malfunction", pkgerrors.CategorySystemError, true},
+}
+
+// MapResponseCode converts a non-approved EPX AUTH_RESP code into a categorized PaymentError.
+// Returns nil for approvals. Unknown codes are treated as non-retriable declines.
+func MapResponseCode(authResp, authRespText string) *pkgerrors.PaymentError {
+	code, ok := responseCodes[authResp]
+	if !ok {
+		code = responseCode{message: "Transaction declined", category: pkgerrors.CategoryDeclined}
+	}
+	if code.category == pkgerrors.CategoryApproved {
+		return nil
+	}
+
+	paymentErr := pkgerrors.NewPaymentError("EPX_"+authResp, code.message, code.category, code.retriable)
+	paymentErr.GatewayMessage = authRespText
+	paymentErr.Details["auth_resp"] = authResp
+	return paymentErr
+}
+
+// isServerPostApproval is the Server Post approval rule (only "00" approves a transaction)
+func isServerPostApproval(authResp string) bool {
+	return authResp == "00"
+}
+
+// declineError categorizes a non-approved response (nil when approved)
+func declineError(isApproved bool, authResp, authRespText string) *pkgerrors.PaymentError {
+	if isApproved {
+		return nil
+	}
+	if paymentErr := MapResponseCode(authResp, authRespText); paymentErr != nil {
+		return paymentErr
+	}
+	// Approval code outside the adapter's approval rule (e.g. "85" on a sale) - treat as a decline
+	return pkgerrors.NewPaymentError("EPX_"+authResp, "Transaction not approved", pkgerrors.CategoryDeclined, false)
+}
+
+// newGatewayError wraps a failure that happened before EPX returned an AUTH_RESP code
+func newGatewayError(message string, category pkgerrors.ErrorCategory, retriable bool, err error) *pkgerrors.PaymentError {
+	paymentErr := pkgerrors.NewPaymentError("EPX_GATEWAY", message, category, retriable)
+	paymentErr.GatewayMessage = err.Error()
+	paymentErr.Cause = err
+	return paymentErr
+}
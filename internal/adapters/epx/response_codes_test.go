@@ -0,0 +1,126 @@
+package epx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kevin07696/payment-service/internal/adapters/ports"
+	pkgerrors "github.com/kevin07696/payment-service/pkg/errors"
+)
+
+func TestMapResponseCode(t *testing.T) {
+	tests := []struct {
+		name          string
+		authResp      string
+		wantNil       bool
+		wantCategory  pkgerrors.ErrorCategory
+		wantRetriable bool
+		wantDecline   bool
+	}{
+		{"approved", "00", true, "", false, false},
+		{"do not honor", "05", false, pkgerrors.CategoryDeclined, false, true},
+		{"insufficient funds", "51", false, pkgerrors.CategoryInsufficientFunds, false, true},
+		{"expired card", "54", false, pkgerrors.CategoryExpiredCard, false, true},
+		{"invalid card number", "14", false, pkgerrors.CategoryInvalidCard, false, true},
+		{"stolen card", "43", false, pkgerrors.CategoryFraud, false, true},
+		{"invalid amount", "13", false, pkgerrors.CategoryInvalidRequest, false, false},
+		{"issuer unavailable", "91", false, pkgerrors.CategorySystemError, true, false},
+		{"system malfunction", "96", false, pkgerrors.CategorySystemError, true, false},
+		{"unknown code", "RR", false, pkgerrors.CategoryDeclined, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentErr := MapResponseCode(tt.authResp, "ISSUER TEXT")
+			if tt.wantNil {
+				assert.Nil(t, paymentErr)
+				return
+			}
+
+			require.NotNil(t, paymentErr)
+			assert.Equal(t, "EPX_"+tt.authResp, paymentErr.Code)
+			assert.Equal(t, tt.wantCategory, paymentErr.Category)
+			assert.Equal(t, tt.wantRetriable, paymentErr.IsRetriable)
+			assert.Equal(t, tt.wantDecline, paymentErr.IsDecline())
+			assert.Equal(t, "ISSUER TEXT", paymentErr.GatewayMessage)
+		})
+	}
+}
+
+func TestParseKeyValueResponse_SetsDecline(t *testing.T) {
+	adapter := newTestAdapter(t)
+
+	approved, err := adapter.parseKeyValueResponse(url.Values{
+		"AUTH_GUID": {"09LMQ886L2K2W11MPX1"},
+		"AUTH_RESP": {"00"},
+	}, &ports.ServerPostRequest{})
+	require.NoError(t, err)
+	assert.Nil(t, approved.Decline)
+
+	declined, err := adapter.parseKeyValueResponse(url.Values{
+		"AUTH_GUID":      {"09LMQ886L2K2W11MPX2"},
+		"AUTH_RESP":      {"51"},
+		"AUTH_RESP_TEXT": {"INSUFF FUNDS"},
+	}, &ports.ServerPostRequest{})
+	require.NoError(t, err)
+	assert.False(t, declined.IsApproved)
+	require.NotNil(t, declined.Decline)
+	assert.Equal(t, pkgerrors.CategoryInsufficientFunds, declined.Decline.Category)
+	assert.False(t, declined.Decline.IsRetriable)
+}
+
+func TestClassifyResponse_MatchesParsedResponse(t *testing.T) {
+	adapter := newTestAdapter(t)
+
+	for _, code := range []string{"00", "05", "51", "54", "14", "43", "13", "85", "91", "96", "RR"} {
+		t.Run(code, func(t *testing.T) {
+			parsed, err := adapter.parseKeyValueResponse(url.Values{
+				"AUTH_GUID":      {"09LMQ886L2K2W11MPX3"},
+				"AUTH_RESP":      {code},
+				"AUTH_RESP_TEXT": {"ISSUER TEXT"},
+			}, &ports.ServerPostRequest{})
+			require.NoError(t, err)
+
+			classified := adapter.ClassifyResponse(code, "ISSUER TEXT")
+			assert.Equal(t, code, classified.AuthResp)
+			assert.Equal(t, parsed.IsApproved, classified.IsApproved)
+			assert.Equal(t, parsed.Decline, classified.Decline)
+		})
+	}
+}
+
+func TestProcessTransaction_GatewayFailureIsRetriable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close() // Connection refused
+
+	adapter := newTestAdapter(t)
+	adapter.config.BaseURL = server.URL
+	adapter.config.MaxRetries = 0
+
+	_, err := adapter.ProcessTransaction(context.Background(), &ports.ServerPostRequest{
+		CustNbr:         "9001",
+		MerchNbr:        "900300",
+		DBAnbr:          "2",
+		TerminalNbr:     "77",
+		TransactionType: ports.TransactionTypeSale,
+		Amount:          "10.00",
+		TranNbr:         "12345",
+		AccountNumber:   strPtr("4111111111111111"),
+		ExpirationDate:  strPtr("1225"),
+		CVV:             strPtr("123"),
+	})
+	require.Error(t, err)
+
+	var paymentErr *pkgerrors.PaymentError
+	require.True(t, errors.As(err, &paymentErr))
+	assert.Equal(t, pkgerrors.CategoryNetworkError, paymentErr.Category)
+	assert.True(t, paymentErr.IsRetriable)
+	assert.False(t, paymentErr.IsDecline())
+}
@@ -2,11 +2,10 @@ package epx
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -14,6 +13,9 @@ import (
 	"time"
 
 	"github.com/kevin07696/payment-service/internal/adapters/ports"
+	pkgerrors "github.com/kevin07696/payment-service/pkg/errors"
+	"github.com/kevin07696/payment-service/pkg/observability"
+	"github.com/kevin07696/payment-service/pkg/security"
 	"go.uber.org/zap"
 )
 
@@ -29,19 +31,33 @@ type ServerPostConfig struct {
 	// Production: epxnow.com:8086
 	SocketEndpoint string
 
-	// HTTP client timeout
+	// Default per-operation timeout for HTTPS POST (ServerPostRequest.Timeout overrides it)
 	Timeout time.Duration
 
+	// Optional per-transaction-type timeouts (e.g. longer for captures and ACH).
+	// Used when the request has no Timeout; falls back to Timeout / SocketTimeout.
+	OperationTimeouts map[ports.TransactionType]time.Duration
+
 	// Socket connection timeout
 	SocketTimeout time.Duration
 
+	// Socket connection pool (XML Socket method)
+	SocketPoolSize    int           // Max open socket connections
+	SocketMaxIdle     int           // Max idle connections kept for reuse
+	SocketIdleTimeout time.Duration // Idle connections older than this are closed instead of reused
+
 	// TLS configuration
 	InsecureSkipVerify bool
+	MinTLSVersion      uint16 // Lowest TLS version negotiated (tls.VersionTLS12 or tls.VersionTLS13)
 
 	// Retry configuration
 	MaxRetries      int
 	RetryDelay      time.Duration
 	RetryableErrors []string // Error codes that should trigger retry
+
+	// Field the statement descriptor is sent in. The descriptor field isn't in EPX's Server Post reference,
+	// so nothing is sent until the name EPX confirms for the merchant account is configured.
+	SoftDescriptorField string
 }
 
 // DefaultServerPostConfig returns default configuration for Server Post adapter
@@ -58,7 +74,11 @@ func DefaultServerPostConfig(environment string) *ServerPostConfig {
 		SocketEndpoint:     socketEndpoint,
 		Timeout:            30 * time.Second,
 		SocketTimeout:      30 * time.Second, // EPX socket stays open 30 seconds
+		SocketPoolSize:     10,
+		SocketMaxIdle:      5,
+		SocketIdleTimeout:  25 * time.Second, // Below EPX's 30 second keep-alive
 		InsecureSkipVerify: environment == "sandbox",
+		MinTLSVersion:      security.DefaultMinTLSVersion,
 		MaxRetries:         3,
 		RetryDelay:         1 * time.Second,
 		RetryableErrors:    []string{"timeout", "connection", "temporary"},
@@ -69,6 +89,7 @@ func DefaultServerPostConfig(environment string) *ServerPostConfig {
 type serverPostAdapter struct {
 	config     *ServerPostConfig
 	httpClient *http.Client
+	socketPool *socketPool
 	logger     *zap.Logger
 }
 
@@ -76,14 +97,14 @@ type serverPostAdapter struct {
 func NewServerPostAdapter(config *ServerPostConfig, logger *zap.Logger) ports.ServerPostAdapter {
 	// Configure HTTP client
 	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: config.InsecureSkipVerify,
-		},
+		TLSClientConfig:     security.ClientTLSConfig(config.MinTLSVersion, config.InsecureSkipVerify),
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 100,
 		IdleConnTimeout:     90 * time.Second,
 	}
 
+	// Every call is bounded by config.Timeout; a Server Post given longer (per-operation or request
+	// override) gets a client with its own bound, see clientFor
 	httpClient := &http.Client{
 		Timeout:   config.Timeout,
 		Transport: transport,
@@ -92,7 +113,14 @@ func NewServerPostAdapter(config *ServerPostConfig, logger *zap.Logger) ports.Se
 	return &serverPostAdapter{
 		config:     config,
 		httpClient: httpClient,
-		logger:     logger,
+		socketPool: newSocketPool(
+			config.SocketEndpoint,
+			config.SocketPoolSize,
+			config.SocketMaxIdle,
+			config.SocketTimeout,
+			config.SocketIdleTimeout,
+		),
+		logger: logger,
 	}
 }
 
@@ -102,7 +130,7 @@ func (a *serverPostAdapter) ProcessTransaction(ctx context.Context, req *ports.S
 	// Validate request
 	if err := a.validateRequest(req); err != nil {
 		a.logger.Error("Invalid Server Post request", zap.Error(err))
-		return nil, fmt.Errorf("invalid request: %w", err)
+		return nil, newGatewayError("invalid request", pkgerrors.CategoryInvalidRequest, false, err)
 	}
 
 	a.logger.Info("Processing EPX Server Post transaction",
@@ -112,16 +140,13 @@ func (a *serverPostAdapter) ProcessTransaction(ctx context.Context, req *ports.S
 	)
 
 	// Build form data
-	formData := a.buildFormData(req)
+	formData := a.buildFormData(req).Encode()
 
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", a.config.BaseURL, strings.NewReader(formData.Encode()))
-	if err != nil {
-		a.logger.Error("Failed to create HTTP request", zap.Error(err))
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// Apply the per-operation deadline (a shorter caller deadline still wins)
+	timeout := a.operationTimeout(req, a.config.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	httpClient := a.clientFor(timeout)
 
 	// Send request with retries
 	var lastErr error
@@ -131,13 +156,33 @@ func (a *serverPostAdapter) ProcessTransaction(ctx context.Context, req *ports.S
 				zap.Int("attempt", attempt),
 				zap.Int("max_retries", a.config.MaxRetries),
 			)
-			time.Sleep(a.config.RetryDelay)
+			select {
+			case <-time.After(a.config.RetryDelay):
+			case <-ctx.Done():
+				return nil, a.deadlineError(ctx, lastErr)
+			}
 		}
 
+		// Create HTTP request (a new one per attempt since the body is consumed)
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", a.config.BaseURL, strings.NewReader(formData))
+		if err != nil {
+			a.logger.Error("Failed to create HTTP request", zap.Error(err))
+			return nil, newGatewayError("failed to create request", pkgerrors.CategoryInvalidRequest, false, err)
+		}
+		httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		observability.InjectTraceContext(ctx, httpReq.Header)
+
 		startTime := time.Now()
-		httpResp, err := a.httpClient.Do(httpReq)
+		httpResp, err := httpClient.Do(httpReq)
 		if err != nil {
 			lastErr = err
+			if ctx.Err() != nil {
+				a.logger.Error("Server Post request exceeded its deadline",
+					zap.Error(err),
+					zap.Duration("elapsed", time.Since(startTime)),
+				)
+				return nil, a.deadlineError(ctx, err)
+			}
 			if a.isRetryable(err) && attempt < a.config.MaxRetries {
 				a.logger.Warn("Retryable error occurred",
 					zap.Error(err),
@@ -149,7 +194,7 @@ func (a *serverPostAdapter) ProcessTransaction(ctx context.Context, req *ports.S
 				zap.Error(err),
 				zap.Duration("elapsed", time.Since(startTime)),
 			)
-			return nil, fmt.Errorf("failed to send request: %w", err)
+			return nil, newGatewayError("failed to send request", pkgerrors.CategoryNetworkError, true, err)
 		}
 		defer httpResp.Body.Close()
 
@@ -157,7 +202,8 @@ func (a *serverPostAdapter) ProcessTransaction(ctx context.Context, req *ports.S
 		body, err := io.ReadAll(httpResp.Body)
 		if err != nil {
 			a.logger.Error("Failed to read response body", zap.Error(err))
-			return nil, fmt.Errorf("failed to read response: %w", err)
+			// Request reached EPX but the outcome is unknown; retrying could double-charge
+			return nil, newGatewayError("failed to read response", pkgerrors.CategoryNetworkError, false, err)
 		}
 
 		a.logger.Info("Received Server Post response",
@@ -171,9 +217,9 @@ func (a *serverPostAdapter) ProcessTransaction(ctx context.Context, req *ports.S
 		if err != nil {
 			a.logger.Error("Failed to parse Server Post response",
 				zap.Error(err),
-				zap.String("body", string(body)),
+				zap.String("body", security.SanitizeBody(string(body))),
 			)
-			return nil, fmt.Errorf("failed to parse response: %w", err)
+			return nil, newGatewayError("failed to parse response", pkgerrors.CategorySystemError, false, err)
 		}
 
 		a.logger.Info("Successfully processed Server Post transaction",
@@ -185,7 +231,7 @@ func (a *serverPostAdapter) ProcessTransaction(ctx context.Context, req *ports.S
 		return response, nil
 	}
 
-	return nil, fmt.Errorf("failed after %d retries: %w", a.config.MaxRetries, lastErr)
+	return nil, newGatewayError(fmt.Sprintf("failed after %d retries", a.config.MaxRetries), pkgerrors.CategoryNetworkError, true, lastErr)
 }
 
 // ProcessTransactionViaSocket sends transaction via XML Socket connection
@@ -194,7 +240,7 @@ func (a *serverPostAdapter) ProcessTransactionViaSocket(ctx context.Context, req
 	// Validate request
 	if err := a.validateRequest(req); err != nil {
 		a.logger.Error("Invalid Server Post request", zap.Error(err))
-		return nil, fmt.Errorf("invalid request: %w", err)
+		return nil, newGatewayError("invalid request", pkgerrors.CategoryInvalidRequest, false, err)
 	}
 
 	a.logger.Info("Processing EPX Server Post via XML Socket",
@@ -206,46 +252,50 @@ func (a *serverPostAdapter) ProcessTransactionViaSocket(ctx context.Context, req
 	// Build XML request
 	xmlData := a.buildXMLRequest(req)
 
-	// Connect to socket with timeout
-	dialer := &net.Dialer{
-		Timeout: a.config.SocketTimeout,
-	}
-
-	conn, err := dialer.DialContext(ctx, "tcp", a.config.SocketEndpoint)
+	// Get a pooled connection (dials if none are idle)
+	conn, reused, err := a.socketPool.get(ctx)
 	if err != nil {
 		a.logger.Error("Failed to connect to EPX socket",
 			zap.Error(err),
 			zap.String("endpoint", a.config.SocketEndpoint),
 		)
-		return nil, fmt.Errorf("failed to connect to socket: %w", err)
+		return nil, newGatewayError("failed to connect to socket", pkgerrors.CategoryNetworkError, true, err)
 	}
-	defer conn.Close()
 
-	// Set read/write deadlines
-	deadline := time.Now().Add(a.config.SocketTimeout)
+	// Set read/write deadlines (a shorter caller deadline still wins)
+	deadline := time.Now().Add(a.operationTimeout(req, a.config.SocketTimeout))
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
 	conn.SetDeadline(deadline)
 
 	// Send XML request
 	startTime := time.Now()
 	_, err = conn.Write([]byte(xmlData))
 	if err != nil {
-		a.logger.Error("Failed to write to socket", zap.Error(err))
-		return nil, fmt.Errorf("failed to write to socket: %w", err)
+		a.socketPool.discard(conn)
+		a.logger.Error("Failed to write to socket", zap.Error(err), zap.Bool("reused_connection", reused))
+		return nil, newGatewayError("failed to write to socket", pkgerrors.CategoryNetworkError, false, err)
 	}
 
 	// Read response
-	buffer := make([]byte, 4096)
-	n, err := conn.Read(buffer)
+	responseXML, reusable, err := readSocketResponse(conn)
 	if err != nil {
-		a.logger.Error("Failed to read from socket", zap.Error(err))
-		return nil, fmt.Errorf("failed to read from socket: %w", err)
+		a.socketPool.discard(conn)
+		a.logger.Error("Failed to read from socket", zap.Error(err), zap.Bool("reused_connection", reused))
+		return nil, newGatewayError("failed to read from socket", pkgerrors.CategoryNetworkError, false, err)
 	}
-
-	responseXML := buffer[:n]
+	if reusable {
+		a.socketPool.put(conn)
+	} else {
+		a.socketPool.discard(conn)
+	}
+	n := len(responseXML)
 
 	a.logger.Info("Received Socket response",
 		zap.Duration("elapsed", time.Since(startTime)),
 		zap.Int("bytes_received", n),
+		zap.Bool("reused_connection", reused),
 	)
 
 	// Parse XML response
@@ -253,9 +303,9 @@ func (a *serverPostAdapter) ProcessTransactionViaSocket(ctx context.Context, req
 	if err != nil {
 		a.logger.Error("Failed to parse Socket response",
 			zap.Error(err),
-			zap.String("xml", string(responseXML)),
+			zap.String("xml", security.SanitizeBody(string(responseXML))),
 		)
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, newGatewayError("failed to parse response", pkgerrors.CategorySystemError, false, err)
 	}
 
 	a.logger.Info("Successfully processed Socket transaction",
@@ -331,13 +381,14 @@ func (a *serverPostAdapter) validateRequest(req *ports.ServerPostRequest) error
 	// Validate transaction type
 	validTypes := map[ports.TransactionType]bool{
 		// Credit Card
-		ports.TransactionTypeSale:          true,
-		ports.TransactionTypeAuthOnly:      true,
-		ports.TransactionTypeCapture:       true,
-		ports.TransactionTypeRefund:        true,
-		ports.TransactionTypeVoid:          true,
-		ports.TransactionTypeReversal:      true,
-		ports.TransactionTypeBRICStorageCC: true,
+		ports.TransactionTypeSale:            true,
+		ports.TransactionTypeAuthOnly:        true,
+		ports.TransactionTypeCapture:         true,
+		ports.TransactionTypeRefund:          true,
+		ports.TransactionTypeVoid:            true,
+		ports.TransactionTypeReversal:        true,
+		ports.TransactionTypeIncrementalAuth: true,
+		ports.TransactionTypeBRICStorageCC:   true,
 		// ACH
 		ports.TransactionTypeACHDebit:       true,
 		ports.TransactionTypeACHCredit:      true,
@@ -420,6 +471,28 @@ func (a *serverPostAdapter) buildFormData(req *ports.ServerPostRequest) url.Valu
 		data.Set("ACI_EXT", *req.ACIExt)
 	}
 
+	// 3-D Secure authentication result (EPX Transaction Specs - 3D Secure)
+	if req.CAVV != nil && *req.CAVV != "" {
+		data.Set("CAVV_UCAF", *req.CAVV)
+	}
+
+	if req.ECI != nil && *req.ECI != "" {
+		data.Set("CAVV_RESP", *req.ECI)
+	}
+
+	if req.ThreeDSVersion != nil && *req.ThreeDSVersion != "" {
+		data.Set("TDS_VER", *req.ThreeDSVersion)
+	}
+
+	if req.DSTransactionID != nil && *req.DSTransactionID != "" {
+		data.Set("DIRECTORY_SERVER_TRAN_ID", *req.DSTransactionID)
+	}
+
+	// Statement descriptor (overrides the DBA name on the cardholder's statement)
+	if req.SoftDescriptor != nil && *req.SoftDescriptor != "" && a.config.SoftDescriptorField != "" {
+		data.Set(a.config.SoftDescriptorField, *req.SoftDescriptor)
+	}
+
 	// Billing information
 	if req.FirstName != nil && *req.FirstName != "" {
 		data.Set("FIRST_NAME", *req.FirstName)
@@ -514,6 +587,17 @@ func (a *serverPostAdapter) parseResponse(body []byte, req *ports.ServerPostRequ
 	return a.parseXMLResponse(body, req)
 }
 
+// ClassifyResponse derives approval and the decline mapping for an AUTH_RESP code exactly as a live response is parsed
+func (a *serverPostAdapter) ClassifyResponse(authResp, authRespText string) *ports.ServerPostResponse {
+	isApproved := isServerPostApproval(authResp)
+	return &ports.ServerPostResponse{
+		AuthResp:     authResp,
+		AuthRespText: authRespText,
+		IsApproved:   isApproved,
+		Decline:      declineError(isApproved, authResp, authRespText),
+	}
+}
+
 // parseKeyValueResponse parses URL-encoded key-value response
 func (a *serverPostAdapter) parseKeyValueResponse(params url.Values, req *ports.ServerPostRequest) (*ports.ServerPostResponse, error) {
 	authGUID := params.Get("AUTH_GUID")
@@ -526,14 +610,15 @@ func (a *serverPostAdapter) parseKeyValueResponse(params url.Values, req *ports.
 		return nil, fmt.Errorf("AUTH_RESP is missing from response")
 	}
 
-	isApproved := authResp == "00"
+	isApproved := isServerPostApproval(authResp)
 
-	return &ports.ServerPostResponse{
+	response := &ports.ServerPostResponse{
 		AuthGUID:     authGUID,
 		AuthResp:     authResp,
 		AuthCode:     params.Get("AUTH_CODE"),
 		AuthRespText: params.Get("AUTH_RESP_TEXT"),
 		IsApproved:   isApproved,
+		Decline:      declineError(isApproved, authResp, params.Get("AUTH_RESP_TEXT")),
 		AuthCardType: params.Get("AUTH_CARD_TYPE"),
 		AuthAVS:      params.Get("AUTH_AVS"),
 		AuthCVV2:     params.Get("AUTH_CVV2"),
@@ -542,7 +627,9 @@ func (a *serverPostAdapter) parseKeyValueResponse(params url.Values, req *ports.
 		Amount:       params.Get("AMOUNT"),
 		ProcessedAt:  time.Now(),
 		RawXML:       "",
-	}, nil
+	}
+	response.CardFundingType = params.Get("AUTH_CARD_FUNDING_TYPE")
+	return response, nil
 }
 
 // parseXMLResponse parses XML response from socket or HTTPS POST
@@ -568,14 +655,15 @@ func (a *serverPostAdapter) parseXMLResponse(body []byte, req *ports.ServerPostR
 		return nil, fmt.Errorf("AUTH_RESP is missing from XML response")
 	}
 
-	isApproved := authResp == "00"
+	isApproved := isServerPostApproval(authResp)
 
-	return &ports.ServerPostResponse{
+	response := &ports.ServerPostResponse{
 		AuthGUID:     authGUID,
 		AuthResp:     authResp,
 		AuthCode:     fieldMap["AUTH_CODE"],
 		AuthRespText: fieldMap["AUTH_RESP_TEXT"],
 		IsApproved:   isApproved,
+		Decline:      declineError(isApproved, authResp, fieldMap["AUTH_RESP_TEXT"]),
 		AuthCardType: fieldMap["AUTH_CARD_TYPE"],
 		AuthAVS:      fieldMap["AUTH_AVS"],
 		AuthCVV2:     fieldMap["AUTH_CVV2"],
@@ -584,7 +672,49 @@ func (a *serverPostAdapter) parseXMLResponse(body []byte, req *ports.ServerPostR
 		Amount:       fieldMap["AMOUNT"],
 		ProcessedAt:  time.Now(),
 		RawXML:       string(body),
-	}, nil
+	}
+	response.CardFundingType = fieldMap["AUTH_CARD_FUNDING_TYPE"]
+	return response, nil
+}
+
+// operationTimeout returns the request's timeout override, the transaction type's timeout,
+// or the configured default, in that order
+func (a *serverPostAdapter) operationTimeout(req *ports.ServerPostRequest, configured time.Duration) time.Duration {
+	if req.Timeout > 0 {
+		return req.Timeout
+	}
+	if timeout, ok := a.config.OperationTimeouts[req.TransactionType]; ok && timeout > 0 {
+		return timeout
+	}
+	return configured
+}
+
+// Close closes the idle pooled socket connections; connections still in use are closed when they are returned
+func (a *serverPostAdapter) Close() {
+	a.socketPool.close()
+}
+
+// clientFor returns the HTTP client for an operation given timeout. The shared client's Timeout would cut
+// a longer operation short, so one is given a copy (same transport) whose Timeout is the operation's.
+func (a *serverPostAdapter) clientFor(timeout time.Duration) *http.Client {
+	if a.httpClient.Timeout == 0 || timeout <= a.httpClient.Timeout {
+		return a.httpClient
+	}
+	client := *a.httpClient
+	client.Timeout = timeout
+	return &client
+}
+
+// deadlineError reports an operation that ran out of time. The request may have reached EPX,
+// so it is not retriable as-is (check the transaction status before resubmitting).
+func (a *serverPostAdapter) deadlineError(ctx context.Context, err error) error {
+	if err == nil {
+		err = ctx.Err()
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return newGatewayError("request canceled", pkgerrors.CategoryNetworkError, false, err)
+	}
+	return newGatewayError("request timed out", pkgerrors.CategoryNetworkError, false, err)
 }
 
 // isRetryable determines if an error should trigger a retry
@@ -1,10 +1,16 @@
 package epx
 
 import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/kevin07696/payment-service/internal/adapters/ports"
+	pkgerrors "github.com/kevin07696/payment-service/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -136,6 +142,8 @@ func TestBuildFormData(t *testing.T) {
 				assert.Equal(t, "25.00", formData["AMOUNT"][0])
 				assert.Equal(t, "09TESTGUID123456789", formData["ORIG_AUTH_GUID"][0])
 				assert.Equal(t, "Z", formData["CARD_ENT_METH"][0])
+				assert.NotContains(t, formData, "CAVV_UCAF", "3DS fields are only sent when present")
+				assert.NotContains(t, formData, "ECI_IND")
 			},
 		},
 		{
@@ -161,6 +169,30 @@ func TestBuildFormData(t *testing.T) {
 				assert.Equal(t, "09STORAGEGUID123456", formData["ORIG_AUTH_GUID"][0])
 			},
 		},
+		{
+			name: "sale with 3-D Secure result",
+			request: &ports.ServerPostRequest{
+				CustNbr:         "9001",
+				MerchNbr:        "900300",
+				DBAnbr:          "2",
+				TerminalNbr:     "77",
+				TransactionType: ports.TransactionTypeSale,
+				Amount:          "49.99",
+				TranNbr:         "12349",
+				TranGroup:       "12349",
+				AuthGUID:        "09LMQ886L2K2W11MPX1",
+				CAVV:            strPtr("AAABBEg0VhI0VniQEjRWAAAAAAA="),
+				ECI:             strPtr("05"),
+				ThreeDSVersion:  strPtr("2"),
+				DSTransactionID: strPtr("f25084f0-5b16-4c0a-ae5d-b24808a95e4b"),
+			},
+			validate: func(t *testing.T, formData map[string][]string) {
+				assert.Equal(t, "AAABBEg0VhI0VniQEjRWAAAAAAA=", formData["CAVV_UCAF"][0])
+				assert.Equal(t, "05", formData["CAVV_RESP"][0])
+				assert.Equal(t, "2", formData["TDS_VER"][0])
+				assert.Equal(t, "f25084f0-5b16-4c0a-ae5d-b24808a95e4b", formData["DIRECTORY_SERVER_TRAN_ID"][0])
+			},
+		},
 		{
 			name: "BRIC storage with zero amount",
 			request: &ports.ServerPostRequest{
@@ -466,3 +498,134 @@ func BenchmarkValidateRequest(b *testing.B) {
 		_ = adapter.validateRequest(request)
 	}
 }
+
+// newSlowServerPostAdapter points the adapter at a server that responds after delay
+func newSlowServerPostAdapter(t *testing.T, delay time.Duration) (*serverPostAdapter, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	config := DefaultServerPostConfig("sandbox")
+	config.BaseURL = srv.URL
+	config.Timeout = 5 * time.Second
+	config.RetryDelay = 10 * time.Millisecond
+	return NewServerPostAdapter(config, zap.NewNop()).(*serverPostAdapter), &requests
+}
+
+// TestProcessTransaction_ContextDeadline tests that a caller deadline shorter than the configured timeout cancels the EPX call
+func TestProcessTransaction_ContextDeadline(t *testing.T) {
+	adapter, requests := newSlowServerPostAdapter(t, 3*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := adapter.ProcessTransaction(ctx, newSocketTestRequest())
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	assert.Less(t, elapsed, time.Second, "call should be cancelled at the caller's deadline")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), requests.Load(), "timed out requests are not retried")
+
+	var paymentErr *pkgerrors.PaymentError
+	require.ErrorAs(t, err, &paymentErr)
+	assert.False(t, paymentErr.IsRetriable, "outcome is unknown after a timeout")
+}
+
+// TestProcessTransaction_RequestTimeout tests the per-request timeout override
+func TestProcessTransaction_RequestTimeout(t *testing.T) {
+	adapter, _ := newSlowServerPostAdapter(t, 3*time.Second)
+
+	req := newSocketTestRequest()
+	req.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := adapter.ProcessTransaction(context.Background(), req)
+
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestOperationTimeout tests timeout precedence: request, transaction type, configured default
+func TestOperationTimeout(t *testing.T) {
+	adapter := newTestAdapter(t)
+	adapter.config.OperationTimeouts = map[ports.TransactionType]time.Duration{
+		ports.TransactionTypeCapture: 60 * time.Second,
+	}
+
+	sale := &ports.ServerPostRequest{TransactionType: ports.TransactionTypeSale}
+	capture := &ports.ServerPostRequest{TransactionType: ports.TransactionTypeCapture}
+	override := &ports.ServerPostRequest{TransactionType: ports.TransactionTypeCapture, Timeout: 5 * time.Second}
+
+	assert.Equal(t, 30*time.Second, adapter.operationTimeout(sale, 30*time.Second))
+	assert.Equal(t, 60*time.Second, adapter.operationTimeout(capture, 30*time.Second))
+	assert.Equal(t, 5*time.Second, adapter.operationTimeout(override, 30*time.Second))
+}
+
+func TestClientFor_KeepsDefaultBound(t *testing.T) {
+	adapter := newTestAdapter(t)
+	require.Equal(t, 30*time.Second, adapter.httpClient.Timeout, "every call through the shared client is bounded")
+
+	assert.Same(t, adapter.httpClient, adapter.clientFor(5*time.Second))
+	assert.Same(t, adapter.httpClient, adapter.clientFor(30*time.Second))
+
+	longer := adapter.clientFor(90 * time.Second)
+	assert.Equal(t, 90*time.Second, longer.Timeout, "a longer operation isn't cut short by the default")
+	assert.Same(t, adapter.httpClient.Transport, longer.Transport)
+	assert.Equal(t, 30*time.Second, adapter.httpClient.Timeout, "the shared client is unchanged")
+}
+
+// TestProcessTransactionViaSocket_ContextDeadline tests that the socket read honors a short caller deadline
+func TestProcessTransactionViaSocket_ContextDeadline(t *testing.T) {
+	// Accept connections but never respond
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	accepted := make(chan net.Conn, 4)
+	t.Cleanup(func() {
+		listener.Close()
+		for {
+			select {
+			case conn := <-accepted:
+				conn.Close()
+			default:
+				return
+			}
+		}
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			select {
+			case accepted <- conn: // Held open until cleanup
+			default:
+				conn.Close()
+			}
+		}
+	}()
+
+	config := DefaultServerPostConfig("sandbox")
+	config.SocketEndpoint = listener.Addr().String()
+	adapter := NewServerPostAdapter(config, zap.NewNop()).(*serverPostAdapter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = adapter.ProcessTransactionViaSocket(ctx, newSocketTestRequest())
+
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,23 @@
+//go:build !unix
+
+package epx
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// connAlive checks an idle connection with a short read: a timeout means the peer
+// hasn't closed it; EOF, a reset, or unexpected unsolicited data means it is unusable.
+func connAlive(conn net.Conn) bool {
+	if err := conn.SetReadDeadline(time.Now().Add(time.Millisecond)); err != nil {
+		return false
+	}
+	defer conn.SetReadDeadline(time.Time{})
+
+	var buf [1]byte
+	_, err := conn.Read(buf[:])
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,31 @@
+//go:build unix
+
+package epx
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// connAlive checks an idle connection with a non-blocking read: EAGAIN means the peer
+// hasn't closed it; EOF, a reset, or unexpected unsolicited data means it is unusable.
+func connAlive(conn net.Conn) bool {
+	sysConn, ok := conn.(syscall.Conn)
+	if !ok {
+		return true
+	}
+	rawConn, err := sysConn.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	alive := false
+	err = rawConn.Read(func(fd uintptr) bool {
+		var buf [1]byte
+		n, err := syscall.Read(int(fd), buf[:])
+		alive = n < 0 && (errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EWOULDBLOCK))
+		return true // Never wait for readability
+	})
+	return err == nil && alive
+}
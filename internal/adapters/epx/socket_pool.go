@@ -0,0 +1,173 @@
+package epx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// socketResponseEnd terminates an EPX XML Socket response
+var socketResponseEnd = []byte("</RESPONSE>")
+
+// maxSocketResponseSize bounds how much is read while waiting for a complete response
+const maxSocketResponseSize = 64 * 1024
+
+// pooledConn is an idle socket connection with the time it was returned to the pool
+type pooledConn struct {
+	net.Conn
+	idleSince time.Time
+}
+
+// socketPool is a bounded pool of keep-alive TCP connections to the EPX socket endpoint.
+// At most maxOpen connections exist at once; callers block in get until one frees up.
+type socketPool struct {
+	endpoint    string
+	idleTimeout time.Duration
+	maxIdle     int
+
+	slots chan struct{} // one token per open (or opening) connection
+
+	mu     sync.Mutex
+	idle   []*pooledConn // LIFO so the warmest connection is reused first
+	closed bool
+
+	dial func(ctx context.Context, network, address string) (net.Conn, error)
+	now  func() time.Time
+}
+
+// newSocketPool creates a socket pool; maxOpen and maxIdle are clamped to at least 1 and maxOpen
+func newSocketPool(endpoint string, maxOpen, maxIdle int, dialTimeout, idleTimeout time.Duration) *socketPool {
+	if maxOpen < 1 {
+		maxOpen = 1
+	}
+	if maxIdle < 1 || maxIdle > maxOpen {
+		maxIdle = maxOpen
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	return &socketPool{
+		endpoint:    endpoint,
+		idleTimeout: idleTimeout,
+		maxIdle:     maxIdle,
+		slots:       make(chan struct{}, maxOpen),
+		dial:        dialer.DialContext,
+		now:         time.Now,
+	}
+}
+
+// get returns a healthy connection, reusing an idle one when possible.
+// The connection must be returned with put (or discard) exactly once.
+func (p *socketPool) get(ctx context.Context) (net.Conn, bool, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+
+	for {
+		conn := p.popIdle()
+		if conn == nil {
+			break
+		}
+		if p.now().Sub(conn.idleSince) > p.idleTimeout || !connAlive(conn.Conn) {
+			conn.Close()
+			continue
+		}
+		return conn.Conn, true, nil
+	}
+
+	conn, err := p.dial(ctx, "tcp", p.endpoint)
+	if err != nil {
+		<-p.slots
+		return nil, false, err
+	}
+	return conn, false, nil
+}
+
+// put returns a connection to the idle list, or closes it if the pool is full or closed
+func (p *socketPool) put(conn net.Conn) {
+	defer func() { <-p.slots }()
+
+	// Clear the per-request deadline so the health check on reuse isn't affected
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return
+	}
+
+	p.mu.Lock()
+	if p.closed || len(p.idle) >= p.maxIdle {
+		p.mu.Unlock()
+		conn.Close()
+		return
+	}
+	p.idle = append(p.idle, &pooledConn{Conn: conn, idleSince: p.now()})
+	p.mu.Unlock()
+}
+
+// discard closes a connection that errored and releases its slot
+func (p *socketPool) discard(conn net.Conn) {
+	conn.Close()
+	<-p.slots
+}
+
+// close closes all idle connections; connections in use are closed when returned
+func (p *socketPool) close() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	for _, conn := range idle {
+		conn.Close()
+	}
+}
+
+// idleCount returns the number of idle connections
+func (p *socketPool) idleCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.idle)
+}
+
+func (p *socketPool) popIdle() *pooledConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.idle) == 0 {
+		return nil
+	}
+	conn := p.idle[len(p.idle)-1]
+	p.idle = p.idle[:len(p.idle)-1]
+	return conn
+}
+
+// readSocketResponse reads until a complete </RESPONSE> document arrives.
+// reusable is false if the peer closed the connection, so it must not go back to the pool.
+func readSocketResponse(conn net.Conn) (response []byte, reusable bool, err error) {
+	var buf bytes.Buffer
+	chunk := make([]byte, 4096)
+
+	for {
+		n, err := conn.Read(chunk)
+		buf.Write(chunk[:n])
+
+		if bytes.Contains(buf.Bytes(), socketResponseEnd) {
+			return buf.Bytes(), err == nil, nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) && buf.Len() > 0 {
+				// Peer sent a response and hung up (single-shot connection)
+				return buf.Bytes(), false, nil
+			}
+			return nil, false, err
+		}
+		if buf.Len() > maxSocketResponseSize {
+			return nil, false, errors.New("socket response exceeds maximum size")
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package epx
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kevin07696/payment-service/internal/adapters/ports"
+)
+
+const fakeSocketResponse = `<RESPONSE><FIELDS>` +
+	`<FIELD KEY="AUTH_GUID">09LMQ886L2K2W11MPX1</FIELD>` +
+	`<FIELD KEY="AUTH_RESP">00</FIELD>` +
+	`<FIELD KEY="AUTH_CODE">057579</FIELD>` +
+	`</FIELDS></RESPONSE>`
+
+// fakeSocketServer answers each </transaction> with an approved response, or with response when set.
+// closeAfterResponse makes it hang up after every response (a broken keep-alive).
+type fakeSocketServer struct {
+	listener           net.Listener
+	accepted           atomic.Int32
+	closeAfterResponse atomic.Bool
+	wg                 sync.WaitGroup
+
+	mu       sync.Mutex
+	conns    []net.Conn
+	response string
+}
+
+func newFakeSocketServer(t testing.TB) *fakeSocketServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &fakeSocketServer{listener: listener}
+	srv.wg.Add(1)
+	go srv.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		srv.mu.Lock()
+		for _, conn := range srv.conns {
+			conn.Close()
+		}
+		srv.mu.Unlock()
+		srv.wg.Wait()
+	})
+	return srv
+}
+
+func (s *fakeSocketServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.accepted.Add(1)
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSocketServer) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		if _, err := reader.ReadString('>'); err != nil {
+			return
+		}
+		// Read until the end of the request document
+		var request strings.Builder
+		for !strings.HasSuffix(request.String(), "</transaction>") {
+			b, err := reader.ReadByte()
+			if err != nil {
+				return
+			}
+			request.WriteByte(b)
+		}
+		s.mu.Lock()
+		response := s.response
+		s.mu.Unlock()
+		if response == "" {
+			response = fakeSocketResponse
+		}
+		if _, err := conn.Write([]byte(response)); err != nil {
+			return
+		}
+		if s.closeAfterResponse.Load() {
+			return
+		}
+	}
+}
+
+func newSocketTestAdapter(t testing.TB, endpoint string) *serverPostAdapter {
+	config := DefaultServerPostConfig("sandbox")
+	config.SocketEndpoint = endpoint
+	config.SocketTimeout = 2 * time.Second
+	return NewServerPostAdapter(config, zap.NewNop()).(*serverPostAdapter)
+}
+
+func newSocketTestRequest() *ports.ServerPostRequest {
+	return &ports.ServerPostRequest{
+		CustNbr:         "9001",
+		MerchNbr:        "900300",
+		DBAnbr:          "2",
+		TerminalNbr:     "77",
+		TransactionType: ports.TransactionTypeSale,
+		Amount:          "10.00",
+		PaymentType:     ports.PaymentMethodTypeCreditCard,
+		AuthGUID:        "09LMQ886L2K2W11MPX1",
+		TranNbr:         "12345",
+		TranGroup:       "SALE",
+	}
+}
+
+func TestProcessTransactionViaSocket_ReusesConnection(t *testing.T) {
+	srv := newFakeSocketServer(t)
+	adapter := newSocketTestAdapter(t, srv.listener.Addr().String())
+
+	for i := 0; i < 5; i++ {
+		resp, err := adapter.ProcessTransactionViaSocket(context.Background(), newSocketTestRequest())
+		require.NoError(t, err)
+		assert.True(t, resp.IsApproved)
+	}
+
+	assert.Equal(t, int32(1), srv.accepted.Load(), "sequential calls should share one connection")
+	assert.Equal(t, 1, adapter.socketPool.idleCount())
+}
+
+func TestProcessTransactionViaSocket_DiscardsBrokenConnection(t *testing.T) {
+	srv := newFakeSocketServer(t)
+	srv.closeAfterResponse.Store(true)
+	adapter := newSocketTestAdapter(t, srv.listener.Addr().String())
+
+	for i := 0; i < 3; i++ {
+		resp, err := adapter.ProcessTransactionViaSocket(context.Background(), newSocketTestRequest())
+		require.NoError(t, err)
+		assert.True(t, resp.IsApproved)
+	}
+
+	assert.Equal(t, int32(3), srv.accepted.Load(), "closed connections must not be reused")
+}
+
+func TestSocketPool_HealthCheckEvictsClosedIdleConnection(t *testing.T) {
+	srv := newFakeSocketServer(t)
+	pool := newSocketPool(srv.listener.Addr().String(), 2, 2, time.Second, time.Minute)
+
+	conn, reused, err := pool.get(context.Background())
+	require.NoError(t, err)
+	assert.False(t, reused)
+
+	// Simulate the peer dropping the idle connection
+	conn.(*net.TCPConn).CloseRead()
+	pool.put(conn)
+	require.Equal(t, 1, pool.idleCount())
+
+	conn2, reused, err := pool.get(context.Background())
+	require.NoError(t, err)
+	assert.False(t, reused, "dead idle connection should be replaced")
+	assert.Equal(t, 0, pool.idleCount())
+	pool.put(conn2)
+}
+
+func TestSocketPool_IdleTimeout(t *testing.T) {
+	srv := newFakeSocketServer(t)
+	pool := newSocketPool(srv.listener.Addr().String(), 2, 2, time.Second, time.Minute)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+
+	conn, _, err := pool.get(context.Background())
+	require.NoError(t, err)
+	pool.put(conn)
+
+	now = now.Add(2 * time.Minute)
+	conn, reused, err := pool.get(context.Background())
+	require.NoError(t, err)
+	assert.False(t, reused, "connections idle past the timeout are closed")
+	pool.put(conn)
+
+	assert.Eventually(t, func() bool { return srv.accepted.Load() == 2 }, time.Second, 5*time.Millisecond)
+}
+
+func TestSocketPool_BoundsOpenConnections(t *testing.T) {
+	srv := newFakeSocketServer(t)
+	pool := newSocketPool(srv.listener.Addr().String(), 1, 1, time.Second, time.Minute)
+
+	conn, _, err := pool.get(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, err = pool.get(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "get blocks while the pool is exhausted")
+
+	pool.discard(conn)
+	conn, _, err = pool.get(context.Background())
+	require.NoError(t, err)
+	pool.put(conn)
+}
+
+func TestSocketPool_Close(t *testing.T) {
+	srv := newFakeSocketServer(t)
+	pool := newSocketPool(srv.listener.Addr().String(), 2, 2, time.Second, time.Minute)
+
+	conn, _, err := pool.get(context.Background())
+	require.NoError(t, err)
+	pool.put(conn)
+	require.Equal(t, 1, pool.idleCount())
+
+	pool.close()
+	assert.Equal(t, 0, pool.idleCount())
+
+	conn, _, err = pool.get(context.Background())
+	require.NoError(t, err)
+	pool.put(conn)
+	assert.Equal(t, 0, pool.idleCount(), "closed pool doesn't keep connections")
+}
+
+func TestServerPostAdapter_CloseReleasesPooledSockets(t *testing.T) {
+	srv := newFakeSocketServer(t)
+	adapter := newSocketTestAdapter(t, srv.listener.Addr().String())
+
+	_, err := adapter.ProcessTransactionViaSocket(context.Background(), newSocketTestRequest())
+	require.NoError(t, err)
+	require.Equal(t, 1, adapter.socketPool.idleCount(), "keep-alive connection pooled")
+
+	adapter.Close()
+	assert.Equal(t, 0, adapter.socketPool.idleCount(), "shutdown closes pooled connections")
+}
+
+func TestProcessTransactionViaSocket_ParseFailureLogsSanitizedXML(t *testing.T) {
+	srv := newFakeSocketServer(t)
+	srv.mu.Lock()
+	srv.response = `<RESPONSE><FIELDS>` +
+		`<FIELD KEY="AUTH_GUID">09LMQ886L2K2W11MPX1</FIELD>` +
+		`<FIELD KEY="EXP_DATE">2812</FIELD>` +
+		`</FIELDS></RESPONSE>`
+	srv.mu.Unlock()
+	core, logs := observer.New(zap.ErrorLevel)
+	adapter := newSocketTestAdapter(t, srv.listener.Addr().String())
+	adapter.logger = zap.New(core)
+
+	_, err := adapter.ProcessTransactionViaSocket(context.Background(), newSocketTestRequest())
+	require.Error(t, err, "a response without AUTH_RESP can't be parsed")
+
+	entries := logs.FilterMessage("Failed to parse Socket response").All()
+	require.Len(t, entries, 1)
+	logged := entries[0].ContextMap()["xml"].(string)
+	assert.NotContains(t, logged, "09LMQ886L2K2W11MPX1")
+	assert.NotContains(t, logged, ">2812<")
+	assert.Contains(t, logged, `<FIELD KEY="AUTH_GUID">`, "the response's shape is kept for debugging")
+}
+
+// BenchmarkProcessTransactionViaSocket compares pooled connections with dialing per transaction
+func BenchmarkProcessTransactionViaSocket(b *testing.B) {
+	b.Run("pooled", func(b *testing.B) {
+		srv := newFakeSocketServer(b)
+		adapter := newSocketTestAdapter(b, srv.listener.Addr().String())
+		req := newSocketTestRequest()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := adapter.ProcessTransactionViaSocket(context.Background(), req); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("dial_per_transaction", func(b *testing.B) {
+		srv := newFakeSocketServer(b)
+		srv.closeAfterResponse.Store(true)
+		adapter := newSocketTestAdapter(b, srv.listener.Addr().String())
+		req := newSocketTestRequest()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := adapter.ProcessTransactionViaSocket(context.Background(), req); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
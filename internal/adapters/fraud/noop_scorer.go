@@ -0,0 +1,20 @@
+package fraud
+
+import (
+	"context"
+
+	"github.com/kevin07696/payment-service/internal/adapters/ports"
+)
+
+// NoopScorer approves every transaction; it's the payment service's scorer when no fraud service is configured
+type NoopScorer struct{}
+
+// NewNoopScorer creates a fraud scorer that approves everything
+func NewNoopScorer() ports.FraudScorer {
+	return NoopScorer{}
+}
+
+// Score approves the transaction with a zero score
+func (NoopScorer) Score(ctx context.Context, tx *ports.FraudContext) (ports.FraudDecision, float64, string, error) {
+	return ports.FraudDecisionApprove, 0, "", nil
+}
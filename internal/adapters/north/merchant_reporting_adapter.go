@@ -59,11 +59,13 @@ type northDisputeSearchResponse struct {
 			CardBrand          string  `json:"cardBrand"`
 			CardNumberLastFour string  `json:"cardNumberLastFour"`
 			TransactionNumber  string  `json:"transactionNumber"`
+			AuthCode           string  `json:"authCode"`
 			ReasonCode         string  `json:"reasonCode"`
 			ReasonDescription  string  `json:"reasonDescription"`
 			TransactionAmount  float64 `json:"transactionAmount"`
 			TransactionDate    string  `json:"transactionDate"`
 			ChargebackAmount   float64 `json:"chargebackAmount"`
+			ResponseDueDate    string  `json:"responseDueDate"`
 		} `json:"disputes"`
 		Meta struct {
 			TotalDisputes      int `json:"totalDisputes"`
@@ -171,11 +173,13 @@ func (a *merchantReportingAdapter) SearchDisputes(ctx context.Context, req *adap
 			CardBrand:          d.CardBrand,
 			CardNumberLastFour: d.CardNumberLastFour,
 			TransactionNumber:  d.TransactionNumber,
+			AuthCode:           d.AuthCode,
 			ReasonCode:         d.ReasonCode,
 			ReasonDescription:  d.ReasonDescription,
 			TransactionAmount:  d.TransactionAmount,
 			TransactionDate:    d.TransactionDate,
 			ChargebackAmount:   d.ChargebackAmount,
+			ResponseDueDate:    d.ResponseDueDate,
 		}
 	}
 
@@ -190,3 +194,109 @@ func (a *merchantReportingAdapter) SearchDisputes(ctx context.Context, req *adap
 		CurrentResultCount: northResp.Data.Meta.CurrentResultCount,
 	}, nil
 }
+
+// North settlement search response structure
+type northSettlementSearchResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Transactions []struct {
+			AuthGUID          string  `json:"authGuid"`
+			TransactionNumber string  `json:"transactionNumber"`
+			TransactionType   string  `json:"transactionType"`
+			Status            string  `json:"status"`
+			Amount            float64 `json:"amount"`
+			TransactionDate   string  `json:"transactionDate"`
+			SettlementDate    string  `json:"settlementDate"`
+			BatchID           string  `json:"batchId"`
+		} `json:"transactions"`
+		Meta struct {
+			TotalTransactions  int `json:"totalTransactions"`
+			CurrentResultCount int `json:"currentResultCount"`
+		} `json:"meta"`
+	} `json:"data"`
+}
+
+// SearchSettlements retrieves settled transactions for a merchant from North's batch reporting
+func (a *merchantReportingAdapter) SearchSettlements(ctx context.Context, req *adapterports.SettlementSearchRequest) (*adapterports.SettlementSearchResponse, error) {
+	findBy := fmt.Sprintf("byMerchant:%s,fromDate:%s,toDate:%s",
+		req.MerchantID,
+		req.FromDate.Format("2006-01-02"),
+		req.ToDate.Format("2006-01-02"),
+	)
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s/merchant/transactions/mid/search", a.config.BaseURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse endpoint URL: %w", err)
+	}
+	query := reqURL.Query()
+	query.Set("findBy", findBy)
+	reqURL.RawQuery = query.Encode()
+
+	a.logger.Info("Calling North Settlement API",
+		adapterports.String("url", reqURL.String()),
+		adapterports.String("findBy", findBy),
+	)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	startTime := time.Now()
+	resp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		a.logger.Error("North Settlement API request failed",
+			adapterports.Err(err),
+			adapterports.String("elapsed", time.Since(startTime).String()),
+		)
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		a.logger.Error("North Settlement API returned non-200 status",
+			adapterports.Int("status_code", resp.StatusCode),
+			adapterports.String("response_body", string(body)),
+		)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var northResp northSettlementSearchResponse
+	if err := json.Unmarshal(body, &northResp); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	if northResp.Status != "success" {
+		return nil, fmt.Errorf("API returned non-success status: %s", northResp.Status)
+	}
+
+	transactions := make([]*adapterports.SettledTransaction, len(northResp.Data.Transactions))
+	for i, t := range northResp.Data.Transactions {
+		transactions[i] = &adapterports.SettledTransaction{
+			AuthGUID:          t.AuthGUID,
+			TransactionNumber: t.TransactionNumber,
+			TransactionType:   t.TransactionType,
+			Status:            t.Status,
+			Amount:            t.Amount,
+			TransactionDate:   t.TransactionDate,
+			SettlementDate:    t.SettlementDate,
+			BatchID:           t.BatchID,
+		}
+	}
+
+	a.logger.Info("Settlements retrieved successfully",
+		adapterports.Int("total_transactions", northResp.Data.Meta.TotalTransactions),
+		adapterports.Int("current_result_count", northResp.Data.Meta.CurrentResultCount),
+	)
+
+	return &adapterports.SettlementSearchResponse{
+		Transactions:       transactions,
+		TotalTransactions:  northResp.Data.Meta.TotalTransactions,
+		CurrentResultCount: northResp.Data.Meta.CurrentResultCount,
+	}, nil
+}
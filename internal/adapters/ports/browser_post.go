@@ -43,6 +43,8 @@ type BrowserPostResponse struct {
 	AuthAVS      string // Address verification ("Y" = match, "N" = no match, "U" = unavailable)
 	AuthCVV2     string // CVV verification ("M" = match, "N" = no match, "P" = not processed)
 
+	CardFundingType string // Card funding indicator (AUTH_CARD_FUNDING_TYPE: "C" = credit, "D" = debit, "P" = prepaid)
+
 	// Transaction echo-back
 	TranNbr   string // Echo back transaction number
 	TranGroup string // Echo back transaction group
@@ -0,0 +1,48 @@
+package ports
+
+import (
+	"context"
+)
+
+// FraudDecision is a fraud scorer's verdict on a transaction
+type FraudDecision string
+
+const (
+	FraudDecisionApprove FraudDecision = "approve" // Send to EPX
+	FraudDecisionDecline FraudDecision = "decline" // Block before EPX is called
+	FraudDecisionReview  FraudDecision = "review"  // Send to EPX, but flag the transaction for manual review
+)
+
+// Valid reports whether d is one of the decisions above
+func (d FraudDecision) Valid() bool {
+	switch d {
+	case FraudDecisionApprove, FraudDecisionDecline, FraudDecisionReview:
+		return true
+	default:
+		return false
+	}
+}
+
+// FraudContext describes a charge about to be sent to EPX. It never holds card data or BRICs.
+type FraudContext struct {
+	AgentID         string
+	TransactionType string // "charge" (sale), "auth" or "increment"
+	Amount          string
+	Currency        string
+	CustomerID      string                 // Empty for guest transactions
+	CustomerEmail   string                 // Empty when not given
+	PaymentMethodID string                 // Saved payment method; empty for a one-time token
+	ThreeDSecure    bool                   // A 3-D Secure result accompanies the charge
+	Metadata        map[string]interface{} // The merchant's transaction metadata
+}
+
+// FraudScorer defines the port for a fraud scoring service consulted before a sale or authorization
+// Implementation is responsible for:
+//   - Calling the scoring service within the request's deadline
+//   - Mapping the service's verdict to a FraudDecision
+//
+// score is the service's risk score (higher is riskier; its scale is the service's own) and reason a short
+// explanation for logs. An error means no verdict was reached; the payment service's fraud policy then decides.
+type FraudScorer interface {
+	Score(ctx context.Context, tx *FraudContext) (decision FraudDecision, score float64, reason string, err error)
+}
@@ -51,4 +51,8 @@ type KeyExchangeAdapter interface {
 	//   - EPX service is unavailable
 	//   - Request parameters are invalid
 	GetTAC(ctx context.Context, req *KeyExchangeRequest) (*KeyExchangeResponse, error)
+
+	// Ping checks that the Key Exchange endpoint is reachable (any HTTP response counts)
+	// Used by readiness probes; sends no credentials and requests no TAC
+	Ping(ctx context.Context) error
 }
@@ -21,12 +21,14 @@ type Dispute struct {
 	Status             string
 	CardBrand          string
 	CardNumberLastFour string
-	TransactionNumber  string
+	TransactionNumber  string // EPX TRAN_NBR of the disputed transaction
+	AuthCode           string // Authorization code of the disputed transaction, when North has it
 	ReasonCode         string
 	ReasonDescription  string
 	TransactionAmount  float64
 	TransactionDate    string
 	ChargebackAmount   float64
+	ResponseDueDate    string // Last day to respond (YYYY-MM-DD); empty when North doesn't give one
 }
 
 // DisputeSearchResponse contains dispute search results
@@ -36,8 +38,37 @@ type DisputeSearchResponse struct {
 	CurrentResultCount int
 }
 
+// SettlementSearchRequest contains parameters for searching settled transactions
+type SettlementSearchRequest struct {
+	MerchantID string
+	FromDate   time.Time // Transaction date range start (inclusive)
+	ToDate     time.Time // Transaction date range end (inclusive)
+}
+
+// SettledTransaction represents a transaction as settled by EPX
+type SettledTransaction struct {
+	AuthGUID          string // EPX transaction token (BRIC) - matches transactions.auth_guid
+	TransactionNumber string
+	TransactionType   string // sale, capture, refund
+	Status            string // settled, rejected
+	Amount            float64
+	TransactionDate   string
+	SettlementDate    string
+	BatchID           string
+}
+
+// SettlementSearchResponse contains settled transaction search results
+type SettlementSearchResponse struct {
+	Transactions       []*SettledTransaction
+	TotalTransactions  int
+	CurrentResultCount int
+}
+
 // MerchantReportingAdapter defines the port for merchant reporting operations
 type MerchantReportingAdapter interface {
 	// SearchDisputes retrieves dispute/chargeback data for a merchant
 	SearchDisputes(ctx context.Context, req *DisputeSearchRequest) (*DisputeSearchResponse, error)
+
+	// SearchSettlements retrieves settled (batched) transactions for a merchant
+	SearchSettlements(ctx context.Context, req *SettlementSearchRequest) (*SettlementSearchResponse, error)
 }
@@ -3,6 +3,8 @@ package ports
 import (
 	"context"
 	"time"
+
+	pkgerrors "github.com/kevin07696/payment-service/pkg/errors"
 )
 
 // TransactionType represents the type of EPX Server Post transaction
@@ -10,12 +12,13 @@ type TransactionType string
 
 const (
 	// Credit Card E-commerce Transactions
-	TransactionTypeSale     TransactionType = "CCE1" // CC Ecommerce Sale (auth + capture)
-	TransactionTypeAuthOnly TransactionType = "CCE2" // CC Ecommerce Auth Only
-	TransactionTypeCapture  TransactionType = "CCE4" // CC Ecommerce Capture
-	TransactionTypeRefund   TransactionType = "CCE9" // CC Ecommerce Refund/Credit
-	TransactionTypeVoid     TransactionType = "CCEX" // CC Ecommerce Void
-	TransactionTypeReversal TransactionType = "CCE7" // CC Ecommerce Reversal (void + release auth)
+	TransactionTypeSale            TransactionType = "CCE1" // CC Ecommerce Sale (auth + capture)
+	TransactionTypeAuthOnly        TransactionType = "CCE2" // CC Ecommerce Auth Only
+	TransactionTypeCapture         TransactionType = "CCE4" // CC Ecommerce Capture
+	TransactionTypeRefund          TransactionType = "CCE9" // CC Ecommerce Refund/Credit
+	TransactionTypeVoid            TransactionType = "CCEX" // CC Ecommerce Void
+	TransactionTypeReversal        TransactionType = "CCE7" // CC Ecommerce Reversal (void + release auth)
+	TransactionTypeIncrementalAuth TransactionType = "CCE6" // CC Ecommerce Incremental Authorization (adds to an open auth; not in the Server Post reference, enabled per merchant)
 
 	// BRIC Storage (Tokenization)
 	TransactionTypeBRICStorageCC  TransactionType = "CCE8" // BRIC Storage - Credit Card (Ecommerce)
@@ -94,26 +97,42 @@ type ServerPostRequest struct {
 	// Required for recurring payments with Storage BRIC
 	ACIExt *string
 
+	// 3-D Secure authentication result (card-not-present sale/authorization)
+	CAVV            *string // Cryptogram (CAVV_UCAF): base64 (28 chars) or hex (40 chars)
+	ECI             *string // Electronic commerce indicator (CAVV_RESP), e.g. "05"
+	ThreeDSVersion  *string // TDS_VER: "2" (EPX takes 3DS 2 only)
+	DSTransactionID *string // DIRECTORY_SERVER_TRAN_ID: UUID, up to 36 chars (required for 3DS 2)
+
+	// Statement descriptor printed on the cardholder's statement (sanitized, max 22 characters)
+	SoftDescriptor *string
+
 	// Optional metadata
 	CustomerID string            // Our internal customer ID
 	Metadata   map[string]string // Additional metadata
+
+	// Optional per-operation deadline (covers retries). Zero uses the adapter's configured timeout.
+	// A shorter context deadline set by the caller always wins.
+	Timeout time.Duration
 }
 
 // ServerPostResponse contains parsed response from EPX Server Post
 // Based on EPX Server Post API - Response Format (page 12-15)
 type ServerPostResponse struct {
 	// Core response fields
-	AuthGUID     string // EPX transaction token (BRIC format)
-	AuthResp     string // EPX approval code ("00" = approved, "05" = declined, "12" = invalid)
-	AuthCode     string // Bank authorization code (NULL if declined)
-	AuthRespText string // Human-readable response message
-	IsApproved   bool   // Derived from AuthResp ("00" = true)
+	AuthGUID     string                  // EPX transaction token (BRIC format)
+	AuthResp     string                  // EPX approval code ("00" = approved, "05" = declined, "12" = invalid)
+	AuthCode     string                  // Bank authorization code (NULL if declined)
+	AuthRespText string                  // Human-readable response message
+	IsApproved   bool                    // Derived from AuthResp ("00" = true)
+	Decline      *pkgerrors.PaymentError // Categorized decline reason (nil when approved)
 
 	// Card/ACH verification (credit card only)
 	AuthCardType string // Card brand ("V"/"M"/"A"/"D") - empty for ACH
 	AuthAVS      string // Address verification - empty for ACH
 	AuthCVV2     string // CVV verification - empty for ACH
 
+	CardFundingType string // Card funding indicator (AUTH_CARD_FUNDING_TYPE: "C"/"D"/"P") - empty for ACH or when not returned
+
 	// Network Transaction ID (for Storage BRIC - card-on-file compliance)
 	NetworkTransactionID *string // NTID returned from Account Verification
 
@@ -154,4 +173,11 @@ type ServerPostAdapter interface {
 	// Performs a $0.00 authorization to verify token status
 	// Returns error if token is expired or invalid
 	ValidateToken(ctx context.Context, authGUID string) error
+
+	// ClassifyResponse maps an AUTH_RESP code (and text) the way a live response is mapped, without
+	// contacting EPX. Only AuthResp, AuthRespText, IsApproved and Decline are set.
+	ClassifyResponse(authResp, authRespText string) *ServerPostResponse
+
+	// Close closes the pooled socket connections; call it at shutdown once no more transactions will be sent
+	Close()
 }
@@ -0,0 +1,68 @@
+-- Migration: Add coupons and subscription discounts
+-- Purpose: Percent-off / fixed-amount-off promotions applied to subscription charges
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS coupons (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    agent_id VARCHAR(100) NOT NULL,
+    code VARCHAR(50) NOT NULL,
+
+    -- Discount: exactly one of percent_off / amount_off is set
+    discount_type VARCHAR(20) NOT NULL,  -- 'percent', 'fixed'
+    percent_off NUMERIC(5, 2),           -- 20.00 = 20% off
+    amount_off NUMERIC(19, 4),           -- Fixed amount off each discounted charge
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+
+    -- Duration: how many charges the discount applies to
+    duration VARCHAR(20) NOT NULL,       -- 'once', 'forever', 'repeating'
+    duration_in_months INT,              -- Required when duration = 'repeating'
+
+    expires_at TIMESTAMPTZ,              -- Coupon cannot be redeemed or applied after this time
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_coupon_code UNIQUE (agent_id, code),
+    CONSTRAINT coupons_discount_type_valid CHECK (discount_type IN ('percent', 'fixed')),
+    CONSTRAINT coupons_discount_value_valid CHECK (
+        (discount_type = 'percent' AND percent_off > 0 AND percent_off <= 100 AND amount_off IS NULL) OR
+        (discount_type = 'fixed' AND amount_off > 0 AND percent_off IS NULL)
+    ),
+    CONSTRAINT coupons_duration_valid CHECK (duration IN ('once', 'forever', 'repeating')),
+    CONSTRAINT coupons_duration_in_months_valid CHECK (
+        (duration = 'repeating' AND duration_in_months > 0) OR
+        (duration <> 'repeating' AND duration_in_months IS NULL)
+    )
+);
+
+CREATE INDEX idx_coupons_agent_id ON coupons(agent_id) WHERE is_active = true;
+
+CREATE TRIGGER update_coupons_updated_at BEFORE UPDATE ON coupons
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Track the coupon applied to each subscription
+ALTER TABLE subscriptions
+  ADD COLUMN coupon_id UUID REFERENCES coupons(id) ON DELETE SET NULL,
+  ADD COLUMN coupon_applied_count INT NOT NULL DEFAULT 0,
+  ADD COLUMN coupon_ends_at DATE;
+
+CREATE INDEX idx_subscriptions_coupon_id ON subscriptions(coupon_id) WHERE coupon_id IS NOT NULL;
+
+COMMENT ON TABLE coupons IS 'Merchant promotions applied to subscription charges';
+COMMENT ON COLUMN subscriptions.coupon_applied_count IS 'Number of charges the coupon discount has been applied to';
+COMMENT ON COLUMN subscriptions.coupon_ends_at IS 'Billing dates on or after this date are charged in full (repeating coupons only)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_subscriptions_coupon_id;
+
+ALTER TABLE subscriptions
+  DROP COLUMN IF EXISTS coupon_ends_at,
+  DROP COLUMN IF EXISTS coupon_applied_count,
+  DROP COLUMN IF EXISTS coupon_id;
+
+DROP TRIGGER IF EXISTS update_coupons_updated_at ON coupons;
+DROP TABLE IF EXISTS coupons;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- Migration: Index transactions by subscription
+-- Purpose: Fast billing history lookups for a subscription (metadata.subscription_id)
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_transactions_subscription_id
+ON transactions((metadata->>'subscription_id'))
+WHERE metadata->>'subscription_id' IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_subscription_id;
+-- +goose StatementEnd
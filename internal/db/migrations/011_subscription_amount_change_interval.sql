@@ -0,0 +1,25 @@
+-- Migration: Minimum interval between subscription amount changes
+-- Purpose: Per-agent limit on how often a subscription's amount can change
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE agent_credentials
+  ADD COLUMN subscription_amount_change_min_days INT,
+  ADD CONSTRAINT check_subscription_amount_change_min_days CHECK (subscription_amount_change_min_days IS NULL OR subscription_amount_change_min_days >= 0);
+
+ALTER TABLE subscriptions
+  ADD COLUMN amount_changed_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN agent_credentials.subscription_amount_change_min_days IS 'Minimum days between subscription amount changes (NULL = unlimited)';
+COMMENT ON COLUMN subscriptions.amount_changed_at IS 'When the subscription amount was last changed (NULL = never changed since creation)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+  DROP COLUMN IF EXISTS amount_changed_at;
+
+ALTER TABLE agent_credentials
+  DROP CONSTRAINT IF EXISTS check_subscription_amount_change_min_days,
+  DROP COLUMN IF EXISTS subscription_amount_change_min_days;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- Migration: Agent tier and configuration overrides
+-- Purpose: Tier defaults (limits, currencies, capabilities) with per-agent overrides
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE agent_credentials
+  ADD COLUMN tier VARCHAR(20) NOT NULL DEFAULT 'standard',
+  ADD COLUMN config_overrides JSONB NOT NULL DEFAULT '{}'::jsonb,
+  ADD CONSTRAINT check_tier CHECK (tier IN ('standard', 'premium', 'enterprise'));
+
+COMMENT ON COLUMN agent_credentials.tier IS 'Tier that supplies default limits, currencies and capabilities';
+COMMENT ON COLUMN agent_credentials.config_overrides IS 'Per-agent overrides of tier defaults (never contains secrets)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE agent_credentials
+  DROP CONSTRAINT IF EXISTS check_tier,
+  DROP COLUMN IF EXISTS config_overrides,
+  DROP COLUMN IF EXISTS tier;
+-- +goose StatementEnd
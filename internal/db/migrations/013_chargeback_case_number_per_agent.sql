@@ -0,0 +1,18 @@
+-- Migration: Scope chargeback case numbers to the agent
+-- Purpose: Idempotent dispute sync upserts keyed on (agent_id, case_number)
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chargebacks
+  DROP CONSTRAINT IF EXISTS chargebacks_case_number_key,
+  ADD CONSTRAINT chargebacks_agent_case_number_key UNIQUE (agent_id, case_number);
+
+COMMENT ON CONSTRAINT chargebacks_agent_case_number_key ON chargebacks IS 'One chargeback row per North case per agent (dispute sync upsert key)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chargebacks
+  DROP CONSTRAINT IF EXISTS chargebacks_agent_case_number_key,
+  ADD CONSTRAINT chargebacks_case_number_key UNIQUE (case_number);
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- Migration: Webhook delivery dead-letter state
+-- Purpose: Stop retrying deliveries after the max attempt count and keep them for manual replay
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE webhook_deliveries
+  DROP CONSTRAINT IF EXISTS valid_status,
+  ADD CONSTRAINT valid_status CHECK (status IN ('pending', 'success', 'failed', 'dead_letter'));
+
+-- Index for dead-letter queue listing
+CREATE INDEX idx_webhook_deliveries_dead_letter
+ON webhook_deliveries(created_at DESC)
+WHERE status = 'dead_letter';
+
+COMMENT ON COLUMN webhook_deliveries.status IS 'pending (retrying), success, failed (legacy), dead_letter (max attempts exhausted, replay manually)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_webhook_deliveries_dead_letter;
+
+UPDATE webhook_deliveries SET status = 'failed' WHERE status = 'dead_letter';
+
+ALTER TABLE webhook_deliveries
+  DROP CONSTRAINT IF EXISTS valid_status,
+  ADD CONSTRAINT valid_status CHECK (status IN ('pending', 'success', 'failed'));
+-- +goose StatementEnd
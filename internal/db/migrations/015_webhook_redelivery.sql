@@ -0,0 +1,23 @@
+-- Migration: Manual webhook redelivery
+-- Purpose: Link replayed deliveries to the delivery they were replayed from
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE webhook_deliveries
+  ADD COLUMN redelivery_of UUID REFERENCES webhook_deliveries(id) ON DELETE SET NULL;
+
+-- Index for failed delivery lookup by subscription and time range
+CREATE INDEX idx_webhook_deliveries_subscription_failed
+ON webhook_deliveries(subscription_id, created_at)
+WHERE status IN ('failed', 'dead_letter');
+
+COMMENT ON COLUMN webhook_deliveries.redelivery_of IS 'Original delivery this row manually replays (NULL for first deliveries)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_webhook_deliveries_subscription_failed;
+
+ALTER TABLE webhook_deliveries
+  DROP COLUMN IF EXISTS redelivery_of;
+-- +goose StatementEnd
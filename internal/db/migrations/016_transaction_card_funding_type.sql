@@ -0,0 +1,17 @@
+-- Migration: Card funding type on transactions
+-- Purpose: Record credit/debit/prepaid funding so processing fees can be estimated per brand and funding type
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  ADD COLUMN card_funding_type VARCHAR(20)
+  CHECK (card_funding_type IS NULL OR card_funding_type IN ('credit', 'debit', 'prepaid'));
+
+COMMENT ON COLUMN transactions.card_funding_type IS 'Card funding type (credit, debit, prepaid) - NULL for ACH or when unknown; used for fee estimates';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS card_funding_type;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- Migration: Transaction settlement state
+-- Purpose: Track when a transaction settled so refunds of unsettled captures can be redirected to voids
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  ADD COLUMN settled_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN transactions.settled_at IS 'When the transaction settled in an EPX batch (NULL = unsettled, still voidable)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS settled_at;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- Migration: Transaction funding date
+-- Purpose: Store when settled funds are expected to reach the merchant (settlement date + funding delay)
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  ADD COLUMN funding_date DATE;
+
+COMMENT ON COLUMN transactions.funding_date IS 'Expected merchant funding date: settlement date plus the merchant''s funding delay in business days (NULL = unsettled)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS funding_date;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- Migration: ACH return tracking on payment methods
+-- Purpose: Count ACH returns (R-codes) per payment method and record why a method was auto-deactivated
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE customer_payment_methods
+  ADD COLUMN return_count INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN last_return_code VARCHAR(4),
+  ADD COLUMN deactivation_reason VARCHAR(50);
+
+COMMENT ON COLUMN customer_payment_methods.return_count IS 'Number of ACH returns received for this payment method';
+COMMENT ON COLUMN customer_payment_methods.last_return_code IS 'Most recent NACHA return code (e.g. R01)';
+COMMENT ON COLUMN customer_payment_methods.deactivation_reason IS 'Why the payment method was automatically deactivated (NULL = not auto-deactivated)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE customer_payment_methods
+  DROP COLUMN IF EXISTS deactivation_reason,
+  DROP COLUMN IF EXISTS last_return_code,
+  DROP COLUMN IF EXISTS return_count;
+-- +goose StatementEnd
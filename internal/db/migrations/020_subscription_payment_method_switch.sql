@@ -0,0 +1,19 @@
+-- Migration: Subscription payment method switch tracking
+-- Purpose: Record when dunning automatically moved a failing ACH subscription to a card on file
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+  ADD COLUMN previous_payment_method_id UUID,
+  ADD COLUMN payment_method_switched_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN subscriptions.previous_payment_method_id IS 'Payment method used before the last automatic switch (NULL = never switched)';
+COMMENT ON COLUMN subscriptions.payment_method_switched_at IS 'When dunning last switched the subscription to another payment method';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+  DROP COLUMN IF EXISTS payment_method_switched_at,
+  DROP COLUMN IF EXISTS previous_payment_method_id;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- Migration: ACH micro-deposit verification
+-- Purpose: Store hashed micro-deposit amounts and failed verification attempts on ACH payment methods
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE customer_payment_methods
+  ADD COLUMN micro_deposit_hash TEXT,
+  ADD COLUMN micro_deposit_sent_at TIMESTAMPTZ,
+  ADD COLUMN micro_deposit_attempts INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN customer_payment_methods.micro_deposit_hash IS 'Salted SHA-256 of the expected micro-deposit amounts (NULL = none pending)';
+COMMENT ON COLUMN customer_payment_methods.micro_deposit_sent_at IS 'When the micro-deposits were sent';
+COMMENT ON COLUMN customer_payment_methods.micro_deposit_attempts IS 'Failed micro-deposit verification attempts (locked at 3)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE customer_payment_methods
+  DROP COLUMN IF EXISTS micro_deposit_attempts,
+  DROP COLUMN IF EXISTS micro_deposit_sent_at,
+  DROP COLUMN IF EXISTS micro_deposit_hash;
+-- +goose StatementEnd
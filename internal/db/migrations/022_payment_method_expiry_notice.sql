@@ -0,0 +1,22 @@
+-- Migration: Expiring card notices
+-- Purpose: Remember when a payment method's expiring-card webhook was sent so each expiry is announced once
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE customer_payment_methods
+  ADD COLUMN expiry_notified_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN customer_payment_methods.expiry_notified_at IS 'When payment_method.expiring was sent for the current expiry (reset when the expiry is updated)';
+
+CREATE INDEX idx_customer_payment_methods_card_expiry
+  ON customer_payment_methods (agent_id, card_exp_year, card_exp_month)
+  WHERE payment_type = 'credit_card' AND is_active = true AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_customer_payment_methods_card_expiry;
+
+ALTER TABLE customer_payment_methods
+  DROP COLUMN IF EXISTS expiry_notified_at;
+-- +goose StatementEnd
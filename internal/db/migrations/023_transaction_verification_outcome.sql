@@ -0,0 +1,16 @@
+-- Migration: AVS/CVV policy outcome on transactions
+-- Purpose: Record the merchant's AVS/CVV policy decision alongside the raw gateway codes
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  ADD COLUMN verification_outcome JSONB;
+
+COMMENT ON COLUMN transactions.verification_outcome IS 'AVS/CVV policy outcome (result, failed_checks, policies and evaluated results); NULL when no policy was configured';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS verification_outcome;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- Migration: Single default payment method
+-- Purpose: Enforce at most one default payment method per (agent, customer)
+
+-- +goose Up
+-- +goose StatementBegin
+-- Keep the most recently updated default where earlier races left several
+UPDATE customer_payment_methods pm
+SET is_default = false, updated_at = CURRENT_TIMESTAMP
+WHERE pm.is_default = true
+  AND pm.deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM customer_payment_methods newer
+    WHERE newer.agent_id = pm.agent_id
+      AND newer.customer_id = pm.customer_id
+      AND newer.is_default = true
+      AND newer.deleted_at IS NULL
+      AND (newer.updated_at, newer.id) > (pm.updated_at, pm.id)
+  );
+
+DROP INDEX IF EXISTS idx_customer_payment_methods_is_default;
+
+CREATE UNIQUE INDEX idx_customer_payment_methods_one_default
+  ON customer_payment_methods (agent_id, customer_id)
+  WHERE is_default = true AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_customer_payment_methods_one_default;
+
+CREATE INDEX idx_customer_payment_methods_is_default ON customer_payment_methods(agent_id, customer_id, is_default) WHERE is_default = true;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- Migration: Data residency tags
+-- Purpose: Tag each merchant, and the transaction and customer payment method rows written for it, with a data region
+--          so compliance exports and purges can be scoped by region
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE agent_credentials
+  ADD COLUMN data_region VARCHAR(32) NOT NULL DEFAULT 'us';
+
+ALTER TABLE transactions
+  ADD COLUMN data_region VARCHAR(32) NOT NULL DEFAULT 'us';
+
+ALTER TABLE customer_payment_methods
+  ADD COLUMN data_region VARCHAR(32) NOT NULL DEFAULT 'us';
+
+COMMENT ON COLUMN agent_credentials.data_region IS 'Data residency region of the merchant (e.g. us, eu, ca); stamped on rows written for it';
+COMMENT ON COLUMN transactions.data_region IS 'Merchant data region when the row was written (not changed if the merchant later moves)';
+COMMENT ON COLUMN customer_payment_methods.data_region IS 'Merchant data region when the row was written (not changed if the merchant later moves)';
+
+CREATE INDEX idx_transactions_data_region ON transactions (data_region, agent_id, created_at);
+CREATE INDEX idx_customer_payment_methods_data_region ON customer_payment_methods (data_region, agent_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_customer_payment_methods_data_region;
+DROP INDEX IF EXISTS idx_transactions_data_region;
+
+ALTER TABLE customer_payment_methods
+  DROP COLUMN IF EXISTS data_region;
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS data_region;
+
+ALTER TABLE agent_credentials
+  DROP COLUMN IF EXISTS data_region;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- Migration: Keyset pagination index for transactions
+-- Purpose: Serve ListTransactions cursor pages ((created_at, id) newest first) per agent without OFFSET scans
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_transactions_agent_created_id
+  ON transactions (agent_id, created_at DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_agent_created_id;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- Migration: Transaction metadata search index
+-- Purpose: Serve ListTransactions metadata_contains filters (metadata @> '{"order_id": "..."}') without sequential scans
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_transactions_metadata
+  ON transactions USING GIN (metadata jsonb_path_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_metadata;
+-- +goose StatementEnd
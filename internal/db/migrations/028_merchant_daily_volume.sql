@@ -0,0 +1,26 @@
+-- Migration: Per-merchant daily volume counters
+-- Purpose: Atomic running totals that enforce daily_volume_limit without racing concurrent sales
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS merchant_daily_volume (
+    agent_id VARCHAR(100) NOT NULL,
+    volume_date DATE NOT NULL,              -- UTC day
+    amount NUMERIC(19, 4) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (agent_id, volume_date),
+    CONSTRAINT merchant_daily_volume_amount_non_negative CHECK (amount >= 0)
+);
+
+COMMENT ON TABLE merchant_daily_volume IS 'Sale/authorization volume reserved per merchant per UTC day (reserved before the gateway call, released on decline)';
+
+CREATE TRIGGER update_merchant_daily_volume_updated_at BEFORE UPDATE ON merchant_daily_volume
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS merchant_daily_volume;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- Migration: Batch-level idempotency for BatchSale
+-- Purpose: Remember each keyed batch's per-item results so a retried batch returns them instead of re-running
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS sale_batches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    agent_id VARCHAR(100) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    results JSONB,                          -- Per-item outcome in request order (transaction_id or error); NULL while processing
+    claimed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_sale_batch_key UNIQUE (agent_id, idempotency_key)
+);
+
+COMMENT ON COLUMN sale_batches.claimed_at IS 'When processing started; an unfinished claim older than the stale cutoff may be taken over';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS sale_batches;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- Migration: Allow partial authorization reversals in the transaction ledger
+-- Purpose: PartialReverseAuthorization records the released amount as a 'reversal' row in the auth's group
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP CONSTRAINT IF EXISTS transactions_type_valid,
+  ADD CONSTRAINT transactions_type_valid CHECK (type IN ('charge', 'refund', 'pre_note', 'auth', 'capture', 'reversal'));
+
+COMMENT ON CONSTRAINT transactions_type_valid ON transactions IS 'reversal rows reduce the active authorization amount of their group';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM transactions WHERE type = 'reversal';
+
+ALTER TABLE transactions
+  DROP CONSTRAINT IF EXISTS transactions_type_valid,
+  ADD CONSTRAINT transactions_type_valid CHECK (type IN ('charge', 'refund', 'pre_note', 'auth', 'capture'));
+-- +goose StatementEnd
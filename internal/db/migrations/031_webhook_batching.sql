@@ -0,0 +1,23 @@
+-- Migration: Webhook event batching
+-- Purpose: Let high-volume subscribers receive events coalesced into one POST per batch
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE webhook_subscriptions
+  ADD COLUMN batch_size INTEGER NOT NULL DEFAULT 1,
+  ADD COLUMN batch_flush_interval_seconds INTEGER NOT NULL DEFAULT 10,
+  ADD CONSTRAINT webhook_subscriptions_batch_size_valid CHECK (batch_size BETWEEN 1 AND 1000),
+  ADD CONSTRAINT webhook_subscriptions_batch_flush_interval_valid CHECK (batch_flush_interval_seconds > 0);
+
+COMMENT ON COLUMN webhook_subscriptions.batch_size IS 'Events coalesced into one POST (1 = one POST per event)';
+COMMENT ON COLUMN webhook_subscriptions.batch_flush_interval_seconds IS 'Maximum time an event waits for its batch to fill before the batch is sent';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE webhook_subscriptions
+  DROP CONSTRAINT IF EXISTS webhook_subscriptions_batch_flush_interval_valid,
+  DROP CONSTRAINT IF EXISTS webhook_subscriptions_batch_size_valid,
+  DROP COLUMN IF EXISTS batch_flush_interval_seconds,
+  DROP COLUMN IF EXISTS batch_size;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- Migration: Calling services and their JWT signing keys
+-- Purpose: Register internal services that call the gRPC API, with several active public keys each for zero-downtime key rotation
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS services (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    service_id VARCHAR(100) NOT NULL UNIQUE,    -- JWT issuer (iss) the service signs its tokens with
+    service_name VARCHAR(255) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS service_public_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    key_id VARCHAR(100) NOT NULL,               -- Matched against the token's kid header
+    public_key TEXT NOT NULL,                   -- PEM-encoded RSA public key
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    retired_at TIMESTAMPTZ,
+
+    CONSTRAINT unique_service_key_id UNIQUE (service_id, key_id)
+);
+
+CREATE INDEX idx_service_public_keys_active ON service_public_keys(service_id) WHERE is_active = true;
+
+COMMENT ON TABLE service_public_keys IS 'Every active key verifies the service''s tokens, so a new key is added before the old one is retired';
+COMMENT ON COLUMN service_public_keys.retired_at IS 'When the key stopped verifying tokens (NULL while active)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS service_public_keys;
+DROP TABLE IF EXISTS services;
+-- +goose StatementEnd
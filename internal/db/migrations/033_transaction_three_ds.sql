@@ -0,0 +1,16 @@
+-- Migration: 3-D Secure results on transactions
+-- Purpose: Keep the authentication result sent with a CNP authorization as chargeback evidence
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  ADD COLUMN three_ds JSONB;
+
+COMMENT ON COLUMN transactions.three_ds IS '3-D Secure result sent to EPX (version, cavv, eci, transaction_status, ds_transaction_id); NULL when 3DS was not run';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS three_ds;
+-- +goose StatementEnd
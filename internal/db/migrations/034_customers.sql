@@ -0,0 +1,28 @@
+-- Migration: Customers
+-- Purpose: Per-merchant customer records referenced by transactions' customer_id, so merchants can require known customers or have them created on first reference
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS customers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    agent_id VARCHAR(100) NOT NULL,
+    customer_id VARCHAR(255) NOT NULL,          -- The merchant's own customer reference (transactions.customer_id)
+    email VARCHAR(255),
+    auto_created BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_agent_customer UNIQUE (agent_id, customer_id)
+);
+
+COMMENT ON COLUMN customers.auto_created IS 'Created from the first payment that referenced it (customer_policy auto_create), holding only the customer_id and email given';
+
+CREATE TRIGGER update_customers_updated_at BEFORE UPDATE ON customers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS update_customers_updated_at ON customers;
+DROP TABLE IF EXISTS customers;
+-- +goose StatementEnd
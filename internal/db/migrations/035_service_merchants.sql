@@ -0,0 +1,22 @@
+-- Migration: Service merchant grants
+-- Purpose: Limit each calling service to the merchants it has been granted, checked on every request carrying an agent_id
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS service_merchants (
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    agent_id VARCHAR(255) NOT NULL REFERENCES agent_credentials(agent_id) ON DELETE CASCADE,
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (service_id, agent_id)
+);
+
+CREATE INDEX idx_service_merchants_agent_id ON service_merchants(agent_id);
+
+COMMENT ON TABLE service_merchants IS 'Merchants (agent_id) a service may act for; revoking deletes the row';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS service_merchants;
+-- +goose StatementEnd
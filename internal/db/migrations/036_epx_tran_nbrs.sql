@@ -0,0 +1,37 @@
+-- Migration: EPX TRAN_NBR allocation
+-- Purpose: Give each transaction a per-merchant numeric TRAN_NBR (at most 10 digits, as EPX requires) that is
+-- allocated once per transaction ID, so a retried request reuses its number instead of risking a collision
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS epx_tran_nbr_counters (
+    agent_id VARCHAR(100) PRIMARY KEY,
+    last_tran_nbr BIGINT NOT NULL,
+
+    CONSTRAINT epx_tran_nbr_range CHECK (last_tran_nbr BETWEEN 1 AND 9999999999)
+);
+
+CREATE TABLE IF NOT EXISTS epx_tran_nbrs (
+    transaction_id UUID PRIMARY KEY,            -- transactions.id; allocated before the row exists
+    agent_id VARCHAR(100) NOT NULL,
+    tran_nbr BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_agent_tran_nbr UNIQUE (agent_id, tran_nbr)
+);
+
+ALTER TABLE transactions
+  ADD COLUMN tran_nbr BIGINT;
+
+COMMENT ON TABLE epx_tran_nbr_counters IS 'Last TRAN_NBR handed out per merchant; allocation fails once a merchant reaches 9999999999';
+COMMENT ON TABLE epx_tran_nbrs IS 'TRAN_NBR allocated to each transaction ID, so retries send EPX the same number';
+COMMENT ON COLUMN transactions.tran_nbr IS 'TRAN_NBR sent to EPX (from epx_tran_nbrs); NULL for transactions created before allocation existed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS tran_nbr;
+DROP TABLE IF EXISTS epx_tran_nbrs;
+DROP TABLE IF EXISTS epx_tran_nbr_counters;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- Migration: Webhook delivery latency and failure reasons
+-- Purpose: Record how long each delivery attempt took and why it failed, for per-subscription delivery stats
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE webhook_deliveries
+  ADD COLUMN latency_ms INT,
+  ADD COLUMN failure_reason VARCHAR(50);
+
+COMMENT ON COLUMN webhook_deliveries.latency_ms IS 'Duration of the latest attempt until the endpoint responded or the request failed; NULL before the first attempt';
+COMMENT ON COLUMN webhook_deliveries.failure_reason IS 'Why the latest attempt failed: http_<status>, timeout, dns_error, connection_refused, tls_error or network_error; NULL on success';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE webhook_deliveries
+  DROP COLUMN IF EXISTS failure_reason,
+  DROP COLUMN IF EXISTS latency_ms;
+-- +goose StatementEnd
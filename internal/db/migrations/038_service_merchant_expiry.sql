@@ -0,0 +1,16 @@
+-- Migration: Service merchant grant expiry
+-- Purpose: Let a service's access to a merchant lapse at a set time without a revoke
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE service_merchants
+  ADD COLUMN expires_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN service_merchants.expires_at IS 'When the grant stops allowing access; NULL grants never expire';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE service_merchants
+  DROP COLUMN IF EXISTS expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- Migration: Chargeback under_review status
+-- Purpose: Track chargebacks whose evidence has been submitted to North and awaits the issuer's decision
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chargebacks
+  DROP CONSTRAINT IF EXISTS chargebacks_status_valid;
+
+ALTER TABLE chargebacks
+  ADD CONSTRAINT chargebacks_status_valid
+  CHECK (status IN ('new', 'pending', 'responded', 'under_review', 'won', 'lost', 'accepted'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE chargebacks SET status = 'responded' WHERE status = 'under_review';
+
+ALTER TABLE chargebacks
+  DROP CONSTRAINT IF EXISTS chargebacks_status_valid;
+
+ALTER TABLE chargebacks
+  ADD CONSTRAINT chargebacks_status_valid
+  CHECK (status IN ('new', 'pending', 'responded', 'won', 'lost', 'accepted'));
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- Migration: Chargeback deadline reminders
+-- Purpose: Record when a merchant was reminded of a chargeback's response deadline so each deadline is announced once
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chargebacks
+  ADD COLUMN deadline_reminder_sent_at TIMESTAMPTZ;
+
+-- Open chargebacks by deadline, for reminders and the auto-loss sweep
+CREATE INDEX idx_chargebacks_open_respond_by ON chargebacks(respond_by_date)
+  WHERE status IN ('new', 'pending') AND respond_by_date IS NOT NULL AND deleted_at IS NULL;
+
+COMMENT ON COLUMN chargebacks.deadline_reminder_sent_at IS 'When chargeback.deadline_approaching was sent; cleared when respond_by_date changes';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_chargebacks_open_respond_by;
+
+ALTER TABLE chargebacks
+  DROP COLUMN IF EXISTS deadline_reminder_sent_at;
+-- +goose StatementEnd
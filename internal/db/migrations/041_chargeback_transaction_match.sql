@@ -0,0 +1,25 @@
+-- Migration: Chargeback transaction matching
+-- Purpose: Record whether dispute sync linked a chargeback to its transaction group, so unmatched ones can be reviewed by hand
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chargebacks
+  ADD COLUMN match_status VARCHAR(20),
+  ADD CONSTRAINT chargebacks_match_status_valid CHECK (match_status IS NULL OR match_status IN ('matched', 'unmatched'));
+
+UPDATE chargebacks SET match_status = 'matched' WHERE group_id IS NOT NULL;
+
+CREATE INDEX idx_chargebacks_unmatched ON chargebacks(agent_id, created_at)
+  WHERE match_status = 'unmatched' AND deleted_at IS NULL;
+
+COMMENT ON COLUMN chargebacks.match_status IS 'matched: group_id linked by dispute sync or by hand; unmatched: no confident match, needs manual review; NULL: synced before matching existed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_chargebacks_unmatched;
+
+ALTER TABLE chargebacks
+  DROP CONSTRAINT IF EXISTS chargebacks_match_status_valid,
+  DROP COLUMN IF EXISTS match_status;
+-- +goose StatementEnd
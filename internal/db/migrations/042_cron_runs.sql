@@ -0,0 +1,31 @@
+-- Migration: Cron run history
+-- Purpose: Record the outcome of each billing cron run so run counts, amounts and duration can be graphed over time
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS cron_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job VARCHAR(50) NOT NULL,                   -- e.g. process-billing
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    duration_ms BIGINT NOT NULL,
+    processed INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    retried INTEGER NOT NULL DEFAULT 0,
+    amount_charged NUMERIC(19, 4) NOT NULL DEFAULT 0,
+    errors JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cron_runs_job_started ON cron_runs(job, started_at DESC);
+
+COMMENT ON TABLE cron_runs IS 'One row per cron run with its outcome, newest runs served by GET /cron/stats';
+COMMENT ON COLUMN cron_runs.retried IS 'Subscriptions attempted again after an earlier failed charge';
+COMMENT ON COLUMN cron_runs.amount_charged IS 'Sum of successful charges in the run';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS cron_runs;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- Migration: Merchant self-service settings
+-- Purpose: Settings a merchant can change through its service (UpdateMerchantSettings), kept apart from EPX credentials
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE agent_credentials
+  ADD COLUMN statement_descriptor VARCHAR(22),
+  ADD COLUMN notification_email VARCHAR(254);
+
+COMMENT ON COLUMN agent_credentials.statement_descriptor IS 'Descriptor shown on cardholder statements (5-22 characters); NULL = EPX default';
+COMMENT ON COLUMN agent_credentials.notification_email IS 'Where the merchant receives operational notices';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE agent_credentials
+  DROP COLUMN IF EXISTS notification_email,
+  DROP COLUMN IF EXISTS statement_descriptor;
+-- +goose StatementEnd
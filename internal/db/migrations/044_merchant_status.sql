@@ -0,0 +1,31 @@
+-- Migration: Merchant lifecycle status
+-- Purpose: Replace the bare is_active flag with a reversible suspension and a terminal closure.
+-- is_active is kept in step (true only while active) so existing checks still block inactive merchants.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE agent_credentials
+  ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'suspended', 'closed')),
+  ADD COLUMN status_reason TEXT,
+  ADD COLUMN suspended_at TIMESTAMPTZ,
+  ADD COLUMN closed_at TIMESTAMPTZ;
+
+-- Deactivated merchants rejected every operation, which is what closed means now
+UPDATE agent_credentials
+SET status = 'closed', closed_at = updated_at
+WHERE is_active = false;
+
+COMMENT ON COLUMN agent_credentials.status IS 'active, suspended (no new charges; refunds and voids allowed) or closed (nothing allowed; terminal)';
+COMMENT ON COLUMN agent_credentials.status_reason IS 'Why the merchant was last suspended or closed';
+COMMENT ON COLUMN agent_credentials.suspended_at IS 'When the current suspension started; NULL unless suspended';
+COMMENT ON COLUMN agent_credentials.closed_at IS 'When the merchant was closed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE agent_credentials
+  DROP COLUMN IF EXISTS closed_at,
+  DROP COLUMN IF EXISTS suspended_at,
+  DROP COLUMN IF EXISTS status_reason,
+  DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
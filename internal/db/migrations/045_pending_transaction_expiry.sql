@@ -0,0 +1,38 @@
+-- Migration: Pending Browser Post transaction expiry
+-- Purpose: Give each pending Browser Post transaction a deadline for its EPX callback (TAC lifetime plus grace).
+-- A callback after the deadline is rejected and the transaction moves to the terminal 'expired' status.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  ADD COLUMN pending_expires_at TIMESTAMPTZ;
+
+ALTER TABLE transactions
+  DROP CONSTRAINT transactions_status_valid,
+  ADD CONSTRAINT transactions_status_valid CHECK (status IN ('pending', 'completed', 'failed', 'refunded', 'voided', 'expired'));
+
+-- Existing pending transactions get the default window (4h TAC validity + 30m grace)
+UPDATE transactions
+SET pending_expires_at = created_at + INTERVAL '4 hours 30 minutes'
+WHERE status = 'pending';
+
+CREATE INDEX idx_transactions_pending_expires_at ON transactions(pending_expires_at)
+  WHERE status = 'pending' AND pending_expires_at IS NOT NULL;
+
+COMMENT ON COLUMN transactions.pending_expires_at IS 'When a pending Browser Post transaction stops accepting its EPX callback; NULL for other transactions';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_pending_expires_at;
+
+-- An expired transaction never got a result, which the old statuses call failed
+UPDATE transactions SET status = 'failed' WHERE status = 'expired';
+
+ALTER TABLE transactions
+  DROP CONSTRAINT transactions_status_valid,
+  ADD CONSTRAINT transactions_status_valid CHECK (status IN ('pending', 'completed', 'failed', 'refunded', 'voided'));
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS pending_expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- Migration: Idempotency request fingerprint
+-- Purpose: Record a hash of the parameters each keyed payment request was made with, so a retry that reuses
+-- the idempotency key with a different amount, merchant or payment method is rejected instead of replayed.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  ADD COLUMN request_hash TEXT;
+
+COMMENT ON COLUMN transactions.request_hash IS 'SHA-256 of the request parameters behind idempotency_key; NULL for unkeyed transactions and those recorded before fingerprints';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS request_hash;
+-- +goose StatementEnd
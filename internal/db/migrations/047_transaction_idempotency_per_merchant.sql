@@ -0,0 +1,29 @@
+-- Migration: Per-merchant transaction idempotency keys
+-- Purpose: Idempotency keys are chosen by each merchant's client (or are EPX TRAN_NBRs, which are numbered per
+-- merchant), so they only need to be unique within a merchant. A globally unique key let one merchant's key
+-- collide with, or replay, another merchant's transaction.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP CONSTRAINT IF EXISTS transactions_idempotency_key_key;
+
+DROP INDEX IF EXISTS idx_transactions_idempotency_key;
+
+CREATE UNIQUE INDEX idx_transactions_agent_idempotency_key
+  ON transactions(agent_id, idempotency_key)
+  WHERE idempotency_key IS NOT NULL;
+
+COMMENT ON COLUMN transactions.idempotency_key IS 'Client idempotency key (or Browser Post TRAN_NBR), unique per merchant; independent of the transaction id';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_agent_idempotency_key;
+
+CREATE INDEX idx_transactions_idempotency_key ON transactions(idempotency_key) WHERE idempotency_key IS NOT NULL;
+
+-- Fails if two merchants have since used the same key
+ALTER TABLE transactions
+  ADD CONSTRAINT transactions_idempotency_key_key UNIQUE (idempotency_key);
+-- +goose StatementEnd
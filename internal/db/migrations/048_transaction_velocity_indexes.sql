@@ -0,0 +1,20 @@
+-- Migration: Velocity limit indexes for transactions
+-- Purpose: Serve GetTransactionVelocity (a card's or customer's recent sales and authorizations) from the
+-- window's rows instead of everything the card or customer has ever paid
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_transactions_customer_velocity
+  ON transactions (agent_id, customer_id, created_at DESC)
+  WHERE customer_id IS NOT NULL AND type IN ('charge', 'auth');
+
+CREATE INDEX idx_transactions_payment_method_velocity
+  ON transactions (agent_id, payment_method_id, created_at DESC)
+  WHERE payment_method_id IS NOT NULL AND type IN ('charge', 'auth');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_payment_method_velocity;
+DROP INDEX IF EXISTS idx_transactions_customer_velocity;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- Migration: TRAN_NBR lookup index for transactions
+-- Purpose: Serve GetTransactionByTranNbr (refunds by the TRAN_NBR on an EPX receipt) without scanning the merchant's transactions
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_transactions_agent_tran_nbr
+  ON transactions (agent_id, tran_nbr)
+  WHERE tran_nbr IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_agent_tran_nbr;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- Migration: Feature flags
+-- Purpose: Operator switches read on every request, so new charges can be stopped during an incident without a redeploy.
+-- A row with no agent_id is the global flag; a merchant's row applies to that merchant only. A missing flag is on.
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS feature_flags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) NOT NULL,                  -- e.g. charges_enabled
+    agent_id VARCHAR(255) REFERENCES agent_credentials(agent_id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL,
+    reason TEXT,
+    updated_by VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One global row and one row per merchant for each flag
+CREATE UNIQUE INDEX idx_feature_flags_name_agent ON feature_flags(name, (COALESCE(agent_id, '')));
+
+COMMENT ON TABLE feature_flags IS 'Operator switches such as charges_enabled, set with the admin CLI; a missing flag is on';
+COMMENT ON COLUMN feature_flags.agent_id IS 'Merchant the flag applies to; NULL for the global flag';
+COMMENT ON COLUMN feature_flags.reason IS 'Why the flag was last changed, e.g. an incident reference';
+COMMENT ON COLUMN feature_flags.updated_by IS 'Operator who last changed the flag';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS feature_flags;
+-- +goose StatementEnd
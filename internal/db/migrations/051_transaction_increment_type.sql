@@ -0,0 +1,20 @@
+-- Migration: Allow incremental authorizations in the transaction ledger
+-- Purpose: IncrementAuthorization records the added amount as an 'increment' row in the auth's group
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP CONSTRAINT IF EXISTS transactions_type_valid,
+  ADD CONSTRAINT transactions_type_valid CHECK (type IN ('charge', 'refund', 'pre_note', 'auth', 'capture', 'reversal', 'increment'));
+
+COMMENT ON CONSTRAINT transactions_type_valid ON transactions IS 'reversal rows reduce and increment rows raise the active authorization amount of their group';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM transactions WHERE type = 'increment';
+
+ALTER TABLE transactions
+  DROP CONSTRAINT IF EXISTS transactions_type_valid,
+  ADD CONSTRAINT transactions_type_valid CHECK (type IN ('charge', 'refund', 'pre_note', 'auth', 'capture', 'reversal'));
+-- +goose StatementEnd
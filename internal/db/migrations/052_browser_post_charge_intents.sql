@@ -0,0 +1,35 @@
+-- Migration: Browser Post charge intents
+-- Purpose: A save_and_charge form tokenizes the card without charging it, so the callback can't trust the AMOUNT or
+-- USER_DATA_2 it carries back through the browser. The form records the amount and customer here under its TRAN_NBR,
+-- and the callback charges only those.
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS browser_post_charge_intents (
+    id UUID PRIMARY KEY,                        -- the ID the form's TRAN_NBR was allocated under
+    agent_id VARCHAR(255) NOT NULL REFERENCES agent_credentials(agent_id) ON DELETE CASCADE,
+    tran_nbr BIGINT NOT NULL,
+    customer_id VARCHAR(255) NOT NULL,
+    amount NUMERIC(19, 4) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    payment_method_id UUID,
+    transaction_id UUID,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_charge_intent_tran_nbr UNIQUE (agent_id, tran_nbr),
+    CONSTRAINT browser_post_charge_intents_amount_positive CHECK (amount > 0),
+    CONSTRAINT browser_post_charge_intents_status_valid CHECK (status IN ('pending', 'processing', 'completed', 'failed'))
+);
+
+COMMENT ON TABLE browser_post_charge_intents IS 'Amount and customer of each save_and_charge Browser Post form, charged by its callback';
+COMMENT ON COLUMN browser_post_charge_intents.status IS 'pending until a callback claims it; processing while the card is saved and charged; then completed or failed';
+COMMENT ON COLUMN browser_post_charge_intents.expires_at IS 'When the form stops accepting its callback (TAC validity plus callback grace)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS browser_post_charge_intents;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- Migration: Daily volume held by pending Browser Post sales
+-- Purpose: A Browser Post sale form reserves its amount against the merchant's daily volume limit before the
+-- customer's browser posts it to EPX. The reserved day is recorded on the pending transaction so a declined
+-- callback can release it.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  ADD COLUMN daily_volume_date DATE;
+
+COMMENT ON COLUMN transactions.daily_volume_date IS 'UTC day a pending Browser Post sale reserved its amount against the merchant''s daily volume limit; NULL when nothing was reserved';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS daily_volume_date;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- Migration: Card fingerprints for velocity limits
+-- Purpose: Key card velocity limits on the card instead of the saved payment method, so charges with one-time
+-- tokens and Browser Post payments count too. Velocity windows are minutes long, so existing rows aren't
+-- backfilled.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  ADD COLUMN card_fingerprint VARCHAR(64);
+
+COMMENT ON COLUMN transactions.card_fingerprint IS 'SHA-256 of the card''s last four digits and expiry, or of the BRIC it was charged with when those aren''t known; NULL when the card is unknown';
+
+DROP INDEX IF EXISTS idx_transactions_payment_method_velocity;
+
+CREATE INDEX idx_transactions_card_velocity
+  ON transactions (agent_id, card_fingerprint, created_at DESC)
+  WHERE card_fingerprint IS NOT NULL AND type IN ('charge', 'auth', 'increment');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_card_velocity;
+
+CREATE INDEX idx_transactions_payment_method_velocity
+  ON transactions (agent_id, payment_method_id, created_at DESC)
+  WHERE payment_method_id IS NOT NULL AND type IN ('charge', 'auth');
+
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS card_fingerprint;
+-- +goose StatementEnd
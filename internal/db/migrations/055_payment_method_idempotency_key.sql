@@ -0,0 +1,23 @@
+-- Migration: Idempotency keys for saved payment methods
+-- Purpose: SavePaymentMethod, ConvertFinancialBRICToStorageBRIC and TokenizePaymentMethod record the request's
+-- idempotency key with the payment method they save, so a retry returns it instead of saving the card twice
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE customer_payment_methods
+  ADD COLUMN idempotency_key VARCHAR(255);
+
+COMMENT ON COLUMN customer_payment_methods.idempotency_key IS 'Idempotency key of the request that saved the payment method (NULL = none sent)';
+
+CREATE UNIQUE INDEX idx_payment_methods_agent_idempotency_key
+  ON customer_payment_methods (agent_id, idempotency_key)
+  WHERE idempotency_key IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_payment_methods_agent_idempotency_key;
+
+ALTER TABLE customer_payment_methods
+  DROP COLUMN IF EXISTS idempotency_key;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- Migration: Chargeback evidence stored for manual submission
+-- Purpose: North has no dispute write API, so SubmitChargebackEvidence stores the merchant's evidence and narrative
+-- and marks when they were stored; the merchant submits them through North's web portal
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chargebacks
+  ADD COLUMN evidence_ready_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN chargebacks.evidence_ready_at IS 'When evidence was last stored for the merchant to submit through North''s portal (NULL = none stored)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chargebacks
+  DROP COLUMN IF EXISTS evidence_ready_at;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- Migration: Billing claims on subscriptions
+-- Purpose: A billing run claims the due subscriptions it bills (FOR UPDATE SKIP LOCKED), so overlapping runs,
+-- whether from the cron endpoint or the ProcessDueBilling RPC, never charge the same subscription twice
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+  ADD COLUMN billing_claimed_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN subscriptions.billing_claimed_at IS 'When a billing run claimed the subscription; cleared when it finishes, and a stale claim may be taken over (NULL = unclaimed)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+  DROP COLUMN IF EXISTS billing_claimed_at;
+-- +goose StatementEnd
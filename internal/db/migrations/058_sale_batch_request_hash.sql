@@ -0,0 +1,17 @@
+-- Migration: Request hash on sale batches
+-- Purpose: A retried BatchSale is only answered with the recorded results when it sends the same sales;
+-- reusing the batch key for different sales is rejected
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sale_batches
+  ADD COLUMN request_hash TEXT;
+
+COMMENT ON COLUMN sale_batches.request_hash IS 'Fingerprint of the batch''s sales (each item''s merchant, amount, currency, customer, payment method and key); NULL for batches recorded before it was stored';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sale_batches
+  DROP COLUMN IF EXISTS request_hash;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- Migration: Queued webhook batch events
+-- Purpose: Events waiting for their subscription's batch to fill are stored, so a restart doesn't lose them
+-- and any instance can send a due batch
+
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webhook_batch_events (
+    id BIGSERIAL PRIMARY KEY,                   -- queue order
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    flush_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_batch_events_subscription ON webhook_batch_events(subscription_id, id);
+
+COMMENT ON TABLE webhook_batch_events IS 'Events queued for batching subscriptions until the batch is full or its flush time passes; removed as they are sent';
+COMMENT ON COLUMN webhook_batch_events.flush_at IS 'When the event''s batch is sent if it hasn''t filled; a batch is due once its oldest event''s flush_at passes';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_batch_events;
+-- +goose StatementEnd
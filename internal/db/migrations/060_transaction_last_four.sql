@@ -0,0 +1,17 @@
+-- Migration: Last four digits on transactions
+-- Purpose: Show the masked card of a Browser Post payment that didn't save its card; until now the status
+-- endpoint could only read it from a saved payment method. Existing rows aren't backfilled.
+
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+  ADD COLUMN last_four VARCHAR(4);
+
+COMMENT ON COLUMN transactions.last_four IS 'Last four digits of the card or bank account number from the Browser Post callback; NULL for other transactions or when the callback had none';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+  DROP COLUMN IF EXISTS last_four;
+-- +goose StatementEnd
@@ -1,10 +1,10 @@
 -- name: CreateAgent :one
 INSERT INTO agent_credentials (
     id, agent_id, cust_nbr, merch_nbr, dba_nbr, terminal_nbr,
-    mac_secret_path, environment, is_active, agent_name
+    mac_secret_path, environment, is_active, agent_name, data_region
 ) VALUES (
     sqlc.arg(id), sqlc.arg(agent_id), sqlc.arg(cust_nbr), sqlc.arg(merch_nbr), sqlc.arg(dba_nbr), sqlc.arg(terminal_nbr),
-    sqlc.arg(mac_secret_path), sqlc.arg(environment), sqlc.arg(is_active), sqlc.arg(agent_name)
+    sqlc.arg(mac_secret_path), sqlc.arg(environment), sqlc.arg(is_active), sqlc.arg(agent_name), sqlc.arg(data_region)
 ) RETURNING *;
 
 -- name: GetAgentByID :one
@@ -15,11 +15,22 @@ WHERE id = sqlc.arg(id);
 SELECT * FROM agent_credentials
 WHERE agent_id = sqlc.arg(agent_id);
 
+-- name: ListAgentsByEPXCredentials :many
+-- Merchants registered with this EPX terminal (cust_nbr alone is not unique); callers require exactly one
+SELECT * FROM agent_credentials
+WHERE cust_nbr = sqlc.arg(cust_nbr)
+  AND merch_nbr = sqlc.arg(merch_nbr)
+  AND dba_nbr = sqlc.arg(dba_nbr)
+  AND terminal_nbr = sqlc.arg(terminal_nbr)
+  AND deleted_at IS NULL;
+
 -- name: ListAgents :many
+-- search is an ILIKE pattern matched against the agent ID and name (NULL = no search)
 SELECT * FROM agent_credentials
 WHERE
     (sqlc.narg(environment)::varchar IS NULL OR environment = sqlc.narg(environment)) AND
-    (sqlc.narg(is_active)::boolean IS NULL OR is_active = sqlc.narg(is_active))
+    (sqlc.narg(is_active)::boolean IS NULL OR is_active = sqlc.narg(is_active)) AND
+    (sqlc.narg(search)::text IS NULL OR agent_id ILIKE sqlc.narg(search) OR agent_name ILIKE sqlc.narg(search))
 ORDER BY created_at DESC
 LIMIT sqlc.arg(limit_val) OFFSET sqlc.arg(offset_val);
 
@@ -27,7 +38,8 @@ LIMIT sqlc.arg(limit_val) OFFSET sqlc.arg(offset_val);
 SELECT COUNT(*) FROM agent_credentials
 WHERE
     (sqlc.narg(environment)::varchar IS NULL OR environment = sqlc.narg(environment)) AND
-    (sqlc.narg(is_active)::boolean IS NULL OR is_active = sqlc.narg(is_active));
+    (sqlc.narg(is_active)::boolean IS NULL OR is_active = sqlc.narg(is_active)) AND
+    (sqlc.narg(search)::text IS NULL OR agent_id ILIKE sqlc.narg(search) OR agent_name ILIKE sqlc.narg(search));
 
 -- name: UpdateAgent :one
 UPDATE agent_credentials
@@ -38,24 +50,47 @@ SET
     terminal_nbr = sqlc.arg(terminal_nbr),
     environment = sqlc.arg(environment),
     agent_name = sqlc.arg(agent_name),
+    subscription_amount_change_min_days = sqlc.narg(subscription_amount_change_min_days),
+    data_region = sqlc.arg(data_region),
     updated_at = CURRENT_TIMESTAMP
 WHERE agent_id = sqlc.arg(agent_id)
 RETURNING *;
 
+-- name: UpdateAgentSettings :one
+-- Self-service settings only; a NULL argument keeps the current value and an empty one clears it
+UPDATE agent_credentials
+SET
+    statement_descriptor = NULLIF(COALESCE(sqlc.narg(statement_descriptor), statement_descriptor), ''),
+    notification_email = NULLIF(COALESCE(sqlc.narg(notification_email), notification_email), ''),
+    updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = sqlc.arg(agent_id) AND deleted_at IS NULL
+RETURNING *;
+
 -- name: UpdateAgentMACPath :exec
 UPDATE agent_credentials
 SET mac_secret_path = sqlc.arg(mac_secret_path), updated_at = CURRENT_TIMESTAMP
 WHERE agent_id = sqlc.arg(agent_id);
 
--- name: DeactivateAgent :exec
+-- name: SuspendAgent :one
 UPDATE agent_credentials
-SET is_active = false, updated_at = CURRENT_TIMESTAMP
-WHERE agent_id = sqlc.arg(agent_id);
+SET status = 'suspended', is_active = false, status_reason = sqlc.narg(reason),
+    suspended_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = sqlc.arg(agent_id) AND status = 'active'
+RETURNING *;
 
--- name: ActivateAgent :exec
+-- name: ReactivateAgent :one
 UPDATE agent_credentials
-SET is_active = true, updated_at = CURRENT_TIMESTAMP
-WHERE agent_id = sqlc.arg(agent_id);
+SET status = 'active', is_active = true, status_reason = NULL,
+    suspended_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = sqlc.arg(agent_id) AND status = 'suspended'
+RETURNING *;
+
+-- name: CloseAgent :one
+UPDATE agent_credentials
+SET status = 'closed', is_active = false, status_reason = sqlc.narg(reason),
+    closed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = sqlc.arg(agent_id) AND status <> 'closed'
+RETURNING *;
 
 -- name: AgentExists :one
 SELECT EXISTS(SELECT 1 FROM agent_credentials WHERE agent_id = sqlc.arg(agent_id));
@@ -0,0 +1,37 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_logs (
+    event_type,
+    entity_type,
+    entity_id,
+    agent_id,
+    user_id,
+    action,
+    before_state,
+    after_state,
+    metadata
+) VALUES (
+    sqlc.arg(event_type),
+    sqlc.arg(entity_type),
+    sqlc.arg(entity_id),
+    sqlc.arg(agent_id),
+    sqlc.narg(user_id),
+    sqlc.arg(action),
+    sqlc.narg(before_state),
+    sqlc.narg(after_state),
+    sqlc.arg(metadata)
+) RETURNING *;
+
+-- name: ListAuditLogs :many
+-- Newest first. success matches the entry's result ("success"), read from metadata.result or metadata.outcome.result;
+-- entries without a result only match when success is NULL.
+SELECT * FROM audit_logs
+WHERE (sqlc.narg(actor)::varchar IS NULL OR user_id = sqlc.narg(actor))
+  AND (sqlc.narg(action)::varchar IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(since)::timestamptz IS NULL OR created_at >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamptz IS NULL OR created_at < sqlc.narg(until))
+  AND (
+    sqlc.narg(success)::boolean IS NULL
+    OR (COALESCE(metadata->>'result', metadata->'outcome'->>'result') = 'success') = sqlc.narg(success)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,25 @@
+-- name: CreateBrowserPostChargeIntent :one
+INSERT INTO browser_post_charge_intents (
+    id, agent_id, tran_nbr, customer_id, amount, currency, expires_at
+) VALUES (
+    sqlc.arg(id), sqlc.arg(agent_id), sqlc.arg(tran_nbr), sqlc.arg(customer_id), sqlc.arg(amount), sqlc.arg(currency), sqlc.arg(expires_at)
+) RETURNING *;
+
+-- name: GetBrowserPostChargeIntent :one
+SELECT * FROM browser_post_charge_intents
+WHERE agent_id = sqlc.arg(agent_id) AND tran_nbr = sqlc.arg(tran_nbr);
+
+-- name: ClaimBrowserPostChargeIntent :one
+-- Only one callback saves and charges a form; returns no row when another callback claimed it first
+UPDATE browser_post_charge_intents
+SET status = 'processing', updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: FinishBrowserPostChargeIntent :exec
+UPDATE browser_post_charge_intents
+SET status = sqlc.arg(status),
+    payment_method_id = sqlc.narg(payment_method_id),
+    transaction_id = sqlc.narg(transaction_id),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
@@ -15,6 +15,45 @@ INSERT INTO chargebacks (
     sqlc.arg(raw_data)
 ) RETURNING *;
 
+-- name: UpsertChargeback :one
+-- Idempotent dispute sync: a re-synced case updates North-sourced fields and keeps our
+-- response data (evidence, notes, group link) intact. created_at = updated_at on insert.
+-- A new respond_by_date re-arms the deadline reminder; a missing one keeps the stored date.
+-- An existing group link (from an earlier match or set by hand) is never replaced.
+INSERT INTO chargebacks (
+    id, group_id, agent_id, customer_id,
+    case_number, dispute_date, chargeback_date,
+    chargeback_amount, currency, reason_code, reason_description,
+    status, respond_by_date,
+    evidence_files, response_notes, internal_notes,
+    raw_data, match_status
+) VALUES (
+    sqlc.arg(id), sqlc.narg(group_id), sqlc.arg(agent_id), sqlc.narg(customer_id),
+    sqlc.arg(case_number), sqlc.arg(dispute_date), sqlc.arg(chargeback_date),
+    sqlc.arg(chargeback_amount), sqlc.arg(currency), sqlc.arg(reason_code), sqlc.narg(reason_description),
+    sqlc.arg(status), sqlc.narg(respond_by_date),
+    sqlc.arg(evidence_files), sqlc.narg(response_notes), sqlc.narg(internal_notes),
+    sqlc.arg(raw_data), sqlc.narg(match_status)
+)
+ON CONFLICT (agent_id, case_number) DO UPDATE SET
+    status = EXCLUDED.status,
+    dispute_date = EXCLUDED.dispute_date,
+    chargeback_date = EXCLUDED.chargeback_date,
+    chargeback_amount = EXCLUDED.chargeback_amount,
+    reason_code = EXCLUDED.reason_code,
+    reason_description = EXCLUDED.reason_description,
+    respond_by_date = COALESCE(EXCLUDED.respond_by_date, chargebacks.respond_by_date),
+    deadline_reminder_sent_at = CASE
+        WHEN EXCLUDED.respond_by_date IS DISTINCT FROM chargebacks.respond_by_date AND EXCLUDED.respond_by_date IS NOT NULL THEN NULL
+        ELSE chargebacks.deadline_reminder_sent_at
+    END,
+    raw_data = EXCLUDED.raw_data,
+    group_id = COALESCE(chargebacks.group_id, EXCLUDED.group_id),
+    customer_id = COALESCE(chargebacks.customer_id, EXCLUDED.customer_id),
+    match_status = CASE WHEN chargebacks.group_id IS NOT NULL THEN chargebacks.match_status ELSE EXCLUDED.match_status END,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
 -- name: GetChargebackByID :one
 SELECT * FROM chargebacks
 WHERE id = sqlc.arg(id);
@@ -35,7 +74,10 @@ WHERE
     (sqlc.narg(group_id)::uuid IS NULL OR group_id = sqlc.narg(group_id)) AND
     (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status)) AND
     (sqlc.narg(dispute_date_from)::date IS NULL OR dispute_date >= sqlc.narg(dispute_date_from)) AND
-    (sqlc.narg(dispute_date_to)::date IS NULL OR dispute_date <= sqlc.narg(dispute_date_to))
+    (sqlc.narg(dispute_date_to)::date IS NULL OR dispute_date <= sqlc.narg(dispute_date_to)) AND
+    (sqlc.narg(match_status)::varchar IS NULL OR match_status = sqlc.narg(match_status)) AND
+    (sqlc.narg(min_amount)::numeric IS NULL OR chargeback_amount::numeric >= sqlc.narg(min_amount)::numeric) AND
+    (sqlc.narg(max_amount)::numeric IS NULL OR chargeback_amount::numeric <= sqlc.narg(max_amount)::numeric)
 ORDER BY dispute_date DESC
 LIMIT sqlc.arg(limit_val) OFFSET sqlc.arg(offset_val);
 
@@ -47,7 +89,10 @@ WHERE
     (sqlc.narg(group_id)::uuid IS NULL OR group_id = sqlc.narg(group_id)) AND
     (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status)) AND
     (sqlc.narg(dispute_date_from)::date IS NULL OR dispute_date >= sqlc.narg(dispute_date_from)) AND
-    (sqlc.narg(dispute_date_to)::date IS NULL OR dispute_date <= sqlc.narg(dispute_date_to));
+    (sqlc.narg(dispute_date_to)::date IS NULL OR dispute_date <= sqlc.narg(dispute_date_to)) AND
+    (sqlc.narg(match_status)::varchar IS NULL OR match_status = sqlc.narg(match_status)) AND
+    (sqlc.narg(min_amount)::numeric IS NULL OR chargeback_amount::numeric >= sqlc.narg(min_amount)::numeric) AND
+    (sqlc.narg(max_amount)::numeric IS NULL OR chargeback_amount::numeric <= sqlc.narg(max_amount)::numeric);
 
 -- name: UpdateChargeback :one
 UPDATE chargebacks
@@ -86,6 +131,81 @@ SET
     updated_at = CURRENT_TIMESTAMP
 WHERE id = sqlc.arg(id);
 
+-- name: StoreChargebackEvidence :one
+-- Appends the file references not already on the merchant's open chargeback, if its deadline hasn't passed,, stores the narrative and
+-- marks the evidence ready for submission through North's portal; no row is returned if the chargeback doesn't accept evidence
+UPDATE chargebacks
+SET
+    evidence_files = COALESCE(evidence_files, '{}') || ARRAY(
+        SELECT u.file_url
+        FROM unnest(sqlc.arg(file_urls)::text[]) WITH ORDINALITY AS u(file_url, n)
+        WHERE NOT (u.file_url = ANY(COALESCE(evidence_files, '{}')))
+        ORDER BY u.n
+    ),
+    response_notes = sqlc.arg(response_notes),
+    evidence_ready_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = sqlc.arg(id) AND
+    agent_id = sqlc.arg(agent_id) AND
+    deleted_at IS NULL AND
+    status IN ('new', 'pending') AND
+    (respond_by_date IS NULL OR respond_by_date >= sqlc.arg(as_of)::date)
+RETURNING *;
+
+-- name: ListChargebacksDueWithin :many
+-- Open chargebacks without a response whose respond_by_date falls between as_of and cutoff (inclusive)
+SELECT * FROM chargebacks
+WHERE
+    deleted_at IS NULL AND
+    status IN ('new', 'pending') AND
+    response_submitted_at IS NULL AND
+    respond_by_date IS NOT NULL AND
+    (sqlc.narg(agent_id)::varchar IS NULL OR agent_id = sqlc.narg(agent_id)) AND
+    (NOT sqlc.arg(unnotified_only)::boolean OR deadline_reminder_sent_at IS NULL) AND
+    respond_by_date >= sqlc.arg(as_of)::date AND
+    respond_by_date <= sqlc.arg(cutoff)::date
+ORDER BY respond_by_date, agent_id, case_number;
+
+-- name: MarkChargebackDeadlineReminded :exec
+UPDATE chargebacks
+SET deadline_reminder_sent_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: MarkOverdueChargebacksLost :many
+-- Open chargebacks without a response whose respond_by_date is before as_of are lost
+UPDATE chargebacks
+SET
+    status = 'lost',
+    resolved_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    deleted_at IS NULL AND
+    status IN ('new', 'pending') AND
+    response_submitted_at IS NULL AND
+    respond_by_date < sqlc.arg(as_of)::date AND
+    (sqlc.narg(agent_id)::varchar IS NULL OR agent_id = sqlc.narg(agent_id))
+RETURNING *;
+
+-- name: ListDisputeMatchCandidates :many
+-- A merchant's card authorizations and charges for the disputed amount in a date window,
+-- with the saved card's last four when the payment used one
+SELECT
+    t.id, t.group_id, t.customer_id, t.type, t.auth_code, t.tran_nbr, t.created_at,
+    pm.last_four
+FROM transactions t
+LEFT JOIN customer_payment_methods pm ON pm.id = t.payment_method_id
+WHERE
+    t.agent_id = sqlc.arg(agent_id) AND
+    t.deleted_at IS NULL AND
+    t.payment_method_type = 'credit_card' AND
+    t.type IN ('auth', 'charge', 'capture') AND
+    t.status <> 'failed' AND
+    t.amount = (sqlc.arg(amount)::text)::numeric AND
+    t.created_at >= sqlc.arg(created_from) AND
+    t.created_at < sqlc.arg(created_to)
+ORDER BY t.created_at;
+
 -- name: UpdateChargebackNotes :exec
 UPDATE chargebacks
 SET internal_notes = sqlc.arg(internal_notes), updated_at = CURRENT_TIMESTAMP
@@ -0,0 +1,31 @@
+-- name: CreateCoupon :one
+INSERT INTO coupons (
+    id, agent_id, code, discount_type,
+    percent_off, amount_off, currency,
+    duration, duration_in_months, expires_at
+) VALUES (
+    sqlc.arg(id), sqlc.arg(agent_id), sqlc.arg(code), sqlc.arg(discount_type),
+    sqlc.narg(percent_off), sqlc.narg(amount_off), sqlc.arg(currency),
+    sqlc.arg(duration), sqlc.narg(duration_in_months), sqlc.narg(expires_at)
+) RETURNING *;
+
+-- name: GetCouponByID :one
+SELECT * FROM coupons
+WHERE id = sqlc.arg(id);
+
+-- name: GetCouponByCode :one
+SELECT * FROM coupons
+WHERE agent_id = sqlc.arg(agent_id) AND code = sqlc.arg(code);
+
+-- name: ListCoupons :many
+SELECT * FROM coupons
+WHERE
+    agent_id = sqlc.arg(agent_id) AND
+    (sqlc.narg(is_active)::boolean IS NULL OR is_active = sqlc.narg(is_active))
+ORDER BY created_at DESC
+LIMIT sqlc.arg(limit_val) OFFSET sqlc.arg(offset_val);
+
+-- name: DeactivateCoupon :exec
+UPDATE coupons
+SET is_active = false, updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
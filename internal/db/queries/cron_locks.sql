@@ -0,0 +1,6 @@
+-- name: TryLockCronJob :one
+-- Session-level lock: held until UnlockCronJob or until the connection closes, so a crashed run never leaves it held
+SELECT pg_try_advisory_lock(hashtextextended('cron:' || sqlc.arg(job)::varchar, 0));
+
+-- name: UnlockCronJob :one
+SELECT pg_advisory_unlock(hashtextextended('cron:' || sqlc.arg(job)::varchar, 0));
@@ -0,0 +1,15 @@
+-- name: CreateCronRun :one
+INSERT INTO cron_runs (
+    job, started_at, finished_at, duration_ms,
+    processed, succeeded, failed, retried, amount_charged, errors
+) VALUES (
+    sqlc.arg(job), sqlc.arg(started_at), sqlc.arg(finished_at), sqlc.arg(duration_ms),
+    sqlc.arg(processed), sqlc.arg(succeeded), sqlc.arg(failed), sqlc.arg(retried), sqlc.arg(amount_charged), sqlc.arg(errors)
+)
+RETURNING *;
+
+-- name: ListCronRuns :many
+SELECT * FROM cron_runs
+WHERE job = sqlc.arg(job)
+ORDER BY started_at DESC
+LIMIT sqlc.arg(limit_val);
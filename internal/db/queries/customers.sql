@@ -0,0 +1,19 @@
+-- name: GetCustomer :one
+SELECT * FROM customers
+WHERE agent_id = sqlc.arg(agent_id) AND customer_id = sqlc.arg(customer_id);
+
+-- name: CreateCustomer :one
+-- A concurrent first reference may insert the same customer; the existing row is returned instead
+INSERT INTO customers (
+    agent_id,
+    customer_id,
+    email,
+    auto_created
+) VALUES (
+    sqlc.arg(agent_id),
+    sqlc.arg(customer_id),
+    sqlc.narg(email),
+    sqlc.arg(auto_created)
+)
+ON CONFLICT (agent_id, customer_id) DO UPDATE SET customer_id = EXCLUDED.customer_id
+RETURNING *;
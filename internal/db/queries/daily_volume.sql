@@ -0,0 +1,17 @@
+-- name: ReserveDailyVolume :one
+-- Adds amount to the merchant's running total for the day unless that would pass the limit.
+-- The upsert locks the counter row, so concurrent reservations are serialized; no row = limit reached.
+INSERT INTO merchant_daily_volume (agent_id, volume_date, amount)
+SELECT sqlc.arg(agent_id), sqlc.arg(volume_date), sqlc.arg(amount)::numeric
+WHERE sqlc.arg(amount)::numeric <= sqlc.arg(daily_limit)::numeric
+ON CONFLICT (agent_id, volume_date) DO UPDATE
+SET amount = merchant_daily_volume.amount + EXCLUDED.amount
+WHERE merchant_daily_volume.amount + EXCLUDED.amount <= sqlc.arg(daily_limit)::numeric
+RETURNING amount;
+
+-- name: ReleaseDailyVolume :exec
+-- Returns a reservation whose payment didn't go through
+UPDATE merchant_daily_volume
+SET amount = GREATEST(amount - sqlc.arg(amount)::numeric, 0)
+WHERE agent_id = sqlc.arg(agent_id) AND volume_date = sqlc.arg(volume_date);
+
@@ -0,0 +1,18 @@
+-- name: GetTranNbr :one
+SELECT * FROM epx_tran_nbrs
+WHERE transaction_id = sqlc.arg(transaction_id);
+
+-- name: NextTranNbr :one
+-- Takes the merchant's next TRAN_NBR, starting at 1
+INSERT INTO epx_tran_nbr_counters (agent_id, last_tran_nbr)
+VALUES (sqlc.arg(agent_id), 1)
+ON CONFLICT (agent_id) DO UPDATE
+SET last_tran_nbr = epx_tran_nbr_counters.last_tran_nbr + 1
+RETURNING last_tran_nbr;
+
+-- name: AssignTranNbr :one
+-- Returns no row when the transaction already has a number (a concurrent retry assigned it first)
+INSERT INTO epx_tran_nbrs (transaction_id, agent_id, tran_nbr)
+VALUES (sqlc.arg(transaction_id), sqlc.arg(agent_id), sqlc.arg(tran_nbr))
+ON CONFLICT (transaction_id) DO NOTHING
+RETURNING *;
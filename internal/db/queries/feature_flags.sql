@@ -0,0 +1,21 @@
+-- name: SetFeatureFlag :one
+-- agent_id NULL sets the global flag
+INSERT INTO feature_flags (name, agent_id, enabled, reason, updated_by)
+VALUES (sqlc.arg(name), sqlc.narg(agent_id), sqlc.arg(enabled), sqlc.narg(reason), sqlc.narg(updated_by))
+ON CONFLICT (name, (COALESCE(agent_id, ''))) DO UPDATE SET
+    enabled = EXCLUDED.enabled,
+    reason = EXCLUDED.reason,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetFeatureFlagsForAgent :many
+-- The global flag and the merchant's own, whichever exist
+SELECT * FROM feature_flags
+WHERE name = sqlc.arg(name)
+  AND (agent_id IS NULL OR agent_id = sqlc.arg(agent_id))
+ORDER BY agent_id NULLS FIRST;
+
+-- name: ListFeatureFlags :many
+SELECT * FROM feature_flags
+ORDER BY name, agent_id NULLS FIRST;
@@ -1,18 +1,25 @@
 -- name: CreatePaymentMethod :one
+-- data_region is stamped from the merchant so region-scoped exports/purges don't depend on callers
 INSERT INTO customer_payment_methods (
     id, agent_id, customer_id, payment_type,
     payment_token, last_four,
     card_brand, card_exp_month, card_exp_year,
     bank_name, account_type,
-    is_default, is_active, is_verified
+    is_default, is_active, is_verified, idempotency_key, data_region
 ) VALUES (
     sqlc.arg(id), sqlc.arg(agent_id), sqlc.arg(customer_id), sqlc.arg(payment_type),
     sqlc.arg(payment_token), sqlc.arg(last_four),
     sqlc.narg(card_brand), sqlc.narg(card_exp_month), sqlc.narg(card_exp_year),
     sqlc.narg(bank_name), sqlc.narg(account_type),
-    sqlc.arg(is_default), sqlc.arg(is_active), sqlc.arg(is_verified)
+    sqlc.arg(is_default), sqlc.arg(is_active), sqlc.arg(is_verified), sqlc.narg(idempotency_key),
+    COALESCE((SELECT ac.data_region FROM agent_credentials ac WHERE ac.agent_id = sqlc.arg(agent_id)), 'us')
 ) RETURNING *;
 
+-- name: GetPaymentMethodByIdempotencyKey :one
+-- The payment method a merchant's request with this idempotency key saved, even if it was deleted since
+SELECT * FROM customer_payment_methods
+WHERE agent_id = sqlc.arg(agent_id) AND idempotency_key = sqlc.arg(idempotency_key);
+
 -- name: GetPaymentMethodByID :one
 SELECT * FROM customer_payment_methods
 WHERE id = sqlc.arg(id) AND deleted_at IS NULL;
@@ -39,10 +46,11 @@ WHERE agent_id = sqlc.arg(agent_id) AND customer_id = sqlc.arg(customer_id) AND
 LIMIT 1;
 
 -- name: SetPaymentMethodAsDefault :exec
--- First unset all defaults for this customer
+-- Unsets the customer's current default. Only that row changes, so no other row's updated_at moves; callers
+-- take LockCustomerPaymentMethods first so concurrent default changes queue until this transaction commits.
 UPDATE customer_payment_methods
 SET is_default = false, updated_at = CURRENT_TIMESTAMP
-WHERE agent_id = sqlc.arg(agent_id) AND customer_id = sqlc.arg(customer_id) AND deleted_at IS NULL;
+WHERE agent_id = sqlc.arg(agent_id) AND customer_id = sqlc.arg(customer_id) AND is_default = true AND deleted_at IS NULL;
 
 -- name: MarkPaymentMethodAsDefault :exec
 -- Then set the specified one as default
@@ -60,11 +68,80 @@ UPDATE customer_payment_methods
 SET is_verified = true, updated_at = CURRENT_TIMESTAMP
 WHERE id = sqlc.arg(id) AND deleted_at IS NULL;
 
+-- name: UpdatePaymentMethodExpiry :one
+UPDATE customer_payment_methods
+SET card_exp_month = sqlc.arg(card_exp_month), card_exp_year = sqlc.arg(card_exp_year), expiry_notified_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListExpiringPaymentMethods :many
+-- Active cards still valid on as_of whose expiry month ends on or before cutoff.
+-- A card is valid through the last day of its expiry month.
+SELECT * FROM customer_payment_methods
+WHERE
+    deleted_at IS NULL AND
+    is_active = true AND
+    payment_type = 'credit_card' AND
+    card_exp_month IS NOT NULL AND card_exp_year IS NOT NULL AND
+    (sqlc.narg(agent_id)::varchar IS NULL OR agent_id = sqlc.narg(agent_id)) AND
+    (NOT sqlc.arg(unnotified_only)::boolean OR expiry_notified_at IS NULL) AND
+    make_date(card_exp_year, card_exp_month, 1) + INTERVAL '1 month' > sqlc.arg(as_of)::date AND
+    make_date(card_exp_year, card_exp_month, 1) + INTERVAL '1 month' <= sqlc.arg(cutoff)::date + 1
+ORDER BY card_exp_year, card_exp_month, agent_id, customer_id;
+
+-- name: MarkPaymentMethodExpiryNotified :exec
+UPDATE customer_payment_methods
+SET expiry_notified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL;
+
+-- name: SetMicroDeposits :exec
+UPDATE customer_payment_methods
+SET
+    micro_deposit_hash = sqlc.arg(micro_deposit_hash),
+    micro_deposit_sent_at = CURRENT_TIMESTAMP,
+    micro_deposit_attempts = 0,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL;
+
+-- name: RecordMicroDepositAttempt :one
+-- Counts a verification attempt against pending micro-deposits unless max_attempts are used up. The check and the
+-- increment are one statement, so concurrent guesses can't get past the limit; no row = locked or none pending.
+UPDATE customer_payment_methods
+SET micro_deposit_attempts = micro_deposit_attempts + 1, updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = sqlc.arg(id) AND
+    deleted_at IS NULL AND
+    micro_deposit_hash IS NOT NULL AND
+    micro_deposit_attempts < sqlc.arg(max_attempts)
+RETURNING *;
+
+-- name: CompleteMicroDepositVerification :one
+-- The attempt that matched was counted by RecordMicroDepositAttempt; it isn't a failure, so it's taken back off
+UPDATE customer_payment_methods
+SET
+    is_verified = true,
+    micro_deposit_hash = NULL,
+    micro_deposit_attempts = GREATEST(micro_deposit_attempts - 1, 0),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL
+RETURNING *;
+
 -- name: DeactivatePaymentMethod :exec
 UPDATE customer_payment_methods
 SET is_active = false, updated_at = CURRENT_TIMESTAMP
 WHERE id = sqlc.arg(id) AND deleted_at IS NULL;
 
+-- name: RecordACHReturn :one
+UPDATE customer_payment_methods
+SET
+    return_count = return_count + 1,
+    last_return_code = sqlc.arg(return_code)::varchar,
+    is_active = CASE WHEN sqlc.narg(deactivation_reason)::varchar IS NULL THEN is_active ELSE false END,
+    deactivation_reason = COALESCE(sqlc.narg(deactivation_reason)::varchar, deactivation_reason),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND deleted_at IS NULL
+RETURNING *;
+
 -- name: ActivatePaymentMethod :exec
 UPDATE customer_payment_methods
 SET is_active = true, updated_at = CURRENT_TIMESTAMP
@@ -74,3 +151,14 @@ WHERE id = sqlc.arg(id) AND deleted_at IS NULL;
 UPDATE customer_payment_methods
 SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
 WHERE id = sqlc.arg(id) AND deleted_at IS NULL;
+
+-- name: LockCustomerPaymentMethods :exec
+-- Serializes saves and default changes for one customer until the transaction ends, so concurrent saves can't both pass the cap
+-- and concurrent default changes can't both clear the old default
+SELECT pg_advisory_xact_lock(hashtextextended(sqlc.arg(agent_id)::varchar || ':' || sqlc.arg(customer_id)::varchar, 0));
+
+-- name: ListActivePaymentMethodsByLastUse :many
+-- Least recently used first (never-used methods by creation time); the default is pruned last
+SELECT id FROM customer_payment_methods
+WHERE agent_id = sqlc.arg(agent_id) AND customer_id = sqlc.arg(customer_id) AND is_active = true AND deleted_at IS NULL
+ORDER BY is_default ASC, COALESCE(last_used_at, created_at) ASC, created_at ASC;
@@ -0,0 +1,20 @@
+-- name: ClaimSaleBatch :one
+-- Claims a batch key for processing. Returns no row if the key is already completed or being processed;
+-- an unfinished claim older than stale_before is taken over by a retry of the same sales (its items are still
+-- protected by their own keys).
+INSERT INTO sale_batches (agent_id, idempotency_key, request_hash)
+VALUES (sqlc.arg(agent_id), sqlc.arg(idempotency_key), sqlc.arg(request_hash))
+ON CONFLICT (agent_id, idempotency_key) DO UPDATE
+SET claimed_at = CURRENT_TIMESTAMP
+WHERE sale_batches.completed_at IS NULL AND sale_batches.claimed_at < sqlc.arg(stale_before)
+  AND sale_batches.request_hash = sqlc.arg(request_hash)
+RETURNING *;
+
+-- name: GetSaleBatch :one
+SELECT * FROM sale_batches
+WHERE agent_id = sqlc.arg(agent_id) AND idempotency_key = sqlc.arg(idempotency_key);
+
+-- name: CompleteSaleBatch :exec
+UPDATE sale_batches
+SET results = sqlc.arg(results), completed_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,119 @@
+-- name: CreateService :one
+INSERT INTO services (
+    service_id,
+    service_name
+) VALUES (
+    sqlc.arg(service_id),
+    sqlc.arg(service_name)
+) RETURNING *;
+
+-- name: GetServiceByServiceID :one
+SELECT * FROM services
+WHERE service_id = sqlc.arg(service_id);
+
+-- name: ListServices :many
+-- search is an ILIKE pattern matched against the service ID and name (NULL = every service)
+SELECT * FROM services
+WHERE sqlc.narg(search)::text IS NULL
+   OR service_id ILIKE sqlc.narg(search)
+   OR service_name ILIKE sqlc.narg(search)
+ORDER BY service_id
+LIMIT sqlc.arg(limit_val) OFFSET sqlc.arg(offset_val);
+
+-- name: AddServicePublicKey :one
+INSERT INTO service_public_keys (
+    service_id,
+    key_id,
+    public_key
+) VALUES (
+    sqlc.arg(service_id),
+    sqlc.arg(key_id),
+    sqlc.arg(public_key)
+) RETURNING *;
+
+-- name: ListActiveServicePublicKeys :many
+-- Keys that verify an active service's tokens (none once the service is deactivated)
+SELECT k.* FROM service_public_keys k
+JOIN services s ON s.id = k.service_id
+WHERE s.service_id = sqlc.arg(service_id)
+  AND s.is_active = true
+  AND k.is_active = true
+ORDER BY k.created_at DESC;
+
+-- name: ListServicePublicKeys :many
+SELECT * FROM service_public_keys
+WHERE service_id = sqlc.arg(service_id)
+ORDER BY created_at DESC;
+
+-- name: RetireServicePublicKey :one
+-- Retires the key only while another of the service's keys stays active. The active keys are locked first,
+-- so concurrent retirements wait for each other and can't leave the service with none.
+WITH active AS (
+    SELECT key_id FROM service_public_keys
+    WHERE service_id = sqlc.arg(service_id)
+      AND is_active = true
+    FOR UPDATE
+)
+UPDATE service_public_keys
+SET is_active = false,
+    retired_at = CURRENT_TIMESTAMP
+WHERE service_id = sqlc.arg(service_id)
+  AND key_id = sqlc.arg(key_id)
+  AND is_active = true
+  AND (SELECT COUNT(*) FROM active) > 1
+RETURNING *;
+
+-- name: DeactivateService :one
+-- A deactivated service's keys stop verifying tokens (see ListActiveServicePublicKeys)
+UPDATE services
+SET is_active = false,
+    updated_at = CURRENT_TIMESTAMP
+WHERE service_id = sqlc.arg(service_id)
+RETURNING *;
+
+-- name: GrantServiceAccess :one
+-- Granting an existing grant replaces its expiry
+INSERT INTO service_merchants (
+    service_id,
+    agent_id,
+    expires_at
+) VALUES (
+    sqlc.arg(service_id),
+    sqlc.arg(agent_id),
+    sqlc.narg(expires_at)
+)
+ON CONFLICT (service_id, agent_id) DO UPDATE SET expires_at = EXCLUDED.expires_at
+RETURNING *;
+
+-- name: RevokeServiceAccess :execrows
+DELETE FROM service_merchants
+WHERE service_id = sqlc.arg(service_id)
+  AND agent_id = sqlc.arg(agent_id);
+
+-- name: HasServiceGrant :one
+-- Whether an active service has a grant for the merchant that hasn't expired by now
+SELECT EXISTS (
+    SELECT 1 FROM service_merchants m
+    JOIN services s ON s.id = m.service_id
+    WHERE s.service_id = sqlc.arg(service_id)
+      AND m.agent_id = sqlc.arg(agent_id)
+      AND s.is_active = true
+      AND (m.expires_at IS NULL OR m.expires_at > sqlc.arg(now)::timestamptz)
+);
+
+-- name: ListServiceGrantDetails :many
+-- Every grant with its service and merchant, optionally for one service and/or merchant
+SELECT
+    s.service_id,
+    s.service_name,
+    s.is_active AS service_active,
+    m.agent_id,
+    a.agent_name,
+    m.granted_at,
+    m.expires_at
+FROM service_merchants m
+JOIN services s ON s.id = m.service_id
+JOIN agent_credentials a ON a.agent_id = m.agent_id
+WHERE (sqlc.narg(service_id)::varchar IS NULL OR s.service_id = sqlc.narg(service_id))
+  AND (sqlc.narg(agent_id)::varchar IS NULL OR m.agent_id = sqlc.narg(agent_id))
+ORDER BY s.service_id, m.agent_id;
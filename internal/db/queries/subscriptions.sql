@@ -4,13 +4,15 @@ INSERT INTO subscriptions (
     interval_value, interval_unit, status,
     payment_method_id, next_billing_date,
     failure_retry_count, max_retries,
-    gateway_subscription_id, metadata
+    gateway_subscription_id, metadata,
+    coupon_id, coupon_ends_at
 ) VALUES (
     sqlc.arg(id), sqlc.arg(agent_id), sqlc.arg(customer_id), sqlc.arg(amount), sqlc.arg(currency),
     sqlc.arg(interval_value), sqlc.arg(interval_unit), sqlc.arg(status),
     sqlc.arg(payment_method_id), sqlc.arg(next_billing_date),
     sqlc.arg(failure_retry_count), sqlc.arg(max_retries),
-    sqlc.narg(gateway_subscription_id), sqlc.arg(metadata)
+    sqlc.narg(gateway_subscription_id), sqlc.arg(metadata),
+    sqlc.narg(coupon_id), sqlc.narg(coupon_ends_at)
 ) RETURNING *;
 
 -- name: GetSubscriptionByID :one
@@ -47,6 +49,7 @@ LIMIT sqlc.arg(limit_val);
 -- name: UpdateSubscription :one
 UPDATE subscriptions
 SET
+    amount_changed_at = CASE WHEN amount <> sqlc.arg(amount) THEN CURRENT_TIMESTAMP ELSE amount_changed_at END,
     amount = sqlc.arg(amount),
     interval_value = sqlc.arg(interval_value),
     interval_unit = sqlc.arg(interval_unit),
@@ -88,12 +91,35 @@ WHERE status = 'active' AND next_billing_date <= sqlc.arg(next_billing_date)
 ORDER BY next_billing_date ASC
 LIMIT sqlc.arg(limit_val);
 
+-- name: ClaimSubscriptionsDueForBilling :many
+-- Claims up to limit_val active subscriptions due by next_billing_date for one billing run. Rows another run holds
+-- are skipped; a claim older than stale_before is taken over (the run that held it never finished).
+UPDATE subscriptions
+SET billing_claimed_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT id FROM subscriptions
+    WHERE
+        status = 'active' AND
+        next_billing_date <= sqlc.arg(next_billing_date) AND
+        (billing_claimed_at IS NULL OR billing_claimed_at < sqlc.arg(stale_before))
+    ORDER BY next_billing_date ASC
+    LIMIT sqlc.arg(limit_val)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ReleaseSubscriptionBillingClaim :exec
+UPDATE subscriptions
+SET billing_claimed_at = NULL
+WHERE id = sqlc.arg(id);
+
 -- name: UpdateSubscriptionBilling :one
 UPDATE subscriptions
 SET
     next_billing_date = sqlc.arg(next_billing_date),
     failure_retry_count = sqlc.arg(failure_retry_count),
     status = sqlc.arg(status),
+    coupon_applied_count = sqlc.arg(coupon_applied_count),
     updated_at = CURRENT_TIMESTAMP
 WHERE id = sqlc.arg(id)
 RETURNING *;
@@ -106,3 +132,15 @@ SET
     updated_at = CURRENT_TIMESTAMP
 WHERE id = sqlc.arg(id)
 RETURNING *;
+
+-- name: SwitchSubscriptionPaymentMethod :one
+UPDATE subscriptions
+SET
+    previous_payment_method_id = payment_method_id,
+    payment_method_id = sqlc.arg(payment_method_id),
+    payment_method_switched_at = CURRENT_TIMESTAMP,
+    failure_retry_count = 0,
+    status = 'active',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id)
+RETURNING *;
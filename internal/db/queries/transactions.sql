@@ -1,23 +1,124 @@
 -- name: CreateTransaction :one
+-- data_region is stamped from the merchant so region-scoped exports/purges don't depend on callers
 INSERT INTO transactions (
     id, group_id, agent_id, customer_id,
     amount, currency, status, type, payment_method_type, payment_method_id,
     auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2,
-    idempotency_key, metadata
+    card_funding_type, idempotency_key, request_hash, metadata, verification_outcome, three_ds, tran_nbr, pending_expires_at, daily_volume_date, card_fingerprint, data_region
 ) VALUES (
     sqlc.arg(id), sqlc.arg(group_id), sqlc.arg(agent_id), sqlc.narg(customer_id),
     sqlc.arg(amount), sqlc.arg(currency), sqlc.arg(status), sqlc.arg(type), sqlc.arg(payment_method_type), sqlc.narg(payment_method_id),
     sqlc.narg(auth_guid), sqlc.narg(auth_resp), sqlc.narg(auth_code), sqlc.narg(auth_resp_text), sqlc.narg(auth_card_type), sqlc.narg(auth_avs), sqlc.narg(auth_cvv2),
-    sqlc.narg(idempotency_key), sqlc.arg(metadata)
+    sqlc.narg(card_funding_type), sqlc.narg(idempotency_key), sqlc.narg(request_hash), sqlc.arg(metadata), sqlc.narg(verification_outcome), sqlc.narg(three_ds), sqlc.narg(tran_nbr), sqlc.narg(pending_expires_at), sqlc.narg(daily_volume_date), sqlc.narg(card_fingerprint),
+    COALESCE((SELECT ac.data_region FROM agent_credentials ac WHERE ac.agent_id = sqlc.arg(agent_id)), 'us')
 ) RETURNING *;
 
+-- name: ClaimStalePendingTransaction :one
+-- Takes over the pending row that reserved a merchant's idempotency key once its request has gone quiet. Only a
+-- reservation of the same request is claimed; returns no row when it finished or another retry claimed it first.
+UPDATE transactions
+SET updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = sqlc.arg(agent_id)
+  AND idempotency_key = sqlc.arg(idempotency_key)
+  AND request_hash = sqlc.arg(request_hash)
+  AND status = 'pending'
+  AND updated_at < sqlc.arg(stale_before)
+RETURNING *;
+
+-- name: CompletePendingTransaction :one
+-- Records the EPX result on the pending row that reserved the request's idempotency key (or a Browser Post form).
+-- A NULL payment_method_type, card_fingerprint or last_four keeps the row's.
+UPDATE transactions
+SET
+    status = sqlc.arg(status),
+    payment_method_type = COALESCE(sqlc.narg(payment_method_type), payment_method_type),
+    card_fingerprint = COALESCE(sqlc.narg(card_fingerprint), card_fingerprint),
+    last_four = COALESCE(sqlc.narg(last_four), last_four),
+    auth_guid = sqlc.narg(auth_guid),
+    auth_resp = sqlc.narg(auth_resp),
+    auth_code = sqlc.narg(auth_code),
+    auth_resp_text = sqlc.narg(auth_resp_text),
+    auth_card_type = sqlc.narg(auth_card_type),
+    auth_avs = sqlc.narg(auth_avs),
+    auth_cvv2 = sqlc.narg(auth_cvv2),
+    card_funding_type = sqlc.narg(card_funding_type),
+    metadata = sqlc.arg(metadata),
+    verification_outcome = sqlc.narg(verification_outcome),
+    tran_nbr = sqlc.narg(tran_nbr),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: SetPendingDailyVolume :exec
+-- Records the UTC day of the daily volume a pending row's request holds: NULL once its hold is released (the
+-- request never reached EPX), or the day of the hold a retry took over the row with
+UPDATE transactions
+SET daily_volume_date = sqlc.narg(daily_volume_date)
+WHERE id = sqlc.arg(id) AND status = 'pending';
+
+-- name: ExpirePendingTransaction :exec
+-- A pending Browser Post transaction whose callback came too late never gets a result. The late callback's BRIC
+-- and response are kept on the expired row, so an approval EPX made anyway can be found and voided.
+UPDATE transactions
+SET
+    status = 'expired',
+    auth_guid = sqlc.narg(auth_guid),
+    auth_resp = sqlc.narg(auth_resp),
+    auth_resp_text = sqlc.narg(auth_resp_text),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND status = 'pending';
+
+-- name: LockTransactionGroup :exec
+-- Serializes the follow-ups that draw on a group's amounts until the transaction ends, so concurrent requests
+-- can't each pass the group's checks against the same remaining amount
+SELECT pg_advisory_xact_lock(hashtextextended('transaction_group:' || sqlc.arg(group_id)::text, 0));
+
+-- name: GetGroupThreeDS :one
+-- The 3-D Secure result of the group's authorization, for chargeback evidence
+SELECT three_ds FROM transactions
+WHERE group_id = sqlc.arg(group_id) AND three_ds IS NOT NULL
+ORDER BY created_at
+LIMIT 1;
+
 -- name: GetTransactionByID :one
 SELECT * FROM transactions
 WHERE id = sqlc.arg(id);
 
+-- name: GetTransactionByAuthGUID :one
+-- The transaction EPX returned this BRIC for (any merchant; callers check ownership)
+SELECT * FROM transactions
+WHERE auth_guid = sqlc.arg(auth_guid)
+  AND deleted_at IS NULL
+ORDER BY created_at ASC
+LIMIT 1;
+
+-- name: GetTransactionByTranNbr :one
+-- The merchant's transaction sent to EPX with this TRAN_NBR, including Browser Post callbacks (their form's
+-- TRAN_NBR). Browser Post rows recorded before tran_nbr was stored for them match on their idempotency key.
+SELECT * FROM transactions
+WHERE agent_id = sqlc.arg(agent_id)
+  AND deleted_at IS NULL
+  AND (tran_nbr = sqlc.arg(tran_nbr)::bigint
+       OR (tran_nbr IS NULL AND idempotency_key = sqlc.arg(tran_nbr_key)::varchar))
+ORDER BY created_at ASC
+LIMIT 1;
+
 -- name: GetTransactionByIdempotencyKey :one
+-- Keys are unique per merchant, so the same key can name different merchants' transactions
+SELECT * FROM transactions
+WHERE agent_id = sqlc.arg(agent_id)
+  AND idempotency_key = sqlc.arg(idempotency_key);
+
+-- name: GetAgentTransactionsByIDs :many
+-- Scoped to the agent so other merchants' transactions are indistinguishable from missing ones
 SELECT * FROM transactions
-WHERE idempotency_key = sqlc.arg(idempotency_key);
+WHERE agent_id = sqlc.arg(agent_id)
+  AND id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: GetTransactionsByIDs :many
+-- Unscoped: callers check each transaction's agent before returning it
+SELECT * FROM transactions
+WHERE id = ANY(sqlc.arg(ids)::uuid[]);
 
 -- name: GetTransactionsByGroupID :many
 SELECT * FROM transactions
@@ -32,10 +133,32 @@ WHERE
     (sqlc.narg(group_id)::uuid IS NULL OR group_id = sqlc.narg(group_id)) AND
     (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status)) AND
     (sqlc.narg(type)::varchar IS NULL OR type = sqlc.narg(type)) AND
-    (sqlc.narg(payment_method_id)::uuid IS NULL OR payment_method_id = sqlc.narg(payment_method_id))
-ORDER BY created_at DESC
+    (sqlc.narg(payment_method_id)::uuid IS NULL OR payment_method_id = sqlc.narg(payment_method_id)) AND
+    (sqlc.narg(metadata_contains)::jsonb IS NULL OR metadata @> sqlc.narg(metadata_contains)::jsonb) AND
+    (sqlc.narg(min_amount)::numeric IS NULL OR amount >= sqlc.narg(min_amount)::numeric) AND
+    (sqlc.narg(max_amount)::numeric IS NULL OR amount <= sqlc.narg(max_amount)::numeric) AND
+    (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from)::timestamptz) AND
+    (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to)::timestamptz)
+ORDER BY created_at DESC, id DESC
 LIMIT sqlc.arg(limit_val) OFFSET sqlc.arg(offset_val);
 
+-- name: ListTransactionsAfterCursor :many
+-- Keyset pagination: newest first, strictly older than the (created_at, id) cursor (NULL = first page).
+-- Stable under concurrent inserts, unlike OFFSET.
+SELECT * FROM transactions
+WHERE
+    agent_id = sqlc.arg(agent_id) AND
+    (sqlc.narg(customer_id)::varchar IS NULL OR customer_id = sqlc.narg(customer_id)) AND
+    (sqlc.narg(metadata_contains)::jsonb IS NULL OR metadata @> sqlc.narg(metadata_contains)::jsonb) AND
+    (sqlc.narg(min_amount)::numeric IS NULL OR amount >= sqlc.narg(min_amount)::numeric) AND
+    (sqlc.narg(max_amount)::numeric IS NULL OR amount <= sqlc.narg(max_amount)::numeric) AND
+    (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from)::timestamptz) AND
+    (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to)::timestamptz) AND
+    (sqlc.narg(cursor_created_at)::timestamptz IS NULL OR
+        (created_at, id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_val);
+
 -- name: CountTransactions :one
 SELECT COUNT(*) FROM transactions
 WHERE
@@ -44,7 +167,12 @@ WHERE
     (sqlc.narg(group_id)::uuid IS NULL OR group_id = sqlc.narg(group_id)) AND
     (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status)) AND
     (sqlc.narg(type)::varchar IS NULL OR type = sqlc.narg(type)) AND
-    (sqlc.narg(payment_method_id)::uuid IS NULL OR payment_method_id = sqlc.narg(payment_method_id));
+    (sqlc.narg(payment_method_id)::uuid IS NULL OR payment_method_id = sqlc.narg(payment_method_id)) AND
+    (sqlc.narg(metadata_contains)::jsonb IS NULL OR metadata @> sqlc.narg(metadata_contains)::jsonb) AND
+    (sqlc.narg(min_amount)::numeric IS NULL OR amount >= sqlc.narg(min_amount)::numeric) AND
+    (sqlc.narg(max_amount)::numeric IS NULL OR amount <= sqlc.narg(max_amount)::numeric) AND
+    (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from)::timestamptz) AND
+    (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to)::timestamptz);
 
 -- name: UpdateTransaction :one
 UPDATE transactions
@@ -61,3 +189,111 @@ RETURNING *;
 UPDATE transactions
 SET status = sqlc.arg(status), updated_at = CURRENT_TIMESTAMP
 WHERE id = sqlc.arg(id);
+
+-- name: MarkTransactionSettled :exec
+UPDATE transactions
+SET settled_at = sqlc.arg(settled_at), funding_date = sqlc.narg(funding_date), updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND settled_at IS NULL;
+
+-- name: ListSubscriptionTransactions :many
+-- Includes billing charges and any follow-up transactions (refunds, voids) in the same group
+SELECT * FROM transactions
+WHERE group_id IN (
+    SELECT t.group_id FROM transactions t
+    WHERE t.metadata->>'subscription_id' = sqlc.arg(subscription_id)::text
+)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(limit_val) OFFSET sqlc.arg(offset_val);
+
+-- name: CountSubscriptionTransactions :one
+SELECT COUNT(*) FROM transactions
+WHERE group_id IN (
+    SELECT t.group_id FROM transactions t
+    WHERE t.metadata->>'subscription_id' = sqlc.arg(subscription_id)::text
+);
+
+-- name: ListSubscriptionChargeAttempts :many
+-- Every billing attempt (approved and declined charges) of a merchant's subscription, oldest first
+SELECT * FROM transactions
+WHERE metadata->>'subscription_id' = sqlc.arg(subscription_id)::text
+  AND agent_id = sqlc.arg(agent_id)
+  AND type = 'charge'
+ORDER BY created_at ASC, id ASC;
+
+-- name: ListTransactionsForReconciliation :many
+-- Settleable transactions (sales, captures, refunds) with an EPX token in the date range.
+-- voided_in_group flags transactions whose group was voided (never expected to settle).
+SELECT t.*,
+    EXISTS (
+        SELECT 1 FROM transactions v
+        WHERE v.group_id = t.group_id AND v.status = 'voided'
+    )::boolean AS voided_in_group
+FROM transactions t
+WHERE t.agent_id = sqlc.arg(agent_id)
+  AND t.type IN ('charge', 'capture', 'refund')
+  AND t.auth_guid IS NOT NULL
+  AND t.created_at >= sqlc.arg(created_from)
+  AND t.created_at < sqlc.arg(created_to)
+ORDER BY t.created_at ASC;
+
+-- name: ListUncapturedAuthorizations :many
+-- A merchant's approved authorizations last approved before the cutoff (the auth itself and any completed increment)
+-- whose group has no completed capture and no void, oldest first. Candidates for the auth auto-void job.
+SELECT t.* FROM transactions t
+WHERE t.agent_id = sqlc.arg(agent_id)
+  AND t.type = 'auth'
+  AND t.status = 'completed'
+  AND t.auth_guid IS NOT NULL
+  AND t.deleted_at IS NULL
+  AND t.created_at < sqlc.arg(approved_before)
+  AND NOT EXISTS (
+      SELECT 1 FROM transactions o
+      WHERE o.group_id = t.group_id
+        AND (o.status = 'voided'
+             OR (o.type = 'capture' AND o.status = 'completed')
+             OR (o.type = 'increment' AND o.status = 'completed' AND o.created_at >= sqlc.arg(approved_before)))
+  )
+ORDER BY t.created_at ASC
+LIMIT sqlc.arg(limit_val);
+
+-- name: GetTransactionVelocity :one
+-- Sale, authorization and increment attempts by one card (card_fingerprint) or customer since a time, for
+-- velocity limits. Every attempt counts, declines included; the amount only sums attempts that weren't declined.
+SELECT
+    COUNT(*)::bigint AS transaction_count,
+    COALESCE(SUM(amount) FILTER (WHERE status <> 'failed'), 0)::numeric AS total_amount
+FROM transactions
+WHERE agent_id = sqlc.arg(agent_id)
+  AND type IN ('charge', 'auth', 'increment')
+  AND created_at >= sqlc.arg(since)
+  AND deleted_at IS NULL
+  AND (sqlc.narg(card_fingerprint)::varchar IS NULL OR card_fingerprint = sqlc.narg(card_fingerprint)::varchar)
+  AND (sqlc.narg(customer_id)::varchar IS NULL OR customer_id = sqlc.narg(customer_id)::varchar);
+
+-- name: GetSettlementTotals :many
+-- Per UTC day, currency and kind: completed sales and captures ('sale'), completed refunds ('refund') and voids
+-- of payments that had captured money ('void'; voiding an uncaptured authorization moves no money).
+SELECT
+    (t.created_at AT TIME ZONE 'UTC')::date AS day,
+    t.currency,
+    (CASE
+        WHEN t.status = 'voided' THEN 'void'
+        WHEN t.type = 'refund' THEN 'refund'
+        ELSE 'sale'
+    END)::varchar AS kind,
+    COUNT(*)::bigint AS transaction_count,
+    COALESCE(SUM(t.amount), 0)::numeric AS total_amount
+FROM transactions t
+WHERE t.agent_id = sqlc.arg(agent_id)
+  AND t.created_at >= sqlc.arg(created_from)
+  AND t.created_at < sqlc.arg(created_to)
+  AND (
+    (t.status = 'completed' AND t.type IN ('charge', 'capture', 'refund'))
+    OR (t.status = 'voided' AND EXISTS (
+        SELECT 1 FROM transactions c
+        WHERE c.group_id = t.group_id AND c.id <> t.id
+          AND c.status = 'completed' AND c.type IN ('charge', 'capture')
+    ))
+  )
+GROUP BY 1, 2, 3
+ORDER BY 1, 2, 3;
@@ -33,8 +33,11 @@ WHERE agent_id = sqlc.arg(agent_id)
 UPDATE webhook_subscriptions
 SET
     webhook_url = COALESCE(sqlc.narg(webhook_url), webhook_url),
+    event_type = COALESCE(sqlc.narg(event_type), event_type),
     secret = COALESCE(sqlc.narg(secret), secret),
     is_active = COALESCE(sqlc.narg(is_active), is_active),
+    batch_size = COALESCE(sqlc.narg(batch_size), batch_size),
+    batch_flush_interval_seconds = COALESCE(sqlc.narg(batch_flush_interval_seconds), batch_flush_interval_seconds),
     updated_at = CURRENT_TIMESTAMP
 WHERE id = sqlc.arg(id)
 RETURNING *;
@@ -52,7 +55,10 @@ INSERT INTO webhook_deliveries (
     http_status_code,
     error_message,
     attempts,
-    next_retry_at
+    next_retry_at,
+    redelivery_of,
+    latency_ms,
+    failure_reason
 ) VALUES (
     sqlc.arg(subscription_id),
     sqlc.arg(event_type),
@@ -61,9 +67,16 @@ INSERT INTO webhook_deliveries (
     sqlc.narg(http_status_code),
     sqlc.narg(error_message),
     sqlc.arg(attempts),
-    sqlc.narg(next_retry_at)
+    sqlc.narg(next_retry_at),
+    sqlc.narg(redelivery_of),
+    sqlc.narg(latency_ms),
+    sqlc.narg(failure_reason)
 ) RETURNING *;
 
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = sqlc.arg(id);
+
 -- name: UpdateWebhookDeliveryStatus :one
 UPDATE webhook_deliveries
 SET
@@ -72,7 +85,9 @@ SET
     error_message = sqlc.narg(error_message),
     attempts = sqlc.arg(attempts),
     next_retry_at = sqlc.narg(next_retry_at),
-    delivered_at = sqlc.narg(delivered_at)
+    delivered_at = sqlc.narg(delivered_at),
+    latency_ms = sqlc.narg(latency_ms),
+    failure_reason = sqlc.narg(failure_reason)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
@@ -89,3 +104,76 @@ WHERE subscription_id = sqlc.arg(subscription_id)
 ORDER BY created_at DESC
 LIMIT sqlc.arg(limit_val)
 OFFSET sqlc.arg(offset_val);
+
+-- name: ListDeadLetterWebhookDeliveries :many
+SELECT d.* FROM webhook_deliveries d
+JOIN webhook_subscriptions s ON s.id = d.subscription_id
+WHERE d.status = 'dead_letter'
+  AND (sqlc.narg(agent_id)::varchar IS NULL OR s.agent_id = sqlc.narg(agent_id))
+  AND (sqlc.narg(subscription_id)::uuid IS NULL OR d.subscription_id = sqlc.narg(subscription_id))
+ORDER BY d.created_at DESC
+LIMIT sqlc.arg(limit_val)
+OFFSET sqlc.arg(offset_val);
+
+-- name: ListFailedWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg(subscription_id)
+  AND status IN ('failed', 'dead_letter')
+  AND created_at >= sqlc.arg(created_from)
+  AND created_at < sqlc.arg(created_to)
+ORDER BY created_at ASC
+LIMIT sqlc.arg(limit_val);
+
+-- name: GetWebhookDeliveryStats :many
+-- Deliveries created within [created_from, created_to) grouped by status and failure reason. Rows from before
+-- failure reasons were recorded fall back to their HTTP status.
+SELECT
+    status,
+    (CASE
+        WHEN status = 'success' THEN ''
+        ELSE COALESCE(failure_reason, 'http_' || http_status_code::text, '')
+    END)::varchar AS failure_reason,
+    COUNT(*) AS deliveries,
+    COUNT(latency_ms) AS timed_deliveries,
+    COALESCE(SUM(latency_ms), 0)::bigint AS total_latency_ms
+FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg(subscription_id)
+  AND created_at >= sqlc.arg(created_from)
+  AND created_at < sqlc.arg(created_to)
+GROUP BY 1, 2;
+
+-- name: EnqueueWebhookBatchEvent :exec
+INSERT INTO webhook_batch_events (
+    subscription_id,
+    event_type,
+    payload,
+    flush_at
+) VALUES (
+    sqlc.arg(subscription_id),
+    sqlc.arg(event_type),
+    sqlc.arg(payload),
+    sqlc.arg(flush_at)
+);
+
+-- name: CountWebhookBatchEvents :one
+SELECT COUNT(*) FROM webhook_batch_events
+WHERE subscription_id = sqlc.arg(subscription_id);
+
+-- name: ClaimWebhookBatchEvents :many
+-- Takes up to limit_val of the subscription's queued events off the queue for sending. Events another instance
+-- is taking are skipped, so each event is sent in one batch.
+DELETE FROM webhook_batch_events
+WHERE id IN (
+    SELECT id FROM webhook_batch_events
+    WHERE subscription_id = sqlc.arg(subscription_id)
+    ORDER BY id
+    LIMIT sqlc.arg(limit_val)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ListDueWebhookBatches :many
+-- Subscriptions whose oldest queued event is past its flush time
+SELECT subscription_id FROM webhook_batch_events
+GROUP BY subscription_id
+HAVING MIN(flush_at) <= sqlc.arg(now)::timestamptz;
@@ -12,17 +12,6 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const activateAgent = `-- name: ActivateAgent :exec
-UPDATE agent_credentials
-SET is_active = true, updated_at = CURRENT_TIMESTAMP
-WHERE agent_id = $1
-`
-
-func (q *Queries) ActivateAgent(ctx context.Context, agentID string) error {
-	_, err := q.db.Exec(ctx, activateAgent, agentID)
-	return err
-}
-
 const agentExists = `-- name: AgentExists :one
 SELECT EXISTS(SELECT 1 FROM agent_credentials WHERE agent_id = $1)
 `
@@ -34,20 +23,66 @@ func (q *Queries) AgentExists(ctx context.Context, agentID string) (bool, error)
 	return exists, err
 }
 
+const closeAgent = `-- name: CloseAgent :one
+UPDATE agent_credentials
+SET status = 'closed', is_active = false, status_reason = $1,
+    closed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = $2 AND status <> 'closed'
+RETURNING id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at, subscription_amount_change_min_days, tier, config_overrides, data_region, statement_descriptor, notification_email, status, status_reason, suspended_at, closed_at
+`
+
+type CloseAgentParams struct {
+	Reason  pgtype.Text `json:"reason"`
+	AgentID string      `json:"agent_id"`
+}
+
+func (q *Queries) CloseAgent(ctx context.Context, arg CloseAgentParams) (AgentCredential, error) {
+	row := q.db.QueryRow(ctx, closeAgent, arg.Reason, arg.AgentID)
+	var i AgentCredential
+	err := row.Scan(
+		&i.ID,
+		&i.AgentID,
+		&i.MacSecretPath,
+		&i.CustNbr,
+		&i.MerchNbr,
+		&i.DbaNbr,
+		&i.TerminalNbr,
+		&i.Environment,
+		&i.AgentName,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SubscriptionAmountChangeMinDays,
+		&i.Tier,
+		&i.ConfigOverrides,
+		&i.DataRegion,
+		&i.StatementDescriptor,
+		&i.NotificationEmail,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
 const countAgents = `-- name: CountAgents :one
 SELECT COUNT(*) FROM agent_credentials
 WHERE
     ($1::varchar IS NULL OR environment = $1) AND
-    ($2::boolean IS NULL OR is_active = $2)
+    ($2::boolean IS NULL OR is_active = $2) AND
+    ($3::text IS NULL OR agent_id ILIKE $3 OR agent_name ILIKE $3)
 `
 
 type CountAgentsParams struct {
 	Environment pgtype.Text `json:"environment"`
 	IsActive    pgtype.Bool `json:"is_active"`
+	Search      pgtype.Text `json:"search"`
 }
 
 func (q *Queries) CountAgents(ctx context.Context, arg CountAgentsParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countAgents, arg.Environment, arg.IsActive)
+	row := q.db.QueryRow(ctx, countAgents, arg.Environment, arg.IsActive, arg.Search)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -56,11 +91,11 @@ func (q *Queries) CountAgents(ctx context.Context, arg CountAgentsParams) (int64
 const createAgent = `-- name: CreateAgent :one
 INSERT INTO agent_credentials (
     id, agent_id, cust_nbr, merch_nbr, dba_nbr, terminal_nbr,
-    mac_secret_path, environment, is_active, agent_name
+    mac_secret_path, environment, is_active, agent_name, data_region
 ) VALUES (
     $1, $2, $3, $4, $5, $6,
-    $7, $8, $9, $10
-) RETURNING id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at
+    $7, $8, $9, $10, $11
+) RETURNING id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at, subscription_amount_change_min_days, tier, config_overrides, data_region, statement_descriptor, notification_email, status, status_reason, suspended_at, closed_at
 `
 
 type CreateAgentParams struct {
@@ -74,6 +109,7 @@ type CreateAgentParams struct {
 	Environment   string      `json:"environment"`
 	IsActive      pgtype.Bool `json:"is_active"`
 	AgentName     string      `json:"agent_name"`
+	DataRegion    string      `json:"data_region"`
 }
 
 func (q *Queries) CreateAgent(ctx context.Context, arg CreateAgentParams) (AgentCredential, error) {
@@ -88,6 +124,7 @@ func (q *Queries) CreateAgent(ctx context.Context, arg CreateAgentParams) (Agent
 		arg.Environment,
 		arg.IsActive,
 		arg.AgentName,
+		arg.DataRegion,
 	)
 	var i AgentCredential
 	err := row.Scan(
@@ -104,23 +141,22 @@ func (q *Queries) CreateAgent(ctx context.Context, arg CreateAgentParams) (Agent
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SubscriptionAmountChangeMinDays,
+		&i.Tier,
+		&i.ConfigOverrides,
+		&i.DataRegion,
+		&i.StatementDescriptor,
+		&i.NotificationEmail,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedAt,
+		&i.ClosedAt,
 	)
 	return i, err
 }
 
-const deactivateAgent = `-- name: DeactivateAgent :exec
-UPDATE agent_credentials
-SET is_active = false, updated_at = CURRENT_TIMESTAMP
-WHERE agent_id = $1
-`
-
-func (q *Queries) DeactivateAgent(ctx context.Context, agentID string) error {
-	_, err := q.db.Exec(ctx, deactivateAgent, agentID)
-	return err
-}
-
 const getAgentByAgentID = `-- name: GetAgentByAgentID :one
-SELECT id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at FROM agent_credentials
+SELECT id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at, subscription_amount_change_min_days, tier, config_overrides, data_region, statement_descriptor, notification_email, status, status_reason, suspended_at, closed_at FROM agent_credentials
 WHERE agent_id = $1
 `
 
@@ -141,12 +177,22 @@ func (q *Queries) GetAgentByAgentID(ctx context.Context, agentID string) (AgentC
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SubscriptionAmountChangeMinDays,
+		&i.Tier,
+		&i.ConfigOverrides,
+		&i.DataRegion,
+		&i.StatementDescriptor,
+		&i.NotificationEmail,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedAt,
+		&i.ClosedAt,
 	)
 	return i, err
 }
 
 const getAgentByID = `-- name: GetAgentByID :one
-SELECT id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at FROM agent_credentials
+SELECT id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at, subscription_amount_change_min_days, tier, config_overrides, data_region, statement_descriptor, notification_email, status, status_reason, suspended_at, closed_at FROM agent_credentials
 WHERE id = $1
 `
 
@@ -167,12 +213,22 @@ func (q *Queries) GetAgentByID(ctx context.Context, id uuid.UUID) (AgentCredenti
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SubscriptionAmountChangeMinDays,
+		&i.Tier,
+		&i.ConfigOverrides,
+		&i.DataRegion,
+		&i.StatementDescriptor,
+		&i.NotificationEmail,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedAt,
+		&i.ClosedAt,
 	)
 	return i, err
 }
 
 const listActiveAgents = `-- name: ListActiveAgents :many
-SELECT id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at FROM agent_credentials
+SELECT id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at, subscription_amount_change_min_days, tier, config_overrides, data_region, statement_descriptor, notification_email, status, status_reason, suspended_at, closed_at FROM agent_credentials
 WHERE is_active = true
 ORDER BY created_at DESC
 `
@@ -200,6 +256,16 @@ func (q *Queries) ListActiveAgents(ctx context.Context) ([]AgentCredential, erro
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SubscriptionAmountChangeMinDays,
+			&i.Tier,
+			&i.ConfigOverrides,
+			&i.DataRegion,
+			&i.StatementDescriptor,
+			&i.NotificationEmail,
+			&i.Status,
+			&i.StatusReason,
+			&i.SuspendedAt,
+			&i.ClosedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -212,25 +278,29 @@ func (q *Queries) ListActiveAgents(ctx context.Context) ([]AgentCredential, erro
 }
 
 const listAgents = `-- name: ListAgents :many
-SELECT id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at FROM agent_credentials
+SELECT id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at, subscription_amount_change_min_days, tier, config_overrides, data_region, statement_descriptor, notification_email, status, status_reason, suspended_at, closed_at FROM agent_credentials
 WHERE
     ($1::varchar IS NULL OR environment = $1) AND
-    ($2::boolean IS NULL OR is_active = $2)
+    ($2::boolean IS NULL OR is_active = $2) AND
+    ($3::text IS NULL OR agent_id ILIKE $3 OR agent_name ILIKE $3)
 ORDER BY created_at DESC
-LIMIT $4 OFFSET $3
+LIMIT $5 OFFSET $4
 `
 
 type ListAgentsParams struct {
 	Environment pgtype.Text `json:"environment"`
 	IsActive    pgtype.Bool `json:"is_active"`
+	Search      pgtype.Text `json:"search"`
 	OffsetVal   int32       `json:"offset_val"`
 	LimitVal    int32       `json:"limit_val"`
 }
 
+// search is an ILIKE pattern matched against the agent ID and name (NULL = no search)
 func (q *Queries) ListAgents(ctx context.Context, arg ListAgentsParams) ([]AgentCredential, error) {
 	rows, err := q.db.Query(ctx, listAgents,
 		arg.Environment,
 		arg.IsActive,
+		arg.Search,
 		arg.OffsetVal,
 		arg.LimitVal,
 	)
@@ -255,6 +325,16 @@ func (q *Queries) ListAgents(ctx context.Context, arg ListAgentsParams) ([]Agent
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SubscriptionAmountChangeMinDays,
+			&i.Tier,
+			&i.ConfigOverrides,
+			&i.DataRegion,
+			&i.StatementDescriptor,
+			&i.NotificationEmail,
+			&i.Status,
+			&i.StatusReason,
+			&i.SuspendedAt,
+			&i.ClosedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -266,6 +346,155 @@ func (q *Queries) ListAgents(ctx context.Context, arg ListAgentsParams) ([]Agent
 	return items, nil
 }
 
+const listAgentsByEPXCredentials = `-- name: ListAgentsByEPXCredentials :many
+SELECT id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at, subscription_amount_change_min_days, tier, config_overrides, data_region, statement_descriptor, notification_email, status, status_reason, suspended_at, closed_at FROM agent_credentials
+WHERE cust_nbr = $1
+  AND merch_nbr = $2
+  AND dba_nbr = $3
+  AND terminal_nbr = $4
+  AND deleted_at IS NULL
+`
+
+type ListAgentsByEPXCredentialsParams struct {
+	CustNbr     string `json:"cust_nbr"`
+	MerchNbr    string `json:"merch_nbr"`
+	DbaNbr      string `json:"dba_nbr"`
+	TerminalNbr string `json:"terminal_nbr"`
+}
+
+// Merchants registered with this EPX terminal (cust_nbr alone is not unique); callers require exactly one
+func (q *Queries) ListAgentsByEPXCredentials(ctx context.Context, arg ListAgentsByEPXCredentialsParams) ([]AgentCredential, error) {
+	rows, err := q.db.Query(ctx, listAgentsByEPXCredentials,
+		arg.CustNbr,
+		arg.MerchNbr,
+		arg.DbaNbr,
+		arg.TerminalNbr,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AgentCredential{}
+	for rows.Next() {
+		var i AgentCredential
+		if err := rows.Scan(
+			&i.ID,
+			&i.AgentID,
+			&i.MacSecretPath,
+			&i.CustNbr,
+			&i.MerchNbr,
+			&i.DbaNbr,
+			&i.TerminalNbr,
+			&i.Environment,
+			&i.AgentName,
+			&i.IsActive,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SubscriptionAmountChangeMinDays,
+			&i.Tier,
+			&i.ConfigOverrides,
+			&i.DataRegion,
+			&i.StatementDescriptor,
+			&i.NotificationEmail,
+			&i.Status,
+			&i.StatusReason,
+			&i.SuspendedAt,
+			&i.ClosedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reactivateAgent = `-- name: ReactivateAgent :one
+UPDATE agent_credentials
+SET status = 'active', is_active = true, status_reason = NULL,
+    suspended_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = $1 AND status = 'suspended'
+RETURNING id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at, subscription_amount_change_min_days, tier, config_overrides, data_region, statement_descriptor, notification_email, status, status_reason, suspended_at, closed_at
+`
+
+func (q *Queries) ReactivateAgent(ctx context.Context, agentID string) (AgentCredential, error) {
+	row := q.db.QueryRow(ctx, reactivateAgent, agentID)
+	var i AgentCredential
+	err := row.Scan(
+		&i.ID,
+		&i.AgentID,
+		&i.MacSecretPath,
+		&i.CustNbr,
+		&i.MerchNbr,
+		&i.DbaNbr,
+		&i.TerminalNbr,
+		&i.Environment,
+		&i.AgentName,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SubscriptionAmountChangeMinDays,
+		&i.Tier,
+		&i.ConfigOverrides,
+		&i.DataRegion,
+		&i.StatementDescriptor,
+		&i.NotificationEmail,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const suspendAgent = `-- name: SuspendAgent :one
+UPDATE agent_credentials
+SET status = 'suspended', is_active = false, status_reason = $1,
+    suspended_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = $2 AND status = 'active'
+RETURNING id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at, subscription_amount_change_min_days, tier, config_overrides, data_region, statement_descriptor, notification_email, status, status_reason, suspended_at, closed_at
+`
+
+type SuspendAgentParams struct {
+	Reason  pgtype.Text `json:"reason"`
+	AgentID string      `json:"agent_id"`
+}
+
+func (q *Queries) SuspendAgent(ctx context.Context, arg SuspendAgentParams) (AgentCredential, error) {
+	row := q.db.QueryRow(ctx, suspendAgent, arg.Reason, arg.AgentID)
+	var i AgentCredential
+	err := row.Scan(
+		&i.ID,
+		&i.AgentID,
+		&i.MacSecretPath,
+		&i.CustNbr,
+		&i.MerchNbr,
+		&i.DbaNbr,
+		&i.TerminalNbr,
+		&i.Environment,
+		&i.AgentName,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SubscriptionAmountChangeMinDays,
+		&i.Tier,
+		&i.ConfigOverrides,
+		&i.DataRegion,
+		&i.StatementDescriptor,
+		&i.NotificationEmail,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
 const updateAgent = `-- name: UpdateAgent :one
 UPDATE agent_credentials
 SET
@@ -275,19 +504,23 @@ SET
     terminal_nbr = $4,
     environment = $5,
     agent_name = $6,
+    subscription_amount_change_min_days = $7,
+    data_region = $8,
     updated_at = CURRENT_TIMESTAMP
-WHERE agent_id = $7
-RETURNING id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at
+WHERE agent_id = $9
+RETURNING id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at, subscription_amount_change_min_days, tier, config_overrides, data_region, statement_descriptor, notification_email, status, status_reason, suspended_at, closed_at
 `
 
 type UpdateAgentParams struct {
-	CustNbr     string `json:"cust_nbr"`
-	MerchNbr    string `json:"merch_nbr"`
-	DbaNbr      string `json:"dba_nbr"`
-	TerminalNbr string `json:"terminal_nbr"`
-	Environment string `json:"environment"`
-	AgentName   string `json:"agent_name"`
-	AgentID     string `json:"agent_id"`
+	CustNbr                         string      `json:"cust_nbr"`
+	MerchNbr                        string      `json:"merch_nbr"`
+	DbaNbr                          string      `json:"dba_nbr"`
+	TerminalNbr                     string      `json:"terminal_nbr"`
+	Environment                     string      `json:"environment"`
+	AgentName                       string      `json:"agent_name"`
+	SubscriptionAmountChangeMinDays pgtype.Int4 `json:"subscription_amount_change_min_days"`
+	DataRegion                      string      `json:"data_region"`
+	AgentID                         string      `json:"agent_id"`
 }
 
 func (q *Queries) UpdateAgent(ctx context.Context, arg UpdateAgentParams) (AgentCredential, error) {
@@ -298,6 +531,8 @@ func (q *Queries) UpdateAgent(ctx context.Context, arg UpdateAgentParams) (Agent
 		arg.TerminalNbr,
 		arg.Environment,
 		arg.AgentName,
+		arg.SubscriptionAmountChangeMinDays,
+		arg.DataRegion,
 		arg.AgentID,
 	)
 	var i AgentCredential
@@ -315,6 +550,16 @@ func (q *Queries) UpdateAgent(ctx context.Context, arg UpdateAgentParams) (Agent
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SubscriptionAmountChangeMinDays,
+		&i.Tier,
+		&i.ConfigOverrides,
+		&i.DataRegion,
+		&i.StatementDescriptor,
+		&i.NotificationEmail,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedAt,
+		&i.ClosedAt,
 	)
 	return i, err
 }
@@ -334,3 +579,51 @@ func (q *Queries) UpdateAgentMACPath(ctx context.Context, arg UpdateAgentMACPath
 	_, err := q.db.Exec(ctx, updateAgentMACPath, arg.MacSecretPath, arg.AgentID)
 	return err
 }
+
+const updateAgentSettings = `-- name: UpdateAgentSettings :one
+UPDATE agent_credentials
+SET
+    statement_descriptor = NULLIF(COALESCE($1, statement_descriptor), ''),
+    notification_email = NULLIF(COALESCE($2, notification_email), ''),
+    updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = $3 AND deleted_at IS NULL
+RETURNING id, agent_id, mac_secret_path, cust_nbr, merch_nbr, dba_nbr, terminal_nbr, environment, agent_name, is_active, deleted_at, created_at, updated_at, subscription_amount_change_min_days, tier, config_overrides, data_region, statement_descriptor, notification_email, status, status_reason, suspended_at, closed_at
+`
+
+type UpdateAgentSettingsParams struct {
+	StatementDescriptor pgtype.Text `json:"statement_descriptor"`
+	NotificationEmail   pgtype.Text `json:"notification_email"`
+	AgentID             string      `json:"agent_id"`
+}
+
+// Self-service settings only; a NULL argument keeps the current value and an empty one clears it
+func (q *Queries) UpdateAgentSettings(ctx context.Context, arg UpdateAgentSettingsParams) (AgentCredential, error) {
+	row := q.db.QueryRow(ctx, updateAgentSettings, arg.StatementDescriptor, arg.NotificationEmail, arg.AgentID)
+	var i AgentCredential
+	err := row.Scan(
+		&i.ID,
+		&i.AgentID,
+		&i.MacSecretPath,
+		&i.CustNbr,
+		&i.MerchNbr,
+		&i.DbaNbr,
+		&i.TerminalNbr,
+		&i.Environment,
+		&i.AgentName,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SubscriptionAmountChangeMinDays,
+		&i.Tier,
+		&i.ConfigOverrides,
+		&i.DataRegion,
+		&i.StatementDescriptor,
+		&i.NotificationEmail,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
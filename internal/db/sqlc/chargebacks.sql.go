@@ -38,16 +38,22 @@ WHERE
     ($3::uuid IS NULL OR group_id = $3) AND
     ($4::varchar IS NULL OR status = $4) AND
     ($5::date IS NULL OR dispute_date >= $5) AND
-    ($6::date IS NULL OR dispute_date <= $6)
+    ($6::date IS NULL OR dispute_date <= $6) AND
+    ($7::varchar IS NULL OR match_status = $7) AND
+    ($8::numeric IS NULL OR chargeback_amount::numeric >= $8::numeric) AND
+    ($9::numeric IS NULL OR chargeback_amount::numeric <= $9::numeric)
 `
 
 type CountChargebacksParams struct {
-	AgentID         pgtype.Text `json:"agent_id"`
-	CustomerID      pgtype.Text `json:"customer_id"`
-	GroupID         pgtype.UUID `json:"group_id"`
-	Status          pgtype.Text `json:"status"`
-	DisputeDateFrom pgtype.Date `json:"dispute_date_from"`
-	DisputeDateTo   pgtype.Date `json:"dispute_date_to"`
+	AgentID         pgtype.Text    `json:"agent_id"`
+	CustomerID      pgtype.Text    `json:"customer_id"`
+	GroupID         pgtype.UUID    `json:"group_id"`
+	Status          pgtype.Text    `json:"status"`
+	DisputeDateFrom pgtype.Date    `json:"dispute_date_from"`
+	DisputeDateTo   pgtype.Date    `json:"dispute_date_to"`
+	MatchStatus     pgtype.Text    `json:"match_status"`
+	MinAmount       pgtype.Numeric `json:"min_amount"`
+	MaxAmount       pgtype.Numeric `json:"max_amount"`
 }
 
 func (q *Queries) CountChargebacks(ctx context.Context, arg CountChargebacksParams) (int64, error) {
@@ -58,6 +64,9 @@ func (q *Queries) CountChargebacks(ctx context.Context, arg CountChargebacksPara
 		arg.Status,
 		arg.DisputeDateFrom,
 		arg.DisputeDateTo,
+		arg.MatchStatus,
+		arg.MinAmount,
+		arg.MaxAmount,
 	)
 	var count int64
 	err := row.Scan(&count)
@@ -79,7 +88,7 @@ INSERT INTO chargebacks (
     $12, $13,
     $14, $15, $16,
     $17
-) RETURNING id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at
+) RETURNING id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at, deadline_reminder_sent_at, match_status, evidence_ready_at
 `
 
 type CreateChargebackParams struct {
@@ -146,12 +155,15 @@ func (q *Queries) CreateChargeback(ctx context.Context, arg CreateChargebackPara
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeadlineReminderSentAt,
+		&i.MatchStatus,
+		&i.EvidenceReadyAt,
 	)
 	return i, err
 }
 
 const getChargebackByCaseNumber = `-- name: GetChargebackByCaseNumber :one
-SELECT id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at FROM chargebacks
+SELECT id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at, deadline_reminder_sent_at, match_status, evidence_ready_at FROM chargebacks
 WHERE agent_id = $1 AND case_number = $2
 `
 
@@ -186,12 +198,15 @@ func (q *Queries) GetChargebackByCaseNumber(ctx context.Context, arg GetChargeba
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeadlineReminderSentAt,
+		&i.MatchStatus,
+		&i.EvidenceReadyAt,
 	)
 	return i, err
 }
 
 const getChargebackByGroupID = `-- name: GetChargebackByGroupID :one
-SELECT id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at FROM chargebacks
+SELECT id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at, deadline_reminder_sent_at, match_status, evidence_ready_at FROM chargebacks
 WHERE group_id = $1
 `
 
@@ -221,12 +236,15 @@ func (q *Queries) GetChargebackByGroupID(ctx context.Context, groupID pgtype.UUI
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeadlineReminderSentAt,
+		&i.MatchStatus,
+		&i.EvidenceReadyAt,
 	)
 	return i, err
 }
 
 const getChargebackByID = `-- name: GetChargebackByID :one
-SELECT id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at FROM chargebacks
+SELECT id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at, deadline_reminder_sent_at, match_status, evidence_ready_at FROM chargebacks
 WHERE id = $1
 `
 
@@ -256,32 +274,41 @@ func (q *Queries) GetChargebackByID(ctx context.Context, id uuid.UUID) (Chargeba
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeadlineReminderSentAt,
+		&i.MatchStatus,
+		&i.EvidenceReadyAt,
 	)
 	return i, err
 }
 
 const listChargebacks = `-- name: ListChargebacks :many
-SELECT id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at FROM chargebacks
+SELECT id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at, deadline_reminder_sent_at, match_status, evidence_ready_at FROM chargebacks
 WHERE
     ($1::varchar IS NULL OR agent_id = $1) AND
     ($2::varchar IS NULL OR customer_id = $2) AND
     ($3::uuid IS NULL OR group_id = $3) AND
     ($4::varchar IS NULL OR status = $4) AND
     ($5::date IS NULL OR dispute_date >= $5) AND
-    ($6::date IS NULL OR dispute_date <= $6)
+    ($6::date IS NULL OR dispute_date <= $6) AND
+    ($7::varchar IS NULL OR match_status = $7) AND
+    ($8::numeric IS NULL OR chargeback_amount::numeric >= $8::numeric) AND
+    ($9::numeric IS NULL OR chargeback_amount::numeric <= $9::numeric)
 ORDER BY dispute_date DESC
-LIMIT $8 OFFSET $7
+LIMIT $11 OFFSET $10
 `
 
 type ListChargebacksParams struct {
-	AgentID         pgtype.Text `json:"agent_id"`
-	CustomerID      pgtype.Text `json:"customer_id"`
-	GroupID         pgtype.UUID `json:"group_id"`
-	Status          pgtype.Text `json:"status"`
-	DisputeDateFrom pgtype.Date `json:"dispute_date_from"`
-	DisputeDateTo   pgtype.Date `json:"dispute_date_to"`
-	OffsetVal       int32       `json:"offset_val"`
-	LimitVal        int32       `json:"limit_val"`
+	AgentID         pgtype.Text    `json:"agent_id"`
+	CustomerID      pgtype.Text    `json:"customer_id"`
+	GroupID         pgtype.UUID    `json:"group_id"`
+	Status          pgtype.Text    `json:"status"`
+	DisputeDateFrom pgtype.Date    `json:"dispute_date_from"`
+	DisputeDateTo   pgtype.Date    `json:"dispute_date_to"`
+	MatchStatus     pgtype.Text    `json:"match_status"`
+	MinAmount       pgtype.Numeric `json:"min_amount"`
+	MaxAmount       pgtype.Numeric `json:"max_amount"`
+	OffsetVal       int32          `json:"offset_val"`
+	LimitVal        int32          `json:"limit_val"`
 }
 
 func (q *Queries) ListChargebacks(ctx context.Context, arg ListChargebacksParams) ([]Chargeback, error) {
@@ -292,6 +319,9 @@ func (q *Queries) ListChargebacks(ctx context.Context, arg ListChargebacksParams
 		arg.Status,
 		arg.DisputeDateFrom,
 		arg.DisputeDateTo,
+		arg.MatchStatus,
+		arg.MinAmount,
+		arg.MaxAmount,
 		arg.OffsetVal,
 		arg.LimitVal,
 	)
@@ -325,6 +355,9 @@ func (q *Queries) ListChargebacks(ctx context.Context, arg ListChargebacksParams
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeadlineReminderSentAt,
+			&i.MatchStatus,
+			&i.EvidenceReadyAt,
 		); err != nil {
 			return nil, err
 		}
@@ -336,6 +369,162 @@ func (q *Queries) ListChargebacks(ctx context.Context, arg ListChargebacksParams
 	return items, nil
 }
 
+const listChargebacksDueWithin = `-- name: ListChargebacksDueWithin :many
+SELECT id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at, deadline_reminder_sent_at, match_status, evidence_ready_at FROM chargebacks
+WHERE
+    deleted_at IS NULL AND
+    status IN ('new', 'pending') AND
+    response_submitted_at IS NULL AND
+    respond_by_date IS NOT NULL AND
+    ($1::varchar IS NULL OR agent_id = $1) AND
+    (NOT $2::boolean OR deadline_reminder_sent_at IS NULL) AND
+    respond_by_date >= $3::date AND
+    respond_by_date <= $4::date
+ORDER BY respond_by_date, agent_id, case_number
+`
+
+type ListChargebacksDueWithinParams struct {
+	AgentID        pgtype.Text `json:"agent_id"`
+	UnnotifiedOnly bool        `json:"unnotified_only"`
+	AsOf           pgtype.Date `json:"as_of"`
+	Cutoff         pgtype.Date `json:"cutoff"`
+}
+
+// Open chargebacks without a response whose respond_by_date falls between as_of and cutoff (inclusive)
+func (q *Queries) ListChargebacksDueWithin(ctx context.Context, arg ListChargebacksDueWithinParams) ([]Chargeback, error) {
+	rows, err := q.db.Query(ctx, listChargebacksDueWithin,
+		arg.AgentID,
+		arg.UnnotifiedOnly,
+		arg.AsOf,
+		arg.Cutoff,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Chargeback{}
+	for rows.Next() {
+		var i Chargeback
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.CaseNumber,
+			&i.DisputeDate,
+			&i.ChargebackDate,
+			&i.ChargebackAmount,
+			&i.Currency,
+			&i.ReasonCode,
+			&i.ReasonDescription,
+			&i.Status,
+			&i.RespondByDate,
+			&i.ResponseSubmittedAt,
+			&i.ResolvedAt,
+			&i.EvidenceFiles,
+			&i.ResponseNotes,
+			&i.InternalNotes,
+			&i.RawData,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeadlineReminderSentAt,
+			&i.MatchStatus,
+			&i.EvidenceReadyAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDisputeMatchCandidates = `-- name: ListDisputeMatchCandidates :many
+SELECT
+    t.id, t.group_id, t.customer_id, t.type, t.auth_code, t.tran_nbr, t.created_at,
+    pm.last_four
+FROM transactions t
+LEFT JOIN customer_payment_methods pm ON pm.id = t.payment_method_id
+WHERE
+    t.agent_id = $1 AND
+    t.deleted_at IS NULL AND
+    t.payment_method_type = 'credit_card' AND
+    t.type IN ('auth', 'charge', 'capture') AND
+    t.status <> 'failed' AND
+    t.amount = ($2::text)::numeric AND
+    t.created_at >= $3 AND
+    t.created_at < $4
+ORDER BY t.created_at
+`
+
+type ListDisputeMatchCandidatesParams struct {
+	AgentID     string    `json:"agent_id"`
+	Amount      string    `json:"amount"`
+	CreatedFrom time.Time `json:"created_from"`
+	CreatedTo   time.Time `json:"created_to"`
+}
+
+type ListDisputeMatchCandidatesRow struct {
+	ID         uuid.UUID   `json:"id"`
+	GroupID    uuid.UUID   `json:"group_id"`
+	CustomerID pgtype.Text `json:"customer_id"`
+	Type       string      `json:"type"`
+	AuthCode   pgtype.Text `json:"auth_code"`
+	TranNbr    pgtype.Int8 `json:"tran_nbr"`
+	CreatedAt  time.Time   `json:"created_at"`
+	LastFour   pgtype.Text `json:"last_four"`
+}
+
+// A merchant's card authorizations and charges for the disputed amount in a date window,
+// with the saved card's last four when the payment used one
+func (q *Queries) ListDisputeMatchCandidates(ctx context.Context, arg ListDisputeMatchCandidatesParams) ([]ListDisputeMatchCandidatesRow, error) {
+	rows, err := q.db.Query(ctx, listDisputeMatchCandidates,
+		arg.AgentID,
+		arg.Amount,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDisputeMatchCandidatesRow{}
+	for rows.Next() {
+		var i ListDisputeMatchCandidatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.CustomerID,
+			&i.Type,
+			&i.AuthCode,
+			&i.TranNbr,
+			&i.CreatedAt,
+			&i.LastFour,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markChargebackDeadlineReminded = `-- name: MarkChargebackDeadlineReminded :exec
+UPDATE chargebacks
+SET deadline_reminder_sent_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) MarkChargebackDeadlineReminded(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markChargebackDeadlineReminded, id)
+	return err
+}
+
 const markChargebackResolved = `-- name: MarkChargebackResolved :exec
 UPDATE chargebacks
 SET
@@ -356,6 +545,143 @@ func (q *Queries) MarkChargebackResolved(ctx context.Context, arg MarkChargeback
 	return err
 }
 
+const markOverdueChargebacksLost = `-- name: MarkOverdueChargebacksLost :many
+UPDATE chargebacks
+SET
+    status = 'lost',
+    resolved_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    deleted_at IS NULL AND
+    status IN ('new', 'pending') AND
+    response_submitted_at IS NULL AND
+    respond_by_date < $1::date AND
+    ($2::varchar IS NULL OR agent_id = $2)
+RETURNING id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at, deadline_reminder_sent_at, match_status, evidence_ready_at
+`
+
+type MarkOverdueChargebacksLostParams struct {
+	AsOf    pgtype.Date `json:"as_of"`
+	AgentID pgtype.Text `json:"agent_id"`
+}
+
+// Open chargebacks without a response whose respond_by_date is before as_of are lost
+func (q *Queries) MarkOverdueChargebacksLost(ctx context.Context, arg MarkOverdueChargebacksLostParams) ([]Chargeback, error) {
+	rows, err := q.db.Query(ctx, markOverdueChargebacksLost, arg.AsOf, arg.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Chargeback{}
+	for rows.Next() {
+		var i Chargeback
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.CaseNumber,
+			&i.DisputeDate,
+			&i.ChargebackDate,
+			&i.ChargebackAmount,
+			&i.Currency,
+			&i.ReasonCode,
+			&i.ReasonDescription,
+			&i.Status,
+			&i.RespondByDate,
+			&i.ResponseSubmittedAt,
+			&i.ResolvedAt,
+			&i.EvidenceFiles,
+			&i.ResponseNotes,
+			&i.InternalNotes,
+			&i.RawData,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeadlineReminderSentAt,
+			&i.MatchStatus,
+			&i.EvidenceReadyAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const storeChargebackEvidence = `-- name: StoreChargebackEvidence :one
+UPDATE chargebacks
+SET
+    evidence_files = COALESCE(evidence_files, '{}') || ARRAY(
+        SELECT u.file_url
+        FROM unnest($1::text[]) WITH ORDINALITY AS u(file_url, n)
+        WHERE NOT (u.file_url = ANY(COALESCE(evidence_files, '{}')))
+        ORDER BY u.n
+    ),
+    response_notes = $2,
+    evidence_ready_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = $3 AND
+    agent_id = $4 AND
+    deleted_at IS NULL AND
+    status IN ('new', 'pending') AND
+    (respond_by_date IS NULL OR respond_by_date >= $5::date)
+RETURNING id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at, deadline_reminder_sent_at, match_status, evidence_ready_at
+`
+
+type StoreChargebackEvidenceParams struct {
+	FileUrls      []string    `json:"file_urls"`
+	ResponseNotes pgtype.Text `json:"response_notes"`
+	ID            uuid.UUID   `json:"id"`
+	AgentID       string      `json:"agent_id"`
+	AsOf          pgtype.Date `json:"as_of"`
+}
+
+// Appends the file references not already on the merchant's open chargeback, if its deadline hasn't passed,, stores the narrative and
+// marks the evidence ready for submission through North's portal; no row is returned if the chargeback doesn't accept evidence
+func (q *Queries) StoreChargebackEvidence(ctx context.Context, arg StoreChargebackEvidenceParams) (Chargeback, error) {
+	row := q.db.QueryRow(ctx, storeChargebackEvidence,
+		arg.FileUrls,
+		arg.ResponseNotes,
+		arg.ID,
+		arg.AgentID,
+		arg.AsOf,
+	)
+	var i Chargeback
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.CaseNumber,
+		&i.DisputeDate,
+		&i.ChargebackDate,
+		&i.ChargebackAmount,
+		&i.Currency,
+		&i.ReasonCode,
+		&i.ReasonDescription,
+		&i.Status,
+		&i.RespondByDate,
+		&i.ResponseSubmittedAt,
+		&i.ResolvedAt,
+		&i.EvidenceFiles,
+		&i.ResponseNotes,
+		&i.InternalNotes,
+		&i.RawData,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeadlineReminderSentAt,
+		&i.MatchStatus,
+		&i.EvidenceReadyAt,
+	)
+	return i, err
+}
+
 const updateChargeback = `-- name: UpdateChargeback :one
 UPDATE chargebacks
 SET
@@ -364,7 +690,7 @@ SET
     resolved_at = $3,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $4
-RETURNING id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at
+RETURNING id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at, deadline_reminder_sent_at, match_status, evidence_ready_at
 `
 
 type UpdateChargebackParams struct {
@@ -405,6 +731,9 @@ func (q *Queries) UpdateChargeback(ctx context.Context, arg UpdateChargebackPara
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeadlineReminderSentAt,
+		&i.MatchStatus,
+		&i.EvidenceReadyAt,
 	)
 	return i, err
 }
@@ -456,7 +785,7 @@ SET
     reason_description = $6,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $7
-RETURNING id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at
+RETURNING id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at, deadline_reminder_sent_at, match_status, evidence_ready_at
 `
 
 type UpdateChargebackStatusParams struct {
@@ -503,6 +832,122 @@ func (q *Queries) UpdateChargebackStatus(ctx context.Context, arg UpdateChargeba
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeadlineReminderSentAt,
+		&i.MatchStatus,
+		&i.EvidenceReadyAt,
+	)
+	return i, err
+}
+
+const upsertChargeback = `-- name: UpsertChargeback :one
+INSERT INTO chargebacks (
+    id, group_id, agent_id, customer_id,
+    case_number, dispute_date, chargeback_date,
+    chargeback_amount, currency, reason_code, reason_description,
+    status, respond_by_date,
+    evidence_files, response_notes, internal_notes,
+    raw_data, match_status
+) VALUES (
+    $1, $2, $3, $4,
+    $5, $6, $7,
+    $8, $9, $10, $11,
+    $12, $13,
+    $14, $15, $16,
+    $17, $18
+)
+ON CONFLICT (agent_id, case_number) DO UPDATE SET
+    status = EXCLUDED.status,
+    dispute_date = EXCLUDED.dispute_date,
+    chargeback_date = EXCLUDED.chargeback_date,
+    chargeback_amount = EXCLUDED.chargeback_amount,
+    reason_code = EXCLUDED.reason_code,
+    reason_description = EXCLUDED.reason_description,
+    respond_by_date = COALESCE(EXCLUDED.respond_by_date, chargebacks.respond_by_date),
+    deadline_reminder_sent_at = CASE
+        WHEN EXCLUDED.respond_by_date IS DISTINCT FROM chargebacks.respond_by_date AND EXCLUDED.respond_by_date IS NOT NULL THEN NULL
+        ELSE chargebacks.deadline_reminder_sent_at
+    END,
+    raw_data = EXCLUDED.raw_data,
+    group_id = COALESCE(chargebacks.group_id, EXCLUDED.group_id),
+    customer_id = COALESCE(chargebacks.customer_id, EXCLUDED.customer_id),
+    match_status = CASE WHEN chargebacks.group_id IS NOT NULL THEN chargebacks.match_status ELSE EXCLUDED.match_status END,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING id, group_id, agent_id, customer_id, case_number, dispute_date, chargeback_date, chargeback_amount, currency, reason_code, reason_description, status, respond_by_date, response_submitted_at, resolved_at, evidence_files, response_notes, internal_notes, raw_data, deleted_at, created_at, updated_at, deadline_reminder_sent_at, match_status, evidence_ready_at
+`
+
+type UpsertChargebackParams struct {
+	ID                uuid.UUID       `json:"id"`
+	GroupID           pgtype.UUID     `json:"group_id"`
+	AgentID           string          `json:"agent_id"`
+	CustomerID        pgtype.Text     `json:"customer_id"`
+	CaseNumber        string          `json:"case_number"`
+	DisputeDate       time.Time       `json:"dispute_date"`
+	ChargebackDate    time.Time       `json:"chargeback_date"`
+	ChargebackAmount  string          `json:"chargeback_amount"`
+	Currency          string          `json:"currency"`
+	ReasonCode        string          `json:"reason_code"`
+	ReasonDescription pgtype.Text     `json:"reason_description"`
+	Status            string          `json:"status"`
+	RespondByDate     pgtype.Date     `json:"respond_by_date"`
+	EvidenceFiles     []string        `json:"evidence_files"`
+	ResponseNotes     pgtype.Text     `json:"response_notes"`
+	InternalNotes     pgtype.Text     `json:"internal_notes"`
+	RawData           json.RawMessage `json:"raw_data"`
+	MatchStatus       pgtype.Text     `json:"match_status"`
+}
+
+// Idempotent dispute sync: a re-synced case updates North-sourced fields and keeps our
+// response data (evidence, notes, group link) intact. created_at = updated_at on insert.
+// A new respond_by_date re-arms the deadline reminder; a missing one keeps the stored date.
+// An existing group link (from an earlier match or set by hand) is never replaced.
+func (q *Queries) UpsertChargeback(ctx context.Context, arg UpsertChargebackParams) (Chargeback, error) {
+	row := q.db.QueryRow(ctx, upsertChargeback,
+		arg.ID,
+		arg.GroupID,
+		arg.AgentID,
+		arg.CustomerID,
+		arg.CaseNumber,
+		arg.DisputeDate,
+		arg.ChargebackDate,
+		arg.ChargebackAmount,
+		arg.Currency,
+		arg.ReasonCode,
+		arg.ReasonDescription,
+		arg.Status,
+		arg.RespondByDate,
+		arg.EvidenceFiles,
+		arg.ResponseNotes,
+		arg.InternalNotes,
+		arg.RawData,
+		arg.MatchStatus,
+	)
+	var i Chargeback
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.CaseNumber,
+		&i.DisputeDate,
+		&i.ChargebackDate,
+		&i.ChargebackAmount,
+		&i.Currency,
+		&i.ReasonCode,
+		&i.ReasonDescription,
+		&i.Status,
+		&i.RespondByDate,
+		&i.ResponseSubmittedAt,
+		&i.ResolvedAt,
+		&i.EvidenceFiles,
+		&i.ResponseNotes,
+		&i.InternalNotes,
+		&i.RawData,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeadlineReminderSentAt,
+		&i.MatchStatus,
+		&i.EvidenceReadyAt,
 	)
 	return i, err
 }
@@ -27,6 +27,26 @@ type AgentCredential struct {
 	DeletedAt     pgtype.Timestamptz `json:"deleted_at"`
 	CreatedAt     time.Time          `json:"created_at"`
 	UpdatedAt     time.Time          `json:"updated_at"`
+	// Minimum days between subscription amount changes (NULL = unlimited)
+	SubscriptionAmountChangeMinDays pgtype.Int4 `json:"subscription_amount_change_min_days"`
+	// Tier that supplies default limits, currencies and capabilities
+	Tier string `json:"tier"`
+	// Per-agent overrides of tier defaults (never contains secrets)
+	ConfigOverrides json.RawMessage `json:"config_overrides"`
+	// Data residency region of the merchant (e.g. us, eu, ca); stamped on rows written for it
+	DataRegion string `json:"data_region"`
+	// Descriptor shown on cardholder statements (5-22 characters); NULL = EPX default
+	StatementDescriptor pgtype.Text `json:"statement_descriptor"`
+	// Where the merchant receives operational notices
+	NotificationEmail pgtype.Text `json:"notification_email"`
+	// active, suspended (no new charges; refunds and voids allowed) or closed (nothing allowed; terminal)
+	Status string `json:"status"`
+	// Why the merchant was last suspended or closed
+	StatusReason pgtype.Text `json:"status_reason"`
+	// When the current suspension started; NULL unless suspended
+	SuspendedAt pgtype.Timestamptz `json:"suspended_at"`
+	// When the merchant was closed
+	ClosedAt pgtype.Timestamptz `json:"closed_at"`
 }
 
 type AuditLog struct {
@@ -45,6 +65,24 @@ type AuditLog struct {
 	CreatedAt   time.Time   `json:"created_at"`
 }
 
+// Amount and customer of each save_and_charge Browser Post form, charged by its callback
+type BrowserPostChargeIntent struct {
+	ID         uuid.UUID      `json:"id"`
+	AgentID    string         `json:"agent_id"`
+	TranNbr    int64          `json:"tran_nbr"`
+	CustomerID string         `json:"customer_id"`
+	Amount     pgtype.Numeric `json:"amount"`
+	Currency   string         `json:"currency"`
+	// pending until a callback claims it; processing while the card is saved and charged; then completed or failed
+	Status          string      `json:"status"`
+	PaymentMethodID pgtype.UUID `json:"payment_method_id"`
+	TransactionID   pgtype.UUID `json:"transaction_id"`
+	// When the form stops accepting its callback (TAC validity plus callback grace)
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type Chargeback struct {
 	ID                  uuid.UUID          `json:"id"`
 	GroupID             pgtype.UUID        `json:"group_id"`
@@ -68,6 +106,58 @@ type Chargeback struct {
 	DeletedAt           pgtype.Timestamptz `json:"deleted_at"`
 	CreatedAt           time.Time          `json:"created_at"`
 	UpdatedAt           time.Time          `json:"updated_at"`
+	// When chargeback.deadline_approaching was sent; cleared when respond_by_date changes
+	DeadlineReminderSentAt pgtype.Timestamptz `json:"deadline_reminder_sent_at"`
+	// matched: group_id linked by dispute sync or by hand; unmatched: no confident match, needs manual review; NULL: synced before matching existed
+	MatchStatus pgtype.Text `json:"match_status"`
+	// When evidence was last stored for the merchant to submit through North's portal (NULL = none stored)
+	EvidenceReadyAt pgtype.Timestamptz `json:"evidence_ready_at"`
+}
+
+// Merchant promotions applied to subscription charges
+type Coupon struct {
+	ID               uuid.UUID          `json:"id"`
+	AgentID          string             `json:"agent_id"`
+	Code             string             `json:"code"`
+	DiscountType     string             `json:"discount_type"`
+	PercentOff       pgtype.Numeric     `json:"percent_off"`
+	AmountOff        pgtype.Numeric     `json:"amount_off"`
+	Currency         string             `json:"currency"`
+	Duration         string             `json:"duration"`
+	DurationInMonths pgtype.Int4        `json:"duration_in_months"`
+	ExpiresAt        pgtype.Timestamptz `json:"expires_at"`
+	IsActive         bool               `json:"is_active"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+// One row per cron run with its outcome, newest runs served by GET /cron/stats
+type CronRun struct {
+	ID         uuid.UUID `json:"id"`
+	Job        string    `json:"job"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Processed  int32     `json:"processed"`
+	Succeeded  int32     `json:"succeeded"`
+	Failed     int32     `json:"failed"`
+	// Subscriptions attempted again after an earlier failed charge
+	Retried int32 `json:"retried"`
+	// Sum of successful charges in the run
+	AmountCharged pgtype.Numeric  `json:"amount_charged"`
+	Errors        json.RawMessage `json:"errors"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type Customer struct {
+	ID         uuid.UUID   `json:"id"`
+	AgentID    string      `json:"agent_id"`
+	CustomerID string      `json:"customer_id"`
+	Email      pgtype.Text `json:"email"`
+	// Created from the first payment that referenced it (customer_policy auto_create), holding only the customer_id and email given
+	AutoCreated bool      `json:"auto_created"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type CustomerPaymentMethod struct {
@@ -89,6 +179,110 @@ type CustomerPaymentMethod struct {
 	CreatedAt    time.Time          `json:"created_at"`
 	UpdatedAt    time.Time          `json:"updated_at"`
 	LastUsedAt   pgtype.Timestamptz `json:"last_used_at"`
+	// Number of ACH returns received for this payment method
+	ReturnCount int32 `json:"return_count"`
+	// Most recent NACHA return code (e.g. R01)
+	LastReturnCode pgtype.Text `json:"last_return_code"`
+	// Why the payment method was automatically deactivated (NULL = not auto-deactivated)
+	DeactivationReason pgtype.Text `json:"deactivation_reason"`
+	// Salted SHA-256 of the expected micro-deposit amounts (NULL = none pending)
+	MicroDepositHash pgtype.Text `json:"micro_deposit_hash"`
+	// When the micro-deposits were sent
+	MicroDepositSentAt pgtype.Timestamptz `json:"micro_deposit_sent_at"`
+	// Failed micro-deposit verification attempts (locked at 3)
+	MicroDepositAttempts int32 `json:"micro_deposit_attempts"`
+	// When payment_method.expiring was sent for the current expiry (reset when the expiry is updated)
+	ExpiryNotifiedAt pgtype.Timestamptz `json:"expiry_notified_at"`
+	// Merchant data region when the row was written (not changed if the merchant later moves)
+	DataRegion string `json:"data_region"`
+	// Idempotency key of the request that saved the payment method (NULL = none sent)
+	IdempotencyKey pgtype.Text `json:"idempotency_key"`
+}
+
+// TRAN_NBR allocated to each transaction ID, so retries send EPX the same number
+type EpxTranNbr struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	AgentID       string    `json:"agent_id"`
+	TranNbr       int64     `json:"tran_nbr"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Last TRAN_NBR handed out per merchant; allocation fails once a merchant reaches 9999999999
+type EpxTranNbrCounter struct {
+	AgentID     string `json:"agent_id"`
+	LastTranNbr int64  `json:"last_tran_nbr"`
+}
+
+// Operator switches such as charges_enabled, set with the admin CLI; a missing flag is on
+type FeatureFlag struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// Merchant the flag applies to; NULL for the global flag
+	AgentID pgtype.Text `json:"agent_id"`
+	Enabled bool        `json:"enabled"`
+	// Why the flag was last changed, e.g. an incident reference
+	Reason pgtype.Text `json:"reason"`
+	// Operator who last changed the flag
+	UpdatedBy pgtype.Text `json:"updated_by"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Sale/authorization volume reserved per merchant per UTC day (reserved before the gateway call, released on decline)
+type MerchantDailyVolume struct {
+	AgentID    string         `json:"agent_id"`
+	VolumeDate pgtype.Date    `json:"volume_date"`
+	Amount     pgtype.Numeric `json:"amount"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+type SaleBatch struct {
+	ID             uuid.UUID `json:"id"`
+	AgentID        string    `json:"agent_id"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	Results        []byte    `json:"results"`
+	// When processing started; an unfinished claim older than the stale cutoff may be taken over
+	ClaimedAt   time.Time          `json:"claimed_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+	// Fingerprint of the batch's sales (each item's merchant, amount, currency, customer, payment method and key); NULL for batches recorded before it was stored
+	RequestHash pgtype.Text `json:"request_hash"`
+}
+
+type SchemaInfo struct {
+	Version   string           `json:"version"`
+	AppliedAt pgtype.Timestamp `json:"applied_at"`
+}
+
+type Service struct {
+	ID          uuid.UUID `json:"id"`
+	ServiceID   string    `json:"service_id"`
+	ServiceName string    `json:"service_name"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Merchants (agent_id) a service may act for; revoking deletes the row
+type ServiceMerchant struct {
+	ServiceID uuid.UUID `json:"service_id"`
+	AgentID   string    `json:"agent_id"`
+	GrantedAt time.Time `json:"granted_at"`
+	// When the grant stops allowing access; NULL grants never expire
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+}
+
+// Every active key verifies the service's tokens, so a new key is added before the old one is retired
+type ServicePublicKey struct {
+	ID        uuid.UUID `json:"id"`
+	ServiceID uuid.UUID `json:"service_id"`
+	KeyID     string    `json:"key_id"`
+	PublicKey string    `json:"public_key"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	// When the key stopped verifying tokens (NULL while active)
+	RetiredAt pgtype.Timestamptz `json:"retired_at"`
 }
 
 type Subscription struct {
@@ -110,39 +304,93 @@ type Subscription struct {
 	CreatedAt             time.Time          `json:"created_at"`
 	UpdatedAt             time.Time          `json:"updated_at"`
 	CancelledAt           pgtype.Timestamptz `json:"cancelled_at"`
+	CouponID              pgtype.UUID        `json:"coupon_id"`
+	// Number of charges the coupon discount has been applied to
+	CouponAppliedCount int32 `json:"coupon_applied_count"`
+	// Billing dates on or after this date are charged in full (repeating coupons only)
+	CouponEndsAt pgtype.Date `json:"coupon_ends_at"`
+	// When the subscription amount was last changed (NULL = never changed since creation)
+	AmountChangedAt pgtype.Timestamptz `json:"amount_changed_at"`
+	// Payment method used before the last automatic switch (NULL = never switched)
+	PreviousPaymentMethodID pgtype.UUID `json:"previous_payment_method_id"`
+	// When dunning last switched the subscription to another payment method
+	PaymentMethodSwitchedAt pgtype.Timestamptz `json:"payment_method_switched_at"`
+	// When a billing run claimed the subscription; cleared when it finishes, and a stale claim may be taken over (NULL = unclaimed)
+	BillingClaimedAt pgtype.Timestamptz `json:"billing_claimed_at"`
 }
 
 type Transaction struct {
-	ID                uuid.UUID          `json:"id"`
-	GroupID           uuid.UUID          `json:"group_id"`
-	AgentID           string             `json:"agent_id"`
-	CustomerID        pgtype.Text        `json:"customer_id"`
-	Amount            pgtype.Numeric     `json:"amount"`
-	Currency          string             `json:"currency"`
-	Status            string             `json:"status"`
-	Type              string             `json:"type"`
-	PaymentMethodType string             `json:"payment_method_type"`
-	PaymentMethodID   pgtype.UUID        `json:"payment_method_id"`
-	AuthGuid          pgtype.Text        `json:"auth_guid"`
-	AuthResp          pgtype.Text        `json:"auth_resp"`
-	AuthCode          pgtype.Text        `json:"auth_code"`
-	AuthRespText      pgtype.Text        `json:"auth_resp_text"`
-	AuthCardType      pgtype.Text        `json:"auth_card_type"`
-	AuthAvs           pgtype.Text        `json:"auth_avs"`
-	AuthCvv2          pgtype.Text        `json:"auth_cvv2"`
-	IdempotencyKey    pgtype.Text        `json:"idempotency_key"`
-	Metadata          []byte             `json:"metadata"`
-	DeletedAt         pgtype.Timestamptz `json:"deleted_at"`
-	CreatedAt         time.Time          `json:"created_at"`
-	UpdatedAt         time.Time          `json:"updated_at"`
+	ID                uuid.UUID      `json:"id"`
+	GroupID           uuid.UUID      `json:"group_id"`
+	AgentID           string         `json:"agent_id"`
+	CustomerID        pgtype.Text    `json:"customer_id"`
+	Amount            pgtype.Numeric `json:"amount"`
+	Currency          string         `json:"currency"`
+	Status            string         `json:"status"`
+	Type              string         `json:"type"`
+	PaymentMethodType string         `json:"payment_method_type"`
+	PaymentMethodID   pgtype.UUID    `json:"payment_method_id"`
+	AuthGuid          pgtype.Text    `json:"auth_guid"`
+	AuthResp          pgtype.Text    `json:"auth_resp"`
+	AuthCode          pgtype.Text    `json:"auth_code"`
+	AuthRespText      pgtype.Text    `json:"auth_resp_text"`
+	AuthCardType      pgtype.Text    `json:"auth_card_type"`
+	AuthAvs           pgtype.Text    `json:"auth_avs"`
+	AuthCvv2          pgtype.Text    `json:"auth_cvv2"`
+	// Client idempotency key (or Browser Post TRAN_NBR), unique per merchant; independent of the transaction id
+	IdempotencyKey pgtype.Text        `json:"idempotency_key"`
+	Metadata       []byte             `json:"metadata"`
+	DeletedAt      pgtype.Timestamptz `json:"deleted_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	// Opaque reference to POS order/transaction (e.g., order-123)
+	ExternalReferenceID pgtype.Text `json:"external_reference_id"`
+	// URL to redirect browser after payment callback processing
+	ReturnUrl pgtype.Text `json:"return_url"`
+	// Card funding type (credit, debit, prepaid) - NULL for ACH or when unknown; used for fee estimates
+	CardFundingType pgtype.Text `json:"card_funding_type"`
+	// When the transaction settled in an EPX batch (NULL = unsettled, still voidable)
+	SettledAt pgtype.Timestamptz `json:"settled_at"`
+	// Expected merchant funding date: settlement date plus the merchant's funding delay in business days (NULL = unsettled)
+	FundingDate pgtype.Date `json:"funding_date"`
+	// AVS/CVV policy outcome (result, failed_checks, policies and evaluated results); NULL when no policy was configured
+	VerificationOutcome []byte `json:"verification_outcome"`
+	// Merchant data region when the row was written (not changed if the merchant later moves)
+	DataRegion string `json:"data_region"`
+	// 3-D Secure result sent to EPX (version, cavv, eci, transaction_status, ds_transaction_id); NULL when 3DS was not run
+	ThreeDs []byte `json:"three_ds"`
+	// TRAN_NBR sent to EPX (from epx_tran_nbrs); NULL for transactions created before allocation existed
+	TranNbr pgtype.Int8 `json:"tran_nbr"`
+	// When a pending Browser Post transaction stops accepting its EPX callback; NULL for other transactions
+	PendingExpiresAt pgtype.Timestamptz `json:"pending_expires_at"`
+	// SHA-256 of the request parameters behind idempotency_key; NULL for unkeyed transactions and those recorded before fingerprints
+	RequestHash pgtype.Text `json:"request_hash"`
+	// UTC day a pending Browser Post sale reserved its amount against the merchant's daily volume limit; NULL when nothing was reserved
+	DailyVolumeDate pgtype.Date `json:"daily_volume_date"`
+	// SHA-256 of the card's last four digits and expiry, or of the BRIC it was charged with when those aren't known; NULL when the card is unknown
+	CardFingerprint pgtype.Text `json:"card_fingerprint"`
+	// Last four digits of the card or bank account number from the Browser Post callback; NULL for other transactions or when the callback had none
+	LastFour pgtype.Text `json:"last_four"`
+}
+
+// Events queued for batching subscriptions until the batch is full or its flush time passes; removed as they are sent
+type WebhookBatchEvent struct {
+	ID             int64           `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	// When the event's batch is sent if it hasn't filled; a batch is due once its oldest event's flush_at passes
+	FlushAt   time.Time `json:"flush_at"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // Webhook delivery log for tracking and retries
 type WebhookDelivery struct {
-	ID             uuid.UUID          `json:"id"`
-	SubscriptionID uuid.UUID          `json:"subscription_id"`
-	EventType      string             `json:"event_type"`
-	Payload        json.RawMessage    `json:"payload"`
+	ID             uuid.UUID       `json:"id"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	// pending (retrying), success, failed (legacy), dead_letter (max attempts exhausted, replay manually)
 	Status         string             `json:"status"`
 	HttpStatusCode pgtype.Int4        `json:"http_status_code"`
 	ErrorMessage   pgtype.Text        `json:"error_message"`
@@ -150,6 +398,12 @@ type WebhookDelivery struct {
 	NextRetryAt    pgtype.Timestamptz `json:"next_retry_at"`
 	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
 	CreatedAt      time.Time          `json:"created_at"`
+	// Original delivery this row manually replays (NULL for first deliveries)
+	RedeliveryOf pgtype.UUID `json:"redelivery_of"`
+	// Duration of the latest attempt until the endpoint responded or the request failed; NULL before the first attempt
+	LatencyMs pgtype.Int4 `json:"latency_ms"`
+	// Why the latest attempt failed: http_<status>, timeout, dns_error, connection_refused, tls_error or network_error; NULL on success
+	FailureReason pgtype.Text `json:"failure_reason"`
 }
 
 // Merchant webhook subscriptions for chargeback events
@@ -162,4 +416,8 @@ type WebhookSubscription struct {
 	IsActive   bool      `json:"is_active"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
+	// Events coalesced into one POST (1 = one POST per event)
+	BatchSize int32 `json:"batch_size"`
+	// Maximum time an event waits for its batch to fill before the batch is sent
+	BatchFlushIntervalSeconds int32 `json:"batch_flush_interval_seconds"`
 }
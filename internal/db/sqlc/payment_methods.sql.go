@@ -23,39 +23,89 @@ func (q *Queries) ActivatePaymentMethod(ctx context.Context, id uuid.UUID) error
 	return err
 }
 
+const completeMicroDepositVerification = `-- name: CompleteMicroDepositVerification :one
+UPDATE customer_payment_methods
+SET
+    is_verified = true,
+    micro_deposit_hash = NULL,
+    micro_deposit_attempts = GREATEST(micro_deposit_attempts - 1, 0),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at, return_count, last_return_code, deactivation_reason, micro_deposit_hash, micro_deposit_sent_at, micro_deposit_attempts, expiry_notified_at, data_region, idempotency_key
+`
+
+// The attempt that matched was counted by RecordMicroDepositAttempt; it isn't a failure, so it's taken back off
+func (q *Queries) CompleteMicroDepositVerification(ctx context.Context, id uuid.UUID) (CustomerPaymentMethod, error) {
+	row := q.db.QueryRow(ctx, completeMicroDepositVerification, id)
+	var i CustomerPaymentMethod
+	err := row.Scan(
+		&i.ID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.PaymentToken,
+		&i.PaymentType,
+		&i.LastFour,
+		&i.CardBrand,
+		&i.CardExpMonth,
+		&i.CardExpYear,
+		&i.BankName,
+		&i.AccountType,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.IsVerified,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastUsedAt,
+		&i.ReturnCount,
+		&i.LastReturnCode,
+		&i.DeactivationReason,
+		&i.MicroDepositHash,
+		&i.MicroDepositSentAt,
+		&i.MicroDepositAttempts,
+		&i.ExpiryNotifiedAt,
+		&i.DataRegion,
+		&i.IdempotencyKey,
+	)
+	return i, err
+}
+
 const createPaymentMethod = `-- name: CreatePaymentMethod :one
 INSERT INTO customer_payment_methods (
     id, agent_id, customer_id, payment_type,
     payment_token, last_four,
     card_brand, card_exp_month, card_exp_year,
     bank_name, account_type,
-    is_default, is_active, is_verified
+    is_default, is_active, is_verified, idempotency_key, data_region
 ) VALUES (
     $1, $2, $3, $4,
     $5, $6,
     $7, $8, $9,
     $10, $11,
-    $12, $13, $14
-) RETURNING id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at
+    $12, $13, $14, $15,
+    COALESCE((SELECT ac.data_region FROM agent_credentials ac WHERE ac.agent_id = $2), 'us')
+) RETURNING id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at, return_count, last_return_code, deactivation_reason, micro_deposit_hash, micro_deposit_sent_at, micro_deposit_attempts, expiry_notified_at, data_region, idempotency_key
 `
 
 type CreatePaymentMethodParams struct {
-	ID           uuid.UUID   `json:"id"`
-	AgentID      string      `json:"agent_id"`
-	CustomerID   string      `json:"customer_id"`
-	PaymentType  string      `json:"payment_type"`
-	PaymentToken string      `json:"payment_token"`
-	LastFour     string      `json:"last_four"`
-	CardBrand    pgtype.Text `json:"card_brand"`
-	CardExpMonth pgtype.Int4 `json:"card_exp_month"`
-	CardExpYear  pgtype.Int4 `json:"card_exp_year"`
-	BankName     pgtype.Text `json:"bank_name"`
-	AccountType  pgtype.Text `json:"account_type"`
-	IsDefault    pgtype.Bool `json:"is_default"`
-	IsActive     pgtype.Bool `json:"is_active"`
-	IsVerified   pgtype.Bool `json:"is_verified"`
+	ID             uuid.UUID   `json:"id"`
+	AgentID        string      `json:"agent_id"`
+	CustomerID     string      `json:"customer_id"`
+	PaymentType    string      `json:"payment_type"`
+	PaymentToken   string      `json:"payment_token"`
+	LastFour       string      `json:"last_four"`
+	CardBrand      pgtype.Text `json:"card_brand"`
+	CardExpMonth   pgtype.Int4 `json:"card_exp_month"`
+	CardExpYear    pgtype.Int4 `json:"card_exp_year"`
+	BankName       pgtype.Text `json:"bank_name"`
+	AccountType    pgtype.Text `json:"account_type"`
+	IsDefault      pgtype.Bool `json:"is_default"`
+	IsActive       pgtype.Bool `json:"is_active"`
+	IsVerified     pgtype.Bool `json:"is_verified"`
+	IdempotencyKey pgtype.Text `json:"idempotency_key"`
 }
 
+// data_region is stamped from the merchant so region-scoped exports/purges don't depend on callers
 func (q *Queries) CreatePaymentMethod(ctx context.Context, arg CreatePaymentMethodParams) (CustomerPaymentMethod, error) {
 	row := q.db.QueryRow(ctx, createPaymentMethod,
 		arg.ID,
@@ -72,6 +122,7 @@ func (q *Queries) CreatePaymentMethod(ctx context.Context, arg CreatePaymentMeth
 		arg.IsDefault,
 		arg.IsActive,
 		arg.IsVerified,
+		arg.IdempotencyKey,
 	)
 	var i CustomerPaymentMethod
 	err := row.Scan(
@@ -93,6 +144,15 @@ func (q *Queries) CreatePaymentMethod(ctx context.Context, arg CreatePaymentMeth
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastUsedAt,
+		&i.ReturnCount,
+		&i.LastReturnCode,
+		&i.DeactivationReason,
+		&i.MicroDepositHash,
+		&i.MicroDepositSentAt,
+		&i.MicroDepositAttempts,
+		&i.ExpiryNotifiedAt,
+		&i.DataRegion,
+		&i.IdempotencyKey,
 	)
 	return i, err
 }
@@ -120,7 +180,7 @@ func (q *Queries) DeletePaymentMethod(ctx context.Context, id uuid.UUID) error {
 }
 
 const getDefaultPaymentMethod = `-- name: GetDefaultPaymentMethod :one
-SELECT id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at FROM customer_payment_methods
+SELECT id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at, return_count, last_return_code, deactivation_reason, micro_deposit_hash, micro_deposit_sent_at, micro_deposit_attempts, expiry_notified_at, data_region, idempotency_key FROM customer_payment_methods
 WHERE agent_id = $1 AND customer_id = $2 AND is_default = true AND is_active = true AND deleted_at IS NULL
 LIMIT 1
 `
@@ -152,12 +212,67 @@ func (q *Queries) GetDefaultPaymentMethod(ctx context.Context, arg GetDefaultPay
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastUsedAt,
+		&i.ReturnCount,
+		&i.LastReturnCode,
+		&i.DeactivationReason,
+		&i.MicroDepositHash,
+		&i.MicroDepositSentAt,
+		&i.MicroDepositAttempts,
+		&i.ExpiryNotifiedAt,
+		&i.DataRegion,
+		&i.IdempotencyKey,
+	)
+	return i, err
+}
+
+const getPaymentMethodByIdempotencyKey = `-- name: GetPaymentMethodByIdempotencyKey :one
+SELECT id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at, return_count, last_return_code, deactivation_reason, micro_deposit_hash, micro_deposit_sent_at, micro_deposit_attempts, expiry_notified_at, data_region, idempotency_key FROM customer_payment_methods
+WHERE agent_id = $1 AND idempotency_key = $2
+`
+
+type GetPaymentMethodByIdempotencyKeyParams struct {
+	AgentID        string      `json:"agent_id"`
+	IdempotencyKey pgtype.Text `json:"idempotency_key"`
+}
+
+// The payment method a merchant's request with this idempotency key saved, even if it was deleted since
+func (q *Queries) GetPaymentMethodByIdempotencyKey(ctx context.Context, arg GetPaymentMethodByIdempotencyKeyParams) (CustomerPaymentMethod, error) {
+	row := q.db.QueryRow(ctx, getPaymentMethodByIdempotencyKey, arg.AgentID, arg.IdempotencyKey)
+	var i CustomerPaymentMethod
+	err := row.Scan(
+		&i.ID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.PaymentToken,
+		&i.PaymentType,
+		&i.LastFour,
+		&i.CardBrand,
+		&i.CardExpMonth,
+		&i.CardExpYear,
+		&i.BankName,
+		&i.AccountType,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.IsVerified,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastUsedAt,
+		&i.ReturnCount,
+		&i.LastReturnCode,
+		&i.DeactivationReason,
+		&i.MicroDepositHash,
+		&i.MicroDepositSentAt,
+		&i.MicroDepositAttempts,
+		&i.ExpiryNotifiedAt,
+		&i.DataRegion,
+		&i.IdempotencyKey,
 	)
 	return i, err
 }
 
 const getPaymentMethodByID = `-- name: GetPaymentMethodByID :one
-SELECT id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at FROM customer_payment_methods
+SELECT id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at, return_count, last_return_code, deactivation_reason, micro_deposit_hash, micro_deposit_sent_at, micro_deposit_attempts, expiry_notified_at, data_region, idempotency_key FROM customer_payment_methods
 WHERE id = $1 AND deleted_at IS NULL
 `
 
@@ -183,12 +298,129 @@ func (q *Queries) GetPaymentMethodByID(ctx context.Context, id uuid.UUID) (Custo
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastUsedAt,
+		&i.ReturnCount,
+		&i.LastReturnCode,
+		&i.DeactivationReason,
+		&i.MicroDepositHash,
+		&i.MicroDepositSentAt,
+		&i.MicroDepositAttempts,
+		&i.ExpiryNotifiedAt,
+		&i.DataRegion,
+		&i.IdempotencyKey,
 	)
 	return i, err
 }
 
+const listActivePaymentMethodsByLastUse = `-- name: ListActivePaymentMethodsByLastUse :many
+SELECT id FROM customer_payment_methods
+WHERE agent_id = $1 AND customer_id = $2 AND is_active = true AND deleted_at IS NULL
+ORDER BY is_default ASC, COALESCE(last_used_at, created_at) ASC, created_at ASC
+`
+
+type ListActivePaymentMethodsByLastUseParams struct {
+	AgentID    string `json:"agent_id"`
+	CustomerID string `json:"customer_id"`
+}
+
+// Least recently used first (never-used methods by creation time); the default is pruned last
+func (q *Queries) ListActivePaymentMethodsByLastUse(ctx context.Context, arg ListActivePaymentMethodsByLastUseParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listActivePaymentMethodsByLastUse, arg.AgentID, arg.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiringPaymentMethods = `-- name: ListExpiringPaymentMethods :many
+SELECT id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at, return_count, last_return_code, deactivation_reason, micro_deposit_hash, micro_deposit_sent_at, micro_deposit_attempts, expiry_notified_at, data_region, idempotency_key FROM customer_payment_methods
+WHERE
+    deleted_at IS NULL AND
+    is_active = true AND
+    payment_type = 'credit_card' AND
+    card_exp_month IS NOT NULL AND card_exp_year IS NOT NULL AND
+    ($1::varchar IS NULL OR agent_id = $1) AND
+    (NOT $2::boolean OR expiry_notified_at IS NULL) AND
+    make_date(card_exp_year, card_exp_month, 1) + INTERVAL '1 month' > $3::date AND
+    make_date(card_exp_year, card_exp_month, 1) + INTERVAL '1 month' <= $4::date + 1
+ORDER BY card_exp_year, card_exp_month, agent_id, customer_id
+`
+
+type ListExpiringPaymentMethodsParams struct {
+	AgentID        pgtype.Text `json:"agent_id"`
+	UnnotifiedOnly bool        `json:"unnotified_only"`
+	AsOf           pgtype.Date `json:"as_of"`
+	Cutoff         pgtype.Date `json:"cutoff"`
+}
+
+// Active cards still valid on as_of whose expiry month ends on or before cutoff.
+// A card is valid through the last day of its expiry month.
+func (q *Queries) ListExpiringPaymentMethods(ctx context.Context, arg ListExpiringPaymentMethodsParams) ([]CustomerPaymentMethod, error) {
+	rows, err := q.db.Query(ctx, listExpiringPaymentMethods,
+		arg.AgentID,
+		arg.UnnotifiedOnly,
+		arg.AsOf,
+		arg.Cutoff,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CustomerPaymentMethod{}
+	for rows.Next() {
+		var i CustomerPaymentMethod
+		if err := rows.Scan(
+			&i.ID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.PaymentToken,
+			&i.PaymentType,
+			&i.LastFour,
+			&i.CardBrand,
+			&i.CardExpMonth,
+			&i.CardExpYear,
+			&i.BankName,
+			&i.AccountType,
+			&i.IsDefault,
+			&i.IsActive,
+			&i.IsVerified,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastUsedAt,
+			&i.ReturnCount,
+			&i.LastReturnCode,
+			&i.DeactivationReason,
+			&i.MicroDepositHash,
+			&i.MicroDepositSentAt,
+			&i.MicroDepositAttempts,
+			&i.ExpiryNotifiedAt,
+			&i.DataRegion,
+			&i.IdempotencyKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPaymentMethods = `-- name: ListPaymentMethods :many
-SELECT id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at FROM customer_payment_methods
+SELECT id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at, return_count, last_return_code, deactivation_reason, micro_deposit_hash, micro_deposit_sent_at, micro_deposit_attempts, expiry_notified_at, data_region, idempotency_key FROM customer_payment_methods
 WHERE
     deleted_at IS NULL AND
     ($1::varchar IS NULL OR agent_id = $1) AND
@@ -241,6 +473,15 @@ func (q *Queries) ListPaymentMethods(ctx context.Context, arg ListPaymentMethods
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.LastUsedAt,
+			&i.ReturnCount,
+			&i.LastReturnCode,
+			&i.DeactivationReason,
+			&i.MicroDepositHash,
+			&i.MicroDepositSentAt,
+			&i.MicroDepositAttempts,
+			&i.ExpiryNotifiedAt,
+			&i.DataRegion,
+			&i.IdempotencyKey,
 		); err != nil {
 			return nil, err
 		}
@@ -253,7 +494,7 @@ func (q *Queries) ListPaymentMethods(ctx context.Context, arg ListPaymentMethods
 }
 
 const listPaymentMethodsByCustomer = `-- name: ListPaymentMethodsByCustomer :many
-SELECT id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at FROM customer_payment_methods
+SELECT id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at, return_count, last_return_code, deactivation_reason, micro_deposit_hash, micro_deposit_sent_at, micro_deposit_attempts, expiry_notified_at, data_region, idempotency_key FROM customer_payment_methods
 WHERE agent_id = $1 AND customer_id = $2 AND deleted_at IS NULL
 ORDER BY is_default DESC, created_at DESC
 `
@@ -291,6 +532,15 @@ func (q *Queries) ListPaymentMethodsByCustomer(ctx context.Context, arg ListPaym
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.LastUsedAt,
+			&i.ReturnCount,
+			&i.LastReturnCode,
+			&i.DeactivationReason,
+			&i.MicroDepositHash,
+			&i.MicroDepositSentAt,
+			&i.MicroDepositAttempts,
+			&i.ExpiryNotifiedAt,
+			&i.DataRegion,
+			&i.IdempotencyKey,
 		); err != nil {
 			return nil, err
 		}
@@ -302,6 +552,22 @@ func (q *Queries) ListPaymentMethodsByCustomer(ctx context.Context, arg ListPaym
 	return items, nil
 }
 
+const lockCustomerPaymentMethods = `-- name: LockCustomerPaymentMethods :exec
+SELECT pg_advisory_xact_lock(hashtextextended($1::varchar || ':' || $2::varchar, 0))
+`
+
+type LockCustomerPaymentMethodsParams struct {
+	AgentID    string `json:"agent_id"`
+	CustomerID string `json:"customer_id"`
+}
+
+// Serializes saves and default changes for one customer until the transaction ends, so concurrent saves can't both pass the cap
+// and concurrent default changes can't both clear the old default
+func (q *Queries) LockCustomerPaymentMethods(ctx context.Context, arg LockCustomerPaymentMethodsParams) error {
+	_, err := q.db.Exec(ctx, lockCustomerPaymentMethods, arg.AgentID, arg.CustomerID)
+	return err
+}
+
 const markPaymentMethodAsDefault = `-- name: MarkPaymentMethodAsDefault :exec
 UPDATE customer_payment_methods
 SET is_default = true, updated_at = CURRENT_TIMESTAMP
@@ -314,6 +580,17 @@ func (q *Queries) MarkPaymentMethodAsDefault(ctx context.Context, id uuid.UUID)
 	return err
 }
 
+const markPaymentMethodExpiryNotified = `-- name: MarkPaymentMethodExpiryNotified :exec
+UPDATE customer_payment_methods
+SET expiry_notified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) MarkPaymentMethodExpiryNotified(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markPaymentMethodExpiryNotified, id)
+	return err
+}
+
 const markPaymentMethodUsed = `-- name: MarkPaymentMethodUsed :exec
 UPDATE customer_payment_methods
 SET last_used_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
@@ -336,10 +613,136 @@ func (q *Queries) MarkPaymentMethodVerified(ctx context.Context, id uuid.UUID) e
 	return err
 }
 
+const recordACHReturn = `-- name: RecordACHReturn :one
+UPDATE customer_payment_methods
+SET
+    return_count = return_count + 1,
+    last_return_code = $1::varchar,
+    is_active = CASE WHEN $2::varchar IS NULL THEN is_active ELSE false END,
+    deactivation_reason = COALESCE($2::varchar, deactivation_reason),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $3 AND deleted_at IS NULL
+RETURNING id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at, return_count, last_return_code, deactivation_reason, micro_deposit_hash, micro_deposit_sent_at, micro_deposit_attempts, expiry_notified_at, data_region, idempotency_key
+`
+
+type RecordACHReturnParams struct {
+	ReturnCode         string      `json:"return_code"`
+	DeactivationReason pgtype.Text `json:"deactivation_reason"`
+	ID                 uuid.UUID   `json:"id"`
+}
+
+func (q *Queries) RecordACHReturn(ctx context.Context, arg RecordACHReturnParams) (CustomerPaymentMethod, error) {
+	row := q.db.QueryRow(ctx, recordACHReturn, arg.ReturnCode, arg.DeactivationReason, arg.ID)
+	var i CustomerPaymentMethod
+	err := row.Scan(
+		&i.ID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.PaymentToken,
+		&i.PaymentType,
+		&i.LastFour,
+		&i.CardBrand,
+		&i.CardExpMonth,
+		&i.CardExpYear,
+		&i.BankName,
+		&i.AccountType,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.IsVerified,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastUsedAt,
+		&i.ReturnCount,
+		&i.LastReturnCode,
+		&i.DeactivationReason,
+		&i.MicroDepositHash,
+		&i.MicroDepositSentAt,
+		&i.MicroDepositAttempts,
+		&i.ExpiryNotifiedAt,
+		&i.DataRegion,
+		&i.IdempotencyKey,
+	)
+	return i, err
+}
+
+const recordMicroDepositAttempt = `-- name: RecordMicroDepositAttempt :one
+UPDATE customer_payment_methods
+SET micro_deposit_attempts = micro_deposit_attempts + 1, updated_at = CURRENT_TIMESTAMP
+WHERE
+    id = $1 AND
+    deleted_at IS NULL AND
+    micro_deposit_hash IS NOT NULL AND
+    micro_deposit_attempts < $2
+RETURNING id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at, return_count, last_return_code, deactivation_reason, micro_deposit_hash, micro_deposit_sent_at, micro_deposit_attempts, expiry_notified_at, data_region, idempotency_key
+`
+
+type RecordMicroDepositAttemptParams struct {
+	ID          uuid.UUID `json:"id"`
+	MaxAttempts int32     `json:"max_attempts"`
+}
+
+// Counts a verification attempt against pending micro-deposits unless max_attempts are used up. The check and the
+// increment are one statement, so concurrent guesses can't get past the limit; no row = locked or none pending.
+func (q *Queries) RecordMicroDepositAttempt(ctx context.Context, arg RecordMicroDepositAttemptParams) (CustomerPaymentMethod, error) {
+	row := q.db.QueryRow(ctx, recordMicroDepositAttempt, arg.ID, arg.MaxAttempts)
+	var i CustomerPaymentMethod
+	err := row.Scan(
+		&i.ID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.PaymentToken,
+		&i.PaymentType,
+		&i.LastFour,
+		&i.CardBrand,
+		&i.CardExpMonth,
+		&i.CardExpYear,
+		&i.BankName,
+		&i.AccountType,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.IsVerified,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastUsedAt,
+		&i.ReturnCount,
+		&i.LastReturnCode,
+		&i.DeactivationReason,
+		&i.MicroDepositHash,
+		&i.MicroDepositSentAt,
+		&i.MicroDepositAttempts,
+		&i.ExpiryNotifiedAt,
+		&i.DataRegion,
+		&i.IdempotencyKey,
+	)
+	return i, err
+}
+
+const setMicroDeposits = `-- name: SetMicroDeposits :exec
+UPDATE customer_payment_methods
+SET
+    micro_deposit_hash = $1,
+    micro_deposit_sent_at = CURRENT_TIMESTAMP,
+    micro_deposit_attempts = 0,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2 AND deleted_at IS NULL
+`
+
+type SetMicroDepositsParams struct {
+	MicroDepositHash pgtype.Text `json:"micro_deposit_hash"`
+	ID               uuid.UUID   `json:"id"`
+}
+
+func (q *Queries) SetMicroDeposits(ctx context.Context, arg SetMicroDepositsParams) error {
+	_, err := q.db.Exec(ctx, setMicroDeposits, arg.MicroDepositHash, arg.ID)
+	return err
+}
+
 const setPaymentMethodAsDefault = `-- name: SetPaymentMethodAsDefault :exec
 UPDATE customer_payment_methods
 SET is_default = false, updated_at = CURRENT_TIMESTAMP
-WHERE agent_id = $1 AND customer_id = $2 AND deleted_at IS NULL
+WHERE agent_id = $1 AND customer_id = $2 AND is_default = true AND deleted_at IS NULL
 `
 
 type SetPaymentMethodAsDefaultParams struct {
@@ -347,8 +750,57 @@ type SetPaymentMethodAsDefaultParams struct {
 	CustomerID string `json:"customer_id"`
 }
 
-// First unset all defaults for this customer
+// Unsets the customer's current default. Only that row changes, so no other row's updated_at moves; callers
+// take LockCustomerPaymentMethods first so concurrent default changes queue until this transaction commits.
 func (q *Queries) SetPaymentMethodAsDefault(ctx context.Context, arg SetPaymentMethodAsDefaultParams) error {
 	_, err := q.db.Exec(ctx, setPaymentMethodAsDefault, arg.AgentID, arg.CustomerID)
 	return err
 }
+
+const updatePaymentMethodExpiry = `-- name: UpdatePaymentMethodExpiry :one
+UPDATE customer_payment_methods
+SET card_exp_month = $1, card_exp_year = $2, expiry_notified_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $3 AND deleted_at IS NULL
+RETURNING id, agent_id, customer_id, payment_token, payment_type, last_four, card_brand, card_exp_month, card_exp_year, bank_name, account_type, is_default, is_active, is_verified, deleted_at, created_at, updated_at, last_used_at, return_count, last_return_code, deactivation_reason, micro_deposit_hash, micro_deposit_sent_at, micro_deposit_attempts, expiry_notified_at, data_region, idempotency_key
+`
+
+type UpdatePaymentMethodExpiryParams struct {
+	CardExpMonth pgtype.Int4 `json:"card_exp_month"`
+	CardExpYear  pgtype.Int4 `json:"card_exp_year"`
+	ID           uuid.UUID   `json:"id"`
+}
+
+func (q *Queries) UpdatePaymentMethodExpiry(ctx context.Context, arg UpdatePaymentMethodExpiryParams) (CustomerPaymentMethod, error) {
+	row := q.db.QueryRow(ctx, updatePaymentMethodExpiry, arg.CardExpMonth, arg.CardExpYear, arg.ID)
+	var i CustomerPaymentMethod
+	err := row.Scan(
+		&i.ID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.PaymentToken,
+		&i.PaymentType,
+		&i.LastFour,
+		&i.CardBrand,
+		&i.CardExpMonth,
+		&i.CardExpYear,
+		&i.BankName,
+		&i.AccountType,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.IsVerified,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastUsedAt,
+		&i.ReturnCount,
+		&i.LastReturnCode,
+		&i.DeactivationReason,
+		&i.MicroDepositHash,
+		&i.MicroDepositSentAt,
+		&i.MicroDepositAttempts,
+		&i.ExpiryNotifiedAt,
+		&i.DataRegion,
+		&i.IdempotencyKey,
+	)
+	return i, err
+}
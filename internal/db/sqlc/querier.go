@@ -12,69 +12,236 @@ import (
 )
 
 type Querier interface {
-	ActivateAgent(ctx context.Context, agentID string) error
 	ActivatePaymentMethod(ctx context.Context, id uuid.UUID) error
 	AddEvidenceFile(ctx context.Context, arg AddEvidenceFileParams) error
+	AddServicePublicKey(ctx context.Context, arg AddServicePublicKeyParams) (ServicePublicKey, error)
 	AgentExists(ctx context.Context, agentID string) (bool, error)
+	// Returns no row when the transaction already has a number (a concurrent retry assigned it first)
+	AssignTranNbr(ctx context.Context, arg AssignTranNbrParams) (EpxTranNbr, error)
 	CancelSubscription(ctx context.Context, arg CancelSubscriptionParams) (Subscription, error)
+	// Only one callback saves and charges a form; returns no row when another callback claimed it first
+	ClaimBrowserPostChargeIntent(ctx context.Context, id uuid.UUID) (BrowserPostChargeIntent, error)
+	// Claims a batch key for processing. Returns no row if the key is already completed or being processed;
+	// an unfinished claim older than stale_before is taken over (its items are still protected by their own keys).
+	ClaimSaleBatch(ctx context.Context, arg ClaimSaleBatchParams) (SaleBatch, error)
+	// Takes over the pending row that reserved a merchant's idempotency key once its request has gone quiet. Only a
+	// reservation of the same request is claimed; returns no row when it finished or another retry claimed it first.
+	ClaimStalePendingTransaction(ctx context.Context, arg ClaimStalePendingTransactionParams) (Transaction, error)
+	// Claims up to limit_val active subscriptions due by next_billing_date for one billing run. Rows another run holds
+	// are skipped; a claim older than stale_before is taken over (the run that held it never finished).
+	ClaimSubscriptionsDueForBilling(ctx context.Context, arg ClaimSubscriptionsDueForBillingParams) ([]Subscription, error)
+	// Takes up to limit_val of the subscription's queued events off the queue for sending. Events another instance
+	// is taking are skipped, so each event is sent in one batch.
+	ClaimWebhookBatchEvents(ctx context.Context, arg ClaimWebhookBatchEventsParams) ([]WebhookBatchEvent, error)
+	CloseAgent(ctx context.Context, arg CloseAgentParams) (AgentCredential, error)
+	// The attempt that matched was counted by RecordMicroDepositAttempt; it isn't a failure, so it's taken back off
+	CompleteMicroDepositVerification(ctx context.Context, id uuid.UUID) (CustomerPaymentMethod, error)
+	// Records the EPX result on the pending row that reserved the request's idempotency key (or a Browser Post form).
+	// A NULL payment_method_type, card_fingerprint or last_four keeps the row's.
+	CompletePendingTransaction(ctx context.Context, arg CompletePendingTransactionParams) (Transaction, error)
+	CompleteSaleBatch(ctx context.Context, arg CompleteSaleBatchParams) error
 	CountAgents(ctx context.Context, arg CountAgentsParams) (int64, error)
 	CountChargebacks(ctx context.Context, arg CountChargebacksParams) (int64, error)
+	CountSubscriptionTransactions(ctx context.Context, subscriptionID string) (int64, error)
 	CountSubscriptions(ctx context.Context, arg CountSubscriptionsParams) (int64, error)
 	CountTransactions(ctx context.Context, arg CountTransactionsParams) (int64, error)
+	CountWebhookBatchEvents(ctx context.Context, subscriptionID uuid.UUID) (int64, error)
 	CreateAgent(ctx context.Context, arg CreateAgentParams) (AgentCredential, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
+	CreateBrowserPostChargeIntent(ctx context.Context, arg CreateBrowserPostChargeIntentParams) (BrowserPostChargeIntent, error)
 	CreateChargeback(ctx context.Context, arg CreateChargebackParams) (Chargeback, error)
+	CreateCoupon(ctx context.Context, arg CreateCouponParams) (Coupon, error)
+	CreateCronRun(ctx context.Context, arg CreateCronRunParams) (CronRun, error)
+	// A concurrent first reference may insert the same customer; the existing row is returned instead
+	CreateCustomer(ctx context.Context, arg CreateCustomerParams) (Customer, error)
+	// data_region is stamped from the merchant so region-scoped exports/purges don't depend on callers
 	CreatePaymentMethod(ctx context.Context, arg CreatePaymentMethodParams) (CustomerPaymentMethod, error)
+	CreateService(ctx context.Context, arg CreateServiceParams) (Service, error)
 	CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error)
+	// data_region is stamped from the merchant so region-scoped exports/purges don't depend on callers
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
 	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
 	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
-	DeactivateAgent(ctx context.Context, agentID string) error
+	DeactivateCoupon(ctx context.Context, id uuid.UUID) error
 	DeactivatePaymentMethod(ctx context.Context, id uuid.UUID) error
+	// A deactivated service's keys stop verifying tokens (see ListActiveServicePublicKeys)
+	DeactivateService(ctx context.Context, serviceID string) (Service, error)
 	DeletePaymentMethod(ctx context.Context, id uuid.UUID) error
 	DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) error
+	EnqueueWebhookBatchEvent(ctx context.Context, arg EnqueueWebhookBatchEventParams) error
+	// A pending Browser Post transaction whose callback came too late never gets a result. The late callback's BRIC
+	// and response are kept on the expired row, so an approval EPX made anyway can be found and voided.
+	ExpirePendingTransaction(ctx context.Context, arg ExpirePendingTransactionParams) error
+	FinishBrowserPostChargeIntent(ctx context.Context, arg FinishBrowserPostChargeIntentParams) error
 	GetAgentByAgentID(ctx context.Context, agentID string) (AgentCredential, error)
 	GetAgentByID(ctx context.Context, id uuid.UUID) (AgentCredential, error)
+	// Scoped to the agent so other merchants' transactions are indistinguishable from missing ones
+	GetAgentTransactionsByIDs(ctx context.Context, arg GetAgentTransactionsByIDsParams) ([]Transaction, error)
+	GetBrowserPostChargeIntent(ctx context.Context, arg GetBrowserPostChargeIntentParams) (BrowserPostChargeIntent, error)
 	GetChargebackByCaseNumber(ctx context.Context, arg GetChargebackByCaseNumberParams) (Chargeback, error)
 	GetChargebackByGroupID(ctx context.Context, groupID pgtype.UUID) (Chargeback, error)
 	GetChargebackByID(ctx context.Context, id uuid.UUID) (Chargeback, error)
+	GetCouponByCode(ctx context.Context, arg GetCouponByCodeParams) (Coupon, error)
+	GetCouponByID(ctx context.Context, id uuid.UUID) (Coupon, error)
+	GetCustomer(ctx context.Context, arg GetCustomerParams) (Customer, error)
 	GetDefaultPaymentMethod(ctx context.Context, arg GetDefaultPaymentMethodParams) (CustomerPaymentMethod, error)
+	// The global flag and the merchant's own, whichever exist
+	GetFeatureFlagsForAgent(ctx context.Context, arg GetFeatureFlagsForAgentParams) ([]FeatureFlag, error)
+	// The 3-D Secure result of the group's authorization, for chargeback evidence
+	GetGroupThreeDS(ctx context.Context, groupID uuid.UUID) ([]byte, error)
+	// The payment method a merchant's request with this idempotency key saved, even if it was deleted since
+	GetPaymentMethodByIdempotencyKey(ctx context.Context, arg GetPaymentMethodByIdempotencyKeyParams) (CustomerPaymentMethod, error)
 	GetPaymentMethodByID(ctx context.Context, id uuid.UUID) (CustomerPaymentMethod, error)
+	GetSaleBatch(ctx context.Context, arg GetSaleBatchParams) (SaleBatch, error)
+	GetServiceByServiceID(ctx context.Context, serviceID string) (Service, error)
+	// Per UTC day, currency and kind: completed sales and captures ('sale'), completed refunds ('refund') and voids
+	// of payments that had captured money ('void'; voiding an uncaptured authorization moves no money).
+	GetSettlementTotals(ctx context.Context, arg GetSettlementTotalsParams) ([]GetSettlementTotalsRow, error)
 	GetSubscriptionByID(ctx context.Context, id uuid.UUID) (Subscription, error)
+	GetTranNbr(ctx context.Context, transactionID uuid.UUID) (EpxTranNbr, error)
+	// The transaction EPX returned this BRIC for (any merchant; callers check ownership)
+	GetTransactionByAuthGUID(ctx context.Context, authGuid pgtype.Text) (Transaction, error)
 	GetTransactionByID(ctx context.Context, id uuid.UUID) (Transaction, error)
-	GetTransactionByIdempotencyKey(ctx context.Context, idempotencyKey pgtype.Text) (Transaction, error)
+	// Keys are unique per merchant, so the same key can name different merchants' transactions
+	GetTransactionByIdempotencyKey(ctx context.Context, arg GetTransactionByIdempotencyKeyParams) (Transaction, error)
+	// The merchant's transaction sent to EPX with this TRAN_NBR, including Browser Post callbacks (their form's
+	// TRAN_NBR). Browser Post rows recorded before tran_nbr was stored for them match on their idempotency key.
+	GetTransactionByTranNbr(ctx context.Context, arg GetTransactionByTranNbrParams) (Transaction, error)
+	// Sale, authorization and increment attempts by one card (card_fingerprint) or customer since a time, for
+	// velocity limits. Every attempt counts, declines included; the amount only sums attempts that weren't declined.
+	GetTransactionVelocity(ctx context.Context, arg GetTransactionVelocityParams) (GetTransactionVelocityRow, error)
 	GetTransactionsByGroupID(ctx context.Context, groupID uuid.UUID) ([]Transaction, error)
+	// Unscoped: callers check each transaction's agent before returning it
+	GetTransactionsByIDs(ctx context.Context, ids []uuid.UUID) ([]Transaction, error)
+	GetWebhookDelivery(ctx context.Context, id uuid.UUID) (WebhookDelivery, error)
 	GetWebhookDeliveryHistory(ctx context.Context, arg GetWebhookDeliveryHistoryParams) ([]WebhookDelivery, error)
+	// Deliveries created within [created_from, created_to) grouped by status and failure reason. Rows from before
+	// failure reasons were recorded fall back to their HTTP status.
+	GetWebhookDeliveryStats(ctx context.Context, arg GetWebhookDeliveryStatsParams) ([]GetWebhookDeliveryStatsRow, error)
 	GetWebhookSubscription(ctx context.Context, id uuid.UUID) (WebhookSubscription, error)
+	// Granting an existing grant replaces its expiry
+	GrantServiceAccess(ctx context.Context, arg GrantServiceAccessParams) (ServiceMerchant, error)
+	// Whether an active service has a grant for the merchant that hasn't expired by now
+	HasServiceGrant(ctx context.Context, arg HasServiceGrantParams) (bool, error)
 	IncrementSubscriptionFailureCount(ctx context.Context, arg IncrementSubscriptionFailureCountParams) (Subscription, error)
 	IncrementSubscriptionRetryCount(ctx context.Context, id uuid.UUID) error
 	ListActiveAgents(ctx context.Context) ([]AgentCredential, error)
+	// Least recently used first (never-used methods by creation time); the default is pruned last
+	ListActivePaymentMethodsByLastUse(ctx context.Context, arg ListActivePaymentMethodsByLastUseParams) ([]uuid.UUID, error)
+	// Keys that verify an active service's tokens (none once the service is deactivated)
+	ListActiveServicePublicKeys(ctx context.Context, serviceID string) ([]ServicePublicKey, error)
 	ListActiveWebhooksByEvent(ctx context.Context, arg ListActiveWebhooksByEventParams) ([]WebhookSubscription, error)
+	// search is an ILIKE pattern matched against the agent ID and name (NULL = no search)
 	ListAgents(ctx context.Context, arg ListAgentsParams) ([]AgentCredential, error)
+	// Merchants registered with this EPX terminal (cust_nbr alone is not unique); callers require exactly one
+	ListAgentsByEPXCredentials(ctx context.Context, arg ListAgentsByEPXCredentialsParams) ([]AgentCredential, error)
+	// Newest first. success matches the entry's result ("success"), read from metadata.result or metadata.outcome.result;
+	// entries without a result only match when success is NULL.
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
 	ListChargebacks(ctx context.Context, arg ListChargebacksParams) ([]Chargeback, error)
+	// Open chargebacks without a response whose respond_by_date falls between as_of and cutoff (inclusive)
+	ListChargebacksDueWithin(ctx context.Context, arg ListChargebacksDueWithinParams) ([]Chargeback, error)
+	ListCoupons(ctx context.Context, arg ListCouponsParams) ([]Coupon, error)
+	ListCronRuns(ctx context.Context, arg ListCronRunsParams) ([]CronRun, error)
+	ListDeadLetterWebhookDeliveries(ctx context.Context, arg ListDeadLetterWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	// A merchant's card authorizations and charges for the disputed amount in a date window,
+	// with the saved card's last four when the payment used one
+	ListDisputeMatchCandidates(ctx context.Context, arg ListDisputeMatchCandidatesParams) ([]ListDisputeMatchCandidatesRow, error)
 	ListDueSubscriptions(ctx context.Context, arg ListDueSubscriptionsParams) ([]Subscription, error)
+	// Subscriptions whose oldest queued event is past its flush time
+	ListDueWebhookBatches(ctx context.Context, now pgtype.Timestamptz) ([]uuid.UUID, error)
+	// Active cards still valid on as_of whose expiry month ends on or before cutoff.
+	// A card is valid through the last day of its expiry month.
+	ListExpiringPaymentMethods(ctx context.Context, arg ListExpiringPaymentMethodsParams) ([]CustomerPaymentMethod, error)
+	ListFailedWebhookDeliveries(ctx context.Context, arg ListFailedWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListFeatureFlags(ctx context.Context) ([]FeatureFlag, error)
 	ListPaymentMethods(ctx context.Context, arg ListPaymentMethodsParams) ([]CustomerPaymentMethod, error)
 	ListPaymentMethodsByCustomer(ctx context.Context, arg ListPaymentMethodsByCustomerParams) ([]CustomerPaymentMethod, error)
 	ListPendingWebhookDeliveries(ctx context.Context, limitVal int32) ([]WebhookDelivery, error)
+	// Every grant with its service and merchant, optionally for one service and/or merchant
+	ListServiceGrantDetails(ctx context.Context, arg ListServiceGrantDetailsParams) ([]ListServiceGrantDetailsRow, error)
+	ListServicePublicKeys(ctx context.Context, serviceID uuid.UUID) ([]ServicePublicKey, error)
+	// search is an ILIKE pattern matched against the service ID and name (NULL = every service)
+	ListServices(ctx context.Context, arg ListServicesParams) ([]Service, error)
+	// Every billing attempt (approved and declined charges) of a merchant's subscription, oldest first
+	ListSubscriptionChargeAttempts(ctx context.Context, arg ListSubscriptionChargeAttemptsParams) ([]Transaction, error)
+	// Includes billing charges and any follow-up transactions (refunds, voids) in the same group
+	ListSubscriptionTransactions(ctx context.Context, arg ListSubscriptionTransactionsParams) ([]Transaction, error)
 	ListSubscriptions(ctx context.Context, arg ListSubscriptionsParams) ([]Subscription, error)
 	ListSubscriptionsByCustomer(ctx context.Context, arg ListSubscriptionsByCustomerParams) ([]Subscription, error)
 	ListSubscriptionsDueForBilling(ctx context.Context, arg ListSubscriptionsDueForBillingParams) ([]Subscription, error)
 	ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]Transaction, error)
+	// Keyset pagination: newest first, strictly older than the (created_at, id) cursor (NULL = first page).
+	// Stable under concurrent inserts, unlike OFFSET.
+	ListTransactionsAfterCursor(ctx context.Context, arg ListTransactionsAfterCursorParams) ([]Transaction, error)
+	// Settleable transactions (sales, captures, refunds) with an EPX token in the date range.
+	// voided_in_group flags transactions whose group was voided (never expected to settle).
+	ListTransactionsForReconciliation(ctx context.Context, arg ListTransactionsForReconciliationParams) ([]ListTransactionsForReconciliationRow, error)
+	// A merchant's approved authorizations last approved before the cutoff (the auth itself and any completed increment)
+	// whose group has no completed capture and no void, oldest first. Candidates for the auth auto-void job.
+	ListUncapturedAuthorizations(ctx context.Context, arg ListUncapturedAuthorizationsParams) ([]Transaction, error)
 	ListWebhookSubscriptions(ctx context.Context, arg ListWebhookSubscriptionsParams) ([]WebhookSubscription, error)
+	// Serializes saves and default changes for one customer until the transaction ends, so concurrent saves can't both pass the cap
+	// and concurrent default changes can't both clear the old default
+	LockCustomerPaymentMethods(ctx context.Context, arg LockCustomerPaymentMethodsParams) error
+	// Serializes the follow-ups that draw on a group's amounts until the transaction ends, so concurrent requests
+	// can't each pass the group's checks against the same remaining amount
+	LockTransactionGroup(ctx context.Context, groupID uuid.UUID) error
+	MarkChargebackDeadlineReminded(ctx context.Context, id uuid.UUID) error
 	MarkChargebackResolved(ctx context.Context, arg MarkChargebackResolvedParams) error
+	// Open chargebacks without a response whose respond_by_date is before as_of are lost
+	MarkOverdueChargebacksLost(ctx context.Context, arg MarkOverdueChargebacksLostParams) ([]Chargeback, error)
 	// Then set the specified one as default
 	MarkPaymentMethodAsDefault(ctx context.Context, id uuid.UUID) error
+	MarkPaymentMethodExpiryNotified(ctx context.Context, id uuid.UUID) error
 	MarkPaymentMethodUsed(ctx context.Context, id uuid.UUID) error
 	MarkPaymentMethodVerified(ctx context.Context, id uuid.UUID) error
+	MarkTransactionSettled(ctx context.Context, arg MarkTransactionSettledParams) error
+	// Takes the merchant's next TRAN_NBR, starting at 1
+	NextTranNbr(ctx context.Context, agentID string) (int64, error)
+	ReactivateAgent(ctx context.Context, agentID string) (AgentCredential, error)
+	RecordACHReturn(ctx context.Context, arg RecordACHReturnParams) (CustomerPaymentMethod, error)
+	// Counts a verification attempt against pending micro-deposits unless max_attempts are used up. The check and the
+	// increment are one statement, so concurrent guesses can't get past the limit; no row = locked or none pending.
+	RecordMicroDepositAttempt(ctx context.Context, arg RecordMicroDepositAttemptParams) (CustomerPaymentMethod, error)
+	// Returns a reservation whose payment didn't go through
+	ReleaseDailyVolume(ctx context.Context, arg ReleaseDailyVolumeParams) error
+	ReleaseSubscriptionBillingClaim(ctx context.Context, id uuid.UUID) error
+	// Adds amount to the merchant's running total for the day unless that would pass the limit.
+	// The upsert locks the counter row, so concurrent reservations are serialized; no row = limit reached.
+	ReserveDailyVolume(ctx context.Context, arg ReserveDailyVolumeParams) (pgtype.Numeric, error)
 	ResetSubscriptionRetryCount(ctx context.Context, id uuid.UUID) error
-	// First unset all defaults for this customer
+	// Retires the key only while another of the service's keys stays active. The active keys are locked first,
+	// so concurrent retirements wait for each other and can't leave the service with none.
+	RetireServicePublicKey(ctx context.Context, arg RetireServicePublicKeyParams) (ServicePublicKey, error)
+	RevokeServiceAccess(ctx context.Context, arg RevokeServiceAccessParams) (int64, error)
+	// agent_id NULL sets the global flag
+	SetFeatureFlag(ctx context.Context, arg SetFeatureFlagParams) (FeatureFlag, error)
+	SetMicroDeposits(ctx context.Context, arg SetMicroDepositsParams) error
+	// Unsets the customer's current default. Only that row changes, so no other row's updated_at moves; callers
+	// take LockCustomerPaymentMethods first so concurrent default changes queue until this transaction commits.
 	SetPaymentMethodAsDefault(ctx context.Context, arg SetPaymentMethodAsDefaultParams) error
+	// Records the UTC day of the daily volume a pending row's request holds: NULL once its hold is released (the
+	// request never reached EPX), or the day of the hold a retry took over the row with
+	SetPendingDailyVolume(ctx context.Context, arg SetPendingDailyVolumeParams) error
+	// Appends the file references not already on the merchant's open chargeback, if its deadline hasn't passed,, stores the narrative and
+	// marks the evidence ready for submission through North's portal; no row is returned if the chargeback doesn't accept evidence
+	StoreChargebackEvidence(ctx context.Context, arg StoreChargebackEvidenceParams) (Chargeback, error)
+	SuspendAgent(ctx context.Context, arg SuspendAgentParams) (AgentCredential, error)
+	SwitchSubscriptionPaymentMethod(ctx context.Context, arg SwitchSubscriptionPaymentMethodParams) (Subscription, error)
+	// Session-level lock: held until UnlockCronJob or until the connection closes, so a crashed run never leaves it held
+	TryLockCronJob(ctx context.Context, job string) (bool, error)
+	UnlockCronJob(ctx context.Context, job string) (bool, error)
 	UpdateAgent(ctx context.Context, arg UpdateAgentParams) (AgentCredential, error)
 	UpdateAgentMACPath(ctx context.Context, arg UpdateAgentMACPathParams) error
+	// Self-service settings only; a NULL argument keeps the current value and an empty one clears it
+	UpdateAgentSettings(ctx context.Context, arg UpdateAgentSettingsParams) (AgentCredential, error)
 	UpdateChargeback(ctx context.Context, arg UpdateChargebackParams) (Chargeback, error)
 	UpdateChargebackNotes(ctx context.Context, arg UpdateChargebackNotesParams) error
 	UpdateChargebackResponse(ctx context.Context, arg UpdateChargebackResponseParams) error
 	UpdateChargebackStatus(ctx context.Context, arg UpdateChargebackStatusParams) (Chargeback, error)
 	UpdateNextBillingDate(ctx context.Context, arg UpdateNextBillingDateParams) error
+	UpdatePaymentMethodExpiry(ctx context.Context, arg UpdatePaymentMethodExpiryParams) (CustomerPaymentMethod, error)
 	UpdateSubscription(ctx context.Context, arg UpdateSubscriptionParams) (Subscription, error)
 	UpdateSubscriptionBilling(ctx context.Context, arg UpdateSubscriptionBillingParams) (Subscription, error)
 	UpdateSubscriptionStatus(ctx context.Context, arg UpdateSubscriptionStatusParams) (Subscription, error)
@@ -82,6 +249,11 @@ type Querier interface {
 	UpdateTransactionStatus(ctx context.Context, arg UpdateTransactionStatusParams) error
 	UpdateWebhookDeliveryStatus(ctx context.Context, arg UpdateWebhookDeliveryStatusParams) (WebhookDelivery, error)
 	UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error)
+	// Idempotent dispute sync: a re-synced case updates North-sourced fields and keeps our
+	// response data (evidence, notes, group link) intact. created_at = updated_at on insert.
+	// A new respond_by_date re-arms the deadline reminder; a missing one keeps the stored date.
+	// An existing group link (from an earlier match or set by hand) is never replaced.
+	UpsertChargeback(ctx context.Context, arg UpsertChargebackParams) (Chargeback, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -7,6 +7,7 @@ package sqlc
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -16,7 +17,7 @@ const cancelSubscription = `-- name: CancelSubscription :one
 UPDATE subscriptions
 SET status = $1, cancelled_at = $2, updated_at = CURRENT_TIMESTAMP
 WHERE id = $3
-RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at
+RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at
 `
 
 type CancelSubscriptionParams struct {
@@ -47,10 +48,87 @@ func (q *Queries) CancelSubscription(ctx context.Context, arg CancelSubscription
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CancelledAt,
+		&i.CouponID,
+		&i.CouponAppliedCount,
+		&i.CouponEndsAt,
+		&i.AmountChangedAt,
+		&i.PreviousPaymentMethodID,
+		&i.PaymentMethodSwitchedAt,
+		&i.BillingClaimedAt,
 	)
 	return i, err
 }
 
+const claimSubscriptionsDueForBilling = `-- name: ClaimSubscriptionsDueForBilling :many
+UPDATE subscriptions
+SET billing_claimed_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT id FROM subscriptions
+    WHERE
+        status = 'active' AND
+        next_billing_date <= $1 AND
+        (billing_claimed_at IS NULL OR billing_claimed_at < $2)
+    ORDER BY next_billing_date ASC
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at
+`
+
+type ClaimSubscriptionsDueForBillingParams struct {
+	NextBillingDate pgtype.Date `json:"next_billing_date"`
+	StaleBefore     time.Time   `json:"stale_before"`
+	LimitVal        int32       `json:"limit_val"`
+}
+
+// Claims up to limit_val active subscriptions due by next_billing_date for one billing run. Rows another run holds
+// are skipped; a claim older than stale_before is taken over (the run that held it never finished).
+func (q *Queries) ClaimSubscriptionsDueForBilling(ctx context.Context, arg ClaimSubscriptionsDueForBillingParams) ([]Subscription, error) {
+	rows, err := q.db.Query(ctx, claimSubscriptionsDueForBilling, arg.NextBillingDate, arg.StaleBefore, arg.LimitVal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Subscription{}
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.Amount,
+			&i.Currency,
+			&i.IntervalValue,
+			&i.IntervalUnit,
+			&i.Status,
+			&i.PaymentMethodID,
+			&i.NextBillingDate,
+			&i.FailureRetryCount,
+			&i.MaxRetries,
+			&i.GatewaySubscriptionID,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CancelledAt,
+			&i.CouponID,
+			&i.CouponAppliedCount,
+			&i.CouponEndsAt,
+			&i.AmountChangedAt,
+			&i.PreviousPaymentMethodID,
+			&i.PaymentMethodSwitchedAt,
+			&i.BillingClaimedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const countSubscriptions = `-- name: CountSubscriptions :one
 SELECT COUNT(*) FROM subscriptions
 WHERE
@@ -78,14 +156,16 @@ INSERT INTO subscriptions (
     interval_value, interval_unit, status,
     payment_method_id, next_billing_date,
     failure_retry_count, max_retries,
-    gateway_subscription_id, metadata
+    gateway_subscription_id, metadata,
+    coupon_id, coupon_ends_at
 ) VALUES (
     $1, $2, $3, $4, $5,
     $6, $7, $8,
     $9, $10,
     $11, $12,
-    $13, $14
-) RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at
+    $13, $14,
+    $15, $16
+) RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at
 `
 
 type CreateSubscriptionParams struct {
@@ -103,6 +183,8 @@ type CreateSubscriptionParams struct {
 	MaxRetries            int32          `json:"max_retries"`
 	GatewaySubscriptionID pgtype.Text    `json:"gateway_subscription_id"`
 	Metadata              []byte         `json:"metadata"`
+	CouponID              pgtype.UUID    `json:"coupon_id"`
+	CouponEndsAt          pgtype.Date    `json:"coupon_ends_at"`
 }
 
 func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error) {
@@ -121,6 +203,8 @@ func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscription
 		arg.MaxRetries,
 		arg.GatewaySubscriptionID,
 		arg.Metadata,
+		arg.CouponID,
+		arg.CouponEndsAt,
 	)
 	var i Subscription
 	err := row.Scan(
@@ -142,12 +226,19 @@ func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscription
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CancelledAt,
+		&i.CouponID,
+		&i.CouponAppliedCount,
+		&i.CouponEndsAt,
+		&i.AmountChangedAt,
+		&i.PreviousPaymentMethodID,
+		&i.PaymentMethodSwitchedAt,
+		&i.BillingClaimedAt,
 	)
 	return i, err
 }
 
 const getSubscriptionByID = `-- name: GetSubscriptionByID :one
-SELECT id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at FROM subscriptions
+SELECT id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at FROM subscriptions
 WHERE id = $1
 `
 
@@ -173,6 +264,13 @@ func (q *Queries) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (Subscr
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CancelledAt,
+		&i.CouponID,
+		&i.CouponAppliedCount,
+		&i.CouponEndsAt,
+		&i.AmountChangedAt,
+		&i.PreviousPaymentMethodID,
+		&i.PaymentMethodSwitchedAt,
+		&i.BillingClaimedAt,
 	)
 	return i, err
 }
@@ -184,7 +282,7 @@ SET
     status = $2,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $3
-RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at
+RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at
 `
 
 type IncrementSubscriptionFailureCountParams struct {
@@ -215,6 +313,13 @@ func (q *Queries) IncrementSubscriptionFailureCount(ctx context.Context, arg Inc
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CancelledAt,
+		&i.CouponID,
+		&i.CouponAppliedCount,
+		&i.CouponEndsAt,
+		&i.AmountChangedAt,
+		&i.PreviousPaymentMethodID,
+		&i.PaymentMethodSwitchedAt,
+		&i.BillingClaimedAt,
 	)
 	return i, err
 }
@@ -231,7 +336,7 @@ func (q *Queries) IncrementSubscriptionRetryCount(ctx context.Context, id uuid.U
 }
 
 const listDueSubscriptions = `-- name: ListDueSubscriptions :many
-SELECT id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at FROM subscriptions
+SELECT id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at FROM subscriptions
 WHERE status = 'active' AND next_billing_date <= $1
 ORDER BY next_billing_date ASC
 LIMIT $2
@@ -270,6 +375,13 @@ func (q *Queries) ListDueSubscriptions(ctx context.Context, arg ListDueSubscript
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.CancelledAt,
+			&i.CouponID,
+			&i.CouponAppliedCount,
+			&i.CouponEndsAt,
+			&i.AmountChangedAt,
+			&i.PreviousPaymentMethodID,
+			&i.PaymentMethodSwitchedAt,
+			&i.BillingClaimedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -282,7 +394,7 @@ func (q *Queries) ListDueSubscriptions(ctx context.Context, arg ListDueSubscript
 }
 
 const listSubscriptions = `-- name: ListSubscriptions :many
-SELECT id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at FROM subscriptions
+SELECT id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at FROM subscriptions
 WHERE
     ($1::varchar IS NULL OR agent_id = $1) AND
     ($2::varchar IS NULL OR customer_id = $2) AND
@@ -333,6 +445,13 @@ func (q *Queries) ListSubscriptions(ctx context.Context, arg ListSubscriptionsPa
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.CancelledAt,
+			&i.CouponID,
+			&i.CouponAppliedCount,
+			&i.CouponEndsAt,
+			&i.AmountChangedAt,
+			&i.PreviousPaymentMethodID,
+			&i.PaymentMethodSwitchedAt,
+			&i.BillingClaimedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -345,7 +464,7 @@ func (q *Queries) ListSubscriptions(ctx context.Context, arg ListSubscriptionsPa
 }
 
 const listSubscriptionsByCustomer = `-- name: ListSubscriptionsByCustomer :many
-SELECT id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at FROM subscriptions
+SELECT id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at FROM subscriptions
 WHERE agent_id = $1 AND customer_id = $2
 ORDER BY created_at DESC
 `
@@ -383,6 +502,13 @@ func (q *Queries) ListSubscriptionsByCustomer(ctx context.Context, arg ListSubsc
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.CancelledAt,
+			&i.CouponID,
+			&i.CouponAppliedCount,
+			&i.CouponEndsAt,
+			&i.AmountChangedAt,
+			&i.PreviousPaymentMethodID,
+			&i.PaymentMethodSwitchedAt,
+			&i.BillingClaimedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -395,7 +521,7 @@ func (q *Queries) ListSubscriptionsByCustomer(ctx context.Context, arg ListSubsc
 }
 
 const listSubscriptionsDueForBilling = `-- name: ListSubscriptionsDueForBilling :many
-SELECT id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at FROM subscriptions
+SELECT id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at FROM subscriptions
 WHERE status = 'active' AND next_billing_date <= $1
 ORDER BY next_billing_date ASC
 LIMIT $2
@@ -434,6 +560,13 @@ func (q *Queries) ListSubscriptionsDueForBilling(ctx context.Context, arg ListSu
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.CancelledAt,
+			&i.CouponID,
+			&i.CouponAppliedCount,
+			&i.CouponEndsAt,
+			&i.AmountChangedAt,
+			&i.PreviousPaymentMethodID,
+			&i.PaymentMethodSwitchedAt,
+			&i.BillingClaimedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -445,6 +578,17 @@ func (q *Queries) ListSubscriptionsDueForBilling(ctx context.Context, arg ListSu
 	return items, nil
 }
 
+const releaseSubscriptionBillingClaim = `-- name: ReleaseSubscriptionBillingClaim :exec
+UPDATE subscriptions
+SET billing_claimed_at = NULL
+WHERE id = $1
+`
+
+func (q *Queries) ReleaseSubscriptionBillingClaim(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, releaseSubscriptionBillingClaim, id)
+	return err
+}
+
 const resetSubscriptionRetryCount = `-- name: ResetSubscriptionRetryCount :exec
 UPDATE subscriptions
 SET failure_retry_count = 0, updated_at = CURRENT_TIMESTAMP
@@ -456,6 +600,57 @@ func (q *Queries) ResetSubscriptionRetryCount(ctx context.Context, id uuid.UUID)
 	return err
 }
 
+const switchSubscriptionPaymentMethod = `-- name: SwitchSubscriptionPaymentMethod :one
+UPDATE subscriptions
+SET
+    previous_payment_method_id = payment_method_id,
+    payment_method_id = $1,
+    payment_method_switched_at = CURRENT_TIMESTAMP,
+    failure_retry_count = 0,
+    status = 'active',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at
+`
+
+type SwitchSubscriptionPaymentMethodParams struct {
+	PaymentMethodID uuid.UUID `json:"payment_method_id"`
+	ID              uuid.UUID `json:"id"`
+}
+
+func (q *Queries) SwitchSubscriptionPaymentMethod(ctx context.Context, arg SwitchSubscriptionPaymentMethodParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, switchSubscriptionPaymentMethod, arg.PaymentMethodID, arg.ID)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.Amount,
+		&i.Currency,
+		&i.IntervalValue,
+		&i.IntervalUnit,
+		&i.Status,
+		&i.PaymentMethodID,
+		&i.NextBillingDate,
+		&i.FailureRetryCount,
+		&i.MaxRetries,
+		&i.GatewaySubscriptionID,
+		&i.Metadata,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CancelledAt,
+		&i.CouponID,
+		&i.CouponAppliedCount,
+		&i.CouponEndsAt,
+		&i.AmountChangedAt,
+		&i.PreviousPaymentMethodID,
+		&i.PaymentMethodSwitchedAt,
+		&i.BillingClaimedAt,
+	)
+	return i, err
+}
+
 const updateNextBillingDate = `-- name: UpdateNextBillingDate :exec
 UPDATE subscriptions
 SET next_billing_date = $1, updated_at = CURRENT_TIMESTAMP
@@ -475,13 +670,14 @@ func (q *Queries) UpdateNextBillingDate(ctx context.Context, arg UpdateNextBilli
 const updateSubscription = `-- name: UpdateSubscription :one
 UPDATE subscriptions
 SET
+    amount_changed_at = CASE WHEN amount <> $1 THEN CURRENT_TIMESTAMP ELSE amount_changed_at END,
     amount = $1,
     interval_value = $2,
     interval_unit = $3,
     payment_method_id = $4,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $5
-RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at
+RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at
 `
 
 type UpdateSubscriptionParams struct {
@@ -520,6 +716,13 @@ func (q *Queries) UpdateSubscription(ctx context.Context, arg UpdateSubscription
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CancelledAt,
+		&i.CouponID,
+		&i.CouponAppliedCount,
+		&i.CouponEndsAt,
+		&i.AmountChangedAt,
+		&i.PreviousPaymentMethodID,
+		&i.PaymentMethodSwitchedAt,
+		&i.BillingClaimedAt,
 	)
 	return i, err
 }
@@ -530,16 +733,18 @@ SET
     next_billing_date = $1,
     failure_retry_count = $2,
     status = $3,
+    coupon_applied_count = $4,
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $4
-RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at
+WHERE id = $5
+RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at
 `
 
 type UpdateSubscriptionBillingParams struct {
-	NextBillingDate   pgtype.Date `json:"next_billing_date"`
-	FailureRetryCount int32       `json:"failure_retry_count"`
-	Status            string      `json:"status"`
-	ID                uuid.UUID   `json:"id"`
+	NextBillingDate    pgtype.Date `json:"next_billing_date"`
+	FailureRetryCount  int32       `json:"failure_retry_count"`
+	Status             string      `json:"status"`
+	CouponAppliedCount int32       `json:"coupon_applied_count"`
+	ID                 uuid.UUID   `json:"id"`
 }
 
 func (q *Queries) UpdateSubscriptionBilling(ctx context.Context, arg UpdateSubscriptionBillingParams) (Subscription, error) {
@@ -547,6 +752,7 @@ func (q *Queries) UpdateSubscriptionBilling(ctx context.Context, arg UpdateSubsc
 		arg.NextBillingDate,
 		arg.FailureRetryCount,
 		arg.Status,
+		arg.CouponAppliedCount,
 		arg.ID,
 	)
 	var i Subscription
@@ -569,6 +775,13 @@ func (q *Queries) UpdateSubscriptionBilling(ctx context.Context, arg UpdateSubsc
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CancelledAt,
+		&i.CouponID,
+		&i.CouponAppliedCount,
+		&i.CouponEndsAt,
+		&i.AmountChangedAt,
+		&i.PreviousPaymentMethodID,
+		&i.PaymentMethodSwitchedAt,
+		&i.BillingClaimedAt,
 	)
 	return i, err
 }
@@ -577,7 +790,7 @@ const updateSubscriptionStatus = `-- name: UpdateSubscriptionStatus :one
 UPDATE subscriptions
 SET status = $1, updated_at = CURRENT_TIMESTAMP
 WHERE id = $2
-RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at
+RETURNING id, agent_id, customer_id, amount, currency, interval_value, interval_unit, status, payment_method_id, next_billing_date, failure_retry_count, max_retries, gateway_subscription_id, metadata, deleted_at, created_at, updated_at, cancelled_at, coupon_id, coupon_applied_count, coupon_ends_at, amount_changed_at, previous_payment_method_id, payment_method_switched_at, billing_claimed_at
 `
 
 type UpdateSubscriptionStatusParams struct {
@@ -607,6 +820,13 @@ func (q *Queries) UpdateSubscriptionStatus(ctx context.Context, arg UpdateSubscr
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CancelledAt,
+		&i.CouponID,
+		&i.CouponAppliedCount,
+		&i.CouponEndsAt,
+		&i.AmountChangedAt,
+		&i.PreviousPaymentMethodID,
+		&i.PaymentMethodSwitchedAt,
+		&i.BillingClaimedAt,
 	)
 	return i, err
 }
@@ -7,11 +7,201 @@ package sqlc
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimStalePendingTransaction = `-- name: ClaimStalePendingTransaction :one
+UPDATE transactions
+SET updated_at = CURRENT_TIMESTAMP
+WHERE agent_id = $1
+  AND idempotency_key = $2
+  AND request_hash = $3
+  AND status = 'pending'
+  AND updated_at < $4
+RETURNING id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four
+`
+
+type ClaimStalePendingTransactionParams struct {
+	AgentID        string      `json:"agent_id"`
+	IdempotencyKey pgtype.Text `json:"idempotency_key"`
+	RequestHash    pgtype.Text `json:"request_hash"`
+	StaleBefore    time.Time   `json:"stale_before"`
+}
+
+// Takes over the pending row that reserved a merchant's idempotency key once its request has gone quiet. Only a
+// reservation of the same request is claimed; returns no row when it finished or another retry claimed it first.
+func (q *Queries) ClaimStalePendingTransaction(ctx context.Context, arg ClaimStalePendingTransactionParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, claimStalePendingTransaction,
+		arg.AgentID,
+		arg.IdempotencyKey,
+		arg.RequestHash,
+		arg.StaleBefore,
+	)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.Amount,
+		&i.Currency,
+		&i.Status,
+		&i.Type,
+		&i.PaymentMethodType,
+		&i.PaymentMethodID,
+		&i.AuthGuid,
+		&i.AuthResp,
+		&i.AuthCode,
+		&i.AuthRespText,
+		&i.AuthCardType,
+		&i.AuthAvs,
+		&i.AuthCvv2,
+		&i.IdempotencyKey,
+		&i.Metadata,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExternalReferenceID,
+		&i.ReturnUrl,
+		&i.CardFundingType,
+		&i.SettledAt,
+		&i.FundingDate,
+		&i.VerificationOutcome,
+		&i.DataRegion,
+		&i.ThreeDs,
+		&i.TranNbr,
+		&i.PendingExpiresAt,
+		&i.RequestHash,
+		&i.DailyVolumeDate,
+		&i.CardFingerprint,
+		&i.LastFour,
+	)
+	return i, err
+}
+
+const completePendingTransaction = `-- name: CompletePendingTransaction :one
+UPDATE transactions
+SET
+    status = $1,
+    payment_method_type = COALESCE($2, payment_method_type),
+    card_fingerprint = COALESCE($3, card_fingerprint),
+    last_four = COALESCE($4, last_four),
+    auth_guid = $5,
+    auth_resp = $6,
+    auth_code = $7,
+    auth_resp_text = $8,
+    auth_card_type = $9,
+    auth_avs = $10,
+    auth_cvv2 = $11,
+    card_funding_type = $12,
+    metadata = $13,
+    verification_outcome = $14,
+    tran_nbr = $15,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $16 AND status = 'pending'
+RETURNING id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four
+`
+
+type CompletePendingTransactionParams struct {
+	Status              string      `json:"status"`
+	PaymentMethodType   pgtype.Text `json:"payment_method_type"`
+	CardFingerprint     pgtype.Text `json:"card_fingerprint"`
+	LastFour            pgtype.Text `json:"last_four"`
+	AuthGuid            pgtype.Text `json:"auth_guid"`
+	AuthResp            pgtype.Text `json:"auth_resp"`
+	AuthCode            pgtype.Text `json:"auth_code"`
+	AuthRespText        pgtype.Text `json:"auth_resp_text"`
+	AuthCardType        pgtype.Text `json:"auth_card_type"`
+	AuthAvs             pgtype.Text `json:"auth_avs"`
+	AuthCvv2            pgtype.Text `json:"auth_cvv2"`
+	CardFundingType     pgtype.Text `json:"card_funding_type"`
+	Metadata            []byte      `json:"metadata"`
+	VerificationOutcome []byte      `json:"verification_outcome"`
+	TranNbr             pgtype.Int8 `json:"tran_nbr"`
+	ID                  uuid.UUID   `json:"id"`
+}
+
+// Records the EPX result on the pending row that reserved the request's idempotency key (or a Browser Post form).
+// A NULL payment_method_type, card_fingerprint or last_four keeps the row's.
+func (q *Queries) CompletePendingTransaction(ctx context.Context, arg CompletePendingTransactionParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, completePendingTransaction,
+		arg.Status,
+		arg.PaymentMethodType,
+		arg.CardFingerprint,
+		arg.LastFour,
+		arg.AuthGuid,
+		arg.AuthResp,
+		arg.AuthCode,
+		arg.AuthRespText,
+		arg.AuthCardType,
+		arg.AuthAvs,
+		arg.AuthCvv2,
+		arg.CardFundingType,
+		arg.Metadata,
+		arg.VerificationOutcome,
+		arg.TranNbr,
+		arg.ID,
+	)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.Amount,
+		&i.Currency,
+		&i.Status,
+		&i.Type,
+		&i.PaymentMethodType,
+		&i.PaymentMethodID,
+		&i.AuthGuid,
+		&i.AuthResp,
+		&i.AuthCode,
+		&i.AuthRespText,
+		&i.AuthCardType,
+		&i.AuthAvs,
+		&i.AuthCvv2,
+		&i.IdempotencyKey,
+		&i.Metadata,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExternalReferenceID,
+		&i.ReturnUrl,
+		&i.CardFundingType,
+		&i.SettledAt,
+		&i.FundingDate,
+		&i.VerificationOutcome,
+		&i.DataRegion,
+		&i.ThreeDs,
+		&i.TranNbr,
+		&i.PendingExpiresAt,
+		&i.RequestHash,
+		&i.DailyVolumeDate,
+		&i.CardFingerprint,
+		&i.LastFour,
+	)
+	return i, err
+}
+
+const countSubscriptionTransactions = `-- name: CountSubscriptionTransactions :one
+SELECT COUNT(*) FROM transactions
+WHERE group_id IN (
+    SELECT t.group_id FROM transactions t
+    WHERE t.metadata->>'subscription_id' = $1::text
+)
+`
+
+func (q *Queries) CountSubscriptionTransactions(ctx context.Context, subscriptionID string) (int64, error) {
+	row := q.db.QueryRow(ctx, countSubscriptionTransactions, subscriptionID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countTransactions = `-- name: CountTransactions :one
 SELECT COUNT(*) FROM transactions
 WHERE
@@ -20,16 +210,26 @@ WHERE
     ($3::uuid IS NULL OR group_id = $3) AND
     ($4::varchar IS NULL OR status = $4) AND
     ($5::varchar IS NULL OR type = $5) AND
-    ($6::uuid IS NULL OR payment_method_id = $6)
+    ($6::uuid IS NULL OR payment_method_id = $6) AND
+    ($7::jsonb IS NULL OR metadata @> $7::jsonb) AND
+    ($8::numeric IS NULL OR amount >= $8::numeric) AND
+    ($9::numeric IS NULL OR amount <= $9::numeric) AND
+    ($10::timestamptz IS NULL OR created_at >= $10::timestamptz) AND
+    ($11::timestamptz IS NULL OR created_at < $11::timestamptz)
 `
 
 type CountTransactionsParams struct {
-	AgentID         pgtype.Text `json:"agent_id"`
-	CustomerID      pgtype.Text `json:"customer_id"`
-	GroupID         pgtype.UUID `json:"group_id"`
-	Status          pgtype.Text `json:"status"`
-	Type            pgtype.Text `json:"type"`
-	PaymentMethodID pgtype.UUID `json:"payment_method_id"`
+	AgentID          pgtype.Text        `json:"agent_id"`
+	CustomerID       pgtype.Text        `json:"customer_id"`
+	GroupID          pgtype.UUID        `json:"group_id"`
+	Status           pgtype.Text        `json:"status"`
+	Type             pgtype.Text        `json:"type"`
+	PaymentMethodID  pgtype.UUID        `json:"payment_method_id"`
+	MetadataContains []byte             `json:"metadata_contains"`
+	MinAmount        pgtype.Numeric     `json:"min_amount"`
+	MaxAmount        pgtype.Numeric     `json:"max_amount"`
+	CreatedFrom      pgtype.Timestamptz `json:"created_from"`
+	CreatedTo        pgtype.Timestamptz `json:"created_to"`
 }
 
 func (q *Queries) CountTransactions(ctx context.Context, arg CountTransactionsParams) (int64, error) {
@@ -40,6 +240,11 @@ func (q *Queries) CountTransactions(ctx context.Context, arg CountTransactionsPa
 		arg.Status,
 		arg.Type,
 		arg.PaymentMethodID,
+		arg.MetadataContains,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CreatedFrom,
+		arg.CreatedTo,
 	)
 	var count int64
 	err := row.Scan(&count)
@@ -51,37 +256,47 @@ INSERT INTO transactions (
     id, group_id, agent_id, customer_id,
     amount, currency, status, type, payment_method_type, payment_method_id,
     auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2,
-    idempotency_key, metadata
+    card_funding_type, idempotency_key, request_hash, metadata, verification_outcome, three_ds, tran_nbr, pending_expires_at, daily_volume_date, card_fingerprint, data_region
 ) VALUES (
     $1, $2, $3, $4,
     $5, $6, $7, $8, $9, $10,
     $11, $12, $13, $14, $15, $16, $17,
-    $18, $19
-) RETURNING id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at
+    $18, $19, $20, $21, $22, $23, $24, $25, $26, $27,
+    COALESCE((SELECT ac.data_region FROM agent_credentials ac WHERE ac.agent_id = $3), 'us')
+) RETURNING id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four
 `
 
 type CreateTransactionParams struct {
-	ID                uuid.UUID      `json:"id"`
-	GroupID           uuid.UUID      `json:"group_id"`
-	AgentID           string         `json:"agent_id"`
-	CustomerID        pgtype.Text    `json:"customer_id"`
-	Amount            pgtype.Numeric `json:"amount"`
-	Currency          string         `json:"currency"`
-	Status            string         `json:"status"`
-	Type              string         `json:"type"`
-	PaymentMethodType string         `json:"payment_method_type"`
-	PaymentMethodID   pgtype.UUID    `json:"payment_method_id"`
-	AuthGuid          pgtype.Text    `json:"auth_guid"`
-	AuthResp          pgtype.Text    `json:"auth_resp"`
-	AuthCode          pgtype.Text    `json:"auth_code"`
-	AuthRespText      pgtype.Text    `json:"auth_resp_text"`
-	AuthCardType      pgtype.Text    `json:"auth_card_type"`
-	AuthAvs           pgtype.Text    `json:"auth_avs"`
-	AuthCvv2          pgtype.Text    `json:"auth_cvv2"`
-	IdempotencyKey    pgtype.Text    `json:"idempotency_key"`
-	Metadata          []byte         `json:"metadata"`
+	ID                  uuid.UUID          `json:"id"`
+	GroupID             uuid.UUID          `json:"group_id"`
+	AgentID             string             `json:"agent_id"`
+	CustomerID          pgtype.Text        `json:"customer_id"`
+	Amount              pgtype.Numeric     `json:"amount"`
+	Currency            string             `json:"currency"`
+	Status              string             `json:"status"`
+	Type                string             `json:"type"`
+	PaymentMethodType   string             `json:"payment_method_type"`
+	PaymentMethodID     pgtype.UUID        `json:"payment_method_id"`
+	AuthGuid            pgtype.Text        `json:"auth_guid"`
+	AuthResp            pgtype.Text        `json:"auth_resp"`
+	AuthCode            pgtype.Text        `json:"auth_code"`
+	AuthRespText        pgtype.Text        `json:"auth_resp_text"`
+	AuthCardType        pgtype.Text        `json:"auth_card_type"`
+	AuthAvs             pgtype.Text        `json:"auth_avs"`
+	AuthCvv2            pgtype.Text        `json:"auth_cvv2"`
+	CardFundingType     pgtype.Text        `json:"card_funding_type"`
+	IdempotencyKey      pgtype.Text        `json:"idempotency_key"`
+	RequestHash         pgtype.Text        `json:"request_hash"`
+	Metadata            []byte             `json:"metadata"`
+	VerificationOutcome []byte             `json:"verification_outcome"`
+	ThreeDs             []byte             `json:"three_ds"`
+	TranNbr             pgtype.Int8        `json:"tran_nbr"`
+	PendingExpiresAt    pgtype.Timestamptz `json:"pending_expires_at"`
+	DailyVolumeDate     pgtype.Date        `json:"daily_volume_date"`
+	CardFingerprint     pgtype.Text        `json:"card_fingerprint"`
 }
 
+// data_region is stamped from the merchant so region-scoped exports/purges don't depend on callers
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
 	row := q.db.QueryRow(ctx, createTransaction,
 		arg.ID,
@@ -101,8 +316,16 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.AuthCardType,
 		arg.AuthAvs,
 		arg.AuthCvv2,
+		arg.CardFundingType,
 		arg.IdempotencyKey,
+		arg.RequestHash,
 		arg.Metadata,
+		arg.VerificationOutcome,
+		arg.ThreeDs,
+		arg.TranNbr,
+		arg.PendingExpiresAt,
+		arg.DailyVolumeDate,
+		arg.CardFingerprint,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -128,88 +351,86 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExternalReferenceID,
+		&i.ReturnUrl,
+		&i.CardFundingType,
+		&i.SettledAt,
+		&i.FundingDate,
+		&i.VerificationOutcome,
+		&i.DataRegion,
+		&i.ThreeDs,
+		&i.TranNbr,
+		&i.PendingExpiresAt,
+		&i.RequestHash,
+		&i.DailyVolumeDate,
+		&i.CardFingerprint,
+		&i.LastFour,
 	)
 	return i, err
 }
 
-const getTransactionByID = `-- name: GetTransactionByID :one
-SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at FROM transactions
-WHERE id = $1
+const setPendingDailyVolume = `-- name: SetPendingDailyVolume :exec
+UPDATE transactions
+SET daily_volume_date = $1
+WHERE id = $2 AND status = 'pending'
 `
 
-func (q *Queries) GetTransactionByID(ctx context.Context, id uuid.UUID) (Transaction, error) {
-	row := q.db.QueryRow(ctx, getTransactionByID, id)
-	var i Transaction
-	err := row.Scan(
-		&i.ID,
-		&i.GroupID,
-		&i.AgentID,
-		&i.CustomerID,
-		&i.Amount,
-		&i.Currency,
-		&i.Status,
-		&i.Type,
-		&i.PaymentMethodType,
-		&i.PaymentMethodID,
-		&i.AuthGuid,
-		&i.AuthResp,
-		&i.AuthCode,
-		&i.AuthRespText,
-		&i.AuthCardType,
-		&i.AuthAvs,
-		&i.AuthCvv2,
-		&i.IdempotencyKey,
-		&i.Metadata,
-		&i.DeletedAt,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+type SetPendingDailyVolumeParams struct {
+	DailyVolumeDate pgtype.Date `json:"daily_volume_date"`
+	ID              uuid.UUID   `json:"id"`
 }
 
-const getTransactionByIdempotencyKey = `-- name: GetTransactionByIdempotencyKey :one
-SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at FROM transactions
-WHERE idempotency_key = $1
+// Records the UTC day of the daily volume a pending row's request holds: NULL once its hold is released (the
+// request never reached EPX), or the day of the hold a retry took over the row with
+func (q *Queries) SetPendingDailyVolume(ctx context.Context, arg SetPendingDailyVolumeParams) error {
+	_, err := q.db.Exec(ctx, setPendingDailyVolume, arg.DailyVolumeDate, arg.ID)
+	return err
+}
+
+const expirePendingTransaction = `-- name: ExpirePendingTransaction :exec
+UPDATE transactions
+SET
+    status = 'expired',
+    auth_guid = $1,
+    auth_resp = $2,
+    auth_resp_text = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $4 AND status = 'pending'
 `
 
-func (q *Queries) GetTransactionByIdempotencyKey(ctx context.Context, idempotencyKey pgtype.Text) (Transaction, error) {
-	row := q.db.QueryRow(ctx, getTransactionByIdempotencyKey, idempotencyKey)
-	var i Transaction
-	err := row.Scan(
-		&i.ID,
-		&i.GroupID,
-		&i.AgentID,
-		&i.CustomerID,
-		&i.Amount,
-		&i.Currency,
-		&i.Status,
-		&i.Type,
-		&i.PaymentMethodType,
-		&i.PaymentMethodID,
-		&i.AuthGuid,
-		&i.AuthResp,
-		&i.AuthCode,
-		&i.AuthRespText,
-		&i.AuthCardType,
-		&i.AuthAvs,
-		&i.AuthCvv2,
-		&i.IdempotencyKey,
-		&i.Metadata,
-		&i.DeletedAt,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+type ExpirePendingTransactionParams struct {
+	AuthGuid     pgtype.Text `json:"auth_guid"`
+	AuthResp     pgtype.Text `json:"auth_resp"`
+	AuthRespText pgtype.Text `json:"auth_resp_text"`
+	ID           uuid.UUID   `json:"id"`
+}
+
+// A pending Browser Post transaction whose callback came too late never gets a result. The late callback's BRIC
+// and response are kept on the expired row, so an approval EPX made anyway can be found and voided.
+func (q *Queries) ExpirePendingTransaction(ctx context.Context, arg ExpirePendingTransactionParams) error {
+	_, err := q.db.Exec(ctx, expirePendingTransaction,
+		arg.AuthGuid,
+		arg.AuthResp,
+		arg.AuthRespText,
+		arg.ID,
 	)
-	return i, err
+	return err
 }
 
-const getTransactionsByGroupID = `-- name: GetTransactionsByGroupID :many
-SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at FROM transactions
-WHERE group_id = $1
-ORDER BY created_at ASC
+const getAgentTransactionsByIDs = `-- name: GetAgentTransactionsByIDs :many
+SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four FROM transactions
+WHERE agent_id = $1
+  AND id = ANY($2::uuid[])
 `
 
-func (q *Queries) GetTransactionsByGroupID(ctx context.Context, groupID uuid.UUID) ([]Transaction, error) {
-	rows, err := q.db.Query(ctx, getTransactionsByGroupID, groupID)
+type GetAgentTransactionsByIDsParams struct {
+	AgentID string      `json:"agent_id"`
+	Ids     []uuid.UUID `json:"ids"`
+}
+
+// Scoped to the agent so other merchants' transactions are indistinguishable from missing ones
+func (q *Queries) GetAgentTransactionsByIDs(ctx context.Context, arg GetAgentTransactionsByIDsParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getAgentTransactionsByIDs, arg.AgentID, arg.Ids)
 	if err != nil {
 		return nil, err
 	}
@@ -240,6 +461,20 @@ func (q *Queries) GetTransactionsByGroupID(ctx context.Context, groupID uuid.UUI
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ExternalReferenceID,
+			&i.ReturnUrl,
+			&i.CardFundingType,
+			&i.SettledAt,
+			&i.FundingDate,
+			&i.VerificationOutcome,
+			&i.DataRegion,
+			&i.ThreeDs,
+			&i.TranNbr,
+			&i.PendingExpiresAt,
+			&i.RequestHash,
+			&i.DailyVolumeDate,
+			&i.CardFingerprint,
+			&i.LastFour,
 		); err != nil {
 			return nil, err
 		}
@@ -251,71 +486,79 @@ func (q *Queries) GetTransactionsByGroupID(ctx context.Context, groupID uuid.UUI
 	return items, nil
 }
 
-const listTransactions = `-- name: ListTransactions :many
-SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at FROM transactions
-WHERE
-    ($1::varchar IS NULL OR agent_id = $1) AND
-    ($2::varchar IS NULL OR customer_id = $2) AND
-    ($3::uuid IS NULL OR group_id = $3) AND
-    ($4::varchar IS NULL OR status = $4) AND
-    ($5::varchar IS NULL OR type = $5) AND
-    ($6::uuid IS NULL OR payment_method_id = $6)
-ORDER BY created_at DESC
-LIMIT $8 OFFSET $7
+const getGroupThreeDS = `-- name: GetGroupThreeDS :one
+SELECT three_ds FROM transactions
+WHERE group_id = $1 AND three_ds IS NOT NULL
+ORDER BY created_at
+LIMIT 1
 `
 
-type ListTransactionsParams struct {
-	AgentID         pgtype.Text `json:"agent_id"`
-	CustomerID      pgtype.Text `json:"customer_id"`
-	GroupID         pgtype.UUID `json:"group_id"`
-	Status          pgtype.Text `json:"status"`
-	Type            pgtype.Text `json:"type"`
-	PaymentMethodID pgtype.UUID `json:"payment_method_id"`
-	OffsetVal       int32       `json:"offset_val"`
-	LimitVal        int32       `json:"limit_val"`
+// The 3-D Secure result of the group's authorization, for chargeback evidence
+func (q *Queries) GetGroupThreeDS(ctx context.Context, groupID uuid.UUID) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getGroupThreeDS, groupID)
+	var three_ds []byte
+	err := row.Scan(&three_ds)
+	return three_ds, err
 }
 
-func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]Transaction, error) {
-	rows, err := q.db.Query(ctx, listTransactions,
-		arg.AgentID,
-		arg.CustomerID,
-		arg.GroupID,
-		arg.Status,
-		arg.Type,
-		arg.PaymentMethodID,
-		arg.OffsetVal,
-		arg.LimitVal,
-	)
+const getSettlementTotals = `-- name: GetSettlementTotals :many
+SELECT
+    (t.created_at AT TIME ZONE 'UTC')::date AS day,
+    t.currency,
+    (CASE
+        WHEN t.status = 'voided' THEN 'void'
+        WHEN t.type = 'refund' THEN 'refund'
+        ELSE 'sale'
+    END)::varchar AS kind,
+    COUNT(*)::bigint AS transaction_count,
+    COALESCE(SUM(t.amount), 0)::numeric AS total_amount
+FROM transactions t
+WHERE t.agent_id = $1
+  AND t.created_at >= $2
+  AND t.created_at < $3
+  AND (
+    (t.status = 'completed' AND t.type IN ('charge', 'capture', 'refund'))
+    OR (t.status = 'voided' AND EXISTS (
+        SELECT 1 FROM transactions c
+        WHERE c.group_id = t.group_id AND c.id <> t.id
+          AND c.status = 'completed' AND c.type IN ('charge', 'capture')
+    ))
+  )
+GROUP BY 1, 2, 3
+ORDER BY 1, 2, 3
+`
+
+type GetSettlementTotalsParams struct {
+	AgentID     string    `json:"agent_id"`
+	CreatedFrom time.Time `json:"created_from"`
+	CreatedTo   time.Time `json:"created_to"`
+}
+
+type GetSettlementTotalsRow struct {
+	Day              pgtype.Date    `json:"day"`
+	Currency         string         `json:"currency"`
+	Kind             string         `json:"kind"`
+	TransactionCount int64          `json:"transaction_count"`
+	TotalAmount      pgtype.Numeric `json:"total_amount"`
+}
+
+// Per UTC day, currency and kind: completed sales and captures ('sale'), completed refunds ('refund') and voids
+// of payments that had captured money ('void'; voiding an uncaptured authorization moves no money).
+func (q *Queries) GetSettlementTotals(ctx context.Context, arg GetSettlementTotalsParams) ([]GetSettlementTotalsRow, error) {
+	rows, err := q.db.Query(ctx, getSettlementTotals, arg.AgentID, arg.CreatedFrom, arg.CreatedTo)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Transaction{}
+	items := []GetSettlementTotalsRow{}
 	for rows.Next() {
-		var i Transaction
+		var i GetSettlementTotalsRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.GroupID,
-			&i.AgentID,
-			&i.CustomerID,
-			&i.Amount,
+			&i.Day,
 			&i.Currency,
-			&i.Status,
-			&i.Type,
-			&i.PaymentMethodType,
-			&i.PaymentMethodID,
-			&i.AuthGuid,
-			&i.AuthResp,
-			&i.AuthCode,
-			&i.AuthRespText,
-			&i.AuthCardType,
-			&i.AuthAvs,
-			&i.AuthCvv2,
-			&i.IdempotencyKey,
-			&i.Metadata,
-			&i.DeletedAt,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.Kind,
+			&i.TransactionCount,
+			&i.TotalAmount,
 		); err != nil {
 			return nil, err
 		}
@@ -327,6 +570,973 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 	return items, nil
 }
 
+const getTransactionByAuthGUID = `-- name: GetTransactionByAuthGUID :one
+SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four FROM transactions
+WHERE auth_guid = $1
+  AND deleted_at IS NULL
+ORDER BY created_at ASC
+LIMIT 1
+`
+
+// The transaction EPX returned this BRIC for (any merchant; callers check ownership)
+func (q *Queries) GetTransactionByAuthGUID(ctx context.Context, authGuid pgtype.Text) (Transaction, error) {
+	row := q.db.QueryRow(ctx, getTransactionByAuthGUID, authGuid)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.Amount,
+		&i.Currency,
+		&i.Status,
+		&i.Type,
+		&i.PaymentMethodType,
+		&i.PaymentMethodID,
+		&i.AuthGuid,
+		&i.AuthResp,
+		&i.AuthCode,
+		&i.AuthRespText,
+		&i.AuthCardType,
+		&i.AuthAvs,
+		&i.AuthCvv2,
+		&i.IdempotencyKey,
+		&i.Metadata,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExternalReferenceID,
+		&i.ReturnUrl,
+		&i.CardFundingType,
+		&i.SettledAt,
+		&i.FundingDate,
+		&i.VerificationOutcome,
+		&i.DataRegion,
+		&i.ThreeDs,
+		&i.TranNbr,
+		&i.PendingExpiresAt,
+		&i.RequestHash,
+		&i.DailyVolumeDate,
+		&i.CardFingerprint,
+		&i.LastFour,
+	)
+	return i, err
+}
+
+const getTransactionByID = `-- name: GetTransactionByID :one
+SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four FROM transactions
+WHERE id = $1
+`
+
+func (q *Queries) GetTransactionByID(ctx context.Context, id uuid.UUID) (Transaction, error) {
+	row := q.db.QueryRow(ctx, getTransactionByID, id)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.Amount,
+		&i.Currency,
+		&i.Status,
+		&i.Type,
+		&i.PaymentMethodType,
+		&i.PaymentMethodID,
+		&i.AuthGuid,
+		&i.AuthResp,
+		&i.AuthCode,
+		&i.AuthRespText,
+		&i.AuthCardType,
+		&i.AuthAvs,
+		&i.AuthCvv2,
+		&i.IdempotencyKey,
+		&i.Metadata,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExternalReferenceID,
+		&i.ReturnUrl,
+		&i.CardFundingType,
+		&i.SettledAt,
+		&i.FundingDate,
+		&i.VerificationOutcome,
+		&i.DataRegion,
+		&i.ThreeDs,
+		&i.TranNbr,
+		&i.PendingExpiresAt,
+		&i.RequestHash,
+		&i.DailyVolumeDate,
+		&i.CardFingerprint,
+		&i.LastFour,
+	)
+	return i, err
+}
+
+const getTransactionByIdempotencyKey = `-- name: GetTransactionByIdempotencyKey :one
+SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four FROM transactions
+WHERE agent_id = $1
+  AND idempotency_key = $2
+`
+
+type GetTransactionByIdempotencyKeyParams struct {
+	AgentID        string      `json:"agent_id"`
+	IdempotencyKey pgtype.Text `json:"idempotency_key"`
+}
+
+// Keys are unique per merchant, so the same key can name different merchants' transactions
+func (q *Queries) GetTransactionByIdempotencyKey(ctx context.Context, arg GetTransactionByIdempotencyKeyParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, getTransactionByIdempotencyKey, arg.AgentID, arg.IdempotencyKey)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.Amount,
+		&i.Currency,
+		&i.Status,
+		&i.Type,
+		&i.PaymentMethodType,
+		&i.PaymentMethodID,
+		&i.AuthGuid,
+		&i.AuthResp,
+		&i.AuthCode,
+		&i.AuthRespText,
+		&i.AuthCardType,
+		&i.AuthAvs,
+		&i.AuthCvv2,
+		&i.IdempotencyKey,
+		&i.Metadata,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExternalReferenceID,
+		&i.ReturnUrl,
+		&i.CardFundingType,
+		&i.SettledAt,
+		&i.FundingDate,
+		&i.VerificationOutcome,
+		&i.DataRegion,
+		&i.ThreeDs,
+		&i.TranNbr,
+		&i.PendingExpiresAt,
+		&i.RequestHash,
+		&i.DailyVolumeDate,
+		&i.CardFingerprint,
+		&i.LastFour,
+	)
+	return i, err
+}
+
+const getTransactionByTranNbr = `-- name: GetTransactionByTranNbr :one
+SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four FROM transactions
+WHERE agent_id = $1
+  AND deleted_at IS NULL
+  AND (tran_nbr = $2::bigint
+       OR (tran_nbr IS NULL AND idempotency_key = $3::varchar))
+ORDER BY created_at ASC
+LIMIT 1
+`
+
+type GetTransactionByTranNbrParams struct {
+	AgentID    string `json:"agent_id"`
+	TranNbr    int64  `json:"tran_nbr"`
+	TranNbrKey string `json:"tran_nbr_key"`
+}
+
+// The merchant's transaction sent to EPX with this TRAN_NBR, including Browser Post callbacks (their form's
+// TRAN_NBR). Browser Post rows recorded before tran_nbr was stored for them match on their idempotency key.
+func (q *Queries) GetTransactionByTranNbr(ctx context.Context, arg GetTransactionByTranNbrParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, getTransactionByTranNbr, arg.AgentID, arg.TranNbr, arg.TranNbrKey)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.AgentID,
+		&i.CustomerID,
+		&i.Amount,
+		&i.Currency,
+		&i.Status,
+		&i.Type,
+		&i.PaymentMethodType,
+		&i.PaymentMethodID,
+		&i.AuthGuid,
+		&i.AuthResp,
+		&i.AuthCode,
+		&i.AuthRespText,
+		&i.AuthCardType,
+		&i.AuthAvs,
+		&i.AuthCvv2,
+		&i.IdempotencyKey,
+		&i.Metadata,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExternalReferenceID,
+		&i.ReturnUrl,
+		&i.CardFundingType,
+		&i.SettledAt,
+		&i.FundingDate,
+		&i.VerificationOutcome,
+		&i.DataRegion,
+		&i.ThreeDs,
+		&i.TranNbr,
+		&i.PendingExpiresAt,
+		&i.RequestHash,
+		&i.DailyVolumeDate,
+		&i.CardFingerprint,
+		&i.LastFour,
+	)
+	return i, err
+}
+
+const getTransactionVelocity = `-- name: GetTransactionVelocity :one
+SELECT
+    COUNT(*)::bigint AS transaction_count,
+    COALESCE(SUM(amount) FILTER (WHERE status <> 'failed'), 0)::numeric AS total_amount
+FROM transactions
+WHERE agent_id = $1
+  AND type IN ('charge', 'auth', 'increment')
+  AND created_at >= $2
+  AND deleted_at IS NULL
+  AND ($3::varchar IS NULL OR card_fingerprint = $3::varchar)
+  AND ($4::varchar IS NULL OR customer_id = $4::varchar)
+`
+
+type GetTransactionVelocityParams struct {
+	AgentID         string      `json:"agent_id"`
+	Since           time.Time   `json:"since"`
+	CardFingerprint pgtype.Text `json:"card_fingerprint"`
+	CustomerID      pgtype.Text `json:"customer_id"`
+}
+
+type GetTransactionVelocityRow struct {
+	TransactionCount int64          `json:"transaction_count"`
+	TotalAmount      pgtype.Numeric `json:"total_amount"`
+}
+
+// Sale, authorization and increment attempts by one card (card_fingerprint) or customer since a time, for
+// velocity limits. Every attempt counts, declines included; the amount only sums attempts that weren't declined.
+func (q *Queries) GetTransactionVelocity(ctx context.Context, arg GetTransactionVelocityParams) (GetTransactionVelocityRow, error) {
+	row := q.db.QueryRow(ctx, getTransactionVelocity,
+		arg.AgentID,
+		arg.Since,
+		arg.CardFingerprint,
+		arg.CustomerID,
+	)
+	var i GetTransactionVelocityRow
+	err := row.Scan(&i.TransactionCount, &i.TotalAmount)
+	return i, err
+}
+
+const getTransactionsByGroupID = `-- name: GetTransactionsByGroupID :many
+SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four FROM transactions
+WHERE group_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetTransactionsByGroupID(ctx context.Context, groupID uuid.UUID) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getTransactionsByGroupID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.Amount,
+			&i.Currency,
+			&i.Status,
+			&i.Type,
+			&i.PaymentMethodType,
+			&i.PaymentMethodID,
+			&i.AuthGuid,
+			&i.AuthResp,
+			&i.AuthCode,
+			&i.AuthRespText,
+			&i.AuthCardType,
+			&i.AuthAvs,
+			&i.AuthCvv2,
+			&i.IdempotencyKey,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExternalReferenceID,
+			&i.ReturnUrl,
+			&i.CardFundingType,
+			&i.SettledAt,
+			&i.FundingDate,
+			&i.VerificationOutcome,
+			&i.DataRegion,
+			&i.ThreeDs,
+			&i.TranNbr,
+			&i.PendingExpiresAt,
+			&i.RequestHash,
+			&i.DailyVolumeDate,
+			&i.CardFingerprint,
+			&i.LastFour,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionsByIDs = `-- name: GetTransactionsByIDs :many
+SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four FROM transactions
+WHERE id = ANY($1::uuid[])
+`
+
+// Unscoped: callers check each transaction's agent before returning it
+func (q *Queries) GetTransactionsByIDs(ctx context.Context, ids []uuid.UUID) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getTransactionsByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.Amount,
+			&i.Currency,
+			&i.Status,
+			&i.Type,
+			&i.PaymentMethodType,
+			&i.PaymentMethodID,
+			&i.AuthGuid,
+			&i.AuthResp,
+			&i.AuthCode,
+			&i.AuthRespText,
+			&i.AuthCardType,
+			&i.AuthAvs,
+			&i.AuthCvv2,
+			&i.IdempotencyKey,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExternalReferenceID,
+			&i.ReturnUrl,
+			&i.CardFundingType,
+			&i.SettledAt,
+			&i.FundingDate,
+			&i.VerificationOutcome,
+			&i.DataRegion,
+			&i.ThreeDs,
+			&i.TranNbr,
+			&i.PendingExpiresAt,
+			&i.RequestHash,
+			&i.DailyVolumeDate,
+			&i.CardFingerprint,
+			&i.LastFour,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubscriptionChargeAttempts = `-- name: ListSubscriptionChargeAttempts :many
+SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four FROM transactions
+WHERE metadata->>'subscription_id' = $1::text
+  AND agent_id = $2
+  AND type = 'charge'
+ORDER BY created_at ASC, id ASC
+`
+
+type ListSubscriptionChargeAttemptsParams struct {
+	SubscriptionID string `json:"subscription_id"`
+	AgentID        string `json:"agent_id"`
+}
+
+// Every billing attempt (approved and declined charges) of a merchant's subscription, oldest first
+func (q *Queries) ListSubscriptionChargeAttempts(ctx context.Context, arg ListSubscriptionChargeAttemptsParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listSubscriptionChargeAttempts, arg.SubscriptionID, arg.AgentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.Amount,
+			&i.Currency,
+			&i.Status,
+			&i.Type,
+			&i.PaymentMethodType,
+			&i.PaymentMethodID,
+			&i.AuthGuid,
+			&i.AuthResp,
+			&i.AuthCode,
+			&i.AuthRespText,
+			&i.AuthCardType,
+			&i.AuthAvs,
+			&i.AuthCvv2,
+			&i.IdempotencyKey,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExternalReferenceID,
+			&i.ReturnUrl,
+			&i.CardFundingType,
+			&i.SettledAt,
+			&i.FundingDate,
+			&i.VerificationOutcome,
+			&i.DataRegion,
+			&i.ThreeDs,
+			&i.TranNbr,
+			&i.PendingExpiresAt,
+			&i.RequestHash,
+			&i.DailyVolumeDate,
+			&i.CardFingerprint,
+			&i.LastFour,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubscriptionTransactions = `-- name: ListSubscriptionTransactions :many
+SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four FROM transactions
+WHERE group_id IN (
+    SELECT t.group_id FROM transactions t
+    WHERE t.metadata->>'subscription_id' = $1::text
+)
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListSubscriptionTransactionsParams struct {
+	SubscriptionID string `json:"subscription_id"`
+	OffsetVal      int32  `json:"offset_val"`
+	LimitVal       int32  `json:"limit_val"`
+}
+
+// Includes billing charges and any follow-up transactions (refunds, voids) in the same group
+func (q *Queries) ListSubscriptionTransactions(ctx context.Context, arg ListSubscriptionTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listSubscriptionTransactions, arg.SubscriptionID, arg.OffsetVal, arg.LimitVal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.Amount,
+			&i.Currency,
+			&i.Status,
+			&i.Type,
+			&i.PaymentMethodType,
+			&i.PaymentMethodID,
+			&i.AuthGuid,
+			&i.AuthResp,
+			&i.AuthCode,
+			&i.AuthRespText,
+			&i.AuthCardType,
+			&i.AuthAvs,
+			&i.AuthCvv2,
+			&i.IdempotencyKey,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExternalReferenceID,
+			&i.ReturnUrl,
+			&i.CardFundingType,
+			&i.SettledAt,
+			&i.FundingDate,
+			&i.VerificationOutcome,
+			&i.DataRegion,
+			&i.ThreeDs,
+			&i.TranNbr,
+			&i.PendingExpiresAt,
+			&i.RequestHash,
+			&i.DailyVolumeDate,
+			&i.CardFingerprint,
+			&i.LastFour,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactions = `-- name: ListTransactions :many
+SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four FROM transactions
+WHERE
+    ($1::varchar IS NULL OR agent_id = $1) AND
+    ($2::varchar IS NULL OR customer_id = $2) AND
+    ($3::uuid IS NULL OR group_id = $3) AND
+    ($4::varchar IS NULL OR status = $4) AND
+    ($5::varchar IS NULL OR type = $5) AND
+    ($6::uuid IS NULL OR payment_method_id = $6) AND
+    ($7::jsonb IS NULL OR metadata @> $7::jsonb) AND
+    ($8::numeric IS NULL OR amount >= $8::numeric) AND
+    ($9::numeric IS NULL OR amount <= $9::numeric) AND
+    ($10::timestamptz IS NULL OR created_at >= $10::timestamptz) AND
+    ($11::timestamptz IS NULL OR created_at < $11::timestamptz)
+ORDER BY created_at DESC, id DESC
+LIMIT $13 OFFSET $12
+`
+
+type ListTransactionsParams struct {
+	AgentID          pgtype.Text        `json:"agent_id"`
+	CustomerID       pgtype.Text        `json:"customer_id"`
+	GroupID          pgtype.UUID        `json:"group_id"`
+	Status           pgtype.Text        `json:"status"`
+	Type             pgtype.Text        `json:"type"`
+	PaymentMethodID  pgtype.UUID        `json:"payment_method_id"`
+	MetadataContains []byte             `json:"metadata_contains"`
+	MinAmount        pgtype.Numeric     `json:"min_amount"`
+	MaxAmount        pgtype.Numeric     `json:"max_amount"`
+	CreatedFrom      pgtype.Timestamptz `json:"created_from"`
+	CreatedTo        pgtype.Timestamptz `json:"created_to"`
+	OffsetVal        int32              `json:"offset_val"`
+	LimitVal         int32              `json:"limit_val"`
+}
+
+func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactions,
+		arg.AgentID,
+		arg.CustomerID,
+		arg.GroupID,
+		arg.Status,
+		arg.Type,
+		arg.PaymentMethodID,
+		arg.MetadataContains,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.OffsetVal,
+		arg.LimitVal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.Amount,
+			&i.Currency,
+			&i.Status,
+			&i.Type,
+			&i.PaymentMethodType,
+			&i.PaymentMethodID,
+			&i.AuthGuid,
+			&i.AuthResp,
+			&i.AuthCode,
+			&i.AuthRespText,
+			&i.AuthCardType,
+			&i.AuthAvs,
+			&i.AuthCvv2,
+			&i.IdempotencyKey,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExternalReferenceID,
+			&i.ReturnUrl,
+			&i.CardFundingType,
+			&i.SettledAt,
+			&i.FundingDate,
+			&i.VerificationOutcome,
+			&i.DataRegion,
+			&i.ThreeDs,
+			&i.TranNbr,
+			&i.PendingExpiresAt,
+			&i.RequestHash,
+			&i.DailyVolumeDate,
+			&i.CardFingerprint,
+			&i.LastFour,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsAfterCursor = `-- name: ListTransactionsAfterCursor :many
+SELECT id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four FROM transactions
+WHERE
+    agent_id = $1 AND
+    ($2::varchar IS NULL OR customer_id = $2) AND
+    ($3::jsonb IS NULL OR metadata @> $3::jsonb) AND
+    ($4::numeric IS NULL OR amount >= $4::numeric) AND
+    ($5::numeric IS NULL OR amount <= $5::numeric) AND
+    ($6::timestamptz IS NULL OR created_at >= $6::timestamptz) AND
+    ($7::timestamptz IS NULL OR created_at < $7::timestamptz) AND
+    ($8::timestamptz IS NULL OR
+        (created_at, id) < ($8::timestamptz, $9::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $10
+`
+
+type ListTransactionsAfterCursorParams struct {
+	AgentID          string             `json:"agent_id"`
+	CustomerID       pgtype.Text        `json:"customer_id"`
+	MetadataContains []byte             `json:"metadata_contains"`
+	MinAmount        pgtype.Numeric     `json:"min_amount"`
+	MaxAmount        pgtype.Numeric     `json:"max_amount"`
+	CreatedFrom      pgtype.Timestamptz `json:"created_from"`
+	CreatedTo        pgtype.Timestamptz `json:"created_to"`
+	CursorCreatedAt  pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID         pgtype.UUID        `json:"cursor_id"`
+	LimitVal         int32              `json:"limit_val"`
+}
+
+// Keyset pagination: newest first, strictly older than the (created_at, id) cursor (NULL = first page).
+// Stable under concurrent inserts, unlike OFFSET.
+func (q *Queries) ListTransactionsAfterCursor(ctx context.Context, arg ListTransactionsAfterCursorParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsAfterCursor,
+		arg.AgentID,
+		arg.CustomerID,
+		arg.MetadataContains,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.LimitVal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.Amount,
+			&i.Currency,
+			&i.Status,
+			&i.Type,
+			&i.PaymentMethodType,
+			&i.PaymentMethodID,
+			&i.AuthGuid,
+			&i.AuthResp,
+			&i.AuthCode,
+			&i.AuthRespText,
+			&i.AuthCardType,
+			&i.AuthAvs,
+			&i.AuthCvv2,
+			&i.IdempotencyKey,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExternalReferenceID,
+			&i.ReturnUrl,
+			&i.CardFundingType,
+			&i.SettledAt,
+			&i.FundingDate,
+			&i.VerificationOutcome,
+			&i.DataRegion,
+			&i.ThreeDs,
+			&i.TranNbr,
+			&i.PendingExpiresAt,
+			&i.RequestHash,
+			&i.DailyVolumeDate,
+			&i.CardFingerprint,
+			&i.LastFour,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsForReconciliation = `-- name: ListTransactionsForReconciliation :many
+SELECT t.id, t.group_id, t.agent_id, t.customer_id, t.amount, t.currency, t.status, t.type, t.payment_method_type, t.payment_method_id, t.auth_guid, t.auth_resp, t.auth_code, t.auth_resp_text, t.auth_card_type, t.auth_avs, t.auth_cvv2, t.idempotency_key, t.metadata, t.deleted_at, t.created_at, t.updated_at, t.external_reference_id, t.return_url, t.card_funding_type, t.settled_at, t.funding_date, t.verification_outcome, t.data_region, t.three_ds, t.tran_nbr, t.pending_expires_at, t.request_hash, t.daily_volume_date, t.card_fingerprint, t.last_four,
+    EXISTS (
+        SELECT 1 FROM transactions v
+        WHERE v.group_id = t.group_id AND v.status = 'voided'
+    )::boolean AS voided_in_group
+FROM transactions t
+WHERE t.agent_id = $1
+  AND t.type IN ('charge', 'capture', 'refund')
+  AND t.auth_guid IS NOT NULL
+  AND t.created_at >= $2
+  AND t.created_at < $3
+ORDER BY t.created_at ASC
+`
+
+type ListTransactionsForReconciliationParams struct {
+	AgentID     string    `json:"agent_id"`
+	CreatedFrom time.Time `json:"created_from"`
+	CreatedTo   time.Time `json:"created_to"`
+}
+
+type ListTransactionsForReconciliationRow struct {
+	ID                  uuid.UUID          `json:"id"`
+	GroupID             uuid.UUID          `json:"group_id"`
+	AgentID             string             `json:"agent_id"`
+	CustomerID          pgtype.Text        `json:"customer_id"`
+	Amount              pgtype.Numeric     `json:"amount"`
+	Currency            string             `json:"currency"`
+	Status              string             `json:"status"`
+	Type                string             `json:"type"`
+	PaymentMethodType   string             `json:"payment_method_type"`
+	PaymentMethodID     pgtype.UUID        `json:"payment_method_id"`
+	AuthGuid            pgtype.Text        `json:"auth_guid"`
+	AuthResp            pgtype.Text        `json:"auth_resp"`
+	AuthCode            pgtype.Text        `json:"auth_code"`
+	AuthRespText        pgtype.Text        `json:"auth_resp_text"`
+	AuthCardType        pgtype.Text        `json:"auth_card_type"`
+	AuthAvs             pgtype.Text        `json:"auth_avs"`
+	AuthCvv2            pgtype.Text        `json:"auth_cvv2"`
+	IdempotencyKey      pgtype.Text        `json:"idempotency_key"`
+	Metadata            []byte             `json:"metadata"`
+	DeletedAt           pgtype.Timestamptz `json:"deleted_at"`
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+	ExternalReferenceID pgtype.Text        `json:"external_reference_id"`
+	ReturnUrl           pgtype.Text        `json:"return_url"`
+	CardFundingType     pgtype.Text        `json:"card_funding_type"`
+	SettledAt           pgtype.Timestamptz `json:"settled_at"`
+	FundingDate         pgtype.Date        `json:"funding_date"`
+	VerificationOutcome []byte             `json:"verification_outcome"`
+	DataRegion          string             `json:"data_region"`
+	ThreeDs             []byte             `json:"three_ds"`
+	TranNbr             pgtype.Int8        `json:"tran_nbr"`
+	PendingExpiresAt    pgtype.Timestamptz `json:"pending_expires_at"`
+	RequestHash         pgtype.Text        `json:"request_hash"`
+	DailyVolumeDate     pgtype.Date        `json:"daily_volume_date"`
+	CardFingerprint     pgtype.Text        `json:"card_fingerprint"`
+	LastFour            pgtype.Text        `json:"last_four"`
+	VoidedInGroup       bool               `json:"voided_in_group"`
+}
+
+// Settleable transactions (sales, captures, refunds) with an EPX token in the date range.
+// voided_in_group flags transactions whose group was voided (never expected to settle).
+func (q *Queries) ListTransactionsForReconciliation(ctx context.Context, arg ListTransactionsForReconciliationParams) ([]ListTransactionsForReconciliationRow, error) {
+	rows, err := q.db.Query(ctx, listTransactionsForReconciliation, arg.AgentID, arg.CreatedFrom, arg.CreatedTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransactionsForReconciliationRow{}
+	for rows.Next() {
+		var i ListTransactionsForReconciliationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.Amount,
+			&i.Currency,
+			&i.Status,
+			&i.Type,
+			&i.PaymentMethodType,
+			&i.PaymentMethodID,
+			&i.AuthGuid,
+			&i.AuthResp,
+			&i.AuthCode,
+			&i.AuthRespText,
+			&i.AuthCardType,
+			&i.AuthAvs,
+			&i.AuthCvv2,
+			&i.IdempotencyKey,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExternalReferenceID,
+			&i.ReturnUrl,
+			&i.CardFundingType,
+			&i.SettledAt,
+			&i.FundingDate,
+			&i.VerificationOutcome,
+			&i.DataRegion,
+			&i.ThreeDs,
+			&i.TranNbr,
+			&i.PendingExpiresAt,
+			&i.RequestHash,
+			&i.DailyVolumeDate,
+			&i.CardFingerprint,
+			&i.LastFour,
+			&i.VoidedInGroup,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUncapturedAuthorizations = `-- name: ListUncapturedAuthorizations :many
+SELECT t.id, t.group_id, t.agent_id, t.customer_id, t.amount, t.currency, t.status, t.type, t.payment_method_type, t.payment_method_id, t.auth_guid, t.auth_resp, t.auth_code, t.auth_resp_text, t.auth_card_type, t.auth_avs, t.auth_cvv2, t.idempotency_key, t.metadata, t.deleted_at, t.created_at, t.updated_at, t.external_reference_id, t.return_url, t.card_funding_type, t.settled_at, t.funding_date, t.verification_outcome, t.data_region, t.three_ds, t.tran_nbr, t.pending_expires_at, t.request_hash, t.daily_volume_date, t.card_fingerprint, t.last_four FROM transactions t
+WHERE t.agent_id = $1
+  AND t.type = 'auth'
+  AND t.status = 'completed'
+  AND t.auth_guid IS NOT NULL
+  AND t.deleted_at IS NULL
+  AND t.created_at < $2
+  AND NOT EXISTS (
+      SELECT 1 FROM transactions o
+      WHERE o.group_id = t.group_id
+        AND (o.status = 'voided'
+             OR (o.type = 'capture' AND o.status = 'completed')
+             OR (o.type = 'increment' AND o.status = 'completed' AND o.created_at >= $2))
+  )
+ORDER BY t.created_at ASC
+LIMIT $3
+`
+
+type ListUncapturedAuthorizationsParams struct {
+	AgentID        string    `json:"agent_id"`
+	ApprovedBefore time.Time `json:"approved_before"`
+	LimitVal       int32     `json:"limit_val"`
+}
+
+// A merchant's approved authorizations last approved before the cutoff (the auth itself and any completed increment)
+// whose group has no completed capture and no void, oldest first. Candidates for the auth auto-void job.
+func (q *Queries) ListUncapturedAuthorizations(ctx context.Context, arg ListUncapturedAuthorizationsParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listUncapturedAuthorizations, arg.AgentID, arg.ApprovedBefore, arg.LimitVal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.AgentID,
+			&i.CustomerID,
+			&i.Amount,
+			&i.Currency,
+			&i.Status,
+			&i.Type,
+			&i.PaymentMethodType,
+			&i.PaymentMethodID,
+			&i.AuthGuid,
+			&i.AuthResp,
+			&i.AuthCode,
+			&i.AuthRespText,
+			&i.AuthCardType,
+			&i.AuthAvs,
+			&i.AuthCvv2,
+			&i.IdempotencyKey,
+			&i.Metadata,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExternalReferenceID,
+			&i.ReturnUrl,
+			&i.CardFundingType,
+			&i.SettledAt,
+			&i.FundingDate,
+			&i.VerificationOutcome,
+			&i.DataRegion,
+			&i.ThreeDs,
+			&i.TranNbr,
+			&i.PendingExpiresAt,
+			&i.RequestHash,
+			&i.DailyVolumeDate,
+			&i.CardFingerprint,
+			&i.LastFour,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockTransactionGroup = `-- name: LockTransactionGroup :exec
+SELECT pg_advisory_xact_lock(hashtextextended('transaction_group:' || $1::text, 0))
+`
+
+// Serializes the follow-ups that draw on a group's amounts until the transaction ends, so concurrent requests
+// can't each pass the group's checks against the same remaining amount
+func (q *Queries) LockTransactionGroup(ctx context.Context, groupID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, lockTransactionGroup, groupID)
+	return err
+}
+
+const markTransactionSettled = `-- name: MarkTransactionSettled :exec
+UPDATE transactions
+SET settled_at = $1, funding_date = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $3 AND settled_at IS NULL
+`
+
+type MarkTransactionSettledParams struct {
+	SettledAt   pgtype.Timestamptz `json:"settled_at"`
+	FundingDate pgtype.Date        `json:"funding_date"`
+	ID          uuid.UUID          `json:"id"`
+}
+
+func (q *Queries) MarkTransactionSettled(ctx context.Context, arg MarkTransactionSettledParams) error {
+	_, err := q.db.Exec(ctx, markTransactionSettled, arg.SettledAt, arg.FundingDate, arg.ID)
+	return err
+}
+
 const updateTransaction = `-- name: UpdateTransaction :one
 UPDATE transactions
 SET
@@ -336,7 +1546,7 @@ SET
     auth_resp_text = $4,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $5
-RETURNING id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at
+RETURNING id, group_id, agent_id, customer_id, amount, currency, status, type, payment_method_type, payment_method_id, auth_guid, auth_resp, auth_code, auth_resp_text, auth_card_type, auth_avs, auth_cvv2, idempotency_key, metadata, deleted_at, created_at, updated_at, external_reference_id, return_url, card_funding_type, settled_at, funding_date, verification_outcome, data_region, three_ds, tran_nbr, pending_expires_at, request_hash, daily_volume_date, card_fingerprint, last_four
 `
 
 type UpdateTransactionParams struct {
@@ -379,6 +1589,20 @@ func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionPa
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ExternalReferenceID,
+		&i.ReturnUrl,
+		&i.CardFundingType,
+		&i.SettledAt,
+		&i.FundingDate,
+		&i.VerificationOutcome,
+		&i.DataRegion,
+		&i.ThreeDs,
+		&i.TranNbr,
+		&i.PendingExpiresAt,
+		&i.RequestHash,
+		&i.DailyVolumeDate,
+		&i.CardFingerprint,
+		&i.LastFour,
 	)
 	return i, err
 }
@@ -8,11 +8,70 @@ package sqlc
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimWebhookBatchEvents = `-- name: ClaimWebhookBatchEvents :many
+DELETE FROM webhook_batch_events
+WHERE id IN (
+    SELECT id FROM webhook_batch_events
+    WHERE subscription_id = $1
+    ORDER BY id
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, subscription_id, event_type, payload, flush_at, created_at
+`
+
+type ClaimWebhookBatchEventsParams struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	LimitVal       int32     `json:"limit_val"`
+}
+
+// Takes up to limit_val of the subscription's queued events off the queue for sending. Events another instance
+// is taking are skipped, so each event is sent in one batch.
+func (q *Queries) ClaimWebhookBatchEvents(ctx context.Context, arg ClaimWebhookBatchEventsParams) ([]WebhookBatchEvent, error) {
+	rows, err := q.db.Query(ctx, claimWebhookBatchEvents, arg.SubscriptionID, arg.LimitVal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookBatchEvent{}
+	for rows.Next() {
+		var i WebhookBatchEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventType,
+			&i.Payload,
+			&i.FlushAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countWebhookBatchEvents = `-- name: CountWebhookBatchEvents :one
+SELECT COUNT(*) FROM webhook_batch_events
+WHERE subscription_id = $1
+`
+
+func (q *Queries) CountWebhookBatchEvents(ctx context.Context, subscriptionID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countWebhookBatchEvents, subscriptionID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
 INSERT INTO webhook_deliveries (
     subscription_id,
@@ -22,7 +81,10 @@ INSERT INTO webhook_deliveries (
     http_status_code,
     error_message,
     attempts,
-    next_retry_at
+    next_retry_at,
+    redelivery_of,
+    latency_ms,
+    failure_reason
 ) VALUES (
     $1,
     $2,
@@ -31,8 +93,11 @@ INSERT INTO webhook_deliveries (
     $5,
     $6,
     $7,
-    $8
-) RETURNING id, subscription_id, event_type, payload, status, http_status_code, error_message, attempts, next_retry_at, delivered_at, created_at
+    $8,
+    $9,
+    $10,
+    $11
+) RETURNING id, subscription_id, event_type, payload, status, http_status_code, error_message, attempts, next_retry_at, delivered_at, created_at, redelivery_of, latency_ms, failure_reason
 `
 
 type CreateWebhookDeliveryParams struct {
@@ -44,6 +109,9 @@ type CreateWebhookDeliveryParams struct {
 	ErrorMessage   pgtype.Text        `json:"error_message"`
 	Attempts       int32              `json:"attempts"`
 	NextRetryAt    pgtype.Timestamptz `json:"next_retry_at"`
+	RedeliveryOf   pgtype.UUID        `json:"redelivery_of"`
+	LatencyMs      pgtype.Int4        `json:"latency_ms"`
+	FailureReason  pgtype.Text        `json:"failure_reason"`
 }
 
 func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error) {
@@ -56,6 +124,9 @@ func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDe
 		arg.ErrorMessage,
 		arg.Attempts,
 		arg.NextRetryAt,
+		arg.RedeliveryOf,
+		arg.LatencyMs,
+		arg.FailureReason,
 	)
 	var i WebhookDelivery
 	err := row.Scan(
@@ -70,6 +141,9 @@ func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDe
 		&i.NextRetryAt,
 		&i.DeliveredAt,
 		&i.CreatedAt,
+		&i.RedeliveryOf,
+		&i.LatencyMs,
+		&i.FailureReason,
 	)
 	return i, err
 }
@@ -87,7 +161,7 @@ INSERT INTO webhook_subscriptions (
     $3,
     $4,
     $5
-) RETURNING id, agent_id, event_type, webhook_url, secret, is_active, created_at, updated_at
+) RETURNING id, agent_id, event_type, webhook_url, secret, is_active, created_at, updated_at, batch_size, batch_flush_interval_seconds
 `
 
 type CreateWebhookSubscriptionParams struct {
@@ -116,6 +190,8 @@ func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebho
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.BatchSize,
+		&i.BatchFlushIntervalSeconds,
 	)
 	return i, err
 }
@@ -135,8 +211,66 @@ func (q *Queries) DeleteWebhookSubscription(ctx context.Context, arg DeleteWebho
 	return err
 }
 
+const enqueueWebhookBatchEvent = `-- name: EnqueueWebhookBatchEvent :exec
+INSERT INTO webhook_batch_events (
+    subscription_id,
+    event_type,
+    payload,
+    flush_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+`
+
+type EnqueueWebhookBatchEventParams struct {
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	FlushAt        time.Time       `json:"flush_at"`
+}
+
+func (q *Queries) EnqueueWebhookBatchEvent(ctx context.Context, arg EnqueueWebhookBatchEventParams) error {
+	_, err := q.db.Exec(ctx, enqueueWebhookBatchEvent,
+		arg.SubscriptionID,
+		arg.EventType,
+		arg.Payload,
+		arg.FlushAt,
+	)
+	return err
+}
+
+const getWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT id, subscription_id, event_type, payload, status, http_status_code, error_message, attempts, next_retry_at, delivered_at, created_at, redelivery_of, latency_ms, failure_reason FROM webhook_deliveries
+WHERE id = $1
+`
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, id uuid.UUID) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, getWebhookDelivery, id)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.HttpStatusCode,
+		&i.ErrorMessage,
+		&i.Attempts,
+		&i.NextRetryAt,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+		&i.RedeliveryOf,
+		&i.LatencyMs,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
 const getWebhookDeliveryHistory = `-- name: GetWebhookDeliveryHistory :many
-SELECT id, subscription_id, event_type, payload, status, http_status_code, error_message, attempts, next_retry_at, delivered_at, created_at FROM webhook_deliveries
+SELECT id, subscription_id, event_type, payload, status, http_status_code, error_message, attempts, next_retry_at, delivered_at, created_at, redelivery_of, latency_ms, failure_reason FROM webhook_deliveries
 WHERE subscription_id = $1
 ORDER BY created_at DESC
 LIMIT $3
@@ -170,6 +304,68 @@ func (q *Queries) GetWebhookDeliveryHistory(ctx context.Context, arg GetWebhookD
 			&i.NextRetryAt,
 			&i.DeliveredAt,
 			&i.CreatedAt,
+			&i.RedeliveryOf,
+			&i.LatencyMs,
+			&i.FailureReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookDeliveryStats = `-- name: GetWebhookDeliveryStats :many
+SELECT
+    status,
+    (CASE
+        WHEN status = 'success' THEN ''
+        ELSE COALESCE(failure_reason, 'http_' || http_status_code::text, '')
+    END)::varchar AS failure_reason,
+    COUNT(*) AS deliveries,
+    COUNT(latency_ms) AS timed_deliveries,
+    COALESCE(SUM(latency_ms), 0)::bigint AS total_latency_ms
+FROM webhook_deliveries
+WHERE subscription_id = $1
+  AND created_at >= $2
+  AND created_at < $3
+GROUP BY 1, 2
+`
+
+type GetWebhookDeliveryStatsParams struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	CreatedFrom    time.Time `json:"created_from"`
+	CreatedTo      time.Time `json:"created_to"`
+}
+
+type GetWebhookDeliveryStatsRow struct {
+	Status          string `json:"status"`
+	FailureReason   string `json:"failure_reason"`
+	Deliveries      int64  `json:"deliveries"`
+	TimedDeliveries int64  `json:"timed_deliveries"`
+	TotalLatencyMs  int64  `json:"total_latency_ms"`
+}
+
+// Deliveries created within [created_from, created_to) grouped by status and failure reason. Rows from before
+// failure reasons were recorded fall back to their HTTP status.
+func (q *Queries) GetWebhookDeliveryStats(ctx context.Context, arg GetWebhookDeliveryStatsParams) ([]GetWebhookDeliveryStatsRow, error) {
+	rows, err := q.db.Query(ctx, getWebhookDeliveryStats, arg.SubscriptionID, arg.CreatedFrom, arg.CreatedTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetWebhookDeliveryStatsRow{}
+	for rows.Next() {
+		var i GetWebhookDeliveryStatsRow
+		if err := rows.Scan(
+			&i.Status,
+			&i.FailureReason,
+			&i.Deliveries,
+			&i.TimedDeliveries,
+			&i.TotalLatencyMs,
 		); err != nil {
 			return nil, err
 		}
@@ -182,7 +378,7 @@ func (q *Queries) GetWebhookDeliveryHistory(ctx context.Context, arg GetWebhookD
 }
 
 const getWebhookSubscription = `-- name: GetWebhookSubscription :one
-SELECT id, agent_id, event_type, webhook_url, secret, is_active, created_at, updated_at FROM webhook_subscriptions
+SELECT id, agent_id, event_type, webhook_url, secret, is_active, created_at, updated_at, batch_size, batch_flush_interval_seconds FROM webhook_subscriptions
 WHERE id = $1
 `
 
@@ -198,12 +394,14 @@ func (q *Queries) GetWebhookSubscription(ctx context.Context, id uuid.UUID) (Web
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.BatchSize,
+		&i.BatchFlushIntervalSeconds,
 	)
 	return i, err
 }
 
 const listActiveWebhooksByEvent = `-- name: ListActiveWebhooksByEvent :many
-SELECT id, agent_id, event_type, webhook_url, secret, is_active, created_at, updated_at FROM webhook_subscriptions
+SELECT id, agent_id, event_type, webhook_url, secret, is_active, created_at, updated_at, batch_size, batch_flush_interval_seconds FROM webhook_subscriptions
 WHERE agent_id = $1
   AND event_type = $2
   AND is_active = true
@@ -232,6 +430,150 @@ func (q *Queries) ListActiveWebhooksByEvent(ctx context.Context, arg ListActiveW
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.BatchSize,
+			&i.BatchFlushIntervalSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeadLetterWebhookDeliveries = `-- name: ListDeadLetterWebhookDeliveries :many
+SELECT d.id, d.subscription_id, d.event_type, d.payload, d.status, d.http_status_code, d.error_message, d.attempts, d.next_retry_at, d.delivered_at, d.created_at, d.redelivery_of, d.latency_ms, d.failure_reason FROM webhook_deliveries d
+JOIN webhook_subscriptions s ON s.id = d.subscription_id
+WHERE d.status = 'dead_letter'
+  AND ($1::varchar IS NULL OR s.agent_id = $1)
+  AND ($2::uuid IS NULL OR d.subscription_id = $2)
+ORDER BY d.created_at DESC
+LIMIT $4
+OFFSET $3
+`
+
+type ListDeadLetterWebhookDeliveriesParams struct {
+	AgentID        pgtype.Text `json:"agent_id"`
+	SubscriptionID pgtype.UUID `json:"subscription_id"`
+	OffsetVal      int32       `json:"offset_val"`
+	LimitVal       int32       `json:"limit_val"`
+}
+
+func (q *Queries) ListDeadLetterWebhookDeliveries(ctx context.Context, arg ListDeadLetterWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listDeadLetterWebhookDeliveries,
+		arg.AgentID,
+		arg.SubscriptionID,
+		arg.OffsetVal,
+		arg.LimitVal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDelivery{}
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.HttpStatusCode,
+			&i.ErrorMessage,
+			&i.Attempts,
+			&i.NextRetryAt,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+			&i.RedeliveryOf,
+			&i.LatencyMs,
+			&i.FailureReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueWebhookBatches = `-- name: ListDueWebhookBatches :many
+SELECT subscription_id FROM webhook_batch_events
+GROUP BY subscription_id
+HAVING MIN(flush_at) <= $1::timestamptz
+`
+
+// Subscriptions whose oldest queued event is past its flush time
+func (q *Queries) ListDueWebhookBatches(ctx context.Context, now pgtype.Timestamptz) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listDueWebhookBatches, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var subscription_id uuid.UUID
+		if err := rows.Scan(&subscription_id); err != nil {
+			return nil, err
+		}
+		items = append(items, subscription_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFailedWebhookDeliveries = `-- name: ListFailedWebhookDeliveries :many
+SELECT id, subscription_id, event_type, payload, status, http_status_code, error_message, attempts, next_retry_at, delivered_at, created_at, redelivery_of, latency_ms, failure_reason FROM webhook_deliveries
+WHERE subscription_id = $1
+  AND status IN ('failed', 'dead_letter')
+  AND created_at >= $2
+  AND created_at < $3
+ORDER BY created_at ASC
+LIMIT $4
+`
+
+type ListFailedWebhookDeliveriesParams struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	CreatedFrom    time.Time `json:"created_from"`
+	CreatedTo      time.Time `json:"created_to"`
+	LimitVal       int32     `json:"limit_val"`
+}
+
+func (q *Queries) ListFailedWebhookDeliveries(ctx context.Context, arg ListFailedWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listFailedWebhookDeliveries,
+		arg.SubscriptionID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.LimitVal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDelivery{}
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.HttpStatusCode,
+			&i.ErrorMessage,
+			&i.Attempts,
+			&i.NextRetryAt,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+			&i.RedeliveryOf,
+			&i.LatencyMs,
+			&i.FailureReason,
 		); err != nil {
 			return nil, err
 		}
@@ -244,7 +586,7 @@ func (q *Queries) ListActiveWebhooksByEvent(ctx context.Context, arg ListActiveW
 }
 
 const listPendingWebhookDeliveries = `-- name: ListPendingWebhookDeliveries :many
-SELECT id, subscription_id, event_type, payload, status, http_status_code, error_message, attempts, next_retry_at, delivered_at, created_at FROM webhook_deliveries
+SELECT id, subscription_id, event_type, payload, status, http_status_code, error_message, attempts, next_retry_at, delivered_at, created_at, redelivery_of, latency_ms, failure_reason FROM webhook_deliveries
 WHERE status = 'pending'
   AND next_retry_at <= CURRENT_TIMESTAMP
 ORDER BY next_retry_at ASC
@@ -272,6 +614,9 @@ func (q *Queries) ListPendingWebhookDeliveries(ctx context.Context, limitVal int
 			&i.NextRetryAt,
 			&i.DeliveredAt,
 			&i.CreatedAt,
+			&i.RedeliveryOf,
+			&i.LatencyMs,
+			&i.FailureReason,
 		); err != nil {
 			return nil, err
 		}
@@ -284,7 +629,7 @@ func (q *Queries) ListPendingWebhookDeliveries(ctx context.Context, limitVal int
 }
 
 const listWebhookSubscriptions = `-- name: ListWebhookSubscriptions :many
-SELECT id, agent_id, event_type, webhook_url, secret, is_active, created_at, updated_at FROM webhook_subscriptions
+SELECT id, agent_id, event_type, webhook_url, secret, is_active, created_at, updated_at, batch_size, batch_flush_interval_seconds FROM webhook_subscriptions
 WHERE agent_id = $1
   AND ($2::boolean IS NULL OR is_active = $2)
 ORDER BY created_at DESC
@@ -313,6 +658,8 @@ func (q *Queries) ListWebhookSubscriptions(ctx context.Context, arg ListWebhookS
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.BatchSize,
+			&i.BatchFlushIntervalSeconds,
 		); err != nil {
 			return nil, err
 		}
@@ -332,9 +679,11 @@ SET
     error_message = $3,
     attempts = $4,
     next_retry_at = $5,
-    delivered_at = $6
-WHERE id = $7
-RETURNING id, subscription_id, event_type, payload, status, http_status_code, error_message, attempts, next_retry_at, delivered_at, created_at
+    delivered_at = $6,
+    latency_ms = $7,
+    failure_reason = $8
+WHERE id = $9
+RETURNING id, subscription_id, event_type, payload, status, http_status_code, error_message, attempts, next_retry_at, delivered_at, created_at, redelivery_of, latency_ms, failure_reason
 `
 
 type UpdateWebhookDeliveryStatusParams struct {
@@ -344,6 +693,8 @@ type UpdateWebhookDeliveryStatusParams struct {
 	Attempts       int32              `json:"attempts"`
 	NextRetryAt    pgtype.Timestamptz `json:"next_retry_at"`
 	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	LatencyMs      pgtype.Int4        `json:"latency_ms"`
+	FailureReason  pgtype.Text        `json:"failure_reason"`
 	ID             uuid.UUID          `json:"id"`
 }
 
@@ -355,6 +706,8 @@ func (q *Queries) UpdateWebhookDeliveryStatus(ctx context.Context, arg UpdateWeb
 		arg.Attempts,
 		arg.NextRetryAt,
 		arg.DeliveredAt,
+		arg.LatencyMs,
+		arg.FailureReason,
 		arg.ID,
 	)
 	var i WebhookDelivery
@@ -370,6 +723,9 @@ func (q *Queries) UpdateWebhookDeliveryStatus(ctx context.Context, arg UpdateWeb
 		&i.NextRetryAt,
 		&i.DeliveredAt,
 		&i.CreatedAt,
+		&i.RedeliveryOf,
+		&i.LatencyMs,
+		&i.FailureReason,
 	)
 	return i, err
 }
@@ -378,25 +734,34 @@ const updateWebhookSubscription = `-- name: UpdateWebhookSubscription :one
 UPDATE webhook_subscriptions
 SET
     webhook_url = COALESCE($1, webhook_url),
-    secret = COALESCE($2, secret),
-    is_active = COALESCE($3, is_active),
+    event_type = COALESCE($2, event_type),
+    secret = COALESCE($3, secret),
+    is_active = COALESCE($4, is_active),
+    batch_size = COALESCE($5, batch_size),
+    batch_flush_interval_seconds = COALESCE($6, batch_flush_interval_seconds),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $4
-RETURNING id, agent_id, event_type, webhook_url, secret, is_active, created_at, updated_at
+WHERE id = $7
+RETURNING id, agent_id, event_type, webhook_url, secret, is_active, created_at, updated_at, batch_size, batch_flush_interval_seconds
 `
 
 type UpdateWebhookSubscriptionParams struct {
-	WebhookUrl pgtype.Text `json:"webhook_url"`
-	Secret     pgtype.Text `json:"secret"`
-	IsActive   pgtype.Bool `json:"is_active"`
-	ID         uuid.UUID   `json:"id"`
+	WebhookUrl                pgtype.Text `json:"webhook_url"`
+	EventType                 pgtype.Text `json:"event_type"`
+	Secret                    pgtype.Text `json:"secret"`
+	IsActive                  pgtype.Bool `json:"is_active"`
+	BatchSize                 pgtype.Int4 `json:"batch_size"`
+	BatchFlushIntervalSeconds pgtype.Int4 `json:"batch_flush_interval_seconds"`
+	ID                        uuid.UUID   `json:"id"`
 }
 
 func (q *Queries) UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error) {
 	row := q.db.QueryRow(ctx, updateWebhookSubscription,
 		arg.WebhookUrl,
+		arg.EventType,
 		arg.Secret,
 		arg.IsActive,
+		arg.BatchSize,
+		arg.BatchFlushIntervalSeconds,
 		arg.ID,
 	)
 	var i WebhookSubscription
@@ -409,6 +774,8 @@ func (q *Queries) UpdateWebhookSubscription(ctx context.Context, arg UpdateWebho
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.BatchSize,
+		&i.BatchFlushIntervalSeconds,
 	)
 	return i, err
 }
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ACHReturnAction is what happens to a payment method after an ACH return
+type ACHReturnAction string
+
+const (
+	// ACHReturnActionRetry keeps the payment method active; the debit may succeed later
+	ACHReturnActionRetry ACHReturnAction = "retry"
+	// ACHReturnActionDeactivate deactivates the payment method; retrying will keep failing
+	ACHReturnActionDeactivate ACHReturnAction = "deactivate"
+)
+
+// DeactivationReason records why a payment method was deactivated automatically
+type DeactivationReason string
+
+const (
+	DeactivationReasonAccountClosed        DeactivationReason = "account_closed"
+	DeactivationReasonNoAccount            DeactivationReason = "no_account"
+	DeactivationReasonInvalidAccount       DeactivationReason = "invalid_account"
+	DeactivationReasonAuthorizationRevoked DeactivationReason = "authorization_revoked"
+	DeactivationReasonAccountFrozen        DeactivationReason = "account_frozen"
+	DeactivationReasonExcessiveReturns     DeactivationReason = "excessive_returns"
+)
+
+// MaxRecoverableACHReturns is how many recoverable returns (e.g. repeated R01s)
+// a payment method may accumulate before it is deactivated
+const MaxRecoverableACHReturns = 3
+
+// ACHReturnCode describes a NACHA return code and how we react to it
+type ACHReturnCode struct {
+	Code        string
+	Description string
+	Action      ACHReturnAction
+	Reason      DeactivationReason // Empty for recoverable returns
+}
+
+// Recoverable returns true if the payment method stays usable after this return
+func (c ACHReturnCode) Recoverable() bool {
+	return c.Action == ACHReturnActionRetry
+}
+
+// achReturnCodes maps the return codes EPX reports to actions
+var achReturnCodes = map[string]ACHReturnCode{
+	"R01": {"R01", "Insufficient funds", ACHReturnActionRetry, ""},
+	"R02": {"R02", "Account closed", ACHReturnActionDeactivate, DeactivationReasonAccountClosed},
+	"R03": {"R03", "No account/unable to locate account", ACHReturnActionDeactivate, DeactivationReasonNoAccount},
+	"R04": {"R04", "Invalid account number", ACHReturnActionDeactivate, DeactivationReasonInvalidAccount},
+	"R05": {"R05", "Unauthorized debit to consumer account", ACHReturnActionDeactivate, DeactivationReasonAuthorizationRevoked},
+	"R07": {"R07", "Authorization revoked by customer", ACHReturnActionDeactivate, DeactivationReasonAuthorizationRevoked},
+	"R08": {"R08", "Payment stopped", ACHReturnActionRetry, ""},
+	"R09": {"R09", "Uncollected funds", ACHReturnActionRetry, ""},
+	"R10": {"R10", "Customer advises not authorized", ACHReturnActionDeactivate, DeactivationReasonAuthorizationRevoked},
+	"R16": {"R16", "Account frozen", ACHReturnActionDeactivate, DeactivationReasonAccountFrozen},
+	"R20": {"R20", "Non-transaction account", ACHReturnActionDeactivate, DeactivationReasonInvalidAccount},
+	"R29": {"R29", "Corporate customer advises not authorized", ACHReturnActionDeactivate, DeactivationReasonAuthorizationRevoked},
+}
+
+// LookupACHReturnCode returns the handling for a return code (case-insensitive)
+func LookupACHReturnCode(code string) (ACHReturnCode, error) {
+	rc, ok := achReturnCodes[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return ACHReturnCode{}, fmt.Errorf("%w: %q", ErrUnknownACHReturnCode, code)
+	}
+	return rc, nil
+}
+
+// ACHReturnOutcome is the result of applying a return to a payment method
+type ACHReturnOutcome struct {
+	ReturnCode  ACHReturnCode
+	ReturnCount int                 // Count including this return
+	Deactivate  bool                // Payment method must be deactivated
+	Reason      *DeactivationReason // Set when Deactivate is true
+}
+
+// EvaluateACHReturn decides what to do with a payment method that has already
+// seen returnCount returns and just received code. Unrecoverable codes deactivate
+// immediately; recoverable ones deactivate once MaxRecoverableACHReturns is reached.
+func EvaluateACHReturn(code string, returnCount int) (*ACHReturnOutcome, error) {
+	rc, err := LookupACHReturnCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &ACHReturnOutcome{
+		ReturnCode:  rc,
+		ReturnCount: returnCount + 1,
+	}
+
+	switch {
+	case !rc.Recoverable():
+		reason := rc.Reason
+		outcome.Deactivate = true
+		outcome.Reason = &reason
+	case outcome.ReturnCount >= MaxRecoverableACHReturns:
+		reason := DeactivationReasonExcessiveReturns
+		outcome.Deactivate = true
+		outcome.Reason = &reason
+	}
+
+	return outcome, nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateACHReturn(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           string
+		returnCount    int
+		wantCount      int
+		wantDeactivate bool
+		wantReason     DeactivationReason
+	}{
+		{"R01 insufficient funds is recoverable", "R01", 0, 1, false, ""},
+		{"R02 account closed deactivates", "R02", 0, 1, true, DeactivationReasonAccountClosed},
+		{"R03 no account deactivates", "R03", 0, 1, true, DeactivationReasonNoAccount},
+		{"R04 invalid account deactivates", "R04", 0, 1, true, DeactivationReasonInvalidAccount},
+		{"lowercase code is accepted", "r02", 0, 1, true, DeactivationReasonAccountClosed},
+		{"recoverable below threshold stays active", "R01", MaxRecoverableACHReturns - 2, MaxRecoverableACHReturns - 1, false, ""},
+		{"recoverable at threshold deactivates", "R01", MaxRecoverableACHReturns - 1, MaxRecoverableACHReturns, true, DeactivationReasonExcessiveReturns},
+		{"unrecoverable keeps its own reason past threshold", "R02", MaxRecoverableACHReturns, MaxRecoverableACHReturns + 1, true, DeactivationReasonAccountClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, err := EvaluateACHReturn(tt.code, tt.returnCount)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantCount, outcome.ReturnCount)
+			assert.Equal(t, tt.wantDeactivate, outcome.Deactivate)
+			if tt.wantDeactivate {
+				require.NotNil(t, outcome.Reason)
+				assert.Equal(t, tt.wantReason, *outcome.Reason)
+			} else {
+				assert.Nil(t, outcome.Reason)
+				assert.True(t, outcome.ReturnCode.Recoverable())
+			}
+		})
+	}
+}
+
+func TestEvaluateACHReturn_UnknownCode(t *testing.T) {
+	_, err := EvaluateACHReturn("R99", 0)
+	assert.ErrorIs(t, err, ErrUnknownACHReturnCode)
+}
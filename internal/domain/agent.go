@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -12,6 +13,37 @@ const (
 	EnvironmentProduction Environment = "production"
 )
 
+const (
+	// PreviousMACSuffix is appended to a merchant's MAC secret path to hold the MAC the last rotation replaced
+	PreviousMACSuffix = ".previous"
+	// PreviousMACGracePeriod is how long after a rotation callbacks signed with the replaced MAC are accepted
+	PreviousMACGracePeriod = 24 * time.Hour
+)
+
+// IsLive reports whether e is EPX production. Every other value ("sandbox", and the "test" and "staging"
+// used by older rows and deployments) means EPX's sandbox.
+func (e Environment) IsLive() bool {
+	return e == EnvironmentProduction
+}
+
+// CheckEPX returns ErrEnvironmentMismatch unless a merchant in environment e may be processed through EPX
+// adapters configured for epx. Production credentials must never reach the sandbox, nor sandbox ones production.
+func (e Environment) CheckEPX(epx Environment) error {
+	if e.IsLive() != epx.IsLive() {
+		return fmt.Errorf("%w: %s merchant can't be processed through %s EPX", ErrEnvironmentMismatch, e, epx)
+	}
+	return nil
+}
+
+// EPXEnvironmentFor returns the EPX environment a deployment (the ENVIRONMENT variable) talks to:
+// production for "production", the sandbox for anything else
+func EPXEnvironmentFor(deployment string) Environment {
+	if deployment == string(EnvironmentProduction) {
+		return EnvironmentProduction
+	}
+	return EnvironmentSandbox
+}
+
 // Agent represents a merchant/agent in the multi-tenant system
 // Agent credentials are stored securely with MAC secrets in a secret manager
 type Agent struct {
@@ -31,8 +63,26 @@ type Agent struct {
 	// Environment
 	Environment Environment `json:"environment"` // sandbox or production
 
-	// Status
-	IsActive bool `json:"is_active"`
+	// Data residency region; stamped on the transactions and payment methods written for this agent
+	DataRegion string `json:"data_region"`
+
+	// Status. IsActive is true only while Status is active.
+	IsActive     bool           `json:"is_active"`
+	Status       MerchantStatus `json:"status"`
+	StatusReason string         `json:"status_reason"` // Why the merchant was last suspended or closed
+	SuspendedAt  *time.Time     `json:"suspended_at"`
+	ClosedAt     *time.Time     `json:"closed_at"`
+
+	// Subscription policy
+	SubscriptionAmountChangeMinDays *int `json:"subscription_amount_change_min_days"` // NULL = unlimited
+
+	// Configuration (tier defaults + per-agent overrides)
+	Tier            MerchantTier             `json:"tier"`
+	ConfigOverrides *MerchantConfigOverrides `json:"config_overrides"`
+
+	// Self-service settings (the only fields a merchant can change through UpdateMerchantSettings)
+	StatementDescriptor string `json:"statement_descriptor"` // Empty = EPX default
+	NotificationEmail   string `json:"notification_email"`
 
 	// Additional metadata
 	Metadata map[string]interface{} `json:"metadata"` // Business name, contact info, etc.
@@ -42,6 +92,11 @@ type Agent struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// EffectiveConfig returns the agent's tier defaults merged with its overrides
+func (a *Agent) EffectiveConfig() *MerchantConfig {
+	return ResolveMerchantConfig(a.Tier, a.ConfigOverrides)
+}
+
 // IsSandbox returns true if this agent is using sandbox environment
 func (a *Agent) IsSandbox() bool {
 	return a.Environment == EnvironmentSandbox
@@ -63,14 +118,27 @@ func (a *Agent) GetMACSecretPath() string {
 	return a.MACSecretPath
 }
 
-// Deactivate marks the agent as inactive
+// PreviousMACPath returns where a MAC rotation keeps the MAC it replaced, so callbacks signed before the rotation
+// still verify during PreviousMACGracePeriod
+func PreviousMACPath(macSecretPath string) string {
+	return macSecretPath + PreviousMACSuffix
+}
+
+// AcceptsPreviousMAC reports whether a MAC replaced at rotatedAt still verifies callbacks at now
+func AcceptsPreviousMAC(rotatedAt, now time.Time) bool {
+	return !rotatedAt.IsZero() && now.Sub(rotatedAt) < PreviousMACGracePeriod
+}
+
+// Deactivate marks the agent as closed
 func (a *Agent) Deactivate() {
 	a.IsActive = false
+	a.Status = MerchantStatusClosed
 	a.UpdatedAt = time.Now()
 }
 
 // Activate marks the agent as active
 func (a *Agent) Activate() {
 	a.IsActive = true
+	a.Status = MerchantStatusActive
 	a.UpdatedAt = time.Now()
 }
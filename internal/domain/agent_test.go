@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvironmentCheckEPX(t *testing.T) {
+	assert.NoError(t, EnvironmentProduction.CheckEPX(EnvironmentProduction))
+	assert.NoError(t, EnvironmentSandbox.CheckEPX(EnvironmentSandbox))
+	assert.NoError(t, Environment("test").CheckEPX(EnvironmentSandbox), "older rows call the sandbox test")
+	assert.NoError(t, Environment("staging").CheckEPX(EnvironmentSandbox))
+
+	err := EnvironmentProduction.CheckEPX(EnvironmentSandbox)
+	assert.ErrorIs(t, err, ErrEnvironmentMismatch)
+	assert.EqualError(t, err, "merchant environment does not match EPX environment: production merchant can't be processed through sandbox EPX")
+	assert.ErrorIs(t, Environment("test").CheckEPX(EnvironmentProduction), ErrEnvironmentMismatch)
+}
+
+func TestEPXEnvironmentFor(t *testing.T) {
+	assert.Equal(t, EnvironmentProduction, EPXEnvironmentFor("production"))
+	for _, deployment := range []string{"development", "staging", "test", ""} {
+		assert.Equal(t, EnvironmentSandbox, EPXEnvironmentFor(deployment), deployment)
+	}
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ParseAmountToCents parses a dollar amount (e.g. "10.50") into cents without going through a float,
+// so "10.50" is always 1050 and never 1049. Sub-cent amounts are rounded to the nearest cent, half away from zero.
+func ParseAmountToCents(s string) (int64, error) {
+	amount, err := decimal.NewFromString(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	cents := amount.Shift(2).Round(0)
+	if cents.GreaterThan(decimal.NewFromInt(math.MaxInt64)) || cents.LessThan(decimal.NewFromInt(math.MinInt64)) {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, s)
+	}
+	return cents.IntPart(), nil
+}
+
+// FormatCents formats cents as a dollar amount with two decimal places (e.g. 1050 -> "10.50")
+func FormatCents(cents int64) string {
+	return decimal.New(cents, -2).StringFixed(2)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmountToCents(t *testing.T) {
+	// Values whose float64 * 100 truncates a cent short (10.50, 19.99, 0.29 ...)
+	for amount, want := range map[string]int64{
+		"10.50":     1050,
+		"0.01":      1,
+		"19.99":     1999,
+		"0.29":      29,
+		"1.15":      115,
+		"100":       10000,
+		"9999.99":   999999,
+		" 5.5 ":     550,
+		"0.00":      0,
+		"-12.34":    -1234,
+		"10.505":    1051,
+		"99.999999": 10000,
+		"0.004":     0,
+	} {
+		cents, err := ParseAmountToCents(amount)
+		require.NoError(t, err, amount)
+		assert.Equal(t, want, cents, amount)
+	}
+
+	for _, amount := range []string{"", "abc", "10.50abc", "1e30"} {
+		_, err := ParseAmountToCents(amount)
+		assert.ErrorIs(t, err, ErrInvalidAmount, amount)
+	}
+}
+
+func TestFormatCents(t *testing.T) {
+	assert.Equal(t, "10.50", FormatCents(1050))
+	assert.Equal(t, "0.01", FormatCents(1))
+	assert.Equal(t, "19.99", FormatCents(1999))
+
+	cents, err := ParseAmountToCents(FormatCents(1049))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1049), cents, "round-trips")
+}
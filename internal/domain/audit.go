@@ -0,0 +1,39 @@
+package domain
+
+import "encoding/json"
+
+// Audit actions recorded for payment mutations
+const (
+	AuditActionSale                   = "sale"
+	AuditActionAuthorize              = "authorize"
+	AuditActionCapture                = "capture"
+	AuditActionPartialReversal        = "partial_reversal"
+	AuditActionIncrementAuthorization = "increment_authorization"
+	AuditActionVoid                   = "void"
+	AuditActionRefund                 = "refund"
+)
+
+// Audit actions recorded for operator changes
+const (
+	AuditActionRotateMAC          = "rotate_mac"
+	AuditActionGrantAccess        = "grant_access"
+	AuditActionRevokeAccess       = "revoke_access"
+	AuditActionDeactivateService  = "deactivate_service"
+	AuditActionUpdateSettings     = "update_settings"
+	AuditActionSuspendMerchant    = "suspend_merchant"
+	AuditActionReactivateMerchant = "reactivate_merchant"
+	AuditActionCloseMerchant      = "close_merchant"
+	AuditActionSetFeatureFlag     = "set_feature_flag"
+)
+
+// AuditEntry is one audit_logs row. Changes and Metadata are already redacted and size-capped JSON.
+type AuditEntry struct {
+	EventType  string // e.g. "payment.sale"
+	EntityType string // e.g. "transaction"
+	EntityID   string
+	AgentID    string
+	Actor      string          // Calling service or user (audit_logs.user_id)
+	Action     string          // One of the AuditAction* values
+	Changes    json.RawMessage // Resulting state (audit_logs.after_state)
+	Metadata   json.RawMessage // Redacted request and outcome
+}
@@ -8,18 +8,27 @@ import (
 
 // Chargeback domain model represents dispute data synced from North API (read-only).
 // North does not provide write APIs for disputes - merchants must respond via North's web portal.
-// This service provides read-only access to dispute data for monitoring and webhook notifications.
+// This service stores evidence for that response and provides read-only access to dispute data for monitoring and webhook notifications.
 
 // ChargebackStatus represents the chargeback state
 type ChargebackStatus string
 
 const (
-	ChargebackStatusNew       ChargebackStatus = "new"       // Just received from North API
-	ChargebackStatusPending   ChargebackStatus = "pending"   // Under review
-	ChargebackStatusResponded ChargebackStatus = "responded" // Evidence submitted
-	ChargebackStatusWon       ChargebackStatus = "won"       // Merchant won the dispute
-	ChargebackStatusLost      ChargebackStatus = "lost"      // Merchant lost the dispute
-	ChargebackStatusAccepted  ChargebackStatus = "accepted"  // Merchant accepted the chargeback
+	ChargebackStatusNew         ChargebackStatus = "new"          // Just received from North API
+	ChargebackStatusPending     ChargebackStatus = "pending"      // Under review
+	ChargebackStatusResponded   ChargebackStatus = "responded"    // Evidence submitted
+	ChargebackStatusUnderReview ChargebackStatus = "under_review" // Reported by North while the issuer reviews the response
+	ChargebackStatusWon         ChargebackStatus = "won"          // Merchant won the dispute
+	ChargebackStatusLost        ChargebackStatus = "lost"         // Merchant lost the dispute
+	ChargebackStatusAccepted    ChargebackStatus = "accepted"     // Merchant accepted the chargeback
+)
+
+// ChargebackMatchStatus records whether dispute sync linked the chargeback to its transaction group
+type ChargebackMatchStatus string
+
+const (
+	ChargebackMatched   ChargebackMatchStatus = "matched"   // GroupID is set
+	ChargebackUnmatched ChargebackMatchStatus = "unmatched" // No confident match; needs manual review
 )
 
 // Chargeback represents a payment dispute/chargeback
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DiscountType defines how a coupon reduces the charge amount
+type DiscountType string
+
+const (
+	DiscountTypePercent DiscountType = "percent" // Percentage off the charge
+	DiscountTypeFixed   DiscountType = "fixed"   // Fixed amount off the charge
+)
+
+// CouponDuration defines how many charges a coupon applies to
+type CouponDuration string
+
+const (
+	CouponDurationOnce      CouponDuration = "once"      // First charge only
+	CouponDurationForever   CouponDuration = "forever"   // Every charge
+	CouponDurationRepeating CouponDuration = "repeating" // Charges within DurationInMonths of the first charge
+)
+
+// Coupon represents a merchant promotion applied to subscription charges
+type Coupon struct {
+	// Identity
+	ID string `json:"id"` // UUID
+
+	// Multi-tenant
+	AgentID string `json:"agent_id"`
+	Code    string `json:"code"`
+
+	// Discount (PercentOff for percent coupons, AmountOff for fixed coupons)
+	DiscountType DiscountType     `json:"discount_type"`
+	PercentOff   *decimal.Decimal `json:"percent_off"`
+	AmountOff    *decimal.Decimal `json:"amount_off"`
+	Currency     string           `json:"currency"`
+
+	// Duration
+	Duration         CouponDuration `json:"duration"`
+	DurationInMonths *int           `json:"duration_in_months"` // Only for repeating coupons
+
+	// Status
+	ExpiresAt *time.Time `json:"expires_at"`
+	IsActive  bool       `json:"is_active"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsExpired returns true if the coupon has passed its expiry time
+func (c *Coupon) IsExpired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
+}
+
+// IsRedeemable returns true if the coupon can be applied at the given time
+func (c *Coupon) IsRedeemable(now time.Time) bool {
+	return c.IsActive && !c.IsExpired(now)
+}
+
+// DiscountEndsAt returns the first billing date that is no longer discounted.
+// Only repeating coupons have an end date; once and forever coupons return nil.
+func (c *Coupon) DiscountEndsAt(firstBillingDate time.Time) *time.Time {
+	if c.Duration != CouponDurationRepeating || c.DurationInMonths == nil {
+		return nil
+	}
+	endsAt := firstBillingDate.AddDate(0, *c.DurationInMonths, 0)
+	return &endsAt
+}
+
+// AppliesToCharge returns true if the discount applies to a charge on billingDate,
+// given how many charges it has already been applied to and the discount end date
+func (c *Coupon) AppliesToCharge(billingDate time.Time, appliedCount int, endsAt *time.Time) bool {
+	switch c.Duration {
+	case CouponDurationOnce:
+		return appliedCount == 0
+	case CouponDurationForever:
+		return true
+	case CouponDurationRepeating:
+		return endsAt != nil && billingDate.Before(*endsAt)
+	default:
+		return false
+	}
+}
+
+// ApplyDiscount returns the charge amount after the discount, rounded to cents and never negative
+func (c *Coupon) ApplyDiscount(amount decimal.Decimal) decimal.Decimal {
+	var discounted decimal.Decimal
+
+	switch c.DiscountType {
+	case DiscountTypePercent:
+		if c.PercentOff == nil {
+			return amount
+		}
+		discount := amount.Mul(*c.PercentOff).Div(decimal.NewFromInt(100))
+		discounted = amount.Sub(discount)
+	case DiscountTypeFixed:
+		if c.AmountOff == nil {
+			return amount
+		}
+		discounted = amount.Sub(*c.AmountOff)
+	default:
+		return amount
+	}
+
+	if discounted.IsNegative() {
+		return decimal.Zero
+	}
+	return discounted.Round(2)
+}
@@ -0,0 +1,19 @@
+package domain
+
+// CustomerPolicy decides how a payment's customer_id is checked against the merchant's customer records
+type CustomerPolicy string
+
+const (
+	CustomerPolicyUnchecked       CustomerPolicy = ""                 // customer_id is an opaque reference and isn't looked up
+	CustomerPolicyAutoCreate      CustomerPolicy = "auto_create"      // An unknown customer_id creates a minimal customer record
+	CustomerPolicyRequireExisting CustomerPolicy = "require_existing" // An unknown customer_id rejects the payment
+)
+
+// IsValid returns true for a known policy (including unchecked)
+func (p CustomerPolicy) IsValid() bool {
+	switch p {
+	case CustomerPolicyUnchecked, CustomerPolicyAutoCreate, CustomerPolicyRequireExisting:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultDataRegion is the data region of merchants that haven't been assigned one
+const DefaultDataRegion = "us"
+
+// dataRegionPattern accepts lowercase region codes such as "us", "eu" or "ca-central"
+var dataRegionPattern = regexp.MustCompile(`^[a-z]{2}(-[a-z0-9]+)*$`)
+
+// ValidateDataRegion checks a merchant data residency region code
+func ValidateDataRegion(region string) error {
+	if len(region) > 32 || !dataRegionPattern.MatchString(region) {
+		return fmt.Errorf("%w: %q (expected a lowercase code such as \"us\" or \"eu\")", ErrInvalidDataRegion, region)
+	}
+	return nil
+}
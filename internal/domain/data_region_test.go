@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDataRegion(t *testing.T) {
+	tests := []struct {
+		region  string
+		wantErr bool
+	}{
+		{"us", false},
+		{"eu", false},
+		{"ca-central", false},
+		{"eu-west2", false},
+		{"", true},
+		{"EU", true},
+		{"u", true},
+		{"eu_west", true},
+		{"eu-", true},
+		{"us-aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			err := ValidateDataRegion(tt.region)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidDataRegion)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, ValidateDataRegion(DefaultDataRegion))
+}
@@ -1,16 +1,31 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Common domain errors
 var (
 	// Transaction errors
-	ErrTransactionNotFound         = errors.New("transaction not found")
-	ErrTransactionCannotBeVoided   = errors.New("transaction cannot be voided")
-	ErrTransactionCannotBeCaptured = errors.New("transaction cannot be captured")
-	ErrTransactionCannotBeRefunded = errors.New("transaction cannot be refunded")
-	ErrInvalidTransactionStatus    = errors.New("invalid transaction status")
-	ErrInvalidTransactionAmount    = errors.New("invalid transaction amount")
+	ErrTransactionNotFound            = errors.New("transaction not found")
+	ErrTransactionCannotBeVoided      = errors.New("transaction cannot be voided")
+	ErrTransactionCannotBeCaptured    = errors.New("transaction cannot be captured")
+	ErrTransactionCannotBeRefunded    = errors.New("transaction cannot be refunded")
+	ErrTransactionCannotBeReversed    = errors.New("authorization cannot be partially reversed")
+	ErrTransactionCannotBeIncremented = errors.New("authorization cannot be incremented")
+	ErrAmountExceedsAuthorization     = errors.New("amount exceeds the remaining authorization")
+	ErrAmountExceedsRefundable        = errors.New("amount exceeds the captured amount not yet refunded")
+	ErrInvalidTransactionStatus       = errors.New("invalid transaction status")
+	ErrInvalidTransactionAmount       = errors.New("invalid transaction amount")
+	ErrAmountOutOfRange               = errors.New("amount is outside the merchant's transaction amount range")
+	ErrPendingTransactionExpired      = errors.New("pending transaction expired before the callback arrived")
+	ErrTransactionAlreadyProcessed    = errors.New("transaction was already processed")
+	ErrTransactionNotSettled          = errors.New("transaction is not settled; void it instead of refunding")
+	ErrDailyVolumeLimitExceeded       = errors.New("daily volume limit exceeded")
+	ErrFraudDeclined                  = errors.New("transaction was declined by fraud screening")
+	ErrVelocityExceeded               = errors.New("velocity limit exceeded")
+	ErrChargesDisabled                = errors.New("new charges are disabled")
 
 	// Subscription errors
 	ErrSubscriptionNotFound         = errors.New("subscription not found")
@@ -18,25 +33,72 @@ var (
 	ErrSubscriptionAlreadyCancelled = errors.New("subscription is already cancelled")
 	ErrInvalidBillingInterval       = errors.New("invalid billing interval")
 	ErrMaxRetriesExceeded           = errors.New("max billing retries exceeded")
+	ErrAmountChangeTooSoon          = errors.New("subscription amount was changed too recently")
+
+	// Coupon errors
+	ErrCouponNotFound         = errors.New("coupon not found")
+	ErrCouponExpired          = errors.New("coupon is expired")
+	ErrCouponInactive         = errors.New("coupon is inactive")
+	ErrCouponCurrencyMismatch = errors.New("coupon currency does not match subscription currency")
 
 	// Payment method errors
-	ErrPaymentMethodNotFound    = errors.New("payment method not found")
-	ErrPaymentMethodExpired     = errors.New("payment method is expired")
-	ErrPaymentMethodNotVerified = errors.New("ACH payment method is not verified")
-	ErrPaymentMethodInactive    = errors.New("payment method is inactive")
-	ErrInvalidPaymentMethodType = errors.New("invalid payment method type")
+	ErrPaymentMethodNotFound        = errors.New("payment method not found")
+	ErrPaymentMethodExpired         = errors.New("payment method is expired")
+	ErrPaymentMethodNotVerified     = errors.New("ACH payment method is not verified")
+	ErrPaymentMethodInactive        = errors.New("payment method is inactive")
+	ErrInvalidPaymentMethodType     = errors.New("invalid payment method type")
+	ErrUnknownACHReturnCode         = errors.New("unknown ACH return code")
+	ErrInvalidCardExpiry            = errors.New("invalid card expiration date")
+	ErrDefaultPaymentMethodConflict = errors.New("another default payment method was set concurrently")
+	ErrSavedPaymentMethodLimit      = errors.New("customer has reached the saved payment method limit")
+
+	// Customer errors
+	ErrCustomerNotFound = errors.New("customer not found")
+
+	// Micro-deposit verification errors
+	ErrMicroDepositsNotInitiated = errors.New("micro-deposits have not been sent for this payment method")
+	ErrMicroDepositMismatch      = errors.New("micro-deposit amounts do not match")
+	ErrMicroDepositsLocked       = errors.New("micro-deposit verification is locked after too many failed attempts")
 
 	// Chargeback errors
 	ErrChargebackNotFound        = errors.New("chargeback not found")
 	ErrChargebackCannotRespond   = errors.New("cannot respond to chargeback (deadline passed or already responded)")
 	ErrChargebackAlreadyResolved = errors.New("chargeback is already resolved")
 	ErrInvalidChargebackStatus   = errors.New("invalid chargeback status")
+	ErrChargebackDeadlinePassed  = errors.New("chargeback response deadline has passed")
+	ErrInvalidChargebackEvidence = errors.New("invalid chargeback evidence")
+
+	// Webhook errors
+	ErrWebhookDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrWebhookSubscriptionExists   = errors.New("webhook subscription already exists")
+	ErrInvalidWebhookURL           = errors.New("invalid webhook URL")
+	ErrUnknownWebhookEventType     = errors.New("unknown webhook event type")
 
 	// Agent errors
-	ErrAgentNotFound      = errors.New("agent not found")
-	ErrAgentInactive      = errors.New("agent is inactive")
-	ErrAgentAlreadyExists = errors.New("agent already exists")
-	ErrInvalidEnvironment = errors.New("invalid environment")
+	ErrAgentNotFound         = errors.New("agent not found")
+	ErrAgentInactive         = errors.New("agent is inactive")
+	ErrAgentAlreadyExists    = errors.New("agent already exists")
+	ErrInvalidEnvironment    = errors.New("invalid environment")
+	ErrEnvironmentMismatch   = errors.New("merchant environment does not match EPX environment")
+	ErrInvalidDataRegion     = errors.New("invalid data region")
+	ErrMACRotationFailed     = errors.New("MAC rotation could not be confirmed")
+	ErrInvalidMerchantConfig = errors.New("stored merchant config overrides are invalid")
+
+	// Merchant lifecycle errors. Both wrap ErrAgentInactive, so existing inactive checks still match.
+	ErrMerchantSuspended = fmt.Errorf("merchant is suspended: %w", ErrAgentInactive)
+	ErrMerchantClosed    = fmt.Errorf("merchant is closed: %w", ErrAgentInactive)
+
+	ErrInvalidStatementDescriptor = errors.New("invalid statement descriptor")
+	ErrInvalidNotificationEmail   = errors.New("invalid notification email")
+
+	// Service (API caller) errors
+	ErrServiceNotFound      = errors.New("service not found")
+	ErrServiceAlreadyExists = errors.New("service already exists")
+	ErrServiceGrantNotFound = errors.New("service has no access to this merchant")
+	ErrServiceKeyNotFound   = errors.New("active service key not found")
+	ErrLastServiceKey       = errors.New("cannot retire the service's last active key")
+	ErrInvalidPublicKey     = errors.New("invalid public key")
 
 	// Gateway errors
 	ErrGatewayTimeout         = errors.New("gateway request timed out")
@@ -46,9 +108,18 @@ var (
 
 	// Idempotency errors
 	ErrDuplicateIdempotencyKey = errors.New("duplicate idempotency key")
+	ErrSaleBatchInProgress     = errors.New("a batch with this idempotency key is still being processed")
+	ErrIdempotencyKeyConflict  = errors.New("idempotency key was already used with different request parameters")
 
 	// Validation errors
 	ErrInvalidAmount        = errors.New("invalid amount")
 	ErrInvalidCurrency      = errors.New("invalid currency")
 	ErrMissingRequiredField = errors.New("missing required field")
+	ErrInvalidTimeRange     = errors.New("invalid time range")
+	ErrBatchTooLarge        = errors.New("batch size exceeds maximum")
+	ErrInvalidReturnURL     = errors.New("invalid return URL")
+	ErrInvalidCursor        = errors.New("invalid pagination cursor")
+	ErrInvalidFilter        = errors.New("invalid list filter")
+	ErrInvalidThreeDSecure  = errors.New("invalid 3-D Secure result")
+	ErrInvalidReference     = errors.New("invalid transaction reference")
 )
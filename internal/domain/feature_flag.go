@@ -0,0 +1,40 @@
+package domain
+
+import "fmt"
+
+// FeatureFlagChargesEnabled is the kill switch for new charges. Turned off, globally or for one merchant,
+// new charges (Sale, Authorize, IncrementAuthorization, subscription billing and Browser Post forms) fail with ErrChargesDisabled;
+// captures, refunds and voids of existing payments still go through.
+const FeatureFlagChargesEnabled = "charges_enabled"
+
+// FeatureFlag is an operator switch read on every request. A missing flag is on.
+type FeatureFlag struct {
+	Name    string
+	AgentID string // Empty for the global flag
+	Enabled bool
+	Reason  string // Why it was last changed, e.g. an incident reference
+}
+
+// IsGlobal returns true for the flag that applies to every merchant
+func (f FeatureFlag) IsGlobal() bool {
+	return f.AgentID == ""
+}
+
+// CheckChargesEnabled returns ErrChargesDisabled when any of flags, the global charges_enabled flag and the
+// merchant's own, is off. A merchant's flag can't turn charges back on while the global one is off.
+func CheckChargesEnabled(flags []FeatureFlag) error {
+	for _, flag := range flags {
+		if flag.Name != FeatureFlagChargesEnabled || flag.Enabled {
+			continue
+		}
+		scope := "for this merchant"
+		if flag.IsGlobal() {
+			scope = "for all merchants"
+		}
+		if flag.Reason != "" {
+			return fmt.Errorf("%w %s: %s", ErrChargesDisabled, scope, flag.Reason)
+		}
+		return fmt.Errorf("%w %s", ErrChargesDisabled, scope)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckChargesEnabled(t *testing.T) {
+	assert.NoError(t, CheckChargesEnabled(nil), "a missing flag is on")
+	assert.NoError(t, CheckChargesEnabled([]FeatureFlag{
+		{Name: FeatureFlagChargesEnabled, Enabled: true},
+		{Name: FeatureFlagChargesEnabled, AgentID: "merchant-1", Enabled: true},
+		{Name: "other_flag", Enabled: false},
+	}))
+
+	err := CheckChargesEnabled([]FeatureFlag{{Name: FeatureFlagChargesEnabled, Enabled: false, Reason: "incident 42"}})
+	assert.ErrorIs(t, err, ErrChargesDisabled)
+	assert.EqualError(t, err, "new charges are disabled for all merchants: incident 42")
+
+	err = CheckChargesEnabled([]FeatureFlag{
+		{Name: FeatureFlagChargesEnabled, Enabled: true},
+		{Name: FeatureFlagChargesEnabled, AgentID: "merchant-1", Enabled: false},
+	})
+	assert.EqualError(t, err, "new charges are disabled for this merchant")
+
+	err = CheckChargesEnabled([]FeatureFlag{
+		{Name: FeatureFlagChargesEnabled, Enabled: false},
+		{Name: FeatureFlagChargesEnabled, AgentID: "merchant-1", Enabled: true},
+	})
+	assert.ErrorIs(t, err, ErrChargesDisabled, "a merchant can't opt out of the global switch")
+}
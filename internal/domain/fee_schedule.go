@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// CardFundingType identifies how a card is funded (drives interchange category)
+type CardFundingType string
+
+const (
+	CardFundingTypeCredit  CardFundingType = "credit"
+	CardFundingTypeDebit   CardFundingType = "debit"
+	CardFundingTypePrepaid CardFundingType = "prepaid"
+)
+
+// ParseCardFundingType maps EPX's funding indicator ("C"/"D"/"P", or the spelled-out type) to a funding type;
+// anything else is unknown ("")
+func ParseCardFundingType(code string) CardFundingType {
+	switch strings.ToLower(strings.TrimSpace(code)) {
+	case "c", "credit":
+		return CardFundingTypeCredit
+	case "d", "debit":
+		return CardFundingTypeDebit
+	case "p", "prepaid":
+		return CardFundingTypePrepaid
+	default:
+		return ""
+	}
+}
+
+// FeeCardBrandACH keys the ACH rate in a fee schedule (ACH has no card brand or funding type)
+const FeeCardBrandACH = "ACH"
+
+// FeeRate is a percentage plus fixed per-transaction fee (e.g. 2.90% + $0.30)
+type FeeRate struct {
+	Percent decimal.Decimal `json:"percent"` // Percent of amount, e.g. 1.80 = 1.80%
+	Fixed   decimal.Decimal `json:"fixed"`   // Flat amount per transaction
+}
+
+// Apply returns the fee for an amount, rounded to cents
+func (r FeeRate) Apply(amount decimal.Decimal) decimal.Decimal {
+	return amount.Mul(r.Percent).Div(decimal.NewFromInt(100)).Add(r.Fixed).Round(2)
+}
+
+// FeeScheduleKey identifies a rate by card brand ("V"/"M"/"A"/"D" or "ACH") and funding type
+type FeeScheduleKey struct {
+	CardBrand   string
+	FundingType CardFundingType
+}
+
+// FeeSchedule is a configurable interchange/processing fee table used for estimates only.
+// Real costs depend on the card's interchange category and are reported by the processor.
+type FeeSchedule struct {
+	Rates   map[FeeScheduleKey]FeeRate
+	Default FeeRate // Used when no rate matches the brand and funding type
+}
+
+// FeeScheduleEntry is one row of a JSON fee schedule
+type FeeScheduleEntry struct {
+	CardBrand   string          `json:"card_brand"`
+	FundingType CardFundingType `json:"funding_type,omitempty"`
+	Percent     decimal.Decimal `json:"percent"`
+	Fixed       decimal.Decimal `json:"fixed"`
+}
+
+// FeeEstimate is an estimated processing fee for a transaction. It is never an actual cost.
+type FeeEstimate struct {
+	TransactionID string          `json:"transaction_id"`
+	AgentID       string          `json:"agent_id"`
+	CardBrand     string          `json:"card_brand"`   // Empty if the transaction has no card brand
+	FundingType   CardFundingType `json:"funding_type"` // Funding type the rate was looked up with
+	Amount        decimal.Decimal `json:"amount"`
+	Rate          FeeRate         `json:"rate"`
+	EstimatedFee  decimal.Decimal `json:"estimated_fee"`
+	IsDefaultRate bool            `json:"is_default_rate"` // No configured rate matched; Default was used
+	IsEstimate    bool            `json:"is_estimate"`     // Always true: estimates exclude assessments and downgrades
+}
+
+// DefaultFeeSchedule returns typical US card-not-present interchange-plus rates
+func DefaultFeeSchedule() *FeeSchedule {
+	rate := func(percent, fixed string) FeeRate {
+		return FeeRate{Percent: decimal.RequireFromString(percent), Fixed: decimal.RequireFromString(fixed)}
+	}
+
+	return &FeeSchedule{
+		Rates: map[FeeScheduleKey]FeeRate{
+			{CardBrand: "V", FundingType: CardFundingTypeCredit}:  rate("1.80", "0.10"),
+			{CardBrand: "V", FundingType: CardFundingTypeDebit}:   rate("0.80", "0.15"),
+			{CardBrand: "V", FundingType: CardFundingTypePrepaid}: rate("1.15", "0.15"),
+			{CardBrand: "M", FundingType: CardFundingTypeCredit}:  rate("1.90", "0.10"),
+			{CardBrand: "M", FundingType: CardFundingTypeDebit}:   rate("0.85", "0.15"),
+			{CardBrand: "M", FundingType: CardFundingTypePrepaid}: rate("1.20", "0.15"),
+			{CardBrand: "A", FundingType: CardFundingTypeCredit}:  rate("2.50", "0.10"),
+			{CardBrand: "D", FundingType: CardFundingTypeCredit}:  rate("1.90", "0.10"),
+			{CardBrand: "D", FundingType: CardFundingTypeDebit}:   rate("0.85", "0.15"),
+			{CardBrand: FeeCardBrandACH}:                          rate("0.00", "0.25"),
+		},
+		Default: rate("2.90", "0.30"),
+	}
+}
+
+// ParseFeeSchedule builds a fee schedule from a JSON array of entries.
+// An entry with card_brand "*" sets the default rate; otherwise the built-in default is kept.
+func ParseFeeSchedule(data []byte) (*FeeSchedule, error) {
+	var entries []FeeScheduleEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse fee schedule: %w", err)
+	}
+
+	schedule := &FeeSchedule{
+		Rates:   make(map[FeeScheduleKey]FeeRate, len(entries)),
+		Default: DefaultFeeSchedule().Default,
+	}
+
+	for _, entry := range entries {
+		if entry.Percent.IsNegative() || entry.Fixed.IsNegative() {
+			return nil, fmt.Errorf("fee schedule entry %q: rates must not be negative", entry.CardBrand)
+		}
+
+		rate := FeeRate{Percent: entry.Percent, Fixed: entry.Fixed}
+		brand := strings.ToUpper(strings.TrimSpace(entry.CardBrand))
+		switch brand {
+		case "":
+			return nil, fmt.Errorf("fee schedule entry: card_brand is required")
+		case "*":
+			schedule.Default = rate
+		default:
+			schedule.Rates[FeeScheduleKey{CardBrand: brand, FundingType: entry.FundingType}] = rate
+		}
+	}
+
+	return schedule, nil
+}
+
+// Estimate returns the estimated fee for a transaction.
+// Card transactions without a recorded funding type are estimated at credit rates.
+func (s *FeeSchedule) Estimate(tx *Transaction) *FeeEstimate {
+	key := FeeScheduleKey{}
+	if tx.PaymentMethodType == PaymentMethodTypeACH {
+		key.CardBrand = FeeCardBrandACH
+	} else {
+		if tx.AuthCardType != nil {
+			key.CardBrand = strings.ToUpper(*tx.AuthCardType)
+		}
+		key.FundingType = CardFundingTypeCredit
+		if tx.CardFundingType != nil && *tx.CardFundingType != "" {
+			key.FundingType = CardFundingType(*tx.CardFundingType)
+		}
+	}
+
+	rate, ok := s.Rates[key]
+	if !ok {
+		rate = s.Default
+	}
+
+	return &FeeEstimate{
+		TransactionID: tx.ID,
+		AgentID:       tx.AgentID,
+		CardBrand:     key.CardBrand,
+		FundingType:   key.FundingType,
+		Amount:        tx.Amount,
+		Rate:          rate,
+		EstimatedFee:  rate.Apply(tx.Amount),
+		IsDefaultRate: !ok,
+		IsEstimate:    true,
+	}
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCardTransaction(amount, cardType string, fundingType *string) *Transaction {
+	return &Transaction{
+		ID:                "tx-123",
+		AgentID:           "test-agent-123",
+		Amount:            decimal.RequireFromString(amount),
+		PaymentMethodType: PaymentMethodTypeCreditCard,
+		AuthCardType:      &cardType,
+		CardFundingType:   fundingType,
+	}
+}
+
+func TestFeeSchedule_Estimate_VisaCreditVsDebit(t *testing.T) {
+	schedule, err := ParseFeeSchedule([]byte(`[
+		{"card_brand": "V", "funding_type": "credit", "percent": "1.80", "fixed": "0.10"},
+		{"card_brand": "V", "funding_type": "debit", "percent": "0.50", "fixed": "0.22"}
+	]`))
+	require.NoError(t, err)
+
+	credit := string(CardFundingTypeCredit)
+	debit := string(CardFundingTypeDebit)
+
+	creditEstimate := schedule.Estimate(newCardTransaction("100.00", "V", &credit))
+	debitEstimate := schedule.Estimate(newCardTransaction("100.00", "V", &debit))
+
+	assert.Equal(t, "1.90", creditEstimate.EstimatedFee.StringFixed(2))
+	assert.Equal(t, "0.72", debitEstimate.EstimatedFee.StringFixed(2))
+	assert.False(t, creditEstimate.EstimatedFee.Equal(debitEstimate.EstimatedFee))
+
+	for _, estimate := range []*FeeEstimate{creditEstimate, debitEstimate} {
+		assert.True(t, estimate.IsEstimate)
+		assert.False(t, estimate.IsDefaultRate)
+		assert.Equal(t, "V", estimate.CardBrand)
+	}
+}
+
+func TestFeeSchedule_Estimate_Fallbacks(t *testing.T) {
+	schedule := DefaultFeeSchedule()
+
+	tests := []struct {
+		name        string
+		tx          *Transaction
+		wantBrand   string
+		wantFunding CardFundingType
+		wantFee     string
+		wantDefault bool
+	}{
+		{
+			name:        "unknown funding type uses credit rate",
+			tx:          newCardTransaction("50.00", "v", nil),
+			wantBrand:   "V",
+			wantFunding: CardFundingTypeCredit,
+			wantFee:     "1.00", // 1.80% + 0.10
+		},
+		{
+			name:        "unconfigured brand uses default rate",
+			tx:          newCardTransaction("10.00", "J", nil),
+			wantBrand:   "J",
+			wantFunding: CardFundingTypeCredit,
+			wantFee:     "0.59", // 2.90% + 0.30
+			wantDefault: true,
+		},
+		{
+			name:      "ACH uses flat ACH rate",
+			tx:        &Transaction{Amount: decimal.NewFromInt(500), PaymentMethodType: PaymentMethodTypeACH},
+			wantBrand: FeeCardBrandACH,
+			wantFee:   "0.25",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate := schedule.Estimate(tt.tx)
+
+			assert.Equal(t, tt.wantBrand, estimate.CardBrand)
+			assert.Equal(t, tt.wantFunding, estimate.FundingType)
+			assert.Equal(t, tt.wantFee, estimate.EstimatedFee.StringFixed(2))
+			assert.Equal(t, tt.wantDefault, estimate.IsDefaultRate)
+		})
+	}
+}
+
+func TestParseFeeSchedule_Errors(t *testing.T) {
+	_, err := ParseFeeSchedule([]byte(`not json`))
+	assert.Error(t, err)
+
+	_, err = ParseFeeSchedule([]byte(`[{"percent": "1.00", "fixed": "0.10"}]`))
+	assert.Error(t, err)
+
+	_, err = ParseFeeSchedule([]byte(`[{"card_brand": "V", "percent": "-1.00", "fixed": "0.10"}]`))
+	assert.Error(t, err)
+
+	schedule, err := ParseFeeSchedule([]byte(`[{"card_brand": "*", "percent": "3.50", "fixed": "0.00"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, "3.5", schedule.Default.Percent.String())
+}
+
+func TestParseCardFundingType(t *testing.T) {
+	assert.Equal(t, CardFundingTypeCredit, ParseCardFundingType("C"))
+	assert.Equal(t, CardFundingTypeDebit, ParseCardFundingType("d"))
+	assert.Equal(t, CardFundingTypePrepaid, ParseCardFundingType("prepaid"))
+	assert.Equal(t, CardFundingType(""), ParseCardFundingType(""))
+	assert.Equal(t, CardFundingType(""), ParseCardFundingType("X"), "unknown indicators aren't guessed")
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Summaries of AVS/CVV result codes
+const (
+	VerificationMatch         = "match"
+	VerificationPartialMatch  = "partial_match" // AVS only: street or ZIP matched, not both
+	VerificationNoMatch       = "no_match"
+	VerificationNotProcessed  = "not_processed"
+	VerificationUnavailable   = "unavailable"
+	VerificationNotApplicable = ""
+)
+
+// Decline severities
+const (
+	DeclineSeveritySoft = "soft" // Retriable: the same request may succeed later
+	DeclineSeverityHard = "hard" // Needs a different card, cardholder action or a corrected request
+)
+
+// GatewayResult is the gateway outcome of a payment operation in a uniform shape,
+// so clients don't have to interpret raw EPX fields per transaction type
+type GatewayResult struct {
+	ResponseCode string // EPX AUTH_RESP ("00" = approved)
+	ResponseText string // EPX AUTH_RESP_TEXT
+	AuthCode     string // Bank authorization code (empty if declined)
+	Approved     bool
+	CardType     string // Card brand ("V"/"M"/"A"/"D") - empty for ACH
+
+	AVSCode   string // Raw AVS result code
+	AVSResult string // One of the Verification* summaries
+	CVVCode   string // Raw CVV2 result code
+	CVVResult string // One of the Verification* summaries
+
+	// Decline mapping (empty when approved)
+	DeclineCode     string // e.g. "EPX_51"
+	DeclineCategory string // e.g. "insufficient_funds"
+	DeclineReason   string // e.g. "Insufficient funds"
+	Retriable       bool   // The same request may succeed later without cardholder action
+
+	Latency time.Duration // Gateway round trip; zero when the result was loaded from storage
+}
+
+// GatewayResult returns the gateway outcome for the transaction. Transactions returned
+// straight from a gateway call carry the full result (latency, decline mapping);
+// stored transactions are summarized from their persisted EPX fields.
+func (t *Transaction) GatewayResult() *GatewayResult {
+	if t.Gateway != nil {
+		return t.Gateway
+	}
+
+	avs := derefString(t.AuthAVS)
+	cvv := derefString(t.AuthCVV2)
+	return &GatewayResult{
+		ResponseCode: derefString(t.AuthResp),
+		ResponseText: derefString(t.AuthRespText),
+		AuthCode:     derefString(t.AuthCode),
+		Approved:     t.IsApproved(),
+		CardType:     derefString(t.AuthCardType),
+		AVSCode:      avs,
+		AVSResult:    SummarizeAVS(avs),
+		CVVCode:      cvv,
+		CVVResult:    SummarizeCVV(cvv),
+	}
+}
+
+// DeclineSeverity returns DeclineSeveritySoft or DeclineSeverityHard for a non-approved result ("" when approved)
+func (r *GatewayResult) DeclineSeverity() string {
+	switch {
+	case r.Approved:
+		return ""
+	case r.Retriable:
+		return DeclineSeveritySoft
+	default:
+		return DeclineSeverityHard
+	}
+}
+
+// SummarizeAVS collapses an AVS result code into match/partial_match/no_match/unavailable.
+// Empty codes (ACH, or AVS not requested) summarize to VerificationNotApplicable.
+func SummarizeAVS(code string) string {
+	switch strings.ToUpper(strings.TrimSpace(code)) {
+	case "":
+		return VerificationNotApplicable
+	case "Y", "X", "D", "M", "F":
+		return VerificationMatch
+	case "A", "B", "Z", "W", "P":
+		return VerificationPartialMatch
+	case "N", "C":
+		return VerificationNoMatch
+	default:
+		return VerificationUnavailable
+	}
+}
+
+// SummarizeCVV collapses a CVV2 result code into match/no_match/not_processed/unavailable.
+// Empty codes (ACH, or CVV not sent) summarize to VerificationNotApplicable.
+func SummarizeCVV(code string) string {
+	switch strings.ToUpper(strings.TrimSpace(code)) {
+	case "":
+		return VerificationNotApplicable
+	case "M":
+		return VerificationMatch
+	case "N":
+		return VerificationNoMatch
+	case "P":
+		return VerificationNotProcessed
+	default:
+		return VerificationUnavailable
+	}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
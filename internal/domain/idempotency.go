@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// RequestFingerprint hashes the parameters that define a keyed request (operation, merchant, amount, ...),
+// so a retry can be told apart from a different request that reuses the idempotency key
+func RequestFingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckReplay reports whether a request with the given fingerprint may be answered with this transaction.
+// Transactions recorded before fingerprints were stored have none and are replayed as before.
+func (t *Transaction) CheckReplay(fingerprint string) error {
+	if t.RequestHash == "" || t.RequestHash == fingerprint {
+		return nil
+	}
+	return fmt.Errorf("%w: transaction %s", ErrIdempotencyKeyConflict, t.ID)
+}
@@ -0,0 +1,388 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MerchantTier determines the default configuration applied to an agent
+type MerchantTier string
+
+const (
+	MerchantTierStandard   MerchantTier = "standard"
+	MerchantTierPremium    MerchantTier = "premium"
+	MerchantTierEnterprise MerchantTier = "enterprise"
+)
+
+// Capability is a feature an agent is allowed to use
+type Capability string
+
+const (
+	CapabilityRecurringBilling Capability = "recurring_billing"
+	CapabilityStoredCards      Capability = "stored_cards"
+	CapabilityACH              Capability = "ach"
+	CapabilityBrowserPost      Capability = "browser_post"
+	CapabilityRefunds          Capability = "refunds"
+)
+
+// MerchantConfig is the effective configuration used when processing an agent's payments.
+// It never contains secrets (MAC secrets stay in the secret manager).
+type MerchantConfig struct {
+	Tier MerchantTier `json:"tier"`
+
+	// Currency allow-list (ISO 4217)
+	AllowedCurrencies []string `json:"allowed_currencies"`
+
+	// Per-transaction and daily limits
+	MinTransactionAmount decimal.Decimal `json:"min_transaction_amount"`
+	MaxTransactionAmount decimal.Decimal `json:"max_transaction_amount"`
+	DailyVolumeLimit     decimal.Decimal `json:"daily_volume_limit"`
+
+	// Surcharge applied to card transactions (percent, e.g. 3.00 = 3%)
+	SurchargePercent decimal.Decimal `json:"surcharge_percent"`
+
+	// Refund policy: only refund settled transactions (unsettled ones must be voided)
+	RequireSettledRefund bool `json:"require_settled_refund"`
+
+	// Business days between settlement and funds reaching the merchant's bank account
+	FundingDelayDays int `json:"funding_delay_days"`
+
+	// Dunning: when an ACH subscription exhausts its retries, switch it to the customer's card on file and retry
+	ACHCardFallback bool `json:"ach_card_fallback"`
+
+	// Uncaptured authorizations are voided by the auth auto-void job this many hours after their latest approval
+	// (0 = never auto-voided)
+	AuthAutoVoidHours int `json:"auth_auto_void_hours"`
+
+	// Payment operation responses include the full transaction group tree unless the request says otherwise
+	IncludeTransactionTree bool `json:"include_transaction_tree"`
+
+	// AVS/CVV policies evaluated on card authorizations and recorded on the transaction (off = not evaluated)
+	AVSPolicy VerificationPolicy `json:"avs_policy"`
+	CVVPolicy VerificationPolicy `json:"cvv_policy"`
+
+	// Velocity limits on one saved card's and one customer's sales and authorizations (off by default)
+	CardVelocity     VelocityLimit `json:"card_velocity"`
+	CustomerVelocity VelocityLimit `json:"customer_velocity"`
+
+	// Cap on a customer's active saved payment methods (0 = unlimited) and what happens when a save would pass it
+	MaxSavedPaymentMethods   int                      `json:"max_saved_payment_methods"`
+	SavedPaymentMethodPolicy SavedPaymentMethodPolicy `json:"saved_payment_method_policy"`
+
+	// How a payment's customer_id is checked against the merchant's customer records (unchecked by default)
+	CustomerPolicy CustomerPolicy `json:"customer_policy"`
+
+	// Hosts a Browser Post return_url may point at ("*.example.com" matches subdomains); empty allows none
+	ReturnURLHosts []string `json:"return_url_hosts"`
+
+	// Browser Post callbacks must carry a valid response MAC signed with the merchant's MAC secret (off = TAC only)
+	BrowserPostMACVerification bool `json:"browser_post_mac_verification"`
+
+	// gRPC request rate allowed for requests carrying the merchant's agent_id (token bucket)
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	BurstLimit        int     `json:"burst_limit"`
+
+	// Enabled features and permitted operations
+	Capabilities            []Capability        `json:"capabilities"`
+	AllowedTransactionTypes []TransactionType   `json:"allowed_transaction_types"`
+	AllowedPaymentTypes     []PaymentMethodType `json:"allowed_payment_types"`
+
+	// OverriddenFields lists the fields that came from agent overrides instead of tier defaults
+	OverriddenFields []string `json:"overridden_fields"`
+}
+
+// MerchantConfigOverrides holds per-agent overrides of tier defaults (nil = use tier default)
+type MerchantConfigOverrides struct {
+	AllowedCurrencies          []string                  `json:"allowed_currencies,omitempty"`
+	MinTransactionAmount       *decimal.Decimal          `json:"min_transaction_amount,omitempty"`
+	MaxTransactionAmount       *decimal.Decimal          `json:"max_transaction_amount,omitempty"`
+	DailyVolumeLimit           *decimal.Decimal          `json:"daily_volume_limit,omitempty"`
+	SurchargePercent           *decimal.Decimal          `json:"surcharge_percent,omitempty"`
+	RequireSettledRefund       *bool                     `json:"require_settled_refund,omitempty"`
+	FundingDelayDays           *int                      `json:"funding_delay_days,omitempty"`
+	ACHCardFallback            *bool                     `json:"ach_card_fallback,omitempty"`
+	AuthAutoVoidHours          *int                      `json:"auth_auto_void_hours,omitempty"`
+	IncludeTransactionTree     *bool                     `json:"include_transaction_tree,omitempty"`
+	AVSPolicy                  *VerificationPolicy       `json:"avs_policy,omitempty"`
+	CVVPolicy                  *VerificationPolicy       `json:"cvv_policy,omitempty"`
+	CardVelocity               *VelocityLimit            `json:"card_velocity,omitempty"`
+	CustomerVelocity           *VelocityLimit            `json:"customer_velocity,omitempty"`
+	MaxSavedPaymentMethods     *int                      `json:"max_saved_payment_methods,omitempty"`
+	SavedPaymentMethodPolicy   *SavedPaymentMethodPolicy `json:"saved_payment_method_policy,omitempty"`
+	CustomerPolicy             *CustomerPolicy           `json:"customer_policy,omitempty"`
+	ReturnURLHosts             []string                  `json:"return_url_hosts,omitempty"`
+	BrowserPostMACVerification *bool                     `json:"browser_post_mac_verification,omitempty"`
+	RequestsPerSecond          *float64                  `json:"requests_per_second,omitempty"`
+	BurstLimit                 *int                      `json:"burst_limit,omitempty"`
+	Capabilities               []Capability              `json:"capabilities,omitempty"`
+	AllowedTransactionTypes    []TransactionType         `json:"allowed_transaction_types,omitempty"`
+	AllowedPaymentTypes        []PaymentMethodType       `json:"allowed_payment_types,omitempty"`
+}
+
+// DefaultMerchantConfig returns the tier defaults (unknown tiers fall back to standard)
+func DefaultMerchantConfig(tier MerchantTier) *MerchantConfig {
+	config := &MerchantConfig{
+		Tier:                 MerchantTierStandard,
+		AllowedCurrencies:    []string{"USD"},
+		MinTransactionAmount: decimal.RequireFromString("0.50"),
+		MaxTransactionAmount: decimal.NewFromInt(10000),
+		DailyVolumeLimit:     decimal.NewFromInt(50000),
+		SurchargePercent:     decimal.Zero,
+		FundingDelayDays:     2,
+		AuthAutoVoidHours:    int(AuthorizationLifetime.Hours()),
+		RequestsPerSecond:    10,
+		BurstLimit:           20,
+		Capabilities: []Capability{
+			CapabilityRecurringBilling,
+			CapabilityStoredCards,
+			CapabilityBrowserPost,
+			CapabilityRefunds,
+		},
+		AllowedTransactionTypes: []TransactionType{
+			TransactionTypeAuth,
+			TransactionTypeCapture,
+			TransactionTypeCharge,
+			TransactionTypeRefund,
+		},
+		AllowedPaymentTypes:      []PaymentMethodType{PaymentMethodTypeCreditCard},
+		SavedPaymentMethodPolicy: SavedPaymentMethodPolicyReject,
+		OverriddenFields:         []string{},
+	}
+
+	switch tier {
+	case MerchantTierPremium:
+		config.Tier = MerchantTierPremium
+		config.FundingDelayDays = 1
+		config.MaxTransactionAmount = decimal.NewFromInt(50000)
+		config.DailyVolumeLimit = decimal.NewFromInt(250000)
+		config.RequestsPerSecond = 50
+		config.BurstLimit = 100
+		config.Capabilities = append(config.Capabilities, CapabilityACH)
+		config.AllowedTransactionTypes = append(config.AllowedTransactionTypes, TransactionTypePreNote)
+		config.AllowedPaymentTypes = append(config.AllowedPaymentTypes, PaymentMethodTypeACH)
+	case MerchantTierEnterprise:
+		config.Tier = MerchantTierEnterprise
+		config.FundingDelayDays = 1
+		config.AllowedCurrencies = []string{"USD", "CAD"}
+		config.MaxTransactionAmount = decimal.NewFromInt(250000)
+		config.DailyVolumeLimit = decimal.NewFromInt(2000000)
+		config.RequestsPerSecond = 200
+		config.BurstLimit = 400
+		config.Capabilities = append(config.Capabilities, CapabilityACH)
+		config.AllowedTransactionTypes = append(config.AllowedTransactionTypes, TransactionTypePreNote)
+		config.AllowedPaymentTypes = append(config.AllowedPaymentTypes, PaymentMethodTypeACH)
+	}
+
+	return config
+}
+
+// ResolveMerchantConfig merges agent overrides on top of the tier defaults
+func ResolveMerchantConfig(tier MerchantTier, overrides *MerchantConfigOverrides) *MerchantConfig {
+	config := DefaultMerchantConfig(tier)
+	if overrides == nil {
+		return config
+	}
+
+	if len(overrides.AllowedCurrencies) > 0 {
+		config.AllowedCurrencies = overrides.AllowedCurrencies
+		config.OverriddenFields = append(config.OverriddenFields, "allowed_currencies")
+	}
+	if overrides.MinTransactionAmount != nil {
+		config.MinTransactionAmount = *overrides.MinTransactionAmount
+		config.OverriddenFields = append(config.OverriddenFields, "min_transaction_amount")
+	}
+	if overrides.MaxTransactionAmount != nil {
+		config.MaxTransactionAmount = *overrides.MaxTransactionAmount
+		config.OverriddenFields = append(config.OverriddenFields, "max_transaction_amount")
+	}
+	if overrides.DailyVolumeLimit != nil {
+		config.DailyVolumeLimit = *overrides.DailyVolumeLimit
+		config.OverriddenFields = append(config.OverriddenFields, "daily_volume_limit")
+	}
+	if overrides.SurchargePercent != nil {
+		config.SurchargePercent = *overrides.SurchargePercent
+		config.OverriddenFields = append(config.OverriddenFields, "surcharge_percent")
+	}
+	if overrides.RequireSettledRefund != nil {
+		config.RequireSettledRefund = *overrides.RequireSettledRefund
+		config.OverriddenFields = append(config.OverriddenFields, "require_settled_refund")
+	}
+	if overrides.FundingDelayDays != nil && *overrides.FundingDelayDays >= 0 {
+		config.FundingDelayDays = *overrides.FundingDelayDays
+		config.OverriddenFields = append(config.OverriddenFields, "funding_delay_days")
+	}
+	if overrides.ACHCardFallback != nil {
+		config.ACHCardFallback = *overrides.ACHCardFallback
+		config.OverriddenFields = append(config.OverriddenFields, "ach_card_fallback")
+	}
+	if overrides.AuthAutoVoidHours != nil && *overrides.AuthAutoVoidHours >= 0 {
+		config.AuthAutoVoidHours = *overrides.AuthAutoVoidHours
+		config.OverriddenFields = append(config.OverriddenFields, "auth_auto_void_hours")
+	}
+	if overrides.IncludeTransactionTree != nil {
+		config.IncludeTransactionTree = *overrides.IncludeTransactionTree
+		config.OverriddenFields = append(config.OverriddenFields, "include_transaction_tree")
+	}
+	if overrides.AVSPolicy != nil && overrides.AVSPolicy.IsValid() {
+		config.AVSPolicy = *overrides.AVSPolicy
+		config.OverriddenFields = append(config.OverriddenFields, "avs_policy")
+	}
+	if overrides.CVVPolicy != nil && overrides.CVVPolicy.IsValid() {
+		config.CVVPolicy = *overrides.CVVPolicy
+		config.OverriddenFields = append(config.OverriddenFields, "cvv_policy")
+	}
+	if overrides.CardVelocity != nil && overrides.CardVelocity.WindowMinutes >= 0 {
+		config.CardVelocity = *overrides.CardVelocity
+		config.OverriddenFields = append(config.OverriddenFields, "card_velocity")
+	}
+	if overrides.CustomerVelocity != nil && overrides.CustomerVelocity.WindowMinutes >= 0 {
+		config.CustomerVelocity = *overrides.CustomerVelocity
+		config.OverriddenFields = append(config.OverriddenFields, "customer_velocity")
+	}
+	if overrides.MaxSavedPaymentMethods != nil && *overrides.MaxSavedPaymentMethods >= 0 {
+		config.MaxSavedPaymentMethods = *overrides.MaxSavedPaymentMethods
+		config.OverriddenFields = append(config.OverriddenFields, "max_saved_payment_methods")
+	}
+	if overrides.SavedPaymentMethodPolicy != nil && overrides.SavedPaymentMethodPolicy.IsValid() {
+		config.SavedPaymentMethodPolicy = *overrides.SavedPaymentMethodPolicy
+		config.OverriddenFields = append(config.OverriddenFields, "saved_payment_method_policy")
+	}
+	if overrides.CustomerPolicy != nil && overrides.CustomerPolicy.IsValid() {
+		config.CustomerPolicy = *overrides.CustomerPolicy
+		config.OverriddenFields = append(config.OverriddenFields, "customer_policy")
+	}
+	if len(overrides.ReturnURLHosts) > 0 {
+		config.ReturnURLHosts = overrides.ReturnURLHosts
+		config.OverriddenFields = append(config.OverriddenFields, "return_url_hosts")
+	}
+	if overrides.BrowserPostMACVerification != nil {
+		config.BrowserPostMACVerification = *overrides.BrowserPostMACVerification
+		config.OverriddenFields = append(config.OverriddenFields, "browser_post_mac_verification")
+	}
+	if overrides.RequestsPerSecond != nil && *overrides.RequestsPerSecond > 0 {
+		config.RequestsPerSecond = *overrides.RequestsPerSecond
+		config.OverriddenFields = append(config.OverriddenFields, "requests_per_second")
+	}
+	if overrides.BurstLimit != nil && *overrides.BurstLimit > 0 {
+		config.BurstLimit = *overrides.BurstLimit
+		config.OverriddenFields = append(config.OverriddenFields, "burst_limit")
+	}
+	if len(overrides.Capabilities) > 0 {
+		config.Capabilities = overrides.Capabilities
+		config.OverriddenFields = append(config.OverriddenFields, "capabilities")
+	}
+	if len(overrides.AllowedTransactionTypes) > 0 {
+		config.AllowedTransactionTypes = overrides.AllowedTransactionTypes
+		config.OverriddenFields = append(config.OverriddenFields, "allowed_transaction_types")
+	}
+	if len(overrides.AllowedPaymentTypes) > 0 {
+		config.AllowedPaymentTypes = overrides.AllowedPaymentTypes
+		config.OverriddenFields = append(config.OverriddenFields, "allowed_payment_types")
+	}
+
+	return config
+}
+
+// ParseMerchantConfigOverrides decodes an agent's stored config_overrides (empty = no overrides)
+func ParseMerchantConfigOverrides(raw []byte) (*MerchantConfigOverrides, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var overrides MerchantConfigOverrides
+	if err := json.Unmarshal(raw, &overrides); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMerchantConfig, err)
+	}
+	return &overrides, nil
+}
+
+// ResolveStoredMerchantConfig resolves the effective config from an agent's stored tier and config_overrides.
+// Unreadable overrides are an error rather than silently falling back to the tier defaults, which could
+// loosen limits the merchant was configured with.
+func ResolveStoredMerchantConfig(tier string, rawOverrides []byte) (*MerchantConfig, error) {
+	overrides, err := ParseMerchantConfigOverrides(rawOverrides)
+	if err != nil {
+		return nil, err
+	}
+	return ResolveMerchantConfig(MerchantTier(tier), overrides), nil
+}
+
+// EvaluateVerification checks a card authorization's AVS/CVV codes against the merchant's policies
+// (nil when no policy is configured)
+func (c *MerchantConfig) EvaluateVerification(avsCode, cvvCode string) *VerificationPolicyOutcome {
+	return EvaluateVerificationPolicy(c.AVSPolicy, c.CVVPolicy, avsCode, cvvCode)
+}
+
+// HasCapability returns true if the capability is enabled
+func (c *MerchantConfig) HasCapability(capability Capability) bool {
+	for _, enabled := range c.Capabilities {
+		if enabled == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsCurrency returns true if the currency is in the allow-list
+func (c *MerchantConfig) AllowsCurrency(currency string) bool {
+	for _, allowed := range c.AllowedCurrencies {
+		if allowed == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsTransactionType returns true if the transaction type is in the allow-list
+func (c *MerchantConfig) AllowsTransactionType(txType TransactionType) bool {
+	for _, allowed := range c.AllowedTransactionTypes {
+		if allowed == txType {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAmount returns ErrAmountOutOfRange unless amount is positive and within the merchant's
+// min/max transaction amount. A non-positive MaxTransactionAmount means no ceiling.
+func (c *MerchantConfig) CheckAmount(amount decimal.Decimal) error {
+	if !amount.IsPositive() || amount.LessThan(c.MinTransactionAmount) {
+		return fmt.Errorf("%w: %s is below the minimum of %s", ErrAmountOutOfRange, amount.StringFixed(2), c.MinTransactionAmount.StringFixed(2))
+	}
+	if c.MaxTransactionAmount.IsPositive() && amount.GreaterThan(c.MaxTransactionAmount) {
+		return fmt.Errorf("%w: %s is above the maximum of %s", ErrAmountOutOfRange, amount.StringFixed(2), c.MaxTransactionAmount.StringFixed(2))
+	}
+	return nil
+}
+
+// CheckRefundSettlement rejects refunds of unsettled transactions when the merchant requires settlement
+func (c *MerchantConfig) CheckRefundSettlement(original *Transaction) error {
+	if c.RequireSettledRefund && !original.IsSettled() {
+		return ErrTransactionNotSettled
+	}
+	return nil
+}
+
+// AuthAutoVoidCutoff returns the latest approval time at which an uncaptured authorization is auto-voided at now,
+// or false when the merchant has auto-void turned off
+func (c *MerchantConfig) AuthAutoVoidCutoff(now time.Time) (time.Time, bool) {
+	if c.AuthAutoVoidHours <= 0 {
+		return time.Time{}, false
+	}
+	return now.Add(-time.Duration(c.AuthAutoVoidHours) * time.Hour), true
+}
+
+// FundingDate returns the date settled funds reach the merchant: the settlement date
+// plus FundingDelayDays business days (weekends skipped; bank holidays are not modeled).
+func (c *MerchantConfig) FundingDate(settledAt time.Time) time.Time {
+	date := time.Date(settledAt.Year(), settledAt.Month(), settledAt.Day(), 0, 0, 0, 0, time.UTC)
+	for remaining := c.FundingDelayDays; remaining > 0; {
+		date = date.AddDate(0, 0, 1)
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			remaining--
+		}
+	}
+	return date
+}
@@ -0,0 +1,319 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveMerchantConfig_TierDefaultsWithoutOverrides(t *testing.T) {
+	tests := []struct {
+		name        string
+		tier        MerchantTier
+		wantTier    MerchantTier
+		wantMax     string
+		wantACH     bool
+		wantCurrLen int
+	}{
+		{"standard", MerchantTierStandard, MerchantTierStandard, "10000", false, 1},
+		{"premium", MerchantTierPremium, MerchantTierPremium, "50000", true, 1},
+		{"enterprise", MerchantTierEnterprise, MerchantTierEnterprise, "250000", true, 2},
+		{"unknown tier falls back to standard", MerchantTier(""), MerchantTierStandard, "10000", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := ResolveMerchantConfig(tt.tier, nil)
+
+			assert.Equal(t, tt.wantTier, config.Tier)
+			assert.True(t, decimal.RequireFromString(tt.wantMax).Equal(config.MaxTransactionAmount))
+			assert.Equal(t, tt.wantACH, config.HasCapability(CapabilityACH))
+			assert.Len(t, config.AllowedCurrencies, tt.wantCurrLen)
+			assert.True(t, config.AllowsCurrency("USD"))
+			assert.Empty(t, config.OverriddenFields)
+		})
+	}
+}
+
+func TestResolveMerchantConfig_EmptyOverridesKeepDefaults(t *testing.T) {
+	config := ResolveMerchantConfig(MerchantTierPremium, &MerchantConfigOverrides{})
+
+	assert.Equal(t, DefaultMerchantConfig(MerchantTierPremium), config)
+}
+
+func TestResolveMerchantConfig_OverridesWin(t *testing.T) {
+	maxAmount := decimal.NewFromInt(500)
+	surcharge := decimal.RequireFromString("3.00")
+
+	config := ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{
+		AllowedCurrencies:    []string{"USD", "EUR"},
+		MaxTransactionAmount: &maxAmount,
+		SurchargePercent:     &surcharge,
+		AllowedPaymentTypes:  []PaymentMethodType{PaymentMethodTypeCreditCard, PaymentMethodTypeACH},
+	})
+
+	// Overridden fields
+	assert.Equal(t, []string{"USD", "EUR"}, config.AllowedCurrencies)
+	assert.True(t, maxAmount.Equal(config.MaxTransactionAmount))
+	assert.True(t, surcharge.Equal(config.SurchargePercent))
+	assert.Equal(t, []PaymentMethodType{PaymentMethodTypeCreditCard, PaymentMethodTypeACH}, config.AllowedPaymentTypes)
+	assert.ElementsMatch(t, []string{
+		"allowed_currencies",
+		"max_transaction_amount",
+		"surcharge_percent",
+		"allowed_payment_types",
+	}, config.OverriddenFields)
+
+	// Untouched fields keep the tier defaults
+	defaults := DefaultMerchantConfig(MerchantTierStandard)
+	assert.Equal(t, MerchantTierStandard, config.Tier)
+	assert.True(t, defaults.MinTransactionAmount.Equal(config.MinTransactionAmount))
+	assert.True(t, defaults.DailyVolumeLimit.Equal(config.DailyVolumeLimit))
+	assert.Equal(t, defaults.Capabilities, config.Capabilities)
+	assert.Equal(t, defaults.AllowedTransactionTypes, config.AllowedTransactionTypes)
+}
+
+func TestResolveStoredMerchantConfig(t *testing.T) {
+	config, err := ResolveStoredMerchantConfig("premium", []byte(`{"max_transaction_amount": "75"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, MerchantTierPremium, config.Tier)
+	assert.True(t, config.MaxTransactionAmount.Equal(decimal.NewFromInt(75)))
+
+	config, err = ResolveStoredMerchantConfig("standard", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, config.OverriddenFields)
+
+	config, err = ResolveStoredMerchantConfig("standard", []byte(`{"max_transaction_amount": true}`))
+	assert.ErrorIs(t, err, ErrInvalidMerchantConfig)
+	assert.Nil(t, config, "unreadable overrides never fall back to tier defaults")
+}
+
+func TestAgent_EffectiveConfig(t *testing.T) {
+	minAmount := decimal.NewFromInt(5)
+	agent := &Agent{
+		AgentID: "test-agent-123",
+		Tier:    MerchantTierEnterprise,
+		ConfigOverrides: &MerchantConfigOverrides{
+			MinTransactionAmount: &minAmount,
+		},
+	}
+
+	config := agent.EffectiveConfig()
+
+	assert.Equal(t, MerchantTierEnterprise, config.Tier)
+	assert.True(t, minAmount.Equal(config.MinTransactionAmount))
+	assert.True(t, decimal.NewFromInt(250000).Equal(config.MaxTransactionAmount))
+	assert.Equal(t, []string{"min_transaction_amount"}, config.OverriddenFields)
+}
+
+func TestMerchantConfig_CheckAmount(t *testing.T) {
+	standard := DefaultMerchantConfig(MerchantTierStandard)
+	minAmount := decimal.NewFromInt(5)
+	maxAmount := decimal.NewFromInt(500)
+	overridden := ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{
+		MinTransactionAmount: &minAmount,
+		MaxTransactionAmount: &maxAmount,
+	})
+	noCeiling := ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{MaxTransactionAmount: &decimal.Zero})
+
+	tests := []struct {
+		name    string
+		config  *MerchantConfig
+		amount  string
+		wantErr bool
+	}{
+		{"in range", standard, "49.99", false},
+		{"at the minimum", standard, "0.50", false},
+		{"at the maximum", standard, "10000", false},
+		{"below the minimum", standard, "0.49", true},
+		{"zero", standard, "0.00", true},
+		{"negative", standard, "-10", true},
+		{"above the maximum", standard, "1000000", true},
+		{"override raises the minimum", overridden, "4.99", true},
+		{"override lowers the maximum", overridden, "500.01", true},
+		{"in the overridden range", overridden, "250", false},
+		{"zero maximum has no ceiling", noCeiling, "1000000", false},
+		{"zero maximum keeps the minimum", noCeiling, "0.10", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.CheckAmount(decimal.RequireFromString(tt.amount))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrAmountOutOfRange)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMerchantConfig_CheckRefundSettlement(t *testing.T) {
+	settledAt := time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC)
+	unsettled := &Transaction{Type: TransactionTypeCapture, Status: TransactionStatusCompleted}
+	settled := &Transaction{Type: TransactionTypeCapture, Status: TransactionStatusCompleted, SettledAt: &settledAt}
+
+	requireSettled := true
+	policyOn := ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{RequireSettledRefund: &requireSettled})
+	policyOff := DefaultMerchantConfig(MerchantTierStandard)
+
+	assert.False(t, policyOff.RequireSettledRefund, "policy must default to off")
+	assert.Contains(t, policyOn.OverriddenFields, "require_settled_refund")
+
+	err := policyOn.CheckRefundSettlement(unsettled)
+	assert.ErrorIs(t, err, ErrTransactionNotSettled)
+	assert.Contains(t, err.Error(), "void")
+
+	assert.NoError(t, policyOn.CheckRefundSettlement(settled))
+	assert.NoError(t, policyOff.CheckRefundSettlement(unsettled))
+}
+
+func TestMerchantConfig_FundingDate(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name      string
+		delayDays int
+		settledAt time.Time
+		want      string
+	}{
+		{name: "same day with no delay", delayDays: 0, settledAt: date("2025-06-04"), want: "2025-06-04"},
+		{name: "midweek T+2", delayDays: 2, settledAt: date("2025-06-04"), want: "2025-06-06"},                // Wed -> Fri
+		{name: "Thursday T+2 skips weekend", delayDays: 2, settledAt: date("2025-06-05"), want: "2025-06-09"}, // Thu -> Mon
+		{name: "Friday T+1", delayDays: 1, settledAt: date("2025-06-06"), want: "2025-06-09"},                 // Fri -> Mon
+		{name: "Saturday settlement T+1", delayDays: 1, settledAt: date("2025-06-07"), want: "2025-06-09"},    // Sat -> Mon
+		{name: "time of day ignored", delayDays: 1, settledAt: time.Date(2025, 6, 4, 23, 59, 0, 0, time.UTC), want: "2025-06-05"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &MerchantConfig{FundingDelayDays: tt.delayDays}
+			assert.Equal(t, tt.want, config.FundingDate(tt.settledAt).Format("2006-01-02"))
+		})
+	}
+}
+
+func TestResolveMerchantConfig_FundingDelayDays(t *testing.T) {
+	assert.Equal(t, 2, DefaultMerchantConfig(MerchantTierStandard).FundingDelayDays)
+	assert.Equal(t, 1, DefaultMerchantConfig(MerchantTierPremium).FundingDelayDays)
+
+	nextDay := 0
+	config := ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{FundingDelayDays: &nextDay})
+	assert.Equal(t, 0, config.FundingDelayDays)
+	assert.Contains(t, config.OverriddenFields, "funding_delay_days")
+
+	negative := -3
+	config = ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{FundingDelayDays: &negative})
+	assert.Equal(t, 2, config.FundingDelayDays, "negative overrides are ignored")
+}
+
+func TestMerchantConfig_AuthAutoVoidCutoff(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	config := DefaultMerchantConfig(MerchantTierStandard)
+	assert.Equal(t, 168, config.AuthAutoVoidHours, "defaults to the authorization lifetime")
+	cutoff, ok := config.AuthAutoVoidCutoff(now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(-AuthorizationLifetime), cutoff)
+
+	day := 24
+	config = ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{AuthAutoVoidHours: &day})
+	cutoff, _ = config.AuthAutoVoidCutoff(now)
+	assert.Equal(t, now.Add(-24*time.Hour), cutoff)
+	assert.Contains(t, config.OverriddenFields, "auth_auto_void_hours")
+
+	off := 0
+	config = ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{AuthAutoVoidHours: &off})
+	_, ok = config.AuthAutoVoidCutoff(now)
+	assert.False(t, ok, "0 turns auto-void off")
+}
+
+func TestResolveMerchantConfig_ACHCardFallback(t *testing.T) {
+	assert.False(t, DefaultMerchantConfig(MerchantTierEnterprise).ACHCardFallback, "opt-in only")
+
+	enabled := true
+	config := ResolveMerchantConfig(MerchantTierPremium, &MerchantConfigOverrides{ACHCardFallback: &enabled})
+	assert.True(t, config.ACHCardFallback)
+	assert.Contains(t, config.OverriddenFields, "ach_card_fallback")
+}
+
+func TestResolveMerchantConfig_IncludeTransactionTree(t *testing.T) {
+	assert.False(t, DefaultMerchantConfig(MerchantTierEnterprise).IncludeTransactionTree, "lean responses by default")
+
+	enabled := true
+	config := ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{IncludeTransactionTree: &enabled})
+	assert.True(t, config.IncludeTransactionTree)
+	assert.Contains(t, config.OverriddenFields, "include_transaction_tree")
+}
+
+func TestResolveMerchantConfig_VerificationPolicies(t *testing.T) {
+	defaults := DefaultMerchantConfig(MerchantTierEnterprise)
+	assert.Equal(t, VerificationPolicyOff, defaults.AVSPolicy)
+	assert.Equal(t, VerificationPolicyOff, defaults.CVVPolicy)
+
+	strict, bogus := VerificationPolicyStrict, VerificationPolicy("paranoid")
+	config := ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{CVVPolicy: &strict, AVSPolicy: &bogus})
+	assert.Equal(t, VerificationPolicyStrict, config.CVVPolicy)
+	assert.Equal(t, VerificationPolicyOff, config.AVSPolicy, "invalid policy is ignored")
+	assert.Contains(t, config.OverriddenFields, "cvv_policy")
+	assert.NotContains(t, config.OverriddenFields, "avs_policy")
+}
+
+func TestResolveMerchantConfig_SavedPaymentMethodCap(t *testing.T) {
+	defaults := DefaultMerchantConfig(MerchantTierEnterprise)
+	assert.Equal(t, 0, defaults.MaxSavedPaymentMethods, "unlimited by default")
+	assert.Equal(t, SavedPaymentMethodPolicyReject, defaults.SavedPaymentMethodPolicy)
+
+	max, prune := 5, SavedPaymentMethodPolicyPruneLRU
+	config := ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{MaxSavedPaymentMethods: &max, SavedPaymentMethodPolicy: &prune})
+	assert.Equal(t, 5, config.MaxSavedPaymentMethods)
+	assert.Equal(t, SavedPaymentMethodPolicyPruneLRU, config.SavedPaymentMethodPolicy)
+	assert.Contains(t, config.OverriddenFields, "max_saved_payment_methods")
+	assert.Contains(t, config.OverriddenFields, "saved_payment_method_policy")
+
+	negative, bogus := -1, SavedPaymentMethodPolicy("shuffle")
+	config = ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{MaxSavedPaymentMethods: &negative, SavedPaymentMethodPolicy: &bogus})
+	assert.Equal(t, 0, config.MaxSavedPaymentMethods, "negative caps are ignored")
+	assert.Equal(t, SavedPaymentMethodPolicyReject, config.SavedPaymentMethodPolicy, "invalid policy is ignored")
+}
+
+func TestResolveMerchantConfig_RateLimits(t *testing.T) {
+	standard := DefaultMerchantConfig(MerchantTierStandard)
+	enterprise := DefaultMerchantConfig(MerchantTierEnterprise)
+	assert.Less(t, standard.RequestsPerSecond, enterprise.RequestsPerSecond)
+	assert.Less(t, standard.BurstLimit, enterprise.BurstLimit)
+
+	rps, burst := 2.5, 5
+	config := ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{RequestsPerSecond: &rps, BurstLimit: &burst})
+	assert.Equal(t, 2.5, config.RequestsPerSecond)
+	assert.Equal(t, 5, config.BurstLimit)
+	assert.Contains(t, config.OverriddenFields, "requests_per_second")
+	assert.Contains(t, config.OverriddenFields, "burst_limit")
+
+	zeroRPS, zeroBurst := 0.0, 0
+	config = ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{RequestsPerSecond: &zeroRPS, BurstLimit: &zeroBurst})
+	assert.Equal(t, standard.RequestsPerSecond, config.RequestsPerSecond, "non-positive limits are ignored")
+	assert.Equal(t, standard.BurstLimit, config.BurstLimit)
+}
+
+func TestResolveMerchantConfig_VelocityOverrides(t *testing.T) {
+	assert.False(t, DefaultMerchantConfig(MerchantTierEnterprise).CustomerVelocity.Enabled(), "velocity limits are off by default")
+
+	config := ResolveMerchantConfig(MerchantTierStandard, &MerchantConfigOverrides{
+		CustomerVelocity: &VelocityLimit{WindowMinutes: 10, MaxCount: 5, MaxAmount: decimal.NewFromInt(300)},
+		CardVelocity:     &VelocityLimit{WindowMinutes: -1, MaxCount: 3},
+	})
+
+	assert.True(t, config.CustomerVelocity.Enabled())
+	assert.Equal(t, 10*time.Minute, config.CustomerVelocity.Window())
+	assert.False(t, config.CardVelocity.Enabled(), "a negative window is ignored")
+	assert.Equal(t, []string{"customer_velocity"}, config.OverriddenFields)
+}
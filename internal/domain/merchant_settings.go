@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// MaxStatementDescriptorLength is the most characters card networks print for a descriptor
+const MaxStatementDescriptorLength = 22
+
+// statementDescriptorPattern accepts the characters card networks print on statements
+var statementDescriptorPattern = regexp.MustCompile(`^[A-Za-z0-9 .,&-]{5,22}$`)
+
+var hasLetter = regexp.MustCompile(`[A-Za-z]`)
+
+var unprintableDescriptorChars = regexp.MustCompile(`[^A-Za-z0-9 .,&-]`)
+
+// ValidateStatementDescriptor checks a merchant's statement descriptor; empty clears it
+func ValidateStatementDescriptor(descriptor string) error {
+	if descriptor == "" {
+		return nil
+	}
+	if !statementDescriptorPattern.MatchString(descriptor) || !hasLetter.MatchString(descriptor) {
+		return fmt.Errorf("%w: expected 5-22 letters, digits, spaces or . , & - with at least one letter", ErrInvalidStatementDescriptor)
+	}
+	return nil
+}
+
+// SanitizeStatementDescriptor makes a descriptor safe to send to EPX: characters networks don't print become
+// spaces, runs of spaces collapse, and anything past 22 characters is cut off
+func SanitizeStatementDescriptor(descriptor string) string {
+	descriptor = strings.Join(strings.Fields(unprintableDescriptorChars.ReplaceAllString(descriptor, " ")), " ")
+	if len(descriptor) > MaxStatementDescriptorLength {
+		descriptor = strings.TrimSpace(descriptor[:MaxStatementDescriptorLength])
+	}
+	return descriptor
+}
+
+// ValidateNotificationEmail checks a merchant's notification address; empty clears it
+func ValidateNotificationEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || len(email) > 254 {
+		return fmt.Errorf("%w: %q", ErrInvalidNotificationEmail, email)
+	}
+	return nil
+}
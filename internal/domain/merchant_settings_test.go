@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateStatementDescriptor(t *testing.T) {
+	tests := []struct {
+		descriptor string
+		wantErr    bool
+	}{
+		{"", false},
+		{"ACME COFFEE", false},
+		{"Acme Co. - Store 12", false},
+		{"B&B INN", false},
+		{"ACME", true},                     // Too short
+		{"ACME COFFEE ROASTERS LLC", true}, // Too long
+		{"12345", true},                    // No letters
+		{"ACME <COFFEE>", true},
+		{"ACME \"COFFEE\"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.descriptor, func(t *testing.T) {
+			err := ValidateStatementDescriptor(tt.descriptor)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidStatementDescriptor)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSanitizeStatementDescriptor(t *testing.T) {
+	assert.Equal(t, "ACME COFFEE", SanitizeStatementDescriptor("ACME COFFEE"))
+	assert.Equal(t, "ACME COFFEE", SanitizeStatementDescriptor("  ACME <COFFEE>\n"))
+	assert.Equal(t, "CAF ROYAL", SanitizeStatementDescriptor("CAFÉ \"ROYAL\""))
+	assert.Equal(t, "ACME COFFEE ROASTERS L", SanitizeStatementDescriptor("ACME COFFEE ROASTERS LLC"), "cut to 22 characters")
+	assert.Equal(t, "ACME COFFEE & TEA BAR", SanitizeStatementDescriptor("ACME COFFEE & TEA BAR LOUNGE"), "no trailing space after the cut")
+	assert.Empty(t, SanitizeStatementDescriptor("<>"))
+}
+
+func TestValidateNotificationEmail(t *testing.T) {
+	assert.NoError(t, ValidateNotificationEmail(""))
+	assert.NoError(t, ValidateNotificationEmail("ops@acme.example"))
+	for _, email := range []string{"ops", "Ops <ops@acme.example>", "ops@acme.example "} {
+		assert.ErrorIs(t, ValidateNotificationEmail(email), ErrInvalidNotificationEmail, email)
+	}
+}
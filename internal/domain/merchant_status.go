@@ -0,0 +1,33 @@
+package domain
+
+// MerchantStatus is where a merchant is in its lifecycle
+type MerchantStatus string
+
+const (
+	MerchantStatusActive    MerchantStatus = "active"    // Everything allowed
+	MerchantStatusSuspended MerchantStatus = "suspended" // No new charges; voids, reversals and refunds still go through. Reversible.
+	MerchantStatusClosed    MerchantStatus = "closed"    // Nothing allowed. Terminal.
+)
+
+// CheckCharge returns why the merchant can't start a new charge (sale, authorization or capture), or nil if it can
+func (s MerchantStatus) CheckCharge() error {
+	switch s {
+	case MerchantStatusActive:
+		return nil
+	case MerchantStatusSuspended:
+		return ErrMerchantSuspended
+	default:
+		return ErrMerchantClosed
+	}
+}
+
+// CheckRefund returns why the merchant can't give money back (void, partial reversal or refund), or nil if it can.
+// Suspended merchants still can, so customers aren't stuck with charges the merchant wants to return.
+func (s MerchantStatus) CheckRefund() error {
+	switch s {
+	case MerchantStatusActive, MerchantStatusSuspended:
+		return nil
+	default:
+		return ErrMerchantClosed
+	}
+}
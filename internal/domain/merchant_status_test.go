@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerchantStatus_PaymentOperations(t *testing.T) {
+	assert.NoError(t, MerchantStatusActive.CheckCharge())
+	assert.NoError(t, MerchantStatusActive.CheckRefund())
+
+	t.Run("suspended merchant can refund but not charge", func(t *testing.T) {
+		assert.ErrorIs(t, MerchantStatusSuspended.CheckCharge(), ErrMerchantSuspended)
+		assert.NoError(t, MerchantStatusSuspended.CheckRefund())
+	})
+
+	t.Run("closed merchant can do neither", func(t *testing.T) {
+		assert.ErrorIs(t, MerchantStatusClosed.CheckCharge(), ErrMerchantClosed)
+		assert.ErrorIs(t, MerchantStatusClosed.CheckRefund(), ErrMerchantClosed)
+	})
+
+	t.Run("unknown status is treated as closed", func(t *testing.T) {
+		assert.ErrorIs(t, MerchantStatus("").CheckCharge(), ErrMerchantClosed)
+		assert.ErrorIs(t, MerchantStatus("").CheckRefund(), ErrMerchantClosed)
+	})
+}
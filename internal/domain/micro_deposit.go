@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// MaxMicroDepositAttempts is how many wrong guesses lock micro-deposit verification
+const MaxMicroDepositAttempts = 3
+
+// Micro-deposit amounts are between 1 and 99 cents
+const (
+	minMicroDepositCents = 1
+	maxMicroDepositCents = 99
+)
+
+// MicroDeposits are the two small credits sent to a bank account to prove the customer can see it
+type MicroDeposits [2]int64 // Amounts in cents
+
+// Amounts returns the deposits as dollar amounts (e.g. "0.32")
+func (m MicroDeposits) Amounts() [2]string {
+	return [2]string{
+		decimal.New(m[0], -2).StringFixed(2),
+		decimal.New(m[1], -2).StringFixed(2),
+	}
+}
+
+// GenerateMicroDeposits picks two random amounts between $0.01 and $0.99
+func GenerateMicroDeposits(random io.Reader) (MicroDeposits, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+
+	var deposits MicroDeposits
+	for i := range deposits {
+		n, err := rand.Int(random, big.NewInt(maxMicroDepositCents-minMicroDepositCents+1))
+		if err != nil {
+			return MicroDeposits{}, fmt.Errorf("failed to generate micro-deposit: %w", err)
+		}
+		deposits[i] = n.Int64() + minMicroDepositCents
+	}
+	return deposits, nil
+}
+
+// ParseMicroDeposits parses the two dollar amounts the customer read off their statement
+func ParseMicroDeposits(amounts []string) (MicroDeposits, error) {
+	if len(amounts) != 2 {
+		return MicroDeposits{}, fmt.Errorf("%w: exactly two amounts are required", ErrInvalidAmount)
+	}
+
+	var deposits MicroDeposits
+	for i, raw := range amounts {
+		amount, err := decimal.NewFromString(strings.TrimSpace(raw))
+		if err != nil {
+			return MicroDeposits{}, fmt.Errorf("%w: %q", ErrInvalidAmount, raw)
+		}
+		cents := amount.Shift(2)
+		if !cents.IsInteger() || cents.LessThan(decimal.NewFromInt(minMicroDepositCents)) || cents.GreaterThan(decimal.NewFromInt(maxMicroDepositCents)) {
+			return MicroDeposits{}, fmt.Errorf("%w: %q is not a micro-deposit amount", ErrInvalidAmount, raw)
+		}
+		deposits[i] = cents.IntPart()
+	}
+	return deposits, nil
+}
+
+// HashMicroDeposits returns "salt:sha256" for storage, so expected amounts are never kept in clear text.
+// Order doesn't matter: customers may enter the deposits either way round.
+func HashMicroDeposits(deposits MicroDeposits, random io.Reader) (string, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(random, salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return hex.EncodeToString(salt) + ":" + microDepositDigest(salt, deposits), nil
+}
+
+// MatchMicroDeposits checks a guess against a stored HashMicroDeposits value (constant time)
+func MatchMicroDeposits(stored string, guess MicroDeposits) bool {
+	saltHex, digest, ok := strings.Cut(stored, ":")
+	if !ok {
+		return false
+	}
+	salt, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(microDepositDigest(salt, guess)), []byte(digest)) == 1
+}
+
+// CheckMicroDepositAttempt validates a verification attempt against the stored hash.
+// attempts is the number of failed attempts so far; a wrong guess returns ErrMicroDepositMismatch,
+// and the attempt that reaches MaxMicroDepositAttempts returns ErrMicroDepositsLocked.
+func CheckMicroDepositAttempt(stored string, attempts int, guess MicroDeposits) error {
+	if stored == "" {
+		return ErrMicroDepositsNotInitiated
+	}
+	if attempts >= MaxMicroDepositAttempts {
+		return ErrMicroDepositsLocked
+	}
+	if MatchMicroDeposits(stored, guess) {
+		return nil
+	}
+	if attempts+1 >= MaxMicroDepositAttempts {
+		return ErrMicroDepositsLocked
+	}
+	return ErrMicroDepositMismatch
+}
+
+func microDepositDigest(salt []byte, deposits MicroDeposits) string {
+	sorted := []int64{deposits[0], deposits[1]}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	h := sha256.New()
+	h.Write(salt)
+	fmt.Fprintf(h, "%d,%d", sorted[0], sorted[1])
+	return hex.EncodeToString(h.Sum(nil))
+}
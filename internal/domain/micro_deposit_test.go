@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMicroDeposits_SuccessfulVerification(t *testing.T) {
+	deposits, err := GenerateMicroDeposits(nil)
+	require.NoError(t, err)
+	for _, cents := range deposits {
+		assert.GreaterOrEqual(t, cents, int64(1))
+		assert.LessOrEqual(t, cents, int64(99))
+	}
+
+	stored, err := HashMicroDeposits(deposits, nil)
+	require.NoError(t, err)
+	amounts := deposits.Amounts()
+	assert.NotContains(t, stored, amounts[0], "amounts are not stored in clear text")
+
+	// The customer may enter the deposits in either order
+	for _, entered := range [][]string{{amounts[0], amounts[1]}, {amounts[1], amounts[0]}} {
+		guess, err := ParseMicroDeposits(entered)
+		require.NoError(t, err)
+		assert.NoError(t, CheckMicroDepositAttempt(stored, 0, guess))
+	}
+}
+
+func TestMicroDeposits_LocksAfterThreeWrongGuesses(t *testing.T) {
+	deposits := MicroDeposits{32, 45}
+	stored, err := HashMicroDeposits(deposits, nil)
+	require.NoError(t, err)
+
+	wrong, err := ParseMicroDeposits([]string{"0.11", "0.12"})
+	require.NoError(t, err)
+
+	attempts := 0
+	for i := 1; i < MaxMicroDepositAttempts; i++ {
+		assert.ErrorIs(t, CheckMicroDepositAttempt(stored, attempts, wrong), ErrMicroDepositMismatch)
+		attempts++
+	}
+
+	assert.ErrorIs(t, CheckMicroDepositAttempt(stored, attempts, wrong), ErrMicroDepositsLocked, "third wrong guess locks")
+	attempts++
+
+	assert.ErrorIs(t, CheckMicroDepositAttempt(stored, attempts, deposits), ErrMicroDepositsLocked, "correct amounts are rejected once locked")
+}
+
+func TestCheckMicroDepositAttempt_NotInitiated(t *testing.T) {
+	assert.ErrorIs(t, CheckMicroDepositAttempt("", 0, MicroDeposits{1, 2}), ErrMicroDepositsNotInitiated)
+}
+
+func TestParseMicroDeposits(t *testing.T) {
+	deposits, err := ParseMicroDeposits([]string{"0.07", " .5 "})
+	require.NoError(t, err)
+	assert.Equal(t, MicroDeposits{7, 50}, deposits)
+
+	for _, amounts := range [][]string{
+		{"0.32"},
+		{"0.32", "0.45", "0.10"},
+		{"0.325", "0.45"},
+		{"1.00", "0.45"},
+		{"0.00", "0.45"},
+		{"abc", "0.45"},
+	} {
+		_, err := ParseMicroDeposits(amounts)
+		assert.ErrorIs(t, err, ErrInvalidAmount, "%v", amounts)
+	}
+}
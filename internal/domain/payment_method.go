@@ -1,16 +1,31 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 )
 
+// SavedPaymentMethodPolicy decides what happens when saving a payment method would pass the merchant's cap
+type SavedPaymentMethodPolicy string
+
+const (
+	SavedPaymentMethodPolicyReject   SavedPaymentMethodPolicy = "reject"    // The new payment method is rejected
+	SavedPaymentMethodPolicyPruneLRU SavedPaymentMethodPolicy = "prune_lru" // The least recently used ones are removed to make room
+)
+
+// IsValid returns true for a known policy
+func (p SavedPaymentMethodPolicy) IsValid() bool {
+	return p == SavedPaymentMethodPolicyReject || p == SavedPaymentMethodPolicyPruneLRU
+}
+
 // PaymentMethod represents a saved payment method (tokenized)
 type PaymentMethod struct {
 	// Identity
 	ID string `json:"id"` // UUID
 
 	// Multi-tenant
-	AgentID string `json:"agent_id"`
+	AgentID    string `json:"agent_id"`
+	DataRegion string `json:"data_region"` // Merchant data residency region when the row was written
 
 	// Customer
 	CustomerID string `json:"customer_id"`
@@ -38,6 +53,15 @@ type PaymentMethod struct {
 	IsActive   bool `json:"is_active"`
 	IsVerified bool `json:"is_verified"` // For ACH pre-note verification
 
+	// ACH returns
+	ReturnCount        int                 `json:"return_count"`
+	LastReturnCode     *string             `json:"last_return_code"`
+	DeactivationReason *DeactivationReason `json:"deactivation_reason"` // Set when deactivated automatically
+
+	// ACH micro-deposit verification
+	MicroDepositsSentAt  *time.Time `json:"micro_deposits_sent_at"`
+	MicroDepositAttempts int        `json:"micro_deposit_attempts"` // Failed attempts (locked at MaxMicroDepositAttempts)
+
 	// Timestamps
 	CreatedAt  time.Time  `json:"created_at"`
 	UpdatedAt  time.Time  `json:"updated_at"`
@@ -75,6 +99,45 @@ func (pm *PaymentMethod) IsExpired() bool {
 	return false
 }
 
+// ValidateCardExpiry checks a card expiration month/year; cards are valid through the end of the expiry month
+func ValidateCardExpiry(month, year int, now time.Time) error {
+	if month < 1 || month > 12 {
+		return fmt.Errorf("%w: month must be 1-12", ErrInvalidCardExpiry)
+	}
+	if year < now.Year() || (year == now.Year() && month < int(now.Month())) {
+		return fmt.Errorf("%w: %02d/%d is in the past", ErrInvalidCardExpiry, month, year)
+	}
+	if year > now.Year()+20 {
+		return fmt.Errorf("%w: year %d is too far in the future", ErrInvalidCardExpiry, year)
+	}
+	return nil
+}
+
+// ExpiresWithin returns true if the card is still valid at now but expires within the given number of days
+// (the last valid day, the end of the expiry month, falls on or before now + days)
+func (pm *PaymentMethod) ExpiresWithin(now time.Time, days int) bool {
+	if !pm.IsCreditCard() || pm.CardExpMonth == nil || pm.CardExpYear == nil {
+		return false
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	firstInvalidDay := time.Date(*pm.CardExpYear, time.Month(*pm.CardExpMonth)+1, 1, 0, 0, 0, 0, time.UTC)
+	cutoff := today.AddDate(0, 0, days)
+
+	return firstInvalidDay.After(today) && !firstInvalidDay.After(cutoff.AddDate(0, 0, 1))
+}
+
+// DaysUntilExpiry returns the number of days from now to the card's last valid day (0 = expires today)
+func (pm *PaymentMethod) DaysUntilExpiry(now time.Time) int {
+	if pm.CardExpMonth == nil || pm.CardExpYear == nil {
+		return 0
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	lastValidDay := time.Date(*pm.CardExpYear, time.Month(*pm.CardExpMonth)+1, 0, 0, 0, 0, 0, time.UTC)
+	return int(lastValidDay.Sub(today).Hours() / 24)
+}
+
 // CanBeUsed returns true if the payment method can be used for transactions
 func (pm *PaymentMethod) CanBeUsed() bool {
 	if !pm.IsActive {
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCardExpiry(t *testing.T) {
+	now := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		month   int
+		year    int
+		wantErr bool
+	}{
+		{"reissued card valid for years", 9, 2029, false},
+		{"expires this month is still valid", 6, 2025, false},
+		{"last month is expired", 5, 2025, true},
+		{"last year is expired", 12, 2024, true},
+		{"month out of range", 13, 2027, true},
+		{"month zero", 0, 2027, true},
+		{"implausibly far future", 1, 2060, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCardExpiry(tt.month, tt.year, now)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCardExpiry)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPaymentMethod_ExpiryUpdateMakesCardUsable(t *testing.T) {
+	month, year := 1, 2020
+	pm := &PaymentMethod{PaymentType: PaymentMethodTypeCreditCard, IsActive: true, CardExpMonth: &month, CardExpYear: &year}
+	assert.False(t, pm.CanBeUsed())
+
+	newYear := time.Now().Year() + 3
+	assert.NoError(t, ValidateCardExpiry(month, newYear, time.Now()))
+	pm.CardExpYear = &newYear
+	assert.True(t, pm.CanBeUsed())
+}
+
+func TestPaymentMethod_ExpiresWithin(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+	card := func(month, year int) *PaymentMethod {
+		return &PaymentMethod{PaymentType: PaymentMethodTypeCreditCard, IsActive: true, CardExpMonth: &month, CardExpYear: &year}
+	}
+
+	tests := []struct {
+		name       string
+		pm         *PaymentMethod
+		withinDays int
+		want       bool
+		wantDays   int
+	}{
+		{"expires this month", card(6, 2025), 30, true, 15},
+		{"expires next month, outside 30 days", card(7, 2025), 30, false, 46},
+		{"expires next month, inside 60 days", card(7, 2025), 60, true, 46},
+		{"already expired", card(5, 2025), 30, false, -15},
+		{"expires years from now", card(9, 2029), 30, false, 1568},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.pm.ExpiresWithin(now, tt.withinDays))
+			assert.Equal(t, tt.wantDays, tt.pm.DaysUntilExpiry(now))
+		})
+	}
+
+	ach := &PaymentMethod{PaymentType: PaymentMethodTypeACH, IsActive: true}
+	assert.False(t, ach.ExpiresWithin(now, 365), "ACH accounts don't expire")
+
+	lastDay := time.Date(2025, 6, 30, 23, 0, 0, 0, time.UTC)
+	assert.True(t, card(6, 2025).ExpiresWithin(lastDay, 1), "still valid on the last day of the expiry month")
+	assert.Equal(t, 0, card(6, 2025).DaysUntilExpiry(lastDay))
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ValidateReturnURL checks that a merchant-supplied return URL is an absolute http(s) URL.
+// The URL is echoed into redirect HTML, so other schemes (javascript:, data:, ...) are rejected.
+// requireHTTPS rejects plain http (production).
+func ValidateReturnURL(raw string, requireHTTPS bool) error {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidReturnURL, err)
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "https":
+	case "http":
+		if requireHTTPS {
+			return fmt.Errorf("%w: https is required", ErrInvalidReturnURL)
+		}
+	default:
+		return fmt.Errorf("%w: scheme must be http or https", ErrInvalidReturnURL)
+	}
+
+	if parsed.Host == "" {
+		return fmt.Errorf("%w: host is required", ErrInvalidReturnURL)
+	}
+
+	return nil
+}
+
+// CheckReturnURLHost checks that a return URL points at one of the allowed hosts. An entry matches the URL's
+// host exactly (case-insensitive, port ignored), and "*.example.com" also matches any subdomain of example.com.
+// An empty allowlist allows nothing, and blank entries are skipped.
+func CheckReturnURLHost(raw string, allowedHosts []string) error {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidReturnURL, err)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == "" || allowed == "*." {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return nil
+			}
+		} else if host == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: host %q is not allowed for this merchant", ErrInvalidReturnURL, host)
+}
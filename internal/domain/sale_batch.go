@@ -0,0 +1,13 @@
+package domain
+
+// SaleBatchItem is the outcome of one item of a batch sale
+type SaleBatchItem struct {
+	Transaction *Transaction // Recorded sale, approved or declined (nil if the item failed)
+	Error       string       // Why no sale was recorded for the item (empty when Transaction is set)
+}
+
+// SaleBatchResult holds a batch sale's item outcomes in request order
+type SaleBatchResult struct {
+	Items    []SaleBatchItem
+	Replayed bool // Returned from an earlier run with the same batch idempotency key (transactions in their current state)
+}
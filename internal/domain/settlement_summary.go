@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MaxSettlementSummaryDays caps the date range of one settlement summary
+const MaxSettlementSummaryDays = 366
+
+// Kinds of money movement totalled in a settlement summary
+const (
+	SettlementKindSale   = "sale"   // Completed sales and captures
+	SettlementKindRefund = "refund" // Completed refunds
+	SettlementKindVoid   = "void"   // Voids of payments that had captured money
+)
+
+// SettlementTotal is the count and amount of one kind of movement on one day, in one currency
+type SettlementTotal struct {
+	Day      time.Time // UTC date
+	Currency string
+	Kind     string // One of the SettlementKind* values
+	Count    int
+	Amount   decimal.Decimal
+}
+
+// SettlementDay is a merchant's money movement on one day (UTC) in one currency
+type SettlementDay struct {
+	Date        time.Time
+	Currency    string
+	GrossSales  decimal.Decimal
+	SaleCount   int
+	Refunds     decimal.Decimal
+	RefundCount int
+	Voids       decimal.Decimal
+	VoidCount   int
+	Net         decimal.Decimal // GrossSales - Refunds - Voids
+}
+
+// TransactionCount is the number of transactions behind the day's totals
+func (d *SettlementDay) TransactionCount() int {
+	return d.SaleCount + d.RefundCount + d.VoidCount
+}
+
+// SettlementRange validates a summary's date range and returns it as [from, to) in UTC; end is inclusive
+func SettlementRange(start, end time.Time) (time.Time, time.Time, error) {
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: end_date is before start_date", ErrInvalidFilter)
+	}
+	if to.Sub(from) > MaxSettlementSummaryDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: at most %d days can be summarized", ErrInvalidFilter, MaxSettlementSummaryDays)
+	}
+	return from, to, nil
+}
+
+// SummarizeSettlement folds per-kind totals into one entry per day and currency, oldest day first
+func SummarizeSettlement(totals []SettlementTotal) []*SettlementDay {
+	type dayKey struct {
+		day      time.Time
+		currency string
+	}
+	byDay := make(map[dayKey]*SettlementDay)
+	for _, total := range totals {
+		key := dayKey{total.Day, total.Currency}
+		day, ok := byDay[key]
+		if !ok {
+			day = &SettlementDay{Date: total.Day, Currency: total.Currency}
+			byDay[key] = day
+		}
+
+		switch total.Kind {
+		case SettlementKindSale:
+			day.GrossSales = day.GrossSales.Add(total.Amount)
+			day.SaleCount += total.Count
+		case SettlementKindRefund:
+			day.Refunds = day.Refunds.Add(total.Amount)
+			day.RefundCount += total.Count
+		case SettlementKindVoid:
+			day.Voids = day.Voids.Add(total.Amount)
+			day.VoidCount += total.Count
+		}
+	}
+
+	days := make([]*SettlementDay, 0, len(byDay))
+	for _, day := range byDay {
+		day.Net = day.GrossSales.Sub(day.Refunds).Sub(day.Voids)
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		if !days[i].Date.Equal(days[j].Date) {
+			return days[i].Date.Before(days[j].Date)
+		}
+		return days[i].Currency < days[j].Currency
+	})
+	return days
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeSettlement_MixedDay(t *testing.T) {
+	june1 := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	june2 := june1.AddDate(0, 0, 1)
+	amount := decimal.RequireFromString
+
+	days := SummarizeSettlement([]SettlementTotal{
+		{Day: june1, Currency: "USD", Kind: SettlementKindRefund, Count: 2, Amount: amount("35.00")},
+		{Day: june1, Currency: "USD", Kind: SettlementKindSale, Count: 5, Amount: amount("250.50")},
+		{Day: june1, Currency: "USD", Kind: SettlementKindVoid, Count: 1, Amount: amount("20.00")},
+		{Day: june2, Currency: "USD", Kind: SettlementKindRefund, Count: 1, Amount: amount("10.00")},
+		{Day: june1, Currency: "CAD", Kind: SettlementKindSale, Count: 1, Amount: amount("15.00")},
+	})
+	require.Len(t, days, 3)
+
+	usd := days[1]
+	assert.Equal(t, june1, usd.Date)
+	assert.Equal(t, "USD", usd.Currency)
+	assert.Equal(t, "250.50", usd.GrossSales.StringFixed(2))
+	assert.Equal(t, "35.00", usd.Refunds.StringFixed(2))
+	assert.Equal(t, "20.00", usd.Voids.StringFixed(2))
+	assert.Equal(t, "195.50", usd.Net.StringFixed(2), "sales minus refunds minus voids")
+	assert.Equal(t, 8, usd.TransactionCount())
+
+	assert.Equal(t, "CAD", days[0].Currency, "currencies are never added together")
+	assert.Equal(t, "15.00", days[0].Net.StringFixed(2))
+
+	assert.Equal(t, june2, days[2].Date)
+	assert.Equal(t, "-10.00", days[2].Net.StringFixed(2), "a refund-only day nets negative")
+}
+
+func TestSettlementRange(t *testing.T) {
+	start := time.Date(2025, 6, 1, 15, 30, 0, 0, time.UTC)
+
+	from, to, err := SettlementRange(start, start)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), to, "end date is inclusive")
+
+	_, _, err = SettlementRange(start, start.AddDate(0, 0, -1))
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+
+	_, _, err = SettlementRange(start, start.AddDate(0, 0, MaxSettlementSummaryDays))
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+}
@@ -39,8 +39,9 @@ type Subscription struct {
 	CustomerID string `json:"customer_id"`
 
 	// Billing details
-	Amount   decimal.Decimal `json:"amount"`
-	Currency string          `json:"currency"` // ISO 4217 code
+	Amount          decimal.Decimal `json:"amount"`
+	Currency        string          `json:"currency"`          // ISO 4217 code
+	AmountChangedAt *time.Time      `json:"amount_changed_at"` // Last amount change (NULL if never changed)
 
 	// Billing interval (e.g., 1 month, 2 weeks, 3 months)
 	IntervalValue int          `json:"interval_value"` // 1, 2, 3, etc.
@@ -51,7 +52,9 @@ type Subscription struct {
 	NextBillingDate time.Time          `json:"next_billing_date"`
 
 	// Payment method (must be a saved payment method)
-	PaymentMethodID string `json:"payment_method_id"` // UUID reference
+	PaymentMethodID         string     `json:"payment_method_id"`          // UUID reference
+	PreviousPaymentMethodID *string    `json:"previous_payment_method_id"` // Set when dunning switched payment methods
+	PaymentMethodSwitchedAt *time.Time `json:"payment_method_switched_at"`
 
 	// Gateway reference
 	GatewaySubscriptionID *string `json:"gateway_subscription_id"` // EPX subscription ID (if applicable)
@@ -60,6 +63,11 @@ type Subscription struct {
 	FailureRetryCount int `json:"failure_retry_count"`
 	MaxRetries        int `json:"max_retries"`
 
+	// Discount
+	CouponID           *string    `json:"coupon_id"`            // UUID reference
+	CouponAppliedCount int        `json:"coupon_applied_count"` // Charges discounted so far
+	CouponEndsAt       *time.Time `json:"coupon_ends_at"`       // Repeating coupons only
+
 	// Metadata
 	Metadata map[string]interface{} `json:"metadata"`
 
@@ -127,3 +135,40 @@ func (s *Subscription) GetIntervalDescription() string {
 	}
 	return strconv.Itoa(s.IntervalValue) + " " + string(s.IntervalUnit) + "s"
 }
+
+// SubscriptionChargeAttempt is one billing attempt of a subscription, approved or declined
+type SubscriptionChargeAttempt struct {
+	TransactionID string
+	AttemptedAt   time.Time
+	Amount        decimal.Decimal
+	Currency      string
+	Status        TransactionStatus
+	AuthResp      string // EPX response code
+	DeclineCode   string // e.g. "EPX_51" (empty when approved)
+	DeclineReason string // e.g. "Insufficient funds" (empty when approved)
+	RetryNumber   int    // 0 for a billing cycle's first attempt, then 1, 2, ... for each retry after a decline
+}
+
+// BillingRunResult summarizes one run of due subscription billing
+type BillingRunResult struct {
+	Processed     int             // Subscriptions due in this batch
+	Succeeded     int             // Charged, or fully discounted and advanced
+	Failed        int             // Declined or errored
+	Retried       int             // Subscriptions attempted again after an earlier failed charge
+	AmountCharged decimal.Decimal // Sum of successful charges
+	Errors        []error
+}
+
+// PlannedCharge is a charge a billing run would make, as reported by a dry run
+type PlannedCharge struct {
+	SubscriptionID  string
+	AgentID         string
+	CustomerID      string
+	PaymentMethodID string
+	PaymentType     string
+	LastFour        string
+	Amount          decimal.Decimal // After any coupon discount; zero means the charge is skipped as fully discounted
+	Currency        string
+	CouponApplied   bool
+	WouldFail       string // Why the run would fail this charge before calling EPX (empty when it would be sent)
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// 3-D Secure authentication statuses (EMV 3DS transStatus)
+const (
+	ThreeDSStatusAuthenticated = "Y" // Fully authenticated
+	ThreeDSStatusAttempted     = "A" // Attempted; the issuer or ACS was unavailable
+	ThreeDSStatusFailed        = "N" // Not authenticated
+	ThreeDSStatusUnavailable   = "U" // Authentication could not be performed
+	ThreeDSStatusRejected      = "R" // Rejected by the issuer
+)
+
+var threeDSStatuses = map[string]bool{
+	ThreeDSStatusAuthenticated: true,
+	ThreeDSStatusAttempted:     true,
+	ThreeDSStatusFailed:        true,
+	ThreeDSStatusUnavailable:   true,
+	ThreeDSStatusRejected:      true,
+}
+
+// ThreeDSecure is the result of a 3-D Secure authentication run by the merchant before a card-not-present
+// authorization. It's sent to EPX with the authorization, stored on the transaction and returned as
+// chargeback evidence for the liability shift.
+type ThreeDSecure struct {
+	Version           string `json:"version,omitempty"`           // Protocol version, e.g. "2.2.0" (EPX takes 3DS 2 only)
+	CAVV              string `json:"cavv,omitempty"`              // Authentication value (CAVV/AAV), base64 (28 chars) or hex (40 chars)
+	ECI               string `json:"eci"`                         // Electronic commerce indicator, e.g. "05"
+	TransactionStatus string `json:"transaction_status"`          // One of the ThreeDSStatus* values
+	DSTransactionID   string `json:"ds_transaction_id,omitempty"` // Directory server transaction ID (UUID, up to 36 chars)
+}
+
+// Validate checks the fields EPX needs: a 3DS 2 version, a two-digit ECI, a known status, a CAVV in one of
+// EPX's formats whenever the status claims authentication or an attempt, and the directory server transaction ID
+// EPX requires for 3DS 2
+func (t *ThreeDSecure) Validate() error {
+	if t.Version != "" && strings.SplitN(t.Version, ".", 2)[0] != "2" {
+		return fmt.Errorf("%w: version %q is not 3-D Secure 2", ErrInvalidThreeDSecure, t.Version)
+	}
+	if len(t.ECI) != 2 || strings.Trim(t.ECI, "0123456789") != "" {
+		return fmt.Errorf("%w: eci must be two digits", ErrInvalidThreeDSecure)
+	}
+	if !threeDSStatuses[t.TransactionStatus] {
+		return fmt.Errorf("%w: unknown transaction_status %q", ErrInvalidThreeDSecure, t.TransactionStatus)
+	}
+	if t.CAVV == "" && (t.TransactionStatus == ThreeDSStatusAuthenticated || t.TransactionStatus == ThreeDSStatusAttempted) {
+		return fmt.Errorf("%w: cavv is required when transaction_status is %s", ErrInvalidThreeDSecure, t.TransactionStatus)
+	}
+	if t.CAVV != "" && !validCAVV(t.CAVV) {
+		return fmt.Errorf("%w: cavv must be 28 base64 or 40 hex characters", ErrInvalidThreeDSecure)
+	}
+	if t.DSTransactionID == "" || len(t.DSTransactionID) > 36 {
+		return fmt.Errorf("%w: ds_transaction_id is required (up to 36 characters)", ErrInvalidThreeDSecure)
+	}
+	return nil
+}
+
+// validCAVV reports whether cavv is in a format EPX accepts for CAVV_UCAF: 28 base64 or 40 hex characters
+func validCAVV(cavv string) bool {
+	switch len(cavv) {
+	case 28:
+		_, err := base64.StdEncoding.DecodeString(cavv)
+		return err == nil
+	case 40:
+		_, err := hex.DecodeString(cavv)
+		return err == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThreeDSecure_Validate(t *testing.T) {
+	dsTranID := "f25084f0-5b16-4c0a-ae5d-b24808a95e4b"
+	valid := ThreeDSecure{Version: "2.2.0", CAVV: "AAABBEg0VhI0VniQEjRWAAAAAAA=", ECI: "05", TransactionStatus: ThreeDSStatusAuthenticated, DSTransactionID: dsTranID}
+	assert.NoError(t, valid.Validate())
+
+	hexCAVV := ThreeDSecure{CAVV: "0123456789abcdef0123456789abcdef01234567", ECI: "02", TransactionStatus: ThreeDSStatusAuthenticated, DSTransactionID: dsTranID}
+	assert.NoError(t, hexCAVV.Validate())
+
+	notAuthenticated := ThreeDSecure{ECI: "07", TransactionStatus: ThreeDSStatusFailed, DSTransactionID: dsTranID}
+	assert.NoError(t, notAuthenticated.Validate(), "no CAVV is issued when authentication fails")
+
+	cavv := valid.CAVV
+	tests := map[string]ThreeDSecure{
+		"3DS 1":                      {Version: "1.0.2", CAVV: cavv, ECI: "05", TransactionStatus: ThreeDSStatusAuthenticated, DSTransactionID: dsTranID},
+		"eci not two digits":         {CAVV: cavv, ECI: "5", TransactionStatus: ThreeDSStatusAuthenticated, DSTransactionID: dsTranID},
+		"eci not numeric":            {CAVV: cavv, ECI: "0A", TransactionStatus: ThreeDSStatusAuthenticated, DSTransactionID: dsTranID},
+		"unknown status":             {CAVV: cavv, ECI: "05", TransactionStatus: "Z", DSTransactionID: dsTranID},
+		"authenticated without cavv": {ECI: "05", TransactionStatus: ThreeDSStatusAuthenticated, DSTransactionID: dsTranID},
+		"attempted without cavv":     {ECI: "06", TransactionStatus: ThreeDSStatusAttempted, DSTransactionID: dsTranID},
+		"cavv in no EPX format":      {CAVV: "x", ECI: "05", TransactionStatus: ThreeDSStatusAuthenticated, DSTransactionID: dsTranID},
+		"without ds transaction id":  {CAVV: cavv, ECI: "05", TransactionStatus: ThreeDSStatusAuthenticated},
+		"ds transaction id too long": {CAVV: cavv, ECI: "05", TransactionStatus: ThreeDSStatusAuthenticated, DSTransactionID: dsTranID + "-0"},
+	}
+	for name, threeDS := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, threeDS.Validate(), ErrInvalidThreeDSecure)
+		})
+	}
+}
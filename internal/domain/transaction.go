@@ -15,17 +15,20 @@ const (
 	TransactionStatusFailed    TransactionStatus = "failed"
 	TransactionStatusRefunded  TransactionStatus = "refunded"
 	TransactionStatusVoided    TransactionStatus = "voided"
+	TransactionStatusExpired   TransactionStatus = "expired" // Browser Post callback never arrived before pending_expires_at
 )
 
 // TransactionType represents the type of transaction
 type TransactionType string
 
 const (
-	TransactionTypeAuth    TransactionType = "auth"     // Authorization only
-	TransactionTypeCapture TransactionType = "capture"  // Capture authorized funds
-	TransactionTypeCharge  TransactionType = "charge"   // Combined auth + capture (sale)
-	TransactionTypeRefund  TransactionType = "refund"   // Return funds
-	TransactionTypePreNote TransactionType = "pre_note" // ACH verification
+	TransactionTypeAuth      TransactionType = "auth"      // Authorization only
+	TransactionTypeCapture   TransactionType = "capture"   // Capture authorized funds
+	TransactionTypeCharge    TransactionType = "charge"    // Combined auth + capture (sale)
+	TransactionTypeRefund    TransactionType = "refund"    // Return funds
+	TransactionTypePreNote   TransactionType = "pre_note"  // ACH verification
+	TransactionTypeReversal  TransactionType = "reversal"  // Partial reversal of an authorization (releases part of the hold)
+	TransactionTypeIncrement TransactionType = "increment" // Incremental authorization (adds to the hold)
 )
 
 // PaymentMethodType represents the payment method used
@@ -64,18 +67,49 @@ type Transaction struct {
 	AuthCardType *string `json:"auth_card_type"` // Card brand ("V"/"M"/"A"/"D") - NULL for ACH
 	AuthAVS      *string `json:"auth_avs"`       // Address verification result
 	AuthCVV2     *string `json:"auth_cvv2"`      // CVV verification result
+	TranNbr      *string `json:"tran_nbr"`       // TRAN_NBR sent to EPX (NULL for transactions older than per-merchant allocation)
+
+	// Card funding type ("credit"/"debit"/"prepaid") - NULL for ACH or when unknown
+	CardFundingType *string `json:"card_funding_type"`
+
+	// Last four digits of the card or bank account a Browser Post payment used (NULL for other payments)
+	LastFour *string `json:"last_four"`
+
+	// Merchant AVS/CVV policy outcome (NULL when no policy was configured)
+	VerificationOutcome *VerificationPolicyOutcome `json:"verification_outcome"`
+
+	// 3-D Secure result sent with the authorization (NULL when the merchant didn't run 3DS)
+	ThreeDS *ThreeDSecure `json:"three_ds"`
+
+	// Data residency region of the merchant when the row was written
+	DataRegion string `json:"data_region"`
 
 	// Idempotency and metadata
-	IdempotencyKey *string                `json:"idempotency_key"`
-	Metadata       map[string]interface{} `json:"metadata"` // Deprecated: Use ExternalReferenceID instead
+	IdempotencyKey  *string                `json:"idempotency_key"`
+	RequestHash     string                 `json:"-"`        // Fingerprint of the keyed request's parameters (empty for older rows)
+	CardFingerprint string                 `json:"-"`        // Card velocity key (see CardFingerprint; empty when the card is unknown)
+	Metadata        map[string]interface{} `json:"metadata"` // Deprecated: Use ExternalReferenceID instead
 
 	// POS Integration (Option 2 architecture)
 	ExternalReferenceID *string `json:"external_reference_id"` // Opaque POS reference (e.g., "order-123")
 	ReturnURL           *string `json:"return_url"`            // POS callback URL for browser redirect
 
+	// When a pending Browser Post transaction stops accepting its callback (NULL for other transactions)
+	PendingExpiresAt *time.Time `json:"pending_expires_at"`
+
+	// Settlement
+	SettledAt   *time.Time `json:"settled_at"`   // NULL until the transaction settles in an EPX batch
+	FundingDate *time.Time `json:"funding_date"` // Expected date funds reach the merchant (date only)
+
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Gateway outcome of the call that created this transaction (not persisted; nil when loaded from storage)
+	Gateway *GatewayResult `json:"-"`
+
+	// Transaction group tree, when requested for a payment operation response (not persisted)
+	Tree *TransactionTree `json:"-"`
 }
 
 // IsApproved returns true if the transaction was approved by the gateway
@@ -100,6 +134,23 @@ func (t *Transaction) CanBeRefunded() bool {
 		(t.Type == TransactionTypeCharge || t.Type == TransactionTypeCapture)
 }
 
+// IsSettled returns true if the transaction has settled (no longer voidable)
+func (t *Transaction) IsSettled() bool {
+	return t.SettledAt != nil
+}
+
+// IsPendingExpired returns true if the transaction is still pending after its expiry. Transactions without
+// pending_expires_at expire once the window has passed since they were created.
+func (t *Transaction) IsPendingExpired(now time.Time, window time.Duration) bool {
+	if t.Status != TransactionStatusPending {
+		return false
+	}
+	if t.PendingExpiresAt != nil {
+		return now.After(*t.PendingExpiresAt)
+	}
+	return now.Sub(t.CreatedAt) > window
+}
+
 // GetAuthGUID safely retrieves the AUTH_GUID
 func (t *Transaction) GetAuthGUID() string {
 	if t.AuthGUID != nil {
@@ -115,3 +166,14 @@ func (t *Transaction) GetCustomerID() string {
 	}
 	return ""
 }
+
+// TransactionStatusResult is one entry of a bulk status lookup
+type TransactionStatusResult struct {
+	TransactionID string       // ID as requested
+	Transaction   *Transaction // nil if not found (or owned by another merchant)
+}
+
+// Found returns true if the requested transaction exists for the merchant
+func (r *TransactionStatusResult) Found() bool {
+	return r.Transaction != nil
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransactionCursor is a keyset position in a newest-first transaction listing.
+// The next page holds the transactions strictly older than (CreatedAt, ID).
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// CursorAfter returns the cursor that continues a listing after tx
+func CursorAfter(tx *Transaction) (*TransactionCursor, error) {
+	id, err := uuid.Parse(tx.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction id: %w", err)
+	}
+	return &TransactionCursor{CreatedAt: tx.CreatedAt, ID: id}, nil
+}
+
+// Encode returns the opaque next_cursor token for clients
+func (c *TransactionCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeTransactionCursor parses a token produced by TransactionCursor.Encode
+func DecodeTransactionCursor(token string) (*TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &TransactionCursor{}
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.ID, err = uuid.Parse(id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionCursor_RoundTrip(t *testing.T) {
+	tx := &Transaction{
+		ID:        uuid.New().String(),
+		CreatedAt: time.Date(2025, 6, 15, 10, 30, 0, 123456000, time.FixedZone("EST", -5*3600)),
+	}
+
+	cursor, err := CursorAfter(tx)
+	require.NoError(t, err)
+
+	decoded, err := DecodeTransactionCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, decoded.CreatedAt.Equal(tx.CreatedAt), "microsecond timestamp survives the round trip")
+	assert.Equal(t, tx.ID, decoded.ID.String())
+}
+
+func TestDecodeTransactionCursor_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "%%%"},
+		{"missing separator", base64.RawURLEncoding.EncodeToString([]byte("2025-06-15T10:30:00Z"))},
+		{"bad timestamp", base64.RawURLEncoding.EncodeToString([]byte("yesterday|" + uuid.NewString()))},
+		{"bad id", base64.RawURLEncoding.EncodeToString([]byte("2025-06-15T10:30:00Z|42"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeTransactionCursor(tt.token)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// TransactionFilter narrows a merchant's transaction listing; nil or empty fields don't filter
+type TransactionFilter struct {
+	CustomerID       *string
+	MetadataContains map[string]string // Every key must be present in the transaction metadata with this (string) value
+	MinAmountCents   *int64            // Inclusive
+	MaxAmountCents   *int64            // Inclusive
+	CreatedFrom      *time.Time        // Inclusive
+	CreatedTo        *time.Time        // Exclusive
+}
+
+// Validate rejects negative or inverted amount bounds, an empty date range and empty metadata keys
+func (f TransactionFilter) Validate() error {
+	if f.MinAmountCents != nil && *f.MinAmountCents < 0 {
+		return fmt.Errorf("%w: min_amount_cents must not be negative", ErrInvalidFilter)
+	}
+	if f.MaxAmountCents != nil && *f.MaxAmountCents < 0 {
+		return fmt.Errorf("%w: max_amount_cents must not be negative", ErrInvalidFilter)
+	}
+	if f.MinAmountCents != nil && f.MaxAmountCents != nil && *f.MinAmountCents > *f.MaxAmountCents {
+		return fmt.Errorf("%w: min_amount_cents is greater than max_amount_cents", ErrInvalidFilter)
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedTo.After(*f.CreatedFrom) {
+		return fmt.Errorf("%w: created_to must be after created_from", ErrInvalidFilter)
+	}
+	for key := range f.MetadataContains {
+		if key == "" {
+			return fmt.Errorf("%w: metadata_contains keys must not be empty", ErrInvalidFilter)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionFilter_Validate(t *testing.T) {
+	cents := func(c int64) *int64 { return &c }
+
+	tests := []struct {
+		name    string
+		filter  TransactionFilter
+		wantErr bool
+	}{
+		{"no filters", TransactionFilter{}, false},
+		{"metadata and range", TransactionFilter{MetadataContains: map[string]string{"order_id": "A-1001"}, MinAmountCents: cents(100), MaxAmountCents: cents(500)}, false},
+		{"single-amount range", TransactionFilter{MinAmountCents: cents(100), MaxAmountCents: cents(100)}, false},
+		{"inverted range", TransactionFilter{MinAmountCents: cents(500), MaxAmountCents: cents(100)}, true},
+		{"negative minimum", TransactionFilter{MinAmountCents: cents(-1)}, true},
+		{"negative maximum", TransactionFilter{MaxAmountCents: cents(-1)}, true},
+		{"empty metadata key", TransactionFilter{MetadataContains: map[string]string{"": "x"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidFilter)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AuthorizationLifetime is how long an issuer holds an authorization before it lapses, counted from the
+// latest approved increment. Card networks guarantee 7 days for most card-not-present authorizations.
+const AuthorizationLifetime = 7 * 24 * time.Hour
+
+// Transaction group statuses (the group as a whole, not its individual transactions)
+const (
+	GroupStatusAuthorized        = "authorized"
+	GroupStatusCaptured          = "captured"
+	GroupStatusPartiallyRefunded = "partially_refunded"
+	GroupStatusRefunded          = "refunded"
+	GroupStatusVoided            = "voided"
+	GroupStatusFailed            = "failed"
+)
+
+// TransactionGroupState summarizes the money movement of a transaction group (auth → captures → refunds)
+type TransactionGroupState struct {
+	Status            string          // One of the GroupStatus* values
+	AuthorizedAmount  decimal.Decimal // Approved auth or sale amount
+	CapturedAmount    decimal.Decimal // Sale amount plus completed captures
+	RefundedAmount    decimal.Decimal // Completed refunds
+	ReversedAmount    decimal.Decimal // Completed partial reversals of the authorization
+	IncrementedAmount decimal.Decimal // Completed incremental authorizations
+	ActiveAuthAmount  decimal.Decimal // Authorized amount plus increments still held after reversals (zero once voided)
+	CapturableAmount  decimal.Decimal // Active authorization not yet captured (zero once voided)
+	RefundableAmount  decimal.Decimal // Captured but not yet refunded (zero once voided)
+	Voided            bool
+	AuthExpiresAt     time.Time // When the open authorization lapses; zero unless one is held
+}
+
+// CheckCapture returns nil if amount can be captured against the group's active authorization
+func (s TransactionGroupState) CheckCapture(amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return ErrInvalidTransactionAmount
+	}
+	if amount.GreaterThan(s.CapturableAmount) {
+		return ErrAmountExceedsAuthorization
+	}
+	return nil
+}
+
+// CheckPartialReversal returns nil if the group's active authorization can be reduced by amount.
+// Only the uncaptured part of an open authorization can be released.
+func (s TransactionGroupState) CheckPartialReversal(amount decimal.Decimal) error {
+	if s.Voided || !s.CapturableAmount.IsPositive() {
+		return ErrTransactionCannotBeReversed
+	}
+	if !amount.IsPositive() {
+		return ErrInvalidTransactionAmount
+	}
+	if amount.GreaterThan(s.CapturableAmount) {
+		return ErrAmountExceedsAuthorization
+	}
+	return nil
+}
+
+// CheckIncrement returns nil if amount can be added to the group's authorization at now. Only an approved,
+// unvoided, uncaptured authorization that hasn't lapsed can be incremented.
+func (s TransactionGroupState) CheckIncrement(amount decimal.Decimal, now time.Time) error {
+	if s.Voided || !s.ActiveAuthAmount.IsPositive() || s.CapturedAmount.IsPositive() {
+		return ErrTransactionCannotBeIncremented
+	}
+	if !now.Before(s.AuthExpiresAt) {
+		return fmt.Errorf("%w: authorization expired at %s", ErrTransactionCannotBeIncremented, s.AuthExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if !amount.IsPositive() {
+		return ErrInvalidTransactionAmount
+	}
+	return nil
+}
+
+// CheckRefund returns nil if amount can be refunded from the group. Completed refunds are summed across the
+// whole group, so partial refunds together never pass the captured amount.
+func (s TransactionGroupState) CheckRefund(amount decimal.Decimal) error {
+	if s.Voided || !s.RefundableAmount.IsPositive() {
+		return ErrTransactionCannotBeRefunded
+	}
+	if !amount.IsPositive() {
+		return ErrInvalidTransactionAmount
+	}
+	if amount.GreaterThan(s.RefundableAmount) {
+		return ErrAmountExceedsRefundable
+	}
+	return nil
+}
+
+// TransactionTree is a group's root transaction (auth or sale) with the transactions that followed it
+type TransactionTree struct {
+	Root     *Transaction   // nil if the group has no auth or sale
+	Children []*Transaction // Increments, captures, reversals, voids and refunds, oldest first
+	State    TransactionGroupState
+}
+
+// BuildTransactionTree arranges a group's transactions (in any order) and computes its state
+func BuildTransactionTree(txs []*Transaction) *TransactionTree {
+	sorted := make([]*Transaction, len(txs))
+	copy(sorted, txs)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].CreatedAt.Before(sorted[j].CreatedAt) })
+
+	tree := &TransactionTree{Children: []*Transaction{}}
+	for _, tx := range sorted {
+		if tree.Root == nil && tx.Status != TransactionStatusVoided &&
+			(tx.Type == TransactionTypeAuth || tx.Type == TransactionTypeCharge) {
+			tree.Root = tx
+			continue
+		}
+		tree.Children = append(tree.Children, tx)
+	}
+
+	tree.State = computeGroupState(tree.Root, tree.Children)
+	return tree
+}
+
+func computeGroupState(root *Transaction, children []*Transaction) TransactionGroupState {
+	state := TransactionGroupState{
+		AuthorizedAmount:  decimal.Zero,
+		CapturedAmount:    decimal.Zero,
+		RefundedAmount:    decimal.Zero,
+		ReversedAmount:    decimal.Zero,
+		IncrementedAmount: decimal.Zero,
+		ActiveAuthAmount:  decimal.Zero,
+		CapturableAmount:  decimal.Zero,
+		RefundableAmount:  decimal.Zero,
+	}
+
+	rootApproved := root != nil && root.Status == TransactionStatusCompleted
+	var lastApproval time.Time
+	if rootApproved {
+		state.AuthorizedAmount = root.Amount
+		lastApproval = root.CreatedAt
+		if root.Type == TransactionTypeCharge {
+			state.CapturedAmount = root.Amount
+		}
+	}
+
+	for _, tx := range children {
+		switch {
+		case tx.Status == TransactionStatusVoided:
+			state.Voided = true
+		case tx.Type == TransactionTypeCapture && tx.Status == TransactionStatusCompleted:
+			state.CapturedAmount = state.CapturedAmount.Add(tx.Amount)
+		case tx.Type == TransactionTypeReversal && tx.Status == TransactionStatusCompleted:
+			state.ReversedAmount = state.ReversedAmount.Add(tx.Amount)
+		case tx.Type == TransactionTypeIncrement && tx.Status == TransactionStatusCompleted:
+			state.IncrementedAmount = state.IncrementedAmount.Add(tx.Amount)
+			if tx.CreatedAt.After(lastApproval) {
+				lastApproval = tx.CreatedAt
+			}
+		case tx.Type == TransactionTypeRefund && tx.Status == TransactionStatusRefunded:
+			state.RefundedAmount = state.RefundedAmount.Add(tx.Amount)
+		}
+	}
+
+	if !state.Voided {
+		if rootApproved && root.Type == TransactionTypeAuth {
+			state.ActiveAuthAmount = decimal.Max(state.AuthorizedAmount.Add(state.IncrementedAmount).Sub(state.ReversedAmount), decimal.Zero)
+			state.CapturableAmount = decimal.Max(state.ActiveAuthAmount.Sub(state.CapturedAmount), decimal.Zero)
+			if state.ActiveAuthAmount.IsPositive() {
+				state.AuthExpiresAt = lastApproval.Add(AuthorizationLifetime)
+			}
+		}
+		state.RefundableAmount = decimal.Max(state.CapturedAmount.Sub(state.RefundedAmount), decimal.Zero)
+	}
+
+	switch {
+	case state.Voided:
+		state.Status = GroupStatusVoided
+	case !rootApproved:
+		state.Status = GroupStatusFailed
+	case state.RefundedAmount.IsPositive() && state.RefundableAmount.IsZero():
+		state.Status = GroupStatusRefunded
+	case state.RefundedAmount.IsPositive():
+		state.Status = GroupStatusPartiallyRefunded
+	case state.CapturedAmount.IsPositive():
+		state.Status = GroupStatusCaptured
+	default:
+		state.Status = GroupStatusAuthorized
+	}
+
+	return state
+}
@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGroupTransaction(txType TransactionType, status TransactionStatus, amount int64, at time.Time) *Transaction {
+	return &Transaction{
+		ID:        string(txType) + "-" + at.Format("150405"),
+		GroupID:   "group-1",
+		Amount:    decimal.NewFromInt(amount),
+		Status:    status,
+		Type:      txType,
+		CreatedAt: at,
+	}
+}
+
+func TestBuildTransactionTree(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	auth := newGroupTransaction(TransactionTypeAuth, TransactionStatusCompleted, 100, t0)
+	capture := newGroupTransaction(TransactionTypeCapture, TransactionStatusCompleted, 60, t0.Add(time.Minute))
+	refund := newGroupTransaction(TransactionTypeRefund, TransactionStatusRefunded, 20, t0.Add(2*time.Minute))
+	failedRefund := newGroupTransaction(TransactionTypeRefund, TransactionStatusFailed, 40, t0.Add(3*time.Minute))
+
+	t.Run("auth with partial capture", func(t *testing.T) {
+		tree := BuildTransactionTree([]*Transaction{capture, auth})
+		require.NotNil(t, tree.Root)
+		assert.Equal(t, auth.ID, tree.Root.ID)
+		assert.Equal(t, []*Transaction{capture}, tree.Children)
+
+		assert.Equal(t, GroupStatusCaptured, tree.State.Status)
+		assert.True(t, tree.State.CapturedAmount.Equal(decimal.NewFromInt(60)))
+		assert.True(t, tree.State.CapturableAmount.Equal(decimal.NewFromInt(40)))
+		assert.True(t, tree.State.RefundableAmount.Equal(decimal.NewFromInt(60)))
+	})
+
+	t.Run("failed refunds don't count", func(t *testing.T) {
+		tree := BuildTransactionTree([]*Transaction{auth, capture, refund, failedRefund})
+		assert.Equal(t, GroupStatusPartiallyRefunded, tree.State.Status)
+		assert.True(t, tree.State.RefundedAmount.Equal(decimal.NewFromInt(20)))
+		assert.True(t, tree.State.RefundableAmount.Equal(decimal.NewFromInt(40)))
+		assert.Len(t, tree.Children, 3)
+	})
+
+	t.Run("fully refunded sale", func(t *testing.T) {
+		sale := newGroupTransaction(TransactionTypeCharge, TransactionStatusCompleted, 20, t0)
+		tree := BuildTransactionTree([]*Transaction{sale, refund})
+		assert.Equal(t, GroupStatusRefunded, tree.State.Status)
+		assert.True(t, tree.State.RefundableAmount.IsZero())
+		assert.True(t, tree.State.CapturableAmount.IsZero(), "sales have nothing left to capture")
+	})
+
+	t.Run("voided auth", func(t *testing.T) {
+		void := newGroupTransaction(TransactionTypeCharge, TransactionStatusVoided, 100, t0.Add(time.Minute))
+		tree := BuildTransactionTree([]*Transaction{void, auth})
+		assert.Equal(t, auth.ID, tree.Root.ID, "the void record is never the root")
+		assert.Equal(t, GroupStatusVoided, tree.State.Status)
+		assert.True(t, tree.State.Voided)
+		assert.True(t, tree.State.CapturableAmount.IsZero())
+	})
+
+	t.Run("declined sale", func(t *testing.T) {
+		declined := newGroupTransaction(TransactionTypeCharge, TransactionStatusFailed, 100, t0)
+		tree := BuildTransactionTree([]*Transaction{declined})
+		assert.Equal(t, GroupStatusFailed, tree.State.Status)
+		assert.True(t, tree.State.AuthorizedAmount.IsZero())
+	})
+}
+
+func TestTransactionGroupState_CheckRefund(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	sale := newGroupTransaction(TransactionTypeCharge, TransactionStatusCompleted, 100, t0)
+	first := newGroupTransaction(TransactionTypeRefund, TransactionStatusRefunded, 60, t0.Add(time.Minute))
+	declined := newGroupTransaction(TransactionTypeRefund, TransactionStatusFailed, 40, t0.Add(2*time.Minute))
+
+	state := BuildTransactionTree([]*Transaction{sale, first, declined}).State
+	assert.True(t, state.RefundableAmount.Equal(decimal.NewFromInt(40)))
+	assert.NoError(t, state.CheckRefund(decimal.NewFromInt(40)), "two partial refunds may add up to exactly the captured amount")
+	assert.ErrorIs(t, state.CheckRefund(decimal.RequireFromString("40.01")), ErrAmountExceedsRefundable)
+	assert.ErrorIs(t, state.CheckRefund(decimal.Zero), ErrInvalidTransactionAmount)
+
+	second := newGroupTransaction(TransactionTypeRefund, TransactionStatusRefunded, 40, t0.Add(3*time.Minute))
+	state = BuildTransactionTree([]*Transaction{sale, first, declined, second}).State
+	assert.True(t, state.RefundedAmount.Equal(decimal.NewFromInt(100)), "every completed refund counts, not just the latest")
+	assert.Equal(t, GroupStatusRefunded, state.Status)
+	assert.ErrorIs(t, state.CheckRefund(decimal.RequireFromString("0.01")), ErrTransactionCannotBeRefunded, "a third refund would pass the captured amount")
+
+	auth := newGroupTransaction(TransactionTypeAuth, TransactionStatusCompleted, 100, t0)
+	assert.ErrorIs(t, BuildTransactionTree([]*Transaction{auth}).State.CheckRefund(decimal.NewFromInt(10)), ErrTransactionCannotBeRefunded, "nothing captured yet")
+}
+
+func TestTransactionGroupState_PartialReversal(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	auth := newGroupTransaction(TransactionTypeAuth, TransactionStatusCompleted, 100, t0)
+	reversal := newGroupTransaction(TransactionTypeReversal, TransactionStatusCompleted, 30, t0.Add(time.Minute))
+	declinedReversal := newGroupTransaction(TransactionTypeReversal, TransactionStatusFailed, 50, t0.Add(2*time.Minute))
+
+	t.Run("reduced auth bounds captures", func(t *testing.T) {
+		state := BuildTransactionTree([]*Transaction{auth, reversal, declinedReversal}).State
+		assert.Equal(t, GroupStatusAuthorized, state.Status)
+		assert.True(t, state.ReversedAmount.Equal(decimal.NewFromInt(30)), "declined reversals don't count")
+		assert.True(t, state.ActiveAuthAmount.Equal(decimal.NewFromInt(70)))
+		assert.True(t, state.CapturableAmount.Equal(decimal.NewFromInt(70)))
+
+		assert.NoError(t, state.CheckCapture(decimal.NewFromInt(70)))
+		assert.ErrorIs(t, state.CheckCapture(decimal.RequireFromString("70.01")), ErrAmountExceedsAuthorization)
+		assert.ErrorIs(t, state.CheckCapture(decimal.Zero), ErrInvalidTransactionAmount)
+	})
+
+	t.Run("capture after reduction leaves the remainder", func(t *testing.T) {
+		capture := newGroupTransaction(TransactionTypeCapture, TransactionStatusCompleted, 50, t0.Add(3*time.Minute))
+		state := BuildTransactionTree([]*Transaction{auth, reversal, capture}).State
+		assert.Equal(t, GroupStatusCaptured, state.Status)
+		assert.True(t, state.ActiveAuthAmount.Equal(decimal.NewFromInt(70)))
+		assert.True(t, state.CapturableAmount.Equal(decimal.NewFromInt(20)))
+
+		assert.NoError(t, state.CheckCapture(decimal.NewFromInt(20)))
+		assert.ErrorIs(t, state.CheckCapture(decimal.NewFromInt(21)), ErrAmountExceedsAuthorization)
+		assert.NoError(t, state.CheckPartialReversal(decimal.NewFromInt(20)))
+		assert.ErrorIs(t, state.CheckPartialReversal(decimal.NewFromInt(21)), ErrAmountExceedsAuthorization)
+	})
+
+	t.Run("only open authorizations can be reduced", func(t *testing.T) {
+		sale := newGroupTransaction(TransactionTypeCharge, TransactionStatusCompleted, 100, t0)
+		assert.ErrorIs(t, BuildTransactionTree([]*Transaction{sale}).State.CheckPartialReversal(decimal.NewFromInt(10)), ErrTransactionCannotBeReversed)
+
+		void := newGroupTransaction(TransactionTypeCharge, TransactionStatusVoided, 100, t0.Add(time.Minute))
+		voided := BuildTransactionTree([]*Transaction{auth, void}).State
+		assert.True(t, voided.ActiveAuthAmount.IsZero())
+		assert.ErrorIs(t, voided.CheckPartialReversal(decimal.NewFromInt(10)), ErrTransactionCannotBeReversed)
+	})
+}
+
+func TestTransactionGroupState_Increment(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	auth := newGroupTransaction(TransactionTypeAuth, TransactionStatusCompleted, 100, t0)
+	increment := newGroupTransaction(TransactionTypeIncrement, TransactionStatusCompleted, 50, t0.Add(48*time.Hour))
+	declinedIncrement := newGroupTransaction(TransactionTypeIncrement, TransactionStatusFailed, 500, t0.Add(49*time.Hour))
+
+	t.Run("increment raises the capturable amount", func(t *testing.T) {
+		state := BuildTransactionTree([]*Transaction{auth, increment, declinedIncrement}).State
+		assert.Equal(t, GroupStatusAuthorized, state.Status)
+		assert.True(t, state.AuthorizedAmount.Equal(decimal.NewFromInt(100)), "the original amount is unchanged")
+		assert.True(t, state.IncrementedAmount.Equal(decimal.NewFromInt(50)), "declined increments don't count")
+		assert.True(t, state.ActiveAuthAmount.Equal(decimal.NewFromInt(150)))
+		assert.True(t, state.CapturableAmount.Equal(decimal.NewFromInt(150)))
+		assert.Equal(t, increment.CreatedAt.Add(AuthorizationLifetime), state.AuthExpiresAt, "an increment extends the hold")
+
+		assert.NoError(t, state.CheckCapture(decimal.NewFromInt(150)))
+		assert.ErrorIs(t, state.CheckCapture(decimal.RequireFromString("150.01")), ErrAmountExceedsAuthorization)
+		assert.NoError(t, state.CheckIncrement(decimal.NewFromInt(25), t0.Add(72*time.Hour)))
+		assert.ErrorIs(t, state.CheckIncrement(decimal.Zero, t0.Add(72*time.Hour)), ErrInvalidTransactionAmount)
+	})
+
+	t.Run("increments and reversals net out", func(t *testing.T) {
+		reversal := newGroupTransaction(TransactionTypeReversal, TransactionStatusCompleted, 30, t0.Add(50*time.Hour))
+		state := BuildTransactionTree([]*Transaction{auth, increment, reversal}).State
+		assert.True(t, state.ActiveAuthAmount.Equal(decimal.NewFromInt(120)))
+	})
+
+	t.Run("captured and voided authorizations can't be incremented", func(t *testing.T) {
+		capture := newGroupTransaction(TransactionTypeCapture, TransactionStatusCompleted, 40, t0.Add(time.Hour))
+		captured := BuildTransactionTree([]*Transaction{auth, capture}).State
+		assert.ErrorIs(t, captured.CheckIncrement(decimal.NewFromInt(10), t0.Add(2*time.Hour)), ErrTransactionCannotBeIncremented)
+
+		void := newGroupTransaction(TransactionTypeAuth, TransactionStatusVoided, 100, t0.Add(time.Hour))
+		voided := BuildTransactionTree([]*Transaction{auth, void}).State
+		assert.True(t, voided.AuthExpiresAt.IsZero())
+		assert.ErrorIs(t, voided.CheckIncrement(decimal.NewFromInt(10), t0.Add(2*time.Hour)), ErrTransactionCannotBeIncremented)
+
+		sale := newGroupTransaction(TransactionTypeCharge, TransactionStatusCompleted, 100, t0)
+		assert.ErrorIs(t, BuildTransactionTree([]*Transaction{sale}).State.CheckIncrement(decimal.NewFromInt(10), t0), ErrTransactionCannotBeIncremented)
+	})
+
+	t.Run("lapsed authorizations can't be incremented", func(t *testing.T) {
+		state := BuildTransactionTree([]*Transaction{auth}).State
+		assert.NoError(t, state.CheckIncrement(decimal.NewFromInt(10), t0.Add(AuthorizationLifetime-time.Second)))
+
+		err := state.CheckIncrement(decimal.NewFromInt(10), t0.Add(AuthorizationLifetime))
+		assert.ErrorIs(t, err, ErrTransactionCannotBeIncremented)
+		assert.Contains(t, err.Error(), "authorization expired at 2025-06-08T12:00:00Z")
+	})
+}
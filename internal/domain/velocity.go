@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// VelocityLimit caps how many sales and authorizations one card or customer can make, and for how much,
+// within a rolling window. It curbs card testing: many small charges against one card in quick succession.
+type VelocityLimit struct {
+	WindowMinutes int             `json:"window_minutes"` // Rolling window; 0 = limit off
+	MaxCount      int             `json:"max_count"`      // Attempts allowed in the window, declines included (0 = uncapped)
+	MaxAmount     decimal.Decimal `json:"max_amount"`     // Total of attempts not declined in the window (0 = uncapped)
+}
+
+// Enabled returns true when the limit has a window and at least one cap
+func (l VelocityLimit) Enabled() bool {
+	return l.WindowMinutes > 0 && (l.MaxCount > 0 || l.MaxAmount.IsPositive())
+}
+
+// Window returns the limit's rolling window
+func (l VelocityLimit) Window() time.Duration {
+	return time.Duration(l.WindowMinutes) * time.Minute
+}
+
+// CardFingerprint identifies a card for velocity limits without keeping card data: a hash of its last four digits
+// and expiry when both are known (saved cards, Browser Post callbacks), otherwise of the BRIC it's charged with.
+// EPX returns no BIN, so cards at one merchant sharing last four and expiry share a fingerprint. Returns "" when
+// nothing identifies the card.
+func CardFingerprint(lastFour string, expMonth, expYear int, bric string) string {
+	if len(lastFour) == 4 && expMonth >= 1 && expMonth <= 12 && expYear > 0 {
+		return RequestFingerprint("card", lastFour, fmt.Sprintf("%02d%02d", expYear%100, expMonth))
+	}
+	if bric != "" {
+		return RequestFingerprint("bric", bric)
+	}
+	return ""
+}
+
+// VelocityUsage is what a card or customer has already done within a velocity window
+type VelocityUsage struct {
+	Count  int
+	Amount decimal.Decimal
+}
+
+// Check returns ErrVelocityExceeded if one more attempt for amount would pass the limit. subject names what is
+// limited ("card" or "customer") in the error.
+func (l VelocityLimit) Check(subject string, usage VelocityUsage, amount decimal.Decimal) error {
+	if !l.Enabled() {
+		return nil
+	}
+	if l.MaxCount > 0 && usage.Count+1 > l.MaxCount {
+		return fmt.Errorf("%w: %s already made %d transactions in %d minutes (limit %d)",
+			ErrVelocityExceeded, subject, usage.Count, l.WindowMinutes, l.MaxCount)
+	}
+	if l.MaxAmount.IsPositive() && usage.Amount.Add(amount).GreaterThan(l.MaxAmount) {
+		return fmt.Errorf("%w: %s would total %s in %d minutes (limit %s)",
+			ErrVelocityExceeded, subject, usage.Amount.Add(amount).StringFixed(2), l.WindowMinutes, l.MaxAmount.StringFixed(2))
+	}
+	return nil
+}
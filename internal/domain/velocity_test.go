@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardFingerprint(t *testing.T) {
+	saved := CardFingerprint("4242", 12, 2028, "0A1MQQ3K2XTBVW8Y0Z1")
+	assert.Equal(t, saved, CardFingerprint("4242", 12, 28, "0A1MQQ3K2XTBVW8Y0Z2"), "last four and expiry identify the card whatever its BRIC")
+	assert.NotEqual(t, saved, CardFingerprint("4242", 11, 2028, "0A1MQQ3K2XTBVW8Y0Z1"))
+	assert.Equal(t, CardFingerprint("", 0, 0, "0A1MQQ3K2XTBVW8Y0Z1"), CardFingerprint("4242", 0, 0, "0A1MQQ3K2XTBVW8Y0Z1"), "without an expiry the BRIC identifies it")
+	assert.Empty(t, CardFingerprint("", 0, 0, ""))
+}
@@ -0,0 +1,91 @@
+package domain
+
+// VerificationPolicy is how strictly a merchant treats an AVS or CVV result
+type VerificationPolicy string
+
+const (
+	VerificationPolicyOff     VerificationPolicy = ""        // Not evaluated
+	VerificationPolicyLenient VerificationPolicy = "lenient" // Fails only on an explicit mismatch
+	VerificationPolicyStrict  VerificationPolicy = "strict"  // Fails unless the check fully matched
+)
+
+// Checks a verification policy can fail
+const (
+	VerificationCheckAVS = "avs"
+	VerificationCheckCVV = "cvv"
+)
+
+// Verification policy results
+const (
+	PolicyResultPass = "pass"
+	PolicyResultFail = "fail"
+)
+
+// IsValid returns true for a known policy (including off)
+func (p VerificationPolicy) IsValid() bool {
+	switch p {
+	case VerificationPolicyOff, VerificationPolicyLenient, VerificationPolicyStrict:
+		return true
+	}
+	return false
+}
+
+// VerificationPolicyOutcome is the merchant's AVS/CVV policy evaluated against a transaction's results.
+// It is recorded for review and chargeback evidence; a failed outcome does not void the transaction.
+type VerificationPolicyOutcome struct {
+	Result       string             `json:"result"`        // PolicyResultPass or PolicyResultFail
+	FailedChecks []string           `json:"failed_checks"` // VerificationCheckAVS and/or VerificationCheckCVV
+	AVSPolicy    VerificationPolicy `json:"avs_policy,omitempty"`
+	AVSResult    string             `json:"avs_result,omitempty"` // Verification* summary that was evaluated
+	CVVPolicy    VerificationPolicy `json:"cvv_policy,omitempty"`
+	CVVResult    string             `json:"cvv_result,omitempty"`
+}
+
+// Passed returns true if every configured check passed
+func (o *VerificationPolicyOutcome) Passed() bool {
+	return o.Result == PolicyResultPass
+}
+
+// EvaluateVerificationPolicy checks raw AVS/CVV codes against the merchant's policies.
+// Returns nil when neither policy is configured. Checks with nothing to evaluate
+// (ACH, or CVV not sent for a stored card) pass.
+func EvaluateVerificationPolicy(avsPolicy, cvvPolicy VerificationPolicy, avsCode, cvvCode string) *VerificationPolicyOutcome {
+	if avsPolicy == VerificationPolicyOff && cvvPolicy == VerificationPolicyOff {
+		return nil
+	}
+
+	outcome := &VerificationPolicyOutcome{
+		Result:       PolicyResultPass,
+		FailedChecks: []string{},
+		AVSPolicy:    avsPolicy,
+		CVVPolicy:    cvvPolicy,
+	}
+
+	if avsPolicy != VerificationPolicyOff {
+		outcome.AVSResult = SummarizeAVS(avsCode)
+		if !verificationPasses(avsPolicy, outcome.AVSResult) {
+			outcome.FailedChecks = append(outcome.FailedChecks, VerificationCheckAVS)
+		}
+	}
+	if cvvPolicy != VerificationPolicyOff {
+		outcome.CVVResult = SummarizeCVV(cvvCode)
+		if !verificationPasses(cvvPolicy, outcome.CVVResult) {
+			outcome.FailedChecks = append(outcome.FailedChecks, VerificationCheckCVV)
+		}
+	}
+
+	if len(outcome.FailedChecks) > 0 {
+		outcome.Result = PolicyResultFail
+	}
+	return outcome
+}
+
+func verificationPasses(policy VerificationPolicy, summary string) bool {
+	if summary == VerificationNotApplicable {
+		return true
+	}
+	if policy == VerificationPolicyStrict {
+		return summary == VerificationMatch
+	}
+	return summary != VerificationNoMatch
+}
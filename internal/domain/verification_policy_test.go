@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateVerificationPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		avsPolicy  VerificationPolicy
+		cvvPolicy  VerificationPolicy
+		avsCode    string
+		cvvCode    string
+		wantResult string
+		wantFailed []string
+	}{
+		{"strict CVV mismatch fails", VerificationPolicyOff, VerificationPolicyStrict, "Y", "N", PolicyResultFail, []string{VerificationCheckCVV}},
+		{"strict CVV not processed fails", VerificationPolicyOff, VerificationPolicyStrict, "Y", "P", PolicyResultFail, []string{VerificationCheckCVV}},
+		{"lenient CVV not processed passes", VerificationPolicyOff, VerificationPolicyLenient, "Y", "P", PolicyResultPass, []string{}},
+		{"strict AVS partial match fails", VerificationPolicyStrict, VerificationPolicyOff, "Z", "M", PolicyResultFail, []string{VerificationCheckAVS}},
+		{"lenient AVS partial match passes", VerificationPolicyLenient, VerificationPolicyOff, "Z", "M", PolicyResultPass, []string{}},
+		{"both fail", VerificationPolicyLenient, VerificationPolicyLenient, "N", "N", PolicyResultFail, []string{VerificationCheckAVS, VerificationCheckCVV}},
+		{"no CVV sent passes (stored card)", VerificationPolicyOff, VerificationPolicyStrict, "Y", "", PolicyResultPass, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := EvaluateVerificationPolicy(tt.avsPolicy, tt.cvvPolicy, tt.avsCode, tt.cvvCode)
+			require.NotNil(t, outcome)
+			assert.Equal(t, tt.wantResult, outcome.Result)
+			assert.Equal(t, tt.wantFailed, outcome.FailedChecks)
+			assert.Equal(t, tt.wantResult == PolicyResultPass, outcome.Passed())
+		})
+	}
+}
+
+func TestMerchantConfig_EvaluateVerification(t *testing.T) {
+	config := DefaultMerchantConfig(MerchantTierStandard)
+	assert.Nil(t, config.EvaluateVerification("N", "N"), "no outcome without a configured policy")
+
+	config.CVVPolicy = VerificationPolicyStrict
+	outcome := config.EvaluateVerification("Y", "N")
+	require.NotNil(t, outcome)
+	assert.Equal(t, PolicyResultFail, outcome.Result)
+	assert.Equal(t, []string{VerificationCheckCVV}, outcome.FailedChecks)
+	assert.Equal(t, VerificationNoMatch, outcome.CVVResult)
+	assert.Empty(t, outcome.AVSResult, "AVS is not evaluated without an AVS policy")
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// WebhookStats summarizes a subscription's deliveries created within a time range
+type WebhookStats struct {
+	From time.Time
+	To   time.Time
+
+	TotalDeliveries int64
+	Succeeded       int64
+	Failed          int64 // Failed for good (failed or dead_letter)
+	Pending         int64 // Failed at least once or not yet attempted, still being retried
+
+	// SuccessRate is Succeeded / (Succeeded + Failed), the share of finished deliveries that reached the endpoint
+	// (0 when none have finished)
+	SuccessRate float64
+
+	// AverageLatency is the mean duration of the latest attempt of each delivery that recorded one
+	AverageLatency time.Duration
+
+	// FailureReasons counts failed and pending deliveries by the reason their latest attempt failed, most common first
+	FailureReasons []WebhookFailureReasonCount
+}
+
+// WebhookFailureReasonCount is the number of deliveries whose latest attempt failed for Reason
+type WebhookFailureReasonCount struct {
+	Reason string // http_<status>, timeout, dns_error, connection_refused, tls_error or network_error
+	Count  int64
+}
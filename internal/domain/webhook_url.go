@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), private in practice but not to net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// ValidateWebhookURL checks that a merchant's webhook endpoint is an absolute https URL without credentials.
+// A host given as an IP address must be public (see CheckWebhookIP); hostnames are checked once resolved.
+func ValidateWebhookURL(raw string) error {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
+	}
+
+	if !strings.EqualFold(parsed.Scheme, "https") {
+		return fmt.Errorf("%w: https is required", ErrInvalidWebhookURL)
+	}
+	if parsed.User != nil {
+		return fmt.Errorf("%w: credentials aren't allowed in the URL", ErrInvalidWebhookURL)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("%w: host is required", ErrInvalidWebhookURL)
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: host %q is not public", ErrInvalidWebhookURL, host)
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return CheckWebhookIP(ip)
+	}
+	return nil
+}
+
+// CheckWebhookIP rejects addresses webhooks must not be sent to: private, loopback, link-local
+// (including cloud metadata endpoints), shared, unspecified and multicast addresses.
+func CheckWebhookIP(ip net.IP) error {
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: address %s is not public", ErrInvalidWebhookURL, ip)
+	}
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -13,6 +12,7 @@ import (
 
 	"github.com/kevin07696/payment-service/internal/domain"
 	"github.com/kevin07696/payment-service/internal/services/ports"
+	"github.com/kevin07696/payment-service/pkg/middleware"
 	agentv1 "github.com/kevin07696/payment-service/proto/agent/v1"
 	"go.uber.org/zap"
 )
@@ -56,6 +56,9 @@ func (h *Handler) RegisterAgent(ctx context.Context, req *agentv1.RegisterAgentR
 		AgentName:   req.AgentId, // Default to agent_id if not provided
 	}
 
+	if req.DataRegion != "" {
+		serviceReq.DataRegion = &req.DataRegion
+	}
 	if req.IdempotencyKey != "" {
 		serviceReq.IdempotencyKey = &req.IdempotencyKey
 	}
@@ -156,6 +159,13 @@ func (h *Handler) UpdateAgent(ctx context.Context, req *agentv1.UpdateAgentReque
 		env := environmentFromProto(*req.Environment)
 		serviceReq.Environment = &env
 	}
+	if req.SubscriptionAmountChangeMinDays != nil {
+		minDays := int(*req.SubscriptionAmountChangeMinDays)
+		serviceReq.SubscriptionAmountChangeMinDays = &minDays
+	}
+	if req.DataRegion != nil {
+		serviceReq.DataRegion = req.DataRegion
+	}
 	if req.IdempotencyKey != "" {
 		serviceReq.IdempotencyKey = &req.IdempotencyKey
 	}
@@ -168,7 +178,7 @@ func (h *Handler) UpdateAgent(ctx context.Context, req *agentv1.UpdateAgentReque
 	return agentToResponse(agent), nil
 }
 
-// DeactivateAgent deactivates an agent
+// DeactivateAgent closes an agent
 func (h *Handler) DeactivateAgent(ctx context.Context, req *agentv1.DeactivateAgentRequest) (*agentv1.AgentResponse, error) {
 	h.logger.Info("DeactivateAgent request received",
 		zap.String("agent_id", req.AgentId),
@@ -179,21 +189,67 @@ func (h *Handler) DeactivateAgent(ctx context.Context, req *agentv1.DeactivateAg
 		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
 	}
 
-	// Get agent before deactivation to return in response
-	agent, err := h.service.GetAgent(ctx, req.AgentId)
+	actor, _ := middleware.ServiceIDFromContext(ctx)
+	agent, err := h.service.DeactivateAgent(ctx, &ports.MerchantStatusRequest{
+		AgentID: req.AgentId,
+		Reason:  req.Reason,
+		Actor:   actor,
+	})
 	if err != nil {
 		return nil, handleServiceError(err)
 	}
 
-	err = h.service.DeactivateAgent(ctx, req.AgentId, req.Reason)
+	return agentToResponse(agent), nil
+}
+
+// SuspendMerchant stops new charges for a merchant until it is reactivated
+func (h *Handler) SuspendMerchant(ctx context.Context, req *agentv1.SuspendMerchantRequest) (*agentv1.Merchant, error) {
+	h.logger.Info("SuspendMerchant request received",
+		zap.String("agent_id", req.AgentId),
+		zap.String("reason", req.Reason),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.Reason == "" {
+		return nil, status.Error(codes.InvalidArgument, "reason is required")
+	}
+
+	actor, _ := middleware.ServiceIDFromContext(ctx)
+	agent, err := h.service.SuspendMerchant(ctx, &ports.MerchantStatusRequest{
+		AgentID: req.AgentId,
+		Reason:  req.Reason,
+		Actor:   actor,
+	})
 	if err != nil {
 		return nil, handleServiceError(err)
 	}
 
-	// Mark as inactive in response
-	agent.IsActive = false
+	return agentToMerchant(agent), nil
+}
 
-	return agentToResponse(agent), nil
+// ReactivateMerchant lifts a merchant's suspension
+func (h *Handler) ReactivateMerchant(ctx context.Context, req *agentv1.ReactivateMerchantRequest) (*agentv1.Merchant, error) {
+	h.logger.Info("ReactivateMerchant request received",
+		zap.String("agent_id", req.AgentId),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+
+	actor, _ := middleware.ServiceIDFromContext(ctx)
+	agent, err := h.service.ReactivateMerchant(ctx, &ports.MerchantStatusRequest{
+		AgentID: req.AgentId,
+		Reason:  req.Reason,
+		Actor:   actor,
+	})
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	return agentToMerchant(agent), nil
 }
 
 // RotateMAC rotates MAC secret in secret manager
@@ -209,27 +265,101 @@ func (h *Handler) RotateMAC(ctx context.Context, req *agentv1.RotateMACRequest)
 		return nil, status.Error(codes.InvalidArgument, "new_mac_secret is required")
 	}
 
-	serviceReq := &ports.RotateMACRequest{
+	actor, _ := middleware.ServiceIDFromContext(ctx)
+	rotation, err := h.service.RotateMAC(ctx, &ports.RotateMACRequest{
 		AgentID:      req.AgentId,
 		NewMACSecret: req.NewMacSecret,
+		KeepPrevious: true,
+		Actor:        actor,
+	})
+	if err != nil {
+		return nil, handleServiceError(err)
 	}
 
-	err := h.service.RotateMAC(ctx, serviceReq)
+	return &agentv1.RotateMACResponse{
+		AgentId:       rotation.AgentID,
+		MacSecretPath: rotation.MACSecretPath,
+		RotatedAt:     timestamppb.New(rotation.RotatedAt),
+	}, nil
+}
+
+// GetEffectiveMerchantConfig returns the resolved configuration a payment operation would use
+func (h *Handler) GetEffectiveMerchantConfig(ctx context.Context, req *agentv1.GetEffectiveMerchantConfigRequest) (*agentv1.EffectiveMerchantConfig, error) {
+	h.logger.Info("GetEffectiveMerchantConfig request received",
+		zap.String("agent_id", req.AgentId),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+
+	config, err := h.service.GetEffectiveConfig(ctx, req.AgentId)
 	if err != nil {
 		return nil, handleServiceError(err)
 	}
 
-	// Get updated agent to return mac_secret_path
+	return merchantConfigToProto(req.AgentId, config), nil
+}
+
+// GetMerchant returns a merchant's own profile and configuration, without EPX credentials.
+// Service tokens only reach merchants they've been granted, through the auth interceptor's agent_id check.
+func (h *Handler) GetMerchant(ctx context.Context, req *agentv1.GetMerchantRequest) (*agentv1.Merchant, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if err := requireMerchantGrant(ctx, req.AgentId); err != nil {
+		return nil, err
+	}
+
 	agent, err := h.service.GetAgent(ctx, req.AgentId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, domain.ErrAgentNotFound) {
+			return nil, status.Error(codes.NotFound, "agent not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get agent")
+	}
+
+	return agentToMerchant(agent), nil
+}
+
+// UpdateMerchantSettings changes a merchant's self-service settings (statement descriptor, notification email)
+func (h *Handler) UpdateMerchantSettings(ctx context.Context, req *agentv1.UpdateMerchantSettingsRequest) (*agentv1.Merchant, error) {
+	h.logger.Info("UpdateMerchantSettings request received",
+		zap.String("agent_id", req.AgentId),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.StatementDescriptor == nil && req.NotificationEmail == nil {
+		return nil, status.Error(codes.InvalidArgument, "no settings to update")
+	}
+	if err := requireMerchantGrant(ctx, req.AgentId); err != nil {
+		return nil, err
+	}
+
+	actor, _ := middleware.ServiceIDFromContext(ctx)
+	agent, err := h.service.UpdateMerchantSettings(ctx, &ports.UpdateMerchantSettingsRequest{
+		AgentID:             req.AgentId,
+		StatementDescriptor: req.StatementDescriptor,
+		NotificationEmail:   req.NotificationEmail,
+		Actor:               actor,
+	})
 	if err != nil {
 		return nil, handleServiceError(err)
 	}
 
-	return &agentv1.RotateMACResponse{
-		AgentId:       agent.AgentID,
-		MacSecretPath: agent.MACSecretPath,
-		RotatedAt:     timestamppb.New(time.Now()),
-	}, nil
+	return agentToMerchant(agent), nil
+}
+
+// requireMerchantGrant confirms the auth interceptor granted the calling service agentID. Merchant self-service
+// is scoped to the authenticated principal, so a call the interceptor didn't check (no service auth configured,
+// or the method listed as merchantless) is denied rather than served for any merchant.
+func requireMerchantGrant(ctx context.Context, agentID string) error {
+	if granted, ok := middleware.MerchantIDFromContext(ctx); !ok || granted != agentID {
+		return status.Errorf(codes.PermissionDenied, "service has no access to merchant %q", agentID)
+	}
+	return nil
 }
 
 // Validation helpers
@@ -262,7 +392,7 @@ func validateRegisterAgentRequest(req *agentv1.RegisterAgentRequest) error {
 // Conversion helpers
 
 func agentToResponse(agent *domain.Agent) *agentv1.AgentResponse {
-	return &agentv1.AgentResponse{
+	resp := &agentv1.AgentResponse{
 		AgentId:       agent.AgentID,
 		MacSecretPath: agent.MACSecretPath,
 		CustNbr:       agent.CustNbr,
@@ -273,11 +403,20 @@ func agentToResponse(agent *domain.Agent) *agentv1.AgentResponse {
 		IsActive:      agent.IsActive,
 		CreatedAt:     timestamppb.New(agent.CreatedAt),
 		UpdatedAt:     timestamppb.New(agent.UpdatedAt),
+		DataRegion:    agent.DataRegion,
+		Status:        string(agent.Status),
+	}
+
+	if agent.SubscriptionAmountChangeMinDays != nil {
+		minDays := int32(*agent.SubscriptionAmountChangeMinDays)
+		resp.SubscriptionAmountChangeMinDays = &minDays
 	}
+
+	return resp
 }
 
 func agentToProto(agent *domain.Agent) *agentv1.Agent {
-	return &agentv1.Agent{
+	proto := &agentv1.Agent{
 		Id:            agent.ID,
 		AgentId:       agent.AgentID,
 		MacSecretPath: agent.MACSecretPath,
@@ -290,6 +429,88 @@ func agentToProto(agent *domain.Agent) *agentv1.Agent {
 		CreatedAt:     timestamppb.New(agent.CreatedAt),
 		UpdatedAt:     timestamppb.New(agent.UpdatedAt),
 		Metadata:      nil, // Not storing metadata yet
+		DataRegion:    agent.DataRegion,
+		Status:        string(agent.Status),
+		StatusReason:  agent.StatusReason,
+	}
+
+	if agent.SubscriptionAmountChangeMinDays != nil {
+		minDays := int32(*agent.SubscriptionAmountChangeMinDays)
+		proto.SubscriptionAmountChangeMinDays = &minDays
+	}
+
+	return proto
+}
+
+func agentToMerchant(agent *domain.Agent) *agentv1.Merchant {
+	merchant := &agentv1.Merchant{
+		AgentId:             agent.AgentID,
+		Environment:         environmentToProto(agent.Environment),
+		IsActive:            agent.IsActive,
+		DataRegion:          agent.DataRegion,
+		StatementDescriptor: agent.StatementDescriptor,
+		NotificationEmail:   agent.NotificationEmail,
+		Config:              merchantConfigToProto(agent.AgentID, agent.EffectiveConfig()),
+		CreatedAt:           timestamppb.New(agent.CreatedAt),
+		UpdatedAt:           timestamppb.New(agent.UpdatedAt),
+		Status:              string(agent.Status),
+		StatusReason:        agent.StatusReason,
+	}
+	if agent.SuspendedAt != nil {
+		merchant.SuspendedAt = timestamppb.New(*agent.SuspendedAt)
+	}
+	if agent.ClosedAt != nil {
+		merchant.ClosedAt = timestamppb.New(*agent.ClosedAt)
+	}
+	return merchant
+}
+
+func merchantConfigToProto(agentID string, config *domain.MerchantConfig) *agentv1.EffectiveMerchantConfig {
+	resp := &agentv1.EffectiveMerchantConfig{
+		AgentId:                    agentID,
+		Tier:                       string(config.Tier),
+		AllowedCurrencies:          config.AllowedCurrencies,
+		MinTransactionAmount:       config.MinTransactionAmount.StringFixed(2),
+		MaxTransactionAmount:       config.MaxTransactionAmount.StringFixed(2),
+		DailyVolumeLimit:           config.DailyVolumeLimit.StringFixed(2),
+		SurchargePercent:           config.SurchargePercent.StringFixed(2),
+		RequireSettledRefund:       config.RequireSettledRefund,
+		FundingDelayDays:           int32(config.FundingDelayDays),
+		AuthAutoVoidHours:          int32(config.AuthAutoVoidHours),
+		AchCardFallback:            config.ACHCardFallback,
+		IncludeTransactionTree:     config.IncludeTransactionTree,
+		AvsPolicy:                  string(config.AVSPolicy),
+		CvvPolicy:                  string(config.CVVPolicy),
+		MaxSavedPaymentMethods:     int32(config.MaxSavedPaymentMethods),
+		SavedPaymentMethodPolicy:   string(config.SavedPaymentMethodPolicy),
+		RequestsPerSecond:          config.RequestsPerSecond,
+		BurstLimit:                 int32(config.BurstLimit),
+		CustomerPolicy:             string(config.CustomerPolicy),
+		ReturnUrlHosts:             config.ReturnURLHosts,
+		BrowserPostMacVerification: config.BrowserPostMACVerification,
+		CardVelocity:               velocityLimitToProto(config.CardVelocity),
+		CustomerVelocity:           velocityLimitToProto(config.CustomerVelocity),
+		OverriddenFields:           config.OverriddenFields,
+	}
+
+	for _, capability := range config.Capabilities {
+		resp.Capabilities = append(resp.Capabilities, string(capability))
+	}
+	for _, txType := range config.AllowedTransactionTypes {
+		resp.AllowedTransactionTypes = append(resp.AllowedTransactionTypes, string(txType))
+	}
+	for _, paymentType := range config.AllowedPaymentTypes {
+		resp.AllowedPaymentTypes = append(resp.AllowedPaymentTypes, string(paymentType))
+	}
+
+	return resp
+}
+
+func velocityLimitToProto(limit domain.VelocityLimit) *agentv1.VelocityLimit {
+	return &agentv1.VelocityLimit{
+		WindowMinutes: int32(limit.WindowMinutes),
+		MaxCount:      int32(limit.MaxCount),
+		MaxAmount:     limit.MaxAmount.StringFixed(2),
 	}
 }
 
@@ -332,17 +553,27 @@ func handleServiceError(err error) error {
 	switch {
 	case errors.Is(err, domain.ErrAgentNotFound):
 		return status.Error(codes.NotFound, "agent not found")
+	case errors.Is(err, domain.ErrMerchantClosed):
+		return status.Error(codes.FailedPrecondition, "merchant is closed")
 	case errors.Is(err, domain.ErrAgentInactive):
 		return status.Error(codes.FailedPrecondition, "agent is inactive")
+	case errors.Is(err, domain.ErrMACRotationFailed):
+		return status.Error(codes.Aborted, "MAC rotation could not be confirmed")
 	case errors.Is(err, domain.ErrAgentAlreadyExists):
 		return status.Error(codes.AlreadyExists, "agent already exists")
 	case errors.Is(err, domain.ErrInvalidEnvironment):
 		return status.Error(codes.InvalidArgument, "invalid environment")
+	case errors.Is(err, domain.ErrInvalidDataRegion):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrInvalidStatementDescriptor), errors.Is(err, domain.ErrInvalidNotificationEmail):
+		return status.Error(codes.InvalidArgument, err.Error())
 	case errors.Is(err, domain.ErrDuplicateIdempotencyKey):
 		return status.Error(codes.AlreadyExists, "duplicate idempotency key")
 	case errors.Is(err, sql.ErrNoRows):
 		return status.Error(codes.NotFound, "resource not found")
-	case err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	case errors.Is(err, context.Canceled):
 		return status.Error(codes.Canceled, "request canceled")
 	default:
 		// Log internal errors but don't expose details to client
@@ -0,0 +1,385 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	agentservice "github.com/kevin07696/payment-service/internal/services/agent"
+	"github.com/kevin07696/payment-service/internal/services/ports"
+	"github.com/kevin07696/payment-service/pkg/middleware"
+	agentv1 "github.com/kevin07696/payment-service/proto/agent/v1"
+)
+
+// fakeAgentService serves merchants from memory and applies settings updates
+type fakeAgentService struct {
+	ports.AgentService
+	agents  map[string]*domain.Agent
+	updates []*ports.UpdateMerchantSettingsRequest
+}
+
+func (f *fakeAgentService) GetAgent(ctx context.Context, agentID string) (*domain.Agent, error) {
+	agent, ok := f.agents[agentID]
+	if !ok {
+		return nil, domain.ErrAgentNotFound
+	}
+	return agent, nil
+}
+
+func (f *fakeAgentService) UpdateMerchantSettings(ctx context.Context, req *ports.UpdateMerchantSettingsRequest) (*domain.Agent, error) {
+	agent, ok := f.agents[req.AgentID]
+	if !ok {
+		return nil, domain.ErrAgentNotFound
+	}
+	if req.StatementDescriptor != nil {
+		if err := domain.ValidateStatementDescriptor(*req.StatementDescriptor); err != nil {
+			return nil, err
+		}
+		agent.StatementDescriptor = *req.StatementDescriptor
+	}
+	if req.NotificationEmail != nil {
+		agent.NotificationEmail = *req.NotificationEmail
+	}
+	f.updates = append(f.updates, req)
+	return agent, nil
+}
+
+func (f *fakeAgentService) SuspendMerchant(ctx context.Context, req *ports.MerchantStatusRequest) (*domain.Agent, error) {
+	agent, ok := f.agents[req.AgentID]
+	if !ok {
+		return nil, domain.ErrAgentNotFound
+	}
+	if agent.Status == domain.MerchantStatusClosed {
+		return nil, domain.ErrMerchantClosed
+	}
+	agent.Status, agent.IsActive, agent.StatusReason = domain.MerchantStatusSuspended, false, req.Reason
+	return agent, nil
+}
+
+// newMerchantFixture serves the agent handler behind the auth interceptor. pos-service is granted merchant-a only;
+// call signs a pos-service token with the given scopes.
+func newMerchantFixture(t *testing.T) (*fakeAgentService, func(method string, scope string, req interface{}) (interface{}, error)) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	service := &fakeAgentService{agents: map[string]*domain.Agent{
+		"merchant-a": {AgentID: "merchant-a", CustNbr: "9001", MerchNbr: "900300", Tier: domain.MerchantTierStandard, IsActive: true},
+		"merchant-b": {AgentID: "merchant-b", CustNbr: "9002", MerchNbr: "900400", Tier: domain.MerchantTierStandard, IsActive: true},
+	}}
+	handler := NewHandler(service, zap.NewNop())
+
+	interceptor := middleware.NewAuthInterceptor(middleware.AuthConfig{
+		Keys: middleware.StaticServiceKeys(map[string]*rsa.PublicKey{"pos-service": &key.PublicKey}),
+		MethodScopes: map[string]string{
+			agentv1.AgentService_GetMerchant_FullMethodName:            "merchant:read",
+			agentv1.AgentService_UpdateMerchantSettings_FullMethodName: "merchant:settings",
+		},
+		Access: func(ctx context.Context, serviceID, agentID string) (bool, error) {
+			return serviceID == "pos-service" && agentID == "merchant-a", nil
+		},
+	}, zap.NewNop())
+
+	return service, func(method, scope string, req interface{}) (interface{}, error) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, middleware.ServiceClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    "pos-service",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+			Scope: scope,
+		}).SignedString(key)
+		require.NoError(t, err)
+
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			switch r := req.(type) {
+			case *agentv1.GetMerchantRequest:
+				return handler.GetMerchant(ctx, r)
+			case *agentv1.UpdateMerchantSettingsRequest:
+				return handler.UpdateMerchantSettings(ctx, r)
+			}
+			return nil, status.Error(codes.Unimplemented, "unexpected request")
+		})
+	}
+}
+
+func TestGetMerchant_ScopedToGrantedMerchants(t *testing.T) {
+	_, call := newMerchantFixture(t)
+
+	resp, err := call(agentv1.AgentService_GetMerchant_FullMethodName, "merchant:read", &agentv1.GetMerchantRequest{AgentId: "merchant-a"})
+	require.NoError(t, err)
+	merchant := resp.(*agentv1.Merchant)
+	assert.Equal(t, "merchant-a", merchant.AgentId)
+	assert.Equal(t, "standard", merchant.Config.Tier)
+	assert.NotEmpty(t, merchant.Config.AllowedCurrencies)
+
+	_, err = call(agentv1.AgentService_GetMerchant_FullMethodName, "merchant:read", &agentv1.GetMerchantRequest{AgentId: "merchant-b"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "a merchant the service wasn't granted")
+
+	_, err = call(agentv1.AgentService_GetMerchant_FullMethodName, "merchant:read", &agentv1.GetMerchantRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "a request naming no merchant can't skip the grant check")
+
+	_, err = call(agentv1.AgentService_GetMerchant_FullMethodName, "merchant:settings", &agentv1.GetMerchantRequest{AgentId: "merchant-a"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "missing merchant:read")
+}
+
+func TestMerchantSelfService_DeniedWithoutInterceptorGrant(t *testing.T) {
+	service, _ := newMerchantFixture(t)
+	handler := NewHandler(service, zap.NewNop())
+	descriptor := "ACME COFFEE"
+
+	// Served without the auth interceptor, as when service auth isn't configured
+	_, err := handler.GetMerchant(context.Background(), &agentv1.GetMerchantRequest{AgentId: "merchant-a"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = handler.UpdateMerchantSettings(context.Background(),
+		&agentv1.UpdateMerchantSettingsRequest{AgentId: "merchant-a", StatementDescriptor: &descriptor})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Empty(t, service.updates)
+	assert.Empty(t, service.agents["merchant-a"].StatementDescriptor)
+}
+
+func TestUpdateMerchantSettings_AccessControl(t *testing.T) {
+	service, call := newMerchantFixture(t)
+	descriptor := "ACME COFFEE"
+	email := "ops@acme.example"
+
+	_, err := call(agentv1.AgentService_UpdateMerchantSettings_FullMethodName, "merchant:read",
+		&agentv1.UpdateMerchantSettingsRequest{AgentId: "merchant-a", StatementDescriptor: &descriptor})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "reading doesn't allow updating")
+
+	_, err = call(agentv1.AgentService_UpdateMerchantSettings_FullMethodName, "merchant:settings",
+		&agentv1.UpdateMerchantSettingsRequest{AgentId: "merchant-b", StatementDescriptor: &descriptor})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Empty(t, service.updates, "denied calls never reach the service")
+	assert.Empty(t, service.agents["merchant-b"].StatementDescriptor)
+
+	resp, err := call(agentv1.AgentService_UpdateMerchantSettings_FullMethodName, "merchant:settings",
+		&agentv1.UpdateMerchantSettingsRequest{AgentId: "merchant-a", StatementDescriptor: &descriptor, NotificationEmail: &email})
+	require.NoError(t, err)
+	merchant := resp.(*agentv1.Merchant)
+	assert.Equal(t, "ACME COFFEE", merchant.StatementDescriptor)
+	assert.Equal(t, "ops@acme.example", merchant.NotificationEmail)
+	require.Len(t, service.updates, 1)
+	assert.Equal(t, "pos-service", service.updates[0].Actor)
+	assert.Equal(t, "9001", service.agents["merchant-a"].CustNbr, "EPX credentials are untouched")
+
+	invalid := "<script>"
+	_, err = call(agentv1.AgentService_UpdateMerchantSettings_FullMethodName, "merchant:settings",
+		&agentv1.UpdateMerchantSettingsRequest{AgentId: "merchant-a", StatementDescriptor: &invalid})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = call(agentv1.AgentService_UpdateMerchantSettings_FullMethodName, "merchant:settings",
+		&agentv1.UpdateMerchantSettingsRequest{AgentId: "merchant-a"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "nothing to update")
+}
+
+func TestSuspendMerchant(t *testing.T) {
+	service := &fakeAgentService{agents: map[string]*domain.Agent{
+		"merchant-a": {AgentID: "merchant-a", Tier: domain.MerchantTierStandard, Status: domain.MerchantStatusActive, IsActive: true},
+		"merchant-c": {AgentID: "merchant-c", Tier: domain.MerchantTierStandard, Status: domain.MerchantStatusClosed},
+	}}
+	handler := NewHandler(service, zap.NewNop())
+
+	_, err := handler.SuspendMerchant(context.Background(), &agentv1.SuspendMerchantRequest{AgentId: "merchant-a"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "a suspension needs a reason")
+
+	merchant, err := handler.SuspendMerchant(context.Background(), &agentv1.SuspendMerchantRequest{AgentId: "merchant-a", Reason: "chargeback review"})
+	require.NoError(t, err)
+	assert.Equal(t, "suspended", merchant.Status)
+	assert.Equal(t, "chargeback review", merchant.StatusReason)
+	assert.False(t, merchant.IsActive)
+
+	_, err = handler.SuspendMerchant(context.Background(), &agentv1.SuspendMerchantRequest{AgentId: "merchant-c", Reason: "chargeback review"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, err.Error(), "merchant is closed")
+}
+
+// fakeAgentStore keeps agent rows in memory for the real agent service
+type fakeAgentStore struct {
+	sqlc.Querier
+	agents map[string]sqlc.AgentCredential
+	audit  []sqlc.CreateAuditLogParams
+}
+
+func (f *fakeAgentStore) Queries() sqlc.Querier { return f }
+
+func (f *fakeAgentStore) WithTx(ctx context.Context, fn func(sqlc.Querier) error) error {
+	return fn(f)
+}
+
+func (f *fakeAgentStore) AgentExists(ctx context.Context, agentID string) (bool, error) {
+	_, ok := f.agents[agentID]
+	return ok, nil
+}
+
+func (f *fakeAgentStore) GetAgentByAgentID(ctx context.Context, agentID string) (sqlc.AgentCredential, error) {
+	agent, ok := f.agents[agentID]
+	if !ok {
+		return sqlc.AgentCredential{}, pgx.ErrNoRows
+	}
+	return agent, nil
+}
+
+func (f *fakeAgentStore) CreateAgent(ctx context.Context, arg sqlc.CreateAgentParams) (sqlc.AgentCredential, error) {
+	agent := sqlc.AgentCredential{
+		ID: arg.ID, AgentID: arg.AgentID, CustNbr: arg.CustNbr, MerchNbr: arg.MerchNbr, DbaNbr: arg.DbaNbr,
+		TerminalNbr: arg.TerminalNbr, MacSecretPath: arg.MacSecretPath, Environment: arg.Environment,
+		IsActive: arg.IsActive, AgentName: arg.AgentName, DataRegion: arg.DataRegion,
+		Tier: string(domain.MerchantTierStandard), Status: string(domain.MerchantStatusActive),
+	}
+	f.agents[arg.AgentID] = agent
+	return agent, nil
+}
+
+func (f *fakeAgentStore) UpdateAgent(ctx context.Context, arg sqlc.UpdateAgentParams) (sqlc.AgentCredential, error) {
+	agent := f.agents[arg.AgentID]
+	agent.CustNbr, agent.MerchNbr, agent.DbaNbr, agent.TerminalNbr = arg.CustNbr, arg.MerchNbr, arg.DbaNbr, arg.TerminalNbr
+	agent.Environment, agent.AgentName, agent.DataRegion = arg.Environment, arg.AgentName, arg.DataRegion
+	f.agents[arg.AgentID] = agent
+	return agent, nil
+}
+
+func (f *fakeAgentStore) SuspendAgent(ctx context.Context, arg sqlc.SuspendAgentParams) (sqlc.AgentCredential, error) {
+	return f.setStatus(arg.AgentID, domain.MerchantStatusSuspended, arg.Reason, domain.MerchantStatusActive)
+}
+
+func (f *fakeAgentStore) ReactivateAgent(ctx context.Context, agentID string) (sqlc.AgentCredential, error) {
+	return f.setStatus(agentID, domain.MerchantStatusActive, pgtype.Text{}, domain.MerchantStatusSuspended)
+}
+
+func (f *fakeAgentStore) CloseAgent(ctx context.Context, arg sqlc.CloseAgentParams) (sqlc.AgentCredential, error) {
+	return f.setStatus(arg.AgentID, domain.MerchantStatusClosed, arg.Reason, domain.MerchantStatusActive, domain.MerchantStatusSuspended)
+}
+
+// setStatus moves a merchant in one of the from statuses to status, as the status UPDATEs do
+func (f *fakeAgentStore) setStatus(agentID string, status domain.MerchantStatus, reason pgtype.Text, from ...domain.MerchantStatus) (sqlc.AgentCredential, error) {
+	agent, ok := f.agents[agentID]
+	if !ok || !slices.Contains(from, domain.MerchantStatus(agent.Status)) {
+		return sqlc.AgentCredential{}, pgx.ErrNoRows
+	}
+	agent.Status, agent.StatusReason = string(status), reason
+	agent.IsActive = pgtype.Bool{Bool: status == domain.MerchantStatusActive, Valid: true}
+	f.agents[agentID] = agent
+	return agent, nil
+}
+
+func (f *fakeAgentStore) CreateAuditLog(ctx context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error) {
+	f.audit = append(f.audit, arg)
+	return sqlc.AuditLog{}, nil
+}
+
+// discardSecrets accepts MAC secrets without keeping them
+type discardSecrets struct {
+	adapterports.SecretManagerAdapter
+}
+
+func (discardSecrets) PutSecret(ctx context.Context, path, value string, metadata map[string]string) (string, error) {
+	return "v1", nil
+}
+
+func TestDataRegion_SetAndReturnedThroughRPCs(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeAgentStore{agents: map[string]sqlc.AgentCredential{}}
+	handler := NewHandler(agentservice.NewAgentService(store, discardSecrets{}, zap.NewNop()), zap.NewNop())
+	register := func(agentID, region string) (*agentv1.AgentResponse, error) {
+		return handler.RegisterAgent(ctx, &agentv1.RegisterAgentRequest{
+			AgentId: agentID, MacSecret: "mac", CustNbr: "9001", MerchNbr: "900300", DbaNbr: "2", TerminalNbr: "77",
+			Environment: agentv1.Environment_ENVIRONMENT_SANDBOX, DataRegion: region,
+		})
+	}
+
+	resp, err := register("merchant-eu", "eu")
+	require.NoError(t, err)
+	assert.Equal(t, "eu", resp.DataRegion)
+	assert.Equal(t, "eu", store.agents["merchant-eu"].DataRegion, "stored on the merchant")
+
+	resp, err = register("merchant-default", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DefaultDataRegion, resp.DataRegion)
+
+	_, err = register("merchant-bad", "Mars")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.NotContains(t, store.agents, "merchant-bad")
+
+	// Moving a merchant changes the region its rows are tagged with from then on
+	ca := "ca-central"
+	updated, err := handler.UpdateAgent(ctx, &agentv1.UpdateAgentRequest{AgentId: "merchant-eu", DataRegion: &ca})
+	require.NoError(t, err)
+	assert.Equal(t, "ca-central", updated.DataRegion)
+
+	bad := "EU"
+	_, err = handler.UpdateAgent(ctx, &agentv1.UpdateAgentRequest{AgentId: "merchant-eu", DataRegion: &bad})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "ca-central", store.agents["merchant-eu"].DataRegion)
+
+	got, err := handler.GetAgent(ctx, &agentv1.GetAgentRequest{AgentId: "merchant-eu"})
+	require.NoError(t, err)
+	assert.Equal(t, "ca-central", got.DataRegion)
+}
+
+func TestMerchantStatus_ThroughRPCs(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeAgentStore{agents: map[string]sqlc.AgentCredential{}}
+	handler := NewHandler(agentservice.NewAgentService(store, discardSecrets{}, zap.NewNop()), zap.NewNop())
+	_, err := handler.RegisterAgent(ctx, &agentv1.RegisterAgentRequest{
+		AgentId: "merchant-a", MacSecret: "mac", CustNbr: "9001", MerchNbr: "900300", DbaNbr: "2", TerminalNbr: "77",
+		Environment: agentv1.Environment_ENVIRONMENT_SANDBOX,
+	})
+	require.NoError(t, err)
+
+	suspended, err := handler.SuspendMerchant(ctx, &agentv1.SuspendMerchantRequest{AgentId: "merchant-a", Reason: "chargeback review"})
+	require.NoError(t, err)
+	assert.Equal(t, "suspended", suspended.Status)
+	assert.False(t, suspended.IsActive)
+	assert.Equal(t, "chargeback review", store.agents["merchant-a"].StatusReason.String)
+
+	// Suspending again changes nothing and records nothing
+	_, err = handler.SuspendMerchant(ctx, &agentv1.SuspendMerchantRequest{AgentId: "merchant-a", Reason: "chargeback review"})
+	require.NoError(t, err)
+	require.Len(t, store.audit, 1)
+	assert.Equal(t, domain.AuditActionSuspendMerchant, store.audit[0].Action)
+
+	reactivated, err := handler.ReactivateMerchant(ctx, &agentv1.ReactivateMerchantRequest{AgentId: "merchant-a"})
+	require.NoError(t, err)
+	assert.Equal(t, "active", reactivated.Status)
+	assert.True(t, reactivated.IsActive)
+
+	closed, err := handler.DeactivateAgent(ctx, &agentv1.DeactivateAgentRequest{AgentId: "merchant-a", Reason: "merchant request"})
+	require.NoError(t, err)
+	assert.False(t, closed.IsActive)
+	assert.Equal(t, string(domain.MerchantStatusClosed), store.agents["merchant-a"].Status)
+
+	// Closing is terminal
+	_, err = handler.ReactivateMerchant(ctx, &agentv1.ReactivateMerchantRequest{AgentId: "merchant-a"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = handler.SuspendMerchant(ctx, &agentv1.SuspendMerchantRequest{AgentId: "merchant-a", Reason: "chargeback review"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, string(domain.MerchantStatusClosed), store.agents["merchant-a"].Status)
+
+	var actions []string
+	for _, entry := range store.audit {
+		actions = append(actions, entry.Action)
+	}
+	assert.Equal(t, []string{domain.AuditActionSuspendMerchant, domain.AuditActionReactivateMerchant, domain.AuditActionCloseMerchant}, actions)
+
+	_, err = handler.SuspendMerchant(ctx, &agentv1.SuspendMerchantRequest{AgentId: "merchant-unknown", Reason: "chargeback review"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
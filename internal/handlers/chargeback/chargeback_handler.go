@@ -2,14 +2,20 @@ package chargeback
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	chargebackService "github.com/kevin07696/payment-service/internal/services/chargeback"
 	chargebackv1 "github.com/kevin07696/payment-service/proto/chargeback/v1"
 	"go.uber.org/zap"
 )
@@ -19,13 +25,20 @@ type QueryExecutor interface {
 	GetChargebackByID(ctx context.Context, id uuid.UUID) (sqlc.Chargeback, error)
 	ListChargebacks(ctx context.Context, params sqlc.ListChargebacksParams) ([]sqlc.Chargeback, error)
 	CountChargebacks(ctx context.Context, params sqlc.CountChargebacksParams) (int64, error)
+	GetGroupThreeDS(ctx context.Context, groupID uuid.UUID) ([]byte, error)
+}
+
+// EvidenceSubmitter submits a merchant's representment of a chargeback
+type EvidenceSubmitter interface {
+	SubmitEvidence(ctx context.Context, req *chargebackService.SubmitEvidenceRequest) (*sqlc.Chargeback, error)
 }
 
 // Handler implements the gRPC ChargebackServiceServer
 type Handler struct {
 	chargebackv1.UnimplementedChargebackServiceServer
-	queries QueryExecutor
-	logger  *zap.Logger
+	queries  QueryExecutor
+	evidence EvidenceSubmitter
+	logger   *zap.Logger
 }
 
 // NewHandlerWithQueries creates a new chargeback handler with a custom query executor
@@ -38,14 +51,15 @@ func NewHandlerWithQueries(queries QueryExecutor, logger *zap.Logger) *Handler {
 
 // DatabaseAdapter wraps a database adapter to extract queries
 type DatabaseAdapter interface {
-	Queries() *sqlc.Queries
+	Queries() sqlc.Querier
 }
 
 // NewHandler creates a new chargeback handler from a database adapter
-func NewHandler(db DatabaseAdapter, logger *zap.Logger) *Handler {
+func NewHandler(db DatabaseAdapter, evidence EvidenceSubmitter, logger *zap.Logger) *Handler {
 	return &Handler{
-		queries: db.Queries(),
-		logger:  logger,
+		queries:  db.Queries(),
+		evidence: evidence,
+		logger:   logger,
 	}
 }
 
@@ -90,8 +104,37 @@ func (h *Handler) GetChargeback(ctx context.Context, req *chargebackv1.GetCharge
 		return nil, status.Error(codes.PermissionDenied, "not authorized to access this chargeback")
 	}
 
-	// Convert to proto
-	return convertChargebackToProto(&chargeback), nil
+	proto := convertChargebackToProto(&chargeback)
+	if chargeback.GroupID.Valid {
+		proto.ThreeDs = h.threeDSEvidence(ctx, uuid.UUID(chargeback.GroupID.Bytes))
+	}
+	return proto, nil
+}
+
+// threeDSEvidence returns the 3-D Secure result of the disputed authorization (nil when 3DS wasn't run)
+func (h *Handler) threeDSEvidence(ctx context.Context, groupID uuid.UUID) *chargebackv1.ThreeDSecureEvidence {
+	data, err := h.queries.GetGroupThreeDS(ctx, groupID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			h.logger.Warn("Failed to load 3-D Secure evidence",
+				zap.String("group_id", groupID.String()),
+				zap.Error(err),
+			)
+		}
+		return nil
+	}
+
+	var threeDS domain.ThreeDSecure
+	if err := json.Unmarshal(data, &threeDS); err != nil {
+		return nil
+	}
+	return &chargebackv1.ThreeDSecureEvidence{
+		Version:           threeDS.Version,
+		Cavv:              threeDS.CAVV,
+		Eci:               threeDS.ECI,
+		TransactionStatus: threeDS.TransactionStatus,
+		DsTransactionId:   threeDS.DSTransactionID,
+	}
 }
 
 // ListChargebacks retrieves chargebacks with flexible filters
@@ -143,6 +186,26 @@ func (h *Handler) ListChargebacks(ctx context.Context, req *chargebackv1.ListCha
 		params.Status = pgtype.Text{Valid: false}
 	}
 
+	if req.MatchStatus != nil && *req.MatchStatus != chargebackv1.ChargebackMatchStatus_CHARGEBACK_MATCH_STATUS_UNSPECIFIED {
+		params.MatchStatus = pgtype.Text{String: mapProtoMatchStatusToDomain(*req.MatchStatus), Valid: true}
+	}
+
+	if req.MinAmountCents != nil {
+		if *req.MinAmountCents < 0 {
+			return nil, status.Error(codes.InvalidArgument, "min_amount_cents must not be negative")
+		}
+		params.MinAmount = centsToNumeric(*req.MinAmountCents)
+	}
+	if req.MaxAmountCents != nil {
+		if *req.MaxAmountCents < 0 {
+			return nil, status.Error(codes.InvalidArgument, "max_amount_cents must not be negative")
+		}
+		params.MaxAmount = centsToNumeric(*req.MaxAmountCents)
+	}
+	if req.MinAmountCents != nil && req.MaxAmountCents != nil && *req.MinAmountCents > *req.MaxAmountCents {
+		return nil, status.Error(codes.InvalidArgument, "min_amount_cents must not exceed max_amount_cents")
+	}
+
 	// Date filters
 	if req.DisputeDateFrom != nil {
 		fromDate := req.DisputeDateFrom.AsTime()
@@ -176,6 +239,9 @@ func (h *Handler) ListChargebacks(ctx context.Context, req *chargebackv1.ListCha
 		Status:          params.Status,
 		DisputeDateFrom: params.DisputeDateFrom,
 		DisputeDateTo:   params.DisputeDateTo,
+		MatchStatus:     params.MatchStatus,
+		MinAmount:       params.MinAmount,
+		MaxAmount:       params.MaxAmount,
 	}
 
 	totalCount, err := h.queries.CountChargebacks(ctx, countParams)
@@ -202,6 +268,64 @@ func (h *Handler) ListChargebacks(ctx context.Context, req *chargebackv1.ListCha
 	}, nil
 }
 
+// SubmitChargebackEvidence stores evidence and a rebuttal for the merchant to submit through North's portal
+func (h *Handler) SubmitChargebackEvidence(ctx context.Context, req *chargebackv1.SubmitChargebackEvidenceRequest) (*chargebackv1.Chargeback, error) {
+	h.logger.Info("SubmitChargebackEvidence request received",
+		zap.String("chargeback_id", req.ChargebackId),
+		zap.String("agent_id", req.AgentId),
+		zap.Int("evidence_files", len(req.EvidenceFileUrls)),
+	)
+
+	if req.ChargebackId == "" {
+		return nil, status.Error(codes.InvalidArgument, "chargeback_id is required")
+	}
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	chargebackID, err := uuid.Parse(req.ChargebackId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid chargeback_id format")
+	}
+
+	chargeback, err := h.evidence.SubmitEvidence(ctx, &chargebackService.SubmitEvidenceRequest{
+		ChargebackID:     chargebackID,
+		AgentID:          req.AgentId,
+		EvidenceFileURLs: req.EvidenceFileUrls,
+		Narrative:        req.Narrative,
+	})
+	if err != nil {
+		h.logger.Error("Failed to submit chargeback evidence",
+			zap.String("chargeback_id", req.ChargebackId),
+			zap.Error(err),
+		)
+		return nil, handleServiceError(err)
+	}
+
+	return convertChargebackToProto(chargeback), nil
+}
+
+// handleServiceError maps evidence submission errors to gRPC status codes
+func handleServiceError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidChargebackEvidence):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrChargebackNotFound):
+		return status.Error(codes.NotFound, "chargeback not found")
+	case errors.Is(err, domain.ErrChargebackDeadlinePassed):
+		return status.Error(codes.FailedPrecondition, "chargeback response deadline has passed")
+	case errors.Is(err, domain.ErrChargebackAlreadyResolved):
+		return status.Error(codes.FailedPrecondition, "chargeback is already resolved")
+	case errors.Is(err, domain.ErrChargebackCannotRespond):
+		return status.Error(codes.FailedPrecondition, "chargeback is not accepting evidence")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request canceled")
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
 // convertChargebackToProto converts database model to protobuf message
 func convertChargebackToProto(cb *sqlc.Chargeback) *chargebackv1.Chargeback {
 	proto := &chargebackv1.Chargeback{
@@ -214,6 +338,7 @@ func convertChargebackToProto(cb *sqlc.Chargeback) *chargebackv1.Chargeback {
 		Currency:         cb.Currency,
 		ReasonCode:       cb.ReasonCode,
 		Status:           mapDomainStatusToProto(cb.Status),
+		MatchStatus:      mapDomainMatchStatusToProto(cb.MatchStatus.String),
 		EvidenceFileUrls: cb.EvidenceFiles,
 		CreatedAt:        timestamppb.New(cb.CreatedAt),
 		UpdatedAt:        timestamppb.New(cb.UpdatedAt),
@@ -266,6 +391,8 @@ func mapDomainStatusToProto(domainStatus string) chargebackv1.ChargebackStatus {
 		return chargebackv1.ChargebackStatus_CHARGEBACK_STATUS_PENDING
 	case "responded":
 		return chargebackv1.ChargebackStatus_CHARGEBACK_STATUS_RESPONDED
+	case "under_review":
+		return chargebackv1.ChargebackStatus_CHARGEBACK_STATUS_UNDER_REVIEW
 	case "won":
 		return chargebackv1.ChargebackStatus_CHARGEBACK_STATUS_WON
 	case "lost":
@@ -286,6 +413,8 @@ func mapProtoStatusToDomain(protoStatus chargebackv1.ChargebackStatus) string {
 		return "pending"
 	case chargebackv1.ChargebackStatus_CHARGEBACK_STATUS_RESPONDED:
 		return "responded"
+	case chargebackv1.ChargebackStatus_CHARGEBACK_STATUS_UNDER_REVIEW:
+		return "under_review"
 	case chargebackv1.ChargebackStatus_CHARGEBACK_STATUS_WON:
 		return "won"
 	case chargebackv1.ChargebackStatus_CHARGEBACK_STATUS_LOST:
@@ -296,3 +425,28 @@ func mapProtoStatusToDomain(protoStatus chargebackv1.ChargebackStatus) string {
 		return "new"
 	}
 }
+
+// mapDomainMatchStatusToProto maps the database match status to the proto enum
+func mapDomainMatchStatusToProto(matchStatus string) chargebackv1.ChargebackMatchStatus {
+	switch domain.ChargebackMatchStatus(matchStatus) {
+	case domain.ChargebackMatched:
+		return chargebackv1.ChargebackMatchStatus_CHARGEBACK_MATCH_STATUS_MATCHED
+	case domain.ChargebackUnmatched:
+		return chargebackv1.ChargebackMatchStatus_CHARGEBACK_MATCH_STATUS_UNMATCHED
+	default:
+		return chargebackv1.ChargebackMatchStatus_CHARGEBACK_MATCH_STATUS_UNSPECIFIED
+	}
+}
+
+// mapProtoMatchStatusToDomain maps the proto enum to the database match status
+func mapProtoMatchStatusToDomain(matchStatus chargebackv1.ChargebackMatchStatus) string {
+	if matchStatus == chargebackv1.ChargebackMatchStatus_CHARGEBACK_MATCH_STATUS_MATCHED {
+		return string(domain.ChargebackMatched)
+	}
+	return string(domain.ChargebackUnmatched)
+}
+
+// centsToNumeric converts an amount in cents to a NUMERIC in dollars
+func centsToNumeric(cents int64) pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(cents), Exp: -2, Valid: true}
+}
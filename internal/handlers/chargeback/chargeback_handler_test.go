@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"google.golang.org/grpc/codes"
@@ -41,6 +43,14 @@ func (m *MockQueryExecutor) CountChargebacks(ctx context.Context, params sqlc.Co
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockQueryExecutor) GetGroupThreeDS(ctx context.Context, groupID uuid.UUID) ([]byte, error) {
+	args := m.Called(ctx, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
 // GetChargeback Tests
 
 func TestGetChargeback_Success(t *testing.T) {
@@ -72,6 +82,7 @@ func TestGetChargeback_Success(t *testing.T) {
 	}
 
 	mockQueries.On("GetChargebackByID", mock.Anything, chargebackID).Return(chargeback, nil)
+	mockQueries.On("GetGroupThreeDS", mock.Anything, groupID).Return(nil, pgx.ErrNoRows)
 
 	// Test request
 	req := &chargebackv1.GetChargebackRequest{
@@ -85,6 +96,7 @@ func TestGetChargeback_Success(t *testing.T) {
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
+	assert.Nil(t, resp.ThreeDs, "no 3DS evidence when 3DS wasn't run")
 	assert.Equal(t, chargebackID.String(), resp.Id)
 	assert.Equal(t, "test-agent-123", resp.AgentId)
 	assert.Equal(t, groupID.String(), resp.GroupId)
@@ -97,6 +109,45 @@ func TestGetChargeback_Success(t *testing.T) {
 	mockQueries.AssertExpectations(t)
 }
 
+func TestGetChargeback_IncludesThreeDSEvidence(t *testing.T) {
+	mockQueries := new(MockQueryExecutor)
+	handler := NewHandlerWithQueries(mockQueries, zap.NewNop())
+
+	chargebackID := uuid.New()
+	groupID := uuid.New()
+	chargeback := sqlc.Chargeback{
+		ID:             chargebackID,
+		AgentID:        "test-agent-123",
+		GroupID:        pgtype.UUID{Bytes: groupID, Valid: true},
+		CaseNumber:     "CASE-002",
+		DisputeDate:    time.Now(),
+		ChargebackDate: time.Now(),
+		Status:         "new",
+		ReasonCode:     "10.4",
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	mockQueries.On("GetChargebackByID", mock.Anything, chargebackID).Return(chargeback, nil)
+	mockQueries.On("GetGroupThreeDS", mock.Anything, groupID).Return(
+		[]byte(`{"version":"2.2.0","cavv":"AAABBEg0VhI0VniQEjRWAAAAAAA=","eci":"05","transaction_status":"Y","ds_transaction_id":"f25084f0"}`), nil)
+
+	resp, err := handler.GetChargeback(context.Background(), &chargebackv1.GetChargebackRequest{
+		ChargebackId: chargebackID.String(),
+		AgentId:      "test-agent-123",
+	})
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp.ThreeDs) {
+		assert.Equal(t, "AAABBEg0VhI0VniQEjRWAAAAAAA=", resp.ThreeDs.Cavv)
+		assert.Equal(t, "05", resp.ThreeDs.Eci)
+		assert.Equal(t, "Y", resp.ThreeDs.TransactionStatus)
+		assert.Equal(t, "2.2.0", resp.ThreeDs.Version)
+		assert.Equal(t, "f25084f0", resp.ThreeDs.DsTransactionId)
+	}
+	mockQueries.AssertExpectations(t)
+}
+
 func TestGetChargeback_MissingChargebackID(t *testing.T) {
 	// Setup
 	mockQueries := new(MockQueryExecutor)
@@ -434,3 +485,123 @@ func TestListChargebacks_WithPagination(t *testing.T) {
 
 	mockQueries.AssertExpectations(t)
 }
+
+// chargebackTable applies the ListChargebacks/CountChargebacks filters in memory
+type chargebackTable struct {
+	MockQueryExecutor
+	rows []sqlc.Chargeback
+}
+
+func (c *chargebackTable) filter(agentID, status pgtype.Text, minAmount, maxAmount pgtype.Numeric) []sqlc.Chargeback {
+	var result []sqlc.Chargeback
+	for _, cb := range c.rows {
+		amount := decimal.RequireFromString(cb.ChargebackAmount)
+		switch {
+		case agentID.Valid && cb.AgentID != agentID.String:
+		case status.Valid && cb.Status != status.String:
+		case minAmount.Valid && amount.LessThan(decimal.NewFromBigInt(minAmount.Int, minAmount.Exp)):
+		case maxAmount.Valid && amount.GreaterThan(decimal.NewFromBigInt(maxAmount.Int, maxAmount.Exp)):
+		default:
+			result = append(result, cb)
+		}
+	}
+	return result
+}
+
+func (c *chargebackTable) ListChargebacks(ctx context.Context, params sqlc.ListChargebacksParams) ([]sqlc.Chargeback, error) {
+	rows := c.filter(params.AgentID, params.Status, params.MinAmount, params.MaxAmount)
+	start := min(int(params.OffsetVal), len(rows))
+	end := min(start+int(params.LimitVal), len(rows))
+	return rows[start:end], nil
+}
+
+func (c *chargebackTable) CountChargebacks(ctx context.Context, params sqlc.CountChargebacksParams) (int64, error) {
+	return int64(len(c.filter(params.AgentID, params.Status, params.MinAmount, params.MaxAmount))), nil
+}
+
+func newChargebackTable() *chargebackTable {
+	row := func(agentID, caseNumber, status, amount string) sqlc.Chargeback {
+		return sqlc.Chargeback{
+			ID:               uuid.New(),
+			AgentID:          agentID,
+			CaseNumber:       caseNumber,
+			Status:           status,
+			ChargebackAmount: amount,
+			Currency:         "USD",
+		}
+	}
+	return &chargebackTable{rows: []sqlc.Chargeback{
+		row("merchant-a", "A-1", "new", "25.00"),
+		row("merchant-a", "A-2", "won", "150.00"),
+		row("merchant-a", "A-3", "new", "99.99"),
+		row("merchant-b", "B-1", "new", "40.00"),
+	}}
+}
+
+func caseNumbers(resp *chargebackv1.ListChargebacksResponse) []string {
+	var cases []string
+	for _, cb := range resp.Chargebacks {
+		cases = append(cases, cb.CaseNumber)
+	}
+	return cases
+}
+
+func TestListChargebacks_StatusFilter(t *testing.T) {
+	handler := NewHandlerWithQueries(newChargebackTable(), zap.NewNop())
+	status := chargebackv1.ChargebackStatus_CHARGEBACK_STATUS_NEW
+
+	resp, err := handler.ListChargebacks(context.Background(), &chargebackv1.ListChargebacksRequest{
+		AgentId: "merchant-a",
+		Status:  &status,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A-1", "A-3"}, caseNumbers(resp))
+	assert.Equal(t, int32(2), resp.TotalCount)
+
+	resp, err = handler.ListChargebacks(context.Background(), &chargebackv1.ListChargebacksRequest{
+		AgentId: "merchant-a",
+		Status:  &status,
+		Limit:   1,
+		Offset:  1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A-3"}, caseNumbers(resp))
+	assert.Equal(t, int32(2), resp.TotalCount, "the total counts every page")
+}
+
+func TestListChargebacks_ScopedToMerchant(t *testing.T) {
+	handler := NewHandlerWithQueries(newChargebackTable(), zap.NewNop())
+
+	resp, err := handler.ListChargebacks(context.Background(), &chargebackv1.ListChargebacksRequest{AgentId: "merchant-b"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"B-1"}, caseNumbers(resp))
+	assert.Equal(t, int32(1), resp.TotalCount)
+	for _, cb := range resp.Chargebacks {
+		assert.Equal(t, "merchant-b", cb.AgentId)
+	}
+
+	resp, err = handler.ListChargebacks(context.Background(), &chargebackv1.ListChargebacksRequest{AgentId: "merchant-c"})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Chargebacks)
+	assert.Zero(t, resp.TotalCount)
+}
+
+func TestListChargebacks_AmountFilter(t *testing.T) {
+	handler := NewHandlerWithQueries(newChargebackTable(), zap.NewNop())
+	minCents, maxCents := int64(2500), int64(9999)
+
+	resp, err := handler.ListChargebacks(context.Background(), &chargebackv1.ListChargebacksRequest{
+		AgentId:        "merchant-a",
+		MinAmountCents: &minCents,
+		MaxAmountCents: &maxCents,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A-1", "A-3"}, caseNumbers(resp), "both bounds are inclusive")
+
+	_, err = handler.ListChargebacks(context.Background(), &chargebackv1.ListChargebacksRequest{
+		AgentId:        "merchant-a",
+		MinAmountCents: &maxCents,
+		MaxAmountCents: &minCents,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
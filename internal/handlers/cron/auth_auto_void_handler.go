@@ -0,0 +1,245 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/adapters/database"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/ports"
+)
+
+const (
+	// maxAuthAutoVoidsPerAgent bounds the voids sent for one merchant in a run; the rest wait for the next run
+	maxAuthAutoVoidsPerAgent = 500
+
+	// authAutoVoidActor is recorded in audit_logs as the caller of the job's voids
+	authAutoVoidActor = "cron/auth-auto-void"
+)
+
+// AuthAutoVoidQueryExecutor defines the queries used by the auth auto-void job
+type AuthAutoVoidQueryExecutor interface {
+	GetAgentByAgentID(ctx context.Context, agentID string) (sqlc.AgentCredential, error)
+	ListActiveAgents(ctx context.Context) ([]sqlc.AgentCredential, error)
+	ListUncapturedAuthorizations(ctx context.Context, arg sqlc.ListUncapturedAuthorizationsParams) ([]sqlc.Transaction, error)
+}
+
+// AuthVoider voids a transaction through EPX and records the void in its group
+type AuthVoider interface {
+	Void(ctx context.Context, req *ports.VoidRequest) (*domain.Transaction, error)
+}
+
+// AuthAutoVoidHandler handles the cron endpoint that voids authorizations left uncaptured past the merchant's
+// auth_auto_void_hours, releasing the customer's held funds before the issuer lets them lapse
+type AuthAutoVoidHandler struct {
+	queries    AuthAutoVoidQueryExecutor
+	payments   AuthVoider
+	logger     *zap.Logger
+	cronSecret string
+	now        func() time.Time
+}
+
+// NewAuthAutoVoidHandler creates a new auth auto-void cron handler
+func NewAuthAutoVoidHandler(
+	payments AuthVoider,
+	db *database.PostgreSQLAdapter,
+	logger *zap.Logger,
+	cronSecret string,
+) *AuthAutoVoidHandler {
+	return NewAuthAutoVoidHandlerWithQueries(payments, db.Queries(), logger, cronSecret)
+}
+
+// NewAuthAutoVoidHandlerWithQueries creates an auth auto-void handler with a custom query executor
+func NewAuthAutoVoidHandlerWithQueries(
+	payments AuthVoider,
+	queries AuthAutoVoidQueryExecutor,
+	logger *zap.Logger,
+	cronSecret string,
+) *AuthAutoVoidHandler {
+	return &AuthAutoVoidHandler{
+		queries:    queries,
+		payments:   payments,
+		logger:     logger,
+		cronSecret: cronSecret,
+		now:        time.Now,
+	}
+}
+
+// AuthAutoVoidRequest represents the request body for an auth auto-void run
+type AuthAutoVoidRequest struct {
+	AgentID *string `json:"agent_id"` // Optional: only this agent's authorizations, otherwise all active agents
+}
+
+// AuthAutoVoidResponse represents the response from an auth auto-void run
+type AuthAutoVoidResponse struct {
+	Success     bool     `json:"success"`
+	Found       int      `json:"found"`    // Uncaptured authorizations past their merchant's window
+	Voided      int      `json:"voided"`   // Voids approved by EPX
+	Declined    int      `json:"declined"` // Voids EPX declined; retried on the next run
+	Errors      []string `json:"errors,omitempty"`
+	ProcessedAt string   `json:"processed_at"`
+}
+
+// VoidExpiredAuthorizations handles the POST /cron/auth-auto-void endpoint
+// This endpoint is called by Cloud Scheduler (e.g. hourly). An authorization is due once its latest approval
+// (the auth or a completed increment) is older than the merchant's auth_auto_void_hours and its group has no
+// capture and no void. Merchants with auth_auto_void_hours 0 are skipped.
+func (h *AuthAutoVoidHandler) VoidExpiredAuthorizations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, http.StatusMethodNotAllowed, "only POST method is allowed")
+		return
+	}
+
+	if !h.authenticateRequest(r) {
+		h.logger.Warn("Unauthorized cron request",
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		h.respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req AuthAutoVoidRequest
+	if r.Body != nil && r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+	}
+
+	ctx := r.Context()
+	now := h.now().UTC()
+
+	var agents []sqlc.AgentCredential
+	if req.AgentID != nil {
+		agent, err := h.queries.GetAgentByAgentID(ctx, *req.AgentID)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("agent not found: %v", err))
+			return
+		}
+		agents = []sqlc.AgentCredential{agent}
+	} else {
+		var err error
+		agents, err = h.queries.ListActiveAgents(ctx)
+		if err != nil {
+			h.respondError(w, http.StatusInternalServerError, fmt.Sprintf("failed to list agents: %v", err))
+			return
+		}
+	}
+
+	resp := AuthAutoVoidResponse{
+		Success:     true,
+		ProcessedAt: now.Format(time.RFC3339),
+	}
+
+	for i := range agents {
+		agent := &agents[i]
+		config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+		if err != nil {
+			h.logger.Error("Skipping agent with invalid merchant config", zap.String("agent_id", agent.AgentID), zap.Error(err))
+			resp.Success = false
+			resp.Errors = append(resp.Errors, fmt.Sprintf("agent %s: %v", agent.AgentID, err))
+			continue
+		}
+		cutoff, ok := config.AuthAutoVoidCutoff(now)
+		if !ok {
+			continue
+		}
+
+		auths, err := h.queries.ListUncapturedAuthorizations(ctx, sqlc.ListUncapturedAuthorizationsParams{
+			AgentID:        agent.AgentID,
+			ApprovedBefore: cutoff,
+			LimitVal:       maxAuthAutoVoidsPerAgent,
+		})
+		if err != nil {
+			resp.Success = false
+			resp.Errors = append(resp.Errors, fmt.Sprintf("agent %s: list uncaptured authorizations: %v", agent.AgentID, err))
+			continue
+		}
+		resp.Found += len(auths)
+
+		for j := range auths {
+			auth := &auths[j]
+			void, err := h.payments.Void(ctx, &ports.VoidRequest{
+				TransactionID: auth.ID.String(),
+				Actor:         authAutoVoidActor,
+			})
+			if err != nil {
+				resp.Success = false
+				resp.Errors = append(resp.Errors, fmt.Sprintf("transaction %s: %v", auth.ID, err))
+				h.logger.Error("Failed to auto-void authorization",
+					zap.String("transaction_id", auth.ID.String()),
+					zap.String("agent_id", auth.AgentID),
+					zap.Error(err),
+				)
+				continue
+			}
+			if void.Status != domain.TransactionStatusVoided {
+				resp.Declined++
+				h.logger.Warn("Auto-void of authorization declined",
+					zap.String("transaction_id", auth.ID.String()),
+					zap.String("agent_id", auth.AgentID),
+				)
+				continue
+			}
+
+			resp.Voided++
+			h.logger.Info("Uncaptured authorization auto-voided",
+				zap.String("transaction_id", auth.ID.String()),
+				zap.String("agent_id", auth.AgentID),
+				zap.String("group_id", auth.GroupID.String()),
+				zap.Time("authorized_at", auth.CreatedAt),
+			)
+		}
+	}
+
+	h.logger.Info("Auth auto-void processed",
+		zap.Int("found", resp.Found),
+		zap.Int("voided", resp.Voided),
+		zap.Int("declined", resp.Declined),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Success {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusPartialContent)
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// authenticateRequest verifies the cron request is authorized
+func (h *AuthAutoVoidHandler) authenticateRequest(r *http.Request) bool {
+	// Check X-Cron-Secret header
+	cronSecret := r.Header.Get("X-Cron-Secret")
+	if cronSecret != "" && cronSecret == h.cronSecret {
+		return true
+	}
+
+	// Check Authorization header (Bearer token)
+	authHeader := r.Header.Get("Authorization")
+	return authHeader == "Bearer "+h.cronSecret
+}
+
+// respondError sends an error response
+func (h *AuthAutoVoidHandler) respondError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	resp := map[string]interface{}{
+		"success": false,
+		"error":   message,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode error response", zap.Error(err))
+	}
+}
@@ -0,0 +1,220 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/ports"
+)
+
+var autoVoidTestNow = time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+
+// fakeAuthStore applies the auth auto-void queries to in-memory transactions and records voids in their group
+type fakeAuthStore struct {
+	agents       []sqlc.AgentCredential
+	transactions []sqlc.Transaction
+	voided       []string
+	declineVoids bool
+}
+
+func (f *fakeAuthStore) GetAgentByAgentID(ctx context.Context, agentID string) (sqlc.AgentCredential, error) {
+	for _, agent := range f.agents {
+		if agent.AgentID == agentID {
+			return agent, nil
+		}
+	}
+	return sqlc.AgentCredential{}, fmt.Errorf("no rows")
+}
+
+func (f *fakeAuthStore) ListActiveAgents(ctx context.Context) ([]sqlc.AgentCredential, error) {
+	return f.agents, nil
+}
+
+func (f *fakeAuthStore) ListUncapturedAuthorizations(ctx context.Context, arg sqlc.ListUncapturedAuthorizationsParams) ([]sqlc.Transaction, error) {
+	var result []sqlc.Transaction
+	for _, tx := range f.transactions {
+		if tx.AgentID != arg.AgentID || tx.Type != "auth" || tx.Status != "completed" || !tx.CreatedAt.Before(arg.ApprovedBefore) {
+			continue
+		}
+		if !f.closedOrRecent(tx.GroupID, arg.ApprovedBefore) {
+			result = append(result, tx)
+		}
+	}
+	return result, nil
+}
+
+// closedOrRecent reports whether the group was captured or voided, or incremented at or after cutoff
+func (f *fakeAuthStore) closedOrRecent(groupID uuid.UUID, cutoff time.Time) bool {
+	for _, tx := range f.transactions {
+		if tx.GroupID != groupID {
+			continue
+		}
+		if tx.Status == "voided" ||
+			(tx.Type == "capture" && tx.Status == "completed") ||
+			(tx.Type == "increment" && tx.Status == "completed" && !tx.CreatedAt.Before(cutoff)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeAuthStore) Void(ctx context.Context, req *ports.VoidRequest) (*domain.Transaction, error) {
+	for _, original := range f.transactions {
+		if original.ID.String() != req.TransactionID {
+			continue
+		}
+		status := domain.TransactionStatusVoided
+		if f.declineVoids {
+			status = domain.TransactionStatusFailed
+		}
+		f.transactions = append(f.transactions, sqlc.Transaction{
+			ID:        uuid.New(),
+			GroupID:   original.GroupID,
+			AgentID:   original.AgentID,
+			Type:      "charge",
+			Status:    string(status),
+			CreatedAt: autoVoidTestNow,
+		})
+		f.voided = append(f.voided, req.TransactionID)
+		return &domain.Transaction{ID: uuid.NewString(), Status: status}, nil
+	}
+	return nil, domain.ErrTransactionNotFound
+}
+
+func (f *fakeAuthStore) addGroup(agentID string, authorizedAt time.Time, followUps ...sqlc.Transaction) sqlc.Transaction {
+	auth := sqlc.Transaction{
+		ID:        uuid.New(),
+		GroupID:   uuid.New(),
+		AgentID:   agentID,
+		Type:      "auth",
+		Status:    "completed",
+		AuthGuid:  pgtype.Text{String: "BRIC-" + agentID, Valid: true},
+		CreatedAt: authorizedAt,
+	}
+	f.transactions = append(f.transactions, auth)
+	for _, tx := range followUps {
+		tx.ID = uuid.New()
+		tx.GroupID = auth.GroupID
+		tx.AgentID = agentID
+		f.transactions = append(f.transactions, tx)
+	}
+	return auth
+}
+
+func testAgent(agentID string, overrides string) sqlc.AgentCredential {
+	agent := sqlc.AgentCredential{AgentID: agentID, Tier: "standard"}
+	if overrides != "" {
+		agent.ConfigOverrides = []byte(overrides)
+	}
+	return agent
+}
+
+func daysAgo(days int) time.Time {
+	return autoVoidTestNow.AddDate(0, 0, -days)
+}
+
+func runAuthAutoVoid(t *testing.T, store *fakeAuthStore, body string) AuthAutoVoidResponse {
+	t.Helper()
+	h := NewAuthAutoVoidHandlerWithQueries(store, store, zap.NewNop(), "secret")
+	h.now = func() time.Time { return autoVoidTestNow }
+
+	req := httptest.NewRequest(http.MethodPost, "/cron/auth-auto-void", strings.NewReader(body))
+	req.Header.Set("X-Cron-Secret", "secret")
+	rec := httptest.NewRecorder()
+	h.VoidExpiredAuthorizations(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp AuthAutoVoidResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	return resp
+}
+
+func TestVoidExpiredAuthorizations(t *testing.T) {
+	store := &fakeAuthStore{agents: []sqlc.AgentCredential{testAgent("agent-1", "")}}
+	expired := store.addGroup("agent-1", daysAgo(8))
+	captured := store.addGroup("agent-1", daysAgo(9), sqlc.Transaction{Type: "capture", Status: "completed", CreatedAt: daysAgo(8)})
+	recent := store.addGroup("agent-1", daysAgo(3))
+	incremented := store.addGroup("agent-1", daysAgo(10), sqlc.Transaction{Type: "increment", Status: "completed", CreatedAt: daysAgo(2)})
+
+	resp := runAuthAutoVoid(t, store, "")
+	assert.True(t, resp.Success)
+	assert.Equal(t, 1, resp.Found)
+	assert.Equal(t, 1, resp.Voided)
+	assert.Equal(t, []string{expired.ID.String()}, store.voided, "only the uncaptured auth past the 7-day default is voided")
+	assert.NotContains(t, store.voided, captured.ID.String())
+	assert.NotContains(t, store.voided, recent.ID.String())
+	assert.NotContains(t, store.voided, incremented.ID.String(), "an increment re-approves the hold")
+
+	again := runAuthAutoVoid(t, store, "")
+	assert.Zero(t, again.Found, "a voided group isn't voided twice")
+}
+
+func TestVoidExpiredAuthorizations_PerMerchantWindow(t *testing.T) {
+	store := &fakeAuthStore{agents: []sqlc.AgentCredential{
+		testAgent("short", `{"auth_auto_void_hours": 24}`),
+		testAgent("off", `{"auth_auto_void_hours": 0}`),
+	}}
+	short := store.addGroup("short", daysAgo(2))
+	store.addGroup("off", daysAgo(30))
+
+	resp := runAuthAutoVoid(t, store, "")
+	assert.Equal(t, 1, resp.Voided)
+	assert.Equal(t, []string{short.ID.String()}, store.voided, "auth_auto_void_hours 0 turns the job off for the merchant")
+
+	store.addGroup("short", daysAgo(3))
+	store.addGroup("other", daysAgo(30))
+	store.agents = append(store.agents, testAgent("other", ""))
+	resp = runAuthAutoVoid(t, store, `{"agent_id": "short"}`)
+	assert.Equal(t, 1, resp.Voided, "agent_id limits the run to one merchant")
+}
+
+func TestVoidExpiredAuthorizations_DeclinedVoidRetried(t *testing.T) {
+	store := &fakeAuthStore{agents: []sqlc.AgentCredential{testAgent("agent-1", "")}, declineVoids: true}
+	store.addGroup("agent-1", daysAgo(8))
+
+	resp := runAuthAutoVoid(t, store, "")
+	assert.Equal(t, 1, resp.Declined)
+	assert.Zero(t, resp.Voided)
+
+	store.declineVoids = false
+	resp = runAuthAutoVoid(t, store, "")
+	assert.Equal(t, 1, resp.Voided, "a declined void leaves the auth open for the next run")
+}
+
+func TestVoidExpiredAuthorizations_InvalidConfigSkipsAgent(t *testing.T) {
+	store := &fakeAuthStore{agents: []sqlc.AgentCredential{
+		testAgent("broken", `{"auth_auto_void_hours": "soon"}`),
+		testAgent("agent-1", ""),
+	}}
+	store.addGroup("broken", daysAgo(30))
+	expired := store.addGroup("agent-1", daysAgo(8))
+
+	h := NewAuthAutoVoidHandlerWithQueries(store, store, zap.NewNop(), "secret")
+	h.now = func() time.Time { return autoVoidTestNow }
+	req := httptest.NewRequest(http.MethodPost, "/cron/auth-auto-void", nil)
+	req.Header.Set("X-Cron-Secret", "secret")
+	rec := httptest.NewRecorder()
+	h.VoidExpiredAuthorizations(rec, req)
+	require.Equal(t, http.StatusPartialContent, rec.Code, rec.Body.String())
+
+	var resp AuthAutoVoidResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.False(t, resp.Success)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0], "agent broken")
+	assert.Equal(t, []string{expired.ID.String()}, store.voided, "unreadable overrides never fall back to the default window")
+}
@@ -8,13 +8,35 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/kevin07696/payment-service/internal/services/ports"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/adapters/database"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/ports"
 )
 
+// billingCronJob names billing runs in cron_runs
+const billingCronJob = "process-billing"
+
+// Billing run history returned by /cron/stats
+const (
+	defaultCronRunsLimit = 20
+	maxCronRunsLimit     = 100
+)
+
+// CronRunQueryExecutor defines the queries used to record and list cron runs
+type CronRunQueryExecutor interface {
+	CreateCronRun(ctx context.Context, arg sqlc.CreateCronRunParams) (sqlc.CronRun, error)
+	ListCronRuns(ctx context.Context, arg sqlc.ListCronRunsParams) ([]sqlc.CronRun, error)
+}
+
 // BillingHandler handles cron job endpoints for subscription billing
 type BillingHandler struct {
 	subscriptionService ports.SubscriptionService
+	runs                CronRunQueryExecutor
 	logger              *zap.Logger
 	cronSecret          string // Secret token for authenticating cron requests
 }
@@ -22,11 +44,23 @@ type BillingHandler struct {
 // NewBillingHandler creates a new billing cron handler
 func NewBillingHandler(
 	subscriptionService ports.SubscriptionService,
+	db *database.PostgreSQLAdapter,
+	logger *zap.Logger,
+	cronSecret string,
+) *BillingHandler {
+	return NewBillingHandlerWithQueries(subscriptionService, db.Queries(), logger, cronSecret)
+}
+
+// NewBillingHandlerWithQueries creates a billing handler with a custom query executor for run history
+func NewBillingHandlerWithQueries(
+	subscriptionService ports.SubscriptionService,
+	runs CronRunQueryExecutor,
 	logger *zap.Logger,
 	cronSecret string,
 ) *BillingHandler {
 	return &BillingHandler{
 		subscriptionService: subscriptionService,
+		runs:                runs,
 		logger:              logger,
 		cronSecret:          cronSecret,
 	}
@@ -40,12 +74,16 @@ type ProcessBillingRequest struct {
 
 // ProcessBillingResponse represents the response from billing processing
 type ProcessBillingResponse struct {
-	Success      bool     `json:"success"`
-	Processed    int      `json:"processed"`
-	SuccessCount int      `json:"success_count"`
-	FailureCount int      `json:"failure_count"`
-	Errors       []string `json:"errors,omitempty"`
-	ProcessedAt  string   `json:"processed_at"`
+	Success       bool     `json:"success"`
+	RunID         string   `json:"run_id,omitempty"` // Empty if the run couldn't be recorded
+	Processed     int      `json:"processed"`
+	SuccessCount  int      `json:"success_count"`
+	FailureCount  int      `json:"failure_count"`
+	RetriedCount  int      `json:"retried_count"`
+	AmountCharged string   `json:"amount_charged"`
+	DurationMs    int64    `json:"duration_ms"`
+	Errors        []string `json:"errors,omitempty"`
+	ProcessedAt   string   `json:"processed_at"`
 }
 
 // ProcessBilling handles the POST /cron/process-billing endpoint
@@ -104,30 +142,60 @@ func (h *BillingHandler) ProcessBilling(w http.ResponseWriter, r *http.Request)
 		batchSize = *req.BatchSize
 	}
 
-	// Process billing
 	ctx := context.Background()
-	processed, success, failed, errs := h.subscriptionService.ProcessDueBilling(ctx, asOfDate, batchSize)
+
+	// ?dry_run=true prices the run without charging or recording anything
+	if dryRunParam := r.URL.Query().Get("dry_run"); dryRunParam != "" {
+		dryRun, err := strconv.ParseBool(dryRunParam)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "dry_run must be true or false")
+			return
+		}
+		if dryRun {
+			h.previewBilling(ctx, w, asOfDate, batchSize)
+			return
+		}
+	}
+
+	// Process billing
+	startedAt := time.Now()
+	result := h.subscriptionService.ProcessDueBilling(ctx, asOfDate, batchSize)
+	finishedAt := time.Now()
 
 	// Build response
 	resp := ProcessBillingResponse{
-		Success:      failed == 0,
-		Processed:    processed,
-		SuccessCount: success,
-		FailureCount: failed,
-		ProcessedAt:  time.Now().Format(time.RFC3339),
+		Success:       result.Failed == 0,
+		Processed:     result.Processed,
+		SuccessCount:  result.Succeeded,
+		FailureCount:  result.Failed,
+		RetriedCount:  result.Retried,
+		AmountCharged: result.AmountCharged.StringFixed(2),
+		DurationMs:    finishedAt.Sub(startedAt).Milliseconds(),
+		ProcessedAt:   finishedAt.Format(time.RFC3339),
 	}
 
-	if len(errs) > 0 {
-		resp.Errors = make([]string, len(errs))
-		for i, err := range errs {
+	if len(result.Errors) > 0 {
+		resp.Errors = make([]string, len(result.Errors))
+		for i, err := range result.Errors {
 			resp.Errors[i] = err.Error()
 		}
 	}
 
+	// The run's outcome is reported either way; a lost history row is only logged
+	run, err := h.recordRun(ctx, result, resp.Errors, startedAt, finishedAt)
+	if err != nil {
+		h.logger.Error("Failed to record billing run", zap.Error(err))
+	} else {
+		resp.RunID = run.ID.String()
+	}
+
 	h.logger.Info("Billing processing completed",
-		zap.Int("processed", processed),
-		zap.Int("success", success),
-		zap.Int("failed", failed),
+		zap.Int("processed", result.Processed),
+		zap.Int("success", result.Succeeded),
+		zap.Int("failed", result.Failed),
+		zap.Int("retried", result.Retried),
+		zap.String("amount_charged", resp.AmountCharged),
+		zap.Int64("duration_ms", resp.DurationMs),
 	)
 
 	// Respond with JSON
@@ -143,6 +211,102 @@ func (h *BillingHandler) ProcessBilling(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// PlannedChargeResponse is one charge a dry run would make
+type PlannedChargeResponse struct {
+	SubscriptionID  string `json:"subscription_id"`
+	AgentID         string `json:"agent_id"`
+	CustomerID      string `json:"customer_id"`
+	PaymentMethodID string `json:"payment_method_id"`
+	PaymentType     string `json:"payment_type,omitempty"`
+	LastFour        string `json:"last_four,omitempty"`
+	Amount          string `json:"amount"`
+	Currency        string `json:"currency"`
+	CouponApplied   bool   `json:"coupon_applied"`
+	WouldFail       string `json:"would_fail,omitempty"` // Why the charge would fail before EPX
+}
+
+// DryRunBillingResponse represents the response from a billing dry run
+type DryRunBillingResponse struct {
+	Success     bool                    `json:"success"`
+	DryRun      bool                    `json:"dry_run"`
+	Count       int                     `json:"count"`
+	TotalAmount string                  `json:"total_amount"` // Sum of the charges that would be sent to EPX
+	Charges     []PlannedChargeResponse `json:"charges"`
+	ProcessedAt string                  `json:"processed_at"`
+}
+
+// previewBilling responds with the charges a billing run would make. Nothing is charged or written.
+func (h *BillingHandler) previewBilling(ctx context.Context, w http.ResponseWriter, asOfDate time.Time, batchSize int) {
+	charges, err := h.subscriptionService.PreviewDueBilling(ctx, asOfDate, batchSize)
+	if err != nil {
+		h.logger.Error("Failed to preview billing", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "failed to list due subscriptions")
+		return
+	}
+
+	resp := DryRunBillingResponse{
+		Success:     true,
+		DryRun:      true,
+		Count:       len(charges),
+		Charges:     make([]PlannedChargeResponse, len(charges)),
+		ProcessedAt: time.Now().Format(time.RFC3339),
+	}
+	total := decimal.Zero
+	for i, charge := range charges {
+		if charge.WouldFail == "" {
+			total = total.Add(charge.Amount)
+		}
+		resp.Charges[i] = PlannedChargeResponse{
+			SubscriptionID:  charge.SubscriptionID,
+			AgentID:         charge.AgentID,
+			CustomerID:      charge.CustomerID,
+			PaymentMethodID: charge.PaymentMethodID,
+			PaymentType:     charge.PaymentType,
+			LastFour:        charge.LastFour,
+			Amount:          charge.Amount.StringFixed(2),
+			Currency:        charge.Currency,
+			CouponApplied:   charge.CouponApplied,
+			WouldFail:       charge.WouldFail,
+		}
+	}
+	resp.TotalAmount = total.StringFixed(2)
+
+	h.logger.Info("Billing dry run completed",
+		zap.Int("count", resp.Count),
+		zap.String("total_amount", resp.TotalAmount),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// recordRun stores a billing run's outcome in cron_runs
+func (h *BillingHandler) recordRun(ctx context.Context, result *domain.BillingRunResult, errs []string, startedAt, finishedAt time.Time) (sqlc.CronRun, error) {
+	if errs == nil {
+		errs = []string{}
+	}
+	errorsJSON, err := json.Marshal(errs)
+	if err != nil {
+		return sqlc.CronRun{}, err
+	}
+
+	return h.runs.CreateCronRun(ctx, sqlc.CreateCronRunParams{
+		Job:           billingCronJob,
+		StartedAt:     startedAt,
+		FinishedAt:    finishedAt,
+		DurationMs:    finishedAt.Sub(startedAt).Milliseconds(),
+		Processed:     int32(result.Processed),
+		Succeeded:     int32(result.Succeeded),
+		Failed:        int32(result.Failed),
+		Retried:       int32(result.Retried),
+		AmountCharged: pgtype.Numeric{Int: result.AmountCharged.Coefficient(), Exp: result.AmountCharged.Exponent(), Valid: true},
+		Errors:        errorsJSON,
+	})
+}
+
 // authenticateRequest verifies the cron request is authorized
 func (h *BillingHandler) authenticateRequest(r *http.Request) bool {
 	// Check X-Cron-Secret header
@@ -202,7 +366,29 @@ func (h *BillingHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
-// Stats handles GET /cron/stats for monitoring billing statistics
+// BillingRun is one billing run in the /cron/stats response
+type BillingRun struct {
+	ID            string   `json:"id"`
+	StartedAt     string   `json:"started_at"`
+	FinishedAt    string   `json:"finished_at"`
+	DurationMs    int64    `json:"duration_ms"`
+	Processed     int32    `json:"processed"`
+	Succeeded     int32    `json:"succeeded"`
+	Failed        int32    `json:"failed"`
+	Retried       int32    `json:"retried"`
+	AmountCharged string   `json:"amount_charged"`
+	Errors        []string `json:"errors,omitempty"`
+}
+
+// BillingStatsResponse represents the response from /cron/stats
+type BillingStatsResponse struct {
+	Success   bool         `json:"success"`
+	Job       string       `json:"job"`
+	Runs      []BillingRun `json:"runs"` // Newest first
+	Timestamp string       `json:"timestamp"`
+}
+
+// Stats handles GET /cron/stats, returning the last N billing runs (?limit=, default 20, max 100)
 func (h *BillingHandler) Stats(w http.ResponseWriter, r *http.Request) {
 	// Authenticate the request
 	if !h.authenticateRequest(r) {
@@ -210,34 +396,57 @@ func (h *BillingHandler) Stats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse query parameters
-	days := 7
-	if daysParam := r.URL.Query().Get("days"); daysParam != "" {
-		if parsed, err := strconv.Atoi(daysParam); err == nil && parsed > 0 && parsed <= 90 {
-			days = parsed
+	limit := defaultCronRunsLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 || parsed > maxCronRunsLimit {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxCronRunsLimit))
+			return
 		}
+		limit = parsed
+	}
+
+	runs, err := h.runs.ListCronRuns(r.Context(), sqlc.ListCronRunsParams{
+		Job:      billingCronJob,
+		LimitVal: int32(limit),
+	})
+	if err != nil {
+		h.logger.Error("Failed to list billing runs", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "failed to list billing runs")
+		return
+	}
+
+	resp := BillingStatsResponse{
+		Success:   true,
+		Job:       billingCronJob,
+		Runs:      make([]BillingRun, len(runs)),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	for i, run := range runs {
+		resp.Runs[i] = billingRunFromRow(run)
 	}
 
-	// In a real implementation, you would query the database for stats
-	// For now, return placeholder data
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-
-	resp := map[string]interface{}{
-		"success": true,
-		"period":  fmt.Sprintf("last_%d_days", days),
-		"stats": map[string]interface{}{
-			"total_subscriptions":    0,
-			"active_subscriptions":   0,
-			"paused_subscriptions":   0,
-			"past_due_subscriptions": 0,
-			"billing_runs":           0,
-			"total_processed":        0,
-			"total_succeeded":        0,
-			"total_failed":           0,
-		},
-		"timestamp": time.Now().Format(time.RFC3339),
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode response", zap.Error(err))
 	}
+}
 
-	json.NewEncoder(w).Encode(resp)
+func billingRunFromRow(run sqlc.CronRun) BillingRun {
+	var errs []string
+	_ = json.Unmarshal(run.Errors, &errs)
+
+	return BillingRun{
+		ID:            run.ID.String(),
+		StartedAt:     run.StartedAt.Format(time.RFC3339),
+		FinishedAt:    run.FinishedAt.Format(time.RFC3339),
+		DurationMs:    run.DurationMs,
+		Processed:     run.Processed,
+		Succeeded:     run.Succeeded,
+		Failed:        run.Failed,
+		Retried:       run.Retried,
+		AmountCharged: decimal.NewFromBigInt(run.AmountCharged.Int, run.AmountCharged.Exp).StringFixed(2),
+		Errors:        errs,
+	}
 }
@@ -0,0 +1,189 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/ports"
+)
+
+// fakeBillingService returns a fixed billing run result and dry run preview
+type fakeBillingService struct {
+	ports.SubscriptionService
+	result    *domain.BillingRunResult
+	planned   []*domain.PlannedCharge
+	runCalled bool
+}
+
+func (f *fakeBillingService) ProcessDueBilling(ctx context.Context, asOfDate time.Time, batchSize int) *domain.BillingRunResult {
+	f.runCalled = true
+	return f.result
+}
+
+func (f *fakeBillingService) PreviewDueBilling(ctx context.Context, asOfDate time.Time, batchSize int) ([]*domain.PlannedCharge, error) {
+	return f.planned, nil
+}
+
+// fakeCronRuns stores cron runs in memory
+type fakeCronRuns struct {
+	runs []sqlc.CronRun
+}
+
+func (f *fakeCronRuns) CreateCronRun(ctx context.Context, arg sqlc.CreateCronRunParams) (sqlc.CronRun, error) {
+	run := sqlc.CronRun{
+		ID:            uuid.New(),
+		Job:           arg.Job,
+		StartedAt:     arg.StartedAt,
+		FinishedAt:    arg.FinishedAt,
+		DurationMs:    arg.DurationMs,
+		Processed:     arg.Processed,
+		Succeeded:     arg.Succeeded,
+		Failed:        arg.Failed,
+		Retried:       arg.Retried,
+		AmountCharged: arg.AmountCharged,
+		Errors:        arg.Errors,
+	}
+	f.runs = append(f.runs, run)
+	return run, nil
+}
+
+func (f *fakeCronRuns) ListCronRuns(ctx context.Context, arg sqlc.ListCronRunsParams) ([]sqlc.CronRun, error) {
+	var result []sqlc.CronRun
+	for _, run := range f.runs {
+		if run.Job == arg.Job {
+			result = append(result, run)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].StartedAt.After(result[j].StartedAt) })
+	if len(result) > int(arg.LimitVal) {
+		result = result[:arg.LimitVal]
+	}
+	return result, nil
+}
+
+func TestProcessBilling_RecordsRun(t *testing.T) {
+	runs := &fakeCronRuns{}
+	service := &fakeBillingService{result: &domain.BillingRunResult{
+		Processed:     4,
+		Succeeded:     3,
+		Failed:        1,
+		Retried:       1,
+		AmountCharged: decimal.RequireFromString("74.97"),
+		Errors:        []error{errors.New("subscription 1: transaction declined: Insufficient funds")},
+	}}
+	handler := NewBillingHandlerWithQueries(service, runs, zap.NewNop(), "secret")
+
+	req := httptest.NewRequest(http.MethodPost, "/cron/process-billing", nil)
+	req.Header.Set("X-Cron-Secret", "secret")
+	rec := httptest.NewRecorder()
+	handler.ProcessBilling(rec, req)
+
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	var resp ProcessBillingResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, 3, resp.SuccessCount)
+	assert.Equal(t, 1, resp.FailureCount)
+	assert.Equal(t, 1, resp.RetriedCount)
+	assert.Equal(t, "74.97", resp.AmountCharged)
+
+	require.Len(t, runs.runs, 1)
+	run := runs.runs[0]
+	assert.Equal(t, resp.RunID, run.ID.String())
+	assert.Equal(t, "process-billing", run.Job)
+	assert.Equal(t, int32(4), run.Processed)
+	assert.Equal(t, int32(3), run.Succeeded)
+	assert.Equal(t, int32(1), run.Failed)
+	assert.Equal(t, int32(1), run.Retried)
+	assert.True(t, decimal.NewFromBigInt(run.AmountCharged.Int, run.AmountCharged.Exp).Equal(decimal.RequireFromString("74.97")))
+	assert.False(t, run.FinishedAt.Before(run.StartedAt))
+	assert.JSONEq(t, `["subscription 1: transaction declined: Insufficient funds"]`, string(run.Errors))
+}
+
+func TestStats_ReturnsLastRuns(t *testing.T) {
+	runs := &fakeCronRuns{}
+	start := time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_, err := runs.CreateCronRun(context.Background(), sqlc.CreateCronRunParams{
+			Job:           billingCronJob,
+			StartedAt:     start.AddDate(0, 0, i),
+			FinishedAt:    start.AddDate(0, 0, i).Add(time.Second),
+			DurationMs:    1000,
+			Succeeded:     int32(i),
+			AmountCharged: pgtype.Numeric{Int: big.NewInt(1999), Exp: -2, Valid: true},
+			Errors:        []byte(`[]`),
+		})
+		require.NoError(t, err)
+	}
+	handler := NewBillingHandlerWithQueries(&fakeBillingService{}, runs, zap.NewNop(), "secret")
+
+	req := httptest.NewRequest(http.MethodGet, "/cron/stats?limit=2", nil)
+	req.Header.Set("X-Cron-Secret", "secret")
+	rec := httptest.NewRecorder()
+	handler.Stats(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp BillingStatsResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Runs, 2)
+	assert.Equal(t, int32(2), resp.Runs[0].Succeeded, "newest run first")
+	assert.Equal(t, int32(1), resp.Runs[1].Succeeded)
+	assert.Equal(t, "19.99", resp.Runs[0].AmountCharged)
+
+	for _, limit := range []string{"0", "101", "x"} {
+		req := httptest.NewRequest(http.MethodGet, "/cron/stats?limit="+limit, nil)
+		req.Header.Set("X-Cron-Secret", "secret")
+		rec := httptest.NewRecorder()
+		handler.Stats(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "limit %s", limit)
+	}
+}
+
+func TestProcessBilling_DryRun(t *testing.T) {
+	runs := &fakeCronRuns{}
+	service := &fakeBillingService{planned: []*domain.PlannedCharge{
+		{SubscriptionID: "sub-1", PaymentMethodID: "pm-1", PaymentType: "credit_card", LastFour: "4242", Amount: decimal.RequireFromString("29.99"), Currency: "USD"},
+		{SubscriptionID: "sub-2", PaymentMethodID: "pm-2", PaymentType: "ach", Amount: decimal.RequireFromString("10"), Currency: "USD", WouldFail: "payment method is not active"},
+	}}
+	handler := NewBillingHandlerWithQueries(service, runs, zap.NewNop(), "secret")
+
+	req := httptest.NewRequest(http.MethodPost, "/cron/process-billing?dry_run=true", nil)
+	req.Header.Set("X-Cron-Secret", "secret")
+	rec := httptest.NewRecorder()
+	handler.ProcessBilling(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp DryRunBillingResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.True(t, resp.DryRun)
+	assert.Equal(t, 2, resp.Count)
+	assert.Equal(t, "29.99", resp.TotalAmount, "charges that would fail aren't counted")
+	require.Len(t, resp.Charges, 2)
+	assert.Equal(t, "pm-1", resp.Charges[0].PaymentMethodID)
+	assert.Equal(t, "4242", resp.Charges[0].LastFour)
+	assert.Equal(t, "10.00", resp.Charges[1].Amount)
+
+	assert.False(t, service.runCalled, "a dry run never reaches the charging path")
+	assert.Empty(t, runs.runs, "a dry run isn't recorded as a billing run")
+
+	req = httptest.NewRequest(http.MethodPost, "/cron/process-billing?dry_run=maybe", nil)
+	req.Header.Set("X-Cron-Secret", "secret")
+	rec = httptest.NewRecorder()
+	handler.ProcessBilling(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
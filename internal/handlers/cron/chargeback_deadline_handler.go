@@ -0,0 +1,266 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/adapters/database"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/services/webhook"
+)
+
+// Chargeback deadline reminder window (days ahead of today)
+const (
+	defaultChargebackDeadlineWindowDays = 5
+	maxChargebackDeadlineWindowDays     = 60
+)
+
+// ChargebackDeadlineQueryExecutor defines the queries used by the chargeback deadline sweep
+type ChargebackDeadlineQueryExecutor interface {
+	ListChargebacksDueWithin(ctx context.Context, arg sqlc.ListChargebacksDueWithinParams) ([]sqlc.Chargeback, error)
+	MarkChargebackDeadlineReminded(ctx context.Context, id uuid.UUID) error
+	MarkOverdueChargebacksLost(ctx context.Context, arg sqlc.MarkOverdueChargebacksLostParams) ([]sqlc.Chargeback, error)
+}
+
+// ChargebackDeadlineHandler handles the cron endpoint that reminds merchants of chargeback response
+// deadlines and marks unanswered chargebacks past their deadline as lost
+type ChargebackDeadlineHandler struct {
+	queries    ChargebackDeadlineQueryExecutor
+	events     EventPublisher
+	logger     *zap.Logger
+	cronSecret string
+	now        func() time.Time
+}
+
+// NewChargebackDeadlineHandler creates a new chargeback deadline cron handler
+func NewChargebackDeadlineHandler(
+	db *database.PostgreSQLAdapter,
+	events EventPublisher,
+	logger *zap.Logger,
+	cronSecret string,
+) *ChargebackDeadlineHandler {
+	return NewChargebackDeadlineHandlerWithQueries(db.Queries(), events, logger, cronSecret)
+}
+
+// NewChargebackDeadlineHandlerWithQueries creates a chargeback deadline handler with a custom query executor
+func NewChargebackDeadlineHandlerWithQueries(
+	queries ChargebackDeadlineQueryExecutor,
+	events EventPublisher,
+	logger *zap.Logger,
+	cronSecret string,
+) *ChargebackDeadlineHandler {
+	return &ChargebackDeadlineHandler{
+		queries:    queries,
+		events:     events,
+		logger:     logger,
+		cronSecret: cronSecret,
+		now:        time.Now,
+	}
+}
+
+// ChargebackDeadlineRequest represents the request body for a chargeback deadline run
+type ChargebackDeadlineRequest struct {
+	AgentID    *string `json:"agent_id"`    // Optional: only this agent's chargebacks, otherwise all agents
+	WithinDays *int    `json:"within_days"` // Optional: defaults to 5
+}
+
+// ChargebackDeadlineResponse represents the response from a chargeback deadline run
+type ChargebackDeadlineResponse struct {
+	Success     bool     `json:"success"`
+	WithinDays  int      `json:"within_days"`
+	MarkedLost  int      `json:"marked_lost"` // Unanswered chargebacks past their deadline
+	Found       int      `json:"found"`       // Deadlines in the window that hadn't been announced yet
+	Reminded    int      `json:"reminded"`    // chargeback.deadline_approaching webhooks sent
+	Errors      []string `json:"errors,omitempty"`
+	ProcessedAt string   `json:"processed_at"`
+}
+
+// ProcessDeadlines handles the POST /cron/chargeback-deadlines endpoint
+// This endpoint is called by Cloud Scheduler (e.g. daily). A chargeback is past its deadline once its
+// respond_by_date (UTC) has ended. Each deadline is announced once; a new date from North re-arms it.
+func (h *ChargebackDeadlineHandler) ProcessDeadlines(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, http.StatusMethodNotAllowed, "only POST method is allowed")
+		return
+	}
+
+	if !h.authenticateRequest(r) {
+		h.logger.Warn("Unauthorized cron request",
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		h.respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req ChargebackDeadlineRequest
+	if r.Body != nil && r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+	}
+
+	withinDays := defaultChargebackDeadlineWindowDays
+	if req.WithinDays != nil {
+		withinDays = *req.WithinDays
+	}
+	if withinDays < 1 || withinDays > maxChargebackDeadlineWindowDays {
+		h.respondError(w, http.StatusBadRequest, fmt.Sprintf("within_days must be between 1 and %d", maxChargebackDeadlineWindowDays))
+		return
+	}
+
+	ctx := r.Context()
+	now := h.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	agentID := pgtype.Text{Valid: false}
+	if req.AgentID != nil {
+		agentID = pgtype.Text{String: *req.AgentID, Valid: true}
+	}
+
+	lost, err := h.queries.MarkOverdueChargebacksLost(ctx, sqlc.MarkOverdueChargebacksLostParams{
+		AsOf:    pgtype.Date{Time: today, Valid: true},
+		AgentID: agentID,
+	})
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, fmt.Sprintf("failed to mark overdue chargebacks lost: %v", err))
+		return
+	}
+
+	resp := ChargebackDeadlineResponse{
+		Success:     true,
+		WithinDays:  withinDays,
+		MarkedLost:  len(lost),
+		ProcessedAt: now.Format(time.RFC3339),
+	}
+
+	for i := range lost {
+		cb := &lost[i]
+		h.logger.Info("Chargeback marked lost after missed deadline",
+			zap.String("chargeback_id", cb.ID.String()),
+			zap.String("agent_id", cb.AgentID),
+			zap.String("case_number", cb.CaseNumber),
+		)
+		if err := h.events.DeliverEvent(ctx, chargebackDeadlineEvent(webhook.EventChargebackUpdated, cb, today, now)); err != nil {
+			resp.Success = false
+			resp.Errors = append(resp.Errors, fmt.Sprintf("chargeback %s: deliver webhook: %v", cb.ID, err))
+		}
+	}
+
+	due, err := h.queries.ListChargebacksDueWithin(ctx, sqlc.ListChargebacksDueWithinParams{
+		AgentID:        agentID,
+		UnnotifiedOnly: true,
+		AsOf:           pgtype.Date{Time: today, Valid: true},
+		Cutoff:         pgtype.Date{Time: today.AddDate(0, 0, withinDays), Valid: true},
+	})
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, fmt.Sprintf("failed to list chargebacks due: %v", err))
+		return
+	}
+	resp.Found = len(due)
+
+	for i := range due {
+		cb := &due[i]
+		if err := h.remind(ctx, cb, today, now); err != nil {
+			resp.Success = false
+			resp.Errors = append(resp.Errors, fmt.Sprintf("chargeback %s: %v", cb.ID, err))
+			h.logger.Error("Failed to send chargeback deadline reminder",
+				zap.String("chargeback_id", cb.ID.String()),
+				zap.String("agent_id", cb.AgentID),
+				zap.Error(err),
+			)
+			continue
+		}
+		resp.Reminded++
+	}
+
+	h.logger.Info("Chargeback deadlines processed",
+		zap.Int("within_days", withinDays),
+		zap.Int("marked_lost", resp.MarkedLost),
+		zap.Int("found", resp.Found),
+		zap.Int("reminded", resp.Reminded),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Success {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusPartialContent)
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// remind delivers the chargeback.deadline_approaching webhook and records the reminder.
+// Delivery is synchronous so a chargeback is only marked reminded once its event is queued.
+func (h *ChargebackDeadlineHandler) remind(ctx context.Context, cb *sqlc.Chargeback, today, now time.Time) error {
+	if err := h.events.DeliverEvent(ctx, chargebackDeadlineEvent(webhook.EventChargebackDeadlineApproaching, cb, today, now)); err != nil {
+		return fmt.Errorf("deliver webhook: %w", err)
+	}
+	if err := h.queries.MarkChargebackDeadlineReminded(ctx, cb.ID); err != nil {
+		return fmt.Errorf("mark reminded: %w", err)
+	}
+	return nil
+}
+
+// chargebackDeadlineEvent builds a deadline webhook for a chargeback
+func chargebackDeadlineEvent(eventType string, cb *sqlc.Chargeback, today, now time.Time) *webhook.WebhookEvent {
+	data := map[string]interface{}{
+		"chargeback_id":   cb.ID.String(),
+		"case_number":     cb.CaseNumber,
+		"status":          cb.Status,
+		"amount":          cb.ChargebackAmount,
+		"currency":        cb.Currency,
+		"reason_code":     cb.ReasonCode,
+		"respond_by_date": cb.RespondByDate.Time.Format("2006-01-02"),
+	}
+	if eventType == webhook.EventChargebackDeadlineApproaching {
+		data["days_until_deadline"] = int(cb.RespondByDate.Time.Sub(today).Hours() / 24)
+	}
+	if cb.ReasonDescription.Valid {
+		data["reason_description"] = cb.ReasonDescription.String
+	}
+
+	return &webhook.WebhookEvent{
+		EventType: eventType,
+		AgentID:   cb.AgentID,
+		Data:      data,
+		Timestamp: now,
+	}
+}
+
+// authenticateRequest verifies the cron request is authorized
+func (h *ChargebackDeadlineHandler) authenticateRequest(r *http.Request) bool {
+	// Check X-Cron-Secret header
+	cronSecret := r.Header.Get("X-Cron-Secret")
+	if cronSecret != "" && cronSecret == h.cronSecret {
+		return true
+	}
+
+	// Check Authorization header (Bearer token)
+	authHeader := r.Header.Get("Authorization")
+	return authHeader == "Bearer "+h.cronSecret
+}
+
+// respondError sends an error response
+func (h *ChargebackDeadlineHandler) respondError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	resp := map[string]interface{}{
+		"success": false,
+		"error":   message,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode error response", zap.Error(err))
+	}
+}
@@ -0,0 +1,196 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/services/webhook"
+)
+
+// fakeDeadlineStore applies the chargeback deadline queries in memory
+type fakeDeadlineStore struct {
+	chargebacks []sqlc.Chargeback
+}
+
+func awaitingResponse(cb *sqlc.Chargeback) bool {
+	return (cb.Status == "new" || cb.Status == "pending") && !cb.ResponseSubmittedAt.Valid && cb.RespondByDate.Valid
+}
+
+func (f *fakeDeadlineStore) ListChargebacksDueWithin(ctx context.Context, arg sqlc.ListChargebacksDueWithinParams) ([]sqlc.Chargeback, error) {
+	var result []sqlc.Chargeback
+	for _, cb := range f.chargebacks {
+		if !awaitingResponse(&cb) {
+			continue
+		}
+		if arg.UnnotifiedOnly && cb.DeadlineReminderSentAt.Valid {
+			continue
+		}
+		if arg.AgentID.Valid && cb.AgentID != arg.AgentID.String {
+			continue
+		}
+		if cb.RespondByDate.Time.Before(arg.AsOf.Time) || cb.RespondByDate.Time.After(arg.Cutoff.Time) {
+			continue
+		}
+		result = append(result, cb)
+	}
+	return result, nil
+}
+
+func (f *fakeDeadlineStore) MarkChargebackDeadlineReminded(ctx context.Context, id uuid.UUID) error {
+	for i := range f.chargebacks {
+		if f.chargebacks[i].ID == id {
+			f.chargebacks[i].DeadlineReminderSentAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+func (f *fakeDeadlineStore) MarkOverdueChargebacksLost(ctx context.Context, arg sqlc.MarkOverdueChargebacksLostParams) ([]sqlc.Chargeback, error) {
+	var lost []sqlc.Chargeback
+	for i := range f.chargebacks {
+		cb := &f.chargebacks[i]
+		if !awaitingResponse(cb) || !cb.RespondByDate.Time.Before(arg.AsOf.Time) {
+			continue
+		}
+		if arg.AgentID.Valid && cb.AgentID != arg.AgentID.String {
+			continue
+		}
+		cb.Status = "lost"
+		cb.ResolvedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		lost = append(lost, *cb)
+	}
+	return lost, nil
+}
+
+var deadlineTestNow = time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+
+func newTestChargeback(caseNumber, status string, respondBy time.Time) sqlc.Chargeback {
+	return sqlc.Chargeback{
+		ID:               uuid.New(),
+		AgentID:          "agent-1",
+		CaseNumber:       caseNumber,
+		ChargebackAmount: "49.99",
+		Currency:         "USD",
+		ReasonCode:       "P22",
+		Status:           status,
+		RespondByDate:    pgtype.Date{Time: respondBy, Valid: true},
+	}
+}
+
+func newTestDeadlineHandler(store *fakeDeadlineStore, events *recordingPublisher) *ChargebackDeadlineHandler {
+	h := NewChargebackDeadlineHandlerWithQueries(store, events, zap.NewNop(), "secret")
+	h.now = func() time.Time { return deadlineTestNow }
+	return h
+}
+
+func runChargebackDeadlines(t *testing.T, h *ChargebackDeadlineHandler, body string) ChargebackDeadlineResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/cron/chargeback-deadlines", strings.NewReader(body))
+	req.Header.Set("X-Cron-Secret", "secret")
+	rec := httptest.NewRecorder()
+	h.ProcessDeadlines(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp ChargebackDeadlineResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	return resp
+}
+
+func day(d int) time.Time {
+	return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestProcessDeadlines_Reminders(t *testing.T) {
+	dueToday := newTestChargeback("CASE-TODAY", "new", day(10))
+	dueSoon := newTestChargeback("CASE-SOON", "pending", day(13))
+	dueLater := newTestChargeback("CASE-LATER", "new", day(25))
+	answered := newTestChargeback("CASE-ANSWERED", "under_review", day(12))
+	answered.ResponseSubmittedAt = pgtype.Timestamptz{Time: day(9), Valid: true}
+	noDeadline := newTestChargeback("CASE-UNDATED", "new", time.Time{})
+	noDeadline.RespondByDate = pgtype.Date{Valid: false}
+
+	store := &fakeDeadlineStore{chargebacks: []sqlc.Chargeback{dueToday, dueSoon, dueLater, answered, noDeadline}}
+	events := &recordingPublisher{}
+	h := newTestDeadlineHandler(store, events)
+
+	resp := runChargebackDeadlines(t, h, "")
+	assert.Equal(t, 5, resp.WithinDays)
+	assert.Zero(t, resp.MarkedLost)
+	assert.Equal(t, 2, resp.Found, "the last day to respond is still in the window")
+	assert.Equal(t, 2, resp.Reminded)
+	require.Len(t, events.events, 2)
+	for _, event := range events.events {
+		assert.Equal(t, webhook.EventChargebackDeadlineApproaching, event.EventType)
+		assert.Equal(t, "agent-1", event.AgentID)
+	}
+	assert.Equal(t, "CASE-TODAY", events.events[0].Data["case_number"])
+	assert.Equal(t, 0, events.events[0].Data["days_until_deadline"])
+	assert.Equal(t, "CASE-SOON", events.events[1].Data["case_number"])
+	assert.Equal(t, 3, events.events[1].Data["days_until_deadline"])
+	assert.Equal(t, "2025-03-13", events.events[1].Data["respond_by_date"])
+
+	resp = runChargebackDeadlines(t, h, "")
+	assert.Zero(t, resp.Found, "each deadline is announced once")
+	assert.Len(t, events.events, 2)
+
+	resp = runChargebackDeadlines(t, h, `{"within_days": 20}`)
+	assert.Equal(t, 1, resp.Reminded)
+	assert.Equal(t, "CASE-LATER", events.events[2].Data["case_number"])
+}
+
+func TestProcessDeadlines_AutoLoss(t *testing.T) {
+	overdue := newTestChargeback("CASE-OVERDUE", "pending", day(9))
+	answered := newTestChargeback("CASE-ANSWERED", "under_review", day(5))
+	answered.ResponseSubmittedAt = pgtype.Timestamptz{Time: day(4), Valid: true}
+	won := newTestChargeback("CASE-WON", "won", day(1))
+	dueToday := newTestChargeback("CASE-TODAY", "new", day(10))
+	dueToday.DeadlineReminderSentAt = pgtype.Timestamptz{Time: day(8), Valid: true}
+
+	store := &fakeDeadlineStore{chargebacks: []sqlc.Chargeback{overdue, answered, won, dueToday}}
+	events := &recordingPublisher{}
+	h := newTestDeadlineHandler(store, events)
+
+	resp := runChargebackDeadlines(t, h, "")
+	assert.Equal(t, 1, resp.MarkedLost)
+	assert.Equal(t, "lost", store.chargebacks[0].Status)
+	assert.True(t, store.chargebacks[0].ResolvedAt.Valid)
+	assert.Equal(t, "under_review", store.chargebacks[1].Status, "answered chargebacks wait for the issuer")
+	assert.Equal(t, "won", store.chargebacks[2].Status)
+	assert.Equal(t, "new", store.chargebacks[3].Status, "the deadline day itself isn't over yet")
+
+	require.Len(t, events.events, 1)
+	assert.Equal(t, webhook.EventChargebackUpdated, events.events[0].EventType)
+	assert.Equal(t, "CASE-OVERDUE", events.events[0].Data["case_number"])
+	assert.Equal(t, "lost", events.events[0].Data["status"])
+
+	resp = runChargebackDeadlines(t, h, "")
+	assert.Zero(t, resp.MarkedLost)
+}
+
+func TestProcessDeadlines_Validation(t *testing.T) {
+	h := newTestDeadlineHandler(&fakeDeadlineStore{}, &recordingPublisher{})
+
+	req := httptest.NewRequest(http.MethodPost, "/cron/chargeback-deadlines", strings.NewReader(`{"within_days": 61}`))
+	req.Header.Set("X-Cron-Secret", "secret")
+	rec := httptest.NewRecorder()
+	h.ProcessDeadlines(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/cron/chargeback-deadlines", nil)
+	rec = httptest.NewRecorder()
+	h.ProcessDeadlines(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
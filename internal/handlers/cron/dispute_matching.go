@@ -0,0 +1,108 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+)
+
+// Transactions created this long before or after North's transaction date are match candidates;
+// North reports the date in the processor's time zone
+const (
+	disputeMatchDaysBefore = 1
+	disputeMatchDaysAfter  = 1
+)
+
+// disputeMatch is the transaction group a dispute was matched to
+type disputeMatch struct {
+	groupID    uuid.UUID
+	customerID pgtype.Text
+}
+
+// groupEvidence is what a group's transactions say about a dispute
+type groupEvidence struct {
+	customerID   pgtype.Text
+	corroborated bool // TRAN_NBR, auth code or card last four agrees
+	conflicts    bool // One of them disagrees
+}
+
+// matchDisputeTransaction finds the transaction group a North dispute was raised against.
+// Candidates are the merchant's card transactions for the disputed amount around North's transaction
+// date. A group is ruled out when its TRAN_NBR, auth code or card last four disagrees with the dispute.
+// The dispute matches only when exactly one group is left and at least one of those agrees;
+// otherwise it returns nil and the chargeback is left for manual review.
+func (h *DisputeSyncHandler) matchDisputeTransaction(ctx context.Context, agentID string, dispute *adapterports.Dispute) (*disputeMatch, error) {
+	txDate, err := time.Parse("2006-01-02", dispute.TransactionDate)
+	if err != nil {
+		return nil, nil
+	}
+
+	amount := dispute.TransactionAmount
+	if amount <= 0 {
+		amount = dispute.ChargebackAmount
+	}
+
+	rows, err := h.chargebacks.ListDisputeMatchCandidates(ctx, sqlc.ListDisputeMatchCandidatesParams{
+		AgentID:     agentID,
+		Amount:      fmt.Sprintf("%.2f", amount),
+		CreatedFrom: txDate.AddDate(0, 0, -disputeMatchDaysBefore),
+		CreatedTo:   txDate.AddDate(0, 0, 1+disputeMatchDaysAfter),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list match candidates: %w", err)
+	}
+
+	groups := make(map[uuid.UUID]*groupEvidence)
+	for i := range rows {
+		row := &rows[i]
+		evidence, ok := groups[row.GroupID]
+		if !ok {
+			evidence = &groupEvidence{}
+			groups[row.GroupID] = evidence
+		}
+		if row.CustomerID.Valid {
+			evidence.customerID = row.CustomerID
+		}
+
+		if dispute.TransactionNumber != "" && row.TranNbr.Valid {
+			evidence.record(strconv.FormatInt(row.TranNbr.Int64, 10) == dispute.TransactionNumber)
+		}
+		if dispute.AuthCode != "" && row.AuthCode.Valid && row.AuthCode.String != "" {
+			evidence.record(row.AuthCode.String == dispute.AuthCode)
+		}
+		if dispute.CardNumberLastFour != "" && row.LastFour.Valid {
+			evidence.record(row.LastFour.String == dispute.CardNumberLastFour)
+		}
+	}
+
+	var match *disputeMatch
+	remaining := 0
+	for groupID, evidence := range groups {
+		if evidence.conflicts {
+			continue
+		}
+		remaining++
+		if evidence.corroborated {
+			match = &disputeMatch{groupID: groupID, customerID: evidence.customerID}
+		}
+	}
+	if remaining != 1 {
+		return nil, nil
+	}
+	return match, nil
+}
+
+func (e *groupEvidence) record(agrees bool) {
+	if agrees {
+		e.corroborated = true
+	} else {
+		e.conflicts = true
+	}
+}
@@ -3,23 +3,34 @@ package cron
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/kevin07696/payment-service/internal/adapters/database"
 	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
 	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
 	"github.com/kevin07696/payment-service/internal/services/webhook"
 	"go.uber.org/zap"
 )
 
+// ChargebackQueryExecutor defines the chargeback queries used by dispute sync
+type ChargebackQueryExecutor interface {
+	GetChargebackByCaseNumber(ctx context.Context, arg sqlc.GetChargebackByCaseNumberParams) (sqlc.Chargeback, error)
+	UpsertChargeback(ctx context.Context, arg sqlc.UpsertChargebackParams) (sqlc.Chargeback, error)
+	ListDisputeMatchCandidates(ctx context.Context, arg sqlc.ListDisputeMatchCandidatesParams) ([]sqlc.ListDisputeMatchCandidatesRow, error)
+}
+
 // DisputeSyncHandler handles cron job endpoints for dispute synchronization
 type DisputeSyncHandler struct {
 	merchantReporting adapterports.MerchantReportingAdapter
 	db                *database.PostgreSQLAdapter
+	chargebacks       ChargebackQueryExecutor
 	webhookService    *webhook.WebhookDeliveryService
 	logger            *zap.Logger
 	cronSecret        string
@@ -36,6 +47,7 @@ func NewDisputeSyncHandler(
 	return &DisputeSyncHandler{
 		merchantReporting: merchantReporting,
 		db:                db,
+		chargebacks:       db.Queries(),
 		webhookService:    webhookService,
 		logger:            logger,
 		cronSecret:        cronSecret,
@@ -207,7 +219,7 @@ func (h *DisputeSyncHandler) syncAgentDisputes(ctx context.Context, agent *sqlc.
 
 	// Process each dispute
 	for _, dispute := range searchResp.Disputes {
-		isNew, err := h.upsertChargeback(ctx, agent.AgentID, dispute)
+		outcome, err := h.upsertChargeback(ctx, agent.AgentID, dispute)
 		if err != nil {
 			h.logger.Error("Failed to upsert chargeback",
 				zap.String("case_number", dispute.CaseNumber),
@@ -217,9 +229,10 @@ func (h *DisputeSyncHandler) syncAgentDisputes(ctx context.Context, agent *sqlc.
 			continue
 		}
 
-		if isNew {
+		switch outcome {
+		case chargebackCreated:
 			newCount++
-		} else {
+		case chargebackUpdated:
 			updatedCount++
 		}
 	}
@@ -227,60 +240,121 @@ func (h *DisputeSyncHandler) syncAgentDisputes(ctx context.Context, agent *sqlc.
 	return newCount, updatedCount, nil
 }
 
-// upsertChargeback inserts or updates a chargeback record
-func (h *DisputeSyncHandler) upsertChargeback(ctx context.Context, agentID string, dispute *adapterports.Dispute) (isNew bool, err error) {
-	// Check if chargeback already exists
-	existing, err := h.db.Queries().GetChargebackByCaseNumber(ctx, sqlc.GetChargebackByCaseNumberParams{
+// chargebackOutcome is the result of ingesting a single dispute
+type chargebackOutcome int
+
+const (
+	chargebackCreated chargebackOutcome = iota
+	chargebackUpdated
+	chargebackUnchanged
+)
+
+// upsertChargeback ingests a dispute idempotently, keyed on (agent_id, case_number).
+// Re-syncing a case updates status/amount on the existing row instead of inserting a duplicate.
+func (h *DisputeSyncHandler) upsertChargeback(ctx context.Context, agentID string, dispute *adapterports.Dispute) (chargebackOutcome, error) {
+	existing, err := h.chargebacks.GetChargebackByCaseNumber(ctx, sqlc.GetChargebackByCaseNumberParams{
 		AgentID:    agentID,
 		CaseNumber: dispute.CaseNumber,
 	})
+	found := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return chargebackUnchanged, fmt.Errorf("failed to get chargeback by case number: %w", err)
+	}
+
+	params := buildChargebackUpsertParams(agentID, dispute, h.logger)
+	if found && keepLocalStatus(existing.Status, params.Status) {
+		params.Status = existing.Status
+	}
+	if found && existing.GroupID.Valid {
+		params.GroupID, params.CustomerID, params.MatchStatus = existing.GroupID, existing.CustomerID, existing.MatchStatus
+	} else {
+		h.applyTransactionMatch(ctx, agentID, dispute, &params)
+	}
+
+	// Nothing changed since the last sync - skip the write and the webhook
+	if found && !chargebackChanged(&existing, &params) {
+		return chargebackUnchanged, nil
+	}
 
+	// The upsert also covers a concurrent sync inserting the case between the read and the write
+	chargeback, err := h.chargebacks.UpsertChargeback(ctx, params)
 	if err != nil {
-		// Chargeback doesn't exist - create new one
-		return true, h.createChargeback(ctx, agentID, dispute)
+		return chargebackUnchanged, fmt.Errorf("failed to upsert chargeback: %w", err)
 	}
 
-	// Chargeback exists - update it
-	return false, h.updateChargeback(ctx, &existing, dispute)
+	outcome, eventType := chargebackUpdated, webhook.EventChargebackUpdated
+	if chargeback.CreatedAt.Equal(chargeback.UpdatedAt) {
+		outcome, eventType = chargebackCreated, webhook.EventChargebackCreated
+	}
+
+	if h.webhookService != nil {
+		h.triggerChargebackWebhook(ctx, agentID, eventType, &chargeback)
+	}
+
+	return outcome, nil
+}
+
+// applyTransactionMatch links the chargeback to the dispute's transaction group, or flags it unmatched.
+// A failed lookup leaves match_status unset so the next sync tries again.
+func (h *DisputeSyncHandler) applyTransactionMatch(ctx context.Context, agentID string, dispute *adapterports.Dispute, params *sqlc.UpsertChargebackParams) {
+	match, err := h.matchDisputeTransaction(ctx, agentID, dispute)
+	if err != nil {
+		h.logger.Warn("Failed to match dispute to a transaction",
+			zap.String("agent_id", agentID),
+			zap.String("case_number", dispute.CaseNumber),
+			zap.Error(err),
+		)
+		return
+	}
+	if match == nil {
+		params.MatchStatus = pgtype.Text{String: string(domain.ChargebackUnmatched), Valid: true}
+		return
+	}
+	params.GroupID = pgtype.UUID{Bytes: match.groupID, Valid: true}
+	params.CustomerID = match.customerID
+	params.MatchStatus = pgtype.Text{String: string(domain.ChargebackMatched), Valid: true}
 }
 
-// createChargeback creates a new chargeback record and triggers webhook
-func (h *DisputeSyncHandler) createChargeback(ctx context.Context, agentID string, dispute *adapterports.Dispute) error {
+// buildChargebackUpsertParams maps a North dispute to upsert parameters
+func buildChargebackUpsertParams(agentID string, dispute *adapterports.Dispute, logger *zap.Logger) sqlc.UpsertChargebackParams {
 	// Parse dates
 	disputeDate, err := time.Parse("2006-01-02", dispute.DisputeDate)
 	if err != nil {
-		h.logger.Warn("Failed to parse dispute date", zap.String("date", dispute.DisputeDate))
+		logger.Warn("Failed to parse dispute date", zap.String("date", dispute.DisputeDate))
 		disputeDate = time.Now()
 	}
 
 	chargebackDate, err := time.Parse("2006-01-02", dispute.ChargebackDate)
 	if err != nil {
-		h.logger.Warn("Failed to parse chargeback date", zap.String("date", dispute.ChargebackDate))
+		logger.Warn("Failed to parse chargeback date", zap.String("date", dispute.ChargebackDate))
 		chargebackDate = time.Now()
 	}
 
-	// Find matching transaction by transaction number
-	var groupID pgtype.UUID
-	if dispute.TransactionNumber != "" {
-		// Try to find the transaction group
-		// This would require a query to find transaction by auth response or other identifiers
-		// For now, we'll leave it NULL and allow manual linking later
-		groupID = pgtype.UUID{Valid: false}
+	// Response deadline, when North gives one; without it no reminder or auto-loss applies
+	respondBy := pgtype.Date{Valid: false}
+	if dispute.ResponseDueDate != "" {
+		if dueDate, err := time.Parse("2006-01-02", dispute.ResponseDueDate); err == nil {
+			respondBy = pgtype.Date{Time: dueDate, Valid: true}
+		} else {
+			logger.Warn("Failed to parse response due date", zap.String("date", dispute.ResponseDueDate))
+		}
 	}
 
+	// Transaction group is filled in by applyTransactionMatch
+	groupID := pgtype.UUID{Valid: false}
+
 	// Marshal dispute as raw_data
 	rawData, err := json.Marshal(dispute)
 	if err != nil {
-		h.logger.Warn("Failed to marshal dispute data", zap.Error(err))
+		logger.Warn("Failed to marshal dispute data", zap.Error(err))
 		rawData = []byte("{}")
 	}
 
-	chargebackID := uuid.New()
-	params := sqlc.CreateChargebackParams{
-		ID:                chargebackID,
+	return sqlc.UpsertChargebackParams{
+		ID:                uuid.New(),
 		GroupID:           groupID,
 		AgentID:           agentID,
-		CustomerID:        pgtype.Text{Valid: false}, // Not available from North API
+		CustomerID:        pgtype.Text{Valid: false}, // Taken from the matched transaction
 		CaseNumber:        dispute.CaseNumber,
 		DisputeDate:       disputeDate,
 		ChargebackDate:    chargebackDate,
@@ -289,53 +363,35 @@ func (h *DisputeSyncHandler) createChargeback(ctx context.Context, agentID strin
 		ReasonCode:        dispute.ReasonCode,
 		ReasonDescription: pgtype.Text{String: dispute.ReasonDescription, Valid: dispute.ReasonDescription != ""},
 		Status:            mapDisputeStatus(dispute.Status),
-		RespondByDate:     pgtype.Date{Valid: false}, // Calculate from chargeback_date + grace period if needed
-		EvidenceFiles:     []string{},                // Empty array for new chargebacks
+		RespondByDate:     respondBy,
+		EvidenceFiles:     []string{}, // Empty array for new chargebacks
 		ResponseNotes:     pgtype.Text{Valid: false},
 		InternalNotes:     pgtype.Text{Valid: false},
 		RawData:           rawData,
 	}
-
-	chargeback, err := h.db.Queries().CreateChargeback(ctx, params)
-	if err != nil {
-		return err
-	}
-
-	// Trigger webhook notification for new chargeback
-	if h.webhookService != nil {
-		h.triggerChargebackWebhook(ctx, agentID, "chargeback.created", &chargeback)
-	}
-
-	return nil
 }
 
-// updateChargeback updates an existing chargeback record and triggers webhook
-func (h *DisputeSyncHandler) updateChargeback(ctx context.Context, existing *sqlc.Chargeback, dispute *adapterports.Dispute) error {
-	// Parse dates
-	disputeDate, _ := time.Parse("2006-01-02", dispute.DisputeDate)
-	chargebackDate, _ := time.Parse("2006-01-02", dispute.ChargebackDate)
-
-	params := sqlc.UpdateChargebackStatusParams{
-		ID:                existing.ID,
-		Status:            mapDisputeStatus(dispute.Status),
-		DisputeDate:       disputeDate,
-		ChargebackDate:    chargebackDate,
-		ChargebackAmount:  fmt.Sprintf("%.2f", dispute.ChargebackAmount),
-		ReasonCode:        dispute.ReasonCode,
-		ReasonDescription: pgtype.Text{String: dispute.ReasonDescription, Valid: dispute.ReasonDescription != ""},
-	}
-
-	chargeback, err := h.db.Queries().UpdateChargebackStatus(ctx, params)
-	if err != nil {
-		return err
-	}
+// chargebackChanged reports whether a re-synced dispute differs from the stored chargeback
+func chargebackChanged(existing *sqlc.Chargeback, params *sqlc.UpsertChargebackParams) bool {
+	return existing.Status != params.Status ||
+		existing.ChargebackAmount != params.ChargebackAmount ||
+		existing.ReasonCode != params.ReasonCode ||
+		existing.ReasonDescription != params.ReasonDescription ||
+		!existing.DisputeDate.Equal(params.DisputeDate) ||
+		!existing.ChargebackDate.Equal(params.ChargebackDate) ||
+		existing.GroupID != params.GroupID ||
+		existing.MatchStatus != params.MatchStatus ||
+		(params.RespondByDate.Valid && !existing.RespondByDate.Time.Equal(params.RespondByDate.Time))
+}
 
-	// Trigger webhook notification for updated chargeback
-	if h.webhookService != nil {
-		h.triggerChargebackWebhook(ctx, existing.AgentID, "chargeback.updated", &chargeback)
+// keepLocalStatus reports whether a chargeback keeps its stored status over the one North reports.
+// North can lag behind an auto-loss past the deadline, so an open status from North doesn't reopen
+// a chargeback that has been lost.
+func keepLocalStatus(stored, synced string) bool {
+	if synced != string(domain.ChargebackStatusNew) && synced != string(domain.ChargebackStatusPending) {
+		return false
 	}
-
-	return nil
+	return stored == string(domain.ChargebackStatusLost)
 }
 
 // mapDisputeStatus maps North API status to our domain status
@@ -347,6 +403,8 @@ func mapDisputeStatus(northStatus string) string {
 		return "pending"
 	case "RESPONDED":
 		return "responded"
+	case "UNDER_REVIEW":
+		return "under_review"
 	case "WON":
 		return "won"
 	case "LOST":
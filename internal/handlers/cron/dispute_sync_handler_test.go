@@ -0,0 +1,368 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+)
+
+type chargebackKey struct {
+	agentID    string
+	caseNumber string
+}
+
+// fakeChargebackStore mimics the (agent_id, case_number) unique upsert in memory
+type fakeChargebackStore struct {
+	rows       map[chargebackKey]sqlc.Chargeback
+	candidates []matchCandidate
+	inserts    int
+	updates    int
+	now        time.Time
+}
+
+func newFakeChargebackStore() *fakeChargebackStore {
+	return &fakeChargebackStore{
+		rows: make(map[chargebackKey]sqlc.Chargeback),
+		now:  time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func (f *fakeChargebackStore) GetChargebackByCaseNumber(ctx context.Context, arg sqlc.GetChargebackByCaseNumberParams) (sqlc.Chargeback, error) {
+	row, ok := f.rows[chargebackKey{arg.AgentID, arg.CaseNumber}]
+	if !ok {
+		return sqlc.Chargeback{}, pgx.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeChargebackStore) UpsertChargeback(ctx context.Context, arg sqlc.UpsertChargebackParams) (sqlc.Chargeback, error) {
+	f.now = f.now.Add(time.Minute)
+	key := chargebackKey{arg.AgentID, arg.CaseNumber}
+
+	row, exists := f.rows[key]
+	if !exists {
+		f.inserts++
+		row = sqlc.Chargeback{
+			ID:            arg.ID,
+			AgentID:       arg.AgentID,
+			CaseNumber:    arg.CaseNumber,
+			Currency:      arg.Currency,
+			EvidenceFiles: arg.EvidenceFiles,
+			CreatedAt:     f.now,
+		}
+	} else {
+		f.updates++
+	}
+
+	row.Status = arg.Status
+	row.DisputeDate = arg.DisputeDate
+	row.ChargebackDate = arg.ChargebackDate
+	row.ChargebackAmount = arg.ChargebackAmount
+	row.ReasonCode = arg.ReasonCode
+	row.ReasonDescription = arg.ReasonDescription
+	if !row.GroupID.Valid {
+		row.GroupID = arg.GroupID
+		row.MatchStatus = arg.MatchStatus
+	}
+	if !row.CustomerID.Valid {
+		row.CustomerID = arg.CustomerID
+	}
+	if arg.RespondByDate.Valid {
+		row.RespondByDate = arg.RespondByDate
+	}
+	row.RawData = arg.RawData
+	row.UpdatedAt = f.now
+
+	f.rows[key] = row
+	return row, nil
+}
+
+// matchCandidate is a local transaction with the amount the candidate query filters on
+type matchCandidate struct {
+	agentID string
+	amount  string
+	row     sqlc.ListDisputeMatchCandidatesRow
+}
+
+func (f *fakeChargebackStore) ListDisputeMatchCandidates(ctx context.Context, arg sqlc.ListDisputeMatchCandidatesParams) ([]sqlc.ListDisputeMatchCandidatesRow, error) {
+	var rows []sqlc.ListDisputeMatchCandidatesRow
+	for _, c := range f.candidates {
+		if c.agentID != arg.AgentID || c.amount != arg.Amount {
+			continue
+		}
+		if c.row.CreatedAt.Before(arg.CreatedFrom) || !c.row.CreatedAt.Before(arg.CreatedTo) {
+			continue
+		}
+		rows = append(rows, c.row)
+	}
+	return rows, nil
+}
+
+func newTestDispute(status string, amount float64) *adapterports.Dispute {
+	return &adapterports.Dispute{
+		CaseNumber:        "CASE-12345",
+		DisputeDate:       "2025-02-10",
+		ChargebackDate:    "2025-02-20",
+		ChargebackAmount:  amount,
+		ReasonCode:        "P22",
+		ReasonDescription: "Non-Matching Card Number",
+		Status:            status,
+	}
+}
+
+func newTestDisputeSyncHandler(store ChargebackQueryExecutor) *DisputeSyncHandler {
+	return &DisputeSyncHandler{
+		chargebacks: store,
+		logger:      zap.NewNop(),
+	}
+}
+
+func TestUpsertChargeback_FirstSyncInserts(t *testing.T) {
+	store := newFakeChargebackStore()
+	handler := newTestDisputeSyncHandler(store)
+
+	outcome, err := handler.upsertChargeback(context.Background(), "test-agent-123", newTestDispute("NEW", 125.50))
+
+	require.NoError(t, err)
+	assert.Equal(t, chargebackCreated, outcome)
+	assert.Equal(t, 1, store.inserts)
+	require.Len(t, store.rows, 1)
+
+	row := store.rows[chargebackKey{"test-agent-123", "CASE-12345"}]
+	assert.Equal(t, "new", row.Status)
+	assert.Equal(t, "125.50", row.ChargebackAmount)
+}
+
+func TestUpsertChargeback_ResyncUpdatesSameRow(t *testing.T) {
+	store := newFakeChargebackStore()
+	handler := newTestDisputeSyncHandler(store)
+	ctx := context.Background()
+
+	_, err := handler.upsertChargeback(ctx, "test-agent-123", newTestDispute("NEW", 125.50))
+	require.NoError(t, err)
+	original := store.rows[chargebackKey{"test-agent-123", "CASE-12345"}]
+
+	outcome, err := handler.upsertChargeback(ctx, "test-agent-123", newTestDispute("PENDING", 100.00))
+
+	require.NoError(t, err)
+	assert.Equal(t, chargebackUpdated, outcome)
+	assert.Equal(t, 1, store.inserts)
+	assert.Equal(t, 1, store.updates)
+	require.Len(t, store.rows, 1, "re-sync must not create a duplicate row")
+
+	row := store.rows[chargebackKey{"test-agent-123", "CASE-12345"}]
+	assert.Equal(t, original.ID, row.ID)
+	assert.Equal(t, "pending", row.Status)
+	assert.Equal(t, "100.00", row.ChargebackAmount)
+}
+
+func TestUpsertChargeback_UnchangedResyncSkipsWrite(t *testing.T) {
+	store := newFakeChargebackStore()
+	handler := newTestDisputeSyncHandler(store)
+	ctx := context.Background()
+
+	_, err := handler.upsertChargeback(ctx, "test-agent-123", newTestDispute("NEW", 125.50))
+	require.NoError(t, err)
+
+	outcome, err := handler.upsertChargeback(ctx, "test-agent-123", newTestDispute("NEW", 125.50))
+
+	require.NoError(t, err)
+	assert.Equal(t, chargebackUnchanged, outcome)
+	assert.Equal(t, 1, store.inserts)
+	assert.Equal(t, 0, store.updates)
+	assert.Len(t, store.rows, 1)
+}
+
+func TestUpsertChargeback_SameCaseNumberDifferentAgents(t *testing.T) {
+	store := newFakeChargebackStore()
+	handler := newTestDisputeSyncHandler(store)
+	ctx := context.Background()
+
+	_, err := handler.upsertChargeback(ctx, "agent-a", newTestDispute("NEW", 50))
+	require.NoError(t, err)
+	outcome, err := handler.upsertChargeback(ctx, "agent-b", newTestDispute("NEW", 50))
+
+	require.NoError(t, err)
+	assert.Equal(t, chargebackCreated, outcome)
+	assert.Len(t, store.rows, 2)
+}
+
+func TestUpsertChargeback_ResponseDueDate(t *testing.T) {
+	store := newFakeChargebackStore()
+	handler := newTestDisputeSyncHandler(store)
+	ctx := context.Background()
+	key := chargebackKey{"test-agent-123", "CASE-12345"}
+
+	dispute := newTestDispute("NEW", 125.50)
+	dispute.ResponseDueDate = "2025-03-15"
+	_, err := handler.upsertChargeback(ctx, "test-agent-123", dispute)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), store.rows[key].RespondByDate.Time)
+
+	dispute.ResponseDueDate = "2025-03-20"
+	outcome, err := handler.upsertChargeback(ctx, "test-agent-123", dispute)
+	require.NoError(t, err)
+	assert.Equal(t, chargebackUpdated, outcome, "a moved deadline is a change")
+	assert.Equal(t, 20, store.rows[key].RespondByDate.Time.Day())
+
+	dispute.ResponseDueDate = ""
+	outcome, err = handler.upsertChargeback(ctx, "test-agent-123", dispute)
+	require.NoError(t, err)
+	assert.Equal(t, chargebackUnchanged, outcome, "a missing date keeps the stored one")
+}
+
+func TestUpsertChargeback_OpenStatusDoesNotReopen(t *testing.T) {
+	store := newFakeChargebackStore()
+	handler := newTestDisputeSyncHandler(store)
+	ctx := context.Background()
+	key := chargebackKey{"test-agent-123", "CASE-12345"}
+
+	_, err := handler.upsertChargeback(ctx, "test-agent-123", newTestDispute("NEW", 125.50))
+	require.NoError(t, err)
+
+	// Auto-lost after a missed deadline while North still reports the case as pending
+	row := store.rows[key]
+	row.Status = "lost"
+	store.rows[key] = row
+
+	outcome, err := handler.upsertChargeback(ctx, "test-agent-123", newTestDispute("PENDING", 125.50))
+	require.NoError(t, err)
+	assert.Equal(t, chargebackUnchanged, outcome)
+	assert.Equal(t, "lost", store.rows[key].Status)
+
+	_, err = handler.upsertChargeback(ctx, "test-agent-123", newTestDispute("WON", 125.50))
+	require.NoError(t, err)
+	assert.Equal(t, "won", store.rows[key].Status, "a final outcome from North still applies")
+}
+
+func newMatchableDispute() *adapterports.Dispute {
+	dispute := newTestDispute("NEW", 125.50)
+	dispute.TransactionAmount = 125.50
+	dispute.TransactionDate = "2025-02-01"
+	dispute.TransactionNumber = "4100"
+	dispute.AuthCode = "A1B2C3"
+	dispute.CardNumberLastFour = "4242"
+	return dispute
+}
+
+func newMatchCandidate(groupID uuid.UUID, tranNbr int64, authCode, lastFour string, createdAt time.Time) matchCandidate {
+	return matchCandidate{
+		agentID: "test-agent-123",
+		amount:  "125.50",
+		row: sqlc.ListDisputeMatchCandidatesRow{
+			ID:         uuid.New(),
+			GroupID:    groupID,
+			CustomerID: pgtype.Text{String: "customer-1", Valid: true},
+			Type:       "charge",
+			AuthCode:   pgtype.Text{String: authCode, Valid: authCode != ""},
+			TranNbr:    pgtype.Int8{Int64: tranNbr, Valid: tranNbr != 0},
+			LastFour:   pgtype.Text{String: lastFour, Valid: lastFour != ""},
+			CreatedAt:  createdAt,
+		},
+	}
+}
+
+func TestUpsertChargeback_MatchesTransactionGroup(t *testing.T) {
+	store := newFakeChargebackStore()
+	handler := newTestDisputeSyncHandler(store)
+	ctx := context.Background()
+	key := chargebackKey{"test-agent-123", "CASE-12345"}
+
+	disputed := uuid.New()
+	txTime := time.Date(2025, 2, 1, 23, 30, 0, 0, time.UTC)
+	store.candidates = []matchCandidate{
+		newMatchCandidate(disputed, 4100, "A1B2C3", "4242", txTime),
+		// Same amount and day, but another card and authorization
+		newMatchCandidate(uuid.New(), 4101, "Z9Y8X7", "1111", txTime.Add(-time.Hour)),
+		// Same card and amount, outside the date window
+		newMatchCandidate(uuid.New(), 3900, "A1B2C3", "4242", txTime.AddDate(0, 0, -5)),
+	}
+
+	_, err := handler.upsertChargeback(ctx, "test-agent-123", newMatchableDispute())
+	require.NoError(t, err)
+
+	row := store.rows[key]
+	assert.Equal(t, pgtype.UUID{Bytes: disputed, Valid: true}, row.GroupID)
+	assert.Equal(t, "customer-1", row.CustomerID.String)
+	assert.Equal(t, "matched", row.MatchStatus.String)
+
+	// A re-sync keeps the link without looking again
+	store.candidates = nil
+	outcome, err := handler.upsertChargeback(ctx, "test-agent-123", newMatchableDispute())
+	require.NoError(t, err)
+	assert.Equal(t, chargebackUnchanged, outcome)
+	assert.Equal(t, disputed, uuid.UUID(store.rows[key].GroupID.Bytes))
+}
+
+func TestUpsertChargeback_AmbiguousMatchIsUnmatched(t *testing.T) {
+	store := newFakeChargebackStore()
+	handler := newTestDisputeSyncHandler(store)
+	key := chargebackKey{"test-agent-123", "CASE-12345"}
+
+	// Two charges on the same card for the same amount; North gives no TRAN_NBR or auth code
+	txTime := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	store.candidates = []matchCandidate{
+		newMatchCandidate(uuid.New(), 0, "", "4242", txTime),
+		newMatchCandidate(uuid.New(), 0, "", "4242", txTime.Add(2*time.Hour)),
+	}
+	dispute := newMatchableDispute()
+	dispute.TransactionNumber = ""
+	dispute.AuthCode = ""
+
+	_, err := handler.upsertChargeback(context.Background(), "test-agent-123", dispute)
+	require.NoError(t, err)
+
+	row := store.rows[key]
+	assert.False(t, row.GroupID.Valid)
+	assert.Equal(t, "unmatched", row.MatchStatus.String)
+}
+
+func TestUpsertChargeback_UncorroboratedCandidateIsUnmatched(t *testing.T) {
+	store := newFakeChargebackStore()
+	handler := newTestDisputeSyncHandler(store)
+	key := chargebackKey{"test-agent-123", "CASE-12345"}
+
+	// Only amount and date agree: the one candidate has nothing to compare
+	store.candidates = []matchCandidate{
+		newMatchCandidate(uuid.New(), 0, "", "", time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)),
+	}
+
+	_, err := handler.upsertChargeback(context.Background(), "test-agent-123", newMatchableDispute())
+	require.NoError(t, err)
+	assert.Equal(t, "unmatched", store.rows[key].MatchStatus.String)
+}
+
+// staleReadStore misses every lookup, as two overlapping syncs both reading before either writes
+type staleReadStore struct {
+	*fakeChargebackStore
+}
+
+func (s staleReadStore) GetChargebackByCaseNumber(ctx context.Context, arg sqlc.GetChargebackByCaseNumberParams) (sqlc.Chargeback, error) {
+	return sqlc.Chargeback{}, pgx.ErrNoRows
+}
+
+func TestUpsertChargeback_OverlappingSyncsKeepOneRow(t *testing.T) {
+	store := newFakeChargebackStore()
+	first := newTestDisputeSyncHandler(staleReadStore{store})
+	second := newTestDisputeSyncHandler(staleReadStore{store})
+	ctx := context.Background()
+
+	_, err := first.upsertChargeback(ctx, "test-agent-123", newTestDispute("NEW", 125.50))
+	require.NoError(t, err)
+	outcome, err := second.upsertChargeback(ctx, "test-agent-123", newTestDispute("PENDING", 125.50))
+	require.NoError(t, err)
+
+	assert.Equal(t, chargebackUpdated, outcome, "the conflicting insert updates the existing row")
+	require.Len(t, store.rows, 1)
+	assert.Equal(t, "pending", store.rows[chargebackKey{"test-agent-123", "CASE-12345"}].Status)
+}
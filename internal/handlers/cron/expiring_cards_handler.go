@@ -0,0 +1,246 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/adapters/database"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/webhook"
+)
+
+// Expiring-card notice window (days ahead of today)
+const (
+	defaultExpiringCardWindowDays = 30
+	maxExpiringCardWindowDays     = 365
+)
+
+// ExpiringCardsQueryExecutor defines the queries used by the expiring-card notifier
+type ExpiringCardsQueryExecutor interface {
+	ListExpiringPaymentMethods(ctx context.Context, arg sqlc.ListExpiringPaymentMethodsParams) ([]sqlc.CustomerPaymentMethod, error)
+	MarkPaymentMethodExpiryNotified(ctx context.Context, id uuid.UUID) error
+}
+
+// EventPublisher delivers webhook events to the merchant's active subscriptions
+type EventPublisher interface {
+	DeliverEvent(ctx context.Context, event *webhook.WebhookEvent) error
+}
+
+// ExpiringCardsHandler handles the cron endpoint that warns merchants about saved cards about to expire
+type ExpiringCardsHandler struct {
+	queries    ExpiringCardsQueryExecutor
+	events     EventPublisher
+	logger     *zap.Logger
+	cronSecret string
+}
+
+// NewExpiringCardsHandler creates a new expiring-card cron handler
+func NewExpiringCardsHandler(
+	db *database.PostgreSQLAdapter,
+	events EventPublisher,
+	logger *zap.Logger,
+	cronSecret string,
+) *ExpiringCardsHandler {
+	return NewExpiringCardsHandlerWithQueries(db.Queries(), events, logger, cronSecret)
+}
+
+// NewExpiringCardsHandlerWithQueries creates an expiring-card handler with a custom query executor
+func NewExpiringCardsHandlerWithQueries(
+	queries ExpiringCardsQueryExecutor,
+	events EventPublisher,
+	logger *zap.Logger,
+	cronSecret string,
+) *ExpiringCardsHandler {
+	return &ExpiringCardsHandler{
+		queries:    queries,
+		events:     events,
+		logger:     logger,
+		cronSecret: cronSecret,
+	}
+}
+
+// ExpiringCardsRequest represents the request body for an expiring-card run
+type ExpiringCardsRequest struct {
+	AgentID    *string `json:"agent_id"`    // Optional: only this agent's cards, otherwise all agents
+	WithinDays *int    `json:"within_days"` // Optional: defaults to 30
+}
+
+// ExpiringCardsResponse represents the response from an expiring-card run
+type ExpiringCardsResponse struct {
+	Success     bool     `json:"success"`
+	WithinDays  int      `json:"within_days"`
+	Found       int      `json:"found"`    // Cards expiring in the window that hadn't been announced yet
+	Notified    int      `json:"notified"` // payment_method.expiring webhooks sent
+	Errors      []string `json:"errors,omitempty"`
+	ProcessedAt string   `json:"processed_at"`
+}
+
+// NotifyExpiringCards handles the POST /cron/expiring-cards endpoint
+// This endpoint is called by Cloud Scheduler (e.g. daily). Each card expiry is announced once;
+// updating the card's expiry date re-arms the notice.
+func (h *ExpiringCardsHandler) NotifyExpiringCards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, http.StatusMethodNotAllowed, "only POST method is allowed")
+		return
+	}
+
+	if !h.authenticateRequest(r) {
+		h.logger.Warn("Unauthorized cron request",
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		h.respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req ExpiringCardsRequest
+	if r.Body != nil && r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+	}
+
+	withinDays := defaultExpiringCardWindowDays
+	if req.WithinDays != nil {
+		withinDays = *req.WithinDays
+	}
+	if withinDays < 1 || withinDays > maxExpiringCardWindowDays {
+		h.respondError(w, http.StatusBadRequest, fmt.Sprintf("within_days must be between 1 and %d", maxExpiringCardWindowDays))
+		return
+	}
+
+	ctx := r.Context()
+	now := time.Now()
+
+	params := sqlc.ListExpiringPaymentMethodsParams{
+		UnnotifiedOnly: true,
+		AsOf:           pgtype.Date{Time: now, Valid: true},
+		Cutoff:         pgtype.Date{Time: now.AddDate(0, 0, withinDays), Valid: true},
+	}
+	if req.AgentID != nil {
+		params.AgentID = pgtype.Text{String: *req.AgentID, Valid: true}
+	}
+
+	cards, err := h.queries.ListExpiringPaymentMethods(ctx, params)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, fmt.Sprintf("failed to list expiring cards: %v", err))
+		return
+	}
+
+	resp := ExpiringCardsResponse{
+		Success:     true,
+		WithinDays:  withinDays,
+		Found:       len(cards),
+		ProcessedAt: now.Format(time.RFC3339),
+	}
+
+	for i := range cards {
+		card := &cards[i]
+		if err := h.notify(ctx, card, now); err != nil {
+			resp.Success = false
+			resp.Errors = append(resp.Errors, fmt.Sprintf("payment method %s: %v", card.ID, err))
+			h.logger.Error("Failed to send expiring card notice",
+				zap.String("payment_method_id", card.ID.String()),
+				zap.String("agent_id", card.AgentID),
+				zap.Error(err),
+			)
+			continue
+		}
+		resp.Notified++
+	}
+
+	h.logger.Info("Expiring card notices sent",
+		zap.Int("within_days", withinDays),
+		zap.Int("found", resp.Found),
+		zap.Int("notified", resp.Notified),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Success {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusPartialContent)
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// notify delivers the payment_method.expiring webhook and records the notice.
+// Delivery is synchronous so a card is only marked notified once its event is queued.
+func (h *ExpiringCardsHandler) notify(ctx context.Context, card *sqlc.CustomerPaymentMethod, now time.Time) error {
+	if err := h.events.DeliverEvent(ctx, expiringCardEvent(card, now)); err != nil {
+		return fmt.Errorf("deliver webhook: %w", err)
+	}
+	if err := h.queries.MarkPaymentMethodExpiryNotified(ctx, card.ID); err != nil {
+		return fmt.Errorf("mark notified: %w", err)
+	}
+	return nil
+}
+
+// expiringCardEvent builds the payment_method.expiring webhook for a saved card
+func expiringCardEvent(card *sqlc.CustomerPaymentMethod, now time.Time) *webhook.WebhookEvent {
+	month, year := int(card.CardExpMonth.Int32), int(card.CardExpYear.Int32)
+	pm := &domain.PaymentMethod{
+		PaymentType:  domain.PaymentMethodTypeCreditCard,
+		CardExpMonth: &month,
+		CardExpYear:  &year,
+	}
+
+	data := map[string]interface{}{
+		"payment_method_id": card.ID.String(),
+		"customer_id":       card.CustomerID,
+		"last_four":         card.LastFour,
+		"card_exp_month":    month,
+		"card_exp_year":     year,
+		"days_until_expiry": pm.DaysUntilExpiry(now),
+		"is_default":        card.IsDefault.Valid && card.IsDefault.Bool,
+	}
+	if card.CardBrand.Valid {
+		data["card_brand"] = card.CardBrand.String
+	}
+
+	return &webhook.WebhookEvent{
+		EventType: webhook.EventPaymentMethodExpiring,
+		AgentID:   card.AgentID,
+		Data:      data,
+		Timestamp: now,
+	}
+}
+
+// authenticateRequest verifies the cron request is authorized
+func (h *ExpiringCardsHandler) authenticateRequest(r *http.Request) bool {
+	// Check X-Cron-Secret header
+	cronSecret := r.Header.Get("X-Cron-Secret")
+	if cronSecret != "" && cronSecret == h.cronSecret {
+		return true
+	}
+
+	// Check Authorization header (Bearer token)
+	authHeader := r.Header.Get("Authorization")
+	return authHeader == "Bearer "+h.cronSecret
+}
+
+// respondError sends an error response
+func (h *ExpiringCardsHandler) respondError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	resp := map[string]interface{}{
+		"success": false,
+		"error":   message,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode error response", zap.Error(err))
+	}
+}
@@ -0,0 +1,147 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/webhook"
+)
+
+// fakeExpiringCardStore applies the ListExpiringPaymentMethods filter in memory
+type fakeExpiringCardStore struct {
+	cards []sqlc.CustomerPaymentMethod
+}
+
+func (f *fakeExpiringCardStore) ListExpiringPaymentMethods(ctx context.Context, arg sqlc.ListExpiringPaymentMethodsParams) ([]sqlc.CustomerPaymentMethod, error) {
+	withinDays := int(arg.Cutoff.Time.Sub(arg.AsOf.Time).Hours() / 24)
+
+	var result []sqlc.CustomerPaymentMethod
+	for _, card := range f.cards {
+		month, year := int(card.CardExpMonth.Int32), int(card.CardExpYear.Int32)
+		pm := &domain.PaymentMethod{PaymentType: domain.PaymentMethodType(card.PaymentType), CardExpMonth: &month, CardExpYear: &year}
+		if !pm.ExpiresWithin(arg.AsOf.Time, withinDays) {
+			continue
+		}
+		if arg.UnnotifiedOnly && card.ExpiryNotifiedAt.Valid {
+			continue
+		}
+		if arg.AgentID.Valid && card.AgentID != arg.AgentID.String {
+			continue
+		}
+		result = append(result, card)
+	}
+	return result, nil
+}
+
+func (f *fakeExpiringCardStore) MarkPaymentMethodExpiryNotified(ctx context.Context, id uuid.UUID) error {
+	for i := range f.cards {
+		if f.cards[i].ID == id {
+			f.cards[i].ExpiryNotifiedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+// recordingPublisher keeps delivered webhook events
+type recordingPublisher struct {
+	events []*webhook.WebhookEvent
+}
+
+func (p *recordingPublisher) DeliverEvent(ctx context.Context, event *webhook.WebhookEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func newTestCard(expiresAt time.Time) sqlc.CustomerPaymentMethod {
+	return sqlc.CustomerPaymentMethod{
+		ID:           uuid.New(),
+		AgentID:      "agent-1",
+		CustomerID:   "customer-1",
+		PaymentType:  string(domain.PaymentMethodTypeCreditCard),
+		LastFour:     "4242",
+		CardBrand:    pgtype.Text{String: "visa", Valid: true},
+		CardExpMonth: pgtype.Int4{Int32: int32(expiresAt.Month()), Valid: true},
+		CardExpYear:  pgtype.Int4{Int32: int32(expiresAt.Year()), Valid: true},
+		IsActive:     pgtype.Bool{Bool: true, Valid: true},
+	}
+}
+
+func runExpiringCards(t *testing.T, h *ExpiringCardsHandler, body string) ExpiringCardsResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/cron/expiring-cards", strings.NewReader(body))
+	req.Header.Set("X-Cron-Secret", "secret")
+	rec := httptest.NewRecorder()
+	h.NotifyExpiringCards(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp ExpiringCardsResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	return resp
+}
+
+func TestNotifyExpiringCards(t *testing.T) {
+	now := time.Now()
+	thisMonth := newTestCard(now)
+	nextMonth := newTestCard(now.AddDate(0, 1, -now.Day()+1))
+	expired := newTestCard(now.AddDate(0, -1, -now.Day()+1))
+
+	store := &fakeExpiringCardStore{cards: []sqlc.CustomerPaymentMethod{thisMonth, nextMonth, expired}}
+	events := &recordingPublisher{}
+	h := NewExpiringCardsHandlerWithQueries(store, events, zap.NewNop(), "secret")
+
+	// Windows reaching exactly the last valid day of this month, then of next month
+	daysLeft := func(card sqlc.CustomerPaymentMethod) int {
+		month, year := int(card.CardExpMonth.Int32), int(card.CardExpYear.Int32)
+		pm := &domain.PaymentMethod{CardExpMonth: &month, CardExpYear: &year}
+		return max(pm.DaysUntilExpiry(now), 1)
+	}
+	thisMonthWindow := fmt.Sprintf(`{"within_days": %d}`, daysLeft(thisMonth))
+	nextMonthWindow := fmt.Sprintf(`{"within_days": %d}`, daysLeft(nextMonth))
+
+	resp := runExpiringCards(t, h, thisMonthWindow)
+	assert.Equal(t, 1, resp.Found, "only the card expiring this month")
+	assert.Equal(t, 1, resp.Notified)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, webhook.EventPaymentMethodExpiring, events.events[0].EventType)
+	assert.Equal(t, "agent-1", events.events[0].AgentID)
+	assert.Equal(t, thisMonth.ID.String(), events.events[0].Data["payment_method_id"])
+
+	resp = runExpiringCards(t, h, nextMonthWindow)
+	assert.Equal(t, 1, resp.Notified, "next month's card; this month's was already announced")
+	require.Len(t, events.events, 2)
+	assert.Equal(t, nextMonth.ID.String(), events.events[1].Data["payment_method_id"])
+
+	resp = runExpiringCards(t, h, nextMonthWindow)
+	assert.Zero(t, resp.Found, "expired cards and announced cards are never sent")
+	assert.Len(t, events.events, 2)
+}
+
+func TestNotifyExpiringCards_Validation(t *testing.T) {
+	h := NewExpiringCardsHandlerWithQueries(&fakeExpiringCardStore{}, &recordingPublisher{}, zap.NewNop(), "secret")
+
+	req := httptest.NewRequest(http.MethodPost, "/cron/expiring-cards", strings.NewReader(`{"within_days": 0}`))
+	req.Header.Set("X-Cron-Secret", "secret")
+	rec := httptest.NewRecorder()
+	h.NotifyExpiringCards(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/cron/expiring-cards", nil)
+	rec = httptest.NewRecorder()
+	h.NotifyExpiringCards(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
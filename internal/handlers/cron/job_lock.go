@@ -0,0 +1,86 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/pkg/observability"
+)
+
+// JobLocker takes a cluster-wide lock on a cron job
+type JobLocker interface {
+	// TryLockCronJob returns acquired=false without waiting when another instance holds the job's lock
+	TryLockCronJob(ctx context.Context, job string) (unlock func(), acquired bool, err error)
+}
+
+// SkippedRunResponse is the response when a run is skipped because the job is already running
+type SkippedRunResponse struct {
+	Success     bool   `json:"success"`
+	Skipped     bool   `json:"skipped"`
+	Job         string `json:"job"`
+	Reason      string `json:"reason"`
+	ProcessedAt string `json:"processed_at"`
+}
+
+// Exclusive lets only one instance run a cron job at a time. An authorized run that finds the job already
+// running elsewhere is skipped with 200 and "skipped": true, so the scheduler doesn't retry it.
+// Unauthorized requests go straight to next, which rejects them, and never take the lock.
+// If the lock can't be checked, the run fails with 503 rather than risk running twice.
+func Exclusive(locker JobLocker, job, cronSecret string, logger *zap.Logger, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !cronRequestAuthorized(r, cronSecret) {
+			next(w, r)
+			return
+		}
+
+		unlock, acquired, err := locker.TryLockCronJob(r.Context(), job)
+		if err != nil {
+			logger.Error("Failed to take cron job lock",
+				zap.String("job", job),
+				zap.Error(err),
+			)
+			writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+				"success": false,
+				"error":   "failed to take job lock",
+			}, logger)
+			return
+		}
+		if !acquired {
+			logger.Info("Cron job already running on another instance, skipping",
+				zap.String("job", job),
+			)
+			observability.RecordCronSkipped(job)
+			writeJSON(w, http.StatusOK, SkippedRunResponse{
+				Success:     true,
+				Skipped:     true,
+				Job:         job,
+				Reason:      "job already running",
+				ProcessedAt: time.Now().Format(time.RFC3339),
+			}, logger)
+			return
+		}
+		defer unlock()
+
+		next(w, r)
+	}
+}
+
+// cronRequestAuthorized checks the cron secret the same way the cron handlers do
+func cronRequestAuthorized(r *http.Request, cronSecret string) bool {
+	if secret := r.Header.Get("X-Cron-Secret"); secret != "" && secret == cronSecret {
+		return true
+	}
+	return r.Header.Get("Authorization") == "Bearer "+cronSecret
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}, logger *zap.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
@@ -0,0 +1,127 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryLocker behaves like a PostgreSQL try-lock shared by every instance
+type memoryLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+	err  error
+}
+
+func newMemoryLocker() *memoryLocker {
+	return &memoryLocker{held: make(map[string]bool)}
+}
+
+func (l *memoryLocker) TryLockCronJob(ctx context.Context, job string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return nil, false, l.err
+	}
+	if l.held[job] {
+		return nil, false, nil
+	}
+	l.held[job] = true
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.held, job)
+	}, true, nil
+}
+
+func cronPost(secret string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/cron/sync-disputes", nil)
+	req.Header.Set("X-Cron-Secret", secret)
+	return req
+}
+
+func TestExclusive_ConcurrentRunsSkipped(t *testing.T) {
+	locker := newMemoryLocker()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var runs atomic.Int32
+
+	handler := Exclusive(locker, "sync-disputes", "secret", zap.NewNop(), func(w http.ResponseWriter, r *http.Request) {
+		if runs.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Two instances receive the same scheduled run; the first holds the lock until released
+	first := httptest.NewRecorder()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler(first, cronPost("secret"))
+	}()
+	<-started
+
+	second := httptest.NewRecorder()
+	secondDone := make(chan struct{})
+	go func() {
+		defer close(secondDone)
+		handler(second, cronPost("secret"))
+	}()
+
+	// The second run returns while the first still holds the lock
+	<-secondDone
+	require.Equal(t, int32(1), runs.Load())
+	assert.Equal(t, http.StatusOK, second.Code)
+
+	var skipped SkippedRunResponse
+	require.NoError(t, json.NewDecoder(second.Body).Decode(&skipped))
+	assert.True(t, skipped.Skipped)
+	assert.True(t, skipped.Success)
+	assert.Equal(t, "sync-disputes", skipped.Job)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	// The lock is released once the run finishes
+	next := httptest.NewRecorder()
+	handler(next, cronPost("secret"))
+	assert.Equal(t, int32(2), runs.Load())
+	assert.Empty(t, locker.held)
+}
+
+func TestExclusive_UnauthorizedDoesNotTakeLock(t *testing.T) {
+	locker := newMemoryLocker()
+	handler := Exclusive(locker, "sync-disputes", "secret", zap.NewNop(), func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, locker.held, "an unauthorized request doesn't hold the lock")
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, cronPost("wrong"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestExclusive_LockError(t *testing.T) {
+	locker := newMemoryLocker()
+	locker.err = errors.New("connection refused")
+	handler := Exclusive(locker, "sync-disputes", "secret", zap.NewNop(), func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("the job doesn't run when the lock can't be checked")
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, cronPost("secret"))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,438 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/adapters/database"
+	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// Reconciliation mismatch types
+const (
+	MismatchMissingLocally = "missing_locally" // Settled at EPX, no local transaction with that AUTH_GUID
+	MismatchMissingAtEPX   = "missing_at_epx"  // Local transaction expected to settle, not in EPX settlement data
+	MismatchAmountDiffers  = "amount_differs"  // Both sides have it, amounts differ
+	MismatchStatusDiffers  = "status_differs"  // Local status disagrees with EPX settlement status
+)
+
+// maxReconciliationDays bounds the date range of a single reconciliation run
+const maxReconciliationDays = 31
+
+// ReconciliationQueryExecutor defines the queries used by reconciliation
+type ReconciliationQueryExecutor interface {
+	GetAgentByAgentID(ctx context.Context, agentID string) (sqlc.AgentCredential, error)
+	ListActiveAgents(ctx context.Context) ([]sqlc.AgentCredential, error)
+	ListTransactionsForReconciliation(ctx context.Context, arg sqlc.ListTransactionsForReconciliationParams) ([]sqlc.ListTransactionsForReconciliationRow, error)
+	MarkTransactionSettled(ctx context.Context, arg sqlc.MarkTransactionSettledParams) error
+}
+
+// ReconciliationHandler handles cron job endpoints comparing local transactions with EPX settlement
+type ReconciliationHandler struct {
+	merchantReporting adapterports.MerchantReportingAdapter
+	queries           ReconciliationQueryExecutor
+	logger            *zap.Logger
+	cronSecret        string
+}
+
+// NewReconciliationHandler creates a new reconciliation cron handler
+func NewReconciliationHandler(
+	merchantReporting adapterports.MerchantReportingAdapter,
+	db *database.PostgreSQLAdapter,
+	logger *zap.Logger,
+	cronSecret string,
+) *ReconciliationHandler {
+	return NewReconciliationHandlerWithQueries(merchantReporting, db.Queries(), logger, cronSecret)
+}
+
+// NewReconciliationHandlerWithQueries creates a reconciliation handler with a custom query executor
+func NewReconciliationHandlerWithQueries(
+	merchantReporting adapterports.MerchantReportingAdapter,
+	queries ReconciliationQueryExecutor,
+	logger *zap.Logger,
+	cronSecret string,
+) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		merchantReporting: merchantReporting,
+		queries:           queries,
+		logger:            logger,
+		cronSecret:        cronSecret,
+	}
+}
+
+// ReconcileRequest represents the request body for reconciliation
+type ReconcileRequest struct {
+	AgentID  *string `json:"agent_id"`  // Optional: reconcile a specific agent, otherwise all active agents
+	FromDate *string `json:"from_date"` // Optional: ISO date, defaults to yesterday
+	ToDate   *string `json:"to_date"`   // Optional: ISO date (inclusive), defaults to from_date
+}
+
+// ReconciliationMismatch is a single discrepancy between local and EPX records
+type ReconciliationMismatch struct {
+	Type          string `json:"type"`
+	AuthGUID      string `json:"auth_guid"`
+	TransactionID string `json:"transaction_id,omitempty"`
+	LocalAmount   string `json:"local_amount,omitempty"`
+	EPXAmount     string `json:"epx_amount,omitempty"`
+	LocalStatus   string `json:"local_status,omitempty"`
+	EPXStatus     string `json:"epx_status,omitempty"`
+}
+
+// AgentReconciliationReport is the reconciliation result for one agent
+type AgentReconciliationReport struct {
+	AgentID       string                   `json:"agent_id"`
+	LocalCount    int                      `json:"local_count"`
+	EPXCount      int                      `json:"epx_count"`
+	Matched       int                      `json:"matched"`
+	MarkedSettled int                      `json:"marked_settled"`
+	Mismatches    []ReconciliationMismatch `json:"mismatches"`
+}
+
+// ReconcileResponse represents the response from a reconciliation run
+type ReconcileResponse struct {
+	Success         bool                        `json:"success"`
+	FromDate        string                      `json:"from_date"`
+	ToDate          string                      `json:"to_date"`
+	TotalMismatches int                         `json:"total_mismatches"`
+	Agents          []AgentReconciliationReport `json:"agents"`
+	Errors          []string                    `json:"errors,omitempty"`
+	ProcessedAt     string                      `json:"processed_at"`
+}
+
+// settlementMatch is a local transaction confirmed settled by EPX
+type settlementMatch struct {
+	row            *sqlc.ListTransactionsForReconciliationRow
+	settlementDate string
+}
+
+// Reconcile handles the POST /cron/reconcile endpoint
+// This endpoint is called by Cloud Scheduler (e.g. daily) to detect drift between local records and EPX settlement
+func (h *ReconciliationHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, http.StatusMethodNotAllowed, "only POST method is allowed")
+		return
+	}
+
+	if !h.authenticateRequest(r) {
+		h.logger.Warn("Unauthorized cron request",
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		h.respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req ReconcileRequest
+	if r.Body != nil && r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+	}
+
+	fromDate, toDate, err := parseReconciliationRange(req.FromDate, req.ToDate, time.Now())
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+
+	var agents []sqlc.AgentCredential
+	if req.AgentID != nil {
+		agent, err := h.queries.GetAgentByAgentID(ctx, *req.AgentID)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("agent not found: %v", err))
+			return
+		}
+		agents = []sqlc.AgentCredential{agent}
+	} else {
+		agents, err = h.queries.ListActiveAgents(ctx)
+		if err != nil {
+			h.respondError(w, http.StatusInternalServerError, fmt.Sprintf("failed to list agents: %v", err))
+			return
+		}
+	}
+
+	resp := ReconcileResponse{
+		Success:     true,
+		FromDate:    fromDate.Format("2006-01-02"),
+		ToDate:      toDate.Format("2006-01-02"),
+		Agents:      []AgentReconciliationReport{},
+		ProcessedAt: time.Now().Format(time.RFC3339),
+	}
+
+	for _, agent := range agents {
+		report, err := h.reconcileAgent(ctx, &agent, fromDate, toDate)
+		if err != nil {
+			resp.Success = false
+			resp.Errors = append(resp.Errors, fmt.Sprintf("agent %s: %v", agent.AgentID, err))
+			h.logger.Error("Failed to reconcile agent",
+				zap.String("agent_id", agent.AgentID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		resp.Agents = append(resp.Agents, *report)
+		resp.TotalMismatches += len(report.Mismatches)
+
+		if len(report.Mismatches) > 0 {
+			h.logger.Warn("Reconciliation mismatches found",
+				zap.String("agent_id", agent.AgentID),
+				zap.Int("mismatches", len(report.Mismatches)),
+			)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Success {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusPartialContent)
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// reconcileAgent compares one agent's transactions with EPX settlement data and marks matches settled
+func (h *ReconciliationHandler) reconcileAgent(ctx context.Context, agent *sqlc.AgentCredential, fromDate, toDate time.Time) (*AgentReconciliationReport, error) {
+	agentID := agent.AgentID
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := h.queries.ListTransactionsForReconciliation(ctx, sqlc.ListTransactionsForReconciliationParams{
+		AgentID:     agentID,
+		CreatedFrom: fromDate,
+		CreatedTo:   toDate.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list local transactions: %w", err)
+	}
+
+	settlements, err := h.merchantReporting.SearchSettlements(ctx, &adapterports.SettlementSearchRequest{
+		MerchantID: agentID,
+		FromDate:   fromDate,
+		ToDate:     toDate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search EPX settlements: %w", err)
+	}
+
+	report, matches := reconcileTransactions(agentID, local, settlements.Transactions)
+
+	for _, match := range matches {
+		if match.row.SettledAt.Valid {
+			continue
+		}
+
+		settledAt, err := time.Parse("2006-01-02", match.settlementDate)
+		if err != nil {
+			settledAt = time.Now()
+		}
+
+		if err := h.queries.MarkTransactionSettled(ctx, sqlc.MarkTransactionSettledParams{
+			ID:          match.row.ID,
+			SettledAt:   pgtype.Timestamptz{Time: settledAt, Valid: true},
+			FundingDate: pgtype.Date{Time: config.FundingDate(settledAt), Valid: true},
+		}); err != nil {
+			h.logger.Warn("Failed to mark transaction settled",
+				zap.String("transaction_id", match.row.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		report.MarkedSettled++
+	}
+
+	return report, nil
+}
+
+// reconcileTransactions matches local transactions to EPX settlement records by AUTH_GUID
+func reconcileTransactions(
+	agentID string,
+	local []sqlc.ListTransactionsForReconciliationRow,
+	settled []*adapterports.SettledTransaction,
+) (*AgentReconciliationReport, []settlementMatch) {
+	report := &AgentReconciliationReport{
+		AgentID:    agentID,
+		LocalCount: len(local),
+		EPXCount:   len(settled),
+		Mismatches: []ReconciliationMismatch{},
+	}
+
+	byAuthGUID := make(map[string]*sqlc.ListTransactionsForReconciliationRow, len(local))
+	for i := range local {
+		byAuthGUID[local[i].AuthGuid.String] = &local[i]
+	}
+
+	seen := make(map[string]bool, len(settled))
+	var matches []settlementMatch
+
+	for _, record := range settled {
+		epxAmount := decimal.NewFromFloat(record.Amount).Round(2)
+		epxSettled := strings.EqualFold(record.Status, "settled")
+
+		row, ok := byAuthGUID[record.AuthGUID]
+		if !ok {
+			if epxSettled {
+				report.Mismatches = append(report.Mismatches, ReconciliationMismatch{
+					Type:      MismatchMissingLocally,
+					AuthGUID:  record.AuthGUID,
+					EPXAmount: epxAmount.StringFixed(2),
+					EPXStatus: record.Status,
+				})
+			}
+			continue
+		}
+		seen[record.AuthGUID] = true
+
+		localAmount := decimal.NewFromBigInt(row.Amount.Int, row.Amount.Exp)
+		expected := expectedToSettle(row)
+
+		if epxSettled != expected {
+			report.Mismatches = append(report.Mismatches, ReconciliationMismatch{
+				Type:          MismatchStatusDiffers,
+				AuthGUID:      record.AuthGUID,
+				TransactionID: row.ID.String(),
+				LocalStatus:   localStatus(row),
+				EPXStatus:     record.Status,
+			})
+			continue
+		}
+		if !epxSettled {
+			continue
+		}
+
+		if !localAmount.Equal(epxAmount) {
+			report.Mismatches = append(report.Mismatches, ReconciliationMismatch{
+				Type:          MismatchAmountDiffers,
+				AuthGUID:      record.AuthGUID,
+				TransactionID: row.ID.String(),
+				LocalAmount:   localAmount.StringFixed(2),
+				EPXAmount:     epxAmount.StringFixed(2),
+			})
+			continue
+		}
+
+		report.Matched++
+		matches = append(matches, settlementMatch{row: row, settlementDate: record.SettlementDate})
+	}
+
+	for i := range local {
+		row := &local[i]
+		if seen[row.AuthGuid.String] || !expectedToSettle(row) {
+			continue
+		}
+		report.Mismatches = append(report.Mismatches, ReconciliationMismatch{
+			Type:          MismatchMissingAtEPX,
+			AuthGUID:      row.AuthGuid.String,
+			TransactionID: row.ID.String(),
+			LocalAmount:   decimal.NewFromBigInt(row.Amount.Int, row.Amount.Exp).StringFixed(2),
+			LocalStatus:   localStatus(row),
+		})
+	}
+
+	return report, matches
+}
+
+// expectedToSettle returns true if EPX should have settled the local transaction
+func expectedToSettle(row *sqlc.ListTransactionsForReconciliationRow) bool {
+	if row.VoidedInGroup {
+		return false
+	}
+	status := domain.TransactionStatus(row.Status)
+	return status == domain.TransactionStatusCompleted || status == domain.TransactionStatusRefunded
+}
+
+// localStatus describes the local status, noting voided groups
+func localStatus(row *sqlc.ListTransactionsForReconciliationRow) string {
+	if row.VoidedInGroup && row.Status != string(domain.TransactionStatusVoided) {
+		return row.Status + " (voided)"
+	}
+	return row.Status
+}
+
+// parseReconciliationRange resolves the inclusive date range (defaults to yesterday)
+func parseReconciliationRange(from, to *string, now time.Time) (time.Time, time.Time, error) {
+	fromDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	if from != nil {
+		parsed, err := time.Parse("2006-01-02", *from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from_date format: %v", err)
+		}
+		fromDate = parsed
+	}
+
+	toDate := fromDate
+	if to != nil {
+		parsed, err := time.Parse("2006-01-02", *to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to_date format: %v", err)
+		}
+		toDate = parsed
+	}
+
+	if toDate.Before(fromDate) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to_date must not be before from_date")
+	}
+	if toDate.Sub(fromDate) >= maxReconciliationDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("date range must not exceed %d days", maxReconciliationDays)
+	}
+
+	return fromDate, toDate, nil
+}
+
+// authenticateRequest verifies the cron request is authorized
+func (h *ReconciliationHandler) authenticateRequest(r *http.Request) bool {
+	// Check X-Cron-Secret header
+	cronSecret := r.Header.Get("X-Cron-Secret")
+	if cronSecret != "" && cronSecret == h.cronSecret {
+		return true
+	}
+
+	// Check Authorization header (Bearer token)
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "Bearer "+h.cronSecret {
+		return true
+	}
+
+	// Check query parameter (less secure, for development only)
+	querySecret := r.URL.Query().Get("secret")
+	if querySecret != "" && querySecret == h.cronSecret {
+		h.logger.Warn("Using query parameter authentication (insecure)",
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		return true
+	}
+
+	return false
+}
+
+// respondError sends an error response
+func (h *ReconciliationHandler) respondError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	resp := map[string]interface{}{
+		"success": false,
+		"error":   message,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode error response", zap.Error(err))
+	}
+}
@@ -0,0 +1,285 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+)
+
+// fakeMerchantReporting returns canned EPX settlement data
+type fakeMerchantReporting struct {
+	settlements []*adapterports.SettledTransaction
+	lastRequest *adapterports.SettlementSearchRequest
+}
+
+func (f *fakeMerchantReporting) SearchDisputes(ctx context.Context, req *adapterports.DisputeSearchRequest) (*adapterports.DisputeSearchResponse, error) {
+	return &adapterports.DisputeSearchResponse{}, nil
+}
+
+func (f *fakeMerchantReporting) SearchSettlements(ctx context.Context, req *adapterports.SettlementSearchRequest) (*adapterports.SettlementSearchResponse, error) {
+	f.lastRequest = req
+	return &adapterports.SettlementSearchResponse{
+		Transactions:       f.settlements,
+		TotalTransactions:  len(f.settlements),
+		CurrentResultCount: len(f.settlements),
+	}, nil
+}
+
+// fakeReconciliationStore serves local transactions and records settlement marks
+type fakeReconciliationStore struct {
+	agents  []sqlc.AgentCredential
+	rows    []sqlc.ListTransactionsForReconciliationRow
+	settled map[uuid.UUID]time.Time
+	funding map[uuid.UUID]time.Time
+}
+
+func (f *fakeReconciliationStore) GetAgentByAgentID(ctx context.Context, agentID string) (sqlc.AgentCredential, error) {
+	for _, agent := range f.agents {
+		if agent.AgentID == agentID {
+			return agent, nil
+		}
+	}
+	return sqlc.AgentCredential{}, assert.AnError
+}
+
+func (f *fakeReconciliationStore) ListActiveAgents(ctx context.Context) ([]sqlc.AgentCredential, error) {
+	return f.agents, nil
+}
+
+func (f *fakeReconciliationStore) ListTransactionsForReconciliation(ctx context.Context, arg sqlc.ListTransactionsForReconciliationParams) ([]sqlc.ListTransactionsForReconciliationRow, error) {
+	return f.rows, nil
+}
+
+func (f *fakeReconciliationStore) MarkTransactionSettled(ctx context.Context, arg sqlc.MarkTransactionSettledParams) error {
+	f.settled[arg.ID] = arg.SettledAt.Time
+	if arg.FundingDate.Valid {
+		f.funding[arg.ID] = arg.FundingDate.Time
+	}
+	return nil
+}
+
+func newReconciliationRow(authGUID, amount, status string) sqlc.ListTransactionsForReconciliationRow {
+	var numeric pgtype.Numeric
+	_ = numeric.Scan(amount)
+	return sqlc.ListTransactionsForReconciliationRow{
+		ID:       uuid.New(),
+		AgentID:  "agent-1",
+		Amount:   numeric,
+		Status:   status,
+		Type:     "charge",
+		AuthGuid: pgtype.Text{String: authGUID, Valid: true},
+	}
+}
+
+func newSettledTransaction(authGUID string, amount float64) *adapterports.SettledTransaction {
+	return &adapterports.SettledTransaction{
+		AuthGUID:       authGUID,
+		Status:         "settled",
+		Amount:         amount,
+		SettlementDate: "2025-03-02",
+	}
+}
+
+func mismatchTypes(report *AgentReconciliationReport) map[string]string {
+	types := make(map[string]string, len(report.Mismatches))
+	for _, m := range report.Mismatches {
+		types[m.AuthGUID] = m.Type
+	}
+	return types
+}
+
+func TestReconcileTransactions(t *testing.T) {
+	voided := newReconciliationRow("BRIC-VOID", "20.00", "completed")
+	voided.VoidedInGroup = true
+
+	local := []sqlc.ListTransactionsForReconciliationRow{
+		newReconciliationRow("BRIC-OK", "10.00", "completed"),
+		newReconciliationRow("BRIC-AMOUNT", "25.00", "completed"),
+		newReconciliationRow("BRIC-UNSETTLED", "30.00", "completed"),
+		newReconciliationRow("BRIC-FAILED", "5.00", "failed"),
+		voided,
+	}
+	settled := []*adapterports.SettledTransaction{
+		newSettledTransaction("BRIC-OK", 10.00),
+		newSettledTransaction("BRIC-AMOUNT", 24.50),
+		newSettledTransaction("BRIC-FAILED", 5.00),
+		newSettledTransaction("BRIC-VOID", 20.00),
+		newSettledTransaction("BRIC-UNKNOWN", 42.00),
+	}
+
+	report, matches := reconcileTransactions("agent-1", local, settled)
+
+	assert.Equal(t, 5, report.LocalCount)
+	assert.Equal(t, 5, report.EPXCount)
+	assert.Equal(t, 1, report.Matched)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "BRIC-OK", matches[0].row.AuthGuid.String)
+
+	assert.Equal(t, map[string]string{
+		"BRIC-AMOUNT":    MismatchAmountDiffers,
+		"BRIC-UNSETTLED": MismatchMissingAtEPX,
+		"BRIC-FAILED":    MismatchStatusDiffers,
+		"BRIC-VOID":      MismatchStatusDiffers,
+		"BRIC-UNKNOWN":   MismatchMissingLocally,
+	}, mismatchTypes(report))
+
+	for _, m := range report.Mismatches {
+		switch m.AuthGUID {
+		case "BRIC-AMOUNT":
+			assert.Equal(t, "25.00", m.LocalAmount)
+			assert.Equal(t, "24.50", m.EPXAmount)
+		case "BRIC-VOID":
+			assert.Equal(t, "completed (voided)", m.LocalStatus)
+		}
+	}
+}
+
+func TestReconcileTransactions_RejectedAtEPX(t *testing.T) {
+	rejected := newSettledTransaction("BRIC-1", 10.00)
+	rejected.Status = "rejected"
+
+	t.Run("expected locally is a status mismatch", func(t *testing.T) {
+		local := []sqlc.ListTransactionsForReconciliationRow{newReconciliationRow("BRIC-1", "10.00", "completed")}
+		report, matches := reconcileTransactions("agent-1", local, []*adapterports.SettledTransaction{rejected})
+		assert.Empty(t, matches)
+		assert.Equal(t, map[string]string{"BRIC-1": MismatchStatusDiffers}, mismatchTypes(report))
+	})
+
+	t.Run("failed locally agrees", func(t *testing.T) {
+		local := []sqlc.ListTransactionsForReconciliationRow{newReconciliationRow("BRIC-1", "10.00", "failed")}
+		report, _ := reconcileTransactions("agent-1", local, []*adapterports.SettledTransaction{rejected})
+		assert.Empty(t, report.Mismatches)
+	})
+
+	t.Run("unknown rejection is ignored", func(t *testing.T) {
+		report, _ := reconcileTransactions("agent-1", nil, []*adapterports.SettledTransaction{rejected})
+		assert.Empty(t, report.Mismatches)
+	})
+}
+
+func TestParseReconciliationRange(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC)
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		from, to *string
+		wantFrom string
+		wantTo   string
+		wantErr  bool
+	}{
+		{name: "defaults to yesterday", wantFrom: "2025-03-09", wantTo: "2025-03-09"},
+		{name: "from only", from: str("2025-03-01"), wantFrom: "2025-03-01", wantTo: "2025-03-01"},
+		{name: "explicit range", from: str("2025-03-01"), to: str("2025-03-07"), wantFrom: "2025-03-01", wantTo: "2025-03-07"},
+		{name: "invalid date", from: str("03/01/2025"), wantErr: true},
+		{name: "to before from", from: str("2025-03-07"), to: str("2025-03-01"), wantErr: true},
+		{name: "range too long", from: str("2025-01-01"), to: str("2025-03-01"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := parseReconciliationRange(tt.from, tt.to, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFrom, from.Format("2006-01-02"))
+			assert.Equal(t, tt.wantTo, to.Format("2006-01-02"))
+		})
+	}
+}
+
+func TestReconcile_Endpoint(t *testing.T) {
+	alreadySettled := newReconciliationRow("BRIC-2", "7.50", "refunded")
+	alreadySettled.SettledAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	ok := newReconciliationRow("BRIC-1", "10.00", "completed")
+
+	store := &fakeReconciliationStore{
+		agents: []sqlc.AgentCredential{{
+			AgentID:         "agent-1",
+			Tier:            "standard",
+			ConfigOverrides: []byte(`{"funding_delay_days": 3}`),
+		}},
+		rows:    []sqlc.ListTransactionsForReconciliationRow{ok, alreadySettled},
+		settled: make(map[uuid.UUID]time.Time),
+		funding: make(map[uuid.UUID]time.Time),
+	}
+	reporting := &fakeMerchantReporting{
+		settlements: []*adapterports.SettledTransaction{
+			newSettledTransaction("BRIC-1", 10.00),
+			newSettledTransaction("BRIC-2", 7.50),
+			newSettledTransaction("BRIC-3", 99.99),
+		},
+	}
+	handler := NewReconciliationHandlerWithQueries(reporting, store, zap.NewNop(), "secret")
+
+	t.Run("rejects unauthenticated requests", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/cron/reconcile", nil)
+		rec := httptest.NewRecorder()
+		handler.Reconcile(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("rejects GET", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/cron/reconcile", nil)
+		req.Header.Set("X-Cron-Secret", "secret")
+		rec := httptest.NewRecorder()
+		handler.Reconcile(rec, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+
+	t.Run("returns mismatch report and marks matches settled", func(t *testing.T) {
+		body := `{"agent_id":"agent-1","from_date":"2025-03-01","to_date":"2025-03-02"}`
+		req := httptest.NewRequest(http.MethodPost, "/cron/reconcile", strings.NewReader(body))
+		req.Header.Set("X-Cron-Secret", "secret")
+		rec := httptest.NewRecorder()
+
+		handler.Reconcile(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var resp ReconcileResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+		assert.True(t, resp.Success)
+		assert.Equal(t, "2025-03-01", resp.FromDate)
+		assert.Equal(t, "2025-03-02", resp.ToDate)
+		assert.Equal(t, 1, resp.TotalMismatches)
+		require.Len(t, resp.Agents, 1)
+		assert.Equal(t, 2, resp.Agents[0].Matched)
+		assert.Equal(t, 1, resp.Agents[0].MarkedSettled)
+		require.Len(t, resp.Agents[0].Mismatches, 1)
+		assert.Equal(t, MismatchMissingLocally, resp.Agents[0].Mismatches[0].Type)
+		assert.Equal(t, "99.99", resp.Agents[0].Mismatches[0].EPXAmount)
+
+		require.Contains(t, store.settled, ok.ID)
+		assert.Equal(t, "2025-03-02", store.settled[ok.ID].Format("2006-01-02"))
+		// Sunday settlement + 3 business days
+		assert.Equal(t, "2025-03-05", store.funding[ok.ID].Format("2006-01-02"))
+		assert.NotContains(t, store.settled, alreadySettled.ID)
+
+		require.NotNil(t, reporting.lastRequest)
+		assert.Equal(t, "agent-1", reporting.lastRequest.MerchantID)
+	})
+
+	t.Run("unknown agent is a bad request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/cron/reconcile", strings.NewReader(`{"agent_id":"nope"}`))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.Reconcile(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
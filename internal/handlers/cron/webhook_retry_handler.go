@@ -0,0 +1,185 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kevin07696/payment-service/internal/services/webhook"
+	"go.uber.org/zap"
+)
+
+// WebhookRetryHandler handles cron job endpoints for webhook delivery retries
+type WebhookRetryHandler struct {
+	webhookService *webhook.WebhookDeliveryService
+	logger         *zap.Logger
+	cronSecret     string
+}
+
+// NewWebhookRetryHandler creates a new webhook retry cron handler
+func NewWebhookRetryHandler(
+	webhookService *webhook.WebhookDeliveryService,
+	logger *zap.Logger,
+	cronSecret string,
+) *WebhookRetryHandler {
+	return &WebhookRetryHandler{
+		webhookService: webhookService,
+		logger:         logger,
+		cronSecret:     cronSecret,
+	}
+}
+
+// RetryWebhooksResponse represents the response from a retry run
+type RetryWebhooksResponse struct {
+	Success     bool   `json:"success"`
+	Retried     int    `json:"retried"`
+	Error       string `json:"error,omitempty"`
+	ProcessedAt string `json:"processed_at"`
+}
+
+// DeadLetterDelivery is a dead-lettered delivery awaiting manual replay
+type DeadLetterDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventType      string          `json:"event_type"`
+	Attempts       int32           `json:"attempts"`
+	HTTPStatusCode *int32          `json:"http_status_code,omitempty"`
+	ErrorMessage   string          `json:"error_message,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	CreatedAt      string          `json:"created_at"`
+}
+
+// RetryWebhooks handles the POST /cron/retry-webhooks endpoint
+// This endpoint is called by Cloud Scheduler (e.g. every minute) to retry due webhook deliveries
+func (h *WebhookRetryHandler) RetryWebhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, http.StatusMethodNotAllowed, "only POST method is allowed")
+		return
+	}
+
+	if !h.authenticateRequest(r) {
+		h.logger.Warn("Unauthorized cron request",
+			zap.String("remote_addr", r.RemoteAddr),
+		)
+		h.respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	retried, err := h.webhookService.RetryFailedDeliveries(context.Background())
+
+	resp := RetryWebhooksResponse{
+		Success:     err == nil,
+		Retried:     retried,
+		ProcessedAt: time.Now().Format(time.RFC3339),
+	}
+	status := http.StatusOK
+	if err != nil {
+		resp.Error = err.Error()
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// ListDeadLetters handles GET /cron/webhooks/dead-letter for manual replay
+// Query parameters: agent_id (optional), limit (default 100, max 1000), offset
+func (h *WebhookRetryHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.respondError(w, http.StatusMethodNotAllowed, "only GET method is allowed")
+		return
+	}
+
+	if !h.authenticateRequest(r) {
+		h.respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+
+	var agentID *string
+	if v := query.Get("agent_id"); v != "" {
+		agentID = &v
+	}
+
+	limit := 100
+	if v := query.Get("limit"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 && parsed <= 1000 {
+			limit = parsed
+		}
+	}
+
+	offset := 0
+	if v := query.Get("offset"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	deliveries, err := h.webhookService.ListDeadLetterDeliveries(r.Context(), agentID, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list dead letter deliveries", zap.Error(err))
+		h.respondError(w, http.StatusInternalServerError, "failed to list dead letter deliveries")
+		return
+	}
+
+	items := make([]DeadLetterDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		item := DeadLetterDelivery{
+			ID:             d.ID.String(),
+			SubscriptionID: d.SubscriptionID.String(),
+			EventType:      d.EventType,
+			Attempts:       d.Attempts,
+			ErrorMessage:   d.ErrorMessage.String,
+			Payload:        d.Payload,
+			CreatedAt:      d.CreatedAt.Format(time.RFC3339),
+		}
+		if d.HttpStatusCode.Valid {
+			code := d.HttpStatusCode.Int32
+			item.HTTPStatusCode = &code
+		}
+		items = append(items, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    true,
+		"deliveries": items,
+	}); err != nil {
+		h.logger.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// authenticateRequest verifies the cron request is authorized
+func (h *WebhookRetryHandler) authenticateRequest(r *http.Request) bool {
+	// Check X-Cron-Secret header
+	cronSecret := r.Header.Get("X-Cron-Secret")
+	if cronSecret != "" && cronSecret == h.cronSecret {
+		return true
+	}
+
+	// Check Authorization header (Bearer token)
+	authHeader := r.Header.Get("Authorization")
+	return authHeader == "Bearer "+h.cronSecret
+}
+
+// respondError sends an error response
+func (h *WebhookRetryHandler) respondError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	resp := map[string]interface{}{
+		"success": false,
+		"error":   message,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode error response", zap.Error(err))
+	}
+}
@@ -3,24 +3,29 @@ package payment
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/kevin07696/payment-service/internal/adapters/database"
 	"github.com/kevin07696/payment-service/internal/adapters/ports"
 	"github.com/kevin07696/payment-service/internal/db/sqlc"
 	"github.com/kevin07696/payment-service/internal/domain"
 	serviceports "github.com/kevin07696/payment-service/internal/services/ports"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
 // DatabaseAdapter defines the interface for database operations
 type DatabaseAdapter interface {
-	Queries() *sqlc.Queries
+	Queries() sqlc.Querier
 }
 
 // PaymentMethodService defines the interface for payment method operations
@@ -28,12 +33,70 @@ type PaymentMethodService interface {
 	ConvertFinancialBRICToStorageBRIC(ctx context.Context, req *serviceports.ConvertFinancialBRICRequest) (*domain.PaymentMethod, error)
 }
 
+// SaleService charges a saved payment method (the save_and_charge flow's second step)
+type SaleService interface {
+	Sale(ctx context.Context, req *serviceports.SaleRequest) (*domain.Transaction, error)
+}
+
+// Charge intent statuses (browser_post_charge_intents.status)
+const (
+	chargeIntentPending   = "pending"
+	chargeIntentCompleted = "completed"
+	chargeIntentFailed    = "failed"
+)
+
+// Browser Post transaction types accepted by GetPaymentForm
+const (
+	browserPostSale          = "sale"            // Charge the card (default)
+	browserPostSaveAndCharge = "save_and_charge" // Tokenize the card with STORAGE, save it, then charge the saved card
+)
+
+const (
+	// DefaultTACValidity is how long an EPX TAC stays valid (per EPX documentation)
+	DefaultTACValidity = 4 * time.Hour
+
+	// DefaultCallbackGrace is extra time after TAC expiry for callbacks that arrive late
+	DefaultCallbackGrace = 30 * time.Minute
+)
+
+// PendingExpiryConfig controls how long a pending Browser Post transaction accepts its callback.
+// The customer can submit the EPX form until the TAC expires, so the expiry window is always
+// the TAC validity plus a grace period for the redirect to reach us.
+type PendingExpiryConfig struct {
+	TACValidity   time.Duration // Zero uses DefaultTACValidity
+	CallbackGrace time.Duration // Zero uses DefaultCallbackGrace
+}
+
+// TACValidityOrDefault returns the configured TAC validity or DefaultTACValidity
+func (c PendingExpiryConfig) TACValidityOrDefault() time.Duration {
+	if c.TACValidity <= 0 {
+		return DefaultTACValidity
+	}
+	return c.TACValidity
+}
+
+// Window returns the pending transaction expiry window (always longer than the TAC validity)
+func (c PendingExpiryConfig) Window() time.Duration {
+	grace := c.CallbackGrace
+	if grace <= 0 {
+		grace = DefaultCallbackGrace
+	}
+	return c.TACValidityOrDefault() + grace
+}
+
+// ExpiresAt returns the pending_expires_at for a pending transaction created at createdAt
+func (c PendingExpiryConfig) ExpiresAt(createdAt time.Time) time.Time {
+	return createdAt.Add(c.Window())
+}
+
 // BrowserPostCallbackHandler handles the redirect callback from EPX Browser Post API
 // This endpoint receives the transaction results after EPX processes the payment
 type BrowserPostCallbackHandler struct {
 	dbAdapter        DatabaseAdapter
 	browserPost      ports.BrowserPostAdapter
 	paymentMethodSvc PaymentMethodService
+	saleSvc          SaleService
+	secretManager    ports.SecretManagerAdapter
 	logger           *zap.Logger
 	epxPostURL       string // EPX Browser Post endpoint URL
 	epxCustNbr       string // EPX Customer Number
@@ -41,6 +104,11 @@ type BrowserPostCallbackHandler struct {
 	epxDBAnbr        string // EPX DBA Number
 	epxTerminalNbr   string // EPX Terminal Number
 	callbackBaseURL  string // Base URL for callback (e.g., "http://localhost:8081")
+	epxEnv           domain.Environment
+	requireHTTPS     bool     // Production: return_url must use https
+	devReturnHosts   []string // Hosts every merchant's return_url may use (e.g. localhost in development)
+	expiry           PendingExpiryConfig
+	now              func() time.Time
 }
 
 // NewBrowserPostCallbackHandler creates a new Browser Post callback handler
@@ -48,6 +116,8 @@ func NewBrowserPostCallbackHandler(
 	dbAdapter DatabaseAdapter,
 	browserPost ports.BrowserPostAdapter,
 	paymentMethodSvc PaymentMethodService,
+	saleSvc SaleService,
+	secretManager ports.SecretManagerAdapter,
 	logger *zap.Logger,
 	epxPostURL string,
 	epxCustNbr string,
@@ -55,11 +125,16 @@ func NewBrowserPostCallbackHandler(
 	epxDBAnbr string,
 	epxTerminalNbr string,
 	callbackBaseURL string,
+	epxEnv domain.Environment,
+	devReturnHosts []string,
+	expiry PendingExpiryConfig,
 ) *BrowserPostCallbackHandler {
 	return &BrowserPostCallbackHandler{
 		dbAdapter:        dbAdapter,
 		browserPost:      browserPost,
 		paymentMethodSvc: paymentMethodSvc,
+		saleSvc:          saleSvc,
+		secretManager:    secretManager,
 		logger:           logger,
 		epxPostURL:       epxPostURL,
 		epxCustNbr:       epxCustNbr,
@@ -67,12 +142,22 @@ func NewBrowserPostCallbackHandler(
 		epxDBAnbr:        epxDBAnbr,
 		epxTerminalNbr:   epxTerminalNbr,
 		callbackBaseURL:  callbackBaseURL,
+		epxEnv:           epxEnv,
+		requireHTTPS:     epxEnv.IsLive(),
+		devReturnHosts:   devReturnHosts,
+		expiry:           expiry,
+		now:              time.Now,
 	}
 }
 
 // GetPaymentForm generates form configuration for Browser Post payment
 // This endpoint is called by the frontend to get EPX credentials and form fields
-// Endpoint: GET /api/v1/payments/browser-post/form?amount=99.99
+// Endpoint: GET /api/v1/payments/browser-post/form?amount=99.99&return_url=https://pos.example.com/done
+// return_url's host must be on the return_url_hosts allowlist (or the configured dev hosts) of the merchant
+// registered with the handler's EPX credentials.
+// A sale form is recorded as the merchant's pending transaction under the form's TRAN_NBR, which its callback completes.
+// With transaction_type=save_and_charge&customer_id=... the form tokenizes the card instead, and the callback
+// saves it to the customer and charges amount against the saved card.
 func (h *BrowserPostCallbackHandler) GetPaymentForm(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		h.logger.Warn("Browser Post form generator received non-GET request",
@@ -90,8 +175,9 @@ func (h *BrowserPostCallbackHandler) GetPaymentForm(w http.ResponseWriter, r *ht
 		return
 	}
 
-	// Validate amount format
-	if _, err := fmt.Sscanf(amount, "%f", new(float64)); err != nil {
+	// Validate amount format; parsed as a decimal, not a float, so "10.50" is exactly 1050 cents (sub-cent amounts round)
+	amountCents, err := domain.ParseAmountToCents(amount)
+	if err != nil {
 		h.logger.Warn("Invalid amount format",
 			zap.String("amount", amount),
 			zap.Error(err),
@@ -99,11 +185,172 @@ func (h *BrowserPostCallbackHandler) GetPaymentForm(w http.ResponseWriter, r *ht
 		http.Error(w, "amount must be a valid number", http.StatusBadRequest)
 		return
 	}
+	amount = domain.FormatCents(amountCents)
+
+	// Validate optional transaction type; save_and_charge must say who the card is saved for and what to charge
+	transactionType := r.URL.Query().Get("transaction_type")
+	customerID := r.URL.Query().Get("customer_id")
+	switch transactionType {
+	case "", browserPostSale:
+		transactionType = browserPostSale
+	case browserPostSaveAndCharge:
+		if amountCents <= 0 {
+			http.Error(w, "save_and_charge requires an amount greater than zero", http.StatusBadRequest)
+			return
+		}
+		if customerID == "" {
+			http.Error(w, "customer_id is required for save_and_charge", http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "transaction_type must be sale or save_and_charge", http.StatusBadRequest)
+		return
+	}
+
+	agent, err := h.merchant(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to resolve Browser Post merchant",
+			zap.Error(err),
+		)
+		http.Error(w, "payment form is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// The form hands the merchant's credentials to the browser for this deployment's EPX, so they must be for it
+	if err := database.CheckEPXEnvironment(agent, h.epxEnv); err != nil {
+		h.logger.Error("Browser Post merchant is registered for the other EPX environment",
+			zap.Error(err),
+			zap.String("agent_id", agent.AgentID),
+		)
+		http.Error(w, "payment form is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// A Browser Post form starts a charge, so the kill switch applies before one is issued; the reason is only logged
+	if err := database.CheckChargesEnabled(r.Context(), h.dbAdapter.Queries(), agent.AgentID); err != nil {
+		h.logger.Warn("Browser Post form refused",
+			zap.Error(err),
+			zap.String("agent_id", agent.AgentID),
+		)
+		http.Error(w, "payment form is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// The form's amount must be within the merchant's range, like any other charge's
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		h.logger.Error("Failed to resolve Browser Post merchant config",
+			zap.Error(err),
+		)
+		http.Error(w, "payment form is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if err := config.CheckAmount(decimal.New(amountCents, -2)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Validate optional return URL (echoed into redirect HTML, so only http(s) is allowed)
+	returnURL := r.URL.Query().Get("return_url")
+	if returnURL != "" {
+		if err := domain.ValidateReturnURL(returnURL, h.requireHTTPS); err != nil {
+			h.logger.Warn("Invalid return_url",
+				zap.String("return_url", returnURL),
+				zap.Error(err),
+			)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		allowed, err := h.allowedReturnHosts(agent)
+		if err != nil {
+			h.logger.Error("Failed to resolve return_url allowlist",
+				zap.Error(err),
+			)
+			http.Error(w, "payment form is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if err := domain.CheckReturnURLHost(returnURL, allowed); err != nil {
+			h.logger.Warn("return_url host not allowed",
+				zap.String("return_url", returnURL),
+			)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// A save_and_charge card is saved to the customer, so the merchant's customer policy applies before EPX tokenizes it
+	if transactionType == browserPostSaveAndCharge {
+		if err := h.resolveCustomer(r.Context(), agent, customerID); err != nil {
+			if errors.Is(err, domain.ErrCustomerNotFound) {
+				http.Error(w, "customer not found", http.StatusNotFound)
+				return
+			}
+			h.logger.Error("Failed to resolve save_and_charge customer",
+				zap.Error(err),
+			)
+			http.Error(w, "payment form is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	// A sale form holds its amount against the merchant's daily volume limit until its callback; a declined callback
+	// releases it. A save_and_charge form's sale reserves when its callback charges the card.
+	var reservation *database.DailyVolumeReservation
+	if transactionType == browserPostSale {
+		reservation, err = database.ReserveDailyVolume(r.Context(), h.dbAdapter.Queries(), agent.AgentID, decimal.New(amountCents, -2), config.DailyVolumeLimit, h.now())
+		if errors.Is(err, domain.ErrDailyVolumeLimitExceeded) {
+			http.Error(w, "daily volume limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		if err != nil {
+			h.logger.Error("Failed to reserve Browser Post daily volume",
+				zap.Error(err),
+			)
+			http.Error(w, "payment form is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+	}
+	releaseVolume := func() {
+		if err := reservation.Release(context.WithoutCancel(r.Context()), h.dbAdapter.Queries()); err != nil {
+			h.logger.Warn("Failed to release daily volume",
+				zap.Error(err),
+			)
+		}
+	}
+
+	// The form's TRAN_NBR comes from the merchant's counter, like every other EPX request's
+	formID := uuid.New()
+	allocated, err := database.AllocateTranNbr(r.Context(), h.dbAdapter.Queries(), agent.AgentID, formID)
+	if err != nil {
+		h.logger.Error("Failed to allocate Browser Post TRAN_NBR",
+			zap.Error(err),
+		)
+		releaseVolume()
+		http.Error(w, "payment form is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	tranNbr := strconv.FormatInt(allocated, 10)
 
-	// Generate unique transaction number using Unix timestamp with microseconds
-	// This ensures uniqueness even for rapid requests within the same second
-	now := time.Now()
-	tranNbr := fmt.Sprintf("%d%06d", now.Unix()%100000, now.Nanosecond()/1000)
+	// A sale form is completed by its callback; a save_and_charge form's callback charges what the form recorded
+	record := func(ctx context.Context, agentID string, id uuid.UUID, tranNbr, amountCents int64) error {
+		return h.createPendingTransaction(ctx, agentID, id, tranNbr, amountCents, reservation.Day())
+	}
+	if transactionType == browserPostSaveAndCharge {
+		record = func(ctx context.Context, agentID string, id uuid.UUID, tranNbr, amountCents int64) error {
+			return h.createChargeIntent(ctx, agentID, id, tranNbr, customerID, amountCents)
+		}
+	}
+	if err := record(r.Context(), agent.AgentID, formID, allocated, amountCents); err != nil {
+		h.logger.Error("Failed to record Browser Post form",
+			zap.Error(err),
+			zap.String("tran_nbr", tranNbr),
+			zap.String("transaction_type", transactionType),
+		)
+		releaseVolume()
+		http.Error(w, "payment form is unavailable", http.StatusServiceUnavailable)
+		return
+	}
 
 	h.logger.Info("Generating Browser Post form configuration",
 		zap.String("amount", amount),
@@ -138,6 +385,18 @@ func (h *BrowserPostCallbackHandler) GetPaymentForm(w http.ResponseWriter, r *ht
 		"merchantName": "Payment Service",
 	}
 
+	if returnURL != "" {
+		formConfig["returnURL"] = returnURL
+	}
+
+	// save_and_charge posts a STORAGE transaction; the callback charges the saved card for amount
+	if transactionType == browserPostSaveAndCharge {
+		formConfig["tranGroup"] = "STORAGE"
+		formConfig["tranCode"] = "STORAGE"
+		formConfig["userData1"] = "save_and_charge=true"
+		formConfig["userData2"] = customerID
+	}
+
 	// Return JSON response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -149,6 +408,87 @@ func (h *BrowserPostCallbackHandler) GetPaymentForm(w http.ResponseWriter, r *ht
 	}
 }
 
+// merchant is the merchant registered with the handler's EPX credentials. Every form and callback carries those
+// credentials, so its settings are the ones that apply; cust_nbr alone isn't unique, so exactly one merchant
+// must match all four.
+func (h *BrowserPostCallbackHandler) merchant(ctx context.Context) (*sqlc.AgentCredential, error) {
+	agents, err := h.dbAdapter.Queries().ListAgentsByEPXCredentials(ctx, sqlc.ListAgentsByEPXCredentialsParams{
+		CustNbr:     h.epxCustNbr,
+		MerchNbr:    h.epxMerchNbr,
+		DbaNbr:      h.epxDBAnbr,
+		TerminalNbr: h.epxTerminalNbr,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up merchant: %w", err)
+	}
+	if len(agents) != 1 {
+		return nil, fmt.Errorf("%w: %d merchants registered with the Browser Post EPX credentials", domain.ErrAgentNotFound, len(agents))
+	}
+	return &agents[0], nil
+}
+
+// allowedReturnHosts returns the merchant's return_url allowlist plus the configured dev hosts
+func (h *BrowserPostCallbackHandler) allowedReturnHosts(agent *sqlc.AgentCredential) ([]string, error) {
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]string{}, config.ReturnURLHosts...), h.devReturnHosts...), nil
+}
+
+// resolveCustomer applies the merchant's customer policy to the customer a form or callback saves a card for
+func (h *BrowserPostCallbackHandler) resolveCustomer(ctx context.Context, agent *sqlc.AgentCredential, customerID string) error {
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		return fmt.Errorf("failed to resolve merchant config: %w", err)
+	}
+	return database.ResolveCustomer(ctx, h.dbAdapter.Queries(), config, agent.AgentID, &customerID, nil)
+}
+
+// createPendingTransaction records a sale form as the merchant's pending charge, which the form's callback completes.
+// volumeDate is the day its daily volume reservation is held against (NULL without one).
+func (h *BrowserPostCallbackHandler) createPendingTransaction(ctx context.Context, agentID string, id uuid.UUID, tranNbr int64, amountCents int64, volumeDate pgtype.Date) error {
+	var amount pgtype.Numeric
+	if err := amount.Scan(domain.FormatCents(amountCents)); err != nil {
+		return fmt.Errorf("invalid amount: %w", err)
+	}
+
+	_, err := h.dbAdapter.Queries().CreateTransaction(ctx, sqlc.CreateTransactionParams{
+		ID:                id,
+		GroupID:           uuid.New(),
+		AgentID:           agentID,
+		Amount:            amount,
+		Currency:          "USD",
+		Status:            string(domain.TransactionStatusPending),
+		Type:              string(domain.TransactionTypeCharge),
+		PaymentMethodType: string(domain.PaymentMethodTypeCreditCard), // Set from the callback (a bank account is ach)
+		TranNbr:           pgtype.Int8{Int64: tranNbr, Valid: true},
+		PendingExpiresAt:  pgtype.Timestamptz{Time: h.expiry.ExpiresAt(h.now()), Valid: true},
+		DailyVolumeDate:   volumeDate,
+		Metadata:          []byte("{}"),
+	})
+	return err
+}
+
+// createChargeIntent records the customer and amount a save_and_charge form's callback charges
+func (h *BrowserPostCallbackHandler) createChargeIntent(ctx context.Context, agentID string, id uuid.UUID, tranNbr int64, customerID string, amountCents int64) error {
+	var amount pgtype.Numeric
+	if err := amount.Scan(domain.FormatCents(amountCents)); err != nil {
+		return fmt.Errorf("invalid amount: %w", err)
+	}
+
+	_, err := h.dbAdapter.Queries().CreateBrowserPostChargeIntent(ctx, sqlc.CreateBrowserPostChargeIntentParams{
+		ID:         id,
+		AgentID:    agentID,
+		TranNbr:    tranNbr,
+		CustomerID: customerID,
+		Amount:     amount,
+		Currency:   "USD",
+		ExpiresAt:  h.expiry.ExpiresAt(h.now()),
+	})
+	return err
+}
+
 // HandleCallback processes the Browser Post redirect callback from EPX
 // According to EPX docs (page 7-8): EPX redirects browser with transaction results as self-posting form
 // Endpoint: POST /api/v1/payments/browser-post/callback
@@ -190,41 +530,87 @@ func (h *BrowserPostCallbackHandler) HandleCallback(w http.ResponseWriter, r *ht
 		return
 	}
 
-	// Check for duplicate transaction using TRAN_NBR (as recommended in EPX docs page 8)
-	// This handles the PRG (POST-REDIRECT-GET) pattern where same response can be received multiple times
-	if response.TranNbr != "" {
-		existingTx, err := h.dbAdapter.Queries().GetTransactionByIdempotencyKey(r.Context(), pgtype.Text{
-			String: response.TranNbr,
-			Valid:  true,
-		})
+	// The callback is for the handler's EPX credentials, recorded under the merchant registered with them
+	if custNbr := response.RawParams["CUST_NBR"]; custNbr != "" && custNbr != h.epxCustNbr {
+		h.logger.Error("Browser Post callback is for other EPX credentials",
+			zap.String("cust_nbr", custNbr),
+			zap.String("tran_nbr", response.TranNbr),
+		)
+		h.renderErrorPage(w, "Payment response could not be verified", "")
+		return
+	}
+	agent, err := h.merchant(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to resolve Browser Post merchant",
+			zap.Error(err),
+			zap.String("tran_nbr", response.TranNbr),
+		)
+		h.renderErrorPage(w, "Failed to process payment response", "")
+		return
+	}
 
-		if err == nil {
-			// Transaction already exists - this is a duplicate callback
-			h.logger.Info("Duplicate Browser Post callback detected",
-				zap.String("tran_nbr", response.TranNbr),
-				zap.String("existing_tx_id", existingTx.ID.String()),
-			)
-			// Still render success page with existing transaction
-			h.renderReceiptPage(w, response, existingTx.ID.String())
-			return
-		}
+	// Merchants with browser_post_mac_verification only accept callbacks signed with their MAC secret
+	if err := h.verifyResponseMAC(r.Context(), agent, params); err != nil {
+		h.logger.Error("Browser Post callback failed MAC verification",
+			zap.Error(err),
+			zap.String("tran_nbr", response.TranNbr),
+		)
+		h.renderErrorPage(w, "Payment response could not be verified", "")
+		return
 	}
 
-	// Store the transaction in database
+	// TRAN_NBR detects duplicates (as recommended in EPX docs page 8): the PRG (POST-REDIRECT-GET) pattern
+	// can deliver the same response more than once
+	if h.isSaveAndCharge(response.RawParams) {
+		// A save_and_charge STORAGE response charged nothing yet: save the card, then charge it
+		h.saveAndCharge(r.Context(), w, agent, response)
+		return
+	}
+
+	// A sale callback completes the pending transaction its form recorded under the TRAN_NBR
+	pending, err := h.pendingTransaction(r.Context(), agent.AgentID, response.TranNbr)
+	if err != nil {
+		// EPX may still have approved the payment. There is no row to keep the BRIC on, and the log masks it,
+		// so the TRAN_NBR is what finds the payment in EPX's reporting
+		h.logger.Error("No pending transaction for Browser Post callback",
+			zap.Error(err),
+			zap.String("tran_nbr", response.TranNbr),
+			zap.String("auth_guid", response.AuthGUID),
+			zap.Bool("is_approved", response.IsApproved),
+		)
+		h.renderErrorPage(w, callbackErrorMessage(err, response.TranNbr), "")
+		return
+	}
+
+	txID := pending.ID.String()
+	alreadyApplied, err := h.checkPendingCallback(pending, response)
+	if errors.Is(err, domain.ErrPendingTransactionExpired) {
+		h.expirePending(r.Context(), pending, response)
+	}
+	if err != nil {
+		h.renderErrorPage(w, callbackErrorMessage(err, response.TranNbr), "")
+		return
+	}
+	if alreadyApplied {
+		h.renderReceiptPage(w, response, txID)
+		return
+	}
+
+	// Record the result on the pending transaction
 	// We store AUTH_GUID (BRIC) even for guest checkouts because it's needed for:
 	// - Refunds (most common reason)
 	// - Voids/cancellations
 	// - Chargeback defense
 	// - Reconciliation with EPX settlement reports
-	txID, err := h.storeTransaction(r.Context(), response)
-	if err != nil {
+	if err := h.completeTransaction(r.Context(), pending.ID, response); err != nil {
 		h.logger.Error("Failed to store transaction",
 			zap.Error(err),
+			zap.String("transaction_id", txID),
 			zap.String("auth_guid", response.AuthGUID),
 		)
 		// Still show success to user if payment was approved, but log the error
-		if response.AuthResp == "00" {
-			h.renderReceiptPage(w, response, "")
+		if response.IsApproved {
+			h.renderReceiptPage(w, response, txID)
 			return
 		}
 		h.renderErrorPage(w, "Failed to record transaction", "")
@@ -237,10 +623,21 @@ func (h *BrowserPostCallbackHandler) HandleCallback(w http.ResponseWriter, r *ht
 		zap.String("auth_guid", response.AuthGUID),
 	)
 
+	// Nothing was collected, so the form's daily volume goes back to the merchant
+	if !response.IsApproved {
+		reservation := database.StoredDailyVolumeReservation(pending.AgentID, pending.DailyVolumeDate, pending.Amount)
+		if err := reservation.Release(r.Context(), h.dbAdapter.Queries()); err != nil {
+			h.logger.Warn("Failed to release daily volume",
+				zap.Error(err),
+				zap.String("transaction_id", txID),
+			)
+		}
+	}
+
 	// Check if user wants to save payment method (from USER_DATA fields)
 	// If yes and transaction approved, convert Financial BRIC to Storage BRIC
 	if response.IsApproved && h.shouldSavePaymentMethod(response.RawParams) {
-		if err := h.savePaymentMethod(r.Context(), response, txID); err != nil {
+		if _, err := h.savePaymentMethod(r.Context(), agent, response.RawParams["USER_DATA_2"], response, txID); err != nil {
 			h.logger.Error("Failed to save payment method",
 				zap.Error(err),
 				zap.String("transaction_id", txID),
@@ -253,86 +650,227 @@ func (h *BrowserPostCallbackHandler) HandleCallback(w http.ResponseWriter, r *ht
 	h.renderReceiptPage(w, response, txID)
 }
 
-// storeTransaction saves the transaction to the database
-// AUTH_GUID (BRIC) is stored for refunds, voids, disputes, and reconciliation
-func (h *BrowserPostCallbackHandler) storeTransaction(ctx context.Context, response *ports.BrowserPostResponse) (string, error) {
-	// Determine status from AUTH_RESP
-	// "00" = approved, others = failed/declined
-	status := "failed"
-	if response.IsApproved {
-		status = "completed"
+// verifyResponseMAC validates the callback's response MAC when the merchant has browser_post_mac_verification
+// enabled; other merchants rely on TAC and transaction state checks. With verification on, anything
+// that keeps the MAC from being checked (unreadable config, no secret manager, a missing or empty secret) rejects
+// the callback.
+func (h *BrowserPostCallbackHandler) verifyResponseMAC(ctx context.Context, agent *sqlc.AgentCredential, params map[string][]string) error {
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		return fmt.Errorf("failed to load merchant config: %w", err)
+	}
+	if !config.BrowserPostMACVerification {
+		return nil
 	}
 
-	// Determine transaction type (Browser Post is always charge, refunds go through Server Post)
-	txType := "charge"
+	if h.secretManager == nil {
+		return fmt.Errorf("no secret manager configured for MAC verification")
+	}
+	secret, err := h.secretManager.GetSecret(ctx, agent.MacSecretPath)
+	if err != nil {
+		return fmt.Errorf("failed to get MAC secret: %w", err)
+	}
+	if secret.Value == "" {
+		return fmt.Errorf("MAC secret at %s is empty", agent.MacSecretPath)
+	}
+	if err := h.browserPost.ValidateResponseMAC(params, secret.Value); err != nil {
+		if !h.verifiesWithPreviousMAC(ctx, agent, params) {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifiesWithPreviousMAC reports whether the callback is signed with the MAC the merchant's last rotation replaced,
+// kept at domain.PreviousMACPath and accepted for domain.PreviousMACGracePeriod after it was stored there
+func (h *BrowserPostCallbackHandler) verifiesWithPreviousMAC(ctx context.Context, agent *sqlc.AgentCredential, params map[string][]string) bool {
+	previous, err := h.secretManager.GetSecret(ctx, domain.PreviousMACPath(agent.MacSecretPath))
+	if err != nil || previous.Value == "" {
+		return false
+	}
+	rotatedAt, err := time.Parse(time.RFC3339, previous.CreatedAt)
+	if err != nil || !domain.AcceptsPreviousMAC(rotatedAt, h.now()) {
+		return false
+	}
+	if h.browserPost.ValidateResponseMAC(params, previous.Value) != nil {
+		return false
+	}
 
-	// Create transaction
-	txID := uuid.New()
-	groupID := uuid.New()
+	h.logger.Info("Callback verified with the previous MAC during its rotation grace period",
+		zap.String("agent_id", agent.AgentID),
+		zap.Time("rotated_at", rotatedAt),
+	)
+	return true
+}
 
-	// Parse amount to numeric
-	var amountNumeric pgtype.Numeric
-	if err := amountNumeric.Scan(response.Amount); err != nil {
-		return "", fmt.Errorf("invalid amount: %w", err)
+// parseTranNbr parses a callback's TRAN_NBR, which forms number per merchant
+func parseTranNbr(raw string) (int64, bool) {
+	tranNbr, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || tranNbr <= 0 {
+		return 0, false
 	}
+	return tranNbr, true
+}
 
-	// Get agent ID from raw params (CUST_NBR)
-	agentID := "unknown"
-	if custNbr, ok := response.RawParams["CUST_NBR"]; ok && custNbr != "" {
-		agentID = custNbr
+// pendingTransaction is the merchant's transaction recorded by the sale form with this TRAN_NBR
+func (h *BrowserPostCallbackHandler) pendingTransaction(ctx context.Context, agentID, rawTranNbr string) (*sqlc.Transaction, error) {
+	tranNbr, ok := parseTranNbr(rawTranNbr)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid TRAN_NBR %q", domain.ErrTransactionNotFound, rawTranNbr)
 	}
 
-	_, err := h.dbAdapter.Queries().CreateTransaction(ctx, sqlc.CreateTransactionParams{
-		ID:                txID,
-		GroupID:           groupID,
-		AgentID:           agentID,
-		CustomerID:        pgtype.Text{}, // No customer ID in Browser Post (guest checkout)
-		Amount:            amountNumeric,
-		Currency:          "USD",
-		Status:            status,
-		Type:              txType,
-		PaymentMethodType: "credit_card",
-		PaymentMethodID:   pgtype.UUID{}, // No saved payment method for Browser Post
-		AuthGuid: pgtype.Text{
-			String: response.AuthGUID,
-			Valid:  response.AuthGUID != "",
-		},
-		AuthResp: pgtype.Text{
-			String: response.AuthResp,
-			Valid:  response.AuthResp != "",
-		},
-		AuthCode: pgtype.Text{
-			String: response.AuthCode,
-			Valid:  response.AuthCode != "",
-		},
-		AuthRespText: pgtype.Text{
-			String: response.AuthRespText,
-			Valid:  response.AuthRespText != "",
-		},
-		AuthCardType: pgtype.Text{
-			String: response.AuthCardType,
-			Valid:  response.AuthCardType != "",
-		},
-		AuthAvs: pgtype.Text{
-			String: response.AuthAVS,
-			Valid:  response.AuthAVS != "",
-		},
-		AuthCvv2: pgtype.Text{
-			String: response.AuthCVV2,
-			Valid:  response.AuthCVV2 != "",
-		},
-		IdempotencyKey: pgtype.Text{
-			String: response.TranNbr,
-			Valid:  response.TranNbr != "",
-		},
-		Metadata: []byte("{}"),
+	tx, err := h.dbAdapter.Queries().GetTransactionByTranNbr(ctx, sqlc.GetTransactionByTranNbrParams{
+		AgentID:    agentID,
+		TranNbr:    tranNbr,
+		TranNbrKey: rawTranNbr,
 	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no form with TRAN_NBR %d", domain.ErrTransactionNotFound, tranNbr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return &tx, nil
+}
 
+// checkPendingCallback decides whether a callback may be applied to the transaction.
+// Returns alreadyApplied=true when the same EPX result was already recorded (browser refresh).
+func (h *BrowserPostCallbackHandler) checkPendingCallback(tx *sqlc.Transaction, resp *ports.BrowserPostResponse) (alreadyApplied bool, err error) {
+	if tx.Status != string(domain.TransactionStatusPending) {
+		if resp.AuthGUID != "" && tx.AuthGuid.String == resp.AuthGUID {
+			h.logger.Info("Duplicate Browser Post callback detected",
+				zap.String("transaction_id", tx.ID.String()),
+				zap.String("tran_nbr", resp.TranNbr),
+			)
+			return true, nil
+		}
+
+		// EPX may still have approved this payment. The row already holds another result and the log masks the
+		// BRIC, so the TRAN_NBR is what finds the payment in EPX's reporting
+		h.logger.Error("EPX callback for already processed transaction",
+			zap.String("transaction_id", tx.ID.String()),
+			zap.String("status", tx.Status),
+			zap.String("tran_nbr", resp.TranNbr),
+			zap.String("auth_guid", resp.AuthGUID),
+			zap.Bool("is_approved", resp.IsApproved),
+		)
+		return false, domain.ErrTransactionAlreadyProcessed
+	}
+
+	// A pending transaction that outlived its TAC can't be completed by a replayed or late callback
+
+	now := h.now()
+	window := h.expiry.Window()
+	age := now.Sub(tx.CreatedAt)
+
+	txn := domain.Transaction{Status: domain.TransactionStatusPending, CreatedAt: tx.CreatedAt}
+	if tx.PendingExpiresAt.Valid {
+		txn.PendingExpiresAt = &tx.PendingExpiresAt.Time
+	}
+	if txn.IsPendingExpired(now, window) {
+		// EPX may still have approved the payment; expirePending keeps its BRIC on the expired row
+		h.logger.Error("EPX callback arrived after pending transaction expired",
+			zap.String("transaction_id", tx.ID.String()),
+			zap.String("tran_nbr", resp.TranNbr),
+			zap.String("auth_guid", resp.AuthGUID),
+			zap.Bool("is_approved", resp.IsApproved),
+			zap.Duration("age", age),
+			zap.Duration("expiry_window", window),
+			zap.Timep("pending_expires_at", txn.PendingExpiresAt),
+		)
+		return false, domain.ErrPendingTransactionExpired
+	}
+
+	if age > h.expiry.TACValidityOrDefault() {
+		h.logger.Warn("Accepting late EPX callback within grace period",
+			zap.String("transaction_id", tx.ID.String()),
+			zap.String("tran_nbr", resp.TranNbr),
+			zap.Duration("age", age),
+		)
+	}
+
+	return false, nil
+}
+
+// expirePending moves a pending transaction whose callback came too late to expired, so it stops looking
+// like a payment in progress. The late callback's BRIC and response are kept on the row, so an approval
+// EPX made anyway can be found and voided.
+func (h *BrowserPostCallbackHandler) expirePending(ctx context.Context, tx *sqlc.Transaction, response *ports.BrowserPostResponse) {
+	err := h.dbAdapter.Queries().ExpirePendingTransaction(ctx, sqlc.ExpirePendingTransactionParams{
+		ID:           tx.ID,
+		AuthGuid:     pgtype.Text{String: response.AuthGUID, Valid: response.AuthGUID != ""},
+		AuthResp:     pgtype.Text{String: response.AuthResp, Valid: response.AuthResp != ""},
+		AuthRespText: pgtype.Text{String: response.AuthRespText, Valid: response.AuthRespText != ""},
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to create transaction: %w", err)
+		h.logger.Error("Failed to mark pending transaction expired",
+			zap.Error(err),
+			zap.String("transaction_id", tx.ID.String()),
+		)
 	}
+}
 
-	return txID.String(), nil
+// callbackErrorMessage maps callback validation errors to a customer-facing message
+func callbackErrorMessage(err error, tranNbr string) string {
+	switch {
+	case errors.Is(err, domain.ErrPendingTransactionExpired):
+		return fmt.Sprintf("Your payment session expired before the payment result was received. Please contact support with reference %s before trying again.", tranNbr)
+	case errors.Is(err, domain.ErrTransactionAlreadyProcessed):
+		return fmt.Sprintf("This payment was already processed (reference %s).", tranNbr)
+	case errors.Is(err, domain.ErrTransactionNotFound):
+		return fmt.Sprintf("We couldn't find the payment this result belongs to. Please contact support with reference %s before trying again.", tranNbr)
+	default:
+		return "Failed to process payment"
+	}
+}
+
+// completeTransaction records the callback's result on the form's pending transaction
+// AUTH_GUID (BRIC) is stored for refunds, voids, disputes, and reconciliation
+func (h *BrowserPostCallbackHandler) completeTransaction(ctx context.Context, id uuid.UUID, response *ports.BrowserPostResponse) error {
+	// Determine status from AUTH_RESP
+	// "00" = approved, others = failed/declined
+	status := domain.TransactionStatusFailed
+	if response.IsApproved {
+		status = domain.TransactionStatusCompleted
+	}
+
+	_, err := h.dbAdapter.Queries().CompletePendingTransaction(ctx, sqlc.CompletePendingTransactionParams{
+		ID:                id,
+		Status:            string(status),
+		PaymentMethodType: pgtype.Text{String: string(browserPostPaymentMethodType(response)), Valid: true},
+		CardFingerprint:   browserPostCardFingerprint(response),
+		LastFour:          browserPostLastFour(response),
+		AuthGuid:          pgtype.Text{String: response.AuthGUID, Valid: response.AuthGUID != ""},
+		AuthResp:          pgtype.Text{String: response.AuthResp, Valid: response.AuthResp != ""},
+		AuthCode:          pgtype.Text{String: response.AuthCode, Valid: response.AuthCode != ""},
+		AuthRespText:      pgtype.Text{String: response.AuthRespText, Valid: response.AuthRespText != ""},
+		AuthCardType:      pgtype.Text{String: response.AuthCardType, Valid: response.AuthCardType != ""},
+		AuthAvs:           pgtype.Text{String: response.AuthAVS, Valid: response.AuthAVS != ""},
+		AuthCvv2:          pgtype.Text{String: response.AuthCVV2, Valid: response.AuthCVV2 != ""},
+		CardFundingType:   browserPostCardFundingType(response),
+		Metadata:          []byte("{}"),
+		TranNbr:           tranNbrParam(response.TranNbr),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A concurrent callback completed it first
+		return domain.ErrTransactionAlreadyProcessed
+	}
+	if err != nil {
+		return fmt.Errorf("failed to complete transaction: %w", err)
+	}
+	return nil
+}
+
+// browserPostCardFundingType is the callback's funding indicator as stored (NULL when EPX didn't return a known one)
+func browserPostCardFundingType(response *ports.BrowserPostResponse) pgtype.Text {
+	fundingType := domain.ParseCardFundingType(response.CardFundingType)
+	return pgtype.Text{String: string(fundingType), Valid: fundingType != ""}
+}
+
+// tranNbrParam is a callback's TRAN_NBR as stored (NULL when it isn't a form's number)
+func tranNbrParam(raw string) pgtype.Int8 {
+	tranNbr, ok := parseTranNbr(raw)
+	return pgtype.Int8{Int64: tranNbr, Valid: ok}
 }
 
 // renderReceiptPage renders an HTML receipt page to the user
@@ -407,37 +945,243 @@ func (h *BrowserPostCallbackHandler) shouldSavePaymentMethod(rawParams map[strin
 	return false
 }
 
-// savePaymentMethod converts the Financial BRIC to a Storage BRIC and saves it
-func (h *BrowserPostCallbackHandler) savePaymentMethod(ctx context.Context, response *ports.BrowserPostResponse, txID string) error {
-	// Extract customer_id from USER_DATA_2
-	customerID, ok := response.RawParams["USER_DATA_2"]
-	if !ok || customerID == "" {
-		return fmt.Errorf("customer_id not provided in USER_DATA_2")
+// isSaveAndCharge checks if the callback answers a save_and_charge form (USER_DATA_1 "save_and_charge=true")
+func (h *BrowserPostCallbackHandler) isSaveAndCharge(rawParams map[string]string) bool {
+	return strings.EqualFold(rawParams["USER_DATA_1"], "save_and_charge=true")
+}
+
+// saveAndCharge handles the callback of a save_and_charge form, whose STORAGE response tokenized the card without
+// charging it: the card is saved to the form's customer and charged the form's amount. Both come from the charge
+// intent the form recorded under its TRAN_NBR, not from USER_DATA_2 and AMOUNT, which pass through the browser.
+// A callback without an intent is never charged.
+func (h *BrowserPostCallbackHandler) saveAndCharge(ctx context.Context, w http.ResponseWriter, agent *sqlc.AgentCredential, response *ports.BrowserPostResponse) {
+	intent, err := h.chargeIntent(ctx, agent.AgentID, response.TranNbr)
+	if err != nil {
+		// EPX tokenized a card nobody will be charged for; the log masks the BRIC, so support finds it by TRAN_NBR
+		h.logger.Error("No charge intent for save_and_charge callback",
+			zap.Error(err),
+			zap.String("tran_nbr", response.TranNbr),
+			zap.String("auth_guid", response.AuthGUID),
+		)
+		h.renderErrorPage(w, callbackErrorMessage(err, response.TranNbr), "")
+		return
+	}
+
+	// A repeated callback shows what the first one did instead of saving and charging again
+	if intent.Status != chargeIntentPending {
+		h.renderChargeIntentResult(ctx, w, intent, response)
+		return
+	}
+
+	if h.now().After(intent.ExpiresAt) {
+		h.logger.Error("save_and_charge callback arrived after the form expired",
+			zap.String("tran_nbr", response.TranNbr),
+			zap.String("auth_guid", response.AuthGUID),
+			zap.Time("expires_at", intent.ExpiresAt),
+		)
+		h.renderErrorPage(w, callbackErrorMessage(domain.ErrPendingTransactionExpired, response.TranNbr), "")
+		return
+	}
+
+	if !response.IsApproved {
+		h.logger.Info("Browser Post storage declined; nothing saved or charged",
+			zap.String("tran_nbr", response.TranNbr),
+			zap.String("auth_resp", response.AuthResp),
+		)
+		h.renderReceiptPage(w, response, "")
+		return
+	}
+
+	claimed, err := h.dbAdapter.Queries().ClaimBrowserPostChargeIntent(ctx, intent.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.renderErrorPage(w, callbackErrorMessage(domain.ErrTransactionAlreadyProcessed, response.TranNbr), "")
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to claim save_and_charge intent",
+			zap.Error(err),
+			zap.String("tran_nbr", response.TranNbr),
+		)
+		h.renderErrorPage(w, "Failed to process payment", "")
+		return
+	}
+
+	amount := decimal.NewFromBigInt(claimed.Amount.Int, claimed.Amount.Exp).StringFixed(2)
+	pm, tx, err := h.saveAndChargeCard(ctx, agent, claimed.CustomerID, amount, "browser-post:"+response.TranNbr, response)
+
+	finish := sqlc.FinishBrowserPostChargeIntentParams{ID: claimed.ID, Status: chargeIntentFailed}
+	if pm != nil {
+		finish.PaymentMethodID = pgUUID(pm.ID)
+	}
+	if tx != nil {
+		finish.Status = chargeIntentCompleted
+		finish.TransactionID = pgUUID(tx.ID)
+	}
+	if ferr := h.dbAdapter.Queries().FinishBrowserPostChargeIntent(ctx, finish); ferr != nil {
+		h.logger.Error("Failed to record save_and_charge result",
+			zap.Error(ferr),
+			zap.String("tran_nbr", response.TranNbr),
+			zap.String("status", finish.Status),
+		)
+	}
+
+	h.renderSaveAndChargeResult(w, response, pm, tx, err)
+}
+
+// saveAndChargeCard saves the tokenized card to the customer and charges it amount with a sale. The payment
+// method is returned even when the sale fails.
+func (h *BrowserPostCallbackHandler) saveAndChargeCard(ctx context.Context, agent *sqlc.AgentCredential, customerID, amount, idempotencyKey string, response *ports.BrowserPostResponse) (*domain.PaymentMethod, *domain.Transaction, error) {
+	pm, err := h.savePaymentMethod(ctx, agent, customerID, response, "")
+	if err != nil {
+		h.logger.Error("Failed to save payment method for save_and_charge",
+			zap.Error(err),
+			zap.String("tran_nbr", response.TranNbr),
+		)
+		return nil, nil, err
 	}
 
-	// Extract agent_id from CUST_NBR
-	agentID, ok := response.RawParams["CUST_NBR"]
-	if !ok || agentID == "" {
-		return fmt.Errorf("agent_id not found in CUST_NBR")
+	tx, err := h.saleSvc.Sale(ctx, &serviceports.SaleRequest{
+		AgentID:         pm.AgentID,
+		CustomerID:      &pm.CustomerID,
+		Amount:          amount,
+		Currency:        "USD",
+		PaymentMethodID: &pm.ID,
+		IdempotencyKey:  &idempotencyKey,
+	})
+	if err != nil {
+		h.logger.Error("Failed to charge saved payment method for save_and_charge",
+			zap.Error(err),
+			zap.String("payment_method_id", pm.ID),
+			zap.String("tran_nbr", response.TranNbr),
+		)
+		return pm, nil, err
 	}
 
-	// Determine payment type
-	paymentType := "credit_card" // Browser Post is typically credit card
-	// Note: If we support ACH through Browser Post, we'd need to detect it here
+	h.logger.Info("Browser Post save_and_charge completed",
+		zap.String("payment_method_id", pm.ID),
+		zap.String("transaction_id", tx.ID),
+		zap.String("status", string(tx.Status)),
+	)
+	return pm, tx, nil
+}
 
-	// Extract last four digits from card number
+// renderSaveAndChargeResult shows the sale's receipt, not the storage's
+func (h *BrowserPostCallbackHandler) renderSaveAndChargeResult(w http.ResponseWriter, response *ports.BrowserPostResponse, pm *domain.PaymentMethod, tx *domain.Transaction, err error) {
+	switch {
+	case pm == nil:
+		h.renderErrorPage(w, "Failed to save payment method", "")
+	case err != nil:
+		h.renderErrorPage(w, "Your card was saved but the payment could not be processed", "")
+	default:
+		receipt := *response
+		receipt.IsApproved = tx.IsApproved()
+		receipt.AuthResp = stringPtrToString(tx.AuthResp)
+		receipt.AuthCode = stringPtrToString(tx.AuthCode)
+		receipt.AuthRespText = stringPtrToString(tx.AuthRespText)
+		h.renderReceiptPage(w, &receipt, tx.ID)
+	}
+}
+
+// renderChargeIntentResult shows a repeated save_and_charge callback the sale its form already made
+func (h *BrowserPostCallbackHandler) renderChargeIntentResult(ctx context.Context, w http.ResponseWriter, intent *sqlc.BrowserPostChargeIntent, response *ports.BrowserPostResponse) {
+	h.logger.Info("Duplicate Browser Post callback detected",
+		zap.String("tran_nbr", response.TranNbr),
+		zap.String("status", intent.Status),
+	)
+	if intent.Status != chargeIntentCompleted || !intent.TransactionID.Valid {
+		h.renderErrorPage(w, callbackErrorMessage(domain.ErrTransactionAlreadyProcessed, response.TranNbr), "")
+		return
+	}
+
+	tx, err := h.dbAdapter.Queries().GetTransactionByID(ctx, intent.TransactionID.Bytes)
+	if err != nil {
+		h.logger.Error("Failed to get save_and_charge sale",
+			zap.Error(err),
+			zap.String("tran_nbr", response.TranNbr),
+		)
+		h.renderErrorPage(w, callbackErrorMessage(domain.ErrTransactionAlreadyProcessed, response.TranNbr), "")
+		return
+	}
+	h.renderStoredReceipt(w, response, &tx)
+}
+
+// renderStoredReceipt renders the receipt of a recorded sale
+func (h *BrowserPostCallbackHandler) renderStoredReceipt(w http.ResponseWriter, response *ports.BrowserPostResponse, tx *sqlc.Transaction) {
+	receipt := *response
+	receipt.IsApproved = tx.Status == string(domain.TransactionStatusCompleted)
+	receipt.AuthResp = tx.AuthResp.String
+	receipt.AuthCode = tx.AuthCode.String
+	receipt.AuthRespText = tx.AuthRespText.String
+	h.renderReceiptPage(w, &receipt, tx.ID.String())
+}
+
+// chargeIntent is the merchant's save_and_charge form with this TRAN_NBR
+func (h *BrowserPostCallbackHandler) chargeIntent(ctx context.Context, agentID, rawTranNbr string) (*sqlc.BrowserPostChargeIntent, error) {
+	tranNbr, ok := parseTranNbr(rawTranNbr)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid TRAN_NBR %q", domain.ErrTransactionNotFound, rawTranNbr)
+	}
+
+	intent, err := h.dbAdapter.Queries().GetBrowserPostChargeIntent(ctx, sqlc.GetBrowserPostChargeIntentParams{
+		AgentID: agentID,
+		TranNbr: tranNbr,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no save_and_charge form with TRAN_NBR %d", domain.ErrTransactionNotFound, tranNbr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get charge intent: %w", err)
+	}
+	return &intent, nil
+}
+
+// pgUUID is a domain ID as a nullable UUID (NULL when it isn't one)
+func pgUUID(id string) pgtype.UUID {
+	parsed, err := uuid.Parse(id)
+	return pgtype.UUID{Bytes: parsed, Valid: err == nil}
+}
+
+// savePaymentMethod converts the Financial BRIC to a Storage BRIC and saves it for the merchant's customer
+func (h *BrowserPostCallbackHandler) savePaymentMethod(ctx context.Context, agent *sqlc.AgentCredential, customerID string, response *ports.BrowserPostResponse, txID string) (*domain.PaymentMethod, error) {
+	if customerID == "" {
+		return nil, fmt.Errorf("customer_id not provided in USER_DATA_2")
+	}
+	if err := h.resolveCustomer(ctx, agent, customerID); err != nil {
+		return nil, err
+	}
+
+	// Determine payment type: a bank account tokenized through Browser Post is saved as ACH
+	paymentType := domain.PaymentMethodTypeCreditCard
+	numberField := "CARD_NBR"
+	var bankName, accountType *string
+	if achType, isACH := browserPostACHAccountType(response.RawParams); isACH {
+		paymentType = domain.PaymentMethodTypeACH
+		numberField = "ACCOUNT_NBR"
+		accountType = &achType
+
+		name := response.RawParams["BANK_NAME"]
+		if name == "" && response.RawParams["ROUTING_NBR"] != "" {
+			name = "Routing " + response.RawParams["ROUTING_NBR"]
+		}
+		if name == "" {
+			return nil, fmt.Errorf("bank name or routing number not provided for ACH account")
+		}
+		bankName = &name
+	}
+
+	// Extract last four digits from the card or account number
 	lastFour := ""
-	if cardNbr, ok := response.RawParams["CARD_NBR"]; ok && len(cardNbr) >= 4 {
-		lastFour = cardNbr[len(cardNbr)-4:]
+	if number, ok := response.RawParams[numberField]; ok && len(number) >= 4 {
+		lastFour = number[len(number)-4:]
 	}
 
 	if lastFour == "" {
-		return fmt.Errorf("unable to extract last four digits from card number")
+		return nil, fmt.Errorf("unable to extract last four digits from %s", numberField)
 	}
 
 	// Extract card expiration (YYMM format)
 	var cardExpMonth, cardExpYear *int
-	if expDate, ok := response.RawParams["EXP_DATE"]; ok && len(expDate) == 4 {
+	if expDate, ok := response.RawParams["EXP_DATE"]; ok && len(expDate) == 4 && paymentType == domain.PaymentMethodTypeCreditCard {
 		// Parse YYMM format
 		year := expDate[0:2]
 		month := expDate[2:4]
@@ -454,7 +1198,7 @@ func (h *BrowserPostCallbackHandler) savePaymentMethod(ctx context.Context, resp
 
 	// Extract card brand from AUTH_CARD_TYPE
 	var cardBrand *string
-	if response.AuthCardType != "" {
+	if response.AuthCardType != "" && paymentType == domain.PaymentMethodTypeCreditCard {
 		brand := getCardTypeName(response.AuthCardType)
 		cardBrand = &brand
 	}
@@ -469,15 +1213,17 @@ func (h *BrowserPostCallbackHandler) savePaymentMethod(ctx context.Context, resp
 
 	// Build ConvertFinancialBRICRequest
 	req := &serviceports.ConvertFinancialBRICRequest{
-		AgentID:       agentID,
+		AgentID:       agent.AgentID,
 		CustomerID:    customerID,
 		FinancialBRIC: response.AuthGUID,
-		PaymentType:   domain.PaymentMethodType(paymentType),
+		PaymentType:   paymentType,
 		TransactionID: txID,
 		LastFour:      lastFour,
 		CardBrand:     cardBrand,
 		CardExpMonth:  cardExpMonth,
 		CardExpYear:   cardExpYear,
+		BankName:      bankName,
+		AccountType:   accountType,
 		IsDefault:     false, // Don't auto-set as default
 		FirstName:     firstName,
 		LastName:      lastName,
@@ -488,18 +1234,81 @@ func (h *BrowserPostCallbackHandler) savePaymentMethod(ctx context.Context, resp
 	}
 
 	// Call payment method service to convert Financial BRIC to Storage BRIC
-	_, err := h.paymentMethodSvc.ConvertFinancialBRICToStorageBRIC(ctx, req)
+	pm, err := h.paymentMethodSvc.ConvertFinancialBRICToStorageBRIC(ctx, req)
 	if err != nil {
-		return fmt.Errorf("failed to convert Financial BRIC to Storage BRIC: %w", err)
+		return nil, fmt.Errorf("failed to convert Financial BRIC to Storage BRIC: %w", err)
 	}
 
 	h.logger.Info("Payment method saved successfully",
 		zap.String("customer_id", customerID),
-		zap.String("agent_id", agentID),
+		zap.String("agent_id", agent.AgentID),
+		zap.String("payment_type", string(paymentType)),
 		zap.String("last_four", lastFour),
 	)
 
-	return nil
+	return pm, nil
+}
+
+// browserPostPaymentMethodType is the payment method type a Browser Post response was paid with
+func browserPostPaymentMethodType(response *ports.BrowserPostResponse) domain.PaymentMethodType {
+	if _, isACH := browserPostACHAccountType(response.RawParams); isACH {
+		return domain.PaymentMethodTypeACH
+	}
+	return domain.PaymentMethodTypeCreditCard
+}
+
+// browserPostCardFingerprint identifies the callback's card for velocity limits by the last four digits of its
+// masked CARD_NBR and its EXP_DATE (YYMM), like a saved copy of the card (NULL for bank accounts)
+func browserPostCardFingerprint(response *ports.BrowserPostResponse) pgtype.Text {
+	if browserPostPaymentMethodType(response) != domain.PaymentMethodTypeCreditCard {
+		return pgtype.Text{}
+	}
+	lastFour := response.RawParams["CARD_NBR"]
+	if len(lastFour) >= 4 {
+		lastFour = lastFour[len(lastFour)-4:]
+	}
+	var expYear, expMonth int
+	if expDate := response.RawParams["EXP_DATE"]; len(expDate) == 4 {
+		expYear, _ = strconv.Atoi(expDate[0:2])
+		expMonth, _ = strconv.Atoi(expDate[2:4])
+	}
+	fingerprint := domain.CardFingerprint(lastFour, expMonth, expYear, response.AuthGUID)
+	return pgtype.Text{String: fingerprint, Valid: fingerprint != ""}
+}
+
+// browserPostLastFour is the last four digits of the callback's masked CARD_NBR, or ACCOUNT_NBR for a bank
+// account (NULL when the callback has none)
+func browserPostLastFour(response *ports.BrowserPostResponse) pgtype.Text {
+	numberField := "CARD_NBR"
+	if browserPostPaymentMethodType(response) != domain.PaymentMethodTypeCreditCard {
+		numberField = "ACCOUNT_NBR"
+	}
+	number := response.RawParams[numberField]
+	if len(number) < 4 {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: number[len(number)-4:], Valid: true}
+}
+
+// browserPostACHAccountType reports whether a Browser Post response is for a bank account rather than a card
+// (an ACH TRAN_TYPE such as CKC8, or a ROUTING_NBR) and the account type, "checking" or "savings".
+// ACCOUNT_TYPE ("C"/"S" or spelled out) wins; otherwise savings TRAN_TYPEs (CKS*) are savings and the rest checking.
+func browserPostACHAccountType(rawParams map[string]string) (string, bool) {
+	tranType := strings.ToUpper(rawParams["TRAN_TYPE"])
+	if !strings.HasPrefix(tranType, "CK") && rawParams["ROUTING_NBR"] == "" {
+		return "", false
+	}
+
+	switch strings.ToUpper(rawParams["ACCOUNT_TYPE"]) {
+	case "S", "SAVINGS":
+		return "savings", true
+	case "C", "CHECKING":
+		return "checking", true
+	}
+	if strings.HasPrefix(tranType, "CKS") {
+		return "savings", true
+	}
+	return "checking", true
 }
 
 // getStringPtr returns a pointer to the string value if it exists in the map
@@ -0,0 +1,1110 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kevin07696/payment-service/internal/adapters/epx"
+	"github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	serviceports "github.com/kevin07696/payment-service/internal/services/ports"
+	"github.com/kevin07696/payment-service/pkg/security"
+)
+
+// fakeBrowserPostStore is the handler's database: the merchants registered with EPX credentials and the
+// transactions recorded for them
+type fakeBrowserPostStore struct {
+	sqlc.Querier
+	agents       []sqlc.AgentCredential
+	transactions []sqlc.Transaction
+	intents      []sqlc.BrowserPostChargeIntent
+	tranNbrs     map[uuid.UUID]sqlc.EpxTranNbr
+	lastTranNbr  map[string]int64
+	customers    []sqlc.Customer
+	flags        []sqlc.FeatureFlag
+	volume       map[string]decimal.Decimal
+}
+
+func newFakeBrowserPostStore(agents ...sqlc.AgentCredential) *fakeBrowserPostStore {
+	return &fakeBrowserPostStore{agents: agents, tranNbrs: map[uuid.UUID]sqlc.EpxTranNbr{}, lastTranNbr: map[string]int64{}}
+}
+
+func (f *fakeBrowserPostStore) Queries() sqlc.Querier { return f }
+
+func (f *fakeBrowserPostStore) ListAgentsByEPXCredentials(ctx context.Context, arg sqlc.ListAgentsByEPXCredentialsParams) ([]sqlc.AgentCredential, error) {
+	var matched []sqlc.AgentCredential
+	for _, agent := range f.agents {
+		if agent.CustNbr == arg.CustNbr && agent.MerchNbr == arg.MerchNbr && agent.DbaNbr == arg.DbaNbr && agent.TerminalNbr == arg.TerminalNbr {
+			matched = append(matched, agent)
+		}
+	}
+	return matched, nil
+}
+
+func (f *fakeBrowserPostStore) GetTransactionByTranNbr(ctx context.Context, arg sqlc.GetTransactionByTranNbrParams) (sqlc.Transaction, error) {
+	for _, tx := range f.transactions {
+		if tx.AgentID == arg.AgentID && tx.TranNbr == (pgtype.Int8{Int64: arg.TranNbr, Valid: true}) {
+			return tx, nil
+		}
+	}
+	return sqlc.Transaction{}, pgx.ErrNoRows
+}
+
+func (f *fakeBrowserPostStore) CreateTransaction(ctx context.Context, arg sqlc.CreateTransactionParams) (sqlc.Transaction, error) {
+	tx := sqlc.Transaction{
+		ID:                arg.ID,
+		GroupID:           arg.GroupID,
+		AgentID:           arg.AgentID,
+		CustomerID:        arg.CustomerID,
+		Amount:            arg.Amount,
+		Currency:          arg.Currency,
+		Status:            arg.Status,
+		Type:              arg.Type,
+		PaymentMethodType: arg.PaymentMethodType,
+		AuthGuid:          arg.AuthGuid,
+		AuthResp:          arg.AuthResp,
+		IdempotencyKey:    arg.IdempotencyKey,
+		Metadata:          arg.Metadata,
+		TranNbr:           arg.TranNbr,
+		PendingExpiresAt:  arg.PendingExpiresAt,
+		DailyVolumeDate:   arg.DailyVolumeDate,
+		CreatedAt:         time.Now(),
+	}
+	f.transactions = append(f.transactions, tx)
+	return tx, nil
+}
+
+func (f *fakeBrowserPostStore) GetTranNbr(ctx context.Context, transactionID uuid.UUID) (sqlc.EpxTranNbr, error) {
+	assigned, ok := f.tranNbrs[transactionID]
+	if !ok {
+		return sqlc.EpxTranNbr{}, pgx.ErrNoRows
+	}
+	return assigned, nil
+}
+
+func (f *fakeBrowserPostStore) NextTranNbr(ctx context.Context, agentID string) (int64, error) {
+	f.lastTranNbr[agentID]++
+	return f.lastTranNbr[agentID], nil
+}
+
+func (f *fakeBrowserPostStore) AssignTranNbr(ctx context.Context, arg sqlc.AssignTranNbrParams) (sqlc.EpxTranNbr, error) {
+	assigned := sqlc.EpxTranNbr{TransactionID: arg.TransactionID, AgentID: arg.AgentID, TranNbr: arg.TranNbr}
+	f.tranNbrs[arg.TransactionID] = assigned
+	return assigned, nil
+}
+
+func (f *fakeBrowserPostStore) CompletePendingTransaction(ctx context.Context, arg sqlc.CompletePendingTransactionParams) (sqlc.Transaction, error) {
+	for i, tx := range f.transactions {
+		if tx.ID != arg.ID || tx.Status != string(domain.TransactionStatusPending) {
+			continue
+		}
+		tx.Status = arg.Status
+		tx.AuthGuid = arg.AuthGuid
+		tx.AuthResp = arg.AuthResp
+		tx.CardFundingType = arg.CardFundingType
+		tx.TranNbr = arg.TranNbr
+		if arg.PaymentMethodType.Valid {
+			tx.PaymentMethodType = arg.PaymentMethodType.String
+		}
+		if arg.CardFingerprint.Valid {
+			tx.CardFingerprint = arg.CardFingerprint
+		}
+		if arg.LastFour.Valid {
+			tx.LastFour = arg.LastFour
+		}
+		f.transactions[i] = tx
+		return tx, nil
+	}
+	return sqlc.Transaction{}, pgx.ErrNoRows
+}
+
+func (f *fakeBrowserPostStore) ExpirePendingTransaction(ctx context.Context, arg sqlc.ExpirePendingTransactionParams) error {
+	for i, tx := range f.transactions {
+		if tx.ID == arg.ID && tx.Status == string(domain.TransactionStatusPending) {
+			f.transactions[i].Status = string(domain.TransactionStatusExpired)
+			f.transactions[i].AuthGuid = arg.AuthGuid
+			f.transactions[i].AuthResp = arg.AuthResp
+			f.transactions[i].AuthRespText = arg.AuthRespText
+		}
+	}
+	return nil
+}
+
+func (f *fakeBrowserPostStore) GetTransactionByID(ctx context.Context, id uuid.UUID) (sqlc.Transaction, error) {
+	for _, tx := range f.transactions {
+		if tx.ID == id {
+			return tx, nil
+		}
+	}
+	return sqlc.Transaction{}, pgx.ErrNoRows
+}
+
+func (f *fakeBrowserPostStore) GetTransactionByIdempotencyKey(ctx context.Context, arg sqlc.GetTransactionByIdempotencyKeyParams) (sqlc.Transaction, error) {
+	for _, tx := range f.transactions {
+		if tx.AgentID == arg.AgentID && tx.IdempotencyKey == arg.IdempotencyKey {
+			return tx, nil
+		}
+	}
+	return sqlc.Transaction{}, pgx.ErrNoRows
+}
+
+func (f *fakeBrowserPostStore) CreateBrowserPostChargeIntent(ctx context.Context, arg sqlc.CreateBrowserPostChargeIntentParams) (sqlc.BrowserPostChargeIntent, error) {
+	intent := sqlc.BrowserPostChargeIntent{
+		ID:         arg.ID,
+		AgentID:    arg.AgentID,
+		TranNbr:    arg.TranNbr,
+		CustomerID: arg.CustomerID,
+		Amount:     arg.Amount,
+		Currency:   arg.Currency,
+		Status:     "pending",
+		ExpiresAt:  arg.ExpiresAt,
+	}
+	f.intents = append(f.intents, intent)
+	return intent, nil
+}
+
+func (f *fakeBrowserPostStore) GetBrowserPostChargeIntent(ctx context.Context, arg sqlc.GetBrowserPostChargeIntentParams) (sqlc.BrowserPostChargeIntent, error) {
+	for _, intent := range f.intents {
+		if intent.AgentID == arg.AgentID && intent.TranNbr == arg.TranNbr {
+			return intent, nil
+		}
+	}
+	return sqlc.BrowserPostChargeIntent{}, pgx.ErrNoRows
+}
+
+func (f *fakeBrowserPostStore) ClaimBrowserPostChargeIntent(ctx context.Context, id uuid.UUID) (sqlc.BrowserPostChargeIntent, error) {
+	for i, intent := range f.intents {
+		if intent.ID == id && intent.Status == "pending" {
+			f.intents[i].Status = "processing"
+			return f.intents[i], nil
+		}
+	}
+	return sqlc.BrowserPostChargeIntent{}, pgx.ErrNoRows
+}
+
+func (f *fakeBrowserPostStore) FinishBrowserPostChargeIntent(ctx context.Context, arg sqlc.FinishBrowserPostChargeIntentParams) error {
+	for i, intent := range f.intents {
+		if intent.ID == arg.ID {
+			f.intents[i].Status = arg.Status
+			f.intents[i].PaymentMethodID = arg.PaymentMethodID
+			f.intents[i].TransactionID = arg.TransactionID
+		}
+	}
+	return nil
+}
+
+func (f *fakeBrowserPostStore) GetCustomer(ctx context.Context, arg sqlc.GetCustomerParams) (sqlc.Customer, error) {
+	for _, customer := range f.customers {
+		if customer.AgentID == arg.AgentID && customer.CustomerID == arg.CustomerID {
+			return customer, nil
+		}
+	}
+	return sqlc.Customer{}, pgx.ErrNoRows
+}
+
+func (f *fakeBrowserPostStore) CreateCustomer(ctx context.Context, arg sqlc.CreateCustomerParams) (sqlc.Customer, error) {
+	customer := sqlc.Customer{ID: uuid.New(), AgentID: arg.AgentID, CustomerID: arg.CustomerID, AutoCreated: arg.AutoCreated}
+	f.customers = append(f.customers, customer)
+	return customer, nil
+}
+
+func (f *fakeBrowserPostStore) GetFeatureFlagsForAgent(ctx context.Context, arg sqlc.GetFeatureFlagsForAgentParams) ([]sqlc.FeatureFlag, error) {
+	var matched []sqlc.FeatureFlag
+	for _, flag := range f.flags {
+		if flag.Name == arg.Name && (!flag.AgentID.Valid || flag.AgentID == arg.AgentID) {
+			matched = append(matched, flag)
+		}
+	}
+	return matched, nil
+}
+
+// pendingSale records the merchant's pending sale form with the TRAN_NBR, as GetPaymentForm does
+// ReserveDailyVolume and ReleaseDailyVolume keep each merchant's volume for the day the test runs in
+func (f *fakeBrowserPostStore) ReserveDailyVolume(ctx context.Context, arg sqlc.ReserveDailyVolumeParams) (pgtype.Numeric, error) {
+	next := f.volume[arg.AgentID].Add(decimal.NewFromBigInt(arg.Amount.Int, arg.Amount.Exp))
+	if next.GreaterThan(decimal.NewFromBigInt(arg.DailyLimit.Int, arg.DailyLimit.Exp)) {
+		return pgtype.Numeric{}, pgx.ErrNoRows
+	}
+	if f.volume == nil {
+		f.volume = map[string]decimal.Decimal{}
+	}
+	f.volume[arg.AgentID] = next
+	return pgtype.Numeric{Int: next.Coefficient(), Exp: next.Exponent(), Valid: true}, nil
+}
+
+func (f *fakeBrowserPostStore) ReleaseDailyVolume(ctx context.Context, arg sqlc.ReleaseDailyVolumeParams) error {
+	f.volume[arg.AgentID] = f.volume[arg.AgentID].Sub(decimal.NewFromBigInt(arg.Amount.Int, arg.Amount.Exp))
+	return nil
+}
+
+func (f *fakeBrowserPostStore) pendingSale(agentID string, tranNbr int64, createdAt time.Time) sqlc.Transaction {
+	tx := sqlc.Transaction{
+		ID:                uuid.New(),
+		AgentID:           agentID,
+		Status:            string(domain.TransactionStatusPending),
+		Type:              string(domain.TransactionTypeCharge),
+		PaymentMethodType: string(domain.PaymentMethodTypeCreditCard),
+		TranNbr:           pgtype.Int8{Int64: tranNbr, Valid: true},
+		CreatedAt:         createdAt,
+	}
+	f.transactions = append(f.transactions, tx)
+	return tx
+}
+
+// browserPostMerchant is a merchant registered with the sandbox EPX credentials the tests configure handlers with
+func browserPostMerchant(agentID, overrides string) sqlc.AgentCredential {
+	return sqlc.AgentCredential{
+		AgentID:         agentID,
+		MacSecretPath:   "agents/" + agentID + "/mac",
+		CustNbr:         "9001",
+		MerchNbr:        "900300",
+		DbaNbr:          "2",
+		TerminalNbr:     "77",
+		Environment:     "sandbox",
+		IsActive:        pgtype.Bool{Bool: true, Valid: true},
+		Tier:            string(domain.MerchantTierStandard),
+		ConfigOverrides: json.RawMessage(overrides),
+		Status:          "active",
+	}
+}
+
+// stubRedirectAdapter returns a fixed EPX redirect response
+type stubRedirectAdapter struct {
+	mockBrowserPostAdapter
+	response *ports.BrowserPostResponse
+}
+
+func (s *stubRedirectAdapter) ParseRedirectResponse(params map[string][]string) (*ports.BrowserPostResponse, error) {
+	return s.response, nil
+}
+
+// recordingPaymentMethodService saves every converted BRIC as a new payment method
+type recordingPaymentMethodService struct {
+	saved []*serviceports.ConvertFinancialBRICRequest
+}
+
+func (r *recordingPaymentMethodService) ConvertFinancialBRICToStorageBRIC(ctx context.Context, req *serviceports.ConvertFinancialBRICRequest) (*domain.PaymentMethod, error) {
+	r.saved = append(r.saved, req)
+	return &domain.PaymentMethod{
+		ID:          "5b0b2a5e-4b8f-4d0a-9d42-0c3c5d3f8e11",
+		AgentID:     req.AgentID,
+		CustomerID:  req.CustomerID,
+		PaymentType: req.PaymentType,
+		LastFour:    req.LastFour,
+	}, nil
+}
+
+// recordingSaleService approves every sale
+type recordingSaleService struct {
+	sales []*serviceports.SaleRequest
+}
+
+func (r *recordingSaleService) Sale(ctx context.Context, req *serviceports.SaleRequest) (*domain.Transaction, error) {
+	r.sales = append(r.sales, req)
+	authResp, authCode := "00", "057579"
+	return &domain.Transaction{
+		ID:              "c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90",
+		AgentID:         req.AgentID,
+		CustomerID:      req.CustomerID,
+		Status:          domain.TransactionStatusCompleted,
+		Type:            domain.TransactionTypeCharge,
+		PaymentMethodID: req.PaymentMethodID,
+		AuthResp:        &authResp,
+		AuthCode:        &authCode,
+	}, nil
+}
+
+func newSaveAndChargeHandler(store *fakeBrowserPostStore, secrets ports.SecretManagerAdapter, response *ports.BrowserPostResponse) (*BrowserPostCallbackHandler, *recordingPaymentMethodService, *recordingSaleService) {
+	methods := &recordingPaymentMethodService{}
+	sales := &recordingSaleService{}
+	handler := NewBrowserPostCallbackHandler(
+		store,
+		&stubRedirectAdapter{response: response},
+		methods,
+		sales,
+		secrets,
+		zap.NewNop(),
+		"https://secure.epxuap.com/browserpost",
+		"9001",
+		"900300",
+		"2",
+		"77",
+		"http://localhost:8081",
+		domain.EnvironmentSandbox,
+		nil,
+		PendingExpiryConfig{},
+	)
+	return handler, methods, sales
+}
+
+func storageResponse(approved bool) *ports.BrowserPostResponse {
+	authResp := "00"
+	if !approved {
+		authResp = "05"
+	}
+	return &ports.BrowserPostResponse{
+		AuthGUID:     "0A1MQQ3K2XTBVW8Y0Z1",
+		AuthResp:     authResp,
+		AuthCardType: "V",
+		Amount:       "42.50",
+		TranNbr:      "12345678",
+		IsApproved:   approved,
+		RawParams: map[string]string{
+			"CUST_NBR":    "9001",
+			"CARD_NBR":    "XXXXXXXXXXXX4242",
+			"EXP_DATE":    "2812",
+			"USER_DATA_1": "save_and_charge=true",
+			"USER_DATA_2": "customer-1",
+		},
+	}
+}
+
+func postCallback(handler *BrowserPostCallbackHandler) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/browser-post/callback", strings.NewReader(url.Values{"AUTH_RESP": {"00"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.HandleCallback(w, req)
+	return w
+}
+
+// issueSaveAndChargeForm gets a save_and_charge form and points the callback response at its TRAN_NBR
+func issueSaveAndChargeForm(t *testing.T, handler *BrowserPostCallbackHandler, response *ports.BrowserPostResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler.GetPaymentForm(w, httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?amount=42.50&transaction_type=save_and_charge&customer_id=customer-1", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var form map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &form))
+	response.TranNbr = form["tranNbr"]
+}
+
+func TestHandleCallback_SaveAndCharge(t *testing.T) {
+	store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`))
+	response := storageResponse(true)
+	handler, methods, sales := newSaveAndChargeHandler(store, nil, response)
+	issueSaveAndChargeForm(t, handler, response)
+
+	// The browser can change what the callback carries back; the form's recorded amount and customer are charged
+	response.Amount = "1.00"
+	response.RawParams["USER_DATA_2"] = "customer-2"
+
+	w := postCallback(handler)
+
+	require.Len(t, methods.saved, 1, "the card is saved")
+	assert.Equal(t, "0A1MQQ3K2XTBVW8Y0Z1", methods.saved[0].FinancialBRIC)
+	assert.Equal(t, "customer-1", methods.saved[0].CustomerID, "the form's customer, not USER_DATA_2")
+	assert.Equal(t, "merchant-1", methods.saved[0].AgentID, "the merchant registered with the EPX credentials, not CUST_NBR")
+	assert.Equal(t, "4242", methods.saved[0].LastFour)
+
+	require.Len(t, sales.sales, 1, "then charged")
+	sale := sales.sales[0]
+	assert.Equal(t, "merchant-1", sale.AgentID)
+	assert.Equal(t, "42.50", sale.Amount, "the form's amount, not AMOUNT")
+	require.NotNil(t, sale.PaymentMethodID)
+	assert.Equal(t, "5b0b2a5e-4b8f-4d0a-9d42-0c3c5d3f8e11", *sale.PaymentMethodID, "the sale charges the new payment method")
+	require.NotNil(t, sale.IdempotencyKey)
+	assert.Equal(t, "browser-post:1", *sale.IdempotencyKey)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "Payment Successful")
+	assert.Contains(t, body, "c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90", "the receipt shows the sale")
+	assert.Contains(t, body, "057579")
+
+	intent := store.intents[0]
+	assert.Equal(t, "completed", intent.Status)
+	assert.Equal(t, "c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90", uuid.UUID(intent.TransactionID.Bytes).String())
+	assert.Equal(t, "5b0b2a5e-4b8f-4d0a-9d42-0c3c5d3f8e11", uuid.UUID(intent.PaymentMethodID.Bytes).String())
+
+	// A repeated callback shows the sale without saving the card or charging again
+	store.transactions = append(store.transactions, sqlc.Transaction{
+		ID:       uuid.MustParse("c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90"),
+		AgentID:  "merchant-1",
+		Status:   string(domain.TransactionStatusCompleted),
+		AuthResp: pgtype.Text{String: "00", Valid: true},
+		AuthCode: pgtype.Text{String: "057579", Valid: true},
+	})
+	body = postCallback(handler).Body.String()
+	assert.Len(t, methods.saved, 1)
+	assert.Len(t, sales.sales, 1)
+	assert.Contains(t, body, "Payment Successful")
+	assert.Contains(t, body, "c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90")
+}
+
+func TestHandleCallback_SaveAndChargeRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *ports.BrowserPostResponse
+		setup    func(t *testing.T, handler *BrowserPostCallbackHandler, store *fakeBrowserPostStore, response *ports.BrowserPostResponse)
+		wantBody string
+	}{
+		{
+			name:     "storage declined",
+			response: storageResponse(false),
+			setup: func(t *testing.T, handler *BrowserPostCallbackHandler, store *fakeBrowserPostStore, response *ports.BrowserPostResponse) {
+				issueSaveAndChargeForm(t, handler, response)
+			},
+			wantBody: "Payment Failed",
+		},
+		{
+			name:     "no form with the TRAN_NBR",
+			response: storageResponse(true),
+			setup: func(t *testing.T, handler *BrowserPostCallbackHandler, store *fakeBrowserPostStore, response *ports.BrowserPostResponse) {
+			},
+			wantBody: "We couldn&#39;t find the payment this result belongs to",
+		},
+		{
+			name:     "form expired",
+			response: storageResponse(true),
+			setup: func(t *testing.T, handler *BrowserPostCallbackHandler, store *fakeBrowserPostStore, response *ports.BrowserPostResponse) {
+				issueSaveAndChargeForm(t, handler, response)
+				store.intents[0].ExpiresAt = time.Now().Add(-time.Minute)
+			},
+			wantBody: "Your payment session expired",
+		},
+		{
+			name:     "another callback is charging the form",
+			response: storageResponse(true),
+			setup: func(t *testing.T, handler *BrowserPostCallbackHandler, store *fakeBrowserPostStore, response *ports.BrowserPostResponse) {
+				issueSaveAndChargeForm(t, handler, response)
+				store.intents[0].Status = "processing"
+			},
+			wantBody: "This payment was already processed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`))
+			handler, methods, sales := newSaveAndChargeHandler(store, nil, tt.response)
+			tt.setup(t, handler, store, tt.response)
+
+			body := postCallback(handler).Body.String()
+
+			assert.Empty(t, methods.saved, "nothing saved")
+			assert.Empty(t, sales.sales, "nothing charged")
+			assert.Contains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestHandleCallback_SaveAndChargeWithoutFormRefusedEvenWithVerifiedMAC(t *testing.T) {
+	secrets := staticSecrets{values: map[string]string{"agents/merchant-1/mac": "mac-secret"}}
+	store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{"browser_post_mac_verification": true}`))
+	handler, methods, sales := newSaveAndChargeHandler(store, secrets, storageResponse(true))
+
+	// A valid MAC only shows EPX saw AMOUNT and USER_DATA_2, not that a form issued them
+	assert.NotContains(t, postCallback(handler).Body.String(), "Payment Successful")
+	assert.Empty(t, methods.saved, "nothing saved")
+	assert.Empty(t, sales.sales, "nothing charged")
+}
+
+func TestGetPaymentForm_SaveAndCharge(t *testing.T) {
+	store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`))
+	handler, _, _ := newSaveAndChargeHandler(store, nil, nil)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.GetPaymentForm(w, httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?"+query, nil))
+		return w
+	}
+
+	w := get("amount=42.50&transaction_type=save_and_charge&customer_id=customer-1")
+	require.Equal(t, http.StatusOK, w.Code)
+	var form map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &form))
+	assert.Equal(t, "STORAGE", form["tranCode"])
+	assert.Equal(t, "42.50", form["amount"])
+	assert.Equal(t, "save_and_charge=true", form["userData1"])
+	assert.Equal(t, "customer-1", form["userData2"])
+
+	require.Len(t, store.intents, 1, "the form records what its callback charges")
+	intent := store.intents[0]
+	assert.Equal(t, "merchant-1", intent.AgentID)
+	assert.Equal(t, int64(1), intent.TranNbr, "the merchant's TRAN_NBR counter")
+	assert.Equal(t, form["tranNbr"], "1")
+	assert.Equal(t, "customer-1", intent.CustomerID)
+	assert.True(t, decimal.RequireFromString("42.50").Equal(decimal.NewFromBigInt(intent.Amount.Int, intent.Amount.Exp)))
+	assert.Empty(t, store.transactions, "no pending sale for a save_and_charge form")
+
+	assert.Equal(t, http.StatusBadRequest, get("amount=0&transaction_type=save_and_charge&customer_id=customer-1").Code, "needs an amount to charge")
+	assert.Equal(t, http.StatusBadRequest, get("amount=42.50&transaction_type=save_and_charge").Code, "needs a customer to save the card for")
+	assert.Equal(t, http.StatusBadRequest, get("amount=42.50&transaction_type=refund").Code)
+
+	w, form = get("amount=42.50"), nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &form))
+	assert.Equal(t, "SALE", form["tranCode"], "sale stays the default")
+}
+
+func TestGetPaymentForm_ChargesDisabled(t *testing.T) {
+	for _, transactionType := range []string{"sale", "save_and_charge"} {
+		t.Run(transactionType, func(t *testing.T) {
+			store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`))
+			store.flags = []sqlc.FeatureFlag{{
+				Name:    domain.FeatureFlagChargesEnabled,
+				AgentID: pgtype.Text{String: "merchant-1", Valid: true},
+				Reason:  pgtype.Text{String: "INC-4821 acquirer outage", Valid: true},
+			}}
+			handler, _, _ := newSaveAndChargeHandler(store, nil, nil)
+
+			w := httptest.NewRecorder()
+			handler.GetPaymentForm(w, httptest.NewRequest(http.MethodGet,
+				"/api/v1/payments/browser-post/form?amount=42.50&customer_id=customer-1&transaction_type="+transactionType, nil))
+
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.NotContains(t, w.Body.String(), "INC-4821", "the reason is only logged")
+			assert.Empty(t, store.transactions, "no pending sale")
+			assert.Empty(t, store.intents, "no charge intent")
+			assert.Empty(t, store.lastTranNbr, "no TRAN_NBR used")
+
+			store.flags[0].Enabled = true
+			w = httptest.NewRecorder()
+			handler.GetPaymentForm(w, httptest.NewRequest(http.MethodGet,
+				"/api/v1/payments/browser-post/form?amount=42.50&customer_id=customer-1&transaction_type="+transactionType, nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
+
+func TestGetPaymentForm_AmountRange(t *testing.T) {
+	store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{"min_transaction_amount": "5", "max_transaction_amount": "100"}`))
+	handler, _, _ := newSaveAndChargeHandler(store, nil, nil)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.GetPaymentForm(w, httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?"+query, nil))
+		return w
+	}
+
+	w := get("amount=100.01")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "above the maximum of 100.00")
+	assert.Equal(t, http.StatusBadRequest, get("amount=4.99&transaction_type=save_and_charge&customer_id=customer-1").Code)
+	assert.Empty(t, store.transactions, "no pending sale")
+	assert.Empty(t, store.intents, "no charge intent")
+
+	assert.Equal(t, http.StatusOK, get("amount=100.00").Code)
+	assert.Equal(t, http.StatusOK, get("amount=5.00&transaction_type=save_and_charge&customer_id=customer-1").Code)
+}
+
+func TestGetPaymentForm_EPXEnvironmentMismatch(t *testing.T) {
+	merchant := browserPostMerchant("merchant-1", `{}`)
+	merchant.Environment = string(domain.EnvironmentProduction)
+	store := newFakeBrowserPostStore(merchant)
+	handler, _, _ := newSaveAndChargeHandler(store, nil, nil)
+
+	w := httptest.NewRecorder()
+	handler.GetPaymentForm(w, httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?amount=42.50", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "production credentials never go to a sandbox form")
+	assert.NotContains(t, w.Body.String(), merchant.CustNbr)
+	assert.Empty(t, store.transactions)
+	assert.Empty(t, store.lastTranNbr, "no TRAN_NBR used")
+}
+
+func TestBrowserPost_DailyVolumeLimit(t *testing.T) {
+	store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{"daily_volume_limit": "100"}`))
+	saleResponse := func(approved bool) *ports.BrowserPostResponse {
+		response := storageResponse(approved)
+		delete(response.RawParams, "USER_DATA_1")
+		delete(response.RawParams, "USER_DATA_2")
+		return response
+	}
+	issueSaleForm := func(handler *BrowserPostCallbackHandler, response *ports.BrowserPostResponse) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.GetPaymentForm(w, httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?amount=42.50", nil))
+		if w.Code == http.StatusOK {
+			var form map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &form))
+			response.TranNbr = form["tranNbr"]
+		}
+		return w
+	}
+
+	declined := saleResponse(false)
+	handler, _, _ := newSaveAndChargeHandler(store, nil, declined)
+	require.Equal(t, http.StatusOK, issueSaleForm(handler, declined).Code)
+	assert.True(t, store.volume["merchant-1"].Equal(decimal.RequireFromString("42.50")), "the form holds its amount")
+	assert.True(t, store.transactions[0].DailyVolumeDate.Valid, "the pending sale records the reserved day")
+	postCallback(handler)
+	assert.True(t, store.volume["merchant-1"].IsZero(), "a declined callback releases it")
+	postCallback(handler)
+	assert.True(t, store.volume["merchant-1"].IsZero(), "a repeated decline releases nothing more")
+
+	approved := saleResponse(true)
+	handler, _, _ = newSaveAndChargeHandler(store, nil, approved)
+	require.Equal(t, http.StatusOK, issueSaleForm(handler, approved).Code)
+	require.Equal(t, http.StatusOK, issueSaleForm(handler, saleResponse(true)).Code)
+	assert.Contains(t, postCallback(handler).Body.String(), "Payment Successful")
+	assert.True(t, store.volume["merchant-1"].Equal(decimal.RequireFromString("85.00")), "an approved callback keeps it")
+
+	used := store.lastTranNbr["merchant-1"]
+	w := issueSaleForm(handler, saleResponse(true))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "daily volume limit exceeded")
+	assert.Equal(t, used, store.lastTranNbr["merchant-1"], "no TRAN_NBR used")
+	assert.Len(t, store.transactions, 3, "no pending sale")
+}
+
+func TestHandleCallback_RecordsCardFingerprint(t *testing.T) {
+	store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`))
+	response := storageResponse(false)
+	delete(response.RawParams, "USER_DATA_1")
+	handler, _, _ := newSaveAndChargeHandler(store, nil, response)
+
+	w := httptest.NewRecorder()
+	handler.GetPaymentForm(w, httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?amount=42.50", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var form map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &form))
+	response.TranNbr = form["tranNbr"]
+	postCallback(handler)
+
+	require.Len(t, store.transactions, 1)
+	assert.Equal(t, string(domain.TransactionStatusFailed), store.transactions[0].Status)
+	assert.Equal(t, domain.CardFingerprint("4242", 12, 2028, "0A1MQQ3K2XTBVW8Y0Z9"), store.transactions[0].CardFingerprint.String,
+		"declines count toward the card's velocity limit under the fingerprint a saved copy of the card gets")
+	assert.Equal(t, "4242", store.transactions[0].LastFour.String, "the masked card is kept without saving the card")
+}
+
+func TestHandleCallback_RecordsCardFundingType(t *testing.T) {
+	store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`))
+	response := storageResponse(true)
+	delete(response.RawParams, "USER_DATA_1")
+	response.CardFundingType = "P"
+	handler, _, _ := newSaveAndChargeHandler(store, nil, response)
+
+	w := httptest.NewRecorder()
+	handler.GetPaymentForm(w, httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?amount=42.50", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var form map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &form))
+	response.TranNbr = form["tranNbr"]
+	postCallback(handler)
+
+	require.Len(t, store.transactions, 1)
+	assert.Equal(t, string(domain.TransactionStatusCompleted), store.transactions[0].Status)
+	assert.Equal(t, pgtype.Text{String: "prepaid", Valid: true}, store.transactions[0].CardFundingType)
+}
+
+func TestBrowserPost_CustomerPolicy(t *testing.T) {
+	get := func(handler *BrowserPostCallbackHandler, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.GetPaymentForm(w, httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?"+query, nil))
+		return w
+	}
+	saveAndCharge := "amount=42.50&transaction_type=save_and_charge&customer_id=customer-1"
+
+	t.Run("require_existing refuses a form for an unknown customer", func(t *testing.T) {
+		store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{"customer_policy": "require_existing"}`))
+		handler, _, _ := newSaveAndChargeHandler(store, nil, nil)
+
+		assert.Equal(t, http.StatusNotFound, get(handler, saveAndCharge).Code)
+		assert.Empty(t, store.intents, "no form is issued")
+		assert.Empty(t, store.customers)
+
+		store.customers = append(store.customers, sqlc.Customer{AgentID: "merchant-1", CustomerID: "customer-1"})
+		assert.Equal(t, http.StatusOK, get(handler, saveAndCharge).Code)
+	})
+
+	t.Run("auto_create creates the form's customer", func(t *testing.T) {
+		store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{"customer_policy": "auto_create"}`))
+		handler, _, _ := newSaveAndChargeHandler(store, nil, nil)
+
+		require.Equal(t, http.StatusOK, get(handler, saveAndCharge).Code)
+		require.Len(t, store.customers, 1)
+		assert.Equal(t, "customer-1", store.customers[0].CustomerID)
+		assert.True(t, store.customers[0].AutoCreated)
+	})
+
+	t.Run("require_existing saves no card for an unknown USER_DATA_2", func(t *testing.T) {
+		store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{"customer_policy": "require_existing"}`))
+		response := storageResponse(true)
+		response.RawParams["USER_DATA_1"] = "save_payment_method=true"
+		handler, methods, _ := newSaveAndChargeHandler(store, nil, response)
+
+		w := get(handler, "amount=42.50")
+		require.Equal(t, http.StatusOK, w.Code)
+		var form map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &form))
+		response.TranNbr = form["tranNbr"]
+
+		assert.Contains(t, postCallback(handler).Body.String(), "Payment Successful", "the sale still completes")
+		assert.Empty(t, methods.saved)
+		assert.Empty(t, store.customers)
+	})
+}
+
+// staticSecrets serves secrets from memory
+type staticSecrets struct {
+	ports.SecretManagerAdapter
+	values    map[string]string
+	createdAt map[string]time.Time
+}
+
+func (s staticSecrets) GetSecret(ctx context.Context, path string) (*ports.Secret, error) {
+	value, ok := s.values[path]
+	if !ok {
+		return nil, errors.New("secret not found")
+	}
+	secret := &ports.Secret{Value: value}
+	if createdAt, ok := s.createdAt[path]; ok {
+		secret.CreatedAt = createdAt.Format(time.RFC3339)
+	}
+	return secret, nil
+}
+
+// signedCallback is an approved EPX redirect for the merchant, with MAC set to the HMAC of its signed fields
+func signedCallback(custNbr, key string) url.Values {
+	form := url.Values{
+		"CUST_NBR":   {custNbr},
+		"MERCH_NBR":  {"900300"},
+		"AUTH_GUID":  {"0A1MQQ3K2XTBVW8Y0Z1"},
+		"AUTH_RESP":  {"00"},
+		"AMOUNT":     {"10.00"},
+		"TRAN_NBR":   {"87654321"},
+		"TRAN_GROUP": {"SALE"},
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(custNbr + "900300" + "0A1MQQ3K2XTBVW8Y0Z1" + "00" + "10.00" + "87654321" + "SALE"))
+	form.Set("MAC", hex.EncodeToString(mac.Sum(nil)))
+	return form
+}
+
+func TestHandleCallback_MACVerification(t *testing.T) {
+	const verifyMAC = `{"browser_post_mac_verification": true}`
+	secrets := staticSecrets{
+		values: map[string]string{
+			"agents/merchant-mac/mac":              "mac-secret",
+			"agents/merchant-mac/mac.previous":     "old-mac-secret",
+			"agents/merchant-empty/mac":            "",
+			"agents/merchant-rotated/mac":          "mac-secret",
+			"agents/merchant-rotated/mac.previous": "old-mac-secret",
+		},
+		createdAt: map[string]time.Time{
+			"agents/merchant-mac/mac.previous":     time.Now().Add(-time.Hour),
+			"agents/merchant-rotated/mac.previous": time.Now().Add(-domain.PreviousMACGracePeriod - time.Hour),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		store    *fakeBrowserPostStore
+		secrets  ports.SecretManagerAdapter
+		form     url.Values
+		verified bool
+	}{
+		{"valid MAC accepted", newFakeBrowserPostStore(browserPostMerchant("merchant-mac", verifyMAC)), secrets,
+			signedCallback("9001", "mac-secret"), true},
+		{"MAC signed with another key rejected", newFakeBrowserPostStore(browserPostMerchant("merchant-mac", verifyMAC)), secrets,
+			signedCallback("9001", "wrong-secret"), false},
+		{"previous MAC accepted during the rotation grace period", newFakeBrowserPostStore(browserPostMerchant("merchant-mac", verifyMAC)), secrets,
+			signedCallback("9001", "old-mac-secret"), true},
+		{"previous MAC rejected after the grace period", newFakeBrowserPostStore(browserPostMerchant("merchant-rotated", verifyMAC)), secrets,
+			signedCallback("9001", "old-mac-secret"), false},
+		{"tampered amount rejected", newFakeBrowserPostStore(browserPostMerchant("merchant-mac", verifyMAC)), secrets,
+			func() url.Values {
+				form := signedCallback("9001", "mac-secret")
+				form.Set("AMOUNT", "1.00")
+				return form
+			}(), false},
+		{"missing MAC rejected", newFakeBrowserPostStore(browserPostMerchant("merchant-mac", verifyMAC)), secrets,
+			func() url.Values {
+				form := signedCallback("9001", "mac-secret")
+				form.Del("MAC")
+				return form
+			}(), false},
+		{"missing secret rejected", newFakeBrowserPostStore(browserPostMerchant("merchant-nosecret", verifyMAC)), secrets,
+			signedCallback("9001", ""), false},
+		{"empty secret rejected", newFakeBrowserPostStore(browserPostMerchant("merchant-empty", verifyMAC)), secrets,
+			signedCallback("9001", ""), false},
+		{"no secret manager rejected", newFakeBrowserPostStore(browserPostMerchant("merchant-mac", verifyMAC)), nil,
+			signedCallback("9001", "mac-secret"), false},
+		{"unreadable merchant config rejected", newFakeBrowserPostStore(browserPostMerchant("merchant-mac", `{"browser_post_mac_verification": "yes"`)), secrets,
+			signedCallback("9001", "mac-secret"), false},
+		{"not checked when the merchant hasn't enabled it", newFakeBrowserPostStore(browserPostMerchant("merchant-tac", `{}`)), secrets,
+			func() url.Values {
+				form := signedCallback("9001", "anything")
+				form.Del("MAC")
+				return form
+			}(), true},
+		{"unregistered EPX credentials rejected", newFakeBrowserPostStore(), secrets,
+			signedCallback("9001", "anything"), false},
+		{"another CUST_NBR rejected", newFakeBrowserPostStore(browserPostMerchant("merchant-tac", `{}`)), secrets,
+			signedCallback("9002", "anything"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, agent := range tt.store.agents {
+				tt.store.pendingSale(agent.AgentID, 87654321, time.Now())
+			}
+			handler := NewBrowserPostCallbackHandler(
+				tt.store,
+				epx.NewBrowserPostAdapter(epx.DefaultBrowserPostConfig("sandbox"), zap.NewNop()),
+				&recordingPaymentMethodService{},
+				&recordingSaleService{},
+				tt.secrets,
+				zap.NewNop(),
+				"https://secure.epxuap.com/browserpost",
+				"9001",
+				"900300",
+				"2",
+				"77",
+				"http://localhost:8081",
+				domain.EnvironmentSandbox,
+				nil,
+				PendingExpiryConfig{},
+			)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/browser-post/callback", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			handler.HandleCallback(w, req)
+
+			body := w.Body.String()
+			if tt.verified {
+				assert.Contains(t, body, "Payment Successful")
+				require.Len(t, tt.store.transactions, 1)
+				assert.Equal(t, string(domain.TransactionStatusCompleted), tt.store.transactions[0].Status)
+			} else {
+				assert.NotContains(t, body, "Payment Successful")
+				for _, tx := range tt.store.transactions {
+					assert.Equal(t, string(domain.TransactionStatusPending), tx.Status, "nothing is recorded")
+				}
+			}
+		})
+	}
+}
+
+// newCallbackHandler is a handler for the sandbox merchant's callbacks with the EPX adapter and a fixed clock
+func newCallbackHandler(store *fakeBrowserPostStore, now time.Time) *BrowserPostCallbackHandler {
+	handler := NewBrowserPostCallbackHandler(
+		store,
+		epx.NewBrowserPostAdapter(epx.DefaultBrowserPostConfig("sandbox"), zap.NewNop()),
+		&recordingPaymentMethodService{},
+		&recordingSaleService{},
+		nil,
+		zap.NewNop(),
+		"https://secure.epxuap.com/browserpost",
+		"9001",
+		"900300",
+		"2",
+		"77",
+		"http://localhost:8081",
+		domain.EnvironmentSandbox,
+		nil,
+		PendingExpiryConfig{TACValidity: time.Hour, CallbackGrace: 10 * time.Minute},
+	)
+	handler.now = func() time.Time { return now }
+	return handler
+}
+
+func postForm(handler *BrowserPostCallbackHandler, form url.Values) string {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/browser-post/callback", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.HandleCallback(w, req)
+	return w.Body.String()
+}
+
+func TestHandleCallback_CompletesMerchantPendingTransaction(t *testing.T) {
+	now := time.Now()
+	otherMerchant := browserPostMerchant("merchant-2", `{}`)
+	otherMerchant.CustNbr = "9002"
+	store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`), otherMerchant)
+	other := store.pendingSale("merchant-2", 87654321, now)
+	pending := store.pendingSale("merchant-1", 87654321, now)
+	handler := newCallbackHandler(store, now)
+
+	assert.Contains(t, postForm(handler, signedCallback("9001", "unused")), "Payment Successful")
+	require.Len(t, store.transactions, 2, "the callback completes the form's transaction instead of adding one")
+	tx := store.transactions[1]
+	assert.Equal(t, pending.ID, tx.ID)
+	assert.Equal(t, string(domain.TransactionStatusCompleted), tx.Status)
+	assert.Equal(t, "0A1MQQ3K2XTBVW8Y0Z1", tx.AuthGuid.String)
+	assert.Equal(t, pgtype.Int8{Int64: 87654321, Valid: true}, tx.TranNbr, "RefundByReference finds it by TRAN_NBR")
+	assert.Equal(t, other, store.transactions[0], "another merchant's TRAN_NBR is untouched")
+
+	assert.Contains(t, postForm(handler, signedCallback("9001", "unused")), "Payment Successful",
+		"a repeated callback with the same AUTH_GUID renders the receipt again")
+	assert.Equal(t, string(domain.TransactionStatusCompleted), store.transactions[1].Status)
+
+	replay := signedCallback("9001", "unused")
+	replay.Set("AUTH_GUID", "0A1MQQ3K2XTBVW8Y0Z2")
+	body := postForm(handler, replay)
+	assert.NotContains(t, body, "Payment Successful")
+	assert.Contains(t, body, "This payment was already processed (reference 87654321).")
+	assert.Equal(t, "0A1MQQ3K2XTBVW8Y0Z1", store.transactions[1].AuthGuid.String, "a different result doesn't overwrite the first")
+}
+
+func TestHandleCallback_LogsNoCardData(t *testing.T) {
+	store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`))
+	store.pendingSale("merchant-1", 87654321, time.Now())
+	handler := newCallbackHandler(store, time.Now())
+	// Logged the way the server logs (cmd/server wraps its logger in RedactingCore)
+	core, logs := observer.New(zap.DebugLevel)
+	handler.logger = zap.New(core, zap.WrapCore(security.RedactingCore))
+
+	form := signedCallback("9001", "unused")
+	form.Set("CARD_NBR", "4111111111111111")
+	form.Set("CVV2", "987")
+	form.Set("EXP_DATE", "2512")
+	form.Set("USER_DATA_1", "save_payment_method=true")
+	form.Set("USER_DATA_2", "customer-1")
+	require.Contains(t, postForm(handler, form), "Payment Successful")
+
+	require.NotEmpty(t, logs.All())
+	for _, entry := range logs.All() {
+		logged := entry.Message + fmt.Sprint(entry.ContextMap())
+		assert.NotContains(t, logged, "4111111111111111", entry.Message)
+		assert.NotContains(t, logged, "987", entry.Message)
+		assert.NotContains(t, logged, "2512", entry.Message)
+		assert.NotContains(t, logged, "0A1MQQ3K2XTBVW8Y0Z1", entry.Message, "BRICs keep their last 4 characters")
+	}
+}
+
+func TestHandleCallback_PendingExpiry(t *testing.T) {
+	const expiredMessage = "Your payment session expired before the payment result was received. Please contact support with reference 87654321 before trying again."
+	now := time.Now()
+	ptrTime := func(t time.Time) *time.Time { return &t }
+	tests := []struct {
+		name       string
+		age        time.Duration
+		expiresAt  *time.Time
+		wantStatus domain.TransactionStatus
+		wantBody   string
+	}{
+		{"within the TAC validity", 30 * time.Minute, nil, domain.TransactionStatusCompleted, "Payment Successful"},
+		{"late but within the grace", 65 * time.Minute, nil, domain.TransactionStatusCompleted, "Payment Successful"},
+		{"past the grace", 2 * time.Hour, nil, domain.TransactionStatusExpired, expiredMessage},
+		{"past the form's own deadline", 30 * time.Minute, ptrTime(now.Add(-time.Minute)), domain.TransactionStatusExpired, expiredMessage},
+		{"within the form's own deadline", 2 * time.Hour, ptrTime(now.Add(time.Minute)), domain.TransactionStatusCompleted, "Payment Successful"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`))
+			store.pendingSale("merchant-1", 87654321, now.Add(-tt.age))
+			if tt.expiresAt != nil {
+				store.transactions[0].PendingExpiresAt = pgtype.Timestamptz{Time: *tt.expiresAt, Valid: true}
+			}
+
+			assert.Contains(t, postForm(newCallbackHandler(store, now), signedCallback("9001", "unused")), tt.wantBody)
+			assert.Equal(t, string(tt.wantStatus), store.transactions[0].Status)
+			assert.Equal(t, "0A1MQQ3K2XTBVW8Y0Z1", store.transactions[0].AuthGuid.String, "an expired row keeps the late BRIC for a void")
+		})
+	}
+}
+
+func TestHandleCallback_UnknownTranNbr(t *testing.T) {
+	store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`))
+
+	body := postForm(newCallbackHandler(store, time.Now()), signedCallback("9001", "unused"))
+	assert.NotContains(t, body, "Payment Successful")
+	assert.Contains(t, body, "reference 87654321")
+	assert.Empty(t, store.transactions, "nothing is recorded without the form's transaction")
+}
+
+func TestGetPaymentForm_RecordsPendingSale(t *testing.T) {
+	now := time.Now()
+	store := newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`))
+	handler := newCallbackHandler(store, now)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?amount=10.00", nil)
+	w := httptest.NewRecorder()
+	handler.GetPaymentForm(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var form map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &form))
+	assert.Equal(t, "1", form["tranNbr"], "the merchant's first TRAN_NBR")
+	require.Len(t, store.transactions, 1)
+	assert.Equal(t, "merchant-1", store.transactions[0].AgentID)
+	assert.Equal(t, string(domain.TransactionStatusPending), store.transactions[0].Status)
+	assert.Equal(t, pgtype.Int8{Int64: 1, Valid: true}, store.transactions[0].TranNbr)
+	assert.Equal(t, pgtype.Timestamptz{Time: now.Add(70 * time.Minute), Valid: true}, store.transactions[0].PendingExpiresAt,
+		"the TAC validity plus the callback grace")
+
+	callback := signedCallback("9001", "unused")
+	callback.Set("TRAN_NBR", "1")
+	assert.Contains(t, postForm(handler, callback), "Payment Successful")
+	assert.Equal(t, string(domain.TransactionStatusCompleted), store.transactions[0].Status)
+}
+
+func TestSavePaymentMethod_ACHStorageCallback(t *testing.T) {
+	merchant := browserPostMerchant("merchant-1", `{}`)
+	handler, methods, _ := newSaveAndChargeHandler(newFakeBrowserPostStore(merchant), nil, nil)
+	response := &ports.BrowserPostResponse{
+		AuthGUID:   "0A1MQQ3K2XTBVW8Y0Z9",
+		AuthResp:   "00",
+		IsApproved: true,
+		RawParams: map[string]string{
+			"CUST_NBR":     "9001",
+			"TRAN_TYPE":    "CKS8",
+			"ROUTING_NBR":  "021000021",
+			"ACCOUNT_NBR":  "XXXXXX6789",
+			"USER_DATA_1":  "save_payment_method=true",
+			"USER_DATA_2":  "customer-1",
+			"EXP_DATE":     "2812",
+			"ACCOUNT_TYPE": "",
+		},
+	}
+
+	pm, err := handler.savePaymentMethod(context.Background(), &merchant, "customer-1", response, "tx-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentMethodTypeACH, pm.PaymentType)
+
+	require.Len(t, methods.saved, 1)
+	saved := methods.saved[0]
+	assert.Equal(t, domain.PaymentMethodTypeACH, saved.PaymentType)
+	assert.Equal(t, "6789", saved.LastFour, "from the account number")
+	require.NotNil(t, saved.BankName)
+	assert.Equal(t, "Routing 021000021", *saved.BankName, "EPX sent no BANK_NAME")
+	require.NotNil(t, saved.AccountType)
+	assert.Equal(t, "savings", *saved.AccountType)
+	assert.Nil(t, saved.CardExpMonth, "no card fields on a bank account")
+	assert.Nil(t, saved.CardBrand)
+
+	// A card response is still saved as a card
+	_, err = handler.savePaymentMethod(context.Background(), &merchant, "customer-1", storageResponse(true), "tx-2")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentMethodTypeCreditCard, methods.saved[1].PaymentType)
+	assert.Nil(t, methods.saved[1].BankName)
+}
+
+func TestBrowserPostACHAccountType(t *testing.T) {
+	for name, tt := range map[string]struct {
+		params   map[string]string
+		wantType string
+		wantACH  bool
+	}{
+		"card":                {map[string]string{"TRAN_TYPE": "CCE8", "CARD_NBR": "XXXXXXXXXXXX4242"}, "", false},
+		"checking tran type":  {map[string]string{"TRAN_TYPE": "CKC8"}, "checking", true},
+		"savings tran type":   {map[string]string{"TRAN_TYPE": "cks8"}, "savings", true},
+		"routing number only": {map[string]string{"ROUTING_NBR": "021000021"}, "checking", true},
+		"account type wins":   {map[string]string{"TRAN_TYPE": "CKC8", "ACCOUNT_TYPE": "S"}, "savings", true},
+	} {
+		accountType, isACH := browserPostACHAccountType(tt.params)
+		assert.Equal(t, tt.wantACH, isACH, name)
+		assert.Equal(t, tt.wantType, accountType, name)
+	}
+}
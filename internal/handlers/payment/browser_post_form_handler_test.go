@@ -5,10 +5,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/kevin07696/payment-service/internal/adapters/ports"
-	"github.com/kevin07696/payment-service/internal/db/sqlc"
 	"github.com/kevin07696/payment-service/internal/domain"
 	serviceports "github.com/kevin07696/payment-service/internal/services/ports"
 	"go.uber.org/zap/zaptest"
@@ -29,13 +29,6 @@ func (m *mockBrowserPostAdapter) ValidateResponseMAC(params map[string][]string,
 	return nil
 }
 
-// mockDatabaseAdapter is a mock implementation of DatabaseAdapter for testing
-type mockDatabaseAdapter struct{}
-
-func (m *mockDatabaseAdapter) Queries() *sqlc.Queries {
-	return nil
-}
-
 // mockPaymentMethodService is a mock implementation of PaymentMethodService for testing
 type mockPaymentMethodService struct{}
 
@@ -121,6 +114,18 @@ func TestGetPaymentForm(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:               "Success - Amount normalized to cents",
+			method:             http.MethodGet,
+			queryParams:        "?amount=10.5",
+			expectedStatusCode: http.StatusOK,
+			expectedError:      false,
+			validateResponse: func(t *testing.T, body map[string]interface{}) {
+				if body["amount"] != "10.50" {
+					t.Errorf("Expected amount=10.50, got %v", body["amount"])
+				}
+			},
+		},
 		{
 			name:               "Error - Missing amount parameter",
 			method:             http.MethodGet,
@@ -176,9 +181,11 @@ func TestGetPaymentForm(t *testing.T) {
 			// Setup
 			logger := zaptest.NewLogger(t)
 			handler := NewBrowserPostCallbackHandler(
-				&mockDatabaseAdapter{},
+				newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`)),
 				&mockBrowserPostAdapter{},
 				&mockPaymentMethodService{},
+				nil,
+				nil,
 				logger,
 				"https://secure.epxuap.com/browserpost", // EPX sandbox URL
 				"9001",                                  // EPX Customer Number
@@ -186,6 +193,9 @@ func TestGetPaymentForm(t *testing.T) {
 				"2",                                     // EPX DBA Number
 				"77",                                    // EPX Terminal Number
 				"http://localhost:8081",                 // Callback base URL
+				domain.EnvironmentSandbox,
+				nil,
+				PendingExpiryConfig{},
 			)
 
 			// Create request
@@ -223,9 +233,11 @@ func TestGetPaymentForm(t *testing.T) {
 func TestGetPaymentForm_UniqueTransactionNumbers(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	handler := NewBrowserPostCallbackHandler(
-		&mockDatabaseAdapter{},
+		newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`)),
 		&mockBrowserPostAdapter{},
 		&mockPaymentMethodService{},
+		nil,
+		nil,
 		logger,
 		"https://secure.epxuap.com/browserpost",
 		"9001",
@@ -233,6 +245,9 @@ func TestGetPaymentForm_UniqueTransactionNumbers(t *testing.T) {
 		"2",
 		"77",
 		"http://localhost:8081",
+		domain.EnvironmentSandbox,
+		nil,
+		PendingExpiryConfig{},
 	)
 
 	tranNbrs := make(map[string]bool)
@@ -310,10 +325,14 @@ func TestGetPaymentForm_CredentialsConfiguration(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			logger := zaptest.NewLogger(t)
+			merchant := browserPostMerchant("merchant-1", `{}`)
+			merchant.CustNbr, merchant.MerchNbr, merchant.DbaNbr, merchant.TerminalNbr = tt.epxCustNbr, tt.epxMerchNbr, tt.epxDBAnbr, tt.epxTerminalNbr
 			handler := NewBrowserPostCallbackHandler(
-				&mockDatabaseAdapter{},
+				newFakeBrowserPostStore(merchant),
 				&mockBrowserPostAdapter{},
 				&mockPaymentMethodService{},
+				nil,
+				nil,
 				logger,
 				tt.epxPostURL,
 				tt.epxCustNbr,
@@ -321,6 +340,9 @@ func TestGetPaymentForm_CredentialsConfiguration(t *testing.T) {
 				tt.epxDBAnbr,
 				tt.epxTerminalNbr,
 				tt.callbackBaseURL,
+				domain.EnvironmentSandbox,
+				nil,
+				PendingExpiryConfig{},
 			)
 
 			req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?amount=99.99", nil)
@@ -377,20 +399,20 @@ func TestGetPaymentForm_EdgeCases(t *testing.T) {
 		{
 			name:               "Zero amount",
 			queryParams:        "?amount=0.00",
-			expectedStatusCode: http.StatusOK,
-			expectedError:      false,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError:      true,
 		},
 		{
-			name:               "Very small amount",
+			name:               "Below the merchant's minimum",
 			queryParams:        "?amount=0.01",
-			expectedStatusCode: http.StatusOK,
-			expectedError:      false,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError:      true,
 		},
 		{
 			name:               "Negative amount (invalid)",
 			queryParams:        "?amount=-99.99",
-			expectedStatusCode: http.StatusOK, // Parses as valid float, validation could be added
-			expectedError:      false,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError:      true,
 		},
 		{
 			name:               "Amount with many decimal places",
@@ -398,6 +420,12 @@ func TestGetPaymentForm_EdgeCases(t *testing.T) {
 			expectedStatusCode: http.StatusOK,
 			expectedError:      false,
 		},
+		{
+			name:               "Amount with trailing garbage",
+			queryParams:        "?amount=10.50abc",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError:      true,
+		},
 		{
 			name:               "Integer amount (no decimal)",
 			queryParams:        "?amount=100",
@@ -422,9 +450,11 @@ func TestGetPaymentForm_EdgeCases(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			logger := zaptest.NewLogger(t)
 			handler := NewBrowserPostCallbackHandler(
-				&mockDatabaseAdapter{},
+				newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`)),
 				&mockBrowserPostAdapter{},
 				&mockPaymentMethodService{},
+				nil,
+				nil,
 				logger,
 				"https://secure.epxuap.com/browserpost",
 				"9001",
@@ -432,6 +462,9 @@ func TestGetPaymentForm_EdgeCases(t *testing.T) {
 				"2",
 				"77",
 				"http://localhost:8081",
+				domain.EnvironmentSandbox,
+				nil,
+				PendingExpiryConfig{},
 			)
 
 			req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form"+tt.queryParams, nil)
@@ -446,13 +479,188 @@ func TestGetPaymentForm_EdgeCases(t *testing.T) {
 	}
 }
 
+// TestGetPaymentForm_ReturnURLScheme tests that only http(s) return URLs are accepted (https-only in production)
+func TestGetPaymentForm_ReturnURLScheme(t *testing.T) {
+	tests := []struct {
+		name               string
+		returnURL          string
+		production         bool
+		expectedStatusCode int
+	}{
+		{"https accepted in production", "https://pos.example.com/done", true, http.StatusOK},
+		{"https accepted in sandbox", "https://pos.example.com/done", false, http.StatusOK},
+		{"http accepted in sandbox", "http://localhost:3000/done", false, http.StatusOK},
+		{"http rejected in production", "http://pos.example.com/done", true, http.StatusBadRequest},
+		{"javascript rejected in sandbox", "javascript:alert(1)", false, http.StatusBadRequest},
+		{"javascript rejected in production", "javascript:alert(1)", true, http.StatusBadRequest},
+		{"data scheme rejected", "data:text/html,<script>alert(1)</script>", false, http.StatusBadRequest},
+		{"relative URL rejected", "/done", false, http.StatusBadRequest},
+		{"missing host rejected", "https:///done", false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			epxEnv := domain.EnvironmentSandbox
+			if tt.production {
+				epxEnv = domain.EnvironmentProduction
+			}
+			merchant := browserPostMerchant("merchant-1", `{"return_url_hosts": ["pos.example.com"]}`)
+			merchant.Environment = string(epxEnv)
+			handler := NewBrowserPostCallbackHandler(
+				newFakeBrowserPostStore(merchant),
+				&mockBrowserPostAdapter{},
+				&mockPaymentMethodService{},
+				nil,
+				nil,
+				zaptest.NewLogger(t),
+				"https://secure.epxuap.com/browserpost",
+				"9001",
+				"900300",
+				"2",
+				"77",
+				"http://localhost:8081",
+				epxEnv,
+				[]string{"localhost"},
+				PendingExpiryConfig{},
+			)
+
+			query := url.Values{"amount": {"10.00"}, "return_url": {tt.returnURL}}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?"+query.Encode(), nil)
+			w := httptest.NewRecorder()
+
+			handler.GetPaymentForm(w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.expectedStatusCode, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusOK {
+				var body map[string]interface{}
+				if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if body["returnURL"] != tt.returnURL {
+					t.Errorf("Expected returnURL %q, got %v", tt.returnURL, body["returnURL"])
+				}
+			}
+		})
+	}
+}
+
+// TestGetPaymentForm_ReturnURLAllowlist tests that return_url must point at a host the handler's merchant allows
+func TestGetPaymentForm_ReturnURLAllowlist(t *testing.T) {
+	otherMerchant := browserPostMerchant("merchant-2", `{"return_url_hosts": ["attacker.example"]}`)
+	otherMerchant.CustNbr = "9002"
+
+	tests := []struct {
+		name               string
+		overrides          string
+		query              url.Values
+		expectedStatusCode int
+	}{
+		{"allowed host", `{"return_url_hosts": ["pos.example.com"]}`,
+			url.Values{"return_url": {"https://pos.example.com/done"}}, http.StatusOK},
+		{"allowed host is case-insensitive and ignores the port", `{"return_url_hosts": ["pos.example.com"]}`,
+			url.Values{"return_url": {"https://POS.example.com:8443/done"}}, http.StatusOK},
+		{"wildcard matches a subdomain", `{"return_url_hosts": ["*.shop.example.net"]}`,
+			url.Values{"return_url": {"https://store-12.shop.example.net/done"}}, http.StatusOK},
+		{"wildcard doesn't match the bare domain", `{"return_url_hosts": ["*.shop.example.net"]}`,
+			url.Values{"return_url": {"https://shop.example.net/done"}}, http.StatusBadRequest},
+		{"dev host allowed for every merchant", `{"return_url_hosts": ["pos.example.com"]}`,
+			url.Values{"return_url": {"http://localhost:3000/done"}}, http.StatusOK},
+		{"disallowed host", `{"return_url_hosts": ["pos.example.com"]}`,
+			url.Values{"return_url": {"https://attacker.example/done"}}, http.StatusBadRequest},
+		{"lookalike host", `{"return_url_hosts": ["pos.example.com"]}`,
+			url.Values{"return_url": {"https://pos.example.com.attacker.example/done"}}, http.StatusBadRequest},
+		{"agent_id can't select another merchant's allowlist", `{"return_url_hosts": ["pos.example.com"]}`,
+			url.Values{"return_url": {"https://attacker.example/done"}, "agent_id": {"merchant-2"}}, http.StatusBadRequest},
+		{"blank entries allow nothing", `{"return_url_hosts": ["", " ", "*."]}`,
+			url.Values{"return_url": {"https://attacker.example./done"}}, http.StatusBadRequest},
+		{"merchant without an allowlist", `{}`,
+			url.Values{"return_url": {"https://pos.example.com/done"}}, http.StatusBadRequest},
+		{"malformed URL", `{"return_url_hosts": ["pos.example.com"]}`,
+			url.Values{"return_url": {"https://pos.example.com/%zz"}}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewBrowserPostCallbackHandler(
+				newFakeBrowserPostStore(browserPostMerchant("merchant-1", tt.overrides), otherMerchant),
+				&mockBrowserPostAdapter{},
+				&mockPaymentMethodService{},
+				nil,
+				nil,
+				zaptest.NewLogger(t),
+				"https://secure.epxuap.com/browserpost",
+				"9001",
+				"900300",
+				"2",
+				"77",
+				"http://localhost:8081",
+				domain.EnvironmentSandbox,
+				[]string{"localhost"},
+				PendingExpiryConfig{},
+			)
+
+			query := tt.query
+			query.Set("amount", "10.00")
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?"+query.Encode(), nil)
+			w := httptest.NewRecorder()
+
+			handler.GetPaymentForm(w, req)
+
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.expectedStatusCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestGetPaymentForm_ReturnURLUnknownMerchant tests that a return_url is refused when the handler's EPX
+// credentials don't identify exactly one merchant
+func TestGetPaymentForm_ReturnURLUnknownMerchant(t *testing.T) {
+	for name, store := range map[string]*fakeBrowserPostStore{
+		"no merchant":        newFakeBrowserPostStore(),
+		"credentials reused": newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{"return_url_hosts": ["pos.example.com"]}`), browserPostMerchant("merchant-2", `{"return_url_hosts": ["pos.example.com"]}`)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := NewBrowserPostCallbackHandler(
+				store,
+				&mockBrowserPostAdapter{},
+				&mockPaymentMethodService{},
+				nil,
+				nil,
+				zaptest.NewLogger(t),
+				"https://secure.epxuap.com/browserpost",
+				"9001",
+				"900300",
+				"2",
+				"77",
+				"http://localhost:8081",
+				domain.EnvironmentSandbox,
+				nil,
+				PendingExpiryConfig{},
+			)
+
+			query := url.Values{"amount": {"10.00"}, "return_url": {"https://pos.example.com/done"}}
+			w := httptest.NewRecorder()
+			handler.GetPaymentForm(w, httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?"+query.Encode(), nil))
+
+			if w.Code != http.StatusServiceUnavailable {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusServiceUnavailable, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
 // BenchmarkGetPaymentForm benchmarks the GetPaymentForm handler performance
 func BenchmarkGetPaymentForm(b *testing.B) {
 	logger := zaptest.NewLogger(b)
 	handler := NewBrowserPostCallbackHandler(
-		&mockDatabaseAdapter{},
+		newFakeBrowserPostStore(browserPostMerchant("merchant-1", `{}`)),
 		&mockBrowserPostAdapter{},
 		&mockPaymentMethodService{},
+		nil,
+		nil,
 		logger,
 		"https://secure.epxuap.com/browserpost",
 		"9001",
@@ -460,6 +668,9 @@ func BenchmarkGetPaymentForm(b *testing.B) {
 		"2",
 		"77",
 		"http://localhost:8081",
+		domain.EnvironmentSandbox,
+		nil,
+		PendingExpiryConfig{},
 	)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/browser-post/form?amount=99.99", nil)
@@ -5,13 +5,17 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/kevin07696/payment-service/internal/domain"
 	"github.com/kevin07696/payment-service/internal/services/ports"
+	pkgerrors "github.com/kevin07696/payment-service/pkg/errors"
+	"github.com/kevin07696/payment-service/pkg/middleware"
 	paymentv1 "github.com/kevin07696/payment-service/proto/payment/v1"
 	"go.uber.org/zap"
 )
@@ -44,16 +48,24 @@ func (h *Handler) Authorize(ctx context.Context, req *paymentv1.AuthorizeRequest
 	}
 
 	// Convert to service request
+	actor, _ := middleware.ServiceIDFromContext(ctx)
 	serviceReq := &ports.AuthorizeRequest{
-		AgentID:  req.AgentId,
-		Amount:   req.Amount,
-		Currency: req.Currency,
-		Metadata: convertMetadata(req.Metadata),
+		AgentID:             req.AgentId,
+		Amount:              req.Amount,
+		Currency:            req.Currency,
+		Metadata:            convertMetadata(req.Metadata),
+		IncludeTree:         req.IncludeTree,
+		ThreeDS:             threeDSFromProto(req.ThreeDs),
+		StatementDescriptor: req.StatementDescriptor,
+		Actor:               actor,
 	}
 
 	if req.CustomerId != "" {
 		serviceReq.CustomerID = &req.CustomerId
 	}
+	if req.CustomerEmail != "" {
+		serviceReq.CustomerEmail = &req.CustomerEmail
+	}
 
 	// Handle payment method oneof
 	switch pm := req.PaymentMethod.(type) {
@@ -89,8 +101,11 @@ func (h *Handler) Capture(ctx context.Context, req *paymentv1.CaptureRequest) (*
 		return nil, status.Error(codes.InvalidArgument, "transaction_id is required")
 	}
 
+	actor, _ := middleware.ServiceIDFromContext(ctx)
 	serviceReq := &ports.CaptureRequest{
 		TransactionID: req.TransactionId,
+		IncludeTree:   req.IncludeTree,
+		Actor:         actor,
 	}
 
 	if req.Amount != "" {
@@ -109,6 +124,74 @@ func (h *Handler) Capture(ctx context.Context, req *paymentv1.CaptureRequest) (*
 	return transactionToPaymentResponse(tx), nil
 }
 
+// PartialReverseAuthorization releases part of an authorization hold
+func (h *Handler) PartialReverseAuthorization(ctx context.Context, req *paymentv1.PartialReverseAuthorizationRequest) (*paymentv1.PaymentResponse, error) {
+	h.logger.Info("Partial authorization reversal request received",
+		zap.String("transaction_id", req.TransactionId),
+		zap.String("amount", req.Amount),
+	)
+
+	if req.TransactionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "transaction_id is required")
+	}
+	if req.Amount == "" {
+		return nil, status.Error(codes.InvalidArgument, "amount is required")
+	}
+
+	actor, _ := middleware.ServiceIDFromContext(ctx)
+	serviceReq := &ports.PartialReversalRequest{
+		TransactionID: req.TransactionId,
+		Amount:        req.Amount,
+		IncludeTree:   req.IncludeTree,
+		Actor:         actor,
+	}
+
+	if req.IdempotencyKey != "" {
+		serviceReq.IdempotencyKey = &req.IdempotencyKey
+	}
+
+	tx, err := h.service.PartialReverseAuthorization(ctx, serviceReq)
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	return transactionToPaymentResponse(tx), nil
+}
+
+// IncrementAuthorization adds to an open authorization hold
+func (h *Handler) IncrementAuthorization(ctx context.Context, req *paymentv1.IncrementAuthorizationRequest) (*paymentv1.PaymentResponse, error) {
+	h.logger.Info("Incremental authorization request received",
+		zap.String("transaction_id", req.TransactionId),
+		zap.String("amount", req.Amount),
+	)
+
+	if req.TransactionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "transaction_id is required")
+	}
+	if req.Amount == "" {
+		return nil, status.Error(codes.InvalidArgument, "amount is required")
+	}
+
+	actor, _ := middleware.ServiceIDFromContext(ctx)
+	serviceReq := &ports.IncrementAuthorizationRequest{
+		TransactionID: req.TransactionId,
+		Amount:        req.Amount,
+		IncludeTree:   req.IncludeTree,
+		Actor:         actor,
+	}
+
+	if req.IdempotencyKey != "" {
+		serviceReq.IdempotencyKey = &req.IdempotencyKey
+	}
+
+	tx, err := h.service.IncrementAuthorization(ctx, serviceReq)
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	return transactionToPaymentResponse(tx), nil
+}
+
 // Sale combines authorize and capture in one operation
 func (h *Handler) Sale(ctx context.Context, req *paymentv1.SaleRequest) (*paymentv1.PaymentResponse, error) {
 	h.logger.Info("Sale request received",
@@ -116,21 +199,42 @@ func (h *Handler) Sale(ctx context.Context, req *paymentv1.SaleRequest) (*paymen
 		zap.String("amount", req.Amount),
 	)
 
-	// Validate request
-	if err := validateSaleRequest(req); err != nil {
+	serviceReq, err := saleRequestFromProto(req)
+	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	serviceReq.Actor, _ = middleware.ServiceIDFromContext(ctx)
+
+	tx, err := h.service.Sale(ctx, serviceReq)
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	return transactionToPaymentResponse(tx), nil
+}
+
+// saleRequestFromProto validates a sale request and converts it to the service request
+func saleRequestFromProto(req *paymentv1.SaleRequest) (*ports.SaleRequest, error) {
+	if err := validateSaleRequest(req); err != nil {
+		return nil, err
+	}
 
 	serviceReq := &ports.SaleRequest{
-		AgentID:  req.AgentId,
-		Amount:   req.Amount,
-		Currency: req.Currency,
-		Metadata: convertMetadata(req.Metadata),
+		AgentID:             req.AgentId,
+		Amount:              req.Amount,
+		Currency:            req.Currency,
+		Metadata:            convertMetadata(req.Metadata),
+		IncludeTree:         req.IncludeTree,
+		ThreeDS:             threeDSFromProto(req.ThreeDs),
+		StatementDescriptor: req.StatementDescriptor,
 	}
 
 	if req.CustomerId != "" {
 		serviceReq.CustomerID = &req.CustomerId
 	}
+	if req.CustomerEmail != "" {
+		serviceReq.CustomerEmail = &req.CustomerEmail
+	}
 
 	// Handle payment method oneof
 	switch pm := req.PaymentMethod.(type) {
@@ -139,19 +243,71 @@ func (h *Handler) Sale(ctx context.Context, req *paymentv1.SaleRequest) (*paymen
 	case *paymentv1.SaleRequest_PaymentToken:
 		serviceReq.PaymentToken = &pm.PaymentToken
 	default:
-		return nil, status.Error(codes.InvalidArgument, "payment_method is required")
+		return nil, fmt.Errorf("payment_method is required")
 	}
 
 	if req.IdempotencyKey != "" {
 		serviceReq.IdempotencyKey = &req.IdempotencyKey
 	}
 
-	tx, err := h.service.Sale(ctx, serviceReq)
+	return serviceReq, nil
+}
+
+// BatchSale runs several sales for one merchant; failed items are reported per item
+func (h *Handler) BatchSale(ctx context.Context, req *paymentv1.BatchSaleRequest) (*paymentv1.BatchSaleResponse, error) {
+	h.logger.Info("Batch sale request received",
+		zap.String("agent_id", req.AgentId),
+		zap.Int("items", len(req.Items)),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if len(req.Items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "items is required")
+	}
+
+	serviceReq := &ports.BatchSaleRequest{
+		AgentID: req.AgentId,
+		Items:   make([]*ports.SaleRequest, len(req.Items)),
+	}
+	if req.BatchIdempotencyKey != "" {
+		serviceReq.IdempotencyKey = &req.BatchIdempotencyKey
+	}
+
+	actor, _ := middleware.ServiceIDFromContext(ctx)
+	for i, item := range req.Items {
+		if item.AgentId != "" && item.AgentId != req.AgentId {
+			return nil, status.Errorf(codes.InvalidArgument, "items[%d]: agent_id does not match the batch", i)
+		}
+		item.AgentId = req.AgentId
+
+		itemReq, err := saleRequestFromProto(item)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "items[%d]: %v", i, err)
+		}
+		itemReq.Actor = actor
+		serviceReq.Items[i] = itemReq
+	}
+
+	result, err := h.service.BatchSale(ctx, serviceReq)
 	if err != nil {
 		return nil, handleServiceError(err)
 	}
 
-	return transactionToPaymentResponse(tx), nil
+	resp := &paymentv1.BatchSaleResponse{
+		Results:  make([]*paymentv1.BatchSaleItemResult, len(result.Items)),
+		Replayed: result.Replayed,
+	}
+	for i, item := range result.Items {
+		itemResult := &paymentv1.BatchSaleItemResult{Index: int32(i), Error: item.Error}
+		if item.Transaction != nil {
+			itemResult.Payment = transactionToPaymentResponse(item.Transaction)
+		}
+		resp.Results[i] = itemResult
+	}
+
+	return resp, nil
 }
 
 // Void cancels an authorized or captured payment
@@ -164,8 +320,11 @@ func (h *Handler) Void(ctx context.Context, req *paymentv1.VoidRequest) (*paymen
 		return nil, status.Error(codes.InvalidArgument, "transaction_id is required")
 	}
 
+	actor, _ := middleware.ServiceIDFromContext(ctx)
 	serviceReq := &ports.VoidRequest{
 		TransactionID: req.TransactionId,
+		IncludeTree:   req.IncludeTree,
+		Actor:         actor,
 	}
 
 	if req.IdempotencyKey != "" {
@@ -190,9 +349,12 @@ func (h *Handler) Refund(ctx context.Context, req *paymentv1.RefundRequest) (*pa
 		return nil, status.Error(codes.InvalidArgument, "transaction_id is required")
 	}
 
+	actor, _ := middleware.ServiceIDFromContext(ctx)
 	serviceReq := &ports.RefundRequest{
 		TransactionID: req.TransactionId,
 		Reason:        req.Reason,
+		IncludeTree:   req.IncludeTree,
+		Actor:         actor,
 	}
 
 	if req.Amount != "" {
@@ -211,6 +373,43 @@ func (h *Handler) Refund(ctx context.Context, req *paymentv1.RefundRequest) (*pa
 	return transactionToPaymentResponse(tx), nil
 }
 
+// RefundByReference refunds a payment identified by its EPX BRIC or TRAN_NBR
+func (h *Handler) RefundByReference(ctx context.Context, req *paymentv1.RefundByReferenceRequest) (*paymentv1.PaymentResponse, error) {
+	h.logger.Info("Refund by reference request received",
+		zap.String("agent_id", req.AgentId),
+		zap.String("tran_nbr", req.TranNbr),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+
+	actor, _ := middleware.ServiceIDFromContext(ctx)
+	serviceReq := &ports.RefundByReferenceRequest{
+		AgentID:     req.AgentId,
+		AuthGUID:    req.AuthGuid,
+		TranNbr:     req.TranNbr,
+		Reason:      req.Reason,
+		IncludeTree: req.IncludeTree,
+		Actor:       actor,
+	}
+
+	if req.Amount != "" {
+		serviceReq.Amount = &req.Amount
+	}
+
+	if req.IdempotencyKey != "" {
+		serviceReq.IdempotencyKey = &req.IdempotencyKey
+	}
+
+	tx, err := h.service.RefundByReference(ctx, serviceReq)
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	return transactionToPaymentResponse(tx), nil
+}
+
 // GetTransaction retrieves transaction details
 func (h *Handler) GetTransaction(ctx context.Context, req *paymentv1.GetTransactionRequest) (*paymentv1.Transaction, error) {
 	if req.TransactionId == "" {
@@ -228,6 +427,147 @@ func (h *Handler) GetTransaction(ctx context.Context, req *paymentv1.GetTransact
 	return transactionToProto(tx), nil
 }
 
+// GetEstimatedFees estimates the processing fee for a transaction
+func (h *Handler) GetEstimatedFees(ctx context.Context, req *paymentv1.GetEstimatedFeesRequest) (*paymentv1.FeeEstimate, error) {
+	if req.TransactionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "transaction_id is required")
+	}
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+
+	estimate, err := h.service.GetEstimatedFees(ctx, req.TransactionId)
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	// Verify agent authorization
+	if err := h.validateTransactionAccess(req.TransactionId, estimate.AgentID, req.AgentId); err != nil {
+		return nil, err
+	}
+
+	return feeEstimateToProto(estimate), nil
+}
+
+// validateTransactionAccess rejects access to a transaction owned by another merchant
+func (h *Handler) validateTransactionAccess(transactionID, ownerAgentID, requestedAgentID string) error {
+	if ownerAgentID == requestedAgentID {
+		return nil
+	}
+
+	h.logger.Warn("Unauthorized transaction access attempt",
+		zap.String("transaction_id", transactionID),
+		zap.String("requested_agent", requestedAgentID),
+	)
+	return status.Error(codes.PermissionDenied, "not authorized to access this transaction")
+}
+
+// BatchGetTransactions returns the merchant's transactions for a batch of IDs.
+// Transactions the merchant can't access are omitted (and listed as missing) instead of failing the call.
+func (h *Handler) BatchGetTransactions(ctx context.Context, req *paymentv1.BatchGetTransactionsRequest) (*paymentv1.BatchGetTransactionsResponse, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if len(req.TransactionIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "transaction_ids is required")
+	}
+
+	txs, err := h.service.BatchGetTransactions(ctx, req.TransactionIds)
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	byID := make(map[string]*domain.Transaction, len(txs))
+	for _, tx := range txs {
+		if h.validateTransactionAccess(tx.ID, tx.AgentID, req.AgentId) == nil {
+			byID[tx.ID] = tx
+		}
+	}
+
+	resp := &paymentv1.BatchGetTransactionsResponse{
+		Transactions:          make([]*paymentv1.Transaction, 0, len(byID)),
+		MissingTransactionIds: []string{},
+	}
+	for _, id := range req.TransactionIds {
+		var tx *domain.Transaction
+		if parsed, err := uuid.Parse(id); err == nil {
+			tx = byID[parsed.String()]
+		}
+		if tx == nil {
+			resp.MissingTransactionIds = append(resp.MissingTransactionIds, id)
+			continue
+		}
+		resp.Transactions = append(resp.Transactions, transactionToProto(tx))
+	}
+
+	return resp, nil
+}
+
+// GetTransactionStatuses returns the current status of a batch of the merchant's transactions
+func (h *Handler) GetTransactionStatuses(ctx context.Context, req *paymentv1.GetTransactionStatusesRequest) (*paymentv1.GetTransactionStatusesResponse, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if len(req.TransactionIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "transaction_ids is required")
+	}
+
+	results, err := h.service.GetTransactionStatuses(ctx, req.AgentId, req.TransactionIds)
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	protoResults := make([]*paymentv1.TransactionStatusResult, len(results))
+	for i, result := range results {
+		protoResults[i] = transactionStatusResultToProto(result)
+	}
+
+	return &paymentv1.GetTransactionStatusesResponse{Results: protoResults}, nil
+}
+
+// ClassifyDeclineCode previews the decline taxonomy for an EPX response code
+func (h *Handler) ClassifyDeclineCode(ctx context.Context, req *paymentv1.ClassifyDeclineCodeRequest) (*paymentv1.DeclineClassification, error) {
+	if req.AuthResp == "" {
+		return nil, status.Error(codes.InvalidArgument, "auth_resp is required")
+	}
+
+	result, err := h.service.ClassifyDeclineCode(ctx, req.AuthResp, req.AuthRespText)
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	return &paymentv1.DeclineClassification{
+		AuthResp:        req.AuthResp,
+		Approved:        result.Approved,
+		DeclineCode:     result.DeclineCode,
+		DeclineCategory: result.DeclineCategory,
+		DeclineReason:   result.DeclineReason,
+		Severity:        result.DeclineSeverity(),
+		Retriable:       result.Retriable,
+	}, nil
+}
+
+// GetSettlementSummary totals a merchant's sales, refunds and voids per day (UTC) for an inclusive date range
+func (h *Handler) GetSettlementSummary(ctx context.Context, req *paymentv1.GetSettlementSummaryRequest) (*paymentv1.GetSettlementSummaryResponse, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.StartDate == nil || req.EndDate == nil {
+		return nil, status.Error(codes.InvalidArgument, "start_date and end_date are required")
+	}
+
+	days, err := h.service.GetSettlementSummary(ctx, req.AgentId, req.StartDate.AsTime(), req.EndDate.AsTime())
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	resp := &paymentv1.GetSettlementSummaryResponse{Days: make([]*paymentv1.SettlementDay, len(days))}
+	for i, day := range days {
+		resp.Days[i] = settlementDayToProto(day)
+	}
+	return resp, nil
+}
+
 // ListTransactions lists transactions for a merchant or customer
 func (h *Handler) ListTransactions(ctx context.Context, req *paymentv1.ListTransactionsRequest) (*paymentv1.ListTransactionsResponse, error) {
 	if req.AgentId == "" {
@@ -259,25 +599,61 @@ func (h *Handler) ListTransactions(ctx context.Context, req *paymentv1.ListTrans
 	}
 	offset := int(req.Offset)
 
-	var customerID *string
+	filter := domain.TransactionFilter{
+		MetadataContains: req.MetadataContains,
+		MinAmountCents:   req.MinAmountCents,
+		MaxAmountCents:   req.MaxAmountCents,
+	}
 	if req.CustomerId != "" {
-		customerID = &req.CustomerId
+		filter.CustomerID = &req.CustomerId
 	}
+	if err := filter.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Cursor pagination (stable under concurrent inserts; no total count)
+	if req.Cursor != "" {
+		if offset != 0 {
+			return nil, status.Error(codes.InvalidArgument, "cursor and offset cannot be combined")
+		}
 
-	txs, totalCount, err := h.service.ListTransactions(ctx, req.AgentId, customerID, limit, offset)
+		txs, nextCursor, err := h.service.ListTransactionsByCursor(ctx, req.AgentId, filter, limit, req.Cursor)
+		if err != nil {
+			return nil, handleServiceError(err)
+		}
+
+		return &paymentv1.ListTransactionsResponse{
+			Transactions: transactionsToProto(txs),
+			NextCursor:   nextCursor,
+		}, nil
+	}
+
+	txs, totalCount, err := h.service.ListTransactions(ctx, req.AgentId, filter, limit, offset)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to list transactions")
 	}
 
+	resp := &paymentv1.ListTransactionsResponse{
+		Transactions: transactionsToProto(txs),
+		TotalCount:   int32(totalCount),
+	}
+
+	// A full offset page also returns a cursor so clients can switch to cursor pagination
+	if len(txs) == limit {
+		if cursor, err := domain.CursorAfter(txs[len(txs)-1]); err == nil {
+			resp.NextCursor = cursor.Encode()
+		}
+	}
+
+	return resp, nil
+}
+
+func transactionsToProto(txs []*domain.Transaction) []*paymentv1.Transaction {
 	protoTxs := make([]*paymentv1.Transaction, len(txs))
 	for i, tx := range txs {
 		protoTxs[i] = transactionToProto(tx)
 	}
-
-	return &paymentv1.ListTransactionsResponse{
-		Transactions: protoTxs,
-		TotalCount:   int32(totalCount),
-	}, nil
+	return protoTxs
 }
 
 // Validation helpers
@@ -329,55 +705,186 @@ func convertMetadata(meta map[string]string) map[string]interface{} {
 
 func transactionToPaymentResponse(tx *domain.Transaction) *paymentv1.PaymentResponse {
 	return &paymentv1.PaymentResponse{
-		TransactionId:     tx.ID,
-		GroupId:           tx.GroupID,
-		AgentId:           tx.AgentID,
-		CustomerId:        stringPtrToString(tx.CustomerID),
-		Amount:            tx.Amount.String(),
-		Currency:          string(tx.Currency),
-		Status:            transactionStatusToProto(tx.Status),
-		Type:              transactionTypeToProto(tx.Type),
-		PaymentMethodType: paymentMethodTypeToProto(tx.PaymentMethodType),
-		AuthGuid:          stringPtrToString(tx.AuthGUID),
-		AuthResp:          stringPtrToString(tx.AuthResp),
-		AuthCode:          stringPtrToString(tx.AuthCode),
-		AuthRespText:      stringPtrToString(tx.AuthRespText),
-		AuthCardType:      stringPtrToString(tx.AuthCardType),
-		AuthAvs:           stringPtrToString(tx.AuthAVS),
-		AuthCvv2:          stringPtrToString(tx.AuthCVV2),
-		IsApproved:        tx.IsApproved(),
-		CreatedAt:         timestamppb.New(tx.CreatedAt),
-		Metadata:          convertMetadataToProto(tx.Metadata),
+		TransactionId:       tx.ID,
+		GroupId:             tx.GroupID,
+		AgentId:             tx.AgentID,
+		CustomerId:          stringPtrToString(tx.CustomerID),
+		Amount:              tx.Amount.String(),
+		Currency:            string(tx.Currency),
+		Status:              transactionStatusToProto(tx.Status),
+		Type:                transactionTypeToProto(tx.Type),
+		PaymentMethodType:   paymentMethodTypeToProto(tx.PaymentMethodType),
+		AuthGuid:            stringPtrToString(tx.AuthGUID),
+		AuthResp:            stringPtrToString(tx.AuthResp),
+		AuthCode:            stringPtrToString(tx.AuthCode),
+		AuthRespText:        stringPtrToString(tx.AuthRespText),
+		AuthCardType:        stringPtrToString(tx.AuthCardType),
+		AuthAvs:             stringPtrToString(tx.AuthAVS),
+		AuthCvv2:            stringPtrToString(tx.AuthCVV2),
+		IsApproved:          tx.IsApproved(),
+		CreatedAt:           timestamppb.New(tx.CreatedAt),
+		Metadata:            convertMetadataToProto(tx.Metadata),
+		Gateway:             gatewayResultToProto(tx.GatewayResult()),
+		Tree:                transactionTreeToProto(tx.Tree),
+		VerificationOutcome: verificationOutcomeToProto(tx.VerificationOutcome),
+		ThreeDs:             threeDSToProto(tx.ThreeDS),
+	}
+}
+
+// threeDSFromProto converts the request's 3-D Secure result (nil when not run)
+func threeDSFromProto(threeDS *paymentv1.ThreeDSecure) *domain.ThreeDSecure {
+	if threeDS == nil {
+		return nil
+	}
+	return &domain.ThreeDSecure{
+		Version:           threeDS.Version,
+		CAVV:              threeDS.Cavv,
+		ECI:               threeDS.Eci,
+		TransactionStatus: threeDS.TransactionStatus,
+		DSTransactionID:   threeDS.DsTransactionId,
+	}
+}
+
+// threeDSToProto converts a stored 3-D Secure result (nil when not run)
+func threeDSToProto(threeDS *domain.ThreeDSecure) *paymentv1.ThreeDSecure {
+	if threeDS == nil {
+		return nil
+	}
+	return &paymentv1.ThreeDSecure{
+		Version:           threeDS.Version,
+		Cavv:              threeDS.CAVV,
+		Eci:               threeDS.ECI,
+		TransactionStatus: threeDS.TransactionStatus,
+		DsTransactionId:   threeDS.DSTransactionID,
+	}
+}
+
+// verificationOutcomeToProto converts the AVS/CVV policy outcome (nil when no policy was configured)
+func verificationOutcomeToProto(outcome *domain.VerificationPolicyOutcome) *paymentv1.VerificationOutcome {
+	if outcome == nil {
+		return nil
+	}
+	return &paymentv1.VerificationOutcome{
+		Result:       outcome.Result,
+		FailedChecks: outcome.FailedChecks,
+		AvsPolicy:    string(outcome.AVSPolicy),
+		AvsResult:    outcome.AVSResult,
+		CvvPolicy:    string(outcome.CVVPolicy),
+		CvvResult:    outcome.CVVResult,
+	}
+}
+
+// transactionTreeToProto converts the transaction group tree (nil when the response is lean)
+func transactionTreeToProto(tree *domain.TransactionTree) *paymentv1.TransactionTree {
+	if tree == nil {
+		return nil
+	}
+
+	proto := &paymentv1.TransactionTree{
+		Children: make([]*paymentv1.Transaction, len(tree.Children)),
+		State: &paymentv1.TransactionGroupState{
+			Status:            tree.State.Status,
+			AuthorizedAmount:  tree.State.AuthorizedAmount.String(),
+			CapturedAmount:    tree.State.CapturedAmount.String(),
+			RefundedAmount:    tree.State.RefundedAmount.String(),
+			CapturableAmount:  tree.State.CapturableAmount.String(),
+			RefundableAmount:  tree.State.RefundableAmount.String(),
+			Voided:            tree.State.Voided,
+			ReversedAmount:    tree.State.ReversedAmount.String(),
+			ActiveAuthAmount:  tree.State.ActiveAuthAmount.String(),
+			IncrementedAmount: tree.State.IncrementedAmount.String(),
+		},
+	}
+	if !tree.State.AuthExpiresAt.IsZero() {
+		proto.State.AuthExpiresAt = timestamppb.New(tree.State.AuthExpiresAt)
+	}
+	if tree.Root != nil {
+		proto.Root = transactionToProto(tree.Root)
+	}
+	for i, child := range tree.Children {
+		proto.Children[i] = transactionToProto(child)
+	}
+	return proto
+}
+
+func gatewayResultToProto(result *domain.GatewayResult) *paymentv1.GatewayResult {
+	return &paymentv1.GatewayResult{
+		ResponseCode:    result.ResponseCode,
+		ResponseText:    result.ResponseText,
+		AuthCode:        result.AuthCode,
+		Approved:        result.Approved,
+		CardType:        result.CardType,
+		AvsCode:         result.AVSCode,
+		AvsResult:       result.AVSResult,
+		CvvCode:         result.CVVCode,
+		CvvResult:       result.CVVResult,
+		DeclineCode:     result.DeclineCode,
+		DeclineCategory: result.DeclineCategory,
+		DeclineReason:   result.DeclineReason,
+		Retriable:       result.Retriable,
+		LatencyMs:       result.Latency.Milliseconds(),
 	}
 }
 
 func transactionToProto(tx *domain.Transaction) *paymentv1.Transaction {
 	proto := &paymentv1.Transaction{
-		Id:                tx.ID,
-		GroupId:           tx.GroupID,
-		AgentId:           tx.AgentID,
-		CustomerId:        stringPtrToString(tx.CustomerID),
-		Amount:            tx.Amount.String(),
-		Currency:          string(tx.Currency),
-		Status:            transactionStatusToProto(tx.Status),
-		Type:              transactionTypeToProto(tx.Type),
-		PaymentMethodType: paymentMethodTypeToProto(tx.PaymentMethodType),
-		AuthGuid:          stringPtrToString(tx.AuthGUID),
-		AuthResp:          stringPtrToString(tx.AuthResp),
-		AuthCode:          stringPtrToString(tx.AuthCode),
-		AuthRespText:      stringPtrToString(tx.AuthRespText),
-		AuthCardType:      stringPtrToString(tx.AuthCardType),
-		AuthAvs:           stringPtrToString(tx.AuthAVS),
-		AuthCvv2:          stringPtrToString(tx.AuthCVV2),
-		IdempotencyKey:    stringPtrToString(tx.IdempotencyKey),
-		CreatedAt:         timestamppb.New(tx.CreatedAt),
-		UpdatedAt:         timestamppb.New(tx.UpdatedAt),
-		Metadata:          convertMetadataToProto(tx.Metadata),
+		Id:                  tx.ID,
+		GroupId:             tx.GroupID,
+		AgentId:             tx.AgentID,
+		CustomerId:          stringPtrToString(tx.CustomerID),
+		Amount:              tx.Amount.String(),
+		Currency:            string(tx.Currency),
+		Status:              transactionStatusToProto(tx.Status),
+		Type:                transactionTypeToProto(tx.Type),
+		PaymentMethodType:   paymentMethodTypeToProto(tx.PaymentMethodType),
+		AuthGuid:            stringPtrToString(tx.AuthGUID),
+		AuthResp:            stringPtrToString(tx.AuthResp),
+		AuthCode:            stringPtrToString(tx.AuthCode),
+		AuthRespText:        stringPtrToString(tx.AuthRespText),
+		AuthCardType:        stringPtrToString(tx.AuthCardType),
+		AuthAvs:             stringPtrToString(tx.AuthAVS),
+		AuthCvv2:            stringPtrToString(tx.AuthCVV2),
+		IdempotencyKey:      stringPtrToString(tx.IdempotencyKey),
+		CreatedAt:           timestamppb.New(tx.CreatedAt),
+		UpdatedAt:           timestamppb.New(tx.UpdatedAt),
+		Metadata:            convertMetadataToProto(tx.Metadata),
+		VerificationOutcome: verificationOutcomeToProto(tx.VerificationOutcome),
+		ThreeDs:             threeDSToProto(tx.ThreeDS),
+		DataRegion:          tx.DataRegion,
 	}
 
 	if tx.PaymentMethodID != nil {
 		proto.PaymentMethodId = *tx.PaymentMethodID
 	}
+	if tx.SettledAt != nil {
+		proto.SettledAt = timestamppb.New(*tx.SettledAt)
+	}
+	if tx.FundingDate != nil {
+		proto.FundingDate = tx.FundingDate.Format("2006-01-02")
+	}
+
+	return proto
+}
+
+func transactionStatusResultToProto(result *domain.TransactionStatusResult) *paymentv1.TransactionStatusResult {
+	proto := &paymentv1.TransactionStatusResult{
+		TransactionId: result.TransactionID,
+		Found:         result.Found(),
+	}
+
+	if tx := result.Transaction; tx != nil {
+		proto.Status = transactionStatusToProto(tx.Status)
+		proto.Type = transactionTypeToProto(tx.Type)
+		proto.Amount = tx.Amount.String()
+		proto.GroupId = tx.GroupID
+		proto.UpdatedAt = timestamppb.New(tx.UpdatedAt)
+		if tx.SettledAt != nil {
+			proto.SettledAt = timestamppb.New(*tx.SettledAt)
+		}
+		if tx.FundingDate != nil {
+			proto.FundingDate = tx.FundingDate.Format("2006-01-02")
+		}
+	}
 
 	return proto
 }
@@ -394,6 +901,8 @@ func transactionStatusToProto(status domain.TransactionStatus) paymentv1.Transac
 		return paymentv1.TransactionStatus_TRANSACTION_STATUS_REFUNDED
 	case domain.TransactionStatusVoided:
 		return paymentv1.TransactionStatus_TRANSACTION_STATUS_VOIDED
+	case domain.TransactionStatusExpired:
+		return paymentv1.TransactionStatus_TRANSACTION_STATUS_EXPIRED
 	default:
 		return paymentv1.TransactionStatus_TRANSACTION_STATUS_UNSPECIFIED
 	}
@@ -411,6 +920,10 @@ func transactionTypeToProto(txType domain.TransactionType) paymentv1.Transaction
 		return paymentv1.TransactionType_TRANSACTION_TYPE_REFUND
 	case domain.TransactionTypePreNote:
 		return paymentv1.TransactionType_TRANSACTION_TYPE_PRE_NOTE
+	case domain.TransactionTypeReversal:
+		return paymentv1.TransactionType_TRANSACTION_TYPE_REVERSAL
+	case domain.TransactionTypeIncrement:
+		return paymentv1.TransactionType_TRANSACTION_TYPE_INCREMENT
 	default:
 		return paymentv1.TransactionType_TRANSACTION_TYPE_UNSPECIFIED
 	}
@@ -447,35 +960,138 @@ func convertMetadataToProto(meta map[string]interface{}) map[string]string {
 
 // Error handling
 
+// feeEstimateToProto converts a domain fee estimate to proto
+func feeEstimateToProto(estimate *domain.FeeEstimate) *paymentv1.FeeEstimate {
+	return &paymentv1.FeeEstimate{
+		TransactionId: estimate.TransactionID,
+		CardBrand:     estimate.CardBrand,
+		FundingType:   string(estimate.FundingType),
+		Amount:        estimate.Amount.StringFixed(2),
+		RatePercent:   estimate.Rate.Percent.String(),
+		RateFixed:     estimate.Rate.Fixed.StringFixed(2),
+		EstimatedFee:  estimate.EstimatedFee.StringFixed(2),
+		IsDefaultRate: estimate.IsDefaultRate,
+		IsEstimate:    estimate.IsEstimate,
+	}
+}
+
+// settlementDayToProto converts a domain settlement day to proto
+func settlementDayToProto(day *domain.SettlementDay) *paymentv1.SettlementDay {
+	return &paymentv1.SettlementDay{
+		Date:        day.Date.Format(time.DateOnly),
+		Currency:    day.Currency,
+		GrossSales:  day.GrossSales.StringFixed(2),
+		SaleCount:   int32(day.SaleCount),
+		Refunds:     day.Refunds.StringFixed(2),
+		RefundCount: int32(day.RefundCount),
+		Voids:       day.Voids.StringFixed(2),
+		VoidCount:   int32(day.VoidCount),
+		Net:         day.Net.StringFixed(2),
+	}
+}
+
 func handleServiceError(err error) error {
+	var paymentErr *pkgerrors.PaymentError
+
 	// Map domain errors to gRPC status codes
 	switch {
+	case errors.Is(err, domain.ErrMerchantSuspended):
+		return status.Error(codes.FailedPrecondition, "merchant is suspended")
+	case errors.Is(err, domain.ErrMerchantClosed):
+		return status.Error(codes.FailedPrecondition, "merchant is closed")
 	case errors.Is(err, domain.ErrAgentInactive):
 		return status.Error(codes.FailedPrecondition, "agent is inactive")
+	case errors.Is(err, domain.ErrEnvironmentMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
 	case errors.Is(err, domain.ErrPaymentMethodNotFound):
 		return status.Error(codes.NotFound, "payment method not found")
+	case errors.Is(err, domain.ErrCustomerNotFound):
+		return status.Error(codes.NotFound, err.Error())
 	case errors.Is(err, domain.ErrTransactionCannotBeVoided):
 		return status.Error(codes.FailedPrecondition, "transaction cannot be voided")
 	case errors.Is(err, domain.ErrTransactionCannotBeCaptured):
 		return status.Error(codes.FailedPrecondition, "transaction cannot be captured")
 	case errors.Is(err, domain.ErrTransactionCannotBeRefunded):
 		return status.Error(codes.FailedPrecondition, "transaction cannot be refunded")
+	case errors.Is(err, domain.ErrTransactionCannotBeReversed):
+		return status.Error(codes.FailedPrecondition, "authorization cannot be partially reversed")
+	case errors.Is(err, domain.ErrTransactionCannotBeIncremented):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrAmountExceedsAuthorization):
+		return status.Error(codes.FailedPrecondition, "amount exceeds the remaining authorization")
+	case errors.Is(err, domain.ErrAmountExceedsRefundable):
+		return status.Error(codes.FailedPrecondition, "amount exceeds the captured amount not yet refunded")
+	case errors.Is(err, domain.ErrInvalidTransactionAmount):
+		return status.Error(codes.InvalidArgument, "invalid transaction amount")
+	case errors.Is(err, domain.ErrAmountOutOfRange):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrTransactionNotSettled):
+		return status.Error(codes.FailedPrecondition, "transaction is not settled yet; void it instead of refunding")
 	case errors.Is(err, domain.ErrTransactionNotFound):
 		return status.Error(codes.NotFound, "transaction not found")
 	case errors.Is(err, domain.ErrTransactionDeclined):
 		return status.Error(codes.Aborted, "transaction was declined")
+	case errors.Is(err, domain.ErrFraudDeclined):
+		return status.Error(codes.PermissionDenied, "transaction was declined by fraud screening")
+	case errors.Is(err, domain.ErrVelocityExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, domain.ErrChargesDisabled):
+		// The flag's reason is for operators (logged by the service), not callers
+		return status.Error(codes.Unavailable, "charges are temporarily unavailable")
+	case errors.Is(err, domain.ErrDailyVolumeLimitExceeded):
+		return status.Error(codes.ResourceExhausted, "daily volume limit exceeded")
 	case errors.Is(err, domain.ErrInvalidAmount):
 		return status.Error(codes.InvalidArgument, "invalid amount")
 	case errors.Is(err, domain.ErrInvalidCurrency):
 		return status.Error(codes.InvalidArgument, "invalid currency")
+	case errors.Is(err, domain.ErrBatchTooLarge):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, "invalid cursor")
+	case errors.Is(err, domain.ErrInvalidFilter):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrIdempotencyKeyConflict):
+		return status.Error(codes.AlreadyExists, "idempotency key was already used with different request parameters")
 	case errors.Is(err, domain.ErrDuplicateIdempotencyKey):
 		return status.Error(codes.AlreadyExists, "duplicate idempotency key")
+	case errors.Is(err, domain.ErrSaleBatchInProgress):
+		return status.Error(codes.Aborted, "batch is still being processed; retry later")
+	case errors.Is(err, domain.ErrMissingRequiredField):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrInvalidReference):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrInvalidThreeDSecure):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrInvalidStatementDescriptor):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrInvalidMerchantConfig):
+		return status.Error(codes.FailedPrecondition, "merchant configuration is invalid")
 	case errors.Is(err, sql.ErrNoRows):
 		return status.Error(codes.NotFound, "resource not found")
-	case err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+	case errors.Is(err, context.DeadlineExceeded):
+		// The caller's deadline (or the EPX timeout) passed mid-call; EPX may still have processed it
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded waiting for the payment gateway; check the transaction status before retrying")
+	case errors.Is(err, context.Canceled):
 		return status.Error(codes.Canceled, "request canceled")
+	case errors.As(err, &paymentErr):
+		return gatewayErrorStatus(paymentErr)
 	default:
 		// Log internal errors but don't expose details to client
 		return status.Error(codes.Internal, "internal server error")
 	}
 }
+
+// gatewayErrorStatus maps a categorized gateway error to a gRPC status.
+// Retriable failures return Unavailable so clients can distinguish them from hard declines.
+func gatewayErrorStatus(paymentErr *pkgerrors.PaymentError) error {
+	switch {
+	case paymentErr.IsDecline():
+		return status.Errorf(codes.FailedPrecondition, "payment declined: %s", paymentErr.Category)
+	case paymentErr.Category == pkgerrors.CategoryInvalidRequest:
+		return status.Error(codes.InvalidArgument, "gateway rejected the request")
+	case paymentErr.IsRetriable:
+		return status.Error(codes.Unavailable, "payment gateway temporarily unavailable")
+	default:
+		return status.Error(codes.Internal, "payment gateway error")
+	}
+}
@@ -0,0 +1,207 @@
+package payment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kevin07696/payment-service/internal/domain"
+	serviceports "github.com/kevin07696/payment-service/internal/services/ports"
+	paymentv1 "github.com/kevin07696/payment-service/proto/payment/v1"
+)
+
+// fakeCaptureService captures half of a $100 authorization, attaching the group tree when asked
+type fakeCaptureService struct {
+	serviceports.PaymentService
+	lastReq *serviceports.CaptureRequest
+}
+
+func (f *fakeCaptureService) Capture(ctx context.Context, req *serviceports.CaptureRequest) (*domain.Transaction, error) {
+	f.lastReq = req
+	now := time.Now()
+	auth := &domain.Transaction{ID: "auth-1", GroupID: "group-1", Amount: decimal.NewFromInt(100), Status: domain.TransactionStatusCompleted, Type: domain.TransactionTypeAuth, CreatedAt: now.Add(-time.Minute)}
+	capture := &domain.Transaction{ID: "capture-1", GroupID: "group-1", Amount: decimal.NewFromInt(50), Status: domain.TransactionStatusCompleted, Type: domain.TransactionTypeCapture, CreatedAt: now}
+
+	if req.IncludeTree != nil && *req.IncludeTree {
+		capture.Tree = domain.BuildTransactionTree([]*domain.Transaction{auth, capture})
+	}
+	return capture, nil
+}
+
+func TestCapture_IncludeTree(t *testing.T) {
+	t.Run("lean by default", func(t *testing.T) {
+		service := &fakeCaptureService{}
+		h := NewHandler(service, zap.NewNop())
+
+		resp, err := h.Capture(context.Background(), &paymentv1.CaptureRequest{TransactionId: "auth-1", Amount: "50"})
+		require.NoError(t, err)
+		assert.Nil(t, service.lastReq.IncludeTree, "unset flag leaves the choice to the merchant default")
+		assert.Nil(t, resp.Tree)
+	})
+
+	t.Run("tree included on request", func(t *testing.T) {
+		service := &fakeCaptureService{}
+		h := NewHandler(service, zap.NewNop())
+
+		resp, err := h.Capture(context.Background(), &paymentv1.CaptureRequest{TransactionId: "auth-1", Amount: "50", IncludeTree: proto.Bool(true)})
+		require.NoError(t, err)
+		require.NotNil(t, service.lastReq.IncludeTree)
+		assert.True(t, *service.lastReq.IncludeTree)
+
+		require.NotNil(t, resp.Tree)
+		assert.Equal(t, "auth-1", resp.Tree.Root.Id)
+		require.Len(t, resp.Tree.Children, 1)
+		assert.Equal(t, "capture-1", resp.Tree.Children[0].Id)
+		assert.Equal(t, domain.GroupStatusCaptured, resp.Tree.State.Status)
+		assert.Equal(t, "50", resp.Tree.State.CapturedAmount)
+		assert.Equal(t, "50", resp.Tree.State.CapturableAmount)
+		assert.Equal(t, "50", resp.Tree.State.RefundableAmount)
+	})
+}
+
+// fakeDisabledService refuses every sale the way the kill switch does, with the operator's reason
+type fakeDisabledService struct {
+	serviceports.PaymentService
+}
+
+func (f *fakeDisabledService) Sale(ctx context.Context, req *serviceports.SaleRequest) (*domain.Transaction, error) {
+	return nil, domain.CheckChargesEnabled([]domain.FeatureFlag{
+		{Name: domain.FeatureFlagChargesEnabled, Enabled: false, Reason: "INC-4821 acquirer outage"},
+	})
+}
+
+func TestSale_ChargesDisabledHidesReason(t *testing.T) {
+	h := NewHandler(&fakeDisabledService{}, zap.NewNop())
+
+	_, err := h.Sale(context.Background(), &paymentv1.SaleRequest{
+		AgentId:       "merchant-1",
+		Amount:        "10.00",
+		Currency:      "USD",
+		PaymentMethod: &paymentv1.SaleRequest_PaymentMethodId{PaymentMethodId: uuid.NewString()},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, "charges are temporarily unavailable", status.Convert(err).Message())
+}
+
+// fakeListService returns one full offset page and records cursor requests and filters
+type fakeListService struct {
+	serviceports.PaymentService
+	cursor string
+	filter domain.TransactionFilter
+}
+
+func (f *fakeListService) ListTransactions(ctx context.Context, agentID string, filter domain.TransactionFilter, limit, offset int) ([]*domain.Transaction, int, error) {
+	f.filter = filter
+	txs := make([]*domain.Transaction, limit)
+	for i := range txs {
+		txs[i] = &domain.Transaction{ID: uuid.NewString(), CreatedAt: time.Now().Add(-time.Duration(i) * time.Minute)}
+	}
+	return txs, limit * 3, nil
+}
+
+func (f *fakeListService) ListTransactionsByCursor(ctx context.Context, agentID string, filter domain.TransactionFilter, limit int, cursor string) ([]*domain.Transaction, string, error) {
+	f.cursor = cursor
+	f.filter = filter
+	if _, err := domain.DecodeTransactionCursor(cursor); err != nil {
+		return nil, "", err
+	}
+	return []*domain.Transaction{}, "", nil
+}
+
+func TestListTransactions_CursorOrOffset(t *testing.T) {
+	service := &fakeListService{}
+	h := NewHandler(service, zap.NewNop())
+	ctx := context.Background()
+
+	first, err := h.ListTransactions(ctx, &paymentv1.ListTransactionsRequest{AgentId: "agent-1", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int32(6), first.TotalCount, "offset path keeps the total count")
+	require.NotEmpty(t, first.NextCursor, "a full offset page hands out a cursor")
+
+	_, err = h.ListTransactions(ctx, &paymentv1.ListTransactionsRequest{AgentId: "agent-1", Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, first.NextCursor, service.cursor)
+
+	_, err = h.ListTransactions(ctx, &paymentv1.ListTransactionsRequest{AgentId: "agent-1", Cursor: first.NextCursor, Offset: 10})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "cursor and offset are exclusive")
+
+	_, err = h.ListTransactions(ctx, &paymentv1.ListTransactionsRequest{AgentId: "agent-1", Cursor: "garbage"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestListTransactions_MetadataAndAmountFilters(t *testing.T) {
+	service := &fakeListService{}
+	h := NewHandler(service, zap.NewNop())
+	ctx := context.Background()
+	minCents, maxCents := int64(1000), int64(5000)
+
+	_, err := h.ListTransactions(ctx, &paymentv1.ListTransactionsRequest{
+		AgentId:          "agent-1",
+		CustomerId:       "cust-1",
+		MetadataContains: map[string]string{"order_id": "A-1001"},
+		MinAmountCents:   &minCents,
+		MaxAmountCents:   &maxCents,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, service.filter.CustomerID)
+	assert.Equal(t, "cust-1", *service.filter.CustomerID)
+	assert.Equal(t, map[string]string{"order_id": "A-1001"}, service.filter.MetadataContains)
+	assert.Equal(t, &minCents, service.filter.MinAmountCents)
+	assert.Equal(t, &maxCents, service.filter.MaxAmountCents)
+
+	_, err = h.ListTransactions(ctx, &paymentv1.ListTransactionsRequest{
+		AgentId:        "agent-1",
+		MinAmountCents: &maxCents,
+		MaxAmountCents: &minCents,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "inverted amount range")
+}
+
+// fakeBatchService returns the stored transactions for the requested IDs, regardless of merchant
+type fakeBatchService struct {
+	serviceports.PaymentService
+	txs map[string]*domain.Transaction
+}
+
+func (f *fakeBatchService) BatchGetTransactions(ctx context.Context, transactionIDs []string) ([]*domain.Transaction, error) {
+	var found []*domain.Transaction
+	for _, id := range transactionIDs {
+		if tx, ok := f.txs[id]; ok {
+			found = append(found, tx)
+		}
+	}
+	return found, nil
+}
+
+func TestBatchGetTransactions_MixedBatch(t *testing.T) {
+	own1, own2, foreign, missing := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+	service := &fakeBatchService{txs: map[string]*domain.Transaction{
+		own1:    {ID: own1, AgentID: "agent-1", Amount: decimal.NewFromInt(10)},
+		own2:    {ID: own2, AgentID: "agent-1", Amount: decimal.NewFromInt(20)},
+		foreign: {ID: foreign, AgentID: "agent-2", Amount: decimal.NewFromInt(30)},
+	}}
+	h := NewHandler(service, zap.NewNop())
+
+	resp, err := h.BatchGetTransactions(context.Background(), &paymentv1.BatchGetTransactionsRequest{
+		AgentId:        "agent-1",
+		TransactionIds: []string{own2, foreign, missing, own1},
+	})
+	require.NoError(t, err, "inaccessible IDs don't fail the batch")
+	require.Len(t, resp.Transactions, 2)
+	assert.Equal(t, own2, resp.Transactions[0].Id, "request order is preserved")
+	assert.Equal(t, own1, resp.Transactions[1].Id)
+	assert.Equal(t, []string{foreign, missing}, resp.MissingTransactionIds, "other merchants' transactions look missing")
+
+	_, err = h.BatchGetTransactions(context.Background(), &paymentv1.BatchGetTransactionsRequest{AgentId: "agent-1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,189 @@
+package payment
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/pkg/middleware"
+)
+
+// exportPageSize is how many transactions the export reads and writes at a time
+const exportPageSize = 500
+
+// transactionExportHeader is the CSV export's header row
+var transactionExportHeader = []string{"id", "type", "status", "amount", "currency", "card_type", "created_at", "group_id"}
+
+// TransactionPageLister pages through a merchant's transactions (the payment service's ListTransactionsByCursor)
+type TransactionPageLister interface {
+	ListTransactionsByCursor(ctx context.Context, agentID string, filter domain.TransactionFilter, limit int, cursor string) ([]*domain.Transaction, string, error)
+}
+
+// TransactionExportHandler streams a merchant's transactions as CSV for bookkeeping
+type TransactionExportHandler struct {
+	transactions TransactionPageLister
+	access       middleware.MerchantAccessFunc // nil = every merchant is denied
+	logger       *zap.Logger
+}
+
+// NewTransactionExportHandler creates a new transaction export handler
+func NewTransactionExportHandler(
+	transactions TransactionPageLister,
+	access middleware.MerchantAccessFunc,
+	logger *zap.Logger,
+) *TransactionExportHandler {
+	return &TransactionExportHandler{
+		transactions: transactions,
+		access:       access,
+		logger:       logger,
+	}
+}
+
+// Export writes the merchant's transactions created in [from, to] as CSV, newest first
+// Endpoint: GET /api/v1/transactions/export?merchant_id=merchant-1&from=2025-06-01&to=2025-06-30
+// from and to are dates (to inclusive) or RFC 3339 times (to exclusive). Transactions are read a page at a time
+// and each page is flushed to the client, so the export never holds the whole range in memory.
+// The calling service must be authenticated and granted the merchant; otherwise the export is 403.
+func (h *TransactionExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	merchantID := query.Get("merchant_id")
+	if merchantID == "" {
+		http.Error(w, "merchant_id is required", http.StatusBadRequest)
+		return
+	}
+
+	var filter domain.TransactionFilter
+	var err error
+	if filter.CreatedFrom, err = parseExportTime("from", query.Get("from"), false); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.CreatedTo, err = parseExportTime("to", query.Get("to"), true); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := filter.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Only an authenticated service granted the merchant may export; without service auth or grants, nobody may
+	serviceID, authenticated := middleware.ServiceIDFromContext(r.Context())
+	allowed := false
+	if authenticated && h.access != nil {
+		if allowed, err = h.access(r.Context(), serviceID, merchantID); err != nil {
+			h.logger.Error("Failed to check merchant access",
+				zap.String("service_id", serviceID),
+				zap.String("agent_id", merchantID),
+				zap.Error(err),
+			)
+			http.Error(w, "merchant access check failed", http.StatusServiceUnavailable)
+			return
+		}
+	}
+	if !allowed {
+		h.logger.Warn("Denied transaction export for merchant",
+			zap.String("service_id", serviceID),
+			zap.String("agent_id", merchantID),
+		)
+		http.Error(w, fmt.Sprintf("service has no access to merchant %q", merchantID), http.StatusForbidden)
+		return
+	}
+
+	// The first page is read before anything is written, so a failure can still be reported with a status code
+	page, cursor, err := h.transactions.ListTransactionsByCursor(r.Context(), merchantID, filter, exportPageSize, "")
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidFilter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to list transactions for export", zap.String("agent_id", merchantID), zap.Error(err))
+		http.Error(w, "failed to list transactions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "transactions-"+merchantID+".csv"))
+	flusher, _ := w.(http.Flusher)
+	out := csv.NewWriter(w)
+	_ = out.Write(transactionExportHeader)
+
+	rows := 0
+	for {
+		for _, tx := range page {
+			_ = out.Write(transactionExportRow(tx))
+		}
+		rows += len(page)
+
+		out.Flush()
+		if err := out.Error(); err != nil {
+			h.logger.Warn("Transaction export aborted by client", zap.String("agent_id", merchantID), zap.Error(err))
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if cursor == "" {
+			break
+		}
+
+		page, cursor, err = h.transactions.ListTransactionsByCursor(r.Context(), merchantID, filter, exportPageSize, cursor)
+		if err != nil {
+			// The status line has gone out; the truncated file is all the client can be given
+			h.logger.Error("Transaction export failed mid-stream",
+				zap.String("agent_id", merchantID),
+				zap.Int("rows_written", rows),
+				zap.Error(err),
+			)
+			return
+		}
+	}
+
+	h.logger.Info("Transactions exported",
+		zap.String("agent_id", merchantID),
+		zap.Int("rows", rows),
+	)
+}
+
+// transactionExportRow is one transaction's CSV row, in transactionExportHeader order
+func transactionExportRow(tx *domain.Transaction) []string {
+	return []string{
+		tx.ID,
+		string(tx.Type),
+		string(tx.Status),
+		tx.Amount.StringFixed(2),
+		tx.Currency,
+		getCardTypeName(stringPtrToString(tx.AuthCardType)),
+		tx.CreatedAt.UTC().Format(time.RFC3339),
+		tx.GroupID,
+	}
+}
+
+// parseExportTime parses an export bound: a date (2006-01-02) or an RFC 3339 time. An end date covers the whole day.
+func parseExportTime(name, value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a date (2006-01-02) or an RFC 3339 time", name)
+	}
+	return &t, nil
+}
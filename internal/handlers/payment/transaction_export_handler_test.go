@@ -0,0 +1,207 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/pkg/middleware"
+)
+
+// fakeTransactionPages pages through a merchant's transactions (held newest first); the cursor is the next index.
+// onPage, when set, runs before each page after the first is served.
+type fakeTransactionPages struct {
+	byMerchant map[string][]*domain.Transaction
+	filters    []domain.TransactionFilter
+	onPage     func(cursor string)
+}
+
+func (f *fakeTransactionPages) ListTransactionsByCursor(ctx context.Context, agentID string, filter domain.TransactionFilter, limit int, cursor string) ([]*domain.Transaction, string, error) {
+	f.filters = append(f.filters, filter)
+	if cursor != "" && f.onPage != nil {
+		f.onPage(cursor)
+	}
+
+	start := 0
+	if cursor != "" {
+		start, _ = strconv.Atoi(cursor)
+	}
+	txs := f.byMerchant[agentID][start:]
+	if len(txs) <= limit {
+		return txs, "", nil
+	}
+	return txs[:limit], strconv.Itoa(start + limit), nil
+}
+
+func exportTransactions(n int) []*domain.Transaction {
+	visa := "V"
+	created := time.Date(2025, 6, 30, 18, 0, 0, 0, time.UTC)
+	txs := make([]*domain.Transaction, n)
+	for i := range txs {
+		txs[i] = &domain.Transaction{
+			ID:           fmt.Sprintf("tx-%d", i),
+			GroupID:      fmt.Sprintf("group-%d", i),
+			AgentID:      "merchant-1",
+			Amount:       decimal.New(int64(1000+i), -2),
+			Currency:     "USD",
+			Status:       domain.TransactionStatusCompleted,
+			Type:         domain.TransactionTypeCharge,
+			AuthCardType: &visa,
+			CreatedAt:    created.Add(-time.Duration(i) * time.Minute),
+		}
+	}
+	return txs
+}
+
+// testServiceAuth returns the HTTP auth wrapper main mounts service endpoints behind, trusting only pos-service,
+// and a signer for pos-service tokens carrying scope
+func testServiceAuth(t *testing.T) (func(scope string, next http.HandlerFunc) http.HandlerFunc, func(scope string) string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	auth := middleware.NewHTTPAuth(middleware.AuthConfig{
+		Keys: middleware.StaticServiceKeys(map[string]*rsa.PublicKey{"pos-service": &key.PublicKey}),
+	}, zap.NewNop())
+
+	return auth, func(scope string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, middleware.ServiceClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    "pos-service",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+			Scope: scope,
+		}).SignedString(key)
+		require.NoError(t, err)
+		return token
+	}
+}
+
+// grantMerchant grants pos-service access to agentID only
+func grantMerchant(agentID string) middleware.MerchantAccessFunc {
+	return func(ctx context.Context, serviceID, requested string) (bool, error) {
+		return serviceID == "pos-service" && requested == agentID, nil
+	}
+}
+
+// exportAs sends an export request as pos-service with the payment:read scope
+func exportAs(t *testing.T, handler *TransactionExportHandler, rec *httptest.ResponseRecorder, target string) {
+	t.Helper()
+	auth, sign := testServiceAuth(t)
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Authorization", "Bearer "+sign("payment:read"))
+	auth("payment:read", handler.Export)(rec, req)
+}
+
+func TestTransactionExportHandler_CSV(t *testing.T) {
+	txs := exportTransactions(2)
+	txs[1].Type = domain.TransactionTypeRefund
+	txs[1].GroupID = txs[0].GroupID
+	txs[1].AuthCardType = nil
+	store := &fakeTransactionPages{byMerchant: map[string][]*domain.Transaction{"merchant-1": txs}}
+	handler := NewTransactionExportHandler(store, grantMerchant("merchant-1"), zap.NewNop())
+
+	rec := httptest.NewRecorder()
+	exportAs(t, handler, rec, "/api/v1/transactions/export?merchant_id=merchant-1&from=2025-06-01&to=2025-06-30")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="transactions-merchant-1.csv"`, rec.Header().Get("Content-Disposition"))
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "type", "status", "amount", "currency", "card_type", "created_at", "group_id"},
+		{"tx-0", "charge", "completed", "10.00", "USD", "Visa", "2025-06-30T18:00:00Z", "group-0"},
+		{"tx-1", "refund", "completed", "10.01", "USD", "", "2025-06-30T17:59:00Z", "group-0"},
+	}, rows)
+
+	require.Len(t, store.filters, 1)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), *store.filters[0].CreatedFrom)
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), *store.filters[0].CreatedTo, "the to date is inclusive")
+}
+
+func TestTransactionExportHandler_StreamsLargeExports(t *testing.T) {
+	const total = 3*exportPageSize + 17
+	store := &fakeTransactionPages{byMerchant: map[string][]*domain.Transaction{"merchant-1": exportTransactions(total)}}
+	handler := NewTransactionExportHandler(store, grantMerchant("merchant-1"), zap.NewNop())
+	rec := httptest.NewRecorder()
+
+	// Each page after the first is read only once the previous ones have reached the client
+	var flushedBefore []int
+	store.onPage = func(cursor string) {
+		assert.True(t, rec.Flushed)
+		flushedBefore = append(flushedBefore, strings.Count(rec.Body.String(), "\n")-1)
+	}
+
+	exportAs(t, handler, rec, "/api/v1/transactions/export?merchant_id=merchant-1")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, rows, total+1)
+	assert.Equal(t, fmt.Sprintf("tx-%d", total-1), rows[total][0])
+	assert.Equal(t, []int{exportPageSize, 2 * exportPageSize, 3 * exportPageSize}, flushedBefore)
+	assert.Len(t, store.filters, 4)
+}
+
+func TestTransactionExportHandler_MerchantScoping(t *testing.T) {
+	store := &fakeTransactionPages{byMerchant: map[string][]*domain.Transaction{
+		"merchant-1": exportTransactions(1),
+		"merchant-2": exportTransactions(1),
+	}}
+	handler := NewTransactionExportHandler(store, grantMerchant("merchant-1"), zap.NewNop())
+
+	auth, sign := testServiceAuth(t)
+	export := auth("payment:read", handler.Export)
+	call := func(scope, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Authorization", "Bearer "+sign(scope))
+		rec := httptest.NewRecorder()
+		export(rec, req)
+		return rec
+	}
+
+	rec := call("payment:read", "/api/v1/transactions/export?merchant_id=merchant-1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = call("payment:read", "/api/v1/transactions/export?merchant_id=merchant-2")
+	assert.Equal(t, http.StatusForbidden, rec.Code, "a merchant the service wasn't granted")
+	assert.NotContains(t, rec.Body.String(), "tx-0")
+
+	rec = call("merchant:read", "/api/v1/transactions/export?merchant_id=merchant-1")
+	assert.Equal(t, http.StatusForbidden, rec.Code, "missing payment:read")
+
+	for name, query := range map[string]string{
+		"missing merchant_id": "",
+		"bad from":            "?merchant_id=merchant-1&from=June",
+		"empty range":         "?merchant_id=merchant-1&from=2025-06-30&to=2025-06-01",
+	} {
+		rec = call("payment:read", "/api/v1/transactions/export"+query)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, name)
+	}
+
+	// Without service auth (no principal) or without merchant grants, every merchant is denied
+	rec = httptest.NewRecorder()
+	handler.Export(rec, httptest.NewRequest(http.MethodGet, "/api/v1/transactions/export?merchant_id=merchant-1", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code, "no authenticated service")
+	assert.NotContains(t, rec.Body.String(), "tx-0")
+
+	export = auth("payment:read", NewTransactionExportHandler(store, nil, zap.NewNop()).Export)
+	rec = call("payment:read", "/api/v1/transactions/export?merchant_id=merchant-1")
+	assert.Equal(t, http.StatusForbidden, rec.Code, "no merchant grants configured")
+}
@@ -0,0 +1,133 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/pkg/middleware"
+)
+
+// TransactionReader loads a transaction (the payment service's GetTransaction)
+type TransactionReader interface {
+	GetTransaction(ctx context.Context, transactionID string) (*domain.Transaction, error)
+}
+
+// PaymentMethodReader loads the saved card a transaction was charged to, for its last four digits
+type PaymentMethodReader interface {
+	GetPaymentMethod(ctx context.Context, paymentMethodID string) (*domain.PaymentMethod, error)
+}
+
+// TransactionStatusResponse is the JSON body of GET /api/v1/payments/{id}/status
+type TransactionStatusResponse struct {
+	TransactionID string    `json:"transaction_id"`
+	Status        string    `json:"status"`
+	Type          string    `json:"type"`
+	Amount        string    `json:"amount"`
+	Currency      string    `json:"currency"`
+	CardBrand     string    `json:"card_brand,omitempty"`
+	MaskedCard    string    `json:"masked_card,omitempty"` // "****4242" from the Browser Post callback or the saved payment method
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TransactionStatusHandler lets Browser Post clients re-fetch a transaction after the EPX redirect,
+// instead of trusting the redirect's query parameters
+type TransactionStatusHandler struct {
+	transactions   TransactionReader
+	paymentMethods PaymentMethodReader
+	access         middleware.MerchantAccessFunc // nil = every merchant is denied
+	logger         *zap.Logger
+}
+
+// NewTransactionStatusHandler creates a new transaction status handler
+func NewTransactionStatusHandler(
+	transactions TransactionReader,
+	paymentMethods PaymentMethodReader,
+	access middleware.MerchantAccessFunc,
+	logger *zap.Logger,
+) *TransactionStatusHandler {
+	return &TransactionStatusHandler{
+		transactions:   transactions,
+		paymentMethods: paymentMethods,
+		access:         access,
+		logger:         logger,
+	}
+}
+
+// GetStatus returns a transaction's status, amount and masked card
+// Endpoint: GET /api/v1/payments/{id}/status
+// A transaction that doesn't exist, or belongs to a merchant the calling service hasn't been granted, is 404;
+// so is every transaction when the caller isn't an authenticated service.
+func (h *TransactionStatusHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	transactionID := r.PathValue("id")
+	tx, err := h.transactions.GetTransaction(r.Context(), transactionID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrTransactionNotFound) {
+			h.logger.Debug("Transaction status lookup failed",
+				zap.String("transaction_id", transactionID),
+				zap.Error(err),
+			)
+		}
+		http.Error(w, "transaction not found", http.StatusNotFound)
+		return
+	}
+
+	// Only an authenticated service granted the transaction's merchant may see it
+	serviceID, authenticated := middleware.ServiceIDFromContext(r.Context())
+	allowed := false
+	if authenticated && h.access != nil {
+		if allowed, err = h.access(r.Context(), serviceID, tx.AgentID); err != nil {
+			h.logger.Error("Failed to check merchant access",
+				zap.String("service_id", serviceID),
+				zap.String("agent_id", tx.AgentID),
+				zap.Error(err),
+			)
+			http.Error(w, "merchant access check failed", http.StatusServiceUnavailable)
+			return
+		}
+	}
+	if !allowed {
+		h.logger.Warn("Denied transaction status for merchant",
+			zap.String("service_id", serviceID),
+			zap.String("agent_id", tx.AgentID),
+			zap.String("transaction_id", transactionID),
+		)
+		http.Error(w, "transaction not found", http.StatusNotFound)
+		return
+	}
+
+	resp := TransactionStatusResponse{
+		TransactionID: tx.ID,
+		Status:        string(tx.Status),
+		Type:          string(tx.Type),
+		Amount:        tx.Amount.StringFixed(2),
+		Currency:      tx.Currency,
+		CardBrand:     getCardTypeName(stringPtrToString(tx.AuthCardType)),
+		UpdatedAt:     tx.UpdatedAt,
+	}
+	if tx.LastFour != nil {
+		resp.MaskedCard = "****" + *tx.LastFour
+	} else if tx.PaymentMethodID != nil && h.paymentMethods != nil {
+		if pm, err := h.paymentMethods.GetPaymentMethod(r.Context(), *tx.PaymentMethodID); err == nil && pm.LastFour != "" {
+			resp.MaskedCard = "****" + pm.LastFour
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode transaction status",
+			zap.String("transaction_id", transactionID),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,139 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/pkg/middleware"
+)
+
+// fakeTransactions serves transactions from memory
+type fakeTransactions map[string]*domain.Transaction
+
+func (f fakeTransactions) GetTransaction(ctx context.Context, transactionID string) (*domain.Transaction, error) {
+	tx, ok := f[transactionID]
+	if !ok {
+		return nil, domain.ErrTransactionNotFound
+	}
+	return tx, nil
+}
+
+// fakePaymentMethods serves saved payment methods from memory
+type fakePaymentMethods map[string]*domain.PaymentMethod
+
+func (f fakePaymentMethods) GetPaymentMethod(ctx context.Context, paymentMethodID string) (*domain.PaymentMethod, error) {
+	pm, ok := f[paymentMethodID]
+	if !ok {
+		return nil, domain.ErrPaymentMethodNotFound
+	}
+	return pm, nil
+}
+
+func TestTransactionStatusHandler_GetStatus(t *testing.T) {
+	cardType, lastFour := "V", "1111"
+	pmID := "5b0b2a5e-4b8f-4d0a-9d42-0c3c5d3f8e11"
+	updatedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	transactions := fakeTransactions{
+		"c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90": {
+			ID:              "c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90",
+			AgentID:         "merchant-1",
+			Amount:          decimal.RequireFromString("42.5"),
+			Currency:        "USD",
+			Status:          domain.TransactionStatusCompleted,
+			Type:            domain.TransactionTypeCharge,
+			AuthCardType:    &cardType,
+			PaymentMethodID: &pmID,
+			UpdatedAt:       updatedAt,
+		},
+		// A Browser Post sale that didn't save its card
+		"7d4e2b91-3c5a-4e8f-b2d6-9a1f0c8e5b37": {
+			ID:           "7d4e2b91-3c5a-4e8f-b2d6-9a1f0c8e5b37",
+			AgentID:      "merchant-1",
+			Amount:       decimal.RequireFromString("19.99"),
+			Currency:     "USD",
+			Status:       domain.TransactionStatusCompleted,
+			Type:         domain.TransactionTypeCharge,
+			AuthCardType: &cardType,
+			LastFour:     &lastFour,
+			UpdatedAt:    updatedAt,
+		},
+	}
+	auth, sign := testServiceAuth(t)
+
+	// serve mounts the handler the way main does, behind auth when authenticated is set
+	serve := func(access middleware.MerchantAccessFunc, authenticated bool) func(id string) *httptest.ResponseRecorder {
+		handler := NewTransactionStatusHandler(transactions, fakePaymentMethods{pmID: {ID: pmID, LastFour: "4242"}}, access, zap.NewNop())
+		getStatus := handler.GetStatus
+		if authenticated {
+			getStatus = auth("payment:read", getStatus)
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/v1/payments/{id}/status", getStatus)
+
+		return func(id string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/"+id+"/status", nil)
+			req.Header.Set("Authorization", "Bearer "+sign("payment:read"))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			return w
+		}
+	}
+	get := serve(grantMerchant("merchant-1"), true)
+
+	t.Run("existing transaction", func(t *testing.T) {
+		w := get("c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var resp TransactionStatusResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, TransactionStatusResponse{
+			TransactionID: "c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90",
+			Status:        "completed",
+			Type:          "charge",
+			Amount:        "42.50",
+			Currency:      "USD",
+			CardBrand:     "Visa",
+			MaskedCard:    "****4242",
+			UpdatedAt:     updatedAt,
+		}, resp)
+	})
+
+	t.Run("browser post transaction without a payment method", func(t *testing.T) {
+		w := get("7d4e2b91-3c5a-4e8f-b2d6-9a1f0c8e5b37")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp TransactionStatusResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "****1111", resp.MaskedCard, "the last four recorded from the callback")
+	})
+
+	t.Run("unknown transaction", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("00000000-0000-0000-0000-000000000000").Code)
+	})
+
+	t.Run("transaction of a merchant the service wasn't granted", func(t *testing.T) {
+		w := serve(grantMerchant("merchant-2"), true)("c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90")
+		assert.Equal(t, http.StatusNotFound, w.Code, "indistinguishable from an unknown transaction")
+		assert.NotContains(t, w.Body.String(), "42.50")
+	})
+
+	t.Run("no merchant grants configured", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(nil, true)("c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90").Code)
+	})
+
+	t.Run("no authenticated service", func(t *testing.T) {
+		w := serve(grantMerchant("merchant-1"), false)("c1f7a3d2-9b7e-4f5e-8a61-3f2d8e4b6a90")
+		assert.Equal(t, http.StatusNotFound, w.Code, "service auth not configured exposes nothing")
+	})
+}
@@ -153,6 +153,30 @@ func (h *Handler) ListPaymentMethods(ctx context.Context, req *paymentmethodv1.L
 	}, nil
 }
 
+// ListExpiringPaymentMethods lists a merchant's active cards expiring within N days
+func (h *Handler) ListExpiringPaymentMethods(ctx context.Context, req *paymentmethodv1.ListExpiringPaymentMethodsRequest) (*paymentmethodv1.ListPaymentMethodsResponse, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.WithinDays <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "within_days must be positive")
+	}
+
+	pms, err := h.service.ListExpiringPaymentMethods(ctx, req.AgentId, int(req.WithinDays))
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	protoPMs := make([]*paymentmethodv1.PaymentMethod, len(pms))
+	for i, pm := range pms {
+		protoPMs[i] = paymentMethodToProto(pm)
+	}
+
+	return &paymentmethodv1.ListPaymentMethodsResponse{
+		PaymentMethods: protoPMs,
+	}, nil
+}
+
 // UpdatePaymentMethodStatus updates the active status of a payment method
 func (h *Handler) UpdatePaymentMethodStatus(ctx context.Context, req *paymentmethodv1.UpdatePaymentMethodStatusRequest) (*paymentmethodv1.PaymentMethodResponse, error) {
 	h.logger.Info("UpdatePaymentMethodStatus request received",
@@ -179,6 +203,37 @@ func (h *Handler) UpdatePaymentMethodStatus(ctx context.Context, req *paymentmet
 	return paymentMethodToResponse(pm), nil
 }
 
+// UpdatePaymentMethodExpiry updates a reissued card's expiration date
+func (h *Handler) UpdatePaymentMethodExpiry(ctx context.Context, req *paymentmethodv1.UpdatePaymentMethodExpiryRequest) (*paymentmethodv1.PaymentMethodResponse, error) {
+	h.logger.Info("UpdatePaymentMethodExpiry request received",
+		zap.String("payment_method_id", req.PaymentMethodId),
+		zap.String("customer_id", req.CustomerId),
+	)
+
+	if req.PaymentMethodId == "" {
+		return nil, status.Error(codes.InvalidArgument, "payment_method_id is required")
+	}
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.CustomerId == "" {
+		return nil, status.Error(codes.InvalidArgument, "customer_id is required")
+	}
+
+	pm, err := h.service.UpdatePaymentMethodExpiry(ctx, &ports.UpdatePaymentMethodExpiryRequest{
+		PaymentMethodID: req.PaymentMethodId,
+		AgentID:         req.AgentId,
+		CustomerID:      req.CustomerId,
+		CardExpMonth:    int(req.CardExpMonth),
+		CardExpYear:     int(req.CardExpYear),
+	})
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	return paymentMethodToResponse(pm), nil
+}
+
 // DeletePaymentMethod soft deletes a payment method (sets deleted_at)
 func (h *Handler) DeletePaymentMethod(ctx context.Context, req *paymentmethodv1.DeletePaymentMethodRequest) (*paymentmethodv1.DeletePaymentMethodResponse, error) {
 	h.logger.Info("DeletePaymentMethod request received",
@@ -270,6 +325,116 @@ func (h *Handler) VerifyACHAccount(ctx context.Context, req *paymentmethodv1.Ver
 	}, nil
 }
 
+// ProcessACHReturn records an ACH return and deactivates the payment method on unrecoverable returns
+func (h *Handler) ProcessACHReturn(ctx context.Context, req *paymentmethodv1.ProcessACHReturnRequest) (*paymentmethodv1.ProcessACHReturnResponse, error) {
+	h.logger.Info("ProcessACHReturn request received",
+		zap.String("payment_method_id", req.PaymentMethodId),
+		zap.String("return_code", req.ReturnCode),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.PaymentMethodId == "" {
+		return nil, status.Error(codes.InvalidArgument, "payment_method_id is required")
+	}
+	if req.ReturnCode == "" {
+		return nil, status.Error(codes.InvalidArgument, "return_code is required")
+	}
+
+	returnCode, err := domain.LookupACHReturnCode(req.ReturnCode)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	pm, err := h.service.ProcessACHReturn(ctx, &ports.ProcessACHReturnRequest{
+		AgentID:         req.AgentId,
+		PaymentMethodID: req.PaymentMethodId,
+		ReturnCode:      returnCode.Code,
+		TransactionID:   req.TransactionId,
+	})
+	if err != nil {
+		h.logger.Error("Failed to process ACH return", zap.Error(err))
+		return nil, handleServiceError(err)
+	}
+
+	action := returnCode.Action
+	if !pm.IsActive && pm.DeactivationReason != nil {
+		action = domain.ACHReturnActionDeactivate
+	}
+
+	return &paymentmethodv1.ProcessACHReturnResponse{
+		PaymentMethod: paymentMethodToProto(pm),
+		ReturnCode:    returnCode.Code,
+		Description:   returnCode.Description,
+		Action:        string(action),
+	}, nil
+}
+
+// InitiateMicroDeposits sends two small ACH credits to verify the account
+func (h *Handler) InitiateMicroDeposits(ctx context.Context, req *paymentmethodv1.InitiateMicroDepositsRequest) (*paymentmethodv1.PaymentMethod, error) {
+	h.logger.Info("InitiateMicroDeposits request received",
+		zap.String("payment_method_id", req.PaymentMethodId),
+		zap.String("customer_id", req.CustomerId),
+	)
+
+	if req.PaymentMethodId == "" {
+		return nil, status.Error(codes.InvalidArgument, "payment_method_id is required")
+	}
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.CustomerId == "" {
+		return nil, status.Error(codes.InvalidArgument, "customer_id is required")
+	}
+
+	pm, err := h.service.InitiateMicroDeposits(ctx, &ports.InitiateMicroDepositsRequest{
+		PaymentMethodID: req.PaymentMethodId,
+		AgentID:         req.AgentId,
+		CustomerID:      req.CustomerId,
+	})
+	if err != nil {
+		h.logger.Error("Failed to initiate micro-deposits", zap.Error(err))
+		return nil, handleServiceError(err)
+	}
+
+	return paymentMethodToProto(pm), nil
+}
+
+// VerifyMicroDeposits confirms the micro-deposit amounts and marks the ACH payment method verified
+func (h *Handler) VerifyMicroDeposits(ctx context.Context, req *paymentmethodv1.VerifyMicroDepositsRequest) (*paymentmethodv1.PaymentMethod, error) {
+	h.logger.Info("VerifyMicroDeposits request received",
+		zap.String("payment_method_id", req.PaymentMethodId),
+		zap.String("customer_id", req.CustomerId),
+	)
+
+	if req.PaymentMethodId == "" {
+		return nil, status.Error(codes.InvalidArgument, "payment_method_id is required")
+	}
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.CustomerId == "" {
+		return nil, status.Error(codes.InvalidArgument, "customer_id is required")
+	}
+	if len(req.Amounts) != 2 {
+		return nil, status.Error(codes.InvalidArgument, "exactly two amounts are required")
+	}
+
+	pm, err := h.service.VerifyMicroDeposits(ctx, &ports.VerifyMicroDepositsRequest{
+		PaymentMethodID: req.PaymentMethodId,
+		AgentID:         req.AgentId,
+		CustomerID:      req.CustomerId,
+		Amounts:         req.Amounts,
+	})
+	if err != nil {
+		h.logger.Warn("Micro-deposit verification failed", zap.Error(err))
+		return nil, handleServiceError(err)
+	}
+
+	return paymentMethodToProto(pm), nil
+}
+
 // ConvertFinancialBRICToStorageBRIC converts a Financial BRIC to Storage BRIC and saves payment method
 func (h *Handler) ConvertFinancialBRICToStorageBRIC(ctx context.Context, req *paymentmethodv1.ConvertFinancialBRICRequest) (*paymentmethodv1.PaymentMethodResponse, error) {
 	h.logger.Info("ConvertFinancialBRICToStorageBRIC request received",
@@ -355,6 +520,56 @@ func (h *Handler) ConvertFinancialBRICToStorageBRIC(ctx context.Context, req *pa
 	return paymentMethodToResponse(pm), nil
 }
 
+// TokenizePaymentMethod exchanges card data for a Storage BRIC and saves the payment method, without charging.
+// Card data is never logged.
+func (h *Handler) TokenizePaymentMethod(ctx context.Context, req *paymentmethodv1.TokenizePaymentMethodRequest) (*paymentmethodv1.PaymentMethodResponse, error) {
+	h.logger.Info("TokenizePaymentMethod request received",
+		zap.String("agent_id", req.AgentId),
+		zap.String("customer_id", req.CustomerId),
+	)
+
+	// Validate request
+	if err := validateTokenizePaymentMethodRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Convert to service request
+	address, zipCode := req.Address, req.ZipCode
+	serviceReq := &ports.TokenizePaymentMethodRequest{
+		AgentID:      req.AgentId,
+		CustomerID:   req.CustomerId,
+		CardNumber:   req.CardNumber,
+		CardExpMonth: int(req.CardExpMonth),
+		CardExpYear:  int(req.CardExpYear),
+		CVV:          req.Cvv,
+		IsDefault:    req.IsDefault,
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		Address:      &address,
+		City:         req.City,
+		State:        req.State,
+		ZipCode:      &zipCode,
+	}
+
+	if req.IdempotencyKey != "" {
+		serviceReq.IdempotencyKey = &req.IdempotencyKey
+	}
+
+	// Call service
+	pm, err := h.service.TokenizePaymentMethod(ctx, serviceReq)
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	h.logger.Info("Payment method tokenized successfully",
+		zap.String("payment_method_id", pm.ID),
+		zap.String("customer_id", pm.CustomerID),
+	)
+
+	// Convert to proto response
+	return paymentMethodToResponse(pm), nil
+}
+
 // Validation helpers
 
 func validateSavePaymentMethodRequest(req *paymentmethodv1.SavePaymentMethodRequest) error {
@@ -437,6 +652,48 @@ func validateConvertFinancialBRICRequest(req *paymentmethodv1.ConvertFinancialBR
 	return nil
 }
 
+func validateTokenizePaymentMethodRequest(req *paymentmethodv1.TokenizePaymentMethodRequest) error {
+	if req.AgentId == "" {
+		return fmt.Errorf("agent_id is required")
+	}
+	if req.CustomerId == "" {
+		return fmt.Errorf("customer_id is required")
+	}
+	if !isDigits(req.CardNumber) || len(req.CardNumber) < 13 || len(req.CardNumber) > 19 {
+		return fmt.Errorf("card_number must be 13-19 digits")
+	}
+	if req.CardExpMonth < 1 || req.CardExpMonth > 12 {
+		return fmt.Errorf("card_exp_month must be between 1 and 12")
+	}
+	if req.CardExpYear < 1000 {
+		return fmt.Errorf("card_exp_year must be four digits")
+	}
+	if req.Cvv != "" && (!isDigits(req.Cvv) || len(req.Cvv) < 3 || len(req.Cvv) > 4) {
+		return fmt.Errorf("cvv must be 3 or 4 digits")
+	}
+	// Address and zip code are required for Account Verification
+	if req.Address == "" {
+		return fmt.Errorf("address is required for credit card Account Verification")
+	}
+	if req.ZipCode == "" {
+		return fmt.Errorf("zip_code is required for credit card Account Verification")
+	}
+	return nil
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // Conversion helpers
 
 func paymentMethodToResponse(pm *domain.PaymentMethod) *paymentmethodv1.PaymentMethodResponse {
@@ -480,6 +737,7 @@ func paymentMethodToProto(pm *domain.PaymentMethod) *paymentmethodv1.PaymentMeth
 	proto := &paymentmethodv1.PaymentMethod{
 		Id:          pm.ID,
 		AgentId:     pm.AgentID,
+		DataRegion:  pm.DataRegion,
 		CustomerId:  pm.CustomerID,
 		PaymentType: paymentMethodTypeToProto(pm.PaymentType),
 		LastFour:    pm.LastFour,
@@ -510,6 +768,18 @@ func paymentMethodToProto(pm *domain.PaymentMethod) *paymentmethodv1.PaymentMeth
 	if pm.LastUsedAt != nil {
 		proto.LastUsedAt = timestamppb.New(*pm.LastUsedAt)
 	}
+	proto.ReturnCount = int32(pm.ReturnCount)
+	if pm.LastReturnCode != nil {
+		proto.LastReturnCode = pm.LastReturnCode
+	}
+	if pm.DeactivationReason != nil {
+		reason := string(*pm.DeactivationReason)
+		proto.DeactivationReason = &reason
+	}
+	proto.MicroDepositAttempts = int32(pm.MicroDepositAttempts)
+	if pm.MicroDepositsSentAt != nil {
+		proto.MicroDepositsSentAt = timestamppb.New(*pm.MicroDepositsSentAt)
+	}
 
 	return proto
 }
@@ -551,13 +821,41 @@ func handleServiceError(err error) error {
 		return status.Error(codes.FailedPrecondition, "payment method is inactive")
 	case errors.Is(err, domain.ErrInvalidPaymentMethodType):
 		return status.Error(codes.InvalidArgument, "invalid payment method type")
+	case errors.Is(err, domain.ErrInvalidCardExpiry):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrDefaultPaymentMethodConflict):
+		return status.Error(codes.Aborted, "default payment method changed concurrently; retry")
+	case errors.Is(err, domain.ErrSavedPaymentMethodLimit):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, domain.ErrInvalidTimeRange):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrUnknownACHReturnCode):
+		return status.Error(codes.InvalidArgument, "unknown ACH return code")
+	case errors.Is(err, domain.ErrMicroDepositsNotInitiated):
+		return status.Error(codes.FailedPrecondition, "micro-deposits have not been sent")
+	case errors.Is(err, domain.ErrMicroDepositMismatch):
+		return status.Error(codes.InvalidArgument, "micro-deposit amounts do not match")
+	case errors.Is(err, domain.ErrMicroDepositsLocked):
+		return status.Error(codes.ResourceExhausted, "too many failed micro-deposit attempts")
+	case errors.Is(err, domain.ErrInvalidAmount):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrMerchantSuspended):
+		return status.Error(codes.FailedPrecondition, "merchant is suspended")
+	case errors.Is(err, domain.ErrMerchantClosed):
+		return status.Error(codes.FailedPrecondition, "merchant is closed")
 	case errors.Is(err, domain.ErrAgentInactive):
 		return status.Error(codes.FailedPrecondition, "agent is inactive")
+	case errors.Is(err, domain.ErrEnvironmentMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrTransactionDeclined):
+		return status.Error(codes.Aborted, "card was declined")
 	case errors.Is(err, domain.ErrDuplicateIdempotencyKey):
 		return status.Error(codes.AlreadyExists, "duplicate idempotency key")
 	case errors.Is(err, sql.ErrNoRows):
 		return status.Error(codes.NotFound, "resource not found")
-	case err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded waiting for the payment gateway; check the status before retrying")
+	case errors.Is(err, context.Canceled):
 		return status.Error(codes.Canceled, "request canceled")
 	default:
 		// Log internal errors but don't expose details to client
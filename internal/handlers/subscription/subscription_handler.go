@@ -19,15 +19,26 @@ import (
 // Handler implements the gRPC SubscriptionServiceServer
 type Handler struct {
 	subscriptionv1.UnimplementedSubscriptionServiceServer
-	service ports.SubscriptionService
-	logger  *zap.Logger
+	service     ports.SubscriptionService
+	billingLock BillingLocker
+	logger      *zap.Logger
 }
 
+// BillingLocker takes the cluster-wide lock a cron job runs under
+type BillingLocker interface {
+	// TryLockCronJob returns acquired=false without waiting when another instance holds the job's lock
+	TryLockCronJob(ctx context.Context, job string) (unlock func(), acquired bool, err error)
+}
+
+// billingJob is the cron job lock ProcessDueBilling shares with /cron/process-billing
+const billingJob = "process-billing"
+
 // NewHandler creates a new subscription handler
-func NewHandler(service ports.SubscriptionService, logger *zap.Logger) *Handler {
+func NewHandler(service ports.SubscriptionService, billingLock BillingLocker, logger *zap.Logger) *Handler {
 	return &Handler{
-		service: service,
-		logger:  logger,
+		service:     service,
+		billingLock: billingLock,
+		logger:      logger,
 	}
 }
 
@@ -56,6 +67,7 @@ func (h *Handler) CreateSubscription(ctx context.Context, req *subscriptionv1.Cr
 		StartDate:       req.StartDate.AsTime(),
 		MaxRetries:      int(req.MaxRetries),
 		Metadata:        convertMetadata(req.Metadata),
+		CouponID:        req.CouponId,
 	}
 
 	if serviceReq.MaxRetries == 0 {
@@ -238,7 +250,69 @@ func (h *Handler) ListCustomerSubscriptions(ctx context.Context, req *subscripti
 	}, nil
 }
 
+// ListSubscriptionTransactions lists the billing history of a subscription
+func (h *Handler) ListSubscriptionTransactions(ctx context.Context, req *subscriptionv1.ListSubscriptionTransactionsRequest) (*subscriptionv1.ListSubscriptionTransactionsResponse, error) {
+	h.logger.Info("ListSubscriptionTransactions request received",
+		zap.String("subscription_id", req.SubscriptionId),
+	)
+
+	if req.SubscriptionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "subscription_id is required")
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = 100 // Default
+	}
+	if limit > 1000 {
+		limit = 1000 // Cap at 1000
+	}
+
+	txs, totalCount, err := h.service.ListSubscriptionTransactions(ctx, req.SubscriptionId, limit, int(req.Offset))
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	protoTxs := make([]*subscriptionv1.BillingTransaction, len(txs))
+	for i, tx := range txs {
+		protoTxs[i] = billingTransactionToProto(tx)
+	}
+
+	return &subscriptionv1.ListSubscriptionTransactionsResponse{
+		Transactions: protoTxs,
+		TotalCount:   int32(totalCount),
+	}, nil
+}
+
+// GetSubscriptionChargeHistory lists every billing attempt of a subscription with its retry number
+func (h *Handler) GetSubscriptionChargeHistory(ctx context.Context, req *subscriptionv1.GetSubscriptionChargeHistoryRequest) (*subscriptionv1.GetSubscriptionChargeHistoryResponse, error) {
+	h.logger.Info("GetSubscriptionChargeHistory request received",
+		zap.String("agent_id", req.AgentId),
+		zap.String("subscription_id", req.SubscriptionId),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.SubscriptionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "subscription_id is required")
+	}
+
+	attempts, err := h.service.GetSubscriptionChargeHistory(ctx, req.AgentId, req.SubscriptionId)
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	protoAttempts := make([]*subscriptionv1.ChargeAttempt, len(attempts))
+	for i, attempt := range attempts {
+		protoAttempts[i] = chargeAttemptToProto(attempt)
+	}
+
+	return &subscriptionv1.GetSubscriptionChargeHistoryResponse{Attempts: protoAttempts}, nil
+}
+
 // ProcessDueBilling processes subscriptions due for billing (internal/admin use)
+// It runs under the billing cron job's lock, so it fails with ABORTED while a billing run is in progress anywhere.
 func (h *Handler) ProcessDueBilling(ctx context.Context, req *subscriptionv1.ProcessDueBillingRequest) (*subscriptionv1.ProcessDueBillingResponse, error) {
 	h.logger.Info("ProcessDueBilling request received",
 		zap.Time("as_of_date", req.AsOfDate.AsTime()),
@@ -250,11 +324,21 @@ func (h *Handler) ProcessDueBilling(ctx context.Context, req *subscriptionv1.Pro
 		batchSize = 100 // Default
 	}
 
-	processed, success, failed, errors := h.service.ProcessDueBilling(ctx, req.AsOfDate.AsTime(), batchSize)
+	unlock, acquired, err := h.billingLock.TryLockCronJob(ctx, billingJob)
+	if err != nil {
+		h.logger.Error("Failed to take billing lock", zap.Error(err))
+		return nil, status.Error(codes.Unavailable, "failed to take billing lock")
+	}
+	if !acquired {
+		return nil, status.Error(codes.Aborted, "a billing run is already in progress")
+	}
+	defer unlock()
+
+	result := h.service.ProcessDueBilling(ctx, req.AsOfDate.AsTime(), batchSize)
 
 	// Convert errors to billing errors
-	billingErrors := make([]*subscriptionv1.BillingError, len(errors))
-	for i, err := range errors {
+	billingErrors := make([]*subscriptionv1.BillingError, len(result.Errors))
+	for i, err := range result.Errors {
 		billingErrors[i] = &subscriptionv1.BillingError{
 			Error:     err.Error(),
 			Retriable: isRetriableError(err),
@@ -262,9 +346,9 @@ func (h *Handler) ProcessDueBilling(ctx context.Context, req *subscriptionv1.Pro
 	}
 
 	return &subscriptionv1.ProcessDueBillingResponse{
-		ProcessedCount: int32(processed),
-		SuccessCount:   int32(success),
-		FailedCount:    int32(failed),
+		ProcessedCount: int32(result.Processed),
+		SuccessCount:   int32(result.Succeeded),
+		FailedCount:    int32(result.Failed),
 		SkippedCount:   int32(0), // Not tracking skipped yet
 		Errors:         billingErrors,
 	}, nil
@@ -334,26 +418,31 @@ func subscriptionToResponse(sub *domain.Subscription) *subscriptionv1.Subscripti
 		resp.CancelledAt = timestamppb.New(*sub.CancelledAt)
 	}
 
+	if sub.CouponID != nil {
+		resp.CouponId = sub.CouponID
+	}
+
 	return resp
 }
 
 func subscriptionToProto(sub *domain.Subscription) *subscriptionv1.Subscription {
 	proto := &subscriptionv1.Subscription{
-		Id:                sub.ID,
-		AgentId:           sub.AgentID,
-		CustomerId:        sub.CustomerID,
-		Amount:            sub.Amount.String(),
-		Currency:          string(sub.Currency),
-		IntervalValue:     int32(sub.IntervalValue),
-		IntervalUnit:      intervalUnitToProto(sub.IntervalUnit),
-		Status:            subscriptionStatusToProto(sub.Status),
-		PaymentMethodId:   sub.PaymentMethodID,
-		NextBillingDate:   timestamppb.New(sub.NextBillingDate),
-		FailureRetryCount: int32(sub.FailureRetryCount),
-		MaxRetries:        int32(sub.MaxRetries),
-		CreatedAt:         timestamppb.New(sub.CreatedAt),
-		UpdatedAt:         timestamppb.New(sub.UpdatedAt),
-		Metadata:          convertMetadataToProto(sub.Metadata),
+		Id:                 sub.ID,
+		AgentId:            sub.AgentID,
+		CustomerId:         sub.CustomerID,
+		Amount:             sub.Amount.String(),
+		Currency:           string(sub.Currency),
+		IntervalValue:      int32(sub.IntervalValue),
+		IntervalUnit:       intervalUnitToProto(sub.IntervalUnit),
+		Status:             subscriptionStatusToProto(sub.Status),
+		PaymentMethodId:    sub.PaymentMethodID,
+		NextBillingDate:    timestamppb.New(sub.NextBillingDate),
+		FailureRetryCount:  int32(sub.FailureRetryCount),
+		MaxRetries:         int32(sub.MaxRetries),
+		CreatedAt:          timestamppb.New(sub.CreatedAt),
+		UpdatedAt:          timestamppb.New(sub.UpdatedAt),
+		Metadata:           convertMetadataToProto(sub.Metadata),
+		CouponAppliedCount: int32(sub.CouponAppliedCount),
 	}
 
 	if sub.GatewaySubscriptionID != nil {
@@ -364,6 +453,61 @@ func subscriptionToProto(sub *domain.Subscription) *subscriptionv1.Subscription
 		proto.CancelledAt = timestamppb.New(*sub.CancelledAt)
 	}
 
+	if sub.CouponID != nil {
+		proto.CouponId = sub.CouponID
+	}
+
+	if sub.PreviousPaymentMethodID != nil {
+		proto.PreviousPaymentMethodId = sub.PreviousPaymentMethodID
+	}
+
+	if sub.PaymentMethodSwitchedAt != nil {
+		proto.PaymentMethodSwitchedAt = timestamppb.New(*sub.PaymentMethodSwitchedAt)
+	}
+
+	return proto
+}
+
+func billingTransactionToProto(tx *domain.Transaction) *subscriptionv1.BillingTransaction {
+	proto := &subscriptionv1.BillingTransaction{
+		TransactionId: tx.ID,
+		GroupId:       tx.GroupID,
+		Amount:        tx.Amount.String(),
+		Currency:      tx.Currency,
+		Status:        string(tx.Status),
+		Type:          string(tx.Type),
+		CreatedAt:     timestamppb.New(tx.CreatedAt),
+	}
+
+	if tx.AuthResp != nil {
+		proto.AuthResp = *tx.AuthResp
+	}
+
+	if tx.Status == domain.TransactionStatusFailed && tx.AuthRespText != nil {
+		proto.FailureReason = tx.AuthRespText
+	}
+
+	return proto
+}
+
+func chargeAttemptToProto(attempt *domain.SubscriptionChargeAttempt) *subscriptionv1.ChargeAttempt {
+	proto := &subscriptionv1.ChargeAttempt{
+		TransactionId: attempt.TransactionID,
+		AttemptedAt:   timestamppb.New(attempt.AttemptedAt),
+		Amount:        attempt.Amount.String(),
+		Currency:      attempt.Currency,
+		Status:        string(attempt.Status),
+		AuthResp:      attempt.AuthResp,
+		RetryNumber:   int32(attempt.RetryNumber),
+	}
+
+	if attempt.DeclineCode != "" {
+		proto.DeclineCode = &attempt.DeclineCode
+	}
+	if attempt.DeclineReason != "" {
+		proto.DeclineReason = &attempt.DeclineReason
+	}
+
 	return proto
 }
 
@@ -475,10 +619,22 @@ func handleServiceError(err error) error {
 		return status.Error(codes.FailedPrecondition, "ACH payment method is not verified")
 	case errors.Is(err, domain.ErrPaymentMethodInactive):
 		return status.Error(codes.FailedPrecondition, "payment method is inactive")
+	case errors.Is(err, domain.ErrAmountChangeTooSoon):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrCouponNotFound):
+		return status.Error(codes.NotFound, "coupon not found")
+	case errors.Is(err, domain.ErrCouponExpired):
+		return status.Error(codes.FailedPrecondition, "coupon is expired")
+	case errors.Is(err, domain.ErrCouponInactive):
+		return status.Error(codes.FailedPrecondition, "coupon is inactive")
+	case errors.Is(err, domain.ErrCouponCurrencyMismatch):
+		return status.Error(codes.InvalidArgument, "coupon currency does not match subscription currency")
 	case errors.Is(err, domain.ErrInvalidBillingInterval):
 		return status.Error(codes.InvalidArgument, "invalid billing interval")
 	case errors.Is(err, domain.ErrInvalidAmount):
 		return status.Error(codes.InvalidArgument, "invalid amount")
+	case errors.Is(err, domain.ErrAmountOutOfRange):
+		return status.Error(codes.InvalidArgument, err.Error())
 	case errors.Is(err, domain.ErrInvalidCurrency):
 		return status.Error(codes.InvalidArgument, "invalid currency")
 	case errors.Is(err, domain.ErrAgentInactive):
@@ -487,7 +643,9 @@ func handleServiceError(err error) error {
 		return status.Error(codes.AlreadyExists, "duplicate idempotency key")
 	case errors.Is(err, sql.ErrNoRows):
 		return status.Error(codes.NotFound, "resource not found")
-	case err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded waiting for the payment gateway; check the status before retrying")
+	case errors.Is(err, context.Canceled):
 		return status.Error(codes.Canceled, "request canceled")
 	default:
 		// Log internal errors but don't expose details to client
@@ -0,0 +1,303 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/ports"
+	subscriptionv1 "github.com/kevin07696/payment-service/proto/subscription/v1"
+)
+
+// MockSubscriptionService is a mock implementation of ports.SubscriptionService
+type MockSubscriptionService struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionService) CreateSubscription(ctx context.Context, req *ports.CreateSubscriptionRequest) (*domain.Subscription, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) UpdateSubscription(ctx context.Context, req *ports.UpdateSubscriptionRequest) (*domain.Subscription, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) CancelSubscription(ctx context.Context, req *ports.CancelSubscriptionRequest) (*domain.Subscription, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) PauseSubscription(ctx context.Context, subscriptionID string) (*domain.Subscription, error) {
+	args := m.Called(ctx, subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) ResumeSubscription(ctx context.Context, subscriptionID string) (*domain.Subscription, error) {
+	args := m.Called(ctx, subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) GetSubscription(ctx context.Context, subscriptionID string) (*domain.Subscription, error) {
+	args := m.Called(ctx, subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) ListCustomerSubscriptions(ctx context.Context, agentID, customerID string) ([]*domain.Subscription, error) {
+	args := m.Called(ctx, agentID, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionService) ListSubscriptionTransactions(ctx context.Context, subscriptionID string, limit, offset int) ([]*domain.Transaction, int, error) {
+	args := m.Called(ctx, subscriptionID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Transaction), args.Int(1), args.Error(2)
+}
+
+func (m *MockSubscriptionService) GetSubscriptionChargeHistory(ctx context.Context, agentID, subscriptionID string) ([]*domain.SubscriptionChargeAttempt, error) {
+	args := m.Called(ctx, agentID, subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.SubscriptionChargeAttempt), args.Error(1)
+}
+
+func (m *MockSubscriptionService) ProcessDueBilling(ctx context.Context, asOfDate time.Time, batchSize int) *domain.BillingRunResult {
+	args := m.Called(ctx, asOfDate, batchSize)
+	return args.Get(0).(*domain.BillingRunResult)
+}
+
+func (m *MockSubscriptionService) PreviewDueBilling(ctx context.Context, asOfDate time.Time, batchSize int) ([]*domain.PlannedCharge, error) {
+	args := m.Called(ctx, asOfDate, batchSize)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.PlannedCharge), args.Error(1)
+}
+
+// fakeBillingLocker hands out the billing lock unless another run holds it
+type fakeBillingLocker struct {
+	held     bool
+	jobs     []string
+	unlocked int
+}
+
+func (f *fakeBillingLocker) TryLockCronJob(ctx context.Context, job string) (func(), bool, error) {
+	f.jobs = append(f.jobs, job)
+	if f.held {
+		return nil, false, nil
+	}
+	f.held = true
+	return func() {
+		f.held = false
+		f.unlocked++
+	}, true, nil
+}
+
+// ListSubscriptionTransactions Tests
+
+// The service's listing (filtering, order, paging) is tested in the subscription service package; this checks
+// how the handler maps each entry
+func TestListSubscriptionTransactions_MapsEntries(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	handler := NewHandler(mockService, &fakeBillingLocker{}, zap.NewNop())
+
+	subscriptionID := uuid.New().String()
+	declineText := "INSUFFICIENT FUNDS"
+	approved := "00"
+	declined := "51"
+	firstCycle := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	// Newest first, as returned by the service
+	txs := []*domain.Transaction{
+		{
+			ID:        uuid.New().String(),
+			GroupID:   uuid.New().String(),
+			Amount:    decimal.RequireFromString("29.99"),
+			Currency:  "USD",
+			Status:    domain.TransactionStatusCompleted,
+			Type:      domain.TransactionTypeCharge,
+			AuthResp:  &approved,
+			CreatedAt: firstCycle.AddDate(0, 2, 0),
+		},
+		{
+			ID:           uuid.New().String(),
+			GroupID:      uuid.New().String(),
+			Amount:       decimal.RequireFromString("29.99"),
+			Currency:     "USD",
+			Status:       domain.TransactionStatusFailed,
+			Type:         domain.TransactionTypeCharge,
+			AuthResp:     &declined,
+			AuthRespText: &declineText,
+			CreatedAt:    firstCycle.AddDate(0, 1, 0),
+		},
+		{
+			ID:        uuid.New().String(),
+			GroupID:   uuid.New().String(),
+			Amount:    decimal.RequireFromString("29.99"),
+			Currency:  "USD",
+			Status:    domain.TransactionStatusCompleted,
+			Type:      domain.TransactionTypeCharge,
+			AuthResp:  &approved,
+			CreatedAt: firstCycle,
+		},
+	}
+
+	mockService.On("ListSubscriptionTransactions", mock.Anything, subscriptionID, 100, 0).Return(txs, 3, nil)
+
+	resp, err := handler.ListSubscriptionTransactions(context.Background(), &subscriptionv1.ListSubscriptionTransactionsRequest{
+		SubscriptionId: subscriptionID,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Transactions, 3)
+	assert.Equal(t, int32(3), resp.TotalCount)
+
+	assert.Equal(t, txs[0].ID, resp.Transactions[0].TransactionId)
+	assert.Equal(t, "completed", resp.Transactions[0].Status)
+	assert.Nil(t, resp.Transactions[0].FailureReason)
+
+	assert.Equal(t, "failed", resp.Transactions[1].Status)
+	assert.Equal(t, "51", resp.Transactions[1].AuthResp)
+	require.NotNil(t, resp.Transactions[1].FailureReason)
+	assert.Equal(t, declineText, *resp.Transactions[1].FailureReason)
+
+	assert.Equal(t, "29.99", resp.Transactions[2].Amount)
+	mockService.AssertExpectations(t)
+}
+
+func TestListSubscriptionTransactions_MissingSubscriptionID(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	handler := NewHandler(mockService, &fakeBillingLocker{}, zap.NewNop())
+
+	resp, err := handler.ListSubscriptionTransactions(context.Background(), &subscriptionv1.ListSubscriptionTransactionsRequest{})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockService.AssertNotCalled(t, "ListSubscriptionTransactions")
+}
+
+func TestListSubscriptionTransactions_SubscriptionNotFound(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	handler := NewHandler(mockService, &fakeBillingLocker{}, zap.NewNop())
+
+	subscriptionID := uuid.New().String()
+	mockService.On("ListSubscriptionTransactions", mock.Anything, subscriptionID, 50, 10).
+		Return(nil, 0, domain.ErrSubscriptionNotFound)
+
+	resp, err := handler.ListSubscriptionTransactions(context.Background(), &subscriptionv1.ListSubscriptionTransactionsRequest{
+		SubscriptionId: subscriptionID,
+		Limit:          50,
+		Offset:         10,
+	})
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	mockService.AssertExpectations(t)
+}
+
+func TestListSubscriptionTransactions_DeadlineExceeded(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	handler := NewHandler(mockService, &fakeBillingLocker{}, zap.NewNop())
+
+	subscriptionID := uuid.New().String()
+	mockService.On("ListSubscriptionTransactions", mock.Anything, subscriptionID, 50, 0).
+		Return(nil, 0, context.DeadlineExceeded)
+
+	_, err := handler.ListSubscriptionTransactions(context.Background(), &subscriptionv1.ListSubscriptionTransactionsRequest{
+		SubscriptionId: subscriptionID,
+		Limit:          50,
+	})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	mockService.ExpectedCalls = nil
+	mockService.On("ListSubscriptionTransactions", mock.Anything, subscriptionID, 50, 0).
+		Return(nil, 0, context.Canceled)
+
+	_, err = handler.ListSubscriptionTransactions(context.Background(), &subscriptionv1.ListSubscriptionTransactionsRequest{
+		SubscriptionId: subscriptionID,
+		Limit:          50,
+	})
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestCreateSubscription_AmountOutOfRange(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	handler := NewHandler(mockService, &fakeBillingLocker{}, zap.NewNop())
+
+	mockService.On("CreateSubscription", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("with coupon 8f7c9c2e: %w: 3.00 is below the minimum of 5.00", domain.ErrAmountOutOfRange))
+
+	_, err := handler.CreateSubscription(context.Background(), &subscriptionv1.CreateSubscriptionRequest{
+		AgentId:         "merchant-1",
+		CustomerId:      "customer-1",
+		Amount:          "10.00",
+		Currency:        "USD",
+		IntervalValue:   1,
+		IntervalUnit:    subscriptionv1.IntervalUnit_INTERVAL_UNIT_MONTH,
+		PaymentMethodId: uuid.New().String(),
+		StartDate:       timestamppb.Now(),
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "below the minimum of 5.00")
+	mockService.AssertExpectations(t)
+}
+
+func TestProcessDueBilling_TakesBillingCronLock(t *testing.T) {
+	mockService := new(MockSubscriptionService)
+	locker := &fakeBillingLocker{}
+	handler := NewHandler(mockService, locker, zap.NewNop())
+	req := &subscriptionv1.ProcessDueBillingRequest{AsOfDate: timestamppb.Now(), BatchSize: 10}
+
+	mockService.On("ProcessDueBilling", mock.Anything, mock.Anything, 10).
+		Return(&domain.BillingRunResult{Processed: 2, Succeeded: 2, AmountCharged: decimal.RequireFromString("20.00")}).Once()
+
+	resp, err := handler.ProcessDueBilling(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.SuccessCount)
+	assert.Equal(t, []string{"process-billing"}, locker.jobs, "same lock as the billing cron job")
+	assert.Equal(t, 1, locker.unlocked)
+
+	// A run in progress elsewhere holds the lock, so this one never bills
+	locker.held = true
+	_, err = handler.ProcessDueBilling(context.Background(), req)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,356 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/webhook"
+	webhookv1 "github.com/kevin07696/payment-service/proto/webhook/v1"
+	"go.uber.org/zap"
+)
+
+// WebhookService defines the webhook service operations used by the handler
+type WebhookService interface {
+	RedeliverWebhook(ctx context.Context, agentID string, deliveryID uuid.UUID) (*sqlc.WebhookDelivery, error)
+	RedeliverFailedWebhooks(ctx context.Context, agentID string, subscriptionID uuid.UUID, from, to time.Time) ([]sqlc.WebhookDelivery, error)
+	GetWebhookStats(ctx context.Context, agentID string, subscriptionID uuid.UUID, from, to time.Time) (*domain.WebhookStats, error)
+	CreateSubscription(ctx context.Context, agentID, eventType, webhookURL string) (*sqlc.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, agentID string, isActive *bool) ([]sqlc.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, agentID string, subscriptionID uuid.UUID, update webhook.SubscriptionUpdate) (*sqlc.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, agentID string, subscriptionID uuid.UUID) error
+}
+
+// Handler implements the gRPC WebhookServiceServer
+type Handler struct {
+	webhookv1.UnimplementedWebhookServiceServer
+	service WebhookService
+	logger  *zap.Logger
+}
+
+// NewHandler creates a new webhook handler
+func NewHandler(service WebhookService, logger *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RedeliverWebhook re-enqueues a fresh delivery of a previous delivery's event payload
+func (h *Handler) RedeliverWebhook(ctx context.Context, req *webhookv1.RedeliverWebhookRequest) (*webhookv1.WebhookDelivery, error) {
+	h.logger.Info("RedeliverWebhook request received",
+		zap.String("agent_id", req.AgentId),
+		zap.String("delivery_id", req.DeliveryId),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.DeliveryId == "" {
+		return nil, status.Error(codes.InvalidArgument, "delivery_id is required")
+	}
+
+	deliveryID, err := uuid.Parse(req.DeliveryId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid delivery_id format")
+	}
+
+	delivery, err := h.service.RedeliverWebhook(ctx, req.AgentId, deliveryID)
+	if err != nil {
+		h.logger.Error("Failed to redeliver webhook",
+			zap.String("delivery_id", req.DeliveryId),
+			zap.Error(err),
+		)
+		return nil, handleServiceError(err)
+	}
+
+	return convertDeliveryToProto(delivery), nil
+}
+
+// RedeliverFailedWebhooks re-enqueues all failed deliveries for a subscription within a time range
+func (h *Handler) RedeliverFailedWebhooks(ctx context.Context, req *webhookv1.RedeliverFailedWebhooksRequest) (*webhookv1.RedeliverFailedWebhooksResponse, error) {
+	h.logger.Info("RedeliverFailedWebhooks request received",
+		zap.String("agent_id", req.AgentId),
+		zap.String("subscription_id", req.SubscriptionId),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.SubscriptionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "subscription_id is required")
+	}
+	if req.CreatedFrom == nil || req.CreatedTo == nil {
+		return nil, status.Error(codes.InvalidArgument, "created_from and created_to are required")
+	}
+
+	subscriptionID, err := uuid.Parse(req.SubscriptionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid subscription_id format")
+	}
+
+	deliveries, err := h.service.RedeliverFailedWebhooks(ctx, req.AgentId, subscriptionID, req.CreatedFrom.AsTime(), req.CreatedTo.AsTime())
+	if err != nil {
+		h.logger.Error("Failed to redeliver failed webhooks",
+			zap.String("subscription_id", req.SubscriptionId),
+			zap.Error(err),
+		)
+		return nil, handleServiceError(err)
+	}
+
+	resp := &webhookv1.RedeliverFailedWebhooksResponse{
+		Deliveries: make([]*webhookv1.WebhookDelivery, 0, len(deliveries)),
+	}
+	for i := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, convertDeliveryToProto(&deliveries[i]))
+	}
+
+	return resp, nil
+}
+
+// GetWebhookStats summarizes how reliably a subscription's endpoint received deliveries within a time range
+func (h *Handler) GetWebhookStats(ctx context.Context, req *webhookv1.GetWebhookStatsRequest) (*webhookv1.WebhookStats, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.SubscriptionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "subscription_id is required")
+	}
+	if req.CreatedFrom == nil || req.CreatedTo == nil {
+		return nil, status.Error(codes.InvalidArgument, "created_from and created_to are required")
+	}
+
+	subscriptionID, err := uuid.Parse(req.SubscriptionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid subscription_id format")
+	}
+
+	stats, err := h.service.GetWebhookStats(ctx, req.AgentId, subscriptionID, req.CreatedFrom.AsTime(), req.CreatedTo.AsTime())
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidTimeRange) {
+			return nil, status.Error(codes.InvalidArgument, "created_from must be before created_to and at most 90 days earlier")
+		}
+		h.logger.Error("Failed to get webhook stats",
+			zap.String("subscription_id", req.SubscriptionId),
+			zap.Error(err),
+		)
+		return nil, handleServiceError(err)
+	}
+
+	resp := &webhookv1.WebhookStats{
+		SubscriptionId:   req.SubscriptionId,
+		CreatedFrom:      timestamppb.New(stats.From),
+		CreatedTo:        timestamppb.New(stats.To),
+		TotalDeliveries:  stats.TotalDeliveries,
+		Succeeded:        stats.Succeeded,
+		Failed:           stats.Failed,
+		Pending:          stats.Pending,
+		SuccessRate:      stats.SuccessRate,
+		AverageLatencyMs: float64(stats.AverageLatency) / float64(time.Millisecond),
+		FailureReasons:   make([]*webhookv1.WebhookFailureReason, 0, len(stats.FailureReasons)),
+	}
+	for _, reason := range stats.FailureReasons {
+		resp.FailureReasons = append(resp.FailureReasons, &webhookv1.WebhookFailureReason{Reason: reason.Reason, Count: reason.Count})
+	}
+
+	return resp, nil
+}
+
+// CreateWebhookSubscription subscribes an https endpoint to an event type; the signing secret is only returned here
+func (h *Handler) CreateWebhookSubscription(ctx context.Context, req *webhookv1.CreateWebhookSubscriptionRequest) (*webhookv1.CreateWebhookSubscriptionResponse, error) {
+	h.logger.Info("CreateWebhookSubscription request received",
+		zap.String("agent_id", req.AgentId),
+		zap.String("event_type", req.EventType),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.EventType == "" {
+		return nil, status.Error(codes.InvalidArgument, "event_type is required")
+	}
+	if req.WebhookUrl == "" {
+		return nil, status.Error(codes.InvalidArgument, "webhook_url is required")
+	}
+
+	subscription, err := h.service.CreateSubscription(ctx, req.AgentId, req.EventType, req.WebhookUrl)
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	return &webhookv1.CreateWebhookSubscriptionResponse{
+		Subscription: convertSubscriptionToProto(subscription),
+		Secret:       subscription.Secret,
+	}, nil
+}
+
+// ListWebhookSubscriptions lists the merchant's webhook subscriptions, newest first
+func (h *Handler) ListWebhookSubscriptions(ctx context.Context, req *webhookv1.ListWebhookSubscriptionsRequest) (*webhookv1.ListWebhookSubscriptionsResponse, error) {
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+
+	subscriptions, err := h.service.ListSubscriptions(ctx, req.AgentId, req.IsActive)
+	if err != nil {
+		h.logger.Error("Failed to list webhook subscriptions",
+			zap.String("agent_id", req.AgentId),
+			zap.Error(err),
+		)
+		return nil, handleServiceError(err)
+	}
+
+	resp := &webhookv1.ListWebhookSubscriptionsResponse{
+		Subscriptions: make([]*webhookv1.WebhookSubscription, 0, len(subscriptions)),
+	}
+	for i := range subscriptions {
+		resp.Subscriptions = append(resp.Subscriptions, convertSubscriptionToProto(&subscriptions[i]))
+	}
+
+	return resp, nil
+}
+
+// UpdateWebhookSubscription changes a subscription's endpoint, event type, active flag or batching
+func (h *Handler) UpdateWebhookSubscription(ctx context.Context, req *webhookv1.UpdateWebhookSubscriptionRequest) (*webhookv1.WebhookSubscription, error) {
+	h.logger.Info("UpdateWebhookSubscription request received",
+		zap.String("agent_id", req.AgentId),
+		zap.String("subscription_id", req.SubscriptionId),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.SubscriptionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "subscription_id is required")
+	}
+	if req.BatchSize != nil && (*req.BatchSize < 1 || *req.BatchSize > 1000) {
+		return nil, status.Error(codes.InvalidArgument, "batch_size must be between 1 and 1000")
+	}
+	if req.BatchFlushIntervalSeconds != nil && *req.BatchFlushIntervalSeconds < 1 {
+		return nil, status.Error(codes.InvalidArgument, "batch_flush_interval_seconds must be at least 1")
+	}
+
+	subscriptionID, err := uuid.Parse(req.SubscriptionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid subscription_id format")
+	}
+
+	subscription, err := h.service.UpdateSubscription(ctx, req.AgentId, subscriptionID, webhook.SubscriptionUpdate{
+		WebhookURL:                req.WebhookUrl,
+		EventType:                 req.EventType,
+		IsActive:                  req.IsActive,
+		BatchSize:                 req.BatchSize,
+		BatchFlushIntervalSeconds: req.BatchFlushIntervalSeconds,
+	})
+	if err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	return convertSubscriptionToProto(subscription), nil
+}
+
+// DeleteWebhookSubscription removes a subscription and its delivery history
+func (h *Handler) DeleteWebhookSubscription(ctx context.Context, req *webhookv1.DeleteWebhookSubscriptionRequest) (*webhookv1.DeleteWebhookSubscriptionResponse, error) {
+	h.logger.Info("DeleteWebhookSubscription request received",
+		zap.String("agent_id", req.AgentId),
+		zap.String("subscription_id", req.SubscriptionId),
+	)
+
+	if req.AgentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "agent_id is required")
+	}
+	if req.SubscriptionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "subscription_id is required")
+	}
+
+	subscriptionID, err := uuid.Parse(req.SubscriptionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid subscription_id format")
+	}
+
+	if err := h.service.DeleteSubscription(ctx, req.AgentId, subscriptionID); err != nil {
+		return nil, handleServiceError(err)
+	}
+
+	return &webhookv1.DeleteWebhookSubscriptionResponse{Success: true}, nil
+}
+
+// ListEventTypes lists the event types webhook subscriptions can select
+func (h *Handler) ListEventTypes(ctx context.Context, req *webhookv1.ListEventTypesRequest) (*webhookv1.ListEventTypesResponse, error) {
+	eventTypes := webhook.EventTypes()
+	resp := &webhookv1.ListEventTypesResponse{
+		EventTypes: make([]*webhookv1.WebhookEventType, 0, len(eventTypes)),
+	}
+	for _, eventType := range eventTypes {
+		resp.EventTypes = append(resp.EventTypes, &webhookv1.WebhookEventType{
+			Name:        eventType.Name,
+			Description: eventType.Description,
+		})
+	}
+	return resp, nil
+}
+
+// convertSubscriptionToProto converts a webhook subscription row to proto, leaving out its secret
+func convertSubscriptionToProto(subscription *sqlc.WebhookSubscription) *webhookv1.WebhookSubscription {
+	return &webhookv1.WebhookSubscription{
+		Id:                        subscription.ID.String(),
+		AgentId:                   subscription.AgentID,
+		EventType:                 subscription.EventType,
+		WebhookUrl:                subscription.WebhookUrl,
+		IsActive:                  subscription.IsActive,
+		BatchSize:                 subscription.BatchSize,
+		BatchFlushIntervalSeconds: subscription.BatchFlushIntervalSeconds,
+		CreatedAt:                 timestamppb.New(subscription.CreatedAt),
+		UpdatedAt:                 timestamppb.New(subscription.UpdatedAt),
+	}
+}
+
+// convertDeliveryToProto converts a webhook delivery row to proto
+func convertDeliveryToProto(delivery *sqlc.WebhookDelivery) *webhookv1.WebhookDelivery {
+	proto := &webhookv1.WebhookDelivery{
+		Id:             delivery.ID.String(),
+		SubscriptionId: delivery.SubscriptionID.String(),
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		CreatedAt:      timestamppb.New(delivery.CreatedAt),
+	}
+
+	if delivery.RedeliveryOf.Valid {
+		redeliveryOf := uuid.UUID(delivery.RedeliveryOf.Bytes).String()
+		proto.RedeliveryOf = &redeliveryOf
+	}
+	if delivery.NextRetryAt.Valid {
+		proto.NextRetryAt = timestamppb.New(delivery.NextRetryAt.Time)
+	}
+
+	return proto
+}
+
+// handleServiceError converts service errors to gRPC status codes
+func handleServiceError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrWebhookDeliveryNotFound):
+		return status.Error(codes.NotFound, "webhook delivery not found")
+	case errors.Is(err, domain.ErrWebhookSubscriptionNotFound):
+		return status.Error(codes.NotFound, "webhook subscription not found")
+	case errors.Is(err, domain.ErrWebhookSubscriptionExists):
+		return status.Error(codes.AlreadyExists, "a subscription for this event type and URL already exists")
+	case errors.Is(err, domain.ErrInvalidWebhookURL), errors.Is(err, domain.ErrUnknownWebhookEventType):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrInvalidTimeRange):
+		return status.Error(codes.InvalidArgument, "created_from must be before created_to")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request canceled")
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
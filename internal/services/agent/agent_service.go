@@ -2,9 +2,12 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/kevin07696/payment-service/internal/adapters/database"
 	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
@@ -16,14 +19,14 @@ import (
 
 // agentService implements the AgentService port
 type agentService struct {
-	db            *database.PostgreSQLAdapter
+	db            database.Store
 	secretManager adapterports.SecretManagerAdapter
 	logger        *zap.Logger
 }
 
 // NewAgentService creates a new agent service
 func NewAgentService(
-	db *database.PostgreSQLAdapter,
+	db database.Store,
 	secretManager adapterports.SecretManagerAdapter,
 	logger *zap.Logger,
 ) ports.AgentService {
@@ -71,11 +74,17 @@ func (s *agentService) RegisterAgent(ctx context.Context, req *ports.RegisterAge
 		return nil, fmt.Errorf("mac_secret is required")
 	}
 
+	// Validate data region (defaults to DefaultDataRegion)
+	dataRegion := valueOrDefault(req.DataRegion, domain.DefaultDataRegion)
+	if err := domain.ValidateDataRegion(dataRegion); err != nil {
+		return nil, err
+	}
+
 	// Generate MAC secret path
 	macSecretPath := fmt.Sprintf("payment-service/agents/%s/mac", req.AgentID)
 
 	var agent *domain.Agent
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
 		// Store MAC secret in secret manager
 		_, err := s.secretManager.PutSecret(ctx, macSecretPath, req.MACSecret, nil)
 		if err != nil {
@@ -94,6 +103,7 @@ func (s *agentService) RegisterAgent(ctx context.Context, req *ports.RegisterAge
 			Environment:   string(req.Environment),
 			IsActive:      pgtype.Bool{Bool: true, Valid: true},
 			AgentName:     req.AgentName,
+			DataRegion:    dataRegion,
 		}
 
 		dbAgent, err := q.CreateAgent(ctx, params)
@@ -101,8 +111,8 @@ func (s *agentService) RegisterAgent(ctx context.Context, req *ports.RegisterAge
 			return fmt.Errorf("failed to create agent: %w", err)
 		}
 
-		agent = sqlcAgentToDomain(&dbAgent)
-		return nil
+		agent, err = sqlcAgentToDomain(&dbAgent)
+		return err
 	})
 
 	if err != nil {
@@ -112,6 +122,7 @@ func (s *agentService) RegisterAgent(ctx context.Context, req *ports.RegisterAge
 	s.logger.Info("Agent registered successfully",
 		zap.String("agent_id", agent.AgentID),
 		zap.String("environment", string(agent.Environment)),
+		zap.String("data_region", agent.DataRegion),
 	)
 
 	return agent, nil
@@ -128,7 +139,7 @@ func (s *agentService) GetAgent(ctx context.Context, agentID string) (*domain.Ag
 		return nil, fmt.Errorf("agent not found: %w", err)
 	}
 
-	return sqlcAgentToDomain(&dbAgent), nil
+	return sqlcAgentToDomain(&dbAgent)
 }
 
 // ListAgents lists all registered agents
@@ -167,7 +178,10 @@ func (s *agentService) ListAgents(ctx context.Context, environment *domain.Envir
 
 	agents := make([]*domain.Agent, len(dbAgents))
 	for i, dbAgent := range dbAgents {
-		agents[i] = sqlcAgentToDomain(&dbAgent)
+		agents[i], err = sqlcAgentToDomain(&dbAgent)
+		if err != nil {
+			return nil, 0, err
+		}
 	}
 
 	return agents, int(count), nil
@@ -194,7 +208,7 @@ func (s *agentService) UpdateAgent(ctx context.Context, req *ports.UpdateAgentRe
 	}
 
 	var agent *domain.Agent
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
 		// If MAC secret is being rotated, update it in secret manager
 		if req.MACSecret != nil {
 			_, err := s.secretManager.PutSecret(ctx, existing.MacSecretPath, *req.MACSecret, nil)
@@ -212,6 +226,23 @@ func (s *agentService) UpdateAgent(ctx context.Context, req *ports.UpdateAgentRe
 			TerminalNbr: valueOrDefault(req.TerminalNbr, existing.TerminalNbr),
 			Environment: valueOrEnvironment(req.Environment, existing.Environment),
 			AgentName:   valueOrDefault(req.AgentName, existing.AgentName),
+			DataRegion:  valueOrDefault(req.DataRegion, existing.DataRegion),
+
+			SubscriptionAmountChangeMinDays: existing.SubscriptionAmountChangeMinDays,
+		}
+
+		if err := domain.ValidateDataRegion(params.DataRegion); err != nil {
+			return err
+		}
+
+		if req.SubscriptionAmountChangeMinDays != nil {
+			if *req.SubscriptionAmountChangeMinDays < 0 {
+				return fmt.Errorf("subscription_amount_change_min_days must not be negative")
+			}
+			params.SubscriptionAmountChangeMinDays = pgtype.Int4{
+				Int32: int32(*req.SubscriptionAmountChangeMinDays),
+				Valid: *req.SubscriptionAmountChangeMinDays > 0,
+			}
 		}
 
 		dbAgent, err := q.UpdateAgent(ctx, params)
@@ -219,8 +250,8 @@ func (s *agentService) UpdateAgent(ctx context.Context, req *ports.UpdateAgentRe
 			return fmt.Errorf("failed to update agent: %w", err)
 		}
 
-		agent = sqlcAgentToDomain(&dbAgent)
-		return nil
+		agent, err = sqlcAgentToDomain(&dbAgent)
+		return err
 	})
 
 	if err != nil {
@@ -234,58 +265,173 @@ func (s *agentService) UpdateAgent(ctx context.Context, req *ports.UpdateAgentRe
 	return agent, nil
 }
 
-// DeactivateAgent deactivates an agent
-func (s *agentService) DeactivateAgent(ctx context.Context, agentID, reason string) error {
-	s.logger.Info("Deactivating agent",
-		zap.String("agent_id", agentID),
-		zap.String("reason", reason),
+// DeactivateAgent closes a merchant. Closing is terminal: the merchant can't be reactivated.
+func (s *agentService) DeactivateAgent(ctx context.Context, req *ports.MerchantStatusRequest) (*domain.Agent, error) {
+	return s.changeStatus(ctx, req, domain.MerchantStatusClosed, domain.AuditActionCloseMerchant)
+}
+
+// SuspendMerchant stops new charges for a merchant until it is reactivated
+func (s *agentService) SuspendMerchant(ctx context.Context, req *ports.MerchantStatusRequest) (*domain.Agent, error) {
+	return s.changeStatus(ctx, req, domain.MerchantStatusSuspended, domain.AuditActionSuspendMerchant)
+}
+
+// ReactivateMerchant lifts a merchant's suspension
+func (s *agentService) ReactivateMerchant(ctx context.Context, req *ports.MerchantStatusRequest) (*domain.Agent, error) {
+	return s.changeStatus(ctx, req, domain.MerchantStatusActive, domain.AuditActionReactivateMerchant)
+}
+
+// changeStatus moves a merchant to the target status and records the change in audit_logs.
+// A merchant already in the target status is returned unchanged; a closed merchant can't be moved at all.
+func (s *agentService) changeStatus(ctx context.Context, req *ports.MerchantStatusRequest, target domain.MerchantStatus, action string) (*domain.Agent, error) {
+	s.logger.Info("Changing merchant status",
+		zap.String("agent_id", req.AgentID),
+		zap.String("status", string(target)),
+		zap.String("reason", req.Reason),
 	)
 
-	// Verify agent exists
-	_, err := s.db.Queries().GetAgentByAgentID(ctx, agentID)
-	if err != nil {
-		return fmt.Errorf("agent not found: %w", err)
-	}
+	var agent *domain.Agent
+	err := s.db.WithTx(ctx, func(q sqlc.Querier) error {
+		current, err := q.GetAgentByAgentID(ctx, req.AgentID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrAgentNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get agent: %w", err)
+		}
 
-	err = s.db.Queries().DeactivateAgent(ctx, agentID)
+		from := domain.MerchantStatus(current.Status)
+		if from == target {
+			agent, err = sqlcAgentToDomain(&current)
+			return err
+		}
+		if from == domain.MerchantStatusClosed {
+			return domain.ErrMerchantClosed
+		}
+
+		reason := pgtype.Text{String: req.Reason, Valid: req.Reason != ""}
+		var updated sqlc.AgentCredential
+		switch target {
+		case domain.MerchantStatusSuspended:
+			updated, err = q.SuspendAgent(ctx, sqlc.SuspendAgentParams{AgentID: req.AgentID, Reason: reason})
+		case domain.MerchantStatusActive:
+			updated, err = q.ReactivateAgent(ctx, req.AgentID)
+		default:
+			updated, err = q.CloseAgent(ctx, sqlc.CloseAgentParams{AgentID: req.AgentID, Reason: reason})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to change merchant status: %w", err)
+		}
+		agent, err = sqlcAgentToDomain(&updated)
+		if err != nil {
+			return err
+		}
+
+		before, err := json.Marshal(map[string]string{"status": string(from)})
+		if err != nil {
+			return err
+		}
+		after, err := json.Marshal(map[string]string{"status": string(target), "reason": req.Reason})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, sqlc.CreateAuditLogParams{
+			EventType:   "agent." + action,
+			EntityType:  "agent",
+			EntityID:    req.AgentID,
+			AgentID:     req.AgentID,
+			UserID:      pgtype.Text{String: req.Actor, Valid: req.Actor != ""},
+			Action:      action,
+			BeforeState: before,
+			AfterState:  after,
+			Metadata:    []byte(`{"result":"success"}`),
+		})
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to deactivate agent: %w", err)
+		return nil, err
 	}
 
-	s.logger.Info("Agent deactivated successfully",
-		zap.String("agent_id", agentID),
+	s.logger.Info("Merchant status changed",
+		zap.String("agent_id", agent.AgentID),
+		zap.String("status", string(agent.Status)),
+		zap.String("actor", req.Actor),
 	)
-
-	return nil
+	return agent, nil
 }
 
-// RotateMAC rotates MAC secret in secret manager
-func (s *agentService) RotateMAC(ctx context.Context, req *ports.RotateMACRequest) error {
-	s.logger.Info("Rotating MAC secret",
-		zap.String("agent_id", req.AgentID),
-	)
+// GetEffectiveConfig returns the fully-resolved configuration for an agent (tier defaults + overrides, no secrets)
+func (s *agentService) GetEffectiveConfig(ctx context.Context, agentID string) (*domain.MerchantConfig, error) {
+	dbAgent, err := s.db.Queries().GetAgentByAgentID(ctx, agentID)
+	if err != nil {
+		return nil, domain.ErrAgentNotFound
+	}
 
-	// Get agent to retrieve MAC secret path
-	agent, err := s.db.Queries().GetAgentByAgentID(ctx, req.AgentID)
+	agent, err := sqlcAgentToDomain(&dbAgent)
 	if err != nil {
-		return fmt.Errorf("agent not found: %w", err)
+		return nil, err
 	}
+	return agent.EffectiveConfig(), nil
+}
 
-	if !agent.IsActive.Valid || !agent.IsActive.Bool {
-		return fmt.Errorf("cannot rotate MAC for inactive agent")
+// UpdateMerchantSettings changes a merchant's self-service settings and records the change in audit_logs
+func (s *agentService) UpdateMerchantSettings(ctx context.Context, req *ports.UpdateMerchantSettingsRequest) (*domain.Agent, error) {
+	if req.StatementDescriptor != nil {
+		if err := domain.ValidateStatementDescriptor(*req.StatementDescriptor); err != nil {
+			return nil, err
+		}
 	}
+	if req.NotificationEmail != nil {
+		if err := domain.ValidateNotificationEmail(*req.NotificationEmail); err != nil {
+			return nil, err
+		}
+	}
+
+	var agent *domain.Agent
+	err := s.db.WithTx(ctx, func(q sqlc.Querier) error {
+		dbAgent, err := q.UpdateAgentSettings(ctx, sqlc.UpdateAgentSettingsParams{
+			AgentID:             req.AgentID,
+			StatementDescriptor: optionalText(req.StatementDescriptor),
+			NotificationEmail:   optionalText(req.NotificationEmail),
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrAgentNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update merchant settings: %w", err)
+		}
+		agent, err = sqlcAgentToDomain(&dbAgent)
+		if err != nil {
+			return err
+		}
 
-	// Update MAC secret in secret manager
-	_, err = s.secretManager.PutSecret(ctx, agent.MacSecretPath, req.NewMACSecret, nil)
+		changes, err := json.Marshal(map[string]string{
+			"statement_descriptor": agent.StatementDescriptor,
+			"notification_email":   agent.NotificationEmail,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, sqlc.CreateAuditLogParams{
+			EventType:  "agent." + domain.AuditActionUpdateSettings,
+			EntityType: "agent",
+			EntityID:   agent.AgentID,
+			AgentID:    agent.AgentID,
+			UserID:     pgtype.Text{String: req.Actor, Valid: req.Actor != ""},
+			Action:     domain.AuditActionUpdateSettings,
+			AfterState: changes,
+			Metadata:   []byte(`{"result":"success"}`),
+		})
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to rotate MAC secret: %w", err)
+		return nil, err
 	}
 
-	s.logger.Info("MAC secret rotated successfully",
-		zap.String("agent_id", req.AgentID),
+	s.logger.Info("Merchant settings updated",
+		zap.String("agent_id", agent.AgentID),
+		zap.String("actor", req.Actor),
 	)
-
-	return nil
+	return agent, nil
 }
 
 // getAgentByIdempotencyKey retrieves an agent by idempotency key
@@ -297,8 +443,8 @@ func (s *agentService) getAgentByIdempotencyKey(ctx context.Context, key string)
 
 // Helper functions
 
-func sqlcAgentToDomain(dbAgent *sqlc.AgentCredential) *domain.Agent {
-	return &domain.Agent{
+func sqlcAgentToDomain(dbAgent *sqlc.AgentCredential) (*domain.Agent, error) {
+	agent := &domain.Agent{
 		ID:            dbAgent.ID.String(),
 		AgentID:       dbAgent.AgentID,
 		CustNbr:       dbAgent.CustNbr,
@@ -307,10 +453,45 @@ func sqlcAgentToDomain(dbAgent *sqlc.AgentCredential) *domain.Agent {
 		TerminalNbr:   dbAgent.TerminalNbr,
 		MACSecretPath: dbAgent.MacSecretPath,
 		Environment:   domain.Environment(dbAgent.Environment),
+		DataRegion:    dbAgent.DataRegion,
 		IsActive:      dbAgent.IsActive.Bool,
+		Tier:          domain.MerchantTier(dbAgent.Tier),
 		CreatedAt:     dbAgent.CreatedAt,
 		UpdatedAt:     dbAgent.UpdatedAt,
+
+		StatementDescriptor: dbAgent.StatementDescriptor.String,
+		NotificationEmail:   dbAgent.NotificationEmail.String,
+
+		Status:       domain.MerchantStatus(dbAgent.Status),
+		StatusReason: dbAgent.StatusReason.String,
+	}
+	if dbAgent.SuspendedAt.Valid {
+		agent.SuspendedAt = &dbAgent.SuspendedAt.Time
+	}
+	if dbAgent.ClosedAt.Valid {
+		agent.ClosedAt = &dbAgent.ClosedAt.Time
+	}
+
+	overrides, err := domain.ParseMerchantConfigOverrides(dbAgent.ConfigOverrides)
+	if err != nil {
+		return nil, fmt.Errorf("agent %s: %w", dbAgent.AgentID, err)
+	}
+	agent.ConfigOverrides = overrides
+
+	if dbAgent.SubscriptionAmountChangeMinDays.Valid {
+		minDays := int(dbAgent.SubscriptionAmountChangeMinDays.Int32)
+		agent.SubscriptionAmountChangeMinDays = &minDays
+	}
+
+	return agent, nil
+}
+
+// optionalText maps an unset field to NULL, which UpdateAgentSettings reads as "keep the current value"
+func optionalText(value *string) pgtype.Text {
+	if value == nil {
+		return pgtype.Text{}
 	}
+	return pgtype.Text{String: *value, Valid: true}
 }
 
 func valueOrDefault(value *string, defaultValue string) string {
@@ -0,0 +1,25 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+func TestSqlcAgentToDomain_ConfigOverrides(t *testing.T) {
+	agent, err := sqlcAgentToDomain(&sqlc.AgentCredential{
+		AgentID:         "merchant-1",
+		Tier:            "standard",
+		ConfigOverrides: []byte(`{"require_settled_refund": true}`),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, agent.ConfigOverrides)
+	assert.True(t, *agent.ConfigOverrides.RequireSettledRefund)
+
+	_, err = sqlcAgentToDomain(&sqlc.AgentCredential{AgentID: "merchant-1", ConfigOverrides: []byte(`not json`)})
+	assert.ErrorIs(t, err, domain.ErrInvalidMerchantConfig, "unreadable overrides aren't dropped silently")
+}
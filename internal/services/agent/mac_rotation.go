@@ -0,0 +1,140 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/ports"
+)
+
+// macSecretBytes is the size of a generated MAC secret (hex encoded to twice this length)
+const macSecretBytes = 32
+
+// RotateMAC replaces the merchant's MAC with req.NewMACSecret (a random one when empty) and confirms the rotation
+// by reading the new version back. With KeepPrevious, the replaced MAC is stored at domain.PreviousMACPath, where
+// callback verification accepts it for domain.PreviousMACGracePeriod. The rotation is recorded in audit_logs
+// with req.Actor as the user.
+func (s *agentService) RotateMAC(ctx context.Context, req *ports.RotateMACRequest) (*ports.MACRotation, error) {
+	s.logger.Info("Rotating MAC secret",
+		zap.String("agent_id", req.AgentID),
+	)
+
+	agent, err := s.db.Queries().GetAgentByAgentID(ctx, req.AgentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrAgentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	if !agent.IsActive.Valid || !agent.IsActive.Bool {
+		return nil, domain.ErrAgentInactive
+	}
+
+	newMAC := req.NewMACSecret
+	if newMAC == "" {
+		if newMAC, err = generateMAC(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read the current MAC before rotating so it can be kept; backends don't all retain old versions
+	var previous *adapterports.Secret
+	if req.KeepPrevious {
+		if previous, err = s.secretManager.GetSecret(ctx, agent.MacSecretPath); err != nil {
+			return nil, fmt.Errorf("read current MAC: %w", err)
+		}
+	}
+
+	info, err := s.secretManager.RotateSecret(ctx, agent.MacSecretPath, newMAC)
+	if err != nil {
+		return nil, fmt.Errorf("rotate MAC secret: %w", err)
+	}
+
+	confirmed, err := s.secretManager.GetSecretVersion(ctx, agent.MacSecretPath, info.CurrentVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%w: read back version %s: %v", domain.ErrMACRotationFailed, info.CurrentVersion, err)
+	}
+	if confirmed.Value != newMAC {
+		return nil, fmt.Errorf("%w: version %s does not hold the new MAC", domain.ErrMACRotationFailed, info.CurrentVersion)
+	}
+
+	result := &ports.MACRotation{
+		AgentID:         req.AgentID,
+		MACSecretPath:   agent.MacSecretPath,
+		CurrentVersion:  info.CurrentVersion,
+		PreviousVersion: info.PreviousVersion,
+		RotatedAt:       time.Now().UTC(),
+	}
+
+	if req.KeepPrevious {
+		result.PreviousPath = domain.PreviousMACPath(agent.MacSecretPath)
+		_, err := s.secretManager.PutSecret(ctx, result.PreviousPath, previous.Value, map[string]string{
+			"version":    info.PreviousVersion,
+			"rotated_at": result.RotatedAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("keep previous MAC: %w", err)
+		}
+	}
+
+	if err := s.recordMACRotation(ctx, req.Actor, result); err != nil {
+		// The new MAC is live; failing here would invite a second rotation
+		s.logger.Error("Failed to record MAC rotation audit log",
+			zap.String("agent_id", req.AgentID),
+			zap.Error(err),
+		)
+	}
+
+	s.logger.Info("MAC secret rotated",
+		zap.String("agent_id", req.AgentID),
+		zap.String("current_version", result.CurrentVersion),
+		zap.String("previous_version", result.PreviousVersion),
+		zap.Bool("previous_kept", req.KeepPrevious),
+		zap.String("actor", req.Actor),
+	)
+	return result, nil
+}
+
+func (s *agentService) recordMACRotation(ctx context.Context, actor string, result *ports.MACRotation) error {
+	changes, err := json.Marshal(map[string]interface{}{
+		"mac_secret_path":  result.MACSecretPath,
+		"current_version":  result.CurrentVersion,
+		"previous_version": result.PreviousVersion,
+		"previous_path":    result.PreviousPath,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Queries().CreateAuditLog(ctx, sqlc.CreateAuditLogParams{
+		EventType:  "agent." + domain.AuditActionRotateMAC,
+		EntityType: "agent",
+		EntityID:   result.AgentID,
+		AgentID:    result.AgentID,
+		UserID:     pgtype.Text{String: actor, Valid: actor != ""},
+		Action:     domain.AuditActionRotateMAC,
+		AfterState: changes,
+		Metadata:   []byte(`{"result":"success"}`),
+	})
+	return err
+}
+
+func generateMAC() (string, error) {
+	b := make([]byte, macSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate MAC: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,195 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/ports"
+)
+
+// mockSecretManager is an in-memory secret manager that keeps every version, like AWS and Vault
+type mockSecretManager struct {
+	versions map[string][]string
+	// staleReads makes GetSecretVersion return the prior value, as a lagging replica would
+	staleReads bool
+}
+
+func newMockSecretManager() *mockSecretManager {
+	return &mockSecretManager{versions: make(map[string][]string)}
+}
+
+func (m *mockSecretManager) GetSecret(ctx context.Context, path string) (*adapterports.Secret, error) {
+	versions := m.versions[path]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("secret not found: %s", path)
+	}
+	return &adapterports.Secret{Value: versions[len(versions)-1], Version: fmt.Sprintf("v%d", len(versions))}, nil
+}
+
+func (m *mockSecretManager) GetSecretVersion(ctx context.Context, path, version string) (*adapterports.Secret, error) {
+	var n int
+	if _, err := fmt.Sscanf(version, "v%d", &n); err != nil || n < 1 || n > len(m.versions[path]) {
+		return nil, fmt.Errorf("secret version not found: %s@%s", path, version)
+	}
+	if m.staleReads && n > 1 {
+		n--
+	}
+	return &adapterports.Secret{Value: m.versions[path][n-1], Version: version}, nil
+}
+
+func (m *mockSecretManager) PutSecret(ctx context.Context, path, value string, metadata map[string]string) (string, error) {
+	m.versions[path] = append(m.versions[path], value)
+	return fmt.Sprintf("v%d", len(m.versions[path])), nil
+}
+
+func (m *mockSecretManager) RotateSecret(ctx context.Context, path, newValue string) (*adapterports.SecretRotationInfo, error) {
+	current, err := m.GetSecret(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	version, _ := m.PutSecret(ctx, path, newValue, nil)
+	return &adapterports.SecretRotationInfo{CurrentVersion: version, PreviousVersion: current.Version}, nil
+}
+
+func (m *mockSecretManager) DeleteSecret(ctx context.Context, path string) error {
+	delete(m.versions, path)
+	return nil
+}
+
+// fakeRotationQueries serves agents from memory and collects audit logs
+type fakeRotationQueries struct {
+	sqlc.Querier
+	agents    map[string]sqlc.AgentCredential
+	auditLogs []sqlc.CreateAuditLogParams
+	agentErr  error
+}
+
+func (f *fakeRotationQueries) Queries() sqlc.Querier { return f }
+
+func (f *fakeRotationQueries) WithTx(ctx context.Context, fn func(sqlc.Querier) error) error {
+	return fn(f)
+}
+
+func (f *fakeRotationQueries) GetAgentByAgentID(ctx context.Context, agentID string) (sqlc.AgentCredential, error) {
+	if f.agentErr != nil {
+		return sqlc.AgentCredential{}, f.agentErr
+	}
+	agent, ok := f.agents[agentID]
+	if !ok {
+		return sqlc.AgentCredential{}, pgx.ErrNoRows
+	}
+	return agent, nil
+}
+
+func (f *fakeRotationQueries) CreateAuditLog(ctx context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error) {
+	f.auditLogs = append(f.auditLogs, arg)
+	return sqlc.AuditLog{ID: int64(len(f.auditLogs))}, nil
+}
+
+const testMACPath = "payment-service/agents/merchant-1/mac"
+
+func newRotationFixture(active bool) (ports.AgentService, *mockSecretManager, *fakeRotationQueries) {
+	secrets := newMockSecretManager()
+	secrets.versions[testMACPath] = []string{"old-mac"}
+	queries := &fakeRotationQueries{agents: map[string]sqlc.AgentCredential{
+		"merchant-1": {
+			AgentID:       "merchant-1",
+			MacSecretPath: testMACPath,
+			IsActive:      pgtype.Bool{Bool: active, Valid: true},
+		},
+	}}
+	return NewAgentService(queries, secrets, zap.NewNop()), secrets, queries
+}
+
+func TestRotateMAC_RotateKeepsPrevious(t *testing.T) {
+	ctx := context.Background()
+	svc, secrets, queries := newRotationFixture(true)
+
+	result, err := svc.RotateMAC(ctx, &ports.RotateMACRequest{AgentID: "merchant-1", KeepPrevious: true, Actor: "admin-cli:ops"})
+	require.NoError(t, err)
+	assert.Equal(t, "v2", result.CurrentVersion)
+	assert.Equal(t, "v1", result.PreviousVersion)
+
+	current, err := secrets.GetSecret(ctx, testMACPath)
+	require.NoError(t, err)
+	assert.Len(t, current.Value, 2*macSecretBytes, "a random MAC is generated when none is given")
+	assert.NotEqual(t, "old-mac", current.Value)
+
+	previous, err := secrets.GetSecret(ctx, domain.PreviousMACPath(testMACPath))
+	require.NoError(t, err)
+	assert.Equal(t, "old-mac", previous.Value)
+	assert.Equal(t, domain.PreviousMACPath(testMACPath), result.PreviousPath)
+
+	require.Len(t, queries.auditLogs, 1)
+	entry := queries.auditLogs[0]
+	assert.Equal(t, domain.AuditActionRotateMAC, entry.Action)
+	assert.Equal(t, "merchant-1", entry.EntityID)
+	assert.Equal(t, "admin-cli:ops", entry.UserID.String)
+	assert.NotContains(t, string(entry.AfterState), current.Value, "the audit entry never holds the MAC")
+	assert.NotContains(t, string(entry.AfterState), "old-mac")
+
+	var changes map[string]string
+	require.NoError(t, json.Unmarshal(entry.AfterState, &changes))
+	assert.Equal(t, "v2", changes["current_version"])
+}
+
+func TestRotateMAC_RotateWithoutKeepingPrevious(t *testing.T) {
+	ctx := context.Background()
+	svc, secrets, _ := newRotationFixture(true)
+
+	result, err := svc.RotateMAC(ctx, &ports.RotateMACRequest{AgentID: "merchant-1", NewMACSecret: "new-mac", Actor: "admin-cli:ops"})
+	require.NoError(t, err)
+	assert.Empty(t, result.PreviousPath)
+
+	current, err := secrets.GetSecret(ctx, testMACPath)
+	require.NoError(t, err)
+	assert.Equal(t, "new-mac", current.Value)
+
+	_, err = secrets.GetSecret(ctx, domain.PreviousMACPath(testMACPath))
+	assert.Error(t, err)
+}
+
+func TestRotateMAC_UnconfirmedRotation(t *testing.T) {
+	svc, secrets, queries := newRotationFixture(true)
+	secrets.staleReads = true
+
+	_, err := svc.RotateMAC(context.Background(), &ports.RotateMACRequest{AgentID: "merchant-1", NewMACSecret: "new-mac", KeepPrevious: true})
+	assert.ErrorIs(t, err, domain.ErrMACRotationFailed)
+	assert.Empty(t, queries.auditLogs, "an unconfirmed rotation isn't recorded as done")
+}
+
+func TestRotateMAC_RejectsUnknownAndInactiveAgents(t *testing.T) {
+	ctx := context.Background()
+
+	svc, _, _ := newRotationFixture(true)
+	_, err := svc.RotateMAC(ctx, &ports.RotateMACRequest{AgentID: "unknown", KeepPrevious: true})
+	assert.ErrorIs(t, err, domain.ErrAgentNotFound)
+
+	svc, secrets, _ := newRotationFixture(false)
+	_, err = svc.RotateMAC(ctx, &ports.RotateMACRequest{AgentID: "merchant-1", KeepPrevious: true})
+	assert.ErrorIs(t, err, domain.ErrAgentInactive)
+	assert.Equal(t, []string{"old-mac"}, secrets.versions[testMACPath])
+}
+
+func TestRotateMAC_ReportsLookupFailures(t *testing.T) {
+	svc, secrets, queries := newRotationFixture(true)
+	queries.agentErr = errors.New("connection refused")
+
+	_, err := svc.RotateMAC(context.Background(), &ports.RotateMACRequest{AgentID: "merchant-1", KeepPrevious: true})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, domain.ErrAgentNotFound, "a database failure isn't a missing merchant")
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, []string{"old-mac"}, secrets.versions[testMACPath])
+}
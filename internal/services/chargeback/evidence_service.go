@@ -0,0 +1,139 @@
+package chargeback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// EvidenceQueries defines the chargeback queries an evidence submission needs
+type EvidenceQueries interface {
+	GetChargebackByID(ctx context.Context, id uuid.UUID) (sqlc.Chargeback, error)
+	StoreChargebackEvidence(ctx context.Context, arg sqlc.StoreChargebackEvidenceParams) (sqlc.Chargeback, error)
+}
+
+// DatabaseAdapter wraps a database adapter to extract queries
+type DatabaseAdapter interface {
+	Queries() sqlc.Querier
+}
+
+// SubmitEvidenceRequest is a merchant's representment of a chargeback
+type SubmitEvidenceRequest struct {
+	ChargebackID     uuid.UUID
+	AgentID          string
+	EvidenceFileURLs []string // References to documents already uploaded to blob storage
+	Narrative        string   // Rebuttal explaining why the chargeback is invalid
+}
+
+// EvidenceService stores chargeback evidence for the merchant to submit through North's web portal.
+// North has no dispute write API, so nothing is sent to North from here.
+type EvidenceService struct {
+	queries EvidenceQueries
+	now     func() time.Time
+	logger  *zap.Logger
+}
+
+// NewEvidenceService creates an evidence service from a database adapter
+func NewEvidenceService(db DatabaseAdapter, logger *zap.Logger) *EvidenceService {
+	return NewEvidenceServiceWithQueries(db.Queries(), logger)
+}
+
+// NewEvidenceServiceWithQueries creates an evidence service with a custom query executor
+func NewEvidenceServiceWithQueries(queries EvidenceQueries, logger *zap.Logger) *EvidenceService {
+	return &EvidenceService{
+		queries: queries,
+		now:     time.Now,
+		logger:  logger,
+	}
+}
+
+// SubmitEvidence attaches the evidence files and narrative to the chargeback and marks the evidence ready for
+// manual submission through North's portal. The chargeback keeps its status, and its deadline reminders, until
+// dispute sync reports North's answer. Only new and pending chargebacks accept evidence, and only until the end
+// of their respond-by date (UTC).
+func (s *EvidenceService) SubmitEvidence(ctx context.Context, req *SubmitEvidenceRequest) (*sqlc.Chargeback, error) {
+	narrative := strings.TrimSpace(req.Narrative)
+	if narrative == "" {
+		return nil, fmt.Errorf("%w: narrative is required", domain.ErrInvalidChargebackEvidence)
+	}
+	fileURLs := make([]string, 0, len(req.EvidenceFileURLs))
+	for _, fileURL := range req.EvidenceFileURLs {
+		if strings.TrimSpace(fileURL) == "" {
+			return nil, fmt.Errorf("%w: evidence file references must not be empty", domain.ErrInvalidChargebackEvidence)
+		}
+		if !slices.Contains(fileURLs, fileURL) {
+			fileURLs = append(fileURLs, fileURL)
+		}
+	}
+
+	now := s.now().UTC()
+	// The status, owner and deadline checks are part of the update, so a concurrent sync or auto-loss can't slip between them
+	stored, err := s.queries.StoreChargebackEvidence(ctx, sqlc.StoreChargebackEvidenceParams{
+		FileUrls:      fileURLs,
+		ResponseNotes: pgtype.Text{String: narrative, Valid: true},
+		ID:            req.ChargebackID,
+		AgentID:       req.AgentID,
+		AsOf:          pgtype.Date{Time: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, s.rejectionReason(ctx, req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("store chargeback evidence: %w", err)
+	}
+
+	s.logger.Info("Chargeback evidence stored for manual submission",
+		zap.String("chargeback_id", stored.ID.String()),
+		zap.String("agent_id", stored.AgentID),
+		zap.String("case_number", stored.CaseNumber),
+		zap.Int("evidence_files", len(stored.EvidenceFiles)),
+	)
+	return &stored, nil
+}
+
+// rejectionReason explains why a chargeback didn't accept evidence
+func (s *EvidenceService) rejectionReason(ctx context.Context, req *SubmitEvidenceRequest) error {
+	chargeback, err := s.queries.GetChargebackByID(ctx, req.ChargebackID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrChargebackNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("get chargeback: %w", err)
+	}
+	// Another merchant's chargeback is reported as missing rather than forbidden
+	if chargeback.AgentID != req.AgentID || chargeback.DeletedAt.Valid {
+		return domain.ErrChargebackNotFound
+	}
+	if err := s.checkAcceptsEvidence(&chargeback); err != nil {
+		return err
+	}
+	// The chargeback changed between the update and this read
+	return domain.ErrChargebackCannotRespond
+}
+
+// checkAcceptsEvidence rejects chargebacks that are resolved, already answered or past their deadline
+func (s *EvidenceService) checkAcceptsEvidence(chargeback *sqlc.Chargeback) error {
+	switch domain.ChargebackStatus(chargeback.Status) {
+	case domain.ChargebackStatusNew, domain.ChargebackStatusPending:
+	case domain.ChargebackStatusWon, domain.ChargebackStatusLost, domain.ChargebackStatusAccepted:
+		return domain.ErrChargebackAlreadyResolved
+	default:
+		return domain.ErrChargebackCannotRespond
+	}
+
+	if chargeback.RespondByDate.Valid && !s.now().Before(chargeback.RespondByDate.Time.AddDate(0, 0, 1)) {
+		return domain.ErrChargebackDeadlinePassed
+	}
+	return nil
+}
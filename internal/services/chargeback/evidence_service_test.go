@@ -0,0 +1,147 @@
+package chargeback
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// fakeEvidenceQueries keeps chargebacks in memory
+type fakeEvidenceQueries struct {
+	chargebacks map[uuid.UUID]sqlc.Chargeback
+	stores      int
+}
+
+func (f *fakeEvidenceQueries) GetChargebackByID(ctx context.Context, id uuid.UUID) (sqlc.Chargeback, error) {
+	cb, ok := f.chargebacks[id]
+	if !ok {
+		return sqlc.Chargeback{}, pgx.ErrNoRows
+	}
+	cb.EvidenceFiles = slices.Clone(cb.EvidenceFiles)
+	return cb, nil
+}
+
+// StoreChargebackEvidence applies the same conditions as the SQL update
+func (f *fakeEvidenceQueries) StoreChargebackEvidence(ctx context.Context, arg sqlc.StoreChargebackEvidenceParams) (sqlc.Chargeback, error) {
+	f.stores++
+	cb, ok := f.chargebacks[arg.ID]
+	if !ok || cb.AgentID != arg.AgentID || cb.DeletedAt.Valid ||
+		(cb.Status != "new" && cb.Status != "pending") ||
+		(cb.RespondByDate.Valid && cb.RespondByDate.Time.Before(arg.AsOf.Time)) {
+		return sqlc.Chargeback{}, pgx.ErrNoRows
+	}
+	for _, fileURL := range arg.FileUrls {
+		if !slices.Contains(cb.EvidenceFiles, fileURL) {
+			cb.EvidenceFiles = append(cb.EvidenceFiles, fileURL)
+		}
+	}
+	cb.ResponseNotes = arg.ResponseNotes
+	cb.EvidenceReadyAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	f.chargebacks[arg.ID] = cb
+	return cb, nil
+}
+
+var testNow = time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+func newEvidenceFixture(cb sqlc.Chargeback) (*EvidenceService, *fakeEvidenceQueries) {
+	queries := &fakeEvidenceQueries{chargebacks: map[uuid.UUID]sqlc.Chargeback{cb.ID: cb}}
+	svc := NewEvidenceServiceWithQueries(queries, zap.NewNop())
+	svc.now = func() time.Time { return testNow }
+	return svc, queries
+}
+
+func testChargeback(status string, respondBy time.Time) sqlc.Chargeback {
+	return sqlc.Chargeback{
+		ID:            uuid.New(),
+		AgentID:       "merchant-1",
+		CaseNumber:    "CASE-100",
+		Status:        status,
+		RespondByDate: pgtype.Date{Time: respondBy, Valid: true},
+		EvidenceFiles: []string{"s3://evidence/receipt.pdf"},
+	}
+}
+
+func TestSubmitEvidence_Success(t *testing.T) {
+	// The deadline day itself still accepts evidence
+	cb := testChargeback("pending", time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC))
+	svc, queries := newEvidenceFixture(cb)
+
+	updated, err := svc.SubmitEvidence(context.Background(), &SubmitEvidenceRequest{
+		ChargebackID:     cb.ID,
+		AgentID:          "merchant-1",
+		EvidenceFileURLs: []string{"s3://evidence/receipt.pdf", "s3://evidence/tracking.jpg", "s3://evidence/tracking.jpg"},
+		Narrative:        "  Item was delivered and signed for.  ",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "pending", updated.Status, "the status waits for North's answer")
+	assert.False(t, updated.ResponseSubmittedAt.Valid, "deadline reminders continue until the merchant submits through North")
+	assert.True(t, updated.EvidenceReadyAt.Valid)
+	assert.Equal(t, "Item was delivered and signed for.", updated.ResponseNotes.String)
+	assert.Equal(t, []string{"s3://evidence/receipt.pdf", "s3://evidence/tracking.jpg"},
+		queries.chargebacks[cb.ID].EvidenceFiles, "files already on the chargeback aren't added twice")
+	assert.Equal(t, 1, queries.stores)
+}
+
+func TestSubmitEvidence_PastDeadline(t *testing.T) {
+	cb := testChargeback("new", time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC))
+	svc, queries := newEvidenceFixture(cb)
+
+	_, err := svc.SubmitEvidence(context.Background(), &SubmitEvidenceRequest{
+		ChargebackID:     cb.ID,
+		AgentID:          "merchant-1",
+		EvidenceFileURLs: []string{"s3://evidence/tracking.jpg"},
+		Narrative:        "Item was delivered.",
+	})
+	assert.ErrorIs(t, err, domain.ErrChargebackDeadlinePassed)
+	assert.Len(t, queries.chargebacks[cb.ID].EvidenceFiles, 1)
+	assert.False(t, queries.chargebacks[cb.ID].EvidenceReadyAt.Valid)
+}
+
+func TestSubmitEvidence_RejectsClosedChargebacks(t *testing.T) {
+	respondBy := testNow.AddDate(0, 0, 7)
+	tests := map[string]error{
+		"under_review": domain.ErrChargebackCannotRespond,
+		"responded":    domain.ErrChargebackCannotRespond,
+		"won":          domain.ErrChargebackAlreadyResolved,
+		"accepted":     domain.ErrChargebackAlreadyResolved,
+	}
+	for status, wantErr := range tests {
+		t.Run(status, func(t *testing.T) {
+			cb := testChargeback(status, respondBy)
+			svc, queries := newEvidenceFixture(cb)
+
+			_, err := svc.SubmitEvidence(context.Background(), &SubmitEvidenceRequest{
+				ChargebackID: cb.ID,
+				AgentID:      "merchant-1",
+				Narrative:    "Item was delivered.",
+			})
+			assert.ErrorIs(t, err, wantErr)
+			assert.False(t, queries.chargebacks[cb.ID].EvidenceReadyAt.Valid)
+		})
+	}
+}
+
+func TestSubmitEvidence_OtherMerchantsChargeback(t *testing.T) {
+	cb := testChargeback("new", testNow.AddDate(0, 0, 7))
+	svc, queries := newEvidenceFixture(cb)
+
+	_, err := svc.SubmitEvidence(context.Background(), &SubmitEvidenceRequest{
+		ChargebackID: cb.ID,
+		AgentID:      "merchant-2",
+		Narrative:    "Item was delivered.",
+	})
+	assert.ErrorIs(t, err, domain.ErrChargebackNotFound)
+	assert.False(t, queries.chargebacks[cb.ID].EvidenceReadyAt.Valid)
+}
@@ -3,58 +3,138 @@ package payment
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/kevin07696/payment-service/internal/adapters/database"
+	"github.com/kevin07696/payment-service/internal/adapters/fraud"
 	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
 	"github.com/kevin07696/payment-service/internal/db/sqlc"
 	"github.com/kevin07696/payment-service/internal/domain"
 	"github.com/kevin07696/payment-service/internal/services/ports"
+	"github.com/kevin07696/payment-service/internal/services/webhook"
+	pkgerrors "github.com/kevin07696/payment-service/pkg/errors"
+	"github.com/kevin07696/payment-service/pkg/observability"
+	"github.com/kevin07696/payment-service/pkg/security"
 	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
+// EventPublisher delivers webhook events to the merchant's active subscriptions
+type EventPublisher interface {
+	DeliverEvent(ctx context.Context, event *webhook.WebhookEvent) error
+}
+
+// MaxTransactionStatusBatch bounds how many transaction IDs one status or batch lookup accepts
+const MaxTransactionStatusBatch = 100
+
+// MaxSaleBatch bounds how many sales one BatchSale call accepts
+const MaxSaleBatch = 100
+
+// saleBatchStaleAfter is how long an unfinished batch claim rejects retries of its key
+const saleBatchStaleAfter = 10 * time.Minute
+
+// pendingTransactionStaleAfter is how long a pending row reserving an idempotency key rejects retries of the key.
+// It outlasts any EPX call, so by then the original request has recorded its result or given up.
+const pendingTransactionStaleAfter = 2 * time.Minute
+
+// uniqueViolation is the PostgreSQL unique_violation code, raised when a merchant's idempotency key is already taken
+const uniqueViolation = "23505"
+
 // paymentService implements the PaymentService port
 type paymentService struct {
-	db            *database.PostgreSQLAdapter
+	db            database.Store
 	serverPost    adapterports.ServerPostAdapter
+	epxEnv        domain.Environment // Environment serverPost is configured for; merchants of the other are refused
 	secretManager adapterports.SecretManagerAdapter
+	events        EventPublisher
+	fees          *domain.FeeSchedule
+	fraud         adapterports.FraudScorer
+	fraudPolicy   FraudPolicy
+	auditPolicy   *security.RedactionPolicy
 	logger        *zap.Logger
 }
 
+// FraudPolicy decides charges the fraud scorer gave no usable verdict on. Empty fields take the defaults.
+type FraudPolicy struct {
+	OnUnknownDecision adapterports.FraudDecision // Empty or unrecognized decision (default decline)
+	OnScorerError     adapterports.FraudDecision // Scorer failed or timed out (default review)
+}
+
+// withDefaults fills in the decisions left empty
+func (p FraudPolicy) withDefaults() FraudPolicy {
+	if p.OnUnknownDecision == "" {
+		p.OnUnknownDecision = adapterports.FraudDecisionDecline
+	}
+	if p.OnScorerError == "" {
+		p.OnScorerError = adapterports.FraudDecisionReview
+	}
+	return p
+}
+
 // NewPaymentService creates a new payment service
+// epxEnv is the EPX environment serverPost is configured for; merchants of the other environment are refused.
+// events may be nil to disable transaction webhooks; fees may be nil to use DefaultFeeSchedule;
+// fraud may be nil to skip fraud screening (every charge is approved); fraudPolicy decides charges it can't score
 func NewPaymentService(
-	db *database.PostgreSQLAdapter,
+	db database.Store,
 	serverPost adapterports.ServerPostAdapter,
+	epxEnv domain.Environment,
 	secretManager adapterports.SecretManagerAdapter,
+	events EventPublisher,
+	fees *domain.FeeSchedule,
+	fraudScorer adapterports.FraudScorer,
+	fraudPolicy FraudPolicy,
 	logger *zap.Logger,
 ) ports.PaymentService {
+	if fees == nil {
+		fees = domain.DefaultFeeSchedule()
+	}
+	if fraudScorer == nil {
+		fraudScorer = fraud.NewNoopScorer()
+	}
+
 	return &paymentService{
 		db:            db,
 		serverPost:    serverPost,
+		epxEnv:        epxEnv,
 		secretManager: secretManager,
+		events:        events,
+		fees:          fees,
+		fraud:         fraudScorer,
+		fraudPolicy:   fraudPolicy.withDefaults(),
+		auditPolicy:   security.DefaultRedactionPolicy(),
 		logger:        logger,
 	}
 }
 
 // Sale combines authorize and capture in one operation
-func (s *paymentService) Sale(ctx context.Context, req *ports.SaleRequest) (*domain.Transaction, error) {
+func (s *paymentService) Sale(ctx context.Context, req *ports.SaleRequest) (result *domain.Transaction, err error) {
+	defer func() { s.audit(ctx, s.auditEntry(domain.AuditActionSale, req.Actor, req.AgentID, req, result, err)) }()
+
 	s.logger.Info("Processing sale transaction",
 		zap.String("agent_id", req.AgentID),
 		zap.String("amount", req.Amount),
 	)
 
-	// Check idempotency
-	if req.IdempotencyKey != nil {
-		existing, err := s.GetTransactionByIdempotencyKey(ctx, *req.IdempotencyKey)
-		if err == nil {
-			s.logger.Info("Idempotent request, returning existing transaction",
-				zap.String("transaction_id", existing.ID),
-			)
-			return existing, nil
-		}
+	// Check idempotency; a reused key must come with the same request
+	fingerprint := saleFingerprint(req)
+	if existing, err := s.replayIdempotent(ctx, req.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree); err != nil || existing != nil {
+		return existing, err
+	}
+
+	// Kill switch: operators can stop new charges, globally or per merchant, during an incident
+	if err := database.CheckChargesEnabled(ctx, s.db.Queries(), req.AgentID); err != nil {
+		s.logger.Warn("Charge refused: charges are disabled", zap.String("agent_id", req.AgentID), zap.Error(err))
+		return nil, err
 	}
 
 	// Get agent credentials using sqlc
@@ -62,20 +142,49 @@ func (s *paymentService) Sale(ctx context.Context, req *ports.SaleRequest) (*dom
 	if err != nil {
 		return nil, fmt.Errorf("failed to get agent: %w", err)
 	}
+	log := merchantLogger(s.logger, &agent)
+
+	// Suspended and closed merchants can't start new charges
+	if err := domain.MerchantStatus(agent.Status).CheckCharge(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEPXEnvironment(log, &agent); err != nil {
+		return nil, err
+	}
+	config, err := resolveMerchantConfig(log, &agent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Amount bounds: a $0.00 or runaway amount never reaches EPX
+	if err := checkAmountRange(config, req.Amount); err != nil {
+		log.Warn("Amount outside the merchant's range", zap.Error(err))
+		return nil, err
+	}
+
+	if req.ThreeDS != nil {
+		if err := req.ThreeDS.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	descriptor, err := statementDescriptor(req.StatementDescriptor, &agent)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check if agent is active (Valid must be true and Bool must be true)
-	if !agent.IsActive.Valid || !agent.IsActive.Bool {
-		return nil, domain.ErrAgentInactive
+	if err := database.ResolveCustomer(ctx, s.db.Queries(), config, req.AgentID, req.CustomerID, req.CustomerEmail); err != nil {
+		return nil, err
 	}
 
 	// Get MAC secret from secret manager (will be used for EPX request signing)
-	_, err = s.secretManager.GetSecret(ctx, agent.MacSecretPath)
+	_, err = s.getSecret(ctx, agent.MacSecretPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get MAC secret: %w", err)
 	}
 
 	// Determine auth_guid (payment token)
-	var authGUID string
+	var authGUID, cardFingerprint string
 	var paymentMethodUUID *uuid.UUID // Reuse parsed UUID
 	if req.PaymentMethodID != nil {
 		// Using saved payment method - parse UUID once
@@ -90,13 +199,79 @@ func (s *paymentService) Sale(ctx context.Context, req *ports.SaleRequest) (*dom
 			return nil, fmt.Errorf("failed to get payment method: %w", err)
 		}
 		authGUID = pm.PaymentToken
+		cardFingerprint = savedCardFingerprint(&pm)
 	} else if req.PaymentToken != nil {
 		// Using one-time token
 		authGUID = *req.PaymentToken
+		cardFingerprint = domain.CardFingerprint("", 0, 0, authGUID)
 	} else {
 		return nil, fmt.Errorf("either payment_method_id or payment_token is required")
 	}
 
+	// Card-testing guard: the card's and customer's recent attempts against the merchant's velocity limits
+	if err := checkVelocity(ctx, s.db.Queries(), config, req.AgentID, cardFingerprint, req.CustomerID, req.Amount, time.Now()); err != nil {
+		log.Warn("Velocity limit exceeded", zap.Error(err))
+		return nil, err
+	}
+
+	// Screen the charge before it reaches EPX; a decline stops it here
+	metadata, err := s.screenFraud(ctx, log, &adapterports.FraudContext{
+		AgentID:         req.AgentID,
+		TransactionType: string(domain.TransactionTypeCharge),
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		CustomerID:      stringOrEmpty(req.CustomerID),
+		CustomerEmail:   stringOrEmpty(req.CustomerEmail),
+		PaymentMethodID: stringOrEmpty(req.PaymentMethodID),
+		ThreeDSecure:    req.ThreeDS != nil,
+		Metadata:        req.Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		log.Warn("Failed to marshal metadata", zap.Error(err))
+		metadataJSON = []byte("{}")
+	}
+	params := sqlc.CreateTransactionParams{
+		GroupID:           uuid.New(),
+		AgentID:           req.AgentID,
+		CustomerID:        toNullableText(req.CustomerID),
+		Amount:            toNumeric(amount),
+		Currency:          req.Currency,
+		Type:              string(domain.TransactionTypeCharge),
+		PaymentMethodType: string(domain.PaymentMethodTypeCreditCard),
+		PaymentMethodID:   toNullableUUID(req.PaymentMethodID),
+		IdempotencyKey:    toNullableText(req.IdempotencyKey),
+		RequestHash:       requestHash(req.IdempotencyKey, fingerprint),
+		CardFingerprint:   pgtype.Text{String: cardFingerprint, Valid: cardFingerprint != ""},
+		Metadata:          metadataJSON,
+		ThreeDs:           threeDSJSON(req.ThreeDS),
+	}
+
+	// Hold the amount against the merchant's daily volume limit before charging; the pending row records the hold's day
+	reservation, err := s.reserveDailyVolume(ctx, req.AgentID, config, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	params.DailyVolumeDate = reservation.Day()
+
+	// Reserve the idempotency key and TRAN_NBR; a concurrent duplicate stops here, before EPX
+	slot, replay, err := s.reserveTransaction(ctx, s.db.Queries(), params, fingerprint, req.IncludeTree)
+	if err != nil || replay != nil {
+		if releaseErr := reservation.Release(ctx, s.db.Queries()); releaseErr != nil {
+			log.Warn("Failed to release daily volume", zap.Error(releaseErr))
+		}
+		return replay, err
+	}
+	reservation = takeOverDailyVolume(ctx, s.db.Queries(), log, slot, reservation)
+
 	// Call EPX Server Post API for sale
 	epxReq := &adapterports.ServerPostRequest{
 		CustNbr:         agent.CustNbr,
@@ -107,63 +282,39 @@ func (s *paymentService) Sale(ctx context.Context, req *ports.SaleRequest) (*dom
 		Amount:          req.Amount,
 		PaymentType:     adapterports.PaymentMethodTypeCreditCard,
 		AuthGUID:        authGUID,
-		TranNbr:         uuid.New().String(),
-		TranGroup:       uuid.New().String(),
+		TranNbr:         strconv.FormatInt(slot.tranNbr, 10),
+		TranGroup:       slot.groupID.String(),
 		CustomerID:      stringOrEmpty(req.CustomerID),
 	}
+	applyThreeDS(epxReq, req.ThreeDS)
+	epxReq.SoftDescriptor = descriptor
 
-	epxResp, err := s.serverPost.ProcessTransaction(ctx, epxReq)
+	gatewayStart := time.Now()
+	epxResp, err := s.processTransaction(ctx, epxReq)
+	gatewayLatency := time.Since(gatewayStart)
+	observability.ObserveEPXLatency(string(epxReq.TransactionType), agent.Tier, gatewayLatency)
 	if err != nil {
-		s.logger.Error("EPX transaction failed", zap.Error(err))
+		log.Error("EPX transaction failed", zap.Error(err))
+		s.releaseUnsentDailyVolume(ctx, log, slot, reservation, err)
 		return nil, fmt.Errorf("gateway error: %w", err)
 	}
 
 	// Save transaction to database using WithTx for transaction safety
 	var transaction *domain.Transaction
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
-		// Parse amount
-		amount, err := decimal.NewFromString(req.Amount)
-		if err != nil {
-			return fmt.Errorf("invalid amount: %w", err)
-		}
-
-		// Marshal metadata
-		metadataJSON, err := json.Marshal(req.Metadata)
-		if err != nil {
-			s.logger.Warn("Failed to marshal metadata", zap.Error(err))
-			metadataJSON = []byte("{}")
-		}
-
+	err = withTxSpan(ctx, s.db, func(q sqlc.Querier) error {
 		// Determine status
 		status := domain.TransactionStatusFailed
 		if epxResp.IsApproved {
 			status = domain.TransactionStatusCompleted
+		} else if err := reservation.Release(ctx, q); err != nil {
+			return fmt.Errorf("failed to release daily volume: %w", err)
 		}
 
-		// Create transaction using sqlc-generated function
-		params := sqlc.CreateTransactionParams{
-			ID:                uuid.New(),
-			GroupID:           uuid.MustParse(epxResp.TranGroup),
-			AgentID:           req.AgentID,
-			CustomerID:        toNullableText(req.CustomerID),
-			Amount:            toNumeric(amount),
-			Currency:          req.Currency,
-			Status:            string(status),
-			Type:              string(domain.TransactionTypeCharge),
-			PaymentMethodType: string(domain.PaymentMethodTypeCreditCard),
-			PaymentMethodID:   toNullableUUID(req.PaymentMethodID),
-			AuthGuid:          toNullableText(&epxResp.AuthGUID),
-			AuthResp:          toNullableText(&epxResp.AuthResp),
-			AuthCode:          toNullableText(&epxResp.AuthCode),
-			AuthRespText:      toNullableText(&epxResp.AuthRespText),
-			AuthCardType:      toNullableText(&epxResp.AuthCardType),
-			AuthAvs:           toNullableText(&epxResp.AuthAVS),
-			AuthCvv2:          toNullableText(&epxResp.AuthCVV2),
-			IdempotencyKey:    toNullableText(req.IdempotencyKey),
-			Metadata:          metadataJSON,
-		}
+		params.Status = string(status)
+		applyGatewayResponse(&params, epxResp)
+		params.VerificationOutcome = verificationOutcomeJSON(config, epxResp)
 
-		dbTx, err := q.CreateTransaction(ctx, params)
+		dbTx, err := slot.record(ctx, q, params)
 		if err != nil {
 			return fmt.Errorf("failed to create transaction: %w", err)
 		}
@@ -171,7 +322,7 @@ func (s *paymentService) Sale(ctx context.Context, req *ports.SaleRequest) (*dom
 		// Mark payment method as used if provided
 		if paymentMethodUUID != nil {
 			if err := q.MarkPaymentMethodUsed(ctx, *paymentMethodUUID); err != nil {
-				s.logger.Warn("Failed to mark payment method as used", zap.Error(err))
+				log.Warn("Failed to mark payment method as used", zap.Error(err))
 			}
 		}
 
@@ -183,32 +334,42 @@ func (s *paymentService) Sale(ctx context.Context, req *ports.SaleRequest) (*dom
 	if err != nil {
 		return nil, err
 	}
+	transaction.Gateway = gatewayResult(epxResp, gatewayLatency)
+	observability.RecordTransaction(string(transaction.Type), string(transaction.Status), agent.Tier)
 
-	s.logger.Info("Sale transaction completed",
+	log.Info("Sale transaction completed",
 		zap.String("transaction_id", transaction.ID),
 		zap.String("status", string(transaction.Status)),
 		zap.Bool("approved", transaction.IsApproved()),
 	)
 
+	s.publishTransactionEvent(transaction, "")
+	s.attachTree(ctx, transaction, req.IncludeTree, &agent)
+
 	return transaction, nil
 }
 
 // Authorize holds funds on a payment method without capturing
-func (s *paymentService) Authorize(ctx context.Context, req *ports.AuthorizeRequest) (*domain.Transaction, error) {
+func (s *paymentService) Authorize(ctx context.Context, req *ports.AuthorizeRequest) (result *domain.Transaction, err error) {
+	defer func() {
+		s.audit(ctx, s.auditEntry(domain.AuditActionAuthorize, req.Actor, req.AgentID, req, result, err))
+	}()
+
 	s.logger.Info("Processing authorization",
 		zap.String("agent_id", req.AgentID),
 		zap.String("amount", req.Amount),
 	)
 
-	// Check idempotency
-	if req.IdempotencyKey != nil {
-		existing, err := s.GetTransactionByIdempotencyKey(ctx, *req.IdempotencyKey)
-		if err == nil {
-			s.logger.Info("Idempotent request, returning existing transaction",
-				zap.String("transaction_id", existing.ID),
-			)
-			return existing, nil
-		}
+	// Check idempotency; a reused key must come with the same request
+	fingerprint := authorizeFingerprint(req)
+	if existing, err := s.replayIdempotent(ctx, req.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree); err != nil || existing != nil {
+		return existing, err
+	}
+
+	// Kill switch: operators can stop new charges, globally or per merchant, during an incident
+	if err := database.CheckChargesEnabled(ctx, s.db.Queries(), req.AgentID); err != nil {
+		s.logger.Warn("Charge refused: charges are disabled", zap.String("agent_id", req.AgentID), zap.Error(err))
+		return nil, err
 	}
 
 	// Get agent credentials
@@ -216,19 +377,49 @@ func (s *paymentService) Authorize(ctx context.Context, req *ports.AuthorizeRequ
 	if err != nil {
 		return nil, fmt.Errorf("failed to get agent: %w", err)
 	}
+	log := merchantLogger(s.logger, &agent)
+
+	// Suspended and closed merchants can't start new charges
+	if err := domain.MerchantStatus(agent.Status).CheckCharge(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEPXEnvironment(log, &agent); err != nil {
+		return nil, err
+	}
+	config, err := resolveMerchantConfig(log, &agent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Amount bounds: a $0.00 or runaway amount never reaches EPX
+	if err := checkAmountRange(config, req.Amount); err != nil {
+		log.Warn("Amount outside the merchant's range", zap.Error(err))
+		return nil, err
+	}
+
+	if req.ThreeDS != nil {
+		if err := req.ThreeDS.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	descriptor, err := statementDescriptor(req.StatementDescriptor, &agent)
+	if err != nil {
+		return nil, err
+	}
 
-	if !agent.IsActive.Valid || !agent.IsActive.Bool {
-		return nil, domain.ErrAgentInactive
+	if err := database.ResolveCustomer(ctx, s.db.Queries(), config, req.AgentID, req.CustomerID, req.CustomerEmail); err != nil {
+		return nil, err
 	}
 
 	// Get MAC secret
-	_, err = s.secretManager.GetSecret(ctx, agent.MacSecretPath)
+	_, err = s.getSecret(ctx, agent.MacSecretPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get MAC secret: %w", err)
 	}
 
 	// Determine auth_guid (payment token)
-	var authGUID string
+	var authGUID, cardFingerprint string
 	var paymentMethodUUID *uuid.UUID
 	if req.PaymentMethodID != nil {
 		pmID, err := uuid.Parse(*req.PaymentMethodID)
@@ -242,12 +433,78 @@ func (s *paymentService) Authorize(ctx context.Context, req *ports.AuthorizeRequ
 			return nil, fmt.Errorf("failed to get payment method: %w", err)
 		}
 		authGUID = pm.PaymentToken
+		cardFingerprint = savedCardFingerprint(&pm)
 	} else if req.PaymentToken != nil {
 		authGUID = *req.PaymentToken
+		cardFingerprint = domain.CardFingerprint("", 0, 0, authGUID)
 	} else {
 		return nil, fmt.Errorf("either payment_method_id or payment_token is required")
 	}
 
+	// Card-testing guard: the card's and customer's recent attempts against the merchant's velocity limits
+	if err := checkVelocity(ctx, s.db.Queries(), config, req.AgentID, cardFingerprint, req.CustomerID, req.Amount, time.Now()); err != nil {
+		log.Warn("Velocity limit exceeded", zap.Error(err))
+		return nil, err
+	}
+
+	// Screen the authorization before it reaches EPX; a decline stops it here
+	metadata, err := s.screenFraud(ctx, log, &adapterports.FraudContext{
+		AgentID:         req.AgentID,
+		TransactionType: string(domain.TransactionTypeAuth),
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		CustomerID:      stringOrEmpty(req.CustomerID),
+		CustomerEmail:   stringOrEmpty(req.CustomerEmail),
+		PaymentMethodID: stringOrEmpty(req.PaymentMethodID),
+		ThreeDSecure:    req.ThreeDS != nil,
+		Metadata:        req.Metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		log.Warn("Failed to marshal metadata", zap.Error(err))
+		metadataJSON = []byte("{}")
+	}
+	params := sqlc.CreateTransactionParams{
+		GroupID:           uuid.New(),
+		AgentID:           req.AgentID,
+		CustomerID:        toNullableText(req.CustomerID),
+		Amount:            toNumeric(amount),
+		Currency:          "USD",
+		Type:              string(domain.TransactionTypeAuth),
+		PaymentMethodType: string(domain.PaymentMethodTypeCreditCard),
+		PaymentMethodID:   toNullableUUID(req.PaymentMethodID),
+		IdempotencyKey:    toNullableText(req.IdempotencyKey),
+		RequestHash:       requestHash(req.IdempotencyKey, fingerprint),
+		CardFingerprint:   pgtype.Text{String: cardFingerprint, Valid: cardFingerprint != ""},
+		Metadata:          metadataJSON,
+		ThreeDs:           threeDSJSON(req.ThreeDS),
+	}
+
+	// Hold the amount against the merchant's daily volume limit before authorizing; the pending row records the hold's day
+	reservation, err := s.reserveDailyVolume(ctx, req.AgentID, config, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	params.DailyVolumeDate = reservation.Day()
+
+	// Reserve the idempotency key and TRAN_NBR; a concurrent duplicate stops here, before EPX
+	slot, replay, err := s.reserveTransaction(ctx, s.db.Queries(), params, fingerprint, req.IncludeTree)
+	if err != nil || replay != nil {
+		if releaseErr := reservation.Release(ctx, s.db.Queries()); releaseErr != nil {
+			log.Warn("Failed to release daily volume", zap.Error(releaseErr))
+		}
+		return replay, err
+	}
+	reservation = takeOverDailyVolume(ctx, s.db.Queries(), log, slot, reservation)
+
 	// Call EPX Server Post API for authorization only
 	epxReq := &adapterports.ServerPostRequest{
 		CustNbr:         agent.CustNbr,
@@ -258,66 +515,45 @@ func (s *paymentService) Authorize(ctx context.Context, req *ports.AuthorizeRequ
 		Amount:          req.Amount,
 		PaymentType:     adapterports.PaymentMethodTypeCreditCard,
 		AuthGUID:        authGUID,
-		TranNbr:         uuid.New().String(),
-		TranGroup:       uuid.New().String(),
+		TranNbr:         strconv.FormatInt(slot.tranNbr, 10),
+		TranGroup:       slot.groupID.String(),
 		CustomerID:      stringOrEmpty(req.CustomerID),
 	}
+	applyThreeDS(epxReq, req.ThreeDS)
+	epxReq.SoftDescriptor = descriptor
 
-	epxResp, err := s.serverPost.ProcessTransaction(ctx, epxReq)
+	gatewayStart := time.Now()
+	epxResp, err := s.processTransaction(ctx, epxReq)
+	gatewayLatency := time.Since(gatewayStart)
+	observability.ObserveEPXLatency(string(epxReq.TransactionType), agent.Tier, gatewayLatency)
 	if err != nil {
-		s.logger.Error("EPX authorization failed", zap.Error(err))
+		log.Error("EPX authorization failed", zap.Error(err))
+		s.releaseUnsentDailyVolume(ctx, log, slot, reservation, err)
 		return nil, fmt.Errorf("gateway error: %w", err)
 	}
 
 	// Save transaction to database
 	var transaction *domain.Transaction
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
-		amount, err := decimal.NewFromString(req.Amount)
-		if err != nil {
-			return fmt.Errorf("invalid amount: %w", err)
-		}
-
-		metadataJSON, err := json.Marshal(req.Metadata)
-		if err != nil {
-			s.logger.Warn("Failed to marshal metadata", zap.Error(err))
-			metadataJSON = []byte("{}")
-		}
-
+	err = withTxSpan(ctx, s.db, func(q sqlc.Querier) error {
 		status := domain.TransactionStatusFailed
 		if epxResp.IsApproved {
 			status = domain.TransactionStatusCompleted
+		} else if err := reservation.Release(ctx, q); err != nil {
+			return fmt.Errorf("failed to release daily volume: %w", err)
 		}
 
-		params := sqlc.CreateTransactionParams{
-			ID:                uuid.New(),
-			GroupID:           uuid.MustParse(epxResp.TranGroup),
-			AgentID:           req.AgentID,
-			CustomerID:        toNullableText(req.CustomerID),
-			Amount:            toNumeric(amount),
-			Currency:          "USD",
-			Status:            string(status),
-			Type:              string(domain.TransactionTypeAuth),
-			PaymentMethodType: string(domain.PaymentMethodTypeCreditCard),
-			PaymentMethodID:   toNullableUUID(req.PaymentMethodID),
-			AuthGuid:          toNullableText(&epxResp.AuthGUID),
-			AuthResp:          toNullableText(&epxResp.AuthResp),
-			AuthCode:          toNullableText(&epxResp.AuthCode),
-			AuthRespText:      toNullableText(&epxResp.AuthRespText),
-			AuthCardType:      toNullableText(&epxResp.AuthCardType),
-			AuthAvs:           toNullableText(&epxResp.AuthAVS),
-			AuthCvv2:          toNullableText(&epxResp.AuthCVV2),
-			IdempotencyKey:    toNullableText(req.IdempotencyKey),
-			Metadata:          metadataJSON,
-		}
+		params.Status = string(status)
+		applyGatewayResponse(&params, epxResp)
+		params.VerificationOutcome = verificationOutcomeJSON(config, epxResp)
 
-		dbTx, err := q.CreateTransaction(ctx, params)
+		dbTx, err := slot.record(ctx, q, params)
 		if err != nil {
 			return fmt.Errorf("failed to create transaction: %w", err)
 		}
 
 		if paymentMethodUUID != nil {
 			if err := q.MarkPaymentMethodUsed(ctx, *paymentMethodUUID); err != nil {
-				s.logger.Warn("Failed to mark payment method as used", zap.Error(err))
+				log.Warn("Failed to mark payment method as used", zap.Error(err))
 			}
 		}
 
@@ -328,39 +564,41 @@ func (s *paymentService) Authorize(ctx context.Context, req *ports.AuthorizeRequ
 	if err != nil {
 		return nil, err
 	}
+	transaction.Gateway = gatewayResult(epxResp, gatewayLatency)
+	observability.RecordTransaction(string(transaction.Type), string(transaction.Status), agent.Tier)
 
-	s.logger.Info("Authorization completed",
+	log.Info("Authorization completed",
 		zap.String("transaction_id", transaction.ID),
 		zap.String("status", string(transaction.Status)),
 		zap.Bool("approved", transaction.IsApproved()),
 	)
 
+	s.publishTransactionEvent(transaction, "")
+	s.attachTree(ctx, transaction, req.IncludeTree, &agent)
+
 	return transaction, nil
 }
 
 // Capture completes a previously authorized payment
-func (s *paymentService) Capture(ctx context.Context, req *ports.CaptureRequest) (*domain.Transaction, error) {
+func (s *paymentService) Capture(ctx context.Context, req *ports.CaptureRequest) (result *domain.Transaction, err error) {
+	defer func() { s.audit(ctx, s.auditEntry(domain.AuditActionCapture, req.Actor, "", req, result, err)) }()
+
 	s.logger.Info("Processing capture",
 		zap.String("transaction_id", req.TransactionID),
 	)
 
-	// Check idempotency
-	if req.IdempotencyKey != nil {
-		existing, err := s.GetTransactionByIdempotencyKey(ctx, *req.IdempotencyKey)
-		if err == nil {
-			s.logger.Info("Idempotent request, returning existing transaction",
-				zap.String("transaction_id", existing.ID),
-			)
-			return existing, nil
-		}
-	}
-
 	// Get original authorization transaction
 	originalTx, err := s.GetTransaction(ctx, req.TransactionID)
 	if err != nil {
 		return nil, err
 	}
 
+	// Check idempotency within the original's merchant; a reused key must come with the same request
+	fingerprint := captureFingerprint(req)
+	if existing, err := s.replayIdempotent(ctx, originalTx.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree); err != nil || existing != nil {
+		return existing, err
+	}
+
 	if !originalTx.CanBeCaptured() {
 		return nil, domain.ErrTransactionCannotBeCaptured
 	}
@@ -370,82 +608,109 @@ func (s *paymentService) Capture(ctx context.Context, req *ports.CaptureRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get agent: %w", err)
 	}
+	log := merchantLogger(s.logger, &agent)
 
-	if !agent.IsActive.Valid || !agent.IsActive.Bool {
-		return nil, domain.ErrAgentInactive
+	// Suspended and closed merchants can't start new charges
+	if err := domain.MerchantStatus(agent.Status).CheckCharge(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEPXEnvironment(log, &agent); err != nil {
+		return nil, err
 	}
 
 	// Get MAC secret
-	_, err = s.secretManager.GetSecret(ctx, agent.MacSecretPath)
+	_, err = s.getSecret(ctx, agent.MacSecretPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get MAC secret: %w", err)
 	}
 
-	// Determine capture amount (partial or full)
-	captureAmount := originalTx.Amount
-	if req.Amount != nil {
-		amt, err := decimal.NewFromString(*req.Amount)
-		if err != nil {
-			return nil, fmt.Errorf("invalid amount format: %w", err)
-		}
-		if amt.GreaterThan(originalTx.Amount) {
-			return nil, fmt.Errorf("capture amount cannot exceed authorized amount")
+	// The group's follow-ups run one at a time: the hold is read, captured at EPX and recorded under the group's
+	// lock, so concurrent captures and reversals can't together take more than the active authorization
+	var (
+		transaction    *domain.Transaction
+		replay         *domain.Transaction
+		epxResp        *adapterports.ServerPostResponse
+		gatewayLatency time.Duration
+		gatewayErr     error
+	)
+	groupID := uuid.MustParse(originalTx.GroupID)
+	err = withTxSpan(ctx, s.db, func(q sqlc.Querier) error {
+		if err := q.LockTransactionGroup(ctx, groupID); err != nil {
+			return fmt.Errorf("failed to lock transaction group: %w", err)
 		}
-		captureAmount = amt
-	}
-
-	// Call EPX Server Post API for capture
-	epxReq := &adapterports.ServerPostRequest{
-		CustNbr:         agent.CustNbr,
-		MerchNbr:        agent.MerchNbr,
-		DBAnbr:          agent.DbaNbr,
-		TerminalNbr:     agent.TerminalNbr,
-		TransactionType: adapterports.TransactionTypeCapture,
-		Amount:          captureAmount.String(),
-		PaymentType:     adapterports.PaymentMethodTypeCreditCard,
-		AuthGUID:        *originalTx.AuthGUID, // Use original AUTH_GUID
-		TranNbr:         uuid.New().String(),
-		TranGroup:       originalTx.GroupID, // Same group as original
-		CustomerID:      stringOrEmpty(originalTx.CustomerID),
-	}
 
-	epxResp, err := s.serverPost.ProcessTransaction(ctx, epxReq)
-	if err != nil {
-		s.logger.Error("EPX capture failed", zap.Error(err))
-		return nil, fmt.Errorf("gateway error: %w", err)
-	}
+		// A retry that waited on the lock replays whatever its twin recorded
+		var err error
+		replay, err = s.replayIdempotent(ctx, originalTx.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree)
+		if err != nil || replay != nil {
+			return err
+		}
 
-	// Save transaction to database
-	var transaction *domain.Transaction
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
-		status := domain.TransactionStatusFailed
-		if epxResp.IsApproved {
-			status = domain.TransactionStatusCompleted
+		// Determine capture amount (partial or full), bounded by what's left of the active authorization
+		state, err := groupState(ctx, q, originalTx.GroupID)
+		if err != nil {
+			return err
+		}
+		captureAmount, err := resolveCaptureAmount(state, req.Amount)
+		if err != nil {
+			return err
 		}
 
 		params := sqlc.CreateTransactionParams{
-			ID:                uuid.New(),
-			GroupID:           uuid.MustParse(originalTx.GroupID),
+			GroupID:           groupID,
 			AgentID:           originalTx.AgentID,
 			CustomerID:        toNullableText(originalTx.CustomerID),
 			Amount:            toNumeric(captureAmount),
 			Currency:          originalTx.Currency,
-			Status:            string(status),
 			Type:              string(domain.TransactionTypeCapture),
 			PaymentMethodType: string(originalTx.PaymentMethodType),
 			PaymentMethodID:   toNullableUUID(originalTx.PaymentMethodID),
-			AuthGuid:          toNullableText(&epxResp.AuthGUID),
-			AuthResp:          toNullableText(&epxResp.AuthResp),
-			AuthCode:          toNullableText(&epxResp.AuthCode),
-			AuthRespText:      toNullableText(&epxResp.AuthRespText),
-			AuthCardType:      toNullableText(&epxResp.AuthCardType),
-			AuthAvs:           toNullableText(&epxResp.AuthAVS),
-			AuthCvv2:          toNullableText(&epxResp.AuthCVV2),
 			IdempotencyKey:    toNullableText(req.IdempotencyKey),
+			RequestHash:       requestHash(req.IdempotencyKey, fingerprint),
 			Metadata:          []byte(fmt.Sprintf(`{"original_transaction_id":"%s"}`, originalTx.ID)),
 		}
 
-		dbTx, err := q.CreateTransaction(ctx, params)
+		// Reserve the idempotency key and TRAN_NBR; a concurrent duplicate stops here, before EPX
+		var slot *transactionSlot
+		slot, replay, err = s.reserveTransaction(ctx, q, params, fingerprint, req.IncludeTree)
+		if err != nil || replay != nil {
+			return err
+		}
+
+		// Call EPX Server Post API for capture
+		epxReq := &adapterports.ServerPostRequest{
+			CustNbr:          agent.CustNbr,
+			MerchNbr:         agent.MerchNbr,
+			DBAnbr:           agent.DbaNbr,
+			TerminalNbr:      agent.TerminalNbr,
+			TransactionType:  adapterports.TransactionTypeCapture,
+			Amount:           captureAmount.String(),
+			PaymentType:      adapterports.PaymentMethodTypeCreditCard,
+			AuthGUID:         *originalTx.AuthGUID, // Use original AUTH_GUID
+			OriginalAuthGUID: *originalTx.AuthGUID, // Sent as ORIG_AUTH_GUID, which EPX requires to reference it
+			TranNbr:          strconv.FormatInt(slot.tranNbr, 10),
+			TranGroup:        originalTx.GroupID, // Same group as original
+			CustomerID:       stringOrEmpty(originalTx.CustomerID),
+		}
+
+		gatewayStart := time.Now()
+		epxResp, gatewayErr = s.processTransaction(ctx, epxReq)
+		gatewayLatency = time.Since(gatewayStart)
+		observability.ObserveEPXLatency(string(epxReq.TransactionType), agent.Tier, gatewayLatency)
+		if gatewayErr != nil {
+			// Commit the reservation anyway so a retry reuses its TRAN_NBR
+			return nil
+		}
+
+		status := domain.TransactionStatusFailed
+		if epxResp.IsApproved {
+			status = domain.TransactionStatusCompleted
+		}
+
+		params.Status = string(status)
+		applyGatewayResponse(&params, epxResp)
+
+		dbTx, err := slot.record(ctx, q, params)
 		if err != nil {
 			return fmt.Errorf("failed to create transaction: %w", err)
 		}
@@ -457,41 +722,79 @@ func (s *paymentService) Capture(ctx context.Context, req *ports.CaptureRequest)
 	if err != nil {
 		return nil, err
 	}
+	if replay != nil {
+		return replay, nil
+	}
+	if gatewayErr != nil {
+		log.Error("EPX capture failed", zap.Error(gatewayErr))
+		return nil, fmt.Errorf("gateway error: %w", gatewayErr)
+	}
+	transaction.Gateway = gatewayResult(epxResp, gatewayLatency)
+	observability.RecordTransaction(string(transaction.Type), string(transaction.Status), agent.Tier)
 
-	s.logger.Info("Capture completed",
+	log.Info("Capture completed",
 		zap.String("transaction_id", transaction.ID),
 		zap.String("original_transaction_id", originalTx.ID),
 		zap.String("status", string(transaction.Status)),
 	)
 
+	s.publishTransactionEvent(transaction, originalTx.ID)
+	s.attachTree(ctx, transaction, req.IncludeTree, &agent)
+
 	return transaction, nil
 }
 
-// Void cancels an authorized or captured payment
-func (s *paymentService) Void(ctx context.Context, req *ports.VoidRequest) (*domain.Transaction, error) {
-	s.logger.Info("Processing void",
-		zap.String("transaction_id", req.TransactionID),
-	)
-
-	// Check idempotency
-	if req.IdempotencyKey != nil {
-		existing, err := s.GetTransactionByIdempotencyKey(ctx, *req.IdempotencyKey)
-		if err == nil {
-			s.logger.Info("Idempotent request, returning existing transaction",
-				zap.String("transaction_id", existing.ID),
-			)
-			return existing, nil
+// resolveCaptureAmount parses the requested capture amount (the whole remaining authorization when nil)
+// and checks it against the group's active authorization
+func resolveCaptureAmount(state domain.TransactionGroupState, requested *string) (decimal.Decimal, error) {
+	if requested == nil {
+		if !state.CapturableAmount.IsPositive() {
+			return decimal.Zero, domain.ErrTransactionCannotBeCaptured
 		}
+		return state.CapturableAmount, nil
 	}
 
-	// Get original transaction
+	amount, err := decimal.NewFromString(*requested)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid amount format: %w", err)
+	}
+	if err := state.CheckCapture(amount); err != nil {
+		return decimal.Zero, err
+	}
+	return amount, nil
+}
+
+// PartialReverseAuthorization releases part of an authorization hold with an EPX reversal.
+// The approved reversal is recorded in the auth's group, reducing its active authorization amount.
+func (s *paymentService) PartialReverseAuthorization(ctx context.Context, req *ports.PartialReversalRequest) (result *domain.Transaction, err error) {
+	defer func() {
+		s.audit(ctx, s.auditEntry(domain.AuditActionPartialReversal, req.Actor, "", req, result, err))
+	}()
+
+	s.logger.Info("Processing partial authorization reversal",
+		zap.String("transaction_id", req.TransactionID),
+		zap.String("amount", req.Amount),
+	)
+
+	// Get original authorization transaction
 	originalTx, err := s.GetTransaction(ctx, req.TransactionID)
 	if err != nil {
 		return nil, err
 	}
 
-	if !originalTx.CanBeVoided() {
-		return nil, domain.ErrTransactionCannotBeVoided
+	// Check idempotency within the original's merchant; a reused key must come with the same request
+	fingerprint := partialReversalFingerprint(req)
+	if existing, err := s.replayIdempotent(ctx, originalTx.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree); err != nil || existing != nil {
+		return existing, err
+	}
+
+	if originalTx.Type != domain.TransactionTypeAuth || originalTx.Status != domain.TransactionStatusCompleted {
+		return nil, domain.ErrTransactionCannotBeReversed
+	}
+
+	reversalAmount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount format: %w", err)
 	}
 
 	// Get agent credentials
@@ -499,69 +802,107 @@ func (s *paymentService) Void(ctx context.Context, req *ports.VoidRequest) (*dom
 	if err != nil {
 		return nil, fmt.Errorf("failed to get agent: %w", err)
 	}
+	log := merchantLogger(s.logger, &agent)
 
-	if !agent.IsActive.Valid || !agent.IsActive.Bool {
-		return nil, domain.ErrAgentInactive
+	// Suspended merchants can still give money back; closed merchants can't
+	if err := domain.MerchantStatus(agent.Status).CheckRefund(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEPXEnvironment(log, &agent); err != nil {
+		return nil, err
 	}
 
 	// Get MAC secret
-	_, err = s.secretManager.GetSecret(ctx, agent.MacSecretPath)
+	_, err = s.getSecret(ctx, agent.MacSecretPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get MAC secret: %w", err)
 	}
 
-	// Call EPX Server Post API for void
-	epxReq := &adapterports.ServerPostRequest{
-		CustNbr:         agent.CustNbr,
-		MerchNbr:        agent.MerchNbr,
-		DBAnbr:          agent.DbaNbr,
-		TerminalNbr:     agent.TerminalNbr,
-		TransactionType: adapterports.TransactionTypeVoid,
-		Amount:          originalTx.Amount.String(),
-		PaymentType:     adapterports.PaymentMethodType(originalTx.PaymentMethodType),
-		AuthGUID:        *originalTx.AuthGUID, // Use original AUTH_GUID
-		TranNbr:         uuid.New().String(),
-		TranGroup:       originalTx.GroupID, // Same group as original
-		CustomerID:      stringOrEmpty(originalTx.CustomerID),
-	}
+	// The group's follow-ups run one at a time: the hold is read, reversed at EPX and recorded under the group's
+	// lock, so concurrent captures and reversals can't together take more than the active authorization
+	var (
+		transaction    *domain.Transaction
+		replay         *domain.Transaction
+		epxResp        *adapterports.ServerPostResponse
+		gatewayLatency time.Duration
+		gatewayErr     error
+	)
+	groupID := uuid.MustParse(originalTx.GroupID)
+	err = withTxSpan(ctx, s.db, func(q sqlc.Querier) error {
+		if err := q.LockTransactionGroup(ctx, groupID); err != nil {
+			return fmt.Errorf("failed to lock transaction group: %w", err)
+		}
 
-	epxResp, err := s.serverPost.ProcessTransaction(ctx, epxReq)
-	if err != nil {
-		s.logger.Error("EPX void failed", zap.Error(err))
-		return nil, fmt.Errorf("gateway error: %w", err)
-	}
+		// A retry that waited on the lock replays whatever its twin recorded
+		var err error
+		replay, err = s.replayIdempotent(ctx, originalTx.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree)
+		if err != nil || replay != nil {
+			return err
+		}
 
-	// Save transaction to database
-	var transaction *domain.Transaction
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
-		status := domain.TransactionStatusFailed
-		if epxResp.IsApproved {
-			status = domain.TransactionStatusVoided
+		state, err := groupState(ctx, q, originalTx.GroupID)
+		if err != nil {
+			return err
+		}
+		if err := state.CheckPartialReversal(reversalAmount); err != nil {
+			return err
 		}
 
 		params := sqlc.CreateTransactionParams{
-			ID:                uuid.New(),
-			GroupID:           uuid.MustParse(originalTx.GroupID),
+			GroupID:           groupID,
 			AgentID:           originalTx.AgentID,
 			CustomerID:        toNullableText(originalTx.CustomerID),
-			Amount:            toNumeric(originalTx.Amount),
+			Amount:            toNumeric(reversalAmount),
 			Currency:          originalTx.Currency,
-			Status:            string(status),
-			Type:              string(domain.TransactionTypeCharge), // Void is still a charge type
+			Type:              string(domain.TransactionTypeReversal),
 			PaymentMethodType: string(originalTx.PaymentMethodType),
 			PaymentMethodID:   toNullableUUID(originalTx.PaymentMethodID),
-			AuthGuid:          toNullableText(&epxResp.AuthGUID),
-			AuthResp:          toNullableText(&epxResp.AuthResp),
-			AuthCode:          toNullableText(&epxResp.AuthCode),
-			AuthRespText:      toNullableText(&epxResp.AuthRespText),
-			AuthCardType:      toNullableText(&epxResp.AuthCardType),
-			AuthAvs:           toNullableText(&epxResp.AuthAVS),
-			AuthCvv2:          toNullableText(&epxResp.AuthCVV2),
 			IdempotencyKey:    toNullableText(req.IdempotencyKey),
+			RequestHash:       requestHash(req.IdempotencyKey, fingerprint),
 			Metadata:          []byte(fmt.Sprintf(`{"original_transaction_id":"%s"}`, originalTx.ID)),
 		}
 
-		dbTx, err := q.CreateTransaction(ctx, params)
+		// Reserve the idempotency key and TRAN_NBR; a concurrent duplicate stops here, before EPX
+		var slot *transactionSlot
+		slot, replay, err = s.reserveTransaction(ctx, q, params, fingerprint, req.IncludeTree)
+		if err != nil || replay != nil {
+			return err
+		}
+
+		// Call EPX Server Post API for the reversal of the released amount
+		epxReq := &adapterports.ServerPostRequest{
+			CustNbr:          agent.CustNbr,
+			MerchNbr:         agent.MerchNbr,
+			DBAnbr:           agent.DbaNbr,
+			TerminalNbr:      agent.TerminalNbr,
+			TransactionType:  adapterports.TransactionTypeReversal,
+			Amount:           reversalAmount.String(),
+			PaymentType:      adapterports.PaymentMethodTypeCreditCard,
+			AuthGUID:         *originalTx.AuthGUID, // Use original AUTH_GUID
+			OriginalAuthGUID: *originalTx.AuthGUID, // Sent as ORIG_AUTH_GUID, which EPX requires to reference it
+			TranNbr:          strconv.FormatInt(slot.tranNbr, 10),
+			TranGroup:        originalTx.GroupID, // Same group as original
+			CustomerID:       stringOrEmpty(originalTx.CustomerID),
+		}
+
+		gatewayStart := time.Now()
+		epxResp, gatewayErr = s.processTransaction(ctx, epxReq)
+		gatewayLatency = time.Since(gatewayStart)
+		observability.ObserveEPXLatency(string(epxReq.TransactionType), agent.Tier, gatewayLatency)
+		if gatewayErr != nil {
+			// Commit the reservation anyway so a retry reuses its TRAN_NBR
+			return nil
+		}
+
+		status := domain.TransactionStatusFailed
+		if epxResp.IsApproved {
+			status = domain.TransactionStatusCompleted
+		}
+
+		params.Status = string(status)
+		applyGatewayResponse(&params, epxResp)
+
+		dbTx, err := slot.record(ctx, q, params)
 		if err != nil {
 			return fmt.Errorf("failed to create transaction: %w", err)
 		}
@@ -573,42 +914,65 @@ func (s *paymentService) Void(ctx context.Context, req *ports.VoidRequest) (*dom
 	if err != nil {
 		return nil, err
 	}
+	if replay != nil {
+		return replay, nil
+	}
+	if gatewayErr != nil {
+		log.Error("EPX reversal failed", zap.Error(gatewayErr))
+		return nil, fmt.Errorf("gateway error: %w", gatewayErr)
+	}
+	transaction.Gateway = gatewayResult(epxResp, gatewayLatency)
+	observability.RecordTransaction(string(transaction.Type), string(transaction.Status), agent.Tier)
 
-	s.logger.Info("Void completed",
+	log.Info("Partial authorization reversal completed",
 		zap.String("transaction_id", transaction.ID),
 		zap.String("original_transaction_id", originalTx.ID),
 		zap.String("status", string(transaction.Status)),
 	)
 
+	s.publishTransactionEvent(transaction, originalTx.ID)
+	s.attachTree(ctx, transaction, req.IncludeTree, &agent)
+
 	return transaction, nil
 }
 
-// Refund returns funds to the customer
-func (s *paymentService) Refund(ctx context.Context, req *ports.RefundRequest) (*domain.Transaction, error) {
-	s.logger.Info("Processing refund",
+// IncrementAuthorization raises an open authorization hold with an EPX incremental authorization.
+// The approved increment is recorded in the auth's group, adding to its active authorization amount
+// and restarting the hold's lifetime.
+func (s *paymentService) IncrementAuthorization(ctx context.Context, req *ports.IncrementAuthorizationRequest) (result *domain.Transaction, err error) {
+	defer func() {
+		s.audit(ctx, s.auditEntry(domain.AuditActionIncrementAuthorization, req.Actor, "", req, result, err))
+	}()
+
+	s.logger.Info("Processing incremental authorization",
 		zap.String("transaction_id", req.TransactionID),
-		zap.String("reason", req.Reason),
+		zap.String("amount", req.Amount),
 	)
 
-	// Check idempotency
-	if req.IdempotencyKey != nil {
-		existing, err := s.GetTransactionByIdempotencyKey(ctx, *req.IdempotencyKey)
-		if err == nil {
-			s.logger.Info("Idempotent request, returning existing transaction",
-				zap.String("transaction_id", existing.ID),
-			)
-			return existing, nil
-		}
-	}
-
-	// Get original transaction
+	// Get original authorization transaction
 	originalTx, err := s.GetTransaction(ctx, req.TransactionID)
 	if err != nil {
 		return nil, err
 	}
 
-	if !originalTx.CanBeRefunded() {
-		return nil, domain.ErrTransactionCannotBeRefunded
+	// Check idempotency within the original's merchant; a reused key must come with the same request
+	fingerprint := incrementFingerprint(req)
+	if existing, err := s.replayIdempotent(ctx, originalTx.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree); err != nil || existing != nil {
+		return existing, err
+	}
+
+	if originalTx.Type != domain.TransactionTypeAuth || originalTx.Status != domain.TransactionStatusCompleted {
+		return nil, domain.ErrTransactionCannotBeIncremented
+	}
+
+	incrementAmount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount format: %w", err)
+	}
+
+	// An increment puts more money on hold, so it's gated like a new charge
+	if err := database.CheckChargesEnabled(ctx, s.db.Queries(), originalTx.AgentID); err != nil {
+		return nil, err
 	}
 
 	// Get agent credentials
@@ -616,187 +980,1468 @@ func (s *paymentService) Refund(ctx context.Context, req *ports.RefundRequest) (
 	if err != nil {
 		return nil, fmt.Errorf("failed to get agent: %w", err)
 	}
+	log := merchantLogger(s.logger, &agent)
+
+	if err := domain.MerchantStatus(agent.Status).CheckCharge(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEPXEnvironment(log, &agent); err != nil {
+		return nil, err
+	}
+	config, err := resolveMerchantConfig(log, &agent)
+	if err != nil {
+		return nil, err
+	}
 
-	if !agent.IsActive.Valid || !agent.IsActive.Bool {
-		return nil, domain.ErrAgentInactive
+	// EPX's Server Post reference doesn't cover incremental authorization (CCE6), so merchants only get it
+	// once EPX has enabled it on their terminal and "increment" is added to their allowed transaction types
+	if !config.AllowsTransactionType(domain.TransactionTypeIncrement) {
+		return nil, fmt.Errorf("%w: incremental authorization is not enabled for this merchant", domain.ErrTransactionCannotBeIncremented)
 	}
 
 	// Get MAC secret
-	_, err = s.secretManager.GetSecret(ctx, agent.MacSecretPath)
+	_, err = s.getSecret(ctx, agent.MacSecretPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get MAC secret: %w", err)
 	}
 
-	// Determine refund amount (partial or full)
-	refundAmount := originalTx.Amount
-	if req.Amount != nil {
-		amt, err := decimal.NewFromString(*req.Amount)
+	// Card-testing guard: the increment counts against the auth's card and customer like a new authorization
+	if err := checkVelocity(ctx, s.db.Queries(), config, originalTx.AgentID, originalTx.CardFingerprint, originalTx.CustomerID, req.Amount, time.Now()); err != nil {
+		log.Warn("Velocity limit exceeded", zap.Error(err))
+		return nil, err
+	}
+
+	// Screen the increment before it reaches EPX; a decline stops it here
+	metadata, err := s.screenFraud(ctx, log, &adapterports.FraudContext{
+		AgentID:         originalTx.AgentID,
+		TransactionType: string(domain.TransactionTypeIncrement),
+		Amount:          req.Amount,
+		Currency:        originalTx.Currency,
+		CustomerID:      stringOrEmpty(originalTx.CustomerID),
+		PaymentMethodID: stringOrEmpty(originalTx.PaymentMethodID),
+		Metadata:        map[string]interface{}{"original_transaction_id": originalTx.ID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		log.Warn("Failed to marshal metadata", zap.Error(err))
+		metadataJSON = []byte(fmt.Sprintf(`{"original_transaction_id":"%s"}`, originalTx.ID))
+	}
+
+	// Hold the added amount against the merchant's daily volume limit before authorizing it
+	reservation, err := s.reserveDailyVolume(ctx, originalTx.AgentID, config, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// The group's follow-ups run one at a time: the hold is checked, incremented at EPX and recorded under the
+	// group's lock, so a concurrent capture, reversal or void can't act on the hold before this one lands
+	var (
+		transaction    *domain.Transaction
+		replay         *domain.Transaction
+		epxResp        *adapterports.ServerPostResponse
+		gatewayLatency time.Duration
+		gatewayErr     error
+	)
+	groupID := uuid.MustParse(originalTx.GroupID)
+	err = withTxSpan(ctx, s.db, func(q sqlc.Querier) error {
+		if err := q.LockTransactionGroup(ctx, groupID); err != nil {
+			return fmt.Errorf("failed to lock transaction group: %w", err)
+		}
+
+		// A retry that waited on the lock replays whatever its twin recorded
+		var err error
+		replay, err = s.replayIdempotent(ctx, originalTx.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree)
+		if err != nil || replay != nil {
+			return err
+		}
+
+		state, err := groupState(ctx, q, originalTx.GroupID)
+		if err != nil {
+			return err
+		}
+		if err := state.CheckIncrement(incrementAmount, time.Now()); err != nil {
+			return err
+		}
+
+		// The increased hold is bounded like a new authorization of the same total
+		if err := config.CheckAmount(state.ActiveAuthAmount.Add(incrementAmount)); err != nil {
+			log.Warn("Incremented authorization outside the merchant's range", zap.Error(err))
+			return err
+		}
+
+		params := sqlc.CreateTransactionParams{
+			GroupID:           groupID,
+			AgentID:           originalTx.AgentID,
+			CustomerID:        toNullableText(originalTx.CustomerID),
+			Amount:            toNumeric(incrementAmount),
+			Currency:          originalTx.Currency,
+			Type:              string(domain.TransactionTypeIncrement),
+			PaymentMethodType: string(originalTx.PaymentMethodType),
+			PaymentMethodID:   toNullableUUID(originalTx.PaymentMethodID),
+			IdempotencyKey:    toNullableText(req.IdempotencyKey),
+			RequestHash:       requestHash(req.IdempotencyKey, fingerprint),
+			CardFingerprint:   pgtype.Text{String: originalTx.CardFingerprint, Valid: originalTx.CardFingerprint != ""},
+			Metadata:          metadataJSON,
+			DailyVolumeDate:   reservation.Day(),
+		}
+
+		// Reserve the idempotency key and TRAN_NBR; a concurrent duplicate stops here, before EPX
+		var slot *transactionSlot
+		slot, replay, err = s.reserveTransaction(ctx, q, params, fingerprint, req.IncludeTree)
+		if err != nil || replay != nil {
+			return err
+		}
+		// Rolled back with the rest of the transaction on failure, so reservation stays the hold to release then
+		held := takeOverDailyVolume(ctx, q, log, slot, reservation)
+
+		// Call EPX Server Post API for the incremental authorization of the added amount
+		epxReq := &adapterports.ServerPostRequest{
+			CustNbr:          agent.CustNbr,
+			MerchNbr:         agent.MerchNbr,
+			DBAnbr:           agent.DbaNbr,
+			TerminalNbr:      agent.TerminalNbr,
+			TransactionType:  adapterports.TransactionTypeIncrementalAuth,
+			Amount:           incrementAmount.String(),
+			PaymentType:      adapterports.PaymentMethodTypeCreditCard,
+			AuthGUID:         *originalTx.AuthGUID, // Use original AUTH_GUID
+			OriginalAuthGUID: *originalTx.AuthGUID, // Sent as ORIG_AUTH_GUID, which EPX requires to reference it
+			TranNbr:          strconv.FormatInt(slot.tranNbr, 10),
+			TranGroup:        originalTx.GroupID, // Same group as original
+			CustomerID:       stringOrEmpty(originalTx.CustomerID),
+		}
+
+		gatewayStart := time.Now()
+		epxResp, gatewayErr = s.processTransaction(ctx, epxReq)
+		gatewayLatency = time.Since(gatewayStart)
+		observability.ObserveEPXLatency(string(epxReq.TransactionType), agent.Tier, gatewayLatency)
+		if gatewayErr != nil {
+			if gatewayNeverReached(gatewayErr) {
+				if err := releaseUnsentVolume(ctx, q, slot, held); err != nil {
+					return fmt.Errorf("failed to release daily volume: %w", err)
+				}
+			}
+			// Commit the reservation anyway so a retry reuses its TRAN_NBR
+			return nil
+		}
+
+		status := domain.TransactionStatusFailed
+		if epxResp.IsApproved {
+			status = domain.TransactionStatusCompleted
+		} else if err := held.Release(ctx, q); err != nil {
+			return fmt.Errorf("failed to release daily volume: %w", err)
+		}
+
+		params.Status = string(status)
+		applyGatewayResponse(&params, epxResp)
+
+		dbTx, err := slot.record(ctx, q, params)
 		if err != nil {
-			return nil, fmt.Errorf("invalid amount format: %w", err)
+			return fmt.Errorf("failed to create transaction: %w", err)
 		}
-		if amt.GreaterThan(originalTx.Amount) {
-			return nil, fmt.Errorf("refund amount cannot exceed original transaction amount")
+
+		transaction = sqlcToDomain(&dbTx)
+		return nil
+	})
+
+	if err != nil || replay != nil {
+		if releaseErr := reservation.Release(ctx, s.db.Queries()); releaseErr != nil {
+			log.Warn("Failed to release daily volume", zap.Error(releaseErr))
 		}
-		refundAmount = amt
+		return replay, err
+	}
+	if gatewayErr != nil {
+		log.Error("EPX incremental authorization failed", zap.Error(gatewayErr))
+		return nil, fmt.Errorf("gateway error: %w", gatewayErr)
 	}
+	transaction.Gateway = gatewayResult(epxResp, gatewayLatency)
+	observability.RecordTransaction(string(transaction.Type), string(transaction.Status), agent.Tier)
 
-	// Call EPX Server Post API for refund
-	epxReq := &adapterports.ServerPostRequest{
-		CustNbr:         agent.CustNbr,
-		MerchNbr:        agent.MerchNbr,
-		DBAnbr:          agent.DbaNbr,
-		TerminalNbr:     agent.TerminalNbr,
-		TransactionType: adapterports.TransactionTypeRefund,
-		Amount:          refundAmount.String(),
-		PaymentType:     adapterports.PaymentMethodType(originalTx.PaymentMethodType),
-		AuthGUID:        *originalTx.AuthGUID, // Use original AUTH_GUID
-		TranNbr:         uuid.New().String(),
-		TranGroup:       originalTx.GroupID, // Same group as original
-		CustomerID:      stringOrEmpty(originalTx.CustomerID),
+	log.Info("Incremental authorization completed",
+		zap.String("transaction_id", transaction.ID),
+		zap.String("original_transaction_id", originalTx.ID),
+		zap.String("status", string(transaction.Status)),
+	)
+
+	s.publishTransactionEvent(transaction, originalTx.ID)
+	s.attachTree(ctx, transaction, req.IncludeTree, &agent)
+
+	return transaction, nil
+}
+
+// Void cancels an authorized or captured payment
+func (s *paymentService) Void(ctx context.Context, req *ports.VoidRequest) (result *domain.Transaction, err error) {
+	defer func() { s.audit(ctx, s.auditEntry(domain.AuditActionVoid, req.Actor, "", req, result, err)) }()
+
+	s.logger.Info("Processing void",
+		zap.String("transaction_id", req.TransactionID),
+	)
+
+	// Get original transaction
+	originalTx, err := s.GetTransaction(ctx, req.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check idempotency within the original's merchant; a reused key must come with the same request
+	fingerprint := voidFingerprint(req)
+	if existing, err := s.replayIdempotent(ctx, originalTx.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree); err != nil || existing != nil {
+		return existing, err
+	}
+
+	if !originalTx.CanBeVoided() {
+		return nil, domain.ErrTransactionCannotBeVoided
 	}
 
-	epxResp, err := s.serverPost.ProcessTransaction(ctx, epxReq)
+	// Get agent credentials
+	agent, err := s.db.Queries().GetAgentByAgentID(ctx, originalTx.AgentID)
 	if err != nil {
-		s.logger.Error("EPX refund failed", zap.Error(err))
-		return nil, fmt.Errorf("gateway error: %w", err)
+		return nil, fmt.Errorf("failed to get agent: %w", err)
 	}
+	log := merchantLogger(s.logger, &agent)
+
+	// Suspended merchants can still give money back; closed merchants can't
+	if err := domain.MerchantStatus(agent.Status).CheckRefund(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEPXEnvironment(log, &agent); err != nil {
+		return nil, err
+	}
+
+	// Get MAC secret
+	_, err = s.getSecret(ctx, agent.MacSecretPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MAC secret: %w", err)
+	}
+
+	// The group's follow-ups run one at a time: the void is checked, sent to EPX and recorded under the group's
+	// lock, so it can't land between another follow-up's state check and its record
+	var (
+		transaction    *domain.Transaction
+		replay         *domain.Transaction
+		epxResp        *adapterports.ServerPostResponse
+		gatewayLatency time.Duration
+		gatewayErr     error
+	)
+	groupID := uuid.MustParse(originalTx.GroupID)
+	err = withTxSpan(ctx, s.db, func(q sqlc.Querier) error {
+		if err := q.LockTransactionGroup(ctx, groupID); err != nil {
+			return fmt.Errorf("failed to lock transaction group: %w", err)
+		}
+
+		// A retry that waited on the lock replays whatever its twin recorded
+		var err error
+		replay, err = s.replayIdempotent(ctx, originalTx.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree)
+		if err != nil || replay != nil {
+			return err
+		}
+
+		state, err := groupState(ctx, q, originalTx.GroupID)
+		if err != nil {
+			return err
+		}
+		if state.Voided {
+			return domain.ErrTransactionCannotBeVoided
+		}
+
+		params := sqlc.CreateTransactionParams{
+			GroupID:           groupID,
+			AgentID:           originalTx.AgentID,
+			CustomerID:        toNullableText(originalTx.CustomerID),
+			Amount:            toNumeric(originalTx.Amount),
+			Currency:          originalTx.Currency,
+			Type:              string(domain.TransactionTypeCharge), // Void is still a charge type
+			PaymentMethodType: string(originalTx.PaymentMethodType),
+			PaymentMethodID:   toNullableUUID(originalTx.PaymentMethodID),
+			IdempotencyKey:    toNullableText(req.IdempotencyKey),
+			RequestHash:       requestHash(req.IdempotencyKey, fingerprint),
+			Metadata:          []byte(fmt.Sprintf(`{"original_transaction_id":"%s"}`, originalTx.ID)),
+		}
+
+		// Reserve the idempotency key and TRAN_NBR; a concurrent duplicate stops here, before EPX
+		var slot *transactionSlot
+		slot, replay, err = s.reserveTransaction(ctx, q, params, fingerprint, req.IncludeTree)
+		if err != nil || replay != nil {
+			return err
+		}
+
+		// Call EPX Server Post API for void
+		epxReq := &adapterports.ServerPostRequest{
+			CustNbr:          agent.CustNbr,
+			MerchNbr:         agent.MerchNbr,
+			DBAnbr:           agent.DbaNbr,
+			TerminalNbr:      agent.TerminalNbr,
+			TransactionType:  adapterports.TransactionTypeVoid,
+			Amount:           originalTx.Amount.String(),
+			PaymentType:      adapterports.PaymentMethodType(originalTx.PaymentMethodType),
+			AuthGUID:         *originalTx.AuthGUID, // Use original AUTH_GUID
+			OriginalAuthGUID: *originalTx.AuthGUID, // Sent as ORIG_AUTH_GUID, which EPX requires to reference it
+			TranNbr:          strconv.FormatInt(slot.tranNbr, 10),
+			TranGroup:        originalTx.GroupID, // Same group as original
+			CustomerID:       stringOrEmpty(originalTx.CustomerID),
+		}
+
+		gatewayStart := time.Now()
+		epxResp, gatewayErr = s.processTransaction(ctx, epxReq)
+		gatewayLatency = time.Since(gatewayStart)
+		observability.ObserveEPXLatency(string(epxReq.TransactionType), agent.Tier, gatewayLatency)
+		if gatewayErr != nil {
+			// Commit the reservation anyway so a retry reuses its TRAN_NBR
+			return nil
+		}
 
-	// Save transaction to database
-	var transaction *domain.Transaction
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
 		status := domain.TransactionStatusFailed
 		if epxResp.IsApproved {
-			status = domain.TransactionStatusRefunded
+			status = domain.TransactionStatusVoided
+		}
+
+		params.Status = string(status)
+		applyGatewayResponse(&params, epxResp)
+
+		dbTx, err := slot.record(ctx, q, params)
+		if err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+
+		transaction = sqlcToDomain(&dbTx)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if replay != nil {
+		return replay, nil
+	}
+	if gatewayErr != nil {
+		log.Error("EPX void failed", zap.Error(gatewayErr))
+		return nil, fmt.Errorf("gateway error: %w", gatewayErr)
+	}
+	transaction.Gateway = gatewayResult(epxResp, gatewayLatency)
+	observability.RecordTransaction(string(transaction.Type), string(transaction.Status), agent.Tier)
+
+	log.Info("Void completed",
+		zap.String("transaction_id", transaction.ID),
+		zap.String("original_transaction_id", originalTx.ID),
+		zap.String("status", string(transaction.Status)),
+	)
+
+	s.publishTransactionEvent(transaction, originalTx.ID)
+	s.attachTree(ctx, transaction, req.IncludeTree, &agent)
+
+	return transaction, nil
+}
+
+// Refund returns funds to the customer
+func (s *paymentService) Refund(ctx context.Context, req *ports.RefundRequest) (result *domain.Transaction, err error) {
+	defer func() { s.audit(ctx, s.auditEntry(domain.AuditActionRefund, req.Actor, "", req, result, err)) }()
+
+	s.logger.Info("Processing refund",
+		zap.String("transaction_id", req.TransactionID),
+		zap.String("reason", req.Reason),
+	)
+
+	// Get original transaction
+	originalTx, err := s.GetTransaction(ctx, req.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check idempotency within the original's merchant; a reused key must come with the same request
+	fingerprint := refundFingerprint(req)
+	if existing, err := s.replayIdempotent(ctx, originalTx.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree); err != nil || existing != nil {
+		return existing, err
+	}
+
+	if !originalTx.CanBeRefunded() {
+		return nil, domain.ErrTransactionCannotBeRefunded
+	}
+
+	// Get agent credentials
+	agent, err := s.db.Queries().GetAgentByAgentID(ctx, originalTx.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	log := merchantLogger(s.logger, &agent)
+
+	// Suspended merchants can still give money back; closed merchants can't
+	if err := domain.MerchantStatus(agent.Status).CheckRefund(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEPXEnvironment(log, &agent); err != nil {
+		return nil, err
+	}
+	config, err := resolveMerchantConfig(log, &agent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Enforce the merchant's refund settlement policy (unsettled transactions should be voided)
+	if err := config.CheckRefundSettlement(originalTx); err != nil {
+		log.Info("Refund rejected for unsettled transaction",
+			zap.String("transaction_id", originalTx.ID),
+		)
+		return nil, err
+	}
+
+	// Get MAC secret
+	_, err = s.getSecret(ctx, agent.MacSecretPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MAC secret: %w", err)
+	}
+
+	// The group's refunds run one at a time: the amount left is read, refunded at EPX and recorded under the
+	// group's lock, so concurrent partial refunds can't together exceed what was captured
+	var (
+		transaction    *domain.Transaction
+		replay         *domain.Transaction
+		refundAmount   decimal.Decimal
+		epxResp        *adapterports.ServerPostResponse
+		gatewayLatency time.Duration
+		gatewayErr     error
+	)
+	groupID := uuid.MustParse(originalTx.GroupID)
+	err = withTxSpan(ctx, s.db, func(q sqlc.Querier) error {
+		if err := q.LockTransactionGroup(ctx, groupID); err != nil {
+			return fmt.Errorf("failed to lock transaction group: %w", err)
+		}
+
+		// A retry that waited on the lock replays whatever its twin recorded
+		var err error
+		replay, err = s.replayIdempotent(ctx, originalTx.AgentID, req.IdempotencyKey, fingerprint, req.IncludeTree)
+		if err != nil || replay != nil {
+			return err
+		}
+
+		// Determine refund amount (partial or full), bounded by what the group's earlier refunds left
+		state, err := groupState(ctx, q, originalTx.GroupID)
+		if err != nil {
+			return err
+		}
+		refundAmount, err = resolveRefundAmount(state, originalTx, req.Amount)
+		if err != nil {
+			log.Info("Refund rejected by group state",
+				zap.String("transaction_id", originalTx.ID),
+				zap.String("refunded_amount", state.RefundedAmount.String()),
+				zap.String("captured_amount", state.CapturedAmount.String()),
+				zap.Error(err),
+			)
+			return err
 		}
 
-		metadata := map[string]interface{}{
+		metadataJSON, err := json.Marshal(map[string]interface{}{
 			"original_transaction_id": originalTx.ID,
 			"refund_reason":           req.Reason,
-		}
-		metadataJSON, err := json.Marshal(metadata)
+		})
 		if err != nil {
-			s.logger.Warn("Failed to marshal metadata", zap.Error(err))
+			log.Warn("Failed to marshal metadata", zap.Error(err))
 			metadataJSON = []byte(fmt.Sprintf(`{"original_transaction_id":"%s"}`, originalTx.ID))
 		}
+		params := sqlc.CreateTransactionParams{
+			GroupID:           groupID,
+			AgentID:           originalTx.AgentID,
+			CustomerID:        toNullableText(originalTx.CustomerID),
+			Amount:            toNumeric(refundAmount),
+			Currency:          originalTx.Currency,
+			Type:              string(domain.TransactionTypeRefund),
+			PaymentMethodType: string(originalTx.PaymentMethodType),
+			PaymentMethodID:   toNullableUUID(originalTx.PaymentMethodID),
+			IdempotencyKey:    toNullableText(req.IdempotencyKey),
+			RequestHash:       requestHash(req.IdempotencyKey, fingerprint),
+			Metadata:          metadataJSON,
+		}
+
+		// Reserve the idempotency key and TRAN_NBR; a concurrent duplicate stops here, before EPX
+		var slot *transactionSlot
+		slot, replay, err = s.reserveTransaction(ctx, q, params, fingerprint, req.IncludeTree)
+		if err != nil || replay != nil {
+			return err
+		}
+
+		// Call EPX Server Post API for refund
+		epxReq := refundRequest(&agent, originalTx, refundAmount, slot.tranNbr)
+
+		gatewayStart := time.Now()
+		epxResp, gatewayErr = s.processTransaction(ctx, epxReq)
+		gatewayLatency = time.Since(gatewayStart)
+		observability.ObserveEPXLatency(string(epxReq.TransactionType), agent.Tier, gatewayLatency)
+		if gatewayErr != nil {
+			// Commit the reservation anyway so a retry reuses its TRAN_NBR
+			return nil
+		}
+
+		status := domain.TransactionStatusFailed
+		if epxResp.IsApproved {
+			status = domain.TransactionStatusRefunded
+		}
+
+		params.Status = string(status)
+		applyGatewayResponse(&params, epxResp)
+
+		dbTx, err := slot.record(ctx, q, params)
+		if err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+
+		transaction = sqlcToDomain(&dbTx)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if replay != nil {
+		return replay, nil
+	}
+	if gatewayErr != nil {
+		log.Error("EPX refund failed", zap.Error(gatewayErr))
+		return nil, fmt.Errorf("gateway error: %w", gatewayErr)
+	}
+	transaction.Gateway = gatewayResult(epxResp, gatewayLatency)
+	observability.RecordTransaction(string(transaction.Type), string(transaction.Status), agent.Tier)
+
+	log.Info("Refund completed",
+		zap.String("transaction_id", transaction.ID),
+		zap.String("original_transaction_id", originalTx.ID),
+		zap.String("amount", refundAmount.String()),
+		zap.String("status", string(transaction.Status)),
+	)
+
+	s.publishTransactionEvent(transaction, originalTx.ID)
+	s.attachTree(ctx, transaction, req.IncludeTree, &agent)
+
+	return transaction, nil
+}
+
+// attachTree sets tx.Tree when the response should include the transaction group tree.
+// agent is nil for idempotent replays; it is only looked up when the request leaves the choice to the merchant.
+// Failing to load the group costs the tree, not the (already recorded) operation.
+func (s *paymentService) attachTree(ctx context.Context, tx *domain.Transaction, requested *bool, agent *sqlc.AgentCredential) {
+	var config *domain.MerchantConfig
+	if requested == nil {
+		if agent == nil {
+			dbAgent, err := s.db.Queries().GetAgentByAgentID(ctx, tx.AgentID)
+			if err != nil {
+				s.logger.Warn("Failed to get agent for transaction tree", zap.String("agent_id", tx.AgentID), zap.Error(err))
+				return
+			}
+			agent = &dbAgent
+		}
+		resolved, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+		if err != nil {
+			s.logger.Warn("Invalid merchant config for transaction tree", zap.String("agent_id", tx.AgentID), zap.Error(err))
+			return
+		}
+		config = resolved
+	}
+	if !includeTree(requested, config) {
+		return
+	}
+
+	txs, err := s.GetTransactionsByGroup(ctx, tx.GroupID)
+	if err != nil {
+		s.logger.Warn("Failed to load transaction tree",
+			zap.String("transaction_id", tx.ID),
+			zap.String("group_id", tx.GroupID),
+			zap.Error(err),
+		)
+		return
+	}
+	tx.Tree = domain.BuildTransactionTree(txs)
+}
+
+// includeTree reports whether a payment response includes the transaction group tree:
+// the request's flag when set, otherwise the merchant's default (lean when unknown)
+func includeTree(requested *bool, config *domain.MerchantConfig) bool {
+	if requested != nil {
+		return *requested
+	}
+	return config != nil && config.IncludeTransactionTree
+}
+
+// auditEntry builds the audit record of a payment mutation. The request and resulting transaction pass through
+// the audit redaction policy, so card data, secrets and full BRICs never reach the entry.
+// tx is nil when the operation failed before a transaction was recorded.
+func (s *paymentService) auditEntry(action, actor, agentID string, request interface{}, tx *domain.Transaction, opErr error) *domain.AuditEntry {
+	entry := &domain.AuditEntry{
+		EventType:  "payment." + action,
+		EntityType: "transaction",
+		AgentID:    agentID,
+		Actor:      actor,
+		Action:     action,
+	}
+
+	outcome := map[string]interface{}{"result": "success"}
+	switch {
+	case opErr != nil:
+		outcome["result"] = "error"
+		outcome["error"] = opErr.Error()
+	case tx != nil && tx.Status == domain.TransactionStatusFailed:
+		outcome["result"] = "declined"
+	}
+
+	if tx != nil {
+		entry.EntityID = tx.ID
+		entry.AgentID = tx.AgentID
+		outcome["status"] = string(tx.Status)
+		if tx.AuthResp != nil {
+			outcome["auth_resp"] = *tx.AuthResp
+		}
+
+		entry.Changes = s.auditPolicy.Payload(action, map[string]interface{}{
+			"transaction_id": tx.ID,
+			"group_id":       tx.GroupID,
+			"type":           string(tx.Type),
+			"status":         string(tx.Status),
+			"amount":         tx.Amount.StringFixed(2),
+			"currency":       tx.Currency,
+			"auth_guid":      stringOrEmpty(tx.AuthGUID),
+		})
+	}
+
+	entry.Metadata = s.auditPolicy.Payload(action, map[string]interface{}{
+		"request": request,
+		"outcome": outcome,
+	})
+	return entry
+}
+
+// auditLogWriter records audit_logs rows
+type auditLogWriter interface {
+	CreateAuditLog(ctx context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error)
+}
+
+// audit records a payment mutation's audit entry
+func (s *paymentService) audit(ctx context.Context, entry *domain.AuditEntry) {
+	writeAuditEntry(ctx, s.db.Queries(), entry, s.logger)
+}
+
+// writeAuditEntry records entry. The payment has already happened (or failed) by now, so a failed write is logged
+// rather than returned, and a cancelled request doesn't stop its own audit entry.
+func writeAuditEntry(ctx context.Context, q auditLogWriter, entry *domain.AuditEntry, logger *zap.Logger) {
+	_, err := q.CreateAuditLog(context.WithoutCancel(ctx), sqlc.CreateAuditLogParams{
+		EventType:  entry.EventType,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		AgentID:    entry.AgentID,
+		UserID:     pgtype.Text{String: entry.Actor, Valid: entry.Actor != ""},
+		Action:     entry.Action,
+		AfterState: entry.Changes,
+		Metadata:   entry.Metadata,
+	})
+	if err != nil {
+		logger.Error("Failed to write payment audit log",
+			zap.String("event_type", entry.EventType),
+			zap.String("transaction_id", entry.EntityID),
+			zap.String("agent_id", entry.AgentID),
+			zap.Error(err),
+		)
+	}
+}
+
+// resolveRefundAmount parses the requested refund amount and checks it against the group's refundable amount.
+// Without an amount, what's left of the original transaction is refunded.
+func resolveRefundAmount(state domain.TransactionGroupState, originalTx *domain.Transaction, requested *string) (decimal.Decimal, error) {
+	if requested == nil {
+		amount := decimal.Min(originalTx.Amount, state.RefundableAmount)
+		if err := state.CheckRefund(amount); err != nil {
+			return decimal.Zero, err
+		}
+		return amount, nil
+	}
+
+	amount, err := decimal.NewFromString(*requested)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid amount format: %w", err)
+	}
+	if amount.GreaterThan(originalTx.Amount) {
+		return decimal.Zero, fmt.Errorf("refund amount cannot exceed original transaction amount")
+	}
+	if err := state.CheckRefund(amount); err != nil {
+		return decimal.Zero, err
+	}
+	return amount, nil
+}
+
+// refundRequest builds the EPX request refunding originalTx. ACH payments are refunded with an ACH credit
+// to the same account (CKC4); card payments with a card refund (CCE9).
+func refundRequest(agent *sqlc.AgentCredential, originalTx *domain.Transaction, amount decimal.Decimal, tranNbr int64) *adapterports.ServerPostRequest {
+	tranType := adapterports.TransactionTypeRefund
+	if originalTx.PaymentMethodType == domain.PaymentMethodTypeACH {
+		tranType = adapterports.TransactionTypeACHCredit
+	}
+
+	return &adapterports.ServerPostRequest{
+		CustNbr:          agent.CustNbr,
+		MerchNbr:         agent.MerchNbr,
+		DBAnbr:           agent.DbaNbr,
+		TerminalNbr:      agent.TerminalNbr,
+		TransactionType:  tranType,
+		Amount:           amount.String(),
+		PaymentType:      adapterports.PaymentMethodType(originalTx.PaymentMethodType),
+		AuthGUID:         *originalTx.AuthGUID, // Use original AUTH_GUID
+		OriginalAuthGUID: *originalTx.AuthGUID, // Sent as ORIG_AUTH_GUID, which EPX requires to reference it
+		TranNbr:          strconv.FormatInt(tranNbr, 10),
+		TranGroup:        originalTx.GroupID, // Same group as original
+		CustomerID:       stringOrEmpty(originalTx.CustomerID),
+	}
+}
+
+// transactionEventType maps a recorded transaction to its webhook event type
+func transactionEventType(tx *domain.Transaction) string {
+	switch tx.Status {
+	case domain.TransactionStatusFailed:
+		return webhook.EventPaymentFailed
+	case domain.TransactionStatusVoided:
+		return webhook.EventPaymentVoided
+	case domain.TransactionStatusRefunded:
+		return webhook.EventPaymentRefunded
+	}
+
+	switch tx.Type {
+	case domain.TransactionTypeAuth:
+		return webhook.EventPaymentAuthorized
+	case domain.TransactionTypeReversal:
+		return webhook.EventPaymentAuthorizationReduced
+	case domain.TransactionTypeIncrement:
+		return webhook.EventPaymentAuthorizationIncreased
+	}
+	// Sale (auth + capture) and capture both move funds
+	return webhook.EventPaymentCaptured
+}
+
+// buildTransactionEvent builds the webhook event for a transaction state transition
+func buildTransactionEvent(tx *domain.Transaction, parentTransactionID string) *webhook.WebhookEvent {
+	data := map[string]interface{}{
+		"transaction_id": tx.ID,
+		"group_id":       tx.GroupID,
+		"type":           string(tx.Type),
+		"status":         string(tx.Status),
+		"amount":         tx.Amount.StringFixed(2),
+		"currency":       tx.Currency,
+		"card_type":      stringOrEmpty(tx.AuthCardType),
+	}
+	if parentTransactionID != "" {
+		data["parent_transaction_id"] = parentTransactionID
+	}
+	if tx.CustomerID != nil {
+		data["customer_id"] = *tx.CustomerID
+	}
+
+	return &webhook.WebhookEvent{
+		EventType: transactionEventType(tx),
+		AgentID:   tx.AgentID,
+		Data:      data,
+		Timestamp: time.Now(),
+	}
+}
+
+// publishTransactionEvent delivers the transaction webhook asynchronously (never blocks the payment)
+func (s *paymentService) publishTransactionEvent(tx *domain.Transaction, parentTransactionID string) {
+	if s.events == nil {
+		return
+	}
+
+	event := buildTransactionEvent(tx, parentTransactionID)
+	go func() {
+		if err := s.events.DeliverEvent(context.Background(), event); err != nil {
+			s.logger.Error("Failed to deliver transaction webhook",
+				zap.String("event_type", event.EventType),
+				zap.String("transaction_id", tx.ID),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// GetTransaction retrieves transaction details using sqlc
+func (s *paymentService) GetTransaction(ctx context.Context, transactionID string) (*domain.Transaction, error) {
+	txID, err := uuid.Parse(transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction ID: %w", err)
+	}
+
+	dbTx, err := s.db.Queries().GetTransactionByID(ctx, txID)
+	if err != nil {
+		s.logger.Debug("Transaction not found",
+			zap.String("transaction_id", transactionID),
+			zap.Error(err),
+		)
+		return nil, domain.ErrTransactionNotFound
+	}
+
+	return sqlcToDomain(&dbTx), nil
+}
+
+// transactionBatchQueries is the query behind batch transaction lookups
+type transactionBatchQueries interface {
+	GetTransactionsByIDs(ctx context.Context, ids []uuid.UUID) ([]sqlc.Transaction, error)
+}
+
+// BatchGetTransactions retrieves a batch of transactions in one query, in request order
+func (s *paymentService) BatchGetTransactions(ctx context.Context, transactionIDs []string) ([]*domain.Transaction, error) {
+	return batchGetTransactions(ctx, s.db.Queries(), transactionIDs)
+}
+
+func batchGetTransactions(ctx context.Context, q transactionBatchQueries, transactionIDs []string) ([]*domain.Transaction, error) {
+	if len(transactionIDs) > MaxTransactionStatusBatch {
+		return nil, fmt.Errorf("%w: %d transaction IDs (max %d)", domain.ErrBatchTooLarge, len(transactionIDs), MaxTransactionStatusBatch)
+	}
+
+	// Malformed IDs can't exist, so they're skipped rather than failing the batch
+	ids := make([]uuid.UUID, 0, len(transactionIDs))
+	for _, id := range transactionIDs {
+		if parsed, err := uuid.Parse(id); err == nil {
+			ids = append(ids, parsed)
+		}
+	}
+	if len(ids) == 0 {
+		return []*domain.Transaction{}, nil
+	}
+
+	dbTxs, err := q.GetTransactionsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]*domain.Transaction, len(dbTxs))
+	for i := range dbTxs {
+		byID[dbTxs[i].ID] = sqlcToDomain(&dbTxs[i])
+	}
+
+	transactions := make([]*domain.Transaction, 0, len(byID))
+	for _, id := range ids {
+		if tx, ok := byID[id]; ok {
+			transactions = append(transactions, tx)
+		}
+	}
+	return transactions, nil
+}
+
+// saleBatchQueries are the queries behind batch-level BatchSale idempotency
+type saleBatchQueries interface {
+	transactionBatchQueries
+	ClaimSaleBatch(ctx context.Context, arg sqlc.ClaimSaleBatchParams) (sqlc.SaleBatch, error)
+	GetSaleBatch(ctx context.Context, arg sqlc.GetSaleBatchParams) (sqlc.SaleBatch, error)
+	CompleteSaleBatch(ctx context.Context, arg sqlc.CompleteSaleBatchParams) error
+}
+
+// saleBatchRecord is one item's stored outcome
+type saleBatchRecord struct {
+	TransactionID string `json:"transaction_id,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// saleFunc runs a single sale
+type saleFunc func(ctx context.Context, req *ports.SaleRequest) (*domain.Transaction, error)
+
+// BatchSale runs a batch of sales, recording keyed batches so retries replay the first run's results
+func (s *paymentService) BatchSale(ctx context.Context, req *ports.BatchSaleRequest) (*domain.SaleBatchResult, error) {
+	return runSaleBatch(ctx, s.db.Queries(), s.Sale, req, time.Now(), s.logger)
+}
+
+func runSaleBatch(ctx context.Context, q saleBatchQueries, sale saleFunc, req *ports.BatchSaleRequest, now time.Time, logger *zap.Logger) (*domain.SaleBatchResult, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: items", domain.ErrMissingRequiredField)
+	}
+	if len(req.Items) > MaxSaleBatch {
+		return nil, fmt.Errorf("%w: %d sales (max %d)", domain.ErrBatchTooLarge, len(req.Items), MaxSaleBatch)
+	}
+	if req.IdempotencyKey == nil {
+		return &domain.SaleBatchResult{Items: processSaleBatch(ctx, sale, req, logger)}, nil
+	}
+
+	fingerprint := saleBatchFingerprint(req)
+	claim, err := q.ClaimSaleBatch(ctx, sqlc.ClaimSaleBatchParams{
+		AgentID:        req.AgentID,
+		IdempotencyKey: *req.IdempotencyKey,
+		RequestHash:    pgtype.Text{String: fingerprint, Valid: true},
+		StaleBefore:    now.Add(-saleBatchStaleAfter),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return replaySaleBatch(ctx, q, req, fingerprint)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim sale batch: %w", err)
+	}
+
+	items := processSaleBatch(ctx, sale, req, logger)
+
+	records := make([]saleBatchRecord, len(items))
+	for i, item := range items {
+		records[i].Error = item.Error
+		if item.Transaction != nil {
+			records[i].TransactionID = item.Transaction.ID
+		}
+	}
+	results, err := json.Marshal(records)
+	if err == nil {
+		err = q.CompleteSaleBatch(ctx, sqlc.CompleteSaleBatchParams{ID: claim.ID, Results: results})
+	}
+	if err != nil {
+		// The sales went through, so return them; a retry is rejected until the claim goes stale,
+		// then re-runs the items under their own idempotency keys
+		logger.Error("Failed to record sale batch results",
+			zap.String("agent_id", req.AgentID),
+			zap.String("batch_id", claim.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	return &domain.SaleBatchResult{Items: items}, nil
+}
+
+// processSaleBatch runs each item in order; an item's failure doesn't stop the rest
+func processSaleBatch(ctx context.Context, sale saleFunc, req *ports.BatchSaleRequest, logger *zap.Logger) []domain.SaleBatchItem {
+	items := make([]domain.SaleBatchItem, len(req.Items))
+	for i, itemReq := range req.Items {
+		itemReq.AgentID = req.AgentID
+
+		tx, err := sale(ctx, itemReq)
+		if err != nil {
+			logger.Warn("Batch sale item failed",
+				zap.String("agent_id", req.AgentID),
+				zap.Int("index", i),
+				zap.Error(err),
+			)
+			items[i].Error = saleBatchItemError(err)
+			continue
+		}
+		items[i].Transaction = tx
+	}
+	return items
+}
+
+// saleBatchItemErrors are the failures a batch item reports to the caller, by their own message only;
+// the detail they wrap (and any other error) is logged instead
+var saleBatchItemErrors = []error{
+	domain.ErrMissingRequiredField,
+	domain.ErrInvalidAmount,
+	domain.ErrAmountOutOfRange,
+	domain.ErrInvalidCurrency,
+	domain.ErrInvalidThreeDSecure,
+	domain.ErrInvalidStatementDescriptor,
+	domain.ErrPaymentMethodNotFound,
+	domain.ErrCustomerNotFound,
+	domain.ErrIdempotencyKeyConflict,
+	domain.ErrTransactionDeclined,
+	domain.ErrFraudDeclined,
+	domain.ErrVelocityExceeded,
+	domain.ErrDailyVolumeLimitExceeded,
+	domain.ErrChargesDisabled,
+	domain.ErrAgentInactive,
+	domain.ErrMerchantClosed,
+	domain.ErrEnvironmentMismatch,
+	context.DeadlineExceeded,
+	context.Canceled,
+}
+
+// saleBatchItemError is the error a batch reports for a failed item (stored with the batch for replays)
+func saleBatchItemError(err error) string {
+	for _, reported := range saleBatchItemErrors {
+		if errors.Is(err, reported) {
+			return reported.Error()
+		}
+	}
+	return "sale failed"
+}
+
+// saleBatchFingerprint identifies a batch's sales, in order, by each item's own fingerprint and idempotency key
+func saleBatchFingerprint(req *ports.BatchSaleRequest) string {
+	parts := make([]string, 0, 2*len(req.Items))
+	for _, itemReq := range req.Items {
+		item := *itemReq
+		item.AgentID = req.AgentID
+		parts = append(parts, saleFingerprint(&item), stringOrEmpty(item.IdempotencyKey))
+	}
+	return domain.RequestFingerprint(parts...)
+}
+
+// replaySaleBatch returns the recorded results of a completed batch with the same key and sales.
+// Batches recorded before request hashes were stored have none and are replayed as before.
+func replaySaleBatch(ctx context.Context, q saleBatchQueries, req *ports.BatchSaleRequest, fingerprint string) (*domain.SaleBatchResult, error) {
+	batch, err := q.GetSaleBatch(ctx, sqlc.GetSaleBatchParams{AgentID: req.AgentID, IdempotencyKey: *req.IdempotencyKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sale batch: %w", err)
+	}
+	if batch.RequestHash.Valid && batch.RequestHash.String != fingerprint {
+		return nil, fmt.Errorf("%w: batch %s", domain.ErrIdempotencyKeyConflict, batch.ID)
+	}
+	if !batch.CompletedAt.Valid {
+		return nil, domain.ErrSaleBatchInProgress
+	}
+
+	var records []saleBatchRecord
+	if err := json.Unmarshal(batch.Results, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode sale batch results: %w", err)
+	}
+	if len(records) != len(req.Items) {
+		return nil, fmt.Errorf("%w: batch key was used for a batch of %d sales", domain.ErrIdempotencyKeyConflict, len(records))
+	}
+
+	var ids []string
+	for _, record := range records {
+		if record.TransactionID != "" {
+			ids = append(ids, record.TransactionID)
+		}
+	}
+	txs, err := batchGetTransactions(ctx, q, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.Transaction, len(txs))
+	for _, tx := range txs {
+		byID[tx.ID] = tx
+	}
+
+	items := make([]domain.SaleBatchItem, len(records))
+	for i, record := range records {
+		items[i] = domain.SaleBatchItem{Transaction: byID[record.TransactionID], Error: record.Error}
+	}
+
+	return &domain.SaleBatchResult{Items: items, Replayed: true}, nil
+}
+
+// GetTransactionStatuses looks up a batch of the agent's transactions in one query
+func (s *paymentService) GetTransactionStatuses(ctx context.Context, agentID string, transactionIDs []string) ([]*domain.TransactionStatusResult, error) {
+	if agentID == "" {
+		return nil, fmt.Errorf("%w: agent_id", domain.ErrMissingRequiredField)
+	}
+	if len(transactionIDs) > MaxTransactionStatusBatch {
+		return nil, fmt.Errorf("%w: %d transaction IDs (max %d)", domain.ErrBatchTooLarge, len(transactionIDs), MaxTransactionStatusBatch)
+	}
+
+	// Malformed IDs can't exist, so they're reported as not found rather than failing the batch
+	ids := make([]uuid.UUID, 0, len(transactionIDs))
+	for _, id := range transactionIDs {
+		if parsed, err := uuid.Parse(id); err == nil {
+			ids = append(ids, parsed)
+		}
+	}
+
+	var transactions []*domain.Transaction
+	if len(ids) > 0 {
+		dbTxs, err := s.db.Queries().GetAgentTransactionsByIDs(ctx, sqlc.GetAgentTransactionsByIDsParams{
+			AgentID: agentID,
+			Ids:     ids,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transactions: %w", err)
+		}
+
+		transactions = make([]*domain.Transaction, len(dbTxs))
+		for i, dbTx := range dbTxs {
+			transactions[i] = sqlcToDomain(&dbTx)
+		}
+	}
+
+	return buildStatusResults(transactionIDs, transactions), nil
+}
+
+// buildStatusResults returns one result per requested ID in request order
+func buildStatusResults(transactionIDs []string, transactions []*domain.Transaction) []*domain.TransactionStatusResult {
+	byID := make(map[string]*domain.Transaction, len(transactions))
+	for _, tx := range transactions {
+		byID[tx.ID] = tx
+	}
+
+	results := make([]*domain.TransactionStatusResult, len(transactionIDs))
+	for i, id := range transactionIDs {
+		result := &domain.TransactionStatusResult{TransactionID: id}
+		if parsed, err := uuid.Parse(id); err == nil {
+			result.Transaction = byID[parsed.String()]
+		}
+		results[i] = result
+	}
+
+	return results
+}
+
+// GetEstimatedFees estimates the processing fee for a transaction from the configured fee schedule
+func (s *paymentService) GetEstimatedFees(ctx context.Context, transactionID string) (*domain.FeeEstimate, error) {
+	tx, err := s.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.fees.Estimate(tx), nil
+}
+
+// ClassifyDeclineCode maps an EPX response code through the gateway adapter's live response mapping
+func (s *paymentService) ClassifyDeclineCode(ctx context.Context, authResp, authRespText string) (*domain.GatewayResult, error) {
+	if authResp == "" {
+		return nil, fmt.Errorf("%w: auth_resp", domain.ErrMissingRequiredField)
+	}
+	return gatewayResult(s.serverPost.ClassifyResponse(authResp, authRespText), 0), nil
+}
+
+// idempotencyKeyQueries looks up the transaction a merchant's idempotency key recorded
+type idempotencyKeyQueries interface {
+	GetTransactionByIdempotencyKey(ctx context.Context, arg sqlc.GetTransactionByIdempotencyKeyParams) (sqlc.Transaction, error)
+}
+
+// replayIdempotent answers a retried request from the transaction its merchant's idempotency key already recorded:
+// the transaction (with its tree when asked for), ErrIdempotencyKeyConflict if the key came with different
+// parameters, ErrDuplicateIdempotencyKey while the key's request is still running, or nil, nil when the key is
+// unset, unused, or held by a stale reservation the retry may take over
+func (s *paymentService) replayIdempotent(ctx context.Context, agentID string, key *string, fingerprint string, includeTree *bool) (*domain.Transaction, error) {
+	if key == nil {
+		return nil, nil
+	}
+	existing, err := s.GetTransactionByIdempotencyKey(ctx, agentID, *key)
+	if err != nil {
+		return nil, nil
+	}
+	if err := existing.CheckReplay(fingerprint); err != nil {
+		s.logger.Warn("Idempotency key reused with different request parameters",
+			zap.String("transaction_id", existing.ID),
+		)
+		return nil, err
+	}
+	if existing.Status == domain.TransactionStatusPending {
+		if time.Since(existing.UpdatedAt) < pendingTransactionStaleAfter {
+			return nil, fmt.Errorf("%w: a request with this key is still being processed", domain.ErrDuplicateIdempotencyKey)
+		}
+		return nil, nil
+	}
+	s.logger.Info("Idempotent request, returning existing transaction",
+		zap.String("transaction_id", existing.ID),
+	)
+	s.attachTree(ctx, existing, includeTree, nil)
+	return existing, nil
+}
+
+// GetTransactionByIdempotencyKey retrieves the merchant's transaction recorded under an idempotency key.
+// Keys are opaque client strings, independent of transaction IDs, and unique per merchant.
+func (s *paymentService) GetTransactionByIdempotencyKey(ctx context.Context, agentID, key string) (*domain.Transaction, error) {
+	tx, err := transactionByIdempotencyKey(ctx, s.db.Queries(), agentID, key)
+	if err != nil {
+		s.logger.Debug("Transaction not found by idempotency key",
+			zap.String("agent_id", agentID),
+			zap.String("idempotency_key", key),
+			zap.Error(err),
+		)
+		return nil, domain.ErrTransactionNotFound
+	}
+	return tx, nil
+}
+
+func transactionByIdempotencyKey(ctx context.Context, q idempotencyKeyQueries, agentID, key string) (*domain.Transaction, error) {
+	dbTx, err := q.GetTransactionByIdempotencyKey(ctx, sqlc.GetTransactionByIdempotencyKeyParams{
+		AgentID:        agentID,
+		IdempotencyKey: pgtype.Text{String: key, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sqlcToDomain(&dbTx), nil
+}
+
+// refundReferenceQueries look a payment up by the EPX references on its receipt
+type refundReferenceQueries interface {
+	GetTransactionByAuthGUID(ctx context.Context, authGuid pgtype.Text) (sqlc.Transaction, error)
+	GetTransactionByTranNbr(ctx context.Context, arg sqlc.GetTransactionByTranNbrParams) (sqlc.Transaction, error)
+	GetTransactionsByGroupID(ctx context.Context, groupID uuid.UUID) ([]sqlc.Transaction, error)
+}
+
+// RefundByReference refunds the merchant's payment with the given BRIC or TRAN_NBR, for integrators that only
+// have the receipt. The reference is resolved to a transaction ID and the refund then runs exactly as Refund.
+func (s *paymentService) RefundByReference(ctx context.Context, req *ports.RefundByReferenceRequest) (*domain.Transaction, error) {
+	original, err := resolveRefundReference(ctx, s.db.Queries(), req.AgentID, req.AuthGUID, req.TranNbr)
+	if err != nil {
+		s.logger.Info("Refund reference not resolved",
+			zap.String("agent_id", req.AgentID),
+			zap.String("tran_nbr", req.TranNbr),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return s.Refund(ctx, &ports.RefundRequest{
+		TransactionID:  original.ID,
+		Amount:         req.Amount,
+		Reason:         req.Reason,
+		IdempotencyKey: req.IdempotencyKey,
+		IncludeTree:    req.IncludeTree,
+		Actor:          req.Actor,
+	})
+}
+
+// resolveRefundReference finds the agent's refundable transaction for a BRIC or TRAN_NBR. Another merchant's
+// payment is ErrTransactionNotFound, like one that doesn't exist. A reference to an authorization resolves to
+// the latest completed capture in its group, since that's what holds the money; other transactions resolve to
+// themselves and are left to Refund's checks.
+func resolveRefundReference(ctx context.Context, q refundReferenceQueries, agentID, authGUID, tranNbr string) (*domain.Transaction, error) {
+	if agentID == "" {
+		return nil, fmt.Errorf("%w: agent_id is required", domain.ErrInvalidReference)
+	}
+	if (authGUID == "") == (tranNbr == "") {
+		return nil, fmt.Errorf("%w: exactly one of auth_guid and tran_nbr is required", domain.ErrInvalidReference)
+	}
+
+	var dbTx sqlc.Transaction
+	var err error
+	if authGUID != "" {
+		dbTx, err = q.GetTransactionByAuthGUID(ctx, pgtype.Text{String: authGUID, Valid: true})
+		if err == nil && dbTx.AgentID != agentID {
+			err = pgx.ErrNoRows
+		}
+	} else {
+		number, parseErr := strconv.ParseInt(tranNbr, 10, 64)
+		if parseErr != nil || number <= 0 {
+			return nil, fmt.Errorf("%w: tran_nbr must be a positive number", domain.ErrInvalidReference)
+		}
+		dbTx, err = q.GetTransactionByTranNbr(ctx, sqlc.GetTransactionByTranNbrParams{
+			AgentID:    agentID,
+			TranNbr:    number,
+			TranNbrKey: tranNbr,
+		})
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up transaction reference: %w", err)
+	}
+
+	tx := sqlcToDomain(&dbTx)
+	if tx.Type != domain.TransactionTypeAuth {
+		return tx, nil
+	}
+
+	group, err := q.GetTransactionsByGroupID(ctx, dbTx.GroupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions by group: %w", err)
+	}
+	var capture *domain.Transaction
+	for i := range group {
+		member := sqlcToDomain(&group[i])
+		if member.Type == domain.TransactionTypeCapture && member.Status == domain.TransactionStatusCompleted &&
+			(capture == nil || member.CreatedAt.After(capture.CreatedAt)) {
+			capture = member
+		}
+	}
+	if capture == nil {
+		return tx, nil
+	}
+	return capture, nil
+}
+
+// transactionListQueries are the queries behind transaction listings
+type transactionListQueries interface {
+	ListTransactions(ctx context.Context, arg sqlc.ListTransactionsParams) ([]sqlc.Transaction, error)
+	CountTransactions(ctx context.Context, arg sqlc.CountTransactionsParams) (int64, error)
+	ListTransactionsAfterCursor(ctx context.Context, arg sqlc.ListTransactionsAfterCursorParams) ([]sqlc.Transaction, error)
+}
+
+// ListTransactions lists transactions with filters using sqlc
+func (s *paymentService) ListTransactions(ctx context.Context, agentID string, filter domain.TransactionFilter, limit, offset int) ([]*domain.Transaction, int, error) {
+	return listTransactions(ctx, s.db.Queries(), agentID, filter, limit, offset)
+}
+
+func listTransactions(ctx context.Context, q transactionListQueries, agentID string, filter domain.TransactionFilter, limit, offset int) ([]*domain.Transaction, int, error) {
+	args, err := transactionFilterArgs(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	params := sqlc.ListTransactionsParams{
+		AgentID:          toNullableText(&agentID),
+		CustomerID:       toNullableText(filter.CustomerID),
+		MetadataContains: args.metadataContains,
+		MinAmount:        args.minAmount,
+		MaxAmount:        args.maxAmount,
+		CreatedFrom:      args.createdFrom,
+		CreatedTo:        args.createdTo,
+		LimitVal:         int32(limit),
+		OffsetVal:        int32(offset),
+	}
+
+	dbTxs, err := q.ListTransactions(ctx, params)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	countParams := sqlc.CountTransactionsParams{
+		AgentID:          toNullableText(&agentID),
+		CustomerID:       toNullableText(filter.CustomerID),
+		MetadataContains: args.metadataContains,
+		MinAmount:        args.minAmount,
+		MaxAmount:        args.maxAmount,
+		CreatedFrom:      args.createdFrom,
+		CreatedTo:        args.createdTo,
+	}
+
+	count, err := q.CountTransactions(ctx, countParams)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count transactions: %w", err)
+	}
+
+	transactions := make([]*domain.Transaction, len(dbTxs))
+	for i, dbTx := range dbTxs {
+		transactions[i] = sqlcToDomain(&dbTx)
+	}
 
-		params := sqlc.CreateTransactionParams{
-			ID:                uuid.New(),
-			GroupID:           uuid.MustParse(originalTx.GroupID),
-			AgentID:           originalTx.AgentID,
-			CustomerID:        toNullableText(originalTx.CustomerID),
-			Amount:            toNumeric(refundAmount),
-			Currency:          originalTx.Currency,
-			Status:            string(status),
-			Type:              string(domain.TransactionTypeRefund),
-			PaymentMethodType: string(originalTx.PaymentMethodType),
-			PaymentMethodID:   toNullableUUID(originalTx.PaymentMethodID),
-			AuthGuid:          toNullableText(&epxResp.AuthGUID),
-			AuthResp:          toNullableText(&epxResp.AuthResp),
-			AuthCode:          toNullableText(&epxResp.AuthCode),
-			AuthRespText:      toNullableText(&epxResp.AuthRespText),
-			AuthCardType:      toNullableText(&epxResp.AuthCardType),
-			AuthAvs:           toNullableText(&epxResp.AuthAVS),
-			AuthCvv2:          toNullableText(&epxResp.AuthCVV2),
-			IdempotencyKey:    toNullableText(req.IdempotencyKey),
-			Metadata:          metadataJSON,
-		}
+	return transactions, int(count), nil
+}
+
+// transactionFilterQueryArgs are the nullable query arguments for a TransactionFilter's metadata, amount and date filters
+type transactionFilterQueryArgs struct {
+	metadataContains []byte // JSONB containment document (nil = no filter)
+	minAmount        pgtype.Numeric
+	maxAmount        pgtype.Numeric
+	createdFrom      pgtype.Timestamptz
+	createdTo        pgtype.Timestamptz
+}
+
+func transactionFilterArgs(filter domain.TransactionFilter) (transactionFilterQueryArgs, error) {
+	var args transactionFilterQueryArgs
+	if err := filter.Validate(); err != nil {
+		return args, err
+	}
 
-		dbTx, err := q.CreateTransaction(ctx, params)
+	if len(filter.MetadataContains) > 0 {
+		doc, err := json.Marshal(filter.MetadataContains)
 		if err != nil {
-			return fmt.Errorf("failed to create transaction: %w", err)
+			return args, fmt.Errorf("failed to encode metadata filter: %w", err)
 		}
+		args.metadataContains = doc
+	}
+	if filter.MinAmountCents != nil {
+		args.minAmount = toNumeric(decimal.New(*filter.MinAmountCents, -2))
+	}
+	if filter.MaxAmountCents != nil {
+		args.maxAmount = toNumeric(decimal.New(*filter.MaxAmountCents, -2))
+	}
+	if filter.CreatedFrom != nil {
+		args.createdFrom = pgtype.Timestamptz{Time: *filter.CreatedFrom, Valid: true}
+	}
+	if filter.CreatedTo != nil {
+		args.createdTo = pgtype.Timestamptz{Time: *filter.CreatedTo, Valid: true}
+	}
+	return args, nil
+}
 
-		transaction = sqlcToDomain(&dbTx)
-		return nil
-	})
+// ListTransactionsByCursor lists transactions newest first using keyset pagination on (created_at, id)
+func (s *paymentService) ListTransactionsByCursor(ctx context.Context, agentID string, filter domain.TransactionFilter, limit int, cursor string) ([]*domain.Transaction, string, error) {
+	return listTransactionPage(ctx, s.db.Queries(), agentID, filter, limit, cursor)
+}
 
+// listTransactionPage fetches one extra row to tell whether another page follows
+func listTransactionPage(ctx context.Context, q transactionListQueries, agentID string, filter domain.TransactionFilter, limit int, cursor string) ([]*domain.Transaction, string, error) {
+	args, err := transactionFilterArgs(filter)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	s.logger.Info("Refund completed",
-		zap.String("transaction_id", transaction.ID),
-		zap.String("original_transaction_id", originalTx.ID),
-		zap.String("amount", refundAmount.String()),
-		zap.String("status", string(transaction.Status)),
-	)
+	params := sqlc.ListTransactionsAfterCursorParams{
+		AgentID:          agentID,
+		CustomerID:       toNullableText(filter.CustomerID),
+		MetadataContains: args.metadataContains,
+		MinAmount:        args.minAmount,
+		MaxAmount:        args.maxAmount,
+		CreatedFrom:      args.createdFrom,
+		CreatedTo:        args.createdTo,
+		LimitVal:         int32(limit + 1),
+	}
 
-	return transaction, nil
-}
+	if cursor != "" {
+		position, err := domain.DecodeTransactionCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		params.CursorCreatedAt = pgtype.Timestamptz{Time: position.CreatedAt, Valid: true}
+		params.CursorID = pgtype.UUID{Bytes: position.ID, Valid: true}
+	}
 
-// GetTransaction retrieves transaction details using sqlc
-func (s *paymentService) GetTransaction(ctx context.Context, transactionID string) (*domain.Transaction, error) {
-	txID, err := uuid.Parse(transactionID)
+	dbTxs, err := q.ListTransactionsAfterCursor(ctx, params)
 	if err != nil {
-		return nil, fmt.Errorf("invalid transaction ID: %w", err)
+		return nil, "", fmt.Errorf("failed to list transactions: %w", err)
 	}
 
-	dbTx, err := s.db.Queries().GetTransactionByID(ctx, txID)
-	if err != nil {
-		s.logger.Debug("Transaction not found",
-			zap.String("transaction_id", transactionID),
-			zap.Error(err),
-		)
-		return nil, domain.ErrTransactionNotFound
+	hasMore := len(dbTxs) > limit
+	if hasMore {
+		dbTxs = dbTxs[:limit]
 	}
 
-	return sqlcToDomain(&dbTx), nil
-}
+	transactions := make([]*domain.Transaction, len(dbTxs))
+	for i := range dbTxs {
+		transactions[i] = sqlcToDomain(&dbTxs[i])
+	}
+
+	if !hasMore || len(transactions) == 0 {
+		return transactions, "", nil
+	}
 
-// GetTransactionByIdempotencyKey retrieves a transaction by idempotency key using sqlc
-func (s *paymentService) GetTransactionByIdempotencyKey(ctx context.Context, key string) (*domain.Transaction, error) {
-	dbTx, err := s.db.Queries().GetTransactionByIdempotencyKey(ctx, pgtype.Text{String: key, Valid: true})
+	next, err := domain.CursorAfter(transactions[len(transactions)-1])
 	if err != nil {
-		s.logger.Debug("Transaction not found by idempotency key",
-			zap.String("idempotency_key", key),
-			zap.Error(err),
-		)
-		return nil, domain.ErrTransactionNotFound
+		return nil, "", err
 	}
+	return transactions, next.Encode(), nil
+}
 
-	return sqlcToDomain(&dbTx), nil
+// settlementQueries are the queries behind settlement summaries
+type settlementQueries interface {
+	GetSettlementTotals(ctx context.Context, arg sqlc.GetSettlementTotalsParams) ([]sqlc.GetSettlementTotalsRow, error)
 }
 
-// ListTransactions lists transactions with filters using sqlc
-func (s *paymentService) ListTransactions(ctx context.Context, agentID string, customerID *string, limit, offset int) ([]*domain.Transaction, int, error) {
-	params := sqlc.ListTransactionsParams{
-		AgentID:    toNullableText(&agentID),
-		CustomerID: toNullableText(customerID),
-		LimitVal:   int32(limit),
-		OffsetVal:  int32(offset),
-	}
+// GetSettlementSummary totals a merchant's sales, refunds and voids per UTC day for [startDate, endDate]
+func (s *paymentService) GetSettlementSummary(ctx context.Context, agentID string, startDate, endDate time.Time) ([]*domain.SettlementDay, error) {
+	return settlementSummary(ctx, s.db.Queries(), agentID, startDate, endDate)
+}
 
-	dbTxs, err := s.db.Queries().ListTransactions(ctx, params)
+func settlementSummary(ctx context.Context, q settlementQueries, agentID string, startDate, endDate time.Time) ([]*domain.SettlementDay, error) {
+	from, to, err := domain.SettlementRange(startDate, endDate)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list transactions: %w", err)
-	}
-
-	countParams := sqlc.CountTransactionsParams{
-		AgentID:    toNullableText(&agentID),
-		CustomerID: toNullableText(customerID),
+		return nil, err
 	}
 
-	count, err := s.db.Queries().CountTransactions(ctx, countParams)
+	rows, err := q.GetSettlementTotals(ctx, sqlc.GetSettlementTotalsParams{
+		AgentID:     agentID,
+		CreatedFrom: from,
+		CreatedTo:   to,
+	})
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count transactions: %w", err)
+		return nil, fmt.Errorf("failed to total settlement: %w", err)
 	}
 
-	transactions := make([]*domain.Transaction, len(dbTxs))
-	for i, dbTx := range dbTxs {
-		transactions[i] = sqlcToDomain(&dbTx)
+	totals := make([]domain.SettlementTotal, len(rows))
+	for i, row := range rows {
+		totals[i] = domain.SettlementTotal{
+			Day:      row.Day.Time,
+			Currency: row.Currency,
+			Kind:     row.Kind,
+			Count:    int(row.TransactionCount),
+			Amount:   decimal.NewFromBigInt(row.TotalAmount.Int, row.TotalAmount.Exp),
+		}
 	}
+	return domain.SummarizeSettlement(totals), nil
+}
 
-	return transactions, int(count), nil
+// groupState computes the current money movement of a transaction group as q sees it
+func groupState(ctx context.Context, q sqlc.Querier, groupID string) (domain.TransactionGroupState, error) {
+	txs, err := transactionsByGroup(ctx, q, groupID)
+	if err != nil {
+		return domain.TransactionGroupState{}, err
+	}
+	return domain.BuildTransactionTree(txs).State, nil
 }
 
 // GetTransactionsByGroup retrieves all transactions in a group using sqlc
 func (s *paymentService) GetTransactionsByGroup(ctx context.Context, groupID string) ([]*domain.Transaction, error) {
+	return transactionsByGroup(ctx, s.db.Queries(), groupID)
+}
+
+// transactionsByGroup reads a group's transactions through q
+func transactionsByGroup(ctx context.Context, q sqlc.Querier, groupID string) ([]*domain.Transaction, error) {
 	gID, err := uuid.Parse(groupID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid group ID: %w", err)
 	}
 
-	dbTxs, err := s.db.Queries().GetTransactionsByGroupID(ctx, gID)
+	dbTxs, err := q.GetTransactionsByGroupID(ctx, gID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions by group: %w", err)
 	}
@@ -809,8 +2454,85 @@ func (s *paymentService) GetTransactionsByGroup(ctx context.Context, groupID str
 	return transactions, nil
 }
 
+// Traced calls to the secret manager, EPX and the database
+
+// getSecret fetches a merchant secret inside a secretmanager.GetSecret span
+func (s *paymentService) getSecret(ctx context.Context, path string) (*adapterports.Secret, error) {
+	ctx, span := observability.StartSpan(ctx, "secretmanager.GetSecret")
+	secret, err := s.secretManager.GetSecret(ctx, path)
+	observability.EndSpan(span, err)
+	return secret, err
+}
+
+// processTransaction sends a request to EPX Server Post inside an epx.ProcessTransaction span
+func (s *paymentService) processTransaction(ctx context.Context, req *adapterports.ServerPostRequest) (*adapterports.ServerPostResponse, error) {
+	ctx, span := observability.StartSpan(ctx, "epx.ProcessTransaction",
+		attribute.String("epx.transaction_type", string(req.TransactionType)),
+	)
+	resp, err := s.serverPost.ProcessTransaction(ctx, req)
+	if resp != nil {
+		span.SetAttributes(
+			attribute.Bool("epx.approved", resp.IsApproved),
+			attribute.String("epx.auth_resp", resp.AuthResp),
+		)
+	}
+	observability.EndSpan(span, err)
+	return resp, err
+}
+
+// transactor runs a function inside a database transaction
+type transactor interface {
+	WithTx(ctx context.Context, fn func(sqlc.Querier) error) error
+}
+
+// withTxSpan runs fn in a database transaction inside a db.WithTx span
+func withTxSpan(ctx context.Context, db transactor, fn func(sqlc.Querier) error) error {
+	ctx, span := observability.StartSpan(ctx, "db.WithTx")
+	err := db.WithTx(ctx, fn)
+	observability.EndSpan(span, err)
+	return err
+}
+
+// checkEPXEnvironment refuses to send a merchant's credentials to EPX adapters of the other environment:
+// production merchants through the sandbox, or sandbox merchants through production
+func (s *paymentService) checkEPXEnvironment(log *zap.Logger, agent *sqlc.AgentCredential) error {
+	if err := database.CheckEPXEnvironment(agent, s.epxEnv); err != nil {
+		log.Error("Merchant environment doesn't match the EPX environment",
+			zap.String("merchant_environment", agent.Environment),
+			zap.String("epx_environment", string(s.epxEnv)),
+		)
+		return err
+	}
+	return nil
+}
+
 // Helper functions to convert between sqlc and domain models
 
+// gatewayResult builds the uniform gateway envelope from an EPX response and its round-trip time
+func gatewayResult(resp *adapterports.ServerPostResponse, latency time.Duration) *domain.GatewayResult {
+	result := &domain.GatewayResult{
+		ResponseCode: resp.AuthResp,
+		ResponseText: resp.AuthRespText,
+		AuthCode:     resp.AuthCode,
+		Approved:     resp.IsApproved,
+		CardType:     resp.AuthCardType,
+		AVSCode:      resp.AuthAVS,
+		AVSResult:    domain.SummarizeAVS(resp.AuthAVS),
+		CVVCode:      resp.AuthCVV2,
+		CVVResult:    domain.SummarizeCVV(resp.AuthCVV2),
+		Latency:      latency,
+	}
+
+	if resp.Decline != nil {
+		result.DeclineCode = resp.Decline.Code
+		result.DeclineCategory = string(resp.Decline.Category)
+		result.DeclineReason = resp.Decline.Message
+		result.Retriable = resp.Decline.IsRetriable
+	}
+
+	return result
+}
+
 func sqlcToDomain(dbTx *sqlc.Transaction) *domain.Transaction {
 	tx := &domain.Transaction{
 		ID:                dbTx.ID.String(),
@@ -821,6 +2543,7 @@ func sqlcToDomain(dbTx *sqlc.Transaction) *domain.Transaction {
 		Status:            domain.TransactionStatus(dbTx.Status),
 		Type:              domain.TransactionType(dbTx.Type),
 		PaymentMethodType: domain.PaymentMethodType(dbTx.PaymentMethodType),
+		DataRegion:        dbTx.DataRegion,
 		CreatedAt:         dbTx.CreatedAt,
 		UpdatedAt:         dbTx.UpdatedAt,
 	}
@@ -856,9 +2579,37 @@ func sqlcToDomain(dbTx *sqlc.Transaction) *domain.Transaction {
 	if dbTx.AuthCvv2.Valid {
 		tx.AuthCVV2 = &dbTx.AuthCvv2.String
 	}
+	if dbTx.CardFundingType.Valid {
+		tx.CardFundingType = &dbTx.CardFundingType.String
+	}
+	if dbTx.LastFour.Valid {
+		tx.LastFour = &dbTx.LastFour.String
+	}
+	if dbTx.SettledAt.Valid {
+		settledAt := dbTx.SettledAt.Time
+		tx.SettledAt = &settledAt
+	}
+	if dbTx.FundingDate.Valid {
+		fundingDate := dbTx.FundingDate.Time
+		tx.FundingDate = &fundingDate
+	}
+	if dbTx.PendingExpiresAt.Valid {
+		pendingExpiresAt := dbTx.PendingExpiresAt.Time
+		tx.PendingExpiresAt = &pendingExpiresAt
+	}
+	if dbTx.RequestHash.Valid {
+		tx.RequestHash = dbTx.RequestHash.String
+	}
+	if dbTx.CardFingerprint.Valid {
+		tx.CardFingerprint = dbTx.CardFingerprint.String
+	}
 	if dbTx.IdempotencyKey.Valid {
 		tx.IdempotencyKey = &dbTx.IdempotencyKey.String
 	}
+	if dbTx.TranNbr.Valid {
+		tranNbr := strconv.FormatInt(dbTx.TranNbr.Int64, 10)
+		tx.TranNbr = &tranNbr
+	}
 
 	if len(dbTx.Metadata) > 0 {
 		if err := json.Unmarshal(dbTx.Metadata, &tx.Metadata); err != nil {
@@ -868,9 +2619,431 @@ func sqlcToDomain(dbTx *sqlc.Transaction) *domain.Transaction {
 		}
 	}
 
+	if len(dbTx.VerificationOutcome) > 0 {
+		var outcome domain.VerificationPolicyOutcome
+		if err := json.Unmarshal(dbTx.VerificationOutcome, &outcome); err == nil {
+			tx.VerificationOutcome = &outcome
+		}
+	}
+
+	if len(dbTx.ThreeDs) > 0 {
+		var threeDS domain.ThreeDSecure
+		if err := json.Unmarshal(dbTx.ThreeDs, &threeDS); err == nil {
+			tx.ThreeDS = &threeDS
+		}
+	}
+
 	return tx
 }
 
+// verificationOutcomeJSON evaluates the merchant's AVS/CVV policies against the gateway response
+// (nil when no policy is configured, leaving the column NULL)
+func verificationOutcomeJSON(config *domain.MerchantConfig, resp *adapterports.ServerPostResponse) []byte {
+	outcome := config.EvaluateVerification(resp.AuthAVS, resp.AuthCVV2)
+	if outcome == nil {
+		return nil
+	}
+	data, err := json.Marshal(outcome)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// applyThreeDS routes the merchant's 3-D Secure result into the EPX request. EPX takes the major version only
+// ("2"); the transaction status isn't sent, it's kept with the transaction as chargeback evidence.
+func applyThreeDS(epxReq *adapterports.ServerPostRequest, threeDS *domain.ThreeDSecure) {
+	if threeDS == nil {
+		return
+	}
+	epxReq.CAVV = nonEmpty(threeDS.CAVV)
+	epxReq.ECI = nonEmpty(threeDS.ECI)
+	epxReq.ThreeDSVersion = nonEmpty("2")
+	epxReq.DSTransactionID = nonEmpty(threeDS.DSTransactionID)
+}
+
+// statementDescriptor picks the descriptor EPX prints for a charge: the request's override when non-blank, else the
+// merchant's default. An override longer than 22 characters is rejected rather than cut short; other invalid characters are
+// sanitized. Returns nil when there's nothing to send, leaving EPX to print the DBA name.
+func statementDescriptor(override *string, agent *sqlc.AgentCredential) (*string, error) {
+	descriptor := agent.StatementDescriptor.String
+	if override != nil && strings.TrimSpace(*override) != "" {
+		if len(strings.TrimSpace(*override)) > domain.MaxStatementDescriptorLength {
+			return nil, fmt.Errorf("%w: at most %d characters", domain.ErrInvalidStatementDescriptor, domain.MaxStatementDescriptorLength)
+		}
+		descriptor = *override
+	}
+	return nonEmpty(domain.SanitizeStatementDescriptor(descriptor)), nil
+}
+
+// threeDSJSON is the stored 3-D Secure result (nil when 3DS wasn't run, leaving the column NULL)
+func threeDSJSON(threeDS *domain.ThreeDSecure) []byte {
+	if threeDS == nil {
+		return nil
+	}
+	data, err := json.Marshal(threeDS)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// merchantLogger scopes an operation's logs to the merchant it touches
+func merchantLogger(logger *zap.Logger, agent *sqlc.AgentCredential) *zap.Logger {
+	return logger.With(
+		zap.String("agent_id", agent.AgentID),
+		zap.String("data_region", agent.DataRegion),
+	)
+}
+
+// screenFraud asks the fraud scorer about a charge before it's sent to EPX. A decline returns ErrFraudDeclined;
+// a review verdict lets the charge through with fraud_review, fraud_score and fraud_reason added to the metadata
+// it's stored with (the returned map). A scorer error or an unknown decision isn't a verdict: the fraud policy
+// decides those charges instead.
+func (s *paymentService) screenFraud(ctx context.Context, log *zap.Logger, tx *adapterports.FraudContext) (map[string]interface{}, error) {
+	decision, score, reason, err := s.fraud.Score(ctx, tx)
+	if err != nil {
+		log.Warn("Fraud scoring failed, applying the fraud policy",
+			zap.Error(err),
+			zap.String("fraud_decision", string(s.fraudPolicy.OnScorerError)),
+		)
+		decision, reason = s.fraudPolicy.OnScorerError, "fraud scoring unavailable"
+	} else if !decision.Valid() {
+		log.Warn("Fraud scorer returned an unknown decision, applying the fraud policy",
+			zap.String("scorer_decision", string(decision)),
+			zap.String("fraud_decision", string(s.fraudPolicy.OnUnknownDecision)),
+		)
+		decision, reason = s.fraudPolicy.OnUnknownDecision, "unknown fraud decision"
+	}
+
+	switch decision {
+	case adapterports.FraudDecisionDecline:
+		log.Warn("Charge declined by fraud screening",
+			zap.Float64("fraud_score", score),
+			zap.String("fraud_reason", reason),
+		)
+		return nil, fmt.Errorf("%w: %s", domain.ErrFraudDeclined, reason)
+	case adapterports.FraudDecisionReview:
+		log.Warn("Charge flagged for fraud review",
+			zap.Float64("fraud_score", score),
+			zap.String("fraud_reason", reason),
+		)
+		metadata := make(map[string]interface{}, len(tx.Metadata)+3)
+		for k, v := range tx.Metadata {
+			metadata[k] = v
+		}
+		metadata["fraud_review"] = true
+		metadata["fraud_score"] = score
+		metadata["fraud_reason"] = reason
+		return metadata, nil
+	}
+	return tx.Metadata, nil
+}
+
+// reserveDailyVolume holds a payment's amount against the agent's daily volume limit
+func (s *paymentService) reserveDailyVolume(ctx context.Context, agentID string, config *domain.MerchantConfig, amount string) (*database.DailyVolumeReservation, error) {
+	parsed, err := decimal.NewFromString(amount)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidAmount, err)
+	}
+
+	return database.ReserveDailyVolume(ctx, s.db.Queries(), agentID, parsed, config.DailyVolumeLimit, time.Now())
+}
+
+// takeOverDailyVolume is the hold a request charges against once its slot is known. A slot taken over from a
+// stale reservation keeps the volume its first attempt held, so the hold the retry just took is released.
+func takeOverDailyVolume(ctx context.Context, q sqlc.Querier, log *zap.Logger, slot *transactionSlot, reservation *database.DailyVolumeReservation) *database.DailyVolumeReservation {
+	if slot.heldVolume == nil {
+		return reservation
+	}
+	if err := reservation.Release(ctx, q); err != nil {
+		log.Warn("Failed to release daily volume", zap.Error(err))
+	}
+	return slot.heldVolume
+}
+
+// gatewayNeverReached reports whether a gateway error was raised before the request went out (an invalid request),
+// so EPX can't have charged it. Any other error is ambiguous: the payment may have gone through.
+func gatewayNeverReached(err error) bool {
+	var paymentErr *pkgerrors.PaymentError
+	return errors.As(err, &paymentErr) && paymentErr.Category == pkgerrors.CategoryInvalidRequest
+}
+
+// releaseUnsentVolume releases the hold of a request that never reached EPX and clears it from the slot's pending
+// row, so a retry taking the row over reserves its own volume instead of adopting the released hold
+func releaseUnsentVolume(ctx context.Context, q sqlc.Querier, slot *transactionSlot, reservation *database.DailyVolumeReservation) error {
+	if err := reservation.Release(ctx, q); err != nil {
+		return err
+	}
+	if !slot.reserved {
+		return nil
+	}
+	return q.SetPendingDailyVolume(ctx, sqlc.SetPendingDailyVolumeParams{ID: slot.id})
+}
+
+// releaseUnsentDailyVolume runs releaseUnsentVolume after a failed sale or authorization whose request never
+// reached EPX; an ambiguous gateway error keeps its hold
+func (s *paymentService) releaseUnsentDailyVolume(ctx context.Context, log *zap.Logger, slot *transactionSlot, reservation *database.DailyVolumeReservation, gatewayErr error) {
+	if !gatewayNeverReached(gatewayErr) {
+		return
+	}
+	if err := withTxSpan(ctx, s.db, func(q sqlc.Querier) error {
+		return releaseUnsentVolume(ctx, q, slot, reservation)
+	}); err != nil {
+		log.Warn("Failed to release daily volume", zap.Error(err))
+	}
+}
+
+// checkAmountRange fails with ErrAmountOutOfRange if amount is outside the merchant's min/max transaction amount
+func checkAmountRange(config *domain.MerchantConfig, amount string) error {
+	parsed, err := decimal.NewFromString(amount)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidAmount, err)
+	}
+	return config.CheckAmount(parsed)
+}
+
+// velocityQueries counts a card's or customer's recent sales and authorizations
+type velocityQueries interface {
+	GetTransactionVelocity(ctx context.Context, arg sqlc.GetTransactionVelocityParams) (sqlc.GetTransactionVelocityRow, error)
+}
+
+// checkVelocity fails with ErrVelocityExceeded if one more attempt for amount would pass the merchant's card or
+// customer velocity limit. The card limit applies to payments with a card fingerprint (domain.CardFingerprint)
+// and the customer limit to payments with a customer_id.
+// Counts are read, not reserved, so concurrent attempts can overshoot a limit by the number in flight.
+func checkVelocity(ctx context.Context, q velocityQueries, config *domain.MerchantConfig, agentID, cardFingerprint string, customerID *string, amount string, now time.Time) error {
+	checkCard := config.CardVelocity.Enabled() && cardFingerprint != ""
+	checkCustomer := config.CustomerVelocity.Enabled() && customerID != nil && *customerID != ""
+	if !checkCard && !checkCustomer {
+		return nil
+	}
+
+	parsed, err := decimal.NewFromString(amount)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidAmount, err)
+	}
+
+	usage := func(limit domain.VelocityLimit, arg sqlc.GetTransactionVelocityParams) (domain.VelocityUsage, error) {
+		arg.AgentID = agentID
+		arg.Since = now.Add(-limit.Window())
+		row, err := q.GetTransactionVelocity(ctx, arg)
+		if err != nil {
+			return domain.VelocityUsage{}, fmt.Errorf("failed to count recent transactions: %w", err)
+		}
+		return domain.VelocityUsage{
+			Count:  int(row.TransactionCount),
+			Amount: decimal.NewFromBigInt(row.TotalAmount.Int, row.TotalAmount.Exp),
+		}, nil
+	}
+
+	if checkCard {
+		used, err := usage(config.CardVelocity, sqlc.GetTransactionVelocityParams{CardFingerprint: pgtype.Text{String: cardFingerprint, Valid: true}})
+		if err != nil {
+			return err
+		}
+		if err := config.CardVelocity.Check("card", used, parsed); err != nil {
+			return err
+		}
+	}
+	if checkCustomer {
+		used, err := usage(config.CustomerVelocity, sqlc.GetTransactionVelocityParams{CustomerID: pgtype.Text{String: *customerID, Valid: true}})
+		if err != nil {
+			return err
+		}
+		if err := config.CustomerVelocity.Check("customer", used, parsed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// savedCardFingerprint is a saved card's fingerprint, the same one a Browser Post payment with the card gets
+func savedCardFingerprint(pm *sqlc.CustomerPaymentMethod) string {
+	if pm.PaymentType != string(domain.PaymentMethodTypeCreditCard) {
+		return ""
+	}
+	return domain.CardFingerprint(pm.LastFour, int(pm.CardExpMonth.Int32), int(pm.CardExpYear.Int32), pm.PaymentToken)
+}
+
+// transactionSlot is the row an operation records its result in: a new transaction, or for a request with an
+// idempotency key the pending row that reserved the key before the EPX call
+type transactionSlot struct {
+	id       uuid.UUID
+	groupID  uuid.UUID
+	tranNbr  int64
+	reserved bool
+
+	// heldVolume is the daily volume a taken-over stale reservation recorded (nil for a new slot)
+	heldVolume *database.DailyVolumeReservation
+}
+
+// reserveTransaction assigns the transaction its ID and TRAN_NBR. A keyed request also inserts its pending row
+// now, so a concurrent request with the same key hits the unique index instead of reaching EPX a second time.
+// A stale reservation of the same request is taken over first, along with the TRAN_NBR its attempt sent. When
+// the key is taken, the request gets the recorded transaction as a replay, or ErrDuplicateIdempotencyKey while
+// the key's request is still running. q is only used before the insert, which may abort q's transaction.
+func (s *paymentService) reserveTransaction(ctx context.Context, q sqlc.Querier, pending sqlc.CreateTransactionParams, fingerprint string, includeTree *bool) (*transactionSlot, *domain.Transaction, error) {
+	slot := &transactionSlot{id: uuid.New(), groupID: pending.GroupID}
+
+	if pending.IdempotencyKey.Valid {
+		claimed, err := q.ClaimStalePendingTransaction(ctx, sqlc.ClaimStalePendingTransactionParams{
+			AgentID:        pending.AgentID,
+			IdempotencyKey: pending.IdempotencyKey,
+			RequestHash:    pending.RequestHash,
+			StaleBefore:    time.Now().Add(-pendingTransactionStaleAfter),
+		})
+		switch {
+		case err == nil:
+			s.logger.Warn("Taking over stale idempotency key reservation",
+				zap.String("transaction_id", claimed.ID.String()),
+			)
+			slot = &transactionSlot{
+				id:         claimed.ID,
+				groupID:    claimed.GroupID,
+				heldVolume: database.StoredDailyVolumeReservation(claimed.AgentID, claimed.DailyVolumeDate, claimed.Amount),
+			}
+			// A row whose request held no volume (it never reached EPX) records the retry's hold from now on
+			if slot.heldVolume == nil && pending.DailyVolumeDate.Valid {
+				if err := q.SetPendingDailyVolume(ctx, sqlc.SetPendingDailyVolumeParams{ID: claimed.ID, DailyVolumeDate: pending.DailyVolumeDate}); err != nil {
+					return nil, nil, fmt.Errorf("failed to record daily volume: %w", err)
+				}
+			}
+		case errors.Is(err, pgx.ErrNoRows):
+			pending.ID = slot.id
+			pending.Status = string(domain.TransactionStatusPending)
+			if _, err := q.CreateTransaction(ctx, pending); err != nil {
+				if !isUniqueViolation(err) {
+					return nil, nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+				}
+				key := pending.IdempotencyKey.String
+				replay, err := s.replayIdempotent(ctx, pending.AgentID, &key, fingerprint, includeTree)
+				if err != nil || replay != nil {
+					return nil, replay, err
+				}
+				// Another retry claimed the stale reservation first
+				return nil, nil, fmt.Errorf("%w: a request with this key is still being processed", domain.ErrDuplicateIdempotencyKey)
+			}
+		default:
+			return nil, nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+		slot.reserved = true
+	}
+
+	// Allocate the merchant's TRAN_NBR; a taken-over reservation gets the number its first attempt sent
+	tranNbr, err := database.AllocateTranNbr(ctx, q, pending.AgentID, slot.id)
+	if err != nil {
+		return nil, nil, err
+	}
+	slot.tranNbr = tranNbr
+	return slot, nil, nil
+}
+
+// record writes the operation's result to its slot, completing the reserved row or inserting a new one
+func (slot *transactionSlot) record(ctx context.Context, q sqlc.Querier, params sqlc.CreateTransactionParams) (sqlc.Transaction, error) {
+	params.ID = slot.id
+	params.GroupID = slot.groupID
+	params.TranNbr = pgtype.Int8{Int64: slot.tranNbr, Valid: true}
+	if !slot.reserved {
+		return q.CreateTransaction(ctx, params)
+	}
+	return q.CompletePendingTransaction(ctx, sqlc.CompletePendingTransactionParams{
+		ID:                  params.ID,
+		Status:              params.Status,
+		AuthGuid:            params.AuthGuid,
+		AuthResp:            params.AuthResp,
+		AuthCode:            params.AuthCode,
+		AuthRespText:        params.AuthRespText,
+		AuthCardType:        params.AuthCardType,
+		AuthAvs:             params.AuthAvs,
+		AuthCvv2:            params.AuthCvv2,
+		CardFundingType:     params.CardFundingType,
+		Metadata:            params.Metadata,
+		VerificationOutcome: params.VerificationOutcome,
+		TranNbr:             params.TranNbr,
+	})
+}
+
+// applyGatewayResponse copies EPX's result onto the transaction being recorded
+func applyGatewayResponse(params *sqlc.CreateTransactionParams, resp *adapterports.ServerPostResponse) {
+	params.AuthGuid = toNullableText(&resp.AuthGUID)
+	params.AuthResp = toNullableText(&resp.AuthResp)
+	params.AuthCode = toNullableText(&resp.AuthCode)
+	params.AuthRespText = toNullableText(&resp.AuthRespText)
+	params.AuthCardType = toNullableText(&resp.AuthCardType)
+	params.AuthAvs = toNullableText(&resp.AuthAVS)
+	params.AuthCvv2 = toNullableText(&resp.AuthCVV2)
+	params.CardFundingType = cardFundingTypeText(resp.CardFundingType)
+}
+
+// cardFundingTypeText is EPX's funding indicator as stored (NULL when EPX didn't return a known one)
+func cardFundingTypeText(code string) pgtype.Text {
+	fundingType := domain.ParseCardFundingType(code)
+	return pgtype.Text{String: string(fundingType), Valid: fundingType != ""}
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// resolveMerchantConfig returns the agent's effective config; unreadable overrides refuse the operation
+func resolveMerchantConfig(log *zap.Logger, agent *sqlc.AgentCredential) (*domain.MerchantConfig, error) {
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		log.Error("Refusing operation: merchant config overrides are invalid", zap.Error(err))
+		return nil, err
+	}
+	return config, nil
+}
+
+// Request fingerprints cover what the caller asked for, not what the merchant's defaults resolved it to.
+// Amounts are normalized so "10.5" and "10.50" are the same request.
+
+func saleFingerprint(req *ports.SaleRequest) string {
+	return domain.RequestFingerprint(string(domain.TransactionTypeCharge), req.AgentID, fingerprintAmount(req.Amount),
+		strings.ToUpper(req.Currency), stringOrEmpty(req.CustomerID), stringOrEmpty(req.PaymentMethodID), stringOrEmpty(req.PaymentToken))
+}
+
+func authorizeFingerprint(req *ports.AuthorizeRequest) string {
+	return domain.RequestFingerprint(string(domain.TransactionTypeAuth), req.AgentID, fingerprintAmount(req.Amount),
+		strings.ToUpper(req.Currency), stringOrEmpty(req.CustomerID), stringOrEmpty(req.PaymentMethodID), stringOrEmpty(req.PaymentToken))
+}
+
+func captureFingerprint(req *ports.CaptureRequest) string {
+	return domain.RequestFingerprint(string(domain.TransactionTypeCapture), req.TransactionID, fingerprintAmount(stringOrEmpty(req.Amount)))
+}
+
+func partialReversalFingerprint(req *ports.PartialReversalRequest) string {
+	return domain.RequestFingerprint(string(domain.TransactionTypeReversal), req.TransactionID, fingerprintAmount(req.Amount))
+}
+
+func incrementFingerprint(req *ports.IncrementAuthorizationRequest) string {
+	return domain.RequestFingerprint(string(domain.TransactionTypeIncrement), req.TransactionID, fingerprintAmount(req.Amount))
+}
+
+func voidFingerprint(req *ports.VoidRequest) string {
+	return domain.RequestFingerprint("void", req.TransactionID)
+}
+
+func refundFingerprint(req *ports.RefundRequest) string {
+	return domain.RequestFingerprint(string(domain.TransactionTypeRefund), req.TransactionID, fingerprintAmount(stringOrEmpty(req.Amount)))
+}
+
+// fingerprintAmount normalizes a decimal amount; anything unparseable is kept as is and fails validation later
+func fingerprintAmount(amount string) string {
+	parsed, err := decimal.NewFromString(strings.TrimSpace(amount))
+	if err != nil {
+		return amount
+	}
+	return parsed.String()
+}
+
+// requestHash is stored for keyed requests only; without a key there's no retry to compare against
+func requestHash(idempotencyKey *string, fingerprint string) pgtype.Text {
+	return pgtype.Text{String: fingerprint, Valid: idempotencyKey != nil}
+}
+
 func toNullableText(s *string) pgtype.Text {
 	if s == nil {
 		return pgtype.Text{Valid: false}
@@ -904,3 +3077,11 @@ func stringOrEmpty(s *string) string {
 	}
 	return *s
 }
+
+// nonEmpty returns a pointer to s, or nil when s is empty
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
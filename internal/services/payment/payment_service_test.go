@@ -0,0 +1,2839 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kevin07696/payment-service/internal/adapters/epx"
+	"github.com/kevin07696/payment-service/internal/adapters/fraud"
+	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	paymenthandler "github.com/kevin07696/payment-service/internal/handlers/payment"
+	"github.com/kevin07696/payment-service/internal/services/ports"
+	"github.com/kevin07696/payment-service/internal/services/webhook"
+	pkgerrors "github.com/kevin07696/payment-service/pkg/errors"
+	"github.com/kevin07696/payment-service/pkg/observability"
+	"github.com/kevin07696/payment-service/pkg/security"
+	paymentv1 "github.com/kevin07696/payment-service/proto/payment/v1"
+)
+
+// recordingPublisher records delivered events
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []*webhook.WebhookEvent
+	done   chan struct{}
+}
+
+func newRecordingPublisher() *recordingPublisher {
+	return &recordingPublisher{done: make(chan struct{}, 10)}
+}
+
+func (p *recordingPublisher) DeliverEvent(ctx context.Context, event *webhook.WebhookEvent) error {
+	p.mu.Lock()
+	p.events = append(p.events, event)
+	p.mu.Unlock()
+	p.done <- struct{}{}
+	return nil
+}
+
+func (p *recordingPublisher) waitForEvents(t *testing.T, n int) []*webhook.WebhookEvent {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-p.done:
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for webhook event %d", i+1)
+		}
+	}
+
+	// Give any unexpected extra deliveries a chance to show up
+	time.Sleep(20 * time.Millisecond)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*webhook.WebhookEvent(nil), p.events...)
+}
+
+func TestPublishTransactionEvent_CaptureEnqueuesOneCapturedEvent(t *testing.T) {
+	publisher := newRecordingPublisher()
+	service := &paymentService{events: publisher, logger: zap.NewNop()}
+
+	cardType := "V"
+	customerID := "customer-456"
+	capture := &domain.Transaction{
+		ID:           "22222222-2222-2222-2222-222222222222",
+		GroupID:      "33333333-3333-3333-3333-333333333333",
+		AgentID:      "test-agent-123",
+		CustomerID:   &customerID,
+		Amount:       decimal.RequireFromString("42.5"),
+		Currency:     "USD",
+		Status:       domain.TransactionStatusCompleted,
+		Type:         domain.TransactionTypeCapture,
+		AuthCardType: &cardType,
+	}
+
+	service.publishTransactionEvent(capture, "11111111-1111-1111-1111-111111111111")
+
+	events := publisher.waitForEvents(t, 1)
+	require.Len(t, events, 1)
+
+	event := events[0]
+	assert.Equal(t, webhook.EventPaymentCaptured, event.EventType)
+	assert.Equal(t, "test-agent-123", event.AgentID)
+	assert.Equal(t, capture.ID, event.Data["transaction_id"])
+	assert.Equal(t, "11111111-1111-1111-1111-111111111111", event.Data["parent_transaction_id"])
+	assert.Equal(t, "42.50", event.Data["amount"])
+	assert.Equal(t, "completed", event.Data["status"])
+	assert.Equal(t, "V", event.Data["card_type"])
+	assert.Equal(t, "customer-456", event.Data["customer_id"])
+}
+
+func TestPublishTransactionEvent_NoPublisherIsNoop(t *testing.T) {
+	service := &paymentService{logger: zap.NewNop()}
+
+	assert.NotPanics(t, func() {
+		service.publishTransactionEvent(&domain.Transaction{ID: "tx"}, "")
+	})
+}
+
+func TestTransactionEventType(t *testing.T) {
+	tests := []struct {
+		name   string
+		txType domain.TransactionType
+		status domain.TransactionStatus
+		want   string
+	}{
+		{"sale", domain.TransactionTypeCharge, domain.TransactionStatusCompleted, webhook.EventPaymentCaptured},
+		{"authorize", domain.TransactionTypeAuth, domain.TransactionStatusCompleted, webhook.EventPaymentAuthorized},
+		{"capture", domain.TransactionTypeCapture, domain.TransactionStatusCompleted, webhook.EventPaymentCaptured},
+		{"void", domain.TransactionTypeCharge, domain.TransactionStatusVoided, webhook.EventPaymentVoided},
+		{"partial reversal", domain.TransactionTypeReversal, domain.TransactionStatusCompleted, webhook.EventPaymentAuthorizationReduced},
+		{"declined reversal", domain.TransactionTypeReversal, domain.TransactionStatusFailed, webhook.EventPaymentFailed},
+		{"incremental auth", domain.TransactionTypeIncrement, domain.TransactionStatusCompleted, webhook.EventPaymentAuthorizationIncreased},
+		{"declined increment", domain.TransactionTypeIncrement, domain.TransactionStatusFailed, webhook.EventPaymentFailed},
+		{"refund", domain.TransactionTypeRefund, domain.TransactionStatusRefunded, webhook.EventPaymentRefunded},
+		{"declined sale", domain.TransactionTypeCharge, domain.TransactionStatusFailed, webhook.EventPaymentFailed},
+		{"declined refund", domain.TransactionTypeRefund, domain.TransactionStatusFailed, webhook.EventPaymentFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &domain.Transaction{Type: tt.txType, Status: tt.status}
+			assert.Equal(t, tt.want, transactionEventType(tx))
+		})
+	}
+}
+
+func TestRefund_MerchantSettlementPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []byte
+		wantErr   error
+	}{
+		{"policy on rejects unsettled capture", []byte(`{"require_settled_refund": true}`), domain.ErrTransactionNotSettled},
+		{"policy off allows unsettled capture", []byte(`{"require_settled_refund": false}`), nil},
+		{"no overrides defaults to off", nil, nil},
+		{"invalid overrides refuse the refund", []byte(`not json`), domain.ErrInvalidMerchantConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := testAgent("merchant-1")
+			agent.ConfigOverrides = tt.overrides
+			store := newFakeStore(agent)
+			sale := store.addTransaction("merchant-1", domain.TransactionTypeCharge, domain.TransactionStatusCompleted, "25.00")
+			gateway := &fakeEPX{}
+			svc := newStoreBackedService(t, store, gateway)
+
+			refund, err := svc.Refund(context.Background(), &ports.RefundRequest{TransactionID: sale.ID.String(), Reason: "customer request"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, gateway.requests(), "a refused refund never reaches EPX")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, domain.TransactionStatusRefunded, refund.Status)
+			assert.Len(t, gateway.requests(), 1)
+		})
+	}
+}
+
+func TestRefund_ConcurrentPartialRefundsHoldTheGroup(t *testing.T) {
+	store := newFakeStore(testAgent("merchant-1"))
+	sale := store.addTransaction("merchant-1", domain.TransactionTypeCharge, domain.TransactionStatusCompleted, "100.00")
+
+	// EPX holds every refund until released, so the second one has its chance to race the first
+	gateway := &fakeEPX{}
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+	svc := newStoreBackedService(t, store, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		gateway.ServeHTTP(w, r)
+	}))
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			amount := "60.00"
+			_, err := svc.Refund(context.Background(), &ports.RefundRequest{
+				TransactionID: sale.ID.String(),
+				Amount:        &amount,
+				Reason:        "customer request",
+			})
+			errs <- err
+		}()
+	}
+
+	<-arrived
+	select {
+	case <-arrived:
+		t.Error("a second refund reached EPX while the first held the group")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	var failures []error
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			failures = append(failures, err)
+		}
+	}
+	require.Len(t, failures, 1, "only one 60.00 refund fits in a 100.00 capture")
+	assert.ErrorIs(t, failures[0], domain.ErrAmountExceedsRefundable)
+	assert.Len(t, gateway.requests(), 1)
+}
+
+func TestCapture_RacingReversalHoldsTheGroup(t *testing.T) {
+	store := newFakeStore(testAgent("merchant-1"))
+	auth := store.addTransaction("merchant-1", domain.TransactionTypeAuth, domain.TransactionStatusCompleted, "100.00")
+
+	// EPX holds every call until released, so the reversal has its chance to race the capture
+	gateway := &fakeEPX{}
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+	svc := newStoreBackedService(t, store, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		gateway.ServeHTTP(w, r)
+	}))
+
+	errs := make(chan error, 2)
+	go func() {
+		amount := "60.00"
+		_, err := svc.Capture(context.Background(), &ports.CaptureRequest{TransactionID: auth.ID.String(), Amount: &amount})
+		errs <- err
+	}()
+	go func() {
+		_, err := svc.PartialReverseAuthorization(context.Background(), &ports.PartialReversalRequest{TransactionID: auth.ID.String(), Amount: "60.00"})
+		errs <- err
+	}()
+
+	<-arrived
+	select {
+	case <-arrived:
+		t.Error("a second follow-up reached EPX while the first held the group")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	var failures []error
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			failures = append(failures, err)
+		}
+	}
+	require.Len(t, failures, 1, "a 60.00 capture and a 60.00 reversal don't both fit in a 100.00 hold")
+	assert.Len(t, gateway.requests(), 1)
+}
+
+func TestVoid_ConcurrentVoidsHoldTheGroup(t *testing.T) {
+	store := newFakeStore(testAgent("merchant-1"))
+	auth := store.addTransaction("merchant-1", domain.TransactionTypeAuth, domain.TransactionStatusCompleted, "100.00")
+
+	// EPX holds every void until released, so the second one has its chance to race the first
+	gateway := &fakeEPX{}
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+	svc := newStoreBackedService(t, store, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		gateway.ServeHTTP(w, r)
+	}))
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := svc.Void(context.Background(), &ports.VoidRequest{TransactionID: auth.ID.String()})
+			errs <- err
+		}()
+	}
+
+	<-arrived
+	select {
+	case <-arrived:
+		t.Error("a second void reached EPX while the first held the group")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	var failures []error
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			failures = append(failures, err)
+		}
+	}
+	require.Len(t, failures, 1, "an auth is voided once")
+	assert.ErrorIs(t, failures[0], domain.ErrTransactionCannotBeVoided)
+	assert.Len(t, gateway.requests(), 1)
+}
+
+func TestIncrementAuthorization_GatedLikeACharge(t *testing.T) {
+	const incrementEnabled = `"allowed_transaction_types": ["auth", "capture", "charge", "refund", "increment"]`
+	tests := []struct {
+		name      string
+		overrides string
+		decision  adapterports.FraudDecision
+		wantErr   error
+	}{
+		{"not enabled for the merchant", `{}`, adapterports.FraudDecisionApprove, domain.ErrTransactionCannotBeIncremented},
+		{"over the daily volume limit", `{` + incrementEnabled + `, "daily_volume_limit": "20"}`, adapterports.FraudDecisionApprove, domain.ErrDailyVolumeLimitExceeded},
+		{"declined by fraud screening", `{` + incrementEnabled + `}`, adapterports.FraudDecisionDecline, domain.ErrFraudDeclined},
+		{"approved", `{` + incrementEnabled + `}`, adapterports.FraudDecisionApprove, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := testAgent("merchant-1")
+			agent.ConfigOverrides = []byte(tt.overrides)
+			store := newFakeStore(agent)
+			auth := store.addTransaction("merchant-1", domain.TransactionTypeAuth, domain.TransactionStatusCompleted, "80.00")
+			gateway := &fakeEPX{}
+			svc := newStoreBackedService(t, store, gateway)
+			scorer := &fakeFraudScorer{decision: tt.decision, reason: "new device"}
+			svc.fraud = scorer
+
+			increment, err := svc.IncrementAuthorization(context.Background(), &ports.IncrementAuthorizationRequest{
+				TransactionID: auth.ID.String(),
+				Amount:        "30.00",
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, gateway.requests(), "a refused increment never reaches EPX")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, domain.TransactionTypeIncrement, increment.Type)
+			require.Len(t, scorer.scored, 1)
+			assert.Equal(t, string(domain.TransactionTypeIncrement), scorer.scored[0].TransactionType)
+
+			var held decimal.Decimal
+			for _, total := range store.volume.totals {
+				held = held.Add(total)
+			}
+			assert.Equal(t, "30.00", held.StringFixed(2), "the increment is held against the daily volume")
+
+			forms := gateway.requests()
+			require.Len(t, forms, 1)
+			assert.Equal(t, string(adapterports.TransactionTypeIncrementalAuth), forms[0].Get("TRAN_TYPE"))
+		})
+	}
+}
+
+func TestAmountRange_ThroughSaleAndAuthorize(t *testing.T) {
+	tests := []struct {
+		name      string
+		tier      domain.MerchantTier
+		overrides []byte
+		amount    string
+		wantErr   error
+	}{
+		{"in range", domain.MerchantTierStandard, nil, "25.00", nil},
+		{"below the default minimum", domain.MerchantTierStandard, nil, "0.00", domain.ErrAmountOutOfRange},
+		{"above the tier maximum", domain.MerchantTierStandard, nil, "1000000.00", domain.ErrAmountOutOfRange},
+		{"higher tier allows more", domain.MerchantTierEnterprise, nil, "100000.00", nil},
+		{"override below the minimum", domain.MerchantTierStandard, []byte(`{"min_transaction_amount": "10"}`), "9.99", domain.ErrAmountOutOfRange},
+		{"override above the maximum", domain.MerchantTierEnterprise, []byte(`{"max_transaction_amount": "100"}`), "100.01", domain.ErrAmountOutOfRange},
+		{"in the overridden range", domain.MerchantTierStandard, []byte(`{"min_transaction_amount": "10", "max_transaction_amount": "100"}`), "100.00", nil},
+		{"malformed amount", domain.MerchantTierStandard, nil, "ten", domain.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := testAgent("merchant-1")
+			agent.Tier, agent.ConfigOverrides = string(tt.tier), tt.overrides
+			gateway := &fakeEPX{}
+			svc := newStoreBackedService(t, newFakeStore(agent), gateway)
+			ctx := context.Background()
+			token := "09LMQ886L2K2W11MPX1"
+
+			_, saleErr := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: tt.amount, Currency: "USD", PaymentToken: &token})
+			_, authErr := svc.Authorize(ctx, &ports.AuthorizeRequest{AgentID: "merchant-1", Amount: tt.amount, Currency: "USD", PaymentToken: &token})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, saleErr, tt.wantErr)
+				assert.ErrorIs(t, authErr, tt.wantErr)
+				assert.Empty(t, gateway.requests(), "never sent to EPX")
+				return
+			}
+			assert.NoError(t, saleErr)
+			assert.NoError(t, authErr)
+			assert.Len(t, gateway.requests(), 2)
+		})
+	}
+}
+
+func TestMerchantStatus_ThroughPaymentRPCs(t *testing.T) {
+	tests := []struct {
+		status    domain.MerchantStatus
+		saleErr   string // FailedPrecondition message, empty when the sale goes through
+		refundErr string
+		wantToEPX int
+	}{
+		{domain.MerchantStatusActive, "", "", 2},
+		{domain.MerchantStatusSuspended, "merchant is suspended", "", 1},
+		{domain.MerchantStatusClosed, "merchant is closed", "merchant is closed", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			agent := testAgent("merchant-1")
+			agent.Status = string(tt.status)
+			store := newFakeStore(agent)
+			sale := store.addTransaction("merchant-1", domain.TransactionTypeCharge, domain.TransactionStatusCompleted, "25.00")
+			gateway := &fakeEPX{}
+			handler := paymenthandler.NewHandler(newStoreBackedService(t, store, gateway), zap.NewNop())
+			ctx := context.Background()
+
+			_, err := handler.Sale(ctx, &paymentv1.SaleRequest{
+				AgentId:       "merchant-1",
+				Amount:        "10.00",
+				Currency:      "USD",
+				PaymentMethod: &paymentv1.SaleRequest_PaymentToken{PaymentToken: "09LMQ886L2K2W11MPX1"},
+			})
+			assertStatusError(t, err, tt.saleErr)
+
+			_, err = handler.Refund(ctx, &paymentv1.RefundRequest{TransactionId: sale.ID.String(), Reason: "customer request"})
+			assertStatusError(t, err, tt.refundErr)
+
+			assert.Len(t, gateway.requests(), tt.wantToEPX, "refused calls never reach EPX")
+		})
+	}
+}
+
+// assertStatusError checks err is FailedPrecondition with message, or nil when message is empty
+func assertStatusError(t *testing.T, err error, message string) {
+	t.Helper()
+	if message == "" {
+		assert.NoError(t, err)
+		return
+	}
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, message, status.Convert(err).Message())
+}
+
+func TestBuildStatusResults_MixedBatch(t *testing.T) {
+	completed := &domain.Transaction{ID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", Status: domain.TransactionStatusCompleted}
+	voided := &domain.Transaction{ID: "6ba7b811-9dad-11d1-80b4-00c04fd430c8", Status: domain.TransactionStatusVoided}
+
+	ids := []string{
+		voided.ID,
+		"00000000-0000-0000-0000-000000000001", // not found (or another merchant's)
+		"not-a-uuid",
+		"6BA7B810-9DAD-11D1-80B4-00C04FD430C8", // same as completed, different case
+	}
+
+	results := buildStatusResults(ids, []*domain.Transaction{completed, voided})
+
+	require.Len(t, results, 4)
+	for i, id := range ids {
+		assert.Equal(t, id, results[i].TransactionID, "results keep request order and IDs")
+	}
+
+	assert.True(t, results[0].Found())
+	assert.Equal(t, domain.TransactionStatusVoided, results[0].Transaction.Status)
+	assert.False(t, results[1].Found())
+	assert.False(t, results[2].Found())
+	assert.True(t, results[3].Found())
+	assert.Equal(t, domain.TransactionStatusCompleted, results[3].Transaction.Status)
+}
+
+func TestGetTransactionStatuses_Validation(t *testing.T) {
+	svc := &paymentService{logger: zap.NewNop()}
+
+	_, err := svc.GetTransactionStatuses(context.Background(), "", []string{"id"})
+	assert.ErrorIs(t, err, domain.ErrMissingRequiredField)
+
+	ids := make([]string, MaxTransactionStatusBatch+1)
+	_, err = svc.GetTransactionStatuses(context.Background(), "agent-1", ids)
+	assert.ErrorIs(t, err, domain.ErrBatchTooLarge)
+
+	results, err := svc.GetTransactionStatuses(context.Background(), "agent-1", []string{"bad-id"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Found())
+}
+
+func TestGatewayResult_ApprovalAndDecline(t *testing.T) {
+	tests := []struct {
+		name            string
+		resp            *adapterports.ServerPostResponse
+		wantApproved    bool
+		wantAVS         string
+		wantCVV         string
+		wantDeclineCode string
+		wantCategory    string
+		wantReason      string
+	}{
+		{
+			name: "approval",
+			resp: &adapterports.ServerPostResponse{
+				AuthResp: "00", AuthRespText: "APPROVAL", AuthCode: "057579", IsApproved: true,
+				AuthCardType: "V", AuthAVS: "Y", AuthCVV2: "M",
+			},
+			wantApproved: true,
+			wantAVS:      domain.VerificationMatch,
+			wantCVV:      domain.VerificationMatch,
+		},
+		{
+			name: "decline",
+			resp: &adapterports.ServerPostResponse{
+				AuthResp: "51", AuthRespText: "INSUFF FUNDS", IsApproved: false,
+				AuthCardType: "V", AuthAVS: "N", AuthCVV2: "N",
+				Decline: pkgerrors.NewPaymentError("EPX_51", "Insufficient funds", pkgerrors.CategoryInsufficientFunds, false),
+			},
+			wantApproved:    false,
+			wantAVS:         domain.VerificationNoMatch,
+			wantCVV:         domain.VerificationNoMatch,
+			wantDeclineCode: "EPX_51",
+			wantCategory:    "insufficient_funds",
+			wantReason:      "Insufficient funds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := gatewayResult(tt.resp, 120*time.Millisecond)
+
+			// Every operation gets the same fields, approved or not
+			assert.Equal(t, tt.resp.AuthResp, result.ResponseCode)
+			assert.Equal(t, tt.resp.AuthRespText, result.ResponseText)
+			assert.Equal(t, tt.resp.AuthCode, result.AuthCode)
+			assert.Equal(t, tt.wantApproved, result.Approved)
+			assert.Equal(t, "V", result.CardType)
+			assert.Equal(t, tt.resp.AuthAVS, result.AVSCode)
+			assert.Equal(t, tt.wantAVS, result.AVSResult)
+			assert.Equal(t, tt.resp.AuthCVV2, result.CVVCode)
+			assert.Equal(t, tt.wantCVV, result.CVVResult)
+			assert.Equal(t, tt.wantDeclineCode, result.DeclineCode)
+			assert.Equal(t, tt.wantCategory, result.DeclineCategory)
+			assert.Equal(t, tt.wantReason, result.DeclineReason)
+			assert.False(t, result.Retriable)
+			assert.Equal(t, 120*time.Millisecond, result.Latency)
+		})
+	}
+}
+
+func TestTransactionGatewayResult_FromStoredFields(t *testing.T) {
+	authResp, text, avs, cvv := "05", "DECLINED", "Z", "P"
+	tx := &domain.Transaction{AuthResp: &authResp, AuthRespText: &text, AuthAVS: &avs, AuthCVV2: &cvv}
+
+	result := tx.GatewayResult()
+	assert.Equal(t, "05", result.ResponseCode)
+	assert.False(t, result.Approved)
+	assert.Equal(t, domain.VerificationPartialMatch, result.AVSResult)
+	assert.Equal(t, domain.VerificationNotProcessed, result.CVVResult)
+	assert.Zero(t, result.Latency, "stored transactions have no gateway latency")
+
+	live := &domain.GatewayResult{ResponseCode: "00", Approved: true, Latency: time.Second}
+	tx.Gateway = live
+	assert.Same(t, live, tx.GatewayResult(), "results from the gateway call take precedence")
+}
+
+func TestIncludeTree(t *testing.T) {
+	yes, no := true, false
+	lean := domain.DefaultMerchantConfig(domain.MerchantTierStandard)
+	full := domain.DefaultMerchantConfig(domain.MerchantTierStandard)
+	full.IncludeTransactionTree = true
+
+	assert.False(t, includeTree(nil, lean), "lean by default")
+	assert.False(t, includeTree(nil, nil), "lean when the merchant config is unknown")
+	assert.True(t, includeTree(nil, full), "merchant default applies when the request doesn't say")
+	assert.True(t, includeTree(&yes, lean), "request flag wins")
+	assert.False(t, includeTree(&no, full), "request flag wins")
+}
+
+func TestVerificationOutcome_RecordedOnTransaction(t *testing.T) {
+	cvvMismatch := &adapterports.ServerPostResponse{
+		AuthResp: "00", AuthRespText: "APPROVAL", IsApproved: true,
+		AuthCardType: "V", AuthAVS: "Y", AuthCVV2: "N",
+	}
+
+	t.Run("strict CVV policy records a failed cvv check", func(t *testing.T) {
+		config, err := domain.ResolveStoredMerchantConfig(string(domain.MerchantTierStandard), []byte(`{"cvv_policy": "strict"}`))
+		require.NoError(t, err)
+
+		stored := verificationOutcomeJSON(config, cvvMismatch)
+		require.NotNil(t, stored)
+
+		tx := sqlcToDomain(&sqlc.Transaction{
+			Amount:              toNumeric(decimal.NewFromInt(10)),
+			AuthCvv2:            pgtype.Text{String: "N", Valid: true},
+			VerificationOutcome: stored,
+		})
+		require.NotNil(t, tx.VerificationOutcome)
+		assert.Equal(t, domain.PolicyResultFail, tx.VerificationOutcome.Result)
+		assert.Equal(t, []string{domain.VerificationCheckCVV}, tx.VerificationOutcome.FailedChecks)
+		assert.Equal(t, domain.VerificationPolicyStrict, tx.VerificationOutcome.CVVPolicy)
+		assert.Equal(t, "N", *tx.AuthCVV2, "raw code is kept separately")
+	})
+
+	t.Run("no policy leaves the outcome absent", func(t *testing.T) {
+		stored := verificationOutcomeJSON(domain.DefaultMerchantConfig(domain.MerchantTierStandard), cvvMismatch)
+		assert.Nil(t, stored)
+
+		tx := sqlcToDomain(&sqlc.Transaction{Amount: toNumeric(decimal.NewFromInt(10)), VerificationOutcome: stored})
+		assert.Nil(t, tx.VerificationOutcome)
+	})
+}
+
+func TestThreeDS_SentToEPXAndStoredOnTransaction(t *testing.T) {
+	store := newFakeStore(testAgent("merchant-1"))
+	gateway := &fakeEPX{}
+	svc := newStoreBackedService(t, store, gateway)
+	ctx := context.Background()
+	token := "09LMQ886L2K2W11MPX1"
+
+	threeDS := &domain.ThreeDSecure{
+		Version:           "2.2.0",
+		CAVV:              "AAABBEg0VhI0VniQEjRWAAAAAAA=",
+		ECI:               "05",
+		TransactionStatus: domain.ThreeDSStatusAuthenticated,
+		DSTransactionID:   "f25084f0-5b16-4c0a-ae5d-b24808a95e4b",
+	}
+	tx, err := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "49.99", Currency: "USD", PaymentToken: &token, ThreeDS: threeDS})
+	require.NoError(t, err)
+
+	require.Len(t, gateway.requests(), 1)
+	form := gateway.requests()[0]
+	assert.Equal(t, "2", form.Get("TDS_VER"), "EPX takes the major version")
+	assert.Equal(t, "05", form.Get("CAVV_RESP"))
+	assert.Equal(t, "AAABBEg0VhI0VniQEjRWAAAAAAA=", form.Get("CAVV_UCAF"))
+	assert.Equal(t, "f25084f0-5b16-4c0a-ae5d-b24808a95e4b", form.Get("DIRECTORY_SERVER_TRAN_ID"))
+	for _, undocumented := range []string{"CAVV", "ECI_IND", "TDS_TRAN_STATUS", "DS_TRAN_ID"} {
+		assert.NotContains(t, form, undocumented)
+	}
+
+	require.NotNil(t, tx.ThreeDS)
+	assert.Equal(t, *threeDS, *tx.ThreeDS, "the full result, status included, is kept as chargeback evidence")
+
+	t.Run("an invalid result never reaches EPX", func(t *testing.T) {
+		invalid := *threeDS
+		invalid.DSTransactionID = ""
+		_, err := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "49.99", Currency: "USD", PaymentToken: &token, ThreeDS: &invalid})
+		assert.ErrorIs(t, err, domain.ErrInvalidThreeDSecure)
+		assert.Len(t, gateway.requests(), 1)
+	})
+
+	t.Run("without 3DS nothing is sent or stored", func(t *testing.T) {
+		tx, err := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "12.00", Currency: "USD", PaymentToken: &token})
+		require.NoError(t, err)
+		form := gateway.requests()[len(gateway.requests())-1]
+		assert.NotContains(t, form, "TDS_VER")
+		assert.NotContains(t, form, "CAVV_UCAF")
+		assert.NotContains(t, form, "CAVV_RESP")
+		assert.Nil(t, tx.ThreeDS)
+	})
+}
+
+// TestProcessTransaction_CallerDeadlineCancelsSlowEPX tests that a caller deadline shorter than the configured EPX
+// timeout ends the EPX call at the caller's deadline and drops the connection to EPX
+func TestProcessTransaction_CallerDeadlineCancelsSlowEPX(t *testing.T) {
+	disconnected := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body) // The server notices a dropped connection once the body is read
+		select {
+		case <-time.After(10 * time.Second):
+		case <-r.Context().Done():
+			close(disconnected)
+		}
+	}))
+	defer srv.Close()
+
+	config := epx.DefaultServerPostConfig("sandbox")
+	config.BaseURL = srv.URL
+	config.Timeout = 30 * time.Second
+	svc := &paymentService{serverPost: epx.NewServerPostAdapter(config, zap.NewNop())}
+
+	// A client calling with a 2s gRPC deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	epxReq := &adapterports.ServerPostRequest{
+		CustNbr:         "9001",
+		MerchNbr:        "900300",
+		DBAnbr:          "2",
+		TerminalNbr:     "77",
+		TransactionType: adapterports.TransactionTypeSale,
+		Amount:          "20.00",
+		PaymentType:     adapterports.PaymentMethodTypeCreditCard,
+		AuthGUID:        "09LMQ886L2K2W11MPX1",
+		TranNbr:         "12345",
+		TranGroup:       uuid.New().String(),
+	}
+
+	start := time.Now()
+	_, err := svc.processTransaction(ctx, epxReq)
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.GreaterOrEqual(t, elapsed, 2*time.Second)
+	assert.Less(t, elapsed, 3*time.Second, "the caller's deadline wins over the 30s EPX timeout")
+
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("EPX request was still open after the caller's deadline")
+	}
+}
+
+func TestRefundRequest_TransactionTypeByPaymentMethod(t *testing.T) {
+	agent := &sqlc.AgentCredential{AgentID: "merchant-1", CustNbr: "9001", MerchNbr: "900300", DbaNbr: "2", TerminalNbr: "77"}
+	parent := func(pmType domain.PaymentMethodType) *domain.Transaction {
+		authGUID := "09LMQ886L2K2W11MPX1"
+		return &domain.Transaction{
+			ID:                uuid.New().String(),
+			GroupID:           uuid.New().String(),
+			Amount:            decimal.RequireFromString("40.00"),
+			PaymentMethodType: pmType,
+			AuthGUID:          &authGUID,
+		}
+	}
+
+	ach := refundRequest(agent, parent(domain.PaymentMethodTypeACH), decimal.RequireFromString("15.00"), 42)
+	assert.Equal(t, adapterports.TransactionTypeACHCredit, ach.TransactionType, "an ACH refund credits the account")
+	assert.Equal(t, adapterports.PaymentMethodTypeACH, ach.PaymentType)
+	assert.Equal(t, "09LMQ886L2K2W11MPX1", ach.AuthGUID)
+	assert.Equal(t, "15", ach.Amount)
+	assert.Equal(t, "42", ach.TranNbr)
+
+	card := refundRequest(agent, parent(domain.PaymentMethodTypeCreditCard), decimal.RequireFromString("40.00"), 43)
+	assert.Equal(t, adapterports.TransactionTypeRefund, card.TransactionType)
+	assert.Equal(t, adapterports.PaymentMethodTypeCreditCard, card.PaymentType)
+	assert.Equal(t, "9001", card.CustNbr)
+}
+
+func TestStatementDescriptor_SentToEPX(t *testing.T) {
+	agent := testAgent("merchant-1")
+	agent.StatementDescriptor = pgtype.Text{String: "ACME COFFEE", Valid: true}
+	store := newFakeStore(agent)
+	gateway := &fakeEPX{}
+	srv := httptest.NewServer(gateway)
+	t.Cleanup(srv.Close)
+	config := epx.DefaultServerPostConfig("sandbox")
+	config.BaseURL = srv.URL
+	config.SoftDescriptorField = "SOFT_DESCRIPTOR"
+	svc := NewPaymentService(store, epx.NewServerPostAdapter(config, zap.NewNop()), domain.EnvironmentSandbox,
+		fakeSecretManager{}, nil, nil, nil, FraudPolicy{}, zap.NewNop())
+	ctx := context.Background()
+	token := "09LMQ886L2K2W11MPX1"
+
+	sale := func(override *string) (url.Values, error) {
+		sent := len(gateway.requests())
+		_, err := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "12.50", Currency: "USD", PaymentToken: &token, StatementDescriptor: override})
+		if len(gateway.requests()) == sent {
+			return nil, err
+		}
+		return gateway.requests()[sent], err
+	}
+
+	t.Run("merchant default", func(t *testing.T) {
+		form, err := sale(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "ACME COFFEE", form.Get("SOFT_DESCRIPTOR"))
+
+		_, err = svc.Authorize(ctx, &ports.AuthorizeRequest{AgentID: "merchant-1", Amount: "12.50", Currency: "USD", PaymentToken: &token})
+		require.NoError(t, err)
+		requests := gateway.requests()
+		assert.Equal(t, "ACME COFFEE", requests[len(requests)-1].Get("SOFT_DESCRIPTOR"))
+	})
+
+	t.Run("per-transaction override", func(t *testing.T) {
+		override := "ACME*LATTE #12"
+		form, err := sale(&override)
+		require.NoError(t, err)
+		assert.Equal(t, "ACME LATTE 12", form.Get("SOFT_DESCRIPTOR"), "characters networks don't print are sanitized")
+
+		blank := " "
+		form, err = sale(&blank)
+		require.NoError(t, err)
+		assert.Equal(t, "ACME COFFEE", form.Get("SOFT_DESCRIPTOR"), "a blank override falls back to the default")
+	})
+
+	t.Run("over-length override is rejected", func(t *testing.T) {
+		override := "ACME COFFEE ROASTERS LLC"
+		form, err := sale(&override)
+		assert.ErrorIs(t, err, domain.ErrInvalidStatementDescriptor)
+		assert.Nil(t, form, "nothing is sent to EPX")
+	})
+
+	t.Run("not sent until the EPX field is configured", func(t *testing.T) {
+		unconfigured := newStoreBackedService(t, store, gateway)
+		sent := len(gateway.requests())
+		_, err := unconfigured.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "12.50", Currency: "USD", PaymentToken: &token})
+		require.NoError(t, err)
+		require.Len(t, gateway.requests(), sent+1)
+		assert.NotContains(t, gateway.requests()[sent], "SOFT_DESCRIPTOR")
+	})
+
+	t.Run("no descriptor sends nothing", func(t *testing.T) {
+		store.agents["merchant-1"] = testAgent("merchant-1")
+		form, err := sale(nil)
+		require.NoError(t, err)
+		assert.NotContains(t, form, "SOFT_DESCRIPTOR")
+	})
+}
+
+func TestCustomerPolicy_ThroughSaleAndAuthorize(t *testing.T) {
+	ctx := context.Background()
+	token, customerID, email := "09LMQ886L2K2W11MPX1", "cust-42", "jane@example.com"
+	policyAgent := func(policy domain.CustomerPolicy) sqlc.AgentCredential {
+		agent := testAgent("merchant-1")
+		agent.ConfigOverrides = []byte(fmt.Sprintf(`{"customer_policy": %q}`, policy))
+		return agent
+	}
+
+	t.Run("require_existing rejects an unknown customer before EPX", func(t *testing.T) {
+		store := newFakeStore(policyAgent(domain.CustomerPolicyRequireExisting))
+		gateway := &fakeEPX{}
+		svc := newStoreBackedService(t, store, gateway)
+
+		_, err := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", CustomerID: &customerID, Amount: "10.00", Currency: "USD", PaymentToken: &token})
+		assert.ErrorIs(t, err, domain.ErrCustomerNotFound)
+		_, err = svc.Authorize(ctx, &ports.AuthorizeRequest{AgentID: "merchant-1", CustomerID: &customerID, Amount: "10.00", Currency: "USD", PaymentToken: &token})
+		assert.ErrorIs(t, err, domain.ErrCustomerNotFound)
+		assert.Empty(t, gateway.requests())
+		assert.Empty(t, store.customers, "nothing is created for strict merchants")
+
+		_, _ = store.CreateCustomer(ctx, sqlc.CreateCustomerParams{AgentID: "merchant-1", CustomerID: customerID})
+		_, err = svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", CustomerID: &customerID, Amount: "10.00", Currency: "USD", PaymentToken: &token})
+		require.NoError(t, err)
+		assert.Len(t, gateway.requests(), 1)
+	})
+
+	t.Run("auto_create creates the customer the sale references", func(t *testing.T) {
+		store := newFakeStore(policyAgent(domain.CustomerPolicyAutoCreate))
+		svc := newStoreBackedService(t, store, &fakeEPX{})
+
+		tx, err := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", CustomerID: &customerID, CustomerEmail: &email, Amount: "10.00", Currency: "USD", PaymentToken: &token})
+		require.NoError(t, err)
+		require.NotNil(t, tx.CustomerID)
+		assert.Equal(t, customerID, *tx.CustomerID)
+
+		created, ok := store.customers["merchant-1/cust-42"]
+		require.True(t, ok)
+		assert.True(t, created.AutoCreated)
+		assert.Equal(t, email, created.Email.String)
+
+		_, err = svc.Authorize(ctx, &ports.AuthorizeRequest{AgentID: "merchant-1", CustomerID: &customerID, Amount: "10.00", Currency: "USD", PaymentToken: &token})
+		require.NoError(t, err)
+		assert.Len(t, store.customers, 1, "the authorization finds the created customer")
+	})
+}
+
+func TestMerchantLogger_IncludesDataRegion(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	agent := &sqlc.AgentCredential{AgentID: "merchant-eu", DataRegion: "eu"}
+
+	merchantLogger(zap.New(core), agent).Info("Sale transaction completed", zap.String("transaction_id", "tx-1"))
+
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, "eu", fields["data_region"])
+	assert.Equal(t, "merchant-eu", fields["agent_id"])
+	assert.Equal(t, "tx-1", fields["transaction_id"])
+}
+
+func TestSqlcToDomain_DataRegion(t *testing.T) {
+	// CreateTransaction stamps the merchant's region on the row it returns
+	created := &sqlc.Transaction{Amount: toNumeric(decimal.NewFromInt(10)), AgentID: "merchant-eu", DataRegion: "eu"}
+
+	tx := sqlcToDomain(created)
+	assert.Equal(t, "eu", tx.DataRegion)
+}
+
+// fakeTransactionPages applies the transaction listing queries' predicates and ordering to in-memory rows
+type fakeTransactionPages struct {
+	rows []sqlc.Transaction
+}
+
+func (f *fakeTransactionPages) insert(agentID string, createdAt time.Time) sqlc.Transaction {
+	row := sqlc.Transaction{
+		ID:        uuid.New(),
+		AgentID:   agentID,
+		Amount:    toNumeric(decimal.NewFromInt(10)),
+		CreatedAt: createdAt,
+	}
+	f.rows = append(f.rows, row)
+	return row
+}
+
+func newerFirst(a, b *sqlc.Transaction) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return bytes.Compare(a.ID[:], b.ID[:]) > 0
+}
+
+// matchesFilters mimics the metadata @> containment and inclusive amount bounds
+func matchesFilters(t *sqlc.Transaction, metadataContains []byte, minAmount, maxAmount pgtype.Numeric) bool {
+	if metadataContains != nil {
+		var want, have map[string]interface{}
+		if json.Unmarshal(metadataContains, &want) != nil || json.Unmarshal(t.Metadata, &have) != nil {
+			return false
+		}
+		for key, value := range want {
+			if have[key] != value {
+				return false
+			}
+		}
+	}
+	amount := decimal.NewFromBigInt(t.Amount.Int, t.Amount.Exp)
+	if minAmount.Valid && amount.LessThan(decimal.NewFromBigInt(minAmount.Int, minAmount.Exp)) {
+		return false
+	}
+	if maxAmount.Valid && amount.GreaterThan(decimal.NewFromBigInt(maxAmount.Int, maxAmount.Exp)) {
+		return false
+	}
+	return true
+}
+
+func (f *fakeTransactionPages) filter(agentID string, metadataContains []byte, minAmount, maxAmount pgtype.Numeric) []sqlc.Transaction {
+	var matched []sqlc.Transaction
+	for _, row := range f.rows {
+		if row.AgentID == agentID && matchesFilters(&row, metadataContains, minAmount, maxAmount) {
+			matched = append(matched, row)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return newerFirst(&matched[i], &matched[j]) })
+	return matched
+}
+
+func (f *fakeTransactionPages) ListTransactions(ctx context.Context, arg sqlc.ListTransactionsParams) ([]sqlc.Transaction, error) {
+	matched := f.filter(arg.AgentID.String, arg.MetadataContains, arg.MinAmount, arg.MaxAmount)
+	if int(arg.OffsetVal) >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[arg.OffsetVal:]
+	if len(matched) > int(arg.LimitVal) {
+		matched = matched[:arg.LimitVal]
+	}
+	return matched, nil
+}
+
+func (f *fakeTransactionPages) CountTransactions(ctx context.Context, arg sqlc.CountTransactionsParams) (int64, error) {
+	return int64(len(f.filter(arg.AgentID.String, arg.MetadataContains, arg.MinAmount, arg.MaxAmount))), nil
+}
+
+func (f *fakeTransactionPages) ListTransactionsAfterCursor(ctx context.Context, arg sqlc.ListTransactionsAfterCursorParams) ([]sqlc.Transaction, error) {
+	cursor := sqlc.Transaction{CreatedAt: arg.CursorCreatedAt.Time, ID: arg.CursorID.Bytes}
+
+	var matched []sqlc.Transaction
+	for _, row := range f.filter(arg.AgentID, arg.MetadataContains, arg.MinAmount, arg.MaxAmount) {
+		if arg.CursorCreatedAt.Valid && !newerFirst(&cursor, &row) {
+			continue
+		}
+		if (arg.CreatedFrom.Valid && row.CreatedAt.Before(arg.CreatedFrom.Time)) || (arg.CreatedTo.Valid && !row.CreatedAt.Before(arg.CreatedTo.Time)) {
+			continue
+		}
+		matched = append(matched, row)
+	}
+
+	if len(matched) > int(arg.LimitVal) {
+		matched = matched[:arg.LimitVal]
+	}
+	return matched, nil
+}
+
+func TestListTransactionPage_StableAcrossInserts(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeTransactionPages{}
+	base := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	// 7 transactions, three sharing a timestamp so the id tiebreaker matters
+	want := map[uuid.UUID]bool{}
+	for _, offset := range []int{0, 1, 2, 2, 2, 3, 4} {
+		want[store.insert("agent-1", base.Add(time.Duration(offset)*time.Minute)).ID] = true
+	}
+	store.insert("agent-2", base.Add(time.Minute)) // another merchant, never listed
+
+	var seen []*domain.Transaction
+	cursor := ""
+	for page := 0; ; page++ {
+		require.Less(t, page, 10, "pagination must terminate")
+
+		txs, next, err := listTransactionPage(ctx, store, "agent-1", domain.TransactionFilter{}, 3, cursor)
+		require.NoError(t, err)
+		seen = append(seen, txs...)
+
+		// New transactions arrive between pages; they sort before the cursor and must not shift later pages
+		store.insert("agent-1", base.Add(time.Hour+time.Duration(page)*time.Minute))
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	require.Len(t, seen, len(want), "every original transaction exactly once")
+	for i, tx := range seen {
+		id := uuid.MustParse(tx.ID)
+		assert.True(t, want[id], "only transactions that existed when iteration started")
+		delete(want, id)
+
+		if i > 0 {
+			prev := seen[i-1]
+			prevRow := sqlc.Transaction{ID: uuid.MustParse(prev.ID), CreatedAt: prev.CreatedAt}
+			row := sqlc.Transaction{ID: id, CreatedAt: tx.CreatedAt}
+			assert.True(t, newerFirst(&prevRow, &row), "newest first, id breaks ties")
+		}
+	}
+}
+
+func TestListTransactionPage_LastPageHasNoCursor(t *testing.T) {
+	store := &fakeTransactionPages{}
+	base := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	store.insert("agent-1", base)
+	store.insert("agent-1", base.Add(time.Minute))
+
+	txs, next, err := listTransactionPage(context.Background(), store, "agent-1", domain.TransactionFilter{}, 2, "")
+	require.NoError(t, err)
+	assert.Len(t, txs, 2)
+	assert.Empty(t, next, "exactly one full page is also the last page")
+
+	_, _, err = listTransactionPage(context.Background(), store, "agent-1", domain.TransactionFilter{}, 2, "not-a-cursor")
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
+
+func TestListTransactionPage_CreatedRange(t *testing.T) {
+	store := &fakeTransactionPages{}
+	day := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	store.insert("agent-1", day.Add(-time.Second))
+	inRange := store.insert("agent-1", day)
+	store.insert("agent-1", day.Add(24*time.Hour))
+
+	to := day.Add(24 * time.Hour)
+	txs, _, err := listTransactionPage(context.Background(), store, "agent-1", domain.TransactionFilter{CreatedFrom: &day, CreatedTo: &to}, 10, "")
+	require.NoError(t, err)
+	require.Len(t, txs, 1, "from is inclusive, to is exclusive")
+	assert.Equal(t, inRange.ID.String(), txs[0].ID)
+
+	_, _, err = listTransactionPage(context.Background(), store, "agent-1", domain.TransactionFilter{CreatedFrom: &to, CreatedTo: &day}, 10, "")
+	assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+}
+
+// fakeSettlementTotals returns fixed per-kind totals and records the range it was asked for
+type fakeSettlementTotals struct {
+	rows []sqlc.GetSettlementTotalsRow
+	args []sqlc.GetSettlementTotalsParams
+}
+
+func (f *fakeSettlementTotals) GetSettlementTotals(ctx context.Context, arg sqlc.GetSettlementTotalsParams) ([]sqlc.GetSettlementTotalsRow, error) {
+	f.args = append(f.args, arg)
+	return f.rows, nil
+}
+
+func TestSettlementSummary_MixedDay(t *testing.T) {
+	day := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	total := func(kind string, count int64, cents int64) sqlc.GetSettlementTotalsRow {
+		return sqlc.GetSettlementTotalsRow{
+			Day:              pgtype.Date{Time: day, Valid: true},
+			Currency:         "USD",
+			Kind:             kind,
+			TransactionCount: count,
+			TotalAmount:      pgtype.Numeric{Int: big.NewInt(cents), Exp: -2, Valid: true},
+		}
+	}
+	q := &fakeSettlementTotals{rows: []sqlc.GetSettlementTotalsRow{
+		total(domain.SettlementKindRefund, 1, 1500),
+		total(domain.SettlementKindSale, 3, 12000),
+		total(domain.SettlementKindVoid, 1, 2500),
+	}}
+
+	days, err := settlementSummary(context.Background(), q, "agent-1", day.Add(9*time.Hour), day.Add(9*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, days, 1)
+	assert.Equal(t, "2025-06-15", days[0].Date.Format(time.DateOnly))
+	assert.Equal(t, "120.00", days[0].GrossSales.StringFixed(2))
+	assert.Equal(t, "80.00", days[0].Net.StringFixed(2), "captures minus refunds minus voids")
+	assert.Equal(t, 5, days[0].TransactionCount())
+
+	require.Len(t, q.args, 1)
+	assert.Equal(t, "agent-1", q.args[0].AgentID)
+	assert.Equal(t, day, q.args[0].CreatedFrom)
+	assert.Equal(t, day.AddDate(0, 0, 1), q.args[0].CreatedTo, "the end date is inclusive")
+
+	_, err = settlementSummary(context.Background(), q, "agent-1", day, day.AddDate(0, 0, -1))
+	assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+	assert.Len(t, q.args, 1, "an invalid range never reaches the database")
+}
+
+func TestListTransactions_MetadataAndAmountFilters(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeTransactionPages{}
+	base := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	add := func(agentID, amount, metadata string, minutes int) uuid.UUID {
+		row := store.insert(agentID, base.Add(time.Duration(minutes)*time.Minute))
+		row.Amount = toNumeric(decimal.RequireFromString(amount))
+		row.Metadata = []byte(metadata)
+		store.rows[len(store.rows)-1] = row
+		return row.ID
+	}
+	order1001 := add("agent-1", "25.00", `{"order_id": "A-1001", "channel": "web"}`, 0)
+	order1001Refund := add("agent-1", "5.00", `{"order_id": "A-1001"}`, 1)
+	order1002 := add("agent-1", "99.99", `{"order_id": "A-1002", "channel": "web"}`, 2)
+	small := add("agent-1", "9.99", `{}`, 3)
+	add("agent-2", "25.00", `{"order_id": "A-1001"}`, 4) // another merchant, never listed
+
+	ids := func(txs []*domain.Transaction) []string {
+		out := make([]string, len(txs))
+		for i, tx := range txs {
+			out[i] = tx.ID
+		}
+		return out
+	}
+	cents := func(c int64) *int64 { return &c }
+
+	t.Run("metadata order_id", func(t *testing.T) {
+		filter := domain.TransactionFilter{MetadataContains: map[string]string{"order_id": "A-1001"}}
+		txs, total, err := listTransactions(ctx, store, "agent-1", filter, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, []string{order1001Refund.String(), order1001.String()}, ids(txs))
+	})
+
+	t.Run("every metadata pair must match", func(t *testing.T) {
+		filter := domain.TransactionFilter{MetadataContains: map[string]string{"order_id": "A-1001", "channel": "web"}}
+		txs, _, err := listTransactions(ctx, store, "agent-1", filter, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{order1001.String()}, ids(txs))
+	})
+
+	t.Run("amount range is inclusive", func(t *testing.T) {
+		filter := domain.TransactionFilter{MinAmountCents: cents(999), MaxAmountCents: cents(2500)}
+		txs, total, err := listTransactions(ctx, store, "agent-1", filter, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, []string{small.String(), order1001.String()}, ids(txs))
+	})
+
+	t.Run("minimum only", func(t *testing.T) {
+		txs, _, err := listTransactions(ctx, store, "agent-1", domain.TransactionFilter{MinAmountCents: cents(5000)}, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{order1002.String()}, ids(txs))
+	})
+
+	t.Run("cursor pages apply the same filters", func(t *testing.T) {
+		filter := domain.TransactionFilter{MetadataContains: map[string]string{"order_id": "A-1001"}}
+		first, next, err := listTransactionPage(ctx, store, "agent-1", filter, 1, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{order1001Refund.String()}, ids(first))
+
+		second, next, err := listTransactionPage(ctx, store, "agent-1", filter, 1, next)
+		require.NoError(t, err)
+		assert.Equal(t, []string{order1001.String()}, ids(second))
+		assert.Empty(t, next)
+	})
+
+	t.Run("inverted range is rejected", func(t *testing.T) {
+		_, _, err := listTransactions(ctx, store, "agent-1", domain.TransactionFilter{MinAmountCents: cents(500), MaxAmountCents: cents(100)}, 10, 0)
+		assert.ErrorIs(t, err, domain.ErrInvalidFilter)
+	})
+}
+
+// fakeTransactionsByID serves GetTransactionsByIDs from in-memory rows (in arbitrary order, like the query)
+type fakeTransactionsByID struct {
+	rows []sqlc.Transaction
+}
+
+func (f *fakeTransactionsByID) GetTransactionsByIDs(ctx context.Context, ids []uuid.UUID) ([]sqlc.Transaction, error) {
+	var matched []sqlc.Transaction
+	for i := len(f.rows) - 1; i >= 0; i-- {
+		for _, id := range ids {
+			if f.rows[i].ID == id {
+				matched = append(matched, f.rows[i])
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+func TestBatchGetTransactions_RequestOrder(t *testing.T) {
+	store := &fakeTransactionsByID{}
+	for _, agentID := range []string{"agent-1", "agent-1", "agent-2"} {
+		store.rows = append(store.rows, sqlc.Transaction{ID: uuid.New(), AgentID: agentID, Amount: toNumeric(decimal.NewFromInt(10))})
+	}
+	first, second, otherMerchant := store.rows[0].ID.String(), store.rows[1].ID.String(), store.rows[2].ID.String()
+
+	txs, err := batchGetTransactions(context.Background(), store, []string{second, "not-a-uuid", uuid.NewString(), otherMerchant, first})
+	require.NoError(t, err)
+	require.Len(t, txs, 3, "malformed and unknown IDs are skipped; access is checked by the caller")
+	assert.Equal(t, []string{second, otherMerchant, first}, []string{txs[0].ID, txs[1].ID, txs[2].ID})
+
+	tooMany := make([]string, MaxTransactionStatusBatch+1)
+	_, err = batchGetTransactions(context.Background(), store, tooMany)
+	assert.ErrorIs(t, err, domain.ErrBatchTooLarge)
+}
+
+// fakeDailyVolume mimics the counter queries: each upsert holds the row lock while it checks and increments
+type fakeDailyVolume struct {
+	mu     sync.Mutex
+	totals map[string]decimal.Decimal
+}
+
+func (f *fakeDailyVolume) key(agentID string, day pgtype.Date) string {
+	return agentID + "/" + day.Time.Format("2006-01-02")
+}
+
+func (f *fakeDailyVolume) ReserveDailyVolume(ctx context.Context, arg sqlc.ReserveDailyVolumeParams) (pgtype.Numeric, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := f.key(arg.AgentID, arg.VolumeDate)
+	next := f.totals[key].Add(decimal.NewFromBigInt(arg.Amount.Int, arg.Amount.Exp))
+	if next.GreaterThan(decimal.NewFromBigInt(arg.DailyLimit.Int, arg.DailyLimit.Exp)) {
+		return pgtype.Numeric{}, pgx.ErrNoRows
+	}
+	f.totals[key] = next
+	return toNumeric(next), nil
+}
+
+func (f *fakeDailyVolume) ReleaseDailyVolume(ctx context.Context, arg sqlc.ReleaseDailyVolumeParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := f.key(arg.AgentID, arg.VolumeDate)
+	f.totals[key] = decimal.Max(f.totals[key].Sub(decimal.NewFromBigInt(arg.Amount.Int, arg.Amount.Exp)), decimal.Zero)
+	return nil
+}
+
+// held is the volume held across all merchants and days
+func (f *fakeDailyVolume) held() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var total decimal.Decimal
+	for _, amount := range f.totals {
+		total = total.Add(amount)
+	}
+	return total.StringFixed(2)
+}
+
+// fakeSaleBatches applies ClaimSaleBatch's claim rules to in-memory batches and serves recorded sales by ID
+type fakeSaleBatches struct {
+	fakeTransactionsByID
+	batches map[string]*sqlc.SaleBatch
+}
+
+func (f *fakeSaleBatches) ClaimSaleBatch(ctx context.Context, arg sqlc.ClaimSaleBatchParams) (sqlc.SaleBatch, error) {
+	key := arg.AgentID + "/" + arg.IdempotencyKey
+	if existing, ok := f.batches[key]; ok {
+		if existing.CompletedAt.Valid || !existing.ClaimedAt.Before(arg.StaleBefore) ||
+			!existing.RequestHash.Valid || existing.RequestHash != arg.RequestHash {
+			return sqlc.SaleBatch{}, pgx.ErrNoRows
+		}
+		existing.ClaimedAt = time.Now()
+		return *existing, nil
+	}
+
+	batch := &sqlc.SaleBatch{ID: uuid.New(), AgentID: arg.AgentID, IdempotencyKey: arg.IdempotencyKey, RequestHash: arg.RequestHash, ClaimedAt: time.Now()}
+	f.batches[key] = batch
+	return *batch, nil
+}
+
+func (f *fakeSaleBatches) GetSaleBatch(ctx context.Context, arg sqlc.GetSaleBatchParams) (sqlc.SaleBatch, error) {
+	batch, ok := f.batches[arg.AgentID+"/"+arg.IdempotencyKey]
+	if !ok {
+		return sqlc.SaleBatch{}, pgx.ErrNoRows
+	}
+	return *batch, nil
+}
+
+func (f *fakeSaleBatches) CompleteSaleBatch(ctx context.Context, arg sqlc.CompleteSaleBatchParams) error {
+	for _, batch := range f.batches {
+		if batch.ID == arg.ID {
+			batch.Results = arg.Results
+			batch.CompletedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+func TestRunSaleBatch_RetryReturnsOriginalResults(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeSaleBatches{batches: map[string]*sqlc.SaleBatch{}}
+
+	var saleCalls int
+	runSale := func(ctx context.Context, req *ports.SaleRequest) (*domain.Transaction, error) {
+		saleCalls++
+		if req.PaymentToken == nil {
+			return nil, fmt.Errorf("%w: either payment_method_id or payment_token", domain.ErrMissingRequiredField)
+		}
+		if req.Amount == "40.00" {
+			return nil, fmt.Errorf("failed to get agent: dial tcp 10.0.3.7:5432: connection refused")
+		}
+		row := sqlc.Transaction{ID: uuid.New(), AgentID: req.AgentID, Amount: toNumeric(decimal.RequireFromString(req.Amount)), Status: string(domain.TransactionStatusCompleted)}
+		store.rows = append(store.rows, row)
+		return sqlcToDomain(&row), nil
+	}
+
+	token := "bric-token"
+	batchKey := "batch-2025-06-15-001"
+	newRequest := func() *ports.BatchSaleRequest {
+		return &ports.BatchSaleRequest{
+			AgentID:        "agent-1",
+			IdempotencyKey: &batchKey,
+			Items: []*ports.SaleRequest{
+				{Amount: "10.00", Currency: "USD", PaymentToken: &token},
+				{Amount: "20.00", Currency: "USD"}, // fails: no payment method
+				{Amount: "30.00", Currency: "USD", PaymentToken: &token},
+				{Amount: "40.00", Currency: "USD", PaymentToken: &token}, // fails: internal error
+			},
+		}
+	}
+
+	first, err := runSaleBatch(ctx, store, runSale, newRequest(), time.Now(), zap.NewNop())
+	require.NoError(t, err)
+	assert.False(t, first.Replayed)
+	require.Len(t, first.Items, 4)
+	assert.Equal(t, 4, saleCalls)
+	assert.Equal(t, "agent-1", first.Items[0].Transaction.AgentID, "items run for the batch's merchant")
+	assert.Nil(t, first.Items[1].Transaction)
+	assert.Equal(t, domain.ErrMissingRequiredField.Error(), first.Items[1].Error, "only the reason is reported")
+	assert.Equal(t, "sale failed", first.Items[3].Error, "internal errors aren't returned")
+
+	// The response was lost; the client retries the whole batch with the same key
+	retry, err := runSaleBatch(ctx, store, runSale, newRequest(), time.Now(), zap.NewNop())
+	require.NoError(t, err)
+	assert.True(t, retry.Replayed)
+	assert.Equal(t, 4, saleCalls, "no item is processed again")
+	require.Len(t, retry.Items, 4)
+	assert.Equal(t, first.Items[0].Transaction.ID, retry.Items[0].Transaction.ID)
+	assert.Equal(t, first.Items[1].Error, retry.Items[1].Error)
+	assert.Nil(t, retry.Items[1].Transaction)
+	assert.Equal(t, first.Items[2].Transaction.ID, retry.Items[2].Transaction.ID)
+
+	// Reusing the key for different sales is rejected, even with as many items
+	fewer := newRequest()
+	fewer.Items = fewer.Items[:2]
+	otherAmount := newRequest()
+	otherAmount.Items[2].Amount = "300.00"
+	otherCard := newRequest()
+	otherToken := "another-bric"
+	otherCard.Items[0].PaymentToken = &otherToken
+	for name, mismatched := range map[string]*ports.BatchSaleRequest{"fewer": fewer, "amount": otherAmount, "card": otherCard} {
+		_, err = runSaleBatch(ctx, store, runSale, mismatched, time.Now(), zap.NewNop())
+		assert.ErrorIs(t, err, domain.ErrIdempotencyKeyConflict, name)
+	}
+	assert.Equal(t, 4, saleCalls)
+
+	// Without a batch key every call runs its items
+	unkeyed := newRequest()
+	unkeyed.IdempotencyKey = nil
+	_, err = runSaleBatch(ctx, store, runSale, unkeyed, time.Now(), zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, 8, saleCalls)
+}
+
+func TestRunSaleBatch_InProgressClaim(t *testing.T) {
+	ctx := context.Background()
+	batchKey := "batch-1"
+	req := &ports.BatchSaleRequest{AgentID: "agent-1", IdempotencyKey: &batchKey, Items: []*ports.SaleRequest{{Amount: "1.00"}}}
+	store := &fakeSaleBatches{batches: map[string]*sqlc.SaleBatch{
+		"agent-1/" + batchKey: {
+			ID: uuid.New(), AgentID: "agent-1", IdempotencyKey: batchKey, ClaimedAt: time.Now(),
+			RequestHash: pgtype.Text{String: saleBatchFingerprint(req), Valid: true},
+		},
+	}}
+
+	var saleCalls int
+	runSale := func(ctx context.Context, req *ports.SaleRequest) (*domain.Transaction, error) {
+		saleCalls++
+		return &domain.Transaction{ID: uuid.NewString()}, nil
+	}
+
+	_, err := runSaleBatch(ctx, store, runSale, req, time.Now(), zap.NewNop())
+	assert.ErrorIs(t, err, domain.ErrSaleBatchInProgress, "a concurrent retry doesn't run the batch twice")
+	assert.Zero(t, saleCalls)
+
+	// Other sales under the key don't take over the abandoned claim
+	other := &ports.BatchSaleRequest{AgentID: "agent-1", IdempotencyKey: &batchKey, Items: []*ports.SaleRequest{{Amount: "2.00"}}}
+	_, err = runSaleBatch(ctx, store, runSale, other, time.Now().Add(saleBatchStaleAfter+time.Minute), zap.NewNop())
+	assert.ErrorIs(t, err, domain.ErrIdempotencyKeyConflict)
+	assert.Zero(t, saleCalls)
+
+	// A claim abandoned past the stale cutoff is taken over
+	result, err := runSaleBatch(ctx, store, runSale, req, time.Now().Add(saleBatchStaleAfter+time.Minute), zap.NewNop())
+	require.NoError(t, err)
+	assert.False(t, result.Replayed)
+	assert.Equal(t, 1, saleCalls)
+}
+
+func TestClassifyDeclineCode_MatchesPaymentFlowMapping(t *testing.T) {
+	svc := &paymentService{serverPost: epx.NewServerPostAdapter(epx.DefaultServerPostConfig("sandbox"), zap.NewNop())}
+
+	tests := []struct {
+		authResp      string
+		wantApproved  bool
+		wantCategory  string
+		wantSeverity  string
+		wantRetriable bool
+	}{
+		{"00", true, "", "", false},
+		{"05", false, "declined", domain.DeclineSeverityHard, false},
+		{"51", false, "insufficient_funds", domain.DeclineSeverityHard, false},
+		{"54", false, "expired_card", domain.DeclineSeverityHard, false},
+		{"43", false, "fraud", domain.DeclineSeverityHard, false},
+		{"91", false, "system_error", domain.DeclineSeveritySoft, true},
+		{"96", false, "system_error", domain.DeclineSeveritySoft, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.authResp, func(t *testing.T) {
+			result, err := svc.ClassifyDeclineCode(context.Background(), tt.authResp, "ISSUER TEXT")
+			require.NoError(t, err)
+
+			// Same fields a Sale with this response would record
+			flow := gatewayResult(&adapterports.ServerPostResponse{
+				AuthResp:     tt.authResp,
+				AuthRespText: "ISSUER TEXT",
+				IsApproved:   tt.authResp == "00",
+				Decline:      epx.MapResponseCode(tt.authResp, "ISSUER TEXT"),
+			}, 0)
+			assert.Equal(t, flow, result)
+
+			assert.Equal(t, tt.wantApproved, result.Approved)
+			assert.Equal(t, tt.wantCategory, result.DeclineCategory)
+			assert.Equal(t, tt.wantSeverity, result.DeclineSeverity())
+			assert.Equal(t, tt.wantRetriable, result.Retriable)
+		})
+	}
+
+	_, err := svc.ClassifyDeclineCode(context.Background(), "", "")
+	assert.ErrorIs(t, err, domain.ErrMissingRequiredField)
+}
+
+type fakeSecretManager struct {
+	adapterports.SecretManagerAdapter
+}
+
+func (fakeSecretManager) GetSecret(ctx context.Context, path string) (*adapterports.Secret, error) {
+	return &adapterports.Secret{Value: "mac-secret"}, nil
+}
+
+func TestSaleTracing_NestsSecretGatewayAndDatabaseSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	var traceparent string
+	gateway := &fakeEPX{}
+	svc := newStoreBackedService(t, newFakeStore(testAgent("merchant-1")), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		gateway.ServeHTTP(w, r)
+	}))
+
+	sale := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return svc.Sale(ctx, req.(*ports.SaleRequest))
+	}
+
+	token := "09LMQ886L2K2W11MPX1"
+	info := &grpc.UnaryServerInfo{FullMethod: "/payment.v1.PaymentService/Sale"}
+	resp, err := observability.TracingInterceptor()(context.Background(), &ports.SaleRequest{
+		AgentID:      "merchant-1",
+		Amount:       "10.00",
+		Currency:     "USD",
+		PaymentToken: &token,
+	}, info, sale)
+	require.NoError(t, err)
+	assert.True(t, resp.(*domain.Transaction).IsApproved())
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	require.Len(t, spans, 4)
+
+	root, ok := spans[info.FullMethod]
+	require.True(t, ok)
+	assert.False(t, root.Parent.IsValid(), "RPC span is the trace root")
+
+	for _, name := range []string{"secretmanager.GetSecret", "epx.ProcessTransaction", "db.WithTx"} {
+		span, ok := spans[name]
+		require.True(t, ok, name)
+		assert.Equal(t, root.SpanContext.TraceID(), span.SpanContext.TraceID(), name)
+		assert.Equal(t, root.SpanContext.SpanID(), span.Parent.SpanID(), "%s is a child of the RPC span", name)
+	}
+
+	// EPX receives the gateway span as the caller's parent
+	gatewaySpan := spans["epx.ProcessTransaction"]
+	assert.Equal(t, fmt.Sprintf("00-%s-%s-01", gatewaySpan.SpanContext.TraceID(), gatewaySpan.SpanContext.SpanID()), traceparent)
+	assert.Len(t, gateway.requests(), 1)
+}
+
+func TestResolveCaptureAmount_BoundedByReducedAuthorization(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	auth := &domain.Transaction{ID: "auth", Type: domain.TransactionTypeAuth, Status: domain.TransactionStatusCompleted, Amount: decimal.NewFromInt(100), CreatedAt: t0}
+	reversal := &domain.Transaction{ID: "reversal", Type: domain.TransactionTypeReversal, Status: domain.TransactionStatusCompleted, Amount: decimal.NewFromInt(40), CreatedAt: t0.Add(time.Minute)}
+	state := domain.BuildTransactionTree([]*domain.Transaction{auth, reversal}).State
+	amount := func(s string) *string { return &s }
+
+	t.Run("defaults to the reduced authorization", func(t *testing.T) {
+		got, err := resolveCaptureAmount(state, nil)
+		require.NoError(t, err)
+		assert.True(t, got.Equal(decimal.NewFromInt(60)))
+	})
+
+	t.Run("captures up to the reduced amount", func(t *testing.T) {
+		got, err := resolveCaptureAmount(state, amount("60.00"))
+		require.NoError(t, err)
+		assert.True(t, got.Equal(decimal.NewFromInt(60)))
+	})
+
+	t.Run("rejects a capture above the reduced amount", func(t *testing.T) {
+		_, err := resolveCaptureAmount(state, amount("60.01"))
+		assert.ErrorIs(t, err, domain.ErrAmountExceedsAuthorization)
+
+		_, err = resolveCaptureAmount(state, amount("100.00"))
+		assert.ErrorIs(t, err, domain.ErrAmountExceedsAuthorization, "the original auth amount no longer applies")
+	})
+
+	t.Run("nothing left to capture", func(t *testing.T) {
+		capture := &domain.Transaction{ID: "capture", Type: domain.TransactionTypeCapture, Status: domain.TransactionStatusCompleted, Amount: decimal.NewFromInt(60), CreatedAt: t0.Add(2 * time.Minute)}
+		captured := domain.BuildTransactionTree([]*domain.Transaction{auth, reversal, capture}).State
+
+		_, err := resolveCaptureAmount(captured, nil)
+		assert.ErrorIs(t, err, domain.ErrTransactionCannotBeCaptured)
+	})
+}
+
+func TestResolveRefundAmount_SumsEarlierRefunds(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	sale := &domain.Transaction{ID: "sale", Type: domain.TransactionTypeCharge, Status: domain.TransactionStatusCompleted, Amount: decimal.NewFromInt(100), CreatedAt: t0}
+	refund := func(id string, amount int64, at time.Duration) *domain.Transaction {
+		return &domain.Transaction{ID: id, Type: domain.TransactionTypeRefund, Status: domain.TransactionStatusRefunded, Amount: decimal.NewFromInt(amount), CreatedAt: t0.Add(at)}
+	}
+	amount := func(s string) *string { return &s }
+
+	state := domain.BuildTransactionTree([]*domain.Transaction{sale}).State
+	got, err := resolveRefundAmount(state, sale, amount("70.00"))
+	require.NoError(t, err)
+	assert.True(t, got.Equal(decimal.NewFromInt(70)))
+
+	state = domain.BuildTransactionTree([]*domain.Transaction{sale, refund("refund-1", 70, time.Minute)}).State
+	got, err = resolveRefundAmount(state, sale, amount("30.00"))
+	require.NoError(t, err, "the second partial refund brings the total to exactly the captured amount")
+	assert.True(t, got.Equal(decimal.NewFromInt(30)))
+
+	_, err = resolveRefundAmount(state, sale, amount("30.01"))
+	assert.ErrorIs(t, err, domain.ErrAmountExceedsRefundable)
+
+	got, err = resolveRefundAmount(state, sale, nil)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(decimal.NewFromInt(30)), "a full refund takes what's left")
+
+	state = domain.BuildTransactionTree([]*domain.Transaction{sale, refund("refund-1", 70, time.Minute), refund("refund-2", 30, 2*time.Minute)}).State
+	_, err = resolveRefundAmount(state, sale, amount("1.00"))
+	assert.ErrorIs(t, err, domain.ErrTransactionCannotBeRefunded, "a third refund is rejected")
+	_, err = resolveRefundAmount(state, sale, nil)
+	assert.ErrorIs(t, err, domain.ErrTransactionCannotBeRefunded)
+}
+
+func TestAuditEntry_SaleOmitsSensitiveFields(t *testing.T) {
+	svc := &paymentService{auditPolicy: security.DefaultRedactionPolicy()}
+
+	customerID := "cust-1"
+	token := "0V703LH1HDL006J74W1"
+	req := &ports.SaleRequest{
+		AgentID:      "merchant-1",
+		CustomerID:   &customerID,
+		Amount:       "29.99",
+		Currency:     "USD",
+		PaymentToken: &token,
+		Metadata: map[string]interface{}{
+			"order_id":    "order-42",
+			"card_number": "4111111111111111",
+			"cvv":         "123",
+			"note":        "card 4111 1111 1111 1111",
+		},
+	}
+	authResp := "00"
+	tx := &domain.Transaction{
+		ID:       "tx-1",
+		GroupID:  "group-1",
+		AgentID:  "merchant-1",
+		Amount:   decimal.RequireFromString("29.99"),
+		Currency: "USD",
+		Type:     domain.TransactionTypeCharge,
+		Status:   domain.TransactionStatusCompleted,
+		AuthGUID: &token,
+		AuthResp: &authResp,
+	}
+
+	entry := svc.auditEntry(domain.AuditActionSale, "service-1", req.AgentID, req, tx, nil)
+
+	assert.Equal(t, "payment.sale", entry.EventType)
+	assert.Equal(t, "tx-1", entry.EntityID)
+	assert.Equal(t, "merchant-1", entry.AgentID)
+	assert.Equal(t, "service-1", entry.Actor)
+
+	for _, payload := range []json.RawMessage{entry.Changes, entry.Metadata} {
+		assert.NotContains(t, string(payload), "4111111111111111")
+		assert.NotContains(t, string(payload), "4111 1111 1111 1111")
+		assert.NotContains(t, string(payload), `"123"`)
+		assert.NotContains(t, string(payload), token, "full BRICs are masked")
+	}
+
+	var changes map[string]interface{}
+	require.NoError(t, json.Unmarshal(entry.Changes, &changes))
+	assert.Equal(t, "29.99", changes["amount"])
+	assert.Equal(t, "completed", changes["status"])
+	assert.Equal(t, "***************74W1", changes["auth_guid"])
+
+	var metadata struct {
+		Request map[string]interface{} `json:"request"`
+		Outcome map[string]interface{} `json:"outcome"`
+	}
+	require.NoError(t, json.Unmarshal(entry.Metadata, &metadata))
+	assert.Equal(t, "merchant-1", metadata.Request["AgentID"])
+	assert.Equal(t, "29.99", metadata.Request["Amount"])
+	assert.Equal(t, "success", metadata.Outcome["result"])
+	assert.Equal(t, "00", metadata.Outcome["auth_resp"])
+	assert.Equal(t, map[string]interface{}{"order_id": "order-42", "note": security.RedactedValue}, metadata.Request["Metadata"])
+}
+
+// fakeTranNbrStore keeps per-merchant counters and allocated numbers in memory.
+// racedBy, when set, is assigned to the next transaction just before AssignTranNbr, as a concurrent retry would.
+type fakeTranNbrStore struct {
+	counters map[string]int64
+	assigned map[uuid.UUID]sqlc.EpxTranNbr
+	racedBy  *sqlc.EpxTranNbr
+}
+
+func newFakeTranNbrStore() *fakeTranNbrStore {
+	return &fakeTranNbrStore{counters: make(map[string]int64), assigned: make(map[uuid.UUID]sqlc.EpxTranNbr)}
+}
+
+func (f *fakeTranNbrStore) GetTranNbr(ctx context.Context, transactionID uuid.UUID) (sqlc.EpxTranNbr, error) {
+	row, ok := f.assigned[transactionID]
+	if !ok {
+		return sqlc.EpxTranNbr{}, pgx.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeTranNbrStore) NextTranNbr(ctx context.Context, agentID string) (int64, error) {
+	f.counters[agentID]++
+	return f.counters[agentID], nil
+}
+
+func (f *fakeTranNbrStore) AssignTranNbr(ctx context.Context, arg sqlc.AssignTranNbrParams) (sqlc.EpxTranNbr, error) {
+	if f.racedBy != nil {
+		f.assigned[arg.TransactionID] = *f.racedBy
+		f.racedBy = nil
+	}
+	if _, ok := f.assigned[arg.TransactionID]; ok {
+		return sqlc.EpxTranNbr{}, pgx.ErrNoRows
+	}
+	row := sqlc.EpxTranNbr{TransactionID: arg.TransactionID, AgentID: arg.AgentID, TranNbr: arg.TranNbr}
+	f.assigned[arg.TransactionID] = row
+	return row, nil
+}
+
+func TestIdempotentReplay_RequestFingerprint(t *testing.T) {
+	key := "order-123"
+	method := "pm-1"
+	original := &ports.SaleRequest{AgentID: "merchant-1", Amount: "10.50", Currency: "usd", PaymentMethodID: &method, IdempotencyKey: &key}
+
+	// The stored transaction carries the first request's fingerprint
+	stored := sqlcToDomain(&sqlc.Transaction{
+		ID:             uuid.New(),
+		Amount:         toNumeric(decimal.RequireFromString("10.50")),
+		IdempotencyKey: toNullableText(&key),
+		RequestHash:    requestHash(original.IdempotencyKey, saleFingerprint(original)),
+	})
+	require.NotEmpty(t, stored.RequestHash)
+
+	t.Run("matching retry returns the existing transaction", func(t *testing.T) {
+		retry := *original
+		retry.Amount, retry.Currency = "10.5", "USD"
+		assert.NoError(t, stored.CheckReplay(saleFingerprint(&retry)))
+	})
+
+	t.Run("conflicting retry is rejected", func(t *testing.T) {
+		for name, change := range map[string]func(r *ports.SaleRequest){
+			"amount":         func(r *ports.SaleRequest) { r.Amount = "105.00" },
+			"merchant":       func(r *ports.SaleRequest) { r.AgentID = "merchant-2" },
+			"payment method": func(r *ports.SaleRequest) { other := "pm-2"; r.PaymentMethodID = &other },
+		} {
+			retry := *original
+			change(&retry)
+			assert.ErrorIs(t, stored.CheckReplay(saleFingerprint(&retry)), domain.ErrIdempotencyKeyConflict, name)
+		}
+		auth := &ports.AuthorizeRequest{AgentID: original.AgentID, Amount: original.Amount, Currency: original.Currency, PaymentMethodID: &method}
+		assert.ErrorIs(t, stored.CheckReplay(authorizeFingerprint(auth)), domain.ErrIdempotencyKeyConflict, "same key for a different operation")
+	})
+
+	t.Run("refund amounts", func(t *testing.T) {
+		full := refundFingerprint(&ports.RefundRequest{TransactionID: "tx-1"})
+		partial := "5.00"
+		assert.NotEqual(t, full, refundFingerprint(&ports.RefundRequest{TransactionID: "tx-1", Amount: &partial}))
+		assert.Equal(t, full, refundFingerprint(&ports.RefundRequest{TransactionID: "tx-1", Reason: "customer request"}), "the reason isn't part of the request's effect")
+	})
+
+	t.Run("unkeyed and older transactions", func(t *testing.T) {
+		assert.False(t, requestHash(nil, saleFingerprint(original)).Valid, "nothing stored without a key")
+		legacy := sqlcToDomain(&sqlc.Transaction{Amount: toNumeric(decimal.NewFromInt(10)), IdempotencyKey: toNullableText(&key)})
+		assert.NoError(t, legacy.CheckReplay(saleFingerprint(original)), "rows from before fingerprints replay as before")
+	})
+}
+
+func TestIdempotentReplay_ThroughRPCs(t *testing.T) {
+	store := newFakeStore(testAgent("merchant-1"))
+	gateway := &fakeEPX{}
+	svc := newStoreBackedService(t, store, gateway)
+	ctx := context.Background()
+	token, key := "09LMQ886L2K2W11MPX1", "order-123"
+
+	first, err := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "10.50", Currency: "USD", PaymentToken: &token, IdempotencyKey: &key})
+	require.NoError(t, err)
+
+	replay, err := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "10.5", Currency: "usd", PaymentToken: &token, IdempotencyKey: &key})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, replay.ID)
+	assert.Len(t, gateway.requests(), 1, "a replay never reaches EPX")
+
+	_, err = svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "99.00", Currency: "USD", PaymentToken: &token, IdempotencyKey: &key})
+	assert.ErrorIs(t, err, domain.ErrIdempotencyKeyConflict)
+
+	auth := store.addTransaction("merchant-1", domain.TransactionTypeAuth, domain.TransactionStatusCompleted, "40.00")
+	captureKey := "capture-1"
+	capture, err := svc.Capture(ctx, &ports.CaptureRequest{TransactionID: auth.ID.String(), IdempotencyKey: &captureKey})
+	require.NoError(t, err)
+	again, err := svc.Capture(ctx, &ports.CaptureRequest{TransactionID: auth.ID.String(), IdempotencyKey: &captureKey})
+	require.NoError(t, err)
+	assert.Equal(t, capture.ID, again.ID)
+
+	partial := "5.00"
+	_, err = svc.Capture(ctx, &ports.CaptureRequest{TransactionID: auth.ID.String(), Amount: &partial, IdempotencyKey: &captureKey})
+	assert.ErrorIs(t, err, domain.ErrIdempotencyKeyConflict)
+	assert.Len(t, gateway.requests(), 2)
+}
+
+func TestSale_IdempotencyKeyReservedBeforeEPX(t *testing.T) {
+	ctx := context.Background()
+	token, key := "09LMQ886L2K2W11MPX1", "order-123"
+	sale := func() *ports.SaleRequest {
+		return &ports.SaleRequest{AgentID: "merchant-1", Amount: "10.50", Currency: "USD", PaymentToken: &token, IdempotencyKey: &key}
+	}
+
+	t.Run("a concurrent request with the same key never reaches EPX", func(t *testing.T) {
+		store := newFakeStore(testAgent("merchant-1"))
+		gateway := &fakeEPX{}
+		inFlight, release := make(chan struct{}), make(chan struct{})
+		svc := newStoreBackedService(t, store, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(inFlight)
+			<-release
+			gateway.ServeHTTP(w, r)
+		}))
+
+		first := make(chan error, 1)
+		go func() {
+			_, err := svc.Sale(ctx, sale())
+			first <- err
+		}()
+		<-inFlight
+
+		_, err := svc.Sale(ctx, sale())
+		assert.ErrorIs(t, err, domain.ErrDuplicateIdempotencyKey, "the key is held while the first request is at EPX")
+		close(release)
+		require.NoError(t, <-first)
+		assert.Len(t, gateway.requests(), 1)
+	})
+
+	t.Run("a retry takes over a stale reservation with its TRAN_NBR", func(t *testing.T) {
+		store := newFakeStore(testAgent("merchant-1"))
+		gateway := &fakeEPX{}
+		var attempts []url.Values
+		var mu sync.Mutex
+		svc := newStoreBackedService(t, store, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			mu.Lock()
+			attempts = append(attempts, r.PostForm)
+			first := len(attempts) == 1
+			mu.Unlock()
+			if first {
+				http.Error(w, "gateway unavailable", http.StatusBadGateway)
+				return
+			}
+			gateway.ServeHTTP(w, r)
+		}))
+
+		_, err := svc.Sale(ctx, sale())
+		require.Error(t, err, "the first attempt's outcome is unknown")
+		reservation, err := store.GetTransactionByIdempotencyKey(ctx, sqlc.GetTransactionByIdempotencyKeyParams{AgentID: "merchant-1", IdempotencyKey: toNullableText(&key)})
+		require.NoError(t, err)
+		assert.Equal(t, string(domain.TransactionStatusPending), reservation.Status)
+
+		_, err = svc.Sale(ctx, sale())
+		assert.ErrorIs(t, err, domain.ErrDuplicateIdempotencyKey, "a fresh reservation still holds the key")
+
+		store.age(reservation.ID, pendingTransactionStaleAfter)
+		tx, err := svc.Sale(ctx, sale())
+		require.NoError(t, err)
+		assert.Equal(t, reservation.ID.String(), tx.ID, "the retry completes the reserved row")
+		assert.Equal(t, domain.TransactionStatusCompleted, tx.Status)
+		require.Len(t, attempts, 2)
+		assert.Equal(t, attempts[0].Get("TRAN_NBR"), attempts[1].Get("TRAN_NBR"), "EPX sees the retry as the same transaction")
+		assert.Equal(t, attempts[0].Get("BATCH_ID"), attempts[1].Get("BATCH_ID"))
+	})
+}
+
+func TestSale_DailyVolumeHeldOnThePendingRow(t *testing.T) {
+	ctx := context.Background()
+	token, key := "09LMQ886L2K2W11MPX1", "order-123"
+	sale := func() *ports.SaleRequest {
+		return &ports.SaleRequest{AgentID: "merchant-1", Amount: "60.00", Currency: "USD", PaymentToken: &token, IdempotencyKey: &key}
+	}
+	limitedAgent := func() sqlc.AgentCredential {
+		agent := testAgent("merchant-1")
+		agent.ConfigOverrides = []byte(`{"daily_volume_limit": "1000"}`)
+		return agent
+	}
+	pendingRow := func(t *testing.T, store *fakeStore) sqlc.Transaction {
+		row, err := store.GetTransactionByIdempotencyKey(ctx, sqlc.GetTransactionByIdempotencyKeyParams{AgentID: "merchant-1", IdempotencyKey: toNullableText(&key)})
+		require.NoError(t, err)
+		require.Equal(t, string(domain.TransactionStatusPending), row.Status)
+		return row
+	}
+
+	t.Run("a retry taking over an ambiguous attempt keeps its hold", func(t *testing.T) {
+		store := newFakeStore(limitedAgent())
+		gateway := &fakeEPX{}
+		var calls atomic.Int32
+		svc := newStoreBackedService(t, store, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				http.Error(w, "gateway unavailable", http.StatusBadGateway)
+				return
+			}
+			gateway.ServeHTTP(w, r)
+		}))
+
+		_, err := svc.Sale(ctx, sale())
+		require.Error(t, err)
+		assert.Equal(t, "60.00", store.volume.held(), "the charge may have gone through")
+		row := pendingRow(t, store)
+		assert.True(t, row.DailyVolumeDate.Valid, "the hold is recorded on the pending row")
+
+		store.age(row.ID, pendingTransactionStaleAfter)
+		tx, err := svc.Sale(ctx, sale())
+		require.NoError(t, err)
+		assert.Equal(t, row.ID.String(), tx.ID)
+		assert.Equal(t, "60.00", store.volume.held(), "the retry reuses the first attempt's hold instead of reserving again")
+	})
+
+	t.Run("a request that never reached EPX releases its hold", func(t *testing.T) {
+		agent := limitedAgent()
+		agent.TerminalNbr = "" // fails the adapter's request validation
+		store := newFakeStore(agent)
+		gateway := &fakeEPX{}
+		svc := newStoreBackedService(t, store, gateway)
+
+		_, err := svc.Sale(ctx, sale())
+		require.Error(t, err)
+		assert.Empty(t, gateway.requests())
+		assert.Equal(t, "0.00", store.volume.held())
+		row := pendingRow(t, store)
+		assert.False(t, row.DailyVolumeDate.Valid, "the released hold is cleared from the pending row")
+
+		store.agents["merchant-1"] = limitedAgent()
+		store.age(row.ID, pendingTransactionStaleAfter)
+		tx, err := svc.Sale(ctx, sale())
+		require.NoError(t, err)
+		assert.Equal(t, row.ID.String(), tx.ID)
+		assert.Equal(t, "60.00", store.volume.held(), "the retry holds volume of its own")
+		completed, err := store.GetTransactionByID(ctx, row.ID)
+		require.NoError(t, err)
+		assert.True(t, completed.DailyVolumeDate.Valid)
+	})
+}
+
+// fakeKeyedTransactions records transactions under (merchant, idempotency key), as the per-merchant unique index does
+type fakeKeyedTransactions struct {
+	byKey map[string]sqlc.Transaction
+}
+
+func (f *fakeKeyedTransactions) GetTransactionByIdempotencyKey(ctx context.Context, arg sqlc.GetTransactionByIdempotencyKeyParams) (sqlc.Transaction, error) {
+	tx, ok := f.byKey[arg.AgentID+"/"+arg.IdempotencyKey.String]
+	if !ok {
+		return sqlc.Transaction{}, pgx.ErrNoRows
+	}
+	return tx, nil
+}
+
+// record stores a sale the way the payment flow does, under a fresh transaction ID
+func (f *fakeKeyedTransactions) record(req *ports.SaleRequest, id uuid.UUID) sqlc.Transaction {
+	tx := sqlc.Transaction{
+		ID:             id,
+		AgentID:        req.AgentID,
+		Amount:         toNumeric(decimal.RequireFromString(req.Amount)),
+		IdempotencyKey: toNullableText(req.IdempotencyKey),
+		RequestHash:    requestHash(req.IdempotencyKey, saleFingerprint(req)),
+	}
+	f.byKey[req.AgentID+"/"+*req.IdempotencyKey] = tx
+	return tx
+}
+
+func TestTransactionByIdempotencyKey_ClientKeyIndependentOfTransactionID(t *testing.T) {
+	store := &fakeKeyedTransactions{byKey: map[string]sqlc.Transaction{}}
+	key := "checkout-7f3a:attempt-1"
+	first := &ports.SaleRequest{AgentID: "merchant-1", Amount: "25.00", Currency: "USD", IdempotencyKey: &key}
+
+	_, err := transactionByIdempotencyKey(context.Background(), store, "merchant-1", key)
+	require.ErrorIs(t, err, pgx.ErrNoRows, "a new key has no recorded transaction")
+	recorded := store.record(first, uuid.New())
+
+	retry := *first
+	existing, err := transactionByIdempotencyKey(context.Background(), store, "merchant-1", key)
+	require.NoError(t, err)
+	assert.Equal(t, recorded.ID.String(), existing.ID, "the second request gets the first one's transaction")
+	assert.NotEqual(t, key, existing.ID)
+	assert.NoError(t, existing.CheckReplay(saleFingerprint(&retry)))
+
+	_, err = transactionByIdempotencyKey(context.Background(), store, "merchant-2", key)
+	assert.ErrorIs(t, err, pgx.ErrNoRows, "keys are scoped to the merchant")
+
+	// Clients that send the transaction's own UUID as the key keep working: it is just another key
+	id := uuid.New()
+	uuidKey := id.String()
+	store.record(&ports.SaleRequest{AgentID: "merchant-1", Amount: "5.00", IdempotencyKey: &uuidKey}, id)
+	existing, err = transactionByIdempotencyKey(context.Background(), store, "merchant-1", uuidKey)
+	require.NoError(t, err)
+	assert.Equal(t, uuidKey, existing.ID)
+}
+
+// recordingAuditWriter keeps the audit rows written, or fails every write with err
+type recordingAuditWriter struct {
+	rows []sqlc.CreateAuditLogParams
+	err  error
+}
+
+func (w *recordingAuditWriter) CreateAuditLog(ctx context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error) {
+	if ctx.Err() != nil {
+		return sqlc.AuditLog{}, ctx.Err()
+	}
+	if w.err != nil {
+		return sqlc.AuditLog{}, w.err
+	}
+	w.rows = append(w.rows, arg)
+	return sqlc.AuditLog{}, nil
+}
+
+func TestCapture_WritesAuditRow(t *testing.T) {
+	store := newFakeStore(testAgent("merchant-1"))
+	auth := store.addTransaction("merchant-1", domain.TransactionTypeAuth, domain.TransactionStatusCompleted, "80.00")
+	svc := newStoreBackedService(t, store, &fakeEPX{})
+
+	amount := "40.00"
+	capture, err := svc.Capture(context.Background(), &ports.CaptureRequest{
+		TransactionID: auth.ID.String(),
+		Amount:        &amount,
+		Actor:         "pos-service",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, store.audit.rows, 1)
+	row := store.audit.rows[0]
+	assert.Equal(t, "payment.capture", row.EventType)
+	assert.Equal(t, "capture", row.Action)
+	assert.Equal(t, "transaction", row.EntityType)
+	assert.Equal(t, capture.ID, row.EntityID)
+	assert.Equal(t, "merchant-1", row.AgentID, "taken from the transaction when the request has none")
+	assert.Equal(t, pgtype.Text{String: "pos-service", Valid: true}, row.UserID)
+
+	var changes map[string]interface{}
+	require.NoError(t, json.Unmarshal(row.AfterState, &changes))
+	assert.Equal(t, "40.00", changes["amount"])
+	var metadata struct {
+		Outcome map[string]interface{} `json:"outcome"`
+	}
+	require.NoError(t, json.Unmarshal(row.Metadata, &metadata))
+	assert.Equal(t, "success", metadata.Outcome["result"])
+}
+
+func TestWriteAuditEntry_OutlivesCancelledRequest(t *testing.T) {
+	svc := &paymentService{auditPolicy: security.DefaultRedactionPolicy()}
+	tx := &domain.Transaction{ID: "capture-1", AgentID: "merchant-1", Type: domain.TransactionTypeCapture, Status: domain.TransactionStatusCompleted}
+
+	// The caller has gone away by the time the capture is audited
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	writer := &recordingAuditWriter{}
+	writeAuditEntry(ctx, writer, svc.auditEntry(domain.AuditActionCapture, "pos-service", "", &ports.CaptureRequest{TransactionID: "auth-1"}, tx, nil), zap.NewNop())
+
+	require.Len(t, writer.rows, 1)
+	assert.Equal(t, "capture-1", writer.rows[0].EntityID)
+}
+
+func TestAuthorizationAdjustments_WriteAuditRows(t *testing.T) {
+	agent := testAgent("merchant-1")
+	agent.ConfigOverrides = []byte(`{"allowed_transaction_types": ["auth", "capture", "charge", "refund", "increment"]}`)
+	store := newFakeStore(agent)
+	auth := store.addTransaction("merchant-1", domain.TransactionTypeAuth, domain.TransactionStatusCompleted, "80.00")
+	svc := newStoreBackedService(t, store, &fakeEPX{})
+	ctx := context.Background()
+
+	reversal, err := svc.PartialReverseAuthorization(ctx, &ports.PartialReversalRequest{TransactionID: auth.ID.String(), Amount: "10.00", Actor: "pos-service"})
+	require.NoError(t, err)
+	increment, err := svc.IncrementAuthorization(ctx, &ports.IncrementAuthorizationRequest{TransactionID: auth.ID.String(), Amount: "25.00", Actor: "pos-service"})
+	require.NoError(t, err)
+
+	require.Len(t, store.audit.rows, 2)
+	assert.Equal(t, "payment.partial_reversal", store.audit.rows[0].EventType)
+	assert.Equal(t, reversal.ID, store.audit.rows[0].EntityID)
+	assert.Equal(t, "payment.increment_authorization", store.audit.rows[1].EventType)
+	assert.Equal(t, increment.ID, store.audit.rows[1].EntityID)
+	for _, row := range store.audit.rows {
+		assert.Equal(t, pgtype.Text{String: "pos-service", Valid: true}, row.UserID)
+	}
+}
+
+func TestWriteAuditEntry_FailureIsLoggedNotReturned(t *testing.T) {
+	svc := &paymentService{auditPolicy: security.DefaultRedactionPolicy()}
+	core, logs := observer.New(zap.ErrorLevel)
+
+	// A failed void is audited too, without a transaction
+	entry := svc.auditEntry(domain.AuditActionVoid, "pos-service", "", &ports.VoidRequest{TransactionID: "tx-1"}, nil, domain.ErrTransactionCannotBeVoided)
+	writeAuditEntry(context.Background(), &recordingAuditWriter{err: fmt.Errorf("connection reset")}, entry, zap.New(core))
+
+	failures := logs.FilterMessage("Failed to write payment audit log").All()
+	require.Len(t, failures, 1)
+	assert.Equal(t, "payment.void", failures[0].ContextMap()["event_type"])
+}
+
+// fakeFraudScorer returns a fixed verdict and records what it was asked to score
+type fakeFraudScorer struct {
+	decision adapterports.FraudDecision
+	score    float64
+	reason   string
+	err      error
+	scored   []*adapterports.FraudContext
+}
+
+func (f *fakeFraudScorer) Score(ctx context.Context, tx *adapterports.FraudContext) (adapterports.FraudDecision, float64, string, error) {
+	f.scored = append(f.scored, tx)
+	return f.decision, f.score, f.reason, f.err
+}
+
+func TestFraudScreening_ThroughSaleAndAuthorize(t *testing.T) {
+	scoringDown := fmt.Errorf("scoring service unavailable")
+	tests := []struct {
+		name       string
+		scorer     *fakeFraudScorer
+		policy     FraudPolicy
+		wantErr    error
+		wantReview string // fraud_reason stored on a charge sent for review
+	}{
+		{name: "approve", scorer: &fakeFraudScorer{decision: adapterports.FraudDecisionApprove, score: 3}},
+		{name: "review", scorer: &fakeFraudScorer{decision: adapterports.FraudDecisionReview, score: 61, reason: "new device"}, wantReview: "new device"},
+		{name: "decline", scorer: &fakeFraudScorer{decision: adapterports.FraudDecisionDecline, score: 97.5, reason: "velocity"}, wantErr: domain.ErrFraudDeclined},
+		{name: "empty decision is declined by default", scorer: &fakeFraudScorer{}, wantErr: domain.ErrFraudDeclined},
+		{name: "unknown decision is declined by default", scorer: &fakeFraudScorer{decision: "allow"}, wantErr: domain.ErrFraudDeclined},
+		{name: "scorer error is reviewed by default", scorer: &fakeFraudScorer{err: scoringDown}, wantReview: "fraud scoring unavailable"},
+		{
+			name:    "scorer error declined by policy",
+			scorer:  &fakeFraudScorer{err: scoringDown},
+			policy:  FraudPolicy{OnScorerError: adapterports.FraudDecisionDecline},
+			wantErr: domain.ErrFraudDeclined,
+		},
+		{
+			name:   "unknown decision approved by policy",
+			scorer: &fakeFraudScorer{decision: "allow"},
+			policy: FraudPolicy{OnUnknownDecision: adapterports.FraudDecisionApprove},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore(testAgent("merchant-1"))
+			gateway := &fakeEPX{}
+			svc := newStoreBackedService(t, store, gateway)
+			svc.fraud = tt.scorer
+			svc.fraudPolicy = tt.policy.withDefaults()
+			ctx := context.Background()
+			token := "09LMQ886L2K2W11MPX1"
+			metadata := map[string]interface{}{"order_id": "A-1"}
+
+			sale, saleErr := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "250.00", Currency: "USD", PaymentToken: &token, Metadata: metadata})
+			auth, authErr := svc.Authorize(ctx, &ports.AuthorizeRequest{AgentID: "merchant-1", Amount: "250.00", Currency: "USD", PaymentToken: &token, Metadata: metadata})
+
+			require.Len(t, tt.scorer.scored, 2)
+			assert.Equal(t, "merchant-1", tt.scorer.scored[0].AgentID)
+			assert.Equal(t, string(domain.TransactionTypeCharge), tt.scorer.scored[0].TransactionType)
+			assert.Equal(t, string(domain.TransactionTypeAuth), tt.scorer.scored[1].TransactionType)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, saleErr, tt.wantErr)
+				assert.ErrorIs(t, authErr, tt.wantErr)
+				assert.Empty(t, gateway.requests(), "EPX is never called")
+				return
+			}
+			require.NoError(t, saleErr)
+			require.NoError(t, authErr)
+			assert.Len(t, gateway.requests(), 2)
+			for _, tx := range []*domain.Transaction{sale, auth} {
+				assert.Equal(t, "A-1", tx.Metadata["order_id"])
+				if tt.wantReview == "" {
+					assert.NotContains(t, tx.Metadata, "fraud_review", "%s", tx.Type)
+					continue
+				}
+				assert.Equal(t, true, tx.Metadata["fraud_review"], "%s", tx.Type)
+				assert.Equal(t, tt.wantReview, tx.Metadata["fraud_reason"], "%s", tx.Type)
+			}
+			assert.Len(t, metadata, 1, "the request's metadata isn't modified")
+		})
+	}
+
+	t.Run("no scorer approves everything", func(t *testing.T) {
+		store := newFakeStore(testAgent("merchant-1"))
+		gateway := &fakeEPX{}
+		svc := newStoreBackedService(t, store, gateway)
+		require.IsType(t, fraud.NoopScorer{}, svc.fraud)
+		token := "09LMQ886L2K2W11MPX1"
+
+		_, err := svc.Sale(context.Background(), &ports.SaleRequest{AgentID: "merchant-1", Amount: "250.00", Currency: "USD", PaymentToken: &token})
+		require.NoError(t, err)
+		assert.Len(t, gateway.requests(), 1)
+	})
+}
+
+// fakeVelocityHistory answers velocity counts from an in-memory list of a merchant's sales and authorizations
+type fakeVelocityHistory struct {
+	txs []sqlc.Transaction
+}
+
+func (f *fakeVelocityHistory) GetTransactionVelocity(ctx context.Context, arg sqlc.GetTransactionVelocityParams) (sqlc.GetTransactionVelocityRow, error) {
+	total := decimal.Zero
+	var count int64
+	for _, tx := range f.txs {
+		if tx.AgentID != arg.AgentID || tx.CreatedAt.Before(arg.Since) {
+			continue
+		}
+		if arg.CardFingerprint.Valid && tx.CardFingerprint != arg.CardFingerprint {
+			continue
+		}
+		if arg.CustomerID.Valid && tx.CustomerID != arg.CustomerID {
+			continue
+		}
+		count++
+		if tx.Status != string(domain.TransactionStatusFailed) {
+			total = total.Add(decimal.NewFromBigInt(tx.Amount.Int, tx.Amount.Exp))
+		}
+	}
+	return sqlc.GetTransactionVelocityRow{TransactionCount: count, TotalAmount: toNumeric(total)}, nil
+}
+
+func velocityHistory(now time.Time, customerID string, amounts ...string) *fakeVelocityHistory {
+	history := &fakeVelocityHistory{}
+	for i, amount := range amounts {
+		history.txs = append(history.txs, sqlc.Transaction{
+			AgentID:    "merchant-1",
+			CustomerID: pgtype.Text{String: customerID, Valid: true},
+			Amount:     toNumeric(decimal.RequireFromString(amount)),
+			Status:     string(domain.TransactionStatusCompleted),
+			Type:       string(domain.TransactionTypeCharge),
+			CreatedAt:  now.Add(-time.Duration(i+1) * time.Minute),
+		})
+	}
+	return history
+}
+
+func TestCheckVelocity_CustomerCountCap(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	config := domain.DefaultMerchantConfig(domain.MerchantTierStandard)
+	config.CustomerVelocity = domain.VelocityLimit{WindowMinutes: 10, MaxCount: 3}
+	customer := "customer-1"
+
+	history := velocityHistory(now, customer, "1.00", "1.00")
+	require.NoError(t, checkVelocity(context.Background(), history, config, "merchant-1", "", &customer, "1.00", now), "the third attempt is allowed")
+
+	history.txs[1].Status = string(domain.TransactionStatusFailed)
+	history.txs = append(history.txs, history.txs[0])
+	err := checkVelocity(context.Background(), history, config, "merchant-1", "", &customer, "1.00", now)
+	assert.ErrorIs(t, err, domain.ErrVelocityExceeded, "declined attempts count too")
+	assert.Contains(t, err.Error(), "customer already made 3 transactions in 10 minutes")
+
+	history.txs[2].CreatedAt = now.Add(-11 * time.Minute)
+	assert.NoError(t, checkVelocity(context.Background(), history, config, "merchant-1", "", &customer, "1.00", now), "attempts outside the window don't count")
+
+	other := "customer-2"
+	assert.NoError(t, checkVelocity(context.Background(), velocityHistory(now, customer, "1.00", "1.00", "1.00"), config, "merchant-1", "", &other, "1.00", now))
+	assert.NoError(t, checkVelocity(context.Background(), velocityHistory(now, customer, "1.00", "1.00", "1.00"), config, "merchant-1", "", nil, "1.00", now), "guests aren't limited")
+}
+
+func TestCheckVelocity_CustomerAmountCap(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	config := domain.DefaultMerchantConfig(domain.MerchantTierStandard)
+	config.CustomerVelocity = domain.VelocityLimit{WindowMinutes: 60, MaxAmount: decimal.NewFromInt(500)}
+	customer := "customer-1"
+	history := velocityHistory(now, customer, "200.00", "250.00")
+
+	assert.NoError(t, checkVelocity(context.Background(), history, config, "merchant-1", "", &customer, "50.00", now), "exactly at the cap")
+
+	err := checkVelocity(context.Background(), history, config, "merchant-1", "", &customer, "50.01", now)
+	assert.ErrorIs(t, err, domain.ErrVelocityExceeded)
+	assert.Contains(t, err.Error(), "customer would total 500.01 in 60 minutes (limit 500.00)")
+
+	history.txs[1].Status = string(domain.TransactionStatusFailed)
+	assert.NoError(t, checkVelocity(context.Background(), history, config, "merchant-1", "", &customer, "250.00", now), "declined amounts weren't charged")
+}
+
+func TestCardVelocity_ThroughSaleAndAuthorize(t *testing.T) {
+	agent := testAgent("merchant-1")
+	agent.ConfigOverrides = []byte(`{"card_velocity": {"window_minutes": 10, "max_count": 2}}`)
+	store := newFakeStore(agent)
+	savedCard := func(token, lastFour string, expMonth int32) string {
+		id := uuid.New()
+		store.paymentMethods[id] = sqlc.CustomerPaymentMethod{
+			ID:           id,
+			AgentID:      "merchant-1",
+			CustomerID:   "customer-1",
+			PaymentToken: token,
+			PaymentType:  string(domain.PaymentMethodTypeCreditCard),
+			LastFour:     lastFour,
+			CardExpMonth: pgtype.Int4{Int32: expMonth, Valid: true},
+			CardExpYear:  pgtype.Int4{Int32: 2028, Valid: true},
+		}
+		return id.String()
+	}
+	gateway := &fakeEPX{}
+	svc := newStoreBackedService(t, store, gateway)
+	ctx := context.Background()
+	sale := func(paymentMethodID, token *string) error {
+		_, err := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "1.00", Currency: "USD", PaymentMethodID: paymentMethodID, PaymentToken: token})
+		return err
+	}
+	authorize := func(paymentMethodID, token *string) error {
+		_, err := svc.Authorize(ctx, &ports.AuthorizeRequest{AgentID: "merchant-1", Amount: "1.00", Currency: "USD", PaymentMethodID: paymentMethodID, PaymentToken: token})
+		return err
+	}
+
+	t.Run("a saved card is limited across its saved copies", func(t *testing.T) {
+		first := savedCard("0A1MQQ3K2XTBVW8Y0Z1", "4242", 12)
+		second := savedCard("0A1MQQ3K2XTBVW8Y0Z2", "4242", 12)
+		require.NoError(t, sale(&first, nil))
+		require.NoError(t, authorize(&second, nil))
+		sent := len(gateway.requests())
+
+		assert.ErrorIs(t, sale(&first, nil), domain.ErrVelocityExceeded)
+		assert.ErrorIs(t, authorize(&second, nil), domain.ErrVelocityExceeded, "the same card saved again")
+		assert.Len(t, gateway.requests(), sent, "never sent to EPX")
+
+		other := savedCard("0A1MQQ3K2XTBVW8Y0Z3", "4242", 11)
+		assert.NoError(t, sale(&other, nil), "a different expiry is a different card")
+	})
+
+	t.Run("a one-time token is limited by its BRIC", func(t *testing.T) {
+		token := "09LMQ886L2K2W11MPX1"
+		require.NoError(t, sale(nil, &token))
+		require.NoError(t, authorize(nil, &token))
+		sent := len(gateway.requests())
+
+		assert.ErrorIs(t, sale(nil, &token), domain.ErrVelocityExceeded)
+		assert.Len(t, gateway.requests(), sent, "never sent to EPX")
+
+		other := "09LMQ886L2K2W11MPX2"
+		assert.NoError(t, sale(nil, &other))
+	})
+}
+
+func TestCardFundingType_RecordedFromSaleAndAuthorize(t *testing.T) {
+	store := newFakeStore(testAgent("merchant-1"))
+	gateway := &fakeEPX{fundingType: "D"}
+	svc := newStoreBackedService(t, store, gateway)
+	ctx := context.Background()
+	token := "09LMQ886L2K2W11MPX1"
+
+	sale, err := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "10.00", Currency: "USD", PaymentToken: &token})
+	require.NoError(t, err)
+	auth, err := svc.Authorize(ctx, &ports.AuthorizeRequest{AgentID: "merchant-1", Amount: "10.00", Currency: "USD", PaymentToken: &token})
+	require.NoError(t, err)
+
+	for _, tx := range []*domain.Transaction{sale, auth} {
+		require.NotNil(t, tx.CardFundingType, "%s", tx.Type)
+		assert.Equal(t, "debit", *tx.CardFundingType)
+		stored, err := store.GetTransactionByID(ctx, uuid.MustParse(tx.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "debit", stored.CardFundingType.String, "stored for fee estimates")
+	}
+
+	gateway.fundingType = "X"
+	unknown, err := svc.Sale(ctx, &ports.SaleRequest{AgentID: "merchant-1", Amount: "10.00", Currency: "USD", PaymentToken: &token})
+	require.NoError(t, err)
+	assert.Nil(t, unknown.CardFundingType, "an unrecognized indicator is stored as unknown")
+}
+
+// fakeReferencedTransactions looks transactions up by BRIC, TRAN_NBR and group the way the queries do
+type fakeReferencedTransactions struct {
+	txs []sqlc.Transaction
+}
+
+func (f *fakeReferencedTransactions) GetTransactionByAuthGUID(ctx context.Context, authGuid pgtype.Text) (sqlc.Transaction, error) {
+	for _, tx := range f.txs {
+		if tx.AuthGuid == authGuid {
+			return tx, nil
+		}
+	}
+	return sqlc.Transaction{}, pgx.ErrNoRows
+}
+
+func (f *fakeReferencedTransactions) GetTransactionByTranNbr(ctx context.Context, arg sqlc.GetTransactionByTranNbrParams) (sqlc.Transaction, error) {
+	for _, tx := range f.txs {
+		if tx.AgentID != arg.AgentID {
+			continue
+		}
+		if tx.TranNbr == (pgtype.Int8{Int64: arg.TranNbr, Valid: true}) ||
+			(!tx.TranNbr.Valid && tx.IdempotencyKey == pgtype.Text{String: arg.TranNbrKey, Valid: true}) {
+			return tx, nil
+		}
+	}
+	return sqlc.Transaction{}, pgx.ErrNoRows
+}
+
+func (f *fakeReferencedTransactions) GetTransactionsByGroupID(ctx context.Context, groupID uuid.UUID) ([]sqlc.Transaction, error) {
+	var group []sqlc.Transaction
+	for _, tx := range f.txs {
+		if tx.GroupID == groupID {
+			group = append(group, tx)
+		}
+	}
+	return group, nil
+}
+
+func referencedTransaction(agentID string, groupID uuid.UUID, txType domain.TransactionType, bric string, tranNbr int64) sqlc.Transaction {
+	return sqlc.Transaction{
+		ID:       uuid.New(),
+		GroupID:  groupID,
+		AgentID:  agentID,
+		Amount:   toNumeric(decimal.NewFromInt(25)),
+		Currency: "USD",
+		Status:   string(domain.TransactionStatusCompleted),
+		Type:     string(txType),
+		AuthGuid: pgtype.Text{String: bric, Valid: true},
+		TranNbr:  pgtype.Int8{Int64: tranNbr, Valid: true},
+	}
+}
+
+func TestResolveRefundReference_ByTranNbr(t *testing.T) {
+	sale := referencedTransaction("merchant-1", uuid.New(), domain.TransactionTypeCharge, "BRIC-SALE", 1001)
+	otherMerchantSale := referencedTransaction("merchant-2", uuid.New(), domain.TransactionTypeCharge, "BRIC-OTHER", 1001)
+	authGroup := uuid.New()
+	auth := referencedTransaction("merchant-1", authGroup, domain.TransactionTypeAuth, "BRIC-AUTH", 1002)
+	capture := referencedTransaction("merchant-1", authGroup, domain.TransactionTypeCapture, "BRIC-CAPTURE", 1003)
+	browserPost := referencedTransaction("merchant-1", uuid.New(), domain.TransactionTypeCharge, "BRIC-BP", 0)
+	browserPost.TranNbr = pgtype.Int8{}
+	browserPost.IdempotencyKey = pgtype.Text{String: "55512", Valid: true}
+	q := &fakeReferencedTransactions{txs: []sqlc.Transaction{otherMerchantSale, sale, auth, capture, browserPost}}
+
+	tx, err := resolveRefundReference(context.Background(), q, "merchant-1", "", "1001")
+	require.NoError(t, err)
+	assert.Equal(t, sale.ID.String(), tx.ID, "TRAN_NBRs are numbered per merchant")
+
+	tx, err = resolveRefundReference(context.Background(), q, "merchant-1", "", "1002")
+	require.NoError(t, err)
+	assert.Equal(t, capture.ID.String(), tx.ID, "an authorization resolves to the capture holding its money")
+
+	tx, err = resolveRefundReference(context.Background(), q, "merchant-1", "", "55512")
+	require.NoError(t, err)
+	assert.Equal(t, browserPost.ID.String(), tx.ID, "Browser Post payments are found by their form's TRAN_NBR")
+
+	_, err = resolveRefundReference(context.Background(), q, "merchant-1", "", "9999")
+	assert.ErrorIs(t, err, domain.ErrTransactionNotFound)
+
+	for name, ref := range map[string][2]string{
+		"neither":       {"", ""},
+		"both":          {"BRIC-SALE", "1001"},
+		"non-numeric":   {"", "receipt-1001"},
+		"zero tran_nbr": {"", "0"},
+	} {
+		_, err := resolveRefundReference(context.Background(), q, "merchant-1", ref[0], ref[1])
+		assert.ErrorIs(t, err, domain.ErrInvalidReference, name)
+	}
+}
+
+func TestResolveRefundReference_ByBRICEnforcesMerchant(t *testing.T) {
+	sale := referencedTransaction("merchant-1", uuid.New(), domain.TransactionTypeCharge, "0V703LH1HDL006J74W1", 1001)
+	q := &fakeReferencedTransactions{txs: []sqlc.Transaction{sale}}
+
+	tx, err := resolveRefundReference(context.Background(), q, "merchant-1", "0V703LH1HDL006J74W1", "")
+	require.NoError(t, err)
+	assert.Equal(t, sale.ID.String(), tx.ID)
+
+	_, err = resolveRefundReference(context.Background(), q, "merchant-2", "0V703LH1HDL006J74W1", "")
+	assert.ErrorIs(t, err, domain.ErrTransactionNotFound, "another merchant's BRIC looks like an unknown one")
+
+	_, err = resolveRefundReference(context.Background(), q, "", "0V703LH1HDL006J74W1", "")
+	assert.ErrorIs(t, err, domain.ErrInvalidReference)
+}
+
+func TestCheckEPXEnvironment(t *testing.T) {
+	sandbox := &paymentService{epxEnv: domain.EnvironmentSandbox, logger: zap.NewNop()}
+	production := &paymentService{epxEnv: domain.EnvironmentProduction, logger: zap.NewNop()}
+	liveMerchant := &sqlc.AgentCredential{AgentID: "merchant-1", Environment: "production"}
+	testMerchant := &sqlc.AgentCredential{AgentID: "merchant-2", Environment: "test"}
+
+	assert.NoError(t, production.checkEPXEnvironment(zap.NewNop(), liveMerchant))
+	assert.NoError(t, sandbox.checkEPXEnvironment(zap.NewNop(), testMerchant))
+
+	assert.ErrorIs(t, sandbox.checkEPXEnvironment(zap.NewNop(), liveMerchant), domain.ErrEnvironmentMismatch,
+		"production credentials never reach the sandbox")
+	assert.ErrorIs(t, production.checkEPXEnvironment(zap.NewNop(), testMerchant), domain.ErrEnvironmentMismatch)
+}
+
+// fakeFeatureFlags answers flag lookups from an in-memory table
+type fakeFeatureFlags struct {
+	flags []sqlc.FeatureFlag
+}
+
+func (f *fakeFeatureFlags) GetFeatureFlagsForAgent(ctx context.Context, arg sqlc.GetFeatureFlagsForAgentParams) ([]sqlc.FeatureFlag, error) {
+	var matched []sqlc.FeatureFlag
+	for _, flag := range f.flags {
+		if flag.Name == arg.Name && (!flag.AgentID.Valid || flag.AgentID == arg.AgentID) {
+			matched = append(matched, flag)
+		}
+	}
+	return matched, nil
+}
+
+func chargesFlag(agentID string, enabled bool) sqlc.FeatureFlag {
+	return sqlc.FeatureFlag{
+		Name:    domain.FeatureFlagChargesEnabled,
+		AgentID: pgtype.Text{String: agentID, Valid: agentID != ""},
+		Enabled: enabled,
+	}
+}
+
+// fakeStore is an in-memory database.Store for driving the service's RPCs end to end.
+// Queries it doesn't implement panic through the nil embedded Querier.
+type fakeStore struct {
+	sqlc.Querier
+
+	mu             sync.Mutex
+	agents         map[string]sqlc.AgentCredential
+	paymentMethods map[uuid.UUID]sqlc.CustomerPaymentMethod
+	transactions   []sqlc.Transaction
+	tranNbrs       *fakeTranNbrStore
+	volume         *fakeDailyVolume
+	flags          *fakeFeatureFlags
+	audit          *recordingAuditWriter
+	groupLocks     map[uuid.UUID]*sync.Mutex
+	customers      map[string]sqlc.Customer
+}
+
+func newFakeStore(agents ...sqlc.AgentCredential) *fakeStore {
+	f := &fakeStore{
+		agents:         make(map[string]sqlc.AgentCredential),
+		paymentMethods: make(map[uuid.UUID]sqlc.CustomerPaymentMethod),
+		tranNbrs:       newFakeTranNbrStore(),
+		volume:         &fakeDailyVolume{totals: make(map[string]decimal.Decimal)},
+		flags:          &fakeFeatureFlags{},
+		audit:          &recordingAuditWriter{},
+		groupLocks:     make(map[uuid.UUID]*sync.Mutex),
+		customers:      make(map[string]sqlc.Customer),
+	}
+	for _, agent := range agents {
+		f.agents[agent.AgentID] = agent
+	}
+	return f
+}
+
+func (f *fakeStore) Queries() sqlc.Querier { return f }
+
+// WithTx holds the advisory locks taken through the transaction until fn returns, as Postgres does
+func (f *fakeStore) WithTx(ctx context.Context, fn func(sqlc.Querier) error) error {
+	tx := &fakeTx{fakeStore: f}
+	defer tx.unlock()
+	return fn(tx)
+}
+
+// fakeTx is a fakeStore seen through one transaction
+type fakeTx struct {
+	*fakeStore
+	held []*sync.Mutex
+}
+
+func (t *fakeTx) LockTransactionGroup(ctx context.Context, groupID uuid.UUID) error {
+	t.mu.Lock()
+	lock, ok := t.groupLocks[groupID]
+	if !ok {
+		lock = &sync.Mutex{}
+		t.groupLocks[groupID] = lock
+	}
+	t.mu.Unlock()
+
+	lock.Lock()
+	t.held = append(t.held, lock)
+	return nil
+}
+
+func (t *fakeTx) unlock() {
+	for _, lock := range t.held {
+		lock.Unlock()
+	}
+}
+
+func (f *fakeStore) GetAgentByAgentID(ctx context.Context, agentID string) (sqlc.AgentCredential, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	agent, ok := f.agents[agentID]
+	if !ok {
+		return sqlc.AgentCredential{}, pgx.ErrNoRows
+	}
+	return agent, nil
+}
+
+func (f *fakeStore) GetPaymentMethodByID(ctx context.Context, id uuid.UUID) (sqlc.CustomerPaymentMethod, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pm, ok := f.paymentMethods[id]
+	if !ok {
+		return sqlc.CustomerPaymentMethod{}, pgx.ErrNoRows
+	}
+	return pm, nil
+}
+
+func (f *fakeStore) GetCustomer(ctx context.Context, arg sqlc.GetCustomerParams) (sqlc.Customer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	customer, ok := f.customers[arg.AgentID+"/"+arg.CustomerID]
+	if !ok {
+		return sqlc.Customer{}, pgx.ErrNoRows
+	}
+	return customer, nil
+}
+
+func (f *fakeStore) CreateCustomer(ctx context.Context, arg sqlc.CreateCustomerParams) (sqlc.Customer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	customer := sqlc.Customer{ID: uuid.New(), AgentID: arg.AgentID, CustomerID: arg.CustomerID, Email: arg.Email, AutoCreated: arg.AutoCreated}
+	f.customers[arg.AgentID+"/"+arg.CustomerID] = customer
+	return customer, nil
+}
+
+func (f *fakeStore) MarkPaymentMethodUsed(ctx context.Context, id uuid.UUID) error { return nil }
+
+func (f *fakeStore) GetFeatureFlagsForAgent(ctx context.Context, arg sqlc.GetFeatureFlagsForAgentParams) ([]sqlc.FeatureFlag, error) {
+	return f.flags.GetFeatureFlagsForAgent(ctx, arg)
+}
+
+func (f *fakeStore) GetTranNbr(ctx context.Context, transactionID uuid.UUID) (sqlc.EpxTranNbr, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tranNbrs.GetTranNbr(ctx, transactionID)
+}
+
+func (f *fakeStore) NextTranNbr(ctx context.Context, agentID string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tranNbrs.NextTranNbr(ctx, agentID)
+}
+
+func (f *fakeStore) AssignTranNbr(ctx context.Context, arg sqlc.AssignTranNbrParams) (sqlc.EpxTranNbr, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tranNbrs.AssignTranNbr(ctx, arg)
+}
+
+func (f *fakeStore) ReserveDailyVolume(ctx context.Context, arg sqlc.ReserveDailyVolumeParams) (pgtype.Numeric, error) {
+	return f.volume.ReserveDailyVolume(ctx, arg)
+}
+
+func (f *fakeStore) ReleaseDailyVolume(ctx context.Context, arg sqlc.ReleaseDailyVolumeParams) error {
+	return f.volume.ReleaseDailyVolume(ctx, arg)
+}
+
+func (f *fakeStore) CreateAuditLog(ctx context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.audit.CreateAuditLog(ctx, arg)
+}
+
+func (f *fakeStore) GetTransactionVelocity(ctx context.Context, arg sqlc.GetTransactionVelocityParams) (sqlc.GetTransactionVelocityRow, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return (&fakeVelocityHistory{txs: f.transactions}).GetTransactionVelocity(ctx, arg)
+}
+
+func (f *fakeStore) CreateTransaction(ctx context.Context, arg sqlc.CreateTransactionParams) (sqlc.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if arg.IdempotencyKey.Valid {
+		for _, tx := range f.transactions {
+			if tx.AgentID == arg.AgentID && tx.IdempotencyKey == arg.IdempotencyKey {
+				return sqlc.Transaction{}, &pgconn.PgError{Code: "23505", ConstraintName: "idx_transactions_agent_idempotency_key"}
+			}
+		}
+	}
+	now := time.Now()
+	tx := sqlc.Transaction{
+		ID:                  arg.ID,
+		GroupID:             arg.GroupID,
+		AgentID:             arg.AgentID,
+		CustomerID:          arg.CustomerID,
+		Amount:              arg.Amount,
+		Currency:            arg.Currency,
+		Status:              arg.Status,
+		Type:                arg.Type,
+		PaymentMethodType:   arg.PaymentMethodType,
+		PaymentMethodID:     arg.PaymentMethodID,
+		AuthGuid:            arg.AuthGuid,
+		AuthResp:            arg.AuthResp,
+		AuthCode:            arg.AuthCode,
+		AuthRespText:        arg.AuthRespText,
+		AuthCardType:        arg.AuthCardType,
+		AuthAvs:             arg.AuthAvs,
+		AuthCvv2:            arg.AuthCvv2,
+		CardFundingType:     arg.CardFundingType,
+		CardFingerprint:     arg.CardFingerprint,
+		IdempotencyKey:      arg.IdempotencyKey,
+		RequestHash:         arg.RequestHash,
+		Metadata:            arg.Metadata,
+		VerificationOutcome: arg.VerificationOutcome,
+		ThreeDs:             arg.ThreeDs,
+		TranNbr:             arg.TranNbr,
+		DailyVolumeDate:     arg.DailyVolumeDate,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	f.transactions = append(f.transactions, tx)
+	return tx, nil
+}
+
+func (f *fakeStore) ClaimStalePendingTransaction(ctx context.Context, arg sqlc.ClaimStalePendingTransactionParams) (sqlc.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, tx := range f.transactions {
+		if tx.AgentID == arg.AgentID && tx.IdempotencyKey == arg.IdempotencyKey && tx.RequestHash == arg.RequestHash &&
+			tx.Status == string(domain.TransactionStatusPending) && tx.UpdatedAt.Before(arg.StaleBefore) {
+			f.transactions[i].UpdatedAt = time.Now()
+			return f.transactions[i], nil
+		}
+	}
+	return sqlc.Transaction{}, pgx.ErrNoRows
+}
+
+func (f *fakeStore) SetPendingDailyVolume(ctx context.Context, arg sqlc.SetPendingDailyVolumeParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, tx := range f.transactions {
+		if tx.ID == arg.ID && tx.Status == string(domain.TransactionStatusPending) {
+			f.transactions[i].DailyVolumeDate = arg.DailyVolumeDate
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) CompletePendingTransaction(ctx context.Context, arg sqlc.CompletePendingTransactionParams) (sqlc.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, tx := range f.transactions {
+		if tx.ID != arg.ID || tx.Status != string(domain.TransactionStatusPending) {
+			continue
+		}
+		tx.Status = arg.Status
+		tx.AuthGuid, tx.AuthResp, tx.AuthCode, tx.AuthRespText = arg.AuthGuid, arg.AuthResp, arg.AuthCode, arg.AuthRespText
+		tx.AuthCardType, tx.AuthAvs, tx.AuthCvv2 = arg.AuthCardType, arg.AuthAvs, arg.AuthCvv2
+		tx.CardFundingType, tx.Metadata, tx.VerificationOutcome = arg.CardFundingType, arg.Metadata, arg.VerificationOutcome
+		tx.TranNbr, tx.UpdatedAt = arg.TranNbr, time.Now()
+		if arg.CardFingerprint.Valid {
+			tx.CardFingerprint = arg.CardFingerprint
+		}
+		if arg.LastFour.Valid {
+			tx.LastFour = arg.LastFour
+		}
+		f.transactions[i] = tx
+		return tx, nil
+	}
+	return sqlc.Transaction{}, pgx.ErrNoRows
+}
+
+// age moves the transaction's last update back by d, as if its request had gone quiet that long ago
+func (f *fakeStore) age(id uuid.UUID, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.transactions {
+		if f.transactions[i].ID == id {
+			f.transactions[i].UpdatedAt = f.transactions[i].UpdatedAt.Add(-d)
+		}
+	}
+}
+
+func (f *fakeStore) GetTransactionByID(ctx context.Context, id uuid.UUID) (sqlc.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, tx := range f.transactions {
+		if tx.ID == id {
+			return tx, nil
+		}
+	}
+	return sqlc.Transaction{}, pgx.ErrNoRows
+}
+
+func (f *fakeStore) GetTransactionByIdempotencyKey(ctx context.Context, arg sqlc.GetTransactionByIdempotencyKeyParams) (sqlc.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, tx := range f.transactions {
+		if tx.AgentID == arg.AgentID && tx.IdempotencyKey == arg.IdempotencyKey {
+			return tx, nil
+		}
+	}
+	return sqlc.Transaction{}, pgx.ErrNoRows
+}
+
+func (f *fakeStore) GetTransactionsByGroupID(ctx context.Context, groupID uuid.UUID) ([]sqlc.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var group []sqlc.Transaction
+	for _, tx := range f.transactions {
+		if tx.GroupID == groupID {
+			group = append(group, tx)
+		}
+	}
+	return group, nil
+}
+
+// addTransaction records an unsettled transaction in its own group, as if an earlier RPC had created it
+func (f *fakeStore) addTransaction(agentID string, txType domain.TransactionType, status domain.TransactionStatus, amount string) sqlc.Transaction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	tx := sqlc.Transaction{
+		ID:                uuid.New(),
+		GroupID:           uuid.New(),
+		AgentID:           agentID,
+		Amount:            toNumeric(decimal.RequireFromString(amount)),
+		Currency:          "USD",
+		Status:            string(status),
+		Type:              string(txType),
+		PaymentMethodType: string(domain.PaymentMethodTypeCreditCard),
+		AuthGuid:          pgtype.Text{String: "09LMQ886L2K2W11MPX1", Valid: true},
+		AuthResp:          pgtype.Text{String: "00", Valid: true},
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	f.transactions = append(f.transactions, tx)
+	return tx
+}
+
+// testAgent is an active sandbox merchant on the standard tier
+func testAgent(agentID string) sqlc.AgentCredential {
+	return sqlc.AgentCredential{
+		AgentID:       agentID,
+		MacSecretPath: "payment-service/agents/" + agentID + "/mac",
+		CustNbr:       "9001",
+		MerchNbr:      "900300",
+		DbaNbr:        "2",
+		TerminalNbr:   "77",
+		Environment:   string(domain.EnvironmentSandbox),
+		Tier:          string(domain.MerchantTierStandard),
+		Status:        string(domain.MerchantStatusActive),
+	}
+}
+
+// fakeEPX is an EPX Server Post endpoint that approves every request and records the forms it received
+type fakeEPX struct {
+	mu    sync.Mutex
+	forms []url.Values
+
+	fundingType string // AUTH_CARD_FUNDING_TYPE returned with approvals (omitted when empty)
+}
+
+func (e *fakeEPX) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	e.mu.Lock()
+	e.forms = append(e.forms, r.PostForm)
+	e.mu.Unlock()
+	fmt.Fprintf(w, `<RESPONSE><FIELDS>
+		<FIELD KEY="AUTH_GUID">09LMQ886L2K2W11MPX1</FIELD>
+		<FIELD KEY="AUTH_RESP">00</FIELD>
+		<FIELD KEY="AUTH_CODE">057579</FIELD>
+		<FIELD KEY="AUTH_RESP_TEXT">APPROVAL</FIELD>
+		<FIELD KEY="TRAN_NBR">%s</FIELD>
+		<FIELD KEY="TRAN_GROUP">%s</FIELD>%s
+	</FIELDS></RESPONSE>`, r.PostForm.Get("TRAN_NBR"), r.PostForm.Get("BATCH_ID"), e.fundingTypeField())
+}
+
+func (e *fakeEPX) fundingTypeField() string {
+	if e.fundingType == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<FIELD KEY="AUTH_CARD_FUNDING_TYPE">%s</FIELD>`, e.fundingType)
+}
+
+func (e *fakeEPX) requests() []url.Values {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]url.Values(nil), e.forms...)
+}
+
+// newStoreBackedService returns a payment service over store whose EPX calls go to epxHandler
+func newStoreBackedService(t *testing.T, store *fakeStore, epxHandler http.Handler) *paymentService {
+	t.Helper()
+	srv := httptest.NewServer(epxHandler)
+	t.Cleanup(srv.Close)
+
+	config := epx.DefaultServerPostConfig("sandbox")
+	config.BaseURL = srv.URL
+	return NewPaymentService(store, epx.NewServerPostAdapter(config, zap.NewNop()), domain.EnvironmentSandbox,
+		fakeSecretManager{}, nil, nil, nil, FraudPolicy{}, zap.NewNop()).(*paymentService)
+}
@@ -2,10 +2,15 @@ package payment_method
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/kevin07696/payment-service/internal/adapters/database"
 	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
@@ -17,20 +22,22 @@ import (
 
 // paymentMethodService implements the PaymentMethodService port
 type paymentMethodService struct {
-	db            *database.PostgreSQLAdapter
+	db            database.Store
 	browserPost   adapterports.BrowserPostAdapter
 	serverPost    adapterports.ServerPostAdapter
 	bricStorage   adapterports.BRICStorageAdapter
+	epxEnv        domain.Environment // EPX environment the adapters are configured for
 	secretManager adapterports.SecretManagerAdapter
 	logger        *zap.Logger
 }
 
 // NewPaymentMethodService creates a new payment method service
 func NewPaymentMethodService(
-	db *database.PostgreSQLAdapter,
+	db database.Store,
 	browserPost adapterports.BrowserPostAdapter,
 	serverPost adapterports.ServerPostAdapter,
 	bricStorage adapterports.BRICStorageAdapter,
+	epxEnv domain.Environment,
 	secretManager adapterports.SecretManagerAdapter,
 	logger *zap.Logger,
 ) ports.PaymentMethodService {
@@ -39,6 +46,7 @@ func NewPaymentMethodService(
 		browserPost:   browserPost,
 		serverPost:    serverPost,
 		bricStorage:   bricStorage,
+		epxEnv:        epxEnv,
 		secretManager: secretManager,
 		logger:        logger,
 	}
@@ -53,14 +61,8 @@ func (s *paymentMethodService) SavePaymentMethod(ctx context.Context, req *ports
 	)
 
 	// Check idempotency
-	if req.IdempotencyKey != nil {
-		existing, err := s.getPaymentMethodByIdempotencyKey(ctx, *req.IdempotencyKey)
-		if err == nil {
-			s.logger.Info("Idempotent request, returning existing payment method",
-				zap.String("payment_method_id", existing.ID),
-			)
-			return existing, nil
-		}
+	if existing, err := s.getPaymentMethodByIdempotencyKey(ctx, req.AgentID, req.IdempotencyKey); err != nil || existing != nil {
+		return existing, err
 	}
 
 	// Validate payment token
@@ -84,16 +86,30 @@ func (s *paymentMethodService) SavePaymentMethod(ctx context.Context, req *ports
 		}
 	}
 
+	agent, err := s.db.Queries().GetAgentByAgentID(ctx, req.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		s.logger.Error("Merchant config overrides are invalid", zap.String("agent_id", req.AgentID), zap.Error(err))
+		return nil, err
+	}
+
 	var paymentMethod *domain.PaymentMethod
-	err := s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+	var pruned []uuid.UUID
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
+		// Hold the customer under the merchant's saved payment method cap
+		var err error
+		pruned, err = makeRoomForPaymentMethod(ctx, q, config, req.AgentID, req.CustomerID)
+		if err != nil {
+			return err
+		}
+
 		// If this is set as default, unset all other defaults first
 		if req.IsDefault {
-			err := q.SetPaymentMethodAsDefault(ctx, sqlc.SetPaymentMethodAsDefaultParams{
-				AgentID:    req.AgentID,
-				CustomerID: req.CustomerID,
-			})
-			if err != nil {
-				s.logger.Warn("Failed to unset existing defaults", zap.Error(err))
+			if err := clearDefault(ctx, q, req.AgentID, req.CustomerID); err != nil {
+				return err
 			}
 		}
 
@@ -114,16 +130,21 @@ func (s *paymentMethodService) SavePaymentMethod(ctx context.Context, req *ports
 			IsActive:     pgtype.Bool{Bool: true, Valid: true},
 			IsVerified:   pgtype.Bool{Bool: req.PaymentType == domain.PaymentMethodTypeCreditCard, Valid: true}, // Credit cards don't need verification
 		}
+		params.IdempotencyKey = toNullableText(req.IdempotencyKey)
 
 		dbPM, err := q.CreatePaymentMethod(ctx, params)
 		if err != nil {
-			return fmt.Errorf("failed to create payment method: %w", err)
+			return fmt.Errorf("failed to create payment method: %w", defaultConflictError(err))
 		}
 
 		paymentMethod = sqlcPaymentMethodToDomain(&dbPM)
 		return nil
 	})
 
+	if isIdempotencyKeyConflict(err) {
+		// A concurrent request with the same key saved the payment method first
+		return s.getPaymentMethodByIdempotencyKey(ctx, req.AgentID, req.IdempotencyKey)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +152,7 @@ func (s *paymentMethodService) SavePaymentMethod(ctx context.Context, req *ports
 	s.logger.Info("Payment method saved",
 		zap.String("payment_method_id", paymentMethod.ID),
 		zap.Bool("is_default", paymentMethod.IsDefault),
+		zap.Int("pruned", len(pruned)),
 	)
 
 	return paymentMethod, nil
@@ -147,14 +169,8 @@ func (s *paymentMethodService) ConvertFinancialBRICToStorageBRIC(ctx context.Con
 	)
 
 	// Check idempotency
-	if req.IdempotencyKey != nil {
-		existing, err := s.getPaymentMethodByIdempotencyKey(ctx, *req.IdempotencyKey)
-		if err == nil {
-			s.logger.Info("Idempotent request, returning existing payment method",
-				zap.String("payment_method_id", existing.ID),
-			)
-			return existing, nil
-		}
+	if existing, err := s.getPaymentMethodByIdempotencyKey(ctx, req.AgentID, req.IdempotencyKey); err != nil || existing != nil {
+		return existing, err
 	}
 
 	// Validate required fields
@@ -185,14 +201,25 @@ func (s *paymentMethodService) ConvertFinancialBRICToStorageBRIC(ctx context.Con
 	if err != nil {
 		return nil, fmt.Errorf("failed to get agent: %w", err)
 	}
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		s.logger.Error("Merchant config overrides are invalid", zap.String("agent_id", req.AgentID), zap.Error(err))
+		return nil, err
+	}
 
 	if !agent.IsActive.Valid || !agent.IsActive.Bool {
 		return nil, fmt.Errorf("agent is not active")
 	}
+	if err := s.checkEPXEnvironment(&agent); err != nil {
+		return nil, err
+	}
 
-	// Build BRIC Storage request
+	// Build BRIC Storage request; converting the same Financial BRIC again reuses its TRAN_NBR
 	batchID := fmt.Sprintf("BRIC-%d", time.Now().Unix())
-	tranNbr := uuid.New().String()
+	tranNbr, err := database.AllocateTranNbr(ctx, s.db.Queries(), agent.AgentID, database.TranNbrRequestID("bric-conversion", req.TransactionID))
+	if err != nil {
+		return nil, err
+	}
 
 	bricReq := &adapterports.BRICStorageRequest{
 		CustNbr:       agent.CustNbr,
@@ -200,7 +227,7 @@ func (s *paymentMethodService) ConvertFinancialBRICToStorageBRIC(ctx context.Con
 		DBAnbr:        agent.DbaNbr,
 		TerminalNbr:   agent.TerminalNbr,
 		BatchID:       batchID,
-		TranNbr:       tranNbr,
+		TranNbr:       strconv.FormatInt(tranNbr, 10),
 		PaymentType:   adapterports.PaymentMethodType(req.PaymentType),
 		FinancialBRIC: &req.FinancialBRIC,
 		FirstName:     req.FirstName,
@@ -242,45 +269,38 @@ func (s *paymentMethodService) ConvertFinancialBRICToStorageBRIC(ctx context.Con
 
 	// Save Storage BRIC to payment_methods table
 	var paymentMethod *domain.PaymentMethod
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+	var pruned []uuid.UUID
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
+		// Hold the customer under the merchant's saved payment method cap
+		var err error
+		pruned, err = makeRoomForPaymentMethod(ctx, q, config, req.AgentID, req.CustomerID)
+		if err != nil {
+			return err
+		}
+
 		// If this is set as default, unset all other defaults first
 		if req.IsDefault {
-			err := q.SetPaymentMethodAsDefault(ctx, sqlc.SetPaymentMethodAsDefaultParams{
-				AgentID:    req.AgentID,
-				CustomerID: req.CustomerID,
-			})
-			if err != nil {
-				s.logger.Warn("Failed to unset existing defaults", zap.Error(err))
+			if err := clearDefault(ctx, q, req.AgentID, req.CustomerID); err != nil {
+				return err
 			}
 		}
 
 		// Create payment method with Storage BRIC
-		params := sqlc.CreatePaymentMethodParams{
-			ID:           uuid.New(),
-			AgentID:      req.AgentID,
-			CustomerID:   req.CustomerID,
-			PaymentType:  string(req.PaymentType),
-			PaymentToken: bricResp.StorageBRIC, // Storage BRIC (never expires)
-			LastFour:     req.LastFour,
-			CardBrand:    toNullableText(req.CardBrand),
-			CardExpMonth: toNullableInt32(req.CardExpMonth),
-			CardExpYear:  toNullableInt32(req.CardExpYear),
-			BankName:     toNullableText(req.BankName),
-			AccountType:  toNullableText(req.AccountType),
-			IsDefault:    pgtype.Bool{Bool: req.IsDefault, Valid: true},
-			IsActive:     pgtype.Bool{Bool: true, Valid: true},
-			IsVerified:   pgtype.Bool{Bool: req.PaymentType == domain.PaymentMethodTypeCreditCard, Valid: true}, // Credit cards verified via Account Verification
-		}
-
+		params := storagePaymentMethodParams(req, bricResp.StorageBRIC)
+		params.IdempotencyKey = toNullableText(req.IdempotencyKey)
 		dbPM, err := q.CreatePaymentMethod(ctx, params)
 		if err != nil {
-			return fmt.Errorf("failed to create payment method: %w", err)
+			return fmt.Errorf("failed to create payment method: %w", defaultConflictError(err))
 		}
 
 		paymentMethod = sqlcPaymentMethodToDomain(&dbPM)
 		return nil
 	})
 
+	if isIdempotencyKeyConflict(err) {
+		// A concurrent request with the same key saved the payment method first
+		return s.getPaymentMethodByIdempotencyKey(ctx, req.AgentID, req.IdempotencyKey)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -289,6 +309,7 @@ func (s *paymentMethodService) ConvertFinancialBRICToStorageBRIC(ctx context.Con
 		zap.String("payment_method_id", paymentMethod.ID),
 		zap.String("storage_bric", bricResp.StorageBRIC),
 		zap.Bool("is_default", paymentMethod.IsDefault),
+		zap.Int("pruned", len(pruned)),
 	)
 
 	// Log Network Transaction ID if present (for compliance)
@@ -302,6 +323,148 @@ func (s *paymentMethodService) ConvertFinancialBRICToStorageBRIC(ctx context.Con
 	return paymentMethod, nil
 }
 
+// TokenizePaymentMethod exchanges card data for a Storage BRIC over Server Post and saves it, without charging.
+// The card number and CVV only go to EPX: they are never logged and the saved row keeps just the last four.
+func (s *paymentMethodService) TokenizePaymentMethod(ctx context.Context, req *ports.TokenizePaymentMethodRequest) (*domain.PaymentMethod, error) {
+	s.logger.Info("Tokenizing payment method",
+		zap.String("agent_id", req.AgentID),
+		zap.String("customer_id", req.CustomerID),
+	)
+
+	// Check idempotency
+	if existing, err := s.getPaymentMethodByIdempotencyKey(ctx, req.AgentID, req.IdempotencyKey); err != nil || existing != nil {
+		return existing, err
+	}
+
+	// Validate required fields
+	if len(req.CardNumber) < 13 {
+		return nil, fmt.Errorf("card_number must be 13-19 digits")
+	}
+	if err := domain.ValidateCardExpiry(req.CardExpMonth, req.CardExpYear, time.Now()); err != nil {
+		return nil, err
+	}
+	// Billing information is required for Account Verification
+	if req.Address == nil || req.ZipCode == nil {
+		return nil, fmt.Errorf("billing address and zip code are required for card tokenization")
+	}
+
+	// Get agent credentials
+	agent, err := s.db.Queries().GetAgentByAgentID(ctx, req.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		s.logger.Error("Merchant config overrides are invalid", zap.String("agent_id", req.AgentID), zap.Error(err))
+		return nil, err
+	}
+
+	if !agent.IsActive.Valid || !agent.IsActive.Bool {
+		return nil, fmt.Errorf("agent is not active")
+	}
+	// Suspended and closed merchants can't send cards to EPX
+	if err := domain.MerchantStatus(agent.Status).CheckCharge(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEPXEnvironment(&agent); err != nil {
+		return nil, err
+	}
+
+	// A retry under the same idempotency key reuses the TRAN_NBR
+	requestID := uuid.New()
+	if req.IdempotencyKey != nil {
+		requestID = database.TranNbrRequestID("tokenize", req.AgentID, *req.IdempotencyKey)
+	}
+	tranNbr, err := database.AllocateTranNbr(ctx, s.db.Queries(), agent.AgentID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := s.tokenize(ctx, &agent, req, tranNbr)
+	if err != nil {
+		return nil, err
+	}
+	params.IdempotencyKey = toNullableText(req.IdempotencyKey)
+
+	// Save Storage BRIC to payment_methods table
+	var paymentMethod *domain.PaymentMethod
+	var pruned []uuid.UUID
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
+		// Hold the customer under the merchant's saved payment method cap
+		var err error
+		pruned, err = makeRoomForPaymentMethod(ctx, q, config, req.AgentID, req.CustomerID)
+		if err != nil {
+			return err
+		}
+
+		// If this is set as default, unset all other defaults first
+		if req.IsDefault {
+			if err := clearDefault(ctx, q, req.AgentID, req.CustomerID); err != nil {
+				return err
+			}
+		}
+
+		dbPM, err := q.CreatePaymentMethod(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to create payment method: %w", defaultConflictError(err))
+		}
+
+		paymentMethod = sqlcPaymentMethodToDomain(&dbPM)
+		return nil
+	})
+
+	if isIdempotencyKeyConflict(err) {
+		// A concurrent request with the same key saved the payment method first
+		return s.getPaymentMethodByIdempotencyKey(ctx, req.AgentID, req.IdempotencyKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Tokenized payment method saved",
+		zap.String("payment_method_id", paymentMethod.ID),
+		zap.Bool("is_default", paymentMethod.IsDefault),
+		zap.Int("pruned", len(pruned)),
+	)
+
+	return paymentMethod, nil
+}
+
+// tokenize sends the card to EPX as a BRIC Storage transaction and returns the payment method row for the
+// Storage BRIC it comes back with
+func (s *paymentMethodService) tokenize(ctx context.Context, agent *sqlc.AgentCredential, req *ports.TokenizePaymentMethodRequest, tranNbr int64) (sqlc.CreatePaymentMethodParams, error) {
+	epxResp, err := s.serverPost.ProcessTransaction(ctx, tokenizeServerPostRequest(agent, req, tranNbr))
+	if err != nil {
+		s.logger.Error("EPX card tokenization failed", zap.Error(err))
+		return sqlc.CreatePaymentMethodParams{}, fmt.Errorf("failed to tokenize card: %w", err)
+	}
+
+	if !epxResp.IsApproved {
+		s.logger.Warn("EPX card tokenization declined",
+			zap.String("auth_resp", epxResp.AuthResp),
+			zap.String("auth_resp_text", epxResp.AuthRespText),
+		)
+		return sqlc.CreatePaymentMethodParams{}, fmt.Errorf("%w: %s", domain.ErrTransactionDeclined, epxResp.AuthRespText)
+	}
+	if epxResp.AuthGUID == "" {
+		return sqlc.CreatePaymentMethodParams{}, fmt.Errorf("EPX approved tokenization without a Storage BRIC")
+	}
+
+	s.logger.Info("Storage BRIC created from card data",
+		zap.String("auth_resp", epxResp.AuthResp),
+		zap.String("auth_card_type", epxResp.AuthCardType),
+	)
+
+	// Log Network Transaction ID if present (for compliance)
+	if epxResp.NetworkTransactionID != nil {
+		s.logger.Info("Network Transaction ID obtained for card-on-file compliance",
+			zap.String("ntid", *epxResp.NetworkTransactionID),
+		)
+	}
+
+	return tokenizedPaymentMethodParams(req, epxResp), nil
+}
+
 // GetPaymentMethod retrieves a specific payment method
 func (s *paymentMethodService) GetPaymentMethod(ctx context.Context, paymentMethodID string) (*domain.PaymentMethod, error) {
 	pmID, err := uuid.Parse(paymentMethodID)
@@ -341,6 +504,34 @@ func (s *paymentMethodService) ListPaymentMethods(ctx context.Context, agentID,
 	return paymentMethods, nil
 }
 
+// MaxExpiringCardWindowDays bounds how far ahead the expiring-card report looks
+const MaxExpiringCardWindowDays = 365
+
+// ListExpiringPaymentMethods lists a merchant's active cards that expire within the given number of days
+// (already expired cards are excluded)
+func (s *paymentMethodService) ListExpiringPaymentMethods(ctx context.Context, agentID string, withinDays int) ([]*domain.PaymentMethod, error) {
+	if withinDays < 1 || withinDays > MaxExpiringCardWindowDays {
+		return nil, fmt.Errorf("%w: within_days must be between 1 and %d", domain.ErrInvalidTimeRange, MaxExpiringCardWindowDays)
+	}
+
+	now := time.Now()
+	dbPMs, err := s.db.Queries().ListExpiringPaymentMethods(ctx, sqlc.ListExpiringPaymentMethodsParams{
+		AgentID: pgtype.Text{String: agentID, Valid: true},
+		AsOf:    pgtype.Date{Time: now, Valid: true},
+		Cutoff:  pgtype.Date{Time: now.AddDate(0, 0, withinDays), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expiring payment methods: %w", err)
+	}
+
+	paymentMethods := make([]*domain.PaymentMethod, len(dbPMs))
+	for i, dbPM := range dbPMs {
+		paymentMethods[i] = sqlcPaymentMethodToDomain(&dbPM)
+	}
+
+	return paymentMethods, nil
+}
+
 // UpdatePaymentMethodStatus updates the active status of a payment method
 func (s *paymentMethodService) UpdatePaymentMethodStatus(ctx context.Context, paymentMethodID, agentID, customerID string, isActive bool) (*domain.PaymentMethod, error) {
 	action := "deactivating"
@@ -394,6 +585,52 @@ func (s *paymentMethodService) UpdatePaymentMethodStatus(ctx context.Context, pa
 	return sqlcPaymentMethodToDomain(&updated), nil
 }
 
+// UpdatePaymentMethodExpiry updates a stored card's expiration date without re-tokenizing
+func (s *paymentMethodService) UpdatePaymentMethodExpiry(ctx context.Context, req *ports.UpdatePaymentMethodExpiryRequest) (*domain.PaymentMethod, error) {
+	s.logger.Info("Updating payment method expiry",
+		zap.String("payment_method_id", req.PaymentMethodID),
+	)
+
+	if err := domain.ValidateCardExpiry(req.CardExpMonth, req.CardExpYear, time.Now()); err != nil {
+		return nil, err
+	}
+
+	pmID, err := uuid.Parse(req.PaymentMethodID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment_method_id format: %w", err)
+	}
+
+	pm, err := s.db.Queries().GetPaymentMethodByID(ctx, pmID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrPaymentMethodNotFound, err)
+	}
+
+	if pm.AgentID != req.AgentID || pm.CustomerID != req.CustomerID {
+		return nil, domain.ErrPaymentMethodNotFound
+	}
+
+	if pm.PaymentType != string(domain.PaymentMethodTypeCreditCard) {
+		return nil, fmt.Errorf("%w: only credit cards have an expiration date", domain.ErrInvalidPaymentMethodType)
+	}
+
+	updated, err := s.db.Queries().UpdatePaymentMethodExpiry(ctx, sqlc.UpdatePaymentMethodExpiryParams{
+		ID:           pmID,
+		CardExpMonth: toNullableInt32(&req.CardExpMonth),
+		CardExpYear:  toNullableInt32(&req.CardExpYear),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update payment method expiry: %w", err)
+	}
+
+	s.logger.Info("Payment method expiry updated",
+		zap.String("payment_method_id", req.PaymentMethodID),
+		zap.Int("card_exp_month", req.CardExpMonth),
+		zap.Int("card_exp_year", req.CardExpYear),
+	)
+
+	return sqlcPaymentMethodToDomain(&updated), nil
+}
+
 // DeletePaymentMethod soft deletes a payment method (sets deleted_at)
 func (s *paymentMethodService) DeletePaymentMethod(ctx context.Context, paymentMethodID string) error {
 	s.logger.Info("Deleting payment method",
@@ -445,20 +682,9 @@ func (s *paymentMethodService) SetDefaultPaymentMethod(ctx context.Context, paym
 	}
 
 	var paymentMethod *domain.PaymentMethod
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
-		// Unset all defaults for this customer
-		err := q.SetPaymentMethodAsDefault(ctx, sqlc.SetPaymentMethodAsDefaultParams{
-			AgentID:    agentID,
-			CustomerID: customerID,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to unset existing defaults: %w", err)
-		}
-
-		// Set this one as default
-		err = q.MarkPaymentMethodAsDefault(ctx, pmID)
-		if err != nil {
-			return fmt.Errorf("failed to set as default: %w", err)
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
+		if err := makeDefault(ctx, q, agentID, customerID, pmID); err != nil {
+			return err
 		}
 
 		// Fetch updated payment method
@@ -526,6 +752,9 @@ func (s *paymentMethodService) VerifyACHAccount(ctx context.Context, req *ports.
 	if !agent.IsActive.Valid || !agent.IsActive.Bool {
 		return fmt.Errorf("agent is not active")
 	}
+	if err := s.checkEPXEnvironment(&agent); err != nil {
+		return err
+	}
 
 	// Get MAC secret
 	_, err = s.secretManager.GetSecret(ctx, agent.MacSecretPath)
@@ -533,6 +762,12 @@ func (s *paymentMethodService) VerifyACHAccount(ctx context.Context, req *ports.
 		return fmt.Errorf("failed to get MAC secret: %w", err)
 	}
 
+	// A retried pre-note for the payment method reuses its TRAN_NBR
+	tranNbr, err := database.AllocateTranNbr(ctx, s.db.Queries(), agent.AgentID, database.TranNbrRequestID("prenote", pm.ID.String()))
+	if err != nil {
+		return err
+	}
+
 	// Send pre-note transaction through EPX
 	epxReq := &adapterports.ServerPostRequest{
 		CustNbr:         agent.CustNbr,
@@ -543,7 +778,7 @@ func (s *paymentMethodService) VerifyACHAccount(ctx context.Context, req *ports.
 		Amount:          "0.00", // Pre-note is $0
 		PaymentType:     adapterports.PaymentMethodTypeACH,
 		AuthGUID:        pm.PaymentToken,
-		TranNbr:         uuid.New().String(),
+		TranNbr:         strconv.FormatInt(tranNbr, 10),
 		TranGroup:       uuid.New().String(),
 		CustomerID:      req.CustomerID,
 	}
@@ -559,7 +794,7 @@ func (s *paymentMethodService) VerifyACHAccount(ctx context.Context, req *ports.
 	}
 
 	// Mark as verified
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
 		err := q.MarkPaymentMethodVerified(ctx, pmID)
 		if err != nil {
 			return fmt.Errorf("failed to mark as verified: %w", err)
@@ -579,19 +814,383 @@ func (s *paymentMethodService) VerifyACHAccount(ctx context.Context, req *ports.
 	return nil
 }
 
-// getPaymentMethodByIdempotencyKey retrieves a payment method by idempotency key
-func (s *paymentMethodService) getPaymentMethodByIdempotencyKey(ctx context.Context, key string) (*domain.PaymentMethod, error) {
-	// Note: This would require adding idempotency_key to payment_methods table
-	// For now, returning not found error
-	return nil, fmt.Errorf("payment method not found")
+// ProcessACHReturn records an ACH return and deactivates the payment method on unrecoverable returns
+func (s *paymentMethodService) ProcessACHReturn(ctx context.Context, req *ports.ProcessACHReturnRequest) (*domain.PaymentMethod, error) {
+	s.logger.Info("Processing ACH return",
+		zap.String("payment_method_id", req.PaymentMethodID),
+		zap.String("return_code", req.ReturnCode),
+	)
+
+	pmID, err := uuid.Parse(req.PaymentMethodID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment_method_id format: %w", err)
+	}
+
+	pm, err := s.db.Queries().GetPaymentMethodByID(ctx, pmID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrPaymentMethodNotFound, err)
+	}
+
+	if pm.AgentID != req.AgentID {
+		return nil, domain.ErrPaymentMethodNotFound
+	}
+
+	if pm.PaymentType != string(domain.PaymentMethodTypeACH) {
+		return nil, fmt.Errorf("%w: ACH returns only apply to ACH payment methods", domain.ErrInvalidPaymentMethodType)
+	}
+
+	outcome, err := domain.EvaluateACHReturn(req.ReturnCode, int(pm.ReturnCount))
+	if err != nil {
+		return nil, err
+	}
+
+	params := sqlc.RecordACHReturnParams{
+		ID:         pmID,
+		ReturnCode: outcome.ReturnCode.Code,
+	}
+	if outcome.Deactivate {
+		params.DeactivationReason = pgtype.Text{String: string(*outcome.Reason), Valid: true}
+	}
+
+	updated, err := s.db.Queries().RecordACHReturn(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record ACH return: %w", err)
+	}
+
+	fields := []zap.Field{
+		zap.String("payment_method_id", req.PaymentMethodID),
+		zap.String("return_code", outcome.ReturnCode.Code),
+		zap.String("description", outcome.ReturnCode.Description),
+		zap.Int32("return_count", updated.ReturnCount),
+	}
+	if req.TransactionID != nil {
+		fields = append(fields, zap.String("transaction_id", *req.TransactionID))
+	}
+	if outcome.Deactivate {
+		s.logger.Warn("ACH payment method deactivated after return",
+			append(fields, zap.String("deactivation_reason", string(*outcome.Reason)))...,
+		)
+	} else {
+		s.logger.Info("ACH return recorded", fields...)
+	}
+
+	return sqlcPaymentMethodToDomain(&updated), nil
+}
+
+// InitiateMicroDeposits stores the hashed amounts of two small ACH credits and sends them
+func (s *paymentMethodService) InitiateMicroDeposits(ctx context.Context, req *ports.InitiateMicroDepositsRequest) (*domain.PaymentMethod, error) {
+	s.logger.Info("Initiating ACH micro-deposits",
+		zap.String("payment_method_id", req.PaymentMethodID),
+	)
+
+	pm, err := s.getCustomerACHPaymentMethod(ctx, req.PaymentMethodID, req.AgentID, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pm.IsVerified.Valid && pm.IsVerified.Bool {
+		return sqlcPaymentMethodToDomain(pm), nil
+	}
+
+	if !pm.IsActive.Valid || !pm.IsActive.Bool {
+		return nil, domain.ErrPaymentMethodInactive
+	}
+
+	agent, err := s.db.Queries().GetAgentByAgentID(ctx, req.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	if !agent.IsActive.Valid || !agent.IsActive.Bool {
+		return nil, domain.ErrAgentInactive
+	}
+	if err := s.checkEPXEnvironment(&agent); err != nil {
+		return nil, err
+	}
+
+	// Get MAC secret
+	_, err = s.secretManager.GetSecret(ctx, agent.MacSecretPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MAC secret: %w", err)
+	}
+
+	deposits, err := domain.GenerateMicroDeposits(nil)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := domain.HashMicroDeposits(deposits, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the amounts before sending, so credits that reach the account can always be verified. If a send
+	// fails, initiating again replaces them with new amounts.
+	err = s.db.Queries().SetMicroDeposits(ctx, sqlc.SetMicroDepositsParams{
+		ID:               pm.ID,
+		MicroDepositHash: pgtype.Text{String: hash, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store micro-deposits: %w", err)
+	}
+
+	// Send both credits in one transaction group. Each send has new amounts, so each credit gets a new TRAN_NBR.
+	tranGroup := uuid.New().String()
+	for _, amount := range deposits.Amounts() {
+		tranNbr, err := database.AllocateTranNbr(ctx, s.db.Queries(), agent.AgentID, uuid.New())
+		if err != nil {
+			return nil, err
+		}
+		epxResp, err := s.serverPost.ProcessTransaction(ctx, &adapterports.ServerPostRequest{
+			CustNbr:         agent.CustNbr,
+			MerchNbr:        agent.MerchNbr,
+			DBAnbr:          agent.DbaNbr,
+			TerminalNbr:     agent.TerminalNbr,
+			TransactionType: adapterports.TransactionTypeACHCredit,
+			Amount:          amount,
+			PaymentType:     adapterports.PaymentMethodTypeACH,
+			AuthGUID:        pm.PaymentToken,
+			TranNbr:         strconv.FormatInt(tranNbr, 10),
+			TranGroup:       tranGroup,
+			CustomerID:      req.CustomerID,
+		})
+		if err != nil {
+			s.logger.Error("EPX micro-deposit failed", zap.Error(err))
+			return nil, fmt.Errorf("failed to send micro-deposit: %w", err)
+		}
+		if !epxResp.IsApproved {
+			return nil, fmt.Errorf("micro-deposit was declined: %s", epxResp.AuthRespText)
+		}
+	}
+
+	updated, err := s.db.Queries().GetPaymentMethodByID(ctx, pm.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch updated payment method: %w", err)
+	}
+
+	s.logger.Info("ACH micro-deposits sent",
+		zap.String("payment_method_id", req.PaymentMethodID),
+	)
+
+	return sqlcPaymentMethodToDomain(&updated), nil
+}
+
+// VerifyMicroDeposits checks the customer's amounts and marks the ACH payment method verified.
+// Wrong guesses are counted; verification locks after domain.MaxMicroDepositAttempts failures.
+func (s *paymentMethodService) VerifyMicroDeposits(ctx context.Context, req *ports.VerifyMicroDepositsRequest) (*domain.PaymentMethod, error) {
+	s.logger.Info("Verifying ACH micro-deposits",
+		zap.String("payment_method_id", req.PaymentMethodID),
+	)
+
+	guess, err := domain.ParseMicroDeposits(req.Amounts)
+	if err != nil {
+		return nil, err
+	}
+
+	pm, err := s.getCustomerACHPaymentMethod(ctx, req.PaymentMethodID, req.AgentID, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pm.IsVerified.Valid && pm.IsVerified.Bool {
+		return sqlcPaymentMethodToDomain(pm), nil
+	}
+
+	// Count the attempt before checking it, in one statement, so concurrent guesses can't get past the limit
+	attempt, err := s.db.Queries().RecordMicroDepositAttempt(ctx, sqlc.RecordMicroDepositAttemptParams{
+		ID:          pm.ID,
+		MaxAttempts: domain.MaxMicroDepositAttempts,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		if !pm.MicroDepositHash.Valid {
+			return nil, domain.ErrMicroDepositsNotInitiated
+		}
+		return nil, domain.ErrMicroDepositsLocked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record micro-deposit attempt: %w", err)
+	}
+
+	checkErr := domain.CheckMicroDepositAttempt(attempt.MicroDepositHash.String, int(attempt.MicroDepositAttempts)-1, guess)
+	if checkErr == nil {
+		verified, err := s.db.Queries().CompleteMicroDepositVerification(ctx, pm.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark as verified: %w", err)
+		}
+
+		s.logger.Info("ACH account verified by micro-deposits",
+			zap.String("payment_method_id", req.PaymentMethodID),
+		)
+		return sqlcPaymentMethodToDomain(&verified), nil
+	}
+
+	s.logger.Warn("ACH micro-deposit verification failed",
+		zap.String("payment_method_id", req.PaymentMethodID),
+		zap.Int32("failed_attempts", attempt.MicroDepositAttempts),
+	)
+	return nil, checkErr
+}
+
+// checkEPXEnvironment refuses to send a merchant's credentials to EPX adapters of the other environment
+func (s *paymentMethodService) checkEPXEnvironment(agent *sqlc.AgentCredential) error {
+	if err := database.CheckEPXEnvironment(agent, s.epxEnv); err != nil {
+		s.logger.Error("Merchant environment doesn't match the EPX environment",
+			zap.String("agent_id", agent.AgentID),
+			zap.String("merchant_environment", agent.Environment),
+			zap.String("epx_environment", string(s.epxEnv)),
+		)
+		return err
+	}
+	return nil
+}
+
+// getCustomerACHPaymentMethod loads an ACH payment method owned by the customer
+func (s *paymentMethodService) getCustomerACHPaymentMethod(ctx context.Context, paymentMethodID, agentID, customerID string) (*sqlc.CustomerPaymentMethod, error) {
+	pmID, err := uuid.Parse(paymentMethodID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment_method_id format: %w", err)
+	}
+
+	pm, err := s.db.Queries().GetPaymentMethodByID(ctx, pmID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrPaymentMethodNotFound, err)
+	}
+
+	if pm.AgentID != agentID || pm.CustomerID != customerID {
+		return nil, domain.ErrPaymentMethodNotFound
+	}
+
+	if pm.PaymentType != string(domain.PaymentMethodTypeACH) {
+		return nil, fmt.Errorf("%w: micro-deposits only apply to ACH payment methods", domain.ErrInvalidPaymentMethodType)
+	}
+
+	return &pm, nil
+}
+
+// getPaymentMethodByIdempotencyKey returns the payment method the merchant's earlier request with the idempotency
+// key saved, or nil when there's no key or no request with it has saved one
+func (s *paymentMethodService) getPaymentMethodByIdempotencyKey(ctx context.Context, agentID string, key *string) (*domain.PaymentMethod, error) {
+	if key == nil {
+		return nil, nil
+	}
+
+	dbPM, err := s.db.Queries().GetPaymentMethodByIdempotencyKey(ctx, sqlc.GetPaymentMethodByIdempotencyKeyParams{
+		AgentID:        agentID,
+		IdempotencyKey: pgtype.Text{String: *key, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+
+	s.logger.Info("Idempotent request, returning existing payment method",
+		zap.String("payment_method_id", dbPM.ID.String()),
+	)
+	return sqlcPaymentMethodToDomain(&dbPM), nil
 }
 
 // Helper functions
 
+// storagePaymentMethodParams is the payment method row saved for a converted Storage BRIC. Cards were verified by
+// EPX Account Verification during the conversion; ACH accounts start unverified, so they go through the
+// pre-note or micro-deposit verification flow before they can be charged.
+func storagePaymentMethodParams(req *ports.ConvertFinancialBRICRequest, storageBRIC string) sqlc.CreatePaymentMethodParams {
+	return sqlc.CreatePaymentMethodParams{
+		ID:           uuid.New(),
+		AgentID:      req.AgentID,
+		CustomerID:   req.CustomerID,
+		PaymentType:  string(req.PaymentType),
+		PaymentToken: storageBRIC, // Storage BRIC (never expires)
+		LastFour:     req.LastFour,
+		CardBrand:    toNullableText(req.CardBrand),
+		CardExpMonth: toNullableInt32(req.CardExpMonth),
+		CardExpYear:  toNullableInt32(req.CardExpYear),
+		BankName:     toNullableText(req.BankName),
+		AccountType:  toNullableText(req.AccountType),
+		IsDefault:    pgtype.Bool{Bool: req.IsDefault, Valid: true},
+		IsActive:     pgtype.Bool{Bool: true, Valid: true},
+		IsVerified:   pgtype.Bool{Bool: req.PaymentType == domain.PaymentMethodTypeCreditCard, Valid: true},
+	}
+}
+
+// tokenizeServerPostRequest is the CCE8 BRIC Storage transaction for raw card data. No amount is sent:
+// EPX runs a $0.00 Account Verification instead of charging the card.
+func tokenizeServerPostRequest(agent *sqlc.AgentCredential, req *ports.TokenizePaymentMethodRequest, tranNbr int64) *adapterports.ServerPostRequest {
+	expDate := fmt.Sprintf("%02d%02d", req.CardExpYear%100, req.CardExpMonth) // YYMM
+	cardEntryMethod := "E"                                                    // Account-based (keyed) entry
+	industryType := "E"                                                       // Ecommerce
+
+	epxReq := &adapterports.ServerPostRequest{
+		CustNbr:         agent.CustNbr,
+		MerchNbr:        agent.MerchNbr,
+		DBAnbr:          agent.DbaNbr,
+		TerminalNbr:     agent.TerminalNbr,
+		TransactionType: adapterports.TransactionTypeBRICStorageCC,
+		PaymentType:     adapterports.PaymentMethodTypeCreditCard,
+		TranNbr:         strconv.FormatInt(tranNbr, 10),
+		TranGroup:       uuid.New().String(),
+		AccountNumber:   &req.CardNumber,
+		ExpirationDate:  &expDate,
+		FirstName:       req.FirstName,
+		LastName:        req.LastName,
+		Address:         req.Address,
+		City:            req.City,
+		State:           req.State,
+		ZipCode:         req.ZipCode,
+		CardEntryMethod: &cardEntryMethod,
+		IndustryType:    &industryType,
+		CustomerID:      req.CustomerID,
+	}
+	if req.CVV != "" {
+		epxReq.CVV = &req.CVV
+	}
+	return epxReq
+}
+
+// tokenizedPaymentMethodParams is the payment method row saved for a tokenized card. Only display metadata is
+// kept alongside the Storage BRIC; the card number and CVV never reach the database.
+func tokenizedPaymentMethodParams(req *ports.TokenizePaymentMethodRequest, resp *adapterports.ServerPostResponse) sqlc.CreatePaymentMethodParams {
+	lastFour := req.CardNumber[len(req.CardNumber)-4:]
+	return sqlc.CreatePaymentMethodParams{
+		ID:           uuid.New(),
+		AgentID:      req.AgentID,
+		CustomerID:   req.CustomerID,
+		PaymentType:  string(domain.PaymentMethodTypeCreditCard),
+		PaymentToken: resp.AuthGUID, // Storage BRIC (never expires)
+		LastFour:     lastFour,
+		CardBrand:    toNullableText(cardBrandName(resp.AuthCardType)),
+		CardExpMonth: toNullableInt32(&req.CardExpMonth),
+		CardExpYear:  toNullableInt32(&req.CardExpYear),
+		IsDefault:    pgtype.Bool{Bool: req.IsDefault, Valid: true},
+		IsActive:     pgtype.Bool{Bool: true, Valid: true},
+		IsVerified:   pgtype.Bool{Bool: true, Valid: true}, // Verified by Account Verification
+	}
+}
+
+// cardBrandName converts an EPX AUTH_CARD_TYPE code to the brand name saved with the payment method
+func cardBrandName(code string) *string {
+	if code == "" {
+		return nil
+	}
+	brands := map[string]string{
+		"V": "Visa",
+		"M": "Mastercard",
+		"A": "American Express",
+		"D": "Discover",
+		"J": "JCB",
+	}
+	if name, ok := brands[strings.ToUpper(code)]; ok {
+		return &name
+	}
+	return &code
+}
+
 func sqlcPaymentMethodToDomain(dbPM *sqlc.CustomerPaymentMethod) *domain.PaymentMethod {
 	pm := &domain.PaymentMethod{
 		ID:           dbPM.ID.String(),
 		AgentID:      dbPM.AgentID,
+		DataRegion:   dbPM.DataRegion,
 		CustomerID:   dbPM.CustomerID,
 		PaymentType:  domain.PaymentMethodType(dbPM.PaymentType),
 		PaymentToken: dbPM.PaymentToken,
@@ -629,9 +1228,136 @@ func sqlcPaymentMethodToDomain(dbPM *sqlc.CustomerPaymentMethod) *domain.Payment
 		pm.LastUsedAt = &dbPM.LastUsedAt.Time
 	}
 
+	pm.ReturnCount = int(dbPM.ReturnCount)
+	if dbPM.LastReturnCode.Valid {
+		pm.LastReturnCode = &dbPM.LastReturnCode.String
+	}
+
+	if dbPM.DeactivationReason.Valid {
+		reason := domain.DeactivationReason(dbPM.DeactivationReason.String)
+		pm.DeactivationReason = &reason
+	}
+
+	pm.MicroDepositAttempts = int(dbPM.MicroDepositAttempts)
+	if dbPM.MicroDepositSentAt.Valid {
+		pm.MicroDepositsSentAt = &dbPM.MicroDepositSentAt.Time
+	}
+
 	return pm
 }
 
+// defaultPaymentMethodQueries is the subset of queries that moves a customer's default payment method
+type defaultPaymentMethodQueries interface {
+	LockCustomerPaymentMethods(ctx context.Context, arg sqlc.LockCustomerPaymentMethodsParams) error
+	SetPaymentMethodAsDefault(ctx context.Context, arg sqlc.SetPaymentMethodAsDefaultParams) error
+	MarkPaymentMethodAsDefault(ctx context.Context, id uuid.UUID) error
+}
+
+// oneDefaultIndex allows at most one default payment method per (agent, customer); idempotencyKeyIndex one
+// payment method per merchant idempotency key
+const (
+	oneDefaultIndex     = "idx_customer_payment_methods_one_default"
+	idempotencyKeyIndex = "idx_payment_methods_agent_idempotency_key"
+	uniqueViolation     = "23505" // PostgreSQL unique_violation
+)
+
+// clearDefault unsets the customer's default payment method. Must run inside the caller's WithTx: the customer
+// lock it takes holds off concurrent default changes until the transaction ends.
+func clearDefault(ctx context.Context, q defaultPaymentMethodQueries, agentID, customerID string) error {
+	if err := q.LockCustomerPaymentMethods(ctx, sqlc.LockCustomerPaymentMethodsParams{
+		AgentID:    agentID,
+		CustomerID: customerID,
+	}); err != nil {
+		return fmt.Errorf("failed to lock customer payment methods: %w", err)
+	}
+
+	err := q.SetPaymentMethodAsDefault(ctx, sqlc.SetPaymentMethodAsDefaultParams{
+		AgentID:    agentID,
+		CustomerID: customerID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unset existing defaults: %w", err)
+	}
+	return nil
+}
+
+// makeDefault replaces the customer's default payment method with pmID. Must run inside the caller's
+// WithTx so the old default is never cleared without the new one being set (or vice versa).
+func makeDefault(ctx context.Context, q defaultPaymentMethodQueries, agentID, customerID string, pmID uuid.UUID) error {
+	if err := clearDefault(ctx, q, agentID, customerID); err != nil {
+		return err
+	}
+	if err := q.MarkPaymentMethodAsDefault(ctx, pmID); err != nil {
+		return fmt.Errorf("failed to set as default: %w", defaultConflictError(err))
+	}
+	return nil
+}
+
+// defaultConflictError maps a one-default index violation (a concurrent default change that
+// committed first) to ErrDefaultPaymentMethodConflict
+func defaultConflictError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == oneDefaultIndex {
+		return domain.ErrDefaultPaymentMethodConflict
+	}
+	return err
+}
+
+// isIdempotencyKeyConflict reports whether an insert lost to a concurrent request with the same idempotency key
+func isIdempotencyKeyConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == idempotencyKeyIndex
+}
+
+// savedPaymentMethodLimitQueries is the subset of queries that keeps a customer under the saved payment method cap
+type savedPaymentMethodLimitQueries interface {
+	LockCustomerPaymentMethods(ctx context.Context, arg sqlc.LockCustomerPaymentMethodsParams) error
+	ListActivePaymentMethodsByLastUse(ctx context.Context, arg sqlc.ListActivePaymentMethodsByLastUseParams) ([]uuid.UUID, error)
+	DeletePaymentMethod(ctx context.Context, id uuid.UUID) error
+}
+
+// makeRoomForPaymentMethod makes sure one more active payment method fits under the merchant's cap, either by
+// rejecting the save with ErrSavedPaymentMethodLimit or (prune_lru policy) by deleting the least recently used
+// ones. Must run inside the caller's WithTx before the insert. Returns the deleted IDs.
+func makeRoomForPaymentMethod(ctx context.Context, q savedPaymentMethodLimitQueries, config *domain.MerchantConfig, agentID, customerID string) ([]uuid.UUID, error) {
+	if config.MaxSavedPaymentMethods <= 0 {
+		return nil, nil
+	}
+
+	if err := q.LockCustomerPaymentMethods(ctx, sqlc.LockCustomerPaymentMethodsParams{
+		AgentID:    agentID,
+		CustomerID: customerID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to lock customer payment methods: %w", err)
+	}
+
+	active, err := q.ListActivePaymentMethodsByLastUse(ctx, sqlc.ListActivePaymentMethodsByLastUseParams{
+		AgentID:    agentID,
+		CustomerID: customerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active payment methods: %w", err)
+	}
+
+	excess := len(active) - config.MaxSavedPaymentMethods + 1
+	if excess <= 0 {
+		return nil, nil
+	}
+	if config.SavedPaymentMethodPolicy != domain.SavedPaymentMethodPolicyPruneLRU {
+		return nil, fmt.Errorf("%w: %d of %d saved", domain.ErrSavedPaymentMethodLimit, len(active), config.MaxSavedPaymentMethods)
+	}
+
+	pruned := active[:excess]
+	for _, id := range pruned {
+		if err := q.DeletePaymentMethod(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to prune payment method %s: %w", id, err)
+		}
+	}
+	return pruned, nil
+}
+
+// agentMerchantConfig resolves the agent's effective configuration (invalid overrides are ignored)
+
 func toNullableText(s *string) pgtype.Text {
 	if s == nil {
 		return pgtype.Text{Valid: false}
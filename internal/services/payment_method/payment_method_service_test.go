@@ -0,0 +1,719 @@
+package payment_method
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/ports"
+)
+
+// fakeDefaultStore holds one customer's payment methods. Transactions run concurrently, as they do in
+// PostgreSQL: only LockCustomerPaymentMethods serializes them, holding the customer lock until the transaction
+// ends, and MarkPaymentMethodAsDefault enforces the one-default unique index.
+type fakeDefaultStore struct {
+	customerLock sync.Mutex
+	mu           sync.Mutex
+	isDefault    map[uuid.UUID]bool
+}
+
+func newFakeDefaultStore(ids ...uuid.UUID) *fakeDefaultStore {
+	store := &fakeDefaultStore{isDefault: map[uuid.UUID]bool{}}
+	for _, id := range ids {
+		store.isDefault[id] = false
+	}
+	return store
+}
+
+func (f *fakeDefaultStore) withTx(fn func(q defaultPaymentMethodQueries) error) error {
+	tx := &fakeDefaultTx{store: f}
+	defer func() {
+		if tx.locked {
+			f.customerLock.Unlock()
+		}
+	}()
+	return fn(tx)
+}
+
+// fakeDefaultTx is one transaction against a fakeDefaultStore
+type fakeDefaultTx struct {
+	store  *fakeDefaultStore
+	locked bool
+}
+
+func (tx *fakeDefaultTx) LockCustomerPaymentMethods(ctx context.Context, arg sqlc.LockCustomerPaymentMethodsParams) error {
+	if !tx.locked {
+		tx.store.customerLock.Lock()
+		tx.locked = true
+	}
+	return nil
+}
+
+func (tx *fakeDefaultTx) SetPaymentMethodAsDefault(ctx context.Context, arg sqlc.SetPaymentMethodAsDefaultParams) error {
+	f := tx.store
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, isDefault := range f.isDefault {
+		if isDefault {
+			f.isDefault[id] = false
+		}
+	}
+	return nil
+}
+
+func (tx *fakeDefaultTx) MarkPaymentMethodAsDefault(ctx context.Context, id uuid.UUID) error {
+	f := tx.store
+	// Widen the window between clearing and marking so an unserialized caller is caught
+	runtime.Gosched()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for other, isDefault := range f.isDefault {
+		if isDefault && other != id {
+			return &pgconn.PgError{Code: uniqueViolation, ConstraintName: oneDefaultIndex}
+		}
+	}
+	f.isDefault[id] = true
+	return nil
+}
+
+func (f *fakeDefaultStore) defaults() []uuid.UUID {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ids []uuid.UUID
+	for id, isDefault := range f.isDefault {
+		if isDefault {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestMakeDefault_FlipsPreviousDefaultOff(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	store := newFakeDefaultStore(a, b)
+	ctx := context.Background()
+
+	require.NoError(t, store.withTx(func(q defaultPaymentMethodQueries) error {
+		return makeDefault(ctx, q, "agent-1", "cust-1", a)
+	}))
+	assert.Equal(t, []uuid.UUID{a}, store.defaults())
+
+	require.NoError(t, store.withTx(func(q defaultPaymentMethodQueries) error {
+		return makeDefault(ctx, q, "agent-1", "cust-1", b)
+	}))
+	assert.Equal(t, []uuid.UUID{b}, store.defaults(), "setting B as default flips A off")
+}
+
+func TestMakeDefault_ConcurrentCallsLeaveOneDefault(t *testing.T) {
+	ids := make([]uuid.UUID, 10)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	store := newFakeDefaultStore(ids...)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(ids)*5)
+	for round := 0; round < 5; round++ {
+		for _, id := range ids {
+			wg.Add(1)
+			go func(id uuid.UUID) {
+				defer wg.Done()
+				errs <- store.withTx(func(q defaultPaymentMethodQueries) error {
+					return makeDefault(ctx, q, "agent-1", "cust-1", id)
+				})
+			}(id)
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Len(t, store.defaults(), 1)
+}
+
+func TestDefaultConflictError(t *testing.T) {
+	conflict := &pgconn.PgError{Code: uniqueViolation, ConstraintName: oneDefaultIndex}
+	assert.ErrorIs(t, defaultConflictError(conflict), domain.ErrDefaultPaymentMethodConflict)
+
+	otherUnique := &pgconn.PgError{Code: uniqueViolation, ConstraintName: "customer_payment_methods_pkey"}
+	assert.Equal(t, otherUnique, defaultConflictError(otherUnique))
+
+	plain := errors.New("connection reset")
+	assert.Equal(t, plain, defaultConflictError(plain))
+}
+
+func TestSqlcPaymentMethodToDomain_DataRegion(t *testing.T) {
+	// CreatePaymentMethod stamps the merchant's region on the row it returns
+	created := &sqlc.CustomerPaymentMethod{ID: uuid.New(), AgentID: "merchant-eu", DataRegion: "eu"}
+
+	pm := sqlcPaymentMethodToDomain(created)
+	assert.Equal(t, "eu", pm.DataRegion)
+}
+
+// fakeSavedMethods holds one customer's active payment methods, least recently used first
+type fakeSavedMethods struct {
+	active  []uuid.UUID
+	locked  bool
+	deleted []uuid.UUID
+}
+
+func (f *fakeSavedMethods) LockCustomerPaymentMethods(ctx context.Context, arg sqlc.LockCustomerPaymentMethodsParams) error {
+	f.locked = true
+	return nil
+}
+
+func (f *fakeSavedMethods) ListActivePaymentMethodsByLastUse(ctx context.Context, arg sqlc.ListActivePaymentMethodsByLastUseParams) ([]uuid.UUID, error) {
+	return append([]uuid.UUID(nil), f.active...), nil
+}
+
+func (f *fakeSavedMethods) DeletePaymentMethod(ctx context.Context, id uuid.UUID) error {
+	f.deleted = append(f.deleted, id)
+	for i, active := range f.active {
+		if active == id {
+			f.active = append(f.active[:i], f.active[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func newFakeSavedMethods(n int) *fakeSavedMethods {
+	f := &fakeSavedMethods{}
+	for i := 0; i < n; i++ {
+		f.active = append(f.active, uuid.New())
+	}
+	return f
+}
+
+func savedMethodCap(max int, policy domain.SavedPaymentMethodPolicy) *domain.MerchantConfig {
+	config := domain.DefaultMerchantConfig(domain.MerchantTierStandard)
+	config.MaxSavedPaymentMethods = max
+	config.SavedPaymentMethodPolicy = policy
+	return config
+}
+
+func TestMakeRoomForPaymentMethod_RejectsAtCap(t *testing.T) {
+	ctx := context.Background()
+
+	below := newFakeSavedMethods(2)
+	pruned, err := makeRoomForPaymentMethod(ctx, below, savedMethodCap(3, domain.SavedPaymentMethodPolicyReject), "agent-1", "cust-1")
+	require.NoError(t, err)
+	assert.Empty(t, pruned)
+	assert.True(t, below.locked, "the count is taken under the customer lock")
+
+	atCap := newFakeSavedMethods(3)
+	_, err = makeRoomForPaymentMethod(ctx, atCap, savedMethodCap(3, domain.SavedPaymentMethodPolicyReject), "agent-1", "cust-1")
+	assert.ErrorIs(t, err, domain.ErrSavedPaymentMethodLimit)
+	assert.Len(t, atCap.active, 3, "nothing is removed when rejecting")
+	assert.Empty(t, atCap.deleted)
+}
+
+func TestMakeRoomForPaymentMethod_PruneLRURemovesOldest(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeSavedMethods(3)
+	oldest, rest := store.active[0], append([]uuid.UUID(nil), store.active[1:]...)
+
+	pruned, err := makeRoomForPaymentMethod(ctx, store, savedMethodCap(3, domain.SavedPaymentMethodPolicyPruneLRU), "agent-1", "cust-1")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{oldest}, pruned)
+	assert.Equal(t, []uuid.UUID{oldest}, store.deleted)
+	assert.Equal(t, rest, store.active)
+
+	// A cap lowered below the current count prunes down to make room for one more
+	store = newFakeSavedMethods(5)
+	want := append([]uuid.UUID(nil), store.active[:3]...)
+	pruned, err = makeRoomForPaymentMethod(ctx, store, savedMethodCap(3, domain.SavedPaymentMethodPolicyPruneLRU), "agent-1", "cust-1")
+	require.NoError(t, err)
+	assert.Equal(t, want, pruned)
+	assert.Len(t, store.active, 2)
+}
+
+func TestMakeRoomForPaymentMethod_UnlimitedByDefault(t *testing.T) {
+	store := newFakeSavedMethods(50)
+
+	pruned, err := makeRoomForPaymentMethod(context.Background(), store, domain.DefaultMerchantConfig(domain.MerchantTierEnterprise), "agent-1", "cust-1")
+	require.NoError(t, err)
+	assert.Empty(t, pruned)
+	assert.False(t, store.locked, "no cap, no lock")
+}
+
+func TestStoragePaymentMethodParams_ACHStartsUnverified(t *testing.T) {
+	bankName, accountType := "Routing 021000021", "savings"
+	params := storagePaymentMethodParams(&ports.ConvertFinancialBRICRequest{
+		AgentID:     "merchant-1",
+		CustomerID:  "customer-1",
+		PaymentType: domain.PaymentMethodTypeACH,
+		LastFour:    "6789",
+		BankName:    &bankName,
+		AccountType: &accountType,
+	}, "STORAGE-BRIC-1")
+
+	assert.Equal(t, "ach", params.PaymentType)
+	assert.Equal(t, "STORAGE-BRIC-1", params.PaymentToken)
+	assert.Equal(t, "Routing 021000021", params.BankName.String)
+	assert.Equal(t, "savings", params.AccountType.String)
+	assert.Equal(t, pgtype.Bool{Bool: false, Valid: true}, params.IsVerified, "ACH goes through verification before it's charged")
+	assert.False(t, params.CardExpMonth.Valid)
+
+	card := storagePaymentMethodParams(&ports.ConvertFinancialBRICRequest{PaymentType: domain.PaymentMethodTypeCreditCard, LastFour: "4242"}, "STORAGE-BRIC-2")
+	assert.True(t, card.IsVerified.Bool, "cards were verified by Account Verification")
+}
+
+// fakeTokenizer records the Server Post request and answers with a fixed response
+type fakeTokenizer struct {
+	adapterports.ServerPostAdapter
+	resp *adapterports.ServerPostResponse
+	req  *adapterports.ServerPostRequest
+}
+
+func (f *fakeTokenizer) ProcessTransaction(ctx context.Context, req *adapterports.ServerPostRequest) (*adapterports.ServerPostResponse, error) {
+	f.req = req
+	return f.resp, nil
+}
+
+func tokenizeTestRequest() *ports.TokenizePaymentMethodRequest {
+	address, zip, key := "1 Main St", "10001", "tokenize-1"
+	return &ports.TokenizePaymentMethodRequest{
+		AgentID:        "merchant-1",
+		CustomerID:     "customer-1",
+		CardNumber:     "4111111111111111",
+		CardExpMonth:   9,
+		CardExpYear:    2031,
+		CVV:            "737",
+		Address:        &address,
+		ZipCode:        &zip,
+		IdempotencyKey: &key,
+	}
+}
+
+func tokenizeTestAgent(status domain.MerchantStatus) sqlc.AgentCredential {
+	return sqlc.AgentCredential{
+		AgentID:     "merchant-1",
+		CustNbr:     "1",
+		MerchNbr:    "2",
+		DbaNbr:      "3",
+		TerminalNbr: "4",
+		Status:      string(status),
+		IsActive:    pgtype.Bool{Bool: true, Valid: true},
+	}
+}
+
+func TestTokenizePaymentMethod_CreatesStorageBRICPaymentMethod(t *testing.T) {
+	ntid := "NTID-1"
+	epx := &fakeTokenizer{resp: &adapterports.ServerPostResponse{
+		AuthGUID:             "STORAGE-BRIC-1",
+		AuthResp:             "00",
+		IsApproved:           true,
+		AuthCardType:         "V",
+		NetworkTransactionID: &ntid,
+	}}
+	store := newFakeStore(tokenizeTestAgent(domain.MerchantStatusActive))
+	core, logs := observer.New(zap.DebugLevel)
+	svc := NewPaymentMethodService(store, nil, epx, nil, domain.EnvironmentSandbox, fakeSecretManager{}, zap.New(core))
+
+	pm, err := svc.TokenizePaymentMethod(context.Background(), tokenizeTestRequest())
+	require.NoError(t, err)
+
+	require.NotNil(t, epx.req)
+	assert.Equal(t, adapterports.TransactionTypeBRICStorageCC, epx.req.TransactionType)
+	assert.Equal(t, "1", epx.req.TranNbr, "the allocated TRAN_NBR is sent")
+	assert.Empty(t, epx.req.Amount, "tokenization doesn't charge")
+	assert.Equal(t, "4111111111111111", *epx.req.AccountNumber)
+	assert.Equal(t, "3109", *epx.req.ExpirationDate)
+	assert.Equal(t, "737", *epx.req.CVV)
+
+	assert.Equal(t, domain.PaymentMethodTypeCreditCard, pm.PaymentType)
+	assert.Equal(t, "STORAGE-BRIC-1", pm.PaymentToken)
+	assert.Equal(t, "1111", pm.LastFour)
+	assert.True(t, pm.IsVerified)
+
+	require.Len(t, store.methods, 1)
+	saved := store.methods[uuid.MustParse(pm.ID)]
+	assert.Equal(t, "Visa", saved.CardBrand.String)
+	assert.Equal(t, pgtype.Int4{Int32: 9, Valid: true}, saved.CardExpMonth)
+	assert.Equal(t, pgtype.Int4{Int32: 2031, Valid: true}, saved.CardExpYear)
+	assert.Equal(t, "tokenize-1", saved.IdempotencyKey.String)
+
+	row := fmt.Sprintf("%+v", saved)
+	assert.NotContains(t, row, "4111111111111111", "card number must not be stored")
+	assert.NotContains(t, row, "737", "CVV must not be stored")
+
+	for _, entry := range logs.All() {
+		for _, value := range entry.ContextMap() {
+			assert.NotContains(t, fmt.Sprint(value), "4111111111111111", "card number logged in %q", entry.Message)
+			assert.NotContains(t, fmt.Sprint(value), "737", "CVV logged in %q", entry.Message)
+		}
+	}
+}
+
+func TestTokenizePaymentMethod_RetryReturnsSavedPaymentMethod(t *testing.T) {
+	epx := &fakeGateway{}
+	store := newFakeStore(tokenizeTestAgent(domain.MerchantStatusActive))
+	svc := NewPaymentMethodService(store, nil, epx, nil, domain.EnvironmentSandbox, fakeSecretManager{}, zap.NewNop())
+	ctx := context.Background()
+
+	first, err := svc.TokenizePaymentMethod(ctx, tokenizeTestRequest())
+	require.NoError(t, err)
+	retry, err := svc.TokenizePaymentMethod(ctx, tokenizeTestRequest())
+	require.NoError(t, err)
+
+	assert.Equal(t, first.ID, retry.ID)
+	assert.Len(t, epx.reqs, 1, "the retry doesn't send the card to EPX again")
+	assert.Len(t, store.methods, 1)
+}
+
+func TestTokenizePaymentMethod_ConcurrentSaveReturnsWinner(t *testing.T) {
+	epx := &fakeGateway{}
+	store := newFakeStore(tokenizeTestAgent(domain.MerchantStatusActive))
+	winner := newTestACHMethod("merchant-1", "customer-1")
+	winner.IdempotencyKey = pgtype.Text{String: "tokenize-1", Valid: true}
+	// The winner commits between this request's lookup and its insert
+	store.beforeCreate = func() { store.methods[winner.ID] = winner }
+	svc := NewPaymentMethodService(store, nil, epx, nil, domain.EnvironmentSandbox, fakeSecretManager{}, zap.NewNop())
+
+	pm, err := svc.TokenizePaymentMethod(context.Background(), tokenizeTestRequest())
+	require.NoError(t, err)
+	assert.Equal(t, winner.ID.String(), pm.ID)
+	assert.Len(t, store.methods, 1)
+}
+
+func TestTokenizePaymentMethod_RefusedForSuspendedAndClosedMerchants(t *testing.T) {
+	for status, want := range map[domain.MerchantStatus]error{
+		domain.MerchantStatusSuspended: domain.ErrMerchantSuspended,
+		domain.MerchantStatusClosed:    domain.ErrMerchantClosed,
+	} {
+		t.Run(string(status), func(t *testing.T) {
+			epx := &fakeGateway{}
+			store := newFakeStore(tokenizeTestAgent(status))
+			svc := NewPaymentMethodService(store, nil, epx, nil, domain.EnvironmentSandbox, fakeSecretManager{}, zap.NewNop())
+
+			_, err := svc.TokenizePaymentMethod(context.Background(), tokenizeTestRequest())
+			assert.ErrorIs(t, err, want)
+			assert.Empty(t, epx.reqs, "no card sent to EPX")
+			assert.Empty(t, store.methods)
+		})
+	}
+}
+
+func TestTokenizePaymentMethod_Declined(t *testing.T) {
+	epx := &fakeTokenizer{resp: &adapterports.ServerPostResponse{AuthResp: "05", AuthRespText: "DECLINED"}}
+	store := newFakeStore(tokenizeTestAgent(domain.MerchantStatusActive))
+	svc := NewPaymentMethodService(store, nil, epx, nil, domain.EnvironmentSandbox, fakeSecretManager{}, zap.NewNop())
+
+	_, err := svc.TokenizePaymentMethod(context.Background(), tokenizeTestRequest())
+	assert.ErrorIs(t, err, domain.ErrTransactionDeclined)
+	assert.Empty(t, store.methods, "nothing saved")
+}
+
+// fakeStore serves one merchant's payment methods and its TRAN_NBR counter; queries the tests don't expect
+// panic on the nil embedded Querier
+type fakeStore struct {
+	sqlc.Querier
+
+	mu       sync.Mutex
+	agent    sqlc.AgentCredential
+	methods  map[uuid.UUID]sqlc.CustomerPaymentMethod
+	counter  int64
+	tranNbrs map[uuid.UUID]int64
+
+	// beforeCreate runs ahead of CreatePaymentMethod's insert, standing in for a concurrent request
+	beforeCreate func()
+}
+
+func newFakeStore(agent sqlc.AgentCredential, methods ...sqlc.CustomerPaymentMethod) *fakeStore {
+	store := &fakeStore{agent: agent, methods: map[uuid.UUID]sqlc.CustomerPaymentMethod{}, tranNbrs: map[uuid.UUID]int64{}}
+	for _, pm := range methods {
+		store.methods[pm.ID] = pm
+	}
+	return store
+}
+
+func (f *fakeStore) Queries() sqlc.Querier { return f }
+
+func (f *fakeStore) WithTx(ctx context.Context, fn func(sqlc.Querier) error) error { return fn(f) }
+
+func (f *fakeStore) GetAgentByAgentID(ctx context.Context, agentID string) (sqlc.AgentCredential, error) {
+	if agentID != f.agent.AgentID {
+		return sqlc.AgentCredential{}, pgx.ErrNoRows
+	}
+	return f.agent, nil
+}
+
+func (f *fakeStore) GetPaymentMethodByID(ctx context.Context, id uuid.UUID) (sqlc.CustomerPaymentMethod, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pm, ok := f.methods[id]
+	if !ok {
+		return sqlc.CustomerPaymentMethod{}, pgx.ErrNoRows
+	}
+	return pm, nil
+}
+
+// CreatePaymentMethod enforces the unique (agent_id, idempotency_key) index
+func (f *fakeStore) CreatePaymentMethod(ctx context.Context, arg sqlc.CreatePaymentMethodParams) (sqlc.CustomerPaymentMethod, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.beforeCreate != nil {
+		f.beforeCreate()
+	}
+	for _, pm := range f.methods {
+		if arg.IdempotencyKey.Valid && pm.AgentID == arg.AgentID && pm.IdempotencyKey == arg.IdempotencyKey {
+			return sqlc.CustomerPaymentMethod{}, &pgconn.PgError{Code: uniqueViolation, ConstraintName: idempotencyKeyIndex}
+		}
+	}
+	pm := sqlc.CustomerPaymentMethod{
+		ID:             arg.ID,
+		AgentID:        arg.AgentID,
+		CustomerID:     arg.CustomerID,
+		PaymentType:    arg.PaymentType,
+		PaymentToken:   arg.PaymentToken,
+		LastFour:       arg.LastFour,
+		CardBrand:      arg.CardBrand,
+		CardExpMonth:   arg.CardExpMonth,
+		CardExpYear:    arg.CardExpYear,
+		IsDefault:      arg.IsDefault,
+		IsActive:       arg.IsActive,
+		IsVerified:     arg.IsVerified,
+		IdempotencyKey: arg.IdempotencyKey,
+	}
+	f.methods[pm.ID] = pm
+	return pm, nil
+}
+
+func (f *fakeStore) GetPaymentMethodByIdempotencyKey(ctx context.Context, arg sqlc.GetPaymentMethodByIdempotencyKeyParams) (sqlc.CustomerPaymentMethod, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, pm := range f.methods {
+		if pm.AgentID == arg.AgentID && pm.IdempotencyKey == arg.IdempotencyKey {
+			return pm, nil
+		}
+	}
+	return sqlc.CustomerPaymentMethod{}, pgx.ErrNoRows
+}
+
+func (f *fakeStore) MarkPaymentMethodVerified(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pm := f.methods[id]
+	pm.IsVerified = pgtype.Bool{Bool: true, Valid: true}
+	f.methods[id] = pm
+	return nil
+}
+
+func (f *fakeStore) SetMicroDeposits(ctx context.Context, arg sqlc.SetMicroDepositsParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pm := f.methods[arg.ID]
+	pm.MicroDepositHash = arg.MicroDepositHash
+	pm.MicroDepositAttempts = 0
+	f.methods[arg.ID] = pm
+	return nil
+}
+
+// RecordMicroDepositAttempt checks and counts under one lock, as the single UPDATE does
+func (f *fakeStore) RecordMicroDepositAttempt(ctx context.Context, arg sqlc.RecordMicroDepositAttemptParams) (sqlc.CustomerPaymentMethod, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pm, ok := f.methods[arg.ID]
+	if !ok || !pm.MicroDepositHash.Valid || pm.MicroDepositAttempts >= arg.MaxAttempts {
+		return sqlc.CustomerPaymentMethod{}, pgx.ErrNoRows
+	}
+	pm.MicroDepositAttempts++
+	f.methods[arg.ID] = pm
+	return pm, nil
+}
+
+func (f *fakeStore) CompleteMicroDepositVerification(ctx context.Context, id uuid.UUID) (sqlc.CustomerPaymentMethod, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pm := f.methods[id]
+	pm.IsVerified = pgtype.Bool{Bool: true, Valid: true}
+	pm.MicroDepositHash = pgtype.Text{}
+	pm.MicroDepositAttempts = max(pm.MicroDepositAttempts-1, 0)
+	f.methods[id] = pm
+	return pm, nil
+}
+
+func (f *fakeStore) GetTranNbr(ctx context.Context, transactionID uuid.UUID) (sqlc.EpxTranNbr, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tranNbr, ok := f.tranNbrs[transactionID]
+	if !ok {
+		return sqlc.EpxTranNbr{}, pgx.ErrNoRows
+	}
+	return sqlc.EpxTranNbr{TransactionID: transactionID, TranNbr: tranNbr}, nil
+}
+
+func (f *fakeStore) NextTranNbr(ctx context.Context, agentID string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.counter++
+	return f.counter, nil
+}
+
+func (f *fakeStore) AssignTranNbr(ctx context.Context, arg sqlc.AssignTranNbrParams) (sqlc.EpxTranNbr, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.tranNbrs[arg.TransactionID]; ok {
+		return sqlc.EpxTranNbr{}, pgx.ErrNoRows
+	}
+	f.tranNbrs[arg.TransactionID] = arg.TranNbr
+	return sqlc.EpxTranNbr{TransactionID: arg.TransactionID, AgentID: arg.AgentID, TranNbr: arg.TranNbr}, nil
+}
+
+// fakeGateway records every Server Post request; the first failErrs calls fail with a gateway error
+type fakeGateway struct {
+	adapterports.ServerPostAdapter
+	failErrs int
+	reqs     []*adapterports.ServerPostRequest
+}
+
+func (f *fakeGateway) ProcessTransaction(ctx context.Context, req *adapterports.ServerPostRequest) (*adapterports.ServerPostResponse, error) {
+	f.reqs = append(f.reqs, req)
+	if len(f.reqs) <= f.failErrs {
+		return nil, errors.New("gateway unavailable")
+	}
+	return &adapterports.ServerPostResponse{AuthResp: "00", IsApproved: true, AuthGUID: "BRIC-1"}, nil
+}
+
+type fakeSecretManager struct {
+	adapterports.SecretManagerAdapter
+}
+
+func (fakeSecretManager) GetSecret(ctx context.Context, path string) (*adapterports.Secret, error) {
+	return &adapterports.Secret{Value: "mac-secret"}, nil
+}
+
+func TestVerifyACHAccount_RetriedPreNoteReusesTranNbr(t *testing.T) {
+	agent := sqlc.AgentCredential{AgentID: "merchant-1", CustNbr: "9001", IsActive: pgtype.Bool{Bool: true, Valid: true}}
+	pm := newTestACHMethod("merchant-1", "customer-1")
+	other := newTestACHMethod("merchant-1", "customer-1")
+	store := newFakeStore(agent, pm, other)
+	gateway := &fakeGateway{failErrs: 1}
+	svc := NewPaymentMethodService(store, nil, gateway, nil, domain.EnvironmentSandbox, fakeSecretManager{}, zap.NewNop())
+	ctx := context.Background()
+
+	req := &ports.VerifyACHAccountRequest{AgentID: "merchant-1", CustomerID: "customer-1", PaymentMethodID: pm.ID.String()}
+	require.Error(t, svc.VerifyACHAccount(ctx, req), "the first pre-note's outcome is unknown")
+	require.NoError(t, svc.VerifyACHAccount(ctx, req))
+	require.NoError(t, svc.VerifyACHAccount(ctx, &ports.VerifyACHAccountRequest{AgentID: "merchant-1", CustomerID: "customer-1", PaymentMethodID: other.ID.String()}))
+
+	require.Len(t, gateway.reqs, 3)
+	assert.Equal(t, "1", gateway.reqs[0].TranNbr, "a merchant TRAN_NBR, not a UUID")
+	assert.Equal(t, gateway.reqs[0].TranNbr, gateway.reqs[1].TranNbr, "EPX sees the retry as the same pre-note")
+	assert.Equal(t, "2", gateway.reqs[2].TranNbr)
+}
+
+func TestVerifyACHAccount_ProductionMerchantNeverReachesSandbox(t *testing.T) {
+	agent := sqlc.AgentCredential{AgentID: "merchant-1", CustNbr: "9001", Environment: string(domain.EnvironmentProduction), IsActive: pgtype.Bool{Bool: true, Valid: true}}
+	pm := newTestACHMethod("merchant-1", "customer-1")
+	gateway := &fakeGateway{}
+	svc := NewPaymentMethodService(newFakeStore(agent, pm), nil, gateway, nil, domain.EnvironmentSandbox, fakeSecretManager{}, zap.NewNop())
+
+	err := svc.VerifyACHAccount(context.Background(), &ports.VerifyACHAccountRequest{AgentID: "merchant-1", CustomerID: "customer-1", PaymentMethodID: pm.ID.String()})
+	assert.ErrorIs(t, err, domain.ErrEnvironmentMismatch)
+	assert.Empty(t, gateway.reqs, "no pre-note sent")
+}
+
+func TestInitiateMicroDeposits_AmountsStoredBeforeSending(t *testing.T) {
+	agent := sqlc.AgentCredential{AgentID: "merchant-1", CustNbr: "9001", IsActive: pgtype.Bool{Bool: true, Valid: true}}
+	pm := newTestACHMethod("merchant-1", "customer-1")
+	store := newFakeStore(agent, pm)
+	gateway := &fakeGateway{failErrs: 2}
+	svc := NewPaymentMethodService(store, nil, gateway, nil, domain.EnvironmentSandbox, fakeSecretManager{}, zap.NewNop())
+	ctx := context.Background()
+	req := &ports.InitiateMicroDepositsRequest{AgentID: "merchant-1", CustomerID: "customer-1", PaymentMethodID: pm.ID.String()}
+
+	_, err := svc.InitiateMicroDeposits(ctx, req)
+	require.Error(t, err)
+	assert.True(t, store.methods[pm.ID].MicroDepositHash.Valid, "amounts kept even though the send failed")
+
+	// A second initiation replaces the amounts; the credits it sends are the ones that verify
+	_, err = svc.InitiateMicroDeposits(ctx, req)
+	require.Error(t, err)
+	_, err = svc.InitiateMicroDeposits(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, gateway.reqs, 4)
+
+	verified, err := svc.VerifyMicroDeposits(ctx, &ports.VerifyMicroDepositsRequest{
+		AgentID:         "merchant-1",
+		CustomerID:      "customer-1",
+		PaymentMethodID: pm.ID.String(),
+		Amounts:         []string{gateway.reqs[2].Amount, gateway.reqs[3].Amount},
+	})
+	require.NoError(t, err)
+	assert.True(t, verified.IsVerified)
+	assert.Zero(t, store.methods[pm.ID].MicroDepositAttempts, "the matching attempt isn't a failure")
+}
+
+func TestVerifyMicroDeposits_ConcurrentGuessesCantPassTheLimit(t *testing.T) {
+	agent := sqlc.AgentCredential{AgentID: "merchant-1", IsActive: pgtype.Bool{Bool: true, Valid: true}}
+	deposits := domain.MicroDeposits{32, 45}
+	hash, err := domain.HashMicroDeposits(deposits, nil)
+	require.NoError(t, err)
+	pm := newTestACHMethod("merchant-1", "customer-1")
+	pm.MicroDepositHash = pgtype.Text{String: hash, Valid: true}
+	pm.MicroDepositAttempts = 1
+	store := newFakeStore(agent, pm)
+	svc := NewPaymentMethodService(store, nil, &fakeGateway{}, nil, domain.EnvironmentSandbox, fakeSecretManager{}, zap.NewNop())
+
+	guess := func(amounts ...string) error {
+		_, err := svc.VerifyMicroDeposits(context.Background(), &ports.VerifyMicroDepositsRequest{
+			AgentID: "merchant-1", CustomerID: "customer-1", PaymentMethodID: pm.ID.String(), Amounts: amounts,
+		})
+		return err
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = guess("0.11", fmt.Sprintf("0.%02d", i+10))
+		}()
+	}
+	wg.Wait()
+
+	mismatches := 0
+	for _, err := range errs {
+		if errors.Is(err, domain.ErrMicroDepositMismatch) {
+			mismatches++
+		} else {
+			assert.ErrorIs(t, err, domain.ErrMicroDepositsLocked)
+		}
+	}
+	assert.Equal(t, 1, mismatches, "only the guesses left before the limit are checked")
+	assert.Equal(t, int32(domain.MaxMicroDepositAttempts), store.methods[pm.ID].MicroDepositAttempts)
+	assert.ErrorIs(t, guess("0.32", "0.45"), domain.ErrMicroDepositsLocked, "the right amounts are too late")
+}
+
+func newTestACHMethod(agentID, customerID string) sqlc.CustomerPaymentMethod {
+	return sqlc.CustomerPaymentMethod{
+		ID:           uuid.New(),
+		AgentID:      agentID,
+		CustomerID:   customerID,
+		PaymentType:  string(domain.PaymentMethodTypeACH),
+		PaymentToken: "STORAGE-BRIC-ACH",
+		IsActive:     pgtype.Bool{Bool: true, Valid: true},
+		IsVerified:   pgtype.Bool{Bool: false, Valid: true},
+	}
+}
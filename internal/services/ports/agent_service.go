@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/kevin07696/payment-service/internal/domain"
 )
@@ -16,6 +17,7 @@ type RegisterAgentRequest struct {
 	TerminalNbr    string
 	Environment    domain.Environment
 	AgentName      string
+	DataRegion     *string // Optional: data residency region (defaults to "us")
 	IdempotencyKey *string
 }
 
@@ -29,13 +31,45 @@ type UpdateAgentRequest struct {
 	TerminalNbr    *string
 	Environment    *domain.Environment
 	AgentName      *string
+	DataRegion     *string // Applies to rows written from now on; existing rows keep their region
 	IdempotencyKey *string
+
+	// SubscriptionAmountChangeMinDays sets the minimum days between subscription amount changes (0 = unlimited)
+	SubscriptionAmountChangeMinDays *int
+}
+
+// UpdateMerchantSettingsRequest contains the self-service settings a merchant may change; nil keeps the current value
+// and an empty string clears it. EPX credentials and limits are deliberately absent.
+type UpdateMerchantSettingsRequest struct {
+	AgentID             string
+	StatementDescriptor *string
+	NotificationEmail   *string
+	Actor               string // Calling service, recorded in audit_logs
+}
+
+// MerchantStatusRequest moves a merchant through its lifecycle (suspend, reactivate or close)
+type MerchantStatusRequest struct {
+	AgentID string
+	Reason  string // Stored on the merchant and in audit_logs
+	Actor   string // Calling service, recorded in audit_logs
 }
 
 // RotateMACRequest contains parameters for rotating MAC secret
 type RotateMACRequest struct {
 	AgentID      string
-	NewMACSecret string
+	NewMACSecret string // Empty generates a random MAC, to be registered with EPX
+	KeepPrevious bool   // Keep the replaced MAC at domain.PreviousMACPath for callbacks already in flight
+	Actor        string // Calling service or operator, recorded in audit_logs
+}
+
+// MACRotation describes a confirmed MAC rotation. It never carries secret values.
+type MACRotation struct {
+	AgentID         string
+	MACSecretPath   string
+	CurrentVersion  string
+	PreviousVersion string
+	PreviousPath    string // Empty unless the previous MAC was kept
+	RotatedAt       time.Time
 }
 
 // AgentService defines the port for agent/merchant credential management
@@ -52,9 +86,21 @@ type AgentService interface {
 	// UpdateAgent updates agent credentials
 	UpdateAgent(ctx context.Context, req *UpdateAgentRequest) (*domain.Agent, error)
 
-	// DeactivateAgent deactivates an agent
-	DeactivateAgent(ctx context.Context, agentID, reason string) error
+	// DeactivateAgent closes a merchant for good: every payment operation is rejected from then on
+	DeactivateAgent(ctx context.Context, req *MerchantStatusRequest) (*domain.Agent, error)
+
+	// SuspendMerchant stops new charges until the merchant is reactivated; voids and refunds still go through
+	SuspendMerchant(ctx context.Context, req *MerchantStatusRequest) (*domain.Agent, error)
+
+	// ReactivateMerchant lifts a suspension
+	ReactivateMerchant(ctx context.Context, req *MerchantStatusRequest) (*domain.Agent, error)
+
+	// RotateMAC replaces the MAC secret in the secret manager, confirms it by reading it back and records it in audit_logs
+	RotateMAC(ctx context.Context, req *RotateMACRequest) (*MACRotation, error)
+
+	// GetEffectiveConfig returns the fully-resolved configuration for an agent (tier defaults + overrides, no secrets)
+	GetEffectiveConfig(ctx context.Context, agentID string) (*domain.MerchantConfig, error)
 
-	// RotateMAC rotates MAC secret in secret manager
-	RotateMAC(ctx context.Context, req *RotateMACRequest) error
+	// UpdateMerchantSettings changes a merchant's self-service settings
+	UpdateMerchantSettings(ctx context.Context, req *UpdateMerchantSettingsRequest) (*domain.Agent, error)
 }
@@ -47,6 +47,27 @@ type ConvertFinancialBRICRequest struct {
 	ZipCode   *string
 }
 
+// TokenizePaymentMethodRequest contains raw card data to exchange for a Storage BRIC over Server Post.
+// The card number and CVV are only forwarded to EPX; they are never stored or logged.
+type TokenizePaymentMethodRequest struct {
+	AgentID        string
+	CustomerID     string
+	CardNumber     string // PAN, 13-19 digits
+	CardExpMonth   int    // 1-12
+	CardExpYear    int    // Four digits, e.g. 2029
+	CVV            string // Optional, 3-4 digits
+	IsDefault      bool
+	IdempotencyKey *string
+
+	// Billing information (required for Account Verification)
+	FirstName *string
+	LastName  *string
+	Address   *string
+	City      *string
+	State     *string
+	ZipCode   *string
+}
+
 // VerifyACHAccountRequest contains parameters for ACH verification
 type VerifyACHAccountRequest struct {
 	PaymentMethodID string
@@ -54,6 +75,38 @@ type VerifyACHAccountRequest struct {
 	CustomerID      string
 }
 
+// ProcessACHReturnRequest contains an ACH return (R-code) reported by EPX for a payment method
+type ProcessACHReturnRequest struct {
+	AgentID         string
+	PaymentMethodID string
+	ReturnCode      string  // NACHA return code, e.g. "R01"
+	TransactionID   *string // Returned debit, for logging
+}
+
+// InitiateMicroDepositsRequest contains parameters for sending ACH micro-deposits
+type InitiateMicroDepositsRequest struct {
+	PaymentMethodID string
+	AgentID         string
+	CustomerID      string
+}
+
+// VerifyMicroDepositsRequest contains the micro-deposit amounts the customer saw on their statement
+type VerifyMicroDepositsRequest struct {
+	PaymentMethodID string
+	AgentID         string
+	CustomerID      string
+	Amounts         []string // Two dollar amounts, e.g. ["0.32", "0.45"], in any order
+}
+
+// UpdatePaymentMethodExpiryRequest contains the new expiration date of a reissued card (same number/BRIC)
+type UpdatePaymentMethodExpiryRequest struct {
+	PaymentMethodID string
+	AgentID         string
+	CustomerID      string
+	CardExpMonth    int // 1-12
+	CardExpYear     int // Four digits, e.g. 2029
+}
+
 // PaymentMethodService defines the port for payment method operations
 type PaymentMethodService interface {
 	// SavePaymentMethod tokenizes and saves a payment method
@@ -73,21 +126,46 @@ type PaymentMethodService interface {
 	// Important: Storage BRICs never expire and are used for recurring payments
 	ConvertFinancialBRICToStorageBRIC(ctx context.Context, req *ConvertFinancialBRICRequest) (*domain.PaymentMethod, error)
 
+	// TokenizePaymentMethod exchanges card data for a Storage BRIC and saves it, without charging the card
+	//
+	// Use case: merchants with their own PCI scope collecting card data server-side instead of via Browser Post
+	//
+	// Process:
+	//   1. Sends a CCE8 BRIC Storage transaction with the card data through Server Post
+	//   2. EPX performs $0.00 Account Verification with the card networks
+	//   3. If approved: saves the Storage BRIC with the last four, brand and expiry (never the PAN or CVV)
+	TokenizePaymentMethod(ctx context.Context, req *TokenizePaymentMethodRequest) (*domain.PaymentMethod, error)
+
 	// GetPaymentMethod retrieves a specific payment method
 	GetPaymentMethod(ctx context.Context, paymentMethodID string) (*domain.PaymentMethod, error)
 
 	// ListPaymentMethods lists all payment methods for a customer
 	ListPaymentMethods(ctx context.Context, agentID, customerID string) ([]*domain.PaymentMethod, error)
 
+	// ListExpiringPaymentMethods lists a merchant's active cards expiring within the given number of days
+	ListExpiringPaymentMethods(ctx context.Context, agentID string, withinDays int) ([]*domain.PaymentMethod, error)
+
 	// UpdatePaymentMethodStatus updates the active status of a payment method
 	UpdatePaymentMethodStatus(ctx context.Context, paymentMethodID, agentID, customerID string, isActive bool) (*domain.PaymentMethod, error)
 
 	// DeletePaymentMethod soft deletes a payment method (sets deleted_at)
 	DeletePaymentMethod(ctx context.Context, paymentMethodID string) error
 
+	// UpdatePaymentMethodExpiry updates a stored card's expiration date without re-tokenizing
+	UpdatePaymentMethodExpiry(ctx context.Context, req *UpdatePaymentMethodExpiryRequest) (*domain.PaymentMethod, error)
+
 	// SetDefaultPaymentMethod marks a payment method as default
 	SetDefaultPaymentMethod(ctx context.Context, paymentMethodID, agentID, customerID string) (*domain.PaymentMethod, error)
 
 	// VerifyACHAccount sends pre-note for ACH verification
 	VerifyACHAccount(ctx context.Context, req *VerifyACHAccountRequest) error
+
+	// ProcessACHReturn records an ACH return and deactivates the payment method on unrecoverable returns
+	ProcessACHReturn(ctx context.Context, req *ProcessACHReturnRequest) (*domain.PaymentMethod, error)
+
+	// InitiateMicroDeposits sends two small ACH credits and stores their hashed amounts
+	InitiateMicroDeposits(ctx context.Context, req *InitiateMicroDepositsRequest) (*domain.PaymentMethod, error)
+
+	// VerifyMicroDeposits checks the customer's amounts and marks the ACH payment method verified
+	VerifyMicroDeposits(ctx context.Context, req *VerifyMicroDepositsRequest) (*domain.PaymentMethod, error)
 }
@@ -2,20 +2,26 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/kevin07696/payment-service/internal/domain"
 )
 
 // AuthorizeRequest contains parameters for authorization
 type AuthorizeRequest struct {
-	AgentID         string
-	CustomerID      *string // Nullable for guest transactions
-	Amount          string
-	Currency        string
-	PaymentMethodID *string // Saved payment method
-	PaymentToken    *string // One-time token from EPX
-	IdempotencyKey  *string
-	Metadata        map[string]interface{}
+	AgentID             string
+	CustomerID          *string // Nullable for guest transactions
+	CustomerEmail       *string // Used when the merchant auto-creates customers on first reference
+	Amount              string
+	Currency            string
+	PaymentMethodID     *string // Saved payment method
+	PaymentToken        *string // One-time token from EPX
+	IdempotencyKey      *string
+	Metadata            map[string]interface{}
+	IncludeTree         *bool                // Include the transaction group tree in the response (nil = merchant default)
+	ThreeDS             *domain.ThreeDSecure // 3-D Secure result for card-not-present payments (nil when not run)
+	StatementDescriptor *string              // Overrides the merchant's statement descriptor for this charge (nil = merchant default)
+	Actor               string               // Calling service, recorded in audit_logs
 }
 
 // CaptureRequest contains parameters for capturing authorized funds
@@ -23,24 +29,58 @@ type CaptureRequest struct {
 	TransactionID  string
 	Amount         *string // Optional: partial capture
 	IdempotencyKey *string
+	IncludeTree    *bool  // Include the transaction group tree in the response (nil = merchant default)
+	Actor          string // Calling service, recorded in audit_logs
+}
+
+// PartialReversalRequest contains parameters for reducing an open authorization
+type PartialReversalRequest struct {
+	TransactionID  string
+	Amount         string // Amount released from the hold (not the new authorized total)
+	IdempotencyKey *string
+	IncludeTree    *bool  // Include the transaction group tree in the response (nil = merchant default)
+	Actor          string // Calling service, recorded in audit_logs
+}
+
+// IncrementAuthorizationRequest contains parameters for increasing an open authorization
+type IncrementAuthorizationRequest struct {
+	TransactionID  string
+	Amount         string // Amount added to the hold (not the new authorized total)
+	IdempotencyKey *string
+	IncludeTree    *bool  // Include the transaction group tree in the response (nil = merchant default)
+	Actor          string // Calling service, recorded in audit_logs
 }
 
 // SaleRequest contains parameters for sale (auth + capture)
 type SaleRequest struct {
-	AgentID         string
-	CustomerID      *string
-	Amount          string
-	Currency        string
-	PaymentMethodID *string
-	PaymentToken    *string
-	IdempotencyKey  *string
-	Metadata        map[string]interface{}
+	AgentID             string
+	CustomerID          *string
+	CustomerEmail       *string // Used when the merchant auto-creates customers on first reference
+	Amount              string
+	Currency            string
+	PaymentMethodID     *string
+	PaymentToken        *string
+	IdempotencyKey      *string
+	Metadata            map[string]interface{}
+	IncludeTree         *bool                // Include the transaction group tree in the response (nil = merchant default)
+	ThreeDS             *domain.ThreeDSecure // 3-D Secure result for card-not-present payments (nil when not run)
+	StatementDescriptor *string              // Overrides the merchant's statement descriptor for this charge (nil = merchant default)
+	Actor               string               // Calling service, recorded in audit_logs
+}
+
+// BatchSaleRequest contains the sales to run as one batch
+type BatchSaleRequest struct {
+	AgentID        string
+	IdempotencyKey *string        // Batch-level key: a retried batch returns the recorded results instead of re-running
+	Items          []*SaleRequest // Each item's own IdempotencyKey still applies
 }
 
 // VoidRequest contains parameters for voiding a transaction
 type VoidRequest struct {
 	TransactionID  string
 	IdempotencyKey *string
+	IncludeTree    *bool  // Include the transaction group tree in the response (nil = merchant default)
+	Actor          string // Calling service, recorded in audit_logs
 }
 
 // RefundRequest contains parameters for refunding a transaction
@@ -49,6 +89,21 @@ type RefundRequest struct {
 	Amount         *string // Optional: partial refund
 	Reason         string
 	IdempotencyKey *string
+	IncludeTree    *bool  // Include the transaction group tree in the response (nil = merchant default)
+	Actor          string // Calling service, recorded in audit_logs
+}
+
+// RefundByReferenceRequest refunds a payment identified by an EPX reference from its receipt
+// (exactly one of AuthGUID and TranNbr) instead of its transaction ID
+type RefundByReferenceRequest struct {
+	AgentID        string // The merchant the payment must belong to
+	AuthGUID       string // EPX BRIC
+	TranNbr        string // EPX TRAN_NBR (numbered per merchant)
+	Amount         *string
+	Reason         string
+	IdempotencyKey *string
+	IncludeTree    *bool  // Include the transaction group tree in the response (nil = merchant default)
+	Actor          string // Calling service, recorded in audit_logs
 }
 
 // PaymentService defines the port for payment operations
@@ -59,6 +114,12 @@ type PaymentService interface {
 	// Capture completes a previously authorized payment
 	Capture(ctx context.Context, req *CaptureRequest) (*domain.Transaction, error)
 
+	// PartialReverseAuthorization releases part of an authorization hold; later captures are bounded by the reduced amount
+	PartialReverseAuthorization(ctx context.Context, req *PartialReversalRequest) (*domain.Transaction, error)
+
+	// IncrementAuthorization adds to an open authorization hold and extends it; later captures can take the increased amount
+	IncrementAuthorization(ctx context.Context, req *IncrementAuthorizationRequest) (*domain.Transaction, error)
+
 	// Sale combines authorize and capture in one operation
 	Sale(ctx context.Context, req *SaleRequest) (*domain.Transaction, error)
 
@@ -68,14 +129,43 @@ type PaymentService interface {
 	// Refund returns funds to the customer
 	Refund(ctx context.Context, req *RefundRequest) (*domain.Transaction, error)
 
+	// RefundByReference refunds the merchant's payment with the given BRIC or TRAN_NBR
+	RefundByReference(ctx context.Context, req *RefundByReferenceRequest) (*domain.Transaction, error)
+
 	// GetTransaction retrieves transaction details
 	GetTransaction(ctx context.Context, transactionID string) (*domain.Transaction, error)
 
-	// GetTransactionByIdempotencyKey retrieves a transaction by idempotency key
-	GetTransactionByIdempotencyKey(ctx context.Context, key string) (*domain.Transaction, error)
+	// BatchGetTransactions retrieves up to 100 transactions in request order.
+	// IDs that are malformed or don't exist are skipped; callers must check each transaction's agent.
+	BatchGetTransactions(ctx context.Context, transactionIDs []string) ([]*domain.Transaction, error)
+
+	// BatchSale runs up to 100 sales in request order; an item's failure doesn't stop the others.
+	// Reusing a batch idempotency key returns the first run's results without re-processing any item.
+	BatchSale(ctx context.Context, req *BatchSaleRequest) (*domain.SaleBatchResult, error)
+
+	// GetTransactionStatuses looks up a batch of transactions owned by the agent.
+	// Returns one result per requested ID, in request order; missing IDs have no transaction.
+	GetTransactionStatuses(ctx context.Context, agentID string, transactionIDs []string) ([]*domain.TransactionStatusResult, error)
+
+	// GetEstimatedFees estimates the processing fee for a transaction (an estimate, not the actual cost)
+	GetEstimatedFees(ctx context.Context, transactionID string) (*domain.FeeEstimate, error)
+
+	// ClassifyDeclineCode maps an EPX response code with the same decline taxonomy as the payment flow
+	ClassifyDeclineCode(ctx context.Context, authResp, authRespText string) (*domain.GatewayResult, error)
+
+	// GetTransactionByIdempotencyKey retrieves the merchant's transaction recorded under an idempotency key (unique per merchant)
+	GetTransactionByIdempotencyKey(ctx context.Context, agentID, key string) (*domain.Transaction, error)
 
 	// ListTransactions lists transactions with filters
-	ListTransactions(ctx context.Context, agentID string, customerID *string, limit, offset int) ([]*domain.Transaction, int, error)
+	ListTransactions(ctx context.Context, agentID string, filter domain.TransactionFilter, limit, offset int) ([]*domain.Transaction, int, error)
+
+	// ListTransactionsByCursor lists transactions newest first using keyset pagination.
+	// cursor is a previous page's next cursor ("" = first page); the returned next cursor is "" on the last page.
+	ListTransactionsByCursor(ctx context.Context, agentID string, filter domain.TransactionFilter, limit int, cursor string) ([]*domain.Transaction, string, error)
+
+	// GetSettlementSummary totals a merchant's sales, refunds and voids per UTC day and currency.
+	// endDate is inclusive; only the dates of startDate and endDate are used.
+	GetSettlementSummary(ctx context.Context, agentID string, startDate, endDate time.Time) ([]*domain.SettlementDay, error)
 
 	// GetTransactionsByGroup retrieves all transactions in a group
 	GetTransactionsByGroup(ctx context.Context, groupID string) ([]*domain.Transaction, error)
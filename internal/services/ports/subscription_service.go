@@ -19,6 +19,7 @@ type CreateSubscriptionRequest struct {
 	StartDate       time.Time
 	MaxRetries      int
 	Metadata        map[string]interface{}
+	CouponID        *string // Optional: UUID of coupon to apply to charges
 	IdempotencyKey  *string
 }
 
@@ -63,6 +64,15 @@ type SubscriptionService interface {
 	// ListCustomerSubscriptions lists all subscriptions for a customer
 	ListCustomerSubscriptions(ctx context.Context, agentID, customerID string) ([]*domain.Subscription, error)
 
+	// ListSubscriptionTransactions lists the billing history of a subscription (charges, failed attempts, refunds)
+	ListSubscriptionTransactions(ctx context.Context, subscriptionID string, limit, offset int) ([]*domain.Transaction, int, error)
+
+	// GetSubscriptionChargeHistory returns every billing attempt of a merchant's subscription, oldest first
+	GetSubscriptionChargeHistory(ctx context.Context, agentID, subscriptionID string) ([]*domain.SubscriptionChargeAttempt, error)
+
 	// ProcessDueBilling processes subscriptions due for billing (cron/admin)
-	ProcessDueBilling(ctx context.Context, asOfDate time.Time, batchSize int) (processed, success, failed int, errors []error)
+	ProcessDueBilling(ctx context.Context, asOfDate time.Time, batchSize int) *domain.BillingRunResult
+
+	// PreviewDueBilling returns the charges ProcessDueBilling would make, without charging or writing anything
+	PreviewDueBilling(ctx context.Context, asOfDate time.Time, batchSize int) ([]*domain.PlannedCharge, error)
 }
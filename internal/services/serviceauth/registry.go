@@ -0,0 +1,357 @@
+package serviceauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/pkg/middleware"
+)
+
+// activeKeysTTL is how long a service's loaded keys verify tokens before they're reloaded,
+// which bounds how long a retired key keeps working
+const activeKeysTTL = 30 * time.Second
+
+// maxCachedServices bounds the key cache. Tokens name their issuer, so without a bound a caller could grow the
+// cache with one entry per made-up service ID.
+const maxCachedServices = 1024
+
+// PostgreSQL error codes
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// QueryExecutor defines the service registry queries
+type QueryExecutor interface {
+	CreateService(ctx context.Context, arg sqlc.CreateServiceParams) (sqlc.Service, error)
+	GetServiceByServiceID(ctx context.Context, serviceID string) (sqlc.Service, error)
+	ListServices(ctx context.Context, arg sqlc.ListServicesParams) ([]sqlc.Service, error)
+	AddServicePublicKey(ctx context.Context, arg sqlc.AddServicePublicKeyParams) (sqlc.ServicePublicKey, error)
+	ListActiveServicePublicKeys(ctx context.Context, serviceID string) ([]sqlc.ServicePublicKey, error)
+	ListServicePublicKeys(ctx context.Context, serviceID uuid.UUID) ([]sqlc.ServicePublicKey, error)
+	RetireServicePublicKey(ctx context.Context, arg sqlc.RetireServicePublicKeyParams) (sqlc.ServicePublicKey, error)
+	DeactivateService(ctx context.Context, serviceID string) (sqlc.Service, error)
+	GrantServiceAccess(ctx context.Context, arg sqlc.GrantServiceAccessParams) (sqlc.ServiceMerchant, error)
+	RevokeServiceAccess(ctx context.Context, arg sqlc.RevokeServiceAccessParams) (int64, error)
+	HasServiceGrant(ctx context.Context, arg sqlc.HasServiceGrantParams) (bool, error)
+	ListServiceGrantDetails(ctx context.Context, arg sqlc.ListServiceGrantDetailsParams) ([]sqlc.ListServiceGrantDetailsRow, error)
+}
+
+type cachedKeys struct {
+	keys     []middleware.ServiceKey
+	loadedAt time.Time
+}
+
+// Registry manages the services allowed to call the API and the public keys their tokens are verified with
+type Registry struct {
+	queries   QueryExecutor
+	cache     map[string]cachedKeys
+	mu        sync.Mutex
+	ttl       time.Duration
+	maxCached int
+	now       func() time.Time
+	logger    *zap.Logger
+}
+
+// NewRegistry creates a service registry
+func NewRegistry(queries QueryExecutor, logger *zap.Logger) *Registry {
+	return &Registry{
+		queries:   queries,
+		cache:     make(map[string]cachedKeys),
+		ttl:       activeKeysTTL,
+		maxCached: maxCachedServices,
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+// CreateService registers a calling service; serviceID is the issuer its tokens carry
+func (r *Registry) CreateService(ctx context.Context, serviceID, name string) (*sqlc.Service, error) {
+	service, err := r.queries.CreateService(ctx, sqlc.CreateServiceParams{
+		ServiceID:   serviceID,
+		ServiceName: name,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrServiceAlreadyExists
+		}
+		return nil, fmt.Errorf("create service: %w", err)
+	}
+
+	r.logger.Info("Service registered", zap.String("service_id", serviceID))
+	return &service, nil
+}
+
+// ListServices returns a page of registered services by service ID, optionally only those matching a search pattern
+func (r *Registry) ListServices(ctx context.Context, arg sqlc.ListServicesParams) ([]sqlc.Service, error) {
+	services, err := r.queries.ListServices(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+	return services, nil
+}
+
+// ListKeys returns a service's public keys, active and retired, newest first
+func (r *Registry) ListKeys(ctx context.Context, serviceID string) ([]sqlc.ServicePublicKey, error) {
+	service, err := r.getService(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := r.queries.ListServicePublicKeys(ctx, service.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list service keys: %w", err)
+	}
+	return keys, nil
+}
+
+// AddKey adds an active public key (PEM) to a service. Tokens signed with it verify alongside the existing keys,
+// so a rotation adds the new key first and retires the old one once no tokens signed with it remain.
+func (r *Registry) AddKey(ctx context.Context, serviceID, keyID, publicKeyPEM string) (*sqlc.ServicePublicKey, error) {
+	if _, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicKeyPEM)); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPublicKey, err)
+	}
+
+	service, err := r.getService(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := r.queries.AddServicePublicKey(ctx, sqlc.AddServicePublicKeyParams{
+		ServiceID: service.ID,
+		KeyID:     keyID,
+		PublicKey: publicKeyPEM,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("key %q already exists for service %q", keyID, serviceID)
+		}
+		return nil, fmt.Errorf("add service key: %w", err)
+	}
+
+	r.invalidate(serviceID)
+	r.logger.Info("Service key added", zap.String("service_id", serviceID), zap.String("key_id", keyID))
+	return &key, nil
+}
+
+// RetireKey stops a key from verifying tokens. The service's last active key can't be retired,
+// since that would lock the service out until a new key is added. The check and the retirement are one UPDATE,
+// so two keys retired at once can't both pass it.
+func (r *Registry) RetireKey(ctx context.Context, serviceID, keyID string) (*sqlc.ServicePublicKey, error) {
+	service, err := r.getService(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := r.queries.RetireServicePublicKey(ctx, sqlc.RetireServicePublicKeyParams{
+		ServiceID: service.ID,
+		KeyID:     keyID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.notRetired(ctx, service.ID, keyID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("retire service key: %w", err)
+	}
+
+	r.invalidate(serviceID)
+	r.logger.Info("Service key retired", zap.String("service_id", serviceID), zap.String("key_id", keyID))
+	return &key, nil
+}
+
+// notRetired explains why RetireServicePublicKey retired nothing: the key is still active only when it's the last one
+func (r *Registry) notRetired(ctx context.Context, serviceID uuid.UUID, keyID string) error {
+	keys, err := r.queries.ListServicePublicKeys(ctx, serviceID)
+	if err != nil {
+		return fmt.Errorf("list service keys: %w", err)
+	}
+	for _, key := range keys {
+		if key.KeyID == keyID && key.IsActive {
+			return domain.ErrLastServiceKey
+		}
+	}
+	return domain.ErrServiceKeyNotFound
+}
+
+// ActiveKeys returns the keys that verify the service's tokens (a middleware.ServiceKeyFunc).
+// Keys are cached briefly, so a retired key may verify tokens for up to activeKeysTTL on other instances.
+// At most maxCachedServices services are cached at once.
+func (r *Registry) ActiveKeys(ctx context.Context, serviceID string) ([]middleware.ServiceKey, error) {
+	r.mu.Lock()
+	cached, ok := r.cache[serviceID]
+	r.mu.Unlock()
+	if ok && r.now().Sub(cached.loadedAt) < r.ttl {
+		return cached.keys, nil
+	}
+
+	rows, err := r.queries.ListActiveServicePublicKeys(ctx, serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("load service keys: %w", err)
+	}
+
+	keys := make([]middleware.ServiceKey, 0, len(rows))
+	for _, row := range rows {
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(row.PublicKey))
+		if err != nil {
+			r.logger.Error("Skipping unparseable service key",
+				zap.String("service_id", serviceID),
+				zap.String("key_id", row.KeyID),
+				zap.Error(err),
+			)
+			continue
+		}
+		keys = append(keys, middleware.ServiceKey{ID: row.KeyID, Key: key})
+	}
+
+	r.mu.Lock()
+	if _, ok := r.cache[serviceID]; !ok && len(r.cache) >= r.maxCached {
+		r.evictLocked()
+	}
+	r.cache[serviceID] = cachedKeys{keys: keys, loadedAt: r.now()}
+	r.mu.Unlock()
+	return keys, nil
+}
+
+// evictLocked makes room in the full key cache: expired entries go first, otherwise the oldest one. r.mu is held.
+func (r *Registry) evictLocked() {
+	now := r.now()
+	oldest, oldestLoaded := "", now
+	for serviceID, cached := range r.cache {
+		if now.Sub(cached.loadedAt) >= r.ttl {
+			delete(r.cache, serviceID)
+			continue
+		}
+		if oldest == "" || cached.loadedAt.Before(oldestLoaded) {
+			oldest, oldestLoaded = serviceID, cached.loadedAt
+		}
+	}
+	if len(r.cache) >= r.maxCached {
+		delete(r.cache, oldest)
+	}
+}
+
+// DeactivateService stops all of the service's tokens from verifying, whatever keys it has
+func (r *Registry) DeactivateService(ctx context.Context, serviceID string) (*sqlc.Service, error) {
+	service, err := r.queries.DeactivateService(ctx, serviceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrServiceNotFound
+		}
+		return nil, fmt.Errorf("deactivate service: %w", err)
+	}
+
+	r.invalidate(serviceID)
+	r.logger.Info("Service deactivated", zap.String("service_id", serviceID))
+	return &service, nil
+}
+
+// GrantAccess lets the service act for the merchant (agentID) until expiresAt, or indefinitely when expiresAt is zero.
+// Granting an existing grant replaces its expiry.
+func (r *Registry) GrantAccess(ctx context.Context, serviceID, agentID string, expiresAt time.Time) (*sqlc.ServiceMerchant, error) {
+	service, err := r.getService(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := r.queries.GrantServiceAccess(ctx, sqlc.GrantServiceAccessParams{
+		ServiceID: service.ID,
+		AgentID:   agentID,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: !expiresAt.IsZero()},
+	})
+	if err != nil {
+		if isPgError(err, foreignKeyViolation) {
+			return nil, domain.ErrAgentNotFound
+		}
+		return nil, fmt.Errorf("grant service access: %w", err)
+	}
+
+	r.logger.Info("Service access granted", zap.String("service_id", serviceID), zap.String("agent_id", agentID))
+	return &grant, nil
+}
+
+// RevokeAccess removes the service's grant for the merchant
+func (r *Registry) RevokeAccess(ctx context.Context, serviceID, agentID string) error {
+	service, err := r.getService(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := r.queries.RevokeServiceAccess(ctx, sqlc.RevokeServiceAccessParams{
+		ServiceID: service.ID,
+		AgentID:   agentID,
+	})
+	if err != nil {
+		return fmt.Errorf("revoke service access: %w", err)
+	}
+	if revoked == 0 {
+		return domain.ErrServiceGrantNotFound
+	}
+
+	r.logger.Info("Service access revoked", zap.String("service_id", serviceID), zap.String("agent_id", agentID))
+	return nil
+}
+
+// ListGrants returns the grants of every service, narrowed to one service and/or merchant when given
+func (r *Registry) ListGrants(ctx context.Context, serviceID, agentID string) ([]sqlc.ListServiceGrantDetailsRow, error) {
+	grants, err := r.queries.ListServiceGrantDetails(ctx, sqlc.ListServiceGrantDetailsParams{
+		ServiceID: pgtype.Text{String: serviceID, Valid: serviceID != ""},
+		AgentID:   pgtype.Text{String: agentID, Valid: agentID != ""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list service grants: %w", err)
+	}
+	return grants, nil
+}
+
+// HasAccess reports whether the service has an unexpired grant for the merchant (a middleware.MerchantAccessFunc).
+// Grants aren't cached: every call checks the database, so a revoked grant or deactivated service is denied at once.
+func (r *Registry) HasAccess(ctx context.Context, serviceID, agentID string) (bool, error) {
+	allowed, err := r.queries.HasServiceGrant(ctx, sqlc.HasServiceGrantParams{
+		ServiceID: serviceID,
+		AgentID:   agentID,
+		Now:       pgtype.Timestamptz{Time: r.now(), Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("check service grant: %w", err)
+	}
+	return allowed, nil
+}
+
+func (r *Registry) getService(ctx context.Context, serviceID string) (*sqlc.Service, error) {
+	service, err := r.queries.GetServiceByServiceID(ctx, serviceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrServiceNotFound
+		}
+		return nil, fmt.Errorf("get service: %w", err)
+	}
+	return &service, nil
+}
+
+// invalidate drops the cached keys so this instance sees a key change immediately
+func (r *Registry) invalidate(serviceID string) {
+	r.mu.Lock()
+	delete(r.cache, serviceID)
+	r.mu.Unlock()
+}
+
+func isUniqueViolation(err error) bool {
+	return isPgError(err, uniqueViolation)
+}
+
+func isPgError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
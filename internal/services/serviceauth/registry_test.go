@@ -0,0 +1,475 @@
+package serviceauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/pkg/middleware"
+	paymentv1 "github.com/kevin07696/payment-service/proto/payment/v1"
+)
+
+// fakeQueries is an in-memory QueryExecutor
+type fakeQueries struct {
+	mu       sync.Mutex // Guards keys while they're retired concurrently
+	services map[string]*sqlc.Service
+	keys     []*sqlc.ServicePublicKey
+	grants   map[uuid.UUID]map[string]pgtype.Timestamptz // Expiry by agent ID
+}
+
+func newFakeQueries() *fakeQueries {
+	return &fakeQueries{services: make(map[string]*sqlc.Service), grants: make(map[uuid.UUID]map[string]pgtype.Timestamptz)}
+}
+
+func (f *fakeQueries) CreateService(ctx context.Context, arg sqlc.CreateServiceParams) (sqlc.Service, error) {
+	service := &sqlc.Service{ID: uuid.New(), ServiceID: arg.ServiceID, ServiceName: arg.ServiceName, IsActive: true}
+	f.services[arg.ServiceID] = service
+	return *service, nil
+}
+
+func (f *fakeQueries) GetServiceByServiceID(ctx context.Context, serviceID string) (sqlc.Service, error) {
+	service, ok := f.services[serviceID]
+	if !ok {
+		return sqlc.Service{}, pgx.ErrNoRows
+	}
+	return *service, nil
+}
+
+func (f *fakeQueries) ListServices(ctx context.Context, arg sqlc.ListServicesParams) ([]sqlc.Service, error) {
+	var result []sqlc.Service
+	for _, service := range f.services {
+		result = append(result, *service)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ServiceID < result[j].ServiceID })
+	if int(arg.OffsetVal) >= len(result) {
+		return nil, nil
+	}
+	result = result[arg.OffsetVal:]
+	if len(result) > int(arg.LimitVal) {
+		result = result[:arg.LimitVal]
+	}
+	return result, nil
+}
+
+func (f *fakeQueries) AddServicePublicKey(ctx context.Context, arg sqlc.AddServicePublicKeyParams) (sqlc.ServicePublicKey, error) {
+	key := &sqlc.ServicePublicKey{
+		ID:        uuid.New(),
+		ServiceID: arg.ServiceID,
+		KeyID:     arg.KeyID,
+		PublicKey: arg.PublicKey,
+		IsActive:  true,
+		CreatedAt: time.Now(),
+	}
+	f.keys = append(f.keys, key)
+	return *key, nil
+}
+
+func (f *fakeQueries) ListActiveServicePublicKeys(ctx context.Context, serviceID string) ([]sqlc.ServicePublicKey, error) {
+	service, ok := f.services[serviceID]
+	if !ok || !service.IsActive {
+		return []sqlc.ServicePublicKey{}, nil
+	}
+	var result []sqlc.ServicePublicKey
+	for _, key := range f.keys {
+		if key.ServiceID == service.ID && key.IsActive {
+			result = append(result, *key)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeQueries) ListServicePublicKeys(ctx context.Context, serviceID uuid.UUID) ([]sqlc.ServicePublicKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []sqlc.ServicePublicKey
+	for _, key := range f.keys {
+		if key.ServiceID == serviceID {
+			result = append(result, *key)
+		}
+	}
+	return result, nil
+}
+
+// RetireServicePublicKey retires the key only while another stays active, atomically as the UPDATE does
+func (f *fakeQueries) RetireServicePublicKey(ctx context.Context, arg sqlc.RetireServicePublicKeyParams) (sqlc.ServicePublicKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var target *sqlc.ServicePublicKey
+	active := 0
+	for _, key := range f.keys {
+		if key.ServiceID == arg.ServiceID && key.IsActive {
+			active++
+			if key.KeyID == arg.KeyID {
+				target = key
+			}
+		}
+	}
+	if target == nil || active <= 1 {
+		return sqlc.ServicePublicKey{}, pgx.ErrNoRows
+	}
+	target.IsActive = false
+	target.RetiredAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	return *target, nil
+}
+
+func (f *fakeQueries) DeactivateService(ctx context.Context, serviceID string) (sqlc.Service, error) {
+	service, ok := f.services[serviceID]
+	if !ok {
+		return sqlc.Service{}, pgx.ErrNoRows
+	}
+	service.IsActive = false
+	return *service, nil
+}
+
+func (f *fakeQueries) GrantServiceAccess(ctx context.Context, arg sqlc.GrantServiceAccessParams) (sqlc.ServiceMerchant, error) {
+	if f.grants[arg.ServiceID] == nil {
+		f.grants[arg.ServiceID] = make(map[string]pgtype.Timestamptz)
+	}
+	f.grants[arg.ServiceID][arg.AgentID] = arg.ExpiresAt
+	return sqlc.ServiceMerchant{ServiceID: arg.ServiceID, AgentID: arg.AgentID, GrantedAt: time.Now(), ExpiresAt: arg.ExpiresAt}, nil
+}
+
+func (f *fakeQueries) RevokeServiceAccess(ctx context.Context, arg sqlc.RevokeServiceAccessParams) (int64, error) {
+	if _, ok := f.grants[arg.ServiceID][arg.AgentID]; !ok {
+		return 0, nil
+	}
+	delete(f.grants[arg.ServiceID], arg.AgentID)
+	return 1, nil
+}
+
+func (f *fakeQueries) HasServiceGrant(ctx context.Context, arg sqlc.HasServiceGrantParams) (bool, error) {
+	service, ok := f.services[arg.ServiceID]
+	if !ok || !service.IsActive {
+		return false, nil
+	}
+	expiresAt, ok := f.grants[service.ID][arg.AgentID]
+	return ok && (!expiresAt.Valid || expiresAt.Time.After(arg.Now.Time)), nil
+}
+
+func (f *fakeQueries) ListServiceGrantDetails(ctx context.Context, arg sqlc.ListServiceGrantDetailsParams) ([]sqlc.ListServiceGrantDetailsRow, error) {
+	var result []sqlc.ListServiceGrantDetailsRow
+	for _, service := range f.services {
+		if arg.ServiceID.Valid && service.ServiceID != arg.ServiceID.String {
+			continue
+		}
+		for agentID, expiresAt := range f.grants[service.ID] {
+			if arg.AgentID.Valid && agentID != arg.AgentID.String {
+				continue
+			}
+			result = append(result, sqlc.ListServiceGrantDetailsRow{
+				ServiceID:     service.ServiceID,
+				ServiceName:   service.ServiceName,
+				ServiceActive: service.IsActive,
+				AgentID:       agentID,
+				ExpiresAt:     expiresAt,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ServiceID != result[j].ServiceID {
+			return result[i].ServiceID < result[j].ServiceID
+		}
+		return result[i].AgentID < result[j].AgentID
+	})
+	return result, nil
+}
+
+func newKeyPair(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// signToken signs a service token, with a kid header unless kid is empty
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Issuer:    "pos-service",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestRegistry_KeyRotation(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry(newFakeQueries(), zap.NewNop())
+	_, err := registry.CreateService(ctx, "pos-service", "POS")
+	require.NoError(t, err)
+
+	oldKey, oldPEM := newKeyPair(t)
+	newKey, newPEM := newKeyPair(t)
+	_, err = registry.AddKey(ctx, "pos-service", "2025-01", oldPEM)
+	require.NoError(t, err)
+
+	// Warm the cache so the tests below prove key changes take effect at once on this instance
+	keys, err := registry.ActiveKeys(ctx, "pos-service")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	_, err = registry.AddKey(ctx, "pos-service", "2025-06", newPEM)
+	require.NoError(t, err)
+
+	interceptor := middleware.NewAuthInterceptor(middleware.AuthConfig{
+		Keys:                registry.ActiveKeys,
+		MerchantlessMethods: map[string]bool{"/payment.v1.PaymentService/Sale": true},
+	}, zap.NewNop())
+	call := func(token string) error {
+		md := metadata.Pairs("authorization", "Bearer "+token)
+		_, err := interceptor(metadata.NewIncomingContext(ctx, md), nil,
+			&grpc.UnaryServerInfo{FullMethod: "/payment.v1.PaymentService/Sale"},
+			func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil })
+		return err
+	}
+
+	// Both keys verify during the rotation window, by kid or by trying each key
+	assert.NoError(t, call(signToken(t, oldKey, "2025-01")))
+	assert.NoError(t, call(signToken(t, newKey, "2025-06")))
+	assert.NoError(t, call(signToken(t, oldKey, "")))
+	assert.NoError(t, call(signToken(t, newKey, "")))
+
+	// A kid naming the other key doesn't fall back to trying every key
+	err = call(signToken(t, oldKey, "2025-06"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = registry.RetireKey(ctx, "pos-service", "2025-01")
+	require.NoError(t, err)
+
+	for _, kid := range []string{"2025-01", ""} {
+		err = call(signToken(t, oldKey, kid))
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), "retired key rejected (kid %q)", kid)
+	}
+	assert.NoError(t, call(signToken(t, newKey, "2025-06")))
+
+	// The remaining key can't be retired
+	_, err = registry.RetireKey(ctx, "pos-service", "2025-06")
+	assert.ErrorIs(t, err, domain.ErrLastServiceKey)
+
+	_, err = registry.RetireKey(ctx, "pos-service", "2024-01")
+	assert.ErrorIs(t, err, domain.ErrServiceKeyNotFound)
+}
+
+func TestRegistry_ConcurrentRetirementsKeepOneKey(t *testing.T) {
+	ctx := context.Background()
+	queries := newFakeQueries()
+	registry := NewRegistry(queries, zap.NewNop())
+	_, err := registry.CreateService(ctx, "pos-service", "POS")
+	require.NoError(t, err)
+	for _, kid := range []string{"2025-01", "2025-06"} {
+		_, pem := newKeyPair(t)
+		_, err = registry.AddKey(ctx, "pos-service", kid, pem)
+		require.NoError(t, err)
+	}
+
+	errs := make(chan error, 2)
+	for _, kid := range []string{"2025-01", "2025-06"} {
+		go func(kid string) {
+			_, err := registry.RetireKey(ctx, "pos-service", kid)
+			errs <- err
+		}(kid)
+	}
+
+	var failures []error
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			failures = append(failures, err)
+		}
+	}
+	require.Len(t, failures, 1, "only one of the two keys can be retired")
+	assert.ErrorIs(t, failures[0], domain.ErrLastServiceKey)
+
+	keys, err := registry.ActiveKeys(ctx, "pos-service")
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
+
+func TestRegistry_KeyCacheIsBounded(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry(newFakeQueries(), zap.NewNop())
+	registry.maxCached = 3
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+
+	// Made-up issuers each get an (empty) entry, up to the bound
+	for i := 0; i < 10; i++ {
+		_, err := registry.ActiveKeys(ctx, uuid.NewString())
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+	}
+	assert.Len(t, registry.cache, 3)
+
+	// Expired entries are dropped before live ones when room is needed
+	now = now.Add(activeKeysTTL)
+	_, err := registry.ActiveKeys(ctx, "pos-service")
+	require.NoError(t, err)
+	assert.Len(t, registry.cache, 1)
+	assert.Contains(t, registry.cache, "pos-service")
+}
+
+func TestRegistry_AddKeyValidation(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry(newFakeQueries(), zap.NewNop())
+	_, pemKey := newKeyPair(t)
+
+	_, err := registry.AddKey(ctx, "unknown-service", "k1", pemKey)
+	assert.ErrorIs(t, err, domain.ErrServiceNotFound)
+
+	_, err = registry.CreateService(ctx, "pos-service", "POS")
+	require.NoError(t, err)
+	_, err = registry.AddKey(ctx, "pos-service", "k1", "not a key")
+	assert.ErrorIs(t, err, domain.ErrInvalidPublicKey)
+}
+
+// newAccessFixture registers pos-service with one key and returns a call func that sends a Sale for a merchant
+func newAccessFixture(t *testing.T) (*Registry, func(agentID string) error) {
+	t.Helper()
+	ctx := context.Background()
+	registry := NewRegistry(newFakeQueries(), zap.NewNop())
+	_, err := registry.CreateService(ctx, "pos-service", "POS")
+	require.NoError(t, err)
+
+	key, pemKey := newKeyPair(t)
+	_, err = registry.AddKey(ctx, "pos-service", "2025-01", pemKey)
+	require.NoError(t, err)
+
+	interceptor := middleware.NewAuthInterceptor(middleware.AuthConfig{
+		Keys:   registry.ActiveKeys,
+		Access: registry.HasAccess,
+	}, zap.NewNop())
+	token := signToken(t, key, "2025-01")
+
+	return registry, func(agentID string) error {
+		md := metadata.Pairs("authorization", "Bearer "+token)
+		_, err := interceptor(metadata.NewIncomingContext(ctx, md), &paymentv1.SaleRequest{AgentId: agentID},
+			&grpc.UnaryServerInfo{FullMethod: "/payment.v1.PaymentService/Sale"},
+			func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil })
+		return err
+	}
+}
+
+func TestRegistry_RevokedGrantLosesOnlyThatMerchant(t *testing.T) {
+	ctx := context.Background()
+	registry, call := newAccessFixture(t)
+
+	err := call("merchant-a")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "no grant yet")
+
+	for _, agentID := range []string{"merchant-a", "merchant-b"} {
+		_, err := registry.GrantAccess(ctx, "pos-service", agentID, time.Time{})
+		require.NoError(t, err)
+	}
+	assert.NoError(t, call("merchant-a"))
+	assert.NoError(t, call("merchant-b"))
+
+	require.NoError(t, registry.RevokeAccess(ctx, "pos-service", "merchant-a"))
+
+	err = call("merchant-a")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.NoError(t, call("merchant-b"), "other grants are kept")
+
+	err = registry.RevokeAccess(ctx, "pos-service", "merchant-a")
+	assert.ErrorIs(t, err, domain.ErrServiceGrantNotFound)
+}
+
+func TestRegistry_DeactivatedServiceRejected(t *testing.T) {
+	ctx := context.Background()
+	registry, call := newAccessFixture(t)
+	_, err := registry.GrantAccess(ctx, "pos-service", "merchant-a", time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, call("merchant-a"))
+
+	service, err := registry.DeactivateService(ctx, "pos-service")
+	require.NoError(t, err)
+	assert.False(t, service.IsActive)
+
+	err = call("merchant-a")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "a deactivated service's tokens don't verify")
+
+	_, err = registry.DeactivateService(ctx, "unknown-service")
+	assert.ErrorIs(t, err, domain.ErrServiceNotFound)
+}
+
+func TestRegistry_ListGrants(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry(newFakeQueries(), zap.NewNop())
+	for _, serviceID := range []string{"pos-service", "billing-service"} {
+		_, err := registry.CreateService(ctx, serviceID, serviceID)
+		require.NoError(t, err)
+	}
+	for _, grant := range [][2]string{{"pos-service", "merchant-b"}, {"pos-service", "merchant-a"}, {"billing-service", "merchant-a"}} {
+		_, err := registry.GrantAccess(ctx, grant[0], grant[1], time.Time{})
+		require.NoError(t, err)
+	}
+
+	grants, err := registry.ListGrants(ctx, "", "")
+	require.NoError(t, err)
+	require.Len(t, grants, 3)
+	assert.Equal(t, "billing-service", grants[0].ServiceID)
+
+	grants, err = registry.ListGrants(ctx, "pos-service", "")
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+	assert.Equal(t, []string{"merchant-a", "merchant-b"}, []string{grants[0].AgentID, grants[1].AgentID})
+
+	grants, err = registry.ListGrants(ctx, "", "merchant-a")
+	require.NoError(t, err)
+	assert.Len(t, grants, 2)
+
+	grants, err = registry.ListGrants(ctx, "pos-service", "merchant-a")
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	assert.True(t, grants[0].ServiceActive)
+}
+
+func TestRegistry_GrantExpiry(t *testing.T) {
+	ctx := context.Background()
+	registry, call := newAccessFixture(t)
+	now := time.Now()
+	registry.now = func() time.Time { return now }
+
+	_, err := registry.GrantAccess(ctx, "pos-service", "merchant-a", now.Add(time.Hour))
+	require.NoError(t, err)
+	_, err = registry.GrantAccess(ctx, "pos-service", "merchant-b", now.Add(-time.Minute))
+	require.NoError(t, err)
+
+	assert.NoError(t, call("merchant-a"), "a grant that hasn't expired allows access")
+	err = call("merchant-b")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "an expired grant is denied")
+
+	// A grant is denied as soon as it expires
+	now = now.Add(time.Hour)
+	err = call("merchant-a")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Granting again replaces the expiry
+	_, err = registry.GrantAccess(ctx, "pos-service", "merchant-a", time.Time{})
+	require.NoError(t, err)
+	assert.NoError(t, call("merchant-a"))
+}
@@ -0,0 +1,69 @@
+package serviceauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/pkg/middleware"
+)
+
+// OwnerQueries looks up the resources a request can name instead of an agent_id
+type OwnerQueries interface {
+	GetTransactionByID(ctx context.Context, id uuid.UUID) (sqlc.Transaction, error)
+	GetSubscriptionByID(ctx context.Context, id uuid.UUID) (sqlc.Subscription, error)
+	GetPaymentMethodByID(ctx context.Context, id uuid.UUID) (sqlc.CustomerPaymentMethod, error)
+}
+
+// Implemented by the generated request messages that name a resource
+type (
+	transactionIDRequest   interface{ GetTransactionId() string }
+	subscriptionIDRequest  interface{ GetSubscriptionId() string }
+	paymentMethodIDRequest interface{ GetPaymentMethodId() string }
+)
+
+// RequestMerchant returns a middleware.MerchantResolverFunc that finds the merchant owning the transaction,
+// subscription or payment method a request names, checked in that order. A malformed or unknown ID has no owner.
+func RequestMerchant(queries OwnerQueries) middleware.MerchantResolverFunc {
+	return func(ctx context.Context, req interface{}) (string, error) {
+		if r, ok := req.(transactionIDRequest); ok && r.GetTransactionId() != "" {
+			return resourceOwner(ctx, r.GetTransactionId(), func(ctx context.Context, id uuid.UUID) (string, error) {
+				tx, err := queries.GetTransactionByID(ctx, id)
+				return tx.AgentID, err
+			})
+		}
+		if r, ok := req.(subscriptionIDRequest); ok && r.GetSubscriptionId() != "" {
+			return resourceOwner(ctx, r.GetSubscriptionId(), func(ctx context.Context, id uuid.UUID) (string, error) {
+				sub, err := queries.GetSubscriptionByID(ctx, id)
+				return sub.AgentID, err
+			})
+		}
+		if r, ok := req.(paymentMethodIDRequest); ok && r.GetPaymentMethodId() != "" {
+			return resourceOwner(ctx, r.GetPaymentMethodId(), func(ctx context.Context, id uuid.UUID) (string, error) {
+				pm, err := queries.GetPaymentMethodByID(ctx, id)
+				return pm.AgentID, err
+			})
+		}
+		return "", nil
+	}
+}
+
+func resourceOwner(ctx context.Context, rawID string, lookup func(context.Context, uuid.UUID) (string, error)) (string, error) {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return "", nil
+	}
+
+	agentID, err := lookup(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("look up request merchant: %w", err)
+	}
+	return agentID, nil
+}
@@ -0,0 +1,120 @@
+package serviceauth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/pkg/middleware"
+	paymentv1 "github.com/kevin07696/payment-service/proto/payment/v1"
+	paymentmethodv1 "github.com/kevin07696/payment-service/proto/payment_method/v1"
+	subscriptionv1 "github.com/kevin07696/payment-service/proto/subscription/v1"
+)
+
+// fakeOwners maps resource IDs to the merchant that owns them
+type fakeOwners struct {
+	owners map[uuid.UUID]string
+	err    error
+}
+
+func (f *fakeOwners) owner(id uuid.UUID) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	agentID, ok := f.owners[id]
+	if !ok {
+		return "", pgx.ErrNoRows
+	}
+	return agentID, nil
+}
+
+func (f *fakeOwners) GetTransactionByID(ctx context.Context, id uuid.UUID) (sqlc.Transaction, error) {
+	agentID, err := f.owner(id)
+	return sqlc.Transaction{ID: id, AgentID: agentID}, err
+}
+
+func (f *fakeOwners) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (sqlc.Subscription, error) {
+	agentID, err := f.owner(id)
+	return sqlc.Subscription{ID: id, AgentID: agentID}, err
+}
+
+func (f *fakeOwners) GetPaymentMethodByID(ctx context.Context, id uuid.UUID) (sqlc.CustomerPaymentMethod, error) {
+	agentID, err := f.owner(id)
+	return sqlc.CustomerPaymentMethod{ID: id, AgentID: agentID}, err
+}
+
+func TestRequestMerchant(t *testing.T) {
+	ctx := context.Background()
+	txA, subB, pmA := uuid.New(), uuid.New(), uuid.New()
+	owners := &fakeOwners{owners: map[uuid.UUID]string{txA: "merchant-a", subB: "merchant-b", pmA: "merchant-a"}}
+	resolve := RequestMerchant(owners)
+	newPaymentMethod := pmA.String()
+
+	tests := []struct {
+		name string
+		req  interface{}
+		want string
+	}{
+		{"transaction", &paymentv1.CaptureRequest{TransactionId: txA.String()}, "merchant-a"},
+		{"subscription before its new payment method", &subscriptionv1.UpdateSubscriptionRequest{SubscriptionId: subB.String(), PaymentMethodId: &newPaymentMethod}, "merchant-b"},
+		{"payment method", &paymentmethodv1.GetPaymentMethodRequest{PaymentMethodId: pmA.String()}, "merchant-a"},
+		{"unknown transaction", &paymentv1.CaptureRequest{TransactionId: uuid.NewString()}, ""},
+		{"malformed transaction ID", &paymentv1.CaptureRequest{TransactionId: "not-a-uuid"}, ""},
+		{"request naming no resource", &paymentv1.SaleRequest{AgentId: "merchant-a"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agentID, err := resolve(ctx, tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, agentID)
+		})
+	}
+
+	owners.err = errors.New("connection refused")
+	_, err := resolve(ctx, &paymentv1.CaptureRequest{TransactionId: txA.String()})
+	assert.Error(t, err, "a failed lookup is an error, not a request without a merchant")
+}
+
+func TestRequestMerchant_GrantCheckedForTransactionOwner(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry(newFakeQueries(), zap.NewNop())
+	_, err := registry.CreateService(ctx, "pos-service", "POS")
+	require.NoError(t, err)
+	key, pemKey := newKeyPair(t)
+	_, err = registry.AddKey(ctx, "pos-service", "2025-01", pemKey)
+	require.NoError(t, err)
+	_, err = registry.GrantAccess(ctx, "pos-service", "merchant-a", time.Time{})
+	require.NoError(t, err)
+
+	txA, txB := uuid.New(), uuid.New()
+	interceptor := middleware.NewAuthInterceptor(middleware.AuthConfig{
+		Keys:            registry.ActiveKeys,
+		Access:          registry.HasAccess,
+		ResolveMerchant: RequestMerchant(&fakeOwners{owners: map[uuid.UUID]string{txA: "merchant-a", txB: "merchant-b"}}),
+	}, zap.NewNop())
+	md := metadata.Pairs("authorization", "Bearer "+signToken(t, key, "2025-01"))
+	refund := func(transactionID uuid.UUID) error {
+		_, err := interceptor(metadata.NewIncomingContext(ctx, md), &paymentv1.RefundRequest{TransactionId: transactionID.String()},
+			&grpc.UnaryServerInfo{FullMethod: paymentv1.PaymentService_Refund_FullMethodName},
+			func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil })
+		return err
+	}
+
+	assert.NoError(t, refund(txA))
+	err = refund(txB)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "another merchant's transaction")
+	err = refund(uuid.New())
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "a transaction that doesn't exist names no merchant")
+}
@@ -0,0 +1,71 @@
+package serviceauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/pkg/middleware"
+)
+
+// TokenInspection is what the registry can tell about a service token an integrator says doesn't work
+type TokenInspection struct {
+	Claims  *middleware.ServiceClaims         // Decoded without verification (nil when the token isn't a JWT)
+	KeyID   string                            // The token's kid header ("" = every active key is tried)
+	Service *sqlc.Service                     // The service named by the issuer (nil when none is registered)
+	Grants  []sqlc.ListServiceGrantDetailsRow // The service's merchant grants, expired ones included
+	Err     error                             // Why the server rejects the token (nil = it is accepted)
+	Reason  string                            // The reason the server gives the caller for Err
+}
+
+// Valid reports whether the server accepts the token
+func (i *TokenInspection) Valid() bool {
+	return i.Err == nil
+}
+
+// InspectToken decodes a service token and verifies it against the issuer's stored keys as the servers do.
+// A rejected token is not an error: the inspection's Err says why. Errors are failures to read the registry.
+func (r *Registry) InspectToken(ctx context.Context, token string) (*TokenInspection, error) {
+	inspection := &TokenInspection{}
+	reject := func(err error) (*TokenInspection, error) {
+		inspection.Err = err
+		inspection.Reason = middleware.RejectionReason(err)
+		return inspection, nil
+	}
+
+	claims := &middleware.ServiceClaims{}
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, claims)
+	if err != nil {
+		return reject(fmt.Errorf("not a JWT: %w", err))
+	}
+	inspection.Claims = claims
+	inspection.KeyID, _ = parsed.Header["kid"].(string)
+
+	if claims.Issuer == "" {
+		return reject(errors.New("token has no issuer (iss), which must be the service ID"))
+	}
+	service, err := r.getService(ctx, claims.Issuer)
+	if err != nil {
+		if errors.Is(err, domain.ErrServiceNotFound) {
+			return reject(fmt.Errorf("no service is registered as %q", claims.Issuer))
+		}
+		return nil, err
+	}
+	inspection.Service = service
+
+	if inspection.Grants, err = r.ListGrants(ctx, claims.Issuer, ""); err != nil {
+		return nil, err
+	}
+	if !service.IsActive {
+		return reject(fmt.Errorf("service %q is deactivated", claims.Issuer))
+	}
+
+	if _, err := middleware.VerifyServiceToken(ctx, middleware.AuthConfig{Keys: r.ActiveKeys, Now: r.now}, token); err != nil {
+		return reject(err)
+	}
+	return inspection, nil
+}
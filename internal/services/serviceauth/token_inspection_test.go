@@ -0,0 +1,104 @@
+package serviceauth
+
+import (
+	"context"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/pkg/middleware"
+)
+
+// newInspectionFixture registers pos-service with key 2025-01 and a grant for merchant-a.
+// sign issues a pos-service token signed by key that expires at expiresAt.
+func newInspectionFixture(t *testing.T) (*Registry, *rsa.PrivateKey, func(key *rsa.PrivateKey, expiresAt time.Time) string) {
+	t.Helper()
+	ctx := context.Background()
+	registry := NewRegistry(newFakeQueries(), zap.NewNop())
+	_, err := registry.CreateService(ctx, "pos-service", "POS")
+	require.NoError(t, err)
+	key, pemKey := newKeyPair(t)
+	_, err = registry.AddKey(ctx, "pos-service", "2025-01", pemKey)
+	require.NoError(t, err)
+	_, err = registry.GrantAccess(ctx, "pos-service", "merchant-a", time.Time{})
+	require.NoError(t, err)
+
+	return registry, key, func(key *rsa.PrivateKey, expiresAt time.Time) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, middleware.ServiceClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    "pos-service",
+				ExpiresAt: jwt.NewNumericDate(expiresAt),
+			},
+			Scope: "payment:read payment:refund",
+		})
+		token.Header["kid"] = "2025-01"
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+}
+
+func TestInspectToken_Valid(t *testing.T) {
+	registry, key, sign := newInspectionFixture(t)
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	inspection, err := registry.InspectToken(context.Background(), sign(key, expiresAt))
+	require.NoError(t, err)
+	assert.True(t, inspection.Valid())
+	assert.Empty(t, inspection.Reason)
+	assert.Equal(t, "pos-service", inspection.Claims.Issuer)
+	assert.Equal(t, expiresAt, inspection.Claims.ExpiresAt.Time)
+	assert.True(t, inspection.Claims.HasScope("payment:refund"))
+	assert.Equal(t, "2025-01", inspection.KeyID)
+	assert.Equal(t, "POS", inspection.Service.ServiceName)
+	require.Len(t, inspection.Grants, 1)
+	assert.Equal(t, "merchant-a", inspection.Grants[0].AgentID)
+}
+
+func TestInspectToken_Expired(t *testing.T) {
+	registry, key, sign := newInspectionFixture(t)
+
+	inspection, err := registry.InspectToken(context.Background(), sign(key, time.Now().Add(-time.Hour)))
+	require.NoError(t, err)
+	assert.False(t, inspection.Valid())
+	assert.ErrorIs(t, inspection.Err, jwt.ErrTokenExpired)
+	assert.Equal(t, "expired", inspection.Reason)
+	assert.NotNil(t, inspection.Claims, "the claims are still shown")
+	assert.Len(t, inspection.Grants, 1)
+}
+
+func TestInspectToken_SignatureMismatch(t *testing.T) {
+	registry, _, sign := newInspectionFixture(t)
+	otherKey, _ := newKeyPair(t)
+
+	inspection, err := registry.InspectToken(context.Background(), sign(otherKey, time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+	assert.False(t, inspection.Valid())
+	assert.ErrorIs(t, inspection.Err, jwt.ErrTokenSignatureInvalid)
+	assert.Equal(t, "bad signature", inspection.Reason)
+	assert.Equal(t, "2025-01", inspection.KeyID)
+}
+
+func TestInspectToken_UnknownServiceAndGarbage(t *testing.T) {
+	registry, key, _ := newInspectionFixture(t)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Issuer:    "billing-service",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}).SignedString(key)
+	require.NoError(t, err)
+
+	inspection, err := registry.InspectToken(context.Background(), token)
+	require.NoError(t, err)
+	assert.EqualError(t, inspection.Err, `no service is registered as "billing-service"`)
+	assert.Nil(t, inspection.Service)
+
+	inspection, err = registry.InspectToken(context.Background(), "not-a-token")
+	require.NoError(t, err)
+	assert.False(t, inspection.Valid())
+	assert.Nil(t, inspection.Claims)
+}
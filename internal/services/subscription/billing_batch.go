@@ -0,0 +1,129 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// Billing run defaults, used when BillingConfig leaves a field zero
+const (
+	defaultBillingConcurrency = 4
+	defaultBillingMaxPerRun   = 1000
+)
+
+// billingClaimStaleAfter is how long a billing run's claim on a subscription holds if the run never releases it
+// (the instance died mid-run); a later run then takes the subscription over.
+const billingClaimStaleAfter = time.Hour
+
+// BillingConfig bounds how ProcessDueBilling works through a run
+type BillingConfig struct {
+	Concurrency      int     // Subscriptions billed at once (default 4)
+	MaxPerRun        int     // Most subscriptions billed in one run, whatever batch size is asked for (default 1000)
+	ChargesPerSecond float64 // Charges started per second across all workers, to stay under EPX rate limits (0 = unlimited)
+}
+
+func (c BillingConfig) withDefaults() BillingConfig {
+	if c.Concurrency <= 0 {
+		c.Concurrency = defaultBillingConcurrency
+	}
+	if c.MaxPerRun <= 0 {
+		c.MaxPerRun = defaultBillingMaxPerRun
+	}
+	return c
+}
+
+// limiter returns the EPX charge limiter, or nil when charges aren't rate limited
+func (c BillingConfig) limiter() *rate.Limiter {
+	if c.ChargesPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(c.ChargesPerSecond), 1)
+}
+
+// billFunc bills one subscription and returns the amount charged
+type billFunc func(ctx context.Context, sub *sqlc.Subscription) (decimal.Decimal, error)
+
+// billSubscriptions bills each subscription once with at most concurrency workers. A subscription that fails
+// or panics is counted as failed and the rest of the batch carries on.
+func billSubscriptions(ctx context.Context, subs []sqlc.Subscription, concurrency int, limiter *rate.Limiter, logger *zap.Logger, bill billFunc) *domain.BillingRunResult {
+	result := &domain.BillingRunResult{Processed: len(subs), AmountCharged: decimal.Zero}
+	if concurrency > len(subs) {
+		concurrency = len(subs)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan *sqlc.Subscription)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sub := range queue {
+				charged, err := billOne(ctx, sub, limiter, logger, bill)
+
+				mu.Lock()
+				if sub.FailureRetryCount > 0 {
+					result.Retried++
+				}
+				if err != nil {
+					result.Failed++
+					result.Errors = append(result.Errors, fmt.Errorf("subscription %s: %w", sub.ID.String(), err))
+				} else {
+					result.Succeeded++
+					result.AmountCharged = result.AmountCharged.Add(charged)
+				}
+				mu.Unlock()
+
+				if err != nil {
+					logger.Error("Failed to process subscription billing",
+						zap.String("subscription_id", sub.ID.String()),
+						zap.Error(err),
+					)
+				} else {
+					logger.Info("Successfully processed subscription billing",
+						zap.String("subscription_id", sub.ID.String()),
+					)
+				}
+			}
+		}()
+	}
+
+	for i := range subs {
+		queue <- &subs[i]
+	}
+	close(queue)
+	wg.Wait()
+
+	return result
+}
+
+// billOne waits for the charge limiter and bills a subscription, turning a panic into an error
+func billOne(ctx context.Context, sub *sqlc.Subscription, limiter *rate.Limiter, logger *zap.Logger, bill billFunc) (charged decimal.Decimal, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Recovered from panic while billing subscription",
+				zap.String("subscription_id", sub.ID.String()),
+				zap.Any("panic", r),
+				zap.Stack("stack"),
+			)
+			charged, err = decimal.Zero, fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return decimal.Zero, fmt.Errorf("wait for charge limiter: %w", err)
+		}
+	}
+	return bill(ctx, sub)
+}
@@ -0,0 +1,102 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+func dueSubscriptions(n int) []sqlc.Subscription {
+	subs := make([]sqlc.Subscription, n)
+	for i := range subs {
+		subs[i] = sqlc.Subscription{ID: uuid.New()}
+	}
+	return subs
+}
+
+func TestBillSubscriptions_AllProcessedOnce(t *testing.T) {
+	subs := dueSubscriptions(50)
+	subs[0].FailureRetryCount = 2
+
+	var mu sync.Mutex
+	billed := make(map[uuid.UUID]int)
+	started := make(chan struct{}, len(subs))
+	release := make(chan struct{})
+	done := make(chan *domain.BillingRunResult)
+
+	go func() {
+		done <- billSubscriptions(context.Background(), subs, 4, nil, zap.NewNop(), func(ctx context.Context, sub *sqlc.Subscription) (decimal.Decimal, error) {
+			started <- struct{}{}
+			<-release
+
+			mu.Lock()
+			billed[sub.ID]++
+			mu.Unlock()
+			return decimal.RequireFromString("10.00"), nil
+		})
+	}()
+
+	// Four workers pick up a subscription each and hold it; nothing else starts until one finishes
+	for i := 0; i < 4; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of 4 workers started billing", i)
+		}
+	}
+	select {
+	case <-started:
+		t.Fatal("a fifth subscription was billed while four were in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	result := <-done
+
+	assert.Len(t, billed, 50)
+	for id, n := range billed {
+		assert.Equal(t, 1, n, "subscription %s billed once", id)
+	}
+	assert.Equal(t, 50, result.Processed)
+	assert.Equal(t, 50, result.Succeeded)
+	assert.Equal(t, 1, result.Retried)
+	assert.True(t, result.AmountCharged.Equal(decimal.RequireFromString("500.00")))
+}
+
+func TestBillSubscriptions_FailuresDontAbortBatch(t *testing.T) {
+	subs := dueSubscriptions(5)
+	core, logs := observer.New(zapcore.ErrorLevel)
+
+	result := billSubscriptions(context.Background(), subs, 2, nil, zap.New(core), func(ctx context.Context, sub *sqlc.Subscription) (decimal.Decimal, error) {
+		switch sub.ID {
+		case subs[1].ID:
+			panic("nil payment method")
+		case subs[3].ID:
+			return decimal.Zero, errors.New("transaction declined")
+		}
+		return decimal.RequireFromString("5.00"), nil
+	})
+
+	assert.Equal(t, 5, result.Processed)
+	assert.Equal(t, 3, result.Succeeded)
+	assert.Equal(t, 2, result.Failed)
+	assert.True(t, result.AmountCharged.Equal(decimal.RequireFromString("15.00")))
+	require.Len(t, result.Errors, 2)
+
+	panics := logs.FilterMessage("Recovered from panic while billing subscription").All()
+	require.Len(t, panics, 1)
+	assert.Equal(t, subs[1].ID.String(), panics[0].ContextMap()["subscription_id"])
+	assert.Equal(t, "nil payment method", panics[0].ContextMap()["panic"])
+}
@@ -0,0 +1,88 @@
+package subscription
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// couponReader loads a subscription's coupon to price its next charge
+type couponReader interface {
+	GetCouponByID(ctx context.Context, id uuid.UUID) (sqlc.Coupon, error)
+}
+
+// billingPreviewQueries are the reads a billing dry run makes. It has no writes, so a dry run can't change anything.
+type billingPreviewQueries interface {
+	couponReader
+	ListSubscriptionsDueForBilling(ctx context.Context, arg sqlc.ListSubscriptionsDueForBillingParams) ([]sqlc.Subscription, error)
+	GetAgentByAgentID(ctx context.Context, agentID string) (sqlc.AgentCredential, error)
+	GetPaymentMethodByID(ctx context.Context, id uuid.UUID) (sqlc.CustomerPaymentMethod, error)
+}
+
+// PreviewDueBilling selects and prices the subscriptions a billing run would charge, without charging them
+func (s *subscriptionService) PreviewDueBilling(ctx context.Context, asOfDate time.Time, batchSize int) ([]*domain.PlannedCharge, error) {
+	if batchSize > s.billing.MaxPerRun {
+		batchSize = s.billing.MaxPerRun
+	}
+	return previewDueBilling(ctx, s.db.Queries(), asOfDate, batchSize, s.logger)
+}
+
+func previewDueBilling(ctx context.Context, q billingPreviewQueries, asOfDate time.Time, batchSize int, logger *zap.Logger) ([]*domain.PlannedCharge, error) {
+	dueSubs, err := q.ListSubscriptionsDueForBilling(ctx, sqlc.ListSubscriptionsDueForBillingParams{
+		NextBillingDate: pgtype.Date{Time: asOfDate, Valid: true},
+		LimitVal:        int32(batchSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	charges := make([]*domain.PlannedCharge, len(dueSubs))
+	for i := range dueSubs {
+		charges[i] = planCharge(ctx, q, &dueSubs[i], logger)
+	}
+	return charges, nil
+}
+
+// planCharge mirrors the checks processSubscriptionBilling makes before calling EPX
+func planCharge(ctx context.Context, q billingPreviewQueries, sub *sqlc.Subscription, logger *zap.Logger) *domain.PlannedCharge {
+	charge := &domain.PlannedCharge{
+		SubscriptionID:  sub.ID.String(),
+		AgentID:         sub.AgentID,
+		CustomerID:      sub.CustomerID,
+		PaymentMethodID: sub.PaymentMethodID.String(),
+		Amount:          decimal.NewFromBigInt(sub.Amount.Int, sub.Amount.Exp),
+		Currency:        sub.Currency,
+	}
+
+	agent, err := q.GetAgentByAgentID(ctx, sub.AgentID)
+	if err != nil {
+		charge.WouldFail = "agent not found"
+		return charge
+	}
+	if !agent.IsActive.Valid || !agent.IsActive.Bool {
+		charge.WouldFail = "agent is not active"
+		return charge
+	}
+
+	pm, err := q.GetPaymentMethodByID(ctx, sub.PaymentMethodID)
+	if err != nil {
+		charge.WouldFail = "payment method not found"
+		return charge
+	}
+	charge.PaymentType = pm.PaymentType
+	charge.LastFour = pm.LastFour
+	if !pm.IsActive.Valid || !pm.IsActive.Bool {
+		charge.WouldFail = "payment method is not active"
+		return charge
+	}
+
+	charge.Amount, charge.CouponApplied = subscriptionChargeAmount(ctx, q, sub, logger)
+	return charge
+}
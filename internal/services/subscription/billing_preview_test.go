@@ -0,0 +1,115 @@
+package subscription
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// fakePreviewQueries serves the billing dry run's reads from memory. It has no write methods, so
+// a dry run has nowhere to create a transaction or advance a subscription.
+type fakePreviewQueries struct {
+	subs    []sqlc.Subscription
+	agents  map[string]sqlc.AgentCredential
+	methods map[uuid.UUID]sqlc.CustomerPaymentMethod
+	coupons map[uuid.UUID]sqlc.Coupon
+}
+
+func (f *fakePreviewQueries) ListSubscriptionsDueForBilling(ctx context.Context, arg sqlc.ListSubscriptionsDueForBillingParams) ([]sqlc.Subscription, error) {
+	var due []sqlc.Subscription
+	for _, sub := range f.subs {
+		if sub.Status == string(domain.SubscriptionStatusActive) && !sub.NextBillingDate.Time.After(arg.NextBillingDate.Time) {
+			due = append(due, sub)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakePreviewQueries) GetAgentByAgentID(ctx context.Context, agentID string) (sqlc.AgentCredential, error) {
+	agent, ok := f.agents[agentID]
+	if !ok {
+		return sqlc.AgentCredential{}, pgx.ErrNoRows
+	}
+	return agent, nil
+}
+
+func (f *fakePreviewQueries) GetPaymentMethodByID(ctx context.Context, id uuid.UUID) (sqlc.CustomerPaymentMethod, error) {
+	pm, ok := f.methods[id]
+	if !ok {
+		return sqlc.CustomerPaymentMethod{}, pgx.ErrNoRows
+	}
+	return pm, nil
+}
+
+func (f *fakePreviewQueries) GetCouponByID(ctx context.Context, id uuid.UUID) (sqlc.Coupon, error) {
+	coupon, ok := f.coupons[id]
+	if !ok {
+		return sqlc.Coupon{}, pgx.ErrNoRows
+	}
+	return coupon, nil
+}
+
+func TestPreviewDueBilling(t *testing.T) {
+	asOf := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	card := sqlc.CustomerPaymentMethod{ID: uuid.New(), PaymentType: "credit_card", LastFour: "4242", IsActive: pgtype.Bool{Bool: true, Valid: true}}
+	closed := sqlc.CustomerPaymentMethod{ID: uuid.New(), PaymentType: "ach", LastFour: "6789", IsActive: pgtype.Bool{Bool: false, Valid: true}}
+	coupon := sqlc.Coupon{
+		ID:           uuid.New(),
+		DiscountType: string(domain.DiscountTypePercent),
+		PercentOff:   pgtype.Numeric{Int: big.NewInt(20), Valid: true},
+		Duration:     string(domain.CouponDurationForever),
+		IsActive:     true,
+	}
+	subscription := func(pmID uuid.UUID, nextBilling time.Time) sqlc.Subscription {
+		return sqlc.Subscription{
+			ID:              uuid.New(),
+			AgentID:         "merchant-1",
+			CustomerID:      "customer-1",
+			Amount:          pgtype.Numeric{Int: big.NewInt(2999), Exp: -2, Valid: true},
+			Currency:        "USD",
+			Status:          string(domain.SubscriptionStatusActive),
+			NextBillingDate: pgtype.Date{Time: nextBilling, Valid: true},
+			PaymentMethodID: pmID,
+		}
+	}
+
+	discounted := subscription(card.ID, asOf)
+	discounted.CouponID = pgtype.UUID{Bytes: coupon.ID, Valid: true}
+	q := &fakePreviewQueries{
+		subs: []sqlc.Subscription{
+			subscription(card.ID, asOf.AddDate(0, 0, -1)),
+			discounted,
+			subscription(closed.ID, asOf),
+			subscription(card.ID, asOf.AddDate(0, 0, 1)), // Not due yet
+		},
+		agents:  map[string]sqlc.AgentCredential{"merchant-1": {AgentID: "merchant-1", IsActive: pgtype.Bool{Bool: true, Valid: true}}},
+		methods: map[uuid.UUID]sqlc.CustomerPaymentMethod{card.ID: card, closed.ID: closed},
+		coupons: map[uuid.UUID]sqlc.Coupon{coupon.ID: coupon},
+	}
+
+	charges, err := previewDueBilling(context.Background(), q, asOf, 100, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, charges, 3)
+
+	assert.Equal(t, "29.99", charges[0].Amount.StringFixed(2))
+	assert.Equal(t, "4242", charges[0].LastFour)
+	assert.Equal(t, card.ID.String(), charges[0].PaymentMethodID)
+	assert.Empty(t, charges[0].WouldFail)
+
+	assert.Equal(t, "23.99", charges[1].Amount.StringFixed(2), "the coupon is applied as the real run would")
+	assert.True(t, charges[1].CouponApplied)
+
+	assert.Equal(t, "payment method is not active", charges[2].WouldFail)
+	assert.Equal(t, "ach", charges[2].PaymentType)
+}
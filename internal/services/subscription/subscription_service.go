@@ -3,39 +3,67 @@ package subscription
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/kevin07696/payment-service/internal/adapters/database"
 	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
 	"github.com/kevin07696/payment-service/internal/db/sqlc"
 	"github.com/kevin07696/payment-service/internal/domain"
 	"github.com/kevin07696/payment-service/internal/services/ports"
+	"github.com/kevin07696/payment-service/internal/services/webhook"
+	pkgerrors "github.com/kevin07696/payment-service/pkg/errors"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
+// EventPublisher delivers webhook events to the merchant's active subscriptions
+type EventPublisher interface {
+	DeliverEvent(ctx context.Context, event *webhook.WebhookEvent) error
+}
+
 // subscriptionService implements the SubscriptionService port
 type subscriptionService struct {
-	db            *database.PostgreSQLAdapter
+	db            database.Store
 	serverPost    adapterports.ServerPostAdapter
+	reporting     adapterports.MerchantReportingAdapter
+	epxEnv        domain.Environment // EPX environment the adapters are configured for
 	secretManager adapterports.SecretManagerAdapter
+	events        EventPublisher
+	billing       BillingConfig
+	chargeLimiter *rate.Limiter // nil when billing charges aren't rate limited
 	logger        *zap.Logger
 }
 
 // NewSubscriptionService creates a new subscription service
+// events may be nil to disable subscription webhooks
+// reporting may be nil; a charge whose outcome is unknown is then left for the next run
 func NewSubscriptionService(
-	db *database.PostgreSQLAdapter,
+	db database.Store,
 	serverPost adapterports.ServerPostAdapter,
+	reporting adapterports.MerchantReportingAdapter,
+	epxEnv domain.Environment,
 	secretManager adapterports.SecretManagerAdapter,
+	events EventPublisher,
+	billing BillingConfig,
 	logger *zap.Logger,
 ) ports.SubscriptionService {
+	billing = billing.withDefaults()
 	return &subscriptionService{
 		db:            db,
 		serverPost:    serverPost,
+		reporting:     reporting,
+		epxEnv:        epxEnv,
 		secretManager: secretManager,
+		events:        events,
+		billing:       billing,
+		chargeLimiter: billing.limiter(),
 		logger:        logger,
 	}
 }
@@ -89,12 +117,44 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req *ports
 		return nil, fmt.Errorf("amount must be greater than zero")
 	}
 
+	// Validate coupon if provided
+	var coupon *domain.Coupon
+	if req.CouponID != nil {
+		coupon, err = s.getRedeemableCoupon(ctx, *req.CouponID, req.AgentID, req.Currency)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Every charge the subscription makes must be within the merchant's amount range, discounted ones included
+	agent, err := s.db.Queries().GetAgentByAgentID(ctx, req.AgentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent: %w", err)
+	}
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSubscriptionAmount(config, amount, coupon); err != nil {
+		return nil, err
+	}
+
 	// Calculate next billing date
 	nextBillingDate := calculateNextBillingDate(req.StartDate, req.IntervalValue, req.IntervalUnit)
 
+	// Repeating coupons stop discounting N months after the first charge
+	var couponID pgtype.UUID
+	var couponEndsAt pgtype.Date
+	if coupon != nil {
+		couponID = toNullableUUID(&coupon.ID)
+		if endsAt := coupon.DiscountEndsAt(nextBillingDate); endsAt != nil {
+			couponEndsAt = pgtype.Date{Time: *endsAt, Valid: true}
+		}
+	}
+
 	// Create subscription in database
 	var subscription *domain.Subscription
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
 		// Marshal metadata
 		metadataJSON, err := json.Marshal(req.Metadata)
 		if err != nil {
@@ -117,6 +177,8 @@ func (s *subscriptionService) CreateSubscription(ctx context.Context, req *ports
 			MaxRetries:            int32(req.MaxRetries),
 			GatewaySubscriptionID: pgtype.Text{Valid: false}, // EPX doesn't use gateway subscription IDs
 			Metadata:              metadataJSON,
+			CouponID:              couponID,
+			CouponEndsAt:          couponEndsAt,
 		}
 
 		dbSub, err := q.CreateSubscription(ctx, params)
@@ -175,7 +237,7 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, req *ports
 	}
 
 	var subscription *domain.Subscription
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
 		// Build update params
 		params := sqlc.UpdateSubscriptionParams{
 			ID: subID,
@@ -190,6 +252,17 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, req *ports
 			if amount.LessThanOrEqual(decimal.Zero) {
 				return fmt.Errorf("amount must be greater than zero")
 			}
+
+			existingAmount := decimal.NewFromBigInt(existing.Amount.Int, existing.Amount.Exp)
+			if !amount.Equal(existingAmount) {
+				if err := checkAmountChangeAllowed(ctx, q, &existing); err != nil {
+					return err
+				}
+				if err := checkUpdatedAmountRange(ctx, q, &existing, amount); err != nil {
+					return err
+				}
+			}
+
 			params.Amount = toNumeric(amount)
 		} else {
 			params.Amount = existing.Amount
@@ -254,6 +327,73 @@ func (s *subscriptionService) UpdateSubscription(ctx context.Context, req *ports
 	return subscription, nil
 }
 
+// checkAmountChangeAllowed enforces the agent's minimum interval between subscription amount changes. The amount
+// was set when the subscription was created, so the first change waits out the interval from creation.
+func checkAmountChangeAllowed(ctx context.Context, q sqlc.Querier, sub *sqlc.Subscription) error {
+	agent, err := q.GetAgentByAgentID(ctx, sub.AgentID)
+	if err != nil {
+		return fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	// Unlimited unless the agent has configured a minimum interval
+	if !agent.SubscriptionAmountChangeMinDays.Valid {
+		return nil
+	}
+
+	lastChangedAt := sub.CreatedAt
+	if sub.AmountChangedAt.Valid {
+		lastChangedAt = sub.AmountChangedAt.Time
+	}
+
+	return validateAmountChangeInterval(lastChangedAt, int(agent.SubscriptionAmountChangeMinDays.Int32), time.Now())
+}
+
+// checkUpdatedAmountRange checks a subscription's new amount, and its discounted amount while the coupon still
+// applies, against the merchant's amount range
+func checkUpdatedAmountRange(ctx context.Context, q sqlc.Querier, sub *sqlc.Subscription, amount decimal.Decimal) error {
+	agent, err := q.GetAgentByAgentID(ctx, sub.AgentID)
+	if err != nil {
+		return fmt.Errorf("failed to get agent: %w", err)
+	}
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		return err
+	}
+
+	var coupon *domain.Coupon
+	if sub.CouponID.Valid {
+		dbCoupon, err := q.GetCouponByID(ctx, uuid.UUID(sub.CouponID.Bytes))
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to get coupon: %w", err)
+		}
+		if err == nil {
+			if current := sqlcCouponToDomain(&dbCoupon); current.IsRedeemable(time.Now()) {
+				coupon = current
+			}
+		}
+	}
+	return checkSubscriptionAmount(config, amount, coupon)
+}
+
+// checkSubscriptionAmount fails with ErrAmountOutOfRange unless amount, and the discounted amount when coupon
+// is set, are within the merchant's amount range. A fully discounted charge skips EPX, so it isn't checked.
+func checkSubscriptionAmount(config *domain.MerchantConfig, amount decimal.Decimal, coupon *domain.Coupon) error {
+	if err := config.CheckAmount(amount); err != nil {
+		return err
+	}
+	if coupon == nil {
+		return nil
+	}
+	discounted := coupon.ApplyDiscount(amount)
+	if discounted.IsZero() {
+		return nil
+	}
+	if err := config.CheckAmount(discounted); err != nil {
+		return fmt.Errorf("with coupon %s: %w", coupon.ID, err)
+	}
+	return nil
+}
+
 // CancelSubscription cancels an active subscription
 func (s *subscriptionService) CancelSubscription(ctx context.Context, req *ports.CancelSubscriptionRequest) (*domain.Subscription, error) {
 	s.logger.Info("Canceling subscription",
@@ -276,7 +416,7 @@ func (s *subscriptionService) CancelSubscription(ctx context.Context, req *ports
 	}
 
 	var subscription *domain.Subscription
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
 		// Get existing subscription
 		existing, err := q.GetSubscriptionByID(ctx, subID)
 		if err != nil {
@@ -342,7 +482,7 @@ func (s *subscriptionService) PauseSubscription(ctx context.Context, subscriptio
 	}
 
 	var subscription *domain.Subscription
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
 		// Get existing subscription
 		existing, err := q.GetSubscriptionByID(ctx, subID)
 		if err != nil {
@@ -392,7 +532,7 @@ func (s *subscriptionService) ResumeSubscription(ctx context.Context, subscripti
 	}
 
 	var subscription *domain.Subscription
-	err = s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
 		// Get existing subscription
 		existing, err := q.GetSubscriptionByID(ctx, subID)
 		if err != nil {
@@ -468,86 +608,248 @@ func (s *subscriptionService) ListCustomerSubscriptions(ctx context.Context, age
 	return subscriptions, nil
 }
 
+// ListSubscriptionTransactions lists the billing history of a subscription (charges, failed attempts, refunds)
+func (s *subscriptionService) ListSubscriptionTransactions(ctx context.Context, subscriptionID string, limit, offset int) ([]*domain.Transaction, int, error) {
+	subID, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid subscription_id format: %w", err)
+	}
+
+	if _, err := s.db.Queries().GetSubscriptionByID(ctx, subID); err != nil {
+		return nil, 0, domain.ErrSubscriptionNotFound
+	}
+
+	dbTxs, err := s.db.Queries().ListSubscriptionTransactions(ctx, sqlc.ListSubscriptionTransactionsParams{
+		SubscriptionID: subID.String(),
+		LimitVal:       int32(limit),
+		OffsetVal:      int32(offset),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list subscription transactions: %w", err)
+	}
+
+	count, err := s.db.Queries().CountSubscriptionTransactions(ctx, subID.String())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count subscription transactions: %w", err)
+	}
+
+	transactions := make([]*domain.Transaction, len(dbTxs))
+	for i, dbTx := range dbTxs {
+		transactions[i] = sqlcTransactionToDomain(&dbTx)
+	}
+
+	return transactions, int(count), nil
+}
+
+// GetSubscriptionChargeHistory returns every billing attempt of a merchant's subscription, oldest first.
+// A subscription belonging to another merchant is reported as not found.
+func (s *subscriptionService) GetSubscriptionChargeHistory(ctx context.Context, agentID, subscriptionID string) ([]*domain.SubscriptionChargeAttempt, error) {
+	subID, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription_id format: %w", err)
+	}
+
+	sub, err := s.db.Queries().GetSubscriptionByID(ctx, subID)
+	if err != nil || sub.AgentID != agentID {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	dbTxs, err := s.db.Queries().ListSubscriptionChargeAttempts(ctx, sqlc.ListSubscriptionChargeAttemptsParams{
+		SubscriptionID: subID.String(),
+		AgentID:        agentID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscription charge attempts: %w", err)
+	}
+
+	return buildChargeHistory(dbTxs, s.serverPost.ClassifyResponse), nil
+}
+
+// buildChargeHistory turns a subscription's charges (oldest first) into billing attempts. Retry numbers count the
+// declines since the last approved charge; classify maps a stored EPX response code to its decline reason.
+func buildChargeHistory(dbTxs []sqlc.Transaction, classify func(authResp, authRespText string) *adapterports.ServerPostResponse) []*domain.SubscriptionChargeAttempt {
+	attempts := make([]*domain.SubscriptionChargeAttempt, len(dbTxs))
+	retry := 0
+	for i := range dbTxs {
+		tx := sqlcTransactionToDomain(&dbTxs[i])
+		attempt := &domain.SubscriptionChargeAttempt{
+			TransactionID: tx.ID,
+			AttemptedAt:   tx.CreatedAt,
+			Amount:        tx.Amount,
+			Currency:      tx.Currency,
+			Status:        tx.Status,
+			AuthResp:      dbTxs[i].AuthResp.String,
+			RetryNumber:   retry,
+		}
+
+		if tx.Status == domain.TransactionStatusFailed {
+			attempt.DeclineReason = dbTxs[i].AuthRespText.String
+			if attempt.AuthResp != "" {
+				if decline := classify(attempt.AuthResp, attempt.DeclineReason).Decline; decline != nil {
+					attempt.DeclineCode = decline.Code
+					attempt.DeclineReason = decline.Message
+				}
+			}
+			retry++
+		} else if tx.Status == domain.TransactionStatusCompleted {
+			retry = 0
+		}
+
+		attempts[i] = attempt
+	}
+	return attempts
+}
+
 // ProcessDueBilling processes subscriptions due for billing (cron/admin)
-func (s *subscriptionService) ProcessDueBilling(ctx context.Context, asOfDate time.Time, batchSize int) (processed, success, failed int, errors []error) {
+// Subscriptions are billed concurrently, up to BillingConfig.Concurrency at a time and BillingConfig.MaxPerRun per run.
+// The run claims the subscriptions it bills, so overlapping runs on any instance never bill one twice.
+func (s *subscriptionService) ProcessDueBilling(ctx context.Context, asOfDate time.Time, batchSize int) *domain.BillingRunResult {
+	if batchSize > s.billing.MaxPerRun {
+		batchSize = s.billing.MaxPerRun
+	}
+
 	s.logger.Info("Processing due billing",
 		zap.Time("as_of_date", asOfDate),
 		zap.Int("batch_size", batchSize),
+		zap.Int("concurrency", s.billing.Concurrency),
 	)
 
-	// Get subscriptions due for billing
-	params := sqlc.ListSubscriptionsDueForBillingParams{
+	// Claim subscriptions due for billing; ones another run holds are skipped
+	params := sqlc.ClaimSubscriptionsDueForBillingParams{
 		NextBillingDate: pgtype.Date{Time: asOfDate, Valid: true},
+		StaleBefore:     time.Now().Add(-billingClaimStaleAfter),
 		LimitVal:        int32(batchSize),
 	}
 
-	dueSubs, err := s.db.Queries().ListSubscriptionsDueForBilling(ctx, params)
+	dueSubs, err := s.db.Queries().ClaimSubscriptionsDueForBilling(ctx, params)
 	if err != nil {
-		s.logger.Error("Failed to list due subscriptions", zap.Error(err))
-		return 0, 0, 0, []error{err}
+		s.logger.Error("Failed to claim due subscriptions", zap.Error(err))
+		return &domain.BillingRunResult{AmountCharged: decimal.Zero, Errors: []error{err}}
 	}
 
-	processed = len(dueSubs)
 	s.logger.Info("Found subscriptions due for billing",
-		zap.Int("count", processed),
+		zap.Int("count", len(dueSubs)),
 	)
 
-	// Process each subscription
-	for _, sub := range dueSubs {
-		if err := s.processSubscriptionBilling(ctx, &sub); err != nil {
-			failed++
-			errors = append(errors, fmt.Errorf("subscription %s: %w", sub.ID.String(), err))
-			s.logger.Error("Failed to process subscription billing",
-				zap.String("subscription_id", sub.ID.String()),
-				zap.Error(err),
-			)
-		} else {
-			success++
-			s.logger.Info("Successfully processed subscription billing",
-				zap.String("subscription_id", sub.ID.String()),
-			)
-		}
-	}
+	result := billSubscriptions(ctx, dueSubs, s.billing.Concurrency, s.chargeLimiter, s.logger, s.billClaimedSubscription)
 
 	s.logger.Info("Billing processing completed",
-		zap.Int("processed", processed),
-		zap.Int("success", success),
-		zap.Int("failed", failed),
+		zap.Int("processed", result.Processed),
+		zap.Int("success", result.Succeeded),
+		zap.Int("failed", result.Failed),
+		zap.Int("retried", result.Retried),
+		zap.String("amount_charged", result.AmountCharged.String()),
 	)
 
-	return processed, success, failed, errors
+	return result
 }
 
-// processSubscriptionBilling handles billing for a single subscription
-func (s *subscriptionService) processSubscriptionBilling(ctx context.Context, sub *sqlc.Subscription) error {
+// billClaimedSubscription bills a subscription this run claimed and then releases the claim, whatever the outcome.
+// A claim that can't be released goes stale after billingClaimStaleAfter and is taken over by a later run.
+func (s *subscriptionService) billClaimedSubscription(ctx context.Context, sub *sqlc.Subscription) (decimal.Decimal, error) {
+	defer func() {
+		if err := s.db.Queries().ReleaseSubscriptionBillingClaim(context.WithoutCancel(ctx), sub.ID); err != nil {
+			s.logger.Warn("Failed to release subscription billing claim",
+				zap.String("subscription_id", sub.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}()
+	return s.processSubscriptionBilling(ctx, sub)
+}
+
+// processSubscriptionBilling handles billing for a single subscription and returns the amount charged
+// (zero when the charge was fully discounted)
+func (s *subscriptionService) processSubscriptionBilling(ctx context.Context, sub *sqlc.Subscription) (decimal.Decimal, error) {
 	// Get agent credentials
 	agent, err := s.db.Queries().GetAgentByAgentID(ctx, sub.AgentID)
 	if err != nil {
-		return fmt.Errorf("failed to get agent: %w", err)
+		return decimal.Zero, fmt.Errorf("failed to get agent: %w", err)
 	}
 
 	if !agent.IsActive.Valid || !agent.IsActive.Bool {
-		return fmt.Errorf("agent is not active")
+		return decimal.Zero, fmt.Errorf("agent is not active")
+	}
+	if err := database.CheckEPXEnvironment(&agent, s.epxEnv); err != nil {
+		s.logger.Error("Merchant environment doesn't match the EPX environment",
+			zap.String("agent_id", agent.AgentID),
+			zap.String("merchant_environment", agent.Environment),
+			zap.String("epx_environment", string(s.epxEnv)),
+		)
+		return decimal.Zero, fmt.Errorf("subscription charge refused: %w", err)
 	}
 
 	// Get payment method
 	pm, err := s.db.Queries().GetPaymentMethodByID(ctx, sub.PaymentMethodID)
 	if err != nil {
-		return fmt.Errorf("failed to get payment method: %w", err)
+		return decimal.Zero, fmt.Errorf("failed to get payment method: %w", err)
+	}
+
+	config, err := domain.ResolveStoredMerchantConfig(agent.Tier, agent.ConfigOverrides)
+	if err != nil {
+		s.logger.Error("Merchant config overrides are invalid", zap.String("agent_id", agent.AgentID), zap.Error(err))
+		return decimal.Zero, err
 	}
 
 	if !pm.IsActive.Valid || !pm.IsActive.Bool {
-		return fmt.Errorf("payment method is not active")
+		// An ACH account deactivated by a return will never succeed - go straight to dunning
+		if achReturnDeactivated(&pm) {
+			return s.handleBillingFailure(ctx, sub, &pm, config, fmt.Errorf("payment method deactivated after ACH return: %s", pm.DeactivationReason.String))
+		}
+		return decimal.Zero, fmt.Errorf("payment method is not active")
 	}
 
 	// Get MAC secret for EPX request signing
 	_, err = s.secretManager.GetSecret(ctx, agent.MacSecretPath)
 	if err != nil {
-		return fmt.Errorf("failed to get MAC secret: %w", err)
+		return decimal.Zero, fmt.Errorf("failed to get MAC secret: %w", err)
+	}
+
+	// Apply coupon discount (if any) to this charge
+	amount, couponApplied := s.chargeAmount(ctx, sub)
+	couponAppliedCount := sub.CouponAppliedCount
+	if couponApplied {
+		couponAppliedCount++
+	}
+
+	// A fully discounted charge has nothing to collect - skip EPX and advance the billing date
+	if amount.IsZero() {
+		s.logger.Info("Subscription charge fully discounted, skipping gateway",
+			zap.String("subscription_id", sub.ID.String()),
+		)
+		return decimal.Zero, s.db.WithTx(ctx, func(q sqlc.Querier) error {
+			return s.advanceBillingDate(ctx, q, sub, couponAppliedCount)
+		})
+	}
+
+	// Not a decline: an amount outside the merchant's range never reaches EPX. Create and update check the range, so
+	// this is a range the merchant changed since; retrying won't help, so the subscription is marked past_due once
+	// instead of staying due on every run
+	if err := config.CheckAmount(amount); err != nil {
+		return decimal.Zero, s.markAmountOutOfRange(ctx, sub, fmt.Errorf("subscription charge refused: %w", err))
+	}
+
+	// Kill switch: not a decline either; the subscription stays due until charges are turned back on
+	if err := database.CheckChargesEnabled(ctx, s.db.Queries(), agent.AgentID); err != nil {
+		return decimal.Zero, fmt.Errorf("subscription charge refused: %w", err)
+	}
+
+	// A charge left undecided is retried on the next run with the same TRAN_NBR, so EPX can't apply it twice
+	tranNbr, err := database.AllocateTranNbr(ctx, s.db.Queries(), agent.AgentID, billingChargeRequestID(sub, &pm))
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	// Hold the amount against the merchant's daily volume limit; over the limit is refused like an out-of-range amount
+	reservation, err := database.ReserveDailyVolume(ctx, s.db.Queries(), agent.AgentID, amount, config.DailyVolumeLimit, time.Now())
+	if errors.Is(err, domain.ErrDailyVolumeLimitExceeded) {
+		return decimal.Zero, fmt.Errorf("subscription charge refused: %w", err)
+	}
+	if err != nil {
+		return decimal.Zero, err
 	}
 
 	// Prepare EPX request
-	amount := decimal.NewFromBigInt(sub.Amount.Int, sub.Amount.Exp)
 	epxReq := &adapterports.ServerPostRequest{
 		CustNbr:         agent.CustNbr,
 		MerchNbr:        agent.MerchNbr,
@@ -557,34 +859,58 @@ func (s *subscriptionService) processSubscriptionBilling(ctx context.Context, su
 		Amount:          amount.String(),
 		PaymentType:     adapterports.PaymentMethodType(pm.PaymentType),
 		AuthGUID:        pm.PaymentToken,
-		TranNbr:         uuid.New().String(),
+		TranNbr:         strconv.FormatInt(tranNbr, 10),
 		TranGroup:       uuid.New().String(),
 		CustomerID:      sub.CustomerID,
 	}
 
-	// Process transaction through EPX
-	epxResp, err := s.serverPost.ProcessTransaction(ctx, epxReq)
-	if err != nil {
-		// Handle billing failure
-		return s.handleBillingFailure(ctx, sub, err)
+	// Process transaction through EPX (unknown outcomes are looked up before deciding)
+	epxResp, outcome, err := s.submitCharge(ctx, epxReq, agent.AgentID, sub.NextBillingDate.Time)
+	if outcome != chargeApproved {
+		// Nothing was collected; an undecided charge reserves again when the next run retries it
+		if releaseErr := reservation.Release(ctx, s.db.Queries()); releaseErr != nil {
+			s.logger.Warn("Failed to release daily volume",
+				zap.String("subscription_id", sub.ID.String()),
+				zap.Error(releaseErr),
+			)
+		}
 	}
+	switch outcome {
+	case chargeIndeterminate:
+		// Not a decline: leave the subscription due so the next billing run tries again
+		return decimal.Zero, fmt.Errorf("subscription charge outcome unknown: %w", err)
+	case chargeFailed:
+		// Record the refused attempt too: it counts toward dunning, so the history's retry numbers must see it
+		s.recordFailedCharge(ctx, sub, &pm, amount, tranNbr, &adapterports.ServerPostResponse{
+			TranGroup:    epxReq.TranGroup,
+			AuthRespText: err.Error(),
+		})
+
+		// Handle billing failure
+		return s.handleBillingFailure(ctx, sub, &pm, config, err)
+	case chargeDeclined:
+		// Record the declined attempt so it appears in the subscription's billing history
+		s.recordFailedCharge(ctx, sub, &pm, amount, tranNbr, epxResp)
 
-	if !epxResp.IsApproved {
 		// Handle declined transaction
-		return s.handleBillingFailure(ctx, sub, fmt.Errorf("transaction declined: %s", epxResp.AuthRespText))
+		return s.handleBillingFailure(ctx, sub, &pm, config, fmt.Errorf("transaction declined: %s", epxResp.AuthRespText))
 	}
 
 	// Save transaction and update subscription
-	return s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+	err = s.db.WithTx(ctx, func(q sqlc.Querier) error {
 		// Create transaction record
 		status := domain.TransactionStatusCompleted
 		pmIDStr := pm.ID.String()
+		metadata := fmt.Sprintf(`{"subscription_id":"%s"}`, sub.ID.String())
+		if couponApplied {
+			metadata = fmt.Sprintf(`{"subscription_id":"%s","coupon_id":"%s"}`, sub.ID.String(), uuid.UUID(sub.CouponID.Bytes).String())
+		}
 		txParams := sqlc.CreateTransactionParams{
 			ID:                uuid.New(),
 			GroupID:           uuid.MustParse(epxResp.TranGroup),
 			AgentID:           sub.AgentID,
 			CustomerID:        toNullableText(&sub.CustomerID),
-			Amount:            sub.Amount,
+			Amount:            toNumeric(amount),
 			Currency:          sub.Currency,
 			Status:            string(status),
 			Type:              string(domain.TransactionTypeCharge),
@@ -598,7 +924,8 @@ func (s *subscriptionService) processSubscriptionBilling(ctx context.Context, su
 			AuthAvs:           toNullableText(&epxResp.AuthAVS),
 			AuthCvv2:          toNullableText(&epxResp.AuthCVV2),
 			IdempotencyKey:    pgtype.Text{Valid: false},
-			Metadata:          []byte(fmt.Sprintf(`{"subscription_id":"%s"}`, sub.ID.String())),
+			Metadata:          []byte(metadata),
+			TranNbr:           pgtype.Int8{Int64: tranNbr, Valid: true},
 		}
 
 		_, err := q.CreateTransaction(ctx, txParams)
@@ -606,37 +933,271 @@ func (s *subscriptionService) processSubscriptionBilling(ctx context.Context, su
 			return fmt.Errorf("failed to create transaction: %w", err)
 		}
 
-		// Calculate next billing date
-		nextBillingDate := calculateNextBillingDate(
-			sub.NextBillingDate.Time,
-			int(sub.IntervalValue),
-			domain.IntervalUnit(sub.IntervalUnit),
-		)
+		return s.advanceBillingDate(ctx, q, sub, couponAppliedCount)
+	})
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return amount, nil
+}
 
-		// Update subscription with new billing date and reset failure count
-		updateParams := sqlc.UpdateSubscriptionBillingParams{
-			ID:                sub.ID,
-			NextBillingDate:   pgtype.Date{Time: nextBillingDate, Valid: true},
-			FailureRetryCount: 0,
-			Status:            string(domain.SubscriptionStatusActive),
+// billingChargeRequestID identifies one attempt to collect a subscription's billing period: the same period,
+// dunning retry and payment method give the same ID, and so the same TRAN_NBR
+func billingChargeRequestID(sub *sqlc.Subscription, pm *sqlc.CustomerPaymentMethod) uuid.UUID {
+	return database.TranNbrRequestID("subscription-charge", sub.ID.String(), sub.NextBillingDate.Time.Format("2006-01-02"),
+		strconv.Itoa(int(sub.FailureRetryCount)), pm.ID.String())
+}
+
+// statusLookupTimeout bounds the settlement lookup after a charge whose outcome is unknown
+const statusLookupTimeout = 15 * time.Second
+
+// chargeOutcome is the result of a subscription charge after unknown outcomes are looked up
+type chargeOutcome int
+
+const (
+	chargeApproved      chargeOutcome = iota // EPX approved the charge
+	chargeDeclined                           // EPX declined the charge; counts toward dunning
+	chargeFailed                             // The charge never reached EPX; counts toward dunning
+	chargeIndeterminate                      // EPX may have processed the charge but its outcome isn't known; not counted, retried next run
+)
+
+// submitCharge sends a charge to EPX. Only a charge refused before it was sent counts as failed: after a timeout
+// or a lost response the charge may have been processed, so it is looked up in the merchant's settled
+// transactions (North's reporting search, from the billing date on) before deciding.
+func (s *subscriptionService) submitCharge(ctx context.Context, req *adapterports.ServerPostRequest, agentID string, since time.Time) (*adapterports.ServerPostResponse, chargeOutcome, error) {
+	resp, err := s.serverPost.ProcessTransaction(ctx, req)
+	if err == nil {
+		if resp.IsApproved {
+			return resp, chargeApproved, nil
 		}
+		return resp, chargeDeclined, nil
+	}
+	if chargeNeverSent(err) {
+		return nil, chargeFailed, err
+	}
 
-		_, err = q.UpdateSubscriptionBilling(ctx, updateParams)
-		if err != nil {
-			return fmt.Errorf("failed to update subscription: %w", err)
+	s.logger.Warn("Subscription charge outcome unknown, checking settled transactions",
+		zap.String("tran_nbr", req.TranNbr),
+		zap.Error(err),
+	)
+	if s.reporting == nil {
+		return nil, chargeIndeterminate, err
+	}
+
+	// The caller's context may be what expired; the lookup gets its own deadline
+	lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusLookupTimeout)
+	defer cancel()
+
+	settled, lookupErr := s.findSettledCharge(lookupCtx, agentID, req.TranNbr, since)
+	if lookupErr != nil {
+		return nil, chargeIndeterminate, fmt.Errorf("%w (settlement lookup: %v)", err, lookupErr)
+	}
+	if settled == nil {
+		// Not settled yet, or never processed; the next run resubmits with the same TRAN_NBR
+		return nil, chargeIndeterminate, err
+	}
+
+	status := &adapterports.ServerPostResponse{
+		AuthGUID:  settled.AuthGUID,
+		TranNbr:   req.TranNbr,
+		TranGroup: req.TranGroup,
+		Amount:    req.Amount,
+	}
+	if settled.Status != "settled" {
+		status.AuthRespText = "rejected at settlement"
+		return status, chargeDeclined, nil
+	}
+	status.IsApproved = true
+	return status, chargeApproved, nil
+}
+
+// findSettledCharge returns the merchant's settled (or rejected) transaction with tranNbr from since through
+// today, or nil if North has no record of it
+func (s *subscriptionService) findSettledCharge(ctx context.Context, agentID, tranNbr string, since time.Time) (*adapterports.SettledTransaction, error) {
+	settlements, err := s.reporting.SearchSettlements(ctx, &adapterports.SettlementSearchRequest{
+		MerchantID: agentID,
+		FromDate:   since,
+		ToDate:     time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, txn := range settlements.Transactions {
+		if txn.TransactionNumber == tranNbr {
+			return txn, nil
 		}
+	}
+	return nil, nil
+}
 
-		return nil
+// chargeNeverSent reports whether err means the charge was refused before it reached EPX. Any other
+// error (timeout, send, read or parse failure) leaves the outcome unknown.
+func chargeNeverSent(err error) bool {
+	var paymentErr *pkgerrors.PaymentError
+	return errors.As(err, &paymentErr) && paymentErr.Category == pkgerrors.CategoryInvalidRequest
+}
+
+// recordFailedCharge stores a failed transaction for a declined billing attempt, or one refused before it
+// reached EPX (no auth response; the refusal is kept as the response text)
+func (s *subscriptionService) recordFailedCharge(ctx context.Context, sub *sqlc.Subscription, pm *sqlc.CustomerPaymentMethod, amount decimal.Decimal, tranNbr int64, epxResp *adapterports.ServerPostResponse) {
+	pmIDStr := pm.ID.String()
+	groupID, err := uuid.Parse(epxResp.TranGroup)
+	if err != nil {
+		groupID = uuid.New()
+	}
+
+	_, err = s.db.Queries().CreateTransaction(ctx, sqlc.CreateTransactionParams{
+		ID:                uuid.New(),
+		GroupID:           groupID,
+		AgentID:           sub.AgentID,
+		CustomerID:        toNullableText(&sub.CustomerID),
+		Amount:            toNumeric(amount),
+		Currency:          sub.Currency,
+		Status:            string(domain.TransactionStatusFailed),
+		Type:              string(domain.TransactionTypeCharge),
+		PaymentMethodType: pm.PaymentType,
+		PaymentMethodID:   toNullableUUID(&pmIDStr),
+		AuthGuid:          toNullableText(&epxResp.AuthGUID),
+		AuthResp:          toNullableText(&epxResp.AuthResp),
+		AuthRespText:      toNullableText(&epxResp.AuthRespText),
+		AuthCardType:      toNullableText(&epxResp.AuthCardType),
+		IdempotencyKey:    pgtype.Text{Valid: false},
+		Metadata:          []byte(fmt.Sprintf(`{"subscription_id":"%s"}`, sub.ID.String())),
+		TranNbr:           pgtype.Int8{Int64: tranNbr, Valid: true},
 	})
+	if err != nil {
+		// Billing failure handling continues even if the history record can't be written
+		s.logger.Error("Failed to record failed subscription charge",
+			zap.String("subscription_id", sub.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// advanceBillingDate moves the subscription to its next billing date and resets the failure count
+func (s *subscriptionService) advanceBillingDate(ctx context.Context, q sqlc.Querier, sub *sqlc.Subscription, couponAppliedCount int32) error {
+	// Calculate next billing date
+	nextBillingDate := calculateNextBillingDate(
+		sub.NextBillingDate.Time,
+		int(sub.IntervalValue),
+		domain.IntervalUnit(sub.IntervalUnit),
+	)
+
+	// Update subscription with new billing date and reset failure count
+	updateParams := sqlc.UpdateSubscriptionBillingParams{
+		ID:                 sub.ID,
+		NextBillingDate:    pgtype.Date{Time: nextBillingDate, Valid: true},
+		FailureRetryCount:  0,
+		Status:             string(domain.SubscriptionStatusActive),
+		CouponAppliedCount: couponAppliedCount,
+	}
+
+	_, err := q.UpdateSubscriptionBilling(ctx, updateParams)
+	if err != nil {
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	return nil
+}
+
+// chargeAmount returns the amount to charge for the current billing cycle and whether a coupon was applied.
+// The coupon is re-validated on every charge; removed, deactivated or expired coupons fall back to the full amount.
+func (s *subscriptionService) chargeAmount(ctx context.Context, sub *sqlc.Subscription) (decimal.Decimal, bool) {
+	return subscriptionChargeAmount(ctx, s.db.Queries(), sub, s.logger)
+}
+
+// subscriptionChargeAmount computes the next charge of a subscription, reading its coupon through coupons
+func subscriptionChargeAmount(ctx context.Context, coupons couponReader, sub *sqlc.Subscription, logger *zap.Logger) (decimal.Decimal, bool) {
+	amount := decimal.NewFromBigInt(sub.Amount.Int, sub.Amount.Exp)
+	if !sub.CouponID.Valid {
+		return amount, false
+	}
+
+	dbCoupon, err := coupons.GetCouponByID(ctx, uuid.UUID(sub.CouponID.Bytes))
+	if err != nil {
+		logger.Warn("Subscription coupon not found, charging full amount",
+			zap.String("subscription_id", sub.ID.String()),
+			zap.Error(err),
+		)
+		return amount, false
+	}
+
+	coupon := sqlcCouponToDomain(&dbCoupon)
+	if !coupon.IsRedeemable(time.Now()) {
+		logger.Info("Subscription coupon expired or inactive, charging full amount",
+			zap.String("subscription_id", sub.ID.String()),
+			zap.String("coupon_id", coupon.ID),
+		)
+		return amount, false
+	}
+
+	var endsAt *time.Time
+	if sub.CouponEndsAt.Valid {
+		endsAt = &sub.CouponEndsAt.Time
+	}
+
+	return discountedChargeAmount(amount, coupon, sub.NextBillingDate.Time, int(sub.CouponAppliedCount), endsAt)
+}
+
+// getRedeemableCoupon loads a coupon and verifies it can be applied to a new subscription
+func (s *subscriptionService) getRedeemableCoupon(ctx context.Context, couponID, agentID, currency string) (*domain.Coupon, error) {
+	id, err := uuid.Parse(couponID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coupon_id format: %w", err)
+	}
+
+	dbCoupon, err := s.db.Queries().GetCouponByID(ctx, id)
+	if err != nil {
+		return nil, domain.ErrCouponNotFound
+	}
+
+	// Coupons are scoped to the agent that created them
+	if dbCoupon.AgentID != agentID {
+		return nil, domain.ErrCouponNotFound
+	}
+
+	coupon := sqlcCouponToDomain(&dbCoupon)
+	if !coupon.IsActive {
+		return nil, domain.ErrCouponInactive
+	}
+	if coupon.IsExpired(time.Now()) {
+		return nil, domain.ErrCouponExpired
+	}
+	if coupon.DiscountType == domain.DiscountTypeFixed && coupon.Currency != currency {
+		return nil, domain.ErrCouponCurrencyMismatch
+	}
+
+	return coupon, nil
 }
 
 // handleBillingFailure handles a failed billing attempt
-func (s *subscriptionService) handleBillingFailure(ctx context.Context, sub *sqlc.Subscription, billingErr error) error {
-	return s.db.WithTx(ctx, func(q *sqlc.Queries) error {
+func (s *subscriptionService) handleBillingFailure(ctx context.Context, sub *sqlc.Subscription, pm *sqlc.CustomerPaymentMethod, config *domain.MerchantConfig, billingErr error) (decimal.Decimal, error) {
+	// Only look up the customer's cards when the card fallback could apply
+	var methods []sqlc.CustomerPaymentMethod
+	if dunningExhausted(sub, pm) && achCardFallbackApplies(config, pm) {
+		var err error
+		methods, err = s.db.Queries().ListPaymentMethodsByCustomer(ctx, sqlc.ListPaymentMethodsByCustomerParams{
+			AgentID:    sub.AgentID,
+			CustomerID: sub.CustomerID,
+		})
+		if err != nil {
+			s.logger.Error("Failed to list payment methods for ACH card fallback",
+				zap.String("subscription_id", sub.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	action, card := planDunning(sub, pm, config, methods, time.Now())
+	if action == dunningSwitchToCard {
+		return s.switchToCard(ctx, sub, pm, card, billingErr)
+	}
+
+	return decimal.Zero, s.db.WithTx(ctx, func(q sqlc.Querier) error {
 		newRetryCount := sub.FailureRetryCount + 1
 		var newStatus string
 
-		if newRetryCount >= sub.MaxRetries {
+		if action == dunningPastDue {
 			// Max retries reached - mark as past_due
 			newStatus = string(domain.SubscriptionStatusPastDue)
 			s.logger.Warn("Subscription billing failed - max retries reached",
@@ -670,6 +1231,157 @@ func (s *subscriptionService) handleBillingFailure(ctx context.Context, sub *sql
 	})
 }
 
+// markAmountOutOfRange moves a subscription whose charge is outside the merchant's amount range to past_due
+// without counting a retry; it's billed again once the merchant fixes the amount and resumes it
+func (s *subscriptionService) markAmountOutOfRange(ctx context.Context, sub *sqlc.Subscription, billingErr error) error {
+	s.logger.Error("Subscription charge outside the merchant's amount range - marking past_due",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.Error(billingErr),
+	)
+	if _, err := s.db.Queries().IncrementSubscriptionFailureCount(ctx, sqlc.IncrementSubscriptionFailureCountParams{
+		ID:                sub.ID,
+		FailureRetryCount: sub.FailureRetryCount,
+		Status:            string(domain.SubscriptionStatusPastDue),
+	}); err != nil {
+		return fmt.Errorf("failed to mark subscription past_due: %w", err)
+	}
+	return billingErr
+}
+
+// switchToCard moves an exhausted ACH subscription to a card on file, announces it and retries the charge
+func (s *subscriptionService) switchToCard(ctx context.Context, sub *sqlc.Subscription, ach, card *sqlc.CustomerPaymentMethod, billingErr error) (decimal.Decimal, error) {
+	updated, err := s.db.Queries().SwitchSubscriptionPaymentMethod(ctx, sqlc.SwitchSubscriptionPaymentMethodParams{
+		ID:              sub.ID,
+		PaymentMethodID: card.ID,
+	})
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to switch subscription payment method: %w", err)
+	}
+
+	s.logger.Warn("ACH subscription exhausted retries - switched to card on file",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.String("previous_payment_method_id", ach.ID.String()),
+		zap.String("payment_method_id", card.ID.String()),
+		zap.Error(billingErr),
+	)
+
+	s.publishPaymentMethodSwitched(&updated, ach, card, billingErr)
+
+	// The card is not ACH, so a failure here follows normal dunning instead of switching again
+	return s.processSubscriptionBilling(ctx, &updated)
+}
+
+// publishPaymentMethodSwitched delivers the switch webhook asynchronously (never blocks billing)
+func (s *subscriptionService) publishPaymentMethodSwitched(sub *sqlc.Subscription, previous, card *sqlc.CustomerPaymentMethod, billingErr error) {
+	if s.events == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"subscription_id":            sub.ID.String(),
+		"customer_id":                sub.CustomerID,
+		"previous_payment_method_id": previous.ID.String(),
+		"payment_method_id":          card.ID.String(),
+		"reason":                     "ach_retries_exhausted",
+		"failure":                    billingErr.Error(),
+	}
+	if previous.LastReturnCode.Valid {
+		data["last_return_code"] = previous.LastReturnCode.String
+	}
+
+	event := &webhook.WebhookEvent{
+		EventType: webhook.EventSubscriptionPaymentMethodSwitched,
+		AgentID:   sub.AgentID,
+		Data:      data,
+		Timestamp: time.Now(),
+	}
+	go func() {
+		if err := s.events.DeliverEvent(context.Background(), event); err != nil {
+			s.logger.Error("Failed to deliver subscription webhook",
+				zap.String("event_type", event.EventType),
+				zap.String("subscription_id", sub.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// dunningAction is the next step after a failed billing attempt
+type dunningAction int
+
+const (
+	dunningRetry        dunningAction = iota // Retries remain; try again on the next billing run
+	dunningSwitchToCard                      // ACH retries exhausted; switch to a card on file and retry now
+	dunningPastDue                           // Retries exhausted; mark the subscription past_due
+)
+
+// planDunning decides what happens after a failed charge on pm. methods are the customer's
+// saved payment methods (only consulted for the ACH card fallback). The returned card is set
+// only for dunningSwitchToCard.
+func planDunning(sub *sqlc.Subscription, pm *sqlc.CustomerPaymentMethod, config *domain.MerchantConfig, methods []sqlc.CustomerPaymentMethod, now time.Time) (dunningAction, *sqlc.CustomerPaymentMethod) {
+	if !dunningExhausted(sub, pm) {
+		return dunningRetry, nil
+	}
+
+	if achCardFallbackApplies(config, pm) {
+		if card := selectFallbackCard(methods, now); card != nil {
+			return dunningSwitchToCard, card
+		}
+	}
+
+	return dunningPastDue, nil
+}
+
+// dunningExhausted returns true when this failure uses up the subscription's retries,
+// or the ACH account was deactivated by a return (retrying it can't succeed)
+func dunningExhausted(sub *sqlc.Subscription, pm *sqlc.CustomerPaymentMethod) bool {
+	return sub.FailureRetryCount+1 >= sub.MaxRetries || achReturnDeactivated(pm)
+}
+
+// achCardFallbackApplies returns true if the merchant opted in and the failing method is ACH
+func achCardFallbackApplies(config *domain.MerchantConfig, pm *sqlc.CustomerPaymentMethod) bool {
+	return config != nil && config.ACHCardFallback &&
+		pm != nil && pm.PaymentType == string(domain.PaymentMethodTypeACH)
+}
+
+// achReturnDeactivated returns true for ACH payment methods deactivated by an unrecoverable return
+func achReturnDeactivated(pm *sqlc.CustomerPaymentMethod) bool {
+	return pm != nil && pm.PaymentType == string(domain.PaymentMethodTypeACH) &&
+		pm.IsActive.Valid && !pm.IsActive.Bool && pm.DeactivationReason.Valid
+}
+
+// selectFallbackCard picks the customer's default card, or their newest usable card if the default isn't one
+func selectFallbackCard(methods []sqlc.CustomerPaymentMethod, now time.Time) *sqlc.CustomerPaymentMethod {
+	var fallback *sqlc.CustomerPaymentMethod
+	for i := range methods {
+		m := &methods[i]
+		if !cardUsable(m, now) {
+			continue
+		}
+		if m.IsDefault.Valid && m.IsDefault.Bool {
+			return m
+		}
+		if fallback == nil || m.CreatedAt.After(fallback.CreatedAt) {
+			fallback = m
+		}
+	}
+	return fallback
+}
+
+// cardUsable returns true for active, unexpired credit cards
+func cardUsable(m *sqlc.CustomerPaymentMethod, now time.Time) bool {
+	if m.PaymentType != string(domain.PaymentMethodTypeCreditCard) || !m.IsActive.Valid || !m.IsActive.Bool {
+		return false
+	}
+	if m.CardExpMonth.Valid && m.CardExpYear.Valid {
+		expYear, expMonth := int(m.CardExpYear.Int32), int(m.CardExpMonth.Int32)
+		if expYear < now.Year() || (expYear == now.Year() && expMonth < int(now.Month())) {
+			return false
+		}
+	}
+	return true
+}
+
 // getSubscriptionByIdempotencyKey retrieves a subscription by idempotency key
 func (s *subscriptionService) getSubscriptionByIdempotencyKey(ctx context.Context, key string) (*domain.Subscription, error) {
 	// Note: This would require a separate SQL query if we want to support idempotency for subscriptions
@@ -694,22 +1406,147 @@ func calculateNextBillingDate(currentDate time.Time, intervalValue int, interval
 	}
 }
 
+// validateAmountChangeInterval rejects an amount change made less than minDays after the amount was last set
+func validateAmountChangeInterval(lastChangedAt time.Time, minDays int, now time.Time) error {
+	if minDays <= 0 {
+		return nil
+	}
+
+	allowedAt := lastChangedAt.AddDate(0, 0, minDays)
+	if now.Before(allowedAt) {
+		return fmt.Errorf("%w: amount changes require %d days between them, next change allowed after %s",
+			domain.ErrAmountChangeTooSoon, minDays, allowedAt.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// discountedChargeAmount applies the coupon to a charge on billingDate if its duration still covers it
+func discountedChargeAmount(amount decimal.Decimal, coupon *domain.Coupon, billingDate time.Time, appliedCount int, endsAt *time.Time) (decimal.Decimal, bool) {
+	if !coupon.AppliesToCharge(billingDate, appliedCount, endsAt) {
+		return amount, false
+	}
+	return coupon.ApplyDiscount(amount), true
+}
+
+func sqlcCouponToDomain(dbCoupon *sqlc.Coupon) *domain.Coupon {
+	coupon := &domain.Coupon{
+		ID:           dbCoupon.ID.String(),
+		AgentID:      dbCoupon.AgentID,
+		Code:         dbCoupon.Code,
+		DiscountType: domain.DiscountType(dbCoupon.DiscountType),
+		Currency:     dbCoupon.Currency,
+		Duration:     domain.CouponDuration(dbCoupon.Duration),
+		IsActive:     dbCoupon.IsActive,
+		CreatedAt:    dbCoupon.CreatedAt,
+		UpdatedAt:    dbCoupon.UpdatedAt,
+	}
+
+	if dbCoupon.PercentOff.Valid {
+		percentOff := decimal.NewFromBigInt(dbCoupon.PercentOff.Int, dbCoupon.PercentOff.Exp)
+		coupon.PercentOff = &percentOff
+	}
+
+	if dbCoupon.AmountOff.Valid {
+		amountOff := decimal.NewFromBigInt(dbCoupon.AmountOff.Int, dbCoupon.AmountOff.Exp)
+		coupon.AmountOff = &amountOff
+	}
+
+	if dbCoupon.DurationInMonths.Valid {
+		months := int(dbCoupon.DurationInMonths.Int32)
+		coupon.DurationInMonths = &months
+	}
+
+	if dbCoupon.ExpiresAt.Valid {
+		coupon.ExpiresAt = &dbCoupon.ExpiresAt.Time
+	}
+
+	return coupon
+}
+
+func sqlcTransactionToDomain(dbTx *sqlc.Transaction) *domain.Transaction {
+	tx := &domain.Transaction{
+		ID:                dbTx.ID.String(),
+		GroupID:           dbTx.GroupID.String(),
+		AgentID:           dbTx.AgentID,
+		Amount:            decimal.NewFromBigInt(dbTx.Amount.Int, dbTx.Amount.Exp),
+		Currency:          dbTx.Currency,
+		Status:            domain.TransactionStatus(dbTx.Status),
+		Type:              domain.TransactionType(dbTx.Type),
+		PaymentMethodType: domain.PaymentMethodType(dbTx.PaymentMethodType),
+		DataRegion:        dbTx.DataRegion,
+		CreatedAt:         dbTx.CreatedAt,
+		UpdatedAt:         dbTx.UpdatedAt,
+	}
+
+	if dbTx.CustomerID.Valid {
+		tx.CustomerID = &dbTx.CustomerID.String
+	}
+	if dbTx.PaymentMethodID.Valid {
+		pmID := uuid.UUID(dbTx.PaymentMethodID.Bytes).String()
+		tx.PaymentMethodID = &pmID
+	}
+	if dbTx.AuthGuid.Valid {
+		tx.AuthGUID = &dbTx.AuthGuid.String
+	}
+	if dbTx.AuthResp.Valid {
+		tx.AuthResp = &dbTx.AuthResp.String
+	}
+	if dbTx.AuthCode.Valid {
+		tx.AuthCode = &dbTx.AuthCode.String
+	}
+	if dbTx.AuthRespText.Valid {
+		tx.AuthRespText = &dbTx.AuthRespText.String
+	}
+
+	if len(dbTx.Metadata) > 0 {
+		if err := json.Unmarshal(dbTx.Metadata, &tx.Metadata); err != nil {
+			tx.Metadata = nil
+		}
+	}
+
+	return tx
+}
+
 func sqlcSubscriptionToDomain(dbSub *sqlc.Subscription) *domain.Subscription {
 	sub := &domain.Subscription{
-		ID:                dbSub.ID.String(),
-		AgentID:           dbSub.AgentID,
-		CustomerID:        dbSub.CustomerID,
-		Amount:            decimal.NewFromBigInt(dbSub.Amount.Int, dbSub.Amount.Exp),
-		Currency:          dbSub.Currency,
-		IntervalValue:     int(dbSub.IntervalValue),
-		IntervalUnit:      domain.IntervalUnit(dbSub.IntervalUnit),
-		Status:            domain.SubscriptionStatus(dbSub.Status),
-		PaymentMethodID:   dbSub.PaymentMethodID.String(),
-		NextBillingDate:   dbSub.NextBillingDate.Time,
-		FailureRetryCount: int(dbSub.FailureRetryCount),
-		MaxRetries:        int(dbSub.MaxRetries),
-		CreatedAt:         dbSub.CreatedAt,
-		UpdatedAt:         dbSub.UpdatedAt,
+		ID:                 dbSub.ID.String(),
+		AgentID:            dbSub.AgentID,
+		CustomerID:         dbSub.CustomerID,
+		Amount:             decimal.NewFromBigInt(dbSub.Amount.Int, dbSub.Amount.Exp),
+		Currency:           dbSub.Currency,
+		IntervalValue:      int(dbSub.IntervalValue),
+		IntervalUnit:       domain.IntervalUnit(dbSub.IntervalUnit),
+		Status:             domain.SubscriptionStatus(dbSub.Status),
+		PaymentMethodID:    dbSub.PaymentMethodID.String(),
+		NextBillingDate:    dbSub.NextBillingDate.Time,
+		FailureRetryCount:  int(dbSub.FailureRetryCount),
+		MaxRetries:         int(dbSub.MaxRetries),
+		CouponAppliedCount: int(dbSub.CouponAppliedCount),
+		CreatedAt:          dbSub.CreatedAt,
+		UpdatedAt:          dbSub.UpdatedAt,
+	}
+
+	if dbSub.CouponID.Valid {
+		couponID := uuid.UUID(dbSub.CouponID.Bytes).String()
+		sub.CouponID = &couponID
+	}
+
+	if dbSub.AmountChangedAt.Valid {
+		sub.AmountChangedAt = &dbSub.AmountChangedAt.Time
+	}
+
+	if dbSub.PreviousPaymentMethodID.Valid {
+		previousID := uuid.UUID(dbSub.PreviousPaymentMethodID.Bytes).String()
+		sub.PreviousPaymentMethodID = &previousID
+	}
+
+	if dbSub.PaymentMethodSwitchedAt.Valid {
+		sub.PaymentMethodSwitchedAt = &dbSub.PaymentMethodSwitchedAt.Time
+	}
+
+	if dbSub.CouponEndsAt.Valid {
+		sub.CouponEndsAt = &dbSub.CouponEndsAt.Time
 	}
 
 	if dbSub.CancelledAt.Valid {
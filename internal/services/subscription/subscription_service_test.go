@@ -0,0 +1,966 @@
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	adapterports "github.com/kevin07696/payment-service/internal/adapters/ports"
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/internal/services/ports"
+	pkgerrors "github.com/kevin07696/payment-service/pkg/errors"
+)
+
+func newTwentyPercentForThreeMonthsCoupon() *domain.Coupon {
+	percentOff := decimal.NewFromInt(20)
+	months := 3
+	return &domain.Coupon{
+		ID:               "8f7c9c2e-6f0b-4a59-9d7e-6a1d2f3b4c5d",
+		AgentID:          "test-agent-123",
+		Code:             "SAVE20",
+		DiscountType:     domain.DiscountTypePercent,
+		PercentOff:       &percentOff,
+		Currency:         "USD",
+		Duration:         domain.CouponDurationRepeating,
+		DurationInMonths: &months,
+		IsActive:         true,
+	}
+}
+
+func TestDiscountedChargeAmount_TwentyPercentForThreeMonths(t *testing.T) {
+	coupon := newTwentyPercentForThreeMonthsCoupon()
+	amount := decimal.RequireFromString("49.99")
+
+	firstBillingDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	endsAt := coupon.DiscountEndsAt(firstBillingDate)
+	require.NotNil(t, endsAt)
+	assert.Equal(t, time.Date(2025, 4, 15, 0, 0, 0, 0, time.UTC), *endsAt)
+
+	expected := []struct {
+		amount  string
+		applied bool
+	}{
+		{"39.99", true},  // Jan
+		{"39.99", true},  // Feb
+		{"39.99", true},  // Mar
+		{"49.99", false}, // Apr - discount ended
+		{"49.99", false}, // May
+	}
+
+	billingDate := firstBillingDate
+	appliedCount := 0
+	for i, want := range expected {
+		charge, applied := discountedChargeAmount(amount, coupon, billingDate, appliedCount, endsAt)
+		assert.Equal(t, want.applied, applied, "cycle %d", i+1)
+		assert.True(t, decimal.RequireFromString(want.amount).Equal(charge), "cycle %d: got %s", i+1, charge)
+
+		if applied {
+			appliedCount++
+		}
+		billingDate = calculateNextBillingDate(billingDate, 1, domain.IntervalUnitMonth)
+	}
+
+	assert.Equal(t, 3, appliedCount)
+}
+
+func TestDiscountedChargeAmount_Durations(t *testing.T) {
+	amountOff := decimal.NewFromInt(10)
+	billingDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	amount := decimal.NewFromInt(25)
+
+	tests := []struct {
+		name         string
+		duration     domain.CouponDuration
+		appliedCount int
+		wantAmount   string
+		wantApplied  bool
+	}{
+		{"once - first charge", domain.CouponDurationOnce, 0, "15", true},
+		{"once - second charge", domain.CouponDurationOnce, 1, "25", false},
+		{"forever - any charge", domain.CouponDurationForever, 12, "15", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coupon := &domain.Coupon{
+				DiscountType: domain.DiscountTypeFixed,
+				AmountOff:    &amountOff,
+				Duration:     tt.duration,
+				IsActive:     true,
+			}
+
+			charge, applied := discountedChargeAmount(amount, coupon, billingDate, tt.appliedCount, nil)
+			assert.Equal(t, tt.wantApplied, applied)
+			assert.True(t, decimal.RequireFromString(tt.wantAmount).Equal(charge), "got %s", charge)
+		})
+	}
+}
+
+func TestCoupon_FixedDiscountNeverNegative(t *testing.T) {
+	amountOff := decimal.NewFromInt(50)
+	coupon := &domain.Coupon{
+		DiscountType: domain.DiscountTypeFixed,
+		AmountOff:    &amountOff,
+		Duration:     domain.CouponDurationForever,
+	}
+
+	assert.True(t, coupon.ApplyDiscount(decimal.NewFromInt(20)).IsZero())
+}
+
+func TestCoupon_IsRedeemable(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		isActive  bool
+		expiresAt *time.Time
+		want      bool
+	}{
+		{"active without expiry", true, nil, true},
+		{"active not yet expired", true, &future, true},
+		{"active but expired", true, &past, false},
+		{"inactive", false, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coupon := newTwentyPercentForThreeMonthsCoupon()
+			coupon.IsActive = tt.isActive
+			coupon.ExpiresAt = tt.expiresAt
+
+			assert.Equal(t, tt.want, coupon.IsRedeemable(now))
+		})
+	}
+}
+
+func TestValidateAmountChangeInterval(t *testing.T) {
+	lastChangedAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		lastChangedAt time.Time
+		minDays       int
+		now           time.Time
+		wantErr       bool
+	}{
+		{"second change within interval rejected", lastChangedAt, 30, lastChangedAt.AddDate(0, 0, 10), true},
+		{"change one second before interval ends rejected", lastChangedAt, 30, lastChangedAt.AddDate(0, 0, 30).Add(-time.Second), true},
+		{"change after interval allowed", lastChangedAt, 30, lastChangedAt.AddDate(0, 0, 31), false},
+		{"change exactly at interval allowed", lastChangedAt, 30, lastChangedAt.AddDate(0, 0, 30), false},
+		{"unlimited when interval is zero", lastChangedAt, 0, lastChangedAt.Add(time.Minute), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAmountChangeInterval(tt.lastChangedAt, tt.minDays, tt.now)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, domain.ErrAmountChangeTooSoon)
+				assert.Contains(t, err.Error(), "next change allowed after")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckAmountChangeAllowed_FirstChangeWaitsFromCreation(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeBillingStore(`{}`)
+	store.agent.SubscriptionAmountChangeMinDays = pgtype.Int4{Int32: 30, Valid: true}
+
+	sub := newDueSubscription(store, "20.00")
+	sub.CreatedAt = time.Now().AddDate(0, 0, -1)
+	err := checkAmountChangeAllowed(ctx, store, sub)
+	assert.ErrorIs(t, err, domain.ErrAmountChangeTooSoon, "a day after creation is within the interval")
+
+	sub.CreatedAt = time.Now().AddDate(0, 0, -31)
+	assert.NoError(t, checkAmountChangeAllowed(ctx, store, sub), "first change once the interval has passed")
+
+	sub.AmountChangedAt = pgtype.Timestamptz{Time: time.Now().AddDate(0, 0, -2), Valid: true}
+	err = checkAmountChangeAllowed(ctx, store, sub)
+	assert.ErrorIs(t, err, domain.ErrAmountChangeTooSoon, "later changes wait from the previous change")
+
+	store.agent.SubscriptionAmountChangeMinDays = pgtype.Int4{}
+	assert.NoError(t, checkAmountChangeAllowed(ctx, store, sub), "unlimited by default")
+}
+
+func newTestPaymentMethod(paymentType domain.PaymentMethodType, isDefault, isActive bool) sqlc.CustomerPaymentMethod {
+	pm := sqlc.CustomerPaymentMethod{
+		ID:          uuid.New(),
+		AgentID:     "test-agent-123",
+		CustomerID:  "cust-1",
+		PaymentType: string(paymentType),
+		IsDefault:   pgtype.Bool{Bool: isDefault, Valid: true},
+		IsActive:    pgtype.Bool{Bool: isActive, Valid: true},
+		CreatedAt:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if paymentType == domain.PaymentMethodTypeCreditCard {
+		pm.CardExpMonth = pgtype.Int4{Int32: 12, Valid: true}
+		pm.CardExpYear = pgtype.Int4{Int32: 2030, Valid: true}
+	}
+	return pm
+}
+
+func TestPlanDunning_ACHCardFallback(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	fallbackOn := domain.DefaultMerchantConfig(domain.MerchantTierPremium)
+	fallbackOn.ACHCardFallback = true
+	fallbackOff := domain.DefaultMerchantConfig(domain.MerchantTierPremium)
+
+	ach := newTestPaymentMethod(domain.PaymentMethodTypeACH, true, true)
+	card := newTestPaymentMethod(domain.PaymentMethodTypeCreditCard, false, true)
+	exhausted := &sqlc.Subscription{ID: uuid.New(), FailureRetryCount: 2, MaxRetries: 3}
+
+	t.Run("ACH failing subscription switches to the card on file", func(t *testing.T) {
+		action, selected := planDunning(exhausted, &ach, fallbackOn, []sqlc.CustomerPaymentMethod{ach, card}, now)
+		assert.Equal(t, dunningSwitchToCard, action)
+		require.NotNil(t, selected)
+		assert.Equal(t, card.ID, selected.ID)
+	})
+
+	t.Run("no card on file falls through to past_due", func(t *testing.T) {
+		action, selected := planDunning(exhausted, &ach, fallbackOn, []sqlc.CustomerPaymentMethod{ach}, now)
+		assert.Equal(t, dunningPastDue, action)
+		assert.Nil(t, selected)
+	})
+
+	t.Run("merchant without the policy goes past_due", func(t *testing.T) {
+		action, _ := planDunning(exhausted, &ach, fallbackOff, []sqlc.CustomerPaymentMethod{ach, card}, now)
+		assert.Equal(t, dunningPastDue, action)
+	})
+
+	t.Run("retries remaining keep retrying ACH", func(t *testing.T) {
+		sub := &sqlc.Subscription{ID: uuid.New(), FailureRetryCount: 0, MaxRetries: 3}
+		action, _ := planDunning(sub, &ach, fallbackOn, []sqlc.CustomerPaymentMethod{ach, card}, now)
+		assert.Equal(t, dunningRetry, action)
+	})
+
+	t.Run("ACH deactivated by a return switches immediately", func(t *testing.T) {
+		returned := newTestPaymentMethod(domain.PaymentMethodTypeACH, true, false)
+		returned.DeactivationReason = pgtype.Text{String: string(domain.DeactivationReasonAccountClosed), Valid: true}
+		sub := &sqlc.Subscription{ID: uuid.New(), FailureRetryCount: 0, MaxRetries: 3}
+
+		action, selected := planDunning(sub, &returned, fallbackOn, []sqlc.CustomerPaymentMethod{returned, card}, now)
+		assert.Equal(t, dunningSwitchToCard, action)
+		require.NotNil(t, selected)
+		assert.Equal(t, card.ID, selected.ID)
+	})
+
+	t.Run("failing card subscription never switches", func(t *testing.T) {
+		other := newTestPaymentMethod(domain.PaymentMethodTypeCreditCard, false, true)
+		action, _ := planDunning(exhausted, &card, fallbackOn, []sqlc.CustomerPaymentMethod{card, other}, now)
+		assert.Equal(t, dunningPastDue, action)
+	})
+}
+
+func TestSelectFallbackCard(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	expired := newTestPaymentMethod(domain.PaymentMethodTypeCreditCard, true, true)
+	expired.CardExpYear = pgtype.Int4{Int32: 2024, Valid: true}
+	inactive := newTestPaymentMethod(domain.PaymentMethodTypeCreditCard, false, false)
+	older := newTestPaymentMethod(domain.PaymentMethodTypeCreditCard, false, true)
+	newer := newTestPaymentMethod(domain.PaymentMethodTypeCreditCard, false, true)
+	newer.CreatedAt = older.CreatedAt.Add(24 * time.Hour)
+	defaultCard := newTestPaymentMethod(domain.PaymentMethodTypeCreditCard, true, true)
+
+	selected := selectFallbackCard([]sqlc.CustomerPaymentMethod{expired, inactive, older, newer}, now)
+	require.NotNil(t, selected)
+	assert.Equal(t, newer.ID, selected.ID, "newest usable card when the default is unusable")
+
+	selected = selectFallbackCard([]sqlc.CustomerPaymentMethod{older, defaultCard, newer}, now)
+	require.NotNil(t, selected)
+	assert.Equal(t, defaultCard.ID, selected.ID, "default card wins")
+
+	assert.Nil(t, selectFallbackCard([]sqlc.CustomerPaymentMethod{expired, inactive}, now))
+}
+
+// fakeServerPost returns a canned response or error for every charge
+type fakeServerPost struct {
+	adapterports.ServerPostAdapter
+	chargeResp *adapterports.ServerPostResponse
+	chargeErr  error
+	charges    []*adapterports.ServerPostRequest
+}
+
+func (f *fakeServerPost) ProcessTransaction(ctx context.Context, req *adapterports.ServerPostRequest) (*adapterports.ServerPostResponse, error) {
+	f.charges = append(f.charges, req)
+	if f.chargeResp != nil {
+		resp := *f.chargeResp
+		resp.TranGroup = req.TranGroup
+		return &resp, nil
+	}
+	return nil, f.chargeErr
+}
+
+// fakeReporting returns canned settled transactions; settledAs, when set, settles every charge sent to gateway
+type fakeReporting struct {
+	adapterports.MerchantReportingAdapter
+	gateway   *fakeServerPost
+	settledAs string
+	err       error
+	searches  []*adapterports.SettlementSearchRequest
+}
+
+func (f *fakeReporting) SearchSettlements(ctx context.Context, req *adapterports.SettlementSearchRequest) (*adapterports.SettlementSearchResponse, error) {
+	f.searches = append(f.searches, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := &adapterports.SettlementSearchResponse{
+		Transactions: []*adapterports.SettledTransaction{{AuthGUID: "other-guid", TransactionNumber: "999", Status: "settled"}},
+	}
+	if f.settledAs != "" {
+		for _, charge := range f.gateway.charges {
+			resp.Transactions = append(resp.Transactions, &adapterports.SettledTransaction{
+				AuthGUID: "settled-guid", TransactionNumber: charge.TranNbr, Status: f.settledAs,
+			})
+		}
+	}
+	return resp, nil
+}
+
+func newGatewayError(message string, category pkgerrors.ErrorCategory, cause error) error {
+	err := pkgerrors.NewPaymentError("EPX_GATEWAY", message, category, false)
+	err.Cause = cause
+	return err
+}
+
+func TestProcessSubscriptionBilling_UnknownOutcomeLookedUp(t *testing.T) {
+	timeout := newGatewayError("request timed out", pkgerrors.CategoryNetworkError, context.DeadlineExceeded)
+	readFailure := newGatewayError("failed to read response", pkgerrors.CategoryNetworkError, io.ErrUnexpectedEOF)
+	invalid := newGatewayError("invalid request", pkgerrors.CategoryInvalidRequest, errors.New("amount is required"))
+
+	tests := []struct {
+		name         string
+		chargeErr    error
+		noReporting  bool
+		settledAs    string
+		lookupErr    error
+		wantErr      bool
+		wantSearches int
+		wantAdvanced int
+		wantFailures int
+		wantRecorded string // Status of the recorded transaction; empty for none
+	}{
+		{name: "timed out charge that settled is approved", chargeErr: timeout, settledAs: "settled",
+			wantSearches: 1, wantAdvanced: 1, wantRecorded: string(domain.TransactionStatusCompleted)},
+		{name: "timed out charge rejected at settlement counts toward dunning", chargeErr: timeout, settledAs: "rejected",
+			wantSearches: 1, wantFailures: 1, wantRecorded: string(domain.TransactionStatusFailed)},
+		{name: "timed out charge North has no record of is left for the next run", chargeErr: timeout,
+			wantErr: true, wantSearches: 1},
+		{name: "lost response is unknown, not a failed charge", chargeErr: readFailure,
+			wantErr: true, wantSearches: 1},
+		{name: "lost response that settled is approved", chargeErr: readFailure, settledAs: "settled",
+			wantSearches: 1, wantAdvanced: 1, wantRecorded: string(domain.TransactionStatusCompleted)},
+		{name: "failed lookup is left for the next run", chargeErr: timeout, lookupErr: errors.New("connection refused"),
+			wantErr: true, wantSearches: 1},
+		{name: "without reporting an unknown outcome is left for the next run", chargeErr: timeout, noReporting: true,
+			wantErr: true},
+		{name: "charge refused before EPX counts without a lookup", chargeErr: invalid,
+			wantFailures: 1, wantRecorded: string(domain.TransactionStatusFailed)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeBillingStore(`{}`)
+			gateway := &fakeServerPost{chargeErr: tt.chargeErr}
+			reporting := &fakeReporting{gateway: gateway, settledAs: tt.settledAs, err: tt.lookupErr}
+			s := newBillingService(store, gateway)
+			if !tt.noReporting {
+				s.reporting = reporting
+			}
+			sub := newDueSubscription(store, "20.00")
+
+			_, err := s.processSubscriptionBilling(context.Background(), sub)
+			if tt.wantErr {
+				assert.Error(t, err)
+			}
+			require.Len(t, gateway.charges, 1)
+			require.Len(t, reporting.searches, tt.wantSearches)
+			for _, search := range reporting.searches {
+				assert.Equal(t, sub.AgentID, search.MerchantID)
+				assert.Equal(t, sub.NextBillingDate.Time, search.FromDate, "searched from the billing date")
+			}
+			assert.Equal(t, tt.wantAdvanced, store.advanced, "billing date advanced")
+			assert.Equal(t, tt.wantFailures, store.failedCount, "counted toward dunning")
+
+			if tt.wantRecorded == "" {
+				assert.Empty(t, store.charges)
+				return
+			}
+			require.Len(t, store.charges, 1)
+			assert.Equal(t, tt.wantRecorded, store.charges[0].Status)
+			if tt.wantSearches == 0 {
+				// Never sent: no EPX response, the refusal is the recorded reason
+				assert.Empty(t, store.charges[0].AuthGuid.String)
+				assert.Empty(t, store.charges[0].AuthResp.String)
+				assert.Contains(t, store.charges[0].AuthRespText.String, "invalid request")
+				return
+			}
+			assert.Equal(t, "settled-guid", store.charges[0].AuthGuid.String)
+		})
+	}
+}
+
+func newTestCharge(status domain.TransactionStatus, authResp, authRespText string, at time.Time) sqlc.Transaction {
+	return sqlc.Transaction{
+		ID:           uuid.New(),
+		AgentID:      "test-agent-123",
+		Amount:       pgtype.Numeric{Int: decimal.RequireFromString("49.99").Coefficient(), Exp: -2, Valid: true},
+		Currency:     "USD",
+		Status:       string(status),
+		Type:         string(domain.TransactionTypeCharge),
+		AuthResp:     pgtype.Text{String: authResp, Valid: true},
+		AuthRespText: pgtype.Text{String: authRespText, Valid: true},
+		CreatedAt:    at,
+	}
+}
+
+func TestBuildChargeHistory_FailedThenRetriedThenSucceeded(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	charges := []sqlc.Transaction{
+		newTestCharge(domain.TransactionStatusFailed, "51", "INSUFF FUNDS", start),
+		newTestCharge(domain.TransactionStatusFailed, "51", "INSUFF FUNDS", start.Add(24*time.Hour)),
+		newTestCharge(domain.TransactionStatusCompleted, "00", "APPROVAL", start.Add(48*time.Hour)),
+		newTestCharge(domain.TransactionStatusFailed, "05", "DO NOT HONOR", start.AddDate(0, 1, 0)),
+		newTestCharge(domain.TransactionStatusFailed, "", "invalid request: amount is required", start.AddDate(0, 1, 1)),
+	}
+
+	classify := func(authResp, authRespText string) *adapterports.ServerPostResponse {
+		if authResp == "00" {
+			return &adapterports.ServerPostResponse{AuthResp: authResp, IsApproved: true}
+		}
+		return &adapterports.ServerPostResponse{
+			AuthResp: authResp,
+			Decline:  pkgerrors.NewPaymentError("EPX_"+authResp, "declined: "+authRespText, pkgerrors.CategoryInsufficientFunds, true),
+		}
+	}
+
+	history := buildChargeHistory(charges, classify)
+	require.Len(t, history, 5)
+
+	wantStatus := []domain.TransactionStatus{
+		domain.TransactionStatusFailed, domain.TransactionStatusFailed,
+		domain.TransactionStatusCompleted, domain.TransactionStatusFailed, domain.TransactionStatusFailed,
+	}
+	wantRetry := []int{0, 1, 2, 0, 1}
+	for i, attempt := range history {
+		assert.Equal(t, charges[i].ID.String(), attempt.TransactionID, "attempt %d in chronological order", i)
+		assert.Equal(t, charges[i].CreatedAt, attempt.AttemptedAt)
+		assert.Equal(t, "49.99", attempt.Amount.StringFixed(2))
+		assert.Equal(t, wantStatus[i], attempt.Status)
+		assert.Equal(t, wantRetry[i], attempt.RetryNumber, "attempt %d retry number", i)
+	}
+
+	assert.Equal(t, "EPX_51", history[0].DeclineCode)
+	assert.Equal(t, "declined: INSUFF FUNDS", history[0].DeclineReason)
+	assert.Empty(t, history[2].DeclineCode, "approved attempt has no decline")
+	assert.Empty(t, history[2].DeclineReason)
+	assert.Equal(t, "EPX_05", history[3].DeclineCode, "next cycle's first attempt starts over at retry 0")
+	assert.Empty(t, history[4].DeclineCode, "a charge refused before EPX has no decline code")
+	assert.Equal(t, "invalid request: amount is required", history[4].DeclineReason)
+}
+
+func TestBillingChargeRequestID_OnePerAttempt(t *testing.T) {
+	pm := newTestPaymentMethod(domain.PaymentMethodTypeCreditCard, true, true)
+	sub := &sqlc.Subscription{
+		ID:              uuid.New(),
+		NextBillingDate: pgtype.Date{Time: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+	}
+	first := billingChargeRequestID(sub, &pm)
+
+	rerun := *sub
+	assert.Equal(t, first, billingChargeRequestID(&rerun, &pm), "an undecided charge retried next run keeps its TRAN_NBR")
+
+	retry := *sub
+	retry.FailureRetryCount++
+	assert.NotEqual(t, first, billingChargeRequestID(&retry, &pm), "a dunning retry after a decline is a new charge")
+
+	nextPeriod := *sub
+	nextPeriod.NextBillingDate.Time = nextPeriod.NextBillingDate.Time.AddDate(0, 1, 0)
+	assert.NotEqual(t, first, billingChargeRequestID(&nextPeriod, &pm))
+
+	card := newTestPaymentMethod(domain.PaymentMethodTypeCreditCard, false, true)
+	assert.NotEqual(t, first, billingChargeRequestID(sub, &card), "switching payment method is a new charge")
+}
+
+// fakeBillingStore serves one merchant and payment method to processSubscriptionBilling and records what it writes
+type fakeBillingStore struct {
+	sqlc.Querier
+	agent       sqlc.AgentCredential
+	pm          sqlc.CustomerPaymentMethod
+	flags       []sqlc.FeatureFlag
+	tranNbrs    map[uuid.UUID]int64
+	volume      decimal.Decimal
+	charges     []sqlc.CreateTransactionParams
+	advanced    int
+	failedCount int
+	lastStatus  string
+	coupons     map[uuid.UUID]sqlc.Coupon
+	created     []sqlc.CreateSubscriptionParams
+	due         []sqlc.Subscription // Unclaimed subscriptions due for billing
+	claims      []sqlc.ClaimSubscriptionsDueForBillingParams
+	released    []uuid.UUID
+
+	subscriptions map[uuid.UUID]sqlc.Subscription
+}
+
+func newFakeBillingStore(overrides string) *fakeBillingStore {
+	return &fakeBillingStore{
+		agent: sqlc.AgentCredential{
+			AgentID:         "test-agent-123",
+			MacSecretPath:   "agents/test-agent-123/mac",
+			CustNbr:         "9001",
+			Environment:     "sandbox",
+			IsActive:        pgtype.Bool{Bool: true, Valid: true},
+			Tier:            string(domain.MerchantTierStandard),
+			ConfigOverrides: []byte(overrides),
+			Status:          "active",
+		},
+		pm: sqlc.CustomerPaymentMethod{
+			ID:           uuid.New(),
+			AgentID:      "test-agent-123",
+			CustomerID:   "customer-1",
+			PaymentToken: "bric-1",
+			PaymentType:  string(domain.PaymentMethodTypeCreditCard),
+			IsActive:     pgtype.Bool{Bool: true, Valid: true},
+		},
+		tranNbrs: make(map[uuid.UUID]int64),
+	}
+}
+
+func (f *fakeBillingStore) Queries() sqlc.Querier { return f }
+
+func (f *fakeBillingStore) WithTx(ctx context.Context, fn func(sqlc.Querier) error) error {
+	return fn(f)
+}
+
+func (f *fakeBillingStore) GetAgentByAgentID(ctx context.Context, agentID string) (sqlc.AgentCredential, error) {
+	return f.agent, nil
+}
+
+func (f *fakeBillingStore) GetPaymentMethodByID(ctx context.Context, id uuid.UUID) (sqlc.CustomerPaymentMethod, error) {
+	return f.pm, nil
+}
+
+func (f *fakeBillingStore) GetFeatureFlagsForAgent(ctx context.Context, arg sqlc.GetFeatureFlagsForAgentParams) ([]sqlc.FeatureFlag, error) {
+	return f.flags, nil
+}
+
+func (f *fakeBillingStore) GetTranNbr(ctx context.Context, transactionID uuid.UUID) (sqlc.EpxTranNbr, error) {
+	nbr, ok := f.tranNbrs[transactionID]
+	if !ok {
+		return sqlc.EpxTranNbr{}, pgx.ErrNoRows
+	}
+	return sqlc.EpxTranNbr{TransactionID: transactionID, TranNbr: nbr}, nil
+}
+
+func (f *fakeBillingStore) NextTranNbr(ctx context.Context, agentID string) (int64, error) {
+	return int64(len(f.tranNbrs) + 1), nil
+}
+
+func (f *fakeBillingStore) AssignTranNbr(ctx context.Context, arg sqlc.AssignTranNbrParams) (sqlc.EpxTranNbr, error) {
+	f.tranNbrs[arg.TransactionID] = arg.TranNbr
+	return sqlc.EpxTranNbr{TransactionID: arg.TransactionID, AgentID: arg.AgentID, TranNbr: arg.TranNbr}, nil
+}
+
+func (f *fakeBillingStore) ReserveDailyVolume(ctx context.Context, arg sqlc.ReserveDailyVolumeParams) (pgtype.Numeric, error) {
+	next := f.volume.Add(decimal.NewFromBigInt(arg.Amount.Int, arg.Amount.Exp))
+	if next.GreaterThan(decimal.NewFromBigInt(arg.DailyLimit.Int, arg.DailyLimit.Exp)) {
+		return pgtype.Numeric{}, pgx.ErrNoRows
+	}
+	f.volume = next
+	return toNumeric(next), nil
+}
+
+func (f *fakeBillingStore) ReleaseDailyVolume(ctx context.Context, arg sqlc.ReleaseDailyVolumeParams) error {
+	f.volume = f.volume.Sub(decimal.NewFromBigInt(arg.Amount.Int, arg.Amount.Exp))
+	return nil
+}
+
+func (f *fakeBillingStore) CreateTransaction(ctx context.Context, arg sqlc.CreateTransactionParams) (sqlc.Transaction, error) {
+	f.charges = append(f.charges, arg)
+	return sqlc.Transaction{ID: arg.ID, Status: arg.Status}, nil
+}
+
+func (f *fakeBillingStore) UpdateSubscriptionBilling(ctx context.Context, arg sqlc.UpdateSubscriptionBillingParams) (sqlc.Subscription, error) {
+	f.advanced++
+	return sqlc.Subscription{ID: arg.ID}, nil
+}
+
+func (f *fakeBillingStore) IncrementSubscriptionFailureCount(ctx context.Context, arg sqlc.IncrementSubscriptionFailureCountParams) (sqlc.Subscription, error) {
+	f.failedCount++
+	f.lastStatus = arg.Status
+	return sqlc.Subscription{ID: arg.ID}, nil
+}
+
+func (f *fakeBillingStore) ClaimSubscriptionsDueForBilling(ctx context.Context, arg sqlc.ClaimSubscriptionsDueForBillingParams) ([]sqlc.Subscription, error) {
+	f.claims = append(f.claims, arg)
+	n := min(int(arg.LimitVal), len(f.due))
+	claimed := f.due[:n]
+	f.due = f.due[n:]
+	return claimed, nil
+}
+
+func (f *fakeBillingStore) ReleaseSubscriptionBillingClaim(ctx context.Context, id uuid.UUID) error {
+	f.released = append(f.released, id)
+	return nil
+}
+
+func (f *fakeBillingStore) GetCouponByID(ctx context.Context, id uuid.UUID) (sqlc.Coupon, error) {
+	coupon, ok := f.coupons[id]
+	if !ok {
+		return sqlc.Coupon{}, pgx.ErrNoRows
+	}
+	return coupon, nil
+}
+
+func (f *fakeBillingStore) CreateSubscription(ctx context.Context, arg sqlc.CreateSubscriptionParams) (sqlc.Subscription, error) {
+	f.created = append(f.created, arg)
+	return sqlc.Subscription{ID: arg.ID, AgentID: arg.AgentID, CustomerID: arg.CustomerID, Amount: arg.Amount, Status: arg.Status}, nil
+}
+
+func (f *fakeBillingStore) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (sqlc.Subscription, error) {
+	sub, ok := f.subscriptions[id]
+	if !ok {
+		return sqlc.Subscription{}, pgx.ErrNoRows
+	}
+	return sub, nil
+}
+
+// subscriptionTransactions selects what ListSubscriptionTransactions does: every recorded transaction in a group
+// one of the subscription's charges started, newest first. Transactions are recorded a minute apart.
+func (f *fakeBillingStore) subscriptionTransactions(subscriptionID string) []sqlc.Transaction {
+	groups := make(map[uuid.UUID]bool)
+	for _, charge := range f.charges {
+		var metadata struct {
+			SubscriptionID string `json:"subscription_id"`
+		}
+		if json.Unmarshal(charge.Metadata, &metadata) == nil && metadata.SubscriptionID == subscriptionID {
+			groups[charge.GroupID] = true
+		}
+	}
+
+	var txs []sqlc.Transaction
+	for i := len(f.charges) - 1; i >= 0; i-- {
+		charge := f.charges[i]
+		if !groups[charge.GroupID] {
+			continue
+		}
+		txs = append(txs, sqlc.Transaction{
+			ID:           charge.ID,
+			GroupID:      charge.GroupID,
+			AgentID:      charge.AgentID,
+			Amount:       charge.Amount,
+			Currency:     charge.Currency,
+			Status:       charge.Status,
+			Type:         charge.Type,
+			AuthResp:     charge.AuthResp,
+			AuthRespText: charge.AuthRespText,
+			Metadata:     charge.Metadata,
+			CreatedAt:    time.Date(2025, 1, 1, 0, i, 0, 0, time.UTC),
+		})
+	}
+	return txs
+}
+
+func (f *fakeBillingStore) ListSubscriptionTransactions(ctx context.Context, arg sqlc.ListSubscriptionTransactionsParams) ([]sqlc.Transaction, error) {
+	txs := f.subscriptionTransactions(arg.SubscriptionID)
+	start := min(int(arg.OffsetVal), len(txs))
+	end := min(start+int(arg.LimitVal), len(txs))
+	return txs[start:end], nil
+}
+
+func (f *fakeBillingStore) CountSubscriptionTransactions(ctx context.Context, subscriptionID string) (int64, error) {
+	return int64(len(f.subscriptionTransactions(subscriptionID))), nil
+}
+
+// fakeSecrets returns the same MAC for every path
+type fakeSecrets struct {
+	adapterports.SecretManagerAdapter
+}
+
+func (fakeSecrets) GetSecret(ctx context.Context, path string) (*adapterports.Secret, error) {
+	return &adapterports.Secret{Value: "mac"}, nil
+}
+
+func newBillingService(store *fakeBillingStore, gateway *fakeServerPost) *subscriptionService {
+	return &subscriptionService{
+		db:            store,
+		serverPost:    gateway,
+		epxEnv:        domain.EnvironmentSandbox,
+		secretManager: fakeSecrets{},
+		billing:       BillingConfig{}.withDefaults(),
+		logger:        zap.NewNop(),
+	}
+}
+
+func newDueSubscription(store *fakeBillingStore, amount string) *sqlc.Subscription {
+	return &sqlc.Subscription{
+		ID:              uuid.New(),
+		AgentID:         store.agent.AgentID,
+		CustomerID:      store.pm.CustomerID,
+		Amount:          toNumeric(decimal.RequireFromString(amount)),
+		Currency:        "USD",
+		IntervalValue:   1,
+		IntervalUnit:    string(domain.IntervalUnitMonth),
+		Status:          string(domain.SubscriptionStatusActive),
+		PaymentMethodID: store.pm.ID,
+		NextBillingDate: pgtype.Date{Time: time.Now(), Valid: true},
+		MaxRetries:      3,
+	}
+}
+
+func TestProcessSubscriptionBilling_DailyVolumeLimit(t *testing.T) {
+	approved := &adapterports.ServerPostResponse{AuthGUID: "guid", AuthResp: "00", IsApproved: true}
+	declined := &adapterports.ServerPostResponse{AuthGUID: "guid", AuthResp: "51", AuthRespText: "INSUFF FUNDS"}
+
+	tests := []struct {
+		name         string
+		amount       string
+		resp         *adapterports.ServerPostResponse
+		wantErr      error
+		wantCharged  bool
+		wantVolume   string
+		wantAdvanced int
+		wantFailures int
+	}{
+		{"approved charge keeps its volume", "20.00", approved, nil, true, "100.00", 1, 0},
+		{"declined charge releases its volume", "20.00", declined, nil, true, "80.00", 0, 1},
+		{"over the limit is refused before EPX and stays due", "30.00", approved, domain.ErrDailyVolumeLimitExceeded, false, "80.00", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeBillingStore(`{"daily_volume_limit": "100"}`)
+			store.volume = decimal.NewFromInt(80)
+			gateway := &fakeServerPost{chargeResp: tt.resp}
+			s := newBillingService(store, gateway)
+
+			_, err := s.processSubscriptionBilling(context.Background(), newDueSubscription(store, tt.amount))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantCharged, len(gateway.charges) == 1, "EPX called")
+			assert.Equal(t, tt.wantVolume, store.volume.StringFixed(2), "volume held for the day")
+			assert.Equal(t, tt.wantAdvanced, store.advanced, "billing date advanced")
+			assert.Equal(t, tt.wantFailures, store.failedCount, "counted toward dunning")
+		})
+	}
+}
+
+func TestProcessDueBilling_ClaimsAndReleasesSubscriptions(t *testing.T) {
+	store := newFakeBillingStore(`{"min_transaction_amount": "5.00"}`)
+	for _, amount := range []string{"20.00", "4.00", "20.00"} {
+		store.due = append(store.due, *newDueSubscription(store, amount))
+	}
+	due := slices.Clone(store.due)
+	gateway := &fakeServerPost{chargeResp: &adapterports.ServerPostResponse{AuthGUID: "guid", AuthResp: "00", IsApproved: true}}
+	s := newBillingService(store, gateway)
+	s.billing.Concurrency = 1
+	s.billing.MaxPerRun = 2
+
+	before := time.Now()
+	first := s.ProcessDueBilling(context.Background(), time.Now(), 100)
+	second := s.ProcessDueBilling(context.Background(), time.Now(), 100)
+
+	require.Len(t, store.claims, 2)
+	assert.Equal(t, int32(2), store.claims[0].LimitVal, "batch size capped at MaxPerRun")
+	assert.WithinDuration(t, before.Add(-billingClaimStaleAfter), store.claims[0].StaleBefore, time.Second)
+	assert.Equal(t, 2, first.Processed)
+	assert.Equal(t, 1, second.Processed, "the second run only gets what the first didn't claim")
+	assert.Len(t, gateway.charges, 2, "each in-range subscription charged once")
+
+	// Every claim is released, including the subscription that failed before reaching EPX
+	released := make([]uuid.UUID, 0, len(due))
+	for _, sub := range due {
+		released = append(released, sub.ID)
+	}
+	assert.ElementsMatch(t, released, store.released)
+}
+
+func TestProcessDueBilling_ReportsBillingOutcomes(t *testing.T) {
+	store := newFakeBillingStore(`{"min_transaction_amount": "5.00"}`)
+	charged := newDueSubscription(store, "20.00")
+	retried := newDueSubscription(store, "15.00")
+	retried.FailureRetryCount = 1
+	outOfRange := newDueSubscription(store, "4.00")
+	store.due = []sqlc.Subscription{*charged, *retried, *outOfRange}
+	gateway := &fakeServerPost{chargeResp: &adapterports.ServerPostResponse{AuthGUID: "guid", AuthResp: "00", IsApproved: true}}
+	s := newBillingService(store, gateway)
+	s.billing.Concurrency = 1
+
+	result := s.ProcessDueBilling(context.Background(), time.Now(), 100)
+
+	assert.Equal(t, 3, result.Processed)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 1, result.Retried, "charged again after an earlier failed attempt")
+	assert.Equal(t, "35.00", result.AmountCharged.StringFixed(2), "only approved charges count")
+	require.Len(t, result.Errors, 1)
+	assert.ErrorIs(t, result.Errors[0], domain.ErrAmountOutOfRange)
+	assert.Contains(t, result.Errors[0].Error(), outOfRange.ID.String())
+}
+
+func TestProcessSubscriptionBilling_ChargesDisabled(t *testing.T) {
+	store := newFakeBillingStore(`{}`)
+	store.flags = []sqlc.FeatureFlag{{Name: domain.FeatureFlagChargesEnabled, Enabled: false}}
+	gateway := &fakeServerPost{chargeResp: &adapterports.ServerPostResponse{AuthResp: "00", IsApproved: true}}
+	s := newBillingService(store, gateway)
+
+	_, err := s.processSubscriptionBilling(context.Background(), newDueSubscription(store, "20.00"))
+	assert.ErrorIs(t, err, domain.ErrChargesDisabled)
+	assert.Empty(t, gateway.charges, "EPX not called")
+	assert.Zero(t, store.advanced, "subscription stays due")
+	assert.Zero(t, store.failedCount, "not counted toward dunning")
+	assert.True(t, store.volume.IsZero(), "no daily volume held")
+}
+
+func TestProcessSubscriptionBilling_ProductionMerchantNeverReachesSandbox(t *testing.T) {
+	store := newFakeBillingStore(`{}`)
+	store.agent.Environment = string(domain.EnvironmentProduction)
+	gateway := &fakeServerPost{chargeResp: &adapterports.ServerPostResponse{AuthResp: "00", IsApproved: true}}
+	s := newBillingService(store, gateway)
+
+	_, err := s.processSubscriptionBilling(context.Background(), newDueSubscription(store, "20.00"))
+	assert.ErrorIs(t, err, domain.ErrEnvironmentMismatch)
+	assert.Empty(t, gateway.charges, "EPX not called")
+	assert.Zero(t, store.failedCount, "not counted toward dunning")
+}
+
+func TestProcessSubscriptionBilling_AmountOutOfRangeMarksPastDueOnce(t *testing.T) {
+	store := newFakeBillingStore(`{"min_transaction_amount": "5.00"}`)
+	gateway := &fakeServerPost{chargeResp: &adapterports.ServerPostResponse{AuthResp: "00", IsApproved: true}}
+	s := newBillingService(store, gateway)
+
+	_, err := s.processSubscriptionBilling(context.Background(), newDueSubscription(store, "4.00"))
+	assert.ErrorIs(t, err, domain.ErrAmountOutOfRange)
+	assert.Empty(t, gateway.charges, "EPX not called")
+	assert.Zero(t, store.advanced)
+	assert.Equal(t, 1, store.failedCount)
+	assert.Equal(t, string(domain.SubscriptionStatusPastDue), store.lastStatus, "no longer due on every run")
+}
+
+func TestCreateSubscription_ChargesMustBeWithinAmountRange(t *testing.T) {
+	fixedOff := func(off string) sqlc.Coupon {
+		return sqlc.Coupon{
+			ID:           uuid.New(),
+			AgentID:      "test-agent-123",
+			DiscountType: string(domain.DiscountTypeFixed),
+			AmountOff:    toNumeric(decimal.RequireFromString(off)),
+			Currency:     "USD",
+			Duration:     string(domain.CouponDurationForever),
+			IsActive:     true,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		amount    string
+		couponOff string // Empty for no coupon
+		wantErr   bool
+	}{
+		{"within range", "10.00", "", false},
+		{"below the minimum", "4.00", "", true},
+		{"coupon leaves a charge within range", "10.00", "4.00", false},
+		{"coupon leaves a charge below the minimum", "10.00", "7.00", true},
+		{"coupon makes the charge free", "10.00", "10.00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeBillingStore(`{"min_transaction_amount": "5.00"}`)
+			store.coupons = map[uuid.UUID]sqlc.Coupon{}
+			req := &ports.CreateSubscriptionRequest{
+				AgentID:         "test-agent-123",
+				CustomerID:      "customer-1",
+				Amount:          tt.amount,
+				Currency:        "USD",
+				IntervalValue:   1,
+				IntervalUnit:    domain.IntervalUnitMonth,
+				PaymentMethodID: store.pm.ID.String(),
+				StartDate:       time.Now(),
+				MaxRetries:      3,
+			}
+			if tt.couponOff != "" {
+				coupon := fixedOff(tt.couponOff)
+				store.coupons[coupon.ID] = coupon
+				couponID := coupon.ID.String()
+				req.CouponID = &couponID
+			}
+
+			_, err := newBillingService(store, &fakeServerPost{}).CreateSubscription(context.Background(), req)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrAmountOutOfRange)
+				assert.Empty(t, store.created, "no subscription that could never be billed")
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, store.created, 1)
+		})
+	}
+}
+
+func TestListSubscriptionTransactions_ThreeBillingCycles(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeBillingStore(`{}`)
+	gateway := &fakeServerPost{}
+	s := newBillingService(store, gateway)
+	sub := newDueSubscription(store, "29.99")
+	other := newDueSubscription(store, "10.00")
+	store.subscriptions = map[uuid.UUID]sqlc.Subscription{sub.ID: *sub, other.ID: *other}
+
+	approved := &adapterports.ServerPostResponse{AuthGUID: "guid", AuthResp: "00", AuthRespText: "APPROVAL", IsApproved: true}
+	declined := &adapterports.ServerPostResponse{AuthGUID: "guid", AuthResp: "51", AuthRespText: "INSUFF FUNDS"}
+
+	// Three billing cycles of sub, the second declined, and one of another subscription
+	for _, resp := range []*adapterports.ServerPostResponse{approved, declined, approved} {
+		gateway.chargeResp = resp
+		_, _ = s.processSubscriptionBilling(ctx, sub)
+	}
+	gateway.chargeResp = approved
+	_, err := s.processSubscriptionBilling(ctx, other)
+	require.NoError(t, err)
+	require.Len(t, store.charges, 4)
+
+	txs, total, err := s.ListSubscriptionTransactions(ctx, sub.ID.String(), 100, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, txs, 3, "one transaction per billing cycle, none of the other subscription's")
+
+	wantStatus := []domain.TransactionStatus{
+		domain.TransactionStatusCompleted, domain.TransactionStatusFailed, domain.TransactionStatusCompleted,
+	}
+	for i, tx := range txs {
+		assert.Equal(t, wantStatus[i], tx.Status, "cycle %d, newest first", 3-i)
+		assert.Equal(t, "29.99", tx.Amount.StringFixed(2))
+	}
+	require.NotNil(t, txs[1].AuthRespText)
+	assert.Equal(t, "INSUFF FUNDS", *txs[1].AuthRespText, "the declined cycle keeps its failure reason")
+
+	page, total, err := s.ListSubscriptionTransactions(ctx, sub.ID.String(), 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total, "the total counts every page")
+	require.Len(t, page, 1)
+	assert.Equal(t, txs[2].ID, page[0].ID, "the oldest cycle is on the last page")
+
+	_, _, err = s.ListSubscriptionTransactions(ctx, uuid.New().String(), 100, 0)
+	assert.ErrorIs(t, err, domain.ErrSubscriptionNotFound)
+}
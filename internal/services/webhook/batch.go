@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/pkg/observability"
+)
+
+// EventTypeBatch is the event type of a batched delivery, whose payload is a JSON array of events
+const EventTypeBatch = "webhook.batch"
+
+// queueBatchEvent stores the event for a batching subscription and sends the batch once it is full.
+// Queued events live in webhook_batch_events, so they survive a restart and any instance can send them.
+// Each event's flush time runs from when it was queued; a batch is due once its oldest event's has passed.
+func (s *WebhookDeliveryService) queueBatchEvent(ctx context.Context, subscription sqlc.WebhookSubscription, event *WebhookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event payload: %w", err)
+	}
+
+	interval := time.Duration(subscription.BatchFlushIntervalSeconds) * time.Second
+	err = s.queries.EnqueueWebhookBatchEvent(ctx, sqlc.EnqueueWebhookBatchEventParams{
+		SubscriptionID: subscription.ID,
+		EventType:      event.EventType,
+		Payload:        payload,
+		FlushAt:        s.now().Add(interval),
+	})
+	if err != nil {
+		return fmt.Errorf("queue batch event: %w", err)
+	}
+
+	queued, err := s.queries.CountWebhookBatchEvents(ctx, subscription.ID)
+	if err != nil {
+		return fmt.Errorf("count queued batch events: %w", err)
+	}
+	if queued < int64(subscription.BatchSize) {
+		return nil
+	}
+
+	_, err = s.sendBatch(ctx, subscription)
+	return err
+}
+
+// FlushDueBatches sends every batch whose flush interval has elapsed. Returns the number of batches sent.
+func (s *WebhookDeliveryService) FlushDueBatches(ctx context.Context) int {
+	due, err := s.queries.ListDueWebhookBatches(ctx, pgtype.Timestamptz{Time: s.now(), Valid: true})
+	if err != nil {
+		s.logger.Error("Failed to list due webhook batches", zap.Error(err))
+		return 0
+	}
+
+	sent := 0
+	for _, subscriptionID := range due {
+		n, err := s.flushSubscription(ctx, subscriptionID)
+		sent += n
+		if err != nil {
+			s.logger.Error("Failed to deliver webhook batch",
+				zap.Error(err),
+				zap.String("subscription_id", subscriptionID.String()),
+			)
+		}
+	}
+	return sent
+}
+
+// flushSubscription sends the subscription's queued events, a batch at a time, and returns the batches sent.
+// Events of a subscription that has since been deactivated are dropped.
+func (s *WebhookDeliveryService) flushSubscription(ctx context.Context, subscriptionID uuid.UUID) (int, error) {
+	subscription, err := s.queries.GetWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		return 0, fmt.Errorf("get webhook subscription: %w", err)
+	}
+
+	sent := 0
+	for {
+		n, err := s.sendBatch(ctx, subscription)
+		if err != nil {
+			return sent, err
+		}
+		if n == 0 {
+			return sent, nil
+		}
+		if subscription.IsActive {
+			sent++
+		}
+		if n < int(subscription.BatchSize) {
+			return sent, nil
+		}
+	}
+}
+
+// RunBatchFlusher flushes due batches every tick until ctx is cancelled. Events still queued at shutdown stay
+// in the database and are sent by the next flush on any instance.
+func (s *WebhookDeliveryService) RunBatchFlusher(ctx context.Context, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.FlushDueBatches(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sendBatch takes up to a batch of the subscription's queued events off the queue and delivers them as one POST.
+// Returns how many events were taken.
+func (s *WebhookDeliveryService) sendBatch(ctx context.Context, subscription sqlc.WebhookSubscription) (int, error) {
+	queued, err := s.queries.ClaimWebhookBatchEvents(ctx, sqlc.ClaimWebhookBatchEventsParams{
+		SubscriptionID: subscription.ID,
+		LimitVal:       subscription.BatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claim batch events: %w", err)
+	}
+	if len(queued) == 0 {
+		return 0, nil
+	}
+	if !subscription.IsActive {
+		s.logger.Warn("Dropped queued webhook events of an inactive subscription",
+			zap.String("subscription_id", subscription.ID.String()),
+			zap.Int("events", len(queued)),
+		)
+		return len(queued), nil
+	}
+
+	sort.Slice(queued, func(i, j int) bool { return queued[i].ID < queued[j].ID })
+	return len(queued), s.deliverBatch(ctx, subscription, queued)
+}
+
+// deliverBatch POSTs the events as one JSON array signed as a whole, and records it as a single delivery
+// so a failed batch is retried, dead-lettered and redelivered like any other delivery
+func (s *WebhookDeliveryService) deliverBatch(ctx context.Context, subscription sqlc.WebhookSubscription, events []sqlc.WebhookBatchEvent) error {
+	payloads := make([]json.RawMessage, len(events))
+	for i, event := range events {
+		payloads[i] = event.Payload
+	}
+	payload, err := json.Marshal(payloads)
+	if err != nil {
+		return fmt.Errorf("marshal batch payload: %w", err)
+	}
+
+	attempt, err := s.send(ctx, subscription, EventTypeBatch, payload)
+	for _, event := range events {
+		observability.RecordWebhookDelivery(event.EventType, err == nil)
+	}
+	if err != nil {
+		return s.recordDeliveryFailure(ctx, subscription.ID, EventTypeBatch, payload, attempt, err)
+	}
+
+	return s.recordDeliverySuccess(ctx, subscription.ID, EventTypeBatch, payload, attempt)
+}
@@ -0,0 +1,252 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+)
+
+func (f *fakeQueries) EnqueueWebhookBatchEvent(ctx context.Context, arg sqlc.EnqueueWebhookBatchEventParams) error {
+	f.batchEvents = append(f.batchEvents, sqlc.WebhookBatchEvent{
+		ID:             int64(len(f.batchEvents) + 1),
+		SubscriptionID: arg.SubscriptionID,
+		EventType:      arg.EventType,
+		Payload:        arg.Payload,
+		FlushAt:        arg.FlushAt,
+	})
+	return nil
+}
+
+func (f *fakeQueries) CountWebhookBatchEvents(ctx context.Context, subscriptionID uuid.UUID) (int64, error) {
+	var count int64
+	for _, event := range f.batchEvents {
+		if event.SubscriptionID == subscriptionID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeQueries) ClaimWebhookBatchEvents(ctx context.Context, arg sqlc.ClaimWebhookBatchEventsParams) ([]sqlc.WebhookBatchEvent, error) {
+	var claimed, kept []sqlc.WebhookBatchEvent
+	for _, event := range f.batchEvents {
+		if event.SubscriptionID == arg.SubscriptionID && len(claimed) < int(arg.LimitVal) {
+			claimed = append(claimed, event)
+			continue
+		}
+		kept = append(kept, event)
+	}
+	f.batchEvents = kept
+	return claimed, nil
+}
+
+func (f *fakeQueries) ListDueWebhookBatches(ctx context.Context, now pgtype.Timestamptz) ([]uuid.UUID, error) {
+	var due []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, event := range f.batchEvents {
+		// Queue order is flush order, so a subscription's first event has its earliest flush time
+		if seen[event.SubscriptionID] {
+			continue
+		}
+		seen[event.SubscriptionID] = true
+		if !now.Time.Before(event.FlushAt) {
+			due = append(due, event.SubscriptionID)
+		}
+	}
+	return due, nil
+}
+
+// receivedPost is one request seen by the recording server
+type receivedPost struct {
+	eventType string
+	signature string
+	body      []byte
+}
+
+// newRecordingServer accepts every POST and records it
+func newRecordingServer(posts *[]receivedPost, mu *sync.Mutex) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		*posts = append(*posts, receivedPost{
+			eventType: r.Header.Get("X-Webhook-Event-Type"),
+			signature: r.Header.Get(SignatureHeader),
+			body:      body,
+		})
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func newNumberedEvent(n int) *WebhookEvent {
+	event := newTestEvent()
+	event.Data = map[string]interface{}{"n": float64(n)}
+	return event
+}
+
+// batchNumbers verifies a batched POST's aggregate signature and returns the events' numbers in order
+func batchNumbers(t *testing.T, post receivedPost) []float64 {
+	t.Helper()
+
+	assert.Equal(t, EventTypeBatch, post.eventType)
+	require.NoError(t, VerifySignature(post.signature, testSecret, post.body, DefaultSignatureTolerance, time.Now()),
+		"the batch is signed as a whole")
+
+	var events []WebhookEvent
+	require.NoError(t, json.Unmarshal(post.body, &events), "batch payload is a JSON array of events")
+	numbers := make([]float64, len(events))
+	for i, event := range events {
+		numbers[i] = event.Data["n"].(float64)
+	}
+	return numbers
+}
+
+func TestDeliverEvent_BatchesUpToBatchSize(t *testing.T) {
+	var posts []receivedPost
+	var mu sync.Mutex
+	server := newRecordingServer(&posts, &mu)
+	defer server.Close()
+
+	subscription := newTestSubscription(server.URL)
+	subscription.BatchSize = 3
+	subscription.BatchFlushIntervalSeconds = 10
+	queries := newFakeQueries(subscription)
+	svc := NewWebhookDeliveryServiceWithQueries(queries, server.Client(), DefaultRetryPolicy(), zap.NewNop())
+
+	for n := 1; n <= 7; n++ {
+		require.NoError(t, svc.DeliverEvent(context.Background(), newNumberedEvent(n)))
+	}
+
+	require.Len(t, posts, 2, "two full batches sent, the seventh event waits")
+	assert.Equal(t, []float64{1, 2, 3}, batchNumbers(t, posts[0]))
+	assert.Equal(t, []float64{4, 5, 6}, batchNumbers(t, posts[1]))
+
+	assert.Len(t, queries.deliveries, 2, "each batch is recorded as one delivery")
+	for _, delivery := range queries.deliveries {
+		assert.Equal(t, EventTypeBatch, delivery.EventType)
+		assert.Equal(t, DeliveryStatusSuccess, delivery.Status)
+	}
+}
+
+func TestFlushDueBatches_FlushesOnInterval(t *testing.T) {
+	var posts []receivedPost
+	var mu sync.Mutex
+	server := newRecordingServer(&posts, &mu)
+	defer server.Close()
+
+	subscription := newTestSubscription(server.URL)
+	subscription.BatchSize = 100
+	subscription.BatchFlushIntervalSeconds = 10
+	svc := NewWebhookDeliveryServiceWithQueries(newFakeQueries(subscription), server.Client(), DefaultRetryPolicy(), zap.NewNop())
+
+	start := time.Now()
+	svc.now = func() time.Time { return start }
+	require.NoError(t, svc.DeliverEvent(context.Background(), newNumberedEvent(1)))
+	svc.now = func() time.Time { return start.Add(4 * time.Second) }
+	require.NoError(t, svc.DeliverEvent(context.Background(), newNumberedEvent(2)))
+
+	svc.now = func() time.Time { return start.Add(9 * time.Second) }
+	assert.Equal(t, 0, svc.FlushDueBatches(context.Background()), "interval runs from the batch's first event")
+	assert.Empty(t, posts)
+
+	svc.now = func() time.Time { return start.Add(10 * time.Second) }
+	assert.Equal(t, 1, svc.FlushDueBatches(context.Background()))
+	require.Len(t, posts, 1)
+	assert.Equal(t, []float64{1, 2}, batchNumbers(t, posts[0]))
+
+	assert.Equal(t, 0, svc.FlushDueBatches(context.Background()), "a flushed batch is not sent again")
+}
+
+func TestFlushDueBatches_QueuedEventsSurviveRestart(t *testing.T) {
+	var posts []receivedPost
+	var mu sync.Mutex
+	server := newRecordingServer(&posts, &mu)
+	defer server.Close()
+
+	subscription := newTestSubscription(server.URL)
+	subscription.BatchSize = 2
+	subscription.BatchFlushIntervalSeconds = 60
+	queries := newFakeQueries(subscription)
+	svc := NewWebhookDeliveryServiceWithQueries(queries, server.Client(), DefaultRetryPolicy(), zap.NewNop())
+	start := time.Now()
+	svc.now = func() time.Time { return start }
+
+	for n := 1; n <= 3; n++ {
+		require.NoError(t, svc.DeliverEvent(context.Background(), newNumberedEvent(n)))
+	}
+	require.Len(t, posts, 1)
+
+	// The instance stops with the third event queued
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc.RunBatchFlusher(ctx, time.Hour)
+	require.Len(t, posts, 1, "nothing is sent early on shutdown")
+
+	// A fresh instance on the same database sends it once its flush time passes
+	restarted := NewWebhookDeliveryServiceWithQueries(queries, server.Client(), DefaultRetryPolicy(), zap.NewNop())
+	restarted.now = func() time.Time { return start.Add(59 * time.Second) }
+	assert.Equal(t, 0, restarted.FlushDueBatches(context.Background()))
+	restarted.now = func() time.Time { return start.Add(time.Minute) }
+	assert.Equal(t, 1, restarted.FlushDueBatches(context.Background()))
+	require.Len(t, posts, 2)
+	assert.Equal(t, []float64{3}, batchNumbers(t, posts[1]))
+	assert.Empty(t, queries.batchEvents)
+}
+
+func TestFlushDueBatches_DropsEventsOfDeactivatedSubscription(t *testing.T) {
+	var posts []receivedPost
+	var mu sync.Mutex
+	server := newRecordingServer(&posts, &mu)
+	defer server.Close()
+
+	subscription := newTestSubscription(server.URL)
+	subscription.BatchSize = 10
+	subscription.BatchFlushIntervalSeconds = 1
+	queries := newFakeQueries(subscription)
+	svc := NewWebhookDeliveryServiceWithQueries(queries, server.Client(), DefaultRetryPolicy(), zap.NewNop())
+	start := time.Now()
+	svc.now = func() time.Time { return start }
+	require.NoError(t, svc.DeliverEvent(context.Background(), newNumberedEvent(1)))
+
+	subscription.IsActive = false
+	queries.subscriptions[subscription.ID] = subscription
+	svc.now = func() time.Time { return start.Add(time.Second) }
+	assert.Equal(t, 0, svc.FlushDueBatches(context.Background()))
+	assert.Empty(t, posts)
+	assert.Empty(t, queries.batchEvents)
+}
+
+func TestDeliverEvent_DefaultSendsOnePostPerEvent(t *testing.T) {
+	var posts []receivedPost
+	var mu sync.Mutex
+	server := newRecordingServer(&posts, &mu)
+	defer server.Close()
+
+	subscription := newTestSubscription(server.URL)
+	subscription.BatchSize = 1
+	svc := NewWebhookDeliveryServiceWithQueries(newFakeQueries(subscription), server.Client(), DefaultRetryPolicy(), zap.NewNop())
+
+	for n := 1; n <= 3; n++ {
+		require.NoError(t, svc.DeliverEvent(context.Background(), newNumberedEvent(n)))
+	}
+
+	require.Len(t, posts, 3)
+	for _, post := range posts {
+		assert.Equal(t, "payment.success", post.eventType)
+		var event WebhookEvent
+		require.NoError(t, json.Unmarshal(post.body, &event), "unbatched payload is a single event object")
+	}
+}
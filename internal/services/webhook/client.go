@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// NewDeliveryClient returns the HTTP client webhooks are delivered with. Every connection is checked against
+// domain.CheckWebhookIP after the hostname resolves, so an endpoint whose DNS is re-pointed at a private address
+// after it was saved is never dialed. Redirects aren't followed; the 3xx is the delivery's (failed) response.
+func NewDeliveryClient(transport *http.Transport, timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   publicAddressOnly,
+	}
+	transport.DialContext = dialer.DialContext
+	// Through a proxy the checked address would be the proxy's, not the merchant's endpoint
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicAddressOnly is a net.Dialer Control hook that refuses to connect to non-public addresses
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidWebhookURL, err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: dial address %q is not an IP", domain.ErrInvalidWebhookURL, host)
+	}
+	return domain.CheckWebhookIP(ip)
+}
@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+func TestDeliveryClient_RefusesNonPublicAddresses(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+	client := NewDeliveryClient(&http.Transport{}, time.Second)
+
+	// By IP, and by a hostname that only resolves to loopback at delivery time
+	for _, url := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		_, err := client.Post(url, "application/json", strings.NewReader(`{}`))
+		assert.ErrorIs(t, err, domain.ErrInvalidWebhookURL, url)
+	}
+	assert.Zero(t, hits.Load(), "no delivery reached the endpoint")
+}
+
+func TestDeliveryClient_DoesNotFollowRedirects(t *testing.T) {
+	var redirected atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hook", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/internal", func(w http.ResponseWriter, r *http.Request) {
+		redirected.Add(1)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewDeliveryClient(&http.Transport{}, time.Second)
+	// The test server is on loopback, so let this client dial it
+	client.Transport.(*http.Transport).DialContext = (&net.Dialer{}).DialContext
+
+	resp, err := client.Post(server.URL+"/hook", "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+	assert.Zero(t, redirected.Load())
+}
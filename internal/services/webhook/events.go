@@ -0,0 +1,71 @@
+package webhook
+
+// Payment event types delivered to merchant webhook subscriptions
+const (
+	EventPaymentAuthorized             = "payment.authorized"
+	EventPaymentAuthorizationReduced   = "payment.authorization_reduced"   // Part of an auth hold was released
+	EventPaymentAuthorizationIncreased = "payment.authorization_increased" // An auth hold was incremented
+	EventPaymentCaptured               = "payment.captured"
+	EventPaymentRefunded               = "payment.refunded"
+	EventPaymentVoided                 = "payment.voided"
+	EventPaymentFailed                 = "payment.failed"
+)
+
+// Payment method event types
+const (
+	EventPaymentMethodExpiring = "payment_method.expiring" // A saved card expires soon
+)
+
+// Subscription event types
+const (
+	EventSubscriptionPaymentMethodSwitched = "subscription.payment_method_switched" // Dunning moved a subscription to another payment method
+)
+
+// Chargeback event types
+const (
+	EventChargebackCreated             = "chargeback.created"
+	EventChargebackUpdated             = "chargeback.updated"
+	EventChargebackDeadlineApproaching = "chargeback.deadline_approaching" // An unanswered chargeback's response deadline is near
+)
+
+// EventTypeInfo describes an event type merchants can subscribe to
+type EventTypeInfo struct {
+	Name        string
+	Description string
+}
+
+// eventCatalog is the canonical list of subscribable event types, in the order ListEventTypes returns them.
+// Every event the service delivers must be listed here or merchants can't subscribe to it.
+var eventCatalog = []EventTypeInfo{
+	{EventPaymentAuthorized, "A card authorization was approved"},
+	{EventPaymentAuthorizationReduced, "Part of an authorization hold was released"},
+	{EventPaymentAuthorizationIncreased, "An authorization hold was incremented"},
+	{EventPaymentCaptured, "An authorization was captured or a sale was approved"},
+	{EventPaymentRefunded, "A payment was refunded"},
+	{EventPaymentVoided, "A payment was voided"},
+	{EventPaymentFailed, "A payment was declined or failed"},
+	{EventPaymentMethodExpiring, "A saved card expires soon"},
+	{EventSubscriptionPaymentMethodSwitched, "Dunning moved a subscription to another payment method"},
+	{EventChargebackCreated, "A new chargeback was received"},
+	{EventChargebackUpdated, "A chargeback's status or details changed"},
+	{EventChargebackDeadlineApproaching, "An unanswered chargeback's response deadline is near"},
+}
+
+// knownEventTypes indexes eventCatalog by name
+var knownEventTypes = func() map[string]bool {
+	known := make(map[string]bool, len(eventCatalog))
+	for _, eventType := range eventCatalog {
+		known[eventType.Name] = true
+	}
+	return known
+}()
+
+// EventTypes returns the subscribable event types
+func EventTypes() []EventTypeInfo {
+	return append([]EventTypeInfo(nil), eventCatalog...)
+}
+
+// IsKnownEventType reports whether eventType is one the service delivers
+func IsKnownEventType(eventType string) bool {
+	return knownEventTypes[eventType]
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+func TestEventTypes_Catalog(t *testing.T) {
+	eventTypes := EventTypes()
+	assert.NotEmpty(t, eventTypes)
+
+	name := regexp.MustCompile(`^[a-z_]+\.[a-z_]+$`)
+	seen := make(map[string]bool)
+	for _, eventType := range eventTypes {
+		assert.Regexp(t, name, eventType.Name)
+		assert.NotEmpty(t, eventType.Description, eventType.Name)
+		assert.False(t, seen[eventType.Name], "%s listed twice", eventType.Name)
+		seen[eventType.Name] = true
+	}
+
+	assert.False(t, IsKnownEventType(EventTypeBatch), "batches aren't subscribable")
+
+	eventTypes[0].Name = "changed"
+	assert.Equal(t, EventPaymentAuthorized, EventTypes()[0].Name, "callers get a copy")
+}
+
+func TestCheckEventType(t *testing.T) {
+	valid := []string{
+		EventPaymentAuthorized,
+		EventPaymentAuthorizationReduced,
+		EventPaymentAuthorizationIncreased,
+		EventPaymentCaptured,
+		EventPaymentRefunded,
+		EventPaymentVoided,
+		EventPaymentFailed,
+		EventPaymentMethodExpiring,
+		EventSubscriptionPaymentMethodSwitched,
+		EventChargebackCreated,
+		EventChargebackUpdated,
+		EventChargebackDeadlineApproaching,
+	}
+	for _, eventType := range valid {
+		assert.NoError(t, checkEventType(eventType), eventType)
+	}
+
+	invalid := []string{
+		"",
+		"payment.capture",
+		"Payment.Captured",
+		" payment.captured",
+		"chargeback.opened",
+		"subscription.created",
+		"webhook.batch",
+		"payment.*",
+	}
+	for _, eventType := range invalid {
+		err := checkEventType(eventType)
+		assert.ErrorIs(t, err, domain.ErrUnknownWebhookEventType, eventType)
+	}
+
+	err := checkEventType("payment.capture")
+	assert.EqualError(t, err, `unknown webhook event type "payment.capture"; ListEventTypes returns the supported types`)
+}
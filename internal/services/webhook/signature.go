@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the versioned webhook signature: "t=<unix seconds>,v1=<hex hmac>"
+//
+// Receivers verify a delivery by:
+//  1. Parsing t and v1 from the header
+//  2. Computing HMAC-SHA256 over "<t>.<raw request body>" with the subscription's secret
+//  3. Comparing against v1 in constant time
+//  4. Rejecting deliveries whose t is older than their tolerance (e.g. 5 minutes) to prevent replays
+const SignatureHeader = "X-Payment-Signature"
+
+// DefaultSignatureTolerance is the recommended maximum age of a signed delivery
+const DefaultSignatureTolerance = 5 * time.Minute
+
+// Signature verification errors
+var (
+	ErrInvalidSignatureHeader = errors.New("invalid webhook signature header")
+	ErrSignatureMismatch      = errors.New("webhook signature does not match")
+	ErrSignatureExpired       = errors.New("webhook signature timestamp outside tolerance")
+)
+
+// ComputeSignature returns the hex HMAC-SHA256 of "<timestamp>.<payload>" using the secret
+func ComputeSignature(secret string, timestamp int64, payload []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SignPayload builds the X-Payment-Signature header value for a payload signed at the given time
+func SignPayload(secret string, signedAt time.Time, payload []byte) string {
+	timestamp := signedAt.Unix()
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, ComputeSignature(secret, timestamp, payload))
+}
+
+// VerifySignature checks an X-Payment-Signature header against the payload and secret.
+// Deliveries signed more than tolerance before now are rejected (tolerance <= 0 disables the check).
+func VerifySignature(header, secret string, payload []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp int64
+	var signatures []string
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			ts, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return ErrInvalidSignatureHeader
+			}
+			timestamp = ts
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if timestamp == 0 || len(signatures) == 0 {
+		return ErrInvalidSignatureHeader
+	}
+
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+
+	expected := ComputeSignature(secret, timestamp, payload)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+
+	return ErrSignatureMismatch
+}
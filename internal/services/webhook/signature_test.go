@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	testSecret    = "whsec_test_secret"
+	testPayload   = `{"event_type":"payment.success","agent_id":"test-agent-123"}`
+	testTimestamp = int64(1735689600) // 2025-01-01T00:00:00Z
+)
+
+func TestComputeSignature_KnownVector(t *testing.T) {
+	// Known vector: HMAC-SHA256("whsec_test_secret", "1735689600.{...}")
+	signature := ComputeSignature(testSecret, testTimestamp, []byte(testPayload))
+
+	assert.Equal(t, "8b41666a92212d4b99fde5f293beb80251d4ab99df5c6af3ddd5e2aa4dfdade7", signature)
+}
+
+func TestSignPayload_HeaderFormat(t *testing.T) {
+	signedAt := time.Unix(testTimestamp, 0)
+
+	header := SignPayload(testSecret, signedAt, []byte(testPayload))
+
+	assert.Equal(t, "t=1735689600,v1="+ComputeSignature(testSecret, testTimestamp, []byte(testPayload)), header)
+}
+
+func TestVerifySignature(t *testing.T) {
+	signedAt := time.Unix(testTimestamp, 0)
+	header := SignPayload(testSecret, signedAt, []byte(testPayload))
+
+	tests := []struct {
+		name    string
+		header  string
+		secret  string
+		payload string
+		now     time.Time
+		wantErr error
+	}{
+		{"valid signature", header, testSecret, testPayload, signedAt.Add(time.Minute), nil},
+		{"wrong secret", header, "other_secret", testPayload, signedAt, ErrSignatureMismatch},
+		{"tampered payload", header, testSecret, `{"event_type":"payment.failed"}`, signedAt, ErrSignatureMismatch},
+		{"replayed after tolerance", header, testSecret, testPayload, signedAt.Add(DefaultSignatureTolerance + time.Second), ErrSignatureExpired},
+		{"timestamp too far in future", header, testSecret, testPayload, signedAt.Add(-DefaultSignatureTolerance - time.Second), ErrSignatureExpired},
+		{"missing v1", "t=1735689600", testSecret, testPayload, signedAt, ErrInvalidSignatureHeader},
+		{"missing timestamp", "v1=abc", testSecret, testPayload, signedAt, ErrInvalidSignatureHeader},
+		{"malformed timestamp", "t=abc,v1=abc", testSecret, testPayload, signedAt, ErrInvalidSignatureHeader},
+		{"unknown schemes ignored", header + ",v0=legacy", testSecret, testPayload, signedAt, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.header, tt.secret, []byte(tt.payload), DefaultSignatureTolerance, tt.now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDeliverToSubscription_SendsVerifiableSignature(t *testing.T) {
+	var receivedHeader string
+	var receivedBody []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeader = r.Header.Get(SignatureHeader)
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscription := newTestSubscription(server.URL)
+	service := NewWebhookDeliveryServiceWithQueries(newFakeQueries(subscription), server.Client(), DefaultRetryPolicy(), zap.NewNop())
+
+	err := service.deliverToSubscription(context.Background(), subscription, newTestEvent())
+	require.NoError(t, err)
+
+	require.NotEmpty(t, receivedHeader)
+	assert.NoError(t, VerifySignature(receivedHeader, testSecret, receivedBody, DefaultSignatureTolerance, time.Now()))
+}
@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// MaxWebhookStatsRange is the longest time range GetWebhookStats covers
+const MaxWebhookStatsRange = 90 * 24 * time.Hour
+
+// Failure reasons recorded for attempts that got no HTTP response
+const (
+	FailureReasonTimeout           = "timeout"
+	FailureReasonDNS               = "dns_error"
+	FailureReasonConnectionRefused = "connection_refused"
+	FailureReasonTLS               = "tls_error"
+	FailureReasonNetwork           = "network_error"
+)
+
+// failureReason classifies a failed attempt: http_<status> when the endpoint responded, otherwise why the
+// request couldn't complete
+func failureReason(statusCode int, err error) string {
+	if statusCode > 0 {
+		return fmt.Sprintf("http_%d", statusCode)
+	}
+
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var recordErr tls.RecordHeaderError
+	switch {
+	case errors.As(err, &dnsErr):
+		return FailureReasonDNS
+	case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+		return FailureReasonTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return FailureReasonConnectionRefused
+	case errors.As(err, &certErr) || errors.As(err, &unknownAuthority) || errors.As(err, &hostnameErr) || errors.As(err, &recordErr):
+		return FailureReasonTLS
+	default:
+		return FailureReasonNetwork
+	}
+}
+
+// GetWebhookStats summarizes the delivery success rate, latency and failure reasons of the agent's subscription
+// for deliveries created within [from, to)
+func (s *WebhookDeliveryService) GetWebhookStats(ctx context.Context, agentID string, subscriptionID uuid.UUID, from, to time.Time) (*domain.WebhookStats, error) {
+	if !from.Before(to) || to.Sub(from) > MaxWebhookStatsRange {
+		return nil, domain.ErrInvalidTimeRange
+	}
+
+	if _, err := s.getOwnedSubscription(ctx, agentID, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.GetWebhookDeliveryStats(ctx, sqlc.GetWebhookDeliveryStatsParams{
+		SubscriptionID: subscriptionID,
+		CreatedFrom:    from,
+		CreatedTo:      to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get webhook delivery stats: %w", err)
+	}
+
+	stats := summarizeDeliveryStats(rows)
+	stats.From, stats.To = from, to
+	return stats, nil
+}
+
+// summarizeDeliveryStats combines the per status and failure reason groups into a subscription's stats
+func summarizeDeliveryStats(rows []sqlc.GetWebhookDeliveryStatsRow) *domain.WebhookStats {
+	stats := &domain.WebhookStats{}
+	reasons := make(map[string]int64)
+	var timed, totalLatencyMs int64
+
+	for _, row := range rows {
+		stats.TotalDeliveries += row.Deliveries
+		timed += row.TimedDeliveries
+		totalLatencyMs += row.TotalLatencyMs
+
+		switch row.Status {
+		case DeliveryStatusSuccess:
+			stats.Succeeded += row.Deliveries
+			continue
+		case DeliveryStatusFailed, DeliveryStatusDeadLetter:
+			stats.Failed += row.Deliveries
+		default:
+			stats.Pending += row.Deliveries
+		}
+		if row.FailureReason != "" {
+			reasons[row.FailureReason] += row.Deliveries
+		}
+	}
+
+	if finished := stats.Succeeded + stats.Failed; finished > 0 {
+		stats.SuccessRate = float64(stats.Succeeded) / float64(finished)
+	}
+	if timed > 0 {
+		stats.AverageLatency = time.Duration(totalLatencyMs) * time.Millisecond / time.Duration(timed)
+	}
+
+	stats.FailureReasons = make([]domain.WebhookFailureReasonCount, 0, len(reasons))
+	for reason, count := range reasons {
+		stats.FailureReasons = append(stats.FailureReasons, domain.WebhookFailureReasonCount{Reason: reason, Count: count})
+	}
+	sort.Slice(stats.FailureReasons, func(i, j int) bool {
+		if stats.FailureReasons[i].Count != stats.FailureReasons[j].Count {
+			return stats.FailureReasons[i].Count > stats.FailureReasons[j].Count
+		}
+		return stats.FailureReasons[i].Reason < stats.FailureReasons[j].Reason
+	})
+
+	return stats
+}
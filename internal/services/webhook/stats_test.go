@@ -0,0 +1,152 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+func TestGetWebhookStats_SeededDeliveries(t *testing.T) {
+	subscription := newTestSubscription("https://merchant.example.com/webhooks")
+	other := newTestSubscription("https://merchant.example.com/other")
+	queries := newFakeQueries(subscription, other)
+	service := NewWebhookDeliveryServiceWithQueries(queries, nil, DefaultRetryPolicy(), zap.NewNop())
+	ctx := context.Background()
+
+	now := time.Now()
+	seed := func(subscriptionID uuid.UUID, status, reason string, latencyMs int32, createdAt time.Time) {
+		delivery, err := queries.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+			SubscriptionID: subscriptionID,
+			EventType:      subscription.EventType,
+			Payload:        []byte(`{}`),
+			Status:         status,
+			Attempts:       1,
+			LatencyMs:      pgtype.Int4{Int32: latencyMs, Valid: true},
+			FailureReason:  pgtype.Text{String: reason, Valid: reason != ""},
+		})
+		require.NoError(t, err)
+		queries.deliveries[delivery.ID].CreatedAt = createdAt
+	}
+
+	// 6 successes, 2 dead-lettered 503s, 1 dead-lettered timeout, 1 pending 503 (still retrying)
+	for i := 0; i < 6; i++ {
+		seed(subscription.ID, DeliveryStatusSuccess, "", 100, now.Add(-time.Hour))
+	}
+	seed(subscription.ID, DeliveryStatusDeadLetter, "http_503", 200, now.Add(-time.Hour))
+	seed(subscription.ID, DeliveryStatusDeadLetter, "http_503", 200, now.Add(-time.Hour))
+	seed(subscription.ID, DeliveryStatusDeadLetter, FailureReasonTimeout, 1000, now.Add(-time.Hour))
+	seed(subscription.ID, DeliveryStatusPending, "http_503", 200, now.Add(-time.Hour))
+
+	// Outside the range or for another subscription
+	seed(subscription.ID, DeliveryStatusDeadLetter, FailureReasonDNS, 5, now.Add(-48*time.Hour))
+	seed(other.ID, DeliveryStatusDeadLetter, FailureReasonDNS, 5, now.Add(-time.Hour))
+
+	stats, err := service.GetWebhookStats(ctx, subscription.AgentID, subscription.ID, now.Add(-24*time.Hour), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(10), stats.TotalDeliveries)
+	assert.Equal(t, int64(6), stats.Succeeded)
+	assert.Equal(t, int64(3), stats.Failed)
+	assert.Equal(t, int64(1), stats.Pending)
+	assert.InDelta(t, 6.0/9.0, stats.SuccessRate, 1e-9, "pending deliveries haven't finished")
+	assert.Equal(t, 220*time.Millisecond, stats.AverageLatency) // (6*100 + 3*200 + 1000) / 10
+
+	require.Len(t, stats.FailureReasons, 2)
+	assert.Equal(t, domain.WebhookFailureReasonCount{Reason: "http_503", Count: 3}, stats.FailureReasons[0])
+	assert.Equal(t, domain.WebhookFailureReasonCount{Reason: FailureReasonTimeout, Count: 1}, stats.FailureReasons[1])
+}
+
+func TestGetWebhookStats_NoDeliveries(t *testing.T) {
+	subscription := newTestSubscription("https://merchant.example.com/webhooks")
+	service := NewWebhookDeliveryServiceWithQueries(newFakeQueries(subscription), nil, DefaultRetryPolicy(), zap.NewNop())
+	now := time.Now()
+
+	stats, err := service.GetWebhookStats(context.Background(), subscription.AgentID, subscription.ID, now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	assert.Zero(t, stats.TotalDeliveries)
+	assert.Zero(t, stats.SuccessRate)
+	assert.Zero(t, stats.AverageLatency)
+	assert.Empty(t, stats.FailureReasons)
+}
+
+func TestGetWebhookStats_ScopedAndValidated(t *testing.T) {
+	subscription := newTestSubscription("https://merchant.example.com/webhooks")
+	service := NewWebhookDeliveryServiceWithQueries(newFakeQueries(subscription), nil, DefaultRetryPolicy(), zap.NewNop())
+	ctx := context.Background()
+	now := time.Now()
+
+	_, err := service.GetWebhookStats(ctx, "other-agent", subscription.ID, now.Add(-time.Hour), now)
+	assert.ErrorIs(t, err, domain.ErrWebhookSubscriptionNotFound)
+
+	_, err = service.GetWebhookStats(ctx, subscription.AgentID, subscription.ID, now, now.Add(-time.Hour))
+	assert.ErrorIs(t, err, domain.ErrInvalidTimeRange)
+
+	_, err = service.GetWebhookStats(ctx, subscription.AgentID, subscription.ID, now.Add(-MaxWebhookStatsRange-time.Hour), now)
+	assert.ErrorIs(t, err, domain.ErrInvalidTimeRange)
+}
+
+func TestDeliverEvent_RecordsLatencyAndFailureReason(t *testing.T) {
+	var calls atomic.Int32
+	server := newFlakyServer(1, &calls)
+	defer server.Close()
+
+	subscription := newTestSubscription(server.URL)
+	queries := newFakeQueries(subscription)
+	service := NewWebhookDeliveryServiceWithQueries(queries, server.Client(), DefaultRetryPolicy(), zap.NewNop())
+	ctx := context.Background()
+
+	require.NoError(t, service.DeliverEvent(ctx, newTestEvent()))
+	delivery := queries.onlyDelivery(t)
+	assert.True(t, delivery.LatencyMs.Valid)
+	assert.Equal(t, fmt.Sprintf("http_%d", http.StatusServiceUnavailable), delivery.FailureReason.String)
+
+	_, err := service.RetryFailedDeliveries(ctx)
+	require.NoError(t, err)
+	delivery = queries.onlyDelivery(t)
+	assert.Equal(t, DeliveryStatusSuccess, delivery.Status)
+	assert.True(t, delivery.LatencyMs.Valid)
+	assert.False(t, delivery.FailureReason.Valid, "a successful retry clears the failure reason")
+}
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		err        error
+		want       string
+	}{
+		{"http status", http.StatusInternalServerError, errors.New("HTTP 500: oops"), "http_500"},
+		{"deadline", 0, fmt.Errorf("send request: %w", context.DeadlineExceeded), FailureReasonTimeout},
+		{"net timeout", 0, fmt.Errorf("send request: %w", timeoutError{}), FailureReasonTimeout},
+		{"dns", 0, fmt.Errorf("send request: %w", &net.DNSError{Err: "no such host", Name: "merchant.invalid"}), FailureReasonDNS},
+		{"refused", 0, fmt.Errorf("send request: %w", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}), FailureReasonConnectionRefused},
+		{"other", 0, errors.New("send request: EOF"), FailureReasonNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, failureReason(tt.statusCode, tt.err))
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// webhookSecretBytes is the size of a subscription's random signing secret
+const webhookSecretBytes = 32
+
+// uniqueViolation is the PostgreSQL unique_violation code, raised for a duplicate (agent, event type, URL)
+const uniqueViolation = "23505"
+
+// hostResolver looks up a webhook host's addresses (net.DefaultResolver in production)
+type hostResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// SubscriptionUpdate holds the subscription fields to change; nil fields are left as they are
+type SubscriptionUpdate struct {
+	WebhookURL                *string
+	EventType                 *string
+	IsActive                  *bool
+	BatchSize                 *int32
+	BatchFlushIntervalSeconds *int32
+}
+
+// CreateSubscription subscribes the agent's https endpoint to an event type with a new random signing secret.
+// The returned subscription is the only place the secret is handed back to the merchant.
+func (s *WebhookDeliveryService) CreateSubscription(ctx context.Context, agentID, eventType, webhookURL string) (*sqlc.WebhookSubscription, error) {
+	if err := checkEventType(eventType); err != nil {
+		return nil, err
+	}
+	if err := s.checkWebhookURL(ctx, webhookURL); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.queries.CreateWebhookSubscription(ctx, sqlc.CreateWebhookSubscriptionParams{
+		AgentID:    agentID,
+		EventType:  eventType,
+		WebhookUrl: strings.TrimSpace(webhookURL),
+		Secret:     secret,
+		IsActive:   true,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrWebhookSubscriptionExists
+		}
+		return nil, fmt.Errorf("create webhook subscription: %w", err)
+	}
+
+	s.logger.Info("Webhook subscription created",
+		zap.String("agent_id", agentID),
+		zap.String("subscription_id", subscription.ID.String()),
+		zap.String("event_type", eventType),
+	)
+
+	return &subscription, nil
+}
+
+// ListSubscriptions returns the agent's subscriptions, newest first. isActive filters by the active flag when set.
+func (s *WebhookDeliveryService) ListSubscriptions(ctx context.Context, agentID string, isActive *bool) ([]sqlc.WebhookSubscription, error) {
+	params := sqlc.ListWebhookSubscriptionsParams{AgentID: agentID}
+	if isActive != nil {
+		params.IsActive = pgtype.Bool{Bool: *isActive, Valid: true}
+	}
+
+	subscriptions, err := s.queries.ListWebhookSubscriptions(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription changes the set fields of one of the agent's subscriptions.
+// Subscriptions belonging to another agent are reported as not found.
+func (s *WebhookDeliveryService) UpdateSubscription(ctx context.Context, agentID string, subscriptionID uuid.UUID, update SubscriptionUpdate) (*sqlc.WebhookSubscription, error) {
+	if _, err := s.getOwnedSubscription(ctx, agentID, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	params := sqlc.UpdateWebhookSubscriptionParams{ID: subscriptionID}
+	if update.EventType != nil {
+		if err := checkEventType(*update.EventType); err != nil {
+			return nil, err
+		}
+		params.EventType = pgtype.Text{String: *update.EventType, Valid: true}
+	}
+	if update.WebhookURL != nil {
+		if err := s.checkWebhookURL(ctx, *update.WebhookURL); err != nil {
+			return nil, err
+		}
+		params.WebhookUrl = pgtype.Text{String: strings.TrimSpace(*update.WebhookURL), Valid: true}
+	}
+	if update.IsActive != nil {
+		params.IsActive = pgtype.Bool{Bool: *update.IsActive, Valid: true}
+	}
+	if update.BatchSize != nil {
+		params.BatchSize = pgtype.Int4{Int32: *update.BatchSize, Valid: true}
+	}
+	if update.BatchFlushIntervalSeconds != nil {
+		params.BatchFlushIntervalSeconds = pgtype.Int4{Int32: *update.BatchFlushIntervalSeconds, Valid: true}
+	}
+
+	subscription, err := s.queries.UpdateWebhookSubscription(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrWebhookSubscriptionExists
+		}
+		return nil, fmt.Errorf("update webhook subscription: %w", err)
+	}
+
+	s.logger.Info("Webhook subscription updated",
+		zap.String("agent_id", agentID),
+		zap.String("subscription_id", subscriptionID.String()),
+	)
+
+	return &subscription, nil
+}
+
+// DeleteSubscription removes one of the agent's subscriptions along with its deliveries.
+// Subscriptions belonging to another agent are reported as not found.
+func (s *WebhookDeliveryService) DeleteSubscription(ctx context.Context, agentID string, subscriptionID uuid.UUID) error {
+	if _, err := s.getOwnedSubscription(ctx, agentID, subscriptionID); err != nil {
+		return err
+	}
+
+	err := s.queries.DeleteWebhookSubscription(ctx, sqlc.DeleteWebhookSubscriptionParams{
+		ID:      subscriptionID,
+		AgentID: agentID,
+	})
+	if err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+
+	s.logger.Info("Webhook subscription deleted",
+		zap.String("agent_id", agentID),
+		zap.String("subscription_id", subscriptionID.String()),
+	)
+
+	return nil
+}
+
+// checkWebhookURL validates the URL and, for a hostname, that every address it resolves to is public.
+// Checked when the URL is saved; the delivery client (NewDeliveryClient) checks each address it connects to again.
+func (s *WebhookDeliveryService) checkWebhookURL(ctx context.Context, raw string) error {
+	if err := domain.ValidateWebhookURL(raw); err != nil {
+		return err
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidWebhookURL, err)
+	}
+	host := parsed.Hostname()
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+
+	addrs, err := s.resolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: host %q does not resolve", domain.ErrInvalidWebhookURL, host)
+	}
+	for _, addr := range addrs {
+		if err := domain.CheckWebhookIP(addr.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkEventType(eventType string) error {
+	if !IsKnownEventType(eventType) {
+		return fmt.Errorf("%w %q; ListEventTypes returns the supported types", domain.ErrUnknownWebhookEventType, eventType)
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// fakeResolver resolves hostnames from a fixed table
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := f[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	addrs := make([]net.IPAddr, len(ips))
+	for i, ip := range ips {
+		addrs[i] = net.IPAddr{IP: net.ParseIP(ip)}
+	}
+	return addrs, nil
+}
+
+func newSubscriptionTestService(queries *fakeQueries) *WebhookDeliveryService {
+	service := NewWebhookDeliveryServiceWithQueries(queries, nil, DefaultRetryPolicy(), zap.NewNop())
+	service.resolver = fakeResolver{
+		"hooks.merchant.example":    {"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"},
+		"internal.merchant.example": {"93.184.216.34", "10.0.0.5"},
+		"metadata.attacker.example": {"169.254.169.254"},
+	}
+	return service
+}
+
+func TestCreateSubscription_ValidatesURL(t *testing.T) {
+	service := newSubscriptionTestService(newFakeQueries())
+	ctx := context.Background()
+
+	for name, url := range map[string]string{
+		"plain http":                  "http://hooks.merchant.example/payments",
+		"private IP literal":          "https://192.168.1.20/payments",
+		"loopback":                    "https://127.0.0.1/payments",
+		"hostname with a private IP":  "https://internal.merchant.example/payments",
+		"hostname resolving metadata": "https://metadata.attacker.example/",
+		"unresolvable hostname":       "https://missing.merchant.example/payments",
+	} {
+		_, err := service.CreateSubscription(ctx, "merchant-a", EventPaymentCaptured, url)
+		assert.ErrorIs(t, err, domain.ErrInvalidWebhookURL, name)
+	}
+
+	_, err := service.CreateSubscription(ctx, "merchant-a", "payment.capture", "https://hooks.merchant.example/payments")
+	assert.ErrorIs(t, err, domain.ErrUnknownWebhookEventType)
+
+	sub, err := service.CreateSubscription(ctx, "merchant-a", EventPaymentCaptured, " https://hooks.merchant.example/payments ")
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.merchant.example/payments", sub.WebhookUrl)
+	assert.True(t, sub.IsActive)
+	assert.Len(t, sub.Secret, 2*webhookSecretBytes)
+
+	_, err = service.CreateSubscription(ctx, "merchant-a", EventPaymentCaptured, "https://hooks.merchant.example/payments")
+	assert.ErrorIs(t, err, domain.ErrWebhookSubscriptionExists)
+}
+
+func TestSubscriptions_ScopedToMerchant(t *testing.T) {
+	queries := newFakeQueries()
+	service := newSubscriptionTestService(queries)
+	ctx := context.Background()
+
+	mine, err := service.CreateSubscription(ctx, "merchant-a", EventPaymentCaptured, "https://hooks.merchant.example/a")
+	require.NoError(t, err)
+	theirs, err := service.CreateSubscription(ctx, "merchant-b", EventPaymentCaptured, "https://hooks.merchant.example/b")
+	require.NoError(t, err)
+	assert.NotEqual(t, mine.Secret, theirs.Secret, "every subscription gets its own secret")
+
+	listed, err := service.ListSubscriptions(ctx, "merchant-a", nil)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, mine.ID, listed[0].ID)
+
+	newURL := "https://hooks.merchant.example/stolen"
+	_, err = service.UpdateSubscription(ctx, "merchant-a", theirs.ID, SubscriptionUpdate{WebhookURL: &newURL})
+	assert.ErrorIs(t, err, domain.ErrWebhookSubscriptionNotFound)
+	assert.Equal(t, "https://hooks.merchant.example/b", queries.subscriptions[theirs.ID].WebhookUrl)
+
+	err = service.DeleteSubscription(ctx, "merchant-a", theirs.ID)
+	assert.ErrorIs(t, err, domain.ErrWebhookSubscriptionNotFound)
+	assert.Contains(t, queries.subscriptions, theirs.ID)
+
+	err = service.DeleteSubscription(ctx, "merchant-a", uuid.New())
+	assert.ErrorIs(t, err, domain.ErrWebhookSubscriptionNotFound)
+
+	require.NoError(t, service.DeleteSubscription(ctx, "merchant-b", theirs.ID))
+	assert.NotContains(t, queries.subscriptions, theirs.ID)
+}
+
+func TestUpdateSubscription(t *testing.T) {
+	queries := newFakeQueries()
+	service := newSubscriptionTestService(queries)
+	ctx := context.Background()
+
+	sub, err := service.CreateSubscription(ctx, "merchant-a", EventPaymentCaptured, "https://hooks.merchant.example/a")
+	require.NoError(t, err)
+
+	privateURL := "https://10.0.0.1/a"
+	_, err = service.UpdateSubscription(ctx, "merchant-a", sub.ID, SubscriptionUpdate{WebhookURL: &privateURL})
+	assert.ErrorIs(t, err, domain.ErrInvalidWebhookURL)
+
+	unknown := "payment.exploded"
+	_, err = service.UpdateSubscription(ctx, "merchant-a", sub.ID, SubscriptionUpdate{EventType: &unknown})
+	assert.ErrorIs(t, err, domain.ErrUnknownWebhookEventType)
+
+	eventType, inactive, batchSize := EventPaymentRefunded, false, int32(25)
+	updated, err := service.UpdateSubscription(ctx, "merchant-a", sub.ID, SubscriptionUpdate{
+		EventType: &eventType,
+		IsActive:  &inactive,
+		BatchSize: &batchSize,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, EventPaymentRefunded, updated.EventType)
+	assert.False(t, updated.IsActive)
+	assert.Equal(t, int32(25), updated.BatchSize)
+	assert.Equal(t, "https://hooks.merchant.example/a", updated.WebhookUrl, "unset fields are unchanged")
+	assert.Equal(t, sub.Secret, updated.Secret)
+
+	active := true
+	listed, err := service.ListSubscriptions(ctx, "merchant-a", &active)
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+}
@@ -7,28 +7,101 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 
 	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+	"github.com/kevin07696/payment-service/pkg/observability"
+	"github.com/kevin07696/payment-service/pkg/security"
+)
+
+// MaxRedeliveryBatch bounds how many failed deliveries one bulk redelivery re-enqueues
+const MaxRedeliveryBatch = 500
+
+// Webhook delivery statuses
+const (
+	DeliveryStatusPending    = "pending"     // Failed, waiting for next retry
+	DeliveryStatusSuccess    = "success"     // Delivered (2xx response)
+	DeliveryStatusFailed     = "failed"      // Legacy terminal failure
+	DeliveryStatusDeadLetter = "dead_letter" // Max attempts exhausted, replay manually
 )
 
 // DatabaseAdapter defines the interface for database operations
 type DatabaseAdapter interface {
-	Queries() *sqlc.Queries
+	Queries() sqlc.Querier
+}
+
+// QueryExecutor defines the webhook queries used by the delivery service
+type QueryExecutor interface {
+	ListActiveWebhooksByEvent(ctx context.Context, arg sqlc.ListActiveWebhooksByEventParams) ([]sqlc.WebhookSubscription, error)
+	GetWebhookSubscription(ctx context.Context, id uuid.UUID) (sqlc.WebhookSubscription, error)
+	CreateWebhookDelivery(ctx context.Context, arg sqlc.CreateWebhookDeliveryParams) (sqlc.WebhookDelivery, error)
+	UpdateWebhookDeliveryStatus(ctx context.Context, arg sqlc.UpdateWebhookDeliveryStatusParams) (sqlc.WebhookDelivery, error)
+	ListPendingWebhookDeliveries(ctx context.Context, limitVal int32) ([]sqlc.WebhookDelivery, error)
+	ListDeadLetterWebhookDeliveries(ctx context.Context, arg sqlc.ListDeadLetterWebhookDeliveriesParams) ([]sqlc.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, id uuid.UUID) (sqlc.WebhookDelivery, error)
+	ListFailedWebhookDeliveries(ctx context.Context, arg sqlc.ListFailedWebhookDeliveriesParams) ([]sqlc.WebhookDelivery, error)
+	GetWebhookDeliveryStats(ctx context.Context, arg sqlc.GetWebhookDeliveryStatsParams) ([]sqlc.GetWebhookDeliveryStatsRow, error)
+	CreateWebhookSubscription(ctx context.Context, arg sqlc.CreateWebhookSubscriptionParams) (sqlc.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context, arg sqlc.ListWebhookSubscriptionsParams) ([]sqlc.WebhookSubscription, error)
+	UpdateWebhookSubscription(ctx context.Context, arg sqlc.UpdateWebhookSubscriptionParams) (sqlc.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, arg sqlc.DeleteWebhookSubscriptionParams) error
+	EnqueueWebhookBatchEvent(ctx context.Context, arg sqlc.EnqueueWebhookBatchEventParams) error
+	CountWebhookBatchEvents(ctx context.Context, subscriptionID uuid.UUID) (int64, error)
+	ClaimWebhookBatchEvents(ctx context.Context, arg sqlc.ClaimWebhookBatchEventsParams) ([]sqlc.WebhookBatchEvent, error)
+	ListDueWebhookBatches(ctx context.Context, now pgtype.Timestamptz) ([]uuid.UUID, error)
+}
+
+// RetryPolicy controls exponential backoff for failed deliveries
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts (including the first) before dead-lettering
+	BaseDelay   time.Duration // Delay after the first failed attempt
+	MaxDelay    time.Duration // Upper bound for any single delay
+}
+
+// DefaultRetryPolicy retries for roughly a day: 1m, 2m, 4m, ... capped at 6h, 10 attempts total
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 10,
+		BaseDelay:   time.Minute,
+		MaxDelay:    6 * time.Hour,
+	}
+}
+
+// NextRetryDelay returns the delay before the next attempt after the given number of attempts
+func (p RetryPolicy) NextRetryDelay(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			break
+		}
+	}
+
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
 }
 
 // WebhookDeliveryService handles webhook delivery to merchant endpoints
 type WebhookDeliveryService struct {
-	db         DatabaseAdapter
-	httpClient *http.Client
-	logger     *zap.Logger
+	queries     QueryExecutor
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	resolver    hostResolver
+	now         func() time.Time
+	logger      *zap.Logger
 }
 
 // WebhookEvent represents an event to be sent via webhook
@@ -41,20 +114,27 @@ type WebhookEvent struct {
 
 // NewWebhookDeliveryService creates a new webhook delivery service
 func NewWebhookDeliveryService(db DatabaseAdapter, httpClient *http.Client, logger *zap.Logger) *WebhookDeliveryService {
+	return NewWebhookDeliveryServiceWithQueries(db.Queries(), httpClient, DefaultRetryPolicy(), logger)
+}
+
+// NewWebhookDeliveryServiceWithQueries creates a webhook delivery service with a custom query executor and retry policy
+func NewWebhookDeliveryServiceWithQueries(queries QueryExecutor, httpClient *http.Client, retryPolicy RetryPolicy, logger *zap.Logger) *WebhookDeliveryService {
 	if httpClient == nil {
-		httpClient = &http.Client{
-			Timeout: 10 * time.Second,
-		}
+		httpClient = NewDeliveryClient(security.NewHTTPTransport(security.DefaultMinTLSVersion), 10*time.Second)
 	}
 
 	return &WebhookDeliveryService{
-		db:         db,
-		httpClient: httpClient,
-		logger:     logger,
+		queries:     queries,
+		httpClient:  httpClient,
+		retryPolicy: retryPolicy,
+		resolver:    net.DefaultResolver,
+		now:         time.Now,
+		logger:      logger,
 	}
 }
 
-// DeliverEvent delivers a webhook event to all subscribed endpoints
+// DeliverEvent delivers a webhook event to all subscribed endpoints.
+// Subscriptions with a batch size above 1 queue the event; their batch is sent once full or by the batch flusher.
 func (s *WebhookDeliveryService) DeliverEvent(ctx context.Context, event *WebhookEvent) error {
 	s.logger.Info("Delivering webhook event",
 		zap.String("event_type", event.EventType),
@@ -62,7 +142,7 @@ func (s *WebhookDeliveryService) DeliverEvent(ctx context.Context, event *Webhoo
 	)
 
 	// Find active webhook subscriptions for this event type
-	subscriptions, err := s.db.Queries().ListActiveWebhooksByEvent(ctx, sqlc.ListActiveWebhooksByEventParams{
+	subscriptions, err := s.queries.ListActiveWebhooksByEvent(ctx, sqlc.ListActiveWebhooksByEventParams{
 		AgentID:   event.AgentID,
 		EventType: event.EventType,
 	})
@@ -86,6 +166,16 @@ func (s *WebhookDeliveryService) DeliverEvent(ctx context.Context, event *Webhoo
 
 	// Deliver to each subscription
 	for _, subscription := range subscriptions {
+		if subscription.BatchSize > 1 {
+			if err := s.queueBatchEvent(ctx, subscription, event); err != nil {
+				s.logger.Error("Failed to queue webhook for batching",
+					zap.Error(err),
+					zap.String("subscription_id", subscription.ID.String()),
+				)
+			}
+			continue
+		}
+
 		if err := s.deliverToSubscription(ctx, subscription, event); err != nil {
 			s.logger.Error("Failed to deliver webhook",
 				zap.Error(err),
@@ -100,7 +190,7 @@ func (s *WebhookDeliveryService) DeliverEvent(ctx context.Context, event *Webhoo
 	return nil
 }
 
-// deliverToSubscription delivers an event to a single webhook subscription
+// deliverToSubscription makes the first delivery attempt and records its outcome
 func (s *WebhookDeliveryService) deliverToSubscription(
 	ctx context.Context,
 	subscription sqlc.WebhookSubscription,
@@ -112,43 +202,72 @@ func (s *WebhookDeliveryService) deliverToSubscription(
 		return fmt.Errorf("marshal event payload: %w", err)
 	}
 
-	// Generate signature
-	signature := s.generateSignature(payload, subscription.Secret)
+	attempt, err := s.send(ctx, subscription, event.EventType, payload)
+	observability.RecordWebhookDelivery(event.EventType, err == nil)
+	if err != nil {
+		return s.recordDeliveryFailure(ctx, subscription.ID, event.EventType, payload, attempt, err)
+	}
+
+	return s.recordDeliverySuccess(ctx, subscription.ID, event.EventType, payload, attempt)
+}
+
+// deliveryAttempt is the outcome of one POST to a subscription's endpoint
+type deliveryAttempt struct {
+	statusCode int           // 0 if no response
+	latency    time.Duration // Until the response, or until the request failed
+}
+
+// latencyMs is the attempt's latency as stored in webhook_deliveries.latency_ms
+func (a deliveryAttempt) latencyMs() pgtype.Int4 {
+	return pgtype.Int4{Int32: int32(a.latency.Milliseconds()), Valid: true}
+}
+
+// send signs and POSTs the payload
+func (s *WebhookDeliveryService) send(
+	ctx context.Context,
+	subscription sqlc.WebhookSubscription,
+	eventType string,
+	payload []byte,
+) (deliveryAttempt, error) {
+	// Sign at send time so retries carry a fresh timestamp within the receiver's tolerance
+	now := time.Now()
+	legacySignature := s.generateSignature(payload, subscription.Secret)
+	signature := SignPayload(subscription.Secret, now, payload)
 
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "POST", subscription.WebhookUrl, bytes.NewReader(payload))
 	if err != nil {
-		return s.recordDeliveryFailure(ctx, subscription.ID, event.EventType, payload, 0, fmt.Sprintf("create request: %v", err))
+		return deliveryAttempt{}, fmt.Errorf("create request: %w", err)
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Webhook-Signature", signature)
-	req.Header.Set("X-Webhook-Event-Type", event.EventType)
-	req.Header.Set("X-Webhook-Timestamp", event.Timestamp.Format(time.RFC3339))
+	req.Header.Set(SignatureHeader, signature)
+	req.Header.Set("X-Webhook-Signature", legacySignature) // Deprecated: unversioned, no replay protection
+	req.Header.Set("X-Webhook-Event-Type", eventType)
+	req.Header.Set("X-Webhook-Timestamp", now.Format(time.RFC3339))
 
 	// Send request
 	resp, err := s.httpClient.Do(req)
+	attempt := deliveryAttempt{latency: time.Since(now)}
 	if err != nil {
-		return s.recordDeliveryFailure(ctx, subscription.ID, event.EventType, payload, 0, fmt.Sprintf("send request: %v", err))
+		return attempt, fmt.Errorf("send request: %w", err)
 	}
 	defer resp.Body.Close()
+	attempt.statusCode = resp.StatusCode
 
 	// Read response body (for logging)
 	body, _ := io.ReadAll(resp.Body)
 
 	// Check response status
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		// Success
-		return s.recordDeliverySuccess(ctx, subscription.ID, event.EventType, payload, resp.StatusCode)
+		return attempt, nil
 	}
 
-	// Failed delivery
-	errorMsg := fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body))
-	return s.recordDeliveryFailure(ctx, subscription.ID, event.EventType, payload, resp.StatusCode, errorMsg)
+	return attempt, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
 }
 
-// generateSignature creates HMAC-SHA256 signature of the payload
+// generateSignature creates the legacy HMAC-SHA256 signature of the payload (see SignPayload)
 func (s *WebhookDeliveryService) generateSignature(payload []byte, secret string) string {
 	h := hmac.New(sha256.New, []byte(secret))
 	h.Write(payload)
@@ -161,17 +280,18 @@ func (s *WebhookDeliveryService) recordDeliverySuccess(
 	subscriptionID uuid.UUID,
 	eventType string,
 	payload []byte,
-	httpStatusCode int,
+	attempt deliveryAttempt,
 ) error {
-	_, err := s.db.Queries().CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+	_, err := s.queries.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
 		SubscriptionID: subscriptionID,
 		EventType:      eventType,
 		Payload:        payload,
-		Status:         "success",
-		HttpStatusCode: pgtype.Int4{Int32: int32(httpStatusCode), Valid: true},
+		Status:         DeliveryStatusSuccess,
+		HttpStatusCode: pgtype.Int4{Int32: int32(attempt.statusCode), Valid: true},
 		ErrorMessage:   pgtype.Text{Valid: false},
 		Attempts:       1,
 		NextRetryAt:    pgtype.Timestamptz{Valid: false},
+		LatencyMs:      attempt.latencyMs(),
 	})
 
 	if err != nil {
@@ -185,33 +305,35 @@ func (s *WebhookDeliveryService) recordDeliverySuccess(
 	s.logger.Info("Webhook delivered successfully",
 		zap.String("subscription_id", subscriptionID.String()),
 		zap.String("event_type", eventType),
-		zap.Int("http_status", httpStatusCode),
+		zap.Int("http_status", attempt.statusCode),
 	)
 
 	return nil
 }
 
-// recordDeliveryFailure records a failed webhook delivery
+// recordDeliveryFailure records a failed first attempt and schedules it for retry
 func (s *WebhookDeliveryService) recordDeliveryFailure(
 	ctx context.Context,
 	subscriptionID uuid.UUID,
 	eventType string,
 	payload []byte,
-	httpStatusCode int,
-	errorMessage string,
+	attempt deliveryAttempt,
+	sendErr error,
 ) error {
-	// Calculate next retry time (exponential backoff)
-	nextRetry := time.Now().Add(5 * time.Minute) // First retry after 5 minutes
+	status, nextRetryAt := s.scheduleRetry(1)
+	errorMessage := sendErr.Error()
 
-	_, err := s.db.Queries().CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+	_, err := s.queries.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
 		SubscriptionID: subscriptionID,
 		EventType:      eventType,
 		Payload:        payload,
-		Status:         "pending", // Will be retried
-		HttpStatusCode: pgtype.Int4{Int32: int32(httpStatusCode), Valid: httpStatusCode > 0},
+		Status:         status,
+		HttpStatusCode: pgtype.Int4{Int32: int32(attempt.statusCode), Valid: attempt.statusCode > 0},
 		ErrorMessage:   pgtype.Text{String: errorMessage, Valid: true},
 		Attempts:       1,
-		NextRetryAt:    pgtype.Timestamptz{Time: nextRetry, Valid: true},
+		NextRetryAt:    nextRetryAt,
+		LatencyMs:      attempt.latencyMs(),
+		FailureReason:  pgtype.Text{String: failureReason(attempt.statusCode, sendErr), Valid: true},
 	})
 
 	if err != nil {
@@ -222,45 +344,43 @@ func (s *WebhookDeliveryService) recordDeliveryFailure(
 		return err
 	}
 
-	s.logger.Warn("Webhook delivery failed, scheduled for retry",
+	s.logger.Warn("Webhook delivery failed",
 		zap.String("subscription_id", subscriptionID.String()),
 		zap.String("event_type", eventType),
-		zap.Int("http_status", httpStatusCode),
+		zap.Int("http_status", attempt.statusCode),
 		zap.String("error", errorMessage),
-		zap.Time("next_retry", nextRetry),
+		zap.String("status", status),
 	)
 
 	return fmt.Errorf("webhook delivery failed: %s", errorMessage)
 }
 
-// RetryFailedDeliveries retries pending webhook deliveries
-func (s *WebhookDeliveryService) RetryFailedDeliveries(ctx context.Context, maxRetries int) (int, error) {
-	s.logger.Info("Starting webhook delivery retry process", zap.Int("max_retries", maxRetries))
+// scheduleRetry returns the status and next retry time after a failed attempt
+func (s *WebhookDeliveryService) scheduleRetry(attempts int) (string, pgtype.Timestamptz) {
+	if attempts >= s.retryPolicy.MaxAttempts {
+		return DeliveryStatusDeadLetter, pgtype.Timestamptz{Valid: false}
+	}
+
+	nextRetry := time.Now().Add(s.retryPolicy.NextRetryDelay(attempts))
+	return DeliveryStatusPending, pgtype.Timestamptz{Time: nextRetry, Valid: true}
+}
 
-	deliveries, err := s.db.Queries().ListPendingWebhookDeliveries(ctx, 100) // Process up to 100 at a time
+// RetryFailedDeliveries retries pending deliveries that are due, backing off exponentially
+// and moving deliveries that reach the policy's max attempts to dead letter.
+// Returns the number of deliveries that succeeded on retry.
+func (s *WebhookDeliveryService) RetryFailedDeliveries(ctx context.Context) (int, error) {
+	s.logger.Info("Starting webhook delivery retry process", zap.Int("max_attempts", s.retryPolicy.MaxAttempts))
+
+	deliveries, err := s.queries.ListPendingWebhookDeliveries(ctx, 100) // Process up to 100 at a time
 	if err != nil {
 		return 0, fmt.Errorf("fetch pending deliveries: %w", err)
 	}
 
 	retried := 0
+	deadLettered := 0
 	for _, delivery := range deliveries {
-		// Skip if max retries exceeded
-		if int(delivery.Attempts) >= maxRetries {
-			// Mark as failed
-			_, _ = s.db.Queries().UpdateWebhookDeliveryStatus(ctx, sqlc.UpdateWebhookDeliveryStatusParams{
-				ID:             delivery.ID,
-				Status:         "failed",
-				HttpStatusCode: delivery.HttpStatusCode,
-				ErrorMessage:   pgtype.Text{String: "max retries exceeded", Valid: true},
-				Attempts:       delivery.Attempts,
-				NextRetryAt:    pgtype.Timestamptz{Valid: false},
-				DeliveredAt:    pgtype.Timestamptz{Valid: false},
-			})
-			continue
-		}
-
 		// Get subscription
-		subscription, err := s.db.Queries().GetWebhookSubscription(ctx, delivery.SubscriptionID)
+		subscription, err := s.queries.GetWebhookSubscription(ctx, delivery.SubscriptionID)
 		if err != nil {
 			s.logger.Error("Failed to get subscription for retry",
 				zap.Error(err),
@@ -269,48 +389,174 @@ func (s *WebhookDeliveryService) RetryFailedDeliveries(ctx context.Context, maxR
 			continue
 		}
 
-		// Unmarshal event from payload
-		var event WebhookEvent
-		if err := json.Unmarshal(delivery.Payload, &event); err != nil {
-			s.logger.Error("Failed to unmarshal event payload",
-				zap.Error(err),
-				zap.String("delivery_id", delivery.ID.String()),
-			)
-			continue
+		attempts := delivery.Attempts + 1
+		params := sqlc.UpdateWebhookDeliveryStatusParams{
+			ID:       delivery.ID,
+			Attempts: attempts,
 		}
 
-		// Retry delivery
-		if err := s.deliverToSubscription(ctx, subscription, &event); err != nil {
-			// Update attempts count and next retry time
-			nextRetry := time.Now().Add(time.Duration(delivery.Attempts+1) * 10 * time.Minute)
-			_, _ = s.db.Queries().UpdateWebhookDeliveryStatus(ctx, sqlc.UpdateWebhookDeliveryStatusParams{
-				ID:             delivery.ID,
-				Status:         "pending",
-				HttpStatusCode: delivery.HttpStatusCode,
-				ErrorMessage:   delivery.ErrorMessage,
-				Attempts:       delivery.Attempts + 1,
-				NextRetryAt:    pgtype.Timestamptz{Time: nextRetry, Valid: true},
-				DeliveredAt:    pgtype.Timestamptz{Valid: false},
-			})
-		} else {
-			// Success - update delivery record
-			_, _ = s.db.Queries().UpdateWebhookDeliveryStatus(ctx, sqlc.UpdateWebhookDeliveryStatusParams{
-				ID:             delivery.ID,
-				Status:         "success",
-				HttpStatusCode: pgtype.Int4{Int32: 200, Valid: true},
-				ErrorMessage:   pgtype.Text{Valid: false},
-				Attempts:       delivery.Attempts + 1,
-				NextRetryAt:    pgtype.Timestamptz{Valid: false},
-				DeliveredAt:    pgtype.Timestamptz{Time: time.Now(), Valid: true},
-			})
+		// Resend the stored payload as-is so the receiver sees the original event
+		attempt, sendErr := s.send(ctx, subscription, delivery.EventType, delivery.Payload)
+		observability.RecordWebhookDelivery(delivery.EventType, sendErr == nil)
+		params.HttpStatusCode = pgtype.Int4{Int32: int32(attempt.statusCode), Valid: attempt.statusCode > 0}
+		params.LatencyMs = attempt.latencyMs()
+
+		if sendErr == nil {
+			params.Status = DeliveryStatusSuccess
+			params.DeliveredAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
 			retried++
+		} else {
+			params.Status, params.NextRetryAt = s.scheduleRetry(int(attempts))
+			params.ErrorMessage = pgtype.Text{String: sendErr.Error(), Valid: true}
+			params.FailureReason = pgtype.Text{String: failureReason(attempt.statusCode, sendErr), Valid: true}
+			if params.Status == DeliveryStatusDeadLetter {
+				deadLettered++
+				s.logger.Warn("Webhook delivery moved to dead letter",
+					zap.String("delivery_id", delivery.ID.String()),
+					zap.String("subscription_id", delivery.SubscriptionID.String()),
+					zap.Int32("attempts", attempts),
+					zap.Error(sendErr),
+				)
+			}
+		}
+
+		if _, err := s.queries.UpdateWebhookDeliveryStatus(ctx, params); err != nil {
+			s.logger.Error("Failed to update webhook delivery status",
+				zap.Error(err),
+				zap.String("delivery_id", delivery.ID.String()),
+			)
 		}
 	}
 
 	s.logger.Info("Webhook retry process completed",
 		zap.Int("total_pending", len(deliveries)),
 		zap.Int("retried", retried),
+		zap.Int("dead_lettered", deadLettered),
 	)
 
 	return retried, nil
 }
+
+// ListDeadLetterDeliveries lists deliveries that exhausted their retries, for manual replay
+func (s *WebhookDeliveryService) ListDeadLetterDeliveries(ctx context.Context, agentID *string, limit, offset int) ([]sqlc.WebhookDelivery, error) {
+	params := sqlc.ListDeadLetterWebhookDeliveriesParams{
+		LimitVal:  int32(limit),
+		OffsetVal: int32(offset),
+	}
+	if agentID != nil {
+		params.AgentID = pgtype.Text{String: *agentID, Valid: true}
+	}
+
+	deliveries, err := s.queries.ListDeadLetterWebhookDeliveries(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("list dead letter deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhook re-enqueues a fresh delivery of the original event payload.
+// The new delivery starts with a reset attempt count and is sent by the retry worker.
+// Deliveries belonging to another agent are reported as not found.
+func (s *WebhookDeliveryService) RedeliverWebhook(ctx context.Context, agentID string, deliveryID uuid.UUID) (*sqlc.WebhookDelivery, error) {
+	original, err := s.queries.GetWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("get webhook delivery: %w", err)
+	}
+
+	if _, err := s.getOwnedSubscription(ctx, agentID, original.SubscriptionID); err != nil {
+		if errors.Is(err, domain.ErrWebhookSubscriptionNotFound) {
+			return nil, domain.ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return s.enqueueRedelivery(ctx, &original)
+}
+
+// RedeliverFailedWebhooks re-enqueues every failed or dead-lettered delivery for a subscription
+// created within [from, to). Returns the new deliveries.
+func (s *WebhookDeliveryService) RedeliverFailedWebhooks(ctx context.Context, agentID string, subscriptionID uuid.UUID, from, to time.Time) ([]sqlc.WebhookDelivery, error) {
+	if !from.Before(to) {
+		return nil, domain.ErrInvalidTimeRange
+	}
+
+	if _, err := s.getOwnedSubscription(ctx, agentID, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	failed, err := s.queries.ListFailedWebhookDeliveries(ctx, sqlc.ListFailedWebhookDeliveriesParams{
+		SubscriptionID: subscriptionID,
+		CreatedFrom:    from,
+		CreatedTo:      to,
+		LimitVal:       MaxRedeliveryBatch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list failed webhook deliveries: %w", err)
+	}
+
+	redeliveries := make([]sqlc.WebhookDelivery, 0, len(failed))
+	for i := range failed {
+		redelivery, err := s.enqueueRedelivery(ctx, &failed[i])
+		if err != nil {
+			return redeliveries, err
+		}
+		redeliveries = append(redeliveries, *redelivery)
+	}
+
+	s.logger.Info("Re-enqueued failed webhook deliveries",
+		zap.String("agent_id", agentID),
+		zap.String("subscription_id", subscriptionID.String()),
+		zap.Int("count", len(redeliveries)),
+	)
+
+	return redeliveries, nil
+}
+
+// getOwnedSubscription returns the subscription if it belongs to the agent
+func (s *WebhookDeliveryService) getOwnedSubscription(ctx context.Context, agentID string, subscriptionID uuid.UUID) (*sqlc.WebhookSubscription, error) {
+	subscription, err := s.queries.GetWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWebhookSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("get webhook subscription: %w", err)
+	}
+
+	if subscription.AgentID != agentID {
+		s.logger.Warn("Webhook subscription access denied for subscription owned by another agent",
+			zap.String("agent_id", agentID),
+			zap.String("subscription_id", subscriptionID.String()),
+		)
+		return nil, domain.ErrWebhookSubscriptionNotFound
+	}
+
+	return &subscription, nil
+}
+
+// enqueueRedelivery creates a pending delivery of the original payload, due immediately
+func (s *WebhookDeliveryService) enqueueRedelivery(ctx context.Context, original *sqlc.WebhookDelivery) (*sqlc.WebhookDelivery, error) {
+	redelivery, err := s.queries.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+		SubscriptionID: original.SubscriptionID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         DeliveryStatusPending,
+		Attempts:       0,
+		NextRetryAt:    pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		RedeliveryOf:   pgtype.UUID{Bytes: original.ID, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create webhook redelivery: %w", err)
+	}
+
+	s.logger.Info("Webhook redelivery enqueued",
+		zap.String("original_delivery_id", original.ID.String()),
+		zap.String("delivery_id", redelivery.ID.String()),
+		zap.String("event_type", original.EventType),
+	)
+
+	return &redelivery, nil
+}
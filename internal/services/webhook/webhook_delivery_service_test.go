@@ -0,0 +1,477 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kevin07696/payment-service/internal/db/sqlc"
+	"github.com/kevin07696/payment-service/internal/domain"
+)
+
+// fakeQueries is an in-memory QueryExecutor (pending deliveries are always due)
+type fakeQueries struct {
+	subscriptions map[uuid.UUID]sqlc.WebhookSubscription
+	deliveries    map[uuid.UUID]*sqlc.WebhookDelivery
+	batchEvents   []sqlc.WebhookBatchEvent // Queued for batching, in queue order
+}
+
+func newFakeQueries(subscriptions ...sqlc.WebhookSubscription) *fakeQueries {
+	f := &fakeQueries{
+		subscriptions: make(map[uuid.UUID]sqlc.WebhookSubscription),
+		deliveries:    make(map[uuid.UUID]*sqlc.WebhookDelivery),
+	}
+	for _, sub := range subscriptions {
+		f.subscriptions[sub.ID] = sub
+	}
+	return f
+}
+
+func (f *fakeQueries) ListActiveWebhooksByEvent(ctx context.Context, arg sqlc.ListActiveWebhooksByEventParams) ([]sqlc.WebhookSubscription, error) {
+	var result []sqlc.WebhookSubscription
+	for _, sub := range f.subscriptions {
+		if sub.AgentID == arg.AgentID && sub.EventType == arg.EventType && sub.IsActive {
+			result = append(result, sub)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeQueries) GetWebhookSubscription(ctx context.Context, id uuid.UUID) (sqlc.WebhookSubscription, error) {
+	sub, ok := f.subscriptions[id]
+	if !ok {
+		return sqlc.WebhookSubscription{}, pgx.ErrNoRows
+	}
+	return sub, nil
+}
+
+func (f *fakeQueries) CreateWebhookDelivery(ctx context.Context, arg sqlc.CreateWebhookDeliveryParams) (sqlc.WebhookDelivery, error) {
+	delivery := &sqlc.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: arg.SubscriptionID,
+		EventType:      arg.EventType,
+		Payload:        arg.Payload,
+		Status:         arg.Status,
+		HttpStatusCode: arg.HttpStatusCode,
+		ErrorMessage:   arg.ErrorMessage,
+		Attempts:       arg.Attempts,
+		NextRetryAt:    arg.NextRetryAt,
+		RedeliveryOf:   arg.RedeliveryOf,
+		LatencyMs:      arg.LatencyMs,
+		FailureReason:  arg.FailureReason,
+		CreatedAt:      time.Now(),
+	}
+	f.deliveries[delivery.ID] = delivery
+	return *delivery, nil
+}
+
+func (f *fakeQueries) UpdateWebhookDeliveryStatus(ctx context.Context, arg sqlc.UpdateWebhookDeliveryStatusParams) (sqlc.WebhookDelivery, error) {
+	delivery, ok := f.deliveries[arg.ID]
+	if !ok {
+		return sqlc.WebhookDelivery{}, pgx.ErrNoRows
+	}
+	delivery.Status = arg.Status
+	delivery.HttpStatusCode = arg.HttpStatusCode
+	delivery.ErrorMessage = arg.ErrorMessage
+	delivery.Attempts = arg.Attempts
+	delivery.NextRetryAt = arg.NextRetryAt
+	delivery.DeliveredAt = arg.DeliveredAt
+	delivery.LatencyMs = arg.LatencyMs
+	delivery.FailureReason = arg.FailureReason
+	return *delivery, nil
+}
+
+func (f *fakeQueries) ListPendingWebhookDeliveries(ctx context.Context, limitVal int32) ([]sqlc.WebhookDelivery, error) {
+	var result []sqlc.WebhookDelivery
+	for _, delivery := range f.deliveries {
+		if delivery.Status == DeliveryStatusPending {
+			result = append(result, *delivery)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeQueries) ListDeadLetterWebhookDeliveries(ctx context.Context, arg sqlc.ListDeadLetterWebhookDeliveriesParams) ([]sqlc.WebhookDelivery, error) {
+	var result []sqlc.WebhookDelivery
+	for _, delivery := range f.deliveries {
+		if delivery.Status == DeliveryStatusDeadLetter {
+			result = append(result, *delivery)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeQueries) GetWebhookDelivery(ctx context.Context, id uuid.UUID) (sqlc.WebhookDelivery, error) {
+	delivery, ok := f.deliveries[id]
+	if !ok {
+		return sqlc.WebhookDelivery{}, pgx.ErrNoRows
+	}
+	return *delivery, nil
+}
+
+func (f *fakeQueries) ListFailedWebhookDeliveries(ctx context.Context, arg sqlc.ListFailedWebhookDeliveriesParams) ([]sqlc.WebhookDelivery, error) {
+	var result []sqlc.WebhookDelivery
+	for _, delivery := range f.deliveries {
+		if delivery.SubscriptionID != arg.SubscriptionID {
+			continue
+		}
+		if delivery.Status != DeliveryStatusFailed && delivery.Status != DeliveryStatusDeadLetter {
+			continue
+		}
+		if delivery.CreatedAt.Before(arg.CreatedFrom) || !delivery.CreatedAt.Before(arg.CreatedTo) {
+			continue
+		}
+		result = append(result, *delivery)
+	}
+	return result, nil
+}
+
+// GetWebhookDeliveryStats groups deliveries like the SQL query, by status and failure reason
+func (f *fakeQueries) GetWebhookDeliveryStats(ctx context.Context, arg sqlc.GetWebhookDeliveryStatsParams) ([]sqlc.GetWebhookDeliveryStatsRow, error) {
+	groups := make(map[[2]string]*sqlc.GetWebhookDeliveryStatsRow)
+	var result []sqlc.GetWebhookDeliveryStatsRow
+	for _, delivery := range f.deliveries {
+		if delivery.SubscriptionID != arg.SubscriptionID ||
+			delivery.CreatedAt.Before(arg.CreatedFrom) || !delivery.CreatedAt.Before(arg.CreatedTo) {
+			continue
+		}
+
+		reason := ""
+		if delivery.Status != DeliveryStatusSuccess {
+			reason = delivery.FailureReason.String
+		}
+		key := [2]string{delivery.Status, reason}
+		if groups[key] == nil {
+			groups[key] = &sqlc.GetWebhookDeliveryStatsRow{Status: delivery.Status, FailureReason: reason}
+		}
+		groups[key].Deliveries++
+		if delivery.LatencyMs.Valid {
+			groups[key].TimedDeliveries++
+			groups[key].TotalLatencyMs += int64(delivery.LatencyMs.Int32)
+		}
+	}
+	for _, row := range groups {
+		result = append(result, *row)
+	}
+	return result, nil
+}
+
+func (f *fakeQueries) CreateWebhookSubscription(ctx context.Context, arg sqlc.CreateWebhookSubscriptionParams) (sqlc.WebhookSubscription, error) {
+	for _, sub := range f.subscriptions {
+		if sub.AgentID == arg.AgentID && sub.EventType == arg.EventType && sub.WebhookUrl == arg.WebhookUrl {
+			return sqlc.WebhookSubscription{}, &pgconn.PgError{Code: uniqueViolation}
+		}
+	}
+	sub := sqlc.WebhookSubscription{
+		ID:                        uuid.New(),
+		AgentID:                   arg.AgentID,
+		EventType:                 arg.EventType,
+		WebhookUrl:                arg.WebhookUrl,
+		Secret:                    arg.Secret,
+		IsActive:                  arg.IsActive,
+		CreatedAt:                 time.Now(),
+		UpdatedAt:                 time.Now(),
+		BatchSize:                 1,
+		BatchFlushIntervalSeconds: 5,
+	}
+	f.subscriptions[sub.ID] = sub
+	return sub, nil
+}
+
+func (f *fakeQueries) ListWebhookSubscriptions(ctx context.Context, arg sqlc.ListWebhookSubscriptionsParams) ([]sqlc.WebhookSubscription, error) {
+	var result []sqlc.WebhookSubscription
+	for _, sub := range f.subscriptions {
+		if sub.AgentID == arg.AgentID && (!arg.IsActive.Valid || sub.IsActive == arg.IsActive.Bool) {
+			result = append(result, sub)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeQueries) UpdateWebhookSubscription(ctx context.Context, arg sqlc.UpdateWebhookSubscriptionParams) (sqlc.WebhookSubscription, error) {
+	sub, ok := f.subscriptions[arg.ID]
+	if !ok {
+		return sqlc.WebhookSubscription{}, pgx.ErrNoRows
+	}
+	if arg.WebhookUrl.Valid {
+		sub.WebhookUrl = arg.WebhookUrl.String
+	}
+	if arg.EventType.Valid {
+		sub.EventType = arg.EventType.String
+	}
+	if arg.Secret.Valid {
+		sub.Secret = arg.Secret.String
+	}
+	if arg.IsActive.Valid {
+		sub.IsActive = arg.IsActive.Bool
+	}
+	if arg.BatchSize.Valid {
+		sub.BatchSize = arg.BatchSize.Int32
+	}
+	if arg.BatchFlushIntervalSeconds.Valid {
+		sub.BatchFlushIntervalSeconds = arg.BatchFlushIntervalSeconds.Int32
+	}
+	sub.UpdatedAt = time.Now()
+	f.subscriptions[sub.ID] = sub
+	return sub, nil
+}
+
+// DeleteWebhookSubscription removes the agent's subscription and cascades to its deliveries
+func (f *fakeQueries) DeleteWebhookSubscription(ctx context.Context, arg sqlc.DeleteWebhookSubscriptionParams) error {
+	sub, ok := f.subscriptions[arg.ID]
+	if !ok || sub.AgentID != arg.AgentID {
+		return nil
+	}
+	delete(f.subscriptions, arg.ID)
+	for id, delivery := range f.deliveries {
+		if delivery.SubscriptionID == arg.ID {
+			delete(f.deliveries, id)
+		}
+	}
+	return nil
+}
+
+// onlyDelivery returns the single recorded delivery
+func (f *fakeQueries) onlyDelivery(t *testing.T) *sqlc.WebhookDelivery {
+	t.Helper()
+	require.Len(t, f.deliveries, 1, "a delivery must be tracked in a single row across retries")
+	for _, delivery := range f.deliveries {
+		return delivery
+	}
+	return nil
+}
+
+func newTestSubscription(url string) sqlc.WebhookSubscription {
+	return sqlc.WebhookSubscription{
+		ID:         uuid.New(),
+		AgentID:    "test-agent-123",
+		EventType:  "payment.success",
+		WebhookUrl: url,
+		Secret:     testSecret,
+		IsActive:   true,
+	}
+}
+
+func newTestEvent() *WebhookEvent {
+	return &WebhookEvent{
+		EventType: "payment.success",
+		AgentID:   "test-agent-123",
+		Data:      map[string]interface{}{"amount": "10.00"},
+		Timestamp: time.Now(),
+	}
+}
+
+// newFlakyServer fails the first failures requests with 503, then returns 200
+func newFlakyServer(failures int32, calls *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestRetryPolicy_NextRetryDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, 10 * time.Minute}, // capped
+		{20, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, policy.NextRetryDelay(tt.attempts), "attempts=%d", tt.attempts)
+	}
+}
+
+func TestRetryFailedDeliveries_FailsTwiceThenSucceeds(t *testing.T) {
+	var calls atomic.Int32
+	server := newFlakyServer(2, &calls)
+	defer server.Close()
+
+	subscription := newTestSubscription(server.URL)
+	queries := newFakeQueries(subscription)
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	service := NewWebhookDeliveryServiceWithQueries(queries, server.Client(), policy, zap.NewNop())
+	ctx := context.Background()
+
+	// Attempt 1 fails and is scheduled for retry
+	require.NoError(t, service.DeliverEvent(ctx, newTestEvent()))
+	delivery := queries.onlyDelivery(t)
+	assert.Equal(t, DeliveryStatusPending, delivery.Status)
+	assert.Equal(t, int32(1), delivery.Attempts)
+	assert.Equal(t, int32(http.StatusServiceUnavailable), delivery.HttpStatusCode.Int32)
+	require.True(t, delivery.NextRetryAt.Valid)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), delivery.NextRetryAt.Time, 5*time.Second)
+
+	// Attempt 2 fails and backs off exponentially
+	retried, err := service.RetryFailedDeliveries(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, retried)
+	delivery = queries.onlyDelivery(t)
+	assert.Equal(t, DeliveryStatusPending, delivery.Status)
+	assert.Equal(t, int32(2), delivery.Attempts)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), delivery.NextRetryAt.Time, 5*time.Second)
+
+	// Attempt 3 succeeds
+	retried, err = service.RetryFailedDeliveries(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, retried)
+	delivery = queries.onlyDelivery(t)
+	assert.Equal(t, DeliveryStatusSuccess, delivery.Status)
+	assert.Equal(t, int32(3), delivery.Attempts)
+	assert.True(t, delivery.DeliveredAt.Valid)
+	assert.False(t, delivery.NextRetryAt.Valid)
+
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRetryFailedDeliveries_ExhaustsRetriesToDeadLetter(t *testing.T) {
+	var calls atomic.Int32
+	server := newFlakyServer(1000, &calls)
+	defer server.Close()
+
+	subscription := newTestSubscription(server.URL)
+	queries := newFakeQueries(subscription)
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	service := NewWebhookDeliveryServiceWithQueries(queries, server.Client(), policy, zap.NewNop())
+	ctx := context.Background()
+
+	require.NoError(t, service.DeliverEvent(ctx, newTestEvent()))
+	for i := 0; i < 2; i++ {
+		_, err := service.RetryFailedDeliveries(ctx)
+		require.NoError(t, err)
+	}
+
+	delivery := queries.onlyDelivery(t)
+	assert.Equal(t, DeliveryStatusDeadLetter, delivery.Status)
+	assert.Equal(t, int32(3), delivery.Attempts)
+	assert.False(t, delivery.NextRetryAt.Valid)
+	assert.Contains(t, delivery.ErrorMessage.String, "HTTP 503")
+
+	// Dead-lettered deliveries are no longer retried
+	retried, err := service.RetryFailedDeliveries(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, retried)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// ...but are listed for manual replay
+	deadLetters, err := service.ListDeadLetterDeliveries(ctx, nil, 100, 0)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, delivery.ID, deadLetters[0].ID)
+}
+
+func TestRedeliverWebhook_CreatesFreshDelivery(t *testing.T) {
+	var calls atomic.Int32
+	server := newFlakyServer(1000, &calls)
+	defer server.Close()
+
+	subscription := newTestSubscription(server.URL)
+	queries := newFakeQueries(subscription)
+	policy := RetryPolicy{MaxAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	service := NewWebhookDeliveryServiceWithQueries(queries, server.Client(), policy, zap.NewNop())
+	ctx := context.Background()
+
+	require.NoError(t, service.DeliverEvent(ctx, newTestEvent()))
+	_, err := service.RetryFailedDeliveries(ctx)
+	require.NoError(t, err)
+	original := *queries.onlyDelivery(t)
+	require.Equal(t, DeliveryStatusDeadLetter, original.Status)
+
+	redelivery, err := service.RedeliverWebhook(ctx, subscription.AgentID, original.ID)
+	require.NoError(t, err)
+
+	require.Len(t, queries.deliveries, 2, "redelivery must create a new row")
+	assert.NotEqual(t, original.ID, redelivery.ID)
+	assert.Equal(t, DeliveryStatusPending, redelivery.Status)
+	assert.Equal(t, int32(0), redelivery.Attempts)
+	assert.True(t, redelivery.NextRetryAt.Valid)
+	assert.Equal(t, original.Payload, redelivery.Payload)
+	assert.Equal(t, original.EventType, redelivery.EventType)
+	require.True(t, redelivery.RedeliveryOf.Valid)
+	assert.Equal(t, original.ID, uuid.UUID(redelivery.RedeliveryOf.Bytes))
+
+	// The original row is left untouched
+	assert.Equal(t, DeliveryStatusDeadLetter, queries.deliveries[original.ID].Status)
+	assert.Equal(t, int32(2), queries.deliveries[original.ID].Attempts)
+}
+
+func TestRedeliverWebhook_OtherAgentGetsNotFound(t *testing.T) {
+	subscription := newTestSubscription("https://merchant.example.com/webhooks")
+	queries := newFakeQueries(subscription)
+	service := NewWebhookDeliveryServiceWithQueries(queries, nil, DefaultRetryPolicy(), zap.NewNop())
+	ctx := context.Background()
+
+	original, err := queries.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+		SubscriptionID: subscription.ID,
+		EventType:      subscription.EventType,
+		Payload:        []byte(`{}`),
+		Status:         DeliveryStatusDeadLetter,
+		Attempts:       10,
+	})
+	require.NoError(t, err)
+
+	_, err = service.RedeliverWebhook(ctx, "other-agent", original.ID)
+	assert.ErrorIs(t, err, domain.ErrWebhookDeliveryNotFound)
+
+	_, err = service.RedeliverWebhook(ctx, subscription.AgentID, uuid.New())
+	assert.ErrorIs(t, err, domain.ErrWebhookDeliveryNotFound)
+
+	assert.Len(t, queries.deliveries, 1)
+}
+
+func TestRedeliverFailedWebhooks_TimeRange(t *testing.T) {
+	subscription := newTestSubscription("https://merchant.example.com/webhooks")
+	queries := newFakeQueries(subscription)
+	service := NewWebhookDeliveryServiceWithQueries(queries, nil, DefaultRetryPolicy(), zap.NewNop())
+	ctx := context.Background()
+
+	now := time.Now()
+	seed := func(status string, createdAt time.Time) {
+		delivery, err := queries.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+			SubscriptionID: subscription.ID,
+			EventType:      subscription.EventType,
+			Payload:        []byte(`{}`),
+			Status:         status,
+		})
+		require.NoError(t, err)
+		queries.deliveries[delivery.ID].CreatedAt = createdAt
+	}
+	seed(DeliveryStatusDeadLetter, now.Add(-2*time.Hour))
+	seed(DeliveryStatusFailed, now.Add(-time.Hour))
+	seed(DeliveryStatusSuccess, now.Add(-time.Hour))
+	seed(DeliveryStatusDeadLetter, now.Add(-48*time.Hour))
+
+	redeliveries, err := service.RedeliverFailedWebhooks(ctx, subscription.AgentID, subscription.ID, now.Add(-24*time.Hour), now)
+	require.NoError(t, err)
+	assert.Len(t, redeliveries, 2)
+	for _, redelivery := range redeliveries {
+		assert.Equal(t, DeliveryStatusPending, redelivery.Status)
+		assert.Equal(t, int32(0), redelivery.Attempts)
+	}
+
+	_, err = service.RedeliverFailedWebhooks(ctx, "other-agent", subscription.ID, now.Add(-24*time.Hour), now)
+	assert.ErrorIs(t, err, domain.ErrWebhookSubscriptionNotFound)
+
+	_, err = service.RedeliverFailedWebhooks(ctx, subscription.AgentID, subscription.ID, now, now.Add(-time.Hour))
+	assert.ErrorIs(t, err, domain.ErrInvalidTimeRange)
+}
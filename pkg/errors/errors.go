@@ -27,6 +27,7 @@ type PaymentError struct {
 	IsRetriable    bool
 	Category       ErrorCategory
 	Details        map[string]interface{}
+	Cause          error // Underlying error, if any
 }
 
 func (e *PaymentError) Error() string {
@@ -36,6 +37,21 @@ func (e *PaymentError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+// Unwrap returns the underlying error so errors.Is/As see through a PaymentError
+func (e *PaymentError) Unwrap() error {
+	return e.Cause
+}
+
+// IsDecline returns true for issuer declines (as opposed to gateway, network or request failures)
+func (e *PaymentError) IsDecline() bool {
+	switch e.Category {
+	case CategoryDeclined, CategoryInsufficientFunds, CategoryInvalidCard, CategoryExpiredCard, CategoryFraud:
+		return true
+	default:
+		return false
+	}
+}
+
 // NewPaymentError creates a new payment error
 func NewPaymentError(code, message string, category ErrorCategory, retriable bool) *PaymentError {
 	return &PaymentError{
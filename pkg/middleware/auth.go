@@ -0,0 +1,407 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultClockSkew is the tolerance applied to exp, nbf and iat when none is configured
+const DefaultClockSkew = 60 * time.Second
+
+// ServiceKey is one of a calling service's active token signing keys
+type ServiceKey struct {
+	ID  string // Matched against the token's kid header
+	Key *rsa.PublicKey
+}
+
+// ServiceKeyFunc returns the active public keys a calling service signs its tokens with
+type ServiceKeyFunc func(ctx context.Context, serviceID string) ([]ServiceKey, error)
+
+// MerchantAccessFunc reports whether a calling service may act for a merchant (agent_id)
+type MerchantAccessFunc func(ctx context.Context, serviceID, agentID string) (bool, error)
+
+// MerchantResolverFunc returns the merchant owning the resource a request names, such as the transaction a Capture
+// or Refund acts on. It returns "" when the request names no such resource or the resource doesn't exist.
+type MerchantResolverFunc func(ctx context.Context, req interface{}) (string, error)
+
+// errUnknownKeyID rejects a token whose kid names none of the service's active keys
+var errUnknownKeyID = errors.New("token kid matches no active key")
+
+// serviceIDKey is the context key holding the authenticated service ID
+type serviceIDKey struct{}
+
+// merchantIDKey is the context key holding the merchant the authenticated service was granted for the request
+type merchantIDKey struct{}
+
+// publicMethodPrefixes are callable without a token (probes and tooling)
+var publicMethodPrefixes = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+// ServiceClaims are the claims of a service JWT: the registered claims plus the granted scopes
+type ServiceClaims struct {
+	jwt.RegisteredClaims
+	Scope string `json:"scope,omitempty"` // Space-separated, e.g. "payment:read payment:refund"
+}
+
+// HasScope reports whether the token grants scope
+func (c *ServiceClaims) HasScope(scope string) bool {
+	for _, granted := range strings.Fields(c.Scope) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthConfig configures service JWT validation
+type AuthConfig struct {
+	Keys                ServiceKeyFunc
+	ClockSkew           time.Duration        // Tolerance for exp/nbf/iat (0 = DefaultClockSkew)
+	Now                 func() time.Time     // Clock used for validation (nil = time.Now)
+	MethodScopes        map[string]string    // Scope each full method name requires (nil = scopes not enforced; unlisted methods are denied)
+	Access              MerchantAccessFunc   // Merchant grants every request is checked against (nil = every merchant is denied)
+	ResolveMerchant     MerchantResolverFunc // Owner of the resource a request names (nil = only agent_id identifies the merchant)
+	MerchantlessMethods map[string]bool      // Full method names that act for no single merchant and need no grant
+}
+
+// ServiceIDFromContext returns the service ID the auth interceptor authenticated
+func ServiceIDFromContext(ctx context.Context) (string, bool) {
+	serviceID, ok := ctx.Value(serviceIDKey{}).(string)
+	return serviceID, ok
+}
+
+// MerchantIDFromContext returns the merchant the auth interceptor checked the request's grant for:
+// the owner of the resource it names, otherwise its agent_id
+func MerchantIDFromContext(ctx context.Context) (string, bool) {
+	merchantID, ok := ctx.Value(merchantIDKey{}).(string)
+	return merchantID, ok
+}
+
+// NewAuthInterceptor validates the RS256 service JWT sent as "authorization: Bearer <token>".
+// The token's issuer names the calling service. A kid header selects one of the service's active keys;
+// without one, each active key is tried. Tokens must carry exp;
+// exp and nbf are honored with the configured clock skew, and an iat further in the future than the skew is rejected.
+// Every rejection is UNAUTHENTICATED and logged with the claimed service ID.
+// With MethodScopes set, a valid token lacking the method's scope is PERMISSION_DENIED, naming the missing scope.
+// Every other method acts for a merchant: its agent_id and the owner of the resource it names (ResolveMerchant) must
+// each be granted to the service by Access, and a request naming no merchant, or any merchant without Access,
+// is PERMISSION_DENIED. Only MerchantlessMethods skip the grant check.
+func NewAuthInterceptor(cfg AuthConfig, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	parseToken := newTokenParser(cfg)
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		for _, prefix := range publicMethodPrefixes {
+			if strings.HasPrefix(info.FullMethod, prefix) {
+				return handler(ctx, req)
+			}
+		}
+
+		tokenString, err := bearerToken(ctx)
+		if err != nil {
+			logger.Warn("Rejected unauthenticated request",
+				zap.String("method", info.FullMethod),
+				zap.Error(err),
+			)
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		claims, err := parseToken(ctx, tokenString)
+		if err != nil {
+			logger.Warn("Rejected service token",
+				zap.String("service_id", claims.Issuer),
+				zap.String("method", info.FullMethod),
+				zap.String("reason", RejectionReason(err)),
+				zap.Error(err),
+			)
+			return nil, status.Error(codes.Unauthenticated, "invalid service token: "+RejectionReason(err))
+		}
+
+		if cfg.MethodScopes != nil {
+			if err := checkScope(cfg.MethodScopes, info.FullMethod, claims); err != nil {
+				logger.Warn("Denied service call",
+					zap.String("service_id", claims.Issuer),
+					zap.String("method", info.FullMethod),
+					zap.Error(err),
+				)
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			}
+		}
+
+		ctx = context.WithValue(ctx, serviceIDKey{}, claims.Issuer)
+		if !cfg.MerchantlessMethods[info.FullMethod] {
+			merchantID, err := checkMerchantAccess(ctx, cfg, claims.Issuer, info.FullMethod, req, logger)
+			if err != nil {
+				return nil, err
+			}
+			ctx = context.WithValue(ctx, merchantIDKey{}, merchantID)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// checkMerchantAccess requires a grant for every merchant the request acts for: its agent_id and the owner of the
+// resource it names. It returns the merchant the request acts for (the owner, otherwise agent_id);
+// the returned error is a gRPC status.
+func checkMerchantAccess(ctx context.Context, cfg AuthConfig, serviceID, method string, req interface{}, logger *zap.Logger) (string, error) {
+	var merchants []string
+	if r, ok := req.(merchantIDRequest); ok && r.GetAgentId() != "" {
+		merchants = append(merchants, r.GetAgentId())
+	}
+	if cfg.ResolveMerchant != nil {
+		owner, err := cfg.ResolveMerchant(ctx, req)
+		if err != nil {
+			logger.Error("Failed to resolve request merchant",
+				zap.String("service_id", serviceID),
+				zap.String("method", method),
+				zap.Error(err),
+			)
+			return "", status.Error(codes.Unavailable, "merchant access check failed")
+		}
+		if owner != "" && (len(merchants) == 0 || merchants[0] != owner) {
+			merchants = append(merchants, owner)
+		}
+	}
+
+	deny := func(reason string, agentID string) error {
+		logger.Warn("Denied service call for merchant",
+			zap.String("service_id", serviceID),
+			zap.String("agent_id", agentID),
+			zap.String("method", method),
+			zap.String("reason", reason),
+		)
+		return status.Error(codes.PermissionDenied, reason)
+	}
+	if len(merchants) == 0 {
+		return "", deny("request names no merchant the service has access to", "")
+	}
+	if cfg.Access == nil {
+		return "", deny("merchant access is not configured", merchants[0])
+	}
+
+	for _, agentID := range merchants {
+		allowed, err := cfg.Access(ctx, serviceID, agentID)
+		if err != nil {
+			logger.Error("Failed to check merchant access",
+				zap.String("service_id", serviceID),
+				zap.String("agent_id", agentID),
+				zap.Error(err),
+			)
+			return "", status.Error(codes.Unavailable, "merchant access check failed")
+		}
+		if !allowed {
+			return "", deny(fmt.Sprintf("service has no access to merchant %q", agentID), agentID)
+		}
+	}
+	return merchants[len(merchants)-1], nil
+}
+
+// NewHTTPAuth validates service JWTs on HTTP endpoints the way NewAuthInterceptor does on gRPC methods.
+// The returned wrapper requires a bearer token granting scope: a missing or invalid token is 401 and a token
+// without the scope is 403. Merchant grants depend on the resource, so handlers check cfg.Access themselves
+// with the service ID from ServiceIDFromContext.
+func NewHTTPAuth(cfg AuthConfig, logger *zap.Logger) func(scope string, next http.HandlerFunc) http.HandlerFunc {
+	parseToken := newTokenParser(cfg)
+
+	return func(scope string, next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			tokenString, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !found || tokenString == "" {
+				logger.Warn("Rejected unauthenticated request",
+					zap.String("path", r.URL.Path),
+				)
+				http.Error(w, "missing bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			claims, err := parseToken(r.Context(), tokenString)
+			if err != nil {
+				logger.Warn("Rejected service token",
+					zap.String("service_id", claims.Issuer),
+					zap.String("path", r.URL.Path),
+					zap.String("reason", RejectionReason(err)),
+					zap.Error(err),
+				)
+				http.Error(w, "invalid service token: "+RejectionReason(err), http.StatusUnauthorized)
+				return
+			}
+
+			if !claims.HasScope(scope) {
+				logger.Warn("Denied service call",
+					zap.String("service_id", claims.Issuer),
+					zap.String("path", r.URL.Path),
+					zap.String("scope", scope),
+				)
+				http.Error(w, fmt.Sprintf("missing scope %q", scope), http.StatusForbidden)
+				return
+			}
+
+			next(w, r.WithContext(context.WithValue(r.Context(), serviceIDKey{}, claims.Issuer)))
+		}
+	}
+}
+
+// newTokenParser returns a function that validates a service token and returns its claims.
+// The claims are returned even on error so rejections can be logged with the claimed service.
+func newTokenParser(cfg AuthConfig) func(ctx context.Context, tokenString string) (*ServiceClaims, error) {
+	skew := cfg.ClockSkew
+	if skew <= 0 {
+		skew = DefaultClockSkew
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithLeeway(skew),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithTimeFunc(now),
+	)
+
+	return func(ctx context.Context, tokenString string) (*ServiceClaims, error) {
+		claims := &ServiceClaims{}
+		_, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			if claims.Issuer == "" {
+				return nil, errors.New("token has no issuer")
+			}
+			keys, err := cfg.Keys(ctx, claims.Issuer)
+			if err != nil {
+				return nil, err
+			}
+			return verificationKey(token, keys)
+		})
+		return claims, err
+	}
+}
+
+// VerifyServiceToken validates a service token as the interceptors do, for diagnosing rejected tokens.
+// The token's claims are returned even when it is rejected.
+func VerifyServiceToken(ctx context.Context, cfg AuthConfig, token string) (*ServiceClaims, error) {
+	return newTokenParser(cfg)(ctx, token)
+}
+
+// verificationKey picks the key named by the token's kid, or offers every active key when there is none
+func verificationKey(token *jwt.Token, keys []ServiceKey) (interface{}, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("service has no active keys")
+	}
+
+	if kid, _ := token.Header["kid"].(string); kid != "" {
+		for _, key := range keys {
+			if key.ID == kid {
+				return key.Key, nil
+			}
+		}
+		return nil, errUnknownKeyID
+	}
+
+	set := jwt.VerificationKeySet{Keys: make([]jwt.VerificationKey, len(keys))}
+	for i, key := range keys {
+		set.Keys[i] = key.Key
+	}
+	return set, nil
+}
+
+// checkScope returns an error naming the scope the token lacks for the method
+func checkScope(methodScopes map[string]string, method string, claims *ServiceClaims) error {
+	required, ok := methodScopes[method]
+	if !ok {
+		return fmt.Errorf("method %s is not available to service tokens", method)
+	}
+	if !claims.HasScope(required) {
+		return fmt.Errorf("missing scope %q", required)
+	}
+	return nil
+}
+
+// bearerToken extracts the token from the authorization metadata
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", errors.New("missing authorization token")
+	}
+
+	token, found := strings.CutPrefix(values[0], "Bearer ")
+	if !found || token == "" {
+		return "", errors.New("authorization must be a bearer token")
+	}
+	return token, nil
+}
+
+// RejectionReason summarizes a validation error for the client without echoing key material
+func RejectionReason(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "expired"
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return "not yet valid"
+	case errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return "issued in the future"
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return "missing required claim"
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return "bad signature"
+	case errors.Is(err, errUnknownKeyID):
+		return "unknown key"
+	default:
+		return "malformed or unknown service"
+	}
+}
+
+// StaticServiceKeys looks service keys up in a fixed map (one key per service, no kid)
+func StaticServiceKeys(keys map[string]*rsa.PublicKey) ServiceKeyFunc {
+	return func(ctx context.Context, serviceID string) ([]ServiceKey, error) {
+		key, ok := keys[serviceID]
+		if !ok {
+			return nil, fmt.Errorf("unknown service %q", serviceID)
+		}
+		return []ServiceKey{{Key: key}}, nil
+	}
+}
+
+// LoadServiceKeys reads one PEM public key per service from dir, named <service_id>.pem
+func LoadServiceKeys(dir string) (map[string]*rsa.PublicKey, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(paths))
+	for _, path := range paths {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service key %s: %w", path, err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse service key %s: %w", path, err)
+		}
+		keys[strings.TrimSuffix(filepath.Base(path), ".pem")] = key
+	}
+	return keys, nil
+}
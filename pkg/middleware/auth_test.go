@@ -0,0 +1,285 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthInterceptor_ExpiryAndClockSkew(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	core, logs := observer.New(zap.WarnLevel)
+	interceptor := NewAuthInterceptor(AuthConfig{
+		Keys:      StaticServiceKeys(map[string]*rsa.PublicKey{"pos-service": &key.PublicKey}),
+		ClockSkew: 60 * time.Second,
+		Now:       func() time.Time { return now },
+
+		MerchantlessMethods: map[string]bool{"/payment.v1.PaymentService/Sale": true},
+	}, zap.New(core))
+
+	sign := func(claims jwt.RegisteredClaims) string {
+		claims.Issuer = "pos-service"
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+		require.NoError(t, err)
+		return token
+	}
+	at := func(offset time.Duration) *jwt.NumericDate { return jwt.NewNumericDate(now.Add(offset)) }
+	call := func(token string) (string, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/payment.v1.PaymentService/Sale"},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				serviceID, _ := ServiceIDFromContext(ctx)
+				return serviceID, nil
+			})
+		if err != nil {
+			return "", err
+		}
+		return resp.(string), nil
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.RegisteredClaims
+		reason string // empty = accepted
+	}{
+		{"valid", jwt.RegisteredClaims{IssuedAt: at(-time.Minute), ExpiresAt: at(5 * time.Minute)}, ""},
+		{"expired", jwt.RegisteredClaims{IssuedAt: at(-10 * time.Minute), ExpiresAt: at(-2 * time.Minute)}, "expired"},
+		{"expired within skew", jwt.RegisteredClaims{IssuedAt: at(-5 * time.Minute), ExpiresAt: at(-30 * time.Second)}, ""},
+		{"not yet valid", jwt.RegisteredClaims{NotBefore: at(2 * time.Minute), ExpiresAt: at(10 * time.Minute)}, "not yet valid"},
+		{"not yet valid within skew", jwt.RegisteredClaims{NotBefore: at(30 * time.Second), ExpiresAt: at(10 * time.Minute)}, ""},
+		{"issued in the future", jwt.RegisteredClaims{IssuedAt: at(2 * time.Minute), ExpiresAt: at(10 * time.Minute)}, "issued in the future"},
+		{"issued slightly ahead within skew", jwt.RegisteredClaims{IssuedAt: at(45 * time.Second), ExpiresAt: at(10 * time.Minute)}, ""},
+		{"no expiry", jwt.RegisteredClaims{IssuedAt: at(-time.Minute)}, "missing required claim"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceID, err := call(sign(tt.claims))
+			if tt.reason == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "pos-service", serviceID)
+				return
+			}
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			assert.Contains(t, status.Convert(err).Message(), tt.reason)
+		})
+	}
+
+	rejected := logs.FilterMessage("Rejected service token").All()
+	require.NotEmpty(t, rejected)
+	assert.Equal(t, "pos-service", rejected[0].ContextMap()["service_id"], "rejections are logged with the service ID")
+}
+
+func TestAuthInterceptor_RejectsMissingAndForeignTokens(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	interceptor := NewAuthInterceptor(AuthConfig{
+		Keys: StaticServiceKeys(map[string]*rsa.PublicKey{"pos-service": &key.PublicKey}),
+	}, zap.NewNop())
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/payment.v1.PaymentService/Sale"}
+
+	_, err = interceptor(context.Background(), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Issuer:    "pos-service",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}).SignedString(other)
+	require.NoError(t, err)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+forged))
+	_, err = interceptor(ctx, nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Health checks need no token
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+	assert.NoError(t, err)
+}
+
+func TestAuthInterceptor_EnforcesMethodScopes(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	interceptor := NewAuthInterceptor(AuthConfig{
+		Keys: StaticServiceKeys(map[string]*rsa.PublicKey{"reporting-service": &key.PublicKey}),
+		MethodScopes: map[string]string{
+			"/payment.v1.PaymentService/GetTransaction": "payment:read",
+			"/payment.v1.PaymentService/Refund":         "payment:refund",
+		},
+		MerchantlessMethods: map[string]bool{"/payment.v1.PaymentService/GetTransaction": true},
+	}, zap.NewNop())
+
+	readOnly, err := jwt.NewWithClaims(jwt.SigningMethodRS256, ServiceClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "reporting-service",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+		Scope: "payment:read",
+	}).SignedString(key)
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+readOnly))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	call := func(method string) error {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	assert.NoError(t, call("/payment.v1.PaymentService/GetTransaction"))
+
+	err = call("/payment.v1.PaymentService/Refund")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), `missing scope "payment:refund"`)
+
+	err = call("/payment.v1.PaymentService/Sale")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "methods without a mapped scope are denied")
+}
+
+// merchantRequest names a merchant directly (agent_id) and/or through a transaction it acts on
+type merchantRequest struct {
+	agentID       string
+	transactionID string
+}
+
+func (r *merchantRequest) GetAgentId() string { return r.agentID }
+
+func TestAuthInterceptor_MerchantAccess(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Issuer:    "pos-service",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}).SignedString(key)
+	require.NoError(t, err)
+
+	owners := map[string]string{"tx-a": "merchant-a", "tx-b": "merchant-b"}
+	grants := map[string]bool{"merchant-a": true}
+	accessChecks := 0
+	access := func(ctx context.Context, serviceID, agentID string) (bool, error) {
+		accessChecks++
+		if agentID == "merchant-down" {
+			return false, errors.New("database unavailable")
+		}
+		return serviceID == "pos-service" && grants[agentID], nil
+	}
+	resolve := func(ctx context.Context, req interface{}) (string, error) {
+		return owners[req.(*merchantRequest).transactionID], nil
+	}
+
+	call := func(cfg AuthConfig, method string, req *merchantRequest) error {
+		cfg.Keys = StaticServiceKeys(map[string]*rsa.PublicKey{"pos-service": &key.PublicKey})
+		interceptor := NewAuthInterceptor(cfg, zap.NewNop())
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil })
+		return err
+	}
+	cfg := AuthConfig{Access: access, ResolveMerchant: resolve}
+
+	tests := []struct {
+		name string
+		cfg  AuthConfig
+		req  *merchantRequest
+		want codes.Code
+	}{
+		{"granted agent_id", cfg, &merchantRequest{agentID: "merchant-a"}, codes.OK},
+		{"agent_id without a grant", cfg, &merchantRequest{agentID: "merchant-b"}, codes.PermissionDenied},
+		{"transaction of a granted merchant", cfg, &merchantRequest{transactionID: "tx-a"}, codes.OK},
+		{"transaction of another merchant", cfg, &merchantRequest{transactionID: "tx-b"}, codes.PermissionDenied},
+		{"granted agent_id with another merchant's transaction", cfg, &merchantRequest{agentID: "merchant-a", transactionID: "tx-b"}, codes.PermissionDenied},
+		{"unknown transaction names no merchant", cfg, &merchantRequest{transactionID: "tx-unknown"}, codes.PermissionDenied},
+		{"no agent_id without a resolver", AuthConfig{Access: access}, &merchantRequest{transactionID: "tx-a"}, codes.PermissionDenied},
+		{"no access func fails closed", AuthConfig{ResolveMerchant: resolve}, &merchantRequest{agentID: "merchant-a"}, codes.PermissionDenied},
+		{"failed grant check", cfg, &merchantRequest{agentID: "merchant-down"}, codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := call(tt.cfg, "/payment.v1.PaymentService/Capture", tt.req)
+			assert.Equal(t, tt.want, status.Code(err), "%v", err)
+		})
+	}
+
+	// Every call checks the grant, so a revoked grant is denied on the next request
+	accessChecks = 0
+	require.NoError(t, call(cfg, "/payment.v1.PaymentService/Capture", &merchantRequest{agentID: "merchant-a"}))
+	grants["merchant-a"] = false
+	err = call(cfg, "/payment.v1.PaymentService/Capture", &merchantRequest{agentID: "merchant-a"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, 2, accessChecks)
+
+	// Methods that act for no merchant skip the grant check, even without an access func
+	merchantless := AuthConfig{MerchantlessMethods: map[string]bool{"/payment.v1.PaymentService/ClassifyDeclineCode": true}}
+	assert.NoError(t, call(merchantless, "/payment.v1.PaymentService/ClassifyDeclineCode", &merchantRequest{}))
+	err = call(merchantless, "/payment.v1.PaymentService/Capture", &merchantRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestHTTPAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	auth := NewHTTPAuth(AuthConfig{
+		Keys: StaticServiceKeys(map[string]*rsa.PublicKey{"pos-service": &key.PublicKey}),
+	}, zap.NewNop())
+	handler := auth("payment:read", func(w http.ResponseWriter, r *http.Request) {
+		serviceID, _ := ServiceIDFromContext(r.Context())
+		w.Write([]byte(serviceID))
+	})
+
+	sign := func(scope string, expiresIn time.Duration) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, ServiceClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    "pos-service",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+			},
+			Scope: scope,
+		}).SignedString(key)
+		require.NoError(t, err)
+		return token
+	}
+	call := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/tx-1/status", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	w := call("Bearer " + sign("payment:read", time.Minute))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "pos-service", w.Body.String(), "the handler sees the calling service")
+
+	assert.Equal(t, http.StatusUnauthorized, call("").Code)
+	assert.Equal(t, http.StatusUnauthorized, call(sign("payment:read", time.Minute)).Code, "not a bearer token")
+
+	w = call("Bearer " + sign("payment:read", -time.Minute))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "expired")
+
+	w = call("Bearer " + sign("payment:refund", time.Minute))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `missing scope "payment:read"`)
+}
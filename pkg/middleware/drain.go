@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Drainer tracks in-flight payment RPCs and HTTP requests (cron jobs, Browser Post callbacks) so shutdown can let
+// them finish. Once draining starts, new RPCs to the tracked services are rejected with UNAVAILABLE and new HTTP
+// requests with 503 (clients and the scheduler retry on another instance) while the running ones, which may be
+// mid-way through an EPX call, complete.
+type Drainer struct {
+	services []string // "/package.Service/" prefixes of the tracked methods
+	mu       sync.RWMutex
+	draining bool
+	active   sync.WaitGroup
+}
+
+// NewDrainer creates a drainer for the named gRPC services (e.g. "payment.v1.PaymentService")
+func NewDrainer(serviceNames ...string) *Drainer {
+	services := make([]string, len(serviceNames))
+	for i, name := range serviceNames {
+		services[i] = "/" + name + "/"
+	}
+	return &Drainer{services: services}
+}
+
+func (d *Drainer) tracks(fullMethod string) bool {
+	for _, prefix := range d.services {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// begin registers an operation, or reports false once draining has started
+func (d *Drainer) begin() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.draining {
+		return false
+	}
+	d.active.Add(1)
+	return true
+}
+
+// Drain stops admitting new operations and waits for the in-flight ones to finish, or for ctx to end
+func (d *Drainer) Drain(ctx context.Context) error {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// UnaryServerInterceptor counts the tracked services' RPCs as in flight, rejecting them with UNAVAILABLE once
+// Drain has been called. RPCs to other services pass through.
+func (d *Drainer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if !d.tracks(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		if !d.begin() {
+			return nil, status.Error(codes.Unavailable, "server is shutting down")
+		}
+		defer d.active.Done()
+
+		return handler(ctx, req)
+	}
+}
+
+// HTTPHandlerFunc counts the handler's requests as in flight, rejecting them with 503 once Drain has been called
+func (d *Drainer) HTTPHandlerFunc(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !d.begin() {
+			w.Header().Set("Connection", "close")
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		defer d.active.Done()
+
+		handler(w, r)
+	}
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDrainer_FinishesInFlightAndRejectsNew(t *testing.T) {
+	drainer := NewDrainer("payment.v1.PaymentService")
+	interceptor := drainer.UnaryServerInterceptor()
+	capture := &grpc.UnaryServerInfo{FullMethod: "/payment.v1.PaymentService/Capture"}
+
+	// A capture that's waiting on EPX when shutdown starts
+	started, release := make(chan struct{}), make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		_, err := interceptor(context.Background(), nil, capture, func(ctx context.Context, req interface{}) (interface{}, error) {
+			close(started)
+			<-release
+			return "captured", nil
+		})
+		result <- err
+	}()
+	<-started
+
+	drained := make(chan error, 1)
+	go func() { drained <- drainer.Drain(context.Background()) }()
+
+	// Drain has begun once new payment RPCs are turned away
+	require.Eventually(t, func() bool {
+		_, err := interceptor(context.Background(), nil, capture, okHandler)
+		return status.Code(err) == codes.Unavailable
+	}, time.Second, time.Millisecond)
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/agent.v1.AgentService/GetMerchant"}, okHandler)
+	assert.NoError(t, err, "untracked services aren't drained")
+
+	select {
+	case <-drained:
+		t.Fatal("drain finished while a capture was in flight")
+	default:
+	}
+
+	close(release)
+	assert.NoError(t, <-result, "the in-flight capture completes")
+	assert.NoError(t, <-drained)
+}
+
+func TestDrainer_GivesUpAtDeadline(t *testing.T) {
+	drainer := NewDrainer("payment.v1.PaymentService")
+	interceptor := drainer.UnaryServerInterceptor()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	go func() {
+		_, _ = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/payment.v1.PaymentService/Sale"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, drainer.Drain(ctx), context.DeadlineExceeded)
+}
+
+func TestDrainer_WaitsForHTTPHandlers(t *testing.T) {
+	drainer := NewDrainer("payment.v1.PaymentService")
+
+	// A cron job that's still running when shutdown starts
+	started, release := make(chan struct{}), make(chan struct{})
+	cron := drainer.HTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-started:
+		default:
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	running := httptest.NewRecorder()
+	finished := make(chan struct{})
+	go func() {
+		cron(running, httptest.NewRequest(http.MethodPost, "/cron/process-billing", nil))
+		close(finished)
+	}()
+	<-started
+
+	drained := make(chan error, 1)
+	go func() { drained <- drainer.Drain(context.Background()) }()
+
+	require.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		cron(rec, httptest.NewRequest(http.MethodPost, "/cron/process-billing", nil))
+		return rec.Code == http.StatusServiceUnavailable
+	}, time.Second, time.Millisecond, "new requests are turned away")
+
+	select {
+	case <-drained:
+		t.Fatal("drain finished while a cron job was running")
+	default:
+	}
+
+	close(release)
+	<-finished
+	assert.Equal(t, http.StatusOK, running.Code, "the running job completes")
+	assert.NoError(t, <-drained)
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// merchantLimitsTTL is how long a merchant's loaded limits are used before they're reloaded
+	merchantLimitsTTL = time.Minute
+	// merchantLimiterIdle is how long an unused limiter is kept; by then its bucket has refilled
+	merchantLimiterIdle = 10 * time.Minute
+)
+
+// MerchantLimitsFunc loads a merchant's configured request rate and burst
+type MerchantLimitsFunc func(ctx context.Context, merchantID string) (requestsPerSecond float64, burst int, err error)
+
+// merchantIDRequest is implemented by every generated request message with an agent_id field
+type merchantIDRequest interface {
+	GetAgentId() string
+}
+
+type merchantLimiter struct {
+	limiter  *rate.Limiter
+	loadedAt time.Time
+	usedAt   time.Time
+}
+
+// limiterKey identifies a bucket: the merchant and the authenticated service acting for it
+type limiterKey struct {
+	serviceID  string
+	merchantID string
+}
+
+// MerchantRateLimiter applies a token bucket per calling service and merchant, sized from the merchant's own configuration
+type MerchantRateLimiter struct {
+	limits    MerchantLimitsFunc
+	limiters  map[limiterKey]*merchantLimiter
+	mu        sync.Mutex
+	ttl       time.Duration
+	idle      time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMerchantRateLimiter creates a per-merchant rate limiter
+func NewMerchantRateLimiter(limits MerchantLimitsFunc) *MerchantRateLimiter {
+	return &MerchantRateLimiter{
+		limits:   limits,
+		limiters: make(map[limiterKey]*merchantLimiter),
+		ttl:      merchantLimitsTTL,
+		idle:     merchantLimiterIdle,
+		now:      time.Now,
+	}
+}
+
+// Allow reports whether the service may make a request for the merchant now.
+// Limits that can't be loaded fail closed: the error is returned unless earlier limits are still cached.
+func (rl *MerchantRateLimiter) Allow(ctx context.Context, serviceID, merchantID string) (bool, error) {
+	limiter, err := rl.getLimiter(ctx, limiterKey{serviceID: serviceID, merchantID: merchantID})
+	if err != nil {
+		return false, err
+	}
+	return limiter.AllowN(rl.now(), 1), nil
+}
+
+// getLimiter returns the bucket's limiter, (re)loading the merchant's limits when they're missing or stale.
+// Reloads keep the bucket's current tokens so a config refresh doesn't hand out a fresh burst.
+func (rl *MerchantRateLimiter) getLimiter(ctx context.Context, key limiterKey) (*rate.Limiter, error) {
+	rl.mu.Lock()
+	rl.evictIdle()
+	entry, exists := rl.limiters[key]
+	if exists {
+		entry.usedAt = rl.now()
+	}
+	fresh := exists && rl.now().Sub(entry.loadedAt) < rl.ttl
+	rl.mu.Unlock()
+	if fresh {
+		return entry.limiter, nil
+	}
+
+	rps, burst, err := rl.limits(ctx, key.merchantID)
+	if err != nil {
+		if exists {
+			return entry.limiter, nil
+		}
+		return nil, err
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if entry, exists = rl.limiters[key]; exists {
+		entry.limiter.SetLimitAt(rl.now(), rate.Limit(rps))
+		entry.limiter.SetBurstAt(rl.now(), burst)
+		entry.loadedAt = rl.now()
+		return entry.limiter, nil
+	}
+
+	entry = &merchantLimiter{limiter: rate.NewLimiter(rate.Limit(rps), burst), loadedAt: rl.now(), usedAt: rl.now()}
+	rl.limiters[key] = entry
+	return entry.limiter, nil
+}
+
+// evictIdle drops limiters unused for the idle period, at most once per period. Callers hold rl.mu.
+func (rl *MerchantRateLimiter) evictIdle() {
+	now := rl.now()
+	if now.Sub(rl.lastSweep) < rl.idle {
+		return
+	}
+	rl.lastSweep = now
+	for key, entry := range rl.limiters {
+		if now.Sub(entry.usedAt) >= rl.idle {
+			delete(rl.limiters, key)
+		}
+	}
+}
+
+// UnaryServerInterceptor throttles a merchant's requests with RESOURCE_EXHAUSTED once the calling service passes
+// the merchant's rate. The merchant is the one the auth interceptor granted the service for the request; without
+// authentication configured it's the request's agent_id. Requests acting for no merchant are left to the per-IP
+// limits, and limits that can't be loaded are UNAVAILABLE.
+func (rl *MerchantRateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		serviceID, authenticated := ServiceIDFromContext(ctx)
+		merchantID, _ := MerchantIDFromContext(ctx)
+		if r, ok := req.(merchantIDRequest); ok && !authenticated {
+			merchantID = r.GetAgentId()
+		}
+
+		if merchantID != "" {
+			allowed, err := rl.Allow(ctx, serviceID, merchantID)
+			if err != nil {
+				return nil, status.Error(codes.Unavailable, "merchant rate limit unavailable")
+			}
+			if !allowed {
+				return nil, status.Error(codes.ResourceExhausted, "merchant rate limit exceeded")
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
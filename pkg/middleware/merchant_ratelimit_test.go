@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type agentRequest struct{ agentID string }
+
+func (r agentRequest) GetAgentId() string { return r.agentID }
+
+func okHandler(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+func TestMerchantRateLimiter_ThrottlesOnlyTheMerchantAtItsCap(t *testing.T) {
+	limits := map[string][2]float64{
+		"merchant-slow": {1, 2},
+		"merchant-fast": {100, 100},
+	}
+	rl := NewMerchantRateLimiter(func(ctx context.Context, merchantID string) (float64, int, error) {
+		l, ok := limits[merchantID]
+		if !ok {
+			return 0, 0, errors.New("unknown merchant")
+		}
+		return l[0], int(l[1]), nil
+	})
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+
+	interceptor := rl.UnaryServerInterceptor()
+	call := func(req interface{}) error {
+		_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/payment.v1.PaymentService/Sale"}, okHandler)
+		return err
+	}
+
+	// Burst of 2, then throttled
+	require.NoError(t, call(agentRequest{"merchant-slow"}))
+	require.NoError(t, call(agentRequest{"merchant-slow"}))
+	err := call(agentRequest{"merchant-slow"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Other merchants and requests without a merchant are unaffected
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, call(agentRequest{"merchant-fast"}))
+	}
+	assert.NoError(t, call(struct{}{}))
+	assert.NoError(t, call(agentRequest{""}))
+
+	// Limits that can't be loaded fail closed
+	assert.Equal(t, codes.Unavailable, status.Code(call(agentRequest{"merchant-unknown"})))
+
+	// Tokens refill at the merchant's rate
+	now = now.Add(time.Second)
+	assert.NoError(t, call(agentRequest{"merchant-slow"}))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call(agentRequest{"merchant-slow"})))
+}
+
+func TestMerchantRateLimiter_ReloadsLimits(t *testing.T) {
+	burst := 1
+	rl := NewMerchantRateLimiter(func(ctx context.Context, merchantID string) (float64, int, error) {
+		return 1, burst, nil
+	})
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	allow := func() bool {
+		allowed, err := rl.Allow(context.Background(), "service-1", "merchant-1")
+		require.NoError(t, err)
+		return allowed
+	}
+
+	assert.True(t, allow())
+	assert.False(t, allow())
+
+	// A raised burst only takes effect once the cached limits expire
+	burst = 3
+	now = now.Add(2 * time.Second)
+	assert.True(t, allow())
+	assert.False(t, allow(), "cached burst of 1 still applies")
+
+	// The reload keeps the bucket's tokens; the larger bucket then fills up at the merchant's rate
+	now = now.Add(merchantLimitsTTL)
+	assert.True(t, allow())
+	now = now.Add(3 * time.Second)
+	for i := 0; i < 3; i++ {
+		assert.True(t, allow())
+	}
+	assert.False(t, allow())
+}
+
+func TestMerchantRateLimiter_KeysOnTheAuthenticatedPrincipal(t *testing.T) {
+	rl := NewMerchantRateLimiter(func(ctx context.Context, merchantID string) (float64, int, error) {
+		return 1, 1, nil
+	})
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+
+	interceptor := rl.UnaryServerInterceptor()
+	call := func(serviceID, merchantID string, req interface{}) error {
+		ctx := context.WithValue(context.Background(), serviceIDKey{}, serviceID)
+		if merchantID != "" {
+			ctx = context.WithValue(ctx, merchantIDKey{}, merchantID)
+		}
+		_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/payment.v1.PaymentService/Refund"}, okHandler)
+		return err
+	}
+
+	// A request naming only a transaction is limited as the merchant the service was granted for it
+	require.NoError(t, call("service-1", "merchant-1", struct{}{}))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("service-1", "merchant-1", struct{}{})))
+
+	// A spoofed agent_id doesn't move the request to another bucket
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("service-1", "merchant-1", agentRequest{"merchant-2"})))
+
+	// Another service acting for the merchant has its own bucket
+	assert.NoError(t, call("service-2", "merchant-1", struct{}{}))
+
+	// Merchantless methods aren't limited per merchant
+	assert.NoError(t, call("service-1", "", agentRequest{"merchant-1"}))
+}
+
+func TestMerchantRateLimiter_EvictsIdleLimiters(t *testing.T) {
+	rl := NewMerchantRateLimiter(func(ctx context.Context, merchantID string) (float64, int, error) {
+		return 1, 1, nil
+	})
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 100; i++ {
+		_, err := rl.Allow(ctx, "service-1", fmt.Sprintf("merchant-%d", i))
+		require.NoError(t, err)
+	}
+	assert.Len(t, rl.limiters, 100)
+
+	// Kept busy, merchant-0 survives the sweep; the rest are dropped
+	now = now.Add(merchantLimiterIdle / 2)
+	_, err := rl.Allow(ctx, "service-1", "merchant-0")
+	require.NoError(t, err)
+	now = now.Add(merchantLimiterIdle / 2)
+	_, err = rl.Allow(ctx, "service-1", "merchant-0")
+	require.NoError(t, err)
+	assert.Len(t, rl.limiters, 1)
+	assert.Contains(t, rl.limiters, limiterKey{serviceID: "service-1", merchantID: "merchant-0"})
+}
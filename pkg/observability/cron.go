@@ -0,0 +1,31 @@
+package observability
+
+import (
+	"net/http"
+	"time"
+)
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// CronHandler wraps a cron endpoint and records its run duration.
+// Runs answered with 2xx other than 206 (partial failure) count as successful.
+func CronHandler(job string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next(recorder, r)
+
+		success := recorder.status >= 200 && recorder.status < 300 && recorder.status != http.StatusPartialContent
+		ObserveCronRun(job, time.Since(start), success)
+	}
+}
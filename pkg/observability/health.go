@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// defaultCheckTimeout bounds each dependency check so a hung dependency can't stall the probe
+const defaultCheckTimeout = 2 * time.Second
+
 // HealthStatus represents the health status of the service
 type HealthStatus struct {
 	Status    string            `json:"status"`
@@ -16,38 +20,64 @@ type HealthStatus struct {
 	Checks    map[string]string `json:"checks"`
 }
 
+// DependencyCheck probes one downstream dependency. It must honor ctx, which carries the check timeout.
+type DependencyCheck func(ctx context.Context) error
+
 // HealthChecker manages health checks for the service
 type HealthChecker struct {
-	dbPool *pgxpool.Pool
+	dbPool  *pgxpool.Pool
+	checks  map[string]DependencyCheck
+	timeout time.Duration
 }
 
 // NewHealthChecker creates a new HealthChecker
 func NewHealthChecker(dbPool *pgxpool.Pool) *HealthChecker {
 	return &HealthChecker{
-		dbPool: dbPool,
+		dbPool:  dbPool,
+		checks:  map[string]DependencyCheck{},
+		timeout: defaultCheckTimeout,
 	}
 }
 
-// Check performs health checks and returns the status
+// AddCheck registers a dependency check reported under name
+func (h *HealthChecker) AddCheck(name string, check DependencyCheck) *HealthChecker {
+	h.checks[name] = check
+	return h
+}
+
+// Check runs the dependency checks in parallel and returns the status
 func (h *HealthChecker) Check(ctx context.Context) HealthStatus {
 	checks := make(map[string]string)
 	overallStatus := "healthy"
 
 	// Database health check
-	if h.dbPool != nil {
-		dbCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
-		defer cancel()
-
-		if err := h.dbPool.Ping(dbCtx); err != nil {
-			checks["database"] = "unhealthy: " + err.Error()
-			overallStatus = "unhealthy"
-		} else {
-			checks["database"] = "healthy"
-		}
-	} else {
+	if h.dbPool == nil {
 		checks["database"] = "not configured"
 	}
 
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range h.dependencyChecks() {
+		wg.Add(1)
+		go func(name string, check DependencyCheck) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+			err := check(checkCtx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				checks[name] = "unhealthy: " + err.Error()
+				overallStatus = "unhealthy"
+			} else {
+				checks[name] = "healthy"
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
 	return HealthStatus{
 		Status:    overallStatus,
 		Timestamp: time.Now(),
@@ -55,7 +85,20 @@ func (h *HealthChecker) Check(ctx context.Context) HealthStatus {
 	}
 }
 
+// dependencyChecks returns the registered checks plus the database ping
+func (h *HealthChecker) dependencyChecks() map[string]DependencyCheck {
+	all := make(map[string]DependencyCheck, len(h.checks)+1)
+	for name, check := range h.checks {
+		all[name] = check
+	}
+	if h.dbPool != nil {
+		all["database"] = h.dbPool.Ping
+	}
+	return all
+}
+
 // HealthHandler returns an HTTP handler for health checks
+// (503 with the per-dependency status when any check fails)
 func (h *HealthChecker) HealthHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		status := h.Check(r.Context())
@@ -0,0 +1,74 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func healthy(ctx context.Context) error { return nil }
+
+func serveReady(t *testing.T, h *HealthChecker) (int, HealthStatus) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.HealthHandler()(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	var status HealthStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	return rec.Code, status
+}
+
+func TestHealthHandler_AllHealthy(t *testing.T) {
+	h := NewHealthChecker(nil).
+		AddCheck("epx_key_exchange", healthy).
+		AddCheck("secret_manager", healthy)
+
+	code, status := serveReady(t, h)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "healthy", status.Status)
+	assert.Equal(t, map[string]string{
+		"database":         "not configured",
+		"epx_key_exchange": "healthy",
+		"secret_manager":   "healthy",
+	}, status.Checks)
+}
+
+func TestHealthHandler_DegradedDependency(t *testing.T) {
+	h := NewHealthChecker(nil).
+		AddCheck("epx_key_exchange", func(ctx context.Context) error { return errors.New("connection refused") }).
+		AddCheck("secret_manager", healthy)
+
+	code, status := serveReady(t, h)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unhealthy", status.Status)
+	assert.Equal(t, "unhealthy: connection refused", status.Checks["epx_key_exchange"])
+	assert.Equal(t, "healthy", status.Checks["secret_manager"])
+}
+
+func TestHealthHandler_HungDependencyTimesOut(t *testing.T) {
+	h := NewHealthChecker(nil).
+		AddCheck("secret_manager", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}).
+		AddCheck("epx_key_exchange", healthy)
+	h.timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	code, status := serveReady(t, h)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unhealthy: context deadline exceeded", status.Checks["secret_manager"])
+	assert.Equal(t, "healthy", status.Checks["epx_key_exchange"])
+}
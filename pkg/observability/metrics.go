@@ -35,8 +35,99 @@ var (
 			Help: "Number of gRPC requests currently being processed",
 		},
 	)
+
+	// Payment metrics (labeled by merchant tier, never merchant ID, to bound cardinality)
+	paymentTransactionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "payment_transactions_total",
+			Help: "Total number of recorded payment transactions",
+		},
+		[]string{"type", "status", "tier"},
+	)
+
+	epxRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "epx_request_duration_seconds",
+			Help:    "Duration of EPX Server Post ProcessTransaction calls in seconds",
+			Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 4, 8, 15, 30},
+		},
+		[]string{"transaction_type", "tier"},
+	)
+
+	// Webhook delivery attempts (first attempts and retries)
+	webhookDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Total number of webhook delivery attempts",
+		},
+		[]string{"event_type", "result"},
+	)
+
+	cronRunDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "cron_run_duration_seconds",
+			Help:    "Duration of cron endpoint runs in seconds",
+			Buckets: []float64{0.1, 0.5, 1, 5, 15, 30, 60, 120, 300},
+		},
+		[]string{"job", "result"},
+	)
+
+	// Cron runs skipped because another instance held the job's lock
+	cronRunsSkippedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cron_runs_skipped_total",
+			Help: "Total number of cron runs skipped because the job was already running",
+		},
+		[]string{"job"},
+	)
+)
+
+// Result label values
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
 )
 
+// tierLabel keeps the tier label non-empty for agents created before tiers existed
+func tierLabel(tier string) string {
+	if tier == "" {
+		return "unknown"
+	}
+	return tier
+}
+
+func resultLabel(success bool) string {
+	if success {
+		return ResultSuccess
+	}
+	return ResultFailure
+}
+
+// RecordTransaction counts a recorded payment transaction by type, status and merchant tier
+func RecordTransaction(txType, status, tier string) {
+	paymentTransactionsTotal.WithLabelValues(txType, status, tierLabel(tier)).Inc()
+}
+
+// ObserveEPXLatency records one EPX ProcessTransaction round trip, including failed calls
+func ObserveEPXLatency(transactionType, tier string, latency time.Duration) {
+	epxRequestDuration.WithLabelValues(transactionType, tierLabel(tier)).Observe(latency.Seconds())
+}
+
+// RecordWebhookDelivery counts one webhook delivery attempt
+func RecordWebhookDelivery(eventType string, success bool) {
+	webhookDeliveriesTotal.WithLabelValues(eventType, resultLabel(success)).Inc()
+}
+
+// ObserveCronRun records the duration of one cron run
+func ObserveCronRun(job string, duration time.Duration, success bool) {
+	cronRunDuration.WithLabelValues(job, resultLabel(success)).Observe(duration.Seconds())
+}
+
+// RecordCronSkipped counts a cron run skipped because the job was already running elsewhere
+func RecordCronSkipped(job string) {
+	cronRunsSkippedTotal.WithLabelValues(job).Inc()
+}
+
 // UnaryServerInterceptor returns a gRPC unary server interceptor that records Prometheus metrics
 func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(
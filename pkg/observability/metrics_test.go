@@ -0,0 +1,72 @@
+package observability
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// histogramCount returns how many observations a histogram series has recorded
+func histogramCount(t *testing.T, observer prometheus.Observer) uint64 {
+	t.Helper()
+	metric, ok := observer.(prometheus.Metric)
+	require.True(t, ok)
+
+	var out dto.Metric
+	require.NoError(t, metric.Write(&out))
+	return out.GetHistogram().GetSampleCount()
+}
+
+func TestRecordTransaction_Capture(t *testing.T) {
+	captures := paymentTransactionsTotal.WithLabelValues("capture", "completed", "standard")
+	before := testutil.ToFloat64(captures)
+
+	RecordTransaction("capture", "completed", "standard")
+
+	assert.Equal(t, before+1, testutil.ToFloat64(captures))
+}
+
+func TestObserveEPXLatency(t *testing.T) {
+	observer := epxRequestDuration.WithLabelValues("CCE4", "standard")
+	before := histogramCount(t, observer)
+
+	ObserveEPXLatency("CCE4", "standard", 350*time.Millisecond)
+
+	assert.Equal(t, before+1, histogramCount(t, observer))
+}
+
+func TestMetricsAreLabeledByTierNotMerchant(t *testing.T) {
+	RecordTransaction("charge", "failed", "")
+	RecordWebhookDelivery("payment.completed", false)
+	ObserveCronRun("reconcile", time.Second, true)
+
+	rec := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	assert.Contains(t, body, `payment_transactions_total{status="failed",tier="unknown",type="charge"}`)
+	assert.Contains(t, body, `webhook_deliveries_total{event_type="payment.completed",result="failure"}`)
+	assert.Contains(t, body, `cron_run_duration_seconds_count{job="reconcile",result="success"}`)
+	assert.False(t, strings.Contains(body, "agent_id"), "no per-merchant labels")
+}
+
+func TestCronHandler_PartialContentIsFailure(t *testing.T) {
+	observer := cronRunDuration.WithLabelValues("test-job", ResultFailure)
+	before := histogramCount(t, observer)
+
+	handler := CronHandler("test-job", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPartialContent)
+	})
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/cron/test", nil))
+
+	assert.Equal(t, before+1, histogramCount(t, observer))
+}
@@ -53,11 +53,11 @@ func (z *ZapLoggerAdapter) Debug(msg string, fields ...ports.Field) {
 	z.logger.Debug(msg, convertFields(fields)...)
 }
 
-// convertFields converts our Field type to zap.Field
+// convertFields converts our Field type to zap.Field, redacting card data and secrets
 func convertFields(fields []ports.Field) []zap.Field {
 	zapFields := make([]zap.Field, len(fields))
 	for i, f := range fields {
-		zapFields[i] = zap.Any(f.Key, f.Value)
+		zapFields[i] = zap.Any(f.Key, RedactField(f.Key, f.Value))
 	}
 	return zapFields
 }
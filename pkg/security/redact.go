@@ -0,0 +1,221 @@
+package security
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// RedactedValue replaces stripped values
+const RedactedValue = "[REDACTED]"
+
+// defaultMaxPayloadBytes caps a serialized audit payload
+const defaultMaxPayloadBytes = 4096
+
+// panKeys hold card and account numbers. Audit payloads drop them; sanitized logs keep the last four digits.
+// Keys are compared normalized (lowercase, letters and digits only), so CARD_NBR, cardNumber and card-number all match.
+var panKeys = map[string]bool{
+	"pan":           true,
+	"cardnumber":    true,
+	"cardnbr":       true,
+	"accountnumber": true,
+	"accountnbr":    true,
+}
+
+// droppedKeys are never recorded: card numbers (panKeys), security codes, expiry dates and secrets
+var droppedKeys = withKeys(panKeys,
+	"cvv",
+	"cavv",
+	"cvv2",
+	"cvc",
+	"cardcvv",
+	"expdate",
+	"expirationdate",
+	"expiry",
+	"expirydate",
+	"cardexp",
+	"trackdata",
+	"macsecret",
+	"mac",
+	"password",
+	"secret",
+)
+
+// maskedKeys hold BRICs (reusable card tokens): only the last 4 characters are kept
+var maskedKeys = map[string]bool{
+	"bric":          true,
+	"authguid":      true,
+	"origauthguid":  true,
+	"financialbric": true,
+	"storagebric":   true,
+	"paymenttoken":  true,
+	"token":         true,
+}
+
+// RedactionPolicy strips card data and secrets from payloads before they're logged or audited
+type RedactionPolicy struct {
+	MaxPayloadBytes int                 // Serialized payloads above this are replaced by a truncation marker (0 = unlimited)
+	Operations      map[string][]string // Extra keys dropped for one operation, e.g. "sale": {"billing_address"}
+}
+
+// DefaultRedactionPolicy drops card data and secrets, masks BRICs and caps payloads at 4 KiB
+func DefaultRedactionPolicy() *RedactionPolicy {
+	return &RedactionPolicy{
+		MaxPayloadBytes: defaultMaxPayloadBytes,
+		Operations:      map[string][]string{},
+	}
+}
+
+// Redact returns a JSON-shaped copy of v (structs become maps keyed by field name) without sensitive fields.
+// Nested maps and lists are walked, and any string containing a card number is stripped whatever its key.
+func (p *RedactionPolicy) Redact(operation string, v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return RedactedValue
+	}
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return RedactedValue
+	}
+
+	extra := map[string]bool{}
+	for _, key := range p.Operations[operation] {
+		extra[normalizeKey(key)] = true
+	}
+	return redactValue(generic, extra)
+}
+
+// Payload redacts v and serializes it for an audit JSONB column.
+// A payload over MaxPayloadBytes is replaced by {"truncated": true, "size": <bytes>}.
+func (p *RedactionPolicy) Payload(operation string, v interface{}) json.RawMessage {
+	out, err := json.Marshal(p.Redact(operation, v))
+	if err != nil {
+		out, _ = json.Marshal(RedactedValue)
+	}
+	if p.MaxPayloadBytes > 0 && len(out) > p.MaxPayloadBytes {
+		out, _ = json.Marshal(map[string]interface{}{"truncated": true, "size": len(out)})
+	}
+	return out
+}
+
+// RedactField applies the default key and value rules to one structured log field
+func RedactField(key string, value interface{}) interface{} {
+	norm := normalizeKey(key)
+	switch {
+	case droppedKeys[norm]:
+		return RedactedValue
+	case maskedKeys[norm]:
+		if s, ok := value.(string); ok {
+			return maskToken(s)
+		}
+		return RedactedValue
+	}
+	if s, ok := value.(string); ok && looksLikePAN(s) {
+		return RedactedValue
+	}
+	return value
+}
+
+func redactValue(v interface{}, extra map[string]bool) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for key, child := range val {
+			norm := normalizeKey(key)
+			switch {
+			case droppedKeys[norm] || extra[norm]:
+				continue
+			case maskedKeys[norm]:
+				if s, ok := child.(string); ok {
+					out[key] = maskToken(s)
+				}
+			default:
+				out[key] = redactValue(child, extra)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, child := range val {
+			out[i] = redactValue(child, extra)
+		}
+		return out
+	case string:
+		if looksLikePAN(val) {
+			return RedactedValue
+		}
+		return val
+	default:
+		return val
+	}
+}
+
+// withKeys returns a copy of base with keys added
+func withKeys(base map[string]bool, keys ...string) map[string]bool {
+	out := make(map[string]bool, len(base)+len(keys))
+	for key := range base {
+		out[key] = true
+	}
+	for _, key := range keys {
+		out[key] = true
+	}
+	return out
+}
+
+// maskToken keeps the last 4 characters of a token
+func maskToken(s string) string {
+	if len(s) <= 4 {
+		return strings.Repeat("*", len(s))
+	}
+	return strings.Repeat("*", len(s)-4) + s[len(s)-4:]
+}
+
+func normalizeKey(key string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(key) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// looksLikePAN reports whether s contains a run of 13-19 digits (single spaces or dashes between digits allowed)
+// that passes the Luhn check
+func looksLikePAN(s string) bool {
+	var digits []int
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, int(r-'0'))
+			continue
+		case (r == ' ' || r == '-') && len(digits) > 0 && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			continue
+		}
+		if isPAN(digits) {
+			return true
+		}
+		digits = digits[:0]
+	}
+	return isPAN(digits)
+}
+
+// isPAN reports whether digits has a card number's length and a valid Luhn check digit
+func isPAN(digits []int) bool {
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i -= 2 {
+		sum += digits[i]
+	}
+	for i := len(digits) - 2; i >= 0; i -= 2 {
+		d := digits[i] * 2
+		if d > 9 {
+			d -= 9
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,58 @@
+package security
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactionPolicy_Redact(t *testing.T) {
+	policy := DefaultRedactionPolicy()
+	policy.Operations["sale"] = []string{"billing_address"}
+
+	payload := map[string]interface{}{
+		"ACCOUNT_NBR":     "4111111111111111",
+		"CVV2":            "123",
+		"AUTH_GUID":       "0V703LH1HDL006J74W1",
+		"amount":          "10.00",
+		"billing_address": "1 Main St",
+		"items": []interface{}{
+			map[string]interface{}{"cardNumber": "5500000000000004", "sku": "A-1"},
+			"ref 4111-1111-1111-1111",
+			"order 1234567890123",
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"AUTH_GUID": "***************74W1",
+		"amount":    "10.00",
+		"items": []interface{}{
+			map[string]interface{}{"sku": "A-1"},
+			RedactedValue,
+			"order 1234567890123", // not Luhn-valid
+		},
+	}, policy.Redact("sale", payload))
+
+	assert.Contains(t, policy.Redact("refund", payload), "billing_address", "extra keys only apply to their operation")
+}
+
+func TestRedactionPolicy_PayloadCapsSize(t *testing.T) {
+	policy := &RedactionPolicy{MaxPayloadBytes: 32}
+
+	small := policy.Payload("sale", map[string]string{"amount": "10.00"})
+	assert.JSONEq(t, `{"amount":"10.00"}`, string(small))
+
+	large := policy.Payload("sale", map[string]string{"description": string(make([]byte, 100))})
+	var marker map[string]interface{}
+	assert.NoError(t, json.Unmarshal(large, &marker))
+	assert.Equal(t, true, marker["truncated"])
+}
+
+func TestRedactField(t *testing.T) {
+	assert.Equal(t, RedactedValue, RedactField("card_number", "4111111111111111"))
+	assert.Equal(t, "***************74W1", RedactField("auth_guid", "0V703LH1HDL006J74W1"))
+	assert.Equal(t, RedactedValue, RedactField("response_body", "ACCOUNT_NBR=4111111111111111&AMOUNT=10.00"))
+	assert.Equal(t, "10.00", RedactField("amount", "10.00"))
+	assert.Equal(t, 42, RedactField("count", 42))
+}
@@ -0,0 +1,251 @@
+package security
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	// xmlFieldPattern matches EPX's <FIELD KEY="name">value</FIELD> response fields
+	xmlFieldPattern = regexp.MustCompile(`(<FIELD\s+KEY="([^"]*)"\s*>)([^<]*)(</FIELD>)`)
+
+	// xmlElementPattern matches simple <NAME>value</NAME> elements (the names are compared in code)
+	xmlElementPattern = regexp.MustCompile(`<([A-Za-z_][A-Za-z0-9_]*)>([^<]*)</([A-Za-z_][A-Za-z0-9_]*)>`)
+
+	// digitRunPattern matches runs that may be card numbers (digits with single spaces or dashes between them)
+	digitRunPattern = regexp.MustCompile(`\d(?:[ -]?\d){12,18}`)
+)
+
+// MaskPAN keeps the last four digits of a card or account number, e.g. "************4242". A value with four
+// digits or fewer (such as an already masked number) is returned unchanged.
+func MaskPAN(s string) string {
+	var digits strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	if digits.Len() <= 4 {
+		return s
+	}
+	return maskToken(digits.String())
+}
+
+// SanitizeValue makes one EPX field safe to log. Card numbers keep their last four digits and BRICs their last
+// four characters; security codes, expiry dates and secrets are replaced, as is any other value holding a card number.
+func SanitizeValue(key, value string) string {
+	norm := normalizeKey(key)
+	switch {
+	case panKeys[norm]:
+		return MaskPAN(value)
+	case droppedKeys[norm]:
+		return RedactedValue
+	case maskedKeys[norm]:
+		return maskToken(value)
+	}
+	return redactPANRuns(value)
+}
+
+// SanitizeParams returns form or query parameters safe to log; repeated values are joined with ","
+func SanitizeParams(params map[string][]string) map[string]string {
+	out := make(map[string]string, len(params))
+	for key, values := range params {
+		sanitized := make([]string, len(values))
+		for i, value := range values {
+			sanitized[i] = SanitizeValue(key, value)
+		}
+		out[key] = strings.Join(sanitized, ",")
+	}
+	return out
+}
+
+// ParamsField is a log field holding sanitized form or query parameters
+func ParamsField(key string, params map[string][]string) zap.Field {
+	return zap.Any(key, SanitizeParams(params))
+}
+
+// SanitizeBody makes a raw EPX request or response body safe to log. URL-encoded pairs and XML fields are
+// sanitized by name like SanitizeValue, and card numbers anywhere else are replaced.
+func SanitizeBody(body string) string {
+	trimmed := strings.TrimSpace(body)
+	if !strings.HasPrefix(trimmed, "<") && strings.Contains(trimmed, "=") {
+		return sanitizeQuery(trimmed)
+	}
+
+	body = xmlFieldPattern.ReplaceAllStringFunc(body, func(field string) string {
+		m := xmlFieldPattern.FindStringSubmatch(field)
+		return m[1] + SanitizeValue(m[2], m[3]) + m[4]
+	})
+	body = xmlElementPattern.ReplaceAllStringFunc(body, func(element string) string {
+		m := xmlElementPattern.FindStringSubmatch(element)
+		if m[1] != m[3] {
+			return element
+		}
+		return "<" + m[1] + ">" + SanitizeValue(m[1], m[2]) + "</" + m[3] + ">"
+	})
+	return redactPANRuns(body)
+}
+
+// sanitizeQuery sanitizes URL-encoded key=value pairs in place, keeping their order
+func sanitizeQuery(query string) string {
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			pairs[i] = redactPANRuns(pair)
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		pairs[i] = key + "=" + SanitizeValue(key, value)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// redactPANRuns replaces every Luhn-valid card number in s, keeping the surrounding text
+func redactPANRuns(s string) string {
+	if !looksLikePAN(s) {
+		return s
+	}
+	return digitRunPattern.ReplaceAllStringFunc(s, func(run string) string {
+		if looksLikePAN(run) {
+			return RedactedValue
+		}
+		return run
+	})
+}
+
+// redactingCore sanitizes fields by key (SanitizeValue) before they reach the wrapped core
+type redactingCore struct {
+	zapcore.Core
+}
+
+// RedactingCore wraps a logger's core so card numbers, CVVs, expiry dates, BRICs and secrets are sanitized in
+// every field, whichever call site logs them: strings, stringers and byte strings by key, values logged with
+// zap.Any (maps, structs, slices) key by key after converting them to JSON, and errors by replacing any card
+// number in their message. Use it with zap.WrapCore.
+func RedactingCore(core zapcore.Core) zapcore.Core {
+	return redactingCore{Core: core}
+}
+
+func (c redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactingCore{Core: c.Core.With(sanitizeFields(fields))}
+}
+
+func (c redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, sanitizeFields(fields))
+}
+
+// sanitizeFields returns fields with sensitive values replaced, copying only when something changes
+func sanitizeFields(fields []zapcore.Field) []zapcore.Field {
+	out := fields
+	copied := false
+	for i, field := range fields {
+		sanitized, changed := sanitizeField(field)
+		if !changed {
+			continue
+		}
+		if !copied {
+			out, copied = append([]zapcore.Field(nil), fields...), true
+		}
+		out[i] = sanitized
+	}
+	return out
+}
+
+// sanitizeField sanitizes one field; changed is false when it is logged as is
+func sanitizeField(field zapcore.Field) (sanitized zapcore.Field, changed bool) {
+	switch field.Type {
+	case zapcore.StringType:
+		value := SanitizeValue(field.Key, field.String)
+		return zap.String(field.Key, value), value != field.String
+	case zapcore.ByteStringType:
+		raw, _ := field.Interface.([]byte)
+		value := SanitizeValue(field.Key, string(raw))
+		return zap.String(field.Key, value), value != string(raw)
+	case zapcore.StringerType:
+		stringer, ok := field.Interface.(fmt.Stringer)
+		if !ok || stringer == nil {
+			return field, false
+		}
+		return zap.String(field.Key, SanitizeValue(field.Key, safeString(stringer))), true
+	case zapcore.ErrorType:
+		err, ok := field.Interface.(error)
+		if !ok || err == nil {
+			return field, false
+		}
+		if droppedKeys[normalizeKey(field.Key)] {
+			return zap.String(field.Key, RedactedValue), true
+		}
+		message := err.Error()
+		if sanitizedMessage := redactPANRuns(message); sanitizedMessage != message {
+			return zap.NamedError(field.Key, errors.New(sanitizedMessage)), true
+		}
+		return field, false
+	case zapcore.ReflectType:
+		return zap.Reflect(field.Key, sanitizeReflected(field.Key, field.Interface)), true
+	}
+	return field, false
+}
+
+// sanitizeReflected converts a zap.Any value to its JSON shape and sanitizes every string in it by its key
+func sanitizeReflected(key string, v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return RedactedValue
+	}
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return RedactedValue
+	}
+	return sanitizeJSONValue(key, generic)
+}
+
+func sanitizeJSONValue(key string, v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for childKey, child := range val {
+			out[childKey] = sanitizeJSONValue(childKey, child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, child := range val {
+			out[i] = sanitizeJSONValue(key, child)
+		}
+		return out
+	case string:
+		return SanitizeValue(key, val)
+	default:
+		if droppedKeys[normalizeKey(key)] {
+			return RedactedValue
+		}
+		return val
+	}
+}
+
+// safeString calls String, treating a panic (e.g. a nil pointer receiver) as an unloggable value
+func safeString(stringer fmt.Stringer) (s string) {
+	defer func() {
+		if recover() != nil {
+			s = RedactedValue
+		}
+	}()
+	return stringer.String()
+}
@@ -0,0 +1,117 @@
+package security
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSanitizeParams(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"CARD_NBR":  "************4242",
+		"CVV2":      RedactedValue,
+		"EXP_DATE":  RedactedValue,
+		"AUTH_GUID": "***************74W1",
+		"MEMO":      "ref " + RedactedValue,
+		"AMOUNT":    "10.00,12.50",
+	}, SanitizeParams(map[string][]string{
+		"CARD_NBR":  {"4242424242424242"},
+		"CVV2":      {"123"},
+		"EXP_DATE":  {"2512"},
+		"AUTH_GUID": {"0V703LH1HDL006J74W1"},
+		"MEMO":      {"ref 4111-1111-1111-1111"},
+		"AMOUNT":    {"10.00", "12.50"},
+	}))
+	assert.Equal(t, "************1111", MaskPAN("4111 1111 1111 1111"))
+}
+
+func TestSanitizeBody(t *testing.T) {
+	xml := `<RESPONSE><FIELDS><FIELD KEY="AUTH_GUID">0V703LH1HDL006J74W1</FIELD>` +
+		`<FIELD KEY="ACCOUNT_NBR">4111111111111111</FIELD><FIELD KEY="AUTH_RESP">00</FIELD></FIELDS>` +
+		`<CVV2>123</CVV2></RESPONSE>`
+	assert.Equal(t, `<RESPONSE><FIELDS><FIELD KEY="AUTH_GUID">***************74W1</FIELD>`+
+		`<FIELD KEY="ACCOUNT_NBR">************1111</FIELD><FIELD KEY="AUTH_RESP">00</FIELD></FIELDS>`+
+		`<CVV2>[REDACTED]</CVV2></RESPONSE>`, SanitizeBody(xml))
+
+	assert.Equal(t, "CARD_NBR=************1111&EXP_DATE=[REDACTED]&TRAN_NBR=42",
+		SanitizeBody("CARD_NBR=4111111111111111&EXP_DATE=2512&TRAN_NBR=42"))
+	assert.Equal(t, "upstream error: card "+RedactedValue, SanitizeBody("upstream error: card 4111111111111111"))
+}
+
+func TestRedactingCore(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core, zap.WrapCore(RedactingCore)).With(zap.String("storage_bric", "0V703LH1HDL006J74W1"))
+
+	logger.Info("stored card",
+		zap.String("financial_bric", "1A2B3C4D5E6F7G8H9J0K"),
+		zap.String("note", "card 4111111111111111"),
+		zap.String("tran_nbr", "12345"),
+		zap.Int("attempt", 1),
+	)
+	logger.Debug("below the level", zap.String("cvv2", "123"))
+
+	entries := logs.All()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, map[string]interface{}{
+		"storage_bric":   "***************74W1",
+		"financial_bric": "****************9J0K",
+		"note":           "card " + RedactedValue,
+		"tran_nbr":       "12345",
+		"attempt":        int64(1),
+	}, entries[0].ContextMap())
+}
+
+type loggedCard struct {
+	CardNumber string            `json:"card_number"`
+	CVV2       string            `json:"cvv2"`
+	AuthGUID   string            `json:"auth_guid"`
+	Amount     string            `json:"amount"`
+	Extra      map[string]string `json:"extra"`
+}
+
+type cardStringer string
+
+func (c cardStringer) String() string { return "card " + string(c) }
+
+func TestRedactingCore_AnyAndErrorFields(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core, zap.WrapCore(RedactingCore))
+
+	logger.Info("logged values",
+		zap.Any("request", loggedCard{
+			CardNumber: "4111111111111111",
+			CVV2:       "987",
+			AuthGUID:   "0V703LH1HDL006J74W1",
+			Amount:     "10.00",
+			Extra:      map[string]string{"EXP_DATE": "2512", "memo": "card 4242424242424242"},
+		}),
+		zap.Any("form", map[string][]string{"CARD_NBR": {"4111111111111111"}, "TRAN_NBR": {"42"}}),
+		ParamsField("params", map[string][]string{"CARD_NBR": {"4111111111111111"}}),
+		zap.Error(errors.New("EPX rejected CARD_NBR=4111111111111111")),
+		zap.Stringer("card", cardStringer("4111111111111111")),
+		zap.ByteString("cvv", []byte("987")),
+	)
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, map[string]interface{}{
+		"card_number": "************1111",
+		"cvv2":        RedactedValue,
+		"auth_guid":   "***************74W1",
+		"amount":      "10.00",
+		"extra":       map[string]interface{}{"EXP_DATE": RedactedValue, "memo": "card " + RedactedValue},
+	}, fields["request"])
+	assert.Equal(t, map[string]interface{}{
+		"CARD_NBR": []interface{}{"************1111"},
+		"TRAN_NBR": []interface{}{"42"},
+	}, fields["form"])
+	assert.Equal(t, map[string]interface{}{"CARD_NBR": "************1111"}, fields["params"], "already sanitized values keep their last four")
+	assert.Equal(t, "EPX rejected CARD_NBR="+RedactedValue, fields["error"])
+	assert.Equal(t, "card "+RedactedValue, fields["card"])
+	assert.Equal(t, RedactedValue, fields["cvv"])
+}
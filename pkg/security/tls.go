@@ -0,0 +1,53 @@
+package security
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// DefaultMinTLSVersion is the lowest TLS version outbound payment connections negotiate
+const DefaultMinTLSVersion = tls.VersionTLS12
+
+// VettedCipherSuites are the TLS 1.2 suites outbound connections offer: ECDHE key exchange with AEAD ciphers only.
+// TLS 1.3 suites are not configurable and are all acceptable.
+var VettedCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// ParseTLSVersion parses a minimum TLS version setting ("1.2" or "1.3")
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported minimum TLS version %q (use 1.2 or 1.3)", version)
+	}
+}
+
+// ClientTLSConfig returns the TLS configuration for outbound payment connections.
+// Versions below minVersion (and anything below TLS 1.2) are refused, as are cipher suites outside VettedCipherSuites.
+func ClientTLSConfig(minVersion uint16, insecureSkipVerify bool) *tls.Config {
+	if minVersion < DefaultMinTLSVersion {
+		minVersion = DefaultMinTLSVersion
+	}
+	return &tls.Config{
+		MinVersion:         minVersion,
+		CipherSuites:       VettedCipherSuites,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+}
+
+// NewHTTPTransport returns a copy of the default transport restricted to the outbound TLS policy
+func NewHTTPTransport(minVersion uint16) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = ClientTLSConfig(minVersion, false)
+	return transport
+}
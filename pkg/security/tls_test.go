@@ -0,0 +1,78 @@
+package security
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTLSServer starts a TLS server offering only the given version range
+func newTLSServer(t *testing.T, minVersion, maxVersion uint16) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{MinVersion: minVersion, MaxVersion: maxVersion}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newPolicyClient trusts the test server's certificate so only the version policy decides the handshake
+func newPolicyClient(minVersion uint16) *http.Client {
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: ClientTLSConfig(minVersion, true)}}
+}
+
+func TestClientTLSConfig_RefusesTLS11Server(t *testing.T) {
+	server := newTLSServer(t, tls.VersionTLS10, tls.VersionTLS11)
+
+	_, err := newPolicyClient(DefaultMinTLSVersion).Get(server.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "protocol version")
+}
+
+func TestClientTLSConfig_MinimumVersion(t *testing.T) {
+	tls12Only := newTLSServer(t, tls.VersionTLS12, tls.VersionTLS12)
+
+	resp, err := newPolicyClient(tls.VersionTLS12).Get(tls12Only.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, uint16(tls.VersionTLS12), resp.TLS.Version)
+
+	_, err = newPolicyClient(tls.VersionTLS13).Get(tls12Only.URL)
+	assert.Error(t, err, "a TLS 1.3 minimum refuses a TLS 1.2 server")
+
+	// Anything configured below TLS 1.2 is raised to it
+	assert.Equal(t, uint16(tls.VersionTLS12), ClientTLSConfig(tls.VersionTLS10, false).MinVersion)
+}
+
+func TestClientTLSConfig_RefusesWeakCipherSuites(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_RSA_WITH_AES_128_CBC_SHA},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	_, err := newPolicyClient(DefaultMinTLSVersion).Get(server.URL)
+	assert.Error(t, err)
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	version, err := ParseTLSVersion("1.2")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), version)
+
+	version, err = ParseTLSVersion("1.3")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), version)
+
+	_, err = ParseTLSVersion("1.1")
+	assert.Error(t, err)
+}
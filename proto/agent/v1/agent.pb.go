@@ -84,6 +84,7 @@ type RegisterAgentRequest struct {
 	Environment    Environment            `protobuf:"varint,7,opt,name=environment,proto3,enum=agent.v1.Environment" json:"environment,omitempty"`                                          // sandbox or production
 	Metadata       map[string]string      `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Additional merchant info
 	IdempotencyKey string                 `protobuf:"bytes,9,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	DataRegion     string                 `protobuf:"bytes,10,opt,name=data_region,json=dataRegion,proto3" json:"data_region,omitempty"` // Data residency region, e.g. "us", "eu" (default "us")
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -181,6 +182,13 @@ func (x *RegisterAgentRequest) GetIdempotencyKey() string {
 	return ""
 }
 
+func (x *RegisterAgentRequest) GetDataRegion() string {
+	if x != nil {
+		return x.DataRegion
+	}
+	return ""
+}
+
 // GetAgentRequest retrieves agent credentials
 type GetAgentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -350,18 +358,20 @@ func (x *ListAgentsResponse) GetTotalCount() int32 {
 
 // UpdateAgentRequest updates agent credentials
 type UpdateAgentRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	MacSecret      *string                `protobuf:"bytes,2,opt,name=mac_secret,json=macSecret,proto3,oneof" json:"mac_secret,omitempty"`                                                  // Optional: update MAC secret
-	CustNbr        *string                `protobuf:"bytes,3,opt,name=cust_nbr,json=custNbr,proto3,oneof" json:"cust_nbr,omitempty"`                                                        // Optional: update customer number
-	MerchNbr       *string                `protobuf:"bytes,4,opt,name=merch_nbr,json=merchNbr,proto3,oneof" json:"merch_nbr,omitempty"`                                                     // Optional: update merchant number
-	DbaNbr         *string                `protobuf:"bytes,5,opt,name=dba_nbr,json=dbaNbr,proto3,oneof" json:"dba_nbr,omitempty"`                                                           // Optional: update DBA number
-	TerminalNbr    *string                `protobuf:"bytes,6,opt,name=terminal_nbr,json=terminalNbr,proto3,oneof" json:"terminal_nbr,omitempty"`                                            // Optional: update terminal number
-	Environment    *Environment           `protobuf:"varint,7,opt,name=environment,proto3,enum=agent.v1.Environment,oneof" json:"environment,omitempty"`                                    // Optional: update environment
-	Metadata       map[string]string      `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Optional: update metadata (empty map if not updating)
-	IdempotencyKey string                 `protobuf:"bytes,9,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state                           protoimpl.MessageState `protogen:"open.v1"`
+	AgentId                         string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	MacSecret                       *string                `protobuf:"bytes,2,opt,name=mac_secret,json=macSecret,proto3,oneof" json:"mac_secret,omitempty"`                                                  // Optional: update MAC secret
+	CustNbr                         *string                `protobuf:"bytes,3,opt,name=cust_nbr,json=custNbr,proto3,oneof" json:"cust_nbr,omitempty"`                                                        // Optional: update customer number
+	MerchNbr                        *string                `protobuf:"bytes,4,opt,name=merch_nbr,json=merchNbr,proto3,oneof" json:"merch_nbr,omitempty"`                                                     // Optional: update merchant number
+	DbaNbr                          *string                `protobuf:"bytes,5,opt,name=dba_nbr,json=dbaNbr,proto3,oneof" json:"dba_nbr,omitempty"`                                                           // Optional: update DBA number
+	TerminalNbr                     *string                `protobuf:"bytes,6,opt,name=terminal_nbr,json=terminalNbr,proto3,oneof" json:"terminal_nbr,omitempty"`                                            // Optional: update terminal number
+	Environment                     *Environment           `protobuf:"varint,7,opt,name=environment,proto3,enum=agent.v1.Environment,oneof" json:"environment,omitempty"`                                    // Optional: update environment
+	Metadata                        map[string]string      `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Optional: update metadata (empty map if not updating)
+	IdempotencyKey                  string                 `protobuf:"bytes,9,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	SubscriptionAmountChangeMinDays *int32                 `protobuf:"varint,10,opt,name=subscription_amount_change_min_days,json=subscriptionAmountChangeMinDays,proto3,oneof" json:"subscription_amount_change_min_days,omitempty"` // Optional: min days between subscription amount changes (0 = unlimited)
+	DataRegion                      *string                `protobuf:"bytes,11,opt,name=data_region,json=dataRegion,proto3,oneof" json:"data_region,omitempty"`                                                                       // Optional: data residency region for rows written from now on
+	unknownFields                   protoimpl.UnknownFields
+	sizeCache                       protoimpl.SizeCache
 }
 
 func (x *UpdateAgentRequest) Reset() {
@@ -457,7 +467,21 @@ func (x *UpdateAgentRequest) GetIdempotencyKey() string {
 	return ""
 }
 
-// DeactivateAgentRequest deactivates an agent
+func (x *UpdateAgentRequest) GetSubscriptionAmountChangeMinDays() int32 {
+	if x != nil && x.SubscriptionAmountChangeMinDays != nil {
+		return *x.SubscriptionAmountChangeMinDays
+	}
+	return 0
+}
+
+func (x *UpdateAgentRequest) GetDataRegion() string {
+	if x != nil && x.DataRegion != nil {
+		return *x.DataRegion
+	}
+	return ""
+}
+
+// DeactivateAgentRequest closes an agent
 type DeactivateAgentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
@@ -510,29 +534,29 @@ func (x *DeactivateAgentRequest) GetReason() string {
 	return ""
 }
 
-// RotateMACRequest rotates MAC secret
-type RotateMACRequest struct {
+// SuspendMerchantRequest suspends an active merchant
+type SuspendMerchantRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	NewMacSecret  string                 `protobuf:"bytes,2,opt,name=new_mac_secret,json=newMacSecret,proto3" json:"new_mac_secret,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"` // Required: shown as the merchant's status_reason
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RotateMACRequest) Reset() {
-	*x = RotateMACRequest{}
+func (x *SuspendMerchantRequest) Reset() {
+	*x = SuspendMerchantRequest{}
 	mi := &file_proto_agent_v1_agent_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RotateMACRequest) String() string {
+func (x *SuspendMerchantRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RotateMACRequest) ProtoMessage() {}
+func (*SuspendMerchantRequest) ProtoMessage() {}
 
-func (x *RotateMACRequest) ProtoReflect() protoreflect.Message {
+func (x *SuspendMerchantRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_agent_v1_agent_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -544,49 +568,48 @@ func (x *RotateMACRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RotateMACRequest.ProtoReflect.Descriptor instead.
-func (*RotateMACRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SuspendMerchantRequest.ProtoReflect.Descriptor instead.
+func (*SuspendMerchantRequest) Descriptor() ([]byte, []int) {
 	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *RotateMACRequest) GetAgentId() string {
+func (x *SuspendMerchantRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *RotateMACRequest) GetNewMacSecret() string {
+func (x *SuspendMerchantRequest) GetReason() string {
 	if x != nil {
-		return x.NewMacSecret
+		return x.Reason
 	}
 	return ""
 }
 
-// RotateMACResponse confirms MAC rotation
-type RotateMACResponse struct {
+// ReactivateMerchantRequest reactivates a suspended merchant
+type ReactivateMerchantRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	MacSecretPath string                 `protobuf:"bytes,2,opt,name=mac_secret_path,json=macSecretPath,proto3" json:"mac_secret_path,omitempty"` // New secret manager path
-	RotatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=rotated_at,json=rotatedAt,proto3" json:"rotated_at,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"` // Optional: recorded in audit_logs
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RotateMACResponse) Reset() {
-	*x = RotateMACResponse{}
+func (x *ReactivateMerchantRequest) Reset() {
+	*x = ReactivateMerchantRequest{}
 	mi := &file_proto_agent_v1_agent_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RotateMACResponse) String() string {
+func (x *ReactivateMerchantRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RotateMACResponse) ProtoMessage() {}
+func (*ReactivateMerchantRequest) ProtoMessage() {}
 
-func (x *RotateMACResponse) ProtoReflect() protoreflect.Message {
+func (x *ReactivateMerchantRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_agent_v1_agent_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -598,63 +621,48 @@ func (x *RotateMACResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RotateMACResponse.ProtoReflect.Descriptor instead.
-func (*RotateMACResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReactivateMerchantRequest.ProtoReflect.Descriptor instead.
+func (*ReactivateMerchantRequest) Descriptor() ([]byte, []int) {
 	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *RotateMACResponse) GetAgentId() string {
+func (x *ReactivateMerchantRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *RotateMACResponse) GetMacSecretPath() string {
+func (x *ReactivateMerchantRequest) GetReason() string {
 	if x != nil {
-		return x.MacSecretPath
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *RotateMACResponse) GetRotatedAt() *timestamppb.Timestamp {
-	if x != nil {
-		return x.RotatedAt
-	}
-	return nil
-}
-
-// AgentResponse is returned from agent operations
-type AgentResponse struct {
+// RotateMACRequest rotates MAC secret
+type RotateMACRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	MacSecretPath string                 `protobuf:"bytes,2,opt,name=mac_secret_path,json=macSecretPath,proto3" json:"mac_secret_path,omitempty"` // Reference to secret manager (NOT the secret itself)
-	CustNbr       string                 `protobuf:"bytes,3,opt,name=cust_nbr,json=custNbr,proto3" json:"cust_nbr,omitempty"`
-	MerchNbr      string                 `protobuf:"bytes,4,opt,name=merch_nbr,json=merchNbr,proto3" json:"merch_nbr,omitempty"`
-	DbaNbr        string                 `protobuf:"bytes,5,opt,name=dba_nbr,json=dbaNbr,proto3" json:"dba_nbr,omitempty"`
-	TerminalNbr   string                 `protobuf:"bytes,6,opt,name=terminal_nbr,json=terminalNbr,proto3" json:"terminal_nbr,omitempty"`
-	Environment   Environment            `protobuf:"varint,7,opt,name=environment,proto3,enum=agent.v1.Environment" json:"environment,omitempty"`
-	IsActive      bool                   `protobuf:"varint,8,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	NewMacSecret  string                 `protobuf:"bytes,2,opt,name=new_mac_secret,json=newMacSecret,proto3" json:"new_mac_secret,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentResponse) Reset() {
-	*x = AgentResponse{}
+func (x *RotateMACRequest) Reset() {
+	*x = RotateMACRequest{}
 	mi := &file_proto_agent_v1_agent_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentResponse) String() string {
+func (x *RotateMACRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentResponse) ProtoMessage() {}
+func (*RotateMACRequest) ProtoMessage() {}
 
-func (x *AgentResponse) ProtoReflect() protoreflect.Message {
+func (x *RotateMACRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_agent_v1_agent_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -666,241 +674,348 @@ func (x *AgentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentResponse.ProtoReflect.Descriptor instead.
-func (*AgentResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use RotateMACRequest.ProtoReflect.Descriptor instead.
+func (*RotateMACRequest) Descriptor() ([]byte, []int) {
 	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *AgentResponse) GetAgentId() string {
+func (x *RotateMACRequest) GetAgentId() string {
 	if x != nil {
 		return x.AgentId
 	}
 	return ""
 }
 
-func (x *AgentResponse) GetMacSecretPath() string {
+func (x *RotateMACRequest) GetNewMacSecret() string {
 	if x != nil {
-		return x.MacSecretPath
+		return x.NewMacSecret
 	}
 	return ""
 }
 
-func (x *AgentResponse) GetCustNbr() string {
+// GetEffectiveMerchantConfigRequest retrieves an agent's effective configuration
+type GetEffectiveMerchantConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEffectiveMerchantConfigRequest) Reset() {
+	*x = GetEffectiveMerchantConfigRequest{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEffectiveMerchantConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEffectiveMerchantConfigRequest) ProtoMessage() {}
+
+func (x *GetEffectiveMerchantConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[9]
 	if x != nil {
-		return x.CustNbr
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEffectiveMerchantConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetEffectiveMerchantConfigRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetEffectiveMerchantConfigRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *AgentResponse) GetMerchNbr() string {
+// EffectiveMerchantConfig is the tier defaults merged with agent overrides (never includes secrets)
+type EffectiveMerchantConfig struct {
+	state                      protoimpl.MessageState `protogen:"open.v1"`
+	AgentId                    string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Tier                       string                 `protobuf:"bytes,2,opt,name=tier,proto3" json:"tier,omitempty"` // standard, premium, enterprise
+	AllowedCurrencies          []string               `protobuf:"bytes,3,rep,name=allowed_currencies,json=allowedCurrencies,proto3" json:"allowed_currencies,omitempty"`
+	MinTransactionAmount       string                 `protobuf:"bytes,4,opt,name=min_transaction_amount,json=minTransactionAmount,proto3" json:"min_transaction_amount,omitempty"` // Decimal as string
+	MaxTransactionAmount       string                 `protobuf:"bytes,5,opt,name=max_transaction_amount,json=maxTransactionAmount,proto3" json:"max_transaction_amount,omitempty"` // Decimal as string
+	DailyVolumeLimit           string                 `protobuf:"bytes,6,opt,name=daily_volume_limit,json=dailyVolumeLimit,proto3" json:"daily_volume_limit,omitempty"`             // Decimal as string
+	SurchargePercent           string                 `protobuf:"bytes,7,opt,name=surcharge_percent,json=surchargePercent,proto3" json:"surcharge_percent,omitempty"`               // Decimal as string (3.00 = 3%)
+	Capabilities               []string               `protobuf:"bytes,8,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	AllowedTransactionTypes    []string               `protobuf:"bytes,9,rep,name=allowed_transaction_types,json=allowedTransactionTypes,proto3" json:"allowed_transaction_types,omitempty"`
+	AllowedPaymentTypes        []string               `protobuf:"bytes,10,rep,name=allowed_payment_types,json=allowedPaymentTypes,proto3" json:"allowed_payment_types,omitempty"`
+	OverriddenFields           []string               `protobuf:"bytes,11,rep,name=overridden_fields,json=overriddenFields,proto3" json:"overridden_fields,omitempty"`                                    // Fields supplied by agent overrides instead of tier defaults
+	RequireSettledRefund       bool                   `protobuf:"varint,12,opt,name=require_settled_refund,json=requireSettledRefund,proto3" json:"require_settled_refund,omitempty"`                     // Refunds of unsettled transactions are rejected (void instead)
+	FundingDelayDays           int32                  `protobuf:"varint,13,opt,name=funding_delay_days,json=fundingDelayDays,proto3" json:"funding_delay_days,omitempty"`                                 // Business days from settlement to merchant funding
+	AchCardFallback            bool                   `protobuf:"varint,14,opt,name=ach_card_fallback,json=achCardFallback,proto3" json:"ach_card_fallback,omitempty"`                                    // Exhausted ACH subscriptions switch to the customer's card on file
+	IncludeTransactionTree     bool                   `protobuf:"varint,15,opt,name=include_transaction_tree,json=includeTransactionTree,proto3" json:"include_transaction_tree,omitempty"`               // Payment responses include the transaction group tree by default
+	AvsPolicy                  string                 `protobuf:"bytes,16,opt,name=avs_policy,json=avsPolicy,proto3" json:"avs_policy,omitempty"`                                                         // AVS policy recorded on card authorizations: "", "lenient", "strict"
+	CvvPolicy                  string                 `protobuf:"bytes,17,opt,name=cvv_policy,json=cvvPolicy,proto3" json:"cvv_policy,omitempty"`                                                         // CVV policy recorded on card authorizations: "", "lenient", "strict"
+	MaxSavedPaymentMethods     int32                  `protobuf:"varint,18,opt,name=max_saved_payment_methods,json=maxSavedPaymentMethods,proto3" json:"max_saved_payment_methods,omitempty"`             // Cap on a customer's active saved payment methods (0 = unlimited)
+	SavedPaymentMethodPolicy   string                 `protobuf:"bytes,19,opt,name=saved_payment_method_policy,json=savedPaymentMethodPolicy,proto3" json:"saved_payment_method_policy,omitempty"`        // At the cap: "reject" the new one or "prune_lru" (delete least recently used)
+	RequestsPerSecond          float64                `protobuf:"fixed64,20,opt,name=requests_per_second,json=requestsPerSecond,proto3" json:"requests_per_second,omitempty"`                             // gRPC requests per second allowed for requests carrying this agent_id
+	BurstLimit                 int32                  `protobuf:"varint,21,opt,name=burst_limit,json=burstLimit,proto3" json:"burst_limit,omitempty"`                                                     // Requests allowed in a burst above requests_per_second
+	CustomerPolicy             string                 `protobuf:"bytes,22,opt,name=customer_policy,json=customerPolicy,proto3" json:"customer_policy,omitempty"`                                          // Payment customer_id check: "" (unchecked), "auto_create" or "require_existing"
+	ReturnUrlHosts             []string               `protobuf:"bytes,23,rep,name=return_url_hosts,json=returnUrlHosts,proto3" json:"return_url_hosts,omitempty"`                                        // Hosts a Browser Post return_url may point at ("*.example.com" matches subdomains)
+	BrowserPostMacVerification bool                   `protobuf:"varint,24,opt,name=browser_post_mac_verification,json=browserPostMacVerification,proto3" json:"browser_post_mac_verification,omitempty"` // Browser Post callbacks must carry a valid response MAC
+	CardVelocity               *VelocityLimit         `protobuf:"bytes,25,opt,name=card_velocity,json=cardVelocity,proto3" json:"card_velocity,omitempty"`                                                // Limit on one saved card's sales and authorizations
+	CustomerVelocity           *VelocityLimit         `protobuf:"bytes,26,opt,name=customer_velocity,json=customerVelocity,proto3" json:"customer_velocity,omitempty"`                                    // Limit on one customer's sales and authorizations
+	AuthAutoVoidHours          int32                  `protobuf:"varint,27,opt,name=auth_auto_void_hours,json=authAutoVoidHours,proto3" json:"auth_auto_void_hours,omitempty"`                            // Uncaptured authorizations are voided this many hours after their latest approval (0 = never)
+	unknownFields              protoimpl.UnknownFields
+	sizeCache                  protoimpl.SizeCache
+}
+
+func (x *EffectiveMerchantConfig) Reset() {
+	*x = EffectiveMerchantConfig{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EffectiveMerchantConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EffectiveMerchantConfig) ProtoMessage() {}
+
+func (x *EffectiveMerchantConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[10]
 	if x != nil {
-		return x.MerchNbr
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EffectiveMerchantConfig.ProtoReflect.Descriptor instead.
+func (*EffectiveMerchantConfig) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *EffectiveMerchantConfig) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *AgentResponse) GetDbaNbr() string {
+func (x *EffectiveMerchantConfig) GetTier() string {
 	if x != nil {
-		return x.DbaNbr
+		return x.Tier
 	}
 	return ""
 }
 
-func (x *AgentResponse) GetTerminalNbr() string {
+func (x *EffectiveMerchantConfig) GetAllowedCurrencies() []string {
 	if x != nil {
-		return x.TerminalNbr
+		return x.AllowedCurrencies
+	}
+	return nil
+}
+
+func (x *EffectiveMerchantConfig) GetMinTransactionAmount() string {
+	if x != nil {
+		return x.MinTransactionAmount
 	}
 	return ""
 }
 
-func (x *AgentResponse) GetEnvironment() Environment {
+func (x *EffectiveMerchantConfig) GetMaxTransactionAmount() string {
 	if x != nil {
-		return x.Environment
+		return x.MaxTransactionAmount
 	}
-	return Environment_ENVIRONMENT_UNSPECIFIED
+	return ""
 }
 
-func (x *AgentResponse) GetIsActive() bool {
+func (x *EffectiveMerchantConfig) GetDailyVolumeLimit() string {
 	if x != nil {
-		return x.IsActive
+		return x.DailyVolumeLimit
 	}
-	return false
+	return ""
 }
 
-func (x *AgentResponse) GetCreatedAt() *timestamppb.Timestamp {
+func (x *EffectiveMerchantConfig) GetSurchargePercent() string {
 	if x != nil {
-		return x.CreatedAt
+		return x.SurchargePercent
+	}
+	return ""
+}
+
+func (x *EffectiveMerchantConfig) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
 	}
 	return nil
 }
 
-func (x *AgentResponse) GetUpdatedAt() *timestamppb.Timestamp {
+func (x *EffectiveMerchantConfig) GetAllowedTransactionTypes() []string {
 	if x != nil {
-		return x.UpdatedAt
+		return x.AllowedTransactionTypes
 	}
 	return nil
 }
 
-// Agent represents complete agent credentials (internal use only)
-type Agent struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	AgentId       string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	MacSecretPath string                 `protobuf:"bytes,3,opt,name=mac_secret_path,json=macSecretPath,proto3" json:"mac_secret_path,omitempty"` // Reference to secret manager
-	CustNbr       string                 `protobuf:"bytes,4,opt,name=cust_nbr,json=custNbr,proto3" json:"cust_nbr,omitempty"`
-	MerchNbr      string                 `protobuf:"bytes,5,opt,name=merch_nbr,json=merchNbr,proto3" json:"merch_nbr,omitempty"`
-	DbaNbr        string                 `protobuf:"bytes,6,opt,name=dba_nbr,json=dbaNbr,proto3" json:"dba_nbr,omitempty"`
-	TerminalNbr   string                 `protobuf:"bytes,7,opt,name=terminal_nbr,json=terminalNbr,proto3" json:"terminal_nbr,omitempty"`
-	Environment   Environment            `protobuf:"varint,8,opt,name=environment,proto3,enum=agent.v1.Environment" json:"environment,omitempty"`
-	IsActive      bool                   `protobuf:"varint,9,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	Metadata      map[string]string      `protobuf:"bytes,12,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *EffectiveMerchantConfig) GetAllowedPaymentTypes() []string {
+	if x != nil {
+		return x.AllowedPaymentTypes
+	}
+	return nil
 }
 
-func (x *Agent) Reset() {
-	*x = Agent{}
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[9]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *EffectiveMerchantConfig) GetOverriddenFields() []string {
+	if x != nil {
+		return x.OverriddenFields
+	}
+	return nil
 }
 
-func (x *Agent) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *EffectiveMerchantConfig) GetRequireSettledRefund() bool {
+	if x != nil {
+		return x.RequireSettledRefund
+	}
+	return false
 }
 
-func (*Agent) ProtoMessage() {}
+func (x *EffectiveMerchantConfig) GetFundingDelayDays() int32 {
+	if x != nil {
+		return x.FundingDelayDays
+	}
+	return 0
+}
 
-func (x *Agent) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[9]
+func (x *EffectiveMerchantConfig) GetAchCardFallback() bool {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.AchCardFallback
 	}
-	return mi.MessageOf(x)
+	return false
 }
 
-// Deprecated: Use Agent.ProtoReflect.Descriptor instead.
-func (*Agent) Descriptor() ([]byte, []int) {
-	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{9}
+func (x *EffectiveMerchantConfig) GetIncludeTransactionTree() bool {
+	if x != nil {
+		return x.IncludeTransactionTree
+	}
+	return false
 }
 
-func (x *Agent) GetId() string {
+func (x *EffectiveMerchantConfig) GetAvsPolicy() string {
 	if x != nil {
-		return x.Id
+		return x.AvsPolicy
 	}
 	return ""
 }
 
-func (x *Agent) GetAgentId() string {
+func (x *EffectiveMerchantConfig) GetCvvPolicy() string {
 	if x != nil {
-		return x.AgentId
+		return x.CvvPolicy
 	}
 	return ""
 }
 
-func (x *Agent) GetMacSecretPath() string {
+func (x *EffectiveMerchantConfig) GetMaxSavedPaymentMethods() int32 {
 	if x != nil {
-		return x.MacSecretPath
+		return x.MaxSavedPaymentMethods
 	}
-	return ""
+	return 0
 }
 
-func (x *Agent) GetCustNbr() string {
+func (x *EffectiveMerchantConfig) GetSavedPaymentMethodPolicy() string {
 	if x != nil {
-		return x.CustNbr
+		return x.SavedPaymentMethodPolicy
 	}
 	return ""
 }
 
-func (x *Agent) GetMerchNbr() string {
+func (x *EffectiveMerchantConfig) GetRequestsPerSecond() float64 {
 	if x != nil {
-		return x.MerchNbr
+		return x.RequestsPerSecond
 	}
-	return ""
+	return 0
 }
 
-func (x *Agent) GetDbaNbr() string {
+func (x *EffectiveMerchantConfig) GetBurstLimit() int32 {
 	if x != nil {
-		return x.DbaNbr
+		return x.BurstLimit
 	}
-	return ""
+	return 0
 }
 
-func (x *Agent) GetTerminalNbr() string {
+func (x *EffectiveMerchantConfig) GetCustomerPolicy() string {
 	if x != nil {
-		return x.TerminalNbr
+		return x.CustomerPolicy
 	}
 	return ""
 }
 
-func (x *Agent) GetEnvironment() Environment {
+func (x *EffectiveMerchantConfig) GetReturnUrlHosts() []string {
 	if x != nil {
-		return x.Environment
+		return x.ReturnUrlHosts
 	}
-	return Environment_ENVIRONMENT_UNSPECIFIED
+	return nil
 }
 
-func (x *Agent) GetIsActive() bool {
+func (x *EffectiveMerchantConfig) GetBrowserPostMacVerification() bool {
 	if x != nil {
-		return x.IsActive
+		return x.BrowserPostMacVerification
 	}
 	return false
 }
 
-func (x *Agent) GetCreatedAt() *timestamppb.Timestamp {
+func (x *EffectiveMerchantConfig) GetCardVelocity() *VelocityLimit {
 	if x != nil {
-		return x.CreatedAt
+		return x.CardVelocity
 	}
 	return nil
 }
 
-func (x *Agent) GetUpdatedAt() *timestamppb.Timestamp {
+func (x *EffectiveMerchantConfig) GetCustomerVelocity() *VelocityLimit {
 	if x != nil {
-		return x.UpdatedAt
+		return x.CustomerVelocity
 	}
 	return nil
 }
 
-func (x *Agent) GetMetadata() map[string]string {
+func (x *EffectiveMerchantConfig) GetAuthAutoVoidHours() int32 {
 	if x != nil {
-		return x.Metadata
+		return x.AuthAutoVoidHours
 	}
-	return nil
+	return 0
 }
 
-// AgentSummary is a lightweight agent representation for lists
-type AgentSummary struct {
+// VelocityLimit caps attempts and amount within a rolling window (window_minutes 0 = off)
+type VelocityLimit struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	MerchNbr      string                 `protobuf:"bytes,2,opt,name=merch_nbr,json=merchNbr,proto3" json:"merch_nbr,omitempty"`
-	Environment   Environment            `protobuf:"varint,3,opt,name=environment,proto3,enum=agent.v1.Environment" json:"environment,omitempty"`
-	IsActive      bool                   `protobuf:"varint,4,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	WindowMinutes int32                  `protobuf:"varint,1,opt,name=window_minutes,json=windowMinutes,proto3" json:"window_minutes,omitempty"`
+	MaxCount      int32                  `protobuf:"varint,2,opt,name=max_count,json=maxCount,proto3" json:"max_count,omitempty"`   // Attempts allowed in the window, declines included (0 = uncapped)
+	MaxAmount     string                 `protobuf:"bytes,3,opt,name=max_amount,json=maxAmount,proto3" json:"max_amount,omitempty"` // Decimal as string; total of attempts not declined in the window (0.00 = uncapped)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentSummary) Reset() {
-	*x = AgentSummary{}
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[10]
+func (x *VelocityLimit) Reset() {
+	*x = VelocityLimit{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentSummary) String() string {
+func (x *VelocityLimit) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentSummary) ProtoMessage() {}
+func (*VelocityLimit) ProtoMessage() {}
 
-func (x *AgentSummary) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_v1_agent_proto_msgTypes[10]
+func (x *VelocityLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -911,14 +1026,692 @@ func (x *AgentSummary) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentSummary.ProtoReflect.Descriptor instead.
-func (*AgentSummary) Descriptor() ([]byte, []int) {
-	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use VelocityLimit.ProtoReflect.Descriptor instead.
+func (*VelocityLimit) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *AgentSummary) GetAgentId() string {
+func (x *VelocityLimit) GetWindowMinutes() int32 {
 	if x != nil {
-		return x.AgentId
+		return x.WindowMinutes
+	}
+	return 0
+}
+
+func (x *VelocityLimit) GetMaxCount() int32 {
+	if x != nil {
+		return x.MaxCount
+	}
+	return 0
+}
+
+func (x *VelocityLimit) GetMaxAmount() string {
+	if x != nil {
+		return x.MaxAmount
+	}
+	return ""
+}
+
+// GetMerchantRequest retrieves a merchant's profile
+type GetMerchantRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMerchantRequest) Reset() {
+	*x = GetMerchantRequest{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMerchantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMerchantRequest) ProtoMessage() {}
+
+func (x *GetMerchantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMerchantRequest.ProtoReflect.Descriptor instead.
+func (*GetMerchantRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetMerchantRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+// UpdateMerchantSettingsRequest changes self-service settings; unset fields are kept and an empty value clears the setting.
+// Only these fields can be changed here: EPX credentials, tier and limits are operator-managed.
+type UpdateMerchantSettingsRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	AgentId             string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	StatementDescriptor *string                `protobuf:"bytes,2,opt,name=statement_descriptor,json=statementDescriptor,proto3,oneof" json:"statement_descriptor,omitempty"` // 5-22 letters, digits, spaces or . , & - shown on cardholder statements
+	NotificationEmail   *string                `protobuf:"bytes,3,opt,name=notification_email,json=notificationEmail,proto3,oneof" json:"notification_email,omitempty"`       // Where operational notices are sent
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *UpdateMerchantSettingsRequest) Reset() {
+	*x = UpdateMerchantSettingsRequest{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMerchantSettingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMerchantSettingsRequest) ProtoMessage() {}
+
+func (x *UpdateMerchantSettingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMerchantSettingsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMerchantSettingsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UpdateMerchantSettingsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *UpdateMerchantSettingsRequest) GetStatementDescriptor() string {
+	if x != nil && x.StatementDescriptor != nil {
+		return *x.StatementDescriptor
+	}
+	return ""
+}
+
+func (x *UpdateMerchantSettingsRequest) GetNotificationEmail() string {
+	if x != nil && x.NotificationEmail != nil {
+		return *x.NotificationEmail
+	}
+	return ""
+}
+
+// Merchant is the self-service view of an agent (never includes EPX credentials or secret paths)
+type Merchant struct {
+	state               protoimpl.MessageState   `protogen:"open.v1"`
+	AgentId             string                   `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Environment         Environment              `protobuf:"varint,2,opt,name=environment,proto3,enum=agent.v1.Environment" json:"environment,omitempty"`
+	IsActive            bool                     `protobuf:"varint,3,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	DataRegion          string                   `protobuf:"bytes,4,opt,name=data_region,json=dataRegion,proto3" json:"data_region,omitempty"`
+	StatementDescriptor string                   `protobuf:"bytes,5,opt,name=statement_descriptor,json=statementDescriptor,proto3" json:"statement_descriptor,omitempty"` // Empty = EPX default
+	NotificationEmail   string                   `protobuf:"bytes,6,opt,name=notification_email,json=notificationEmail,proto3" json:"notification_email,omitempty"`
+	Config              *EffectiveMerchantConfig `protobuf:"bytes,7,opt,name=config,proto3" json:"config,omitempty"` // Tier, limits, allowed currencies and policies
+	CreatedAt           *timestamppb.Timestamp   `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt           *timestamppb.Timestamp   `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Status              string                   `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"`                                 // active, suspended (no new charges) or closed
+	StatusReason        string                   `protobuf:"bytes,11,opt,name=status_reason,json=statusReason,proto3" json:"status_reason,omitempty"` // Why the merchant was suspended or closed
+	SuspendedAt         *timestamppb.Timestamp   `protobuf:"bytes,12,opt,name=suspended_at,json=suspendedAt,proto3" json:"suspended_at,omitempty"`    // Unset unless suspended
+	ClosedAt            *timestamppb.Timestamp   `protobuf:"bytes,13,opt,name=closed_at,json=closedAt,proto3" json:"closed_at,omitempty"`             // Unset unless closed
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Merchant) Reset() {
+	*x = Merchant{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Merchant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Merchant) ProtoMessage() {}
+
+func (x *Merchant) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Merchant.ProtoReflect.Descriptor instead.
+func (*Merchant) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Merchant) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *Merchant) GetEnvironment() Environment {
+	if x != nil {
+		return x.Environment
+	}
+	return Environment_ENVIRONMENT_UNSPECIFIED
+}
+
+func (x *Merchant) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *Merchant) GetDataRegion() string {
+	if x != nil {
+		return x.DataRegion
+	}
+	return ""
+}
+
+func (x *Merchant) GetStatementDescriptor() string {
+	if x != nil {
+		return x.StatementDescriptor
+	}
+	return ""
+}
+
+func (x *Merchant) GetNotificationEmail() string {
+	if x != nil {
+		return x.NotificationEmail
+	}
+	return ""
+}
+
+func (x *Merchant) GetConfig() *EffectiveMerchantConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *Merchant) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Merchant) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Merchant) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Merchant) GetStatusReason() string {
+	if x != nil {
+		return x.StatusReason
+	}
+	return ""
+}
+
+func (x *Merchant) GetSuspendedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SuspendedAt
+	}
+	return nil
+}
+
+func (x *Merchant) GetClosedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClosedAt
+	}
+	return nil
+}
+
+// RotateMACResponse confirms MAC rotation
+type RotateMACResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	MacSecretPath string                 `protobuf:"bytes,2,opt,name=mac_secret_path,json=macSecretPath,proto3" json:"mac_secret_path,omitempty"` // New secret manager path
+	RotatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=rotated_at,json=rotatedAt,proto3" json:"rotated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateMACResponse) Reset() {
+	*x = RotateMACResponse{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateMACResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateMACResponse) ProtoMessage() {}
+
+func (x *RotateMACResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateMACResponse.ProtoReflect.Descriptor instead.
+func (*RotateMACResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RotateMACResponse) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RotateMACResponse) GetMacSecretPath() string {
+	if x != nil {
+		return x.MacSecretPath
+	}
+	return ""
+}
+
+func (x *RotateMACResponse) GetRotatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RotatedAt
+	}
+	return nil
+}
+
+// AgentResponse is returned from agent operations
+type AgentResponse struct {
+	state                           protoimpl.MessageState `protogen:"open.v1"`
+	AgentId                         string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	MacSecretPath                   string                 `protobuf:"bytes,2,opt,name=mac_secret_path,json=macSecretPath,proto3" json:"mac_secret_path,omitempty"` // Reference to secret manager (NOT the secret itself)
+	CustNbr                         string                 `protobuf:"bytes,3,opt,name=cust_nbr,json=custNbr,proto3" json:"cust_nbr,omitempty"`
+	MerchNbr                        string                 `protobuf:"bytes,4,opt,name=merch_nbr,json=merchNbr,proto3" json:"merch_nbr,omitempty"`
+	DbaNbr                          string                 `protobuf:"bytes,5,opt,name=dba_nbr,json=dbaNbr,proto3" json:"dba_nbr,omitempty"`
+	TerminalNbr                     string                 `protobuf:"bytes,6,opt,name=terminal_nbr,json=terminalNbr,proto3" json:"terminal_nbr,omitempty"`
+	Environment                     Environment            `protobuf:"varint,7,opt,name=environment,proto3,enum=agent.v1.Environment" json:"environment,omitempty"`
+	IsActive                        bool                   `protobuf:"varint,8,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	CreatedAt                       *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt                       *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	SubscriptionAmountChangeMinDays *int32                 `protobuf:"varint,11,opt,name=subscription_amount_change_min_days,json=subscriptionAmountChangeMinDays,proto3,oneof" json:"subscription_amount_change_min_days,omitempty"` // Unset = unlimited
+	DataRegion                      string                 `protobuf:"bytes,12,opt,name=data_region,json=dataRegion,proto3" json:"data_region,omitempty"`                                                                             // Data residency region
+	Status                          string                 `protobuf:"bytes,13,opt,name=status,proto3" json:"status,omitempty"`                                                                                                       // active, suspended or closed
+	unknownFields                   protoimpl.UnknownFields
+	sizeCache                       protoimpl.SizeCache
+}
+
+func (x *AgentResponse) Reset() {
+	*x = AgentResponse{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentResponse) ProtoMessage() {}
+
+func (x *AgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentResponse.ProtoReflect.Descriptor instead.
+func (*AgentResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AgentResponse) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *AgentResponse) GetMacSecretPath() string {
+	if x != nil {
+		return x.MacSecretPath
+	}
+	return ""
+}
+
+func (x *AgentResponse) GetCustNbr() string {
+	if x != nil {
+		return x.CustNbr
+	}
+	return ""
+}
+
+func (x *AgentResponse) GetMerchNbr() string {
+	if x != nil {
+		return x.MerchNbr
+	}
+	return ""
+}
+
+func (x *AgentResponse) GetDbaNbr() string {
+	if x != nil {
+		return x.DbaNbr
+	}
+	return ""
+}
+
+func (x *AgentResponse) GetTerminalNbr() string {
+	if x != nil {
+		return x.TerminalNbr
+	}
+	return ""
+}
+
+func (x *AgentResponse) GetEnvironment() Environment {
+	if x != nil {
+		return x.Environment
+	}
+	return Environment_ENVIRONMENT_UNSPECIFIED
+}
+
+func (x *AgentResponse) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *AgentResponse) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *AgentResponse) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *AgentResponse) GetSubscriptionAmountChangeMinDays() int32 {
+	if x != nil && x.SubscriptionAmountChangeMinDays != nil {
+		return *x.SubscriptionAmountChangeMinDays
+	}
+	return 0
+}
+
+func (x *AgentResponse) GetDataRegion() string {
+	if x != nil {
+		return x.DataRegion
+	}
+	return ""
+}
+
+func (x *AgentResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// Agent represents complete agent credentials (internal use only)
+type Agent struct {
+	state                           protoimpl.MessageState `protogen:"open.v1"`
+	Id                              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AgentId                         string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	MacSecretPath                   string                 `protobuf:"bytes,3,opt,name=mac_secret_path,json=macSecretPath,proto3" json:"mac_secret_path,omitempty"` // Reference to secret manager
+	CustNbr                         string                 `protobuf:"bytes,4,opt,name=cust_nbr,json=custNbr,proto3" json:"cust_nbr,omitempty"`
+	MerchNbr                        string                 `protobuf:"bytes,5,opt,name=merch_nbr,json=merchNbr,proto3" json:"merch_nbr,omitempty"`
+	DbaNbr                          string                 `protobuf:"bytes,6,opt,name=dba_nbr,json=dbaNbr,proto3" json:"dba_nbr,omitempty"`
+	TerminalNbr                     string                 `protobuf:"bytes,7,opt,name=terminal_nbr,json=terminalNbr,proto3" json:"terminal_nbr,omitempty"`
+	Environment                     Environment            `protobuf:"varint,8,opt,name=environment,proto3,enum=agent.v1.Environment" json:"environment,omitempty"`
+	IsActive                        bool                   `protobuf:"varint,9,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	CreatedAt                       *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt                       *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Metadata                        map[string]string      `protobuf:"bytes,12,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	SubscriptionAmountChangeMinDays *int32                 `protobuf:"varint,13,opt,name=subscription_amount_change_min_days,json=subscriptionAmountChangeMinDays,proto3,oneof" json:"subscription_amount_change_min_days,omitempty"` // Unset = unlimited
+	DataRegion                      string                 `protobuf:"bytes,14,opt,name=data_region,json=dataRegion,proto3" json:"data_region,omitempty"`                                                                             // Data residency region
+	Status                          string                 `protobuf:"bytes,15,opt,name=status,proto3" json:"status,omitempty"`                                                                                                       // active, suspended or closed
+	StatusReason                    string                 `protobuf:"bytes,16,opt,name=status_reason,json=statusReason,proto3" json:"status_reason,omitempty"`
+	unknownFields                   protoimpl.UnknownFields
+	sizeCache                       protoimpl.SizeCache
+}
+
+func (x *Agent) Reset() {
+	*x = Agent{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Agent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Agent) ProtoMessage() {}
+
+func (x *Agent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Agent.ProtoReflect.Descriptor instead.
+func (*Agent) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Agent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Agent) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *Agent) GetMacSecretPath() string {
+	if x != nil {
+		return x.MacSecretPath
+	}
+	return ""
+}
+
+func (x *Agent) GetCustNbr() string {
+	if x != nil {
+		return x.CustNbr
+	}
+	return ""
+}
+
+func (x *Agent) GetMerchNbr() string {
+	if x != nil {
+		return x.MerchNbr
+	}
+	return ""
+}
+
+func (x *Agent) GetDbaNbr() string {
+	if x != nil {
+		return x.DbaNbr
+	}
+	return ""
+}
+
+func (x *Agent) GetTerminalNbr() string {
+	if x != nil {
+		return x.TerminalNbr
+	}
+	return ""
+}
+
+func (x *Agent) GetEnvironment() Environment {
+	if x != nil {
+		return x.Environment
+	}
+	return Environment_ENVIRONMENT_UNSPECIFIED
+}
+
+func (x *Agent) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *Agent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Agent) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Agent) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Agent) GetSubscriptionAmountChangeMinDays() int32 {
+	if x != nil && x.SubscriptionAmountChangeMinDays != nil {
+		return *x.SubscriptionAmountChangeMinDays
+	}
+	return 0
+}
+
+func (x *Agent) GetDataRegion() string {
+	if x != nil {
+		return x.DataRegion
+	}
+	return ""
+}
+
+func (x *Agent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Agent) GetStatusReason() string {
+	if x != nil {
+		return x.StatusReason
+	}
+	return ""
+}
+
+// AgentSummary is a lightweight agent representation for lists
+type AgentSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	MerchNbr      string                 `protobuf:"bytes,2,opt,name=merch_nbr,json=merchNbr,proto3" json:"merch_nbr,omitempty"`
+	Environment   Environment            `protobuf:"varint,3,opt,name=environment,proto3,enum=agent.v1.Environment" json:"environment,omitempty"`
+	IsActive      bool                   `protobuf:"varint,4,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentSummary) Reset() {
+	*x = AgentSummary{}
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentSummary) ProtoMessage() {}
+
+func (x *AgentSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_v1_agent_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentSummary.ProtoReflect.Descriptor instead.
+func (*AgentSummary) Descriptor() ([]byte, []int) {
+	return file_proto_agent_v1_agent_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AgentSummary) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
 	}
 	return ""
 }
@@ -955,7 +1748,7 @@ var File_proto_agent_v1_agent_proto protoreflect.FileDescriptor
 
 const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"\n" +
-	"\x1aproto/agent/v1/agent.proto\x12\bagent.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xad\x03\n" +
+	"\x1aproto/agent/v1/agent.proto\x12\bagent.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xce\x03\n" +
 	"\x14RegisterAgentRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1d\n" +
 	"\n" +
@@ -966,7 +1759,10 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"\fterminal_nbr\x18\x06 \x01(\tR\vterminalNbr\x127\n" +
 	"\venvironment\x18\a \x01(\x0e2\x15.agent.v1.EnvironmentR\venvironment\x12H\n" +
 	"\bmetadata\x18\b \x03(\v2,.agent.v1.RegisterAgentRequest.MetadataEntryR\bmetadata\x12'\n" +
-	"\x0fidempotency_key\x18\t \x01(\tR\x0eidempotencyKey\x1a;\n" +
+	"\x0fidempotency_key\x18\t \x01(\tR\x0eidempotencyKey\x12\x1f\n" +
+	"\vdata_region\x18\n" +
+	" \x01(\tR\n" +
+	"dataRegion\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\",\n" +
@@ -983,7 +1779,7 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"\x12ListAgentsResponse\x12.\n" +
 	"\x06agents\x18\x01 \x03(\v2\x16.agent.v1.AgentSummaryR\x06agents\x12\x1f\n" +
 	"\vtotal_count\x18\x02 \x01(\x05R\n" +
-	"totalCount\"\x9e\x04\n" +
+	"totalCount\"\xcf\x05\n" +
 	"\x12UpdateAgentRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\"\n" +
 	"\n" +
@@ -994,7 +1790,11 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"\fterminal_nbr\x18\x06 \x01(\tH\x04R\vterminalNbr\x88\x01\x01\x12<\n" +
 	"\venvironment\x18\a \x01(\x0e2\x15.agent.v1.EnvironmentH\x05R\venvironment\x88\x01\x01\x12F\n" +
 	"\bmetadata\x18\b \x03(\v2*.agent.v1.UpdateAgentRequest.MetadataEntryR\bmetadata\x12'\n" +
-	"\x0fidempotency_key\x18\t \x01(\tR\x0eidempotencyKey\x1a;\n" +
+	"\x0fidempotency_key\x18\t \x01(\tR\x0eidempotencyKey\x12Q\n" +
+	"#subscription_amount_change_min_days\x18\n" +
+	" \x01(\x05H\x06R\x1fsubscriptionAmountChangeMinDays\x88\x01\x01\x12$\n" +
+	"\vdata_region\x18\v \x01(\tH\aR\n" +
+	"dataRegion\x88\x01\x01\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\r\n" +
@@ -1005,18 +1805,92 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"\n" +
 	"\b_dba_nbrB\x0f\n" +
 	"\r_terminal_nbrB\x0e\n" +
-	"\f_environment\"K\n" +
+	"\f_environmentB&\n" +
+	"$_subscription_amount_change_min_daysB\x0e\n" +
+	"\f_data_region\"K\n" +
 	"\x16DeactivateAgentRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"K\n" +
+	"\x16SuspendMerchantRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"N\n" +
+	"\x19ReactivateMerchantRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
 	"\x06reason\x18\x02 \x01(\tR\x06reason\"S\n" +
 	"\x10RotateMACRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12$\n" +
-	"\x0enew_mac_secret\x18\x02 \x01(\tR\fnewMacSecret\"\x91\x01\n" +
+	"\x0enew_mac_secret\x18\x02 \x01(\tR\fnewMacSecret\">\n" +
+	"!GetEffectiveMerchantConfigRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\x9d\n" +
+	"\n" +
+	"\x17EffectiveMerchantConfig\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04tier\x18\x02 \x01(\tR\x04tier\x12-\n" +
+	"\x12allowed_currencies\x18\x03 \x03(\tR\x11allowedCurrencies\x124\n" +
+	"\x16min_transaction_amount\x18\x04 \x01(\tR\x14minTransactionAmount\x124\n" +
+	"\x16max_transaction_amount\x18\x05 \x01(\tR\x14maxTransactionAmount\x12,\n" +
+	"\x12daily_volume_limit\x18\x06 \x01(\tR\x10dailyVolumeLimit\x12+\n" +
+	"\x11surcharge_percent\x18\a \x01(\tR\x10surchargePercent\x12\"\n" +
+	"\fcapabilities\x18\b \x03(\tR\fcapabilities\x12:\n" +
+	"\x19allowed_transaction_types\x18\t \x03(\tR\x17allowedTransactionTypes\x122\n" +
+	"\x15allowed_payment_types\x18\n" +
+	" \x03(\tR\x13allowedPaymentTypes\x12+\n" +
+	"\x11overridden_fields\x18\v \x03(\tR\x10overriddenFields\x124\n" +
+	"\x16require_settled_refund\x18\f \x01(\bR\x14requireSettledRefund\x12,\n" +
+	"\x12funding_delay_days\x18\r \x01(\x05R\x10fundingDelayDays\x12*\n" +
+	"\x11ach_card_fallback\x18\x0e \x01(\bR\x0fachCardFallback\x128\n" +
+	"\x18include_transaction_tree\x18\x0f \x01(\bR\x16includeTransactionTree\x12\x1d\n" +
+	"\n" +
+	"avs_policy\x18\x10 \x01(\tR\tavsPolicy\x12\x1d\n" +
+	"\n" +
+	"cvv_policy\x18\x11 \x01(\tR\tcvvPolicy\x129\n" +
+	"\x19max_saved_payment_methods\x18\x12 \x01(\x05R\x16maxSavedPaymentMethods\x12=\n" +
+	"\x1bsaved_payment_method_policy\x18\x13 \x01(\tR\x18savedPaymentMethodPolicy\x12.\n" +
+	"\x13requests_per_second\x18\x14 \x01(\x01R\x11requestsPerSecond\x12\x1f\n" +
+	"\vburst_limit\x18\x15 \x01(\x05R\n" +
+	"burstLimit\x12'\n" +
+	"\x0fcustomer_policy\x18\x16 \x01(\tR\x0ecustomerPolicy\x12(\n" +
+	"\x10return_url_hosts\x18\x17 \x03(\tR\x0ereturnUrlHosts\x12A\n" +
+	"\x1dbrowser_post_mac_verification\x18\x18 \x01(\bR\x1abrowserPostMacVerification\x12<\n" +
+	"\rcard_velocity\x18\x19 \x01(\v2\x17.agent.v1.VelocityLimitR\fcardVelocity\x12D\n" +
+	"\x11customer_velocity\x18\x1a \x01(\v2\x17.agent.v1.VelocityLimitR\x10customerVelocity\x12/\n" +
+	"\x14auth_auto_void_hours\x18\x1b \x01(\x05R\x11authAutoVoidHours\"r\n" +
+	"\rVelocityLimit\x12%\n" +
+	"\x0ewindow_minutes\x18\x01 \x01(\x05R\rwindowMinutes\x12\x1b\n" +
+	"\tmax_count\x18\x02 \x01(\x05R\bmaxCount\x12\x1d\n" +
+	"\n" +
+	"max_amount\x18\x03 \x01(\tR\tmaxAmount\"/\n" +
+	"\x12GetMerchantRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\xd6\x01\n" +
+	"\x1dUpdateMerchantSettingsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x126\n" +
+	"\x14statement_descriptor\x18\x02 \x01(\tH\x00R\x13statementDescriptor\x88\x01\x01\x122\n" +
+	"\x12notification_email\x18\x03 \x01(\tH\x01R\x11notificationEmail\x88\x01\x01B\x17\n" +
+	"\x15_statement_descriptorB\x15\n" +
+	"\x13_notification_email\"\xe4\x04\n" +
+	"\bMerchant\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x127\n" +
+	"\venvironment\x18\x02 \x01(\x0e2\x15.agent.v1.EnvironmentR\venvironment\x12\x1b\n" +
+	"\tis_active\x18\x03 \x01(\bR\bisActive\x12\x1f\n" +
+	"\vdata_region\x18\x04 \x01(\tR\n" +
+	"dataRegion\x121\n" +
+	"\x14statement_descriptor\x18\x05 \x01(\tR\x13statementDescriptor\x12-\n" +
+	"\x12notification_email\x18\x06 \x01(\tR\x11notificationEmail\x129\n" +
+	"\x06config\x18\a \x01(\v2!.agent.v1.EffectiveMerchantConfigR\x06config\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\tR\x06status\x12#\n" +
+	"\rstatus_reason\x18\v \x01(\tR\fstatusReason\x12=\n" +
+	"\fsuspended_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\vsuspendedAt\x127\n" +
+	"\tclosed_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\bclosedAt\"\x91\x01\n" +
 	"\x11RotateMACResponse\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12&\n" +
 	"\x0fmac_secret_path\x18\x02 \x01(\tR\rmacSecretPath\x129\n" +
 	"\n" +
-	"rotated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\trotatedAt\"\x92\x03\n" +
+	"rotated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\trotatedAt\"\xc6\x04\n" +
 	"\rAgentResponse\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12&\n" +
 	"\x0fmac_secret_path\x18\x02 \x01(\tR\rmacSecretPath\x12\x19\n" +
@@ -1030,7 +1904,12 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
 	"updated_at\x18\n" +
-	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x92\x04\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12Q\n" +
+	"#subscription_amount_change_min_days\x18\v \x01(\x05H\x00R\x1fsubscriptionAmountChangeMinDays\x88\x01\x01\x12\x1f\n" +
+	"\vdata_region\x18\f \x01(\tR\n" +
+	"dataRegion\x12\x16\n" +
+	"\x06status\x18\r \x01(\tR\x06statusB&\n" +
+	"$_subscription_amount_change_min_days\"\xeb\x05\n" +
 	"\x05Agent\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
 	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12&\n" +
@@ -1046,10 +1925,16 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
 	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x129\n" +
-	"\bmetadata\x18\f \x03(\v2\x1d.agent.v1.Agent.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\f \x03(\v2\x1d.agent.v1.Agent.MetadataEntryR\bmetadata\x12Q\n" +
+	"#subscription_amount_change_min_days\x18\r \x01(\x05H\x00R\x1fsubscriptionAmountChangeMinDays\x88\x01\x01\x12\x1f\n" +
+	"\vdata_region\x18\x0e \x01(\tR\n" +
+	"dataRegion\x12\x16\n" +
+	"\x06status\x18\x0f \x01(\tR\x06status\x12#\n" +
+	"\rstatus_reason\x18\x10 \x01(\tR\fstatusReason\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd7\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B&\n" +
+	"$_subscription_amount_change_min_days\"\xd7\x01\n" +
 	"\fAgentSummary\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1b\n" +
 	"\tmerch_nbr\x18\x02 \x01(\tR\bmerchNbr\x127\n" +
@@ -1060,15 +1945,20 @@ const file_proto_agent_v1_agent_proto_rawDesc = "" +
 	"\vEnvironment\x12\x1b\n" +
 	"\x17ENVIRONMENT_UNSPECIFIED\x10\x00\x12\x17\n" +
 	"\x13ENVIRONMENT_SANDBOX\x10\x01\x12\x1a\n" +
-	"\x16ENVIRONMENT_PRODUCTION\x10\x022\xb3\x03\n" +
+	"\x16ENVIRONMENT_PRODUCTION\x10\x022\xd1\x06\n" +
 	"\fAgentService\x12H\n" +
 	"\rRegisterAgent\x12\x1e.agent.v1.RegisterAgentRequest\x1a\x17.agent.v1.AgentResponse\x126\n" +
 	"\bGetAgent\x12\x19.agent.v1.GetAgentRequest\x1a\x0f.agent.v1.Agent\x12G\n" +
 	"\n" +
 	"ListAgents\x12\x1b.agent.v1.ListAgentsRequest\x1a\x1c.agent.v1.ListAgentsResponse\x12D\n" +
 	"\vUpdateAgent\x12\x1c.agent.v1.UpdateAgentRequest\x1a\x17.agent.v1.AgentResponse\x12L\n" +
-	"\x0fDeactivateAgent\x12 .agent.v1.DeactivateAgentRequest\x1a\x17.agent.v1.AgentResponse\x12D\n" +
-	"\tRotateMAC\x12\x1a.agent.v1.RotateMACRequest\x1a\x1b.agent.v1.RotateMACResponseB>Z<github.com/kevin07696/payment-service/proto/agent/v1;agentv1b\x06proto3"
+	"\x0fDeactivateAgent\x12 .agent.v1.DeactivateAgentRequest\x1a\x17.agent.v1.AgentResponse\x12G\n" +
+	"\x0fSuspendMerchant\x12 .agent.v1.SuspendMerchantRequest\x1a\x12.agent.v1.Merchant\x12M\n" +
+	"\x12ReactivateMerchant\x12#.agent.v1.ReactivateMerchantRequest\x1a\x12.agent.v1.Merchant\x12D\n" +
+	"\tRotateMAC\x12\x1a.agent.v1.RotateMACRequest\x1a\x1b.agent.v1.RotateMACResponse\x12l\n" +
+	"\x1aGetEffectiveMerchantConfig\x12+.agent.v1.GetEffectiveMerchantConfigRequest\x1a!.agent.v1.EffectiveMerchantConfig\x12?\n" +
+	"\vGetMerchant\x12\x1c.agent.v1.GetMerchantRequest\x1a\x12.agent.v1.Merchant\x12U\n" +
+	"\x16UpdateMerchantSettings\x12'.agent.v1.UpdateMerchantSettingsRequest\x1a\x12.agent.v1.MerchantB>Z<github.com/kevin07696/payment-service/proto/agent/v1;agentv1b\x06proto3"
 
 var (
 	file_proto_agent_v1_agent_proto_rawDescOnce sync.Once
@@ -1083,59 +1973,85 @@ func file_proto_agent_v1_agent_proto_rawDescGZIP() []byte {
 }
 
 var file_proto_agent_v1_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_agent_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_proto_agent_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
 var file_proto_agent_v1_agent_proto_goTypes = []any{
-	(Environment)(0),               // 0: agent.v1.Environment
-	(*RegisterAgentRequest)(nil),   // 1: agent.v1.RegisterAgentRequest
-	(*GetAgentRequest)(nil),        // 2: agent.v1.GetAgentRequest
-	(*ListAgentsRequest)(nil),      // 3: agent.v1.ListAgentsRequest
-	(*ListAgentsResponse)(nil),     // 4: agent.v1.ListAgentsResponse
-	(*UpdateAgentRequest)(nil),     // 5: agent.v1.UpdateAgentRequest
-	(*DeactivateAgentRequest)(nil), // 6: agent.v1.DeactivateAgentRequest
-	(*RotateMACRequest)(nil),       // 7: agent.v1.RotateMACRequest
-	(*RotateMACResponse)(nil),      // 8: agent.v1.RotateMACResponse
-	(*AgentResponse)(nil),          // 9: agent.v1.AgentResponse
-	(*Agent)(nil),                  // 10: agent.v1.Agent
-	(*AgentSummary)(nil),           // 11: agent.v1.AgentSummary
-	nil,                            // 12: agent.v1.RegisterAgentRequest.MetadataEntry
-	nil,                            // 13: agent.v1.UpdateAgentRequest.MetadataEntry
-	nil,                            // 14: agent.v1.Agent.MetadataEntry
-	(*timestamppb.Timestamp)(nil),  // 15: google.protobuf.Timestamp
+	(Environment)(0),                          // 0: agent.v1.Environment
+	(*RegisterAgentRequest)(nil),              // 1: agent.v1.RegisterAgentRequest
+	(*GetAgentRequest)(nil),                   // 2: agent.v1.GetAgentRequest
+	(*ListAgentsRequest)(nil),                 // 3: agent.v1.ListAgentsRequest
+	(*ListAgentsResponse)(nil),                // 4: agent.v1.ListAgentsResponse
+	(*UpdateAgentRequest)(nil),                // 5: agent.v1.UpdateAgentRequest
+	(*DeactivateAgentRequest)(nil),            // 6: agent.v1.DeactivateAgentRequest
+	(*SuspendMerchantRequest)(nil),            // 7: agent.v1.SuspendMerchantRequest
+	(*ReactivateMerchantRequest)(nil),         // 8: agent.v1.ReactivateMerchantRequest
+	(*RotateMACRequest)(nil),                  // 9: agent.v1.RotateMACRequest
+	(*GetEffectiveMerchantConfigRequest)(nil), // 10: agent.v1.GetEffectiveMerchantConfigRequest
+	(*EffectiveMerchantConfig)(nil),           // 11: agent.v1.EffectiveMerchantConfig
+	(*VelocityLimit)(nil),                     // 12: agent.v1.VelocityLimit
+	(*GetMerchantRequest)(nil),                // 13: agent.v1.GetMerchantRequest
+	(*UpdateMerchantSettingsRequest)(nil),     // 14: agent.v1.UpdateMerchantSettingsRequest
+	(*Merchant)(nil),                          // 15: agent.v1.Merchant
+	(*RotateMACResponse)(nil),                 // 16: agent.v1.RotateMACResponse
+	(*AgentResponse)(nil),                     // 17: agent.v1.AgentResponse
+	(*Agent)(nil),                             // 18: agent.v1.Agent
+	(*AgentSummary)(nil),                      // 19: agent.v1.AgentSummary
+	nil,                                       // 20: agent.v1.RegisterAgentRequest.MetadataEntry
+	nil,                                       // 21: agent.v1.UpdateAgentRequest.MetadataEntry
+	nil,                                       // 22: agent.v1.Agent.MetadataEntry
+	(*timestamppb.Timestamp)(nil),             // 23: google.protobuf.Timestamp
 }
 var file_proto_agent_v1_agent_proto_depIdxs = []int32{
 	0,  // 0: agent.v1.RegisterAgentRequest.environment:type_name -> agent.v1.Environment
-	12, // 1: agent.v1.RegisterAgentRequest.metadata:type_name -> agent.v1.RegisterAgentRequest.MetadataEntry
+	20, // 1: agent.v1.RegisterAgentRequest.metadata:type_name -> agent.v1.RegisterAgentRequest.MetadataEntry
 	0,  // 2: agent.v1.ListAgentsRequest.environment:type_name -> agent.v1.Environment
-	11, // 3: agent.v1.ListAgentsResponse.agents:type_name -> agent.v1.AgentSummary
+	19, // 3: agent.v1.ListAgentsResponse.agents:type_name -> agent.v1.AgentSummary
 	0,  // 4: agent.v1.UpdateAgentRequest.environment:type_name -> agent.v1.Environment
-	13, // 5: agent.v1.UpdateAgentRequest.metadata:type_name -> agent.v1.UpdateAgentRequest.MetadataEntry
-	15, // 6: agent.v1.RotateMACResponse.rotated_at:type_name -> google.protobuf.Timestamp
-	0,  // 7: agent.v1.AgentResponse.environment:type_name -> agent.v1.Environment
-	15, // 8: agent.v1.AgentResponse.created_at:type_name -> google.protobuf.Timestamp
-	15, // 9: agent.v1.AgentResponse.updated_at:type_name -> google.protobuf.Timestamp
-	0,  // 10: agent.v1.Agent.environment:type_name -> agent.v1.Environment
-	15, // 11: agent.v1.Agent.created_at:type_name -> google.protobuf.Timestamp
-	15, // 12: agent.v1.Agent.updated_at:type_name -> google.protobuf.Timestamp
-	14, // 13: agent.v1.Agent.metadata:type_name -> agent.v1.Agent.MetadataEntry
-	0,  // 14: agent.v1.AgentSummary.environment:type_name -> agent.v1.Environment
-	15, // 15: agent.v1.AgentSummary.created_at:type_name -> google.protobuf.Timestamp
-	1,  // 16: agent.v1.AgentService.RegisterAgent:input_type -> agent.v1.RegisterAgentRequest
-	2,  // 17: agent.v1.AgentService.GetAgent:input_type -> agent.v1.GetAgentRequest
-	3,  // 18: agent.v1.AgentService.ListAgents:input_type -> agent.v1.ListAgentsRequest
-	5,  // 19: agent.v1.AgentService.UpdateAgent:input_type -> agent.v1.UpdateAgentRequest
-	6,  // 20: agent.v1.AgentService.DeactivateAgent:input_type -> agent.v1.DeactivateAgentRequest
-	7,  // 21: agent.v1.AgentService.RotateMAC:input_type -> agent.v1.RotateMACRequest
-	9,  // 22: agent.v1.AgentService.RegisterAgent:output_type -> agent.v1.AgentResponse
-	10, // 23: agent.v1.AgentService.GetAgent:output_type -> agent.v1.Agent
-	4,  // 24: agent.v1.AgentService.ListAgents:output_type -> agent.v1.ListAgentsResponse
-	9,  // 25: agent.v1.AgentService.UpdateAgent:output_type -> agent.v1.AgentResponse
-	9,  // 26: agent.v1.AgentService.DeactivateAgent:output_type -> agent.v1.AgentResponse
-	8,  // 27: agent.v1.AgentService.RotateMAC:output_type -> agent.v1.RotateMACResponse
-	22, // [22:28] is the sub-list for method output_type
-	16, // [16:22] is the sub-list for method input_type
-	16, // [16:16] is the sub-list for extension type_name
-	16, // [16:16] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	21, // 5: agent.v1.UpdateAgentRequest.metadata:type_name -> agent.v1.UpdateAgentRequest.MetadataEntry
+	12, // 6: agent.v1.EffectiveMerchantConfig.card_velocity:type_name -> agent.v1.VelocityLimit
+	12, // 7: agent.v1.EffectiveMerchantConfig.customer_velocity:type_name -> agent.v1.VelocityLimit
+	0,  // 8: agent.v1.Merchant.environment:type_name -> agent.v1.Environment
+	11, // 9: agent.v1.Merchant.config:type_name -> agent.v1.EffectiveMerchantConfig
+	23, // 10: agent.v1.Merchant.created_at:type_name -> google.protobuf.Timestamp
+	23, // 11: agent.v1.Merchant.updated_at:type_name -> google.protobuf.Timestamp
+	23, // 12: agent.v1.Merchant.suspended_at:type_name -> google.protobuf.Timestamp
+	23, // 13: agent.v1.Merchant.closed_at:type_name -> google.protobuf.Timestamp
+	23, // 14: agent.v1.RotateMACResponse.rotated_at:type_name -> google.protobuf.Timestamp
+	0,  // 15: agent.v1.AgentResponse.environment:type_name -> agent.v1.Environment
+	23, // 16: agent.v1.AgentResponse.created_at:type_name -> google.protobuf.Timestamp
+	23, // 17: agent.v1.AgentResponse.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 18: agent.v1.Agent.environment:type_name -> agent.v1.Environment
+	23, // 19: agent.v1.Agent.created_at:type_name -> google.protobuf.Timestamp
+	23, // 20: agent.v1.Agent.updated_at:type_name -> google.protobuf.Timestamp
+	22, // 21: agent.v1.Agent.metadata:type_name -> agent.v1.Agent.MetadataEntry
+	0,  // 22: agent.v1.AgentSummary.environment:type_name -> agent.v1.Environment
+	23, // 23: agent.v1.AgentSummary.created_at:type_name -> google.protobuf.Timestamp
+	1,  // 24: agent.v1.AgentService.RegisterAgent:input_type -> agent.v1.RegisterAgentRequest
+	2,  // 25: agent.v1.AgentService.GetAgent:input_type -> agent.v1.GetAgentRequest
+	3,  // 26: agent.v1.AgentService.ListAgents:input_type -> agent.v1.ListAgentsRequest
+	5,  // 27: agent.v1.AgentService.UpdateAgent:input_type -> agent.v1.UpdateAgentRequest
+	6,  // 28: agent.v1.AgentService.DeactivateAgent:input_type -> agent.v1.DeactivateAgentRequest
+	7,  // 29: agent.v1.AgentService.SuspendMerchant:input_type -> agent.v1.SuspendMerchantRequest
+	8,  // 30: agent.v1.AgentService.ReactivateMerchant:input_type -> agent.v1.ReactivateMerchantRequest
+	9,  // 31: agent.v1.AgentService.RotateMAC:input_type -> agent.v1.RotateMACRequest
+	10, // 32: agent.v1.AgentService.GetEffectiveMerchantConfig:input_type -> agent.v1.GetEffectiveMerchantConfigRequest
+	13, // 33: agent.v1.AgentService.GetMerchant:input_type -> agent.v1.GetMerchantRequest
+	14, // 34: agent.v1.AgentService.UpdateMerchantSettings:input_type -> agent.v1.UpdateMerchantSettingsRequest
+	17, // 35: agent.v1.AgentService.RegisterAgent:output_type -> agent.v1.AgentResponse
+	18, // 36: agent.v1.AgentService.GetAgent:output_type -> agent.v1.Agent
+	4,  // 37: agent.v1.AgentService.ListAgents:output_type -> agent.v1.ListAgentsResponse
+	17, // 38: agent.v1.AgentService.UpdateAgent:output_type -> agent.v1.AgentResponse
+	17, // 39: agent.v1.AgentService.DeactivateAgent:output_type -> agent.v1.AgentResponse
+	15, // 40: agent.v1.AgentService.SuspendMerchant:output_type -> agent.v1.Merchant
+	15, // 41: agent.v1.AgentService.ReactivateMerchant:output_type -> agent.v1.Merchant
+	16, // 42: agent.v1.AgentService.RotateMAC:output_type -> agent.v1.RotateMACResponse
+	11, // 43: agent.v1.AgentService.GetEffectiveMerchantConfig:output_type -> agent.v1.EffectiveMerchantConfig
+	15, // 44: agent.v1.AgentService.GetMerchant:output_type -> agent.v1.Merchant
+	15, // 45: agent.v1.AgentService.UpdateMerchantSettings:output_type -> agent.v1.Merchant
+	35, // [35:46] is the sub-list for method output_type
+	24, // [24:35] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
 }
 
 func init() { file_proto_agent_v1_agent_proto_init() }
@@ -1145,13 +2061,16 @@ func file_proto_agent_v1_agent_proto_init() {
 	}
 	file_proto_agent_v1_agent_proto_msgTypes[2].OneofWrappers = []any{}
 	file_proto_agent_v1_agent_proto_msgTypes[4].OneofWrappers = []any{}
+	file_proto_agent_v1_agent_proto_msgTypes[13].OneofWrappers = []any{}
+	file_proto_agent_v1_agent_proto_msgTypes[16].OneofWrappers = []any{}
+	file_proto_agent_v1_agent_proto_msgTypes[17].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_agent_v1_agent_proto_rawDesc), len(file_proto_agent_v1_agent_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   14,
+			NumMessages:   22,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,12 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AgentService_RegisterAgent_FullMethodName   = "/agent.v1.AgentService/RegisterAgent"
-	AgentService_GetAgent_FullMethodName        = "/agent.v1.AgentService/GetAgent"
-	AgentService_ListAgents_FullMethodName      = "/agent.v1.AgentService/ListAgents"
-	AgentService_UpdateAgent_FullMethodName     = "/agent.v1.AgentService/UpdateAgent"
-	AgentService_DeactivateAgent_FullMethodName = "/agent.v1.AgentService/DeactivateAgent"
-	AgentService_RotateMAC_FullMethodName       = "/agent.v1.AgentService/RotateMAC"
+	AgentService_RegisterAgent_FullMethodName              = "/agent.v1.AgentService/RegisterAgent"
+	AgentService_GetAgent_FullMethodName                   = "/agent.v1.AgentService/GetAgent"
+	AgentService_ListAgents_FullMethodName                 = "/agent.v1.AgentService/ListAgents"
+	AgentService_UpdateAgent_FullMethodName                = "/agent.v1.AgentService/UpdateAgent"
+	AgentService_DeactivateAgent_FullMethodName            = "/agent.v1.AgentService/DeactivateAgent"
+	AgentService_SuspendMerchant_FullMethodName            = "/agent.v1.AgentService/SuspendMerchant"
+	AgentService_ReactivateMerchant_FullMethodName         = "/agent.v1.AgentService/ReactivateMerchant"
+	AgentService_RotateMAC_FullMethodName                  = "/agent.v1.AgentService/RotateMAC"
+	AgentService_GetEffectiveMerchantConfig_FullMethodName = "/agent.v1.AgentService/GetEffectiveMerchantConfig"
+	AgentService_GetMerchant_FullMethodName                = "/agent.v1.AgentService/GetMerchant"
+	AgentService_UpdateMerchantSettings_FullMethodName     = "/agent.v1.AgentService/UpdateMerchantSettings"
 )
 
 // AgentServiceClient is the client API for AgentService service.
@@ -42,10 +47,20 @@ type AgentServiceClient interface {
 	ListAgents(ctx context.Context, in *ListAgentsRequest, opts ...grpc.CallOption) (*ListAgentsResponse, error)
 	// UpdateAgent updates agent credentials
 	UpdateAgent(ctx context.Context, in *UpdateAgentRequest, opts ...grpc.CallOption) (*AgentResponse, error)
-	// DeactivateAgent deactivates an agent
+	// DeactivateAgent closes a merchant: every payment operation is rejected and it can't be reactivated
 	DeactivateAgent(ctx context.Context, in *DeactivateAgentRequest, opts ...grpc.CallOption) (*AgentResponse, error)
+	// SuspendMerchant stops new charges until the merchant is reactivated; voids and refunds still go through
+	SuspendMerchant(ctx context.Context, in *SuspendMerchantRequest, opts ...grpc.CallOption) (*Merchant, error)
+	// ReactivateMerchant lifts a suspension
+	ReactivateMerchant(ctx context.Context, in *ReactivateMerchantRequest, opts ...grpc.CallOption) (*Merchant, error)
 	// RotateMAC rotates MAC secret in secret manager
 	RotateMAC(ctx context.Context, in *RotateMACRequest, opts ...grpc.CallOption) (*RotateMACResponse, error)
+	// GetEffectiveMerchantConfig returns the resolved configuration a payment operation would use
+	GetEffectiveMerchantConfig(ctx context.Context, in *GetEffectiveMerchantConfigRequest, opts ...grpc.CallOption) (*EffectiveMerchantConfig, error)
+	// GetMerchant returns a merchant's own profile and configuration (no EPX credentials), for the merchant's services
+	GetMerchant(ctx context.Context, in *GetMerchantRequest, opts ...grpc.CallOption) (*Merchant, error)
+	// UpdateMerchantSettings changes a merchant's self-service settings
+	UpdateMerchantSettings(ctx context.Context, in *UpdateMerchantSettingsRequest, opts ...grpc.CallOption) (*Merchant, error)
 }
 
 type agentServiceClient struct {
@@ -106,6 +121,26 @@ func (c *agentServiceClient) DeactivateAgent(ctx context.Context, in *Deactivate
 	return out, nil
 }
 
+func (c *agentServiceClient) SuspendMerchant(ctx context.Context, in *SuspendMerchantRequest, opts ...grpc.CallOption) (*Merchant, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Merchant)
+	err := c.cc.Invoke(ctx, AgentService_SuspendMerchant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ReactivateMerchant(ctx context.Context, in *ReactivateMerchantRequest, opts ...grpc.CallOption) (*Merchant, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Merchant)
+	err := c.cc.Invoke(ctx, AgentService_ReactivateMerchant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentServiceClient) RotateMAC(ctx context.Context, in *RotateMACRequest, opts ...grpc.CallOption) (*RotateMACResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(RotateMACResponse)
@@ -116,6 +151,36 @@ func (c *agentServiceClient) RotateMAC(ctx context.Context, in *RotateMACRequest
 	return out, nil
 }
 
+func (c *agentServiceClient) GetEffectiveMerchantConfig(ctx context.Context, in *GetEffectiveMerchantConfigRequest, opts ...grpc.CallOption) (*EffectiveMerchantConfig, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EffectiveMerchantConfig)
+	err := c.cc.Invoke(ctx, AgentService_GetEffectiveMerchantConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetMerchant(ctx context.Context, in *GetMerchantRequest, opts ...grpc.CallOption) (*Merchant, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Merchant)
+	err := c.cc.Invoke(ctx, AgentService_GetMerchant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) UpdateMerchantSettings(ctx context.Context, in *UpdateMerchantSettingsRequest, opts ...grpc.CallOption) (*Merchant, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Merchant)
+	err := c.cc.Invoke(ctx, AgentService_UpdateMerchantSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentServiceServer is the server API for AgentService service.
 // All implementations must embed UnimplementedAgentServiceServer
 // for forward compatibility.
@@ -131,10 +196,20 @@ type AgentServiceServer interface {
 	ListAgents(context.Context, *ListAgentsRequest) (*ListAgentsResponse, error)
 	// UpdateAgent updates agent credentials
 	UpdateAgent(context.Context, *UpdateAgentRequest) (*AgentResponse, error)
-	// DeactivateAgent deactivates an agent
+	// DeactivateAgent closes a merchant: every payment operation is rejected and it can't be reactivated
 	DeactivateAgent(context.Context, *DeactivateAgentRequest) (*AgentResponse, error)
+	// SuspendMerchant stops new charges until the merchant is reactivated; voids and refunds still go through
+	SuspendMerchant(context.Context, *SuspendMerchantRequest) (*Merchant, error)
+	// ReactivateMerchant lifts a suspension
+	ReactivateMerchant(context.Context, *ReactivateMerchantRequest) (*Merchant, error)
 	// RotateMAC rotates MAC secret in secret manager
 	RotateMAC(context.Context, *RotateMACRequest) (*RotateMACResponse, error)
+	// GetEffectiveMerchantConfig returns the resolved configuration a payment operation would use
+	GetEffectiveMerchantConfig(context.Context, *GetEffectiveMerchantConfigRequest) (*EffectiveMerchantConfig, error)
+	// GetMerchant returns a merchant's own profile and configuration (no EPX credentials), for the merchant's services
+	GetMerchant(context.Context, *GetMerchantRequest) (*Merchant, error)
+	// UpdateMerchantSettings changes a merchant's self-service settings
+	UpdateMerchantSettings(context.Context, *UpdateMerchantSettingsRequest) (*Merchant, error)
 	mustEmbedUnimplementedAgentServiceServer()
 }
 
@@ -160,9 +235,24 @@ func (UnimplementedAgentServiceServer) UpdateAgent(context.Context, *UpdateAgent
 func (UnimplementedAgentServiceServer) DeactivateAgent(context.Context, *DeactivateAgentRequest) (*AgentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeactivateAgent not implemented")
 }
+func (UnimplementedAgentServiceServer) SuspendMerchant(context.Context, *SuspendMerchantRequest) (*Merchant, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SuspendMerchant not implemented")
+}
+func (UnimplementedAgentServiceServer) ReactivateMerchant(context.Context, *ReactivateMerchantRequest) (*Merchant, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReactivateMerchant not implemented")
+}
 func (UnimplementedAgentServiceServer) RotateMAC(context.Context, *RotateMACRequest) (*RotateMACResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RotateMAC not implemented")
 }
+func (UnimplementedAgentServiceServer) GetEffectiveMerchantConfig(context.Context, *GetEffectiveMerchantConfigRequest) (*EffectiveMerchantConfig, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEffectiveMerchantConfig not implemented")
+}
+func (UnimplementedAgentServiceServer) GetMerchant(context.Context, *GetMerchantRequest) (*Merchant, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMerchant not implemented")
+}
+func (UnimplementedAgentServiceServer) UpdateMerchantSettings(context.Context, *UpdateMerchantSettingsRequest) (*Merchant, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMerchantSettings not implemented")
+}
 func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
 func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
 
@@ -274,6 +364,42 @@ func _AgentService_DeactivateAgent_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_SuspendMerchant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuspendMerchantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).SuspendMerchant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_SuspendMerchant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).SuspendMerchant(ctx, req.(*SuspendMerchantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ReactivateMerchant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReactivateMerchantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ReactivateMerchant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ReactivateMerchant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ReactivateMerchant(ctx, req.(*ReactivateMerchantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentService_RotateMAC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RotateMACRequest)
 	if err := dec(in); err != nil {
@@ -292,6 +418,60 @@ func _AgentService_RotateMAC_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_GetEffectiveMerchantConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEffectiveMerchantConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetEffectiveMerchantConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetEffectiveMerchantConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetEffectiveMerchantConfig(ctx, req.(*GetEffectiveMerchantConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetMerchant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMerchantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetMerchant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetMerchant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetMerchant(ctx, req.(*GetMerchantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_UpdateMerchantSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMerchantSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).UpdateMerchantSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_UpdateMerchantSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).UpdateMerchantSettings(ctx, req.(*UpdateMerchantSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -319,10 +499,30 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeactivateAgent",
 			Handler:    _AgentService_DeactivateAgent_Handler,
 		},
+		{
+			MethodName: "SuspendMerchant",
+			Handler:    _AgentService_SuspendMerchant_Handler,
+		},
+		{
+			MethodName: "ReactivateMerchant",
+			Handler:    _AgentService_ReactivateMerchant_Handler,
+		},
 		{
 			MethodName: "RotateMAC",
 			Handler:    _AgentService_RotateMAC_Handler,
 		},
+		{
+			MethodName: "GetEffectiveMerchantConfig",
+			Handler:    _AgentService_GetEffectiveMerchantConfig_Handler,
+		},
+		{
+			MethodName: "GetMerchant",
+			Handler:    _AgentService_GetMerchant_Handler,
+		},
+		{
+			MethodName: "UpdateMerchantSettings",
+			Handler:    _AgentService_UpdateMerchantSettings_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/agent/v1/agent.proto",
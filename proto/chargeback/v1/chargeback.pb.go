@@ -23,17 +23,18 @@ const (
 )
 
 // ChargebackStatus represents the chargeback state
-// Matches database constraint: ('new', 'pending', 'responded', 'won', 'lost', 'accepted')
+// Matches database constraint: ('new', 'pending', 'responded', 'under_review', 'won', 'lost', 'accepted')
 type ChargebackStatus int32
 
 const (
-	ChargebackStatus_CHARGEBACK_STATUS_UNSPECIFIED ChargebackStatus = 0
-	ChargebackStatus_CHARGEBACK_STATUS_NEW         ChargebackStatus = 1
-	ChargebackStatus_CHARGEBACK_STATUS_PENDING     ChargebackStatus = 2
-	ChargebackStatus_CHARGEBACK_STATUS_RESPONDED   ChargebackStatus = 3
-	ChargebackStatus_CHARGEBACK_STATUS_WON         ChargebackStatus = 4
-	ChargebackStatus_CHARGEBACK_STATUS_LOST        ChargebackStatus = 5
-	ChargebackStatus_CHARGEBACK_STATUS_ACCEPTED    ChargebackStatus = 6
+	ChargebackStatus_CHARGEBACK_STATUS_UNSPECIFIED  ChargebackStatus = 0
+	ChargebackStatus_CHARGEBACK_STATUS_NEW          ChargebackStatus = 1
+	ChargebackStatus_CHARGEBACK_STATUS_PENDING      ChargebackStatus = 2
+	ChargebackStatus_CHARGEBACK_STATUS_RESPONDED    ChargebackStatus = 3
+	ChargebackStatus_CHARGEBACK_STATUS_WON          ChargebackStatus = 4
+	ChargebackStatus_CHARGEBACK_STATUS_LOST         ChargebackStatus = 5
+	ChargebackStatus_CHARGEBACK_STATUS_ACCEPTED     ChargebackStatus = 6
+	ChargebackStatus_CHARGEBACK_STATUS_UNDER_REVIEW ChargebackStatus = 7 // Reported by North while the issuer reviews the response
 )
 
 // Enum value maps for ChargebackStatus.
@@ -46,15 +47,17 @@ var (
 		4: "CHARGEBACK_STATUS_WON",
 		5: "CHARGEBACK_STATUS_LOST",
 		6: "CHARGEBACK_STATUS_ACCEPTED",
+		7: "CHARGEBACK_STATUS_UNDER_REVIEW",
 	}
 	ChargebackStatus_value = map[string]int32{
-		"CHARGEBACK_STATUS_UNSPECIFIED": 0,
-		"CHARGEBACK_STATUS_NEW":         1,
-		"CHARGEBACK_STATUS_PENDING":     2,
-		"CHARGEBACK_STATUS_RESPONDED":   3,
-		"CHARGEBACK_STATUS_WON":         4,
-		"CHARGEBACK_STATUS_LOST":        5,
-		"CHARGEBACK_STATUS_ACCEPTED":    6,
+		"CHARGEBACK_STATUS_UNSPECIFIED":  0,
+		"CHARGEBACK_STATUS_NEW":          1,
+		"CHARGEBACK_STATUS_PENDING":      2,
+		"CHARGEBACK_STATUS_RESPONDED":    3,
+		"CHARGEBACK_STATUS_WON":          4,
+		"CHARGEBACK_STATUS_LOST":         5,
+		"CHARGEBACK_STATUS_ACCEPTED":     6,
+		"CHARGEBACK_STATUS_UNDER_REVIEW": 7,
 	}
 )
 
@@ -85,6 +88,56 @@ func (ChargebackStatus) EnumDescriptor() ([]byte, []int) {
 	return file_proto_chargeback_v1_chargeback_proto_rawDescGZIP(), []int{0}
 }
 
+// ChargebackMatchStatus says whether dispute sync linked the chargeback to its transaction group
+type ChargebackMatchStatus int32
+
+const (
+	ChargebackMatchStatus_CHARGEBACK_MATCH_STATUS_UNSPECIFIED ChargebackMatchStatus = 0 // Synced before matching existed
+	ChargebackMatchStatus_CHARGEBACK_MATCH_STATUS_MATCHED     ChargebackMatchStatus = 1 // group_id is set
+	ChargebackMatchStatus_CHARGEBACK_MATCH_STATUS_UNMATCHED   ChargebackMatchStatus = 2 // No confident match; needs manual review
+)
+
+// Enum value maps for ChargebackMatchStatus.
+var (
+	ChargebackMatchStatus_name = map[int32]string{
+		0: "CHARGEBACK_MATCH_STATUS_UNSPECIFIED",
+		1: "CHARGEBACK_MATCH_STATUS_MATCHED",
+		2: "CHARGEBACK_MATCH_STATUS_UNMATCHED",
+	}
+	ChargebackMatchStatus_value = map[string]int32{
+		"CHARGEBACK_MATCH_STATUS_UNSPECIFIED": 0,
+		"CHARGEBACK_MATCH_STATUS_MATCHED":     1,
+		"CHARGEBACK_MATCH_STATUS_UNMATCHED":   2,
+	}
+)
+
+func (x ChargebackMatchStatus) Enum() *ChargebackMatchStatus {
+	p := new(ChargebackMatchStatus)
+	*p = x
+	return p
+}
+
+func (x ChargebackMatchStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ChargebackMatchStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_chargeback_v1_chargeback_proto_enumTypes[1].Descriptor()
+}
+
+func (ChargebackMatchStatus) Type() protoreflect.EnumType {
+	return &file_proto_chargeback_v1_chargeback_proto_enumTypes[1]
+}
+
+func (x ChargebackMatchStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ChargebackMatchStatus.Descriptor instead.
+func (ChargebackMatchStatus) EnumDescriptor() ([]byte, []int) {
+	return file_proto_chargeback_v1_chargeback_proto_rawDescGZIP(), []int{1}
+}
+
 // GetChargebackRequest retrieves a chargeback by ID
 type GetChargebackRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -149,6 +202,9 @@ type ListChargebacksRequest struct {
 	DisputeDateTo   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=dispute_date_to,json=disputeDateTo,proto3,oneof" json:"dispute_date_to,omitempty"`
 	Limit           int32                  `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"` // Default: 100
 	Offset          int32                  `protobuf:"varint,8,opt,name=offset,proto3" json:"offset,omitempty"`
+	MatchStatus     *ChargebackMatchStatus `protobuf:"varint,9,opt,name=match_status,json=matchStatus,proto3,enum=chargeback.v1.ChargebackMatchStatus,oneof" json:"match_status,omitempty"` // Optional: e.g. UNMATCHED for the manual review queue
+	MinAmountCents  *int64                 `protobuf:"varint,10,opt,name=min_amount_cents,json=minAmountCents,proto3,oneof" json:"min_amount_cents,omitempty"`                              // Optional: chargeback amount >= this (inclusive)
+	MaxAmountCents  *int64                 `protobuf:"varint,11,opt,name=max_amount_cents,json=maxAmountCents,proto3,oneof" json:"max_amount_cents,omitempty"`                              // Optional: chargeback amount <= this (inclusive)
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -239,6 +295,27 @@ func (x *ListChargebacksRequest) GetOffset() int32 {
 	return 0
 }
 
+func (x *ListChargebacksRequest) GetMatchStatus() ChargebackMatchStatus {
+	if x != nil && x.MatchStatus != nil {
+		return *x.MatchStatus
+	}
+	return ChargebackMatchStatus_CHARGEBACK_MATCH_STATUS_UNSPECIFIED
+}
+
+func (x *ListChargebacksRequest) GetMinAmountCents() int64 {
+	if x != nil && x.MinAmountCents != nil {
+		return *x.MinAmountCents
+	}
+	return 0
+}
+
+func (x *ListChargebacksRequest) GetMaxAmountCents() int64 {
+	if x != nil && x.MaxAmountCents != nil {
+		return *x.MaxAmountCents
+	}
+	return 0
+}
+
 // ListChargebacksResponse contains chargeback list
 type ListChargebacksResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -292,7 +369,76 @@ func (x *ListChargebacksResponse) GetTotalCount() int32 {
 	return 0
 }
 
-// Chargeback represents a complete chargeback record (read-only)
+// SubmitChargebackEvidenceRequest is a merchant's representment of a new or pending chargeback
+type SubmitChargebackEvidenceRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ChargebackId     string                 `protobuf:"bytes,1,opt,name=chargeback_id,json=chargebackId,proto3" json:"chargeback_id,omitempty"`               // UUID
+	AgentId          string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`                              // For authorization
+	EvidenceFileUrls []string               `protobuf:"bytes,3,rep,name=evidence_file_urls,json=evidenceFileUrls,proto3" json:"evidence_file_urls,omitempty"` // References to documents already uploaded to blob storage
+	Narrative        string                 `protobuf:"bytes,4,opt,name=narrative,proto3" json:"narrative,omitempty"`                                         // Required: rebuttal explaining why the chargeback is invalid
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SubmitChargebackEvidenceRequest) Reset() {
+	*x = SubmitChargebackEvidenceRequest{}
+	mi := &file_proto_chargeback_v1_chargeback_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitChargebackEvidenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitChargebackEvidenceRequest) ProtoMessage() {}
+
+func (x *SubmitChargebackEvidenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_chargeback_v1_chargeback_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitChargebackEvidenceRequest.ProtoReflect.Descriptor instead.
+func (*SubmitChargebackEvidenceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_chargeback_v1_chargeback_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SubmitChargebackEvidenceRequest) GetChargebackId() string {
+	if x != nil {
+		return x.ChargebackId
+	}
+	return ""
+}
+
+func (x *SubmitChargebackEvidenceRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *SubmitChargebackEvidenceRequest) GetEvidenceFileUrls() []string {
+	if x != nil {
+		return x.EvidenceFileUrls
+	}
+	return nil
+}
+
+func (x *SubmitChargebackEvidenceRequest) GetNarrative() string {
+	if x != nil {
+		return x.Narrative
+	}
+	return ""
+}
+
+// Chargeback represents a complete chargeback record
 type Chargeback struct {
 	state      protoimpl.MessageState `protogen:"open.v1"`
 	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -312,19 +458,22 @@ type Chargeback struct {
 	RespondByDate       *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=respond_by_date,json=respondByDate,proto3,oneof" json:"respond_by_date,omitempty"`
 	ResponseSubmittedAt *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=response_submitted_at,json=responseSubmittedAt,proto3,oneof" json:"response_submitted_at,omitempty"`
 	ResolvedAt          *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=resolved_at,json=resolvedAt,proto3,oneof" json:"resolved_at,omitempty"`
-	// Evidence and response (read-only - merchants respond via North portal)
-	EvidenceFileUrls []string               `protobuf:"bytes,16,rep,name=evidence_file_urls,json=evidenceFileUrls,proto3" json:"evidence_file_urls,omitempty"` // File URLs if North API provides them
-	ResponseText     *string                `protobuf:"bytes,17,opt,name=response_text,json=responseText,proto3,oneof" json:"response_text,omitempty"`         // Response text if submitted via North portal
+	// Evidence and response (from North, or stored with SubmitChargebackEvidence)
+	EvidenceFileUrls []string               `protobuf:"bytes,16,rep,name=evidence_file_urls,json=evidenceFileUrls,proto3" json:"evidence_file_urls,omitempty"` // Evidence file references
+	ResponseText     *string                `protobuf:"bytes,17,opt,name=response_text,json=responseText,proto3,oneof" json:"response_text,omitempty"`         // Rebuttal narrative
 	InternalNotes    *string                `protobuf:"bytes,18,opt,name=internal_notes,json=internalNotes,proto3,oneof" json:"internal_notes,omitempty"`      // Internal notes (local tracking only)
 	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt        *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	// 3-D Secure result sent with the disputed authorization (GetChargeback only; unset when 3DS was not run)
+	ThreeDs       *ThreeDSecureEvidence `protobuf:"bytes,21,opt,name=three_ds,json=threeDs,proto3" json:"three_ds,omitempty"`
+	MatchStatus   ChargebackMatchStatus `protobuf:"varint,22,opt,name=match_status,json=matchStatus,proto3,enum=chargeback.v1.ChargebackMatchStatus" json:"match_status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Chargeback) Reset() {
 	*x = Chargeback{}
-	mi := &file_proto_chargeback_v1_chargeback_proto_msgTypes[3]
+	mi := &file_proto_chargeback_v1_chargeback_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -336,7 +485,7 @@ func (x *Chargeback) String() string {
 func (*Chargeback) ProtoMessage() {}
 
 func (x *Chargeback) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_chargeback_v1_chargeback_proto_msgTypes[3]
+	mi := &file_proto_chargeback_v1_chargeback_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -349,7 +498,7 @@ func (x *Chargeback) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Chargeback.ProtoReflect.Descriptor instead.
 func (*Chargeback) Descriptor() ([]byte, []int) {
-	return file_proto_chargeback_v1_chargeback_proto_rawDescGZIP(), []int{3}
+	return file_proto_chargeback_v1_chargeback_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Chargeback) GetId() string {
@@ -492,6 +641,97 @@ func (x *Chargeback) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Chargeback) GetThreeDs() *ThreeDSecureEvidence {
+	if x != nil {
+		return x.ThreeDs
+	}
+	return nil
+}
+
+func (x *Chargeback) GetMatchStatus() ChargebackMatchStatus {
+	if x != nil {
+		return x.MatchStatus
+	}
+	return ChargebackMatchStatus_CHARGEBACK_MATCH_STATUS_UNSPECIFIED
+}
+
+// ThreeDSecureEvidence is the authentication result that supports a fraud liability shift
+type ThreeDSecureEvidence struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Version           string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Cavv              string                 `protobuf:"bytes,2,opt,name=cavv,proto3" json:"cavv,omitempty"`
+	Eci               string                 `protobuf:"bytes,3,opt,name=eci,proto3" json:"eci,omitempty"`
+	TransactionStatus string                 `protobuf:"bytes,4,opt,name=transaction_status,json=transactionStatus,proto3" json:"transaction_status,omitempty"` // EMV 3DS transStatus: Y, A, N, U or R
+	DsTransactionId   string                 `protobuf:"bytes,5,opt,name=ds_transaction_id,json=dsTransactionId,proto3" json:"ds_transaction_id,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ThreeDSecureEvidence) Reset() {
+	*x = ThreeDSecureEvidence{}
+	mi := &file_proto_chargeback_v1_chargeback_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ThreeDSecureEvidence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThreeDSecureEvidence) ProtoMessage() {}
+
+func (x *ThreeDSecureEvidence) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_chargeback_v1_chargeback_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThreeDSecureEvidence.ProtoReflect.Descriptor instead.
+func (*ThreeDSecureEvidence) Descriptor() ([]byte, []int) {
+	return file_proto_chargeback_v1_chargeback_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ThreeDSecureEvidence) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ThreeDSecureEvidence) GetCavv() string {
+	if x != nil {
+		return x.Cavv
+	}
+	return ""
+}
+
+func (x *ThreeDSecureEvidence) GetEci() string {
+	if x != nil {
+		return x.Eci
+	}
+	return ""
+}
+
+func (x *ThreeDSecureEvidence) GetTransactionStatus() string {
+	if x != nil {
+		return x.TransactionStatus
+	}
+	return ""
+}
+
+func (x *ThreeDSecureEvidence) GetDsTransactionId() string {
+	if x != nil {
+		return x.DsTransactionId
+	}
+	return ""
+}
+
 var File_proto_chargeback_v1_chargeback_proto protoreflect.FileDescriptor
 
 const file_proto_chargeback_v1_chargeback_proto_rawDesc = "" +
@@ -499,7 +739,7 @@ const file_proto_chargeback_v1_chargeback_proto_rawDesc = "" +
 	"$proto/chargeback/v1/chargeback.proto\x12\rchargeback.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"V\n" +
 	"\x14GetChargebackRequest\x12#\n" +
 	"\rchargeback_id\x18\x01 \x01(\tR\fchargebackId\x12\x19\n" +
-	"\bagent_id\x18\x02 \x01(\tR\aagentId\"\xcd\x03\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\"\xb4\x05\n" +
 	"\x16ListChargebacksRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12$\n" +
 	"\vcustomer_id\x18\x02 \x01(\tH\x00R\n" +
@@ -509,16 +749,28 @@ const file_proto_chargeback_v1_chargeback_proto_rawDesc = "" +
 	"\x11dispute_date_from\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampH\x03R\x0fdisputeDateFrom\x88\x01\x01\x12G\n" +
 	"\x0fdispute_date_to\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampH\x04R\rdisputeDateTo\x88\x01\x01\x12\x14\n" +
 	"\x05limit\x18\a \x01(\x05R\x05limit\x12\x16\n" +
-	"\x06offset\x18\b \x01(\x05R\x06offsetB\x0e\n" +
+	"\x06offset\x18\b \x01(\x05R\x06offset\x12L\n" +
+	"\fmatch_status\x18\t \x01(\x0e2$.chargeback.v1.ChargebackMatchStatusH\x05R\vmatchStatus\x88\x01\x01\x12-\n" +
+	"\x10min_amount_cents\x18\n" +
+	" \x01(\x03H\x06R\x0eminAmountCents\x88\x01\x01\x12-\n" +
+	"\x10max_amount_cents\x18\v \x01(\x03H\aR\x0emaxAmountCents\x88\x01\x01B\x0e\n" +
 	"\f_customer_idB\v\n" +
 	"\t_group_idB\t\n" +
 	"\a_statusB\x14\n" +
 	"\x12_dispute_date_fromB\x12\n" +
-	"\x10_dispute_date_to\"w\n" +
+	"\x10_dispute_date_toB\x0f\n" +
+	"\r_match_statusB\x13\n" +
+	"\x11_min_amount_centsB\x13\n" +
+	"\x11_max_amount_cents\"w\n" +
 	"\x17ListChargebacksResponse\x12;\n" +
 	"\vchargebacks\x18\x01 \x03(\v2\x19.chargeback.v1.ChargebackR\vchargebacks\x12\x1f\n" +
 	"\vtotal_count\x18\x02 \x01(\x05R\n" +
-	"totalCount\"\xa7\b\n" +
+	"totalCount\"\xad\x01\n" +
+	"\x1fSubmitChargebackEvidenceRequest\x12#\n" +
+	"\rchargeback_id\x18\x01 \x01(\tR\fchargebackId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12,\n" +
+	"\x12evidence_file_urls\x18\x03 \x03(\tR\x10evidenceFileUrls\x12\x1c\n" +
+	"\tnarrative\x18\x04 \x01(\tR\tnarrative\"\xb0\t\n" +
 	"\n" +
 	"Chargeback\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
@@ -547,12 +799,20 @@ const file_proto_chargeback_v1_chargeback_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\x14 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAtB\x12\n" +
+	"updated_at\x18\x14 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12>\n" +
+	"\bthree_ds\x18\x15 \x01(\v2#.chargeback.v1.ThreeDSecureEvidenceR\athreeDs\x12G\n" +
+	"\fmatch_status\x18\x16 \x01(\x0e2$.chargeback.v1.ChargebackMatchStatusR\vmatchStatusB\x12\n" +
 	"\x10_respond_by_dateB\x18\n" +
 	"\x16_response_submitted_atB\x0e\n" +
 	"\f_resolved_atB\x10\n" +
 	"\x0e_response_textB\x11\n" +
-	"\x0f_internal_notes*\xe7\x01\n" +
+	"\x0f_internal_notes\"\xb1\x01\n" +
+	"\x14ThreeDSecureEvidence\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x12\n" +
+	"\x04cavv\x18\x02 \x01(\tR\x04cavv\x12\x10\n" +
+	"\x03eci\x18\x03 \x01(\tR\x03eci\x12-\n" +
+	"\x12transaction_status\x18\x04 \x01(\tR\x11transactionStatus\x12*\n" +
+	"\x11ds_transaction_id\x18\x05 \x01(\tR\x0fdsTransactionId*\x8b\x02\n" +
 	"\x10ChargebackStatus\x12!\n" +
 	"\x1dCHARGEBACK_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
 	"\x15CHARGEBACK_STATUS_NEW\x10\x01\x12\x1d\n" +
@@ -560,10 +820,16 @@ const file_proto_chargeback_v1_chargeback_proto_rawDesc = "" +
 	"\x1bCHARGEBACK_STATUS_RESPONDED\x10\x03\x12\x19\n" +
 	"\x15CHARGEBACK_STATUS_WON\x10\x04\x12\x1a\n" +
 	"\x16CHARGEBACK_STATUS_LOST\x10\x05\x12\x1e\n" +
-	"\x1aCHARGEBACK_STATUS_ACCEPTED\x10\x062\xc6\x01\n" +
+	"\x1aCHARGEBACK_STATUS_ACCEPTED\x10\x06\x12\"\n" +
+	"\x1eCHARGEBACK_STATUS_UNDER_REVIEW\x10\a*\x8c\x01\n" +
+	"\x15ChargebackMatchStatus\x12'\n" +
+	"#CHARGEBACK_MATCH_STATUS_UNSPECIFIED\x10\x00\x12#\n" +
+	"\x1fCHARGEBACK_MATCH_STATUS_MATCHED\x10\x01\x12%\n" +
+	"!CHARGEBACK_MATCH_STATUS_UNMATCHED\x10\x022\xad\x02\n" +
 	"\x11ChargebackService\x12O\n" +
 	"\rGetChargeback\x12#.chargeback.v1.GetChargebackRequest\x1a\x19.chargeback.v1.Chargeback\x12`\n" +
-	"\x0fListChargebacks\x12%.chargeback.v1.ListChargebacksRequest\x1a&.chargeback.v1.ListChargebacksResponseBHZFgithub.com/kevin07696/payment-service/proto/chargeback/v1;chargebackv1b\x06proto3"
+	"\x0fListChargebacks\x12%.chargeback.v1.ListChargebacksRequest\x1a&.chargeback.v1.ListChargebacksResponse\x12e\n" +
+	"\x18SubmitChargebackEvidence\x12..chargeback.v1.SubmitChargebackEvidenceRequest\x1a\x19.chargeback.v1.ChargebackBHZFgithub.com/kevin07696/payment-service/proto/chargeback/v1;chargebackv1b\x06proto3"
 
 var (
 	file_proto_chargeback_v1_chargeback_proto_rawDescOnce sync.Once
@@ -577,38 +843,46 @@ func file_proto_chargeback_v1_chargeback_proto_rawDescGZIP() []byte {
 	return file_proto_chargeback_v1_chargeback_proto_rawDescData
 }
 
-var file_proto_chargeback_v1_chargeback_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_chargeback_v1_chargeback_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_chargeback_v1_chargeback_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_chargeback_v1_chargeback_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_proto_chargeback_v1_chargeback_proto_goTypes = []any{
-	(ChargebackStatus)(0),           // 0: chargeback.v1.ChargebackStatus
-	(*GetChargebackRequest)(nil),    // 1: chargeback.v1.GetChargebackRequest
-	(*ListChargebacksRequest)(nil),  // 2: chargeback.v1.ListChargebacksRequest
-	(*ListChargebacksResponse)(nil), // 3: chargeback.v1.ListChargebacksResponse
-	(*Chargeback)(nil),              // 4: chargeback.v1.Chargeback
-	(*timestamppb.Timestamp)(nil),   // 5: google.protobuf.Timestamp
+	(ChargebackStatus)(0),                   // 0: chargeback.v1.ChargebackStatus
+	(ChargebackMatchStatus)(0),              // 1: chargeback.v1.ChargebackMatchStatus
+	(*GetChargebackRequest)(nil),            // 2: chargeback.v1.GetChargebackRequest
+	(*ListChargebacksRequest)(nil),          // 3: chargeback.v1.ListChargebacksRequest
+	(*ListChargebacksResponse)(nil),         // 4: chargeback.v1.ListChargebacksResponse
+	(*SubmitChargebackEvidenceRequest)(nil), // 5: chargeback.v1.SubmitChargebackEvidenceRequest
+	(*Chargeback)(nil),                      // 6: chargeback.v1.Chargeback
+	(*ThreeDSecureEvidence)(nil),            // 7: chargeback.v1.ThreeDSecureEvidence
+	(*timestamppb.Timestamp)(nil),           // 8: google.protobuf.Timestamp
 }
 var file_proto_chargeback_v1_chargeback_proto_depIdxs = []int32{
 	0,  // 0: chargeback.v1.ListChargebacksRequest.status:type_name -> chargeback.v1.ChargebackStatus
-	5,  // 1: chargeback.v1.ListChargebacksRequest.dispute_date_from:type_name -> google.protobuf.Timestamp
-	5,  // 2: chargeback.v1.ListChargebacksRequest.dispute_date_to:type_name -> google.protobuf.Timestamp
-	4,  // 3: chargeback.v1.ListChargebacksResponse.chargebacks:type_name -> chargeback.v1.Chargeback
-	5,  // 4: chargeback.v1.Chargeback.dispute_date:type_name -> google.protobuf.Timestamp
-	5,  // 5: chargeback.v1.Chargeback.chargeback_date:type_name -> google.protobuf.Timestamp
-	0,  // 6: chargeback.v1.Chargeback.status:type_name -> chargeback.v1.ChargebackStatus
-	5,  // 7: chargeback.v1.Chargeback.respond_by_date:type_name -> google.protobuf.Timestamp
-	5,  // 8: chargeback.v1.Chargeback.response_submitted_at:type_name -> google.protobuf.Timestamp
-	5,  // 9: chargeback.v1.Chargeback.resolved_at:type_name -> google.protobuf.Timestamp
-	5,  // 10: chargeback.v1.Chargeback.created_at:type_name -> google.protobuf.Timestamp
-	5,  // 11: chargeback.v1.Chargeback.updated_at:type_name -> google.protobuf.Timestamp
-	1,  // 12: chargeback.v1.ChargebackService.GetChargeback:input_type -> chargeback.v1.GetChargebackRequest
-	2,  // 13: chargeback.v1.ChargebackService.ListChargebacks:input_type -> chargeback.v1.ListChargebacksRequest
-	4,  // 14: chargeback.v1.ChargebackService.GetChargeback:output_type -> chargeback.v1.Chargeback
-	3,  // 15: chargeback.v1.ChargebackService.ListChargebacks:output_type -> chargeback.v1.ListChargebacksResponse
-	14, // [14:16] is the sub-list for method output_type
-	12, // [12:14] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	8,  // 1: chargeback.v1.ListChargebacksRequest.dispute_date_from:type_name -> google.protobuf.Timestamp
+	8,  // 2: chargeback.v1.ListChargebacksRequest.dispute_date_to:type_name -> google.protobuf.Timestamp
+	1,  // 3: chargeback.v1.ListChargebacksRequest.match_status:type_name -> chargeback.v1.ChargebackMatchStatus
+	6,  // 4: chargeback.v1.ListChargebacksResponse.chargebacks:type_name -> chargeback.v1.Chargeback
+	8,  // 5: chargeback.v1.Chargeback.dispute_date:type_name -> google.protobuf.Timestamp
+	8,  // 6: chargeback.v1.Chargeback.chargeback_date:type_name -> google.protobuf.Timestamp
+	0,  // 7: chargeback.v1.Chargeback.status:type_name -> chargeback.v1.ChargebackStatus
+	8,  // 8: chargeback.v1.Chargeback.respond_by_date:type_name -> google.protobuf.Timestamp
+	8,  // 9: chargeback.v1.Chargeback.response_submitted_at:type_name -> google.protobuf.Timestamp
+	8,  // 10: chargeback.v1.Chargeback.resolved_at:type_name -> google.protobuf.Timestamp
+	8,  // 11: chargeback.v1.Chargeback.created_at:type_name -> google.protobuf.Timestamp
+	8,  // 12: chargeback.v1.Chargeback.updated_at:type_name -> google.protobuf.Timestamp
+	7,  // 13: chargeback.v1.Chargeback.three_ds:type_name -> chargeback.v1.ThreeDSecureEvidence
+	1,  // 14: chargeback.v1.Chargeback.match_status:type_name -> chargeback.v1.ChargebackMatchStatus
+	2,  // 15: chargeback.v1.ChargebackService.GetChargeback:input_type -> chargeback.v1.GetChargebackRequest
+	3,  // 16: chargeback.v1.ChargebackService.ListChargebacks:input_type -> chargeback.v1.ListChargebacksRequest
+	5,  // 17: chargeback.v1.ChargebackService.SubmitChargebackEvidence:input_type -> chargeback.v1.SubmitChargebackEvidenceRequest
+	6,  // 18: chargeback.v1.ChargebackService.GetChargeback:output_type -> chargeback.v1.Chargeback
+	4,  // 19: chargeback.v1.ChargebackService.ListChargebacks:output_type -> chargeback.v1.ListChargebacksResponse
+	6,  // 20: chargeback.v1.ChargebackService.SubmitChargebackEvidence:output_type -> chargeback.v1.Chargeback
+	18, // [18:21] is the sub-list for method output_type
+	15, // [15:18] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_proto_chargeback_v1_chargeback_proto_init() }
@@ -617,14 +891,14 @@ func file_proto_chargeback_v1_chargeback_proto_init() {
 		return
 	}
 	file_proto_chargeback_v1_chargeback_proto_msgTypes[1].OneofWrappers = []any{}
-	file_proto_chargeback_v1_chargeback_proto_msgTypes[3].OneofWrappers = []any{}
+	file_proto_chargeback_v1_chargeback_proto_msgTypes[4].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_chargeback_v1_chargeback_proto_rawDesc), len(file_proto_chargeback_v1_chargeback_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   4,
+			NumEnums:      2,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,22 +19,25 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ChargebackService_GetChargeback_FullMethodName   = "/chargeback.v1.ChargebackService/GetChargeback"
-	ChargebackService_ListChargebacks_FullMethodName = "/chargeback.v1.ChargebackService/ListChargebacks"
+	ChargebackService_GetChargeback_FullMethodName            = "/chargeback.v1.ChargebackService/GetChargeback"
+	ChargebackService_ListChargebacks_FullMethodName          = "/chargeback.v1.ChargebackService/ListChargebacks"
+	ChargebackService_SubmitChargebackEvidence_FullMethodName = "/chargeback.v1.ChargebackService/SubmitChargebackEvidence"
 )
 
 // ChargebackServiceClient is the client API for ChargebackService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
-// ChargebackService handles dispute management operations (read-only)
-// Note: North API does not provide write operations for disputes.
-// Merchants must respond to chargebacks through North's web portal.
+// ChargebackService handles dispute management operations.
+// Disputes are synced from North, which has no dispute write API; merchants respond through
+// North's web portal, using evidence stored with SubmitChargebackEvidence.
 type ChargebackServiceClient interface {
 	// GetChargeback retrieves a specific chargeback by ID
 	GetChargeback(ctx context.Context, in *GetChargebackRequest, opts ...grpc.CallOption) (*Chargeback, error)
 	// ListChargebacks lists chargebacks with filters
 	ListChargebacks(ctx context.Context, in *ListChargebacksRequest, opts ...grpc.CallOption) (*ListChargebacksResponse, error)
+	// SubmitChargebackEvidence stores evidence and a rebuttal for the merchant to submit through North's portal
+	SubmitChargebackEvidence(ctx context.Context, in *SubmitChargebackEvidenceRequest, opts ...grpc.CallOption) (*Chargeback, error)
 }
 
 type chargebackServiceClient struct {
@@ -65,18 +68,30 @@ func (c *chargebackServiceClient) ListChargebacks(ctx context.Context, in *ListC
 	return out, nil
 }
 
+func (c *chargebackServiceClient) SubmitChargebackEvidence(ctx context.Context, in *SubmitChargebackEvidenceRequest, opts ...grpc.CallOption) (*Chargeback, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Chargeback)
+	err := c.cc.Invoke(ctx, ChargebackService_SubmitChargebackEvidence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ChargebackServiceServer is the server API for ChargebackService service.
 // All implementations must embed UnimplementedChargebackServiceServer
 // for forward compatibility.
 //
-// ChargebackService handles dispute management operations (read-only)
-// Note: North API does not provide write operations for disputes.
-// Merchants must respond to chargebacks through North's web portal.
+// ChargebackService handles dispute management operations.
+// Disputes are synced from North, which has no dispute write API; merchants respond through
+// North's web portal, using evidence stored with SubmitChargebackEvidence.
 type ChargebackServiceServer interface {
 	// GetChargeback retrieves a specific chargeback by ID
 	GetChargeback(context.Context, *GetChargebackRequest) (*Chargeback, error)
 	// ListChargebacks lists chargebacks with filters
 	ListChargebacks(context.Context, *ListChargebacksRequest) (*ListChargebacksResponse, error)
+	// SubmitChargebackEvidence stores evidence and a rebuttal for the merchant to submit through North's portal
+	SubmitChargebackEvidence(context.Context, *SubmitChargebackEvidenceRequest) (*Chargeback, error)
 	mustEmbedUnimplementedChargebackServiceServer()
 }
 
@@ -93,6 +108,9 @@ func (UnimplementedChargebackServiceServer) GetChargeback(context.Context, *GetC
 func (UnimplementedChargebackServiceServer) ListChargebacks(context.Context, *ListChargebacksRequest) (*ListChargebacksResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListChargebacks not implemented")
 }
+func (UnimplementedChargebackServiceServer) SubmitChargebackEvidence(context.Context, *SubmitChargebackEvidenceRequest) (*Chargeback, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitChargebackEvidence not implemented")
+}
 func (UnimplementedChargebackServiceServer) mustEmbedUnimplementedChargebackServiceServer() {}
 func (UnimplementedChargebackServiceServer) testEmbeddedByValue()                           {}
 
@@ -150,6 +168,24 @@ func _ChargebackService_ListChargebacks_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ChargebackService_SubmitChargebackEvidence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitChargebackEvidenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChargebackServiceServer).SubmitChargebackEvidence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChargebackService_SubmitChargebackEvidence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChargebackServiceServer).SubmitChargebackEvidence(ctx, req.(*SubmitChargebackEvidenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ChargebackService_ServiceDesc is the grpc.ServiceDesc for ChargebackService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -165,6 +201,10 @@ var ChargebackService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListChargebacks",
 			Handler:    _ChargebackService_ListChargebacks_Handler,
 		},
+		{
+			MethodName: "SubmitChargebackEvidence",
+			Handler:    _ChargebackService_SubmitChargebackEvidence_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/chargeback/v1/chargeback.proto",
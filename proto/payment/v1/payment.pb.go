@@ -23,7 +23,7 @@ const (
 )
 
 // TransactionStatus represents the current state of a transaction
-// Matches database constraint: ('pending', 'completed', 'failed', 'refunded', 'voided')
+// Matches database constraint: ('pending', 'completed', 'failed', 'refunded', 'voided', 'expired')
 type TransactionStatus int32
 
 const (
@@ -33,6 +33,7 @@ const (
 	TransactionStatus_TRANSACTION_STATUS_FAILED      TransactionStatus = 3
 	TransactionStatus_TRANSACTION_STATUS_REFUNDED    TransactionStatus = 4
 	TransactionStatus_TRANSACTION_STATUS_VOIDED      TransactionStatus = 5
+	TransactionStatus_TRANSACTION_STATUS_EXPIRED     TransactionStatus = 6 // Browser Post callback didn't arrive before the pending transaction expired
 )
 
 // Enum value maps for TransactionStatus.
@@ -44,6 +45,7 @@ var (
 		3: "TRANSACTION_STATUS_FAILED",
 		4: "TRANSACTION_STATUS_REFUNDED",
 		5: "TRANSACTION_STATUS_VOIDED",
+		6: "TRANSACTION_STATUS_EXPIRED",
 	}
 	TransactionStatus_value = map[string]int32{
 		"TRANSACTION_STATUS_UNSPECIFIED": 0,
@@ -52,6 +54,7 @@ var (
 		"TRANSACTION_STATUS_FAILED":      3,
 		"TRANSACTION_STATUS_REFUNDED":    4,
 		"TRANSACTION_STATUS_VOIDED":      5,
+		"TRANSACTION_STATUS_EXPIRED":     6,
 	}
 )
 
@@ -93,6 +96,8 @@ const (
 	TransactionType_TRANSACTION_TYPE_CHARGE      TransactionType = 3 // Combined auth + capture (sale)
 	TransactionType_TRANSACTION_TYPE_REFUND      TransactionType = 4 // Return funds
 	TransactionType_TRANSACTION_TYPE_PRE_NOTE    TransactionType = 5 // ACH verification
+	TransactionType_TRANSACTION_TYPE_REVERSAL    TransactionType = 6 // Partial reversal of an authorization
+	TransactionType_TRANSACTION_TYPE_INCREMENT   TransactionType = 7 // Incremental authorization
 )
 
 // Enum value maps for TransactionType.
@@ -104,6 +109,8 @@ var (
 		3: "TRANSACTION_TYPE_CHARGE",
 		4: "TRANSACTION_TYPE_REFUND",
 		5: "TRANSACTION_TYPE_PRE_NOTE",
+		6: "TRANSACTION_TYPE_REVERSAL",
+		7: "TRANSACTION_TYPE_INCREMENT",
 	}
 	TransactionType_value = map[string]int32{
 		"TRANSACTION_TYPE_UNSPECIFIED": 0,
@@ -112,6 +119,8 @@ var (
 		"TRANSACTION_TYPE_CHARGE":      3,
 		"TRANSACTION_TYPE_REFUND":      4,
 		"TRANSACTION_TYPE_PRE_NOTE":    5,
+		"TRANSACTION_TYPE_REVERSAL":    6,
+		"TRANSACTION_TYPE_INCREMENT":   7,
 	}
 )
 
@@ -205,11 +214,15 @@ type AuthorizeRequest struct {
 	//
 	//	*AuthorizeRequest_PaymentMethodId
 	//	*AuthorizeRequest_PaymentToken
-	PaymentMethod  isAuthorizeRequest_PaymentMethod `protobuf_oneof:"payment_method"`
-	IdempotencyKey string                           `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
-	Metadata       map[string]string                `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	PaymentMethod       isAuthorizeRequest_PaymentMethod `protobuf_oneof:"payment_method"`
+	IdempotencyKey      string                           `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Metadata            map[string]string                `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	IncludeTree         *bool                            `protobuf:"varint,9,opt,name=include_tree,json=includeTree,proto3,oneof" json:"include_tree,omitempty"`                         // Include the transaction group tree (unset = merchant default)
+	ThreeDs             *ThreeDSecure                    `protobuf:"bytes,10,opt,name=three_ds,json=threeDs,proto3" json:"three_ds,omitempty"`                                           // 3-D Secure result for card-not-present payments (unset when not run)
+	CustomerEmail       string                           `protobuf:"bytes,11,opt,name=customer_email,json=customerEmail,proto3" json:"customer_email,omitempty"`                         // Stored when the merchant's customer_policy auto-creates an unknown customer_id
+	StatementDescriptor *string                          `protobuf:"bytes,12,opt,name=statement_descriptor,json=statementDescriptor,proto3,oneof" json:"statement_descriptor,omitempty"` // Overrides the merchant's statement descriptor for this charge (max 22 characters)
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *AuthorizeRequest) Reset() {
@@ -309,6 +322,34 @@ func (x *AuthorizeRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *AuthorizeRequest) GetIncludeTree() bool {
+	if x != nil && x.IncludeTree != nil {
+		return *x.IncludeTree
+	}
+	return false
+}
+
+func (x *AuthorizeRequest) GetThreeDs() *ThreeDSecure {
+	if x != nil {
+		return x.ThreeDs
+	}
+	return nil
+}
+
+func (x *AuthorizeRequest) GetCustomerEmail() string {
+	if x != nil {
+		return x.CustomerEmail
+	}
+	return ""
+}
+
+func (x *AuthorizeRequest) GetStatementDescriptor() string {
+	if x != nil && x.StatementDescriptor != nil {
+		return *x.StatementDescriptor
+	}
+	return ""
+}
+
 type isAuthorizeRequest_PaymentMethod interface {
 	isAuthorizeRequest_PaymentMethod()
 }
@@ -325,19 +366,97 @@ func (*AuthorizeRequest_PaymentMethodId) isAuthorizeRequest_PaymentMethod() {}
 
 func (*AuthorizeRequest_PaymentToken) isAuthorizeRequest_PaymentMethod() {}
 
+// ThreeDSecure is the result of a 3-D Secure authentication run by the merchant before the payment
+type ThreeDSecure struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Version           string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`                                              // Protocol version, e.g. "2.2.0"; 3-D Secure 2 only
+	Cavv              string                 `protobuf:"bytes,2,opt,name=cavv,proto3" json:"cavv,omitempty"`                                                    // Authentication value (CAVV/AAV), 28 base64 or 40 hex chars; required when transaction_status is Y or A
+	Eci               string                 `protobuf:"bytes,3,opt,name=eci,proto3" json:"eci,omitempty"`                                                      // Electronic commerce indicator (two digits, e.g. "05")
+	TransactionStatus string                 `protobuf:"bytes,4,opt,name=transaction_status,json=transactionStatus,proto3" json:"transaction_status,omitempty"` // EMV 3DS transStatus: Y, A, N, U or R
+	DsTransactionId   string                 `protobuf:"bytes,5,opt,name=ds_transaction_id,json=dsTransactionId,proto3" json:"ds_transaction_id,omitempty"`     // Directory server transaction ID (required, up to 36 chars)
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ThreeDSecure) Reset() {
+	*x = ThreeDSecure{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ThreeDSecure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThreeDSecure) ProtoMessage() {}
+
+func (x *ThreeDSecure) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThreeDSecure.ProtoReflect.Descriptor instead.
+func (*ThreeDSecure) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ThreeDSecure) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ThreeDSecure) GetCavv() string {
+	if x != nil {
+		return x.Cavv
+	}
+	return ""
+}
+
+func (x *ThreeDSecure) GetEci() string {
+	if x != nil {
+		return x.Eci
+	}
+	return ""
+}
+
+func (x *ThreeDSecure) GetTransactionStatus() string {
+	if x != nil {
+		return x.TransactionStatus
+	}
+	return ""
+}
+
+func (x *ThreeDSecure) GetDsTransactionId() string {
+	if x != nil {
+		return x.DsTransactionId
+	}
+	return ""
+}
+
 // CaptureRequest captures a previously authorized payment
 type CaptureRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	TransactionId  string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"` // Original authorization transaction ID
 	Amount         string                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`                                    // Optional: partial capture amount
 	IdempotencyKey string                 `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	IncludeTree    *bool                  `protobuf:"varint,4,opt,name=include_tree,json=includeTree,proto3,oneof" json:"include_tree,omitempty"` // Include the transaction group tree (unset = merchant default)
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
 func (x *CaptureRequest) Reset() {
 	*x = CaptureRequest{}
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[1]
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -349,7 +468,7 @@ func (x *CaptureRequest) String() string {
 func (*CaptureRequest) ProtoMessage() {}
 
 func (x *CaptureRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[1]
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -362,7 +481,7 @@ func (x *CaptureRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CaptureRequest.ProtoReflect.Descriptor instead.
 func (*CaptureRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{1}
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *CaptureRequest) GetTransactionId() string {
@@ -386,6 +505,151 @@ func (x *CaptureRequest) GetIdempotencyKey() string {
 	return ""
 }
 
+func (x *CaptureRequest) GetIncludeTree() bool {
+	if x != nil && x.IncludeTree != nil {
+		return *x.IncludeTree
+	}
+	return false
+}
+
+// PartialReverseAuthorizationRequest reduces an open authorization
+type PartialReverseAuthorizationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId  string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"` // Original authorization transaction ID
+	Amount         string                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`                                    // Amount to release from the hold (not the new authorized total)
+	IdempotencyKey string                 `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	IncludeTree    *bool                  `protobuf:"varint,4,opt,name=include_tree,json=includeTree,proto3,oneof" json:"include_tree,omitempty"` // Include the transaction group tree (unset = merchant default)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PartialReverseAuthorizationRequest) Reset() {
+	*x = PartialReverseAuthorizationRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PartialReverseAuthorizationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartialReverseAuthorizationRequest) ProtoMessage() {}
+
+func (x *PartialReverseAuthorizationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartialReverseAuthorizationRequest.ProtoReflect.Descriptor instead.
+func (*PartialReverseAuthorizationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PartialReverseAuthorizationRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *PartialReverseAuthorizationRequest) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *PartialReverseAuthorizationRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *PartialReverseAuthorizationRequest) GetIncludeTree() bool {
+	if x != nil && x.IncludeTree != nil {
+		return *x.IncludeTree
+	}
+	return false
+}
+
+// IncrementAuthorizationRequest increases an open authorization
+type IncrementAuthorizationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId  string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"` // Original authorization transaction ID
+	Amount         string                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`                                    // Amount to add to the hold (not the new authorized total)
+	IdempotencyKey string                 `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	IncludeTree    *bool                  `protobuf:"varint,4,opt,name=include_tree,json=includeTree,proto3,oneof" json:"include_tree,omitempty"` // Include the transaction group tree (unset = merchant default)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *IncrementAuthorizationRequest) Reset() {
+	*x = IncrementAuthorizationRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncrementAuthorizationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncrementAuthorizationRequest) ProtoMessage() {}
+
+func (x *IncrementAuthorizationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncrementAuthorizationRequest.ProtoReflect.Descriptor instead.
+func (*IncrementAuthorizationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *IncrementAuthorizationRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *IncrementAuthorizationRequest) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *IncrementAuthorizationRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *IncrementAuthorizationRequest) GetIncludeTree() bool {
+	if x != nil && x.IncludeTree != nil {
+		return *x.IncludeTree
+	}
+	return false
+}
+
 // SaleRequest combines authorize and capture
 type SaleRequest struct {
 	state      protoimpl.MessageState `protogen:"open.v1"`
@@ -399,16 +663,20 @@ type SaleRequest struct {
 	//
 	//	*SaleRequest_PaymentMethodId
 	//	*SaleRequest_PaymentToken
-	PaymentMethod  isSaleRequest_PaymentMethod `protobuf_oneof:"payment_method"`
-	IdempotencyKey string                      `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
-	Metadata       map[string]string           `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	PaymentMethod       isSaleRequest_PaymentMethod `protobuf_oneof:"payment_method"`
+	IdempotencyKey      string                      `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Metadata            map[string]string           `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	IncludeTree         *bool                       `protobuf:"varint,9,opt,name=include_tree,json=includeTree,proto3,oneof" json:"include_tree,omitempty"`                         // Include the transaction group tree (unset = merchant default)
+	ThreeDs             *ThreeDSecure               `protobuf:"bytes,10,opt,name=three_ds,json=threeDs,proto3" json:"three_ds,omitempty"`                                           // 3-D Secure result for card-not-present payments (unset when not run)
+	CustomerEmail       string                      `protobuf:"bytes,11,opt,name=customer_email,json=customerEmail,proto3" json:"customer_email,omitempty"`                         // Stored when the merchant's customer_policy auto-creates an unknown customer_id
+	StatementDescriptor *string                     `protobuf:"bytes,12,opt,name=statement_descriptor,json=statementDescriptor,proto3,oneof" json:"statement_descriptor,omitempty"` // Overrides the merchant's statement descriptor for this charge (max 22 characters)
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *SaleRequest) Reset() {
 	*x = SaleRequest{}
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[2]
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -420,7 +688,7 @@ func (x *SaleRequest) String() string {
 func (*SaleRequest) ProtoMessage() {}
 
 func (x *SaleRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[2]
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -433,7 +701,7 @@ func (x *SaleRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SaleRequest.ProtoReflect.Descriptor instead.
 func (*SaleRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{2}
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *SaleRequest) GetAgentId() string {
@@ -503,6 +771,34 @@ func (x *SaleRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *SaleRequest) GetIncludeTree() bool {
+	if x != nil && x.IncludeTree != nil {
+		return *x.IncludeTree
+	}
+	return false
+}
+
+func (x *SaleRequest) GetThreeDs() *ThreeDSecure {
+	if x != nil {
+		return x.ThreeDs
+	}
+	return nil
+}
+
+func (x *SaleRequest) GetCustomerEmail() string {
+	if x != nil {
+		return x.CustomerEmail
+	}
+	return ""
+}
+
+func (x *SaleRequest) GetStatementDescriptor() string {
+	if x != nil && x.StatementDescriptor != nil {
+		return *x.StatementDescriptor
+	}
+	return ""
+}
+
 type isSaleRequest_PaymentMethod interface {
 	isSaleRequest_PaymentMethod()
 }
@@ -519,30 +815,31 @@ func (*SaleRequest_PaymentMethodId) isSaleRequest_PaymentMethod() {}
 
 func (*SaleRequest_PaymentToken) isSaleRequest_PaymentMethod() {}
 
-// VoidRequest cancels an authorized or captured payment
-type VoidRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	TransactionId  string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	IdempotencyKey string                 `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+// BatchSaleRequest runs several sales for one merchant
+type BatchSaleRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	AgentId             string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	BatchIdempotencyKey string                 `protobuf:"bytes,2,opt,name=batch_idempotency_key,json=batchIdempotencyKey,proto3" json:"batch_idempotency_key,omitempty"` // Optional: reusing it returns the first run's results (complements each item's idempotency_key)
+	Items               []*SaleRequest         `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`                                                          // Max 100; an item's agent_id must be empty or match
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
-func (x *VoidRequest) Reset() {
-	*x = VoidRequest{}
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[3]
+func (x *BatchSaleRequest) Reset() {
+	*x = BatchSaleRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VoidRequest) String() string {
+func (x *BatchSaleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VoidRequest) ProtoMessage() {}
+func (*BatchSaleRequest) ProtoMessage() {}
 
-func (x *VoidRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[3]
+func (x *BatchSaleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -553,51 +850,57 @@ func (x *VoidRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VoidRequest.ProtoReflect.Descriptor instead.
-func (*VoidRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use BatchSaleRequest.ProtoReflect.Descriptor instead.
+func (*BatchSaleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *VoidRequest) GetTransactionId() string {
+func (x *BatchSaleRequest) GetAgentId() string {
 	if x != nil {
-		return x.TransactionId
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *VoidRequest) GetIdempotencyKey() string {
+func (x *BatchSaleRequest) GetBatchIdempotencyKey() string {
 	if x != nil {
-		return x.IdempotencyKey
+		return x.BatchIdempotencyKey
 	}
 	return ""
 }
 
-// RefundRequest refunds a captured payment
-type RefundRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	TransactionId  string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	Amount         string                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"` // Optional: partial refund amount
-	Reason         string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
-	IdempotencyKey string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+func (x *BatchSaleRequest) GetItems() []*SaleRequest {
+	if x != nil {
+		return x.Items
+	}
+	return nil
 }
 
-func (x *RefundRequest) Reset() {
-	*x = RefundRequest{}
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[4]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+// BatchSaleItemResult is the outcome of one batch item
+type BatchSaleItemResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`    // Position in the request
+	Payment       *PaymentResponse       `protobuf:"bytes,2,opt,name=payment,proto3" json:"payment,omitempty"` // Set when a sale was recorded (approved or declined)
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`     // Set when no sale was recorded for the item
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RefundRequest) String() string {
+func (x *BatchSaleItemResult) Reset() {
+	*x = BatchSaleItemResult{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchSaleItemResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RefundRequest) ProtoMessage() {}
+func (*BatchSaleItemResult) ProtoMessage() {}
 
-func (x *RefundRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[4]
+func (x *BatchSaleItemResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -608,62 +911,56 @@ func (x *RefundRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RefundRequest.ProtoReflect.Descriptor instead.
-func (*RefundRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{4}
-}
-
-func (x *RefundRequest) GetTransactionId() string {
-	if x != nil {
-		return x.TransactionId
-	}
-	return ""
+// Deprecated: Use BatchSaleItemResult.ProtoReflect.Descriptor instead.
+func (*BatchSaleItemResult) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *RefundRequest) GetAmount() string {
+func (x *BatchSaleItemResult) GetIndex() int32 {
 	if x != nil {
-		return x.Amount
+		return x.Index
 	}
-	return ""
+	return 0
 }
 
-func (x *RefundRequest) GetReason() string {
+func (x *BatchSaleItemResult) GetPayment() *PaymentResponse {
 	if x != nil {
-		return x.Reason
+		return x.Payment
 	}
-	return ""
+	return nil
 }
 
-func (x *RefundRequest) GetIdempotencyKey() string {
+func (x *BatchSaleItemResult) GetError() string {
 	if x != nil {
-		return x.IdempotencyKey
+		return x.Error
 	}
 	return ""
 }
 
-// GetTransactionRequest retrieves a transaction
-type GetTransactionRequest struct {
+// BatchSaleResponse contains one result per item, in request order
+type BatchSaleResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Results       []*BatchSaleItemResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Replayed      bool                   `protobuf:"varint,2,opt,name=replayed,proto3" json:"replayed,omitempty"` // Results of an earlier run with the same batch_idempotency_key (transactions in their current state)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTransactionRequest) Reset() {
-	*x = GetTransactionRequest{}
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[5]
+func (x *BatchSaleResponse) Reset() {
+	*x = BatchSaleResponse{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTransactionRequest) String() string {
+func (x *BatchSaleResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTransactionRequest) ProtoMessage() {}
+func (*BatchSaleResponse) ProtoMessage() {}
 
-func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[5]
+func (x *BatchSaleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -674,46 +971,50 @@ func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTransactionRequest.ProtoReflect.Descriptor instead.
-func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use BatchSaleResponse.ProtoReflect.Descriptor instead.
+func (*BatchSaleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *GetTransactionRequest) GetTransactionId() string {
+func (x *BatchSaleResponse) GetResults() []*BatchSaleItemResult {
 	if x != nil {
-		return x.TransactionId
+		return x.Results
 	}
-	return ""
+	return nil
 }
 
-// ListTransactionsRequest lists transactions
-type ListTransactionsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	CustomerId    string                 `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`          // Optional: filter by customer
-	GroupId       string                 `protobuf:"bytes,3,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`                   // Optional: get all transactions in a group
-	Status        TransactionStatus      `protobuf:"varint,4,opt,name=status,proto3,enum=payment.v1.TransactionStatus" json:"status,omitempty"` // Optional: filter by status
-	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`                                     // Default: 100
-	Offset        int32                  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *BatchSaleResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
 }
 
-func (x *ListTransactionsRequest) Reset() {
-	*x = ListTransactionsRequest{}
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[6]
+// VoidRequest cancels an authorized or captured payment
+type VoidRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId  string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	IncludeTree    *bool                  `protobuf:"varint,3,opt,name=include_tree,json=includeTree,proto3,oneof" json:"include_tree,omitempty"` // Include the transaction group tree (unset = merchant default)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *VoidRequest) Reset() {
+	*x = VoidRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListTransactionsRequest) String() string {
+func (x *VoidRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListTransactionsRequest) ProtoMessage() {}
+func (*VoidRequest) ProtoMessage() {}
 
-func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[6]
+func (x *VoidRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -724,77 +1025,59 @@ func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
-func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{6}
-}
-
-func (x *ListTransactionsRequest) GetAgentId() string {
-	if x != nil {
-		return x.AgentId
-	}
-	return ""
+// Deprecated: Use VoidRequest.ProtoReflect.Descriptor instead.
+func (*VoidRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *ListTransactionsRequest) GetCustomerId() string {
+func (x *VoidRequest) GetTransactionId() string {
 	if x != nil {
-		return x.CustomerId
+		return x.TransactionId
 	}
 	return ""
 }
 
-func (x *ListTransactionsRequest) GetGroupId() string {
+func (x *VoidRequest) GetIdempotencyKey() string {
 	if x != nil {
-		return x.GroupId
+		return x.IdempotencyKey
 	}
 	return ""
 }
 
-func (x *ListTransactionsRequest) GetStatus() TransactionStatus {
-	if x != nil {
-		return x.Status
-	}
-	return TransactionStatus_TRANSACTION_STATUS_UNSPECIFIED
-}
-
-func (x *ListTransactionsRequest) GetLimit() int32 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
-}
-
-func (x *ListTransactionsRequest) GetOffset() int32 {
-	if x != nil {
-		return x.Offset
+func (x *VoidRequest) GetIncludeTree() bool {
+	if x != nil && x.IncludeTree != nil {
+		return *x.IncludeTree
 	}
-	return 0
+	return false
 }
 
-// ListTransactionsResponse contains transaction list
-type ListTransactionsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
-	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// RefundRequest refunds a captured payment
+type RefundRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId  string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Amount         string                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"` // Optional: partial refund amount
+	Reason         string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	IncludeTree    *bool                  `protobuf:"varint,5,opt,name=include_tree,json=includeTree,proto3,oneof" json:"include_tree,omitempty"` // Include the transaction group tree (unset = merchant default)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *ListTransactionsResponse) Reset() {
-	*x = ListTransactionsResponse{}
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[7]
+func (x *RefundRequest) Reset() {
+	*x = RefundRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListTransactionsResponse) String() string {
+func (x *RefundRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListTransactionsResponse) ProtoMessage() {}
+func (*RefundRequest) ProtoMessage() {}
 
-func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[7]
+func (x *RefundRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -805,67 +1088,75 @@ func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListTransactionsResponse.ProtoReflect.Descriptor instead.
-func (*ListTransactionsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use RefundRequest.ProtoReflect.Descriptor instead.
+func (*RefundRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *ListTransactionsResponse) GetTransactions() []*Transaction {
+func (x *RefundRequest) GetTransactionId() string {
 	if x != nil {
-		return x.Transactions
+		return x.TransactionId
 	}
-	return nil
+	return ""
 }
 
-func (x *ListTransactionsResponse) GetTotalCount() int32 {
+func (x *RefundRequest) GetAmount() string {
 	if x != nil {
-		return x.TotalCount
+		return x.Amount
 	}
-	return 0
+	return ""
 }
 
-// PaymentResponse is returned from payment operations
-type PaymentResponse struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	TransactionId     string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	GroupId           string                 `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"` // Transaction group ID (links related transactions)
-	AgentId           string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	CustomerId        string                 `protobuf:"bytes,4,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
-	Amount            string                 `protobuf:"bytes,5,opt,name=amount,proto3" json:"amount,omitempty"` // Decimal as string
-	Currency          string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
-	Status            TransactionStatus      `protobuf:"varint,7,opt,name=status,proto3,enum=payment.v1.TransactionStatus" json:"status,omitempty"`
-	Type              TransactionType        `protobuf:"varint,8,opt,name=type,proto3,enum=payment.v1.TransactionType" json:"type,omitempty"`
-	PaymentMethodType PaymentMethodType      `protobuf:"varint,9,opt,name=payment_method_type,json=paymentMethodType,proto3,enum=payment.v1.PaymentMethodType" json:"payment_method_type,omitempty"`
-	// EPX Gateway response fields
-	AuthGuid      string                 `protobuf:"bytes,10,opt,name=auth_guid,json=authGuid,proto3" json:"auth_guid,omitempty"`               // EPX token for this transaction
-	AuthResp      string                 `protobuf:"bytes,11,opt,name=auth_resp,json=authResp,proto3" json:"auth_resp,omitempty"`               // EPX approval code ("00" = approved)
-	AuthCode      string                 `protobuf:"bytes,12,opt,name=auth_code,json=authCode,proto3" json:"auth_code,omitempty"`               // Bank authorization code
-	AuthRespText  string                 `protobuf:"bytes,13,opt,name=auth_resp_text,json=authRespText,proto3" json:"auth_resp_text,omitempty"` // Human-readable response message
-	AuthCardType  string                 `protobuf:"bytes,14,opt,name=auth_card_type,json=authCardType,proto3" json:"auth_card_type,omitempty"` // Card brand (V/M/A/D)
-	AuthAvs       string                 `protobuf:"bytes,15,opt,name=auth_avs,json=authAvs,proto3" json:"auth_avs,omitempty"`                  // Address verification result
-	AuthCvv2      string                 `protobuf:"bytes,16,opt,name=auth_cvv2,json=authCvv2,proto3" json:"auth_cvv2,omitempty"`               // CVV verification result
-	IsApproved    bool                   `protobuf:"varint,17,opt,name=is_approved,json=isApproved,proto3" json:"is_approved,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Metadata      map[string]string      `protobuf:"bytes,19,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *RefundRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
 }
 
-func (x *PaymentResponse) Reset() {
-	*x = PaymentResponse{}
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[8]
+func (x *RefundRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *RefundRequest) GetIncludeTree() bool {
+	if x != nil && x.IncludeTree != nil {
+		return *x.IncludeTree
+	}
+	return false
+}
+
+// RefundByReferenceRequest refunds the agent's payment with this EPX reference; set exactly one of auth_guid and tran_nbr
+type RefundByReferenceRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`    // The merchant the payment belongs to
+	AuthGuid       string                 `protobuf:"bytes,2,opt,name=auth_guid,json=authGuid,proto3" json:"auth_guid,omitempty"` // EPX BRIC (AUTH_GUID) from the receipt
+	TranNbr        string                 `protobuf:"bytes,3,opt,name=tran_nbr,json=tranNbr,proto3" json:"tran_nbr,omitempty"`    // EPX TRAN_NBR from the receipt
+	Amount         string                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`                     // Optional: partial refund amount
+	Reason         string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	IncludeTree    *bool                  `protobuf:"varint,7,opt,name=include_tree,json=includeTree,proto3,oneof" json:"include_tree,omitempty"` // Include the transaction group tree (unset = merchant default)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RefundByReferenceRequest) Reset() {
+	*x = RefundByReferenceRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PaymentResponse) String() string {
+func (x *RefundByReferenceRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PaymentResponse) ProtoMessage() {}
+func (*RefundByReferenceRequest) ProtoMessage() {}
 
-func (x *PaymentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[8]
+func (x *RefundByReferenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -876,188 +1167,1790 @@ func (x *PaymentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PaymentResponse.ProtoReflect.Descriptor instead.
-func (*PaymentResponse) Descriptor() ([]byte, []int) {
-	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use RefundByReferenceRequest.ProtoReflect.Descriptor instead.
+func (*RefundByReferenceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *PaymentResponse) GetTransactionId() string {
+func (x *RefundByReferenceRequest) GetAgentId() string {
 	if x != nil {
-		return x.TransactionId
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *PaymentResponse) GetGroupId() string {
+func (x *RefundByReferenceRequest) GetAuthGuid() string {
 	if x != nil {
-		return x.GroupId
+		return x.AuthGuid
 	}
 	return ""
 }
 
-func (x *PaymentResponse) GetAgentId() string {
+func (x *RefundByReferenceRequest) GetTranNbr() string {
 	if x != nil {
-		return x.AgentId
+		return x.TranNbr
 	}
 	return ""
 }
 
-func (x *PaymentResponse) GetCustomerId() string {
+func (x *RefundByReferenceRequest) GetAmount() string {
 	if x != nil {
-		return x.CustomerId
+		return x.Amount
 	}
 	return ""
 }
 
-func (x *PaymentResponse) GetAmount() string {
+func (x *RefundByReferenceRequest) GetReason() string {
 	if x != nil {
-		return x.Amount
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RefundByReferenceRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *RefundByReferenceRequest) GetIncludeTree() bool {
+	if x != nil && x.IncludeTree != nil {
+		return *x.IncludeTree
+	}
+	return false
+}
+
+// GetTransactionRequest retrieves a transaction
+type GetTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionRequest) Reset() {
+	*x = GetTransactionRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionRequest) ProtoMessage() {}
+
+func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetTransactionRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+// GetTransactionStatusesRequest looks up a batch of transactions
+type GetTransactionStatusesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`                      // Only this merchant's transactions are returned
+	TransactionIds []string               `protobuf:"bytes,2,rep,name=transaction_ids,json=transactionIds,proto3" json:"transaction_ids,omitempty"` // Max 100
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetTransactionStatusesRequest) Reset() {
+	*x = GetTransactionStatusesRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionStatusesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionStatusesRequest) ProtoMessage() {}
+
+func (x *GetTransactionStatusesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionStatusesRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionStatusesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetTransactionStatusesRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetTransactionStatusesRequest) GetTransactionIds() []string {
+	if x != nil {
+		return x.TransactionIds
+	}
+	return nil
+}
+
+// GetTransactionStatusesResponse contains one result per requested ID, in request order
+type GetTransactionStatusesResponse struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Results       []*TransactionStatusResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionStatusesResponse) Reset() {
+	*x = GetTransactionStatusesResponse{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionStatusesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionStatusesResponse) ProtoMessage() {}
+
+func (x *GetTransactionStatusesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionStatusesResponse.ProtoReflect.Descriptor instead.
+func (*GetTransactionStatusesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetTransactionStatusesResponse) GetResults() []*TransactionStatusResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// BatchGetTransactionsRequest fetches a batch of transactions by ID
+type BatchGetTransactionsRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`                      // Only this merchant's transactions are returned
+	TransactionIds []string               `protobuf:"bytes,2,rep,name=transaction_ids,json=transactionIds,proto3" json:"transaction_ids,omitempty"` // Max 100
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BatchGetTransactionsRequest) Reset() {
+	*x = BatchGetTransactionsRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetTransactionsRequest) ProtoMessage() {}
+
+func (x *BatchGetTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *BatchGetTransactionsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *BatchGetTransactionsRequest) GetTransactionIds() []string {
+	if x != nil {
+		return x.TransactionIds
+	}
+	return nil
+}
+
+// BatchGetTransactionsResponse contains the accessible transactions in request order
+type BatchGetTransactionsResponse struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Transactions          []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	MissingTransactionIds []string               `protobuf:"bytes,2,rep,name=missing_transaction_ids,json=missingTransactionIds,proto3" json:"missing_transaction_ids,omitempty"` // Requested IDs that don't exist or belong to another merchant
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *BatchGetTransactionsResponse) Reset() {
+	*x = BatchGetTransactionsResponse{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetTransactionsResponse) ProtoMessage() {}
+
+func (x *BatchGetTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BatchGetTransactionsResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *BatchGetTransactionsResponse) GetMissingTransactionIds() []string {
+	if x != nil {
+		return x.MissingTransactionIds
+	}
+	return nil
+}
+
+// TransactionStatusResult is the status of one requested transaction
+type TransactionStatusResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Found         bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"` // False if the ID doesn't exist or belongs to another merchant
+	Status        TransactionStatus      `protobuf:"varint,3,opt,name=status,proto3,enum=payment.v1.TransactionStatus" json:"status,omitempty"`
+	Type          TransactionType        `protobuf:"varint,4,opt,name=type,proto3,enum=payment.v1.TransactionType" json:"type,omitempty"`
+	Amount        string                 `protobuf:"bytes,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	GroupId       string                 `protobuf:"bytes,6,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	SettledAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=settled_at,json=settledAt,proto3" json:"settled_at,omitempty"`       // Unset until settled
+	FundingDate   string                 `protobuf:"bytes,9,opt,name=funding_date,json=fundingDate,proto3" json:"funding_date,omitempty"` // Expected merchant funding date (YYYY-MM-DD)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionStatusResult) Reset() {
+	*x = TransactionStatusResult{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionStatusResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionStatusResult) ProtoMessage() {}
+
+func (x *TransactionStatusResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionStatusResult.ProtoReflect.Descriptor instead.
+func (*TransactionStatusResult) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TransactionStatusResult) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *TransactionStatusResult) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *TransactionStatusResult) GetStatus() TransactionStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TransactionStatus_TRANSACTION_STATUS_UNSPECIFIED
+}
+
+func (x *TransactionStatusResult) GetType() TransactionType {
+	if x != nil {
+		return x.Type
+	}
+	return TransactionType_TRANSACTION_TYPE_UNSPECIFIED
+}
+
+func (x *TransactionStatusResult) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *TransactionStatusResult) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *TransactionStatusResult) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *TransactionStatusResult) GetSettledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SettledAt
+	}
+	return nil
+}
+
+func (x *TransactionStatusResult) GetFundingDate() string {
+	if x != nil {
+		return x.FundingDate
+	}
+	return ""
+}
+
+// ListTransactionsRequest lists transactions
+type ListTransactionsRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AgentId          string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	CustomerId       string                 `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`                                                                                             // Optional: filter by customer
+	GroupId          string                 `protobuf:"bytes,3,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`                                                                                                      // Optional: get all transactions in a group
+	Status           TransactionStatus      `protobuf:"varint,4,opt,name=status,proto3,enum=payment.v1.TransactionStatus" json:"status,omitempty"`                                                                                    // Optional: filter by status
+	Limit            int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`                                                                                                                        // Default: 100
+	Offset           int32                  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`                                                                                                                      // Offset pagination (cannot be combined with cursor)
+	Cursor           string                 `protobuf:"bytes,7,opt,name=cursor,proto3" json:"cursor,omitempty"`                                                                                                                       // Cursor pagination: next_cursor from a previous page
+	MetadataContains map[string]string      `protobuf:"bytes,8,rep,name=metadata_contains,json=metadataContains,proto3" json:"metadata_contains,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Optional: only transactions whose metadata has all these key/value pairs, e.g. {"order_id": "A-1001"}
+	MinAmountCents   *int64                 `protobuf:"varint,9,opt,name=min_amount_cents,json=minAmountCents,proto3,oneof" json:"min_amount_cents,omitempty"`                                                                        // Optional: amount >= this (inclusive)
+	MaxAmountCents   *int64                 `protobuf:"varint,10,opt,name=max_amount_cents,json=maxAmountCents,proto3,oneof" json:"max_amount_cents,omitempty"`                                                                       // Optional: amount <= this (inclusive)
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListTransactionsRequest) Reset() {
+	*x = ListTransactionsRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsRequest) ProtoMessage() {}
+
+func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListTransactionsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetStatus() TransactionStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TransactionStatus_TRANSACTION_STATUS_UNSPECIFIED
+}
+
+func (x *ListTransactionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetMetadataContains() map[string]string {
+	if x != nil {
+		return x.MetadataContains
+	}
+	return nil
+}
+
+func (x *ListTransactionsRequest) GetMinAmountCents() int64 {
+	if x != nil && x.MinAmountCents != nil {
+		return *x.MinAmountCents
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetMaxAmountCents() int64 {
+	if x != nil && x.MaxAmountCents != nil {
+		return *x.MaxAmountCents
+	}
+	return 0
+}
+
+// ListTransactionsResponse contains transaction list
+type ListTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"` // Offset pagination only (0 for cursor pages)
+	NextCursor    string                 `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`  // Opaque token for the next page; empty when there are no more transactions
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsResponse) Reset() {
+	*x = ListTransactionsResponse{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsResponse) ProtoMessage() {}
+
+func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListTransactionsResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *ListTransactionsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *ListTransactionsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// PaymentResponse is returned from payment operations
+type PaymentResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId     string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	GroupId           string                 `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"` // Transaction group ID (links related transactions)
+	AgentId           string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	CustomerId        string                 `protobuf:"bytes,4,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	Amount            string                 `protobuf:"bytes,5,opt,name=amount,proto3" json:"amount,omitempty"` // Decimal as string
+	Currency          string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status            TransactionStatus      `protobuf:"varint,7,opt,name=status,proto3,enum=payment.v1.TransactionStatus" json:"status,omitempty"`
+	Type              TransactionType        `protobuf:"varint,8,opt,name=type,proto3,enum=payment.v1.TransactionType" json:"type,omitempty"`
+	PaymentMethodType PaymentMethodType      `protobuf:"varint,9,opt,name=payment_method_type,json=paymentMethodType,proto3,enum=payment.v1.PaymentMethodType" json:"payment_method_type,omitempty"`
+	// EPX Gateway response fields
+	AuthGuid     string                 `protobuf:"bytes,10,opt,name=auth_guid,json=authGuid,proto3" json:"auth_guid,omitempty"`               // EPX token for this transaction
+	AuthResp     string                 `protobuf:"bytes,11,opt,name=auth_resp,json=authResp,proto3" json:"auth_resp,omitempty"`               // EPX approval code ("00" = approved)
+	AuthCode     string                 `protobuf:"bytes,12,opt,name=auth_code,json=authCode,proto3" json:"auth_code,omitempty"`               // Bank authorization code
+	AuthRespText string                 `protobuf:"bytes,13,opt,name=auth_resp_text,json=authRespText,proto3" json:"auth_resp_text,omitempty"` // Human-readable response message
+	AuthCardType string                 `protobuf:"bytes,14,opt,name=auth_card_type,json=authCardType,proto3" json:"auth_card_type,omitempty"` // Card brand (V/M/A/D)
+	AuthAvs      string                 `protobuf:"bytes,15,opt,name=auth_avs,json=authAvs,proto3" json:"auth_avs,omitempty"`                  // Address verification result
+	AuthCvv2     string                 `protobuf:"bytes,16,opt,name=auth_cvv2,json=authCvv2,proto3" json:"auth_cvv2,omitempty"`               // CVV verification result
+	IsApproved   bool                   `protobuf:"varint,17,opt,name=is_approved,json=isApproved,proto3" json:"is_approved,omitempty"`
+	CreatedAt    *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Metadata     map[string]string      `protobuf:"bytes,19,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Uniform gateway outcome (same shape for every payment operation)
+	Gateway *GatewayResult `protobuf:"bytes,20,opt,name=gateway,proto3" json:"gateway,omitempty"`
+	// Full transaction group (only when include_tree is requested or the merchant default)
+	Tree *TransactionTree `protobuf:"bytes,21,opt,name=tree,proto3" json:"tree,omitempty"`
+	// Merchant AVS/CVV policy outcome (unset when no policy is configured)
+	VerificationOutcome *VerificationOutcome `protobuf:"bytes,22,opt,name=verification_outcome,json=verificationOutcome,proto3" json:"verification_outcome,omitempty"`
+	ThreeDs             *ThreeDSecure        `protobuf:"bytes,23,opt,name=three_ds,json=threeDs,proto3" json:"three_ds,omitempty"` // 3-D Secure result sent with the payment (unset when not run)
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *PaymentResponse) Reset() {
+	*x = PaymentResponse{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentResponse) ProtoMessage() {}
+
+func (x *PaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentResponse.ProtoReflect.Descriptor instead.
+func (*PaymentResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PaymentResponse) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetStatus() TransactionStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TransactionStatus_TRANSACTION_STATUS_UNSPECIFIED
+}
+
+func (x *PaymentResponse) GetType() TransactionType {
+	if x != nil {
+		return x.Type
+	}
+	return TransactionType_TRANSACTION_TYPE_UNSPECIFIED
+}
+
+func (x *PaymentResponse) GetPaymentMethodType() PaymentMethodType {
+	if x != nil {
+		return x.PaymentMethodType
+	}
+	return PaymentMethodType_PAYMENT_METHOD_TYPE_UNSPECIFIED
+}
+
+func (x *PaymentResponse) GetAuthGuid() string {
+	if x != nil {
+		return x.AuthGuid
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetAuthResp() string {
+	if x != nil {
+		return x.AuthResp
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetAuthCode() string {
+	if x != nil {
+		return x.AuthCode
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetAuthRespText() string {
+	if x != nil {
+		return x.AuthRespText
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetAuthCardType() string {
+	if x != nil {
+		return x.AuthCardType
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetAuthAvs() string {
+	if x != nil {
+		return x.AuthAvs
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetAuthCvv2() string {
+	if x != nil {
+		return x.AuthCvv2
+	}
+	return ""
+}
+
+func (x *PaymentResponse) GetIsApproved() bool {
+	if x != nil {
+		return x.IsApproved
+	}
+	return false
+}
+
+func (x *PaymentResponse) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *PaymentResponse) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *PaymentResponse) GetGateway() *GatewayResult {
+	if x != nil {
+		return x.Gateway
+	}
+	return nil
+}
+
+func (x *PaymentResponse) GetTree() *TransactionTree {
+	if x != nil {
+		return x.Tree
+	}
+	return nil
+}
+
+func (x *PaymentResponse) GetVerificationOutcome() *VerificationOutcome {
+	if x != nil {
+		return x.VerificationOutcome
+	}
+	return nil
+}
+
+func (x *PaymentResponse) GetThreeDs() *ThreeDSecure {
+	if x != nil {
+		return x.ThreeDs
+	}
+	return nil
+}
+
+// VerificationOutcome is the merchant's AVS/CVV policy evaluated against the gateway's verification results
+type VerificationOutcome struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`                                 // "pass" or "fail"
+	FailedChecks  []string               `protobuf:"bytes,2,rep,name=failed_checks,json=failedChecks,proto3" json:"failed_checks,omitempty"` // "avs" and/or "cvv"
+	AvsPolicy     string                 `protobuf:"bytes,3,opt,name=avs_policy,json=avsPolicy,proto3" json:"avs_policy,omitempty"`          // "lenient", "strict" (empty if not evaluated)
+	AvsResult     string                 `protobuf:"bytes,4,opt,name=avs_result,json=avsResult,proto3" json:"avs_result,omitempty"`          // AVS summary that was evaluated
+	CvvPolicy     string                 `protobuf:"bytes,5,opt,name=cvv_policy,json=cvvPolicy,proto3" json:"cvv_policy,omitempty"`
+	CvvResult     string                 `protobuf:"bytes,6,opt,name=cvv_result,json=cvvResult,proto3" json:"cvv_result,omitempty"` // CVV summary that was evaluated
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerificationOutcome) Reset() {
+	*x = VerificationOutcome{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerificationOutcome) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerificationOutcome) ProtoMessage() {}
+
+func (x *VerificationOutcome) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerificationOutcome.ProtoReflect.Descriptor instead.
+func (*VerificationOutcome) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *VerificationOutcome) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *VerificationOutcome) GetFailedChecks() []string {
+	if x != nil {
+		return x.FailedChecks
+	}
+	return nil
+}
+
+func (x *VerificationOutcome) GetAvsPolicy() string {
+	if x != nil {
+		return x.AvsPolicy
+	}
+	return ""
+}
+
+func (x *VerificationOutcome) GetAvsResult() string {
+	if x != nil {
+		return x.AvsResult
+	}
+	return ""
+}
+
+func (x *VerificationOutcome) GetCvvPolicy() string {
+	if x != nil {
+		return x.CvvPolicy
+	}
+	return ""
+}
+
+func (x *VerificationOutcome) GetCvvResult() string {
+	if x != nil {
+		return x.CvvResult
+	}
+	return ""
+}
+
+// TransactionTree is a transaction group: the auth or sale and everything that followed it
+type TransactionTree struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Root          *Transaction           `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`         // Unset if the group has no auth or sale
+	Children      []*Transaction         `protobuf:"bytes,2,rep,name=children,proto3" json:"children,omitempty"` // Captures, voids and refunds, oldest first
+	State         *TransactionGroupState `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionTree) Reset() {
+	*x = TransactionTree{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionTree) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionTree) ProtoMessage() {}
+
+func (x *TransactionTree) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionTree.ProtoReflect.Descriptor instead.
+func (*TransactionTree) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *TransactionTree) GetRoot() *Transaction {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *TransactionTree) GetChildren() []*Transaction {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+func (x *TransactionTree) GetState() *TransactionGroupState {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+// TransactionGroupState summarizes the money movement of a transaction group
+type TransactionGroupState struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Status            string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`                                             // "authorized", "captured", "partially_refunded", "refunded", "voided", "failed"
+	AuthorizedAmount  string                 `protobuf:"bytes,2,opt,name=authorized_amount,json=authorizedAmount,proto3" json:"authorized_amount,omitempty"` // Decimal as string
+	CapturedAmount    string                 `protobuf:"bytes,3,opt,name=captured_amount,json=capturedAmount,proto3" json:"captured_amount,omitempty"`
+	RefundedAmount    string                 `protobuf:"bytes,4,opt,name=refunded_amount,json=refundedAmount,proto3" json:"refunded_amount,omitempty"`
+	CapturableAmount  string                 `protobuf:"bytes,5,opt,name=capturable_amount,json=capturableAmount,proto3" json:"capturable_amount,omitempty"` // Active authorization not yet captured
+	RefundableAmount  string                 `protobuf:"bytes,6,opt,name=refundable_amount,json=refundableAmount,proto3" json:"refundable_amount,omitempty"` // Captured but not yet refunded
+	Voided            bool                   `protobuf:"varint,7,opt,name=voided,proto3" json:"voided,omitempty"`
+	ReversedAmount    string                 `protobuf:"bytes,8,opt,name=reversed_amount,json=reversedAmount,proto3" json:"reversed_amount,omitempty"`           // Released by partial authorization reversals
+	ActiveAuthAmount  string                 `protobuf:"bytes,9,opt,name=active_auth_amount,json=activeAuthAmount,proto3" json:"active_auth_amount,omitempty"`   // Authorized amount plus increments still held after reversals
+	IncrementedAmount string                 `protobuf:"bytes,10,opt,name=incremented_amount,json=incrementedAmount,proto3" json:"incremented_amount,omitempty"` // Added by incremental authorizations
+	AuthExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=auth_expires_at,json=authExpiresAt,proto3" json:"auth_expires_at,omitempty"`           // When the open authorization lapses (unset unless one is held)
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *TransactionGroupState) Reset() {
+	*x = TransactionGroupState{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionGroupState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionGroupState) ProtoMessage() {}
+
+func (x *TransactionGroupState) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionGroupState.ProtoReflect.Descriptor instead.
+func (*TransactionGroupState) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *TransactionGroupState) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TransactionGroupState) GetAuthorizedAmount() string {
+	if x != nil {
+		return x.AuthorizedAmount
+	}
+	return ""
+}
+
+func (x *TransactionGroupState) GetCapturedAmount() string {
+	if x != nil {
+		return x.CapturedAmount
+	}
+	return ""
+}
+
+func (x *TransactionGroupState) GetRefundedAmount() string {
+	if x != nil {
+		return x.RefundedAmount
+	}
+	return ""
+}
+
+func (x *TransactionGroupState) GetCapturableAmount() string {
+	if x != nil {
+		return x.CapturableAmount
+	}
+	return ""
+}
+
+func (x *TransactionGroupState) GetRefundableAmount() string {
+	if x != nil {
+		return x.RefundableAmount
+	}
+	return ""
+}
+
+func (x *TransactionGroupState) GetVoided() bool {
+	if x != nil {
+		return x.Voided
+	}
+	return false
+}
+
+func (x *TransactionGroupState) GetReversedAmount() string {
+	if x != nil {
+		return x.ReversedAmount
+	}
+	return ""
+}
+
+func (x *TransactionGroupState) GetActiveAuthAmount() string {
+	if x != nil {
+		return x.ActiveAuthAmount
+	}
+	return ""
+}
+
+func (x *TransactionGroupState) GetIncrementedAmount() string {
+	if x != nil {
+		return x.IncrementedAmount
+	}
+	return ""
+}
+
+func (x *TransactionGroupState) GetAuthExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AuthExpiresAt
+	}
+	return nil
+}
+
+// GatewayResult summarizes the processor's answer for a payment operation
+type GatewayResult struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	ResponseCode string                 `protobuf:"bytes,1,opt,name=response_code,json=responseCode,proto3" json:"response_code,omitempty"` // EPX AUTH_RESP ("00" = approved)
+	ResponseText string                 `protobuf:"bytes,2,opt,name=response_text,json=responseText,proto3" json:"response_text,omitempty"` // EPX AUTH_RESP_TEXT
+	AuthCode     string                 `protobuf:"bytes,3,opt,name=auth_code,json=authCode,proto3" json:"auth_code,omitempty"`             // Bank authorization code (empty if declined)
+	Approved     bool                   `protobuf:"varint,4,opt,name=approved,proto3" json:"approved,omitempty"`
+	CardType     string                 `protobuf:"bytes,5,opt,name=card_type,json=cardType,proto3" json:"card_type,omitempty"`    // Card brand (V/M/A/D), empty for ACH
+	AvsCode      string                 `protobuf:"bytes,6,opt,name=avs_code,json=avsCode,proto3" json:"avs_code,omitempty"`       // Raw AVS result code
+	AvsResult    string                 `protobuf:"bytes,7,opt,name=avs_result,json=avsResult,proto3" json:"avs_result,omitempty"` // "match", "partial_match", "no_match", "unavailable" (empty if not applicable)
+	CvvCode      string                 `protobuf:"bytes,8,opt,name=cvv_code,json=cvvCode,proto3" json:"cvv_code,omitempty"`       // Raw CVV2 result code
+	CvvResult    string                 `protobuf:"bytes,9,opt,name=cvv_result,json=cvvResult,proto3" json:"cvv_result,omitempty"` // "match", "no_match", "not_processed", "unavailable" (empty if not applicable)
+	// Decline mapping (empty when approved)
+	DeclineCode     string `protobuf:"bytes,10,opt,name=decline_code,json=declineCode,proto3" json:"decline_code,omitempty"`             // e.g. "EPX_51"
+	DeclineCategory string `protobuf:"bytes,11,opt,name=decline_category,json=declineCategory,proto3" json:"decline_category,omitempty"` // e.g. "insufficient_funds", "expired_card", "fraud"
+	DeclineReason   string `protobuf:"bytes,12,opt,name=decline_reason,json=declineReason,proto3" json:"decline_reason,omitempty"`       // e.g. "Insufficient funds"
+	Retriable       bool   `protobuf:"varint,13,opt,name=retriable,proto3" json:"retriable,omitempty"`                                   // The same request may succeed later without cardholder action
+	LatencyMs       int64  `protobuf:"varint,14,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`                  // Gateway round trip; 0 when replayed from storage (idempotent retry)
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GatewayResult) Reset() {
+	*x = GatewayResult{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GatewayResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GatewayResult) ProtoMessage() {}
+
+func (x *GatewayResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GatewayResult.ProtoReflect.Descriptor instead.
+func (*GatewayResult) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GatewayResult) GetResponseCode() string {
+	if x != nil {
+		return x.ResponseCode
+	}
+	return ""
+}
+
+func (x *GatewayResult) GetResponseText() string {
+	if x != nil {
+		return x.ResponseText
+	}
+	return ""
+}
+
+func (x *GatewayResult) GetAuthCode() string {
+	if x != nil {
+		return x.AuthCode
+	}
+	return ""
+}
+
+func (x *GatewayResult) GetApproved() bool {
+	if x != nil {
+		return x.Approved
+	}
+	return false
+}
+
+func (x *GatewayResult) GetCardType() string {
+	if x != nil {
+		return x.CardType
+	}
+	return ""
+}
+
+func (x *GatewayResult) GetAvsCode() string {
+	if x != nil {
+		return x.AvsCode
+	}
+	return ""
+}
+
+func (x *GatewayResult) GetAvsResult() string {
+	if x != nil {
+		return x.AvsResult
+	}
+	return ""
+}
+
+func (x *GatewayResult) GetCvvCode() string {
+	if x != nil {
+		return x.CvvCode
+	}
+	return ""
+}
+
+func (x *GatewayResult) GetCvvResult() string {
+	if x != nil {
+		return x.CvvResult
+	}
+	return ""
+}
+
+func (x *GatewayResult) GetDeclineCode() string {
+	if x != nil {
+		return x.DeclineCode
+	}
+	return ""
+}
+
+func (x *GatewayResult) GetDeclineCategory() string {
+	if x != nil {
+		return x.DeclineCategory
+	}
+	return ""
+}
+
+func (x *GatewayResult) GetDeclineReason() string {
+	if x != nil {
+		return x.DeclineReason
+	}
+	return ""
+}
+
+func (x *GatewayResult) GetRetriable() bool {
+	if x != nil {
+		return x.Retriable
+	}
+	return false
+}
+
+func (x *GatewayResult) GetLatencyMs() int64 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return 0
+}
+
+// Transaction represents a complete transaction record
+type Transaction struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	GroupId           string                 `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"` // Links related transactions (auth → capture → refund)
+	AgentId           string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	CustomerId        string                 `protobuf:"bytes,4,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"` // Nullable for guest transactions
+	Amount            string                 `protobuf:"bytes,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency          string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status            TransactionStatus      `protobuf:"varint,7,opt,name=status,proto3,enum=payment.v1.TransactionStatus" json:"status,omitempty"`
+	Type              TransactionType        `protobuf:"varint,8,opt,name=type,proto3,enum=payment.v1.TransactionType" json:"type,omitempty"`
+	PaymentMethodType PaymentMethodType      `protobuf:"varint,9,opt,name=payment_method_type,json=paymentMethodType,proto3,enum=payment.v1.PaymentMethodType" json:"payment_method_type,omitempty"`
+	PaymentMethodId   string                 `protobuf:"bytes,10,opt,name=payment_method_id,json=paymentMethodId,proto3" json:"payment_method_id,omitempty"` // Saved payment method used (if any)
+	// EPX Gateway response fields
+	AuthGuid       string                 `protobuf:"bytes,11,opt,name=auth_guid,json=authGuid,proto3" json:"auth_guid,omitempty"`               // EPX token for this transaction
+	AuthResp       string                 `protobuf:"bytes,12,opt,name=auth_resp,json=authResp,proto3" json:"auth_resp,omitempty"`               // EPX approval code
+	AuthCode       string                 `protobuf:"bytes,13,opt,name=auth_code,json=authCode,proto3" json:"auth_code,omitempty"`               // Bank authorization code
+	AuthRespText   string                 `protobuf:"bytes,14,opt,name=auth_resp_text,json=authRespText,proto3" json:"auth_resp_text,omitempty"` // Human-readable response
+	AuthCardType   string                 `protobuf:"bytes,15,opt,name=auth_card_type,json=authCardType,proto3" json:"auth_card_type,omitempty"` // Card brand
+	AuthAvs        string                 `protobuf:"bytes,16,opt,name=auth_avs,json=authAvs,proto3" json:"auth_avs,omitempty"`                  // Address verification
+	AuthCvv2       string                 `protobuf:"bytes,17,opt,name=auth_cvv2,json=authCvv2,proto3" json:"auth_cvv2,omitempty"`               // CVV verification
+	IdempotencyKey string                 `protobuf:"bytes,18,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt      *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Metadata       map[string]string      `protobuf:"bytes,21,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Settlement (unset until the transaction settles)
+	SettledAt   *timestamppb.Timestamp `protobuf:"bytes,22,opt,name=settled_at,json=settledAt,proto3" json:"settled_at,omitempty"`
+	FundingDate string                 `protobuf:"bytes,23,opt,name=funding_date,json=fundingDate,proto3" json:"funding_date,omitempty"` // Expected merchant funding date (YYYY-MM-DD)
+	// Merchant AVS/CVV policy outcome (unset when no policy was configured)
+	VerificationOutcome *VerificationOutcome `protobuf:"bytes,24,opt,name=verification_outcome,json=verificationOutcome,proto3" json:"verification_outcome,omitempty"`
+	DataRegion          string               `protobuf:"bytes,25,opt,name=data_region,json=dataRegion,proto3" json:"data_region,omitempty"` // Merchant data residency region when the transaction was written
+	ThreeDs             *ThreeDSecure        `protobuf:"bytes,26,opt,name=three_ds,json=threeDs,proto3" json:"three_ds,omitempty"`          // 3-D Secure result sent with the authorization (unset when not run)
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Transaction) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Transaction) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *Transaction) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *Transaction) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *Transaction) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *Transaction) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Transaction) GetStatus() TransactionStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TransactionStatus_TRANSACTION_STATUS_UNSPECIFIED
+}
+
+func (x *Transaction) GetType() TransactionType {
+	if x != nil {
+		return x.Type
+	}
+	return TransactionType_TRANSACTION_TYPE_UNSPECIFIED
+}
+
+func (x *Transaction) GetPaymentMethodType() PaymentMethodType {
+	if x != nil {
+		return x.PaymentMethodType
+	}
+	return PaymentMethodType_PAYMENT_METHOD_TYPE_UNSPECIFIED
+}
+
+func (x *Transaction) GetPaymentMethodId() string {
+	if x != nil {
+		return x.PaymentMethodId
+	}
+	return ""
+}
+
+func (x *Transaction) GetAuthGuid() string {
+	if x != nil {
+		return x.AuthGuid
+	}
+	return ""
+}
+
+func (x *Transaction) GetAuthResp() string {
+	if x != nil {
+		return x.AuthResp
+	}
+	return ""
+}
+
+func (x *Transaction) GetAuthCode() string {
+	if x != nil {
+		return x.AuthCode
+	}
+	return ""
+}
+
+func (x *Transaction) GetAuthRespText() string {
+	if x != nil {
+		return x.AuthRespText
+	}
+	return ""
+}
+
+func (x *Transaction) GetAuthCardType() string {
+	if x != nil {
+		return x.AuthCardType
+	}
+	return ""
+}
+
+func (x *Transaction) GetAuthAvs() string {
+	if x != nil {
+		return x.AuthAvs
+	}
+	return ""
+}
+
+func (x *Transaction) GetAuthCvv2() string {
+	if x != nil {
+		return x.AuthCvv2
+	}
+	return ""
+}
+
+func (x *Transaction) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *Transaction) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Transaction) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Transaction) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Transaction) GetSettledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SettledAt
+	}
+	return nil
+}
+
+func (x *Transaction) GetFundingDate() string {
+	if x != nil {
+		return x.FundingDate
+	}
+	return ""
+}
+
+func (x *Transaction) GetVerificationOutcome() *VerificationOutcome {
+	if x != nil {
+		return x.VerificationOutcome
+	}
+	return nil
+}
+
+func (x *Transaction) GetDataRegion() string {
+	if x != nil {
+		return x.DataRegion
+	}
+	return ""
+}
+
+func (x *Transaction) GetThreeDs() *ThreeDSecure {
+	if x != nil {
+		return x.ThreeDs
+	}
+	return nil
+}
+
+// GetEstimatedFeesRequest estimates fees for a transaction
+type GetEstimatedFeesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	AgentId       string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // For authorization
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEstimatedFeesRequest) Reset() {
+	*x = GetEstimatedFeesRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEstimatedFeesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEstimatedFeesRequest) ProtoMessage() {}
+
+func (x *GetEstimatedFeesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEstimatedFeesRequest.ProtoReflect.Descriptor instead.
+func (*GetEstimatedFeesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetEstimatedFeesRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *GetEstimatedFeesRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+// FeeEstimate is an ESTIMATED processing fee from the configured fee schedule.
+// It excludes network assessments and interchange downgrades; use processor statements for actual costs.
+type FeeEstimate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	CardBrand     string                 `protobuf:"bytes,2,opt,name=card_brand,json=cardBrand,proto3" json:"card_brand,omitempty"`                // "V", "M", "A", "D", "ACH", or empty if unknown
+	FundingType   string                 `protobuf:"bytes,3,opt,name=funding_type,json=fundingType,proto3" json:"funding_type,omitempty"`          // "credit", "debit", "prepaid" (unknown card funding is estimated as credit)
+	Amount        string                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`                                       // Transaction amount
+	RatePercent   string                 `protobuf:"bytes,5,opt,name=rate_percent,json=ratePercent,proto3" json:"rate_percent,omitempty"`          // Percent applied (e.g., "1.80" = 1.80%)
+	RateFixed     string                 `protobuf:"bytes,6,opt,name=rate_fixed,json=rateFixed,proto3" json:"rate_fixed,omitempty"`                // Fixed per-transaction fee applied
+	EstimatedFee  string                 `protobuf:"bytes,7,opt,name=estimated_fee,json=estimatedFee,proto3" json:"estimated_fee,omitempty"`       // Estimated fee, rounded to cents
+	IsDefaultRate bool                   `protobuf:"varint,8,opt,name=is_default_rate,json=isDefaultRate,proto3" json:"is_default_rate,omitempty"` // No configured rate matched the brand and funding type
+	IsEstimate    bool                   `protobuf:"varint,9,opt,name=is_estimate,json=isEstimate,proto3" json:"is_estimate,omitempty"`            // Always true
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FeeEstimate) Reset() {
+	*x = FeeEstimate{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FeeEstimate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeeEstimate) ProtoMessage() {}
+
+func (x *FeeEstimate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeeEstimate.ProtoReflect.Descriptor instead.
+func (*FeeEstimate) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *FeeEstimate) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
 	}
 	return ""
 }
 
-func (x *PaymentResponse) GetCurrency() string {
+func (x *FeeEstimate) GetCardBrand() string {
 	if x != nil {
-		return x.Currency
+		return x.CardBrand
 	}
 	return ""
 }
 
-func (x *PaymentResponse) GetStatus() TransactionStatus {
+func (x *FeeEstimate) GetFundingType() string {
 	if x != nil {
-		return x.Status
+		return x.FundingType
 	}
-	return TransactionStatus_TRANSACTION_STATUS_UNSPECIFIED
+	return ""
 }
 
-func (x *PaymentResponse) GetType() TransactionType {
+func (x *FeeEstimate) GetAmount() string {
 	if x != nil {
-		return x.Type
+		return x.Amount
 	}
-	return TransactionType_TRANSACTION_TYPE_UNSPECIFIED
+	return ""
 }
 
-func (x *PaymentResponse) GetPaymentMethodType() PaymentMethodType {
+func (x *FeeEstimate) GetRatePercent() string {
 	if x != nil {
-		return x.PaymentMethodType
+		return x.RatePercent
 	}
-	return PaymentMethodType_PAYMENT_METHOD_TYPE_UNSPECIFIED
+	return ""
 }
 
-func (x *PaymentResponse) GetAuthGuid() string {
+func (x *FeeEstimate) GetRateFixed() string {
 	if x != nil {
-		return x.AuthGuid
+		return x.RateFixed
 	}
 	return ""
 }
 
-func (x *PaymentResponse) GetAuthResp() string {
+func (x *FeeEstimate) GetEstimatedFee() string {
 	if x != nil {
-		return x.AuthResp
+		return x.EstimatedFee
 	}
 	return ""
 }
 
-func (x *PaymentResponse) GetAuthCode() string {
+func (x *FeeEstimate) GetIsDefaultRate() bool {
 	if x != nil {
-		return x.AuthCode
+		return x.IsDefaultRate
 	}
-	return ""
+	return false
 }
 
-func (x *PaymentResponse) GetAuthRespText() string {
+func (x *FeeEstimate) GetIsEstimate() bool {
 	if x != nil {
-		return x.AuthRespText
+		return x.IsEstimate
 	}
-	return ""
+	return false
 }
 
-func (x *PaymentResponse) GetAuthCardType() string {
+// GetSettlementSummaryRequest is a merchant and an inclusive date range (at most 366 days)
+type GetSettlementSummaryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	StartDate     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"` // Only the UTC date is used
+	EndDate       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`       // Inclusive; only the UTC date is used
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSettlementSummaryRequest) Reset() {
+	*x = GetSettlementSummaryRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSettlementSummaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSettlementSummaryRequest) ProtoMessage() {}
+
+func (x *GetSettlementSummaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[28]
 	if x != nil {
-		return x.AuthCardType
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *PaymentResponse) GetAuthAvs() string {
+// Deprecated: Use GetSettlementSummaryRequest.ProtoReflect.Descriptor instead.
+func (*GetSettlementSummaryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetSettlementSummaryRequest) GetAgentId() string {
 	if x != nil {
-		return x.AuthAvs
+		return x.AgentId
 	}
 	return ""
 }
 
-func (x *PaymentResponse) GetAuthCvv2() string {
+func (x *GetSettlementSummaryRequest) GetStartDate() *timestamppb.Timestamp {
 	if x != nil {
-		return x.AuthCvv2
+		return x.StartDate
 	}
-	return ""
+	return nil
 }
 
-func (x *PaymentResponse) GetIsApproved() bool {
+func (x *GetSettlementSummaryRequest) GetEndDate() *timestamppb.Timestamp {
 	if x != nil {
-		return x.IsApproved
+		return x.EndDate
 	}
-	return false
+	return nil
 }
 
-func (x *PaymentResponse) GetCreatedAt() *timestamppb.Timestamp {
+// GetSettlementSummaryResponse has one entry per day and currency with activity, oldest first
+type GetSettlementSummaryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Days          []*SettlementDay       `protobuf:"bytes,1,rep,name=days,proto3" json:"days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSettlementSummaryResponse) Reset() {
+	*x = GetSettlementSummaryResponse{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSettlementSummaryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSettlementSummaryResponse) ProtoMessage() {}
+
+func (x *GetSettlementSummaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[29]
 	if x != nil {
-		return x.CreatedAt
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *PaymentResponse) GetMetadata() map[string]string {
+// Deprecated: Use GetSettlementSummaryResponse.ProtoReflect.Descriptor instead.
+func (*GetSettlementSummaryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetSettlementSummaryResponse) GetDays() []*SettlementDay {
 	if x != nil {
-		return x.Metadata
+		return x.Days
 	}
 	return nil
 }
 
-// Transaction represents a complete transaction record
-type Transaction struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	GroupId           string                 `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"` // Links related transactions (auth → capture → refund)
-	AgentId           string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	CustomerId        string                 `protobuf:"bytes,4,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"` // Nullable for guest transactions
-	Amount            string                 `protobuf:"bytes,5,opt,name=amount,proto3" json:"amount,omitempty"`
-	Currency          string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
-	Status            TransactionStatus      `protobuf:"varint,7,opt,name=status,proto3,enum=payment.v1.TransactionStatus" json:"status,omitempty"`
-	Type              TransactionType        `protobuf:"varint,8,opt,name=type,proto3,enum=payment.v1.TransactionType" json:"type,omitempty"`
-	PaymentMethodType PaymentMethodType      `protobuf:"varint,9,opt,name=payment_method_type,json=paymentMethodType,proto3,enum=payment.v1.PaymentMethodType" json:"payment_method_type,omitempty"`
-	PaymentMethodId   string                 `protobuf:"bytes,10,opt,name=payment_method_id,json=paymentMethodId,proto3" json:"payment_method_id,omitempty"` // Saved payment method used (if any)
-	// EPX Gateway response fields
-	AuthGuid       string                 `protobuf:"bytes,11,opt,name=auth_guid,json=authGuid,proto3" json:"auth_guid,omitempty"`               // EPX token for this transaction
-	AuthResp       string                 `protobuf:"bytes,12,opt,name=auth_resp,json=authResp,proto3" json:"auth_resp,omitempty"`               // EPX approval code
-	AuthCode       string                 `protobuf:"bytes,13,opt,name=auth_code,json=authCode,proto3" json:"auth_code,omitempty"`               // Bank authorization code
-	AuthRespText   string                 `protobuf:"bytes,14,opt,name=auth_resp_text,json=authRespText,proto3" json:"auth_resp_text,omitempty"` // Human-readable response
-	AuthCardType   string                 `protobuf:"bytes,15,opt,name=auth_card_type,json=authCardType,proto3" json:"auth_card_type,omitempty"` // Card brand
-	AuthAvs        string                 `protobuf:"bytes,16,opt,name=auth_avs,json=authAvs,proto3" json:"auth_avs,omitempty"`                  // Address verification
-	AuthCvv2       string                 `protobuf:"bytes,17,opt,name=auth_cvv2,json=authCvv2,proto3" json:"auth_cvv2,omitempty"`               // CVV verification
-	IdempotencyKey string                 `protobuf:"bytes,18,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
-	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt      *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	Metadata       map[string]string      `protobuf:"bytes,21,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+// SettlementDay is a merchant's money movement on one day in one currency
+type SettlementDay struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"` // YYYY-MM-DD (UTC)
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	GrossSales    string                 `protobuf:"bytes,3,opt,name=gross_sales,json=grossSales,proto3" json:"gross_sales,omitempty"` // Completed sales and captures
+	SaleCount     int32                  `protobuf:"varint,4,opt,name=sale_count,json=saleCount,proto3" json:"sale_count,omitempty"`
+	Refunds       string                 `protobuf:"bytes,5,opt,name=refunds,proto3" json:"refunds,omitempty"` // Completed refunds
+	RefundCount   int32                  `protobuf:"varint,6,opt,name=refund_count,json=refundCount,proto3" json:"refund_count,omitempty"`
+	Voids         string                 `protobuf:"bytes,7,opt,name=voids,proto3" json:"voids,omitempty"` // Voids of captured payments (voided authorizations moved no money)
+	VoidCount     int32                  `protobuf:"varint,8,opt,name=void_count,json=voidCount,proto3" json:"void_count,omitempty"`
+	Net           string                 `protobuf:"bytes,9,opt,name=net,proto3" json:"net,omitempty"` // gross_sales - refunds - voids
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Transaction) Reset() {
-	*x = Transaction{}
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[9]
+func (x *SettlementDay) Reset() {
+	*x = SettlementDay{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Transaction) String() string {
+func (x *SettlementDay) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Transaction) ProtoMessage() {}
+func (*SettlementDay) ProtoMessage() {}
 
-func (x *Transaction) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_v1_payment_proto_msgTypes[9]
+func (x *SettlementDay) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1068,156 +2961,218 @@ func (x *Transaction) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
-func (*Transaction) Descriptor() ([]byte, []int) {
-	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use SettlementDay.ProtoReflect.Descriptor instead.
+func (*SettlementDay) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *Transaction) GetId() string {
+func (x *SettlementDay) GetDate() string {
 	if x != nil {
-		return x.Id
+		return x.Date
 	}
 	return ""
 }
 
-func (x *Transaction) GetGroupId() string {
+func (x *SettlementDay) GetCurrency() string {
 	if x != nil {
-		return x.GroupId
+		return x.Currency
 	}
 	return ""
 }
 
-func (x *Transaction) GetAgentId() string {
+func (x *SettlementDay) GetGrossSales() string {
 	if x != nil {
-		return x.AgentId
+		return x.GrossSales
 	}
 	return ""
 }
 
-func (x *Transaction) GetCustomerId() string {
+func (x *SettlementDay) GetSaleCount() int32 {
 	if x != nil {
-		return x.CustomerId
+		return x.SaleCount
 	}
-	return ""
+	return 0
 }
 
-func (x *Transaction) GetAmount() string {
+func (x *SettlementDay) GetRefunds() string {
 	if x != nil {
-		return x.Amount
+		return x.Refunds
 	}
 	return ""
 }
 
-func (x *Transaction) GetCurrency() string {
+func (x *SettlementDay) GetRefundCount() int32 {
 	if x != nil {
-		return x.Currency
+		return x.RefundCount
 	}
-	return ""
+	return 0
 }
 
-func (x *Transaction) GetStatus() TransactionStatus {
+func (x *SettlementDay) GetVoids() string {
 	if x != nil {
-		return x.Status
+		return x.Voids
 	}
-	return TransactionStatus_TRANSACTION_STATUS_UNSPECIFIED
+	return ""
 }
 
-func (x *Transaction) GetType() TransactionType {
+func (x *SettlementDay) GetVoidCount() int32 {
 	if x != nil {
-		return x.Type
+		return x.VoidCount
 	}
-	return TransactionType_TRANSACTION_TYPE_UNSPECIFIED
+	return 0
 }
 
-func (x *Transaction) GetPaymentMethodType() PaymentMethodType {
+func (x *SettlementDay) GetNet() string {
 	if x != nil {
-		return x.PaymentMethodType
+		return x.Net
 	}
-	return PaymentMethodType_PAYMENT_METHOD_TYPE_UNSPECIFIED
+	return ""
 }
 
-func (x *Transaction) GetPaymentMethodId() string {
-	if x != nil {
-		return x.PaymentMethodId
-	}
-	return ""
+// ClassifyDeclineCodeRequest is an EPX response to classify
+type ClassifyDeclineCodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuthResp      string                 `protobuf:"bytes,1,opt,name=auth_resp,json=authResp,proto3" json:"auth_resp,omitempty"`               // EPX AUTH_RESP code, e.g. "51"
+	AuthRespText  string                 `protobuf:"bytes,2,opt,name=auth_resp_text,json=authRespText,proto3" json:"auth_resp_text,omitempty"` // Optional: EPX AUTH_RESP_TEXT, echoed as the gateway message
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Transaction) GetAuthGuid() string {
+func (x *ClassifyDeclineCodeRequest) Reset() {
+	*x = ClassifyDeclineCodeRequest{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClassifyDeclineCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClassifyDeclineCodeRequest) ProtoMessage() {}
+
+func (x *ClassifyDeclineCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[31]
 	if x != nil {
-		return x.AuthGuid
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Transaction) GetAuthResp() string {
+// Deprecated: Use ClassifyDeclineCodeRequest.ProtoReflect.Descriptor instead.
+func (*ClassifyDeclineCodeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ClassifyDeclineCodeRequest) GetAuthResp() string {
 	if x != nil {
 		return x.AuthResp
 	}
 	return ""
 }
 
-func (x *Transaction) GetAuthCode() string {
+func (x *ClassifyDeclineCodeRequest) GetAuthRespText() string {
 	if x != nil {
-		return x.AuthCode
+		return x.AuthRespText
 	}
 	return ""
 }
 
-func (x *Transaction) GetAuthRespText() string {
+// DeclineClassification is the decline taxonomy applied to an EPX response code, as in the payment flow
+type DeclineClassification struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AuthResp        string                 `protobuf:"bytes,1,opt,name=auth_resp,json=authResp,proto3" json:"auth_resp,omitempty"`
+	Approved        bool                   `protobuf:"varint,2,opt,name=approved,proto3" json:"approved,omitempty"`
+	DeclineCode     string                 `protobuf:"bytes,3,opt,name=decline_code,json=declineCode,proto3" json:"decline_code,omitempty"`             // e.g. "EPX_51" (empty when approved)
+	DeclineCategory string                 `protobuf:"bytes,4,opt,name=decline_category,json=declineCategory,proto3" json:"decline_category,omitempty"` // e.g. "insufficient_funds", "expired_card", "fraud"
+	DeclineReason   string                 `protobuf:"bytes,5,opt,name=decline_reason,json=declineReason,proto3" json:"decline_reason,omitempty"`       // e.g. "Insufficient funds"
+	Severity        string                 `protobuf:"bytes,6,opt,name=severity,proto3" json:"severity,omitempty"`                                      // "soft" (may succeed later as-is) or "hard" (needs cardholder action or a fixed request); empty when approved
+	Retriable       bool                   `protobuf:"varint,7,opt,name=retriable,proto3" json:"retriable,omitempty"`                                   // The same request may succeed later without cardholder action
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DeclineClassification) Reset() {
+	*x = DeclineClassification{}
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeclineClassification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeclineClassification) ProtoMessage() {}
+
+func (x *DeclineClassification) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_v1_payment_proto_msgTypes[32]
 	if x != nil {
-		return x.AuthRespText
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Transaction) GetAuthCardType() string {
+// Deprecated: Use DeclineClassification.ProtoReflect.Descriptor instead.
+func (*DeclineClassification) Descriptor() ([]byte, []int) {
+	return file_proto_payment_v1_payment_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DeclineClassification) GetAuthResp() string {
 	if x != nil {
-		return x.AuthCardType
+		return x.AuthResp
 	}
 	return ""
 }
 
-func (x *Transaction) GetAuthAvs() string {
+func (x *DeclineClassification) GetApproved() bool {
 	if x != nil {
-		return x.AuthAvs
+		return x.Approved
 	}
-	return ""
+	return false
 }
 
-func (x *Transaction) GetAuthCvv2() string {
+func (x *DeclineClassification) GetDeclineCode() string {
 	if x != nil {
-		return x.AuthCvv2
+		return x.DeclineCode
 	}
 	return ""
 }
 
-func (x *Transaction) GetIdempotencyKey() string {
+func (x *DeclineClassification) GetDeclineCategory() string {
 	if x != nil {
-		return x.IdempotencyKey
+		return x.DeclineCategory
 	}
 	return ""
 }
 
-func (x *Transaction) GetCreatedAt() *timestamppb.Timestamp {
+func (x *DeclineClassification) GetDeclineReason() string {
 	if x != nil {
-		return x.CreatedAt
+		return x.DeclineReason
 	}
-	return nil
+	return ""
 }
 
-func (x *Transaction) GetUpdatedAt() *timestamppb.Timestamp {
+func (x *DeclineClassification) GetSeverity() string {
 	if x != nil {
-		return x.UpdatedAt
+		return x.Severity
 	}
-	return nil
+	return ""
 }
 
-func (x *Transaction) GetMetadata() map[string]string {
+func (x *DeclineClassification) GetRetriable() bool {
 	if x != nil {
-		return x.Metadata
+		return x.Retriable
 	}
-	return nil
+	return false
 }
 
 var File_proto_payment_v1_payment_proto protoreflect.FileDescriptor
@@ -1225,7 +3180,7 @@ var File_proto_payment_v1_payment_proto protoreflect.FileDescriptor
 const file_proto_payment_v1_payment_proto_rawDesc = "" +
 	"\n" +
 	"\x1eproto/payment/v1/payment.proto\x12\n" +
-	"payment.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x97\x03\n" +
+	"payment.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xfd\x04\n" +
 	"\x10AuthorizeRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
 	"\vcustomer_id\x18\x02 \x01(\tR\n" +
@@ -1235,15 +3190,42 @@ const file_proto_payment_v1_payment_proto_rawDesc = "" +
 	"\x11payment_method_id\x18\x05 \x01(\tH\x00R\x0fpaymentMethodId\x12%\n" +
 	"\rpayment_token\x18\x06 \x01(\tH\x00R\fpaymentToken\x12'\n" +
 	"\x0fidempotency_key\x18\a \x01(\tR\x0eidempotencyKey\x12F\n" +
-	"\bmetadata\x18\b \x03(\v2*.payment.v1.AuthorizeRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\b \x03(\v2*.payment.v1.AuthorizeRequest.MetadataEntryR\bmetadata\x12&\n" +
+	"\finclude_tree\x18\t \x01(\bH\x01R\vincludeTree\x88\x01\x01\x123\n" +
+	"\bthree_ds\x18\n" +
+	" \x01(\v2\x18.payment.v1.ThreeDSecureR\athreeDs\x12%\n" +
+	"\x0ecustomer_email\x18\v \x01(\tR\rcustomerEmail\x126\n" +
+	"\x14statement_descriptor\x18\f \x01(\tH\x02R\x13statementDescriptor\x88\x01\x01\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x10\n" +
-	"\x0epayment_method\"x\n" +
+	"\x0epayment_methodB\x0f\n" +
+	"\r_include_treeB\x17\n" +
+	"\x15_statement_descriptor\"\xa9\x01\n" +
+	"\fThreeDSecure\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x12\n" +
+	"\x04cavv\x18\x02 \x01(\tR\x04cavv\x12\x10\n" +
+	"\x03eci\x18\x03 \x01(\tR\x03eci\x12-\n" +
+	"\x12transaction_status\x18\x04 \x01(\tR\x11transactionStatus\x12*\n" +
+	"\x11ds_transaction_id\x18\x05 \x01(\tR\x0fdsTransactionId\"\xb1\x01\n" +
 	"\x0eCaptureRequest\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
 	"\x06amount\x18\x02 \x01(\tR\x06amount\x12'\n" +
-	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\"\x8d\x03\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\x12&\n" +
+	"\finclude_tree\x18\x04 \x01(\bH\x00R\vincludeTree\x88\x01\x01B\x0f\n" +
+	"\r_include_tree\"\xc5\x01\n" +
+	"\"PartialReverseAuthorizationRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\tR\x06amount\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\x12&\n" +
+	"\finclude_tree\x18\x04 \x01(\bH\x00R\vincludeTree\x88\x01\x01B\x0f\n" +
+	"\r_include_tree\"\xc0\x01\n" +
+	"\x1dIncrementAuthorizationRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\tR\x06amount\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\x12&\n" +
+	"\finclude_tree\x18\x04 \x01(\bH\x00R\vincludeTree\x88\x01\x01B\x0f\n" +
+	"\r_include_tree\"\xf3\x04\n" +
 	"\vSaleRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
 	"\vcustomer_id\x18\x02 \x01(\tR\n" +
@@ -1253,21 +3235,75 @@ const file_proto_payment_v1_payment_proto_rawDesc = "" +
 	"\x11payment_method_id\x18\x05 \x01(\tH\x00R\x0fpaymentMethodId\x12%\n" +
 	"\rpayment_token\x18\x06 \x01(\tH\x00R\fpaymentToken\x12'\n" +
 	"\x0fidempotency_key\x18\a \x01(\tR\x0eidempotencyKey\x12A\n" +
-	"\bmetadata\x18\b \x03(\v2%.payment.v1.SaleRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\b \x03(\v2%.payment.v1.SaleRequest.MetadataEntryR\bmetadata\x12&\n" +
+	"\finclude_tree\x18\t \x01(\bH\x01R\vincludeTree\x88\x01\x01\x123\n" +
+	"\bthree_ds\x18\n" +
+	" \x01(\v2\x18.payment.v1.ThreeDSecureR\athreeDs\x12%\n" +
+	"\x0ecustomer_email\x18\v \x01(\tR\rcustomerEmail\x126\n" +
+	"\x14statement_descriptor\x18\f \x01(\tH\x02R\x13statementDescriptor\x88\x01\x01\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x10\n" +
-	"\x0epayment_method\"]\n" +
+	"\x0epayment_methodB\x0f\n" +
+	"\r_include_treeB\x17\n" +
+	"\x15_statement_descriptor\"\x90\x01\n" +
+	"\x10BatchSaleRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x122\n" +
+	"\x15batch_idempotency_key\x18\x02 \x01(\tR\x13batchIdempotencyKey\x12-\n" +
+	"\x05items\x18\x03 \x03(\v2\x17.payment.v1.SaleRequestR\x05items\"x\n" +
+	"\x13BatchSaleItemResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x125\n" +
+	"\apayment\x18\x02 \x01(\v2\x1b.payment.v1.PaymentResponseR\apayment\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"j\n" +
+	"\x11BatchSaleResponse\x129\n" +
+	"\aresults\x18\x01 \x03(\v2\x1f.payment.v1.BatchSaleItemResultR\aresults\x12\x1a\n" +
+	"\breplayed\x18\x02 \x01(\bR\breplayed\"\x96\x01\n" +
 	"\vVoidRequest\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12'\n" +
-	"\x0fidempotency_key\x18\x02 \x01(\tR\x0eidempotencyKey\"\x8f\x01\n" +
+	"\x0fidempotency_key\x18\x02 \x01(\tR\x0eidempotencyKey\x12&\n" +
+	"\finclude_tree\x18\x03 \x01(\bH\x00R\vincludeTree\x88\x01\x01B\x0f\n" +
+	"\r_include_tree\"\xc8\x01\n" +
 	"\rRefundRequest\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
 	"\x06amount\x18\x02 \x01(\tR\x06amount\x12\x16\n" +
 	"\x06reason\x18\x03 \x01(\tR\x06reason\x12'\n" +
-	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\">\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\x12&\n" +
+	"\finclude_tree\x18\x05 \x01(\bH\x00R\vincludeTree\x88\x01\x01B\x0f\n" +
+	"\r_include_tree\"\xff\x01\n" +
+	"\x18RefundByReferenceRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1b\n" +
+	"\tauth_guid\x18\x02 \x01(\tR\bauthGuid\x12\x19\n" +
+	"\btran_nbr\x18\x03 \x01(\tR\atranNbr\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\tR\x06amount\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12'\n" +
+	"\x0fidempotency_key\x18\x06 \x01(\tR\x0eidempotencyKey\x12&\n" +
+	"\finclude_tree\x18\a \x01(\bH\x00R\vincludeTree\x88\x01\x01B\x0f\n" +
+	"\r_include_tree\">\n" +
 	"\x15GetTransactionRequest\x12%\n" +
-	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\"\xd5\x01\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\"c\n" +
+	"\x1dGetTransactionStatusesRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12'\n" +
+	"\x0ftransaction_ids\x18\x02 \x03(\tR\x0etransactionIds\"_\n" +
+	"\x1eGetTransactionStatusesResponse\x12=\n" +
+	"\aresults\x18\x01 \x03(\v2#.payment.v1.TransactionStatusResultR\aresults\"a\n" +
+	"\x1bBatchGetTransactionsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12'\n" +
+	"\x0ftransaction_ids\x18\x02 \x03(\tR\x0etransactionIds\"\x93\x01\n" +
+	"\x1cBatchGetTransactionsResponse\x12;\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x17.payment.v1.TransactionR\ftransactions\x126\n" +
+	"\x17missing_transaction_ids\x18\x02 \x03(\tR\x15missingTransactionIds\"\x8a\x03\n" +
+	"\x17TransactionStatusResult\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\x125\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x1d.payment.v1.TransactionStatusR\x06status\x12/\n" +
+	"\x04type\x18\x04 \x01(\x0e2\x1b.payment.v1.TransactionTypeR\x04type\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\tR\x06amount\x12\x19\n" +
+	"\bgroup_id\x18\x06 \x01(\tR\agroupId\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x129\n" +
+	"\n" +
+	"settled_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tsettledAt\x12!\n" +
+	"\ffunding_date\x18\t \x01(\tR\vfundingDate\"\xa2\x04\n" +
 	"\x17ListTransactionsRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
 	"\vcustomer_id\x18\x02 \x01(\tR\n" +
@@ -1275,11 +3311,23 @@ const file_proto_payment_v1_payment_proto_rawDesc = "" +
 	"\bgroup_id\x18\x03 \x01(\tR\agroupId\x125\n" +
 	"\x06status\x18\x04 \x01(\x0e2\x1d.payment.v1.TransactionStatusR\x06status\x12\x14\n" +
 	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x16\n" +
-	"\x06offset\x18\x06 \x01(\x05R\x06offset\"x\n" +
+	"\x06offset\x18\x06 \x01(\x05R\x06offset\x12\x16\n" +
+	"\x06cursor\x18\a \x01(\tR\x06cursor\x12f\n" +
+	"\x11metadata_contains\x18\b \x03(\v29.payment.v1.ListTransactionsRequest.MetadataContainsEntryR\x10metadataContains\x12-\n" +
+	"\x10min_amount_cents\x18\t \x01(\x03H\x00R\x0eminAmountCents\x88\x01\x01\x12-\n" +
+	"\x10max_amount_cents\x18\n" +
+	" \x01(\x03H\x01R\x0emaxAmountCents\x88\x01\x01\x1aC\n" +
+	"\x15MetadataContainsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x13\n" +
+	"\x11_min_amount_centsB\x13\n" +
+	"\x11_max_amount_cents\"\x99\x01\n" +
 	"\x18ListTransactionsResponse\x12;\n" +
 	"\ftransactions\x18\x01 \x03(\v2\x17.payment.v1.TransactionR\ftransactions\x12\x1f\n" +
 	"\vtotal_count\x18\x02 \x01(\x05R\n" +
-	"totalCount\"\xb5\x06\n" +
+	"totalCount\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\"\xa4\b\n" +
 	"\x0fPaymentResponse\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x19\n" +
 	"\bgroup_id\x18\x02 \x01(\tR\agroupId\x12\x19\n" +
@@ -1303,10 +3351,61 @@ const file_proto_payment_v1_payment_proto_rawDesc = "" +
 	"isApproved\x129\n" +
 	"\n" +
 	"created_at\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12E\n" +
-	"\bmetadata\x18\x13 \x03(\v2).payment.v1.PaymentResponse.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\x13 \x03(\v2).payment.v1.PaymentResponse.MetadataEntryR\bmetadata\x123\n" +
+	"\agateway\x18\x14 \x01(\v2\x19.payment.v1.GatewayResultR\agateway\x12/\n" +
+	"\x04tree\x18\x15 \x01(\v2\x1b.payment.v1.TransactionTreeR\x04tree\x12R\n" +
+	"\x14verification_outcome\x18\x16 \x01(\v2\x1f.payment.v1.VerificationOutcomeR\x13verificationOutcome\x123\n" +
+	"\bthree_ds\x18\x17 \x01(\v2\x18.payment.v1.ThreeDSecureR\athreeDs\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x85\a\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xce\x01\n" +
+	"\x13VerificationOutcome\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\x12#\n" +
+	"\rfailed_checks\x18\x02 \x03(\tR\ffailedChecks\x12\x1d\n" +
+	"\n" +
+	"avs_policy\x18\x03 \x01(\tR\tavsPolicy\x12\x1d\n" +
+	"\n" +
+	"avs_result\x18\x04 \x01(\tR\tavsResult\x12\x1d\n" +
+	"\n" +
+	"cvv_policy\x18\x05 \x01(\tR\tcvvPolicy\x12\x1d\n" +
+	"\n" +
+	"cvv_result\x18\x06 \x01(\tR\tcvvResult\"\xac\x01\n" +
+	"\x0fTransactionTree\x12+\n" +
+	"\x04root\x18\x01 \x01(\v2\x17.payment.v1.TransactionR\x04root\x123\n" +
+	"\bchildren\x18\x02 \x03(\v2\x17.payment.v1.TransactionR\bchildren\x127\n" +
+	"\x05state\x18\x03 \x01(\v2!.payment.v1.TransactionGroupStateR\x05state\"\xea\x03\n" +
+	"\x15TransactionGroupState\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12+\n" +
+	"\x11authorized_amount\x18\x02 \x01(\tR\x10authorizedAmount\x12'\n" +
+	"\x0fcaptured_amount\x18\x03 \x01(\tR\x0ecapturedAmount\x12'\n" +
+	"\x0frefunded_amount\x18\x04 \x01(\tR\x0erefundedAmount\x12+\n" +
+	"\x11capturable_amount\x18\x05 \x01(\tR\x10capturableAmount\x12+\n" +
+	"\x11refundable_amount\x18\x06 \x01(\tR\x10refundableAmount\x12\x16\n" +
+	"\x06voided\x18\a \x01(\bR\x06voided\x12'\n" +
+	"\x0freversed_amount\x18\b \x01(\tR\x0ereversedAmount\x12,\n" +
+	"\x12active_auth_amount\x18\t \x01(\tR\x10activeAuthAmount\x12-\n" +
+	"\x12incremented_amount\x18\n" +
+	" \x01(\tR\x11incrementedAmount\x12B\n" +
+	"\x0fauth_expires_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\rauthExpiresAt\"\xd5\x03\n" +
+	"\rGatewayResult\x12#\n" +
+	"\rresponse_code\x18\x01 \x01(\tR\fresponseCode\x12#\n" +
+	"\rresponse_text\x18\x02 \x01(\tR\fresponseText\x12\x1b\n" +
+	"\tauth_code\x18\x03 \x01(\tR\bauthCode\x12\x1a\n" +
+	"\bapproved\x18\x04 \x01(\bR\bapproved\x12\x1b\n" +
+	"\tcard_type\x18\x05 \x01(\tR\bcardType\x12\x19\n" +
+	"\bavs_code\x18\x06 \x01(\tR\aavsCode\x12\x1d\n" +
+	"\n" +
+	"avs_result\x18\a \x01(\tR\tavsResult\x12\x19\n" +
+	"\bcvv_code\x18\b \x01(\tR\acvvCode\x12\x1d\n" +
+	"\n" +
+	"cvv_result\x18\t \x01(\tR\tcvvResult\x12!\n" +
+	"\fdecline_code\x18\n" +
+	" \x01(\tR\vdeclineCode\x12)\n" +
+	"\x10decline_category\x18\v \x01(\tR\x0fdeclineCategory\x12%\n" +
+	"\x0edecline_reason\x18\f \x01(\tR\rdeclineReason\x12\x1c\n" +
+	"\tretriable\x18\r \x01(\bR\tretriable\x12\x1d\n" +
+	"\n" +
+	"latency_ms\x18\x0e \x01(\x03R\tlatencyMs\"\x8d\t\n" +
 	"\vTransaction\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
 	"\bgroup_id\x18\x02 \x01(\tR\agroupId\x12\x19\n" +
@@ -1332,36 +3431,103 @@ const file_proto_payment_v1_payment_proto_rawDesc = "" +
 	"created_at\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
 	"updated_at\x18\x14 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12A\n" +
-	"\bmetadata\x18\x15 \x03(\v2%.payment.v1.Transaction.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\x15 \x03(\v2%.payment.v1.Transaction.MetadataEntryR\bmetadata\x129\n" +
+	"\n" +
+	"settled_at\x18\x16 \x01(\v2\x1a.google.protobuf.TimestampR\tsettledAt\x12!\n" +
+	"\ffunding_date\x18\x17 \x01(\tR\vfundingDate\x12R\n" +
+	"\x14verification_outcome\x18\x18 \x01(\v2\x1f.payment.v1.VerificationOutcomeR\x13verificationOutcome\x12\x1f\n" +
+	"\vdata_region\x18\x19 \x01(\tR\n" +
+	"dataRegion\x123\n" +
+	"\bthree_ds\x18\x1a \x01(\v2\x18.payment.v1.ThreeDSecureR\athreeDs\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01*\xd8\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"[\n" +
+	"\x17GetEstimatedFeesRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\"\xbe\x02\n" +
+	"\vFeeEstimate\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x1d\n" +
+	"\n" +
+	"card_brand\x18\x02 \x01(\tR\tcardBrand\x12!\n" +
+	"\ffunding_type\x18\x03 \x01(\tR\vfundingType\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\tR\x06amount\x12!\n" +
+	"\frate_percent\x18\x05 \x01(\tR\vratePercent\x12\x1d\n" +
+	"\n" +
+	"rate_fixed\x18\x06 \x01(\tR\trateFixed\x12#\n" +
+	"\restimated_fee\x18\a \x01(\tR\festimatedFee\x12&\n" +
+	"\x0fis_default_rate\x18\b \x01(\bR\risDefaultRate\x12\x1f\n" +
+	"\vis_estimate\x18\t \x01(\bR\n" +
+	"isEstimate\"\xaa\x01\n" +
+	"\x1bGetSettlementSummaryRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x129\n" +
+	"\n" +
+	"start_date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x125\n" +
+	"\bend_date\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendDate\"M\n" +
+	"\x1cGetSettlementSummaryResponse\x12-\n" +
+	"\x04days\x18\x01 \x03(\v2\x19.payment.v1.SettlementDayR\x04days\"\x83\x02\n" +
+	"\rSettlementDay\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\x12\x1f\n" +
+	"\vgross_sales\x18\x03 \x01(\tR\n" +
+	"grossSales\x12\x1d\n" +
+	"\n" +
+	"sale_count\x18\x04 \x01(\x05R\tsaleCount\x12\x18\n" +
+	"\arefunds\x18\x05 \x01(\tR\arefunds\x12!\n" +
+	"\frefund_count\x18\x06 \x01(\x05R\vrefundCount\x12\x14\n" +
+	"\x05voids\x18\a \x01(\tR\x05voids\x12\x1d\n" +
+	"\n" +
+	"void_count\x18\b \x01(\x05R\tvoidCount\x12\x10\n" +
+	"\x03net\x18\t \x01(\tR\x03net\"_\n" +
+	"\x1aClassifyDeclineCodeRequest\x12\x1b\n" +
+	"\tauth_resp\x18\x01 \x01(\tR\bauthResp\x12$\n" +
+	"\x0eauth_resp_text\x18\x02 \x01(\tR\fauthRespText\"\xff\x01\n" +
+	"\x15DeclineClassification\x12\x1b\n" +
+	"\tauth_resp\x18\x01 \x01(\tR\bauthResp\x12\x1a\n" +
+	"\bapproved\x18\x02 \x01(\bR\bapproved\x12!\n" +
+	"\fdecline_code\x18\x03 \x01(\tR\vdeclineCode\x12)\n" +
+	"\x10decline_category\x18\x04 \x01(\tR\x0fdeclineCategory\x12%\n" +
+	"\x0edecline_reason\x18\x05 \x01(\tR\rdeclineReason\x12\x1a\n" +
+	"\bseverity\x18\x06 \x01(\tR\bseverity\x12\x1c\n" +
+	"\tretriable\x18\a \x01(\bR\tretriable*\xf8\x01\n" +
 	"\x11TransactionStatus\x12\"\n" +
 	"\x1eTRANSACTION_STATUS_UNSPECIFIED\x10\x00\x12\x1e\n" +
 	"\x1aTRANSACTION_STATUS_PENDING\x10\x01\x12 \n" +
 	"\x1cTRANSACTION_STATUS_COMPLETED\x10\x02\x12\x1d\n" +
 	"\x19TRANSACTION_STATUS_FAILED\x10\x03\x12\x1f\n" +
 	"\x1bTRANSACTION_STATUS_REFUNDED\x10\x04\x12\x1d\n" +
-	"\x19TRANSACTION_STATUS_VOIDED\x10\x05*\xc5\x01\n" +
+	"\x19TRANSACTION_STATUS_VOIDED\x10\x05\x12\x1e\n" +
+	"\x1aTRANSACTION_STATUS_EXPIRED\x10\x06*\x84\x02\n" +
 	"\x0fTransactionType\x12 \n" +
 	"\x1cTRANSACTION_TYPE_UNSPECIFIED\x10\x00\x12\x19\n" +
 	"\x15TRANSACTION_TYPE_AUTH\x10\x01\x12\x1c\n" +
 	"\x18TRANSACTION_TYPE_CAPTURE\x10\x02\x12\x1b\n" +
 	"\x17TRANSACTION_TYPE_CHARGE\x10\x03\x12\x1b\n" +
 	"\x17TRANSACTION_TYPE_REFUND\x10\x04\x12\x1d\n" +
-	"\x19TRANSACTION_TYPE_PRE_NOTE\x10\x05*z\n" +
+	"\x19TRANSACTION_TYPE_PRE_NOTE\x10\x05\x12\x1d\n" +
+	"\x19TRANSACTION_TYPE_REVERSAL\x10\x06\x12\x1e\n" +
+	"\x1aTRANSACTION_TYPE_INCREMENT\x10\a*z\n" +
 	"\x11PaymentMethodType\x12#\n" +
 	"\x1fPAYMENT_METHOD_TYPE_UNSPECIFIED\x10\x00\x12#\n" +
 	"\x1fPAYMENT_METHOD_TYPE_CREDIT_CARD\x10\x01\x12\x1b\n" +
-	"\x17PAYMENT_METHOD_TYPE_ACH\x10\x022\x87\x04\n" +
+	"\x17PAYMENT_METHOD_TYPE_ACH\x10\x022\xf2\n" +
+	"\n" +
 	"\x0ePaymentService\x12F\n" +
 	"\tAuthorize\x12\x1c.payment.v1.AuthorizeRequest\x1a\x1b.payment.v1.PaymentResponse\x12B\n" +
-	"\aCapture\x12\x1a.payment.v1.CaptureRequest\x1a\x1b.payment.v1.PaymentResponse\x12<\n" +
+	"\aCapture\x12\x1a.payment.v1.CaptureRequest\x1a\x1b.payment.v1.PaymentResponse\x12j\n" +
+	"\x1bPartialReverseAuthorization\x12..payment.v1.PartialReverseAuthorizationRequest\x1a\x1b.payment.v1.PaymentResponse\x12`\n" +
+	"\x16IncrementAuthorization\x12).payment.v1.IncrementAuthorizationRequest\x1a\x1b.payment.v1.PaymentResponse\x12<\n" +
 	"\x04Sale\x12\x17.payment.v1.SaleRequest\x1a\x1b.payment.v1.PaymentResponse\x12<\n" +
 	"\x04Void\x12\x17.payment.v1.VoidRequest\x1a\x1b.payment.v1.PaymentResponse\x12@\n" +
-	"\x06Refund\x12\x19.payment.v1.RefundRequest\x1a\x1b.payment.v1.PaymentResponse\x12L\n" +
-	"\x0eGetTransaction\x12!.payment.v1.GetTransactionRequest\x1a\x17.payment.v1.Transaction\x12]\n" +
-	"\x10ListTransactions\x12#.payment.v1.ListTransactionsRequest\x1a$.payment.v1.ListTransactionsResponseBBZ@github.com/kevin07696/payment-service/proto/payment/v1;paymentv1b\x06proto3"
+	"\x06Refund\x12\x19.payment.v1.RefundRequest\x1a\x1b.payment.v1.PaymentResponse\x12V\n" +
+	"\x11RefundByReference\x12$.payment.v1.RefundByReferenceRequest\x1a\x1b.payment.v1.PaymentResponse\x12L\n" +
+	"\x0eGetTransaction\x12!.payment.v1.GetTransactionRequest\x1a\x17.payment.v1.Transaction\x12o\n" +
+	"\x16GetTransactionStatuses\x12).payment.v1.GetTransactionStatusesRequest\x1a*.payment.v1.GetTransactionStatusesResponse\x12H\n" +
+	"\tBatchSale\x12\x1c.payment.v1.BatchSaleRequest\x1a\x1d.payment.v1.BatchSaleResponse\x12i\n" +
+	"\x14BatchGetTransactions\x12'.payment.v1.BatchGetTransactionsRequest\x1a(.payment.v1.BatchGetTransactionsResponse\x12]\n" +
+	"\x10ListTransactions\x12#.payment.v1.ListTransactionsRequest\x1a$.payment.v1.ListTransactionsResponse\x12P\n" +
+	"\x10GetEstimatedFees\x12#.payment.v1.GetEstimatedFeesRequest\x1a\x17.payment.v1.FeeEstimate\x12`\n" +
+	"\x13ClassifyDeclineCode\x12&.payment.v1.ClassifyDeclineCodeRequest\x1a!.payment.v1.DeclineClassification\x12i\n" +
+	"\x14GetSettlementSummary\x12'.payment.v1.GetSettlementSummaryRequest\x1a(.payment.v1.GetSettlementSummaryResponseBBZ@github.com/kevin07696/payment-service/proto/payment/v1;paymentv1b\x06proto3"
 
 var (
 	file_proto_payment_v1_payment_proto_rawDescOnce sync.Once
@@ -1376,62 +3542,130 @@ func file_proto_payment_v1_payment_proto_rawDescGZIP() []byte {
 }
 
 var file_proto_payment_v1_payment_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_proto_payment_v1_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_proto_payment_v1_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
 var file_proto_payment_v1_payment_proto_goTypes = []any{
-	(TransactionStatus)(0),           // 0: payment.v1.TransactionStatus
-	(TransactionType)(0),             // 1: payment.v1.TransactionType
-	(PaymentMethodType)(0),           // 2: payment.v1.PaymentMethodType
-	(*AuthorizeRequest)(nil),         // 3: payment.v1.AuthorizeRequest
-	(*CaptureRequest)(nil),           // 4: payment.v1.CaptureRequest
-	(*SaleRequest)(nil),              // 5: payment.v1.SaleRequest
-	(*VoidRequest)(nil),              // 6: payment.v1.VoidRequest
-	(*RefundRequest)(nil),            // 7: payment.v1.RefundRequest
-	(*GetTransactionRequest)(nil),    // 8: payment.v1.GetTransactionRequest
-	(*ListTransactionsRequest)(nil),  // 9: payment.v1.ListTransactionsRequest
-	(*ListTransactionsResponse)(nil), // 10: payment.v1.ListTransactionsResponse
-	(*PaymentResponse)(nil),          // 11: payment.v1.PaymentResponse
-	(*Transaction)(nil),              // 12: payment.v1.Transaction
-	nil,                              // 13: payment.v1.AuthorizeRequest.MetadataEntry
-	nil,                              // 14: payment.v1.SaleRequest.MetadataEntry
-	nil,                              // 15: payment.v1.PaymentResponse.MetadataEntry
-	nil,                              // 16: payment.v1.Transaction.MetadataEntry
-	(*timestamppb.Timestamp)(nil),    // 17: google.protobuf.Timestamp
+	(TransactionStatus)(0),                     // 0: payment.v1.TransactionStatus
+	(TransactionType)(0),                       // 1: payment.v1.TransactionType
+	(PaymentMethodType)(0),                     // 2: payment.v1.PaymentMethodType
+	(*AuthorizeRequest)(nil),                   // 3: payment.v1.AuthorizeRequest
+	(*ThreeDSecure)(nil),                       // 4: payment.v1.ThreeDSecure
+	(*CaptureRequest)(nil),                     // 5: payment.v1.CaptureRequest
+	(*PartialReverseAuthorizationRequest)(nil), // 6: payment.v1.PartialReverseAuthorizationRequest
+	(*IncrementAuthorizationRequest)(nil),      // 7: payment.v1.IncrementAuthorizationRequest
+	(*SaleRequest)(nil),                        // 8: payment.v1.SaleRequest
+	(*BatchSaleRequest)(nil),                   // 9: payment.v1.BatchSaleRequest
+	(*BatchSaleItemResult)(nil),                // 10: payment.v1.BatchSaleItemResult
+	(*BatchSaleResponse)(nil),                  // 11: payment.v1.BatchSaleResponse
+	(*VoidRequest)(nil),                        // 12: payment.v1.VoidRequest
+	(*RefundRequest)(nil),                      // 13: payment.v1.RefundRequest
+	(*RefundByReferenceRequest)(nil),           // 14: payment.v1.RefundByReferenceRequest
+	(*GetTransactionRequest)(nil),              // 15: payment.v1.GetTransactionRequest
+	(*GetTransactionStatusesRequest)(nil),      // 16: payment.v1.GetTransactionStatusesRequest
+	(*GetTransactionStatusesResponse)(nil),     // 17: payment.v1.GetTransactionStatusesResponse
+	(*BatchGetTransactionsRequest)(nil),        // 18: payment.v1.BatchGetTransactionsRequest
+	(*BatchGetTransactionsResponse)(nil),       // 19: payment.v1.BatchGetTransactionsResponse
+	(*TransactionStatusResult)(nil),            // 20: payment.v1.TransactionStatusResult
+	(*ListTransactionsRequest)(nil),            // 21: payment.v1.ListTransactionsRequest
+	(*ListTransactionsResponse)(nil),           // 22: payment.v1.ListTransactionsResponse
+	(*PaymentResponse)(nil),                    // 23: payment.v1.PaymentResponse
+	(*VerificationOutcome)(nil),                // 24: payment.v1.VerificationOutcome
+	(*TransactionTree)(nil),                    // 25: payment.v1.TransactionTree
+	(*TransactionGroupState)(nil),              // 26: payment.v1.TransactionGroupState
+	(*GatewayResult)(nil),                      // 27: payment.v1.GatewayResult
+	(*Transaction)(nil),                        // 28: payment.v1.Transaction
+	(*GetEstimatedFeesRequest)(nil),            // 29: payment.v1.GetEstimatedFeesRequest
+	(*FeeEstimate)(nil),                        // 30: payment.v1.FeeEstimate
+	(*GetSettlementSummaryRequest)(nil),        // 31: payment.v1.GetSettlementSummaryRequest
+	(*GetSettlementSummaryResponse)(nil),       // 32: payment.v1.GetSettlementSummaryResponse
+	(*SettlementDay)(nil),                      // 33: payment.v1.SettlementDay
+	(*ClassifyDeclineCodeRequest)(nil),         // 34: payment.v1.ClassifyDeclineCodeRequest
+	(*DeclineClassification)(nil),              // 35: payment.v1.DeclineClassification
+	nil,                                        // 36: payment.v1.AuthorizeRequest.MetadataEntry
+	nil,                                        // 37: payment.v1.SaleRequest.MetadataEntry
+	nil,                                        // 38: payment.v1.ListTransactionsRequest.MetadataContainsEntry
+	nil,                                        // 39: payment.v1.PaymentResponse.MetadataEntry
+	nil,                                        // 40: payment.v1.Transaction.MetadataEntry
+	(*timestamppb.Timestamp)(nil),              // 41: google.protobuf.Timestamp
 }
 var file_proto_payment_v1_payment_proto_depIdxs = []int32{
-	13, // 0: payment.v1.AuthorizeRequest.metadata:type_name -> payment.v1.AuthorizeRequest.MetadataEntry
-	14, // 1: payment.v1.SaleRequest.metadata:type_name -> payment.v1.SaleRequest.MetadataEntry
-	0,  // 2: payment.v1.ListTransactionsRequest.status:type_name -> payment.v1.TransactionStatus
-	12, // 3: payment.v1.ListTransactionsResponse.transactions:type_name -> payment.v1.Transaction
-	0,  // 4: payment.v1.PaymentResponse.status:type_name -> payment.v1.TransactionStatus
-	1,  // 5: payment.v1.PaymentResponse.type:type_name -> payment.v1.TransactionType
-	2,  // 6: payment.v1.PaymentResponse.payment_method_type:type_name -> payment.v1.PaymentMethodType
-	17, // 7: payment.v1.PaymentResponse.created_at:type_name -> google.protobuf.Timestamp
-	15, // 8: payment.v1.PaymentResponse.metadata:type_name -> payment.v1.PaymentResponse.MetadataEntry
-	0,  // 9: payment.v1.Transaction.status:type_name -> payment.v1.TransactionStatus
-	1,  // 10: payment.v1.Transaction.type:type_name -> payment.v1.TransactionType
-	2,  // 11: payment.v1.Transaction.payment_method_type:type_name -> payment.v1.PaymentMethodType
-	17, // 12: payment.v1.Transaction.created_at:type_name -> google.protobuf.Timestamp
-	17, // 13: payment.v1.Transaction.updated_at:type_name -> google.protobuf.Timestamp
-	16, // 14: payment.v1.Transaction.metadata:type_name -> payment.v1.Transaction.MetadataEntry
-	3,  // 15: payment.v1.PaymentService.Authorize:input_type -> payment.v1.AuthorizeRequest
-	4,  // 16: payment.v1.PaymentService.Capture:input_type -> payment.v1.CaptureRequest
-	5,  // 17: payment.v1.PaymentService.Sale:input_type -> payment.v1.SaleRequest
-	6,  // 18: payment.v1.PaymentService.Void:input_type -> payment.v1.VoidRequest
-	7,  // 19: payment.v1.PaymentService.Refund:input_type -> payment.v1.RefundRequest
-	8,  // 20: payment.v1.PaymentService.GetTransaction:input_type -> payment.v1.GetTransactionRequest
-	9,  // 21: payment.v1.PaymentService.ListTransactions:input_type -> payment.v1.ListTransactionsRequest
-	11, // 22: payment.v1.PaymentService.Authorize:output_type -> payment.v1.PaymentResponse
-	11, // 23: payment.v1.PaymentService.Capture:output_type -> payment.v1.PaymentResponse
-	11, // 24: payment.v1.PaymentService.Sale:output_type -> payment.v1.PaymentResponse
-	11, // 25: payment.v1.PaymentService.Void:output_type -> payment.v1.PaymentResponse
-	11, // 26: payment.v1.PaymentService.Refund:output_type -> payment.v1.PaymentResponse
-	12, // 27: payment.v1.PaymentService.GetTransaction:output_type -> payment.v1.Transaction
-	10, // 28: payment.v1.PaymentService.ListTransactions:output_type -> payment.v1.ListTransactionsResponse
-	22, // [22:29] is the sub-list for method output_type
-	15, // [15:22] is the sub-list for method input_type
-	15, // [15:15] is the sub-list for extension type_name
-	15, // [15:15] is the sub-list for extension extendee
-	0,  // [0:15] is the sub-list for field type_name
+	36, // 0: payment.v1.AuthorizeRequest.metadata:type_name -> payment.v1.AuthorizeRequest.MetadataEntry
+	4,  // 1: payment.v1.AuthorizeRequest.three_ds:type_name -> payment.v1.ThreeDSecure
+	37, // 2: payment.v1.SaleRequest.metadata:type_name -> payment.v1.SaleRequest.MetadataEntry
+	4,  // 3: payment.v1.SaleRequest.three_ds:type_name -> payment.v1.ThreeDSecure
+	8,  // 4: payment.v1.BatchSaleRequest.items:type_name -> payment.v1.SaleRequest
+	23, // 5: payment.v1.BatchSaleItemResult.payment:type_name -> payment.v1.PaymentResponse
+	10, // 6: payment.v1.BatchSaleResponse.results:type_name -> payment.v1.BatchSaleItemResult
+	20, // 7: payment.v1.GetTransactionStatusesResponse.results:type_name -> payment.v1.TransactionStatusResult
+	28, // 8: payment.v1.BatchGetTransactionsResponse.transactions:type_name -> payment.v1.Transaction
+	0,  // 9: payment.v1.TransactionStatusResult.status:type_name -> payment.v1.TransactionStatus
+	1,  // 10: payment.v1.TransactionStatusResult.type:type_name -> payment.v1.TransactionType
+	41, // 11: payment.v1.TransactionStatusResult.updated_at:type_name -> google.protobuf.Timestamp
+	41, // 12: payment.v1.TransactionStatusResult.settled_at:type_name -> google.protobuf.Timestamp
+	0,  // 13: payment.v1.ListTransactionsRequest.status:type_name -> payment.v1.TransactionStatus
+	38, // 14: payment.v1.ListTransactionsRequest.metadata_contains:type_name -> payment.v1.ListTransactionsRequest.MetadataContainsEntry
+	28, // 15: payment.v1.ListTransactionsResponse.transactions:type_name -> payment.v1.Transaction
+	0,  // 16: payment.v1.PaymentResponse.status:type_name -> payment.v1.TransactionStatus
+	1,  // 17: payment.v1.PaymentResponse.type:type_name -> payment.v1.TransactionType
+	2,  // 18: payment.v1.PaymentResponse.payment_method_type:type_name -> payment.v1.PaymentMethodType
+	41, // 19: payment.v1.PaymentResponse.created_at:type_name -> google.protobuf.Timestamp
+	39, // 20: payment.v1.PaymentResponse.metadata:type_name -> payment.v1.PaymentResponse.MetadataEntry
+	27, // 21: payment.v1.PaymentResponse.gateway:type_name -> payment.v1.GatewayResult
+	25, // 22: payment.v1.PaymentResponse.tree:type_name -> payment.v1.TransactionTree
+	24, // 23: payment.v1.PaymentResponse.verification_outcome:type_name -> payment.v1.VerificationOutcome
+	4,  // 24: payment.v1.PaymentResponse.three_ds:type_name -> payment.v1.ThreeDSecure
+	28, // 25: payment.v1.TransactionTree.root:type_name -> payment.v1.Transaction
+	28, // 26: payment.v1.TransactionTree.children:type_name -> payment.v1.Transaction
+	26, // 27: payment.v1.TransactionTree.state:type_name -> payment.v1.TransactionGroupState
+	41, // 28: payment.v1.TransactionGroupState.auth_expires_at:type_name -> google.protobuf.Timestamp
+	0,  // 29: payment.v1.Transaction.status:type_name -> payment.v1.TransactionStatus
+	1,  // 30: payment.v1.Transaction.type:type_name -> payment.v1.TransactionType
+	2,  // 31: payment.v1.Transaction.payment_method_type:type_name -> payment.v1.PaymentMethodType
+	41, // 32: payment.v1.Transaction.created_at:type_name -> google.protobuf.Timestamp
+	41, // 33: payment.v1.Transaction.updated_at:type_name -> google.protobuf.Timestamp
+	40, // 34: payment.v1.Transaction.metadata:type_name -> payment.v1.Transaction.MetadataEntry
+	41, // 35: payment.v1.Transaction.settled_at:type_name -> google.protobuf.Timestamp
+	24, // 36: payment.v1.Transaction.verification_outcome:type_name -> payment.v1.VerificationOutcome
+	4,  // 37: payment.v1.Transaction.three_ds:type_name -> payment.v1.ThreeDSecure
+	41, // 38: payment.v1.GetSettlementSummaryRequest.start_date:type_name -> google.protobuf.Timestamp
+	41, // 39: payment.v1.GetSettlementSummaryRequest.end_date:type_name -> google.protobuf.Timestamp
+	33, // 40: payment.v1.GetSettlementSummaryResponse.days:type_name -> payment.v1.SettlementDay
+	3,  // 41: payment.v1.PaymentService.Authorize:input_type -> payment.v1.AuthorizeRequest
+	5,  // 42: payment.v1.PaymentService.Capture:input_type -> payment.v1.CaptureRequest
+	6,  // 43: payment.v1.PaymentService.PartialReverseAuthorization:input_type -> payment.v1.PartialReverseAuthorizationRequest
+	7,  // 44: payment.v1.PaymentService.IncrementAuthorization:input_type -> payment.v1.IncrementAuthorizationRequest
+	8,  // 45: payment.v1.PaymentService.Sale:input_type -> payment.v1.SaleRequest
+	12, // 46: payment.v1.PaymentService.Void:input_type -> payment.v1.VoidRequest
+	13, // 47: payment.v1.PaymentService.Refund:input_type -> payment.v1.RefundRequest
+	14, // 48: payment.v1.PaymentService.RefundByReference:input_type -> payment.v1.RefundByReferenceRequest
+	15, // 49: payment.v1.PaymentService.GetTransaction:input_type -> payment.v1.GetTransactionRequest
+	16, // 50: payment.v1.PaymentService.GetTransactionStatuses:input_type -> payment.v1.GetTransactionStatusesRequest
+	9,  // 51: payment.v1.PaymentService.BatchSale:input_type -> payment.v1.BatchSaleRequest
+	18, // 52: payment.v1.PaymentService.BatchGetTransactions:input_type -> payment.v1.BatchGetTransactionsRequest
+	21, // 53: payment.v1.PaymentService.ListTransactions:input_type -> payment.v1.ListTransactionsRequest
+	29, // 54: payment.v1.PaymentService.GetEstimatedFees:input_type -> payment.v1.GetEstimatedFeesRequest
+	34, // 55: payment.v1.PaymentService.ClassifyDeclineCode:input_type -> payment.v1.ClassifyDeclineCodeRequest
+	31, // 56: payment.v1.PaymentService.GetSettlementSummary:input_type -> payment.v1.GetSettlementSummaryRequest
+	23, // 57: payment.v1.PaymentService.Authorize:output_type -> payment.v1.PaymentResponse
+	23, // 58: payment.v1.PaymentService.Capture:output_type -> payment.v1.PaymentResponse
+	23, // 59: payment.v1.PaymentService.PartialReverseAuthorization:output_type -> payment.v1.PaymentResponse
+	23, // 60: payment.v1.PaymentService.IncrementAuthorization:output_type -> payment.v1.PaymentResponse
+	23, // 61: payment.v1.PaymentService.Sale:output_type -> payment.v1.PaymentResponse
+	23, // 62: payment.v1.PaymentService.Void:output_type -> payment.v1.PaymentResponse
+	23, // 63: payment.v1.PaymentService.Refund:output_type -> payment.v1.PaymentResponse
+	23, // 64: payment.v1.PaymentService.RefundByReference:output_type -> payment.v1.PaymentResponse
+	28, // 65: payment.v1.PaymentService.GetTransaction:output_type -> payment.v1.Transaction
+	17, // 66: payment.v1.PaymentService.GetTransactionStatuses:output_type -> payment.v1.GetTransactionStatusesResponse
+	11, // 67: payment.v1.PaymentService.BatchSale:output_type -> payment.v1.BatchSaleResponse
+	19, // 68: payment.v1.PaymentService.BatchGetTransactions:output_type -> payment.v1.BatchGetTransactionsResponse
+	22, // 69: payment.v1.PaymentService.ListTransactions:output_type -> payment.v1.ListTransactionsResponse
+	30, // 70: payment.v1.PaymentService.GetEstimatedFees:output_type -> payment.v1.FeeEstimate
+	35, // 71: payment.v1.PaymentService.ClassifyDeclineCode:output_type -> payment.v1.DeclineClassification
+	32, // 72: payment.v1.PaymentService.GetSettlementSummary:output_type -> payment.v1.GetSettlementSummaryResponse
+	57, // [57:73] is the sub-list for method output_type
+	41, // [41:57] is the sub-list for method input_type
+	41, // [41:41] is the sub-list for extension type_name
+	41, // [41:41] is the sub-list for extension extendee
+	0,  // [0:41] is the sub-list for field type_name
 }
 
 func init() { file_proto_payment_v1_payment_proto_init() }
@@ -1443,17 +3677,24 @@ func file_proto_payment_v1_payment_proto_init() {
 		(*AuthorizeRequest_PaymentMethodId)(nil),
 		(*AuthorizeRequest_PaymentToken)(nil),
 	}
-	file_proto_payment_v1_payment_proto_msgTypes[2].OneofWrappers = []any{
+	file_proto_payment_v1_payment_proto_msgTypes[2].OneofWrappers = []any{}
+	file_proto_payment_v1_payment_proto_msgTypes[3].OneofWrappers = []any{}
+	file_proto_payment_v1_payment_proto_msgTypes[4].OneofWrappers = []any{}
+	file_proto_payment_v1_payment_proto_msgTypes[5].OneofWrappers = []any{
 		(*SaleRequest_PaymentMethodId)(nil),
 		(*SaleRequest_PaymentToken)(nil),
 	}
+	file_proto_payment_v1_payment_proto_msgTypes[9].OneofWrappers = []any{}
+	file_proto_payment_v1_payment_proto_msgTypes[10].OneofWrappers = []any{}
+	file_proto_payment_v1_payment_proto_msgTypes[11].OneofWrappers = []any{}
+	file_proto_payment_v1_payment_proto_msgTypes[18].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_payment_v1_payment_proto_rawDesc), len(file_proto_payment_v1_payment_proto_rawDesc)),
 			NumEnums:      3,
-			NumMessages:   14,
+			NumMessages:   38,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,13 +19,22 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PaymentService_Authorize_FullMethodName        = "/payment.v1.PaymentService/Authorize"
-	PaymentService_Capture_FullMethodName          = "/payment.v1.PaymentService/Capture"
-	PaymentService_Sale_FullMethodName             = "/payment.v1.PaymentService/Sale"
-	PaymentService_Void_FullMethodName             = "/payment.v1.PaymentService/Void"
-	PaymentService_Refund_FullMethodName           = "/payment.v1.PaymentService/Refund"
-	PaymentService_GetTransaction_FullMethodName   = "/payment.v1.PaymentService/GetTransaction"
-	PaymentService_ListTransactions_FullMethodName = "/payment.v1.PaymentService/ListTransactions"
+	PaymentService_Authorize_FullMethodName                   = "/payment.v1.PaymentService/Authorize"
+	PaymentService_Capture_FullMethodName                     = "/payment.v1.PaymentService/Capture"
+	PaymentService_PartialReverseAuthorization_FullMethodName = "/payment.v1.PaymentService/PartialReverseAuthorization"
+	PaymentService_IncrementAuthorization_FullMethodName      = "/payment.v1.PaymentService/IncrementAuthorization"
+	PaymentService_Sale_FullMethodName                        = "/payment.v1.PaymentService/Sale"
+	PaymentService_Void_FullMethodName                        = "/payment.v1.PaymentService/Void"
+	PaymentService_Refund_FullMethodName                      = "/payment.v1.PaymentService/Refund"
+	PaymentService_RefundByReference_FullMethodName           = "/payment.v1.PaymentService/RefundByReference"
+	PaymentService_GetTransaction_FullMethodName              = "/payment.v1.PaymentService/GetTransaction"
+	PaymentService_GetTransactionStatuses_FullMethodName      = "/payment.v1.PaymentService/GetTransactionStatuses"
+	PaymentService_BatchSale_FullMethodName                   = "/payment.v1.PaymentService/BatchSale"
+	PaymentService_BatchGetTransactions_FullMethodName        = "/payment.v1.PaymentService/BatchGetTransactions"
+	PaymentService_ListTransactions_FullMethodName            = "/payment.v1.PaymentService/ListTransactions"
+	PaymentService_GetEstimatedFees_FullMethodName            = "/payment.v1.PaymentService/GetEstimatedFees"
+	PaymentService_ClassifyDeclineCode_FullMethodName         = "/payment.v1.PaymentService/ClassifyDeclineCode"
+	PaymentService_GetSettlementSummary_FullMethodName        = "/payment.v1.PaymentService/GetSettlementSummary"
 )
 
 // PaymentServiceClient is the client API for PaymentService service.
@@ -38,16 +47,34 @@ type PaymentServiceClient interface {
 	Authorize(ctx context.Context, in *AuthorizeRequest, opts ...grpc.CallOption) (*PaymentResponse, error)
 	// Capture completes a previously authorized payment
 	Capture(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (*PaymentResponse, error)
+	// PartialReverseAuthorization releases part of an authorization hold; later captures are bounded by the reduced amount
+	PartialReverseAuthorization(ctx context.Context, in *PartialReverseAuthorizationRequest, opts ...grpc.CallOption) (*PaymentResponse, error)
+	// IncrementAuthorization adds to an open authorization hold and extends it; later captures can take the increased amount
+	IncrementAuthorization(ctx context.Context, in *IncrementAuthorizationRequest, opts ...grpc.CallOption) (*PaymentResponse, error)
 	// Sale combines authorize and capture in one operation
 	Sale(ctx context.Context, in *SaleRequest, opts ...grpc.CallOption) (*PaymentResponse, error)
 	// Void cancels an authorized or captured payment
 	Void(ctx context.Context, in *VoidRequest, opts ...grpc.CallOption) (*PaymentResponse, error)
 	// Refund returns funds to the customer
 	Refund(ctx context.Context, in *RefundRequest, opts ...grpc.CallOption) (*PaymentResponse, error)
+	// RefundByReference refunds a payment identified by its EPX BRIC or TRAN_NBR instead of its transaction ID
+	RefundByReference(ctx context.Context, in *RefundByReferenceRequest, opts ...grpc.CallOption) (*PaymentResponse, error)
 	// GetTransaction retrieves transaction details
 	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*Transaction, error)
+	// GetTransactionStatuses returns the current status of a batch of transactions (max 100 IDs)
+	GetTransactionStatuses(ctx context.Context, in *GetTransactionStatusesRequest, opts ...grpc.CallOption) (*GetTransactionStatusesResponse, error)
+	// BatchSale runs up to 100 sales; a retried batch_idempotency_key returns the original results without re-processing
+	BatchSale(ctx context.Context, in *BatchSaleRequest, opts ...grpc.CallOption) (*BatchSaleResponse, error)
+	// BatchGetTransactions returns full details for a batch of transactions (max 100 IDs), in request order
+	BatchGetTransactions(ctx context.Context, in *BatchGetTransactionsRequest, opts ...grpc.CallOption) (*BatchGetTransactionsResponse, error)
 	// ListTransactions lists transactions for a merchant or customer
 	ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error)
+	// GetEstimatedFees estimates the processing fee for a transaction (estimate only, not the actual cost)
+	GetEstimatedFees(ctx context.Context, in *GetEstimatedFeesRequest, opts ...grpc.CallOption) (*FeeEstimate, error)
+	// ClassifyDeclineCode previews how an EPX response code is categorized (diagnostic; contacts no gateway)
+	ClassifyDeclineCode(ctx context.Context, in *ClassifyDeclineCodeRequest, opts ...grpc.CallOption) (*DeclineClassification, error)
+	// GetSettlementSummary totals a merchant's sales, refunds and voids per day (UTC) for a date range
+	GetSettlementSummary(ctx context.Context, in *GetSettlementSummaryRequest, opts ...grpc.CallOption) (*GetSettlementSummaryResponse, error)
 }
 
 type paymentServiceClient struct {
@@ -78,6 +105,26 @@ func (c *paymentServiceClient) Capture(ctx context.Context, in *CaptureRequest,
 	return out, nil
 }
 
+func (c *paymentServiceClient) PartialReverseAuthorization(ctx context.Context, in *PartialReverseAuthorizationRequest, opts ...grpc.CallOption) (*PaymentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentResponse)
+	err := c.cc.Invoke(ctx, PaymentService_PartialReverseAuthorization_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) IncrementAuthorization(ctx context.Context, in *IncrementAuthorizationRequest, opts ...grpc.CallOption) (*PaymentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentResponse)
+	err := c.cc.Invoke(ctx, PaymentService_IncrementAuthorization_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *paymentServiceClient) Sale(ctx context.Context, in *SaleRequest, opts ...grpc.CallOption) (*PaymentResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PaymentResponse)
@@ -108,6 +155,16 @@ func (c *paymentServiceClient) Refund(ctx context.Context, in *RefundRequest, op
 	return out, nil
 }
 
+func (c *paymentServiceClient) RefundByReference(ctx context.Context, in *RefundByReferenceRequest, opts ...grpc.CallOption) (*PaymentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentResponse)
+	err := c.cc.Invoke(ctx, PaymentService_RefundByReference_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *paymentServiceClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*Transaction, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Transaction)
@@ -118,6 +175,36 @@ func (c *paymentServiceClient) GetTransaction(ctx context.Context, in *GetTransa
 	return out, nil
 }
 
+func (c *paymentServiceClient) GetTransactionStatuses(ctx context.Context, in *GetTransactionStatusesRequest, opts ...grpc.CallOption) (*GetTransactionStatusesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTransactionStatusesResponse)
+	err := c.cc.Invoke(ctx, PaymentService_GetTransactionStatuses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) BatchSale(ctx context.Context, in *BatchSaleRequest, opts ...grpc.CallOption) (*BatchSaleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchSaleResponse)
+	err := c.cc.Invoke(ctx, PaymentService_BatchSale_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) BatchGetTransactions(ctx context.Context, in *BatchGetTransactionsRequest, opts ...grpc.CallOption) (*BatchGetTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetTransactionsResponse)
+	err := c.cc.Invoke(ctx, PaymentService_BatchGetTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *paymentServiceClient) ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListTransactionsResponse)
@@ -128,6 +215,36 @@ func (c *paymentServiceClient) ListTransactions(ctx context.Context, in *ListTra
 	return out, nil
 }
 
+func (c *paymentServiceClient) GetEstimatedFees(ctx context.Context, in *GetEstimatedFeesRequest, opts ...grpc.CallOption) (*FeeEstimate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FeeEstimate)
+	err := c.cc.Invoke(ctx, PaymentService_GetEstimatedFees_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) ClassifyDeclineCode(ctx context.Context, in *ClassifyDeclineCodeRequest, opts ...grpc.CallOption) (*DeclineClassification, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeclineClassification)
+	err := c.cc.Invoke(ctx, PaymentService_ClassifyDeclineCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentServiceClient) GetSettlementSummary(ctx context.Context, in *GetSettlementSummaryRequest, opts ...grpc.CallOption) (*GetSettlementSummaryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSettlementSummaryResponse)
+	err := c.cc.Invoke(ctx, PaymentService_GetSettlementSummary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PaymentServiceServer is the server API for PaymentService service.
 // All implementations must embed UnimplementedPaymentServiceServer
 // for forward compatibility.
@@ -138,16 +255,34 @@ type PaymentServiceServer interface {
 	Authorize(context.Context, *AuthorizeRequest) (*PaymentResponse, error)
 	// Capture completes a previously authorized payment
 	Capture(context.Context, *CaptureRequest) (*PaymentResponse, error)
+	// PartialReverseAuthorization releases part of an authorization hold; later captures are bounded by the reduced amount
+	PartialReverseAuthorization(context.Context, *PartialReverseAuthorizationRequest) (*PaymentResponse, error)
+	// IncrementAuthorization adds to an open authorization hold and extends it; later captures can take the increased amount
+	IncrementAuthorization(context.Context, *IncrementAuthorizationRequest) (*PaymentResponse, error)
 	// Sale combines authorize and capture in one operation
 	Sale(context.Context, *SaleRequest) (*PaymentResponse, error)
 	// Void cancels an authorized or captured payment
 	Void(context.Context, *VoidRequest) (*PaymentResponse, error)
 	// Refund returns funds to the customer
 	Refund(context.Context, *RefundRequest) (*PaymentResponse, error)
+	// RefundByReference refunds a payment identified by its EPX BRIC or TRAN_NBR instead of its transaction ID
+	RefundByReference(context.Context, *RefundByReferenceRequest) (*PaymentResponse, error)
 	// GetTransaction retrieves transaction details
 	GetTransaction(context.Context, *GetTransactionRequest) (*Transaction, error)
+	// GetTransactionStatuses returns the current status of a batch of transactions (max 100 IDs)
+	GetTransactionStatuses(context.Context, *GetTransactionStatusesRequest) (*GetTransactionStatusesResponse, error)
+	// BatchSale runs up to 100 sales; a retried batch_idempotency_key returns the original results without re-processing
+	BatchSale(context.Context, *BatchSaleRequest) (*BatchSaleResponse, error)
+	// BatchGetTransactions returns full details for a batch of transactions (max 100 IDs), in request order
+	BatchGetTransactions(context.Context, *BatchGetTransactionsRequest) (*BatchGetTransactionsResponse, error)
 	// ListTransactions lists transactions for a merchant or customer
 	ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error)
+	// GetEstimatedFees estimates the processing fee for a transaction (estimate only, not the actual cost)
+	GetEstimatedFees(context.Context, *GetEstimatedFeesRequest) (*FeeEstimate, error)
+	// ClassifyDeclineCode previews how an EPX response code is categorized (diagnostic; contacts no gateway)
+	ClassifyDeclineCode(context.Context, *ClassifyDeclineCodeRequest) (*DeclineClassification, error)
+	// GetSettlementSummary totals a merchant's sales, refunds and voids per day (UTC) for a date range
+	GetSettlementSummary(context.Context, *GetSettlementSummaryRequest) (*GetSettlementSummaryResponse, error)
 	mustEmbedUnimplementedPaymentServiceServer()
 }
 
@@ -164,6 +299,12 @@ func (UnimplementedPaymentServiceServer) Authorize(context.Context, *AuthorizeRe
 func (UnimplementedPaymentServiceServer) Capture(context.Context, *CaptureRequest) (*PaymentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Capture not implemented")
 }
+func (UnimplementedPaymentServiceServer) PartialReverseAuthorization(context.Context, *PartialReverseAuthorizationRequest) (*PaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PartialReverseAuthorization not implemented")
+}
+func (UnimplementedPaymentServiceServer) IncrementAuthorization(context.Context, *IncrementAuthorizationRequest) (*PaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IncrementAuthorization not implemented")
+}
 func (UnimplementedPaymentServiceServer) Sale(context.Context, *SaleRequest) (*PaymentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Sale not implemented")
 }
@@ -173,12 +314,33 @@ func (UnimplementedPaymentServiceServer) Void(context.Context, *VoidRequest) (*P
 func (UnimplementedPaymentServiceServer) Refund(context.Context, *RefundRequest) (*PaymentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Refund not implemented")
 }
+func (UnimplementedPaymentServiceServer) RefundByReference(context.Context, *RefundByReferenceRequest) (*PaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundByReference not implemented")
+}
 func (UnimplementedPaymentServiceServer) GetTransaction(context.Context, *GetTransactionRequest) (*Transaction, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
 }
+func (UnimplementedPaymentServiceServer) GetTransactionStatuses(context.Context, *GetTransactionStatusesRequest) (*GetTransactionStatusesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionStatuses not implemented")
+}
+func (UnimplementedPaymentServiceServer) BatchSale(context.Context, *BatchSaleRequest) (*BatchSaleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchSale not implemented")
+}
+func (UnimplementedPaymentServiceServer) BatchGetTransactions(context.Context, *BatchGetTransactionsRequest) (*BatchGetTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetTransactions not implemented")
+}
 func (UnimplementedPaymentServiceServer) ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListTransactions not implemented")
 }
+func (UnimplementedPaymentServiceServer) GetEstimatedFees(context.Context, *GetEstimatedFeesRequest) (*FeeEstimate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEstimatedFees not implemented")
+}
+func (UnimplementedPaymentServiceServer) ClassifyDeclineCode(context.Context, *ClassifyDeclineCodeRequest) (*DeclineClassification, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClassifyDeclineCode not implemented")
+}
+func (UnimplementedPaymentServiceServer) GetSettlementSummary(context.Context, *GetSettlementSummaryRequest) (*GetSettlementSummaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSettlementSummary not implemented")
+}
 func (UnimplementedPaymentServiceServer) mustEmbedUnimplementedPaymentServiceServer() {}
 func (UnimplementedPaymentServiceServer) testEmbeddedByValue()                        {}
 
@@ -236,6 +398,42 @@ func _PaymentService_Capture_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_PartialReverseAuthorization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PartialReverseAuthorizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).PartialReverseAuthorization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_PartialReverseAuthorization_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).PartialReverseAuthorization(ctx, req.(*PartialReverseAuthorizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_IncrementAuthorization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IncrementAuthorizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).IncrementAuthorization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_IncrementAuthorization_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).IncrementAuthorization(ctx, req.(*IncrementAuthorizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PaymentService_Sale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SaleRequest)
 	if err := dec(in); err != nil {
@@ -290,6 +488,24 @@ func _PaymentService_Refund_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_RefundByReference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundByReferenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).RefundByReference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_RefundByReference_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).RefundByReference(ctx, req.(*RefundByReferenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PaymentService_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetTransactionRequest)
 	if err := dec(in); err != nil {
@@ -308,6 +524,60 @@ func _PaymentService_GetTransaction_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_GetTransactionStatuses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionStatusesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetTransactionStatuses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetTransactionStatuses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetTransactionStatuses(ctx, req.(*GetTransactionStatusesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_BatchSale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchSaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).BatchSale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_BatchSale_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).BatchSale(ctx, req.(*BatchSaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_BatchGetTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).BatchGetTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_BatchGetTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).BatchGetTransactions(ctx, req.(*BatchGetTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PaymentService_ListTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListTransactionsRequest)
 	if err := dec(in); err != nil {
@@ -326,6 +596,60 @@ func _PaymentService_ListTransactions_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentService_GetEstimatedFees_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEstimatedFeesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetEstimatedFees(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetEstimatedFees_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetEstimatedFees(ctx, req.(*GetEstimatedFeesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_ClassifyDeclineCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClassifyDeclineCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).ClassifyDeclineCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_ClassifyDeclineCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).ClassifyDeclineCode(ctx, req.(*ClassifyDeclineCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentService_GetSettlementSummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSettlementSummaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServiceServer).GetSettlementSummary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentService_GetSettlementSummary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServiceServer).GetSettlementSummary(ctx, req.(*GetSettlementSummaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PaymentService_ServiceDesc is the grpc.ServiceDesc for PaymentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -341,6 +665,14 @@ var PaymentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Capture",
 			Handler:    _PaymentService_Capture_Handler,
 		},
+		{
+			MethodName: "PartialReverseAuthorization",
+			Handler:    _PaymentService_PartialReverseAuthorization_Handler,
+		},
+		{
+			MethodName: "IncrementAuthorization",
+			Handler:    _PaymentService_IncrementAuthorization_Handler,
+		},
 		{
 			MethodName: "Sale",
 			Handler:    _PaymentService_Sale_Handler,
@@ -353,14 +685,42 @@ var PaymentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Refund",
 			Handler:    _PaymentService_Refund_Handler,
 		},
+		{
+			MethodName: "RefundByReference",
+			Handler:    _PaymentService_RefundByReference_Handler,
+		},
 		{
 			MethodName: "GetTransaction",
 			Handler:    _PaymentService_GetTransaction_Handler,
 		},
+		{
+			MethodName: "GetTransactionStatuses",
+			Handler:    _PaymentService_GetTransactionStatuses_Handler,
+		},
+		{
+			MethodName: "BatchSale",
+			Handler:    _PaymentService_BatchSale_Handler,
+		},
+		{
+			MethodName: "BatchGetTransactions",
+			Handler:    _PaymentService_BatchGetTransactions_Handler,
+		},
 		{
 			MethodName: "ListTransactions",
 			Handler:    _PaymentService_ListTransactions_Handler,
 		},
+		{
+			MethodName: "GetEstimatedFees",
+			Handler:    _PaymentService_GetEstimatedFees_Handler,
+		},
+		{
+			MethodName: "ClassifyDeclineCode",
+			Handler:    _PaymentService_ClassifyDeclineCode_Handler,
+		},
+		{
+			MethodName: "GetSettlementSummary",
+			Handler:    _PaymentService_GetSettlementSummary_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/payment/v1/payment.proto",
@@ -367,6 +367,59 @@ func (x *ListPaymentMethodsResponse) GetPaymentMethods() []*PaymentMethod {
 	return nil
 }
 
+// ListExpiringPaymentMethodsRequest lists cards expiring soon (already expired cards are excluded)
+type ListExpiringPaymentMethodsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	WithinDays    int32                  `protobuf:"varint,2,opt,name=within_days,json=withinDays,proto3" json:"within_days,omitempty"` // 1-365
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExpiringPaymentMethodsRequest) Reset() {
+	*x = ListExpiringPaymentMethodsRequest{}
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExpiringPaymentMethodsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExpiringPaymentMethodsRequest) ProtoMessage() {}
+
+func (x *ListExpiringPaymentMethodsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExpiringPaymentMethodsRequest.ProtoReflect.Descriptor instead.
+func (*ListExpiringPaymentMethodsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListExpiringPaymentMethodsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ListExpiringPaymentMethodsRequest) GetWithinDays() int32 {
+	if x != nil {
+		return x.WithinDays
+	}
+	return 0
+}
+
 // UpdatePaymentMethodStatusRequest updates payment method status
 type UpdatePaymentMethodStatusRequest struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
@@ -380,7 +433,7 @@ type UpdatePaymentMethodStatusRequest struct {
 
 func (x *UpdatePaymentMethodStatusRequest) Reset() {
 	*x = UpdatePaymentMethodStatusRequest{}
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[4]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -392,7 +445,7 @@ func (x *UpdatePaymentMethodStatusRequest) String() string {
 func (*UpdatePaymentMethodStatusRequest) ProtoMessage() {}
 
 func (x *UpdatePaymentMethodStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[4]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -405,7 +458,7 @@ func (x *UpdatePaymentMethodStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdatePaymentMethodStatusRequest.ProtoReflect.Descriptor instead.
 func (*UpdatePaymentMethodStatusRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{4}
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *UpdatePaymentMethodStatusRequest) GetPaymentMethodId() string {
@@ -436,6 +489,83 @@ func (x *UpdatePaymentMethodStatusRequest) GetIsActive() bool {
 	return false
 }
 
+// UpdatePaymentMethodExpiryRequest sets a new card expiration date
+type UpdatePaymentMethodExpiryRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PaymentMethodId string                 `protobuf:"bytes,1,opt,name=payment_method_id,json=paymentMethodId,proto3" json:"payment_method_id,omitempty"`
+	AgentId         string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	CustomerId      string                 `protobuf:"bytes,3,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	CardExpMonth    int32                  `protobuf:"varint,4,opt,name=card_exp_month,json=cardExpMonth,proto3" json:"card_exp_month,omitempty"` // 1-12
+	CardExpYear     int32                  `protobuf:"varint,5,opt,name=card_exp_year,json=cardExpYear,proto3" json:"card_exp_year,omitempty"`    // Four digits, e.g. 2029
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdatePaymentMethodExpiryRequest) Reset() {
+	*x = UpdatePaymentMethodExpiryRequest{}
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePaymentMethodExpiryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePaymentMethodExpiryRequest) ProtoMessage() {}
+
+func (x *UpdatePaymentMethodExpiryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePaymentMethodExpiryRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePaymentMethodExpiryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdatePaymentMethodExpiryRequest) GetPaymentMethodId() string {
+	if x != nil {
+		return x.PaymentMethodId
+	}
+	return ""
+}
+
+func (x *UpdatePaymentMethodExpiryRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *UpdatePaymentMethodExpiryRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *UpdatePaymentMethodExpiryRequest) GetCardExpMonth() int32 {
+	if x != nil {
+		return x.CardExpMonth
+	}
+	return 0
+}
+
+func (x *UpdatePaymentMethodExpiryRequest) GetCardExpYear() int32 {
+	if x != nil {
+		return x.CardExpYear
+	}
+	return 0
+}
+
 // DeletePaymentMethodRequest soft deletes a payment method
 type DeletePaymentMethodRequest struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
@@ -447,7 +577,7 @@ type DeletePaymentMethodRequest struct {
 
 func (x *DeletePaymentMethodRequest) Reset() {
 	*x = DeletePaymentMethodRequest{}
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[5]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -459,7 +589,7 @@ func (x *DeletePaymentMethodRequest) String() string {
 func (*DeletePaymentMethodRequest) ProtoMessage() {}
 
 func (x *DeletePaymentMethodRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[5]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -472,7 +602,7 @@ func (x *DeletePaymentMethodRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeletePaymentMethodRequest.ProtoReflect.Descriptor instead.
 func (*DeletePaymentMethodRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{5}
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *DeletePaymentMethodRequest) GetPaymentMethodId() string {
@@ -500,7 +630,7 @@ type DeletePaymentMethodResponse struct {
 
 func (x *DeletePaymentMethodResponse) Reset() {
 	*x = DeletePaymentMethodResponse{}
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[6]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -512,7 +642,7 @@ func (x *DeletePaymentMethodResponse) String() string {
 func (*DeletePaymentMethodResponse) ProtoMessage() {}
 
 func (x *DeletePaymentMethodResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[6]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -525,7 +655,7 @@ func (x *DeletePaymentMethodResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeletePaymentMethodResponse.ProtoReflect.Descriptor instead.
 func (*DeletePaymentMethodResponse) Descriptor() ([]byte, []int) {
-	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{6}
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *DeletePaymentMethodResponse) GetSuccess() bool {
@@ -554,7 +684,7 @@ type SetDefaultPaymentMethodRequest struct {
 
 func (x *SetDefaultPaymentMethodRequest) Reset() {
 	*x = SetDefaultPaymentMethodRequest{}
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[7]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -566,7 +696,7 @@ func (x *SetDefaultPaymentMethodRequest) String() string {
 func (*SetDefaultPaymentMethodRequest) ProtoMessage() {}
 
 func (x *SetDefaultPaymentMethodRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[7]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -579,7 +709,7 @@ func (x *SetDefaultPaymentMethodRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetDefaultPaymentMethodRequest.ProtoReflect.Descriptor instead.
 func (*SetDefaultPaymentMethodRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{7}
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *SetDefaultPaymentMethodRequest) GetPaymentMethodId() string {
@@ -615,7 +745,7 @@ type VerifyACHAccountRequest struct {
 
 func (x *VerifyACHAccountRequest) Reset() {
 	*x = VerifyACHAccountRequest{}
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[8]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -627,7 +757,7 @@ func (x *VerifyACHAccountRequest) String() string {
 func (*VerifyACHAccountRequest) ProtoMessage() {}
 
 func (x *VerifyACHAccountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[8]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -640,7 +770,7 @@ func (x *VerifyACHAccountRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VerifyACHAccountRequest.ProtoReflect.Descriptor instead.
 func (*VerifyACHAccountRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{8}
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *VerifyACHAccountRequest) GetPaymentMethodId() string {
@@ -677,7 +807,7 @@ type VerifyACHAccountResponse struct {
 
 func (x *VerifyACHAccountResponse) Reset() {
 	*x = VerifyACHAccountResponse{}
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[9]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -689,7 +819,7 @@ func (x *VerifyACHAccountResponse) String() string {
 func (*VerifyACHAccountResponse) ProtoMessage() {}
 
 func (x *VerifyACHAccountResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[9]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -702,7 +832,7 @@ func (x *VerifyACHAccountResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VerifyACHAccountResponse.ProtoReflect.Descriptor instead.
 func (*VerifyACHAccountResponse) Descriptor() ([]byte, []int) {
-	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{9}
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *VerifyACHAccountResponse) GetPaymentMethodId() string {
@@ -733,6 +863,274 @@ func (x *VerifyACHAccountResponse) GetMessage() string {
 	return ""
 }
 
+// InitiateMicroDepositsRequest sends micro-deposits to an ACH payment method
+type InitiateMicroDepositsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PaymentMethodId string                 `protobuf:"bytes,1,opt,name=payment_method_id,json=paymentMethodId,proto3" json:"payment_method_id,omitempty"`
+	AgentId         string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	CustomerId      string                 `protobuf:"bytes,3,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *InitiateMicroDepositsRequest) Reset() {
+	*x = InitiateMicroDepositsRequest{}
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitiateMicroDepositsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitiateMicroDepositsRequest) ProtoMessage() {}
+
+func (x *InitiateMicroDepositsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitiateMicroDepositsRequest.ProtoReflect.Descriptor instead.
+func (*InitiateMicroDepositsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *InitiateMicroDepositsRequest) GetPaymentMethodId() string {
+	if x != nil {
+		return x.PaymentMethodId
+	}
+	return ""
+}
+
+func (x *InitiateMicroDepositsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *InitiateMicroDepositsRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+// VerifyMicroDepositsRequest contains the amounts the customer saw on their bank statement
+type VerifyMicroDepositsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PaymentMethodId string                 `protobuf:"bytes,1,opt,name=payment_method_id,json=paymentMethodId,proto3" json:"payment_method_id,omitempty"`
+	AgentId         string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	CustomerId      string                 `protobuf:"bytes,3,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	Amounts         []string               `protobuf:"bytes,4,rep,name=amounts,proto3" json:"amounts,omitempty"` // Exactly two dollar amounts, e.g. ["0.32", "0.45"], in any order
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *VerifyMicroDepositsRequest) Reset() {
+	*x = VerifyMicroDepositsRequest{}
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyMicroDepositsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyMicroDepositsRequest) ProtoMessage() {}
+
+func (x *VerifyMicroDepositsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyMicroDepositsRequest.ProtoReflect.Descriptor instead.
+func (*VerifyMicroDepositsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *VerifyMicroDepositsRequest) GetPaymentMethodId() string {
+	if x != nil {
+		return x.PaymentMethodId
+	}
+	return ""
+}
+
+func (x *VerifyMicroDepositsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *VerifyMicroDepositsRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *VerifyMicroDepositsRequest) GetAmounts() []string {
+	if x != nil {
+		return x.Amounts
+	}
+	return nil
+}
+
+// ProcessACHReturnRequest reports an ACH return for a saved ACH payment method
+type ProcessACHReturnRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AgentId         string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	PaymentMethodId string                 `protobuf:"bytes,2,opt,name=payment_method_id,json=paymentMethodId,proto3" json:"payment_method_id,omitempty"`
+	ReturnCode      string                 `protobuf:"bytes,3,opt,name=return_code,json=returnCode,proto3" json:"return_code,omitempty"`                // NACHA return code, e.g. "R01"
+	TransactionId   *string                `protobuf:"bytes,4,opt,name=transaction_id,json=transactionId,proto3,oneof" json:"transaction_id,omitempty"` // Returned debit
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ProcessACHReturnRequest) Reset() {
+	*x = ProcessACHReturnRequest{}
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessACHReturnRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessACHReturnRequest) ProtoMessage() {}
+
+func (x *ProcessACHReturnRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessACHReturnRequest.ProtoReflect.Descriptor instead.
+func (*ProcessACHReturnRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ProcessACHReturnRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ProcessACHReturnRequest) GetPaymentMethodId() string {
+	if x != nil {
+		return x.PaymentMethodId
+	}
+	return ""
+}
+
+func (x *ProcessACHReturnRequest) GetReturnCode() string {
+	if x != nil {
+		return x.ReturnCode
+	}
+	return ""
+}
+
+func (x *ProcessACHReturnRequest) GetTransactionId() string {
+	if x != nil && x.TransactionId != nil {
+		return *x.TransactionId
+	}
+	return ""
+}
+
+// ProcessACHReturnResponse describes how the return was handled
+type ProcessACHReturnResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PaymentMethod *PaymentMethod         `protobuf:"bytes,1,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"`
+	ReturnCode    string                 `protobuf:"bytes,2,opt,name=return_code,json=returnCode,proto3" json:"return_code,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"` // e.g. "Insufficient funds"
+	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`           // "retry" or "deactivate"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessACHReturnResponse) Reset() {
+	*x = ProcessACHReturnResponse{}
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessACHReturnResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessACHReturnResponse) ProtoMessage() {}
+
+func (x *ProcessACHReturnResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessACHReturnResponse.ProtoReflect.Descriptor instead.
+func (*ProcessACHReturnResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ProcessACHReturnResponse) GetPaymentMethod() *PaymentMethod {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return nil
+}
+
+func (x *ProcessACHReturnResponse) GetReturnCode() string {
+	if x != nil {
+		return x.ReturnCode
+	}
+	return ""
+}
+
+func (x *ProcessACHReturnResponse) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ProcessACHReturnResponse) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
 // ConvertFinancialBRICRequest converts a Financial BRIC to Storage BRIC
 type ConvertFinancialBRICRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -765,7 +1163,7 @@ type ConvertFinancialBRICRequest struct {
 
 func (x *ConvertFinancialBRICRequest) Reset() {
 	*x = ConvertFinancialBRICRequest{}
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[10]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -777,7 +1175,7 @@ func (x *ConvertFinancialBRICRequest) String() string {
 func (*ConvertFinancialBRICRequest) ProtoMessage() {}
 
 func (x *ConvertFinancialBRICRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[10]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -790,7 +1188,7 @@ func (x *ConvertFinancialBRICRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConvertFinancialBRICRequest.ProtoReflect.Descriptor instead.
 func (*ConvertFinancialBRICRequest) Descriptor() ([]byte, []int) {
-	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{10}
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *ConvertFinancialBRICRequest) GetAgentId() string {
@@ -926,6 +1324,156 @@ func (x *ConvertFinancialBRICRequest) GetZipCode() string {
 	return ""
 }
 
+// TokenizePaymentMethodRequest tokenizes raw card data (card number and CVV are never stored)
+type TokenizePaymentMethodRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	CustomerId     string                 `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	CardNumber     string                 `protobuf:"bytes,3,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`          // PAN, 13-19 digits
+	CardExpMonth   int32                  `protobuf:"varint,4,opt,name=card_exp_month,json=cardExpMonth,proto3" json:"card_exp_month,omitempty"` // 1-12
+	CardExpYear    int32                  `protobuf:"varint,5,opt,name=card_exp_year,json=cardExpYear,proto3" json:"card_exp_year,omitempty"`    // Four digits, e.g. 2029
+	Cvv            string                 `protobuf:"bytes,6,opt,name=cvv,proto3" json:"cvv,omitempty"`                                          // Optional, 3-4 digits
+	IsDefault      bool                   `protobuf:"varint,7,opt,name=is_default,json=isDefault,proto3" json:"is_default,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,8,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Billing information (address and zip_code required for Account Verification)
+	FirstName     *string `protobuf:"bytes,9,opt,name=first_name,json=firstName,proto3,oneof" json:"first_name,omitempty"`
+	LastName      *string `protobuf:"bytes,10,opt,name=last_name,json=lastName,proto3,oneof" json:"last_name,omitempty"`
+	Address       string  `protobuf:"bytes,11,opt,name=address,proto3" json:"address,omitempty"`
+	City          *string `protobuf:"bytes,12,opt,name=city,proto3,oneof" json:"city,omitempty"`
+	State         *string `protobuf:"bytes,13,opt,name=state,proto3,oneof" json:"state,omitempty"`
+	ZipCode       string  `protobuf:"bytes,14,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenizePaymentMethodRequest) Reset() {
+	*x = TokenizePaymentMethodRequest{}
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenizePaymentMethodRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenizePaymentMethodRequest) ProtoMessage() {}
+
+func (x *TokenizePaymentMethodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenizePaymentMethodRequest.ProtoReflect.Descriptor instead.
+func (*TokenizePaymentMethodRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TokenizePaymentMethodRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *TokenizePaymentMethodRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *TokenizePaymentMethodRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *TokenizePaymentMethodRequest) GetCardExpMonth() int32 {
+	if x != nil {
+		return x.CardExpMonth
+	}
+	return 0
+}
+
+func (x *TokenizePaymentMethodRequest) GetCardExpYear() int32 {
+	if x != nil {
+		return x.CardExpYear
+	}
+	return 0
+}
+
+func (x *TokenizePaymentMethodRequest) GetCvv() string {
+	if x != nil {
+		return x.Cvv
+	}
+	return ""
+}
+
+func (x *TokenizePaymentMethodRequest) GetIsDefault() bool {
+	if x != nil {
+		return x.IsDefault
+	}
+	return false
+}
+
+func (x *TokenizePaymentMethodRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *TokenizePaymentMethodRequest) GetFirstName() string {
+	if x != nil && x.FirstName != nil {
+		return *x.FirstName
+	}
+	return ""
+}
+
+func (x *TokenizePaymentMethodRequest) GetLastName() string {
+	if x != nil && x.LastName != nil {
+		return *x.LastName
+	}
+	return ""
+}
+
+func (x *TokenizePaymentMethodRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *TokenizePaymentMethodRequest) GetCity() string {
+	if x != nil && x.City != nil {
+		return *x.City
+	}
+	return ""
+}
+
+func (x *TokenizePaymentMethodRequest) GetState() string {
+	if x != nil && x.State != nil {
+		return *x.State
+	}
+	return ""
+}
+
+func (x *TokenizePaymentMethodRequest) GetZipCode() string {
+	if x != nil {
+		return x.ZipCode
+	}
+	return ""
+}
+
 // PaymentMethodResponse is returned from payment method operations
 type PaymentMethodResponse struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
@@ -954,7 +1502,7 @@ type PaymentMethodResponse struct {
 
 func (x *PaymentMethodResponse) Reset() {
 	*x = PaymentMethodResponse{}
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[11]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -966,7 +1514,7 @@ func (x *PaymentMethodResponse) String() string {
 func (*PaymentMethodResponse) ProtoMessage() {}
 
 func (x *PaymentMethodResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[11]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -979,7 +1527,7 @@ func (x *PaymentMethodResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PaymentMethodResponse.ProtoReflect.Descriptor instead.
 func (*PaymentMethodResponse) Descriptor() ([]byte, []int) {
-	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{11}
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *PaymentMethodResponse) GetPaymentMethodId() string {
@@ -1104,19 +1652,27 @@ type PaymentMethod struct {
 	BankName    *string `protobuf:"bytes,9,opt,name=bank_name,json=bankName,proto3,oneof" json:"bank_name,omitempty"`
 	AccountType *string `protobuf:"bytes,10,opt,name=account_type,json=accountType,proto3,oneof" json:"account_type,omitempty"`
 	// Status
-	IsDefault     bool                   `protobuf:"varint,11,opt,name=is_default,json=isDefault,proto3" json:"is_default,omitempty"`
-	IsActive      bool                   `protobuf:"varint,12,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	IsVerified    bool                   `protobuf:"varint,13,opt,name=is_verified,json=isVerified,proto3" json:"is_verified,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	LastUsedAt    *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	IsDefault  bool                   `protobuf:"varint,11,opt,name=is_default,json=isDefault,proto3" json:"is_default,omitempty"`
+	IsActive   bool                   `protobuf:"varint,12,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	IsVerified bool                   `protobuf:"varint,13,opt,name=is_verified,json=isVerified,proto3" json:"is_verified,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt  *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	LastUsedAt *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	// ACH returns
+	ReturnCount        int32   `protobuf:"varint,17,opt,name=return_count,json=returnCount,proto3" json:"return_count,omitempty"`
+	LastReturnCode     *string `protobuf:"bytes,18,opt,name=last_return_code,json=lastReturnCode,proto3,oneof" json:"last_return_code,omitempty"`
+	DeactivationReason *string `protobuf:"bytes,19,opt,name=deactivation_reason,json=deactivationReason,proto3,oneof" json:"deactivation_reason,omitempty"` // Set when deactivated automatically
+	// ACH micro-deposit verification
+	MicroDepositsSentAt  *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=micro_deposits_sent_at,json=microDepositsSentAt,proto3" json:"micro_deposits_sent_at,omitempty"`
+	MicroDepositAttempts int32                  `protobuf:"varint,21,opt,name=micro_deposit_attempts,json=microDepositAttempts,proto3" json:"micro_deposit_attempts,omitempty"` // Failed attempts (locked at 3)
+	DataRegion           string                 `protobuf:"bytes,22,opt,name=data_region,json=dataRegion,proto3" json:"data_region,omitempty"`                                  // Merchant data residency region when the payment method was saved
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *PaymentMethod) Reset() {
 	*x = PaymentMethod{}
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[12]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1128,7 +1684,7 @@ func (x *PaymentMethod) String() string {
 func (*PaymentMethod) ProtoMessage() {}
 
 func (x *PaymentMethod) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[12]
+	mi := &file_proto_payment_method_v1_payment_method_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1141,7 +1697,7 @@ func (x *PaymentMethod) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PaymentMethod.ProtoReflect.Descriptor instead.
 func (*PaymentMethod) Descriptor() ([]byte, []int) {
-	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{12}
+	return file_proto_payment_method_v1_payment_method_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *PaymentMethod) GetId() string {
@@ -1256,6 +1812,48 @@ func (x *PaymentMethod) GetLastUsedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *PaymentMethod) GetReturnCount() int32 {
+	if x != nil {
+		return x.ReturnCount
+	}
+	return 0
+}
+
+func (x *PaymentMethod) GetLastReturnCode() string {
+	if x != nil && x.LastReturnCode != nil {
+		return *x.LastReturnCode
+	}
+	return ""
+}
+
+func (x *PaymentMethod) GetDeactivationReason() string {
+	if x != nil && x.DeactivationReason != nil {
+		return *x.DeactivationReason
+	}
+	return ""
+}
+
+func (x *PaymentMethod) GetMicroDepositsSentAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MicroDepositsSentAt
+	}
+	return nil
+}
+
+func (x *PaymentMethod) GetMicroDepositAttempts() int32 {
+	if x != nil {
+		return x.MicroDepositAttempts
+	}
+	return 0
+}
+
+func (x *PaymentMethod) GetDataRegion() string {
+	if x != nil {
+		return x.DataRegion
+	}
+	return ""
+}
+
 var File_proto_payment_method_v1_payment_method_proto protoreflect.FileDescriptor
 
 const file_proto_payment_method_v1_payment_method_proto_rawDesc = "" +
@@ -1296,13 +1894,24 @@ const file_proto_payment_method_v1_payment_method_proto_rawDesc = "" +
 	"\n" +
 	"_is_active\"g\n" +
 	"\x1aListPaymentMethodsResponse\x12I\n" +
-	"\x0fpayment_methods\x18\x01 \x03(\v2 .payment_method.v1.PaymentMethodR\x0epaymentMethods\"\xa7\x01\n" +
+	"\x0fpayment_methods\x18\x01 \x03(\v2 .payment_method.v1.PaymentMethodR\x0epaymentMethods\"_\n" +
+	"!ListExpiringPaymentMethodsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
+	"\vwithin_days\x18\x02 \x01(\x05R\n" +
+	"withinDays\"\xa7\x01\n" +
 	" UpdatePaymentMethodStatusRequest\x12*\n" +
 	"\x11payment_method_id\x18\x01 \x01(\tR\x0fpaymentMethodId\x12\x19\n" +
 	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1f\n" +
 	"\vcustomer_id\x18\x03 \x01(\tR\n" +
 	"customerId\x12\x1b\n" +
-	"\tis_active\x18\x04 \x01(\bR\bisActive\"q\n" +
+	"\tis_active\x18\x04 \x01(\bR\bisActive\"\xd4\x01\n" +
+	" UpdatePaymentMethodExpiryRequest\x12*\n" +
+	"\x11payment_method_id\x18\x01 \x01(\tR\x0fpaymentMethodId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1f\n" +
+	"\vcustomer_id\x18\x03 \x01(\tR\n" +
+	"customerId\x12$\n" +
+	"\x0ecard_exp_month\x18\x04 \x01(\x05R\fcardExpMonth\x12\"\n" +
+	"\rcard_exp_year\x18\x05 \x01(\x05R\vcardExpYear\"q\n" +
 	"\x1aDeletePaymentMethodRequest\x12*\n" +
 	"\x11payment_method_id\x18\x01 \x01(\tR\x0fpaymentMethodId\x12'\n" +
 	"\x0fidempotency_key\x18\x02 \x01(\tR\x0eidempotencyKey\"Q\n" +
@@ -1323,7 +1932,31 @@ const file_proto_payment_method_v1_payment_method_proto_rawDesc = "" +
 	"\x11payment_method_id\x18\x01 \x01(\tR\x0fpaymentMethodId\x12%\n" +
 	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12\x16\n" +
 	"\x06status\x18\x03 \x01(\tR\x06status\x12\x18\n" +
-	"\amessage\x18\x04 \x01(\tR\amessage\"\xec\x06\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"\x86\x01\n" +
+	"\x1cInitiateMicroDepositsRequest\x12*\n" +
+	"\x11payment_method_id\x18\x01 \x01(\tR\x0fpaymentMethodId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1f\n" +
+	"\vcustomer_id\x18\x03 \x01(\tR\n" +
+	"customerId\"\x9e\x01\n" +
+	"\x1aVerifyMicroDepositsRequest\x12*\n" +
+	"\x11payment_method_id\x18\x01 \x01(\tR\x0fpaymentMethodId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1f\n" +
+	"\vcustomer_id\x18\x03 \x01(\tR\n" +
+	"customerId\x12\x18\n" +
+	"\aamounts\x18\x04 \x03(\tR\aamounts\"\xc0\x01\n" +
+	"\x17ProcessACHReturnRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12*\n" +
+	"\x11payment_method_id\x18\x02 \x01(\tR\x0fpaymentMethodId\x12\x1f\n" +
+	"\vreturn_code\x18\x03 \x01(\tR\n" +
+	"returnCode\x12*\n" +
+	"\x0etransaction_id\x18\x04 \x01(\tH\x00R\rtransactionId\x88\x01\x01B\x11\n" +
+	"\x0f_transaction_id\"\xbe\x01\n" +
+	"\x18ProcessACHReturnResponse\x12G\n" +
+	"\x0epayment_method\x18\x01 \x01(\v2 .payment_method.v1.PaymentMethodR\rpaymentMethod\x12\x1f\n" +
+	"\vreturn_code\x18\x02 \x01(\tR\n" +
+	"returnCode\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\"\xec\x06\n" +
 	"\x1bConvertFinancialBRICRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
 	"\vcustomer_id\x18\x02 \x01(\tR\n" +
@@ -1363,7 +1996,32 @@ const file_proto_payment_method_v1_payment_method_proto_rawDesc = "" +
 	"\b_addressB\a\n" +
 	"\x05_cityB\b\n" +
 	"\x06_stateB\v\n" +
-	"\t_zip_code\"\xd0\x05\n" +
+	"\t_zip_code\"\xfe\x03\n" +
+	"\x1cTokenizePaymentMethodRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
+	"\vcustomer_id\x18\x02 \x01(\tR\n" +
+	"customerId\x12\x1f\n" +
+	"\vcard_number\x18\x03 \x01(\tR\n" +
+	"cardNumber\x12$\n" +
+	"\x0ecard_exp_month\x18\x04 \x01(\x05R\fcardExpMonth\x12\"\n" +
+	"\rcard_exp_year\x18\x05 \x01(\x05R\vcardExpYear\x12\x10\n" +
+	"\x03cvv\x18\x06 \x01(\tR\x03cvv\x12\x1d\n" +
+	"\n" +
+	"is_default\x18\a \x01(\bR\tisDefault\x12'\n" +
+	"\x0fidempotency_key\x18\b \x01(\tR\x0eidempotencyKey\x12\"\n" +
+	"\n" +
+	"first_name\x18\t \x01(\tH\x00R\tfirstName\x88\x01\x01\x12 \n" +
+	"\tlast_name\x18\n" +
+	" \x01(\tH\x01R\blastName\x88\x01\x01\x12\x18\n" +
+	"\aaddress\x18\v \x01(\tR\aaddress\x12\x17\n" +
+	"\x04city\x18\f \x01(\tH\x02R\x04city\x88\x01\x01\x12\x19\n" +
+	"\x05state\x18\r \x01(\tH\x03R\x05state\x88\x01\x01\x12\x19\n" +
+	"\bzip_code\x18\x0e \x01(\tR\azipCodeB\r\n" +
+	"\v_first_nameB\f\n" +
+	"\n" +
+	"_last_nameB\a\n" +
+	"\x05_cityB\b\n" +
+	"\x06_state\"\xd0\x05\n" +
 	"\x15PaymentMethodResponse\x12*\n" +
 	"\x11payment_method_id\x18\x01 \x01(\tR\x0fpaymentMethodId\x12\x19\n" +
 	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1f\n" +
@@ -1392,7 +2050,7 @@ const file_proto_payment_method_v1_payment_method_proto_rawDesc = "" +
 	"\x0e_card_exp_yearB\f\n" +
 	"\n" +
 	"_bank_nameB\x0f\n" +
-	"\r_account_type\"\xe7\x05\n" +
+	"\r_account_type\"\xc4\b\n" +
 	"\rPaymentMethod\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
 	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1f\n" +
@@ -1417,26 +2075,41 @@ const file_proto_payment_method_v1_payment_method_proto_rawDesc = "" +
 	"\n" +
 	"updated_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12<\n" +
 	"\flast_used_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
-	"lastUsedAtB\r\n" +
+	"lastUsedAt\x12!\n" +
+	"\freturn_count\x18\x11 \x01(\x05R\vreturnCount\x12-\n" +
+	"\x10last_return_code\x18\x12 \x01(\tH\x05R\x0elastReturnCode\x88\x01\x01\x124\n" +
+	"\x13deactivation_reason\x18\x13 \x01(\tH\x06R\x12deactivationReason\x88\x01\x01\x12O\n" +
+	"\x16micro_deposits_sent_at\x18\x14 \x01(\v2\x1a.google.protobuf.TimestampR\x13microDepositsSentAt\x124\n" +
+	"\x16micro_deposit_attempts\x18\x15 \x01(\x05R\x14microDepositAttempts\x12\x1f\n" +
+	"\vdata_region\x18\x16 \x01(\tR\n" +
+	"dataRegionB\r\n" +
 	"\v_card_brandB\x11\n" +
 	"\x0f_card_exp_monthB\x10\n" +
 	"\x0e_card_exp_yearB\f\n" +
 	"\n" +
 	"_bank_nameB\x0f\n" +
-	"\r_account_type*z\n" +
+	"\r_account_typeB\x13\n" +
+	"\x11_last_return_codeB\x16\n" +
+	"\x14_deactivation_reason*z\n" +
 	"\x11PaymentMethodType\x12#\n" +
 	"\x1fPAYMENT_METHOD_TYPE_UNSPECIFIED\x10\x00\x12#\n" +
 	"\x1fPAYMENT_METHOD_TYPE_CREDIT_CARD\x10\x01\x12\x1b\n" +
-	"\x17PAYMENT_METHOD_TYPE_ACH\x10\x022\xad\a\n" +
+	"\x17PAYMENT_METHOD_TYPE_ACH\x10\x022\xe2\f\n" +
 	"\x14PaymentMethodService\x12j\n" +
 	"\x11SavePaymentMethod\x12+.payment_method.v1.SavePaymentMethodRequest\x1a(.payment_method.v1.PaymentMethodResponse\x12`\n" +
 	"\x10GetPaymentMethod\x12*.payment_method.v1.GetPaymentMethodRequest\x1a .payment_method.v1.PaymentMethod\x12q\n" +
-	"\x12ListPaymentMethods\x12,.payment_method.v1.ListPaymentMethodsRequest\x1a-.payment_method.v1.ListPaymentMethodsResponse\x12z\n" +
-	"\x19UpdatePaymentMethodStatus\x123.payment_method.v1.UpdatePaymentMethodStatusRequest\x1a(.payment_method.v1.PaymentMethodResponse\x12t\n" +
+	"\x12ListPaymentMethods\x12,.payment_method.v1.ListPaymentMethodsRequest\x1a-.payment_method.v1.ListPaymentMethodsResponse\x12\x81\x01\n" +
+	"\x1aListExpiringPaymentMethods\x124.payment_method.v1.ListExpiringPaymentMethodsRequest\x1a-.payment_method.v1.ListPaymentMethodsResponse\x12z\n" +
+	"\x19UpdatePaymentMethodStatus\x123.payment_method.v1.UpdatePaymentMethodStatusRequest\x1a(.payment_method.v1.PaymentMethodResponse\x12z\n" +
+	"\x19UpdatePaymentMethodExpiry\x123.payment_method.v1.UpdatePaymentMethodExpiryRequest\x1a(.payment_method.v1.PaymentMethodResponse\x12t\n" +
 	"\x13DeletePaymentMethod\x12-.payment_method.v1.DeletePaymentMethodRequest\x1a..payment_method.v1.DeletePaymentMethodResponse\x12v\n" +
 	"\x17SetDefaultPaymentMethod\x121.payment_method.v1.SetDefaultPaymentMethodRequest\x1a(.payment_method.v1.PaymentMethodResponse\x12k\n" +
 	"\x10VerifyACHAccount\x12*.payment_method.v1.VerifyACHAccountRequest\x1a+.payment_method.v1.VerifyACHAccountResponse\x12}\n" +
-	"!ConvertFinancialBRICToStorageBRIC\x12..payment_method.v1.ConvertFinancialBRICRequest\x1a(.payment_method.v1.PaymentMethodResponseBOZMgithub.com/kevin07696/payment-service/proto/payment_method/v1;paymentmethodv1b\x06proto3"
+	"!ConvertFinancialBRICToStorageBRIC\x12..payment_method.v1.ConvertFinancialBRICRequest\x1a(.payment_method.v1.PaymentMethodResponse\x12r\n" +
+	"\x15TokenizePaymentMethod\x12/.payment_method.v1.TokenizePaymentMethodRequest\x1a(.payment_method.v1.PaymentMethodResponse\x12k\n" +
+	"\x10ProcessACHReturn\x12*.payment_method.v1.ProcessACHReturnRequest\x1a+.payment_method.v1.ProcessACHReturnResponse\x12j\n" +
+	"\x15InitiateMicroDeposits\x12/.payment_method.v1.InitiateMicroDepositsRequest\x1a .payment_method.v1.PaymentMethod\x12f\n" +
+	"\x13VerifyMicroDeposits\x12-.payment_method.v1.VerifyMicroDepositsRequest\x1a .payment_method.v1.PaymentMethodBOZMgithub.com/kevin07696/payment-service/proto/payment_method/v1;paymentmethodv1b\x06proto3"
 
 var (
 	file_proto_payment_method_v1_payment_method_proto_rawDescOnce sync.Once
@@ -1451,57 +2124,78 @@ func file_proto_payment_method_v1_payment_method_proto_rawDescGZIP() []byte {
 }
 
 var file_proto_payment_method_v1_payment_method_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_payment_method_v1_payment_method_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_proto_payment_method_v1_payment_method_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_proto_payment_method_v1_payment_method_proto_goTypes = []any{
-	(PaymentMethodType)(0),                   // 0: payment_method.v1.PaymentMethodType
-	(*SavePaymentMethodRequest)(nil),         // 1: payment_method.v1.SavePaymentMethodRequest
-	(*GetPaymentMethodRequest)(nil),          // 2: payment_method.v1.GetPaymentMethodRequest
-	(*ListPaymentMethodsRequest)(nil),        // 3: payment_method.v1.ListPaymentMethodsRequest
-	(*ListPaymentMethodsResponse)(nil),       // 4: payment_method.v1.ListPaymentMethodsResponse
-	(*UpdatePaymentMethodStatusRequest)(nil), // 5: payment_method.v1.UpdatePaymentMethodStatusRequest
-	(*DeletePaymentMethodRequest)(nil),       // 6: payment_method.v1.DeletePaymentMethodRequest
-	(*DeletePaymentMethodResponse)(nil),      // 7: payment_method.v1.DeletePaymentMethodResponse
-	(*SetDefaultPaymentMethodRequest)(nil),   // 8: payment_method.v1.SetDefaultPaymentMethodRequest
-	(*VerifyACHAccountRequest)(nil),          // 9: payment_method.v1.VerifyACHAccountRequest
-	(*VerifyACHAccountResponse)(nil),         // 10: payment_method.v1.VerifyACHAccountResponse
-	(*ConvertFinancialBRICRequest)(nil),      // 11: payment_method.v1.ConvertFinancialBRICRequest
-	(*PaymentMethodResponse)(nil),            // 12: payment_method.v1.PaymentMethodResponse
-	(*PaymentMethod)(nil),                    // 13: payment_method.v1.PaymentMethod
-	(*timestamppb.Timestamp)(nil),            // 14: google.protobuf.Timestamp
+	(PaymentMethodType)(0),                    // 0: payment_method.v1.PaymentMethodType
+	(*SavePaymentMethodRequest)(nil),          // 1: payment_method.v1.SavePaymentMethodRequest
+	(*GetPaymentMethodRequest)(nil),           // 2: payment_method.v1.GetPaymentMethodRequest
+	(*ListPaymentMethodsRequest)(nil),         // 3: payment_method.v1.ListPaymentMethodsRequest
+	(*ListPaymentMethodsResponse)(nil),        // 4: payment_method.v1.ListPaymentMethodsResponse
+	(*ListExpiringPaymentMethodsRequest)(nil), // 5: payment_method.v1.ListExpiringPaymentMethodsRequest
+	(*UpdatePaymentMethodStatusRequest)(nil),  // 6: payment_method.v1.UpdatePaymentMethodStatusRequest
+	(*UpdatePaymentMethodExpiryRequest)(nil),  // 7: payment_method.v1.UpdatePaymentMethodExpiryRequest
+	(*DeletePaymentMethodRequest)(nil),        // 8: payment_method.v1.DeletePaymentMethodRequest
+	(*DeletePaymentMethodResponse)(nil),       // 9: payment_method.v1.DeletePaymentMethodResponse
+	(*SetDefaultPaymentMethodRequest)(nil),    // 10: payment_method.v1.SetDefaultPaymentMethodRequest
+	(*VerifyACHAccountRequest)(nil),           // 11: payment_method.v1.VerifyACHAccountRequest
+	(*VerifyACHAccountResponse)(nil),          // 12: payment_method.v1.VerifyACHAccountResponse
+	(*InitiateMicroDepositsRequest)(nil),      // 13: payment_method.v1.InitiateMicroDepositsRequest
+	(*VerifyMicroDepositsRequest)(nil),        // 14: payment_method.v1.VerifyMicroDepositsRequest
+	(*ProcessACHReturnRequest)(nil),           // 15: payment_method.v1.ProcessACHReturnRequest
+	(*ProcessACHReturnResponse)(nil),          // 16: payment_method.v1.ProcessACHReturnResponse
+	(*ConvertFinancialBRICRequest)(nil),       // 17: payment_method.v1.ConvertFinancialBRICRequest
+	(*TokenizePaymentMethodRequest)(nil),      // 18: payment_method.v1.TokenizePaymentMethodRequest
+	(*PaymentMethodResponse)(nil),             // 19: payment_method.v1.PaymentMethodResponse
+	(*PaymentMethod)(nil),                     // 20: payment_method.v1.PaymentMethod
+	(*timestamppb.Timestamp)(nil),             // 21: google.protobuf.Timestamp
 }
 var file_proto_payment_method_v1_payment_method_proto_depIdxs = []int32{
 	0,  // 0: payment_method.v1.SavePaymentMethodRequest.payment_type:type_name -> payment_method.v1.PaymentMethodType
 	0,  // 1: payment_method.v1.ListPaymentMethodsRequest.payment_type:type_name -> payment_method.v1.PaymentMethodType
-	13, // 2: payment_method.v1.ListPaymentMethodsResponse.payment_methods:type_name -> payment_method.v1.PaymentMethod
-	0,  // 3: payment_method.v1.ConvertFinancialBRICRequest.payment_type:type_name -> payment_method.v1.PaymentMethodType
-	0,  // 4: payment_method.v1.PaymentMethodResponse.payment_type:type_name -> payment_method.v1.PaymentMethodType
-	14, // 5: payment_method.v1.PaymentMethodResponse.created_at:type_name -> google.protobuf.Timestamp
-	14, // 6: payment_method.v1.PaymentMethodResponse.last_used_at:type_name -> google.protobuf.Timestamp
-	0,  // 7: payment_method.v1.PaymentMethod.payment_type:type_name -> payment_method.v1.PaymentMethodType
-	14, // 8: payment_method.v1.PaymentMethod.created_at:type_name -> google.protobuf.Timestamp
-	14, // 9: payment_method.v1.PaymentMethod.updated_at:type_name -> google.protobuf.Timestamp
-	14, // 10: payment_method.v1.PaymentMethod.last_used_at:type_name -> google.protobuf.Timestamp
-	1,  // 11: payment_method.v1.PaymentMethodService.SavePaymentMethod:input_type -> payment_method.v1.SavePaymentMethodRequest
-	2,  // 12: payment_method.v1.PaymentMethodService.GetPaymentMethod:input_type -> payment_method.v1.GetPaymentMethodRequest
-	3,  // 13: payment_method.v1.PaymentMethodService.ListPaymentMethods:input_type -> payment_method.v1.ListPaymentMethodsRequest
-	5,  // 14: payment_method.v1.PaymentMethodService.UpdatePaymentMethodStatus:input_type -> payment_method.v1.UpdatePaymentMethodStatusRequest
-	6,  // 15: payment_method.v1.PaymentMethodService.DeletePaymentMethod:input_type -> payment_method.v1.DeletePaymentMethodRequest
-	8,  // 16: payment_method.v1.PaymentMethodService.SetDefaultPaymentMethod:input_type -> payment_method.v1.SetDefaultPaymentMethodRequest
-	9,  // 17: payment_method.v1.PaymentMethodService.VerifyACHAccount:input_type -> payment_method.v1.VerifyACHAccountRequest
-	11, // 18: payment_method.v1.PaymentMethodService.ConvertFinancialBRICToStorageBRIC:input_type -> payment_method.v1.ConvertFinancialBRICRequest
-	12, // 19: payment_method.v1.PaymentMethodService.SavePaymentMethod:output_type -> payment_method.v1.PaymentMethodResponse
-	13, // 20: payment_method.v1.PaymentMethodService.GetPaymentMethod:output_type -> payment_method.v1.PaymentMethod
-	4,  // 21: payment_method.v1.PaymentMethodService.ListPaymentMethods:output_type -> payment_method.v1.ListPaymentMethodsResponse
-	12, // 22: payment_method.v1.PaymentMethodService.UpdatePaymentMethodStatus:output_type -> payment_method.v1.PaymentMethodResponse
-	7,  // 23: payment_method.v1.PaymentMethodService.DeletePaymentMethod:output_type -> payment_method.v1.DeletePaymentMethodResponse
-	12, // 24: payment_method.v1.PaymentMethodService.SetDefaultPaymentMethod:output_type -> payment_method.v1.PaymentMethodResponse
-	10, // 25: payment_method.v1.PaymentMethodService.VerifyACHAccount:output_type -> payment_method.v1.VerifyACHAccountResponse
-	12, // 26: payment_method.v1.PaymentMethodService.ConvertFinancialBRICToStorageBRIC:output_type -> payment_method.v1.PaymentMethodResponse
-	19, // [19:27] is the sub-list for method output_type
-	11, // [11:19] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	20, // 2: payment_method.v1.ListPaymentMethodsResponse.payment_methods:type_name -> payment_method.v1.PaymentMethod
+	20, // 3: payment_method.v1.ProcessACHReturnResponse.payment_method:type_name -> payment_method.v1.PaymentMethod
+	0,  // 4: payment_method.v1.ConvertFinancialBRICRequest.payment_type:type_name -> payment_method.v1.PaymentMethodType
+	0,  // 5: payment_method.v1.PaymentMethodResponse.payment_type:type_name -> payment_method.v1.PaymentMethodType
+	21, // 6: payment_method.v1.PaymentMethodResponse.created_at:type_name -> google.protobuf.Timestamp
+	21, // 7: payment_method.v1.PaymentMethodResponse.last_used_at:type_name -> google.protobuf.Timestamp
+	0,  // 8: payment_method.v1.PaymentMethod.payment_type:type_name -> payment_method.v1.PaymentMethodType
+	21, // 9: payment_method.v1.PaymentMethod.created_at:type_name -> google.protobuf.Timestamp
+	21, // 10: payment_method.v1.PaymentMethod.updated_at:type_name -> google.protobuf.Timestamp
+	21, // 11: payment_method.v1.PaymentMethod.last_used_at:type_name -> google.protobuf.Timestamp
+	21, // 12: payment_method.v1.PaymentMethod.micro_deposits_sent_at:type_name -> google.protobuf.Timestamp
+	1,  // 13: payment_method.v1.PaymentMethodService.SavePaymentMethod:input_type -> payment_method.v1.SavePaymentMethodRequest
+	2,  // 14: payment_method.v1.PaymentMethodService.GetPaymentMethod:input_type -> payment_method.v1.GetPaymentMethodRequest
+	3,  // 15: payment_method.v1.PaymentMethodService.ListPaymentMethods:input_type -> payment_method.v1.ListPaymentMethodsRequest
+	5,  // 16: payment_method.v1.PaymentMethodService.ListExpiringPaymentMethods:input_type -> payment_method.v1.ListExpiringPaymentMethodsRequest
+	6,  // 17: payment_method.v1.PaymentMethodService.UpdatePaymentMethodStatus:input_type -> payment_method.v1.UpdatePaymentMethodStatusRequest
+	7,  // 18: payment_method.v1.PaymentMethodService.UpdatePaymentMethodExpiry:input_type -> payment_method.v1.UpdatePaymentMethodExpiryRequest
+	8,  // 19: payment_method.v1.PaymentMethodService.DeletePaymentMethod:input_type -> payment_method.v1.DeletePaymentMethodRequest
+	10, // 20: payment_method.v1.PaymentMethodService.SetDefaultPaymentMethod:input_type -> payment_method.v1.SetDefaultPaymentMethodRequest
+	11, // 21: payment_method.v1.PaymentMethodService.VerifyACHAccount:input_type -> payment_method.v1.VerifyACHAccountRequest
+	17, // 22: payment_method.v1.PaymentMethodService.ConvertFinancialBRICToStorageBRIC:input_type -> payment_method.v1.ConvertFinancialBRICRequest
+	18, // 23: payment_method.v1.PaymentMethodService.TokenizePaymentMethod:input_type -> payment_method.v1.TokenizePaymentMethodRequest
+	15, // 24: payment_method.v1.PaymentMethodService.ProcessACHReturn:input_type -> payment_method.v1.ProcessACHReturnRequest
+	13, // 25: payment_method.v1.PaymentMethodService.InitiateMicroDeposits:input_type -> payment_method.v1.InitiateMicroDepositsRequest
+	14, // 26: payment_method.v1.PaymentMethodService.VerifyMicroDeposits:input_type -> payment_method.v1.VerifyMicroDepositsRequest
+	19, // 27: payment_method.v1.PaymentMethodService.SavePaymentMethod:output_type -> payment_method.v1.PaymentMethodResponse
+	20, // 28: payment_method.v1.PaymentMethodService.GetPaymentMethod:output_type -> payment_method.v1.PaymentMethod
+	4,  // 29: payment_method.v1.PaymentMethodService.ListPaymentMethods:output_type -> payment_method.v1.ListPaymentMethodsResponse
+	4,  // 30: payment_method.v1.PaymentMethodService.ListExpiringPaymentMethods:output_type -> payment_method.v1.ListPaymentMethodsResponse
+	19, // 31: payment_method.v1.PaymentMethodService.UpdatePaymentMethodStatus:output_type -> payment_method.v1.PaymentMethodResponse
+	19, // 32: payment_method.v1.PaymentMethodService.UpdatePaymentMethodExpiry:output_type -> payment_method.v1.PaymentMethodResponse
+	9,  // 33: payment_method.v1.PaymentMethodService.DeletePaymentMethod:output_type -> payment_method.v1.DeletePaymentMethodResponse
+	19, // 34: payment_method.v1.PaymentMethodService.SetDefaultPaymentMethod:output_type -> payment_method.v1.PaymentMethodResponse
+	12, // 35: payment_method.v1.PaymentMethodService.VerifyACHAccount:output_type -> payment_method.v1.VerifyACHAccountResponse
+	19, // 36: payment_method.v1.PaymentMethodService.ConvertFinancialBRICToStorageBRIC:output_type -> payment_method.v1.PaymentMethodResponse
+	19, // 37: payment_method.v1.PaymentMethodService.TokenizePaymentMethod:output_type -> payment_method.v1.PaymentMethodResponse
+	16, // 38: payment_method.v1.PaymentMethodService.ProcessACHReturn:output_type -> payment_method.v1.ProcessACHReturnResponse
+	20, // 39: payment_method.v1.PaymentMethodService.InitiateMicroDeposits:output_type -> payment_method.v1.PaymentMethod
+	20, // 40: payment_method.v1.PaymentMethodService.VerifyMicroDeposits:output_type -> payment_method.v1.PaymentMethod
+	27, // [27:41] is the sub-list for method output_type
+	13, // [13:27] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_proto_payment_method_v1_payment_method_proto_init() }
@@ -1511,16 +2205,18 @@ func file_proto_payment_method_v1_payment_method_proto_init() {
 	}
 	file_proto_payment_method_v1_payment_method_proto_msgTypes[0].OneofWrappers = []any{}
 	file_proto_payment_method_v1_payment_method_proto_msgTypes[2].OneofWrappers = []any{}
-	file_proto_payment_method_v1_payment_method_proto_msgTypes[10].OneofWrappers = []any{}
-	file_proto_payment_method_v1_payment_method_proto_msgTypes[11].OneofWrappers = []any{}
-	file_proto_payment_method_v1_payment_method_proto_msgTypes[12].OneofWrappers = []any{}
+	file_proto_payment_method_v1_payment_method_proto_msgTypes[14].OneofWrappers = []any{}
+	file_proto_payment_method_v1_payment_method_proto_msgTypes[16].OneofWrappers = []any{}
+	file_proto_payment_method_v1_payment_method_proto_msgTypes[17].OneofWrappers = []any{}
+	file_proto_payment_method_v1_payment_method_proto_msgTypes[18].OneofWrappers = []any{}
+	file_proto_payment_method_v1_payment_method_proto_msgTypes[19].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_payment_method_v1_payment_method_proto_rawDesc), len(file_proto_payment_method_v1_payment_method_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   13,
+			NumMessages:   20,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
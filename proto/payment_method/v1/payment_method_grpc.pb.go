@@ -22,11 +22,17 @@ const (
 	PaymentMethodService_SavePaymentMethod_FullMethodName                 = "/payment_method.v1.PaymentMethodService/SavePaymentMethod"
 	PaymentMethodService_GetPaymentMethod_FullMethodName                  = "/payment_method.v1.PaymentMethodService/GetPaymentMethod"
 	PaymentMethodService_ListPaymentMethods_FullMethodName                = "/payment_method.v1.PaymentMethodService/ListPaymentMethods"
+	PaymentMethodService_ListExpiringPaymentMethods_FullMethodName        = "/payment_method.v1.PaymentMethodService/ListExpiringPaymentMethods"
 	PaymentMethodService_UpdatePaymentMethodStatus_FullMethodName         = "/payment_method.v1.PaymentMethodService/UpdatePaymentMethodStatus"
+	PaymentMethodService_UpdatePaymentMethodExpiry_FullMethodName         = "/payment_method.v1.PaymentMethodService/UpdatePaymentMethodExpiry"
 	PaymentMethodService_DeletePaymentMethod_FullMethodName               = "/payment_method.v1.PaymentMethodService/DeletePaymentMethod"
 	PaymentMethodService_SetDefaultPaymentMethod_FullMethodName           = "/payment_method.v1.PaymentMethodService/SetDefaultPaymentMethod"
 	PaymentMethodService_VerifyACHAccount_FullMethodName                  = "/payment_method.v1.PaymentMethodService/VerifyACHAccount"
 	PaymentMethodService_ConvertFinancialBRICToStorageBRIC_FullMethodName = "/payment_method.v1.PaymentMethodService/ConvertFinancialBRICToStorageBRIC"
+	PaymentMethodService_TokenizePaymentMethod_FullMethodName             = "/payment_method.v1.PaymentMethodService/TokenizePaymentMethod"
+	PaymentMethodService_ProcessACHReturn_FullMethodName                  = "/payment_method.v1.PaymentMethodService/ProcessACHReturn"
+	PaymentMethodService_InitiateMicroDeposits_FullMethodName             = "/payment_method.v1.PaymentMethodService/InitiateMicroDeposits"
+	PaymentMethodService_VerifyMicroDeposits_FullMethodName               = "/payment_method.v1.PaymentMethodService/VerifyMicroDeposits"
 )
 
 // PaymentMethodServiceClient is the client API for PaymentMethodService service.
@@ -41,8 +47,13 @@ type PaymentMethodServiceClient interface {
 	GetPaymentMethod(ctx context.Context, in *GetPaymentMethodRequest, opts ...grpc.CallOption) (*PaymentMethod, error)
 	// ListPaymentMethods lists all payment methods for a customer
 	ListPaymentMethods(ctx context.Context, in *ListPaymentMethodsRequest, opts ...grpc.CallOption) (*ListPaymentMethodsResponse, error)
+	// ListExpiringPaymentMethods lists a merchant's active cards expiring within N days
+	// Use it to prompt customers to update cards before a subscription charge fails
+	ListExpiringPaymentMethods(ctx context.Context, in *ListExpiringPaymentMethodsRequest, opts ...grpc.CallOption) (*ListPaymentMethodsResponse, error)
 	// UpdatePaymentMethodStatus updates the active status of a payment method
 	UpdatePaymentMethodStatus(ctx context.Context, in *UpdatePaymentMethodStatusRequest, opts ...grpc.CallOption) (*PaymentMethodResponse, error)
+	// UpdatePaymentMethodExpiry updates a reissued card's expiration date (same number/BRIC, no re-tokenization)
+	UpdatePaymentMethodExpiry(ctx context.Context, in *UpdatePaymentMethodExpiryRequest, opts ...grpc.CallOption) (*PaymentMethodResponse, error)
 	// DeletePaymentMethod soft deletes a payment method (sets deleted_at)
 	DeletePaymentMethod(ctx context.Context, in *DeletePaymentMethodRequest, opts ...grpc.CallOption) (*DeletePaymentMethodResponse, error)
 	// SetDefaultPaymentMethod marks a payment method as default
@@ -52,6 +63,17 @@ type PaymentMethodServiceClient interface {
 	// ConvertFinancialBRICToStorageBRIC converts Financial BRIC to Storage BRIC and saves payment method
 	// Use case: Customer completes payment and wants to save their payment method
 	ConvertFinancialBRICToStorageBRIC(ctx context.Context, in *ConvertFinancialBRICRequest, opts ...grpc.CallOption) (*PaymentMethodResponse, error)
+	// TokenizePaymentMethod exchanges card data for a Storage BRIC over Server Post and saves it, without charging
+	// Use case: merchants with their own PCI scope collecting cards server-side instead of via Browser Post
+	TokenizePaymentMethod(ctx context.Context, in *TokenizePaymentMethodRequest, opts ...grpc.CallOption) (*PaymentMethodResponse, error)
+	// ProcessACHReturn records an ACH return (R-code) reported by EPX
+	// Unrecoverable returns (R02 account closed, R03 no account, R04 invalid account, ...) deactivate the payment method
+	ProcessACHReturn(ctx context.Context, in *ProcessACHReturnRequest, opts ...grpc.CallOption) (*ProcessACHReturnResponse, error)
+	// InitiateMicroDeposits sends two small ACH credits ($0.01-$0.99) to verify the account
+	InitiateMicroDeposits(ctx context.Context, in *InitiateMicroDepositsRequest, opts ...grpc.CallOption) (*PaymentMethod, error)
+	// VerifyMicroDeposits confirms the deposit amounts and marks the ACH payment method verified
+	// Locks after 3 failed attempts
+	VerifyMicroDeposits(ctx context.Context, in *VerifyMicroDepositsRequest, opts ...grpc.CallOption) (*PaymentMethod, error)
 }
 
 type paymentMethodServiceClient struct {
@@ -92,6 +114,16 @@ func (c *paymentMethodServiceClient) ListPaymentMethods(ctx context.Context, in
 	return out, nil
 }
 
+func (c *paymentMethodServiceClient) ListExpiringPaymentMethods(ctx context.Context, in *ListExpiringPaymentMethodsRequest, opts ...grpc.CallOption) (*ListPaymentMethodsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPaymentMethodsResponse)
+	err := c.cc.Invoke(ctx, PaymentMethodService_ListExpiringPaymentMethods_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *paymentMethodServiceClient) UpdatePaymentMethodStatus(ctx context.Context, in *UpdatePaymentMethodStatusRequest, opts ...grpc.CallOption) (*PaymentMethodResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PaymentMethodResponse)
@@ -102,6 +134,16 @@ func (c *paymentMethodServiceClient) UpdatePaymentMethodStatus(ctx context.Conte
 	return out, nil
 }
 
+func (c *paymentMethodServiceClient) UpdatePaymentMethodExpiry(ctx context.Context, in *UpdatePaymentMethodExpiryRequest, opts ...grpc.CallOption) (*PaymentMethodResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentMethodResponse)
+	err := c.cc.Invoke(ctx, PaymentMethodService_UpdatePaymentMethodExpiry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *paymentMethodServiceClient) DeletePaymentMethod(ctx context.Context, in *DeletePaymentMethodRequest, opts ...grpc.CallOption) (*DeletePaymentMethodResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DeletePaymentMethodResponse)
@@ -142,6 +184,46 @@ func (c *paymentMethodServiceClient) ConvertFinancialBRICToStorageBRIC(ctx conte
 	return out, nil
 }
 
+func (c *paymentMethodServiceClient) TokenizePaymentMethod(ctx context.Context, in *TokenizePaymentMethodRequest, opts ...grpc.CallOption) (*PaymentMethodResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentMethodResponse)
+	err := c.cc.Invoke(ctx, PaymentMethodService_TokenizePaymentMethod_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentMethodServiceClient) ProcessACHReturn(ctx context.Context, in *ProcessACHReturnRequest, opts ...grpc.CallOption) (*ProcessACHReturnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProcessACHReturnResponse)
+	err := c.cc.Invoke(ctx, PaymentMethodService_ProcessACHReturn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentMethodServiceClient) InitiateMicroDeposits(ctx context.Context, in *InitiateMicroDepositsRequest, opts ...grpc.CallOption) (*PaymentMethod, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentMethod)
+	err := c.cc.Invoke(ctx, PaymentMethodService_InitiateMicroDeposits_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentMethodServiceClient) VerifyMicroDeposits(ctx context.Context, in *VerifyMicroDepositsRequest, opts ...grpc.CallOption) (*PaymentMethod, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PaymentMethod)
+	err := c.cc.Invoke(ctx, PaymentMethodService_VerifyMicroDeposits_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PaymentMethodServiceServer is the server API for PaymentMethodService service.
 // All implementations must embed UnimplementedPaymentMethodServiceServer
 // for forward compatibility.
@@ -154,8 +236,13 @@ type PaymentMethodServiceServer interface {
 	GetPaymentMethod(context.Context, *GetPaymentMethodRequest) (*PaymentMethod, error)
 	// ListPaymentMethods lists all payment methods for a customer
 	ListPaymentMethods(context.Context, *ListPaymentMethodsRequest) (*ListPaymentMethodsResponse, error)
+	// ListExpiringPaymentMethods lists a merchant's active cards expiring within N days
+	// Use it to prompt customers to update cards before a subscription charge fails
+	ListExpiringPaymentMethods(context.Context, *ListExpiringPaymentMethodsRequest) (*ListPaymentMethodsResponse, error)
 	// UpdatePaymentMethodStatus updates the active status of a payment method
 	UpdatePaymentMethodStatus(context.Context, *UpdatePaymentMethodStatusRequest) (*PaymentMethodResponse, error)
+	// UpdatePaymentMethodExpiry updates a reissued card's expiration date (same number/BRIC, no re-tokenization)
+	UpdatePaymentMethodExpiry(context.Context, *UpdatePaymentMethodExpiryRequest) (*PaymentMethodResponse, error)
 	// DeletePaymentMethod soft deletes a payment method (sets deleted_at)
 	DeletePaymentMethod(context.Context, *DeletePaymentMethodRequest) (*DeletePaymentMethodResponse, error)
 	// SetDefaultPaymentMethod marks a payment method as default
@@ -165,6 +252,17 @@ type PaymentMethodServiceServer interface {
 	// ConvertFinancialBRICToStorageBRIC converts Financial BRIC to Storage BRIC and saves payment method
 	// Use case: Customer completes payment and wants to save their payment method
 	ConvertFinancialBRICToStorageBRIC(context.Context, *ConvertFinancialBRICRequest) (*PaymentMethodResponse, error)
+	// TokenizePaymentMethod exchanges card data for a Storage BRIC over Server Post and saves it, without charging
+	// Use case: merchants with their own PCI scope collecting cards server-side instead of via Browser Post
+	TokenizePaymentMethod(context.Context, *TokenizePaymentMethodRequest) (*PaymentMethodResponse, error)
+	// ProcessACHReturn records an ACH return (R-code) reported by EPX
+	// Unrecoverable returns (R02 account closed, R03 no account, R04 invalid account, ...) deactivate the payment method
+	ProcessACHReturn(context.Context, *ProcessACHReturnRequest) (*ProcessACHReturnResponse, error)
+	// InitiateMicroDeposits sends two small ACH credits ($0.01-$0.99) to verify the account
+	InitiateMicroDeposits(context.Context, *InitiateMicroDepositsRequest) (*PaymentMethod, error)
+	// VerifyMicroDeposits confirms the deposit amounts and marks the ACH payment method verified
+	// Locks after 3 failed attempts
+	VerifyMicroDeposits(context.Context, *VerifyMicroDepositsRequest) (*PaymentMethod, error)
 	mustEmbedUnimplementedPaymentMethodServiceServer()
 }
 
@@ -184,9 +282,15 @@ func (UnimplementedPaymentMethodServiceServer) GetPaymentMethod(context.Context,
 func (UnimplementedPaymentMethodServiceServer) ListPaymentMethods(context.Context, *ListPaymentMethodsRequest) (*ListPaymentMethodsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListPaymentMethods not implemented")
 }
+func (UnimplementedPaymentMethodServiceServer) ListExpiringPaymentMethods(context.Context, *ListExpiringPaymentMethodsRequest) (*ListPaymentMethodsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListExpiringPaymentMethods not implemented")
+}
 func (UnimplementedPaymentMethodServiceServer) UpdatePaymentMethodStatus(context.Context, *UpdatePaymentMethodStatusRequest) (*PaymentMethodResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdatePaymentMethodStatus not implemented")
 }
+func (UnimplementedPaymentMethodServiceServer) UpdatePaymentMethodExpiry(context.Context, *UpdatePaymentMethodExpiryRequest) (*PaymentMethodResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePaymentMethodExpiry not implemented")
+}
 func (UnimplementedPaymentMethodServiceServer) DeletePaymentMethod(context.Context, *DeletePaymentMethodRequest) (*DeletePaymentMethodResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeletePaymentMethod not implemented")
 }
@@ -199,6 +303,18 @@ func (UnimplementedPaymentMethodServiceServer) VerifyACHAccount(context.Context,
 func (UnimplementedPaymentMethodServiceServer) ConvertFinancialBRICToStorageBRIC(context.Context, *ConvertFinancialBRICRequest) (*PaymentMethodResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ConvertFinancialBRICToStorageBRIC not implemented")
 }
+func (UnimplementedPaymentMethodServiceServer) TokenizePaymentMethod(context.Context, *TokenizePaymentMethodRequest) (*PaymentMethodResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TokenizePaymentMethod not implemented")
+}
+func (UnimplementedPaymentMethodServiceServer) ProcessACHReturn(context.Context, *ProcessACHReturnRequest) (*ProcessACHReturnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessACHReturn not implemented")
+}
+func (UnimplementedPaymentMethodServiceServer) InitiateMicroDeposits(context.Context, *InitiateMicroDepositsRequest) (*PaymentMethod, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InitiateMicroDeposits not implemented")
+}
+func (UnimplementedPaymentMethodServiceServer) VerifyMicroDeposits(context.Context, *VerifyMicroDepositsRequest) (*PaymentMethod, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyMicroDeposits not implemented")
+}
 func (UnimplementedPaymentMethodServiceServer) mustEmbedUnimplementedPaymentMethodServiceServer() {}
 func (UnimplementedPaymentMethodServiceServer) testEmbeddedByValue()                              {}
 
@@ -274,6 +390,24 @@ func _PaymentMethodService_ListPaymentMethods_Handler(srv interface{}, ctx conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentMethodService_ListExpiringPaymentMethods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListExpiringPaymentMethodsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentMethodServiceServer).ListExpiringPaymentMethods(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentMethodService_ListExpiringPaymentMethods_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentMethodServiceServer).ListExpiringPaymentMethods(ctx, req.(*ListExpiringPaymentMethodsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PaymentMethodService_UpdatePaymentMethodStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdatePaymentMethodStatusRequest)
 	if err := dec(in); err != nil {
@@ -292,6 +426,24 @@ func _PaymentMethodService_UpdatePaymentMethodStatus_Handler(srv interface{}, ct
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentMethodService_UpdatePaymentMethodExpiry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePaymentMethodExpiryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentMethodServiceServer).UpdatePaymentMethodExpiry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentMethodService_UpdatePaymentMethodExpiry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentMethodServiceServer).UpdatePaymentMethodExpiry(ctx, req.(*UpdatePaymentMethodExpiryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PaymentMethodService_DeletePaymentMethod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeletePaymentMethodRequest)
 	if err := dec(in); err != nil {
@@ -364,6 +516,78 @@ func _PaymentMethodService_ConvertFinancialBRICToStorageBRIC_Handler(srv interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentMethodService_TokenizePaymentMethod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TokenizePaymentMethodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentMethodServiceServer).TokenizePaymentMethod(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentMethodService_TokenizePaymentMethod_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentMethodServiceServer).TokenizePaymentMethod(ctx, req.(*TokenizePaymentMethodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentMethodService_ProcessACHReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessACHReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentMethodServiceServer).ProcessACHReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentMethodService_ProcessACHReturn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentMethodServiceServer).ProcessACHReturn(ctx, req.(*ProcessACHReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentMethodService_InitiateMicroDeposits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InitiateMicroDepositsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentMethodServiceServer).InitiateMicroDeposits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentMethodService_InitiateMicroDeposits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentMethodServiceServer).InitiateMicroDeposits(ctx, req.(*InitiateMicroDepositsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentMethodService_VerifyMicroDeposits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyMicroDepositsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentMethodServiceServer).VerifyMicroDeposits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentMethodService_VerifyMicroDeposits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentMethodServiceServer).VerifyMicroDeposits(ctx, req.(*VerifyMicroDepositsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PaymentMethodService_ServiceDesc is the grpc.ServiceDesc for PaymentMethodService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -383,10 +607,18 @@ var PaymentMethodService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListPaymentMethods",
 			Handler:    _PaymentMethodService_ListPaymentMethods_Handler,
 		},
+		{
+			MethodName: "ListExpiringPaymentMethods",
+			Handler:    _PaymentMethodService_ListExpiringPaymentMethods_Handler,
+		},
 		{
 			MethodName: "UpdatePaymentMethodStatus",
 			Handler:    _PaymentMethodService_UpdatePaymentMethodStatus_Handler,
 		},
+		{
+			MethodName: "UpdatePaymentMethodExpiry",
+			Handler:    _PaymentMethodService_UpdatePaymentMethodExpiry_Handler,
+		},
 		{
 			MethodName: "DeletePaymentMethod",
 			Handler:    _PaymentMethodService_DeletePaymentMethod_Handler,
@@ -403,6 +635,22 @@ var PaymentMethodService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ConvertFinancialBRICToStorageBRIC",
 			Handler:    _PaymentMethodService_ConvertFinancialBRICToStorageBRIC_Handler,
 		},
+		{
+			MethodName: "TokenizePaymentMethod",
+			Handler:    _PaymentMethodService_TokenizePaymentMethod_Handler,
+		},
+		{
+			MethodName: "ProcessACHReturn",
+			Handler:    _PaymentMethodService_ProcessACHReturn_Handler,
+		},
+		{
+			MethodName: "InitiateMicroDeposits",
+			Handler:    _PaymentMethodService_InitiateMicroDeposits_Handler,
+		},
+		{
+			MethodName: "VerifyMicroDeposits",
+			Handler:    _PaymentMethodService_VerifyMicroDeposits_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/payment_method/v1/payment_method.proto",
@@ -151,6 +151,7 @@ type CreateSubscriptionRequest struct {
 	MaxRetries      int32                  `protobuf:"varint,9,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"` // Default: 3
 	Metadata        map[string]string      `protobuf:"bytes,10,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	IdempotencyKey  string                 `protobuf:"bytes,11,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	CouponId        *string                `protobuf:"bytes,12,opt,name=coupon_id,json=couponId,proto3,oneof" json:"coupon_id,omitempty"` // Optional: UUID of coupon to discount charges
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -262,6 +263,13 @@ func (x *CreateSubscriptionRequest) GetIdempotencyKey() string {
 	return ""
 }
 
+func (x *CreateSubscriptionRequest) GetCouponId() string {
+	if x != nil && x.CouponId != nil {
+		return *x.CouponId
+	}
+	return ""
+}
+
 // UpdateSubscriptionRequest updates subscription properties
 type UpdateSubscriptionRequest struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
@@ -657,6 +665,436 @@ func (x *ListCustomerSubscriptionsResponse) GetSubscriptions() []*Subscription {
 	return nil
 }
 
+// ListSubscriptionTransactionsRequest lists transactions generated by a subscription
+type ListSubscriptionTransactionsRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SubscriptionId string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	Limit          int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // Default: 100
+	Offset         int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListSubscriptionTransactionsRequest) Reset() {
+	*x = ListSubscriptionTransactionsRequest{}
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscriptionTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscriptionTransactionsRequest) ProtoMessage() {}
+
+func (x *ListSubscriptionTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscriptionTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSubscriptionTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListSubscriptionTransactionsRequest) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+func (x *ListSubscriptionTransactionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListSubscriptionTransactionsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// ListSubscriptionTransactionsResponse contains the subscription's billing history (newest first)
+type ListSubscriptionTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*BillingTransaction  `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSubscriptionTransactionsResponse) Reset() {
+	*x = ListSubscriptionTransactionsResponse{}
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscriptionTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscriptionTransactionsResponse) ProtoMessage() {}
+
+func (x *ListSubscriptionTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscriptionTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSubscriptionTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListSubscriptionTransactionsResponse) GetTransactions() []*BillingTransaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *ListSubscriptionTransactionsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+// BillingTransaction is a single charge, failed attempt, or refund tied to a subscription
+type BillingTransaction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	GroupId       string                 `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Amount        string                 `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"` // Decimal as string
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`                                          // completed, failed, refunded, voided, pending
+	Type          string                 `protobuf:"bytes,6,opt,name=type,proto3" json:"type,omitempty"`                                              // charge, refund, ...
+	AuthResp      string                 `protobuf:"bytes,7,opt,name=auth_resp,json=authResp,proto3" json:"auth_resp,omitempty"`                      // EPX response code
+	FailureReason *string                `protobuf:"bytes,8,opt,name=failure_reason,json=failureReason,proto3,oneof" json:"failure_reason,omitempty"` // Gateway decline message for failed charges
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BillingTransaction) Reset() {
+	*x = BillingTransaction{}
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BillingTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BillingTransaction) ProtoMessage() {}
+
+func (x *BillingTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BillingTransaction.ProtoReflect.Descriptor instead.
+func (*BillingTransaction) Descriptor() ([]byte, []int) {
+	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BillingTransaction) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *BillingTransaction) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *BillingTransaction) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *BillingTransaction) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *BillingTransaction) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *BillingTransaction) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *BillingTransaction) GetAuthResp() string {
+	if x != nil {
+		return x.AuthResp
+	}
+	return ""
+}
+
+func (x *BillingTransaction) GetFailureReason() string {
+	if x != nil && x.FailureReason != nil {
+		return *x.FailureReason
+	}
+	return ""
+}
+
+func (x *BillingTransaction) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// GetSubscriptionChargeHistoryRequest requests a merchant's subscription billing attempts
+type GetSubscriptionChargeHistoryRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	SubscriptionId string                 `protobuf:"bytes,2,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetSubscriptionChargeHistoryRequest) Reset() {
+	*x = GetSubscriptionChargeHistoryRequest{}
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSubscriptionChargeHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSubscriptionChargeHistoryRequest) ProtoMessage() {}
+
+func (x *GetSubscriptionChargeHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSubscriptionChargeHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetSubscriptionChargeHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetSubscriptionChargeHistoryRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GetSubscriptionChargeHistoryRequest) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+// GetSubscriptionChargeHistoryResponse contains the billing attempts in chronological order
+type GetSubscriptionChargeHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attempts      []*ChargeAttempt       `protobuf:"bytes,1,rep,name=attempts,proto3" json:"attempts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSubscriptionChargeHistoryResponse) Reset() {
+	*x = GetSubscriptionChargeHistoryResponse{}
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSubscriptionChargeHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSubscriptionChargeHistoryResponse) ProtoMessage() {}
+
+func (x *GetSubscriptionChargeHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSubscriptionChargeHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetSubscriptionChargeHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetSubscriptionChargeHistoryResponse) GetAttempts() []*ChargeAttempt {
+	if x != nil {
+		return x.Attempts
+	}
+	return nil
+}
+
+// ChargeAttempt is one approved or declined billing attempt
+type ChargeAttempt struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	AttemptedAt   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=attempted_at,json=attemptedAt,proto3" json:"attempted_at,omitempty"`
+	Amount        string                 `protobuf:"bytes,3,opt,name=amount,proto3" json:"amount,omitempty"` // Decimal as string
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`                                          // completed, failed, pending
+	AuthResp      string                 `protobuf:"bytes,6,opt,name=auth_resp,json=authResp,proto3" json:"auth_resp,omitempty"`                      // EPX response code
+	DeclineCode   *string                `protobuf:"bytes,7,opt,name=decline_code,json=declineCode,proto3,oneof" json:"decline_code,omitempty"`       // e.g. EPX_51 (declined attempts only)
+	DeclineReason *string                `protobuf:"bytes,8,opt,name=decline_reason,json=declineReason,proto3,oneof" json:"decline_reason,omitempty"` // e.g. Insufficient funds (declined attempts only)
+	RetryNumber   int32                  `protobuf:"varint,9,opt,name=retry_number,json=retryNumber,proto3" json:"retry_number,omitempty"`            // 0 for a billing cycle's first attempt, then 1, 2, ... for retries after declines
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChargeAttempt) Reset() {
+	*x = ChargeAttempt{}
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChargeAttempt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChargeAttempt) ProtoMessage() {}
+
+func (x *ChargeAttempt) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChargeAttempt.ProtoReflect.Descriptor instead.
+func (*ChargeAttempt) Descriptor() ([]byte, []int) {
+	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ChargeAttempt) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ChargeAttempt) GetAttemptedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AttemptedAt
+	}
+	return nil
+}
+
+func (x *ChargeAttempt) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *ChargeAttempt) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ChargeAttempt) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ChargeAttempt) GetAuthResp() string {
+	if x != nil {
+		return x.AuthResp
+	}
+	return ""
+}
+
+func (x *ChargeAttempt) GetDeclineCode() string {
+	if x != nil && x.DeclineCode != nil {
+		return *x.DeclineCode
+	}
+	return ""
+}
+
+func (x *ChargeAttempt) GetDeclineReason() string {
+	if x != nil && x.DeclineReason != nil {
+		return *x.DeclineReason
+	}
+	return ""
+}
+
+func (x *ChargeAttempt) GetRetryNumber() int32 {
+	if x != nil {
+		return x.RetryNumber
+	}
+	return 0
+}
+
 // ProcessDueBillingRequest processes billing batch
 type ProcessDueBillingRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -668,7 +1106,7 @@ type ProcessDueBillingRequest struct {
 
 func (x *ProcessDueBillingRequest) Reset() {
 	*x = ProcessDueBillingRequest{}
-	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[8]
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -680,7 +1118,7 @@ func (x *ProcessDueBillingRequest) String() string {
 func (*ProcessDueBillingRequest) ProtoMessage() {}
 
 func (x *ProcessDueBillingRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[8]
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -693,7 +1131,7 @@ func (x *ProcessDueBillingRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProcessDueBillingRequest.ProtoReflect.Descriptor instead.
 func (*ProcessDueBillingRequest) Descriptor() ([]byte, []int) {
-	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{8}
+	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *ProcessDueBillingRequest) GetAsOfDate() *timestamppb.Timestamp {
@@ -724,7 +1162,7 @@ type ProcessDueBillingResponse struct {
 
 func (x *ProcessDueBillingResponse) Reset() {
 	*x = ProcessDueBillingResponse{}
-	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[9]
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -736,7 +1174,7 @@ func (x *ProcessDueBillingResponse) String() string {
 func (*ProcessDueBillingResponse) ProtoMessage() {}
 
 func (x *ProcessDueBillingResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[9]
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -749,7 +1187,7 @@ func (x *ProcessDueBillingResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProcessDueBillingResponse.ProtoReflect.Descriptor instead.
 func (*ProcessDueBillingResponse) Descriptor() ([]byte, []int) {
-	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{9}
+	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *ProcessDueBillingResponse) GetProcessedCount() int32 {
@@ -800,7 +1238,7 @@ type BillingError struct {
 
 func (x *BillingError) Reset() {
 	*x = BillingError{}
-	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[10]
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -812,7 +1250,7 @@ func (x *BillingError) String() string {
 func (*BillingError) ProtoMessage() {}
 
 func (x *BillingError) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[10]
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -825,7 +1263,7 @@ func (x *BillingError) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BillingError.ProtoReflect.Descriptor instead.
 func (*BillingError) Descriptor() ([]byte, []int) {
-	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{10}
+	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *BillingError) GetSubscriptionId() string {
@@ -874,13 +1312,14 @@ type SubscriptionResponse struct {
 	CreatedAt             *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt             *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	CancelledAt           *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=cancelled_at,json=cancelledAt,proto3,oneof" json:"cancelled_at,omitempty"`
+	CouponId              *string                `protobuf:"bytes,15,opt,name=coupon_id,json=couponId,proto3,oneof" json:"coupon_id,omitempty"` // UUID of applied coupon
 	unknownFields         protoimpl.UnknownFields
 	sizeCache             protoimpl.SizeCache
 }
 
 func (x *SubscriptionResponse) Reset() {
 	*x = SubscriptionResponse{}
-	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[11]
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -892,7 +1331,7 @@ func (x *SubscriptionResponse) String() string {
 func (*SubscriptionResponse) ProtoMessage() {}
 
 func (x *SubscriptionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[11]
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -905,7 +1344,7 @@ func (x *SubscriptionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SubscriptionResponse.ProtoReflect.Descriptor instead.
 func (*SubscriptionResponse) Descriptor() ([]byte, []int) {
-	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{11}
+	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *SubscriptionResponse) GetSubscriptionId() string {
@@ -1006,6 +1445,13 @@ func (x *SubscriptionResponse) GetCancelledAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *SubscriptionResponse) GetCouponId() string {
+	if x != nil && x.CouponId != nil {
+		return *x.CouponId
+	}
+	return ""
+}
+
 // Subscription represents a complete subscription record
 type Subscription struct {
 	state      protoimpl.MessageState `protogen:"open.v1"`
@@ -1015,25 +1461,29 @@ type Subscription struct {
 	Amount     string                 `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
 	Currency   string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
 	// Billing interval
-	IntervalValue         int32                  `protobuf:"varint,6,opt,name=interval_value,json=intervalValue,proto3" json:"interval_value,omitempty"`
-	IntervalUnit          IntervalUnit           `protobuf:"varint,7,opt,name=interval_unit,json=intervalUnit,proto3,enum=subscription.v1.IntervalUnit" json:"interval_unit,omitempty"`
-	Status                SubscriptionStatus     `protobuf:"varint,8,opt,name=status,proto3,enum=subscription.v1.SubscriptionStatus" json:"status,omitempty"`
-	PaymentMethodId       string                 `protobuf:"bytes,9,opt,name=payment_method_id,json=paymentMethodId,proto3" json:"payment_method_id,omitempty"` // UUID of saved payment method
-	NextBillingDate       *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=next_billing_date,json=nextBillingDate,proto3" json:"next_billing_date,omitempty"`
-	GatewaySubscriptionId string                 `protobuf:"bytes,11,opt,name=gateway_subscription_id,json=gatewaySubscriptionId,proto3" json:"gateway_subscription_id,omitempty"`
-	FailureRetryCount     int32                  `protobuf:"varint,12,opt,name=failure_retry_count,json=failureRetryCount,proto3" json:"failure_retry_count,omitempty"`
-	MaxRetries            int32                  `protobuf:"varint,13,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`
-	CreatedAt             *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt             *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	CancelledAt           *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=cancelled_at,json=cancelledAt,proto3,oneof" json:"cancelled_at,omitempty"`
-	Metadata              map[string]string      `protobuf:"bytes,17,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields         protoimpl.UnknownFields
-	sizeCache             protoimpl.SizeCache
+	IntervalValue           int32                  `protobuf:"varint,6,opt,name=interval_value,json=intervalValue,proto3" json:"interval_value,omitempty"`
+	IntervalUnit            IntervalUnit           `protobuf:"varint,7,opt,name=interval_unit,json=intervalUnit,proto3,enum=subscription.v1.IntervalUnit" json:"interval_unit,omitempty"`
+	Status                  SubscriptionStatus     `protobuf:"varint,8,opt,name=status,proto3,enum=subscription.v1.SubscriptionStatus" json:"status,omitempty"`
+	PaymentMethodId         string                 `protobuf:"bytes,9,opt,name=payment_method_id,json=paymentMethodId,proto3" json:"payment_method_id,omitempty"` // UUID of saved payment method
+	NextBillingDate         *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=next_billing_date,json=nextBillingDate,proto3" json:"next_billing_date,omitempty"`
+	GatewaySubscriptionId   string                 `protobuf:"bytes,11,opt,name=gateway_subscription_id,json=gatewaySubscriptionId,proto3" json:"gateway_subscription_id,omitempty"`
+	FailureRetryCount       int32                  `protobuf:"varint,12,opt,name=failure_retry_count,json=failureRetryCount,proto3" json:"failure_retry_count,omitempty"`
+	MaxRetries              int32                  `protobuf:"varint,13,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`
+	CreatedAt               *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt               *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	CancelledAt             *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=cancelled_at,json=cancelledAt,proto3,oneof" json:"cancelled_at,omitempty"`
+	Metadata                map[string]string      `protobuf:"bytes,17,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CouponId                *string                `protobuf:"bytes,18,opt,name=coupon_id,json=couponId,proto3,oneof" json:"coupon_id,omitempty"`                                                  // UUID of applied coupon
+	CouponAppliedCount      int32                  `protobuf:"varint,19,opt,name=coupon_applied_count,json=couponAppliedCount,proto3" json:"coupon_applied_count,omitempty"`                       // Charges discounted so far
+	PreviousPaymentMethodId *string                `protobuf:"bytes,20,opt,name=previous_payment_method_id,json=previousPaymentMethodId,proto3,oneof" json:"previous_payment_method_id,omitempty"` // Set when dunning switched the subscription to a card
+	PaymentMethodSwitchedAt *timestamppb.Timestamp `protobuf:"bytes,21,opt,name=payment_method_switched_at,json=paymentMethodSwitchedAt,proto3,oneof" json:"payment_method_switched_at,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
 }
 
 func (x *Subscription) Reset() {
 	*x = Subscription{}
-	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[12]
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1045,7 +1495,7 @@ func (x *Subscription) String() string {
 func (*Subscription) ProtoMessage() {}
 
 func (x *Subscription) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[12]
+	mi := &file_proto_subscription_v1_subscription_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1058,7 +1508,7 @@ func (x *Subscription) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Subscription.ProtoReflect.Descriptor instead.
 func (*Subscription) Descriptor() ([]byte, []int) {
-	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{12}
+	return file_proto_subscription_v1_subscription_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *Subscription) GetId() string {
@@ -1180,11 +1630,39 @@ func (x *Subscription) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *Subscription) GetCouponId() string {
+	if x != nil && x.CouponId != nil {
+		return *x.CouponId
+	}
+	return ""
+}
+
+func (x *Subscription) GetCouponAppliedCount() int32 {
+	if x != nil {
+		return x.CouponAppliedCount
+	}
+	return 0
+}
+
+func (x *Subscription) GetPreviousPaymentMethodId() string {
+	if x != nil && x.PreviousPaymentMethodId != nil {
+		return *x.PreviousPaymentMethodId
+	}
+	return ""
+}
+
+func (x *Subscription) GetPaymentMethodSwitchedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PaymentMethodSwitchedAt
+	}
+	return nil
+}
+
 var File_proto_subscription_v1_subscription_proto protoreflect.FileDescriptor
 
 const file_proto_subscription_v1_subscription_proto_rawDesc = "" +
 	"\n" +
-	"(proto/subscription/v1/subscription.proto\x12\x0fsubscription.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xba\x04\n" +
+	"(proto/subscription/v1/subscription.proto\x12\x0fsubscription.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xea\x04\n" +
 	"\x19CreateSubscriptionRequest\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
 	"\vcustomer_id\x18\x02 \x01(\tR\n" +
@@ -1200,10 +1678,13 @@ const file_proto_subscription_v1_subscription_proto_rawDesc = "" +
 	"maxRetries\x12T\n" +
 	"\bmetadata\x18\n" +
 	" \x03(\v28.subscription.v1.CreateSubscriptionRequest.MetadataEntryR\bmetadata\x12'\n" +
-	"\x0fidempotency_key\x18\v \x01(\tR\x0eidempotencyKey\x1a;\n" +
+	"\x0fidempotency_key\x18\v \x01(\tR\x0eidempotencyKey\x12 \n" +
+	"\tcoupon_id\x18\f \x01(\tH\x00R\bcouponId\x88\x01\x01\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xf6\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\f\n" +
+	"\n" +
+	"_coupon_id\"\xf6\x02\n" +
 	"\x19UpdateSubscriptionRequest\x12'\n" +
 	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\x12\x1b\n" +
 	"\x06amount\x18\x02 \x01(\tH\x00R\x06amount\x88\x01\x01\x12*\n" +
@@ -1233,7 +1714,44 @@ const file_proto_subscription_v1_subscription_proto_rawDesc = "" +
 	"\x06status\x18\x03 \x01(\x0e2#.subscription.v1.SubscriptionStatusH\x00R\x06status\x88\x01\x01B\t\n" +
 	"\a_status\"h\n" +
 	"!ListCustomerSubscriptionsResponse\x12C\n" +
-	"\rsubscriptions\x18\x01 \x03(\v2\x1d.subscription.v1.SubscriptionR\rsubscriptions\"s\n" +
+	"\rsubscriptions\x18\x01 \x03(\v2\x1d.subscription.v1.SubscriptionR\rsubscriptions\"|\n" +
+	"#ListSubscriptionTransactionsRequest\x12'\n" +
+	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\"\x90\x01\n" +
+	"$ListSubscriptionTransactionsResponse\x12G\n" +
+	"\ftransactions\x18\x01 \x03(\v2#.subscription.v1.BillingTransactionR\ftransactions\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\"\xcd\x02\n" +
+	"\x12BillingTransaction\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x19\n" +
+	"\bgroup_id\x18\x02 \x01(\tR\agroupId\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\tR\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x12\n" +
+	"\x04type\x18\x06 \x01(\tR\x04type\x12\x1b\n" +
+	"\tauth_resp\x18\a \x01(\tR\bauthResp\x12*\n" +
+	"\x0efailure_reason\x18\b \x01(\tH\x00R\rfailureReason\x88\x01\x01\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAtB\x11\n" +
+	"\x0f_failure_reason\"i\n" +
+	"#GetSubscriptionChargeHistoryRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12'\n" +
+	"\x0fsubscription_id\x18\x02 \x01(\tR\x0esubscriptionId\"b\n" +
+	"$GetSubscriptionChargeHistoryResponse\x12:\n" +
+	"\battempts\x18\x01 \x03(\v2\x1e.subscription.v1.ChargeAttemptR\battempts\"\xf9\x02\n" +
+	"\rChargeAttempt\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12=\n" +
+	"\fattempted_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vattemptedAt\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\tR\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1b\n" +
+	"\tauth_resp\x18\x06 \x01(\tR\bauthResp\x12&\n" +
+	"\fdecline_code\x18\a \x01(\tH\x00R\vdeclineCode\x88\x01\x01\x12*\n" +
+	"\x0edecline_reason\x18\b \x01(\tH\x01R\rdeclineReason\x88\x01\x01\x12!\n" +
+	"\fretry_number\x18\t \x01(\x05R\vretryNumberB\x0f\n" +
+	"\r_decline_codeB\x11\n" +
+	"\x0f_decline_reason\"s\n" +
 	"\x18ProcessDueBillingRequest\x128\n" +
 	"\n" +
 	"as_of_date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\basOfDate\x12\x1d\n" +
@@ -1250,7 +1768,7 @@ const file_proto_subscription_v1_subscription_proto_rawDesc = "" +
 	"\vcustomer_id\x18\x02 \x01(\tR\n" +
 	"customerId\x12\x14\n" +
 	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1c\n" +
-	"\tretriable\x18\x04 \x01(\bR\tretriable\"\xce\x05\n" +
+	"\tretriable\x18\x04 \x01(\bR\tretriable\"\xfe\x05\n" +
 	"\x14SubscriptionResponse\x12'\n" +
 	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\x12\x19\n" +
 	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1f\n" +
@@ -1269,8 +1787,11 @@ const file_proto_subscription_v1_subscription_proto_rawDesc = "" +
 	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
 	"updated_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12B\n" +
-	"\fcancelled_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampH\x00R\vcancelledAt\x88\x01\x01B\x0f\n" +
-	"\r_cancelled_at\"\x84\a\n" +
+	"\fcancelled_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampH\x00R\vcancelledAt\x88\x01\x01\x12 \n" +
+	"\tcoupon_id\x18\x0f \x01(\tH\x01R\bcouponId\x88\x01\x01B\x0f\n" +
+	"\r_cancelled_atB\f\n" +
+	"\n" +
+	"_coupon_id\"\xc4\t\n" +
 	"\fSubscription\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
 	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x1f\n" +
@@ -1293,11 +1814,19 @@ const file_proto_subscription_v1_subscription_proto_rawDesc = "" +
 	"\n" +
 	"updated_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12B\n" +
 	"\fcancelled_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampH\x00R\vcancelledAt\x88\x01\x01\x12G\n" +
-	"\bmetadata\x18\x11 \x03(\v2+.subscription.v1.Subscription.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\x11 \x03(\v2+.subscription.v1.Subscription.MetadataEntryR\bmetadata\x12 \n" +
+	"\tcoupon_id\x18\x12 \x01(\tH\x01R\bcouponId\x88\x01\x01\x120\n" +
+	"\x14coupon_applied_count\x18\x13 \x01(\x05R\x12couponAppliedCount\x12@\n" +
+	"\x1aprevious_payment_method_id\x18\x14 \x01(\tH\x02R\x17previousPaymentMethodId\x88\x01\x01\x12\\\n" +
+	"\x1apayment_method_switched_at\x18\x15 \x01(\v2\x1a.google.protobuf.TimestampH\x03R\x17paymentMethodSwitchedAt\x88\x01\x01\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0f\n" +
-	"\r_cancelled_at*\x8d\x01\n" +
+	"\r_cancelled_atB\f\n" +
+	"\n" +
+	"_coupon_idB\x1d\n" +
+	"\x1b_previous_payment_method_idB\x1d\n" +
+	"\x1b_payment_method_switched_at*\x8d\x01\n" +
 	"\fIntervalUnit\x12\x1d\n" +
 	"\x19INTERVAL_UNIT_UNSPECIFIED\x10\x00\x12\x15\n" +
 	"\x11INTERVAL_UNIT_DAY\x10\x01\x12\x16\n" +
@@ -1309,7 +1838,7 @@ const file_proto_subscription_v1_subscription_proto_rawDesc = "" +
 	"\x1aSUBSCRIPTION_STATUS_ACTIVE\x10\x01\x12\x1e\n" +
 	"\x1aSUBSCRIPTION_STATUS_PAUSED\x10\x02\x12!\n" +
 	"\x1dSUBSCRIPTION_STATUS_CANCELLED\x10\x03\x12 \n" +
-	"\x1cSUBSCRIPTION_STATUS_PAST_DUE\x10\x042\xec\x06\n" +
+	"\x1cSUBSCRIPTION_STATUS_PAST_DUE\x10\x042\x88\t\n" +
 	"\x13SubscriptionService\x12g\n" +
 	"\x12CreateSubscription\x12*.subscription.v1.CreateSubscriptionRequest\x1a%.subscription.v1.SubscriptionResponse\x12g\n" +
 	"\x12UpdateSubscription\x12*.subscription.v1.UpdateSubscriptionRequest\x1a%.subscription.v1.SubscriptionResponse\x12g\n" +
@@ -1317,7 +1846,9 @@ const file_proto_subscription_v1_subscription_proto_rawDesc = "" +
 	"\x11PauseSubscription\x12).subscription.v1.PauseSubscriptionRequest\x1a%.subscription.v1.SubscriptionResponse\x12g\n" +
 	"\x12ResumeSubscription\x12*.subscription.v1.ResumeSubscriptionRequest\x1a%.subscription.v1.SubscriptionResponse\x12Y\n" +
 	"\x0fGetSubscription\x12'.subscription.v1.GetSubscriptionRequest\x1a\x1d.subscription.v1.Subscription\x12\x82\x01\n" +
-	"\x19ListCustomerSubscriptions\x121.subscription.v1.ListCustomerSubscriptionsRequest\x1a2.subscription.v1.ListCustomerSubscriptionsResponse\x12j\n" +
+	"\x19ListCustomerSubscriptions\x121.subscription.v1.ListCustomerSubscriptionsRequest\x1a2.subscription.v1.ListCustomerSubscriptionsResponse\x12\x8b\x01\n" +
+	"\x1cListSubscriptionTransactions\x124.subscription.v1.ListSubscriptionTransactionsRequest\x1a5.subscription.v1.ListSubscriptionTransactionsResponse\x12\x8b\x01\n" +
+	"\x1cGetSubscriptionChargeHistory\x124.subscription.v1.GetSubscriptionChargeHistoryRequest\x1a5.subscription.v1.GetSubscriptionChargeHistoryResponse\x12j\n" +
 	"\x11ProcessDueBilling\x12).subscription.v1.ProcessDueBillingRequest\x1a*.subscription.v1.ProcessDueBillingResponseBLZJgithub.com/kevin07696/payment-service/proto/subscription/v1;subscriptionv1b\x06proto3"
 
 var (
@@ -1333,70 +1864,85 @@ func file_proto_subscription_v1_subscription_proto_rawDescGZIP() []byte {
 }
 
 var file_proto_subscription_v1_subscription_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_proto_subscription_v1_subscription_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_proto_subscription_v1_subscription_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_proto_subscription_v1_subscription_proto_goTypes = []any{
-	(IntervalUnit)(0),                         // 0: subscription.v1.IntervalUnit
-	(SubscriptionStatus)(0),                   // 1: subscription.v1.SubscriptionStatus
-	(*CreateSubscriptionRequest)(nil),         // 2: subscription.v1.CreateSubscriptionRequest
-	(*UpdateSubscriptionRequest)(nil),         // 3: subscription.v1.UpdateSubscriptionRequest
-	(*CancelSubscriptionRequest)(nil),         // 4: subscription.v1.CancelSubscriptionRequest
-	(*PauseSubscriptionRequest)(nil),          // 5: subscription.v1.PauseSubscriptionRequest
-	(*ResumeSubscriptionRequest)(nil),         // 6: subscription.v1.ResumeSubscriptionRequest
-	(*GetSubscriptionRequest)(nil),            // 7: subscription.v1.GetSubscriptionRequest
-	(*ListCustomerSubscriptionsRequest)(nil),  // 8: subscription.v1.ListCustomerSubscriptionsRequest
-	(*ListCustomerSubscriptionsResponse)(nil), // 9: subscription.v1.ListCustomerSubscriptionsResponse
-	(*ProcessDueBillingRequest)(nil),          // 10: subscription.v1.ProcessDueBillingRequest
-	(*ProcessDueBillingResponse)(nil),         // 11: subscription.v1.ProcessDueBillingResponse
-	(*BillingError)(nil),                      // 12: subscription.v1.BillingError
-	(*SubscriptionResponse)(nil),              // 13: subscription.v1.SubscriptionResponse
-	(*Subscription)(nil),                      // 14: subscription.v1.Subscription
-	nil,                                       // 15: subscription.v1.CreateSubscriptionRequest.MetadataEntry
-	nil,                                       // 16: subscription.v1.Subscription.MetadataEntry
-	(*timestamppb.Timestamp)(nil),             // 17: google.protobuf.Timestamp
+	(IntervalUnit)(0),                            // 0: subscription.v1.IntervalUnit
+	(SubscriptionStatus)(0),                      // 1: subscription.v1.SubscriptionStatus
+	(*CreateSubscriptionRequest)(nil),            // 2: subscription.v1.CreateSubscriptionRequest
+	(*UpdateSubscriptionRequest)(nil),            // 3: subscription.v1.UpdateSubscriptionRequest
+	(*CancelSubscriptionRequest)(nil),            // 4: subscription.v1.CancelSubscriptionRequest
+	(*PauseSubscriptionRequest)(nil),             // 5: subscription.v1.PauseSubscriptionRequest
+	(*ResumeSubscriptionRequest)(nil),            // 6: subscription.v1.ResumeSubscriptionRequest
+	(*GetSubscriptionRequest)(nil),               // 7: subscription.v1.GetSubscriptionRequest
+	(*ListCustomerSubscriptionsRequest)(nil),     // 8: subscription.v1.ListCustomerSubscriptionsRequest
+	(*ListCustomerSubscriptionsResponse)(nil),    // 9: subscription.v1.ListCustomerSubscriptionsResponse
+	(*ListSubscriptionTransactionsRequest)(nil),  // 10: subscription.v1.ListSubscriptionTransactionsRequest
+	(*ListSubscriptionTransactionsResponse)(nil), // 11: subscription.v1.ListSubscriptionTransactionsResponse
+	(*BillingTransaction)(nil),                   // 12: subscription.v1.BillingTransaction
+	(*GetSubscriptionChargeHistoryRequest)(nil),  // 13: subscription.v1.GetSubscriptionChargeHistoryRequest
+	(*GetSubscriptionChargeHistoryResponse)(nil), // 14: subscription.v1.GetSubscriptionChargeHistoryResponse
+	(*ChargeAttempt)(nil),                        // 15: subscription.v1.ChargeAttempt
+	(*ProcessDueBillingRequest)(nil),             // 16: subscription.v1.ProcessDueBillingRequest
+	(*ProcessDueBillingResponse)(nil),            // 17: subscription.v1.ProcessDueBillingResponse
+	(*BillingError)(nil),                         // 18: subscription.v1.BillingError
+	(*SubscriptionResponse)(nil),                 // 19: subscription.v1.SubscriptionResponse
+	(*Subscription)(nil),                         // 20: subscription.v1.Subscription
+	nil,                                          // 21: subscription.v1.CreateSubscriptionRequest.MetadataEntry
+	nil,                                          // 22: subscription.v1.Subscription.MetadataEntry
+	(*timestamppb.Timestamp)(nil),                // 23: google.protobuf.Timestamp
 }
 var file_proto_subscription_v1_subscription_proto_depIdxs = []int32{
 	0,  // 0: subscription.v1.CreateSubscriptionRequest.interval_unit:type_name -> subscription.v1.IntervalUnit
-	17, // 1: subscription.v1.CreateSubscriptionRequest.start_date:type_name -> google.protobuf.Timestamp
-	15, // 2: subscription.v1.CreateSubscriptionRequest.metadata:type_name -> subscription.v1.CreateSubscriptionRequest.MetadataEntry
+	23, // 1: subscription.v1.CreateSubscriptionRequest.start_date:type_name -> google.protobuf.Timestamp
+	21, // 2: subscription.v1.CreateSubscriptionRequest.metadata:type_name -> subscription.v1.CreateSubscriptionRequest.MetadataEntry
 	0,  // 3: subscription.v1.UpdateSubscriptionRequest.interval_unit:type_name -> subscription.v1.IntervalUnit
 	1,  // 4: subscription.v1.ListCustomerSubscriptionsRequest.status:type_name -> subscription.v1.SubscriptionStatus
-	14, // 5: subscription.v1.ListCustomerSubscriptionsResponse.subscriptions:type_name -> subscription.v1.Subscription
-	17, // 6: subscription.v1.ProcessDueBillingRequest.as_of_date:type_name -> google.protobuf.Timestamp
-	12, // 7: subscription.v1.ProcessDueBillingResponse.errors:type_name -> subscription.v1.BillingError
-	0,  // 8: subscription.v1.SubscriptionResponse.interval_unit:type_name -> subscription.v1.IntervalUnit
-	1,  // 9: subscription.v1.SubscriptionResponse.status:type_name -> subscription.v1.SubscriptionStatus
-	17, // 10: subscription.v1.SubscriptionResponse.next_billing_date:type_name -> google.protobuf.Timestamp
-	17, // 11: subscription.v1.SubscriptionResponse.created_at:type_name -> google.protobuf.Timestamp
-	17, // 12: subscription.v1.SubscriptionResponse.updated_at:type_name -> google.protobuf.Timestamp
-	17, // 13: subscription.v1.SubscriptionResponse.cancelled_at:type_name -> google.protobuf.Timestamp
-	0,  // 14: subscription.v1.Subscription.interval_unit:type_name -> subscription.v1.IntervalUnit
-	1,  // 15: subscription.v1.Subscription.status:type_name -> subscription.v1.SubscriptionStatus
-	17, // 16: subscription.v1.Subscription.next_billing_date:type_name -> google.protobuf.Timestamp
-	17, // 17: subscription.v1.Subscription.created_at:type_name -> google.protobuf.Timestamp
-	17, // 18: subscription.v1.Subscription.updated_at:type_name -> google.protobuf.Timestamp
-	17, // 19: subscription.v1.Subscription.cancelled_at:type_name -> google.protobuf.Timestamp
-	16, // 20: subscription.v1.Subscription.metadata:type_name -> subscription.v1.Subscription.MetadataEntry
-	2,  // 21: subscription.v1.SubscriptionService.CreateSubscription:input_type -> subscription.v1.CreateSubscriptionRequest
-	3,  // 22: subscription.v1.SubscriptionService.UpdateSubscription:input_type -> subscription.v1.UpdateSubscriptionRequest
-	4,  // 23: subscription.v1.SubscriptionService.CancelSubscription:input_type -> subscription.v1.CancelSubscriptionRequest
-	5,  // 24: subscription.v1.SubscriptionService.PauseSubscription:input_type -> subscription.v1.PauseSubscriptionRequest
-	6,  // 25: subscription.v1.SubscriptionService.ResumeSubscription:input_type -> subscription.v1.ResumeSubscriptionRequest
-	7,  // 26: subscription.v1.SubscriptionService.GetSubscription:input_type -> subscription.v1.GetSubscriptionRequest
-	8,  // 27: subscription.v1.SubscriptionService.ListCustomerSubscriptions:input_type -> subscription.v1.ListCustomerSubscriptionsRequest
-	10, // 28: subscription.v1.SubscriptionService.ProcessDueBilling:input_type -> subscription.v1.ProcessDueBillingRequest
-	13, // 29: subscription.v1.SubscriptionService.CreateSubscription:output_type -> subscription.v1.SubscriptionResponse
-	13, // 30: subscription.v1.SubscriptionService.UpdateSubscription:output_type -> subscription.v1.SubscriptionResponse
-	13, // 31: subscription.v1.SubscriptionService.CancelSubscription:output_type -> subscription.v1.SubscriptionResponse
-	13, // 32: subscription.v1.SubscriptionService.PauseSubscription:output_type -> subscription.v1.SubscriptionResponse
-	13, // 33: subscription.v1.SubscriptionService.ResumeSubscription:output_type -> subscription.v1.SubscriptionResponse
-	14, // 34: subscription.v1.SubscriptionService.GetSubscription:output_type -> subscription.v1.Subscription
-	9,  // 35: subscription.v1.SubscriptionService.ListCustomerSubscriptions:output_type -> subscription.v1.ListCustomerSubscriptionsResponse
-	11, // 36: subscription.v1.SubscriptionService.ProcessDueBilling:output_type -> subscription.v1.ProcessDueBillingResponse
-	29, // [29:37] is the sub-list for method output_type
-	21, // [21:29] is the sub-list for method input_type
-	21, // [21:21] is the sub-list for extension type_name
-	21, // [21:21] is the sub-list for extension extendee
-	0,  // [0:21] is the sub-list for field type_name
+	20, // 5: subscription.v1.ListCustomerSubscriptionsResponse.subscriptions:type_name -> subscription.v1.Subscription
+	12, // 6: subscription.v1.ListSubscriptionTransactionsResponse.transactions:type_name -> subscription.v1.BillingTransaction
+	23, // 7: subscription.v1.BillingTransaction.created_at:type_name -> google.protobuf.Timestamp
+	15, // 8: subscription.v1.GetSubscriptionChargeHistoryResponse.attempts:type_name -> subscription.v1.ChargeAttempt
+	23, // 9: subscription.v1.ChargeAttempt.attempted_at:type_name -> google.protobuf.Timestamp
+	23, // 10: subscription.v1.ProcessDueBillingRequest.as_of_date:type_name -> google.protobuf.Timestamp
+	18, // 11: subscription.v1.ProcessDueBillingResponse.errors:type_name -> subscription.v1.BillingError
+	0,  // 12: subscription.v1.SubscriptionResponse.interval_unit:type_name -> subscription.v1.IntervalUnit
+	1,  // 13: subscription.v1.SubscriptionResponse.status:type_name -> subscription.v1.SubscriptionStatus
+	23, // 14: subscription.v1.SubscriptionResponse.next_billing_date:type_name -> google.protobuf.Timestamp
+	23, // 15: subscription.v1.SubscriptionResponse.created_at:type_name -> google.protobuf.Timestamp
+	23, // 16: subscription.v1.SubscriptionResponse.updated_at:type_name -> google.protobuf.Timestamp
+	23, // 17: subscription.v1.SubscriptionResponse.cancelled_at:type_name -> google.protobuf.Timestamp
+	0,  // 18: subscription.v1.Subscription.interval_unit:type_name -> subscription.v1.IntervalUnit
+	1,  // 19: subscription.v1.Subscription.status:type_name -> subscription.v1.SubscriptionStatus
+	23, // 20: subscription.v1.Subscription.next_billing_date:type_name -> google.protobuf.Timestamp
+	23, // 21: subscription.v1.Subscription.created_at:type_name -> google.protobuf.Timestamp
+	23, // 22: subscription.v1.Subscription.updated_at:type_name -> google.protobuf.Timestamp
+	23, // 23: subscription.v1.Subscription.cancelled_at:type_name -> google.protobuf.Timestamp
+	22, // 24: subscription.v1.Subscription.metadata:type_name -> subscription.v1.Subscription.MetadataEntry
+	23, // 25: subscription.v1.Subscription.payment_method_switched_at:type_name -> google.protobuf.Timestamp
+	2,  // 26: subscription.v1.SubscriptionService.CreateSubscription:input_type -> subscription.v1.CreateSubscriptionRequest
+	3,  // 27: subscription.v1.SubscriptionService.UpdateSubscription:input_type -> subscription.v1.UpdateSubscriptionRequest
+	4,  // 28: subscription.v1.SubscriptionService.CancelSubscription:input_type -> subscription.v1.CancelSubscriptionRequest
+	5,  // 29: subscription.v1.SubscriptionService.PauseSubscription:input_type -> subscription.v1.PauseSubscriptionRequest
+	6,  // 30: subscription.v1.SubscriptionService.ResumeSubscription:input_type -> subscription.v1.ResumeSubscriptionRequest
+	7,  // 31: subscription.v1.SubscriptionService.GetSubscription:input_type -> subscription.v1.GetSubscriptionRequest
+	8,  // 32: subscription.v1.SubscriptionService.ListCustomerSubscriptions:input_type -> subscription.v1.ListCustomerSubscriptionsRequest
+	10, // 33: subscription.v1.SubscriptionService.ListSubscriptionTransactions:input_type -> subscription.v1.ListSubscriptionTransactionsRequest
+	13, // 34: subscription.v1.SubscriptionService.GetSubscriptionChargeHistory:input_type -> subscription.v1.GetSubscriptionChargeHistoryRequest
+	16, // 35: subscription.v1.SubscriptionService.ProcessDueBilling:input_type -> subscription.v1.ProcessDueBillingRequest
+	19, // 36: subscription.v1.SubscriptionService.CreateSubscription:output_type -> subscription.v1.SubscriptionResponse
+	19, // 37: subscription.v1.SubscriptionService.UpdateSubscription:output_type -> subscription.v1.SubscriptionResponse
+	19, // 38: subscription.v1.SubscriptionService.CancelSubscription:output_type -> subscription.v1.SubscriptionResponse
+	19, // 39: subscription.v1.SubscriptionService.PauseSubscription:output_type -> subscription.v1.SubscriptionResponse
+	19, // 40: subscription.v1.SubscriptionService.ResumeSubscription:output_type -> subscription.v1.SubscriptionResponse
+	20, // 41: subscription.v1.SubscriptionService.GetSubscription:output_type -> subscription.v1.Subscription
+	9,  // 42: subscription.v1.SubscriptionService.ListCustomerSubscriptions:output_type -> subscription.v1.ListCustomerSubscriptionsResponse
+	11, // 43: subscription.v1.SubscriptionService.ListSubscriptionTransactions:output_type -> subscription.v1.ListSubscriptionTransactionsResponse
+	14, // 44: subscription.v1.SubscriptionService.GetSubscriptionChargeHistory:output_type -> subscription.v1.GetSubscriptionChargeHistoryResponse
+	17, // 45: subscription.v1.SubscriptionService.ProcessDueBilling:output_type -> subscription.v1.ProcessDueBillingResponse
+	36, // [36:46] is the sub-list for method output_type
+	26, // [26:36] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
 }
 
 func init() { file_proto_subscription_v1_subscription_proto_init() }
@@ -1404,17 +1950,20 @@ func file_proto_subscription_v1_subscription_proto_init() {
 	if File_proto_subscription_v1_subscription_proto != nil {
 		return
 	}
+	file_proto_subscription_v1_subscription_proto_msgTypes[0].OneofWrappers = []any{}
 	file_proto_subscription_v1_subscription_proto_msgTypes[1].OneofWrappers = []any{}
 	file_proto_subscription_v1_subscription_proto_msgTypes[6].OneofWrappers = []any{}
-	file_proto_subscription_v1_subscription_proto_msgTypes[11].OneofWrappers = []any{}
-	file_proto_subscription_v1_subscription_proto_msgTypes[12].OneofWrappers = []any{}
+	file_proto_subscription_v1_subscription_proto_msgTypes[10].OneofWrappers = []any{}
+	file_proto_subscription_v1_subscription_proto_msgTypes[13].OneofWrappers = []any{}
+	file_proto_subscription_v1_subscription_proto_msgTypes[17].OneofWrappers = []any{}
+	file_proto_subscription_v1_subscription_proto_msgTypes[18].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_subscription_v1_subscription_proto_rawDesc), len(file_proto_subscription_v1_subscription_proto_rawDesc)),
 			NumEnums:      2,
-			NumMessages:   15,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
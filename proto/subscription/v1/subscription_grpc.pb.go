@@ -19,14 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	SubscriptionService_CreateSubscription_FullMethodName        = "/subscription.v1.SubscriptionService/CreateSubscription"
-	SubscriptionService_UpdateSubscription_FullMethodName        = "/subscription.v1.SubscriptionService/UpdateSubscription"
-	SubscriptionService_CancelSubscription_FullMethodName        = "/subscription.v1.SubscriptionService/CancelSubscription"
-	SubscriptionService_PauseSubscription_FullMethodName         = "/subscription.v1.SubscriptionService/PauseSubscription"
-	SubscriptionService_ResumeSubscription_FullMethodName        = "/subscription.v1.SubscriptionService/ResumeSubscription"
-	SubscriptionService_GetSubscription_FullMethodName           = "/subscription.v1.SubscriptionService/GetSubscription"
-	SubscriptionService_ListCustomerSubscriptions_FullMethodName = "/subscription.v1.SubscriptionService/ListCustomerSubscriptions"
-	SubscriptionService_ProcessDueBilling_FullMethodName         = "/subscription.v1.SubscriptionService/ProcessDueBilling"
+	SubscriptionService_CreateSubscription_FullMethodName           = "/subscription.v1.SubscriptionService/CreateSubscription"
+	SubscriptionService_UpdateSubscription_FullMethodName           = "/subscription.v1.SubscriptionService/UpdateSubscription"
+	SubscriptionService_CancelSubscription_FullMethodName           = "/subscription.v1.SubscriptionService/CancelSubscription"
+	SubscriptionService_PauseSubscription_FullMethodName            = "/subscription.v1.SubscriptionService/PauseSubscription"
+	SubscriptionService_ResumeSubscription_FullMethodName           = "/subscription.v1.SubscriptionService/ResumeSubscription"
+	SubscriptionService_GetSubscription_FullMethodName              = "/subscription.v1.SubscriptionService/GetSubscription"
+	SubscriptionService_ListCustomerSubscriptions_FullMethodName    = "/subscription.v1.SubscriptionService/ListCustomerSubscriptions"
+	SubscriptionService_ListSubscriptionTransactions_FullMethodName = "/subscription.v1.SubscriptionService/ListSubscriptionTransactions"
+	SubscriptionService_GetSubscriptionChargeHistory_FullMethodName = "/subscription.v1.SubscriptionService/GetSubscriptionChargeHistory"
+	SubscriptionService_ProcessDueBilling_FullMethodName            = "/subscription.v1.SubscriptionService/ProcessDueBilling"
 )
 
 // SubscriptionServiceClient is the client API for SubscriptionService service.
@@ -49,6 +51,10 @@ type SubscriptionServiceClient interface {
 	GetSubscription(ctx context.Context, in *GetSubscriptionRequest, opts ...grpc.CallOption) (*Subscription, error)
 	// ListCustomerSubscriptions lists all subscriptions for a customer
 	ListCustomerSubscriptions(ctx context.Context, in *ListCustomerSubscriptionsRequest, opts ...grpc.CallOption) (*ListCustomerSubscriptionsResponse, error)
+	// ListSubscriptionTransactions lists the billing history of a subscription
+	ListSubscriptionTransactions(ctx context.Context, in *ListSubscriptionTransactionsRequest, opts ...grpc.CallOption) (*ListSubscriptionTransactionsResponse, error)
+	// GetSubscriptionChargeHistory lists every billing attempt of a subscription with its retry number (oldest first)
+	GetSubscriptionChargeHistory(ctx context.Context, in *GetSubscriptionChargeHistoryRequest, opts ...grpc.CallOption) (*GetSubscriptionChargeHistoryResponse, error)
 	// ProcessDueBilling processes subscriptions due for billing (internal/admin use)
 	ProcessDueBilling(ctx context.Context, in *ProcessDueBillingRequest, opts ...grpc.CallOption) (*ProcessDueBillingResponse, error)
 }
@@ -131,6 +137,26 @@ func (c *subscriptionServiceClient) ListCustomerSubscriptions(ctx context.Contex
 	return out, nil
 }
 
+func (c *subscriptionServiceClient) ListSubscriptionTransactions(ctx context.Context, in *ListSubscriptionTransactionsRequest, opts ...grpc.CallOption) (*ListSubscriptionTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSubscriptionTransactionsResponse)
+	err := c.cc.Invoke(ctx, SubscriptionService_ListSubscriptionTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *subscriptionServiceClient) GetSubscriptionChargeHistory(ctx context.Context, in *GetSubscriptionChargeHistoryRequest, opts ...grpc.CallOption) (*GetSubscriptionChargeHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSubscriptionChargeHistoryResponse)
+	err := c.cc.Invoke(ctx, SubscriptionService_GetSubscriptionChargeHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *subscriptionServiceClient) ProcessDueBilling(ctx context.Context, in *ProcessDueBillingRequest, opts ...grpc.CallOption) (*ProcessDueBillingResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ProcessDueBillingResponse)
@@ -161,6 +187,10 @@ type SubscriptionServiceServer interface {
 	GetSubscription(context.Context, *GetSubscriptionRequest) (*Subscription, error)
 	// ListCustomerSubscriptions lists all subscriptions for a customer
 	ListCustomerSubscriptions(context.Context, *ListCustomerSubscriptionsRequest) (*ListCustomerSubscriptionsResponse, error)
+	// ListSubscriptionTransactions lists the billing history of a subscription
+	ListSubscriptionTransactions(context.Context, *ListSubscriptionTransactionsRequest) (*ListSubscriptionTransactionsResponse, error)
+	// GetSubscriptionChargeHistory lists every billing attempt of a subscription with its retry number (oldest first)
+	GetSubscriptionChargeHistory(context.Context, *GetSubscriptionChargeHistoryRequest) (*GetSubscriptionChargeHistoryResponse, error)
 	// ProcessDueBilling processes subscriptions due for billing (internal/admin use)
 	ProcessDueBilling(context.Context, *ProcessDueBillingRequest) (*ProcessDueBillingResponse, error)
 	mustEmbedUnimplementedSubscriptionServiceServer()
@@ -194,6 +224,12 @@ func (UnimplementedSubscriptionServiceServer) GetSubscription(context.Context, *
 func (UnimplementedSubscriptionServiceServer) ListCustomerSubscriptions(context.Context, *ListCustomerSubscriptionsRequest) (*ListCustomerSubscriptionsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListCustomerSubscriptions not implemented")
 }
+func (UnimplementedSubscriptionServiceServer) ListSubscriptionTransactions(context.Context, *ListSubscriptionTransactionsRequest) (*ListSubscriptionTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubscriptionTransactions not implemented")
+}
+func (UnimplementedSubscriptionServiceServer) GetSubscriptionChargeHistory(context.Context, *GetSubscriptionChargeHistoryRequest) (*GetSubscriptionChargeHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSubscriptionChargeHistory not implemented")
+}
 func (UnimplementedSubscriptionServiceServer) ProcessDueBilling(context.Context, *ProcessDueBillingRequest) (*ProcessDueBillingResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ProcessDueBilling not implemented")
 }
@@ -344,6 +380,42 @@ func _SubscriptionService_ListCustomerSubscriptions_Handler(srv interface{}, ctx
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SubscriptionService_ListSubscriptionTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSubscriptionTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubscriptionServiceServer).ListSubscriptionTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SubscriptionService_ListSubscriptionTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubscriptionServiceServer).ListSubscriptionTransactions(ctx, req.(*ListSubscriptionTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubscriptionService_GetSubscriptionChargeHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSubscriptionChargeHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubscriptionServiceServer).GetSubscriptionChargeHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SubscriptionService_GetSubscriptionChargeHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubscriptionServiceServer).GetSubscriptionChargeHistory(ctx, req.(*GetSubscriptionChargeHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SubscriptionService_ProcessDueBilling_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ProcessDueBillingRequest)
 	if err := dec(in); err != nil {
@@ -397,6 +469,14 @@ var SubscriptionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListCustomerSubscriptions",
 			Handler:    _SubscriptionService_ListCustomerSubscriptions_Handler,
 		},
+		{
+			MethodName: "ListSubscriptionTransactions",
+			Handler:    _SubscriptionService_ListSubscriptionTransactions_Handler,
+		},
+		{
+			MethodName: "GetSubscriptionChargeHistory",
+			Handler:    _SubscriptionService_GetSubscriptionChargeHistory_Handler,
+		},
 		{
 			MethodName: "ProcessDueBilling",
 			Handler:    _SubscriptionService_ProcessDueBilling_Handler,